@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// generateGo renders the typed Go client for the model
+func generateGo(m *apiModel, packageName string) ([]byte, error) {
+	var body strings.Builder
+	imports := map[string]bool{"context": true}
+
+	for _, def := range m.sortedTypes() {
+		writeGoType(&body, m, def, imports)
+	}
+	for _, op := range m.Operations {
+		writeGoOperation(&body, op, imports)
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by genclient from the OpenAPI specification. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", packageName)
+	for _, pkg := range []string{"context", "net/url", "strconv", "time"} {
+		if imports[pkg] {
+			fmt.Fprintf(&out, "\t%q\n", pkg)
+		}
+	}
+	out.WriteString(")\n")
+	out.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format Go client: %w", err)
+	}
+	return formatted, nil
+}
+
+// writeGoType renders a struct type, plus the query encoder of parameter types
+func writeGoType(b *strings.Builder, m *apiModel, def *typeDef, imports map[string]bool) {
+	doc := def.Doc
+	if doc == "" {
+		doc = "is the " + def.Name + " schema of the Heimdall API"
+	}
+	fmt.Fprintf(b, "\n// %s %s\ntype %s struct {\n", def.Name, strings.TrimSuffix(doc, "."), def.Name)
+	for _, f := range def.Fields {
+		if f.Doc != "" {
+			fmt.Fprintf(b, "\t// %s\n", f.Doc)
+		}
+		tag := f.JSONName
+		if !f.Required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "\t%s %s `json:%q`\n", exportedName(f.JSONName), goFieldType(m, def.Name, f, imports), tag)
+	}
+	b.WriteString("}\n")
+
+	if strings.HasSuffix(def.Name, "Params") {
+		writeGoQueryEncoder(b, m, def, imports)
+	}
+}
+
+// writeGoQueryEncoder renders the method encoding a parameter struct into a query string
+func writeGoQueryEncoder(b *strings.Builder, m *apiModel, def *typeDef, imports map[string]bool) {
+	imports["net/url"] = true
+
+	fmt.Fprintf(b, "\n// values encodes the parameters set in p as a query string\nfunc (p *%s) values() url.Values {\n", def.Name)
+	b.WriteString("\tquery := url.Values{}\n\tif p == nil {\n\t\treturn query\n\t}\n")
+	for _, f := range def.Fields {
+		name := exportedName(f.JSONName)
+		switch f.Type.Kind {
+		case kindInteger:
+			imports["strconv"] = true
+			fmt.Fprintf(b, "\tif p.%s != 0 {\n\t\tquery.Set(%q, strconv.Itoa(p.%s))\n\t}\n", name, f.JSONName, name)
+		case kindTime:
+			fmt.Fprintf(b, "\tif p.%s != nil {\n\t\tquery.Set(%q, p.%s.Format(time.RFC3339))\n\t}\n", name, f.JSONName, name)
+		case kindBoolean:
+			imports["strconv"] = true
+			fmt.Fprintf(b, "\tif p.%s {\n\t\tquery.Set(%q, strconv.FormatBool(p.%s))\n\t}\n", name, f.JSONName, name)
+		default:
+			fmt.Fprintf(b, "\tif p.%s != \"\" {\n\t\tquery.Set(%q, p.%s)\n\t}\n", name, f.JSONName, name)
+		}
+	}
+	b.WriteString("\treturn query\n}\n")
+}
+
+// goFieldType returns the Go type of a struct field. Optional scalars of request types
+// are pointers so that unset fields are omitted rather than sent as zero values.
+func goFieldType(m *apiModel, typeName string, f field, imports map[string]bool) string {
+	t := goType(f.Type, imports)
+
+	switch f.Type.Kind {
+	case kindTime, kindNamed:
+		if !f.Required || f.Type.Nullable {
+			return "*" + t
+		}
+	case kindString, kindInteger, kindNumber, kindBoolean:
+		if f.Type.Nullable || (!f.Required && m.isRequestType(typeName)) {
+			return "*" + t
+		}
+	}
+	return t
+}
+
+// goType returns the Go type of a type reference
+func goType(t *typeRef, imports map[string]bool) string {
+	switch t.Kind {
+	case kindString:
+		return "string"
+	case kindTime:
+		imports["time"] = true
+		return "time.Time"
+	case kindInteger:
+		return "int"
+	case kindNumber:
+		return "float64"
+	case kindBoolean:
+		return "bool"
+	case kindArray:
+		return "[]" + goType(t.Elem, imports)
+	case kindMap:
+		return "map[string]interface{}"
+	case kindNamed:
+		return t.Name
+	default:
+		return "interface{}"
+	}
+}
+
+// writeGoOperation renders the client method of an operation
+func writeGoOperation(b *strings.Builder, op *operation, imports map[string]bool) {
+	name := exportedName(op.ID)
+
+	args := []string{"ctx context.Context"}
+	for _, p := range op.PathParams {
+		args = append(args, p.Name+" string")
+	}
+	if op.Body != nil {
+		args = append(args, "req "+goArgType(op.Body, imports))
+	}
+	if op.QueryType != "" {
+		args = append(args, "params *"+op.QueryType)
+	}
+
+	results := "error"
+	if op.Result != nil {
+		results = "(" + goArgType(op.Result, imports) + ", error)"
+	}
+
+	summary := strings.TrimSuffix(op.Summary, ".")
+	fmt.Fprintf(b, "\n// %s calls %s %s: %s\n", name, op.Method, op.Path, lowerFirst(summary))
+	if op.Description != "" && op.Description != op.Summary {
+		fmt.Fprintf(b, "//\n// %s\n", op.Description)
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), results)
+
+	query := "nil"
+	if op.QueryType != "" {
+		query = "params.values()"
+	}
+	body := "nil"
+	if op.Body != nil {
+		body = "req"
+		if !op.BodyRequired {
+			b.WriteString("\tvar body interface{}\n\tif req != nil {\n\t\tbody = req\n\t}\n")
+			body = "body"
+		}
+	}
+	path := goPathExpression(op.Path, imports)
+
+	if op.Result == nil {
+		fmt.Fprintf(b, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n", op.Method, path, query, body)
+		return
+	}
+
+	resultType := goType(op.Result, imports)
+	if op.Result.Kind == kindNamed {
+		fmt.Fprintf(b, "\tvar result %s\n\tif err := c.do(ctx, %q, %s, %s, %s, &result); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &result, nil\n}\n",
+			resultType, op.Method, path, query, body)
+		return
+	}
+	fmt.Fprintf(b, "\tvar result %s\n\tif err := c.do(ctx, %q, %s, %s, %s, &result); err != nil {\n\t\treturn nil, err\n\t}\n\treturn result, nil\n}\n",
+		resultType, op.Method, path, query, body)
+}
+
+// goArgType returns the Go type used to pass or return a value of t
+func goArgType(t *typeRef, imports map[string]bool) string {
+	if t.Kind == kindNamed {
+		return "*" + t.Name
+	}
+	return goType(t, imports)
+}
+
+// goPathExpression returns a Go expression building a request path with escaped path parameters
+func goPathExpression(path string, imports map[string]bool) string {
+	var parts []string
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		if start > 0 {
+			parts = append(parts, fmt.Sprintf("%q", path[:start]))
+		}
+		imports["net/url"] = true
+		parts = append(parts, "url.PathEscape("+path[start+1:end]+")")
+		path = path[end+1:]
+	}
+	if path != "" {
+		parts = append(parts, fmt.Sprintf("%q", path))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// lowerFirst lower-cases the first letter of s
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
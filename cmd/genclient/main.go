@@ -0,0 +1,49 @@
+// Command genclient generates the typed Go and TypeScript API clients from the
+// OpenAPI specification produced by internal/openapi, so the clients stay in
+// sync with the server's handlers.
+//
+// Run it from the repository root after changing the API:
+//
+//	go run ./cmd/genclient
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/techsavvyash/heimdall/internal/openapi"
+)
+
+func main() {
+	goOut := flag.String("go-out", "pkg/client/api_gen.go", "Output file of the generated Go client")
+	goPackage := flag.String("go-package", "client", "Package name of the generated Go client")
+	tsOut := flag.String("ts-out", "sdk/nodejs/src/api.ts", "Output file of the generated TypeScript client")
+	flag.Parse()
+
+	model, err := buildModel(openapi.NewGenerator().GenerateSpec())
+	if err != nil {
+		log.Fatalf("Failed to build client model: %v", err)
+	}
+
+	goSource, err := generateGo(model, *goPackage)
+	if err != nil {
+		log.Fatalf("Failed to generate Go client: %v", err)
+	}
+	writeFile(*goOut, goSource)
+	writeFile(*tsOut, generateTypeScript(model))
+
+	log.Printf("✅ Generated %d operations and %d types", len(model.Operations), len(model.Types))
+}
+
+// writeFile writes generated code, creating parent directories as needed
+func writeFile(path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", path, err)
+	}
+	log.Printf("📝 Wrote %s", path)
+}
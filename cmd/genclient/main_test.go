@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/openapi"
+)
+
+// TestGeneratedClientsUpToDate fails when the API changed without regenerating the clients
+func TestGeneratedClientsUpToDate(t *testing.T) {
+	model, err := buildModel(openapi.NewGenerator().GenerateSpec())
+	if err != nil {
+		t.Fatalf("Failed to build client model: %v", err)
+	}
+
+	goSource, err := generateGo(model, "client")
+	if err != nil {
+		t.Fatalf("Failed to generate Go client: %v", err)
+	}
+
+	generated := map[string][]byte{
+		"../../pkg/client/api_gen.go": goSource,
+		"../../sdk/nodejs/src/api.ts": generateTypeScript(model),
+	}
+	for path, expected := range generated {
+		actual, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("Expected %s to match the generated client, run 'go run ./cmd/genclient'", path)
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"getUserById":   "GetUserByID",
+		"tenantId":      "TenantID",
+		"policyIds":     "PolicyIDs",
+		"ipAddress":     "IPAddress",
+		"createdAfter":  "CreatedAfter",
+		"refresh_token": "RefreshToken",
+	}
+
+	for input, expected := range tests {
+		if name := exportedName(input); name != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, input, name)
+		}
+	}
+}
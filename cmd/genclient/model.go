@@ -0,0 +1,443 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// typeKind is the language-neutral kind of a type reference
+type typeKind int
+
+const (
+	kindAny typeKind = iota
+	kindString
+	kindTime
+	kindInteger
+	kindNumber
+	kindBoolean
+	kindArray
+	kindMap
+	kindNamed
+)
+
+// typeRef references a type in the client model
+type typeRef struct {
+	Kind     typeKind
+	Elem     *typeRef // Element type of arrays
+	Name     string   // Name of named types
+	Enum     []string // Allowed values of strings
+	Nullable bool
+}
+
+// field is a property of a named object type
+type field struct {
+	JSONName string
+	Type     *typeRef
+	Required bool
+	Doc      string
+}
+
+// typeDef is a named object type
+type typeDef struct {
+	Name   string
+	Doc    string
+	Fields []field
+}
+
+// param is a path or query parameter
+type param struct {
+	Name     string
+	Type     *typeRef
+	Required bool
+	Doc      string
+}
+
+// operation is a single API call
+type operation struct {
+	ID           string // operationId, e.g. "listPolicies"
+	Method       string
+	Path         string // Full request path including the API prefix
+	Summary      string
+	Description  string
+	PathParams   []param
+	QueryType    string // Named type holding the query parameters, empty when none
+	Body         *typeRef
+	BodyRequired bool
+	Result       *typeRef // nil when the response carries only a message
+}
+
+// apiModel is the language-neutral client model built from an OpenAPI spec
+type apiModel struct {
+	Types        map[string]*typeDef
+	Operations   []*operation
+	requestTypes map[string]bool
+}
+
+// skippedTags lists operations that are not part of the versioned API and are left out of the clients
+var skippedTags = map[string]bool{
+	"Health":        true, // Served outside the /v1 prefix
+	"OAuth":         true, // Follows RFC 6749 instead of the API's envelope, used through OAuth libraries
+	"SAML":          true, // Browser redirects and identity provider callbacks
+	"Key Discovery": true, // Plain JWKS documents, used through JWT libraries
+	"Webhooks":      true, // Called by external services
+}
+
+// buildModel converts an OpenAPI spec into a client model
+func buildModel(spec *openapi3.T) (*apiModel, error) {
+	m := &apiModel{
+		Types:        make(map[string]*typeDef),
+		requestTypes: make(map[string]bool),
+	}
+
+	prefix := ""
+	if len(spec.Servers) > 0 {
+		serverURL, err := url.Parse(spec.Servers[0].URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL: %w", err)
+		}
+		prefix = strings.TrimSuffix(serverURL.Path, "/")
+	}
+
+	schemaNames := make([]string, 0, len(spec.Components.Schemas))
+	for name := range spec.Components.Schemas {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(schemaNames)
+	for _, name := range schemaNames {
+		if isEnvelopeSchema(name) {
+			continue
+		}
+		schema := spec.Components.Schemas[name].Value
+		if err := m.addObject(name, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := spec.Paths.Map()
+	pathKeys := make([]string, 0, len(paths))
+	for path := range paths {
+		pathKeys = append(pathKeys, path)
+	}
+	sort.Strings(pathKeys)
+
+	for _, path := range pathKeys {
+		item := paths[path]
+		for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+			op := item.GetOperation(method)
+			if op == nil || skipOperation(op) {
+				continue
+			}
+			built, err := m.buildOperation(prefix+path, method, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			m.Operations = append(m.Operations, built)
+		}
+	}
+
+	for _, op := range m.Operations {
+		if op.Body != nil {
+			m.markRequestType(op.Body)
+		}
+	}
+
+	return m, nil
+}
+
+// isEnvelopeSchema reports whether a component is a response envelope the clients unwrap
+func isEnvelopeSchema(name string) bool {
+	return name == "SuccessResponse" || name == "MessageResponse" || name == "Error"
+}
+
+// skipOperation reports whether an operation is left out of the clients
+func skipOperation(op *openapi3.Operation) bool {
+	if op.OperationID == "" {
+		return true
+	}
+	for _, tag := range op.Tags {
+		if skippedTags[tag] {
+			return true
+		}
+	}
+	return isDownload(op)
+}
+
+// isDownload reports whether an operation returns raw bytes instead of the
+// JSON envelope, which clients fetch directly, e.g. through a presigned URL
+func isDownload(op *openapi3.Operation) bool {
+	response := op.Responses.Value("200")
+	if response == nil || response.Value == nil || len(response.Value.Content) == 0 {
+		return false
+	}
+	return response.Value.Content.Get("application/json") == nil
+}
+
+// buildOperation converts an OpenAPI operation
+func (m *apiModel) buildOperation(path, method string, op *openapi3.Operation) (*operation, error) {
+	built := &operation{
+		ID:          op.OperationID,
+		Method:      method,
+		Path:        path,
+		Summary:     op.Summary,
+		Description: op.Description,
+	}
+	typeName := exportedName(op.OperationID)
+
+	var query []field
+	for _, ref := range op.Parameters {
+		p := ref.Value
+		if p == nil || p.Schema == nil {
+			continue
+		}
+		t, err := m.resolve(typeName+exportedName(p.Name), p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		switch p.In {
+		case "path":
+			built.PathParams = append(built.PathParams, param{Name: p.Name, Type: t, Required: true, Doc: p.Description})
+		case "query":
+			query = append(query, field{JSONName: p.Name, Type: t, Required: p.Required, Doc: p.Description})
+		}
+	}
+	if len(query) > 0 {
+		built.QueryType = typeName + "Params"
+		if err := m.define(&typeDef{Name: built.QueryType, Doc: "holds the query parameters of " + typeName, Fields: query}); err != nil {
+			return nil, err
+		}
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if media := op.RequestBody.Value.Content.Get("application/json"); media != nil && media.Schema != nil {
+			t, err := m.resolve(typeName+"Request", media.Schema)
+			if err != nil {
+				return nil, err
+			}
+			built.Body = t
+			built.BodyRequired = op.RequestBody.Value.Required
+		}
+	}
+
+	result, err := m.resultType(typeName, op)
+	if err != nil {
+		return nil, err
+	}
+	built.Result = result
+
+	return built, nil
+}
+
+// resultType returns the type of the data field of the operation's success response
+func (m *apiModel) resultType(typeName string, op *openapi3.Operation) (*typeRef, error) {
+	for _, status := range []string{"200", "201", "202"} {
+		response := op.Responses.Value(status)
+		if response == nil || response.Value == nil {
+			continue
+		}
+		media := response.Value.Content.Get("application/json")
+		if media == nil || media.Schema == nil {
+			return nil, nil
+		}
+		if media.Schema.Ref != "" {
+			if refName(media.Schema.Ref) == "MessageResponse" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("success response must use the {success, data} envelope, got %s", media.Schema.Ref)
+		}
+		data, ok := media.Schema.Value.Properties["data"]
+		if !ok {
+			return nil, nil
+		}
+		return m.resolve(typeName+"Result", data)
+	}
+	return nil, nil
+}
+
+// resolve converts a schema into a type reference, defining named types for inline objects
+func (m *apiModel) resolve(inlineName string, ref *openapi3.SchemaRef) (*typeRef, error) {
+	if ref.Ref != "" {
+		return &typeRef{Kind: kindNamed, Name: refName(ref.Ref)}, nil
+	}
+
+	schema := ref.Value
+	if schema == nil || schema.Type == nil || len(*schema.Type) == 0 {
+		return &typeRef{Kind: kindAny, Nullable: schema != nil && schema.Nullable}, nil
+	}
+
+	t := &typeRef{Nullable: schema.Nullable}
+	switch (*schema.Type)[0] {
+	case "string":
+		t.Kind = kindString
+		if schema.Format == "date-time" {
+			t.Kind = kindTime
+		}
+		for _, value := range schema.Enum {
+			t.Enum = append(t.Enum, fmt.Sprint(value))
+		}
+	case "integer":
+		t.Kind = kindInteger
+	case "number":
+		t.Kind = kindNumber
+	case "boolean":
+		t.Kind = kindBoolean
+	case "array":
+		t.Kind = kindArray
+		if schema.Items == nil {
+			t.Elem = &typeRef{Kind: kindAny}
+			break
+		}
+		elem, err := m.resolve(inlineName+"Item", schema.Items)
+		if err != nil {
+			return nil, err
+		}
+		t.Elem = elem
+	case "object":
+		if len(schema.Properties) == 0 {
+			t.Kind = kindMap
+			break
+		}
+		if err := m.addObject(inlineName, schema); err != nil {
+			return nil, err
+		}
+		t.Kind = kindNamed
+		t.Name = inlineName
+	default:
+		t.Kind = kindAny
+	}
+
+	return t, nil
+}
+
+// addObject defines a named type from an object schema
+func (m *apiModel) addObject(name string, schema *openapi3.Schema) error {
+	required := make(map[string]bool, len(schema.Required))
+	for _, property := range schema.Required {
+		required[property] = true
+	}
+
+	def := &typeDef{Name: name, Doc: schema.Description}
+	for _, property := range sortedProperties(schema.Properties) {
+		t, err := m.resolve(name+exportedName(property), schema.Properties[property])
+		if err != nil {
+			return err
+		}
+		doc := ""
+		if value := schema.Properties[property].Value; value != nil {
+			doc = value.Description
+		}
+		def.Fields = append(def.Fields, field{JSONName: property, Type: t, Required: required[property], Doc: doc})
+	}
+
+	return m.define(def)
+}
+
+// define registers a named type, rejecting conflicting names
+func (m *apiModel) define(def *typeDef) error {
+	if _, exists := m.Types[def.Name]; exists {
+		return fmt.Errorf("type %s is defined twice", def.Name)
+	}
+	m.Types[def.Name] = def
+	return nil
+}
+
+// markRequestType records a type and the types it references as request bodies
+func (m *apiModel) markRequestType(t *typeRef) {
+	for t.Kind == kindArray {
+		t = t.Elem
+	}
+	if t.Kind != kindNamed || m.requestTypes[t.Name] {
+		return
+	}
+	m.requestTypes[t.Name] = true
+
+	if def, ok := m.Types[t.Name]; ok {
+		for _, f := range def.Fields {
+			m.markRequestType(f.Type)
+		}
+	}
+}
+
+// isRequestType reports whether a named type is sent as (part of) a request body
+func (m *apiModel) isRequestType(name string) bool {
+	return m.requestTypes[name]
+}
+
+// sortedTypes returns the named types in name order
+func (m *apiModel) sortedTypes() []*typeDef {
+	names := make([]string, 0, len(m.Types))
+	for name := range m.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	defs := make([]*typeDef, 0, len(names))
+	for _, name := range names {
+		defs = append(defs, m.Types[name])
+	}
+	return defs
+}
+
+// refName returns the component name of a schema reference
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// sortedProperties returns the property names of a schema in sorted order
+func sortedProperties(properties openapi3.Schemas) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initialisms are words written in all caps in exported Go names
+var initialisms = map[string]string{
+	"id": "ID", "ids": "IDs", "ip": "IP", "url": "URL", "uri": "URI",
+	"api": "API", "jwt": "JWT", "jwks": "JWKS", "json": "JSON", "http": "HTTP",
+}
+
+// exportedName converts a camelCase identifier into an exported Go name, e.g. "getUserById" -> "GetUserByID"
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		if upper, ok := initialisms[strings.ToLower(word)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// splitWords splits a camelCase, snake_case or kebab-case identifier into words
+func splitWords(name string) []string {
+	var words []string
+	var current []rune
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == '.':
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		case unicode.IsUpper(r) && i > 0 && len(current) > 0:
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateTypeScript renders the typed TypeScript client for the model.
+// The client wraps the SDK's axios instance so it shares its authentication and tenant handling.
+func generateTypeScript(m *apiModel) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by genclient from the OpenAPI specification. DO NOT EDIT.\n\n")
+	b.WriteString("import { AxiosInstance, AxiosRequestConfig } from 'axios';\n")
+	b.WriteString("import { HeimdallError } from './types';\n")
+
+	for _, def := range m.sortedTypes() {
+		writeTSType(&b, m, def)
+	}
+
+	b.WriteString("\n/**\n * Typed client for every Heimdall API operation.\n * Methods resolve with the response's data field and reject with a HeimdallError.\n */\n")
+	b.WriteString("export class HeimdallApi {\n  constructor(private readonly http: AxiosInstance) {}\n")
+	for _, op := range m.Operations {
+		writeTSOperation(&b, op)
+	}
+	b.WriteString(`
+  private async request<T>(config: AxiosRequestConfig): Promise<T> {
+    try {
+      const response = await this.http.request<{ success: boolean; data: T }>(config);
+      return response.data.data;
+    } catch (error: any) {
+      if (error.response) {
+        const errorData = error.response.data;
+        throw new HeimdallError(
+          errorData?.error?.message || 'Request failed',
+          errorData?.error?.code || 'REQUEST_FAILED',
+          error.response.status
+        );
+      }
+      throw error;
+    }
+  }
+}
+`)
+
+	return []byte(b.String())
+}
+
+// writeTSType renders an interface
+func writeTSType(b *strings.Builder, m *apiModel, def *typeDef) {
+	b.WriteString("\n")
+	if def.Doc != "" {
+		fmt.Fprintf(b, "/** %s */\n", def.Doc)
+	}
+	fmt.Fprintf(b, "export interface %s {\n", def.Name)
+	for _, f := range def.Fields {
+		if f.Doc != "" {
+			fmt.Fprintf(b, "  /** %s */\n", f.Doc)
+		}
+		optional := ""
+		if !f.Required {
+			optional = "?"
+		}
+		fmt.Fprintf(b, "  %s%s: %s;\n", f.JSONName, optional, tsType(f.Type))
+	}
+	b.WriteString("}\n")
+}
+
+// tsType returns the TypeScript type of a type reference
+func tsType(t *typeRef) string {
+	var ts string
+	switch t.Kind {
+	case kindString:
+		if len(t.Enum) > 0 {
+			values := make([]string, len(t.Enum))
+			for i, value := range t.Enum {
+				values[i] = fmt.Sprintf("'%s'", value)
+			}
+			ts = strings.Join(values, " | ")
+		} else {
+			ts = "string"
+		}
+	case kindTime:
+		ts = "string"
+	case kindInteger, kindNumber:
+		ts = "number"
+	case kindBoolean:
+		ts = "boolean"
+	case kindArray:
+		elem := tsType(t.Elem)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		ts = elem + "[]"
+	case kindMap:
+		ts = "Record<string, any>"
+	case kindNamed:
+		ts = t.Name
+	default:
+		ts = "any"
+	}
+
+	if t.Nullable {
+		ts += " | null"
+	}
+	return ts
+}
+
+// writeTSOperation renders the client method of an operation
+func writeTSOperation(b *strings.Builder, op *operation) {
+	var args []string
+	for _, p := range op.PathParams {
+		args = append(args, p.Name+": string")
+	}
+	if op.Body != nil {
+		if op.BodyRequired {
+			args = append(args, "body: "+tsType(op.Body))
+		} else {
+			args = append(args, "body?: "+tsType(op.Body))
+		}
+	}
+	if op.QueryType != "" {
+		args = append(args, "params?: "+op.QueryType)
+	}
+
+	result := "void"
+	if op.Result != nil {
+		result = tsType(op.Result)
+	}
+
+	fmt.Fprintf(b, "\n  /**\n   * %s\n", op.Summary)
+	if op.Description != "" && op.Description != op.Summary {
+		fmt.Fprintf(b, "   *\n   * %s\n", op.Description)
+	}
+	fmt.Fprintf(b, "   *\n   * `%s %s`\n   */\n", op.Method, op.Path)
+	fmt.Fprintf(b, "  async %s(%s): Promise<%s> {\n", op.ID, strings.Join(args, ", "), result)
+
+	config := []string{fmt.Sprintf("method: '%s'", op.Method), "url: " + tsPathExpression(op.Path)}
+	if op.Body != nil {
+		config = append(config, "data: body")
+	}
+	if op.QueryType != "" {
+		config = append(config, "params")
+	}
+	fmt.Fprintf(b, "    return this.request<%s>({ %s });\n  }\n", result, strings.Join(config, ", "))
+}
+
+// tsPathExpression returns a TypeScript expression building a request path with encoded path parameters
+func tsPathExpression(path string) string {
+	if !strings.Contains(path, "{") {
+		return "'" + path + "'"
+	}
+
+	var b strings.Builder
+	b.WriteString("`")
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		b.WriteString(path[:start])
+		fmt.Fprintf(&b, "${encodeURIComponent(%s)}", path[start+1:end])
+		path = path[end+1:]
+	}
+	b.WriteString(path)
+	b.WriteString("`")
+	return b.String()
+}
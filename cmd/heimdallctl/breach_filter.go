@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/breach"
+)
+
+// buildBreachFilter builds the offline breached password filter, deployed with
+// PASSWORD_BREACH_FILTER_PATH, from a list of SHA-1 hashes with one HASH or
+// HASH:COUNT per line, such as the Have I Been Pwned password download
+func buildBreachFilter(args []string) error {
+	fs := flag.NewFlagSet("breach-filter build", flag.ExitOnError)
+	in := fs.String("in", "", "File of SHA-1 hashes, one HASH or HASH:COUNT per line")
+	out := fs.String("out", "breached_passwords.bloom", "Filter file to write")
+	falsePositiveRate := fs.Float64("fp", 0.001, "Share of safe passwords the filter may report breached")
+	_ = fs.Parse(args)
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "Usage: heimdallctl breach-filter build -in <hashes.txt> [-out <file>] [-fp 0.001]")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	// The filter is sized for the number of hashes, so the file is read twice
+	count := 0
+	if err := eachBreachHash(*in, func(string) error { count++; return nil }); err != nil {
+		return err
+	}
+	filter, err := breach.NewBloomFilter(count, *falsePositiveRate)
+	if err != nil {
+		return err
+	}
+	if err := eachBreachHash(*in, filter.AddHash); err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+	writer := bufio.NewWriter(file)
+	if _, err := filter.WriteTo(writer); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write filter: %w", err)
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write filter: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write filter: %w", err)
+	}
+
+	log.Printf("✅ Wrote a filter of %d breached password hashes to %s", count, *out)
+	log.Printf("   Deploy it with PASSWORD_BREACH_FILTER_PATH")
+	return nil
+}
+
+// eachBreachHash calls fn with the hash of each non-empty line of a file
+func eachBreachHash(path string, fn func(hash string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash == "" {
+			continue
+		}
+		if err := fn(hash); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
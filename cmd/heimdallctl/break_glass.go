@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techsavvyash/heimdall/internal/auth"
+)
+
+const (
+	breakGlassPrivateKeyFile = "break_glass_private.pem"
+	breakGlassPublicKeyFile  = "break_glass_public.pem"
+)
+
+// generateBreakGlassKey writes a new break-glass key pair to a directory. The
+// public key is deployed with BREAK_GLASS_PUBLIC_KEY_PATH, the private key is
+// kept offline.
+func generateBreakGlassKey(args []string) error {
+	fs := flag.NewFlagSet("break-glass keygen", flag.ExitOnError)
+	dir := parseDir(fs, args)
+
+	privateKeyPEM, publicKeyPEM, err := auth.GenerateRSAKeyPEM(4096)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	privateKeyPath := filepath.Join(dir, breakGlassPrivateKeyFile)
+	if _, err := os.Stat(privateKeyPath); err == nil {
+		return fmt.Errorf("%s already exists", privateKeyPath)
+	}
+	if err := os.WriteFile(privateKeyPath, []byte(privateKeyPEM), 0o600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, breakGlassPublicKeyFile), []byte(publicKeyPEM), 0o644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	log.Printf("✅ Wrote %s and %s to %s", breakGlassPrivateKeyFile, breakGlassPublicKeyFile, dir)
+	log.Printf("   Deploy the public key with BREAK_GLASS_PUBLIC_KEY_PATH and keep the private key offline")
+	return nil
+}
+
+// issueBreakGlassToken signs a break-glass token with the offline break-glass
+// key and prints it
+func issueBreakGlassToken(args []string) error {
+	fs := flag.NewFlagSet("break-glass issue", flag.ExitOnError)
+	keyPath := fs.String("key", "", "Break-glass private key")
+	userID := fs.String("user", "", "ID of the emergency user the token acts as")
+	tenantID := fs.String("tenant", os.Getenv("HEIMDALL_TENANT_ID"), "Tenant of the emergency user")
+	operator := fs.String("operator", os.Getenv("USER"), "Operator using the token")
+	reason := fs.String("reason", "", "Why emergency access is needed, e.g. an incident ID")
+	ttl := fs.Duration("ttl", 30*time.Minute, "Token lifetime, at most BREAK_GLASS_MAX_TTL_MINUTES")
+	_ = fs.Parse(args)
+
+	if *keyPath == "" || *userID == "" || *tenantID == "" || *operator == "" || *reason == "" {
+		fmt.Fprintln(os.Stderr, "Usage: heimdallctl break-glass issue -key <file> -user <id> -tenant <id> -operator <name> -reason <text> [-ttl 30m]")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	privateKeyData, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read break-glass private key: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyData)
+	if err != nil {
+		return fmt.Errorf("failed to parse break-glass private key: %w", err)
+	}
+
+	token, err := auth.IssueBreakGlassToken(privateKey, *userID, *tenantID, *operator, *reason, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	log.Printf("🚨 Issued a break-glass token for %s, expiring at %s", *operator, time.Now().Add(*ttl).UTC().Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,262 @@
+// Command heimdallctl manages a Heimdall deployment from the command line.
+//
+// Policies can be kept as .rego files in a directory (e.g. a Git checkout) and
+// pushed to or pulled from the current tenant:
+//
+//	heimdallctl policy push ./policies -dry-run
+//	heimdallctl policy pull ./policies
+//
+// The server, token and tenant are read from HEIMDALL_URL, HEIMDALL_TOKEN and
+// HEIMDALL_TENANT_ID, or from the -url, -token and -tenant flags.
+//
+// Break-glass tokens for emergency access are issued offline with the
+// break-glass private key, without contacting the server:
+//
+//	heimdallctl break-glass keygen ./keys
+//	heimdallctl break-glass issue -key ./keys/break_glass_private.pem -user <id> -tenant <id> -operator alice -reason "INC-1234"
+//
+// The platform signing key is rotated without invalidating issued tokens:
+//
+//	heimdallctl signing-key rotate
+//	heimdallctl signing-key list
+//
+// The offline filter of breached passwords is built from a list of SHA-1 hashes:
+//
+//	heimdallctl breach-filter build -in pwned-passwords.txt -out breached_passwords.bloom
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/policyfs"
+	"github.com/techsavvyash/heimdall/internal/utils"
+	"github.com/techsavvyash/heimdall/pkg/client"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 3 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] + " " + os.Args[2] {
+	case "policy push":
+		err = pushPolicies(os.Args[3:])
+	case "policy pull":
+		err = pullPolicies(os.Args[3:])
+	case "break-glass keygen":
+		err = generateBreakGlassKey(os.Args[3:])
+	case "break-glass issue":
+		err = issueBreakGlassToken(os.Args[3:])
+	case "signing-key list":
+		err = listSigningKeys(os.Args[3:])
+	case "signing-key rotate":
+		err = rotateSigningKey(os.Args[3:])
+	case "breach-filter build":
+		err = buildBreachFilter(os.Args[3:])
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+}
+
+// connection holds the flags shared by all commands
+type connection struct {
+	url     *string
+	token   *string
+	tenant  *string
+	timeout *time.Duration
+}
+
+func newConnection(fs *flag.FlagSet) *connection {
+	return &connection{
+		url:     fs.String("url", envOr("HEIMDALL_URL", "http://localhost:8080"), "Heimdall server URL"),
+		token:   fs.String("token", os.Getenv("HEIMDALL_TOKEN"), "Access token"),
+		tenant:  fs.String("tenant", os.Getenv("HEIMDALL_TENANT_ID"), "Tenant ID"),
+		timeout: fs.Duration("timeout", time.Minute, "Request timeout"),
+	}
+}
+
+func (c *connection) client() *client.Client {
+	return client.New(*c.url, client.WithAccessToken(*c.token), client.WithTenantID(*c.tenant))
+}
+
+// pushPolicies syncs the tenant's policies to the .rego files in a directory
+func pushPolicies(args []string) error {
+	fs := flag.NewFlagSet("policy push", flag.ExitOnError)
+	conn := newConnection(fs)
+	dryRun := fs.Bool("dry-run", false, "Show the changes without applying them")
+	deleteMissing := fs.Bool("delete", false, "Delete policies that have no file in the directory")
+	dir := parseDir(fs, args)
+
+	files, err := policyfs.Read(dir)
+	if err != nil {
+		return err
+	}
+
+	req := &client.SyncPoliciesRequest{
+		Files:  make([]client.PolicyFile, 0, len(files)),
+		DryRun: dryRun,
+		Delete: deleteMissing,
+	}
+	for _, file := range files {
+		req.Files = append(req.Files, client.PolicyFile{Path: file.Path, Content: file.Content})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *conn.timeout)
+	defer cancel()
+
+	result, err := conn.client().SyncPolicies(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range result.Changes {
+		if change.Action == "unchanged" {
+			continue
+		}
+		fmt.Printf("%s %s\n", change.Action, change.Path)
+		fmt.Print(change.Diff)
+	}
+	summary := fmt.Sprintf("%d created, %d updated, %d deleted, %d unchanged",
+		result.Created, result.Updated, result.Deleted, result.Unchanged)
+	if result.DryRun {
+		log.Printf("🔍 Dry run: %s", summary)
+	} else {
+		log.Printf("✅ Pushed policies: %s", summary)
+	}
+	return nil
+}
+
+// pullPolicies writes the tenant's policies to .rego files in a directory
+func pullPolicies(args []string) error {
+	fs := flag.NewFlagSet("policy pull", flag.ExitOnError)
+	conn := newConnection(fs)
+	dryRun := fs.Bool("dry-run", false, "Show the changes without writing files")
+	deleteMissing := fs.Bool("delete", false, "Delete local files that have no policy on the server")
+	dir := parseDir(fs, args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *conn.timeout)
+	defer cancel()
+
+	exported, err := conn.client().ExportPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	local := map[string]string{}
+	if _, err := os.Stat(dir); err == nil {
+		files, err := policyfs.Read(dir)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			local[file.Path] = file.Content
+		}
+	}
+
+	var changed []policyfs.File
+	remote := make(map[string]bool, len(exported.Files))
+	for _, file := range exported.Files {
+		remote[file.Path] = true
+		content, exists := local[file.Path]
+		if exists && content == file.Content {
+			continue
+		}
+		action := "update"
+		if !exists {
+			action = "create"
+		}
+		fmt.Printf("%s %s\n", action, file.Path)
+		fmt.Print(utils.LineDiff(content, file.Content))
+		changed = append(changed, policyfs.File{Path: file.Path, Content: file.Content})
+	}
+
+	var removed []string
+	if *deleteMissing {
+		for path := range local {
+			if !remote[path] {
+				removed = append(removed, path)
+			}
+		}
+		sort.Strings(removed)
+		for _, path := range removed {
+			fmt.Printf("delete %s\n", path)
+			fmt.Print(utils.LineDiff(local[path], ""))
+		}
+	}
+
+	if *dryRun {
+		log.Printf("🔍 Dry run: %d files to write, %d to delete", len(changed), len(removed))
+		return nil
+	}
+
+	if err := policyfs.Write(dir, changed); err != nil {
+		return err
+	}
+	for _, path := range removed {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(path)+policyfs.Extension)); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", path, err)
+		}
+	}
+	log.Printf("✅ Pulled policies: %d files written, %d deleted", len(changed), len(removed))
+	return nil
+}
+
+// parseDir parses a command's flags and returns its directory argument
+func parseDir(fs *flag.FlagSet, args []string) string {
+	_ = fs.Parse(args)
+	dir := fs.Arg(0)
+	// Allow flags after the directory, e.g. "push ./policies -dry-run"
+	if fs.NArg() > 0 {
+		_ = fs.Parse(fs.Args()[1:])
+	}
+	if dir == "" || fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Usage: heimdallctl %s <dir> [flags]\n", fs.Name())
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	return dir
+}
+
+func envOr(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func printUsage() {
+	fmt.Println("Usage: heimdallctl policy <command> <dir> [flags]")
+	fmt.Println("       heimdallctl break-glass <command> [flags]")
+	fmt.Println("       heimdallctl signing-key <command> [flags]")
+	fmt.Println("       heimdallctl breach-filter build [flags]")
+	fmt.Println("\nPolicy commands:")
+	fmt.Println("  push    Sync the tenant's policies to the .rego files in <dir>")
+	fmt.Println("  pull    Write the tenant's policies to .rego files in <dir>")
+	fmt.Println("\nPolicy flags:")
+	fmt.Println("  -dry-run    Show a diff of the changes without applying them")
+	fmt.Println("  -delete     Also delete policies (push) or files (pull) missing on the other side")
+	fmt.Println("  -url, -token, -tenant    Server connection (default $HEIMDALL_URL, $HEIMDALL_TOKEN, $HEIMDALL_TENANT_ID)")
+	fmt.Println("\nBreak-glass commands:")
+	fmt.Println("  keygen <dir>    Generate the break-glass key pair in <dir>")
+	fmt.Println("  issue           Sign a short-lived break-glass token offline (see -h)")
+	fmt.Println("\nSigning key commands:")
+	fmt.Println("  list      List the platform signing keys and their status")
+	fmt.Println("  rotate    Sign new tokens with a new key; tokens signed with the old key stay valid until they expire")
+	fmt.Println("\nBreach filter commands:")
+	fmt.Println("  build    Build the offline breached password filter from SHA-1 hashes (see -h)")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// listSigningKeys prints the platform signing keys
+func listSigningKeys(args []string) error {
+	fs := flag.NewFlagSet("signing-key list", flag.ExitOnError)
+	conn := newConnection(fs)
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *conn.timeout)
+	defer cancel()
+
+	keys, err := conn.client().ListPlatformSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		line := fmt.Sprintf("%s\t%s\tcreated %s", key.Kid, key.Status, key.CreatedAt.Format(time.RFC3339))
+		if key.RetiredAt != nil {
+			line += fmt.Sprintf("\tretired %s", key.RetiredAt.Format(time.RFC3339))
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// rotateSigningKey replaces the active platform signing key. Tokens signed
+// with the previous key stay valid until they expire.
+func rotateSigningKey(args []string) error {
+	fs := flag.NewFlagSet("signing-key rotate", flag.ExitOnError)
+	conn := newConnection(fs)
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *conn.timeout)
+	defer cancel()
+
+	key, err := conn.client().RotatePlatformSigningKey(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Rotated signing key: new tokens are signed with %s", key.Kid)
+	return nil
+}
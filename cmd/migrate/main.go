@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
+	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/config"
 	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/encryption"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -23,6 +31,13 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Values of encrypted columns are read and written with the configured keys
+	keyring, err := encryption.FromConfig(&cfg.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+	encryption.SetKeyring(keyring)
+
 	// Connect to database
 	if err := database.Connect(cfg); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -34,17 +49,58 @@ func main() {
 	// Execute command
 	switch command {
 	case "up", "migrate":
-		if err := database.RunMigrations(db); err != nil {
+		migrator := newMigrator(db)
+		applied, err := migrator.Up(context.Background(), countArg(0))
+		if err != nil {
 			log.Fatalf("Migration failed: %v", err)
 		}
-		log.Println("✅ Migrations completed successfully")
+		log.Printf("✅ Migrations completed successfully (%d applied)", applied)
+
+	case "down":
+		migrator := newMigrator(db)
+		rolledBack, err := migrator.Down(context.Background(), countArg(1))
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("✅ Rolled back %d migrations", rolledBack)
+
+	case "status":
+		status, err := newMigrator(db).Status(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		printStatus(status)
+		if status.Dirty {
+			os.Exit(2)
+		}
+
+	case "force":
+		if len(os.Args) < 3 {
+			log.Fatal("Usage: migrate force <version>")
+		}
+		version, err := strconv.ParseUint(os.Args[2], 10, 32)
+		if err != nil {
+			log.Fatalf("Invalid version %q", os.Args[2])
+		}
+		if err := newMigrator(db).Force(context.Background(), uint(version)); err != nil {
+			log.Fatalf("Force failed: %v", err)
+		}
+		log.Printf("✅ Forced schema version to %d", version)
 
 	case "seed":
-		if err := database.SeedDefaultData(db); err != nil {
-			log.Fatalf("Seed failed: %v", err)
+		// Seed a fixture, the default data unless --file is given
+		flags := flag.NewFlagSet("seed", flag.ExitOnError)
+		file := flags.String("file", "", "Path of a YAML fixture to seed")
+		_ = flags.Parse(os.Args[2:])
+		fixture, err := database.DefaultFixture()
+		if *file != "" {
+			fixture, err = database.LoadFixture(*file)
+		}
+		if err != nil {
+			log.Fatalf("Failed to load fixture: %v", err)
 		}
-		if err := database.SeedDefaultTenant(db); err != nil {
-			log.Fatalf("Tenant seed failed: %v", err)
+		if err := database.Seed(db, fixture); err != nil {
+			log.Fatalf("Seed failed: %v", err)
 		}
 		log.Println("✅ Seed completed successfully")
 
@@ -53,14 +109,60 @@ func main() {
 		if err := database.RunMigrations(db); err != nil {
 			log.Fatalf("Migration failed: %v", err)
 		}
-		if err := database.SeedDefaultData(db); err != nil {
+		if err := database.SeedDefaults(db); err != nil {
 			log.Fatalf("Seed failed: %v", err)
 		}
-		if err := database.SeedDefaultTenant(db); err != nil {
-			log.Fatalf("Tenant seed failed: %v", err)
-		}
 		log.Println("✅ Fresh migration completed successfully")
 
+	case "tenant-keys":
+		// Issue tenant-scoped signing keys for tenants still on the shared key
+		jwtService, err := auth.NewJWTService(&cfg.JWT)
+		if err != nil {
+			log.Fatalf("Failed to initialize JWT service: %v", err)
+		}
+		signingKeyService := service.NewSigningKeyService(db, jwtService)
+		migrated, err := signingKeyService.MigrateAllTenants(context.Background())
+		if err != nil {
+			log.Fatalf("Signing key migration failed after %d tenants: %v", migrated, err)
+		}
+		log.Printf("✅ Migrated %d tenants to tenant-scoped signing keys", migrated)
+
+	case "reencrypt":
+		// Rewrite encrypted columns with the primary key, e.g. after a key rotation
+		if keyring == nil {
+			log.Fatal("ENCRYPTION_KEYS or ENCRYPTION_KEYS_FILE is required to re-encrypt columns")
+		}
+		rewritten, err := service.NewReencryptor(db, keyring).Reencrypt(context.Background())
+		if err != nil {
+			log.Fatalf("Re-encryption failed after %d values: %v", rewritten, err)
+		}
+		log.Printf("✅ Re-encrypted %d values with key %s", rewritten, keyring.PrimaryKeyID())
+
+	case "bootstrap":
+		// Create the system roles and the first super admin
+		flags := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+		req := &service.BootstrapRequest{}
+		flags.StringVar(&req.Email, "email", cfg.Bootstrap.AdminEmail, "Email of the first super admin")
+		flags.StringVar(&req.TenantSlug, "tenant", cfg.Bootstrap.TenantSlug, "Slug of the admin's tenant")
+		flags.StringVar(&req.FirstName, "first-name", "", "First name of the admin")
+		flags.StringVar(&req.LastName, "last-name", "", "Last name of the admin")
+		_ = flags.Parse(os.Args[2:])
+		if req.Email == "" {
+			log.Fatal("Usage: migrate bootstrap --email <email> [--tenant <slug>] [--first-name <name>] [--last-name <name>]")
+		}
+		if err := database.SeedDefaults(db); err != nil {
+			log.Fatalf("Seed failed: %v", err)
+		}
+		identityProvider, err := auth.NewIdentityProvider(&cfg.Auth, db, notify.NewSMTPMailer(&cfg.SMTP))
+		if err != nil {
+			log.Fatalf("Failed to initialize identity provider: %v", err)
+		}
+		result, err := service.NewBootstrapper(db, identityProvider).Bootstrap(context.Background(), req)
+		if err != nil {
+			log.Fatalf("Bootstrap failed: %v", err)
+		}
+		printBootstrap(result)
+
 	default:
 		fmt.Printf("Unknown command: %s\n\n", command)
 		printUsage()
@@ -68,6 +170,61 @@ func main() {
 	}
 }
 
+func newMigrator(db *gorm.DB) *database.Migrator {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	return migrator
+}
+
+// countArg returns the optional migration count argument of up and down
+func countArg(defaultValue int) int {
+	if len(os.Args) < 3 {
+		return defaultValue
+	}
+	if os.Args[2] == "all" {
+		return 0
+	}
+	n, err := strconv.Atoi(os.Args[2])
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid migration count %q", os.Args[2])
+	}
+	return n
+}
+
+func printStatus(status *database.MigrationStatus) {
+	state := "clean"
+	if status.Dirty {
+		state = "dirty"
+	}
+	fmt.Printf("Schema version: %d (%s)\n", status.Version, state)
+	for _, migration := range status.Applied {
+		fmt.Printf("  [x] %06d_%s\n", migration.Version, migration.Name)
+	}
+	for _, migration := range status.Pending {
+		fmt.Printf("  [ ] %06d_%s\n", migration.Version, migration.Name)
+	}
+	if status.Dirty {
+		fmt.Printf("\n❌ Migration %d failed part-way. Repair the schema, then run: migrate force <version>\n", status.Version)
+	}
+}
+
+func printBootstrap(result *service.BootstrapResult) {
+	switch {
+	case !result.Created:
+		log.Printf("✅ Already bootstrapped: %s is super admin, nothing was changed", result.Email)
+	case result.Password == "":
+		log.Printf("✅ Made the existing user %s super admin; they sign in with their current password", result.Email)
+	default:
+		log.Printf("✅ Created super admin %s (user %s)", result.Email, result.UserID)
+		fmt.Println()
+		fmt.Printf("  One-time password: %s\n", result.Password)
+		fmt.Println()
+		fmt.Println("  This password is shown only once. Sign in and change it right away.")
+	}
+}
+
 func printUsage() {
 	fmt.Println("Heimdall Database Migration Tool")
 	fmt.Println()
@@ -75,12 +232,22 @@ func printUsage() {
 	fmt.Println("  go run cmd/migrate/main.go <command>")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  up, migrate  Run database migrations")
-	fmt.Println("  seed         Seed default data (permissions, etc.)")
-	fmt.Println("  fresh        Run migrations and seed data")
+	fmt.Println("  up, migrate [n]  Apply all pending migrations, or the next n")
+	fmt.Println("  down [n|all]     Roll back the last migration, the last n, or all of them")
+	fmt.Println("  status           Show the schema version and pending migrations (exit code 2 when dirty)")
+	fmt.Println("  force <version>  Mark the schema as clean at version without running migrations")
+	fmt.Println("  seed [--file f]  Seed a YAML fixture, the default permissions and tenant by default")
+	fmt.Println("  fresh            Run migrations and seed the default data")
+	fmt.Println("  tenant-keys      Migrate tenants from the shared JWT key to tenant signing keys")
+	fmt.Println("  reencrypt        Encrypt sensitive columns with the primary ENCRYPTION_KEYS key")
+	fmt.Println("  bootstrap        Create the system roles and the first super admin (--email, --tenant)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  go run cmd/migrate/main.go up")
-	fmt.Println("  go run cmd/migrate/main.go seed")
+	fmt.Println("  go run cmd/migrate/main.go down 1")
+	fmt.Println("  go run cmd/migrate/main.go status")
+	fmt.Println("  go run cmd/migrate/main.go force 1")
 	fmt.Println("  go run cmd/migrate/main.go fresh")
+	fmt.Println("  go run cmd/migrate/main.go seed --file fixtures/demo.yaml")
+	fmt.Println("  go run cmd/migrate/main.go bootstrap --email admin@example.com")
 }
@@ -2,103 +2,584 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/techsavvyash/heimdall/internal/adminui"
 	"github.com/techsavvyash/heimdall/internal/api"
 	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/breach"
 	"github.com/techsavvyash/heimdall/internal/config"
 	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/encryption"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/geo"
+	"github.com/techsavvyash/heimdall/internal/grpcapi"
+	"github.com/techsavvyash/heimdall/internal/loginui"
 	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/notify"
 	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/opa/embedded"
 	"github.com/techsavvyash/heimdall/internal/openapi"
+	"github.com/techsavvyash/heimdall/internal/resilience"
 	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/storage"
+	"github.com/techsavvyash/heimdall/internal/utils"
+	"github.com/techsavvyash/heimdall/policies"
+	"google.golang.org/grpc"
 )
 
 func main() {
+	validateSpec := flag.Bool("validate-spec", false, "Check that the OpenAPI spec documents exactly the registered API routes, then exit")
+	flag.Parse()
+	if *validateSpec {
+		os.Exit(validateAPISpec())
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	settings := config.NewReloader(cfg)
+	if cfg.Dev.Enabled {
+		log.Println("⚠️  Development mode: any password signs seeded users in; never use it in production")
+	}
+
+	// Keys encrypting sensitive columns, set before anything reads them
+	keyring, err := encryption.FromConfig(&cfg.Encryption)
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+	encryption.SetKeyring(keyring)
+	if keyring != nil {
+		log.Printf("✅ Column encryption enabled (key %s)", keyring.PrimaryKeyID())
+	} else {
+		log.Println("⚠️  ENCRYPTION_KEYS is not set, sensitive columns are stored unencrypted")
+	}
 
-	// Connect to PostgreSQL
-	if err := database.Connect(cfg); err != nil {
+	// Connect to PostgreSQL, waiting for it to come up
+	startup := context.Background()
+	err = resilience.WaitFor(startup, "database", cfg.Startup.DatabaseWait, cfg.Startup.RetryBackoff, func(context.Context) error {
+		return database.Connect(cfg)
+	})
+	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer database.Close()
 	log.Println("✅ Database connected")
 
-	// Connect to Redis
-	if err := database.ConnectRedis(cfg); err != nil {
-		log.Printf("⚠️  Failed to connect to Redis: %v (continuing without cache)", err)
-	} else {
+	// Apply pending migrations when enabled, or warn about them
+	if err := database.MigrateOnStartup(database.GetDB(), cfg.Database.AutoMigrate); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	// Development databases are seeded with the default data and a fixture
+	if cfg.Dev.Enabled {
+		if err := database.SeedDefaults(database.GetDB()); err != nil {
+			log.Fatalf("Failed to seed the default data: %v", err)
+		}
+		if cfg.Dev.Fixture != "" {
+			fixture, err := database.LoadFixture(cfg.Dev.Fixture)
+			if err != nil {
+				log.Fatalf("Failed to load HEIMDALL_DEV_FIXTURE: %v", err)
+			}
+			if err := database.Seed(database.GetDB(), fixture); err != nil {
+				log.Fatalf("Failed to seed HEIMDALL_DEV_FIXTURE: %v", err)
+			}
+		}
+	}
+
+	// Connect to Redis, unless the in-process store is configured
+	if cfg.Redis.Enabled {
+		err = resilience.WaitFor(startup, "Redis", cfg.Startup.RedisWait, cfg.Startup.RetryBackoff, func(context.Context) error {
+			return database.ConnectRedis(cfg)
+		})
+	}
+	switch {
+	case !cfg.Redis.Enabled:
+		database.UseMemoryStore()
+		log.Println("✅ Using the in-process store instead of Redis")
+	case err != nil:
+		if cfg.Redis.Required {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		database.UseMemoryStore()
+		log.Printf("⚠️  Failed to connect to Redis: %v (using an in-process store, which is not shared between replicas)", err)
+	default:
 		defer database.CloseRedis()
 		log.Println("✅ Redis connected")
 	}
 
-	// Initialize JWT service
+	// Initialize JWT service, generating its keys in development mode
+	if cfg.Dev.Enabled {
+		generated, err := auth.EnsureKeyFiles(cfg.JWT.PrivateKeyPath, cfg.JWT.PublicKeyPath)
+		if err != nil {
+			log.Fatalf("Failed to generate JWT keys: %v", err)
+		}
+		if generated {
+			log.Printf("✅ Generated JWT keys in %s", cfg.JWT.PrivateKeyPath)
+		}
+	}
 	jwtService, err := auth.NewJWTService(&cfg.JWT)
 	if err != nil {
 		log.Fatalf("Failed to initialize JWT service: %v", err)
 	}
 	log.Println("✅ JWT service initialized")
 
-	// Initialize FusionAuth client
-	fusionAuthClient := auth.NewFusionAuthClient(&cfg.Auth)
-	log.Println("✅ FusionAuth client initialized")
-
 	// Get database and redis clients
 	db := database.GetDB()
 	redis := database.GetRedis()
 
+	// Initialize the identity provider (FusionAuth, or native credentials in the database)
+	identityProvider, err := auth.NewIdentityProvider(&cfg.Auth, db, notify.NewSMTPMailer(&cfg.SMTP))
+	if err != nil {
+		log.Fatalf("Failed to initialize identity provider: %v", err)
+	}
+	log.Printf("✅ Identity provider initialized (%s)", cfg.Auth.Provider)
+
+	// On first startup, create the first super admin so policies can be managed
+	if cfg.Bootstrap.AdminEmail != "" {
+		result, err := service.NewBootstrapper(db, identityProvider).Bootstrap(context.Background(), &service.BootstrapRequest{
+			Email:      cfg.Bootstrap.AdminEmail,
+			TenantSlug: cfg.Bootstrap.TenantSlug,
+		})
+		switch {
+		case err != nil:
+			log.Printf("⚠️  Failed to bootstrap the super admin: %v (run: migrate bootstrap --email %s)", err, cfg.Bootstrap.AdminEmail)
+		case result.Created && result.Password != "":
+			log.Printf("✅ Created super admin %s with one-time password %s; it is shown only once, sign in and change it", result.Email, result.Password)
+		case result.Created:
+			log.Printf("✅ Made the existing user %s super admin", result.Email)
+		}
+	}
+
 	// Initialize OPA client and evaluator
+	if cfg.OPA.Embedded {
+		embeddedOPA, err := embedded.Start(context.Background(), policies.Files)
+		if err != nil {
+			log.Fatalf("Failed to start the embedded OPA: %v", err)
+		}
+		defer embeddedOPA.Stop()
+		cfg.OPA.URL = embeddedOPA.URL
+		log.Printf("✅ Embedded OPA started at %s with the bundled policies", embeddedOPA.URL)
+	}
 	opaClient := opa.NewClient(&cfg.OPA)
 	opaEvaluator := opa.NewEvaluator(opaClient, redis, cfg.OPA.EnableCache)
+	opaEvaluator.SetCacheTTL(cfg.OPA.CacheTTL)
+	opaEvaluator.SetMaxStale(cfg.OPA.MaxStale)
+	opaEvaluator.SetRowFilters(cfg.OPA.RowFilters)
 	log.Println("✅ OPA client initialized")
 
-	// Verify OPA is healthy
-	if err := opaClient.HealthCheck(context.Background()); err != nil {
-		log.Printf("⚠️  OPA health check failed: %v (policies may not be enforced properly)", err)
+	// Permissions guarding the management API, from the bundled admin_api
+	// system policy until a copy loaded into OPA is read
+	routePermissions, err := opa.NewRoutePermissions(context.Background(), policies.Files)
+	if err != nil {
+		log.Fatalf("Failed to load the admin API route permissions: %v", err)
+	}
+
+	// Verify OPA is healthy, waiting for it to come up
+	opaErr := resilience.WaitFor(startup, "OPA", cfg.Startup.OPAWait, cfg.Startup.RetryBackoff, opaClient.HealthCheck)
+	if opaErr != nil {
+		log.Printf("⚠️  OPA health check failed: %v (policies may not be enforced properly)", opaErr)
 	} else {
 		log.Println("✅ OPA is healthy")
 	}
 
+	// Dependencies re-checked in the background and recovered once they are
+	// back, critical ones failing readiness while they are unavailable
+	dependencies := resilience.NewDependencies(5 * time.Second)
+
 	// Initialize services
-	authService := service.NewAuthService(db, fusionAuthClient, jwtService, redis)
-	userService := service.NewUserService(db, fusionAuthClient)
-	passwordService := service.NewPasswordService(fusionAuthClient)
+	webhookDispatcher := events.NewWebhookDispatcher(&cfg.Webhooks)
+	loginThrottler := service.NewLoginThrottler(redis, &cfg.Security)
+	authService := service.NewAuthService(db, identityProvider, jwtService, redis, loginThrottler, webhookDispatcher)
+	for _, url := range cfg.LoginHooks.URLs {
+		authService.AddLoginHook(service.NewWebhookLoginHook(url, &cfg.LoginHooks))
+	}
+	userService := service.NewUserService(db, identityProvider)
+	userService.SetSessionStore(redis, cfg.JWT.AccessTokenExpiry)
+
+	// Login history and suspicious login detection
+	var geoResolver geo.Resolver = geo.NoopResolver{}
+	if cfg.Security.GeoIPURL != "" {
+		geoResolver = geo.NewHTTPResolver(cfg.Security.GeoIPURL, 3*time.Second)
+	}
+	var loginAlertMailer notify.Mailer
+	if cfg.Security.LoginAlertEmail {
+		loginAlertMailer = notify.NewSMTPMailer(&cfg.SMTP)
+	}
+	loginHistoryService := service.NewLoginHistoryService(db, geoResolver, webhookDispatcher, loginAlertMailer)
+	authService.SetLoginHistoryService(loginHistoryService)
+	passwordService := service.NewPasswordService(identityProvider)
+
+	// Breached password checks, with the offline filter as fallback when the
+	// range API is unreachable
+	var breachChecker breach.Checker
+	if cfg.PasswordBreach.APIURL != "" {
+		breachChecker = breach.NewHIBPChecker(cfg.PasswordBreach.APIURL, cfg.PasswordBreach.Timeout)
+	}
+	if cfg.PasswordBreach.FilterPath != "" {
+		filter, err := breach.LoadBloomFilter(cfg.PasswordBreach.FilterPath)
+		switch {
+		case err != nil:
+			log.Printf("⚠️  Failed to load breached password filter: %v", err)
+		case breachChecker != nil:
+			breachChecker = breach.NewFallbackChecker(breachChecker, filter)
+		default:
+			breachChecker = filter
+		}
+	}
+	passwordBreach := service.NewPasswordBreachPolicy(db, breachChecker, cfg.PasswordBreach.Enabled)
+	authService.SetPasswordBreachPolicy(passwordBreach)
+	passwordService.SetPasswordBreachPolicy(passwordBreach)
+	passwordPolicy := service.NewPasswordPolicyService(db, service.PasswordPolicy{
+		MinLength:        cfg.PasswordPolicy.MinLength,
+		MaxLength:        cfg.PasswordPolicy.MaxLength,
+		RequireUppercase: cfg.PasswordPolicy.RequireUppercase,
+		RequireLowercase: cfg.PasswordPolicy.RequireLowercase,
+		RequireDigit:     cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol:    cfg.PasswordPolicy.RequireSymbol,
+		DisallowCommon:   cfg.PasswordPolicy.DisallowCommon,
+		DisallowUserInfo: cfg.PasswordPolicy.DisallowUserInfo,
+		HistorySize:      cfg.PasswordPolicy.HistorySize,
+	})
+	authService.SetPasswordPolicyService(passwordPolicy)
+	passwordService.SetPasswordPolicyService(passwordPolicy)
+	if cfg.Auth.Provider == "fusionauth" && cfg.Auth.CredentialCacheTTL > 0 {
+		log.Printf("⚠️  Caching verified credentials for %s to sign users in while FusionAuth is unavailable", cfg.Auth.CredentialCacheTTL)
+		credentialCache := service.NewCredentialCache(redis, cfg.Auth.CredentialCacheTTL)
+		authService.SetCredentialCache(credentialCache)
+		passwordService.SetCredentialCache(credentialCache)
+	}
 	tenantService := service.NewTenantService(db)
+	roleService := service.NewRoleService(db)
+
+	// Tenant-scoped signing keys; tenants without their own key use the shared
+	// platform keys, which may have been rotated since the key files were made
+	signingKeyService := service.NewSigningKeyService(db, jwtService)
+	jwtService.SetKeyStore(signingKeyService)
+	if err := signingKeyService.LoadPlatformKeys(context.Background()); err != nil {
+		log.Fatalf("Failed to load platform signing keys: %v", err)
+	}
+
+	// Tenant claims templates shaping the claims embedded in access tokens
+	claimsTemplateService := service.NewClaimsTemplateService(db)
+	jwtService.SetClaimsTemplates(claimsTemplateService)
+
+	// OAuth clients of tenants, authenticated with the client credentials grant
+	oauthClientService := service.NewOAuthClientService(db, jwtService)
+
+	// Tenant quotas on top of the per-IP rate limits
+	rateLimitService := service.NewRateLimitService(db, &cfg.Server)
+
+	// Tenant IP access lists, enforced on API requests and at login
+	ipAccessService := service.NewIPAccessService(db)
+	authService.AddLoginHook(ipAccessService)
+
+	// Typed user attributes validated per tenant and exposed to policies
+	userAttributeService := service.NewUserAttributeService(db)
+	userService.SetUserAttributeService(userAttributeService)
+
+	// Registry of other services' resources, and attribute sources enriching
+	// authorization inputs, e.g. resource owners from the database
+	resourceService := service.NewResourceService(db)
+	enrichment, err := service.NewEnrichment(db, &cfg.OPA, userAttributeService, resourceService)
+	if err != nil {
+		log.Fatalf("Failed to configure attribute sources: %v", err)
+	}
+	opaEvaluator.SetEnrichment(enrichment)
+
+	// Effective permissions of users whose access tokens do not embed them,
+	// prefetched at login and cached instead of looked up per decision
+	if cfg.OPA.PrefetchPermissions {
+		permissionCache := service.NewPermissionCache(db, redis, cfg.OPA.PermissionCacheTTL)
+		enrichment.Register(opa.AnyResourceType, permissionCache)
+		authService.SetPermissionCache(permissionCache)
+	}
+
+	// CORS origins of tenants' browser applications
+	corsOriginService := service.NewCORSOriginService(db)
+
+	// Routes whose redacted bodies are logged for debugging, selected at runtime
+	bodyLoggingService := service.NewBodyLoggingService(redis, cfg.Server.BodyLogMaxDuration)
+
+	// Audit trail of sensitive administrative actions
+	adminAuditService := service.NewAdminAuditService(db)
+
+	// Decision logs and status reported by OPA sidecars running Heimdall's bundles
+	decisionLogService := service.NewDecisionLogService(db)
+	opaInstanceService := service.NewOPAInstanceService(db)
+
+	// Authorization analytics aggregated from the audit log
+	analyticsService := service.NewAnalyticsService(db)
+
+	// Snapshot of all tenants for the admin landing page
+	overviewService := service.NewOverviewService(db, opaEvaluator)
+
+	// Device logins of CLIs and other tools without a browser
+	deviceService := service.NewDeviceAuthorizationService(db, &cfg.OAuth)
+
+	// Per-tenant SAML service providers
+	samlService, err := service.NewSAMLService(db, redis, &cfg.SAML)
+	if err != nil {
+		log.Fatalf("Failed to initialize SAML: %v", err)
+	}
+
+	// Background jobs, such as bundle builds, run by the workers of all instances
+	jobQueue := service.NewJobQueue(db, &cfg.Jobs)
 
 	// Initialize policy and bundle services
 	policyService := service.NewPolicyService(db, opaClient)
-	bundleService, err := service.NewBundleService(db, &cfg.MinIO)
+	policyService.SetOPABinary(cfg.OPA.Binary)
+	var bundleService *service.BundleService
+	bundleStore, err := storage.New(&cfg.BundleStorage)
 	if err != nil {
 		log.Printf("⚠️  Failed to initialize bundle service: %v (bundle management will not work)", err)
 	} else {
-		// Ensure MinIO bucket exists
-		if err := bundleService.EnsureBucket(context.Background()); err != nil {
-			log.Printf("⚠️  Failed to ensure MinIO bucket: %v", err)
+		bundleService = service.NewBundleService(db, bundleStore, jobQueue)
+		policyService.SetBundleRebuilder(bundleService)
+		// Ensure the bundle storage bucket exists, creating it again once the
+		// storage is back if it is unavailable
+		err := resilience.WaitFor(startup, "bundle storage", cfg.Startup.BundleStorageWait, cfg.Startup.RetryBackoff, bundleService.EnsureBucket)
+		if err != nil {
+			log.Printf("⚠️  Failed to ensure %s bundle storage %s: %v", cfg.BundleStorage.Backend, bundleStore.Bucket(), err)
 		} else {
-			log.Println("✅ MinIO bucket ready")
+			log.Printf("✅ %s bundle storage ready", cfg.BundleStorage.Backend)
 		}
+		dependencies.Add(resilience.Dependency{
+			Name:     config.DependencyBundleStorage,
+			Critical: slices.Contains(cfg.Startup.CriticalDependencies, config.DependencyBundleStorage),
+			Check:    bundleService.EnsureBucket,
+		}, err)
 	}
 	log.Println("✅ Services initialized")
 
+	// Background workers applying queued identity provider changes, repairing
+	// drift between FusionAuth and the users table and purging deactivated users
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// Background workers are waited for on shutdown, after workerCtx is cancelled
+	var workers sync.WaitGroup
+	startWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+
+	// Reload the configuration on SIGHUP, applying the settings that are safe
+	// to change without a restart
+	settings.OnReload(func(cfg *config.Config) {
+		opaEvaluator.SetCacheTTL(cfg.OPA.CacheTTL)
+		opaEvaluator.SetMaxStale(cfg.OPA.MaxStale)
+		opaEvaluator.SetRowFilters(cfg.OPA.RowFilters)
+		rateLimitService.SetServerConfig(&cfg.Server)
+	})
+	startWorker(settings.Watch)
+
+	// Singleton background tasks, e.g. the scheduled cleanup, run by one
+	// instance at a time when several share the database
+	leader := service.NewLeaderElector(db, cfg.Coordination.InstanceID, cfg.Coordination.LeaseTTL)
+	singleton := func(name string, run func(ctx context.Context)) {
+		startWorker(func(ctx context.Context) { leader.Run(ctx, name, run) })
+	}
+
+	// Apply platform key rotations of other instances and expire retired keys
+	if cfg.JWT.KeyRefreshInterval > 0 {
+		startWorker(func(ctx context.Context) { signingKeyService.Run(ctx, cfg.JWT.KeyRefreshInterval) })
+	}
+
+	startWorker(service.NewOutboxProcessor(db, identityProvider, &cfg.Outbox).Run)
+	if fusionAuthClient, ok := identityProvider.(*auth.FusionAuthClient); ok && cfg.Outbox.ReconcileInterval > 0 {
+		reconciler := service.NewUserReconciler(db, fusionAuthClient, cfg.Outbox.ReconcileRepair)
+		singleton("user_reconcile", func(ctx context.Context) { reconciler.Run(ctx, cfg.Outbox.ReconcileInterval) })
+	}
+	if cfg.Outbox.PurgeInterval > 0 {
+		purger := service.NewUserPurger(db, identityProvider, cfg.Outbox.PurgeRetention)
+		singleton("user_purge", func(ctx context.Context) { purger.Run(ctx, cfg.Outbox.PurgeInterval) })
+	}
+	log.Println("✅ Outbox worker started")
+
+	// Roles and role assignments pushed to OPA as data documents whenever they change
+	var rbacSync *service.RBACDataSync
+	if cfg.OPA.DataSyncInterval > 0 {
+		rbacSync = service.NewRBACDataSync(db, opaClient)
+		roleService.SetRBACDataSync(rbacSync)
+		userService.SetRBACDataSync(rbacSync)
+		samlService.SetRBACDataSync(rbacSync)
+		authService.SetRBACDataSync(rbacSync)
+		startWorker(func(ctx context.Context) { rbacSync.Run(ctx, cfg.OPA.DataSyncInterval) })
+		log.Println("✅ OPA RBAC data sync started")
+	}
+	if cfg.OPA.RoutePermissionsInterval > 0 {
+		startWorker(func(ctx context.Context) { routePermissions.Run(ctx, opaClient, cfg.OPA.RoutePermissionsInterval) })
+	}
+
+	// OPA coming back gets the roles and role assignments it missed
+	dependencies.Add(resilience.Dependency{
+		Name:     config.DependencyOPA,
+		Critical: slices.Contains(cfg.Startup.CriticalDependencies, config.DependencyOPA),
+		Check:    opaClient.HealthCheck,
+		Recover: func(ctx context.Context) error {
+			if rbacSync == nil {
+				return nil
+			}
+			return rbacSync.SyncAll(ctx)
+		},
+	}, opaErr)
+	if cfg.Startup.CheckInterval > 0 {
+		startWorker(func(ctx context.Context) { dependencies.Run(ctx, cfg.Startup.CheckInterval) })
+	}
+
+	// Temporary role assignments, e.g. on-call elevated access, removed once expired
+	userService.SetDecisionCache(opaEvaluator)
+	userService.SetMaxElevation(cfg.Security.MaxElevation)
+	if cfg.Security.RoleExpiryInterval > 0 {
+		roleExpiry := service.NewRoleExpiry(db, rbacSync, opaEvaluator)
+		singleton("role_expiry", func(ctx context.Context) { roleExpiry.Run(ctx, cfg.Security.RoleExpiryInterval) })
+	}
+
+	// Values of earlier encryption keys, or written unencrypted, rewritten with the primary key
+	if keyring != nil && cfg.Encryption.ReencryptInterval > 0 {
+		reencryptor := service.NewReencryptor(db, keyring)
+		singleton("reencrypt", func(ctx context.Context) { reencryptor.Run(ctx, cfg.Encryption.ReencryptInterval) })
+	}
+
+	// Alert rules of the tenants, evaluated on their audit logs
+	if cfg.Alerts.EvaluationInterval > 0 {
+		pagerDuty := notify.NewPagerDuty(cfg.Alerts.PagerDutyURL, cfg.Webhooks.Timeout)
+		alertEvaluator := service.NewAlertEvaluator(db, webhookDispatcher, &cfg.Webhooks, pagerDuty)
+		singleton("alert_evaluation", func(ctx context.Context) { alertEvaluator.Run(ctx, cfg.Alerts.EvaluationInterval) })
+	}
+
+	// Daily login and registration stats the auth analytics are read from
+	if cfg.Security.AnalyticsInterval > 0 {
+		aggregator := service.NewAuthActivityAggregator(db)
+		singleton("auth_analytics", func(ctx context.Context) { aggregator.Run(ctx, cfg.Security.AnalyticsInterval) })
+	}
+
+	// Expired tokens, soft-deleted records and audit logs past retention, and
+	// orphaned bundle objects, purged periodically
+	var cleaner *service.Cleaner
+	if cfg.Cleanup.Interval > 0 {
+		var cleanupStore storage.ObjectStore
+		if bundleService != nil {
+			cleanupStore = bundleStore
+		}
+		cleaner = service.NewCleaner(db, cleanupStore, &cfg.Cleanup)
+		singleton("cleanup", func(ctx context.Context) { cleaner.Run(ctx, cfg.Cleanup.Interval) })
+	}
+
+	// LDAP connector authenticating directory users and syncing their groups
+	if cfg.LDAP.URL != "" {
+		ldapService := service.NewLDAPService(db, auth.NewLDAPConnector(&cfg.LDAP), &cfg.LDAP)
+		ldapService.SetRBACDataSync(rbacSync)
+		authService.SetLDAPService(ldapService)
+		if cfg.LDAP.SyncInterval > 0 {
+			singleton("ldap_sync", func(ctx context.Context) { ldapService.Run(ctx, cfg.LDAP.SyncInterval) })
+		}
+		log.Println("✅ LDAP connector enabled")
+	}
+
 	// Initialize handlers
 	authHandler := api.NewAuthHandler(authService)
-	userHandler := api.NewUserHandler(userService)
+	userHandler := api.NewUserHandler(userService, loginHistoryService)
 	passwordHandler := api.NewPasswordHandler(passwordService)
 	tenantHandler := api.NewTenantHandler(tenantService)
+	roleHandler := api.NewRoleHandler(roleService)
 	policyHandler := api.NewPolicyHandler(policyService, bundleService)
+	simulationService := service.NewSimulationService(db, opaClient, enrichment, ipAccessService)
+	authzHandler := api.NewAuthzHandler(opaEvaluator, simulationService)
+	signingKeyHandler := api.NewSigningKeyHandler(signingKeyService, jwtService)
+	samlHandler := api.NewSAMLHandler(samlService, authService)
+	claimsTemplateHandler := api.NewClaimsTemplateHandler(claimsTemplateService)
+	rateLimitHandler := api.NewRateLimitHandler(rateLimitService)
+	ipAccessHandler := api.NewIPAccessHandler(ipAccessService)
+	userAttributeHandler := api.NewUserAttributeHandler(userAttributeService)
+	auditHandler := api.NewAuditHandler(adminAuditService, decisionLogService)
+	analyticsHandler := api.NewAnalyticsHandler(analyticsService)
+	overviewHandler := api.NewOverviewHandler(overviewService)
+	bodyLoggingHandler := api.NewBodyLoggingHandler(bodyLoggingService)
+	opaInstanceHandler := api.NewOPAInstanceHandler(opaInstanceService)
+	resourceHandler := api.NewResourceHandler(resourceService)
+	var accessRequestMailer notify.Mailer
+	if cfg.Security.AccessRequestEmail {
+		accessRequestMailer = notify.NewSMTPMailer(&cfg.SMTP)
+	}
+	accessRequestService := service.NewAccessRequestService(db, userService, webhookDispatcher, accessRequestMailer)
+	accessRequestHandler := api.NewAccessRequestHandler(accessRequestService)
+	alertRuleHandler := api.NewAlertRuleHandler(service.NewAlertRuleService(db))
+	actionNonceHandler := api.NewActionNonceHandler(service.NewActionNonceService(db, cfg.Security.ActionNonceTTL))
+	oauthHandler := api.NewOAuthHandler(oauthClientService, deviceService, authService)
+	magicLinkService := service.NewMagicLinkService(db, jwtService, redis, authService, notify.NewSMTPMailer(&cfg.SMTP), &cfg.MagicLink)
+	magicLinkHandler := api.NewMagicLinkHandler(magicLinkService)
+
+	// GitOps policy sync is enabled when a repository and webhook secret are configured
+	var gitSyncHandler *api.GitSyncHandler
+	if cfg.PolicySync.GitRepoURL != "" && cfg.PolicySync.WebhookSecret != "" {
+		gitSyncer, err := service.NewGitPolicySyncer(policyService, &cfg.PolicySync, jobQueue)
+		if err != nil {
+			log.Fatalf("Failed to initialize Git policy sync: %v", err)
+		}
+		gitSyncHandler = api.NewGitSyncHandler(gitSyncer)
+		log.Printf("✅ Git policy sync enabled for branch %s", cfg.PolicySync.GitBranch)
+	}
+
+	// Job workers start once all job types are registered
+	startWorker(jobQueue.Run)
+	jobHandler := api.NewJobHandler(jobQueue)
+
+	// Break-glass access is enabled when the public key of the offline break-glass key is configured
+	var breakGlassHandler *api.BreakGlassHandler
+	if cfg.BreakGlass.PublicKeyPath != "" {
+		breakGlassVerifier, err := auth.NewBreakGlassVerifier(&cfg.BreakGlass)
+		if err != nil {
+			log.Fatalf("Failed to initialize break-glass access: %v", err)
+		}
+		breakGlassPager := events.NewWebhookDispatcher(&config.WebhookConfig{
+			URLs:    append(append([]string{}, cfg.Webhooks.URLs...), cfg.BreakGlass.WebhookURLs...),
+			Secret:  cfg.Webhooks.Secret,
+			Timeout: cfg.Webhooks.Timeout,
+		})
+		breakGlassHandler = api.NewBreakGlassHandler(service.NewBreakGlassService(breakGlassVerifier, adminAuditService, breakGlassPager))
+		log.Printf("✅ Break-glass access enabled (key %s)", breakGlassVerifier.KeyID())
+	}
 	log.Println("✅ Handlers initialized")
 
+	// gRPC API for internal services, on its own port
+	var grpcServer *grpc.Server
+	grpcMetrics := grpcapi.NewMetrics()
+	if cfg.Server.GRPCPort != "" {
+		listener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port: %v", err)
+		}
+		grpcAPI := grpcapi.NewServer(authService, jwtService, opaEvaluator, redis)
+		grpcAPI.SetUserAttributeService(userAttributeService)
+		grpcServer = grpcAPI.Register(grpcMetrics)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+		log.Printf("🔌 gRPC API listening on port %s", cfg.Server.GRPCPort)
+	}
+
 	// Initialize OpenAPI handler
 	openapiHandler := openapi.NewHandler()
 	if err := openapiHandler.Initialize(); err != nil {
@@ -107,31 +588,31 @@ func main() {
 	log.Println("✅ OpenAPI specification generated")
 
 	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
-		AppName: "Heimdall v1.0.0",
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			return c.Status(code).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"message": err.Error(),
-					"code":    "INTERNAL_ERROR",
-				},
-			})
-		},
-	})
+	fiberConfig := fiber.Config{
+		AppName:      "Heimdall v1.0.0",
+		ErrorHandler: middleware.ErrorHandler,
+		// Bodies are read up to the largest route limit, and RequestLimits
+		// rejects those over their route's own
+		BodyLimit: middleware.MaxBodyLimit(&cfg.Server),
+	}
+	middleware.TrustProxies(&fiberConfig, &cfg.Server)
+	app := fiber.New(fiberConfig)
 
 	// Global middleware
 	app.Use(recover.New())
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
-	}))
-	app.Use(middleware.CORS(cfg))
-	app.Use(middleware.RateLimitMiddleware(cfg))
+	app.Use(middleware.RequestLogger(settings))
+	app.Use(middleware.SecurityHeaders(&cfg.Headers))
+	if cfg.Server.Compression {
+		// Bodies are compressed after their ETag is computed and 304s are answered
+		app.Use(compress.New(compress.Config{Level: compress.LevelBestSpeed}))
+	}
+	app.Use(middleware.ETag(cfg.Server.ETagMinSize))
+	app.Use(middleware.BodyLogger(bodyLoggingService, cfg.Server.BodyLogMaxBytes))
+	app.Use(middleware.CORS(settings, corsOriginService))
+	app.Use(middleware.RequestLimits(settings))
+	app.Use(middleware.RateLimitMiddleware(settings))
 	app.Use(middleware.TenantMiddleware())
+	app.Use(middleware.IPAccessMiddleware(ipAccessService))
 
 	// Health check endpoint
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -141,32 +622,143 @@ func main() {
 			"version": "1.0.0",
 		})
 	})
+	var draining atomic.Bool
+	app.Get("/health/ready", api.ReadinessCheck(db, redis, dependencies, &draining))
+	app.Get("/health/opa", func(c *fiber.Ctx) error {
+		breaker := opaClient.BreakerStats()
+		status := "healthy"
+		if breaker.State != resilience.BreakerClosed {
+			status = "degraded"
+		}
+		return c.JSON(fiber.Map{
+			"status":  status,
+			"breaker": breaker,
+			"dedupe":  opaEvaluator.DedupeStats(),
+		})
+	})
+	if fusionAuthClient, ok := identityProvider.(*auth.FusionAuthClient); ok {
+		app.Get("/health/fusionauth", func(c *fiber.Ctx) error {
+			breaker := fusionAuthClient.BreakerStats()
+			status := "healthy"
+			if breaker.State != resilience.BreakerClosed {
+				status = "degraded"
+			}
+			return c.JSON(fiber.Map{
+				"status":  status,
+				"breaker": breaker,
+			})
+		})
+	}
+	if grpcServer != nil {
+		app.Get("/health/grpc", func(c *fiber.Ctx) error {
+			return c.JSON(fiber.Map{
+				"status":  "healthy",
+				"methods": grpcMetrics.Snapshot(),
+			})
+		})
+	}
+	if cleaner != nil {
+		app.Get("/health/cleanup", func(c *fiber.Ctx) error {
+			return c.JSON(fiber.Map{
+				"status": "healthy",
+				"tasks":  cleaner.Snapshot(),
+			})
+		})
+	}
 
-	// Setup API routes
-	api.SetupRoutes(app, authHandler, userHandler, passwordHandler, tenantHandler, policyHandler, jwtService, opaEvaluator)
-	log.Println("✅ Routes configured")
-
-	// Setup OpenAPI/Swagger routes
+	// Setup OpenAPI/Swagger routes ahead of the authenticated /v1 routes
 	openapiHandler.RegisterRoutes(app)
 	log.Println("✅ Swagger UI configured")
 
+	if cfg.Server.AdminUI {
+		adminui.RegisterRoutes(app)
+		log.Println("✅ Admin UI configured")
+	}
+	if cfg.Server.LoginUI {
+		loginui.RegisterRoutes(app, tenantService, cfg.Server.LoginRedirects)
+		log.Println("✅ Login pages configured")
+	}
+
+	// Setup API routes
+	api.SetupRoutes(app, &api.Handlers{
+		Auth:           authHandler,
+		User:           userHandler,
+		Password:       passwordHandler,
+		Tenant:         tenantHandler,
+		Policy:         policyHandler,
+		Role:           roleHandler,
+		Authz:          authzHandler,
+		SigningKey:     signingKeyHandler,
+		SAML:           samlHandler,
+		ClaimsTemplate: claimsTemplateHandler,
+		OAuth:          oauthHandler,
+		RateLimit:      rateLimitHandler,
+		IPAccess:       ipAccessHandler,
+		UserAttribute:  userAttributeHandler,
+		Audit:          auditHandler,
+		Analytics:      analyticsHandler,
+		Overview:       overviewHandler,
+		OPAInstance:    opaInstanceHandler,
+		Resource:       resourceHandler,
+		AccessRequest:  accessRequestHandler,
+		AlertRule:      alertRuleHandler,
+		ActionNonce:    actionNonceHandler,
+		MagicLink:      magicLinkHandler,
+		Job:            jobHandler,
+		BodyLogging:    bodyLoggingHandler,
+		GitSync:        gitSyncHandler,
+		BreakGlass:     breakGlassHandler,
+	}, jwtService, opaEvaluator, routePermissions)
+	log.Println("✅ Routes configured")
+
 	// Get port from configuration
 	port := cfg.Server.Port
 
-	// Graceful shutdown
+	// Graceful shutdown: readiness fails while load balancers stop routing to
+	// this instance, then connections are no longer accepted and in-flight
+	// requests, background workers, webhook deliveries and login records get
+	// the drain timeout to finish before the database is closed
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
 
 		log.Println("\n🛑 Shutting down server...")
-		if err := app.Shutdown(); err != nil {
-			log.Printf("Error during shutdown: %v", err)
+		draining.Store(true)
+		time.Sleep(cfg.Server.ShutdownDelay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.DrainTimeout)
+		defer cancel()
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			log.Printf("⚠️  In-flight requests did not finish in time: %v", err)
+		}
+		if grpcServer != nil {
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				grpcServer.Stop()
+			}
+		}
+		stopWorkers()
+		if err := utils.WaitContext(ctx, &workers); err != nil {
+			log.Printf("⚠️  Background workers did not stop in time: %v", err)
+		}
+		if err := webhookDispatcher.Drain(ctx); err != nil {
+			log.Printf("⚠️  Webhook deliveries did not finish in time: %v", err)
+		}
+		if err := authService.Drain(ctx); err != nil {
+			log.Printf("⚠️  Login records were not written in time: %v", err)
 		}
 		database.Close()
 		database.CloseRedis()
 		log.Println("✅ Server stopped gracefully")
-		os.Exit(0)
 	}()
 
 	// Start server
@@ -174,11 +766,33 @@ func main() {
 	log.Printf("📡 Environment: %s", cfg.Server.Environment)
 	log.Printf("🔗 API endpoint: http://localhost:%s/v1", port)
 	log.Printf("❤️  Health check: http://localhost:%s/health", port)
-	log.Printf("📚 Swagger UI: http://localhost:%s/swagger/", port)
-	log.Printf("📄 OpenAPI spec: http://localhost:%s/swagger/spec", port)
+	log.Printf("📚 Swagger UI: http://localhost:%s/docs/", port)
+	if cfg.Server.AdminUI {
+		log.Printf("🛡️  Admin UI: http://localhost:%s%s/", port, adminui.BasePath)
+	}
+	if cfg.Server.LoginUI {
+		log.Printf("🔑 Login pages: http://localhost:%s%s", port, loginui.BasePath)
+	}
+	log.Printf("📄 OpenAPI spec: http://localhost:%s/v1/openapi.json", port)
 
 	if err := app.Listen(":" + port); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start server: %v\n", err)
 		os.Exit(1)
 	}
+	<-shutdownDone
+}
+
+// validateAPISpec compares the generated OpenAPI spec with the API routes,
+// returning the exit code: 1 when a route is undocumented or vice versa
+func validateAPISpec() int {
+	problems := openapi.CheckRoutes(openapi.NewGenerator().GenerateSpec(), api.Routes())
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "OpenAPI spec does not match the routes (%d problems)\n", len(problems))
+		return 1
+	}
+	fmt.Println("✅ OpenAPI spec matches the routes")
+	return 0
 }
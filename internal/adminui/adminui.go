@@ -0,0 +1,53 @@
+// Package adminui serves the embedded admin web UI, a single page app that
+// signs in with the /v1/auth endpoints and manages tenants, users, roles,
+// policies and bundles through the OPA-protected /v1 API
+package adminui
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BasePath is the path the admin UI is served under
+const BasePath = "/admin"
+
+//go:embed static
+var static embed.FS
+
+// files holds the UI's files relative to its base path
+var files, _ = fs.Sub(static, "static")
+
+// RegisterRoutes serves the admin UI. The UI only calls the public API, so
+// its files are served without authentication.
+func RegisterRoutes(app *fiber.App) {
+	app.Get(BasePath, serve)
+	app.Get(BasePath+"/*", serve)
+}
+
+// serve returns a file of the UI. Other paths without an extension are views
+// of the single page app, which are answered with its index page.
+func serve(c *fiber.Ctx) error {
+	name := strings.Trim(strings.TrimPrefix(c.Path(), BasePath), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	content, err := fs.ReadFile(files, name)
+	if errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "" {
+		name = "index.html"
+		content, err = fs.ReadFile(files, name)
+	}
+	if err != nil {
+		return fiber.ErrNotFound
+	}
+
+	// Files are not fingerprinted, so browsers revalidate them after upgrades
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Type(strings.TrimPrefix(path.Ext(name), "."))
+	return c.Send(content)
+}
@@ -0,0 +1,49 @@
+package adminui
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRegisterRoutes(t *testing.T) {
+	app := fiber.New()
+	RegisterRoutes(app)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"/admin", fiber.StatusOK, "text/html", `<main id="view">`},
+		{"/admin/", fiber.StatusOK, "text/html", `<main id="view">`},
+		{"/admin/tenants", fiber.StatusOK, "text/html", `<main id="view">`},
+		{"/admin/app.js", fiber.StatusOK, "javascript", "async function api("},
+		{"/admin/app.css", fiber.StatusOK, "text/css", ".badge"},
+		{"/admin/missing.js", fiber.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+		if err != nil {
+			t.Fatalf("Request to %s failed: %v", tt.path, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("Expected status %d for %s, got %d", tt.status, tt.path, resp.StatusCode)
+			continue
+		}
+		if tt.status != fiber.StatusOK {
+			continue
+		}
+		if contentType := resp.Header.Get(fiber.HeaderContentType); !strings.Contains(contentType, tt.contentType) {
+			t.Errorf("Expected %s to be served as %s, got %s", tt.path, tt.contentType, contentType)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), tt.contains) {
+			t.Errorf("Expected %s to contain %q", tt.path, tt.contains)
+		}
+	}
+}
@@ -0,0 +1,646 @@
+// Heimdall admin UI: a single page app on top of the /v1 API. It signs in
+// with /v1/auth/login and sends the access token with every request, so the
+// API's OPA policies decide what each administrator can see and change.
+"use strict";
+
+const BASE = "/admin";
+const PAGE_SIZE = 20;
+const session = {
+  get accessToken() { return sessionStorage.getItem("heimdall.accessToken"); },
+  get refreshToken() { return sessionStorage.getItem("heimdall.refreshToken"); },
+  get user() { return JSON.parse(sessionStorage.getItem("heimdall.user") || "null"); },
+  store(auth) {
+    sessionStorage.setItem("heimdall.accessToken", auth.accessToken);
+    sessionStorage.setItem("heimdall.refreshToken", auth.refreshToken);
+    if (auth.user) {
+      sessionStorage.setItem("heimdall.user", JSON.stringify(auth.user));
+    }
+  },
+  clear() {
+    sessionStorage.removeItem("heimdall.accessToken");
+    sessionStorage.removeItem("heimdall.refreshToken");
+    sessionStorage.removeItem("heimdall.user");
+  },
+};
+
+// ApiError is a failed API call, with the code and message of the error response
+class ApiError extends Error {
+  constructor(status, body) {
+    const error = (body && body.error) || {};
+    super(error.message || `Request failed with status ${status}`);
+    this.status = status;
+    this.code = error.code;
+    this.details = error.details;
+  }
+}
+
+// api calls the /v1 API, refreshing the access token once when it expired
+async function api(method, path, body, retry = true, extraHeaders = {}) {
+  const headers = { Accept: "application/json", ...extraHeaders };
+  if (session.accessToken) {
+    headers.Authorization = `Bearer ${session.accessToken}`;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const response = await fetch(`/v1${path}`, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+
+  if (response.status === 401 && retry && session.refreshToken) {
+    if (await refresh()) {
+      return api(method, path, body, false, extraHeaders);
+    }
+  }
+  const payload = response.status === 204 ? null : await response.json().catch(() => null);
+  if (!response.ok) {
+    if (response.status === 401) {
+      session.clear();
+      navigate("/login");
+    }
+    throw new ApiError(response.status, payload);
+  }
+  return payload ? payload.data : null;
+}
+
+// confirmed calls a destructive endpoint with a one-time nonce confirming the
+// action, which the API requires for it. Replays are rejected, so each
+// confirmation fetches its own nonce.
+async function confirmed(nonceAction, resourceId, method, path, body) {
+  const { nonce } = await api("POST", "/action-nonces", { action: nonceAction, resourceId });
+  return api(method, path, body, true, { "X-Action-Nonce": nonce });
+}
+
+async function refresh() {
+  const response = await fetch("/v1/auth/refresh", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ refreshToken: session.refreshToken }),
+  });
+  if (!response.ok) {
+    return false;
+  }
+  session.store((await response.json()).data);
+  return true;
+}
+
+// h creates an element. Text is always set as text, never parsed as HTML.
+function h(tag, attrs = {}, ...children) {
+  const el = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs)) {
+    if (value === undefined || value === null || value === false) {
+      continue;
+    }
+    if (key.startsWith("on")) {
+      el.addEventListener(key.slice(2), value);
+    } else if (key === "class") {
+      el.className = value;
+    } else if (key in el && typeof value !== "string") {
+      el[key] = value;
+    } else {
+      el.setAttribute(key, value === true ? "" : value);
+    }
+  }
+  for (const child of children.flat()) {
+    if (child !== undefined && child !== null && child !== false) {
+      el.append(child instanceof Node ? child : String(child));
+    }
+  }
+  return el;
+}
+
+function badge(status) {
+  return h("span", { class: `badge ${status}` }, status);
+}
+
+function formatTime(value) {
+  return value ? new Date(value).toLocaleString() : "—";
+}
+
+function notify(message, kind = "ok") {
+  const notice = document.getElementById("notice");
+  notice.textContent = message;
+  notice.className = kind;
+  notice.hidden = false;
+  clearTimeout(notify.timer);
+  notify.timer = setTimeout(() => { notice.hidden = true; }, 6000);
+}
+
+// action runs an API call from a button or form, reporting its outcome
+async function action(fn, success) {
+  try {
+    await fn();
+    if (success) {
+      notify(success);
+    }
+    await render();
+  } catch (err) {
+    notify(err.message, "error");
+  }
+}
+
+function formValues(form) {
+  return Object.fromEntries(new FormData(form).entries());
+}
+
+function table(columns, rows, empty = "Nothing here yet") {
+  if (rows.length === 0) {
+    return h("p", { class: "muted" }, empty);
+  }
+  return h("table", {},
+    h("thead", {}, h("tr", {}, columns.map((column) => h("th", {}, column.title)))),
+    h("tbody", {}, rows.map((row) => h("tr", {}, columns.map((column) =>
+      h("td", { class: column.class }, column.render(row)))))));
+}
+
+function pager(page, onPage) {
+  if (!page || page.totalPages <= 1) {
+    return null;
+  }
+  return h("div", { class: "pager" },
+    h("button", { class: "secondary", disabled: page.page <= 1, onclick: () => onPage(page.page - 1) }, "Previous"),
+    `Page ${page.page} of ${page.totalPages} (${page.total} total)`,
+    h("button", { class: "secondary", disabled: page.page >= page.totalPages, onclick: () => onPage(page.page + 1) }, "Next"));
+}
+
+function query(params) {
+  const search = new URLSearchParams();
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== "") {
+      search.set(key, value);
+    }
+  }
+  return search.toString();
+}
+
+// Views, keyed by their path below /admin
+const views = {
+  "/login": loginView,
+  "/": overviewView,
+  "/tenants": tenantsView,
+  "/users": usersView,
+  "/roles": rolesView,
+  "/policies": policiesView,
+  "/bundles": bundlesView,
+};
+
+function loginView() {
+  const form = h("form", {
+    class: "stacked",
+    onsubmit: async (event) => {
+      event.preventDefault();
+      try {
+        const response = await fetch("/v1/auth/login", {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify(formValues(form)),
+        });
+        const payload = await response.json().catch(() => null);
+        if (!response.ok) {
+          throw new ApiError(response.status, payload);
+        }
+        session.store(payload.data);
+        navigate("/");
+      } catch (err) {
+        notify(err.message, "error");
+      }
+    },
+  },
+  h("label", {}, "Email", h("input", { name: "email", type: "email", required: true, autocomplete: "username" })),
+  h("label", {}, "Password", h("input", { name: "password", type: "password", required: true, autocomplete: "current-password" })),
+  h("button", { type: "submit" }, "Sign in"));
+  return [h("h1", {}, "Sign in"), form];
+}
+
+async function overviewView() {
+  const overview = await api("GET", "/admin/overview");
+  const card = (title, value, detail) => h("div", { class: "card" },
+    h("h3", {}, title), h("div", { class: "value" }, value), detail && h("div", { class: "detail" }, detail));
+  const byStatus = (counts) => Object.entries(counts.byStatus || {}).map(([status, n]) => `${n} ${status}`).join(", ");
+  const rates = overview.errorRates;
+  const opa = overview.opa;
+
+  return [
+    h("h1", {}, "Overview"),
+    h("div", { class: "cards" },
+      card("Tenants", overview.tenants.total, byStatus(overview.tenants)),
+      card("Users", overview.users.total, `${overview.users.activeLast24h} active in 24h · ${byStatus(overview.users)}`),
+      card("Active bundles", overview.bundles.active),
+      card("OPA", opa.reachable ? "Reachable" : "Unreachable",
+        opa.error || `${opa.healthy} healthy, ${opa.unhealthy} unhealthy, ${opa.stale} stale of ${opa.instances} instances`),
+      card("Admin action errors", `${(rates.adminActionErrorRate * 100).toFixed(2)}%`, `${rates.adminActionErrors} of ${rates.adminActions} in 24h`),
+      card("Decision errors", `${(rates.decisionErrorRate * 100).toFixed(2)}%`, `${rates.decisionErrors} of ${rates.decisions} in 24h`)),
+    h("h2", {}, "Recently activated bundles"),
+    table([
+      { title: "Bundle", render: (b) => b.name },
+      { title: "Version", render: (b) => b.version },
+      { title: "Revision", render: (b) => b.revision || "—" },
+      { title: "Tenant", render: (b) => b.tenantId },
+      { title: "Activated", render: (b) => formatTime(b.activatedAt) },
+    ], overview.bundles.recent, "No active bundles"),
+    h("h2", {}, "Failed deployments"),
+    table([
+      { title: "Bundle", render: (d) => d.bundleName },
+      { title: "Environment", render: (d) => d.environment || "—" },
+      { title: "Error", render: (d) => d.error || "—" },
+      { title: "When", render: (d) => formatTime(d.deployedAt) },
+    ], overview.failedDeployments, "No failed deployments"),
+  ];
+}
+
+async function tenantsView(params) {
+  const page = Number(params.get("page") || 1);
+  const data = await api("GET", `/tenants?${query({ page, pageSize: PAGE_SIZE })}`);
+  const create = h("form", {
+    onsubmit: (event) => {
+      event.preventDefault();
+      action(() => api("POST", "/tenants", formValues(event.target)), "Tenant created");
+    },
+  },
+  h("label", {}, "Name", h("input", { name: "name", required: true })),
+  h("label", {}, "Slug", h("input", { name: "slug", required: true, pattern: "[a-z0-9-]+" })),
+  h("button", { type: "submit" }, "Create tenant"));
+
+  return [
+    h("h1", {}, "Tenants"),
+    create,
+    table([
+      { title: "Name", render: (t) => t.name },
+      { title: "Slug", render: (t) => t.slug },
+      { title: "Status", render: (t) => badge(t.status) },
+      { title: "Created", render: (t) => formatTime(t.createdAt) },
+      {
+        title: "", class: "actions", render: (t) => t.status === "suspended"
+          ? h("button", { class: "secondary", onclick: () => action(() => api("POST", `/tenants/${t.id}/activate`), `${t.name} activated`) }, "Activate")
+          : h("button", { class: "danger", onclick: () => action(() => api("POST", `/tenants/${t.id}/suspend`), `${t.name} suspended`) }, "Suspend"),
+      },
+    ], data.tenants),
+    pager(data.pagination, (p) => navigate(`/tenants?page=${p}`)),
+  ];
+}
+
+async function usersView(params) {
+  const page = Number(params.get("page") || 1);
+  const search = params.get("query") || "";
+  const data = await api("GET", `/users?${query({ page, pageSize: PAGE_SIZE, query: search })}`);
+  const selected = params.get("user");
+
+  const searchForm = h("form", {
+    onsubmit: (event) => {
+      event.preventDefault();
+      navigate(`/users?${query({ query: formValues(event.target).query })}`);
+    },
+  },
+  h("label", {}, "Search", h("input", { name: "query", value: search, placeholder: "Email or name" })),
+  h("button", { type: "submit" }, "Search"));
+
+  const statusAction = (u) => {
+    if (u.status === "deactivated") {
+      return h("button", { class: "secondary", onclick: () => action(() => api("POST", `/users/${u.id}/restore`), `${u.email} restored`) }, "Restore");
+    }
+    const next = u.status === "suspended" ? "active" : "suspended";
+    return h("button", {
+      class: next === "active" ? "secondary" : "danger",
+      onclick: () => action(() => api("PATCH", `/users/${u.id}/status`, { status: next }), `${u.email} is now ${next}`),
+    }, next === "active" ? "Reactivate" : "Suspend");
+  };
+
+  const view = [
+    h("h1", {}, "Users"),
+    searchForm,
+    table([
+      { title: "Email", render: (u) => u.email },
+      { title: "Roles", render: (u) => (u.roles || []).join(", ") || "—" },
+      { title: "Status", render: (u) => badge(u.status) },
+      { title: "Last login", render: (u) => formatTime(u.lastLoginAt) },
+      {
+        title: "", class: "actions", render: (u) => [
+          h("button", { class: "secondary", onclick: () => navigate(`/users?${query({ query: search, page, user: u.id })}`) }, "Roles"),
+          statusAction(u),
+        ],
+      },
+    ], data.users, "No users found"),
+    pager(data.pagination, (p) => navigate(`/users?${query({ query: search, page: p })}`)),
+  ];
+  if (selected) {
+    view.push(...await userRolesPanel(data.users.find((u) => u.id === selected) || { id: selected, email: selected }));
+  }
+  return view;
+}
+
+async function userRolesPanel(user) {
+  const assignments = await api("GET", `/users/${user.id}/roles`);
+  const assign = h("form", {
+    onsubmit: (event) => {
+      event.preventDefault();
+      const { role } = formValues(event.target);
+      action(async () => {
+        const found = await api("GET", `/roles/${encodeURIComponent(role)}`);
+        await api("POST", `/users/${user.id}/roles`, { roleId: found.id });
+      }, `${role} assigned to ${user.email}`);
+    },
+  },
+  h("label", {}, "Role", h("input", { name: "role", required: true, placeholder: "Role name" })),
+  h("button", { type: "submit" }, "Assign role"));
+
+  return [
+    h("h2", {}, `Roles of ${user.email}`),
+    assign,
+    table([
+      { title: "Role", render: (a) => a.roleName },
+      { title: "Assigned", render: (a) => formatTime(a.assignedAt) },
+      { title: "Expires", render: (a) => (a.expired ? badge("expired") : formatTime(a.expiresAt)) },
+      {
+        title: "", class: "actions", render: (a) => h("button", {
+          class: "danger",
+          onclick: () => action(() => api("DELETE", `/users/${user.id}/roles/${a.roleId}`), `${a.roleName} removed`),
+        }, "Remove"),
+      },
+    ], assignments || [], "No roles assigned"),
+  ];
+}
+
+async function rolesView(params) {
+  const name = params.get("name");
+  const lookup = h("form", {
+    onsubmit: (event) => {
+      event.preventDefault();
+      navigate(`/roles?${query({ name: formValues(event.target).name })}`);
+    },
+  },
+  h("label", {}, "Role name", h("input", { name: "name", required: true, value: name || "" })),
+  h("button", { type: "submit" }, "Open"));
+  const view = [h("h1", {}, "Roles"), lookup];
+  if (!name) {
+    view.push(h("p", { class: "muted" }, "Open a role by name to edit it, or enter a new name to create one."));
+    return view;
+  }
+
+  let role = null;
+  try {
+    role = await api("GET", `/roles/${encodeURIComponent(name)}`);
+  } catch (err) {
+    if (err.status !== 404) {
+      throw err;
+    }
+  }
+
+  const editor = h("form", {
+    class: "stacked",
+    onsubmit: (event) => {
+      event.preventDefault();
+      const values = formValues(event.target);
+      const permissions = values.permissions.split(/[\s,]+/).filter(Boolean);
+      action(() => api("PUT", `/roles/${encodeURIComponent(name)}`, {
+        description: values.description,
+        parentRole: values.parentRole,
+        permissions,
+      }), `${name} saved`);
+    },
+  },
+  h("label", {}, "Description", h("input", { name: "description", value: role ? role.description || "" : "" })),
+  h("label", {}, "Parent role", h("input", { name: "parentRole", value: role ? role.parentRole || "" : "" })),
+  h("label", {}, "Permissions, one per line",
+    h("textarea", { name: "permissions", rows: 8 }, role ? role.permissions.join("\n") : "")),
+  h("button", { type: "submit" }, role ? "Save role" : "Create role"));
+
+  view.push(h("h2", {}, role ? name : `New role ${name}`), editor);
+  if (role && !role.isSystem) {
+    view.push(h("button", {
+      class: "danger",
+      onclick: () => {
+        if (confirm(`Delete role ${name}?`)) {
+          action(async () => {
+            await api("DELETE", `/roles/${encodeURIComponent(name)}`);
+            navigate("/roles");
+          }, `${name} deleted`);
+        }
+      },
+    }, "Delete role"));
+  }
+  return view;
+}
+
+async function policiesView(params) {
+  const id = params.get("id");
+  if (id) {
+    return policyEditor(id === "new" ? null : await api("GET", `/policies/${id}`));
+  }
+  const page = Number(params.get("page") || 1);
+  const data = await api("GET", `/policies?${query({ page, pageSize: PAGE_SIZE })}`);
+  return [
+    h("h1", {}, "Policies"),
+    h("button", { onclick: () => navigate("/policies?id=new") }, "New policy"),
+    h("p"),
+    table([
+      { title: "Name", render: (p) => p.name },
+      { title: "Path", render: (p) => p.path },
+      { title: "Version", render: (p) => p.version },
+      { title: "Status", render: (p) => badge(p.status) },
+      { title: "Valid", render: (p) => badge(p.isValid ? "valid" : "invalid") },
+      {
+        title: "", class: "actions", render: (p) =>
+          h("button", { class: "secondary", onclick: () => navigate(`/policies?id=${p.id}`) }, "Edit"),
+      },
+    ], data.policies),
+    pager(data.pagination, (p) => navigate(`/policies?page=${p}`)),
+  ];
+}
+
+// policyEditor edits a Rego policy. Saving validates the policy with OPA and
+// shows the result, so syntax errors are reported right away.
+function policyEditor(policy) {
+  const content = h("textarea", {
+    class: "rego",
+    name: "content",
+    spellcheck: false,
+    required: true,
+    onkeydown: (event) => {
+      // Tab indents rather than leaving the editor
+      if (event.key === "Tab" && !event.shiftKey) {
+        event.preventDefault();
+        event.target.setRangeText("    ", event.target.selectionStart, event.target.selectionEnd, "end");
+      }
+    },
+  }, policy ? policy.content : "package authz\n\nimport rego.v1\n\ndefault allow := false\n\nallow if {\n    input.user.roles[_] == \"admin\"\n}\n");
+  const validation = h("div");
+  const showValidation = (p) => {
+    validation.replaceChildren(p.isValid
+      ? h("div", { class: "validation valid" }, "Policy is valid")
+      : h("div", { class: "validation invalid" }, p.validationError || "Policy has not been validated"));
+  };
+  if (policy && (policy.isValid || policy.validationError)) {
+    showValidation(policy);
+  }
+
+  const form = h("form", {
+    class: "stacked",
+    style: "max-width: none",
+    onsubmit: async (event) => {
+      event.preventDefault();
+      const values = formValues(form);
+      try {
+        let saved;
+        if (policy) {
+          saved = await api("PUT", `/policies/${policy.id}`, { name: values.name, description: values.description, content: values.content });
+        } else {
+          saved = await api("POST", "/policies", { name: values.name, description: values.description, path: values.path, type: "rego", content: values.content });
+        }
+        await api("POST", `/policies/${saved.id}/validate`);
+        const validated = await api("GET", `/policies/${saved.id}`);
+        notify(`${validated.name} saved`);
+        if (!policy) {
+          navigate(`/policies?id=${saved.id}`);
+          return;
+        }
+        policy = validated;
+        showValidation(validated);
+      } catch (err) {
+        notify(err.message, "error");
+      }
+    },
+  },
+  h("label", {}, "Name", h("input", { name: "name", required: true, minlength: 3, value: policy ? policy.name : "" })),
+  h("label", {}, "Path", h("input", { name: "path", value: policy ? policy.path : "", disabled: Boolean(policy), placeholder: "authz/reports" })),
+  h("label", {}, "Description", h("input", { name: "description", value: policy ? policy.description || "" : "" })),
+  h("label", {}, "Rego", content),
+  h("div", {},
+    h("button", { type: "submit" }, "Save and validate"),
+    " ",
+    policy && h("button", {
+      type: "button",
+      class: "secondary",
+      onclick: () => action(() => api("POST", `/policies/${policy.id}/publish`), `${policy.name} published`),
+    }, "Publish")));
+
+  return [
+    h("h1", {}, policy ? `Policy ${policy.name}` : "New policy"),
+    h("button", { class: "secondary", onclick: () => navigate("/policies") }, "Back to policies"),
+    form,
+    validation,
+  ];
+}
+
+async function bundlesView(params) {
+  const page = Number(params.get("page") || 1);
+  const data = await api("GET", `/bundles?${query({ page, pageSize: PAGE_SIZE })}`);
+  const selected = params.get("id");
+
+  const create = h("form", {
+    onsubmit: (event) => {
+      event.preventDefault();
+      const values = formValues(event.target);
+      action(() => api("POST", "/bundles", {
+        name: values.name,
+        version: values.version,
+        selector: { pathPrefix: values.pathPrefix },
+      }), `Bundle ${values.name} ${values.version} created`);
+    },
+  },
+  h("label", {}, "Name", h("input", { name: "name", required: true })),
+  h("label", {}, "Version", h("input", { name: "version", required: true, placeholder: "1.0.0" })),
+  h("label", {}, "Policies under path", h("input", { name: "pathPrefix", required: true, placeholder: "authz/*" })),
+  h("button", { type: "submit" }, "Build bundle"));
+
+  const view = [
+    h("h1", {}, "Bundles"),
+    create,
+    table([
+      { title: "Name", render: (b) => b.name },
+      { title: "Version", render: (b) => b.version },
+      { title: "Status", render: (b) => badge(b.status) },
+      { title: "Created", render: (b) => formatTime(b.createdAt) },
+      {
+        title: "", class: "actions", render: (b) => [
+          h("button", { class: "secondary", onclick: () => navigate(`/bundles?${query({ page, id: b.id })}`) }, "Deployments"),
+          b.status === "ready" && h("button", {
+            onclick: () => {
+              if (confirm(`Activate ${b.name} ${b.version}? It replaces the active bundle.`)) {
+                action(() => confirmed("bundles.activate", b.id, "POST", `/bundles/${b.id}/activate`), `${b.name} ${b.version} activated`);
+              }
+            },
+          }, "Activate"),
+          (b.status === "ready" || b.status === "active") && h("button", {
+            class: "secondary",
+            onclick: () => action(() => api("POST", `/bundles/${b.id}/deploy`, { environment: "production" }), `${b.name} ${b.version} deployed`),
+          }, "Deploy"),
+        ],
+      },
+    ], data.bundles),
+    pager(data.pagination, (p) => navigate(`/bundles?page=${p}`)),
+  ];
+  if (selected) {
+    const deployments = await api("GET", `/bundles/${selected}/deployments`);
+    view.push(h("h2", {}, "Deployments"), table([
+      { title: "Environment", render: (d) => d.environment || "—" },
+      { title: "Revision", render: (d) => d.revision || "—" },
+      { title: "Status", render: (d) => badge(d.status) },
+      { title: "Error", render: (d) => d.errorMessage || "—" },
+      { title: "Deployed", render: (d) => formatTime(d.deployedAt) },
+    ], deployments || [], "Not deployed yet"));
+  }
+  return view;
+}
+
+// Routing uses real paths below /admin, which the server answers with this page
+
+function currentRoute() {
+  let path = location.pathname.slice(BASE.length) || "/";
+  if (path.length > 1 && path.endsWith("/")) {
+    path = path.slice(0, -1);
+  }
+  return { path, params: new URLSearchParams(location.search) };
+}
+
+function navigate(to) {
+  history.pushState(null, "", BASE + to);
+  render();
+}
+
+async function render() {
+  let { path, params } = currentRoute();
+  if (!session.accessToken && path !== "/login") {
+    history.replaceState(null, "", `${BASE}/login`);
+    path = "/login";
+  }
+
+  const signedIn = Boolean(session.accessToken);
+  document.getElementById("nav").hidden = !signedIn;
+  document.getElementById("session").hidden = !signedIn;
+  document.getElementById("session-user").textContent = signedIn && session.user ? session.user.email : "";
+  for (const link of document.querySelectorAll("#nav a")) {
+    link.classList.toggle("active", link.pathname.replace(/\/$/, "") === (BASE + path).replace(/\/$/, ""));
+  }
+
+  const view = views[path] || (() => [h("h1", {}, "Not found")]);
+  const main = document.getElementById("view");
+  try {
+    main.replaceChildren(...[await view(params)].flat().filter(Boolean));
+  } catch (err) {
+    const message = err.status === 403 ? "You are not allowed to view this page." : err.message;
+    main.replaceChildren(h("h1", {}, "Something went wrong"), h("p", { class: "muted" }, message));
+  }
+}
+
+document.addEventListener("click", (event) => {
+  const link = event.target.closest("a");
+  if (link && link.pathname.startsWith(BASE) && link.origin === location.origin && !event.metaKey && !event.ctrlKey) {
+    event.preventDefault();
+    navigate(link.pathname.slice(BASE.length) + link.search || "/");
+  }
+});
+
+document.getElementById("logout").addEventListener("click", async () => {
+  try {
+    await api("POST", "/auth/logout", { refreshToken: session.refreshToken }, false);
+  } catch (err) {
+    // Signing out locally is enough when the session already ended
+  }
+  session.clear();
+  navigate("/login");
+});
+
+window.addEventListener("popstate", render);
+render();
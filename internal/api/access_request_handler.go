@@ -0,0 +1,190 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// AccessRequestHandler handles just-in-time access requests. Requests belong
+// to the caller's tenant.
+type AccessRequestHandler struct {
+	accessRequestService *service.AccessRequestService
+}
+
+// NewAccessRequestHandler creates a new access request handler
+func NewAccessRequestHandler(accessRequestService *service.AccessRequestService) *AccessRequestHandler {
+	return &AccessRequestHandler{
+		accessRequestService: accessRequestService,
+	}
+}
+
+// CreateAccessRequest requests temporary access to a role for the caller
+// POST /v1/access-requests
+func (h *AccessRequestHandler) CreateAccessRequest(c *fiber.Ctx) error {
+	tenantID, userID, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.CreateAccessRequestRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	request, err := h.accessRequestService.CreateAccessRequest(c.UserContext(), tenantID, userID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "ACCESS_REQUEST_CREATION_FAILED", "Failed to create access request")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// ListAccessRequests retrieves the access requests of the caller's tenant,
+// optionally filtered by requester, role and status
+// GET /v1/access-requests?status=pending&requesterId=...&roleId=...
+func (h *AccessRequestHandler) ListAccessRequests(c *fiber.Ctx) error {
+	tenantID, _, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+	return h.list(c, tenantID, service.AccessRequestFilter{
+		RequesterID: c.Query("requesterId"),
+		RoleID:      c.Query("roleId"),
+	})
+}
+
+// ListMyAccessRequests retrieves the caller's own access requests
+// GET /v1/users/me/access-requests
+func (h *AccessRequestHandler) ListMyAccessRequests(c *fiber.Ctx) error {
+	tenantID, userID, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+	return h.list(c, tenantID, service.AccessRequestFilter{RequesterID: userID.String()})
+}
+
+func (h *AccessRequestHandler) list(c *fiber.Ctx, tenantID uuid.UUID, filter service.AccessRequestFilter) error {
+	params, err := pagination.Parse(c.Query, service.AccessRequestListOptions)
+	if err != nil {
+		return err
+	}
+
+	requests, page, err := h.accessRequestService.ListAccessRequests(c.UserContext(), tenantID, filter, params)
+	if err != nil {
+		return apperrors.Wrap(err, "ACCESS_REQUEST_LIST_FAILED", "Failed to retrieve access requests")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"accessRequests": requests,
+			"pagination":     page,
+		},
+	})
+}
+
+// GetAccessRequest retrieves an access request
+// GET /v1/access-requests/:requestId
+func (h *AccessRequestHandler) GetAccessRequest(c *fiber.Ctx) error {
+	tenantID, _, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := h.accessRequestService.GetAccessRequest(c.UserContext(), tenantID, c.Params("requestId"))
+	if err != nil {
+		return apperrors.Wrap(err, "ACCESS_REQUEST_RETRIEVAL_FAILED", "Failed to retrieve access request")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// ApproveAccessRequest approves a pending request, granting the role for a limited time
+// POST /v1/access-requests/:requestId/approve
+func (h *AccessRequestHandler) ApproveAccessRequest(c *fiber.Ctx) error {
+	tenantID, userID, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	var decision service.AccessRequestDecision
+	if err := bindRequest(c, &decision); err != nil {
+		return err
+	}
+
+	request, err := h.accessRequestService.ApproveAccessRequest(c.UserContext(), tenantID, c.Params("requestId"), userID, &decision)
+	if err != nil {
+		return apperrors.Wrap(err, "ACCESS_REQUEST_APPROVAL_FAILED", "Failed to approve access request")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// DenyAccessRequest denies a pending request
+// POST /v1/access-requests/:requestId/deny
+func (h *AccessRequestHandler) DenyAccessRequest(c *fiber.Ctx) error {
+	tenantID, userID, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	var decision service.AccessRequestDecision
+	if err := bindRequest(c, &decision); err != nil {
+		return err
+	}
+
+	request, err := h.accessRequestService.DenyAccessRequest(c.UserContext(), tenantID, c.Params("requestId"), userID, &decision)
+	if err != nil {
+		return apperrors.Wrap(err, "ACCESS_REQUEST_DENIAL_FAILED", "Failed to deny access request")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// CancelAccessRequest withdraws one of the caller's pending requests
+// POST /v1/access-requests/:requestId/cancel
+func (h *AccessRequestHandler) CancelAccessRequest(c *fiber.Ctx) error {
+	tenantID, userID, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	request, err := h.accessRequestService.CancelAccessRequest(c.UserContext(), tenantID, c.Params("requestId"), userID)
+	if err != nil {
+		return apperrors.Wrap(err, "ACCESS_REQUEST_CANCELLATION_FAILED", "Failed to cancel access request")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// caller returns the caller's tenant and user
+func (h *AccessRequestHandler) caller(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, apperrors.Validation("INVALID_REQUEST", "Tenant ID is required")
+	}
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, apperrors.Validation("INVALID_REQUEST", "User ID is required")
+	}
+	return tenantID, userID, nil
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// ActionNonceHandler handles the endpoint issuing the nonces that confirm
+// destructive actions
+type ActionNonceHandler struct {
+	actionNonceService *service.ActionNonceService
+}
+
+// NewActionNonceHandler creates a new action nonce handler
+func NewActionNonceHandler(actionNonceService *service.ActionNonceService) *ActionNonceHandler {
+	return &ActionNonceHandler{
+		actionNonceService: actionNonceService,
+	}
+}
+
+// IssueNonce issues a one-time nonce confirming an action of the caller on a
+// resource, to be sent in the X-Action-Nonce header of the action's request
+// POST /v1/action-nonces
+func (h *ActionNonceHandler) IssueNonce(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return apperrors.Validation("INVALID_REQUEST", "Tenant ID is required")
+	}
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return apperrors.Validation("INVALID_REQUEST", "User ID is required")
+	}
+
+	var req service.ActionNonceRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	nonce, err := h.actionNonceService.IssueNonce(c.UserContext(), tenantID, userID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "ACTION_NONCE_ISSUE_FAILED", "Failed to issue action nonce")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    nonce,
+	})
+}
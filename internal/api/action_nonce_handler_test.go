@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestActionNonceHandler(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "admin@acme.com")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		handler := NewActionNonceHandler(service.NewActionNonceService(db, time.Minute))
+
+		deleted := 0
+		app := testutil.CreateTestApp()
+		protected := app.Group("/v1").Use(middleware.AuthMiddleware(jwtService))
+		protected.Post("/action-nonces", handler.IssueNonce)
+		protected.Delete("/tenants/:tenantId",
+			middleware.RequireActionNonce(handler.actionNonceService, "tenants.delete", "tenantId"),
+			func(c *fiber.Ctx) error {
+				deleted++
+				return c.JSON(fiber.Map{"success": true})
+			})
+
+		authHeader := testutil.WithAuthHeader(testutil.GenerateTestToken(t, jwtService, user.ID.String(), tenant.ID.String(), user.Email, []string{"admin"}))
+		path := "/v1/tenants/" + tenant.ID.String()
+
+		resp := testutil.MakeRequest(t, app, "DELETE", path, nil, authHeader)
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp.Code)
+
+		resp = testutil.MakeRequest(t, app, "POST", "/v1/action-nonces", map[string]string{"action": "users.read", "resourceId": tenant.ID.String()}, authHeader)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp.Code)
+
+		resp = testutil.MakeRequest(t, app, "POST", "/v1/action-nonces", map[string]string{"action": "tenants.delete", "resourceId": tenant.ID.String()}, authHeader)
+		testutil.AssertStatusCode(t, http.StatusCreated, resp.Code)
+		nonce, _ := testutil.GetDataField(t, testutil.ParseJSONResponse(t, resp))["nonce"].(string)
+
+		withNonce := map[string]string{"Authorization": authHeader["Authorization"], middleware.ActionNonceHeader: nonce}
+		resp = testutil.MakeRequest(t, app, "DELETE", path, nil, withNonce)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		// The confirmed request cannot be replayed
+		resp = testutil.MakeRequest(t, app, "DELETE", path, nil, withNonce)
+		testutil.AssertStatusCode(t, http.StatusConflict, resp.Code)
+		if deleted != 1 {
+			t.Errorf("Expected the action to run once, ran %d times", deleted)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// AlertRuleHandler handles the alert rule endpoints. Rules belong to the
+// caller's tenant.
+type AlertRuleHandler struct {
+	alertRuleService *service.AlertRuleService
+}
+
+// NewAlertRuleHandler creates a new alert rule handler
+func NewAlertRuleHandler(alertRuleService *service.AlertRuleService) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		alertRuleService: alertRuleService,
+	}
+}
+
+// ListRules retrieves the alert rules of the caller's tenant
+// GET /v1/alert-rules
+func (h *AlertRuleHandler) ListRules(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Parse(c.Query, service.AlertRuleListOptions)
+	if err != nil {
+		return err
+	}
+
+	rules, page, err := h.alertRuleService.ListRules(c.UserContext(), tenantID, params)
+	if err != nil {
+		return apperrors.Wrap(err, "ALERT_RULE_LIST_FAILED", "Failed to retrieve alert rules")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"rules":      rules,
+			"pagination": page,
+		},
+	})
+}
+
+// CreateRule creates an alert rule
+// POST /v1/alert-rules
+func (h *AlertRuleHandler) CreateRule(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.AlertRuleRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := h.alertRuleService.CreateRule(c.UserContext(), tenantID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "ALERT_RULE_CREATION_FAILED", "Failed to create alert rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// GetRule retrieves an alert rule
+// GET /v1/alert-rules/:ruleId
+func (h *AlertRuleHandler) GetRule(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	rule, err := h.alertRuleService.GetRule(c.UserContext(), tenantID, c.Params("ruleId"))
+	if err != nil {
+		return apperrors.Wrap(err, "ALERT_RULE_RETRIEVAL_FAILED", "Failed to retrieve alert rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// UpdateRule replaces the condition and settings of an alert rule, keeping
+// the channels omitted from the request
+// PUT /v1/alert-rules/:ruleId
+func (h *AlertRuleHandler) UpdateRule(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.AlertRuleRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := h.alertRuleService.UpdateRule(c.UserContext(), tenantID, c.Params("ruleId"), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "ALERT_RULE_UPDATE_FAILED", "Failed to update alert rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// DeleteRule removes an alert rule and the alerts it fired
+// DELETE /v1/alert-rules/:ruleId
+func (h *AlertRuleHandler) DeleteRule(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.alertRuleService.DeleteRule(c.UserContext(), tenantID, c.Params("ruleId")); err != nil {
+		return apperrors.Wrap(err, "ALERT_RULE_DELETION_FAILED", "Failed to delete alert rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Alert rule deleted successfully",
+	})
+}
+
+// ListAlerts retrieves the alerts an alert rule fired
+// GET /v1/alert-rules/:ruleId/alerts
+func (h *AlertRuleHandler) ListAlerts(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Parse(c.Query, service.AlertListOptions)
+	if err != nil {
+		return err
+	}
+
+	alerts, page, err := h.alertRuleService.ListAlerts(c.UserContext(), tenantID, c.Params("ruleId"), params)
+	if err != nil {
+		return apperrors.Wrap(err, "ALERT_LIST_FAILED", "Failed to retrieve alerts")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"alerts":     alerts,
+			"pagination": page,
+		},
+	})
+}
+
+// tenantID returns the caller's tenant
+func (h *AlertRuleHandler) tenantID(c *fiber.Ctx) (uuid.UUID, error) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return uuid.Nil, apperrors.Validation("INVALID_REQUEST", "Tenant ID is required")
+	}
+	return tenantID, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// AuditHandler handles audit log endpoints
+type AuditHandler struct {
+	adminAuditService  *service.AdminAuditService
+	decisionLogService *service.DecisionLogService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(adminAuditService *service.AdminAuditService, decisionLogService *service.DecisionLogService) *AuditHandler {
+	return &AuditHandler{
+		adminAuditService:  adminAuditService,
+		decisionLogService: decisionLogService,
+	}
+}
+
+// ListAdminActions retrieves the sensitive administrative actions of the
+// caller's tenant, optionally filtered by action and acting user
+// GET /v1/audit/admin-actions?action=roles.assign&userId=...
+func (h *AuditHandler) ListAdminActions(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant ID is required",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	params, err := pagination.Parse(c.Query, service.AdminActionListOptions)
+	if err != nil {
+		return err
+	}
+
+	filter := service.AdminActionFilter{
+		Action: c.Query("action"),
+		UserID: c.Query("userId"),
+	}
+	actions, page, err := h.adminAuditService.ListAdminActions(c.UserContext(), tenantID, filter, params)
+	if err != nil {
+		return apperrors.Wrap(err, "AUDIT_LIST_FAILED", "Failed to retrieve admin actions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"actions":    actions,
+			"pagination": page,
+		},
+	})
+}
+
+// IngestDecisionLogs stores the decision logs uploaded by an OPA instance, as
+// OPA's decision log plugin sends them to a service at its /logs resource
+// POST /v1/logs
+func (h *AuditHandler) IngestDecisionLogs(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	source := &service.DecisionLogSource{
+		TenantID:  tenantUUID,
+		ClientID:  middleware.GetClientID(c),
+		UserID:    middleware.GetUserID(c),
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+	gzipped := strings.EqualFold(c.Get(fiber.HeaderContentEncoding), "gzip")
+	accepted, err := h.decisionLogService.IngestDecisionLogs(c.UserContext(), source, c.Body(), gzipped)
+	if err != nil {
+		return apperrors.Wrap(err, "DECISION_LOG_INGEST_FAILED", "Failed to store decision logs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"accepted": accepted,
+		},
+	})
+}
+
+// ListDecisions retrieves the decisions reported by the caller's tenant's OPA
+// instances, optionally filtered by instance and decision ID
+// GET /v1/audit/decisions?instanceId=...&decisionId=...
+func (h *AuditHandler) ListDecisions(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant ID is required",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	params, err := pagination.Parse(c.Query, service.DecisionLogListOptions)
+	if err != nil {
+		return err
+	}
+
+	filter := service.DecisionLogFilter{
+		InstanceID: c.Query("instanceId"),
+		DecisionID: c.Query("decisionId"),
+	}
+	decisions, page, err := h.decisionLogService.ListDecisions(c.UserContext(), tenantUUID, filter, params)
+	if err != nil {
+		return apperrors.Wrap(err, "AUDIT_LIST_FAILED", "Failed to retrieve decisions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"decisions":  decisions,
+			"pagination": page,
+		},
+	})
+}
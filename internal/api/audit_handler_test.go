@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestAuditHandler_AdminActions(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		admin := testutil.CreateTestUser(t, db, tenant, "admin@example.com")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		outsider := testutil.CreateTestUser(t, db, other, "admin@globex.com")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		handler := NewAuditHandler(service.NewAdminAuditService(db), service.NewDecisionLogService(db))
+
+		app := testutil.CreateTestApp()
+		protected := app.Group("/v1").Use(middleware.AuthMiddleware(jwtService))
+		protected.Post("/users/:userId/roles",
+			middleware.AuditAdminAction(handler.adminAuditService, "roles.assign", "users", "userId"),
+			func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"success": true, "data": fiber.Map{"roleId": "r1", "apiKey": "k-123"}})
+			})
+		protected.Post("/tenants/:tenantId/suspend",
+			middleware.AuditAdminAction(handler.adminAuditService, "tenants.suspend", "tenants", "tenantId"),
+			func(c *fiber.Ctx) error {
+				return apperrors.New(apperrors.ErrForbidden, "FORBIDDEN", "Insufficient permissions")
+			})
+		protected.Get("/audit/admin-actions", handler.ListAdminActions)
+
+		auth := testutil.WithAuthHeader(testutil.GenerateTestToken(t, jwtService, admin.ID.String(), tenant.ID.String(), admin.Email, []string{"admin"}))
+		resp := testutil.MakeRequest(t, app, "POST", "/v1/users/"+admin.ID.String()+"/roles",
+			map[string]interface{}{"roleId": "r1", "password": "hunter2", "nested": map[string]interface{}{"clientSecret": "s3cret"}}, auth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		// Failed actions are recorded with the status the error handler renders
+		resp = testutil.MakeRequest(t, app, "POST", "/v1/tenants/"+other.ID.String()+"/suspend", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "FORBIDDEN")
+
+		outsiderAuth := testutil.WithAuthHeader(testutil.GenerateTestToken(t, jwtService, outsider.ID.String(), other.ID.String(), outsider.Email, []string{"admin"}))
+		resp = testutil.MakeRequest(t, app, "POST", "/v1/users/"+outsider.ID.String()+"/roles", map[string]interface{}{"roleId": "r2"}, outsiderAuth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		// Tenants only see their own users' actions
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/audit/admin-actions?sort=createdAt", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		actions, _ := testutil.GetDataField(t, testutil.ParseJSONResponse(t, resp))["actions"].([]interface{})
+		if len(actions) != 2 {
+			t.Fatalf("Expected 2 admin actions, got %d", len(actions))
+		}
+
+		assigned, _ := actions[0].(map[string]interface{})
+		if assigned["action"] != "roles.assign" || assigned["status"] != "success" || assigned["userId"] != admin.ID.String() ||
+			assigned["resourceId"] != admin.ID.String() || assigned["method"] != "POST" {
+			t.Errorf("Unexpected role assignment record: %v", assigned)
+		}
+		details, _ := assigned["details"].(map[string]interface{})
+		request, _ := details["request"].(map[string]interface{})
+		nested, _ := request["nested"].(map[string]interface{})
+		if request["roleId"] != "r1" || request["password"] != "[REDACTED]" || nested["clientSecret"] != "[REDACTED]" {
+			t.Errorf("Expected a redacted request payload, got %v", request)
+		}
+		response, _ := details["response"].(map[string]interface{})
+		data, _ := response["data"].(map[string]interface{})
+		if data["roleId"] != "r1" || data["apiKey"] != "[REDACTED]" {
+			t.Errorf("Expected a redacted response payload, got %v", response)
+		}
+
+		suspended, _ := actions[1].(map[string]interface{})
+		if suspended["action"] != "tenants.suspend" || suspended["status"] != "failure" ||
+			suspended["statusCode"] != float64(http.StatusForbidden) || suspended["message"] != "FORBIDDEN" {
+			t.Errorf("Unexpected suspension record: %v", suspended)
+		}
+
+		// Filters
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/audit/admin-actions?action=tenants.suspend", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		actions, _ = testutil.GetDataField(t, testutil.ParseJSONResponse(t, resp))["actions"].([]interface{})
+		if len(actions) != 1 {
+			t.Errorf("Expected 1 suspension, got %d", len(actions))
+		}
+
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/audit/admin-actions?userId=not-a-uuid", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "INVALID_USER_ID")
+	})
+}
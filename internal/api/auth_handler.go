@@ -1,12 +1,21 @@
 package api
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/middleware"
 	"github.com/techsavvyash/heimdall/internal/service"
-	"github.com/techsavvyash/heimdall/internal/utils"
 )
 
+// DeviceIDHeader optionally identifies the device a client runs on. Refresh
+// tokens are bound to it together with the user agent.
+const DeviceIDHeader = "X-Device-ID"
+
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
 	authService *service.AuthService
@@ -23,38 +32,22 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // POST /v1/auth/register
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req service.RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validate request
-	if err := utils.ValidateStruct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Validation failed",
-				"code":    "VALIDATION_ERROR",
-				"details": err,
-			},
-		})
-	}
+	req.IPAddress = c.IP()
+	req.UserAgent = c.Get(fiber.HeaderUserAgent)
+	req.DeviceID = c.Get(DeviceIDHeader)
 
 	// Register user
-	result, err := h.authService.Register(c.Context(), &req)
+	result, err := h.authService.Register(c.UserContext(), &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "REGISTRATION_FAILED",
-			},
-		})
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			return rejectedResponse(c, rejected, "REGISTRATION_REJECTED", "Registration rejected")
+		}
+		return apperrors.Wrap(err, "REGISTRATION_FAILED", "Registration failed")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -67,31 +60,48 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 // POST /v1/auth/login
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req service.LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validate request
-	if err := utils.ValidateStruct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Validation failed",
-				"code":    "VALIDATION_ERROR",
-				"details": err,
-			},
-		})
-	}
+	req.IPAddress = c.IP()
+	req.UserAgent = c.Get(fiber.HeaderUserAgent)
+	req.DeviceID = c.Get(DeviceIDHeader)
 
 	// Authenticate user
-	result, err := h.authService.Login(c.Context(), &req)
+	result, err := h.authService.Login(c.UserContext(), &req)
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			retryAfter := int64(math.Ceil(throttled.RetryAfter.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+
+			code := "TOO_MANY_LOGIN_ATTEMPTS"
+			message := "Too many login attempts, please try again later"
+			if throttled.Locked {
+				code = "ACCOUNT_LOCKED"
+				message = "Account temporarily locked due to repeated failed logins"
+			}
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message":    message,
+					"code":       code,
+					"retryAfter": retryAfter,
+				},
+			})
+		}
+
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			return rejectedResponse(c, rejected, "LOGIN_REJECTED", "Login rejected")
+		}
+
+		// A locked account or an unreachable identity provider keeps its own status
+		if errors.Is(err, apperrors.ErrForbidden) || errors.Is(err, apperrors.ErrUnavailable) {
+			return err
+		}
+
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
@@ -107,33 +117,54 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	})
 }
 
-// RefreshToken generates a new access token
-// POST /v1/auth/refresh
-func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
-	var req struct {
-		RefreshToken string `json:"refreshToken" validate:"required"`
-	}
-
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+// rejectedResponse responds to an operation rejected by a login hook, with
+// 401 STEP_UP_REQUIRED when the hook requires step-up verification
+func rejectedResponse(c *fiber.Ctx, rejected *service.LoginRejectedError, code, message string) error {
+	if rejected.StepUp {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
+				"message": "Additional verification required",
+				"code":    "STEP_UP_REQUIRED",
+				"details": fiber.Map{
+					"reason":  rejected.Reason,
+					"methods": rejected.Methods,
+				},
 			},
 		})
 	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": message,
+			"code":    code,
+			"details": fiber.Map{
+				"reason": rejected.Reason,
+			},
+		},
+	})
+}
+
+// RefreshToken generates a new access token
+// POST /v1/auth/refresh
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	var req service.RefreshTokenRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	req.IPAddress = c.IP()
+	req.UserAgent = c.Get(fiber.HeaderUserAgent)
+	req.DeviceID = c.Get(DeviceIDHeader)
 
 	// Refresh token
-	result, err := h.authService.RefreshToken(c.Context(), req.RefreshToken)
+	result, err := h.authService.RefreshToken(c.UserContext(), &req)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid or expired refresh token",
-				"code":    "INVALID_REFRESH_TOKEN",
-			},
-		})
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			return rejectedResponse(c, rejected, "LOGIN_REJECTED", "Login rejected")
+		}
+		return apperrors.Wrap(err, "INVALID_REFRESH_TOKEN", "Invalid or expired refresh token")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -142,20 +173,38 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	})
 }
 
+// ExchangeToken exchanges the caller's access token for a short-lived token
+// limited to a subset of its permissions, for handing to another service
+// POST /v1/auth/token/exchange
+func (h *AuthHandler) ExchangeToken(c *fiber.Ctx) error {
+	var req service.TokenExchangeRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	// The subject token is the bearer token, already validated by the auth middleware
+	subjectToken, _ := auth.ExtractTokenFromHeader(c.Get(fiber.HeaderAuthorization))
+
+	result, err := h.authService.ExchangeToken(c.UserContext(), subjectToken, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "TOKEN_EXCHANGE_FAILED", "Token exchange failed")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
 // Logout revokes user's current session
 // POST /v1/auth/logout
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	tokenID := middleware.GetTokenID(c)
 
-	if err := h.authService.Logout(c.Context(), userID, tokenID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Logout failed",
-				"code":    "LOGOUT_FAILED",
-			},
-		})
+	if err := h.authService.Logout(c.UserContext(), userID, tokenID); err != nil {
+		return apperrors.Wrap(err, "LOGOUT_FAILED", "Logout failed")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -169,19 +218,36 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 
-	if err := h.authService.LogoutEverywhere(c.Context(), userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	if err := h.authService.LogoutEverywhere(c.UserContext(), userID); err != nil {
+		return apperrors.Wrap(err, "LOGOUT_FAILED", "Logout failed")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "All sessions revoked successfully",
+	})
+}
+
+// UnlockAccount clears a user's login lockout (admin only)
+// POST /v1/users/:userId/unlock
+func (h *AuthHandler) UnlockAccount(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Logout failed",
-				"code":    "LOGOUT_FAILED",
+				"message": "User ID is required",
+				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
+	if err := h.authService.UnlockAccount(c.UserContext(), userID); err != nil {
+		return apperrors.Wrap(err, "UNLOCK_FAILED", "Failed to unlock account")
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "All sessions revoked successfully",
+		"message": "Account unlocked successfully",
 	})
 }
-
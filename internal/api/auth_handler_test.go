@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestAuthHandler_ExchangeToken_Audience(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		claimsTemplates := service.NewClaimsTemplateService(db)
+		jwtService.SetClaimsTemplates(claimsTemplates)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		billing := testutil.CreateTestRole(t, db, tenant, "billing")
+		testutil.AssignPermissionToRole(t, db, billing, testutil.CreateTestPermission(t, db, "invoices.read", "invoices", "read"))
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		testutil.AssignRoleToUser(t, db, alice, billing)
+		if _, _, err := claimsTemplates.UpsertTemplate(ctx, tenant.ID, &service.UpsertClaimsTemplateRequest{Audiences: []string{"billing-service"}}, service.Precondition{}); err != nil {
+			t.Fatalf("UpsertTemplate returned error: %v", err)
+		}
+
+		app := testutil.CreateTestApp()
+		protected := app.Group("/v1").Use(middleware.AuthMiddleware(jwtService))
+		protected.Post("/auth/token/exchange", NewAuthHandler(service.NewAuthService(db, nil, jwtService, nil, nil, nil)).ExchangeToken)
+		protected.Get("/users/me", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+		tokens, err := jwtService.GenerateTokenPair(alice.ID.String(), tenant.ID.String(), alice.Email, []string{"billing"})
+		if err != nil {
+			t.Fatalf("Failed to generate token pair: %v", err)
+		}
+		resp := testutil.MakeRequest(t, app, "POST", "/v1/auth/token/exchange",
+			map[string]string{"scope": "invoices.read", "audience": "billing-service"}, testutil.WithAuthHeader(tokens.AccessToken))
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		data, _ := testutil.ParseJSONResponse(t, resp)["data"].(map[string]interface{})
+		serviceToken, _ := data["accessToken"].(string)
+		if serviceToken == "" {
+			t.Fatalf("Expected an exchanged token, got %v", data)
+		}
+
+		// A token meant for a tenant's service only is not accepted by Heimdall,
+		// neither by its API nor for another exchange
+		for _, request := range []struct{ method, path string }{{"GET", "/v1/users/me"}, {"POST", "/v1/auth/token/exchange"}} {
+			resp = testutil.MakeRequest(t, app, request.method, request.path, map[string]string{"scope": "invoices.read"}, testutil.WithAuthHeader(serviceToken))
+			testutil.AssertStatusCode(t, http.StatusUnauthorized, resp.Code)
+			testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "INVALID_TOKEN")
+		}
+	})
+}
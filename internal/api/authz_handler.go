@@ -0,0 +1,318 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// AuthzHandler handles authorization decision endpoints
+type AuthzHandler struct {
+	evaluator         *opa.Evaluator
+	simulationService *service.SimulationService
+}
+
+// NewAuthzHandler creates a new authorization handler
+func NewAuthzHandler(evaluator *opa.Evaluator, simulationService *service.SimulationService) *AuthzHandler {
+	return &AuthzHandler{
+		evaluator:         evaluator,
+		simulationService: simulationService,
+	}
+}
+
+// AuthzCheckRequest represents an authorization check request
+type AuthzCheckRequest struct {
+	Resource opa.ResourceContext    `json:"resource"`
+	Action   string                 `json:"action" validate:"required"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+}
+
+// Check evaluates an authorization decision for the authenticated user
+// POST /v1/authz/check
+//
+// Clients may send a Cache-Control header to trade freshness for latency:
+// "max-stale=<seconds>" accepts cached decisions past their freshness lifetime,
+// "max-age=<seconds>" bounds the age of a cached decision, "no-cache" forces a
+// fresh evaluation and "no-store" prevents the result from being cached.
+func (h *AuthzHandler) Check(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" && middleware.GetClientID(c) == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req AuthzCheckRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if req.Resource.Type == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Resource type is required",
+				"code":    "VALIDATION_ERROR",
+				"details": fiber.Map{"resource.type": "This field is required"},
+			},
+		})
+	}
+
+	// Exchanged tokens are denied anything outside their scope without asking OPA
+	if !middleware.ScopeAllows(c, req.Resource.Type, req.Action) {
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data": fiber.Map{
+				"decision": false,
+				"allow":    false,
+				"reason":   "out_of_scope",
+			},
+		})
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	if req.Resource.TenantID == "" {
+		req.Resource.TenantID = tenantID
+	}
+
+	// Client tokens have no roles for OPA to evaluate, their scope is the decision
+	if middleware.GetClientID(c) != "" {
+		allowed := middleware.ClientAllows(c, req.Resource.Type, req.Action, req.Resource.TenantID)
+		reason := "out_of_scope"
+		if allowed {
+			reason = "client_scope"
+		}
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data": fiber.Map{
+				"decision": allowed,
+				"allow":    allowed,
+				"reason":   reason,
+			},
+		})
+	}
+
+	input, err := h.buildInput(c, &req)
+	if err != nil {
+		return err
+	}
+
+	hints := parseCacheHints(c.Get(fiber.HeaderCacheControl), h.evaluator.MaxStale())
+
+	decision, err := h.evaluator.EvaluateWithCacheHints(c.UserContext(), userID, input, hints)
+	if err != nil {
+		return apperrors.Wrap(err, "AUTHZ_EVALUATION_FAILED", "Authorization evaluation failed")
+	}
+
+	ageSeconds := int64(decision.Age / time.Second)
+	if decision.CacheStatus == opa.CacheStatusHit || decision.CacheStatus == opa.CacheStatusStale {
+		c.Set(fiber.HeaderAge, strconv.FormatInt(ageSeconds, 10))
+	}
+	c.Set("X-Decision-Cache", decision.CacheStatus)
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+
+	reason := decision.Reason
+	if reason == "" {
+		reason = "access_denied"
+		if decision.Allow {
+			reason = "access_granted"
+		}
+	}
+
+	data := fiber.Map{
+		"decision": decision.Allow,
+		"allow":    decision.Allow,
+		"reason":   reason,
+		"metadata": fiber.Map{
+			"decisionId": decision.DecisionID,
+			"cache": fiber.Map{
+				"status":   decision.CacheStatus,
+				"age":      ageSeconds,
+				"maxStale": int64(decision.MaxStale / time.Second),
+			},
+		},
+	}
+	// Clients enforcing the decision must fulfil its obligations
+	if len(decision.Obligations) > 0 {
+		data["obligations"] = decision.Obligations
+	}
+	if len(decision.Advice) > 0 {
+		data["advice"] = decision.Advice
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// AuthzExplainRequest represents a request to explain an authorization decision
+type AuthzExplainRequest struct {
+	Resource opa.ResourceContext    `json:"resource"`
+	Action   string                 `json:"action" validate:"required"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+	Trace    bool                   `json:"trace,omitempty"` // Include OPA's raw evaluation trace
+}
+
+// Explain evaluates an authorization decision for the authenticated user with
+// OPA's explanation enabled and returns the rules that fired, the inputs they
+// read and why the request was denied. Decisions are never cached.
+// POST /v1/authz/explain
+func (h *AuthzHandler) Explain(c *fiber.Ctx) error {
+	if middleware.GetUserID(c) == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req AuthzExplainRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+	if req.Resource.Type == "" {
+		return apperrors.Validation("VALIDATION_ERROR", "Resource type is required").
+			WithDetails(map[string]interface{}{"resource.type": "This field is required"})
+	}
+	if req.Resource.TenantID == "" {
+		req.Resource.TenantID = middleware.GetTenantID(c)
+	}
+
+	input, err := h.buildInput(c, &AuthzCheckRequest{Resource: req.Resource, Action: req.Action, Context: req.Context})
+	if err != nil {
+		return err
+	}
+
+	explanation, err := h.evaluator.Explain(c.UserContext(), input, req.Trace)
+	if err != nil {
+		return apperrors.Wrap(err, "AUTHZ_EVALUATION_FAILED", "Authorization evaluation failed")
+	}
+
+	// Exchanged tokens are denied anything outside their scope before OPA is asked
+	if !middleware.ScopeAllows(c, req.Resource.Type, req.Action) {
+		explanation.Allow = false
+		explanation.Reason = "out_of_scope"
+		explanation.Denials = append([]string{"out_of_scope: the token's scope does not include this resource and action"}, explanation.Denials...)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    explanation,
+	})
+}
+
+// Simulate evaluates a what-if request of a user, or of a set of roles, of the
+// caller's tenant against the active policies and optionally a draft bundle,
+// without the user's credentials
+// POST /v1/authz/simulate
+func (h *AuthzHandler) Simulate(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return apperrors.Validation("INVALID_REQUEST", "Tenant ID is required")
+	}
+
+	var req service.SimulateRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	simulation, err := h.simulationService.Simulate(c.UserContext(), tenantID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "AUTHZ_SIMULATION_FAILED", "Authorization simulation failed")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    simulation,
+	})
+}
+
+// buildInput builds the authorization input of a check. The subject always
+// comes from the token; the request only describes the resource.
+func (h *AuthzHandler) buildInput(c *fiber.Ctx, req *AuthzCheckRequest) (map[string]interface{}, error) {
+	builder := opa.NewContextBuilderFromFiber(c)
+	builder.WithResource(req.Resource.Type, req.Resource.ID)
+	builder.WithResourceOwner(req.Resource.OwnerID)
+	builder.WithResourceTenant(req.Resource.TenantID)
+	builder.WithResourceAttributes(req.Resource.Attributes)
+	builder.WithAction(req.Action)
+	if err := builder.Enrich(c.UserContext(), h.evaluator.Enrichment()); err != nil {
+		return nil, apperrors.Wrap(err, "AUTHZ_EVALUATION_FAILED", "Authorization evaluation failed")
+	}
+	input := builder.Build()
+
+	// Caller-supplied context may add attributes but never override request-derived ones
+	if contextMap, ok := input["context"].(map[string]interface{}); ok {
+		for key, value := range req.Context {
+			if _, exists := contextMap[key]; !exists {
+				contextMap[key] = value
+			}
+		}
+	}
+	return input, nil
+}
+
+// parseCacheHints parses Cache-Control request directives into evaluator cache hints.
+// A bare "max-stale" directive accepts any staleness up to the server-side limit.
+func parseCacheHints(header string, maxStaleLimit time.Duration) opa.CacheHints {
+	var hints opa.CacheHints
+
+	for _, directive := range strings.Split(header, ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(directive), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch name {
+		case "no-cache":
+			hints.NoCache = true
+		case "no-store":
+			hints.NoStore = true
+		case "max-age":
+			if seconds, err := parseDeltaSeconds(value); err == nil {
+				maxAge := seconds
+				hints.MaxAge = &maxAge
+			}
+		case "max-stale":
+			if !hasValue {
+				hints.MaxStale = maxStaleLimit
+				continue
+			}
+			if seconds, err := parseDeltaSeconds(value); err == nil {
+				hints.MaxStale = seconds
+			}
+		}
+	}
+
+	return hints
+}
+
+// parseDeltaSeconds parses a non-negative delta-seconds value
+func parseDeltaSeconds(value string) (time.Duration, error) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("negative delta-seconds: %d", seconds)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCacheHints(t *testing.T) {
+	limit := 5 * time.Minute
+
+	hints := parseCacheHints("max-stale=30, no-store", limit)
+	if hints.MaxStale != 30*time.Second {
+		t.Errorf("Expected max-stale 30s, got %v", hints.MaxStale)
+	}
+	if !hints.NoStore {
+		t.Error("Expected no-store to be set")
+	}
+	if hints.NoCache {
+		t.Error("Expected no-cache to be unset")
+	}
+
+	hints = parseCacheHints("max-stale", limit)
+	if hints.MaxStale != limit {
+		t.Errorf("Expected bare max-stale to use limit %v, got %v", limit, hints.MaxStale)
+	}
+
+	hints = parseCacheHints("No-Cache, max-age=\"10\"", limit)
+	if !hints.NoCache {
+		t.Error("Expected no-cache to be set")
+	}
+	if hints.MaxAge == nil || *hints.MaxAge != 10*time.Second {
+		t.Errorf("Expected max-age 10s, got %v", hints.MaxAge)
+	}
+
+	hints = parseCacheHints("max-stale=-1, max-age=abc", limit)
+	if hints.MaxStale != 0 {
+		t.Errorf("Expected invalid max-stale to be ignored, got %v", hints.MaxStale)
+	}
+	if hints.MaxAge != nil {
+		t.Errorf("Expected invalid max-age to be ignored, got %v", *hints.MaxAge)
+	}
+}
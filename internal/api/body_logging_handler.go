@@ -0,0 +1,105 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// BodyLoggingHandler handles the body logging endpoints, which select the
+// routes whose redacted request and response bodies are logged for debugging
+type BodyLoggingHandler struct {
+	bodyLoggingService *service.BodyLoggingService
+}
+
+// NewBodyLoggingHandler creates a new body logging handler
+func NewBodyLoggingHandler(bodyLoggingService *service.BodyLoggingService) *BodyLoggingHandler {
+	return &BodyLoggingHandler{
+		bodyLoggingService: bodyLoggingService,
+	}
+}
+
+// ListRules retrieves the body logging rules that have not expired
+// GET /v1/admin/body-logging
+func (h *BodyLoggingHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.bodyLoggingService.ListRules(c.UserContext())
+	if err != nil {
+		return apperrors.Wrap(err, "BODY_LOGGING_LIST_FAILED", "Failed to retrieve body logging rules")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// EnableRule logs the bodies of a route for a limited time. The route may be
+// given as documented, e.g. /v1/users/{userId}, or as registered, e.g.
+// /v1/users/:userId, and must exist.
+// POST /v1/admin/body-logging
+func (h *BodyLoggingHandler) EnableRule(c *fiber.Ctx) error {
+	var req service.EnableBodyLoggingRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	route, ok := registeredRoute(c.App(), req.Method, req.Path)
+	if !ok {
+		return apperrors.Validation("UNKNOWN_ROUTE", "No route is registered for "+strings.TrimSpace(req.Method+" "+req.Path))
+	}
+	req.Path = route
+
+	rule, err := h.bodyLoggingService.EnableRule(c.UserContext(), middleware.GetUserID(c), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "BODY_LOGGING_ENABLE_FAILED", "Failed to enable body logging")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// DisableRule deletes a body logging rule
+// DELETE /v1/admin/body-logging/:ruleId
+func (h *BodyLoggingHandler) DisableRule(c *fiber.Ctx) error {
+	if err := h.bodyLoggingService.DisableRule(c.UserContext(), c.Params("ruleId")); err != nil {
+		return apperrors.Wrap(err, "BODY_LOGGING_DISABLE_FAILED", "Failed to disable body logging")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Body logging disabled successfully",
+	})
+}
+
+// registeredRoute returns the registered path of the route serving method and
+// path, treating {param}, :param and * segments alike. An empty method matches
+// a route of any method.
+func registeredRoute(app *fiber.App, method, path string) (string, bool) {
+	want := routePattern(path)
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || (method != "" && route.Method != method) {
+			continue
+		}
+		if routePattern(route.Path) == want {
+			return route.Path, true
+		}
+	}
+	return "", false
+}
+
+// routePattern replaces the parameters of a route path and drops its trailing
+// slash
+func routePattern(path string) string {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{") || segment == "*" {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// BreakGlassHandler handles break-glass emergency access
+type BreakGlassHandler struct {
+	breakGlassService *service.BreakGlassService
+}
+
+// NewBreakGlassHandler creates a new break-glass handler
+func NewBreakGlassHandler(breakGlassService *service.BreakGlassService) *BreakGlassHandler {
+	return &BreakGlassHandler{
+		breakGlassService: breakGlassService,
+	}
+}
+
+// GetSession describes the break-glass token the request was made with, so
+// operators can check a token before relying on it
+// GET /v1/break-glass/session
+func (h *BreakGlassHandler) GetSession(c *fiber.Ctx) error {
+	claims := middleware.GetBreakGlass(c)
+	if claims == nil {
+		return apperrors.NotFound("BREAK_GLASS_NOT_ACTIVE", "Request was not made with a break-glass token")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    h.breakGlassService.Session(claims),
+	})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// recordingPager keeps the events published
+type recordingPager struct {
+	events []events.Event
+}
+
+func (p *recordingPager) Publish(ctx context.Context, event events.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestBreakGlassHandler(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		emergency := testutil.CreateTestUser(t, db, tenant, "breakglass@acme.com")
+		user := testutil.CreateTestUser(t, db, tenant, "user@acme.com")
+
+		privateKeyPEM, publicKeyPEM, err := auth.GenerateRSAKeyPEM(2048)
+		if err != nil {
+			t.Fatalf("Failed to generate break-glass key: %v", err)
+		}
+		publicKeyPath := filepath.Join(t.TempDir(), "break_glass_public.pem")
+		if err := os.WriteFile(publicKeyPath, []byte(publicKeyPEM), 0o644); err != nil {
+			t.Fatalf("Failed to write break-glass public key: %v", err)
+		}
+		privateKey, _ := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+		verifier, err := auth.NewBreakGlassVerifier(&config.BreakGlassConfig{PublicKeyPath: publicKeyPath, MaxTTL: time.Hour})
+		if err != nil {
+			t.Fatalf("Failed to create break-glass verifier: %v", err)
+		}
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		pager := &recordingPager{}
+		adminAuditService := service.NewAdminAuditService(db)
+		handler := NewBreakGlassHandler(service.NewBreakGlassService(verifier, adminAuditService, pager))
+
+		// OPA is unreachable
+		evaluator := opa.NewEvaluator(opa.NewClient(&config.OPAConfig{URL: "http://127.0.0.1:1", Timeout: time.Second}), nil, false)
+
+		app := testutil.CreateTestApp()
+		v1 := app.Group("/v1")
+		v1.Use(middleware.BreakGlassMiddleware(handler.breakGlassService))
+		protected := v1.Use(middleware.AuthMiddleware(jwtService))
+		protected.Post("/tenants/:tenantId/activate",
+			middleware.AuditAdminAction(adminAuditService, "tenants.activate", "tenants", "tenantId"),
+			middleware.RequirePermissionOPA(evaluator, "tenants", "activate"),
+			func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"success": true})
+			})
+		protected.Get("/break-glass/session", handler.GetSession)
+
+		// Regular users are stuck while OPA is down
+		userAuth := testutil.WithAuthHeader(testutil.GenerateTestToken(t, jwtService, user.ID.String(), tenant.ID.String(), user.Email, []string{"admin"}))
+		resp := testutil.MakeRequest(t, app, "POST", "/v1/tenants/"+tenant.ID.String()+"/activate", nil, userAuth)
+		testutil.AssertStatusCode(t, http.StatusInternalServerError, resp.Code)
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/break-glass/session", nil, userAuth)
+		testutil.AssertStatusCode(t, http.StatusNotFound, resp.Code)
+
+		// Break-glass tokens get through, paging operators on their first use only
+		token, err := auth.IssueBreakGlassToken(privateKey, emergency.ID.String(), tenant.ID.String(), "alice", "OPA outage INC-1234", 15*time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to issue break-glass token: %v", err)
+		}
+		breakGlassAuth := testutil.WithAuthHeader(token)
+		resp = testutil.MakeRequest(t, app, "POST", "/v1/tenants/"+tenant.ID.String()+"/activate", nil, breakGlassAuth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/break-glass/session", nil, breakGlassAuth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		session := testutil.GetDataField(t, testutil.ParseJSONResponse(t, resp))
+		if session["userId"] != emergency.ID.String() || session["operator"] != "alice" || session["reason"] != "OPA outage INC-1234" || session["expiresAt"] == "" {
+			t.Errorf("Unexpected break-glass session: %v", session)
+		}
+
+		if len(pager.events) != 1 || pager.events[0].Type != events.EventBreakGlassUsed || pager.events[0].Data["operator"] != "alice" {
+			t.Errorf("Expected a single breakglass.used page, got %+v", pager.events)
+		}
+
+		// Every request is audited, and admin actions name the operator
+		var requests []models.AuditLog
+		db.Where("action = ?", "breakglass.request").Order("created_at").Find(&requests)
+		if len(requests) != 2 || requests[0].UserID == nil || *requests[0].UserID != emergency.ID || requests[0].StatusCode != http.StatusOK {
+			t.Fatalf("Expected both break-glass requests to be audited, got %+v", requests)
+		}
+		var activation models.AuditLog
+		db.Where("action = ? AND user_id = ?", "tenants.activate", emergency.ID).First(&activation)
+		if !strings.Contains(string(activation.Metadata), `"operator":"alice"`) {
+			t.Errorf("Expected the admin action to name the operator, got %s", activation.Metadata)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/storage"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestPolicyHandler_DownloadBundle(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "admin@example.com")
+
+		store, err := storage.NewLocalStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		content := []byte("0123456789abcdef")
+		if err := store.Put(context.Background(), "bundles/heimdall-1.0.0.tar.gz", content, "application/gzip"); err != nil {
+			t.Fatalf("Failed to store bundle: %v", err)
+		}
+
+		built := &models.PolicyBundle{
+			TenantID: tenant.ID, Name: "built", Version: "1.0.0", Status: models.BundleStatusReady,
+			StoragePath: "bundles/heimdall-1.0.0.tar.gz", Size: int64(len(content)), Checksum: "abc",
+			CreatedBy: user.ID, UpdatedBy: user.ID,
+		}
+		building := &models.PolicyBundle{
+			TenantID: tenant.ID, Name: "building", Version: "1.0.1", Status: models.BundleStatusBuilding,
+			CreatedBy: user.ID, UpdatedBy: user.ID,
+		}
+		for _, bundle := range []*models.PolicyBundle{built, building} {
+			if err := db.Create(bundle).Error; err != nil {
+				t.Fatalf("Failed to create bundle: %v", err)
+			}
+		}
+
+		handler := NewPolicyHandler(nil, service.NewBundleService(db, store, service.NewJobQueue(db, nil)))
+		app := testutil.CreateTestApp()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("tenantID", tenant.ID.String())
+			return c.Next()
+		})
+		app.Get("/v1/bundles/:id/download", handler.DownloadBundle)
+		app.Get("/v1/bundles/:id/download-url", handler.GetBundleDownloadURL)
+		path := "/v1/bundles/" + built.ID.String() + "/download"
+
+		resp := testutil.MakeRequest(t, app, "GET", path, nil, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		if resp.Body.String() != string(content) || resp.Header().Get("Accept-Ranges") != "bytes" || resp.Header().Get("ETag") != `"abc"` {
+			t.Errorf("Unexpected download: %q, headers %v", resp.Body.String(), resp.Header())
+		}
+
+		resp = testutil.MakeRequest(t, app, "GET", path, nil, map[string]string{"Range": "bytes=4-7"})
+		testutil.AssertStatusCode(t, http.StatusPartialContent, resp.Code)
+		if resp.Body.String() != "4567" || resp.Header().Get("Content-Range") != "bytes 4-7/16" {
+			t.Errorf("Expected bytes 4-7, got %q, Content-Range %q", resp.Body.String(), resp.Header().Get("Content-Range"))
+		}
+
+		resp = testutil.MakeRequest(t, app, "GET", path, nil, map[string]string{"Range": "bytes=-3"})
+		testutil.AssertStatusCode(t, http.StatusPartialContent, resp.Code)
+		if resp.Body.String() != "def" {
+			t.Errorf("Expected the last 3 bytes, got %q", resp.Body.String())
+		}
+
+		// A range of a changed bundle is answered with the whole bundle
+		resp = testutil.MakeRequest(t, app, "GET", path, nil, map[string]string{"Range": "bytes=4-7", "If-Range": `"old"`})
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		if resp.Body.String() != string(content) {
+			t.Errorf("Expected the whole bundle, got %q", resp.Body.String())
+		}
+
+		resp = testutil.MakeRequest(t, app, "GET", path, nil, map[string]string{"Range": "bytes=100-200"})
+		testutil.AssertStatusCode(t, http.StatusRequestedRangeNotSatisfiable, resp.Code)
+		if resp.Header().Get("Content-Range") != "bytes */16" {
+			t.Errorf("Expected Content-Range bytes */16, got %q", resp.Header().Get("Content-Range"))
+		}
+
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/bundles/"+building.ID.String()+"/download", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusConflict, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "BUNDLE_NOT_BUILT")
+
+		// Local storage cannot presign URLs
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/bundles/"+built.ID.String()+"/download-url", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusConflict, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "PRESIGNED_URLS_NOT_SUPPORTED")
+
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/bundles/"+built.ID.String()+"/download-url?expiresIn=10", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "INVALID_EXPIRY")
+	})
+}
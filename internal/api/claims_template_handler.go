@@ -0,0 +1,102 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// ClaimsTemplateHandler handles tenant claims template endpoints
+type ClaimsTemplateHandler struct {
+	claimsTemplateService *service.ClaimsTemplateService
+}
+
+// NewClaimsTemplateHandler creates a new claims template handler
+func NewClaimsTemplateHandler(claimsTemplateService *service.ClaimsTemplateService) *ClaimsTemplateHandler {
+	return &ClaimsTemplateHandler{
+		claimsTemplateService: claimsTemplateService,
+	}
+}
+
+// GetTemplate retrieves a tenant's claims template
+// GET /v1/tenants/:tenantId/claims-template
+func (h *ClaimsTemplateHandler) GetTemplate(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	template, err := h.claimsTemplateService.GetTemplate(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "CLAIMS_TEMPLATE_RETRIEVAL_FAILED", "Failed to retrieve claims template")
+	}
+
+	c.Set(fiber.HeaderETag, template.ETag)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// UpsertTemplate creates or replaces a tenant's claims template. If-Match and
+// If-None-Match headers make the request conditional on the current ETag.
+// PUT /v1/tenants/:tenantId/claims-template
+func (h *ClaimsTemplateHandler) UpsertTemplate(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpsertClaimsTemplateRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	template, created, err := h.claimsTemplateService.UpsertTemplate(c.UserContext(), tenantID, &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "CLAIMS_TEMPLATE_UPSERT_FAILED", "Failed to save claims template")
+	}
+
+	c.Set(fiber.HeaderETag, template.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    template,
+	})
+}
+
+// DeleteTemplate removes a tenant's claims template, restoring the default claims
+// DELETE /v1/tenants/:tenantId/claims-template
+func (h *ClaimsTemplateHandler) DeleteTemplate(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.claimsTemplateService.DeleteTemplate(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "CLAIMS_TEMPLATE_DELETION_FAILED", "Failed to delete claims template")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Claims template deleted successfully",
+	})
+}
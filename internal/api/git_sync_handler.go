@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// GitSyncHandler handles push webhooks that sync policies from a Git repository
+type GitSyncHandler struct {
+	syncer *service.GitPolicySyncer
+}
+
+// NewGitSyncHandler creates a new Git sync handler
+func NewGitSyncHandler(syncer *service.GitPolicySyncer) *GitSyncHandler {
+	return &GitSyncHandler{
+		syncer: syncer,
+	}
+}
+
+// gitPushEvent holds the fields shared by GitHub and GitLab push payloads
+type gitPushEvent struct {
+	Ref string `json:"ref"`
+}
+
+// HandlePush syncs policies after a push to the configured branch
+// POST /v1/webhooks/git/policies
+func (h *GitSyncHandler) HandlePush(c *fiber.Ctx) error {
+	if !h.syncer.VerifySignature(c.Body(), c.Get("X-Hub-Signature-256"), c.Get("X-Gitlab-Token")) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid webhook signature",
+				"code":    "INVALID_SIGNATURE",
+			},
+		})
+	}
+
+	// GitHub sends a ping when the webhook is created
+	if c.Get("X-GitHub-Event") == "ping" {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "pong",
+		})
+	}
+
+	var event gitPushEvent
+	if err := json.Unmarshal(c.Body(), &event); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid push payload",
+				"code":    "INVALID_PAYLOAD",
+			},
+		})
+	}
+
+	if strings.TrimPrefix(event.Ref, "refs/heads/") != h.syncer.Branch() {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Push ignored, branch is not synced",
+		})
+	}
+
+	// Cloning can outlast the webhook sender's timeout, so sync in the background
+	job, err := h.syncer.EnqueueSync(c.UserContext())
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_SYNC_FAILED", "Failed to start policy sync")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Policy sync started",
+		"data": fiber.Map{
+			"jobId": job.ID,
+		},
+	})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/resilience"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds each dependency check of the readiness probe
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck reports whether the instance can serve requests. It is ready
+// when the database and the critical dependencies are reachable, and degraded
+// when tokens, blacklists, caches and rate limits are kept in-process because
+// Redis is unavailable, which is fine for a single node but not shared between
+// replicas, or when another dependency is down. Once draining is set on
+// shutdown it fails, so load balancers stop routing to the instance.
+// GET /health/ready
+func ReadinessCheck(db *gorm.DB, store *database.RedisClient, dependencies *resilience.Dependencies, draining *atomic.Bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if draining.Load() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "draining",
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+		defer cancel()
+
+		status := "ready"
+		databaseCheck := fiber.Map{"status": "ok"}
+		if err := pingDatabase(ctx, db); err != nil {
+			status = "unavailable"
+			databaseCheck = fiber.Map{"status": "down", "error": err.Error()}
+		}
+
+		cacheCheck := fiber.Map{"status": "ok", "backend": database.StoreBackendRedis}
+		switch {
+		case store == nil:
+			cacheCheck = fiber.Map{"status": "degraded", "backend": "none", "message": "No store is configured: refresh tokens, blacklists, caches and rate limits are disabled"}
+		case store.Degraded():
+			cacheCheck = fiber.Map{"status": "degraded", "backend": store.Backend(), "message": "Redis is unavailable: refresh tokens, blacklists, caches and rate limits are kept in this process only"}
+		default:
+			if err := store.Ping(ctx); err != nil {
+				cacheCheck = fiber.Map{"status": "down", "backend": store.Backend(), "error": err.Error()}
+			}
+		}
+		if status == "ready" && cacheCheck["status"] != "ok" {
+			status = "degraded"
+		}
+
+		// Dependencies are checked in the background, so their last status is reported
+		checks := fiber.Map{
+			"database": databaseCheck,
+			"cache":    cacheCheck,
+		}
+		for name, dependency := range dependencies.Statuses() {
+			checks[name] = dependency
+			if status == "ready" && dependency.Status != resilience.DependencyOK {
+				status = "degraded"
+			}
+		}
+		if !dependencies.Ready() {
+			status = "unavailable"
+		}
+
+		code := fiber.StatusOK
+		if status == "unavailable" {
+			code = fiber.StatusServiceUnavailable
+		}
+		return c.Status(code).JSON(fiber.Map{
+			"status": status,
+			"checks": checks,
+		})
+	}
+}
+
+func pingDatabase(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
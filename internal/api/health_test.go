@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/resilience"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestReadinessCheck(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		var draining atomic.Bool
+		app := testutil.CreateTestApp()
+		app.Get("/health/ready", ReadinessCheck(db, nil, nil, &draining))
+
+		// Without a store the instance serves requests, degraded
+		resp := testutil.MakeRequest(t, app, "GET", "/health/ready", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		if body := testutil.ParseJSONResponse(t, resp); body["status"] != "degraded" {
+			t.Errorf("Expected a degraded instance, got %v", body)
+		}
+
+		// Draining instances fail readiness so load balancers stop routing to them
+		draining.Store(true)
+		resp = testutil.MakeRequest(t, app, "GET", "/health/ready", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusServiceUnavailable, resp.Code)
+		if body := testutil.ParseJSONResponse(t, resp); body["status"] != "draining" {
+			t.Errorf("Expected a draining instance, got %v", body)
+		}
+	})
+}
+
+func TestReadinessCheck_Dependencies(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		var draining atomic.Bool
+		var opaUp, bucketCreated atomic.Bool
+		var recoveries atomic.Int32
+		errDown := errors.New("connection refused")
+
+		dependencies := resilience.NewDependencies(time.Second)
+		dependencies.Add(resilience.Dependency{
+			Name:     "opa",
+			Critical: true,
+			Check: func(context.Context) error {
+				if !opaUp.Load() {
+					return errDown
+				}
+				return nil
+			},
+			Recover: func(context.Context) error {
+				recoveries.Add(1)
+				return nil
+			},
+		}, errDown)
+		dependencies.Add(resilience.Dependency{
+			Name: "bundle_storage",
+			Check: func(context.Context) error {
+				if !bucketCreated.Load() {
+					return errDown
+				}
+				return nil
+			},
+		}, errDown)
+
+		app := testutil.CreateTestApp()
+		app.Get("/health/ready", ReadinessCheck(db, database.NewMemoryClient(), dependencies, &draining))
+		ready := func(wantCode int, wantStatus string) map[string]interface{} {
+			t.Helper()
+			resp := testutil.MakeRequest(t, app, "GET", "/health/ready", nil, nil)
+			testutil.AssertStatusCode(t, wantCode, resp.Code)
+			body := testutil.ParseJSONResponse(t, resp)
+			if body["status"] != wantStatus {
+				t.Errorf("Expected status %s, got %v", wantStatus, body)
+			}
+			return body
+		}
+
+		// A critical dependency fails readiness until it is back
+		body := ready(http.StatusServiceUnavailable, "unavailable")
+		opaCheck := body["checks"].(map[string]interface{})["opa"].(map[string]interface{})
+		if opaCheck["status"] != "down" || opaCheck["error"] != "connection refused" {
+			t.Errorf("Expected OPA to be reported down, got %v", opaCheck)
+		}
+
+		opaUp.Store(true)
+		dependencies.CheckAll(context.Background())
+		if recoveries.Load() != 1 {
+			t.Errorf("Expected OPA to be recovered once, got %d recoveries", recoveries.Load())
+		}
+		ready(http.StatusOK, "degraded")
+
+		bucketCreated.Store(true)
+		dependencies.CheckAll(context.Background())
+		ready(http.StatusOK, "degraded") // The in-process store is still used
+		if recoveries.Load() != 1 {
+			t.Errorf("Expected an available dependency not to be recovered again, got %d recoveries", recoveries.Load())
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// IPAccessHandler handles tenant IP access list endpoints
+type IPAccessHandler struct {
+	ipAccessService *service.IPAccessService
+}
+
+// NewIPAccessHandler creates a new IP access handler
+func NewIPAccessHandler(ipAccessService *service.IPAccessService) *IPAccessHandler {
+	return &IPAccessHandler{
+		ipAccessService: ipAccessService,
+	}
+}
+
+// GetIPAccess retrieves a tenant's IP access lists
+// GET /v1/tenants/:tenantId/ip-access
+func (h *IPAccessHandler) GetIPAccess(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	lists, err := h.ipAccessService.GetIPAccess(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "IP_ACCESS_RETRIEVAL_FAILED", "Failed to retrieve IP access lists")
+	}
+
+	c.Set(fiber.HeaderETag, lists.ETag)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    lists,
+	})
+}
+
+// UpsertIPAccess creates or replaces a tenant's IP access lists. If-Match and
+// If-None-Match headers make the request conditional on the current ETag.
+// PUT /v1/tenants/:tenantId/ip-access
+func (h *IPAccessHandler) UpsertIPAccess(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpsertIPAccessRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+	req.ClientIP = c.IP()
+
+	lists, created, err := h.ipAccessService.UpsertIPAccess(c.UserContext(), tenantID, &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "IP_ACCESS_UPSERT_FAILED", "Failed to save IP access lists")
+	}
+
+	c.Set(fiber.HeaderETag, lists.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    lists,
+	})
+}
+
+// DeleteIPAccess removes a tenant's IP access lists
+// DELETE /v1/tenants/:tenantId/ip-access
+func (h *IPAccessHandler) DeleteIPAccess(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.ipAccessService.DeleteIPAccess(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "IP_ACCESS_DELETION_FAILED", "Failed to delete IP access lists")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "IP access lists deleted successfully",
+	})
+}
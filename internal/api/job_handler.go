@@ -0,0 +1,83 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// JobHandler handles the endpoints reporting background jobs, such as bundle
+// builds, to clients polling them
+type JobHandler struct {
+	jobQueue *service.JobQueue
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobQueue *service.JobQueue) *JobHandler {
+	return &JobHandler{
+		jobQueue: jobQueue,
+	}
+}
+
+// ListJobs retrieves the jobs of the caller's tenant, optionally filtered by
+// type and status
+// GET /v1/jobs?type=bundle.build&status=dead
+func (h *JobHandler) ListJobs(c *fiber.Ctx) error {
+	tenantID, _, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Parse(c.Query, service.JobListOptions)
+	if err != nil {
+		return err
+	}
+
+	jobs, page, err := h.jobQueue.ListJobs(c.UserContext(), tenantID, service.JobFilter{Type: c.Query("type")}, params)
+	if err != nil {
+		return apperrors.Wrap(err, "JOB_LIST_FAILED", "Failed to retrieve jobs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"jobs":       jobs,
+			"pagination": page,
+		},
+	})
+}
+
+// GetJob retrieves a job of the caller's tenant or one the caller started
+// GET /v1/jobs/:jobId
+func (h *JobHandler) GetJob(c *fiber.Ctx) error {
+	tenantID, userID, err := h.caller(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.jobQueue.GetJob(c.UserContext(), tenantID, userID, c.Params("jobId"))
+	if err != nil {
+		return apperrors.Wrap(err, "JOB_RETRIEVAL_FAILED", "Failed to retrieve job")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// caller returns the caller's tenant and user
+func (h *JobHandler) caller(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, apperrors.Validation("INVALID_REQUEST", "Tenant ID is required")
+	}
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, apperrors.Validation("INVALID_REQUEST", "User ID is required")
+	}
+	return tenantID, userID, nil
+}
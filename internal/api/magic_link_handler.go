@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// MagicLinkHandler handles passwordless login by emailed links
+type MagicLinkHandler struct {
+	magicLinkService *service.MagicLinkService
+}
+
+// NewMagicLinkHandler creates a new magic link handler
+func NewMagicLinkHandler(magicLinkService *service.MagicLinkService) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: magicLinkService,
+	}
+}
+
+// RequestLink emails a single-use login link to the user
+// POST /v1/auth/magic-link
+func (h *MagicLinkHandler) RequestLink(c *fiber.Ctx) error {
+	var req service.MagicLinkRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.magicLinkService.RequestLink(c.UserContext(), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "MAGIC_LINK_FAILED", "Failed to send magic link")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "If the email is registered, a sign-in link has been sent to it",
+		"data":    result,
+	})
+}
+
+// Redeem signs the user of a magic link in, returning the usual tokens
+// POST /v1/auth/magic-link/verify
+func (h *MagicLinkHandler) Redeem(c *fiber.Ctx) error {
+	var req service.MagicLinkRedeemRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.magicLinkService.Redeem(c.UserContext(), req.Token, &service.LoginRequest{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		DeviceID:  c.Get(DeviceIDHeader),
+	})
+	if err != nil {
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			return rejectedResponse(c, rejected, "LOGIN_REJECTED", "Login rejected")
+		}
+		return apperrors.Wrap(err, "INVALID_MAGIC_LINK", "Invalid or expired magic link")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
@@ -0,0 +1,428 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// OAuthHandler handles the OAuth token and device authorization endpoints and
+// OAuth client management
+type OAuthHandler struct {
+	oauthClientService *service.OAuthClientService
+	deviceService      *service.DeviceAuthorizationService
+	authService        *service.AuthService
+}
+
+// NewOAuthHandler creates a new OAuth handler
+func NewOAuthHandler(oauthClientService *service.OAuthClientService, deviceService *service.DeviceAuthorizationService, authService *service.AuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthClientService: oauthClientService,
+		deviceService:      deviceService,
+		authService:        authService,
+	}
+}
+
+// OAuthTokenRequest represents a request to the OAuth token endpoint. Client
+// credentials may also be sent with HTTP Basic authentication (RFC 6749 §2.3.1).
+type OAuthTokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type" validate:"required" example:"client_credentials"`
+	ClientID     string `json:"client_id,omitempty" form:"client_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	ClientSecret string `json:"client_secret,omitempty" form:"client_secret" example:"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"`
+	Scope        string `json:"scope,omitempty" form:"scope" example:"invoices.read"` // Space-separated permissions, defaults to all of the client's scopes
+	DeviceCode   string `json:"device_code,omitempty" form:"device_code" example:"3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea"`
+}
+
+// DeviceCodeRequest represents a request to start a device authorization (RFC 8628 §3.1)
+type DeviceCodeRequest struct {
+	ClientID string `json:"client_id" form:"client_id" validate:"required" example:"heimdallctl"` // Name of the device's client, shown to the user
+}
+
+// OAuthErrorResponse represents an error of the OAuth token endpoint (RFC 6749 §5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_client"`
+	ErrorDescription string `json:"error_description,omitempty" example:"Invalid client credentials"`
+}
+
+// Token issues access tokens to OAuth clients. Requests may be form or JSON
+// encoded, and responses and errors follow RFC 6749 §5 rather than the API's
+// response envelope so standard OAuth libraries can use the endpoint.
+// POST /v1/oauth/token
+func (h *OAuthHandler) Token(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	var req OAuthTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return oauthError(c, fiber.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	clientID, clientSecret := req.ClientID, req.ClientSecret
+	if username, password, ok := basicAuth(c); ok {
+		clientID, clientSecret = username, password
+	}
+
+	switch req.GrantType {
+	case auth.ClientCredentialsGrantType:
+	case service.DeviceCodeGrantType:
+		return h.deviceToken(c, clientID, req.DeviceCode)
+	case "":
+		return oauthError(c, fiber.StatusBadRequest, "invalid_request", "grant_type is required")
+	default:
+		return oauthError(c, fiber.StatusBadRequest, "unsupported_grant_type", "Unsupported grant type")
+	}
+
+	result, err := h.oauthClientService.IssueToken(c.UserContext(), clientID, clientSecret, req.Scope)
+	if err != nil {
+		var typed *apperrors.Error
+		if errors.As(err, &typed) {
+			switch typed.Code {
+			case "INVALID_CLIENT":
+				c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="heimdall"`)
+				return oauthError(c, fiber.StatusUnauthorized, "invalid_client", typed.Message)
+			case "INVALID_SCOPE":
+				return oauthError(c, fiber.StatusBadRequest, "invalid_scope", typed.Message)
+			}
+		}
+		return apperrors.Wrap(err, "TOKEN_ISSUE_FAILED", "Failed to issue token")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// deviceToken issues tokens to a device once the user approved its device
+// authorization, signing the user in as with any other login
+func (h *OAuthHandler) deviceToken(c *fiber.Ctx, clientID, deviceCode string) error {
+	identityUser, err := h.deviceService.PollAuthorization(c.UserContext(), clientID, deviceCode)
+	if err != nil {
+		var typed *apperrors.Error
+		if errors.As(err, &typed) {
+			switch typed.Code {
+			case "INVALID_REQUEST", "INVALID_GRANT", "AUTHORIZATION_PENDING", "SLOW_DOWN", "ACCESS_DENIED", "EXPIRED_TOKEN":
+				return oauthError(c, fiber.StatusBadRequest, strings.ToLower(typed.Code), typed.Message)
+			}
+		}
+		return apperrors.Wrap(err, "TOKEN_ISSUE_FAILED", "Failed to issue token")
+	}
+
+	result, err := h.authService.LoginExternal(c.UserContext(), identityUser, &service.LoginRequest{
+		Email:     identityUser.Email,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		DeviceID:  c.Get(DeviceIDHeader),
+	})
+	if err != nil {
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			return oauthError(c, fiber.StatusBadRequest, "access_denied", "Login rejected: "+rejected.Reason)
+		}
+		return apperrors.Wrap(err, "TOKEN_ISSUE_FAILED", "Failed to issue token")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(service.OAuthTokenResponse{
+		AccessToken:  result.AccessToken,
+		TokenType:    result.TokenType,
+		ExpiresIn:    result.ExpiresIn,
+		RefreshToken: result.RefreshToken,
+	})
+}
+
+// DeviceCode starts a device authorization. The device shows the user code and
+// verification URI to the user and polls the token endpoint with the device
+// code. Responses and errors follow RFC 8628 rather than the API's envelope.
+// POST /v1/oauth/device/code
+func (h *OAuthHandler) DeviceCode(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	var req DeviceCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return oauthError(c, fiber.StatusBadRequest, "invalid_request", "Invalid request body")
+	}
+
+	result, err := h.deviceService.StartAuthorization(c.UserContext(), req.ClientID)
+	if err != nil {
+		var typed *apperrors.Error
+		if errors.As(err, &typed) && typed.Code == "INVALID_REQUEST" {
+			return oauthError(c, fiber.StatusBadRequest, "invalid_request", typed.Message)
+		}
+		return apperrors.Wrap(err, "DEVICE_AUTHORIZATION_FAILED", "Failed to start device authorization")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(result)
+}
+
+// GetDeviceAuthorization retrieves the device authorization of a user code for
+// the verification page
+// GET /v1/oauth/device?user_code=...
+func (h *OAuthHandler) GetDeviceAuthorization(c *fiber.Ctx) error {
+	userCode := c.Query("user_code")
+	if userCode == "" {
+		return apperrors.Validation("INVALID_REQUEST", "user_code is required")
+	}
+
+	authorization, err := h.deviceService.GetAuthorization(c.UserContext(), userCode)
+	if err != nil {
+		return apperrors.Wrap(err, "DEVICE_AUTHORIZATION_RETRIEVAL_FAILED", "Failed to retrieve device authorization")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    authorization,
+	})
+}
+
+// ApproveDevice lets the device of a user code sign in as the current user
+// POST /v1/oauth/device/approve
+func (h *OAuthHandler) ApproveDevice(c *fiber.Ctx) error {
+	return h.decideDevice(c, true)
+}
+
+// DenyDevice rejects the device of a user code
+// POST /v1/oauth/device/deny
+func (h *OAuthHandler) DenyDevice(c *fiber.Ctx) error {
+	return h.decideDevice(c, false)
+}
+
+func (h *OAuthHandler) decideDevice(c *fiber.Ctx, approve bool) error {
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req service.DeviceVerificationRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	var authorization *service.DeviceAuthorizationResponse
+	if approve {
+		authorization, err = h.deviceService.ApproveAuthorization(c.UserContext(), req.UserCode, userID)
+	} else {
+		authorization, err = h.deviceService.DenyAuthorization(c.UserContext(), req.UserCode, userID)
+	}
+	if err != nil {
+		return apperrors.Wrap(err, "DEVICE_AUTHORIZATION_UPDATE_FAILED", "Failed to update device authorization")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    authorization,
+	})
+}
+
+// ListClients lists a tenant's OAuth clients
+// GET /v1/tenants/:tenantId/oauth-clients
+func (h *OAuthHandler) ListClients(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	clients, err := h.oauthClientService.ListClients(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "OAUTH_CLIENT_LIST_FAILED", "Failed to list OAuth clients")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    clients,
+		"count":   len(clients),
+	})
+}
+
+// CreateClient registers an OAuth client for a tenant. The response carries the
+// client secret, which cannot be retrieved again.
+// POST /v1/tenants/:tenantId/oauth-clients
+func (h *OAuthHandler) CreateClient(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.CreateOAuthClientRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	client, err := h.oauthClientService.CreateClient(c.UserContext(), tenantID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "OAUTH_CLIENT_CREATION_FAILED", "Failed to create OAuth client")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    client,
+	})
+}
+
+// GetClient retrieves an OAuth client of a tenant
+// GET /v1/tenants/:tenantId/oauth-clients/:clientId
+func (h *OAuthHandler) GetClient(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	client, err := h.oauthClientService.GetClient(c.UserContext(), tenantID, c.Params("clientId"))
+	if err != nil {
+		return apperrors.Wrap(err, "OAUTH_CLIENT_RETRIEVAL_FAILED", "Failed to retrieve OAuth client")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    client,
+	})
+}
+
+// UpdateClient changes the name or scopes of an OAuth client
+// PATCH /v1/tenants/:tenantId/oauth-clients/:clientId
+func (h *OAuthHandler) UpdateClient(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpdateOAuthClientRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	client, err := h.oauthClientService.UpdateClient(c.UserContext(), tenantID, c.Params("clientId"), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "OAUTH_CLIENT_UPDATE_FAILED", "Failed to update OAuth client")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    client,
+	})
+}
+
+// RotateClientSecret issues a new secret for an OAuth client
+// POST /v1/tenants/:tenantId/oauth-clients/:clientId/rotate-secret
+func (h *OAuthHandler) RotateClientSecret(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.RotateOAuthClientSecretRequest
+	if len(c.Body()) > 0 {
+		if err := bindRequest(c, &req); err != nil {
+			return err
+		}
+	}
+
+	client, err := h.oauthClientService.RotateSecret(c.UserContext(), tenantID, c.Params("clientId"), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "OAUTH_CLIENT_ROTATION_FAILED", "Failed to rotate client secret")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    client,
+	})
+}
+
+// DeleteClient removes an OAuth client
+// DELETE /v1/tenants/:tenantId/oauth-clients/:clientId
+func (h *OAuthHandler) DeleteClient(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.oauthClientService.DeleteClient(c.UserContext(), tenantID, c.Params("clientId")); err != nil {
+		return apperrors.Wrap(err, "OAUTH_CLIENT_DELETION_FAILED", "Failed to delete OAuth client")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "OAuth client deleted successfully",
+	})
+}
+
+// oauthError writes an error response of the OAuth token endpoint (RFC 6749 §5.2)
+func oauthError(c *fiber.Ctx, status int, code, description string) error {
+	return c.Status(status).JSON(OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+// basicAuth returns the credentials of an HTTP Basic Authorization header. OAuth
+// clients form-encode their ID and secret before encoding them (RFC 6749 §2.3.1).
+func basicAuth(c *fiber.Ctx) (string, string, bool) {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) < 6 || !strings.EqualFold(header[:6], "Basic ") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(header[6:])
+	if err != nil {
+		return "", "", false
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", false
+	}
+	if unescaped, err := url.QueryUnescape(username); err == nil {
+		username = unescaped
+	}
+	if unescaped, err := url.QueryUnescape(password); err == nil {
+		password = unescaped
+	}
+	return username, password, true
+}
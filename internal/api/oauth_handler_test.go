@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestOAuthHandler_ClientCredentials(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		testutil.CreateTestPermission(t, db, "invoices.read", "invoices", "read")
+		testutil.CreateTestPermission(t, db, "invoices.export", "invoices", "export")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		oauthClientService := service.NewOAuthClientService(db, jwtService)
+		client, err := oauthClientService.CreateClient(ctx, tenant.ID, &service.CreateOAuthClientRequest{
+			Name:   "Export job",
+			Scopes: []string{"invoices.read", "invoices.export"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		// Client tokens are authorized by scope alone, so no OPA evaluator is needed
+		app := testutil.CreateTestApp()
+		v1 := app.Group("/v1")
+		v1.Post("/oauth/token", NewOAuthHandler(oauthClientService, nil, nil).Token)
+		protected := v1.Use(middleware.AuthMiddleware(jwtService))
+		ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+		protected.Get("/invoices", middleware.RequirePermissionOPA(nil, "invoices", "read"), ok)
+		protected.Delete("/invoices", middleware.RequirePermissionOPA(nil, "invoices", "delete"), ok)
+		protected.Get("/tenants/:tenantId/invoices", middleware.RequirePermissionOPA(nil, "invoices", "read"), ok)
+		protected.Get("/me", middleware.RequireUnscopedToken(), ok)
+
+		// Form-encoded request with HTTP Basic client authentication
+		form := url.Values{"grant_type": {"client_credentials"}, "scope": {"invoices.read"}}
+		req := httptest.NewRequest(http.MethodPost, "/v1/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+		req.SetBasicAuth(client.ClientID, client.ClientSecret)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to request token: %v", err)
+		}
+		testutil.AssertStatusCode(t, http.StatusOK, resp.StatusCode)
+		if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+			t.Errorf("Expected Cache-Control no-store, got %q", resp.Header.Get(fiber.HeaderCacheControl))
+		}
+		var token service.OAuthTokenResponse
+		body, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+			t.Fatalf("Expected an unwrapped token response, got %s", body)
+		}
+		if token.Scope != "invoices.read" || token.TokenType != "Bearer" {
+			t.Errorf("Unexpected token response %+v", token)
+		}
+
+		auth := testutil.WithAuthHeader(token.AccessToken)
+		testutil.AssertStatusCode(t, http.StatusNoContent, testutil.MakeRequest(t, app, "GET", "/v1/invoices", nil, auth).Code)
+		testutil.AssertStatusCode(t, http.StatusNoContent, testutil.MakeRequest(t, app, "GET", "/v1/tenants/"+tenant.ID.String()+"/invoices", nil, auth).Code)
+
+		resp2 := testutil.MakeRequest(t, app, "DELETE", "/v1/invoices", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp2.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp2), "OUT_OF_SCOPE")
+
+		resp2 = testutil.MakeRequest(t, app, "GET", "/v1/tenants/"+other.ID.String()+"/invoices", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp2.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp2), "TENANT_ISOLATION_VIOLATION")
+
+		resp2 = testutil.MakeRequest(t, app, "GET", "/v1/me", nil, auth)
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp2.Code)
+
+		// JSON request with credentials in the body
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", map[string]string{
+			"grant_type":    "client_credentials",
+			"client_id":     client.ClientID,
+			"client_secret": client.ClientSecret,
+		}, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp2.Code)
+		testutil.AssertJSONField(t, testutil.ParseJSONResponse(t, resp2), "scope", "invoices.export invoices.read")
+
+		// Errors follow RFC 6749
+		badCredentials := base64.StdEncoding.EncodeToString([]byte(client.ClientID + ":wrong"))
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", map[string]string{"grant_type": "client_credentials"},
+			map[string]string{"Authorization": "Basic " + badCredentials})
+		testutil.AssertStatusCode(t, http.StatusUnauthorized, resp2.Code)
+		testutil.AssertJSONField(t, testutil.ParseJSONResponse(t, resp2), "error", "invalid_client")
+
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", map[string]string{
+			"grant_type":    "client_credentials",
+			"client_id":     client.ClientID,
+			"client_secret": client.ClientSecret,
+			"scope":         "invoices.delete",
+		}, nil)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp2.Code)
+		testutil.AssertJSONField(t, testutil.ParseJSONResponse(t, resp2), "error", "invalid_scope")
+
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", map[string]string{"grant_type": "password"}, nil)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp2.Code)
+		testutil.AssertJSONField(t, testutil.ParseJSONResponse(t, resp2), "error", "unsupported_grant_type")
+	})
+}
+
+func TestOAuthHandler_DeviceAuthorization(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "alice@example.com")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		deviceService := service.NewDeviceAuthorizationService(db, &config.OAuthConfig{
+			DeviceVerificationURI: "https://heimdall.example.com/device",
+			DeviceCodeExpiry:      10 * time.Minute,
+			DevicePollInterval:    5 * time.Second,
+		})
+		authService := service.NewAuthService(db, nil, jwtService, nil, nil, nil)
+		handler := NewOAuthHandler(nil, deviceService, authService)
+
+		app := testutil.CreateTestApp()
+		v1 := app.Group("/v1")
+		v1.Post("/oauth/token", handler.Token)
+		v1.Post("/oauth/device/code", handler.DeviceCode)
+		protected := v1.Use(middleware.AuthMiddleware(jwtService))
+		protected.Get("/oauth/device", middleware.RequireUnscopedToken(), handler.GetDeviceAuthorization)
+		protected.Post("/oauth/device/approve", middleware.RequireUnscopedToken(), handler.ApproveDevice)
+
+		// The device starts with a form-encoded request
+		form := url.Values{"client_id": {"heimdallctl"}}
+		req := httptest.NewRequest(http.MethodPost, "/v1/oauth/device/code", strings.NewReader(form.Encode()))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Failed to request device code: %v", err)
+		}
+		testutil.AssertStatusCode(t, http.StatusOK, resp.StatusCode)
+		var started service.DeviceCodeResponse
+		body, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(body, &started); err != nil || started.DeviceCode == "" || started.UserCode == "" {
+			t.Fatalf("Expected an unwrapped device code response, got %s", body)
+		}
+
+		poll := map[string]string{
+			"grant_type":  service.DeviceCodeGrantType,
+			"client_id":   "heimdallctl",
+			"device_code": started.DeviceCode,
+		}
+		resp2 := testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", poll, nil)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp2.Code)
+		testutil.AssertJSONField(t, testutil.ParseJSONResponse(t, resp2), "error", "authorization_pending")
+
+		// The user approves the device while signed in in a browser
+		auth := testutil.WithAuthHeader(testutil.GenerateTestToken(t, jwtService, user.ID.String(), tenant.ID.String(), user.Email, nil))
+		resp2 = testutil.MakeRequest(t, app, "GET", "/v1/oauth/device?user_code="+url.QueryEscape(started.UserCode), nil, auth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp2.Code)
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/device/approve", map[string]string{"userCode": started.UserCode}, auth)
+		testutil.AssertStatusCode(t, http.StatusOK, resp2.Code)
+		data := testutil.GetDataField(t, testutil.ParseJSONResponse(t, resp2))
+		if data["status"] != "approved" {
+			t.Errorf("Expected approved device authorization, got %v", data)
+		}
+
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", poll, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp2.Code)
+		var token service.OAuthTokenResponse
+		if err := json.Unmarshal(resp2.Body.Bytes(), &token); err != nil || token.AccessToken == "" || token.RefreshToken == "" {
+			t.Fatalf("Expected access and refresh tokens, got %s", resp2.Body.String())
+		}
+		claims, err := jwtService.ValidateAccessToken(token.AccessToken)
+		if err != nil {
+			t.Fatalf("Failed to validate device token: %v", err)
+		}
+		if claims.UserID != user.ID.String() {
+			t.Errorf("Expected a token for the approving user, got %q", claims.UserID)
+		}
+
+		resp2 = testutil.MakeRequest(t, app, "POST", "/v1/oauth/token", poll, nil)
+		testutil.AssertStatusCode(t, http.StatusBadRequest, resp2.Code)
+		testutil.AssertJSONField(t, testutil.ParseJSONResponse(t, resp2), "error", "invalid_grant")
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// OPAInstanceHandler handles the status API of OPA instances, e.g. sidecars
+// running Heimdall's bundles, and the endpoints listing them
+type OPAInstanceHandler struct {
+	opaInstanceService *service.OPAInstanceService
+}
+
+// NewOPAInstanceHandler creates a new OPA instance handler
+func NewOPAInstanceHandler(opaInstanceService *service.OPAInstanceService) *OPAInstanceHandler {
+	return &OPAInstanceHandler{
+		opaInstanceService: opaInstanceService,
+	}
+}
+
+// ReportStatus records the status of an OPA instance, as OPA's status plugin
+// sends it to a service at its /status resource
+// POST /v1/status
+func (h *OPAInstanceHandler) ReportStatus(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	source := &service.OPAInstanceSource{
+		TenantID:  tenantUUID,
+		ClientID:  middleware.GetClientID(c),
+		IPAddress: c.IP(),
+	}
+	if err := h.opaInstanceService.ReportStatus(c.UserContext(), source, c.Body()); err != nil {
+		return apperrors.Wrap(err, "OPA_STATUS_REPORT_FAILED", "Failed to record status")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Status recorded successfully",
+	})
+}
+
+// ListInstances retrieves the OPA instances reporting to the caller's tenant,
+// with the bundle revisions they have loaded and the health of their plugins
+// GET /v1/opa-instances
+func (h *OPAInstanceHandler) ListInstances(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant ID is required",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	params, err := pagination.Parse(c.Query, service.OPAInstanceListOptions)
+	if err != nil {
+		return err
+	}
+
+	instances, page, err := h.opaInstanceService.ListInstances(c.UserContext(), tenantUUID, params)
+	if err != nil {
+		return apperrors.Wrap(err, "OPA_INSTANCE_LIST_FAILED", "Failed to retrieve OPA instances")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"instances":  instances,
+			"pagination": page,
+		},
+	})
+}
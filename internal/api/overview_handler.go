@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// OverviewHandler handles the admin overview endpoint
+type OverviewHandler struct {
+	overviewService *service.OverviewService
+}
+
+// NewOverviewHandler creates a new overview handler
+func NewOverviewHandler(overviewService *service.OverviewService) *OverviewHandler {
+	return &OverviewHandler{
+		overviewService: overviewService,
+	}
+}
+
+// GetOverview returns a snapshot of all tenants for the admin landing page
+// GET /v1/admin/overview
+func (h *OverviewHandler) GetOverview(c *fiber.Ctx) error {
+	overview, err := h.overviewService.Overview(c.UserContext())
+	if err != nil {
+		return apperrors.Wrap(err, "OVERVIEW_FAILED", "Failed to compute admin overview")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    overview,
+	})
+}
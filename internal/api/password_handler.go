@@ -2,9 +2,10 @@ package api
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/middleware"
 	"github.com/techsavvyash/heimdall/internal/service"
-	"github.com/techsavvyash/heimdall/internal/utils"
 )
 
 // PasswordHandler handles password-related endpoints
@@ -34,41 +35,79 @@ func (h *PasswordHandler) ChangePassword(c *fiber.Ctx) error {
 	}
 
 	var req service.ChangePasswordRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validate request
-	if err := utils.ValidateStruct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Validation failed",
-				"code":    "VALIDATION_ERROR",
-				"details": err,
-			},
-		})
+	// Change password
+	if err := h.passwordService.ChangePassword(c.UserContext(), userID, &req); err != nil {
+		return apperrors.Wrap(err, "PASSWORD_CHANGE_FAILED", "Failed to change password")
 	}
 
-	// Change password
-	if err := h.passwordService.ChangePassword(c.Context(), userID, &req); err != nil {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Password changed successfully",
+	})
+}
+
+// ForgotPassword starts a password reset, emailing the user a reset token
+// POST /v1/auth/password/reset
+func (h *PasswordHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req service.ForgotPasswordRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.passwordService.ForgotPassword(c.UserContext(), &req); err != nil {
+		return apperrors.Wrap(err, "PASSWORD_RESET_FAILED", "Failed to start password reset")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "If the email is registered, a password reset has been sent to it",
+	})
+}
+
+// ResetPassword sets a new password with an emailed reset token
+// POST /v1/auth/password/reset/confirm
+func (h *PasswordHandler) ResetPassword(c *fiber.Ctx) error {
+	var req service.ResetPasswordRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.passwordService.ResetPassword(c.UserContext(), &req); err != nil {
+		return apperrors.Wrap(err, "PASSWORD_RESET_FAILED", "Failed to reset password")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Password reset successfully",
+	})
+}
+
+// GetPasswordPolicy returns the password policy of a tenant, so clients can
+// check new passwords before submitting them
+// GET /v1/tenants/:tenantId/password-policy
+func (h *PasswordHandler) GetPasswordPolicy(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "PASSWORD_CHANGE_FAILED",
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
+	policy, err := h.passwordService.GetPasswordPolicy(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "PASSWORD_POLICY_RETRIEVAL_FAILED", "Failed to retrieve password policy")
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Password changed successfully",
+		"data":    policy,
 	})
 }
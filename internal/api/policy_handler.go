@@ -1,10 +1,20 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/middleware"
-	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"github.com/techsavvyash/heimdall/internal/service"
 )
 
@@ -48,14 +58,8 @@ func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
 	}
 
 	var req service.CreatePolicyRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
 	userUUID, err := uuid.Parse(userID)
@@ -83,21 +87,18 @@ func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
 	// Set tenant ID from authenticated user's context
 	req.TenantID = tenantUUID
 
-	policy, err := h.policyService.CreatePolicy(c.Context(), userUUID, &req)
+	policy, err := h.policyService.CreatePolicy(c.UserContext(), userUUID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to create policy",
-				"code":    "POLICY_CREATION_FAILED",
-				"details": err.Error(),
-			},
-		})
+		return apperrors.Wrap(err, "POLICY_CREATION_FAILED", "Failed to create policy")
 	}
 
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
-		"data":    policy,
+		"data":    data,
 	})
 }
 
@@ -126,29 +127,26 @@ func (h *PolicyHandler) ListPolicies(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get status filter from query params
-	var status *models.PolicyStatus
-	if statusStr := c.Query("status"); statusStr != "" {
-		s := models.PolicyStatus(statusStr)
-		status = &s
+	params, err := pagination.Parse(c.Query, service.PolicyListOptions)
+	if err != nil {
+		return err
 	}
 
-	policies, err := h.policyService.GetPoliciesByTenant(c.Context(), tenantUUID, status)
+	policies, page, err := h.policyService.ListPolicies(c.UserContext(), tenantUUID, middleware.GetRowFilter(c), params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to list policies",
-				"code":    "POLICY_LIST_FAILED",
-				"details": err.Error(),
-			},
-		})
+		return apperrors.Wrap(err, "POLICY_LIST_FAILED", "Failed to list policies")
 	}
 
+	data, err := redact(c, policies)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    policies,
-		"count":   len(policies),
+		"data": fiber.Map{
+			"policies":   data,
+			"pagination": page,
+		},
 	})
 }
 
@@ -165,113 +163,117 @@ func (h *PolicyHandler) GetPolicy(c *fiber.Ctx) error {
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
 
-	policy, err := h.policyService.GetPolicy(c.Context(), policyID)
+	policy, err := h.policyService.GetPolicy(c.UserContext(), policyID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Policy not found",
-				"code":    "POLICY_NOT_FOUND",
-			},
-		})
+		return apperrors.Wrap(err, "POLICY_RETRIEVAL_FAILED", "Failed to retrieve policy")
 	}
 
+	c.Set(fiber.HeaderETag, service.ResourceETag(policy.UpdatedAt))
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    policy,
+		"data":    data,
 	})
 }
 
-// UpdatePolicy updates a policy
-// PUT /v1/policies/:id
-func (h *PolicyHandler) UpdatePolicy(c *fiber.Ctx) error {
-	policyID, err := uuid.Parse(c.Params("id"))
+// GetPolicyByPath retrieves a policy of the current tenant by path
+// GET /v1/policies/path/*
+func (h *PolicyHandler) GetPolicyByPath(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid policy ID",
-				"code":    "INVALID_POLICY_ID",
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
 			},
 		})
 	}
 
-	userID := middleware.GetUserID(c)
-	userUUID, err := uuid.Parse(userID)
+	policyPath, err := url.PathUnescape(c.Params("*"))
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid user ID",
-				"code":    "INVALID_USER_ID",
-			},
-		})
+		return apperrors.Validation("INVALID_POLICY_PATH", "Invalid policy path").WithCause(err)
 	}
 
-	var req service.UpdatePolicyRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	policy, err := h.policyService.GetPolicyByPath(c.UserContext(), tenantUUID, policyPath)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_RETRIEVAL_FAILED", "Failed to retrieve policy")
 	}
 
-	policy, err := h.policyService.UpdatePolicy(c.Context(), policyID, userUUID, &req)
+	c.Set(fiber.HeaderETag, service.ResourceETag(policy.UpdatedAt))
+	data, err := redact(c, policy)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to update policy",
-				"code":    "POLICY_UPDATE_FAILED",
-				"details": err.Error(),
-			},
-		})
+		return err
 	}
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    policy,
+		"data":    data,
 	})
 }
 
-// DeletePolicy deletes a policy
-// DELETE /v1/policies/:id
-func (h *PolicyHandler) DeletePolicy(c *fiber.Ctx) error {
-	policyID, err := uuid.Parse(c.Params("id"))
+// UpsertPolicy creates or replaces a policy of the current tenant by path. If-Match and
+// If-None-Match headers make the request conditional on the policy's current ETag.
+// PUT /v1/policies/path/*
+func (h *PolicyHandler) UpsertPolicy(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid policy ID",
-				"code":    "INVALID_POLICY_ID",
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
 			},
 		})
 	}
 
-	if err := h.policyService.DeletePolicy(c.Context(), policyID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	userUUID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to delete policy",
-				"code":    "POLICY_DELETE_FAILED",
-				"details": err.Error(),
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
 			},
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	// Slashes in the path may arrive escaped, e.g. from generated clients
+	policyPath, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return apperrors.Validation("INVALID_POLICY_PATH", "Invalid policy path").WithCause(err)
+	}
+
+	var req service.UpsertPolicyRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	policy, created, err := h.policyService.UpsertPolicy(c.UserContext(), tenantUUID, userUUID, policyPath, &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_UPSERT_FAILED", "Failed to save policy")
+	}
+
+	c.Set(fiber.HeaderETag, service.ResourceETag(policy.UpdatedAt))
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
+	}
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
 		"success": true,
-		"message": "Policy deleted successfully",
+		"data":    data,
 	})
 }
 
-// PublishPolicy publishes a policy
-// POST /v1/policies/:id/publish
-func (h *PolicyHandler) PublishPolicy(c *fiber.Ctx) error {
+// UpdatePolicy updates a policy
+// PUT /v1/policies/:id
+func (h *PolicyHandler) UpdatePolicy(c *fiber.Ctx) error {
 	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -282,6 +284,9 @@ func (h *PolicyHandler) PublishPolicy(c *fiber.Ctx) error {
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
 
 	userID := middleware.GetUserID(c)
 	userUUID, err := uuid.Parse(userID)
@@ -295,27 +300,29 @@ func (h *PolicyHandler) PublishPolicy(c *fiber.Ctx) error {
 		})
 	}
 
-	policy, err := h.policyService.PublishPolicy(c.Context(), policyID, userUUID)
+	var req service.UpdatePolicyRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	policy, err := h.policyService.UpdatePolicy(c.UserContext(), policyID, userUUID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to publish policy",
-				"code":    "POLICY_PUBLISH_FAILED",
-				"details": err.Error(),
-			},
-		})
+		return apperrors.Wrap(err, "POLICY_UPDATE_FAILED", "Failed to update policy")
 	}
 
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    policy,
+		"data":    data,
 	})
 }
 
-// ValidatePolicy validates a policy
-// POST /v1/policies/:id/validate
-func (h *PolicyHandler) ValidatePolicy(c *fiber.Ctx) error {
+// DeletePolicy deletes a policy
+// DELETE /v1/policies/:id
+func (h *PolicyHandler) DeletePolicy(c *fiber.Ctx) error {
 	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -326,59 +333,71 @@ func (h *PolicyHandler) ValidatePolicy(c *fiber.Ctx) error {
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
 
-	if err := h.policyService.ValidatePolicy(c.Context(), policyID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Policy validation failed",
-				"code":    "POLICY_VALIDATION_FAILED",
-				"details": err.Error(),
-			},
-		})
+	if err := h.policyService.DeletePolicy(c.UserContext(), policyID); err != nil {
+		return apperrors.Wrap(err, "POLICY_DELETE_FAILED", "Failed to delete policy")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Policy is valid",
+		"message": "Policy deleted successfully",
 	})
 }
 
-// TestPolicy tests a policy
-// POST /v1/policies/:id/test
-func (h *PolicyHandler) TestPolicy(c *fiber.Ctx) error {
-	policyID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
+// ListDeletedPolicies lists the tenant's deleted policies that can still be restored
+// GET /v1/policies/deleted
+func (h *PolicyHandler) ListDeletedPolicies(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid policy ID",
-				"code":    "INVALID_POLICY_ID",
+				"message": "Tenant ID is required",
+				"code":    "TENANT_REQUIRED",
 			},
 		})
 	}
 
-	results, err := h.policyService.TestPolicy(c.Context(), policyID)
+	tenantUUID, err := uuid.Parse(tenantID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Policy test failed",
-				"code":    "POLICY_TEST_FAILED",
-				"details": err.Error(),
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_TENANT_ID",
 			},
 		})
 	}
 
+	params, err := pagination.Parse(c.Query, service.DeletedPolicyListOptions)
+	if err != nil {
+		return err
+	}
+
+	policies, page, err := h.policyService.ListDeletedPolicies(c.UserContext(), tenantUUID, params)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_LIST_FAILED", "Failed to list deleted policies")
+	}
+
+	data, err := redact(c, policies)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    results,
+		"data": fiber.Map{
+			"policies":   data,
+			"pagination": page,
+		},
 	})
 }
 
-// GetPolicyVersions retrieves all versions of a policy
-// GET /v1/policies/:id/versions
-func (h *PolicyHandler) GetPolicyVersions(c *fiber.Ctx) error {
+// RestorePolicy restores a deleted policy
+// POST /v1/policies/:id/restore
+func (h *PolicyHandler) RestorePolicy(c *fiber.Ctx) error {
 	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -389,33 +408,11 @@ func (h *PolicyHandler) GetPolicyVersions(c *fiber.Ctx) error {
 			},
 		})
 	}
-
-	versions, err := h.policyService.GetPolicyVersions(c.Context(), policyID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to get policy versions",
-				"code":    "POLICY_VERSIONS_FAILED",
-				"details": err.Error(),
-			},
-		})
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"data":    versions,
-		"count":   len(versions),
-	})
-}
-
-// --- Bundle Endpoints ---
-
-// CreateBundle creates a new policy bundle
-// POST /v1/bundles
-func (h *PolicyHandler) CreateBundle(c *fiber.Ctx) error {
-	userID := middleware.GetUserID(c)
-	userUUID, err := uuid.Parse(userID)
+	userUUID, err := uuid.Parse(middleware.GetUserID(c))
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -426,111 +423,64 @@ func (h *PolicyHandler) CreateBundle(c *fiber.Ctx) error {
 		})
 	}
 
-	var req service.CreateBundleRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
-	}
-
-	bundle, err := h.bundleService.CreateBundle(c.Context(), userUUID, &req)
+	policy, err := h.policyService.RestorePolicy(c.UserContext(), policyID, userUUID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to create bundle",
-				"code":    "BUNDLE_CREATION_FAILED",
-				"details": err.Error(),
-			},
-		})
-	}
-
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"data":    bundle,
-		"message": "Bundle is being built",
-	})
-}
-
-// ListBundles lists all bundles
-// GET /v1/bundles
-func (h *PolicyHandler) ListBundles(c *fiber.Ctx) error {
-	tenantID := middleware.GetTenantID(c)
-	var tenantUUID *uuid.UUID
-	if tenantID != "" {
-		parsed, err := uuid.Parse(tenantID)
-		if err == nil {
-			tenantUUID = &parsed
-		}
+		return apperrors.Wrap(err, "POLICY_RESTORE_FAILED", "Failed to restore policy")
 	}
 
-	bundles, err := h.bundleService.GetBundles(c.Context(), tenantUUID)
+	data, err := redact(c, policy)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to list bundles",
-				"code":    "BUNDLE_LIST_FAILED",
-				"details": err.Error(),
-			},
-		})
+		return err
 	}
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    bundles,
-		"count":   len(bundles),
+		"data":    data,
 	})
 }
 
-// GetBundle retrieves a specific bundle
-// GET /v1/bundles/:id
-func (h *PolicyHandler) GetBundle(c *fiber.Ctx) error {
-	bundleID, err := uuid.Parse(c.Params("id"))
+// PurgePolicy permanently deletes a deleted policy
+// DELETE /v1/policies/:id/purge
+func (h *PolicyHandler) PurgePolicy(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid bundle ID",
-				"code":    "INVALID_BUNDLE_ID",
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
 
-	bundle, err := h.bundleService.GetBundle(c.Context(), bundleID)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Bundle not found",
-				"code":    "BUNDLE_NOT_FOUND",
-			},
-		})
+	if err := h.policyService.PurgePolicy(c.UserContext(), policyID); err != nil {
+		return apperrors.Wrap(err, "POLICY_PURGE_FAILED", "Failed to purge policy")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    bundle,
+		"message": "Policy purged successfully",
 	})
 }
 
-// ActivateBundle activates a bundle
-// POST /v1/bundles/:id/activate
-func (h *PolicyHandler) ActivateBundle(c *fiber.Ctx) error {
-	bundleID, err := uuid.Parse(c.Params("id"))
+// PublishPolicy publishes a policy
+// POST /v1/policies/:id/publish
+func (h *PolicyHandler) PublishPolicy(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid bundle ID",
-				"code":    "INVALID_BUNDLE_ID",
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
 
 	userID := middleware.GetUserID(c)
 	userUUID, err := uuid.Parse(userID)
@@ -544,37 +494,37 @@ func (h *PolicyHandler) ActivateBundle(c *fiber.Ctx) error {
 		})
 	}
 
-	bundle, err := h.bundleService.ActivateBundle(c.Context(), bundleID, userUUID)
+	policy, err := h.policyService.PublishPolicy(c.UserContext(), policyID, userUUID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to activate bundle",
-				"code":    "BUNDLE_ACTIVATION_FAILED",
-				"details": err.Error(),
-			},
-		})
+		return apperrors.Wrap(err, "POLICY_PUBLISH_FAILED", "Failed to publish policy")
 	}
 
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    bundle,
+		"data":    data,
 	})
 }
 
-// DeployBundle deploys a bundle
-// POST /v1/bundles/:id/deploy
-func (h *PolicyHandler) DeployBundle(c *fiber.Ctx) error {
-	bundleID, err := uuid.Parse(c.Params("id"))
+// ArchivePolicy archives a policy, warning when other policies import it
+// POST /v1/policies/:id/archive
+func (h *PolicyHandler) ArchivePolicy(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid bundle ID",
-				"code":    "INVALID_BUNDLE_ID",
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
 
 	userID := middleware.GetUserID(c)
 	userUUID, err := uuid.Parse(userID)
@@ -588,34 +538,789 @@ func (h *PolicyHandler) DeployBundle(c *fiber.Ctx) error {
 		})
 	}
 
-	var req struct {
-		Environment string `json:"environment"`
+	policy, warnings, err := h.policyService.ArchivePolicy(c.UserContext(), policyID, userUUID)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_ARCHIVE_FAILED", "Failed to archive policy")
 	}
-	if err := c.BodyParser(&req); err != nil {
-		req.Environment = "production"
+
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
 	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success":  true,
+		"data":     data,
+		"warnings": warnings,
+	})
+}
 
-	deployment, err := h.bundleService.DeployBundle(c.Context(), bundleID, userUUID, req.Environment)
+// GetPolicyDependencies returns the policies a policy imports and the policies importing it
+// GET /v1/policies/:id/dependencies
+func (h *PolicyHandler) GetPolicyDependencies(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to deploy bundle",
-				"code":    "BUNDLE_DEPLOY_FAILED",
-				"details": err.Error(),
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
 			},
 		})
 	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
+
+	dependencies, err := h.policyService.GetPolicyDependencies(c.UserContext(), policyID)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_DEPENDENCIES_FAILED", "Failed to get policy dependencies")
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    deployment,
+		"data":    dependencies,
 	})
 }
 
-// DeleteBundle deletes a bundle
-// DELETE /v1/bundles/:id
-func (h *PolicyHandler) DeleteBundle(c *fiber.Ctx) error {
+// ValidatePolicy validates a policy
+// POST /v1/policies/:id/validate
+func (h *PolicyHandler) ValidatePolicy(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
+			},
+		})
+	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
+
+	if err := h.policyService.ValidatePolicy(c.UserContext(), policyID); err != nil {
+		return apperrors.Wrap(err, "POLICY_VALIDATION_FAILED", "Policy validation failed")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Policy is valid",
+	})
+}
+
+// TestPolicy tests a policy
+// POST /v1/policies/:id/test
+func (h *PolicyHandler) TestPolicy(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
+			},
+		})
+	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
+
+	results, err := h.policyService.TestPolicy(c.UserContext(), policyID)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_TEST_FAILED", "Policy test failed")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    results,
+	})
+}
+
+// GetPolicyVersions retrieves all versions of a policy
+// GET /v1/policies/:id/versions
+func (h *PolicyHandler) GetPolicyVersions(c *fiber.Ctx) error {
+	policyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid policy ID",
+				"code":    "INVALID_POLICY_ID",
+			},
+		})
+	}
+	if err := h.authorizePolicy(c, policyID); err != nil {
+		return err
+	}
+
+	versions, err := h.policyService.GetPolicyVersions(c.UserContext(), policyID)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_VERSIONS_FAILED", "Failed to get policy versions")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    versions,
+		"count":   len(versions),
+	})
+}
+
+// --- Bundle Endpoints ---
+
+// SyncPolicies creates, updates and optionally deletes the tenant's policies to match a set of files
+// POST /v1/policies/sync
+func (h *PolicyHandler) SyncPolicies(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	var req service.SyncPoliciesRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid user ID",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_TENANT_ID",
+			},
+		})
+	}
+
+	result, err := h.policyService.SyncPolicies(c.UserContext(), tenantUUID, userUUID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_SYNC_FAILED", "Failed to sync policies")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ExportPolicies exports the tenant's policies as files
+// GET /v1/policies/export
+func (h *PolicyHandler) ExportPolicies(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_TENANT_ID",
+			},
+		})
+	}
+
+	files, err := h.policyService.ExportPolicies(c.UserContext(), tenantUUID)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_EXPORT_FAILED", "Failed to export policies")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"files": files,
+		},
+	})
+}
+
+// ListPolicyTemplates lists the library of policy templates
+// GET /v1/policy-templates
+func (h *PolicyHandler) ListPolicyTemplates(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    h.policyService.ListPolicyTemplates(),
+	})
+}
+
+// CreatePolicyFromTemplate creates a draft policy from a policy template
+// POST /v1/policies/from-template
+func (h *PolicyHandler) CreatePolicyFromTemplate(c *fiber.Ctx) error {
+	tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	userUUID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req service.CreatePolicyFromTemplateRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+	req.TenantID = tenantUUID
+
+	policy, err := h.policyService.CreatePolicyFromTemplate(c.UserContext(), userUUID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_CREATION_FAILED", "Failed to create policy")
+	}
+
+	data, err := redact(c, policy)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// CompilePolicyRules compiles the rules of a JSON policy into Rego without saving them
+// POST /v1/policies/compile
+func (h *PolicyHandler) CompilePolicyRules(c *fiber.Ctx) error {
+	var req service.PolicyRuleSet
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	content, err := req.Compile()
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_COMPILE_FAILED", "Failed to compile policy rules")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"content": content,
+		},
+	})
+}
+
+// RunTestSuite runs the test cases of a set of policies without saving them.
+// Failing suites are answered with 422, so CI steps calling it fail with them.
+// POST /v1/policies/test-suite
+func (h *PolicyHandler) RunTestSuite(c *fiber.Ctx) error {
+	var req service.PolicyTestSuiteRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.policyService.RunTestSuite(c.UserContext(), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_TEST_SUITE_FAILED", "Failed to run policy test suite")
+	}
+
+	if !result.Passed {
+		message := fmt.Sprintf("%d of %d policy tests failed", result.Failed, result.Total)
+		if result.ExitCode == service.TestSuiteInvalid {
+			message = "Policies of the suite did not compile; " + message
+		}
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"data":    result,
+			"error": fiber.Map{
+				"message": message,
+				"code":    "POLICY_TESTS_FAILED",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// FormatPolicy formats Rego with opa fmt without saving it
+// POST /v1/policies/format
+func (h *PolicyHandler) FormatPolicy(c *fiber.Ctx) error {
+	var req service.PolicyContentRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.policyService.FormatPolicy(c.UserContext(), req.Content)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_FORMAT_FAILED", "Failed to format policy")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// LintPolicy checks Rego for style and safety issues without saving it
+// POST /v1/policies/lint
+func (h *PolicyHandler) LintPolicy(c *fiber.Ctx) error {
+	var req service.PolicyContentRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"issues": service.LintPolicy(req.Content),
+		},
+	})
+}
+
+// CreateBundle creates a new policy bundle
+// POST /v1/bundles
+func (h *PolicyHandler) CreateBundle(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid user ID",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	var req service.CreateBundleRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	// Global bundles and bundles of other tenants are built by platform admins
+	if !middleware.IsPlatformScope(c) {
+		if req.IsGlobal {
+			return apperrors.Forbidden("PLATFORM_SCOPE_REQUIRED", "Global bundles require a platform-scoped token")
+		}
+		tenantUUID, err := uuid.Parse(middleware.GetTenantID(c))
+		if err != nil {
+			return apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
+		}
+		if req.TenantID != nil && *req.TenantID != tenantUUID {
+			return apperrors.Forbidden("TENANT_ISOLATION_VIOLATION", "Access denied: cannot access resources from another tenant")
+		}
+		req.TenantID = &tenantUUID
+	}
+
+	bundle, err := h.bundleService.CreateBundle(c.UserContext(), userUUID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_CREATION_FAILED", "Failed to create bundle")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    bundle,
+		"message": "Bundle is being built",
+	})
+}
+
+// ListBundles lists all bundles
+// GET /v1/bundles
+func (h *PolicyHandler) ListBundles(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	var tenantUUID *uuid.UUID
+	if tenantID != "" {
+		parsed, err := uuid.Parse(tenantID)
+		if err == nil {
+			tenantUUID = &parsed
+		}
+	}
+
+	params, err := pagination.Parse(c.Query, service.BundleListOptions)
+	if err != nil {
+		return err
+	}
+
+	bundles, page, err := h.bundleService.ListBundles(c.UserContext(), tenantUUID, params)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_LIST_FAILED", "Failed to list bundles")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"bundles":    bundles,
+			"pagination": page,
+		},
+	})
+}
+
+// authorizePolicy confines tokens without platform scope to the policies of
+// their own tenant, as CreateBundle does for bundles. Global policies, which
+// have no tenant, require platform scope.
+func (h *PolicyHandler) authorizePolicy(c *fiber.Ctx, policyID uuid.UUID) error {
+	if middleware.IsPlatformScope(c) {
+		return nil
+	}
+	tenantID, err := h.policyService.PolicyTenant(c.UserContext(), policyID)
+	if err != nil {
+		return apperrors.Wrap(err, "POLICY_RETRIEVAL_FAILED", "Failed to retrieve policy")
+	}
+	return authorizeTenantResource(c, tenantID, "Global policies require a platform-scoped token")
+}
+
+// authorizeBundle confines tokens without platform scope to the bundles of
+// their own tenant. Global bundles apply to every tenant, so they may be read,
+// but only platform admins change them.
+func (h *PolicyHandler) authorizeBundle(c *fiber.Ctx, bundleID uuid.UUID, write bool) error {
+	if middleware.IsPlatformScope(c) {
+		return nil
+	}
+	tenantID, global, err := h.bundleService.BundleTenant(c.UserContext(), bundleID)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_RETRIEVAL_FAILED", "Failed to retrieve bundle")
+	}
+	if global {
+		if write {
+			return apperrors.Forbidden("PLATFORM_SCOPE_REQUIRED", "Global bundles require a platform-scoped token")
+		}
+		return nil
+	}
+	return authorizeTenantResource(c, tenantID, "Global bundles require a platform-scoped token")
+}
+
+// authorizeTenantResource rejects a resource of another tenant, or one of no
+// tenant, for a token without platform scope
+func authorizeTenantResource(c *fiber.Ctx, tenantID uuid.UUID, globalMessage string) error {
+	if tenantID == uuid.Nil {
+		return apperrors.Forbidden("PLATFORM_SCOPE_REQUIRED", globalMessage)
+	}
+	if tenantID.String() != middleware.GetTenantID(c) {
+		return apperrors.Forbidden("TENANT_ISOLATION_VIOLATION", "Access denied: cannot access resources from another tenant")
+	}
+	return nil
+}
+
+// GetBundle retrieves a specific bundle
+// GET /v1/bundles/:id
+func (h *PolicyHandler) GetBundle(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, false); err != nil {
+		return err
+	}
+
+	bundle, err := h.bundleService.GetBundle(c.UserContext(), bundleID)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_RETRIEVAL_FAILED", "Failed to retrieve bundle")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    bundle,
+	})
+}
+
+// DownloadBundle streams a built bundle through the API. A single byte range
+// may be requested with the Range header, so large downloads can be resumed.
+// GET /v1/bundles/:id/download
+func (h *PolicyHandler) DownloadBundle(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, false); err != nil {
+		return err
+	}
+
+	bundle, object, err := h.bundleService.DownloadBundle(c.UserContext(), bundleID)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_DOWNLOAD_FAILED", "Failed to download bundle")
+	}
+
+	size, err := object.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = object.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		object.Close()
+		return apperrors.Wrap(err, "BUNDLE_DOWNLOAD_FAILED", "Failed to download bundle")
+	}
+
+	etag := `"` + bundle.Checksum + `"`
+	c.Set(fiber.HeaderContentType, "application/gzip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, path.Base(bundle.StoragePath)))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderETag, etag)
+
+	// A range is only served from the same bundle the client started with
+	ifRange := c.Get(fiber.HeaderIfRange)
+	if c.Get(fiber.HeaderRange) == "" || (ifRange != "" && ifRange != etag) {
+		return c.SendStream(object, int(size))
+	}
+
+	ranges, err := c.Range(int(size))
+	if errors.Is(err, fiber.ErrRangeUnsatisfiable) {
+		object.Close()
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", size))
+		return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Requested range is not satisfiable",
+				"code":    "RANGE_NOT_SATISFIABLE",
+			},
+		})
+	}
+	// Malformed, non-byte and multipart ranges are answered with the whole bundle
+	if err != nil || ranges.Type != "bytes" || len(ranges.Ranges) != 1 {
+		return c.SendStream(object, int(size))
+	}
+
+	start, end := int64(ranges.Ranges[0].Start), int64(ranges.Ranges[0].End)
+	if _, err := object.Seek(start, io.SeekStart); err != nil {
+		object.Close()
+		return apperrors.Wrap(err, "BUNDLE_DOWNLOAD_FAILED", "Failed to download bundle")
+	}
+	c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	c.Status(fiber.StatusPartialContent)
+	// Keep the object closable once the range has been sent
+	return c.SendStream(struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(object, end-start+1), object}, int(end-start+1))
+}
+
+// GetBundleDownloadURL returns a time-limited presigned URL downloading a built
+// bundle directly from storage. The optional expiresIn query parameter sets its
+// lifetime in seconds.
+// GET /v1/bundles/:id/download-url
+func (h *PolicyHandler) GetBundleDownloadURL(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, false); err != nil {
+		return err
+	}
+
+	expiry := service.DefaultBundleDownloadURLExpiry
+	if expiresIn := c.Query("expiresIn"); expiresIn != "" {
+		seconds, err := strconv.Atoi(expiresIn)
+		if err != nil {
+			return apperrors.Validation("INVALID_EXPIRY", "expiresIn must be a number of seconds")
+		}
+		expiry = time.Duration(seconds) * time.Second
+	}
+
+	download, err := h.bundleService.BundleDownloadURL(c.UserContext(), bundleID, expiry)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_DOWNLOAD_URL_FAILED", "Failed to create bundle download URL")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    download,
+	})
+}
+
+// ListBundleDeployments lists the deployments of a bundle, newest first, with
+// the manifest revision each one shipped
+// GET /v1/bundles/:id/deployments
+func (h *PolicyHandler) ListBundleDeployments(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, false); err != nil {
+		return err
+	}
+
+	deployments, err := h.bundleService.GetBundleDeployments(c.UserContext(), bundleID)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_DEPLOYMENTS_FAILED", "Failed to list bundle deployments")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    deployments,
+	})
+}
+
+// ListBundleContents lists the files inside a built bundle with their sizes and checksums
+// GET /v1/bundles/:id/contents
+func (h *PolicyHandler) ListBundleContents(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, false); err != nil {
+		return err
+	}
+
+	contents, err := h.bundleService.ListBundleContents(c.UserContext(), bundleID)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_CONTENTS_FAILED", "Failed to list bundle contents")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    contents,
+	})
+}
+
+// GetBundleFile returns a single file inside a built bundle, with its SHA-256 checksum as ETag
+// GET /v1/bundles/:id/contents/*
+func (h *PolicyHandler) GetBundleFile(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, false); err != nil {
+		return err
+	}
+
+	filePath, err := url.PathUnescape(c.Params("*"))
+	if err != nil || filePath == "" {
+		return apperrors.Validation("INVALID_FILE_PATH", "Invalid bundle file path")
+	}
+
+	file, data, err := h.bundleService.GetBundleFile(c.UserContext(), bundleID, filePath)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_FILE_RETRIEVAL_FAILED", "Failed to retrieve bundle file")
+	}
+
+	contentType := fiber.MIMEOctetStream
+	switch {
+	case strings.HasSuffix(file.Path, ".rego"):
+		contentType = fiber.MIMETextPlainCharsetUTF8
+	case strings.HasSuffix(file.Path, ".json"), path.Base(file.Path) == ".manifest":
+		contentType = fiber.MIMEApplicationJSONCharsetUTF8
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderETag, `"`+file.Checksum+`"`)
+	return c.Status(fiber.StatusOK).Send(data)
+}
+
+// ActivateBundle activates a bundle
+// POST /v1/bundles/:id/activate
+func (h *PolicyHandler) ActivateBundle(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
+			},
+		})
+	}
+	if err := h.authorizeBundle(c, bundleID, true); err != nil {
+		return err
+	}
+
+	userID := middleware.GetUserID(c)
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid user ID",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	bundle, err := h.bundleService.ActivateBundle(c.UserContext(), bundleID, userUUID)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_ACTIVATION_FAILED", "Failed to activate bundle")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    bundle,
+	})
+}
+
+// DeployBundle deploys a bundle
+// POST /v1/bundles/:id/deploy
+func (h *PolicyHandler) DeployBundle(c *fiber.Ctx) error {
 	bundleID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -626,17 +1331,66 @@ func (h *PolicyHandler) DeleteBundle(c *fiber.Ctx) error {
 			},
 		})
 	}
+	if err := h.authorizeBundle(c, bundleID, true); err != nil {
+		return err
+	}
+
+	userID := middleware.GetUserID(c)
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid user ID",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	var req struct {
+		Environment string `json:"environment" validate:"omitempty,max=100"`
+	}
+	// The body is optional; deployments target production by default
+	if len(c.Body()) > 0 {
+		if err := bindRequest(c, &req); err != nil {
+			return err
+		}
+	}
+	if req.Environment == "" {
+		req.Environment = "production"
+	}
+
+	deployment, err := h.bundleService.DeployBundle(c.UserContext(), bundleID, userUUID, req.Environment)
+	if err != nil {
+		return apperrors.Wrap(err, "BUNDLE_DEPLOY_FAILED", "Failed to deploy bundle")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    deployment,
+	})
+}
 
-	if err := h.bundleService.DeleteBundle(c.Context(), bundleID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+// DeleteBundle deletes a bundle
+// DELETE /v1/bundles/:id
+func (h *PolicyHandler) DeleteBundle(c *fiber.Ctx) error {
+	bundleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to delete bundle",
-				"code":    "BUNDLE_DELETE_FAILED",
-				"details": err.Error(),
+				"message": "Invalid bundle ID",
+				"code":    "INVALID_BUNDLE_ID",
 			},
 		})
 	}
+	if err := h.authorizeBundle(c, bundleID, true); err != nil {
+		return err
+	}
+
+	if err := h.bundleService.DeleteBundle(c.UserContext(), bundleID); err != nil {
+		return apperrors.Wrap(err, "BUNDLE_DELETE_FAILED", "Failed to delete bundle")
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
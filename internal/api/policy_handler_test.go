@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestPolicyHandler_TenantScope(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		user := testutil.CreateTestUser(t, db, acme, "admin@acme.com")
+
+		createPolicy := func(db *gorm.DB, tenantID uuid.UUID, path string) *models.Policy {
+			policy := &models.Policy{TenantID: tenantID, Name: path, Path: path, Content: "package " + path, CreatedBy: user.ID, UpdatedBy: user.ID}
+			if err := db.Create(policy).Error; err != nil {
+				t.Fatalf("Failed to create policy: %v", err)
+			}
+			return policy
+		}
+		own := createPolicy(db, acme.ID, "acme")
+		other := createPolicy(db, globex.ID, "globex")
+
+		// The schema gives every policy a tenant, so one without, standing in
+		// for a global policy, is written with the foreign keys turned off
+		if !database.IsSQLite(db) {
+			t.Skip("Global policies are only written to SQLite test databases")
+		}
+		var global *models.Policy
+		err := db.Connection(func(conn *gorm.DB) error {
+			if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+				return err
+			}
+			defer conn.Exec("PRAGMA foreign_keys = ON")
+			global = createPolicy(conn, uuid.Nil, "global")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to turn off foreign keys: %v", err)
+		}
+
+		bundle := func(tenantID uuid.UUID, global bool) *models.PolicyBundle {
+			bundle := &models.PolicyBundle{TenantID: tenantID, IsGlobal: global, Name: "release", Version: "1.0.0", Status: models.BundleStatusReady, CreatedBy: user.ID, UpdatedBy: user.ID}
+			if err := db.Create(bundle).Error; err != nil {
+				t.Fatalf("Failed to create bundle: %v", err)
+			}
+			return bundle
+		}
+		otherBundle := bundle(globex.ID, false)
+		globalBundle := bundle(uuid.Nil, true)
+
+		handler := NewPolicyHandler(service.NewPolicyService(db, nil), service.NewBundleService(db, nil, service.NewJobQueue(db, nil)))
+		newApp := func(scope string) *fiber.App {
+			app := testutil.CreateTestApp()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("userID", user.ID.String())
+				c.Locals("tenantID", acme.ID.String())
+				c.Locals("accessScope", scope)
+				return c.Next()
+			})
+			app.Get("/v1/policies/:id", handler.GetPolicy)
+			app.Put("/v1/policies/:id", handler.UpdatePolicy)
+			app.Delete("/v1/policies/:id", handler.DeletePolicy)
+			app.Get("/v1/bundles/:id", handler.GetBundle)
+			app.Delete("/v1/bundles/:id", handler.DeleteBundle)
+			return app
+		}
+		tenantApp, platformApp := newApp(auth.AccessScopeTenant), newApp(auth.AccessScopePlatform)
+
+		resp := testutil.MakeRequest(t, tenantApp, "GET", "/v1/policies/"+own.ID.String(), nil, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		// Tenant-scoped tokens cannot act on global policies or other tenants' ones
+		tests := []struct {
+			method, path, code string
+		}{
+			{"GET", "/v1/policies/" + global.ID.String(), "PLATFORM_SCOPE_REQUIRED"},
+			{"PUT", "/v1/policies/" + global.ID.String(), "PLATFORM_SCOPE_REQUIRED"},
+			{"DELETE", "/v1/policies/" + global.ID.String(), "PLATFORM_SCOPE_REQUIRED"},
+			{"GET", "/v1/policies/" + other.ID.String(), "TENANT_ISOLATION_VIOLATION"},
+			{"DELETE", "/v1/policies/" + other.ID.String(), "TENANT_ISOLATION_VIOLATION"},
+			{"GET", "/v1/bundles/" + otherBundle.ID.String(), "TENANT_ISOLATION_VIOLATION"},
+			{"DELETE", "/v1/bundles/" + globalBundle.ID.String(), "PLATFORM_SCOPE_REQUIRED"},
+		}
+		for _, tt := range tests {
+			resp := testutil.MakeRequest(t, tenantApp, tt.method, tt.path, map[string]string{"description": "changed"}, nil)
+			testutil.AssertStatusCode(t, http.StatusForbidden, resp.Code)
+			testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), tt.code)
+		}
+		var unchanged models.Policy
+		if err := db.First(&unchanged, "id = ?", global.ID).Error; err != nil || unchanged.Description != "" {
+			t.Errorf("Expected the global policy to be left alone, got %+v, %v", unchanged, err)
+		}
+
+		// Global bundles apply to every tenant, so they may be read
+		resp = testutil.MakeRequest(t, tenantApp, "GET", "/v1/bundles/"+globalBundle.ID.String(), nil, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		resp = testutil.MakeRequest(t, tenantApp, "GET", "/v1/policies/"+uuid.NewString(), nil, nil)
+		testutil.AssertStatusCode(t, http.StatusNotFound, resp.Code)
+
+		// Platform admins act on every tenant's policies and global ones
+		for _, id := range []uuid.UUID{global.ID, other.ID} {
+			resp = testutil.MakeRequest(t, platformApp, "GET", "/v1/policies/"+id.String(), nil, nil)
+			testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+		}
+	})
+}
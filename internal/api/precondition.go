@@ -0,0 +1,22 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// preconditionFrom reads the optimistic concurrency headers of an upsert request
+func preconditionFrom(c *fiber.Ctx) service.Precondition {
+	return service.Precondition{
+		IfMatch:     c.Get(fiber.HeaderIfMatch),
+		IfNoneMatch: c.Get(fiber.HeaderIfNoneMatch),
+	}
+}
+
+// upsertStatus returns 201 Created for new resources and 200 OK for replaced ones
+func upsertStatus(created bool) int {
+	if created {
+		return fiber.StatusCreated
+	}
+	return fiber.StatusOK
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// RateLimitHandler handles tenant rate limit endpoints
+type RateLimitHandler struct {
+	rateLimitService *service.RateLimitService
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(rateLimitService *service.RateLimitService) *RateLimitHandler {
+	return &RateLimitHandler{
+		rateLimitService: rateLimitService,
+	}
+}
+
+// GetLimits retrieves a tenant's quotas
+// GET /v1/tenants/:tenantId/rate-limits
+func (h *RateLimitHandler) GetLimits(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	limits, err := h.rateLimitService.GetLimits(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "RATE_LIMITS_RETRIEVAL_FAILED", "Failed to retrieve rate limits")
+	}
+
+	c.Set(fiber.HeaderETag, limits.ETag)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    limits,
+	})
+}
+
+// UpsertLimits creates or replaces a tenant's quotas. If-Match and
+// If-None-Match headers make the request conditional on the current ETag.
+// PUT /v1/tenants/:tenantId/rate-limits
+func (h *RateLimitHandler) UpsertLimits(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpsertRateLimitsRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	limits, created, err := h.rateLimitService.UpsertLimits(c.UserContext(), tenantID, &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "RATE_LIMITS_UPSERT_FAILED", "Failed to save rate limits")
+	}
+
+	c.Set(fiber.HeaderETag, limits.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    limits,
+	})
+}
+
+// DeleteLimits removes a tenant's quotas
+// DELETE /v1/tenants/:tenantId/rate-limits
+func (h *RateLimitHandler) DeleteLimits(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.rateLimitService.DeleteLimits(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "RATE_LIMITS_DELETION_FAILED", "Failed to delete rate limits")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Rate limits deleted successfully",
+	})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+)
+
+// redact removes the fields masked by the policy decisions that allowed the
+// request from a resource, or from each resource of a list, before it is
+// returned. Resources are returned as they are when no fields are masked.
+func redact(c *fiber.Ctx, resource interface{}) (interface{}, error) {
+	fields := middleware.GetFieldMask(c)
+	if len(fields) == 0 {
+		return resource, nil
+	}
+	return redactFields(resource, fields)
+}
+
+// redactFields removes fields, named by dotted JSON paths such as
+// metadata.ssn, from the JSON form of a resource or of each resource of a list
+func redactFields(resource interface{}, fields []string) (interface{}, error) {
+	encoded, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource for redaction: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode resource for redaction: %w", err)
+	}
+
+	for _, field := range fields {
+		removeField(value, strings.Split(field, "."))
+	}
+	return value, nil
+}
+
+// removeField deletes a path from a decoded JSON object, or from each object of
+// a list, descending into the lists along the path, e.g. roles.permissions
+func removeField(value interface{}, path []string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(value, path[0])
+			return
+		}
+		removeField(value[path[0]], path[1:])
+	case []interface{}:
+		for _, item := range value {
+			removeField(item, path)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+func TestRedact(t *testing.T) {
+	users := []service.UserProfile{{
+		ID:        "u-1",
+		Email:     "alice@acme.com",
+		Metadata:  map[string]interface{}{"department": "sales", "ssn": "123-45-6789"},
+		Roles:     []string{"user"},
+		Status:    "active",
+		CreatedAt: "2024-01-15T10:30:00Z",
+	}}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Get("/", func(c *fiber.Ctx) error {
+		if fields := c.Query("mask"); fields != "" {
+			c.Locals("opaObligations", []opa.Directive{
+				{Type: "log_elevated"},
+				{Type: opa.ObligationMaskFields, Parameters: map[string]interface{}{"fields": []interface{}{fields}}},
+			})
+		}
+		data, err := redact(c, users)
+		if err != nil {
+			return err
+		}
+		return c.JSON(data)
+	})
+
+	tests := []struct {
+		name string
+		mask string
+		want string
+	}{
+		{"no mask", "", `[{"id":"u-1","email":"alice@acme.com","tenantId":"","metadata":{"department":"sales","ssn":"123-45-6789"},"roles":["user"],"status":"active","loginCount":0,"createdAt":"2024-01-15T10:30:00Z"}]`},
+		{"top-level field", "email", `[{"createdAt":"2024-01-15T10:30:00Z","id":"u-1","loginCount":0,"metadata":{"department":"sales","ssn":"123-45-6789"},"roles":["user"],"status":"active","tenantId":""}]`},
+		{"nested field", "metadata.ssn", `[{"createdAt":"2024-01-15T10:30:00Z","email":"alice@acme.com","id":"u-1","loginCount":0,"metadata":{"department":"sales"},"roles":["user"],"status":"active","tenantId":""}]`},
+		{"missing field", "profile.phone", `[{"createdAt":"2024-01-15T10:30:00Z","email":"alice@acme.com","id":"u-1","loginCount":0,"metadata":{"department":"sales","ssn":"123-45-6789"},"roles":["user"],"status":"active","tenantId":""}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/?mask="+tt.mask, nil))
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("Got %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
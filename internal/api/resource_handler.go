@@ -0,0 +1,133 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// ResourceHandler handles the resource registry endpoints. Resources belong to
+// the caller's tenant.
+type ResourceHandler struct {
+	resourceService *service.ResourceService
+}
+
+// NewResourceHandler creates a new resource handler
+func NewResourceHandler(resourceService *service.ResourceService) *ResourceHandler {
+	return &ResourceHandler{
+		resourceService: resourceService,
+	}
+}
+
+// ListResources retrieves the registered resources of the caller's tenant,
+// optionally filtered by type, owner and label
+// GET /v1/resources?type=documents&ownerId=...&label=env=prod
+func (h *ResourceHandler) ListResources(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	params, err := pagination.Parse(c.Query, service.ResourceListOptions)
+	if err != nil {
+		return err
+	}
+
+	filter := service.ResourceFilter{
+		Type:    c.Query("type"),
+		OwnerID: c.Query("ownerId"),
+		Label:   c.Query("label"),
+	}
+	resources, page, err := h.resourceService.ListResources(c.UserContext(), tenantID, filter, params)
+	if err != nil {
+		return apperrors.Wrap(err, "RESOURCE_LIST_FAILED", "Failed to retrieve resources")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"resources":  resources,
+			"pagination": page,
+		},
+	})
+}
+
+// GetResource retrieves a resource. Resources of Heimdall's own types, such as
+// users and policies, are derived from the database.
+// GET /v1/resources/:resourceType/:resourceId
+func (h *ResourceHandler) GetResource(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	resource, err := h.resourceService.GetResource(c.UserContext(), tenantID, c.Params("resourceType"), c.Params("resourceId"))
+	if err != nil {
+		return apperrors.Wrap(err, "RESOURCE_RETRIEVAL_FAILED", "Failed to retrieve resource")
+	}
+
+	if !resource.Derived {
+		c.Set(fiber.HeaderETag, resource.ETag)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    resource,
+	})
+}
+
+// UpsertResource registers a resource or replaces its owner, labels and
+// attributes. If-Match and If-None-Match headers make the request conditional
+// on the current ETag.
+// PUT /v1/resources/:resourceType/:resourceId
+func (h *ResourceHandler) UpsertResource(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.UpsertResourceRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	resource, created, err := h.resourceService.UpsertResource(c.UserContext(), tenantID, c.Params("resourceType"), c.Params("resourceId"), &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "RESOURCE_UPSERT_FAILED", "Failed to save resource")
+	}
+
+	c.Set(fiber.HeaderETag, resource.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    resource,
+	})
+}
+
+// DeleteResource removes a registered resource
+// DELETE /v1/resources/:resourceType/:resourceId
+func (h *ResourceHandler) DeleteResource(c *fiber.Ctx) error {
+	tenantID, err := h.tenantID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.resourceService.DeleteResource(c.UserContext(), tenantID, c.Params("resourceType"), c.Params("resourceId")); err != nil {
+		return apperrors.Wrap(err, "RESOURCE_DELETION_FAILED", "Failed to delete resource")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Resource deleted successfully",
+	})
+}
+
+// tenantID returns the caller's tenant
+func (h *ResourceHandler) tenantID(c *fiber.Ctx) (uuid.UUID, error) {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return uuid.Nil, apperrors.Validation("INVALID_REQUEST", "Tenant ID is required")
+	}
+	return tenantID, nil
+}
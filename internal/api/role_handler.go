@@ -0,0 +1,164 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// RoleHandler handles role and permission management endpoints
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// GetRole retrieves a role of the current tenant by name
+// GET /v1/roles/:name
+func (h *RoleHandler) GetRole(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	role, err := h.roleService.GetRole(c.UserContext(), tenantID, c.Params("name"))
+	if err != nil {
+		return apperrors.Wrap(err, "ROLE_RETRIEVAL_FAILED", "Failed to retrieve role")
+	}
+
+	c.Set(fiber.HeaderETag, role.ETag)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    role,
+	})
+}
+
+// UpsertRole creates or replaces a role of the current tenant by name, including its
+// permissions. If-Match and If-None-Match headers make the request conditional on
+// the role's current ETag.
+// PUT /v1/roles/:name
+func (h *RoleHandler) UpsertRole(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	userID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req service.UpsertRoleRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	role, created, err := h.roleService.UpsertRole(c.UserContext(), tenantID, c.Params("name"), &req, preconditionFrom(c), userID)
+	if err != nil {
+		return apperrors.Wrap(err, "ROLE_UPSERT_FAILED", "Failed to save role")
+	}
+
+	c.Set(fiber.HeaderETag, role.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    role,
+	})
+}
+
+// DeleteRole deletes a role of the current tenant by name
+// DELETE /v1/roles/:name
+func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(middleware.GetTenantID(c))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Tenant context is required",
+				"code":    "TENANT_REQUIRED",
+			},
+		})
+	}
+
+	if err := h.roleService.DeleteRole(c.UserContext(), tenantID, c.Params("name")); err != nil {
+		return apperrors.Wrap(err, "ROLE_DELETION_FAILED", "Failed to delete role")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Role deleted successfully",
+	})
+}
+
+// GetPermission retrieves a permission by name
+// GET /v1/permissions/:name
+func (h *RoleHandler) GetPermission(c *fiber.Ctx) error {
+	permission, err := h.roleService.GetPermission(c.UserContext(), c.Params("name"))
+	if err != nil {
+		return apperrors.Wrap(err, "PERMISSION_RETRIEVAL_FAILED", "Failed to retrieve permission")
+	}
+
+	c.Set(fiber.HeaderETag, service.ResourceETag(permission.UpdatedAt))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    permission,
+	})
+}
+
+// UpsertPermission creates or replaces a permission by name. If-Match and
+// If-None-Match headers make the request conditional on the permission's current ETag.
+// PUT /v1/permissions/:name
+func (h *RoleHandler) UpsertPermission(c *fiber.Ctx) error {
+	var req service.UpsertPermissionRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	permission, created, err := h.roleService.UpsertPermission(c.UserContext(), c.Params("name"), &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "PERMISSION_UPSERT_FAILED", "Failed to save permission")
+	}
+
+	c.Set(fiber.HeaderETag, service.ResourceETag(permission.UpdatedAt))
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    permission,
+	})
+}
+
+// DeletePermission deletes a permission by name
+// DELETE /v1/permissions/:name
+func (h *RoleHandler) DeletePermission(c *fiber.Ctx) error {
+	if err := h.roleService.DeletePermission(c.UserContext(), c.Params("name")); err != nil {
+		return apperrors.Wrap(err, "PERMISSION_DELETION_FAILED", "Failed to delete permission")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Permission deleted successfully",
+	})
+}
@@ -1,146 +1,680 @@
 package api
 
 import (
+	"reflect"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/middleware"
 	"github.com/techsavvyash/heimdall/internal/opa"
 )
 
-// SetupRoutes configures all API routes
-func SetupRoutes(app *fiber.App, authHandler *AuthHandler, userHandler *UserHandler, passwordHandler *PasswordHandler, tenantHandler *TenantHandler, policyHandler *PolicyHandler, jwtService *auth.JWTService, evaluator *opa.Evaluator) {
+// Handlers groups all API handlers registered by SetupRoutes
+type Handlers struct {
+	Auth           *AuthHandler
+	User           *UserHandler
+	Password       *PasswordHandler
+	Tenant         *TenantHandler
+	Policy         *PolicyHandler
+	Role           *RoleHandler
+	Authz          *AuthzHandler
+	SigningKey     *SigningKeyHandler
+	SAML           *SAMLHandler
+	ClaimsTemplate *ClaimsTemplateHandler
+	OAuth          *OAuthHandler
+	RateLimit      *RateLimitHandler
+	IPAccess       *IPAccessHandler
+	UserAttribute  *UserAttributeHandler
+	Audit          *AuditHandler
+	Analytics      *AnalyticsHandler
+	Overview       *OverviewHandler
+	OPAInstance    *OPAInstanceHandler
+	Resource       *ResourceHandler
+	AccessRequest  *AccessRequestHandler
+	AlertRule      *AlertRuleHandler
+	ActionNonce    *ActionNonceHandler
+	MagicLink      *MagicLinkHandler
+	Job            *JobHandler
+	BodyLogging    *BodyLoggingHandler
+	GitSync        *GitSyncHandler    // Optional, nil when Git policy sync is not configured
+	BreakGlass     *BreakGlassHandler // Optional, nil when break-glass access is not configured
+}
+
+// SetupRoutes configures all API routes, management routes being guarded by
+// the permissions of the admin_api system policy
+func SetupRoutes(app *fiber.App, h *Handlers, jwtService *auth.JWTService, evaluator *opa.Evaluator, permissions *opa.RoutePermissions) {
 	// API v1 group
 	v1 := app.Group("/v1")
 
 	// Public routes (no authentication required)
-	setupPublicRoutes(v1, authHandler)
+	setupPublicRoutes(v1, h)
 
 	// Protected routes (authentication required)
-	setupProtectedRoutes(v1, authHandler, userHandler, passwordHandler, tenantHandler, policyHandler, jwtService, evaluator)
+	setupProtectedRoutes(v1, h, jwtService, evaluator, permissions)
+}
+
+// Routes returns the routes SetupRoutes registers, with every optional handler
+// configured, e.g. to check them against the OpenAPI spec. The handlers are not
+// usable, so the routes must not be served.
+func Routes() []fiber.Route {
+	// Zero handlers are enough to register their methods
+	h := &Handlers{}
+	fields := reflect.ValueOf(h).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		fields.Field(i).Set(reflect.New(fields.Field(i).Type().Elem()))
+	}
+
+	app := fiber.New()
+	SetupRoutes(app, h, nil, nil, nil)
+	return app.GetRoutes(true)
 }
 
 // setupPublicRoutes configures public routes
-func setupPublicRoutes(v1 fiber.Router, authHandler *AuthHandler) {
+func setupPublicRoutes(v1 fiber.Router, h *Handlers) {
 	auth := v1.Group("/auth")
 
 	// Authentication endpoints
-	auth.Post("/register", authHandler.Register)
-	auth.Post("/login", authHandler.Login)
-	auth.Post("/refresh", authHandler.RefreshToken)
+	auth.Post("/register", h.Auth.Register)
+	auth.Post("/login", h.Auth.Login)
+	auth.Post("/refresh", h.Auth.RefreshToken)
+
+	// Passwordless login by emailed single-use links
+	auth.Post("/magic-link", h.MagicLink.RequestLink)
+	auth.Post("/magic-link/verify", h.MagicLink.Redeem)
+
+	// Password resets with emailed tokens
+	auth.Post("/password/reset", h.Password.ForgotPassword)
+	auth.Post("/password/reset/confirm", h.Password.ResetPassword)
+
+	// OAuth token endpoint, authenticated by client credentials or a device code
+	v1.Post("/oauth/token", h.OAuth.Token)
+	v1.Post("/oauth/device/code", h.OAuth.DeviceCode)
+
+	// Key discovery endpoints
+	v1.Get("/.well-known/jwks.json", h.SigningKey.GetSharedJWKS)
+	v1.Get("/tenants/:tenantId/.well-known/jwks.json", h.SigningKey.GetTenantJWKS)
+
+	// Tenant branding and login methods, fetched by hosted login pages
+	v1.Get("/tenants/slug/:slug/public", h.Tenant.GetPublicTenant)
+
+	// Password policy, fetched by sign-up and password forms before submitting
+	v1.Get("/tenants/:tenantId/password-policy", h.Password.GetPasswordPolicy)
+
+	// SAML single sign-on endpoints, called by browsers and identity providers
+	saml := v1.Group("/saml/:tenantId")
+	saml.Get("/metadata", h.SAML.GetMetadata)
+	saml.Get("/login", h.SAML.Login)
+	saml.Post("/acs", h.SAML.ConsumeAssertion)
+
+	// Webhook endpoints (authenticated by signature)
+	if h.GitSync != nil {
+		v1.Post("/webhooks/git/policies", h.GitSync.HandlePush)
+	}
 }
 
 // setupProtectedRoutes configures routes that require authentication
-func setupProtectedRoutes(v1 fiber.Router, authHandler *AuthHandler, userHandler *UserHandler, passwordHandler *PasswordHandler, tenantHandler *TenantHandler, policyHandler *PolicyHandler, jwtService *auth.JWTService, evaluator *opa.Evaluator) {
-	// Apply authentication middleware
-	protected := v1.Use(middleware.AuthMiddleware(jwtService))
+func setupProtectedRoutes(v1 fiber.Router, h *Handlers, jwtService *auth.JWTService, evaluator *opa.Evaluator, permissions *opa.RoutePermissions) {
+	// Apply authentication middleware, accepting break-glass tokens first when
+	// emergency access is configured
+	if h.BreakGlass != nil {
+		v1.Use(middleware.BreakGlassMiddleware(h.BreakGlass.breakGlassService))
+	}
+	protected := v1.Use(
+		middleware.AuthMiddleware(jwtService),
+		middleware.TenantRateLimit(h.RateLimit.rateLimitService),
+		middleware.UserAttributesMiddleware(h.UserAttribute.userAttributeService),
+	)
+
+	// Self-service routes check no permission, so exchanged tokens limited to a
+	// scope cannot use them
+	unscoped := middleware.RequireUnscopedToken()
+
+	// Management routes (OPA-protected) require the permissions the admin_api
+	// system policy lists for them, which operators may change
+	guarded := middleware.RequireRoutePermissionsOPA(evaluator, permissions)
+
+	// Routes operating across tenants require a platform-scoped token; tenant
+	// admins are confined to their own tenant
+	platform := middleware.RequirePlatformScope()
+
+	// Sensitive administrative actions are recorded in the audit log, ahead of
+	// permission checks so denied attempts are recorded too
+	audit := func(action, resource, idParam string) fiber.Handler {
+		return middleware.AuditAdminAction(h.Audit.adminAuditService, action, resource, idParam)
+	}
+
+	// Destructive actions must be confirmed with a one-time nonce the user
+	// fetched for them, after permission checks so denied requests keep theirs
+	confirmed := func(action, idParam string) fiber.Handler {
+		return middleware.RequireActionNonce(h.ActionNonce.actionNonceService, action, idParam)
+	}
+
+	// Nonces confirming destructive actions, checked by the actions' routes
+	protected.Post("/action-nonces", h.ActionNonce.IssueNonce)
 
 	// Auth routes (authenticated)
 	authRoutes := protected.Group("/auth")
-	authRoutes.Post("/logout", authHandler.Logout)
-	authRoutes.Post("/logout-all", authHandler.LogoutAll)
-	authRoutes.Post("/password/change", passwordHandler.ChangePassword)
+	authRoutes.Post("/logout", unscoped, h.Auth.Logout)
+	authRoutes.Post("/logout-all", unscoped, h.Auth.LogoutAll)
+	authRoutes.Post("/password/change", unscoped, h.Password.ChangePassword)
+	authRoutes.Post("/token/exchange", h.Auth.ExchangeToken)
+
+	// Device verification, where users sign devices in as themselves
+	deviceRoutes := protected.Group("/oauth/device")
+	deviceRoutes.Get("/", unscoped, h.OAuth.GetDeviceAuthorization)
+	deviceRoutes.Post("/approve", unscoped, h.OAuth.ApproveDevice)
+	deviceRoutes.Post("/deny", unscoped, h.OAuth.DenyDevice)
 
 	// User routes
 	userRoutes := protected.Group("/users")
-	userRoutes.Get("/me", userHandler.GetMe)
-	userRoutes.Patch("/me", userHandler.UpdateMe)
-	userRoutes.Delete("/me", userHandler.DeleteMe)
-	userRoutes.Get("/me/permissions", userHandler.GetMyPermissions)
+	userRoutes.Get("/me", unscoped, h.User.GetMe)
+	userRoutes.Patch("/me", unscoped, h.User.UpdateMe)
+	userRoutes.Delete("/me", audit("users.delete", "users", ""), unscoped, h.User.DeleteMe)
+	userRoutes.Get("/me/permissions", unscoped, h.User.GetMyPermissions)
+	userRoutes.Get("/me/login-history", unscoped, h.User.GetMyLoginHistory)
+	userRoutes.Get("/me/access-requests", unscoped, h.AccessRequest.ListMyAccessRequests)
 
 	// Admin user routes (OPA-protected)
 	userRoutes.Get("/",
-		middleware.RequirePermissionOPA(evaluator, "users", "read"),
-		userHandler.ListUsers)
+		guarded,
+		middleware.FilterRowsOPA(evaluator, "users"),
+		h.User.ListUsers)
 	userRoutes.Get("/:userId",
-		middleware.RequirePermissionOPA(evaluator, "users", "read"),
-		userHandler.GetUserByID)
+		guarded,
+		h.User.GetUserByID)
+	userRoutes.Get("/:userId/roles",
+		guarded,
+		h.User.GetRoleAssignments)
 	userRoutes.Post("/:userId/roles",
-		middleware.RequirePermissionOPA(evaluator, "roles", "assign"),
-		userHandler.AssignRole)
+		audit("roles.assign", "users", "userId"),
+		guarded,
+		h.User.AssignRole)
 	userRoutes.Delete("/:userId/roles/:roleId",
-		middleware.RequirePermissionOPA(evaluator, "roles", "assign"),
-		userHandler.RemoveRole)
+		audit("roles.remove", "users", "userId"),
+		guarded,
+		h.User.RemoveRole)
+	userRoutes.Post("/:userId/elevations",
+		audit("roles.elevate", "users", "userId"),
+		guarded,
+		h.User.ElevateRole)
+	userRoutes.Post("/:userId/deactivate",
+		audit("users.deactivate", "users", "userId"),
+		guarded,
+		confirmed("users.deactivate", "userId"),
+		h.User.DeactivateUser)
+	userRoutes.Post("/:userId/restore",
+		audit("users.restore", "users", "userId"),
+		guarded,
+		h.User.RestoreUser)
+	userRoutes.Patch("/:userId/status",
+		audit("users.status", "users", "userId"),
+		guarded,
+		h.User.UpdateUserStatus)
+	userRoutes.Patch("/:userId/attributes",
+		audit("users.attributes", "users", "userId"),
+		guarded,
+		h.User.UpdateUserAttributes)
+	userRoutes.Post("/:userId/unlock",
+		guarded,
+		h.Auth.UnlockAccount)
 
 	// Tenant routes (OPA-protected)
 	tenantRoutes := protected.Group("/tenants")
 	tenantRoutes.Get("/",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "read"),
-		tenantHandler.ListTenants)
+		platform,
+		guarded,
+		h.Tenant.ListTenants)
 	tenantRoutes.Post("/",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "create"),
-		tenantHandler.CreateTenant)
+		platform,
+		guarded,
+		h.Tenant.CreateTenant)
 	tenantRoutes.Get("/slug/:slug",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "read"),
-		tenantHandler.GetTenantBySlug)
+		guarded,
+		h.Tenant.GetTenantBySlug)
+	tenantRoutes.Put("/slug/:slug",
+		platform,
+		guarded,
+		h.Tenant.UpsertTenant)
 	tenantRoutes.Get("/:tenantId",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "read"),
-		tenantHandler.GetTenant)
+		guarded,
+		h.Tenant.GetTenant)
 	tenantRoutes.Patch("/:tenantId",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "update"),
-		tenantHandler.UpdateTenant)
+		guarded,
+		h.Tenant.UpdateTenant)
 	tenantRoutes.Delete("/:tenantId",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "delete"),
-		tenantHandler.DeleteTenant)
+		audit("tenants.delete", "tenants", "tenantId"),
+		platform,
+		guarded,
+		confirmed("tenants.delete", "tenantId"),
+		h.Tenant.DeleteTenant)
 	tenantRoutes.Post("/:tenantId/suspend",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "suspend"),
-		tenantHandler.SuspendTenant)
+		audit("tenants.suspend", "tenants", "tenantId"),
+		platform,
+		guarded,
+		h.Tenant.SuspendTenant)
 	tenantRoutes.Post("/:tenantId/activate",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "activate"),
-		tenantHandler.ActivateTenant)
+		audit("tenants.activate", "tenants", "tenantId"),
+		platform,
+		guarded,
+		h.Tenant.ActivateTenant)
 	tenantRoutes.Get("/:tenantId/stats",
-		middleware.RequirePermissionOPA(evaluator, "tenants", "read"),
-		tenantHandler.GetTenantStats)
+		guarded,
+		h.Tenant.GetTenantStats)
+
+	// Tenant default roles (OPA-protected), given to users when they register.
+	// Client tokens cannot change them, which would grant roles to new users.
+	tenantRoutes.Get("/:tenantId/default-roles",
+		guarded,
+		h.Tenant.GetDefaultRoles)
+	tenantRoutes.Patch("/:tenantId/default-roles",
+		audit("tenants.default_roles", "tenants", "tenantId"),
+		unscoped,
+		guarded,
+		h.Tenant.UpdateDefaultRoles)
+
+	// Tenant signing key routes (OPA-protected)
+	tenantRoutes.Get("/:tenantId/signing-keys",
+		guarded,
+		h.SigningKey.ListSigningKeys)
+	tenantRoutes.Post("/:tenantId/signing-keys",
+		guarded,
+		h.SigningKey.CreateSigningKey)
+	tenantRoutes.Post("/:tenantId/signing-keys/migrate",
+		guarded,
+		h.SigningKey.MigrateSigningKeys)
+	tenantRoutes.Delete("/:tenantId/signing-keys/:kid",
+		guarded,
+		h.SigningKey.RevokeSigningKey)
+
+	// Tenant SAML configuration routes (OPA-protected)
+	tenantRoutes.Get("/:tenantId/saml",
+		guarded,
+		h.SAML.GetConfig)
+	tenantRoutes.Put("/:tenantId/saml",
+		guarded,
+		h.SAML.UpsertConfig)
+	tenantRoutes.Delete("/:tenantId/saml",
+		guarded,
+		h.SAML.DeleteConfig)
+
+	// Tenant claims template routes (OPA-protected)
+	tenantRoutes.Get("/:tenantId/claims-template",
+		guarded,
+		h.ClaimsTemplate.GetTemplate)
+	tenantRoutes.Put("/:tenantId/claims-template",
+		guarded,
+		h.ClaimsTemplate.UpsertTemplate)
+	tenantRoutes.Delete("/:tenantId/claims-template",
+		guarded,
+		h.ClaimsTemplate.DeleteTemplate)
+
+	// Tenant rate limit routes (OPA-protected). Quotas are set by operators, so
+	// they have their own resource rather than the tenant's update permission.
+	tenantRoutes.Get("/:tenantId/rate-limits",
+		guarded,
+		h.RateLimit.GetLimits)
+	tenantRoutes.Put("/:tenantId/rate-limits",
+		unscoped,
+		guarded,
+		h.RateLimit.UpsertLimits)
+	tenantRoutes.Delete("/:tenantId/rate-limits",
+		unscoped,
+		guarded,
+		h.RateLimit.DeleteLimits)
+
+	// Tenant IP access lists (OPA-protected). The lists are enforced before
+	// authentication by IPAccessMiddleware.
+	tenantRoutes.Get("/:tenantId/ip-access",
+		guarded,
+		h.IPAccess.GetIPAccess)
+	tenantRoutes.Put("/:tenantId/ip-access",
+		unscoped,
+		guarded,
+		h.IPAccess.UpsertIPAccess)
+	tenantRoutes.Delete("/:tenantId/ip-access",
+		unscoped,
+		guarded,
+		h.IPAccess.DeleteIPAccess)
+
+	// Tenant user attribute schemas (OPA-protected). Attributes are validated
+	// on user updates and exposed to policies by UserAttributesMiddleware.
+	tenantRoutes.Get("/:tenantId/user-attributes",
+		guarded,
+		h.UserAttribute.GetSchema)
+	tenantRoutes.Put("/:tenantId/user-attributes",
+		unscoped,
+		guarded,
+		h.UserAttribute.UpsertSchema)
+	tenantRoutes.Delete("/:tenantId/user-attributes",
+		unscoped,
+		guarded,
+		h.UserAttribute.DeleteSchema)
+
+	// Tenant OAuth client routes (OPA-protected). Client tokens cannot manage
+	// clients, which would let them widen their own scopes.
+	tenantRoutes.Get("/:tenantId/oauth-clients",
+		guarded,
+		h.OAuth.ListClients)
+	tenantRoutes.Post("/:tenantId/oauth-clients",
+		unscoped,
+		guarded,
+		h.OAuth.CreateClient)
+	tenantRoutes.Get("/:tenantId/oauth-clients/:clientId",
+		guarded,
+		h.OAuth.GetClient)
+	tenantRoutes.Patch("/:tenantId/oauth-clients/:clientId",
+		unscoped,
+		guarded,
+		h.OAuth.UpdateClient)
+	tenantRoutes.Delete("/:tenantId/oauth-clients/:clientId",
+		unscoped,
+		guarded,
+		h.OAuth.DeleteClient)
+	tenantRoutes.Post("/:tenantId/oauth-clients/:clientId/rotate-secret",
+		unscoped,
+		guarded,
+		h.OAuth.RotateClientSecret)
+
+	// Role routes, addressed by name (OPA-protected)
+	roleRoutes := protected.Group("/roles")
+	roleRoutes.Get("/:name",
+		guarded,
+		h.Role.GetRole)
+	roleRoutes.Put("/:name",
+		guarded,
+		h.Role.UpsertRole)
+	roleRoutes.Delete("/:name",
+		guarded,
+		h.Role.DeleteRole)
+
+	// Permission routes, addressed by name (OPA-protected)
+	permissionRoutes := protected.Group("/permissions")
+	permissionRoutes.Get("/:name",
+		guarded,
+		h.Role.GetPermission)
+	permissionRoutes.Put("/:name",
+		guarded,
+		h.Role.UpsertPermission)
+	permissionRoutes.Delete("/:name",
+		guarded,
+		h.Role.DeletePermission)
 
 	// Policy routes (OPA-protected)
 	policyRoutes := protected.Group("/policies")
 	policyRoutes.Get("/",
-		middleware.RequirePermissionOPA(evaluator, "policies", "read"),
-		policyHandler.ListPolicies)
+		guarded,
+		middleware.FilterRowsOPA(evaluator, "policies"),
+		h.Policy.ListPolicies)
 	policyRoutes.Post("/",
-		middleware.RequirePermissionOPA(evaluator, "policies", "create"),
-		policyHandler.CreatePolicy)
+		guarded,
+		h.Policy.CreatePolicy)
+	policyRoutes.Post("/from-template",
+		guarded,
+		h.Policy.CreatePolicyFromTemplate)
+	policyRoutes.Post("/compile",
+		guarded,
+		h.Policy.CompilePolicyRules)
+	policyRoutes.Post("/test-suite",
+		guarded,
+		h.Policy.RunTestSuite)
+	policyRoutes.Post("/format",
+		guarded,
+		h.Policy.FormatPolicy)
+	policyRoutes.Post("/lint",
+		guarded,
+		h.Policy.LintPolicy)
+	policyRoutes.Post("/sync",
+		guarded,
+		h.Policy.SyncPolicies)
+	policyRoutes.Get("/export",
+		guarded,
+		h.Policy.ExportPolicies)
+	policyRoutes.Get("/path/*",
+		guarded,
+		h.Policy.GetPolicyByPath)
+	policyRoutes.Put("/path/*",
+		guarded,
+		h.Policy.UpsertPolicy)
+	policyRoutes.Get("/deleted",
+		guarded,
+		h.Policy.ListDeletedPolicies)
 	policyRoutes.Get("/:id",
-		middleware.RequirePermissionOPA(evaluator, "policies", "read"),
-		policyHandler.GetPolicy)
+		guarded,
+		h.Policy.GetPolicy)
 	policyRoutes.Put("/:id",
-		middleware.RequirePermissionOPA(evaluator, "policies", "update"),
-		policyHandler.UpdatePolicy)
+		guarded,
+		h.Policy.UpdatePolicy)
 	policyRoutes.Delete("/:id",
-		middleware.RequirePermissionOPA(evaluator, "policies", "delete"),
-		policyHandler.DeletePolicy)
+		guarded,
+		h.Policy.DeletePolicy)
+	policyRoutes.Post("/:id/restore",
+		audit("policies.restore", "policies", "id"),
+		guarded,
+		h.Policy.RestorePolicy)
+	policyRoutes.Delete("/:id/purge",
+		audit("policies.purge", "policies", "id"),
+		guarded,
+		confirmed("policies.purge", "id"),
+		h.Policy.PurgePolicy)
 	policyRoutes.Post("/:id/publish",
-		middleware.RequirePermissionOPA(evaluator, "policies", "publish"),
-		policyHandler.PublishPolicy)
+		audit("policies.publish", "policies", "id"),
+		guarded,
+		h.Policy.PublishPolicy)
+	policyRoutes.Post("/:id/archive",
+		audit("policies.archive", "policies", "id"),
+		guarded,
+		h.Policy.ArchivePolicy)
+	policyRoutes.Get("/:id/dependencies",
+		guarded,
+		h.Policy.GetPolicyDependencies)
 	policyRoutes.Post("/:id/validate",
-		middleware.RequirePermissionOPA(evaluator, "policies", "test"),
-		policyHandler.ValidatePolicy)
+		guarded,
+		h.Policy.ValidatePolicy)
 	policyRoutes.Post("/:id/test",
-		middleware.RequirePermissionOPA(evaluator, "policies", "test"),
-		policyHandler.TestPolicy)
+		guarded,
+		h.Policy.TestPolicy)
 	policyRoutes.Get("/:id/versions",
-		middleware.RequirePermissionOPA(evaluator, "policies", "read"),
-		policyHandler.GetPolicyVersions)
+		guarded,
+		h.Policy.GetPolicyVersions)
+
+	// Policy template routes (OPA-protected)
+	protected.Get("/policy-templates",
+		guarded,
+		h.Policy.ListPolicyTemplates)
+
+	// Audit log routes (OPA-protected)
+	auditRoutes := protected.Group("/audit")
+	auditRoutes.Get("/admin-actions",
+		guarded,
+		h.Audit.ListAdminActions)
+	auditRoutes.Get("/decisions",
+		guarded,
+		h.Audit.ListDecisions)
+
+	// Authorization and login analytics (OPA-protected)
+	protected.Get("/analytics/authz",
+		guarded,
+		h.Analytics.GetAuthzAnalytics)
+	protected.Get("/analytics/auth",
+		guarded,
+		h.Analytics.GetAuthAnalytics)
+
+	// Snapshot of all tenants for the admin landing page (OPA-protected)
+	protected.Get("/admin/overview",
+		platform,
+		guarded,
+		h.Overview.GetOverview)
+
+	// Routes whose redacted bodies are logged for debugging (OPA-protected)
+	bodyLoggingRoutes := protected.Group("/admin/body-logging")
+	bodyLoggingRoutes.Get("/",
+		platform,
+		guarded,
+		h.BodyLogging.ListRules)
+	bodyLoggingRoutes.Post("/",
+		audit("body_logging.enable", "admin", ""),
+		platform,
+		guarded,
+		h.BodyLogging.EnableRule)
+	bodyLoggingRoutes.Delete("/:ruleId",
+		audit("body_logging.disable", "admin", "ruleId"),
+		platform,
+		guarded,
+		h.BodyLogging.DisableRule)
+
+	// Shared platform signing key routes (OPA-protected)
+	signingKeyRoutes := protected.Group("/signing-keys")
+	signingKeyRoutes.Get("/",
+		platform,
+		guarded,
+		h.SigningKey.ListPlatformKeys)
+	signingKeyRoutes.Post("/rotate",
+		audit("signing_keys.rotate", "signing_keys", ""),
+		platform,
+		guarded,
+		h.SigningKey.RotatePlatformKey)
+
+	// Decision log API of OPA instances, e.g. sidecars running Heimdall's
+	// bundles, which report their decisions with a service pointing at /v1
+	protected.Post("/logs",
+		guarded,
+		h.Audit.IngestDecisionLogs)
+
+	// Status API of the same OPA instances, and the instances as last reported
+	protected.Post("/status",
+		guarded,
+		h.OPAInstance.ReportStatus)
+	protected.Get("/opa-instances",
+		guarded,
+		h.OPAInstance.ListInstances)
+
+	// Resource registry routes (OPA-protected). Services register the owner and
+	// labels of their resources, which attribute sources add to policy input.
+	resourceRoutes := protected.Group("/resources")
+	resourceRoutes.Get("/",
+		guarded,
+		h.Resource.ListResources)
+	resourceRoutes.Get("/:resourceType/:resourceId",
+		guarded,
+		h.Resource.GetResource)
+	resourceRoutes.Put("/:resourceType/:resourceId",
+		guarded,
+		h.Resource.UpsertResource)
+	resourceRoutes.Delete("/:resourceType/:resourceId",
+		guarded,
+		h.Resource.DeleteResource)
+
+	// Background jobs, polled for the outcome of asynchronous operations (OPA-protected)
+	jobRoutes := protected.Group("/jobs")
+	jobRoutes.Get("/",
+		guarded,
+		h.Job.ListJobs)
+	jobRoutes.Get("/:jobId",
+		guarded,
+		h.Job.GetJob)
+
+	// Alert rules evaluated on the tenant's audit log (OPA-protected)
+	alertRuleRoutes := protected.Group("/alert-rules")
+	alertRuleRoutes.Get("/",
+		guarded,
+		h.AlertRule.ListRules)
+	alertRuleRoutes.Post("/",
+		audit("alert_rules.create", "alert_rules", ""),
+		guarded,
+		h.AlertRule.CreateRule)
+	alertRuleRoutes.Get("/:ruleId",
+		guarded,
+		h.AlertRule.GetRule)
+	alertRuleRoutes.Put("/:ruleId",
+		audit("alert_rules.update", "alert_rules", "ruleId"),
+		guarded,
+		h.AlertRule.UpdateRule)
+	alertRuleRoutes.Delete("/:ruleId",
+		audit("alert_rules.delete", "alert_rules", "ruleId"),
+		guarded,
+		h.AlertRule.DeleteRule)
+	alertRuleRoutes.Get("/:ruleId/alerts",
+		guarded,
+		h.AlertRule.ListAlerts)
+
+	// Just-in-time access requests. Users request roles for themselves;
+	// approvals grant them for a limited time.
+	accessRequestRoutes := protected.Group("/access-requests")
+	accessRequestRoutes.Post("/",
+		audit("access_requests.create", "access_requests", ""),
+		unscoped,
+		h.AccessRequest.CreateAccessRequest)
+	accessRequestRoutes.Get("/",
+		guarded,
+		h.AccessRequest.ListAccessRequests)
+	accessRequestRoutes.Get("/:requestId",
+		guarded,
+		h.AccessRequest.GetAccessRequest)
+	accessRequestRoutes.Post("/:requestId/approve",
+		audit("access_requests.approve", "access_requests", "requestId"),
+		guarded,
+		h.AccessRequest.ApproveAccessRequest)
+	accessRequestRoutes.Post("/:requestId/deny",
+		audit("access_requests.deny", "access_requests", "requestId"),
+		guarded,
+		h.AccessRequest.DenyAccessRequest)
+	accessRequestRoutes.Post("/:requestId/cancel",
+		audit("access_requests.cancel", "access_requests", "requestId"),
+		unscoped,
+		h.AccessRequest.CancelAccessRequest)
+
+	// Authorization decision routes
+	authzRoutes := protected.Group("/authz")
+	authzRoutes.Post("/check", h.Authz.Check)
+	authzRoutes.Post("/explain",
+		guarded,
+		h.Authz.Explain)
+	authzRoutes.Post("/simulate",
+		guarded,
+		h.Authz.Simulate)
+
+	// Break-glass routes, describing the emergency access a request was made with
+	if h.BreakGlass != nil {
+		protected.Get("/break-glass/session", h.BreakGlass.GetSession)
+	}
 
 	// Bundle routes (OPA-protected)
 	bundleRoutes := protected.Group("/bundles")
 	bundleRoutes.Get("/",
-		middleware.RequirePermissionOPA(evaluator, "bundles", "read"),
-		policyHandler.ListBundles)
+		guarded,
+		h.Policy.ListBundles)
 	bundleRoutes.Post("/",
-		middleware.RequirePermissionOPA(evaluator, "bundles", "create"),
-		policyHandler.CreateBundle)
+		guarded,
+		h.Policy.CreateBundle)
 	bundleRoutes.Get("/:id",
-		middleware.RequirePermissionOPA(evaluator, "bundles", "read"),
-		policyHandler.GetBundle)
+		guarded,
+		h.Policy.GetBundle)
+	bundleRoutes.Get("/:id/download",
+		guarded,
+		h.Policy.DownloadBundle)
+	bundleRoutes.Get("/:id/download-url",
+		guarded,
+		h.Policy.GetBundleDownloadURL)
+	bundleRoutes.Get("/:id/contents",
+		guarded,
+		h.Policy.ListBundleContents)
+	bundleRoutes.Get("/:id/contents/*",
+		guarded,
+		h.Policy.GetBundleFile)
 	bundleRoutes.Post("/:id/activate",
-		middleware.RequirePermissionOPA(evaluator, "bundles", "activate"),
+		guarded,
 		middleware.RequireMFA(evaluator, "bundles", "activate"),
-		policyHandler.ActivateBundle)
+		confirmed("bundles.activate", "id"),
+		h.Policy.ActivateBundle)
+	bundleRoutes.Get("/:id/deployments",
+		guarded,
+		h.Policy.ListBundleDeployments)
 	bundleRoutes.Post("/:id/deploy",
-		middleware.RequirePermissionOPA(evaluator, "bundles", "deploy"),
+		guarded,
 		middleware.RequireMFA(evaluator, "bundles", "deploy"),
-		policyHandler.DeployBundle)
+		h.Policy.DeployBundle)
 	bundleRoutes.Delete("/:id",
-		middleware.RequirePermissionOPA(evaluator, "bundles", "delete"),
-		policyHandler.DeleteBundle)
+		guarded,
+		confirmed("bundles.delete", "id"),
+		h.Policy.DeleteBundle)
 }
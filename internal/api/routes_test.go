@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"github.com/techsavvyash/heimdall/policies"
+	"gorm.io/gorm"
+)
+
+func TestRoutes_GuardedRoutesHavePermissions(t *testing.T) {
+	permissions, err := opa.NewRoutePermissions(context.Background(), policies.Files)
+	if err != nil {
+		t.Fatalf("NewRoutePermissions returned error: %v", err)
+	}
+
+	// Every route guarded by the admin_api policy is listed in the bundled copy,
+	// so none is denied to everyone
+	guard := reflect.ValueOf(middleware.RequireRoutePermissionsOPA(nil, nil)).Pointer()
+	guarded := 0
+	for _, route := range Routes() {
+		for _, handler := range route.Handlers {
+			if reflect.ValueOf(handler).Pointer() != guard {
+				continue
+			}
+			guarded++
+			if required, ok := permissions.Lookup(route.Method, route.Path); !ok || len(required) == 0 {
+				t.Errorf("No permissions guard %s %s in policies/admin_api.rego", route.Method, route.Path)
+			}
+		}
+	}
+	if guarded == 0 {
+		t.Fatal("Expected management routes to be guarded")
+	}
+}
+
+func TestRequireRoutePermissionsOPA(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		testutil.CreateTestPermission(t, db, "roles.create", "roles", "create")
+		testutil.CreateTestPermission(t, db, "roles.update", "roles", "update")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		oauthClientService := service.NewOAuthClientService(db, jwtService)
+		client, err := oauthClientService.CreateClient(ctx, tenant.ID, &service.CreateOAuthClientRequest{
+			Name:   "Role provisioner",
+			Scopes: []string{"roles.create", "roles.update"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		permissions, err := opa.NewRoutePermissions(ctx, policies.Files)
+		if err != nil {
+			t.Fatalf("NewRoutePermissions returned error: %v", err)
+		}
+
+		// Client tokens are authorized by scope alone, so no OPA evaluator is needed
+		app := testutil.CreateTestApp()
+		protected := app.Group("/v1").Use(middleware.AuthMiddleware(jwtService))
+		guarded := middleware.RequireRoutePermissionsOPA(nil, permissions)
+		ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+		protected.Put("/roles/:name", guarded, ok)
+		protected.Get("/unlisted", guarded, ok)
+
+		token := func(scope string) map[string]string {
+			issued, err := oauthClientService.IssueToken(ctx, client.ClientID, client.ClientSecret, scope)
+			if err != nil {
+				t.Fatalf("Failed to issue token: %v", err)
+			}
+			return testutil.WithAuthHeader(issued.AccessToken)
+		}
+
+		// Upserting a role needs both permissions the policy lists
+		resp := testutil.MakeRequest(t, app, "PUT", "/v1/roles/editor", nil, token("roles.create roles.update"))
+		testutil.AssertStatusCode(t, http.StatusNoContent, resp.Code)
+
+		resp = testutil.MakeRequest(t, app, "PUT", "/v1/roles/editor", nil, token("roles.create"))
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "OUT_OF_SCOPE")
+
+		// Routes the policy does not list are denied
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/unlisted", nil, token("roles.create roles.update"))
+		testutil.AssertStatusCode(t, http.StatusForbidden, resp.Code)
+		testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "FORBIDDEN")
+	})
+}
+
+func TestRequireRoutePermissionsOPA_NoPermissions(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		oauthClientService := service.NewOAuthClientService(db, jwtService)
+		testutil.CreateTestPermission(t, db, "jobs.read", "jobs", "read")
+		client, err := oauthClientService.CreateClient(ctx, tenant.ID, &service.CreateOAuthClientRequest{Name: "Reporter", Scopes: []string{"jobs.read"}})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		// An operator's copy of the policy lists a tenant route with no permissions
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"result": {"version": 1, "routes": {"GET /v1/tenants/:tenantId/jobs": []}}}`))
+		}))
+		defer server.Close()
+		permissions, err := opa.NewRoutePermissions(ctx, policies.Files)
+		if err != nil {
+			t.Fatalf("NewRoutePermissions returned error: %v", err)
+		}
+		if err := permissions.Refresh(ctx, opa.NewClient(&config.OPAConfig{URL: server.URL, Timeout: time.Second, MaxIdleConns: 1})); err != nil {
+			t.Fatalf("Refresh returned error: %v", err)
+		}
+
+		app := testutil.CreateTestApp()
+		protected := app.Group("/v1").Use(middleware.AuthMiddleware(jwtService))
+		protected.Get("/tenants/:tenantId/jobs", middleware.RequireRoutePermissionsOPA(nil, permissions), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+
+		userTokens, err := jwtService.GenerateTokenPair(alice.ID.String(), tenant.ID.String(), alice.Email, nil)
+		if err != nil {
+			t.Fatalf("Failed to generate token pair: %v", err)
+		}
+		clientToken, err := oauthClientService.IssueToken(ctx, client.ClientID, client.ClientSecret, "")
+		if err != nil {
+			t.Fatalf("Failed to issue token: %v", err)
+		}
+
+		for name, token := range map[string]string{"user": userTokens.AccessToken, "client": clientToken.AccessToken} {
+			// The route needs no permission within the token's own tenant...
+			resp := testutil.MakeRequest(t, app, "GET", "/v1/tenants/"+tenant.ID.String()+"/jobs", nil, testutil.WithAuthHeader(token))
+			if resp.Code != http.StatusNoContent {
+				t.Errorf("%s: expected 204 for its own tenant, got %d", name, resp.Code)
+			}
+
+			// ...but its tenant isolation still applies
+			resp = testutil.MakeRequest(t, app, "GET", "/v1/tenants/"+other.ID.String()+"/jobs", nil, testutil.WithAuthHeader(token))
+			testutil.AssertStatusCode(t, http.StatusForbidden, resp.Code)
+			testutil.AssertJSONError(t, testutil.ParseJSONResponse(t, resp), "TENANT_ISOLATION_VIOLATION")
+		}
+
+		resp := testutil.MakeRequest(t, app, "GET", "/v1/tenants/"+tenant.ID.String()+"/jobs", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusUnauthorized, resp.Code)
+	})
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// samlRequestCookie holds the ID of the pending SAML authentication request, so
+// the assertion consumer service only accepts the response to it
+const samlRequestCookie = "heimdall_saml_request"
+
+// SAMLHandler handles SAML single sign-on and tenant SAML configuration endpoints
+type SAMLHandler struct {
+	samlService *service.SAMLService
+	authService *service.AuthService
+}
+
+// NewSAMLHandler creates a new SAML handler
+func NewSAMLHandler(samlService *service.SAMLService, authService *service.AuthService) *SAMLHandler {
+	return &SAMLHandler{
+		samlService: samlService,
+		authService: authService,
+	}
+}
+
+// GetMetadata returns the service provider metadata of a tenant
+// GET /v1/saml/:tenantId/metadata
+func (h *SAMLHandler) GetMetadata(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	metadata, err := h.samlService.Metadata(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "SAML_METADATA_FAILED", "Failed to generate SAML metadata")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/samlmetadata+xml")
+	return c.Status(fiber.StatusOK).Send(metadata)
+}
+
+// Login redirects the browser to the tenant's identity provider. After a
+// successful login the tokens are sent to redirect_uri, which must be allowed
+// by SAML_ALLOWED_REDIRECT_URLS.
+// GET /v1/saml/:tenantId/login
+func (h *SAMLHandler) Login(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	loginURL, requestID, err := h.samlService.LoginRedirect(c.UserContext(), tenantID, c.Query("redirect_uri"))
+	if err != nil {
+		return apperrors.Wrap(err, "SAML_LOGIN_FAILED", "Failed to start SAML login")
+	}
+
+	// The response is posted cross-site by the identity provider, which needs
+	// SameSite=None, and browsers only accept that on secure cookies
+	sameSite := fiber.CookieSameSiteLaxMode
+	if c.Protocol() == "https" {
+		sameSite = fiber.CookieSameSiteNoneMode
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     samlRequestCookie,
+		Value:    requestID,
+		Path:     "/v1/saml/" + tenantID.String(),
+		Expires:  time.Now().Add(10 * time.Minute),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: sameSite,
+	})
+	return c.Redirect(loginURL, fiber.StatusFound)
+}
+
+// ConsumeAssertion validates a SAML response from the tenant's identity provider
+// and issues Heimdall tokens. When the relay state is an allowed redirect URL the
+// browser is redirected there with the tokens in the URL fragment.
+// POST /v1/saml/:tenantId/acs
+func (h *SAMLHandler) ConsumeAssertion(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	samlResponse := c.FormValue("SAMLResponse")
+	if samlResponse == "" {
+		return apperrors.Validation("INVALID_REQUEST", "SAMLResponse is required")
+	}
+
+	requestID := c.Cookies(samlRequestCookie)
+	c.ClearCookie(samlRequestCookie)
+
+	identityUser, err := h.samlService.ConsumeResponse(c.UserContext(), tenantID, samlResponse, requestID)
+	if err != nil {
+		return apperrors.Wrap(err, "SAML_LOGIN_FAILED", "SAML login failed")
+	}
+
+	result, err := h.authService.LoginExternal(c.UserContext(), identityUser, &service.LoginRequest{
+		Email:     identityUser.Email,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		DeviceID:  c.Get(DeviceIDHeader),
+	})
+	if err != nil {
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "Login rejected",
+					"code":    "LOGIN_REJECTED",
+					"details": fiber.Map{
+						"reason": rejected.Reason,
+					},
+				},
+			})
+		}
+		return apperrors.Wrap(err, "SAML_LOGIN_FAILED", "SAML login failed")
+	}
+
+	if relayState := c.FormValue("RelayState"); relayState != "" && h.samlService.AllowedRedirect(relayState) {
+		fragment := url.Values{}
+		fragment.Set("access_token", result.AccessToken)
+		fragment.Set("refresh_token", result.RefreshToken)
+		fragment.Set("token_type", result.TokenType)
+		fragment.Set("expires_in", strconv.FormatInt(result.ExpiresIn, 10))
+		return c.Redirect(relayState+"#"+fragment.Encode(), fiber.StatusSeeOther)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetConfig retrieves a tenant's SAML configuration
+// GET /v1/tenants/:tenantId/saml
+func (h *SAMLHandler) GetConfig(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	samlConfig, err := h.samlService.GetConfig(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "SAML_CONFIG_RETRIEVAL_FAILED", "Failed to retrieve SAML configuration")
+	}
+
+	c.Set(fiber.HeaderETag, samlConfig.ETag)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    samlConfig,
+	})
+}
+
+// UpsertConfig creates or replaces a tenant's SAML configuration. If-Match and
+// If-None-Match headers make the request conditional on the current ETag.
+// PUT /v1/tenants/:tenantId/saml
+func (h *SAMLHandler) UpsertConfig(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpsertSAMLConfigRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	samlConfig, created, err := h.samlService.UpsertConfig(c.UserContext(), tenantID, &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "SAML_CONFIG_UPSERT_FAILED", "Failed to save SAML configuration")
+	}
+
+	c.Set(fiber.HeaderETag, samlConfig.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    samlConfig,
+	})
+}
+
+// DeleteConfig removes a tenant's SAML configuration
+// DELETE /v1/tenants/:tenantId/saml
+func (h *SAMLHandler) DeleteConfig(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.samlService.DeleteConfig(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "SAML_CONFIG_DELETION_FAILED", "Failed to delete SAML configuration")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "SAML configuration deleted successfully",
+	})
+}
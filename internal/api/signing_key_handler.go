@@ -0,0 +1,182 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// SigningKeyHandler handles tenant and platform signing key and JWKS endpoints
+type SigningKeyHandler struct {
+	signingKeyService *service.SigningKeyService
+	jwtService        *auth.JWTService
+}
+
+// NewSigningKeyHandler creates a new signing key handler
+func NewSigningKeyHandler(signingKeyService *service.SigningKeyService, jwtService *auth.JWTService) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		signingKeyService: signingKeyService,
+		jwtService:        jwtService,
+	}
+}
+
+// GetSharedJWKS returns the JWKS of the shared platform signing keys
+// GET /v1/.well-known/jwks.json
+func (h *SigningKeyHandler) GetSharedJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.jwtService.SharedJWKS())
+}
+
+// GetTenantJWKS returns the JWKS for a tenant's signing keys
+// GET /v1/tenants/:tenantId/.well-known/jwks.json
+func (h *SigningKeyHandler) GetTenantJWKS(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	jwks, err := h.signingKeyService.GetTenantJWKS(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "JWKS_RETRIEVAL_FAILED", "Failed to retrieve signing keys")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(jwks)
+}
+
+// ListSigningKeys lists a tenant's signing keys
+// GET /v1/tenants/:tenantId/signing-keys
+func (h *SigningKeyHandler) ListSigningKeys(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	keys, err := h.signingKeyService.ListSigningKeys(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "SIGNING_KEY_LIST_FAILED", "Failed to list signing keys")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    keys,
+		"count":   len(keys),
+	})
+}
+
+// CreateSigningKey issues a new active signing key for a tenant, retiring the current one
+// POST /v1/tenants/:tenantId/signing-keys
+func (h *SigningKeyHandler) CreateSigningKey(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	key, err := h.signingKeyService.CreateSigningKey(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "SIGNING_KEY_CREATION_FAILED", "Failed to create signing key")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// MigrateSigningKeys moves a tenant from the shared platform key to its own signing key
+// POST /v1/tenants/:tenantId/signing-keys/migrate
+func (h *SigningKeyHandler) MigrateSigningKeys(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	key, err := h.signingKeyService.MigrateFromSharedKey(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "SIGNING_KEY_MIGRATION_FAILED", "Failed to migrate signing keys")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    key,
+	})
+}
+
+// RevokeSigningKey revokes a tenant signing key
+// DELETE /v1/tenants/:tenantId/signing-keys/:kid
+func (h *SigningKeyHandler) RevokeSigningKey(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.signingKeyService.RevokeSigningKey(c.UserContext(), tenantID, c.Params("kid")); err != nil {
+		return apperrors.Wrap(err, "SIGNING_KEY_REVOCATION_FAILED", "Failed to revoke signing key")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Signing key revoked successfully",
+	})
+}
+
+// ListPlatformKeys lists the shared platform signing keys
+// GET /v1/signing-keys
+func (h *SigningKeyHandler) ListPlatformKeys(c *fiber.Ctx) error {
+	keys, err := h.signingKeyService.ListPlatformKeys(c.UserContext())
+	if err != nil {
+		return apperrors.Wrap(err, "SIGNING_KEY_LIST_FAILED", "Failed to list signing keys")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    keys,
+		"count":   len(keys),
+	})
+}
+
+// RotatePlatformKey issues a new primary platform signing key, retiring the current one
+// POST /v1/signing-keys/rotate
+func (h *SigningKeyHandler) RotatePlatformKey(c *fiber.Ctx) error {
+	key, err := h.signingKeyService.RotatePlatformKey(c.UserContext())
+	if err != nil {
+		return apperrors.Wrap(err, "SIGNING_KEY_ROTATION_FAILED", "Failed to rotate signing key")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    key,
+	})
+}
@@ -1,11 +1,12 @@
 package api
 
 import (
-	"strconv"
-
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"github.com/techsavvyash/heimdall/internal/service"
-	"github.com/techsavvyash/heimdall/internal/utils"
 )
 
 // TenantHandler handles tenant-related endpoints
@@ -24,38 +25,14 @@ func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
 // POST /v1/tenants
 func (h *TenantHandler) CreateTenant(c *fiber.Ctx) error {
 	var req service.CreateTenantRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
-	}
-
-	// Validate request
-	if err := utils.ValidateStruct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Validation failed",
-				"code":    "VALIDATION_ERROR",
-				"details": err,
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
 	// Create tenant
-	result, err := h.tenantService.CreateTenant(c.Context(), &req)
+	result, err := h.tenantService.CreateTenant(c.UserContext(), &req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_CREATION_FAILED",
-			},
-		})
+		return apperrors.Wrap(err, "TENANT_CREATION_FAILED", "Failed to create tenant")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -78,20 +55,19 @@ func (h *TenantHandler) GetTenant(c *fiber.Ctx) error {
 		})
 	}
 
-	tenant, err := h.tenantService.GetTenant(c.Context(), tenantID)
+	tenant, err := h.tenantService.GetTenant(c.UserContext(), tenantID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_NOT_FOUND",
-			},
-		})
+		return apperrors.Wrap(err, "TENANT_RETRIEVAL_FAILED", "Failed to retrieve tenant")
 	}
 
+	c.Set(fiber.HeaderETag, tenant.ETag)
+	data, err := redact(c, tenant)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    tenant,
+		"data":    data,
 	})
 }
 
@@ -109,59 +85,91 @@ func (h *TenantHandler) GetTenantBySlug(c *fiber.Ctx) error {
 		})
 	}
 
-	tenant, err := h.tenantService.GetTenantBySlug(c.Context(), slug)
+	tenant, err := h.tenantService.GetTenantBySlug(c.UserContext(), slug)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_NOT_FOUND",
-			},
-		})
+		return apperrors.Wrap(err, "TENANT_RETRIEVAL_FAILED", "Failed to retrieve tenant")
+	}
+	// Tenant-scoped users cannot look up other tenants by slug
+	if !middleware.IsPlatformScope(c) && tenant.ID != middleware.GetTenantID(c) {
+		return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
 	}
 
+	c.Set(fiber.HeaderETag, tenant.ETag)
+	data, err := redact(c, tenant)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// GetPublicTenant retrieves the branding and login methods of a tenant for
+// hosted login pages, without authentication. Responses may be cached for five
+// minutes and revalidated with If-None-Match.
+// GET /v1/tenants/slug/:slug/public
+func (h *TenantHandler) GetPublicTenant(c *fiber.Ctx) error {
+	tenant, err := h.tenantService.GetPublicTenant(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return apperrors.Wrap(err, "TENANT_RETRIEVAL_FAILED", "Failed to retrieve tenant")
+	}
+
+	c.Set(fiber.HeaderETag, tenant.ETag)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"data":    tenant,
 	})
 }
 
-// ListTenants retrieves a paginated list of tenants
-// GET /v1/tenants?page=1&pageSize=20
-func (h *TenantHandler) ListTenants(c *fiber.Ctx) error {
-	// Parse pagination params
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
+// UpsertTenant creates or replaces the tenant with the given slug. If-Match and
+// If-None-Match headers make the request conditional on the tenant's current ETag.
+// PUT /v1/tenants/slug/:slug
+func (h *TenantHandler) UpsertTenant(c *fiber.Ctx) error {
+	var req service.UpsertTenantRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	tenant, created, err := h.tenantService.UpsertTenant(c.UserContext(), c.Params("slug"), &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "TENANT_UPSERT_FAILED", "Failed to save tenant")
+	}
 
-	// Validate pagination
-	if page < 1 {
-		page = 1
+	c.Set(fiber.HeaderETag, tenant.ETag)
+	data, err := redact(c, tenant)
+	if err != nil {
+		return err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// ListTenants retrieves a paginated list of tenants
+// GET /v1/tenants?page=1&pageSize=20&sort=-createdAt&status=active
+func (h *TenantHandler) ListTenants(c *fiber.Ctx) error {
+	params, err := pagination.Parse(c.Query, service.TenantListOptions)
+	if err != nil {
+		return err
 	}
 
-	tenants, total, err := h.tenantService.ListTenants(c.Context(), page, pageSize)
+	tenants, page, err := h.tenantService.ListTenantsPage(c.UserContext(), params)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to retrieve tenants",
-				"code":    "TENANT_LIST_FAILED",
-			},
-		})
+		return apperrors.Wrap(err, "TENANT_LIST_FAILED", "Failed to retrieve tenants")
 	}
 
+	data, err := redact(c, tenants)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
-			"tenants": tenants,
-			"pagination": fiber.Map{
-				"page":       page,
-				"pageSize":   pageSize,
-				"total":      total,
-				"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
-			},
+			"tenants":    data,
+			"pagination": page,
 		},
 	})
 }
@@ -181,42 +189,22 @@ func (h *TenantHandler) UpdateTenant(c *fiber.Ctx) error {
 	}
 
 	var req service.UpdateTenantRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
-	// Validate request
-	if err := utils.ValidateStruct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Validation failed",
-				"code":    "VALIDATION_ERROR",
-				"details": err,
-			},
-		})
+	result, err := h.tenantService.UpdateTenant(c.UserContext(), tenantID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "TENANT_UPDATE_FAILED", "Failed to update tenant")
 	}
 
-	result, err := h.tenantService.UpdateTenant(c.Context(), tenantID, &req)
+	data, err := redact(c, result)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_UPDATE_FAILED",
-			},
-		})
+		return err
 	}
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    result,
+		"data":    data,
 	})
 }
 
@@ -234,14 +222,8 @@ func (h *TenantHandler) DeleteTenant(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.tenantService.DeleteTenant(c.Context(), tenantID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_DELETION_FAILED",
-			},
-		})
+	if err := h.tenantService.DeleteTenant(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "TENANT_DELETION_FAILED", "Failed to delete tenant")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -264,14 +246,8 @@ func (h *TenantHandler) SuspendTenant(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.tenantService.SuspendTenant(c.Context(), tenantID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_SUSPENSION_FAILED",
-			},
-		})
+	if err := h.tenantService.SuspendTenant(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "TENANT_SUSPENSION_FAILED", "Failed to suspend tenant")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -294,14 +270,8 @@ func (h *TenantHandler) ActivateTenant(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.tenantService.ActivateTenant(c.Context(), tenantID); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": err.Error(),
-				"code":    "TENANT_ACTIVATION_FAILED",
-			},
-		})
+	if err := h.tenantService.ActivateTenant(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "TENANT_ACTIVATION_FAILED", "Failed to activate tenant")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -324,19 +294,68 @@ func (h *TenantHandler) GetTenantStats(c *fiber.Ctx) error {
 		})
 	}
 
-	stats, err := h.tenantService.GetTenantStats(c.Context(), tenantID)
+	stats, err := h.tenantService.GetTenantStats(c.UserContext(), tenantID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return apperrors.Wrap(err, "STATS_RETRIEVAL_FAILED", "Failed to retrieve tenant stats")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// GetDefaultRoles retrieves the roles given to users registering with a tenant
+// GET /v1/tenants/:tenantId/default-roles
+func (h *TenantHandler) GetDefaultRoles(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to retrieve tenant stats",
-				"code":    "STATS_RETRIEVAL_FAILED",
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
+	roles, err := h.tenantService.GetDefaultRoles(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "DEFAULT_ROLES_RETRIEVAL_FAILED", "Failed to retrieve default roles")
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    stats,
+		"data":    roles,
+	})
+}
+
+// UpdateDefaultRoles adds and removes roles given to users registering with a tenant
+// PATCH /v1/tenants/:tenantId/default-roles
+func (h *TenantHandler) UpdateDefaultRoles(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpdateDefaultRolesRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	roles, err := h.tenantService.UpdateDefaultRoles(c.UserContext(), tenantID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "DEFAULT_ROLES_UPDATE_FAILED", "Failed to update default roles")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    roles,
 	})
 }
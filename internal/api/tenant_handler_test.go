@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/service"
 	"github.com/techsavvyash/heimdall/internal/testutil"
 	"gorm.io/gorm"
@@ -22,6 +23,12 @@ func setupTenantTestApp(t *testing.T, db *gorm.DB) (*fiber.App, string) {
 	// Create app
 	app := testutil.CreateTestApp()
 
+	// Tenants are managed across tenants by platform admins
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("accessScope", auth.AccessScopePlatform)
+		return c.Next()
+	})
+
 	// Setup routes
 	v1 := app.Group("/v1")
 	tenantRoutes := v1.Group("/tenants")
@@ -152,6 +159,30 @@ func TestTenantHandler_GetTenantBySlug(t *testing.T) {
 	})
 }
 
+func TestTenantHandler_GetTenantBySlugTenantScope(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		own := testutil.CreateTestTenant(t, db, "Own Tenant", "own")
+		testutil.CreateTestTenant(t, db, "Other Tenant", "other")
+
+		app := testutil.CreateTestApp()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("tenantID", own.ID.String())
+			c.Locals("accessScope", auth.AccessScopeTenant)
+			return c.Next()
+		})
+		app.Get("/v1/tenants/slug/:slug", NewTenantHandler(service.NewTenantService(db)).GetTenantBySlug)
+
+		resp := testutil.MakeRequest(t, app, "GET", "/v1/tenants/slug/own", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusOK, resp.Code)
+
+		// Other tenants are hidden from tenant-scoped users
+		resp = testutil.MakeRequest(t, app, "GET", "/v1/tenants/slug/other", nil, nil)
+		testutil.AssertStatusCode(t, http.StatusNotFound, resp.Code)
+	})
+}
+
 func TestTenantHandler_ListTenants(t *testing.T) {
 	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
 		testutil.TruncateTables(t, db)
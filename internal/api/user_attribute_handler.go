@@ -0,0 +1,102 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+)
+
+// UserAttributeHandler handles tenant user attribute schema endpoints
+type UserAttributeHandler struct {
+	userAttributeService *service.UserAttributeService
+}
+
+// NewUserAttributeHandler creates a new user attribute handler
+func NewUserAttributeHandler(userAttributeService *service.UserAttributeService) *UserAttributeHandler {
+	return &UserAttributeHandler{
+		userAttributeService: userAttributeService,
+	}
+}
+
+// GetSchema retrieves a tenant's user attribute schema
+// GET /v1/tenants/:tenantId/user-attributes
+func (h *UserAttributeHandler) GetSchema(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	schema, err := h.userAttributeService.GetSchema(c.UserContext(), tenantID)
+	if err != nil {
+		return apperrors.Wrap(err, "USER_ATTRIBUTE_SCHEMA_RETRIEVAL_FAILED", "Failed to retrieve user attribute schema")
+	}
+
+	c.Set(fiber.HeaderETag, schema.ETag)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    schema,
+	})
+}
+
+// UpsertSchema creates or replaces a tenant's user attribute schema. If-Match
+// and If-None-Match headers make the request conditional on the current ETag.
+// PUT /v1/tenants/:tenantId/user-attributes
+func (h *UserAttributeHandler) UpsertSchema(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	var req service.UpsertUserAttributeSchemaRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	schema, created, err := h.userAttributeService.UpsertSchema(c.UserContext(), tenantID, &req, preconditionFrom(c))
+	if err != nil {
+		return apperrors.Wrap(err, "USER_ATTRIBUTE_SCHEMA_UPSERT_FAILED", "Failed to save user attribute schema")
+	}
+
+	c.Set(fiber.HeaderETag, schema.ETag)
+	return c.Status(upsertStatus(created)).JSON(fiber.Map{
+		"success": true,
+		"data":    schema,
+	})
+}
+
+// DeleteSchema removes a tenant's user attribute schema
+// DELETE /v1/tenants/:tenantId/user-attributes
+func (h *UserAttributeHandler) DeleteSchema(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("tenantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Invalid tenant ID",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.userAttributeService.DeleteSchema(c.UserContext(), tenantID); err != nil {
+		return apperrors.Wrap(err, "USER_ATTRIBUTE_SCHEMA_DELETION_FAILED", "Failed to delete user attribute schema")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "User attribute schema deleted successfully",
+	})
+}
@@ -1,22 +1,26 @@
 package api
 
 import (
-	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/middleware"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"github.com/techsavvyash/heimdall/internal/service"
 )
 
 // UserHandler handles user-related endpoints
 type UserHandler struct {
-	userService *service.UserService
+	userService         *service.UserService
+	loginHistoryService *service.LoginHistoryService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, loginHistoryService *service.LoginHistoryService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:         userService,
+		loginHistoryService: loginHistoryService,
 	}
 }
 
@@ -34,15 +38,9 @@ func (h *UserHandler) GetMe(c *fiber.Ctx) error {
 		})
 	}
 
-	profile, err := h.userService.GetUserProfile(c.Context(), userID)
+	profile, err := h.userService.GetUserProfile(c.UserContext(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to retrieve user profile",
-				"code":    "PROFILE_RETRIEVAL_FAILED",
-			},
-		})
+		return apperrors.Wrap(err, "PROFILE_RETRIEVAL_FAILED", "Failed to retrieve user profile")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -66,60 +64,173 @@ func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
 	}
 
 	var req service.UpdateProfileRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	profile, err := h.userService.UpdateUserProfile(c.UserContext(), userID, &req)
+	if err != nil {
+		return apperrors.Wrap(err, "PROFILE_UPDATE_FAILED", "Failed to update profile")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// DeleteMe deactivates the current user's account, which is purged after the
+// retention period unless an admin restores it
+// DELETE /v1/users/me
+func (h *UserHandler) DeleteMe(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	if err := h.userService.DeactivateUser(c.UserContext(), userID); err != nil {
+		return apperrors.Wrap(err, "ACCOUNT_DELETION_FAILED", "Failed to delete account")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Account deleted successfully",
+	})
+}
+
+// DeactivateUser deactivates a user, disabling its login until it is restored
+// or purged after the retention period (admin endpoint)
+// POST /v1/users/:userId/deactivate
+func (h *UserHandler) DeactivateUser(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Invalid request body",
+				"message": "User ID is required",
 				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
-	profile, err := h.userService.UpdateUserProfile(c.Context(), userID, &req)
+	if err := h.userService.DeactivateUser(c.UserContext(), userID); err != nil {
+		return apperrors.Wrap(err, "USER_DEACTIVATION_FAILED", "Failed to deactivate user")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "User deactivated successfully",
+	})
+}
+
+// RestoreUser reactivates a deactivated user that has not been purged (admin endpoint)
+// POST /v1/users/:userId/restore
+func (h *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User ID is required",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	profile, err := h.userService.RestoreUser(c.UserContext(), userID)
+	if err != nil {
+		return apperrors.Wrap(err, "USER_RESTORE_FAILED", "Failed to restore user")
+	}
+
+	data, err := redact(c, profile)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// UpdateUserStatus suspends or reactivates a user, revoking a suspended user's
+// sessions (admin endpoint)
+// PATCH /v1/users/:userId/status
+func (h *UserHandler) UpdateUserStatus(c *fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to update profile",
-				"code":    "PROFILE_UPDATE_FAILED",
+				"message": "User ID is required",
+				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
+	var req service.UpdateUserStatusRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	profile, err := h.userService.UpdateUserStatus(c.UserContext(), userID, req.Status)
+	if err != nil {
+		return apperrors.Wrap(err, "USER_STATUS_UPDATE_FAILED", "Failed to update user status")
+	}
+
+	data, err := redact(c, profile)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    profile,
+		"data":    data,
 	})
 }
 
-// DeleteMe deletes the current user's account
-// DELETE /v1/users/me
-func (h *UserHandler) DeleteMe(c *fiber.Ctx) error {
-	userID := middleware.GetUserID(c)
+// UpdateUserAttributes sets a user's attributes, validated against the user
+// attribute schema of the tenant (admin endpoint)
+// PATCH /v1/users/:userId/attributes
+func (h *UserHandler) UpdateUserAttributes(c *fiber.Ctx) error {
+	userID := c.Params("userId")
 	if userID == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "User not authenticated",
-				"code":    "UNAUTHORIZED",
+				"message": "User ID is required",
+				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
-	if err := h.userService.DeleteUser(c.Context(), userID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+	var attributes map[string]interface{}
+	if err := c.BodyParser(&attributes); err != nil || len(attributes) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to delete account",
-				"code":    "ACCOUNT_DELETION_FAILED",
+				"message": "Request body must be an object of attributes",
+				"code":    "INVALID_REQUEST",
 			},
 		})
 	}
 
+	profile, err := h.userService.UpdateUserAttributes(c.UserContext(), userID, attributes)
+	if err != nil {
+		return apperrors.Wrap(err, "USER_ATTRIBUTES_UPDATE_FAILED", "Failed to update user attributes")
+	}
+
+	data, err := redact(c, profile)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"message": "Account deleted successfully",
+		"data":    data,
 	})
 }
 
@@ -137,25 +248,24 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 		})
 	}
 
-	profile, err := h.userService.GetUserProfile(c.Context(), userID)
+	profile, err := h.userService.GetUserProfile(c.UserContext(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "User not found",
-				"code":    "USER_NOT_FOUND",
-			},
-		})
+		return apperrors.Wrap(err, "USER_RETRIEVAL_FAILED", "Failed to retrieve user")
 	}
 
+	data, err := redact(c, profile)
+	if err != nil {
+		return err
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"data":    profile,
+		"data":    data,
 	})
 }
 
-// ListUsers retrieves a paginated list of users (admin endpoint)
-// GET /v1/users?page=1&pageSize=20
+// ListUsers retrieves a paginated list of users, optionally searched by email
+// or name, role, status, and creation or last login time (admin endpoint)
+// GET /v1/users?page=1&pageSize=20&query=ali&role=admin&lastLoginBefore=2024-01-01T00:00:00Z
 func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
 	if tenantID == "" {
@@ -168,39 +278,63 @@ func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
 		})
 	}
 
-	// Parse pagination params
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	pageSize, _ := strconv.Atoi(c.Query("pageSize", "20"))
-
-	// Validate pagination
-	if page < 1 {
-		page = 1
+	params, err := pagination.Parse(c.Query, service.UserListOptions)
+	if err != nil {
+		return err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	search, err := service.ParseUserSearch(c.Query)
+	if err != nil {
+		return err
+	}
+	search.RowFilter = middleware.GetRowFilter(c)
+
+	users, page, err := h.userService.ListUsers(c.UserContext(), tenantID, search, params)
+	if err != nil {
+		return apperrors.Wrap(err, "USER_LIST_FAILED", "Failed to retrieve users")
 	}
 
-	users, total, err := h.userService.ListUsers(c.Context(), tenantID, page, pageSize)
+	data, err := redact(c, users)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"users":      data,
+			"pagination": page,
+		},
+	})
+}
+
+// GetMyLoginHistory retrieves the current user's login history
+// GET /v1/users/me/login-history
+func (h *UserHandler) GetMyLoginHistory(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
-				"message": "Failed to retrieve users",
-				"code":    "USER_LIST_FAILED",
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
 			},
 		})
 	}
 
+	params, err := pagination.Parse(c.Query, service.LoginHistoryListOptions)
+	if err != nil {
+		return err
+	}
+
+	history, page, err := h.loginHistoryService.GetLoginHistory(c.UserContext(), userID, params)
+	if err != nil {
+		return apperrors.Wrap(err, "LOGIN_HISTORY_FAILED", "Failed to retrieve login history")
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
-			"users": users,
-			"pagination": fiber.Map{
-				"page":      page,
-				"pageSize":  pageSize,
-				"total":     total,
-				"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
-			},
+			"logins":     history,
+			"pagination": page,
 		},
 	})
 }
@@ -219,15 +353,9 @@ func (h *UserHandler) GetMyPermissions(c *fiber.Ctx) error {
 		})
 	}
 
-	permissions, err := h.userService.GetUserPermissions(c.Context(), userID)
+	permissions, err := h.userService.GetUserPermissions(c.UserContext(), userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to retrieve permissions",
-				"code":    "PERMISSIONS_RETRIEVAL_FAILED",
-			},
-		})
+		return apperrors.Wrap(err, "PERMISSIONS_RETRIEVAL_FAILED", "Failed to retrieve permissions")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -253,28 +381,17 @@ func (h *UserHandler) AssignRole(c *fiber.Ctx) error {
 	}
 
 	var req struct {
-		RoleID string `json:"roleId" validate:"required"`
+		RoleID    string     `json:"roleId" validate:"required,uuid"`
+		ExpiresAt *time.Time `json:"expiresAt"`
 	}
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Invalid request body",
-				"code":    "INVALID_REQUEST",
-			},
-		})
+	if err := bindRequest(c, &req); err != nil {
+		return err
 	}
 
 	assignedByID := middleware.GetUserID(c)
-	if err := h.userService.AssignRoleToUser(c.Context(), userID, req.RoleID, assignedByID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to assign role",
-				"code":    "ROLE_ASSIGNMENT_FAILED",
-			},
-		})
+	if err := h.userService.AssignRoleToUser(c.UserContext(), userID, req.RoleID, assignedByID, req.ExpiresAt); err != nil {
+		return apperrors.Wrap(err, "ROLE_ASSIGNMENT_FAILED", "Failed to assign role")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -283,6 +400,39 @@ func (h *UserHandler) AssignRole(c *fiber.Ctx) error {
 	})
 }
 
+// GetRoleAssignments lists the roles assigned to a user (admin endpoint)
+// GET /v1/users/:userId/roles
+func (h *UserHandler) GetRoleAssignments(c *fiber.Ctx) error {
+	assignments, err := h.userService.GetRoleAssignments(c.UserContext(), c.Params("userId"))
+	if err != nil {
+		return apperrors.Wrap(err, "ROLE_ASSIGNMENTS_RETRIEVAL_FAILED", "Failed to retrieve role assignments")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    assignments,
+	})
+}
+
+// ElevateRole grants a role to a user for a limited time (admin endpoint)
+// POST /v1/users/:userId/elevations
+func (h *UserHandler) ElevateRole(c *fiber.Ctx) error {
+	var req service.ElevateRoleRequest
+	if err := bindRequest(c, &req); err != nil {
+		return err
+	}
+
+	assignment, err := h.userService.ElevateRole(c.UserContext(), c.Params("userId"), middleware.GetUserID(c), &req)
+	if err != nil {
+		return apperrors.Wrap(err, "ROLE_ELEVATION_FAILED", "Failed to grant elevated access")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    assignment,
+	})
+}
+
 // RemoveRole removes a role from a user (admin endpoint)
 // DELETE /v1/users/:userId/roles/:roleId
 func (h *UserHandler) RemoveRole(c *fiber.Ctx) error {
@@ -299,14 +449,8 @@ func (h *UserHandler) RemoveRole(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.userService.RemoveRoleFromUser(c.Context(), userID, roleID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "Failed to remove role",
-				"code":    "ROLE_REMOVAL_FAILED",
-			},
-		})
+	if err := h.userService.RemoveRoleFromUser(c.UserContext(), userID, roleID); err != nil {
+		return apperrors.Wrap(err, "ROLE_REMOVAL_FAILED", "Failed to remove role")
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/utils"
+)
+
+// bindRequest parses the request body into req and validates it using its struct tags.
+// The returned error is typed and can be returned directly from a handler.
+func bindRequest(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return apperrors.Validation("INVALID_REQUEST", "Invalid request body").WithCause(err)
+	}
+
+	if fields := utils.ValidateStruct(req); fields != nil {
+		return apperrors.Validation("VALIDATION_ERROR", "Validation failed").WithDetails(fields)
+	}
+
+	return nil
+}
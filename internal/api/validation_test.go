@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/middleware"
+)
+
+func TestBindRequest(t *testing.T) {
+	type createRequest struct {
+		Name   string   `json:"name" validate:"required,min=3"`
+		Status string   `json:"status" validate:"omitempty,oneof=active inactive"`
+		Tags   []string `json:"tags" validate:"omitempty,max=2"`
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		var req createRequest
+		if err := bindRequest(c, &req); err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"success": true, "data": req})
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+		expectedFields []string
+	}{
+		{"valid body", `{"name":"policy","status":"active"}`, fiber.StatusOK, "", nil},
+		{"malformed body", `{"name":`, fiber.StatusBadRequest, "INVALID_REQUEST", nil},
+		{"missing field", `{}`, fiber.StatusBadRequest, "VALIDATION_ERROR", []string{"name"}},
+		{"multiple invalid fields", `{"name":"ab","status":"deleted","tags":["a","b","c"]}`, fiber.StatusBadRequest, "VALIDATION_ERROR", []string{"name", "status", "tags"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedCode == "" {
+				return
+			}
+
+			var body struct {
+				Error struct {
+					Code    string            `json:"code"`
+					Details map[string]string `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if body.Error.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %s", tt.expectedCode, body.Error.Code)
+			}
+			if len(body.Error.Details) != len(tt.expectedFields) {
+				t.Errorf("Expected %d field errors, got %v", len(tt.expectedFields), body.Error.Details)
+			}
+			for _, field := range tt.expectedFields {
+				if _, ok := body.Error.Details[field]; !ok {
+					t.Errorf("Expected error for field '%s', got %v", field, body.Error.Details)
+				}
+			}
+		})
+	}
+}
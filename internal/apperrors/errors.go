@@ -0,0 +1,140 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error kinds shared by all services. Use errors.Is to test for a kind.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("validation failed")
+	ErrPrecondition = errors.New("precondition failed")
+	ErrUnavailable  = errors.New("service unavailable")
+	ErrTimeout      = errors.New("timed out")
+	ErrInternal     = errors.New("internal error")
+)
+
+// Error is a typed error carrying a stable, machine readable code
+type Error struct {
+	Kind    error       // One of the Err* kinds
+	Code    string      // Stable error code, e.g. TENANT_NOT_FOUND
+	Message string      // Human readable message safe to return to clients
+	Details interface{} // Optional structured details, e.g. field errors
+	Err     error       // Underlying cause, never returned to clients
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is and errors.As
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// WithDetails attaches structured details to the error
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// WithCause records the underlying cause of the error
+func (e *Error) WithCause(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// New creates a typed error of the given kind
+func New(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NotFound creates an error for a missing resource
+func NotFound(code, message string) *Error {
+	return New(ErrNotFound, code, message)
+}
+
+// Conflict creates an error for a request that conflicts with existing state
+func Conflict(code, message string) *Error {
+	return New(ErrConflict, code, message)
+}
+
+// Forbidden creates an error for an operation the caller may not perform
+func Forbidden(code, message string) *Error {
+	return New(ErrForbidden, code, message)
+}
+
+// Unauthorized creates an error for missing or invalid credentials
+func Unauthorized(code, message string) *Error {
+	return New(ErrUnauthorized, code, message)
+}
+
+// Validation creates an error for invalid input
+func Validation(code, message string) *Error {
+	return New(ErrValidation, code, message)
+}
+
+// PreconditionFailed creates an error for a conditional request whose precondition does not hold
+func PreconditionFailed(code, message string) *Error {
+	return New(ErrPrecondition, code, message)
+}
+
+// Unavailable creates an error for a request that cannot be served while a
+// dependency, e.g. the identity provider, is down
+func Unavailable(code, message string) *Error {
+	return New(ErrUnavailable, code, message)
+}
+
+// Timeout creates an error for a request that did not complete in time, e.g.
+// while waiting on a slow dependency
+func Timeout(code, message string) *Error {
+	return New(ErrTimeout, code, message)
+}
+
+// Wrap converts an unexpected error into an internal error with the given code.
+// Typed errors are returned unchanged so their status and code are preserved.
+func Wrap(err error, code, message string) error {
+	if err == nil {
+		return nil
+	}
+	var typed *Error
+	if errors.As(err, &typed) {
+		return err
+	}
+	return &Error{Kind: ErrInternal, Code: code, Message: message, Err: err}
+}
+
+// HTTPStatus returns the HTTP status code for an error
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrPrecondition):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
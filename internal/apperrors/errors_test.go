@@ -0,0 +1,62 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	cause := errors.New("record not found")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"not found", NotFound("TENANT_NOT_FOUND", "Tenant not found"), http.StatusNotFound},
+		{"conflict", Conflict("TENANT_SLUG_EXISTS", "Slug taken"), http.StatusConflict},
+		{"forbidden", Forbidden("SYSTEM_POLICY_IMMUTABLE", "System policy"), http.StatusForbidden},
+		{"unauthorized", Unauthorized("INVALID_REFRESH_TOKEN", "Invalid token"), http.StatusUnauthorized},
+		{"precondition failed", PreconditionFailed("ETAG_MISMATCH", "Resource was modified"), http.StatusPreconditionFailed},
+		{"unavailable", Unavailable("IDENTITY_PROVIDER_UNAVAILABLE", "Try again shortly"), http.StatusServiceUnavailable},
+		{"timeout", Timeout("REQUEST_TIMEOUT", "The request timed out"), http.StatusGatewayTimeout},
+		{"validation", Validation("INVALID_SLUG", "Invalid slug").WithCause(cause), http.StatusBadRequest},
+		{"wrapped typed error", fmt.Errorf("context: %w", NotFound("USER_NOT_FOUND", "User not found")), http.StatusNotFound},
+		{"untyped error", cause, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := HTTPStatus(tt.err); status != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, status)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(nil, "FAILED", "Failed") != nil {
+		t.Error("Expected nil for nil error")
+	}
+
+	notFound := NotFound("POLICY_NOT_FOUND", "Policy not found")
+	if err := Wrap(notFound, "POLICY_RETRIEVAL_FAILED", "Failed to retrieve policy"); err != notFound {
+		t.Errorf("Expected typed error to be returned unchanged, got %v", err)
+	}
+
+	cause := errors.New("connection refused")
+	err := Wrap(cause, "POLICY_RETRIEVAL_FAILED", "Failed to retrieve policy")
+
+	var typed *Error
+	if !errors.As(err, &typed) {
+		t.Fatalf("Expected *Error, got %T", err)
+	}
+	if typed.Code != "POLICY_RETRIEVAL_FAILED" {
+		t.Errorf("Expected code POLICY_RETRIEVAL_FAILED, got %s", typed.Code)
+	}
+	if !errors.Is(err, ErrInternal) || !errors.Is(err, cause) {
+		t.Error("Expected wrapped error to match both ErrInternal and its cause")
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+// Access scopes of user tokens. Platform-scoped users operate across tenants,
+// e.g. listing all tenants and managing global bundles, while tenant-scoped
+// users are confined to the tenant of their token.
+const (
+	AccessScopePlatform = "platform"
+	AccessScopeTenant   = "tenant"
+)
+
+// PlatformRole is the system role granting the platform scope. Tenants cannot
+// create roles of this name; it is created by bootstrapping.
+const PlatformRole = "super_admin"
+
+// AccessScopeOf returns the access scope of a user with the roles
+func AccessScopeOf(roles []string) string {
+	for _, role := range roles {
+		if role == PlatformRole {
+			return AccessScopePlatform
+		}
+	}
+	return AccessScopeTenant
+}
+
+// IsPlatformScope reports whether the token operates across tenants. Tokens
+// issued without an access scope are tenant-scoped.
+func (c *TokenClaims) IsPlatformScope() bool {
+	return c.AccessScope == AccessScopePlatform
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// BreakGlassTokenType is the type of break-glass tokens
+const BreakGlassTokenType = "break_glass"
+
+// BreakGlassVerifier verifies break-glass tokens. Operators sign them offline
+// with a key Heimdall never holds, so they are accepted without FusionAuth or a
+// session, and only live for a short time.
+type BreakGlassVerifier struct {
+	publicKey *rsa.PublicKey
+	keyID     string
+	maxTTL    time.Duration
+}
+
+// NewBreakGlassVerifier loads the public key verifying break-glass tokens
+func NewBreakGlassVerifier(cfg *config.BreakGlassConfig) (*BreakGlassVerifier, error) {
+	publicKeyData, err := os.ReadFile(cfg.PublicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read break-glass public key: %w", err)
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse break-glass public key: %w", err)
+	}
+
+	return &BreakGlassVerifier{
+		publicKey: publicKey,
+		keyID:     KeyThumbprint(publicKey),
+		maxTTL:    cfg.MaxTTL,
+	}, nil
+}
+
+// KeyID returns the key ID break-glass tokens must name in their kid header
+func (v *BreakGlassVerifier) KeyID() string {
+	return v.keyID
+}
+
+// Verify validates a break-glass token and returns its claims. Tokens must be
+// signed with the break-glass key, name the emergency user, its tenant, the
+// operator and a reason, and expire within the maximum lifetime of being issued.
+func (v *BreakGlassVerifier) Verify(tokenString string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if keyID, _ := token.Header["kid"].(string); keyID != v.keyID {
+			return nil, fmt.Errorf("unknown break-glass key: %s", keyID)
+		}
+		return v.publicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithIssuedAt())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse break-glass token: %w", err)
+	}
+
+	if claims.Type != BreakGlassTokenType {
+		return nil, fmt.Errorf("invalid token type: expected %s, got %s", BreakGlassTokenType, claims.Type)
+	}
+	if claims.IssuedAt == nil || claims.ExpiresAt.Sub(claims.IssuedAt.Time) > v.maxTTL {
+		return nil, fmt.Errorf("break-glass token must expire within %s of being issued", v.maxTTL)
+	}
+	if _, err := uuid.Parse(claims.UserID); err != nil {
+		return nil, fmt.Errorf("break-glass token has an invalid user ID")
+	}
+	if _, err := uuid.Parse(claims.TenantID); err != nil {
+		return nil, fmt.Errorf("break-glass token has an invalid tenant ID")
+	}
+	if claims.ID == "" || claims.Operator == "" || claims.Reason == "" {
+		return nil, fmt.Errorf("break-glass token must have an ID, an operator and a reason")
+	}
+
+	return claims, nil
+}
+
+// IssueBreakGlassToken signs a break-glass token for an emergency user of a
+// tenant with the offline break-glass key. It is used by operators' tooling,
+// never by the server.
+func IssueBreakGlassToken(privateKey *rsa.PrivateKey, userID, tenantID, operator, reason string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &TokenClaims{
+		UserID:   userID,
+		TenantID: tenantID,
+		Type:     BreakGlassTokenType,
+		Operator: operator,
+		Reason:   reason,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	return signToken(claims, privateKey, KeyThumbprint(&privateKey.PublicKey))
+}
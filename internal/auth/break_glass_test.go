@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+const (
+	breakGlassUserID   = "550e8400-e29b-41d4-a716-446655440000"
+	breakGlassTenantID = "660e8400-e29b-41d4-a716-446655440000"
+)
+
+func TestBreakGlassVerifier(t *testing.T) {
+	privateKeyPEM, publicKeyPEM, err := GenerateRSAKeyPEM(2048)
+	if err != nil {
+		t.Fatalf("Failed to generate break-glass key: %v", err)
+	}
+	publicKeyPath := filepath.Join(t.TempDir(), "break_glass_public.pem")
+	if err := os.WriteFile(publicKeyPath, []byte(publicKeyPEM), 0o644); err != nil {
+		t.Fatalf("Failed to write break-glass public key: %v", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+	if err != nil {
+		t.Fatalf("Failed to parse break-glass private key: %v", err)
+	}
+
+	verifier, err := NewBreakGlassVerifier(&config.BreakGlassConfig{PublicKeyPath: publicKeyPath, MaxTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to create break-glass verifier: %v", err)
+	}
+
+	token, err := IssueBreakGlassToken(privateKey, breakGlassUserID, breakGlassTenantID, "alice", "INC-1234", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to issue break-glass token: %v", err)
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Expected the break-glass token to be accepted: %v", err)
+	}
+	if claims.UserID != breakGlassUserID || claims.TenantID != breakGlassTenantID || claims.Operator != "alice" || claims.Reason != "INC-1234" || claims.ID == "" {
+		t.Errorf("Unexpected break-glass claims: %+v", claims)
+	}
+
+	// Break-glass tokens are no access tokens, and access tokens no break-glass tokens
+	jwtService, cleanup := CreateTestJWTService(t)
+	defer cleanup()
+	if _, err := jwtService.ValidateAccessToken(token); err == nil {
+		t.Error("Expected the break-glass token to be rejected as an access token")
+	}
+	tokens, err := jwtService.GenerateTokenPair(breakGlassUserID, breakGlassTenantID, "alice@example.com", []string{"admin"})
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+	if _, err := verifier.Verify(tokens.AccessToken); err == nil {
+		t.Error("Expected an access token to be rejected as a break-glass token")
+	}
+
+	otherPrivateKeyPEM, _, err := GenerateRSAKeyPEM(2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	otherPrivateKey, _ := jwt.ParseRSAPrivateKeyFromPEM([]byte(otherPrivateKeyPEM))
+
+	tests := []struct {
+		name  string
+		token func() (string, error)
+		err   string
+	}{
+		{"lifetime above the maximum", func() (string, error) {
+			return IssueBreakGlassToken(privateKey, breakGlassUserID, breakGlassTenantID, "alice", "INC-1234", 2*time.Hour)
+		}, "must expire within"},
+		{"expired", func() (string, error) {
+			return IssueBreakGlassToken(privateKey, breakGlassUserID, breakGlassTenantID, "alice", "INC-1234", -time.Minute)
+		}, "expired"},
+		{"signed with another key", func() (string, error) {
+			return IssueBreakGlassToken(otherPrivateKey, breakGlassUserID, breakGlassTenantID, "alice", "INC-1234", 30*time.Minute)
+		}, "unknown break-glass key"},
+		{"without a reason", func() (string, error) {
+			return IssueBreakGlassToken(privateKey, breakGlassUserID, breakGlassTenantID, "alice", "", 30*time.Minute)
+		}, "reason"},
+		{"with an invalid user", func() (string, error) {
+			return IssueBreakGlassToken(privateKey, "root", breakGlassTenantID, "alice", "INC-1234", 30*time.Minute)
+		}, "invalid user ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.token()
+			if err != nil {
+				t.Fatalf("Failed to issue break-glass token: %v", err)
+			}
+			if _, err := verifier.Verify(token); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
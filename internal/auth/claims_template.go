@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrTokenTooLarge is returned when an access token exceeds its tenant's size
+// limit even without the optional template claims
+var ErrTokenTooLarge = errors.New("access token exceeds the size limit")
+
+// ReservedClaims are the claim names set by Heimdall, which custom claims cannot use
+var ReservedClaims = map[string]bool{
+	"userId": true, "tenantId": true, "email": true, "roles": true, "type": true, "attrs": true, "accessScope": true,
+	"permissions": true, "permissionsOmitted": true, "tenant": true, "scope": true, "act": true, "clientId": true,
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+}
+
+// TemplateClaims are the claims a tenant's claims template embeds in an access token
+type TemplateClaims struct {
+	Roles       []string               // Replaces the user's roles
+	Permissions []string               // Nil to leave permissions to server-side lookups
+	Tenant      map[string]interface{} // Tenant metadata
+	Custom      map[string]interface{} // Top-level custom claims
+	MaxSize     int                    // Maximum size of the encoded token in bytes, 0 for no limit
+}
+
+// ClaimsTemplateStore resolves tenant claims templates
+type ClaimsTemplateStore interface {
+	// AccessTokenClaims returns the claims of a user's access token under the
+	// tenant's template, or nil if the tenant has no template
+	AccessTokenClaims(userID, tenantID string, roles []string) (*TemplateClaims, error)
+
+	// Audiences returns the audiences the tenant registered in addition to
+	// Heimdall's, e.g. its own services, or nil if it has no template
+	Audiences(tenantID string) ([]string, error)
+}
+
+// tokenClaimsJSON has the fields of TokenClaims without its JSON methods
+type tokenClaimsJSON TokenClaims
+
+// MarshalJSON encodes the claims with the custom claims at the top level
+func (c TokenClaims) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal(tokenClaimsJSON(c))
+	if err != nil || len(c.Custom) == 0 {
+		return encoded, err
+	}
+
+	merged := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(encoded, &merged); err != nil {
+		return nil, err
+	}
+	for name, value := range c.Custom {
+		if ReservedClaims[name] {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		merged[name] = raw
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON decodes the claims, collecting unknown claims into Custom
+func (c *TokenClaims) UnmarshalJSON(data []byte) error {
+	var decoded tokenClaimsJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for name, raw := range all {
+		if ReservedClaims[name] {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		if decoded.Custom == nil {
+			decoded.Custom = make(map[string]interface{})
+		}
+		decoded.Custom[name] = value
+	}
+
+	*c = TokenClaims(decoded)
+	return nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// DevIdentityProvider accepts any password, for local development only. Users
+// registered through it are stored like the native provider's, and users
+// seeded directly into Heimdall's database, e.g. by a fixture, sign in by
+// email alone.
+type DevIdentityProvider struct {
+	*NativeIdentityProvider
+}
+
+var _ IdentityProvider = (*DevIdentityProvider)(nil)
+
+// NewDevIdentityProvider creates a new development identity provider
+func NewDevIdentityProvider(db *gorm.DB) *DevIdentityProvider {
+	return &DevIdentityProvider{NativeIdentityProvider: NewNativeIdentityProvider(db, nil)}
+}
+
+// Login signs in active users by email, whatever the password
+func (p *DevIdentityProvider) Login(ctx context.Context, req *LoginRequest) (*IdentityUser, error) {
+	email := normalizeEmail(req.Email)
+	credential, err := p.find(ctx, "email = ?", email)
+	if err == nil {
+		if !credential.Active {
+			return nil, ErrInvalidCredentials
+		}
+		return toIdentityUser(credential), nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	var user models.User
+	err = p.db.WithContext(ctx).Where("lower(email) = ? AND status = ?", email, "active").Order("created_at").First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &IdentityUser{ID: user.ID.String(), Email: user.Email, Active: true, Verified: true}, nil
+}
+
+// ChangePassword accepts any current password
+func (p *DevIdentityProvider) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	credential, err := p.find(ctx, "user_id = ?", userID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return p.setPassword(ctx, credential, newPassword)
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+)
+
+func TestDevIdentityProvider_Login(t *testing.T) {
+	ctx := context.Background()
+	native, _ := newTestNativeProvider(t)
+	provider := &DevIdentityProvider{NativeIdentityProvider: native}
+
+	userID := uuid.NewString()
+	if _, err := provider.Register(ctx, &RegisterRequest{UserID: userID, Email: "alice@example.com", Password: "SecurePassword123!"}); err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if user, err := provider.Login(ctx, &LoginRequest{Email: "Alice@example.com", Password: "anything"}); err != nil || user.ID != userID {
+		t.Errorf("Login = (%+v, %v), want the registered user with any password", user, err)
+	}
+
+	// Users seeded without credentials sign in by email
+	tenant := models.Tenant{Name: "Acme", Slug: "acme", Status: "active", Settings: []byte("{}")}
+	if err := native.db.Create(&tenant).Error; err != nil {
+		t.Fatalf("Failed to seed tenant: %v", err)
+	}
+	seeded := models.User{ID: uuid.New(), TenantID: tenant.ID, Email: "bob@example.com", Status: models.UserStatusActive}
+	if err := native.db.Create(&seeded).Error; err != nil {
+		t.Fatalf("Failed to seed user: %v", err)
+	}
+	if user, err := provider.Login(ctx, &LoginRequest{Email: "bob@example.com"}); err != nil || user.ID != seeded.ID.String() {
+		t.Errorf("Login = (%+v, %v), want the seeded user", user, err)
+	}
+
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "nobody@example.com"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown email, got %v", err)
+	}
+
+	// Deactivated users cannot log in
+	if _, err := provider.UpdateUser(ctx, userID, map[string]interface{}{"active": false}); err != nil {
+		t.Fatalf("UpdateUser returned error: %v", err)
+	}
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "alice@example.com"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a deactivated user, got %v", err)
+	}
+}
@@ -2,14 +2,18 @@ package auth
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/resilience"
 )
 
 // FusionAuthClient wraps FusionAuth API interactions
@@ -19,8 +23,13 @@ type FusionAuthClient struct {
 	tenantID      string
 	applicationID string
 	httpClient    *http.Client
+	maxRetries    int
+	retryBackoff  time.Duration
+	breaker       *resilience.Breaker
 }
 
+var _ IdentityProvider = (*FusionAuthClient)(nil)
+
 // NewFusionAuthClient creates a new FusionAuth client
 func NewFusionAuthClient(cfg *config.AuthConfig) *FusionAuthClient {
 	return &FusionAuthClient{
@@ -29,13 +38,17 @@ func NewFusionAuthClient(cfg *config.AuthConfig) *FusionAuthClient {
 		tenantID:      cfg.TenantID,
 		applicationID: cfg.ApplicationID,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: cfg.Timeout,
 		},
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		breaker:      resilience.NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
 	}
 }
 
 // RegisterRequest represents a user registration request
 type RegisterRequest struct {
+	UserID    string `json:"-"` // Generated when empty
 	Email     string `json:"email"`
 	Password  string `json:"password"`
 	FirstName string `json:"firstName"`
@@ -48,25 +61,83 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-// FusionAuthUser represents a FusionAuth user
-type FusionAuthUser struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Active    bool   `json:"active"`
-	Verified  bool   `json:"verified"`
+// FusionAuthRegistration represents a user's registration to an application
+type FusionAuthRegistration struct {
+	ApplicationID string `json:"applicationId"`
+}
+
+// APIError is returned when FusionAuth responds with a non-2xx status
+type APIError struct {
+	StatusCode    int
+	Body          string
+	FieldErrors   map[string][]ErrorDetail // Validation errors by field, e.g. user.email
+	GeneralErrors []ErrorDetail
+}
+
+// ErrorDetail is a single error reported by FusionAuth
+type ErrorDetail struct {
+	Code    string `json:"code"` // e.g. [duplicate]user.email
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("FusionAuth API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// Unwrap maps the error to the provider errors callers test for with errors.Is
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrUserNotFound
+	case e.StatusCode == http.StatusLocked:
+		return ErrAccountLocked
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrProviderUnavailable
+	case e.hasCode("[duplicate]user.email"):
+		return ErrEmailTaken
+	}
+	return nil
+}
+
+// hasCode reports whether FusionAuth reported an error with the given code
+func (e *APIError) hasCode(code string) bool {
+	for _, details := range e.FieldErrors {
+		for _, detail := range details {
+			if detail.Code == code {
+				return true
+			}
+		}
+	}
+	for _, detail := range e.GeneralErrors {
+		if detail.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakerStats returns the state of the circuit breaker guarding FusionAuth
+func (c *FusionAuthClient) BreakerStats() resilience.BreakerStats {
+	return c.breaker.Snapshot()
+}
+
+// ApplicationID returns the FusionAuth application users are registered to
+func (c *FusionAuthClient) ApplicationID() string {
+	return c.applicationID
 }
 
 // FusionAuthResponse represents a generic FusionAuth API response
 type FusionAuthResponse struct {
-	User  *FusionAuthUser `json:"user,omitempty"`
-	Token string          `json:"token,omitempty"`
+	User  *IdentityUser `json:"user,omitempty"`
+	Token string        `json:"token,omitempty"`
 }
 
 // Register creates a new user in FusionAuth
-func (c *FusionAuthClient) Register(req *RegisterRequest) (*FusionAuthUser, error) {
-	userID := uuid.New().String()
+func (c *FusionAuthClient) Register(ctx context.Context, req *RegisterRequest) (*IdentityUser, error) {
+	userID := req.UserID
+	if userID == "" {
+		userID = uuid.New().String()
+	}
 
 	payload := map[string]interface{}{
 		"user": map[string]interface{}{
@@ -83,7 +154,7 @@ func (c *FusionAuthClient) Register(req *RegisterRequest) (*FusionAuthUser, erro
 		"skipVerification":     false,
 	}
 
-	resp, err := c.doRequest("POST", "/api/user/registration", payload)
+	resp, err := c.doRequest(ctx, "POST", "/api/user/registration", payload)
 	if err != nil {
 		return nil, err
 	}
@@ -97,14 +168,25 @@ func (c *FusionAuthClient) Register(req *RegisterRequest) (*FusionAuthUser, erro
 }
 
 // Login authenticates a user
-func (c *FusionAuthClient) Login(req *LoginRequest) (*FusionAuthUser, error) {
+func (c *FusionAuthClient) Login(ctx context.Context, req *LoginRequest) (*IdentityUser, error) {
 	payload := map[string]interface{}{
 		"loginId":       req.Email,
 		"password":      req.Password,
 		"applicationId": c.applicationID,
 	}
 
-	resp, err := c.doRequest("POST", "/api/login", payload)
+	// A failed login changes nothing, so it is retried like an idempotent request
+	resp, err := c.send(ctx, "POST", "/api/login", payload, true)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			// FusionAuth answers 404 for an unknown email and a wrong password alike
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+		case http.StatusConflict, http.StatusLocked:
+			return nil, fmt.Errorf("%w: %w", ErrAccountLocked, err)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -118,8 +200,8 @@ func (c *FusionAuthClient) Login(req *LoginRequest) (*FusionAuthUser, error) {
 }
 
 // GetUser retrieves a user by ID
-func (c *FusionAuthClient) GetUser(userID string) (*FusionAuthUser, error) {
-	resp, err := c.doRequest("GET", fmt.Sprintf("/api/user/%s", userID), nil)
+func (c *FusionAuthClient) GetUser(ctx context.Context, userID string) (*IdentityUser, error) {
+	resp, err := c.doRequest(ctx, "GET", fmt.Sprintf("/api/user/%s", userID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -132,14 +214,44 @@ func (c *FusionAuthClient) GetUser(userID string) (*FusionAuthUser, error) {
 	return result.User, nil
 }
 
+// SearchUsers returns a page of all users in the tenant, ordered by ID, and the
+// total number of users
+func (c *FusionAuthClient) SearchUsers(ctx context.Context, startRow, numberOfResults int) ([]IdentityUser, int, error) {
+	payload := map[string]interface{}{
+		"search": map[string]interface{}{
+			"queryString":     "*",
+			"startRow":        startRow,
+			"numberOfResults": numberOfResults,
+			"sortFields": []map[string]string{
+				{"name": "id", "order": "asc"},
+			},
+		},
+	}
+
+	resp, err := c.send(ctx, "POST", "/api/user/search", payload, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result struct {
+		Total int              `json:"total"`
+		Users []IdentityUser `json:"users"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Users, result.Total, nil
+}
+
 // UpdateUser updates a user's information using PATCH for partial updates
-func (c *FusionAuthClient) UpdateUser(userID string, updates map[string]interface{}) (*FusionAuthUser, error) {
+func (c *FusionAuthClient) UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) (*IdentityUser, error) {
 	payload := map[string]interface{}{
 		"user": updates,
 	}
 
 	// Use PATCH for partial updates (PUT requires email/username)
-	resp, err := c.doRequest("PATCH", fmt.Sprintf("/api/user/%s", userID), payload)
+	resp, err := c.doRequest(ctx, "PATCH", fmt.Sprintf("/api/user/%s", userID), payload)
 	if err != nil {
 		return nil, err
 	}
@@ -153,83 +265,159 @@ func (c *FusionAuthClient) UpdateUser(userID string, updates map[string]interfac
 }
 
 // DeleteUser deletes a user
-func (c *FusionAuthClient) DeleteUser(userID string) error {
-	_, err := c.doRequest("DELETE", fmt.Sprintf("/api/user/%s", userID), nil)
+func (c *FusionAuthClient) DeleteUser(ctx context.Context, userID string) error {
+	_, err := c.doRequest(ctx, "DELETE", fmt.Sprintf("/api/user/%s", userID), nil)
 	return err
 }
 
 // ChangePassword changes a user's password
-func (c *FusionAuthClient) ChangePassword(userID, currentPassword, newPassword string) error {
+func (c *FusionAuthClient) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
 	payload := map[string]interface{}{
 		"currentPassword": currentPassword,
 		"password":        newPassword,
 	}
 
-	_, err := c.doRequest("POST", fmt.Sprintf("/api/user/change-password/%s", userID), payload)
+	_, err := c.doRequest(ctx, "POST", fmt.Sprintf("/api/user/change-password/%s", userID), payload)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		// FusionAuth answers 404 when the current password does not match
+		return fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
 	return err
 }
 
 // ForgotPassword initiates a password reset
-func (c *FusionAuthClient) ForgotPassword(email string) error {
+func (c *FusionAuthClient) ForgotPassword(ctx context.Context, email string) error {
 	payload := map[string]interface{}{
 		"loginId":       email,
 		"applicationId": c.applicationID,
 		"sendForgotPasswordEmail": true,
 	}
 
-	_, err := c.doRequest("POST", "/api/user/forgot-password", payload)
+	_, err := c.doRequest(ctx, "POST", "/api/user/forgot-password", payload)
 	return err
 }
 
 // VerifyEmail sends a verification email
-func (c *FusionAuthClient) VerifyEmail(email string) error {
+func (c *FusionAuthClient) VerifyEmail(ctx context.Context, email string) error {
 	payload := map[string]interface{}{
 		"email":         email,
 		"applicationId": c.applicationID,
 	}
 
-	_, err := c.doRequest("PUT", "/api/user/verify-email", payload)
+	_, err := c.doRequest(ctx, "PUT", "/api/user/verify-email", payload)
 	return err
 }
 
 // DeactivateUser deactivates a user account
-func (c *FusionAuthClient) DeactivateUser(userID string) error {
+func (c *FusionAuthClient) DeactivateUser(ctx context.Context, userID string) error {
 	payload := map[string]interface{}{
 		"user": map[string]interface{}{
 			"active": false,
 		},
 	}
 
-	_, err := c.doRequest("PUT", fmt.Sprintf("/api/user/%s", userID), payload)
+	_, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/api/user/%s", userID), payload)
 	return err
 }
 
 // ReactivateUser reactivates a user account
-func (c *FusionAuthClient) ReactivateUser(userID string) error {
+func (c *FusionAuthClient) ReactivateUser(ctx context.Context, userID string) error {
 	payload := map[string]interface{}{
 		"user": map[string]interface{}{
 			"active": true,
 		},
 	}
 
-	_, err := c.doRequest("PUT", fmt.Sprintf("/api/user/%s", userID), payload)
+	_, err := c.doRequest(ctx, "PUT", fmt.Sprintf("/api/user/%s", userID), payload)
 	return err
 }
 
-// doRequest performs an HTTP request to FusionAuth
-func (c *FusionAuthClient) doRequest(method, path string, body interface{}) ([]byte, error) {
-	url := c.baseURL + path
+// doRequest performs an HTTP request to FusionAuth. Only requests with an
+// idempotent method are retried.
+func (c *FusionAuthClient) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	return c.send(ctx, method, path, body, method != http.MethodPost)
+}
 
-	var reqBody io.Reader
+// send performs an HTTP request to FusionAuth through the circuit breaker.
+// Idempotent requests are retried while FusionAuth refuses connections or
+// answers with a 5xx status; timed out requests are not, so a slow FusionAuth
+// does not hold callers for several timeouts. Other requests are only retried
+// when the connection could not be established, so FusionAuth never saw them.
+// The request and its retries are abandoned once ctx is done.
+func (c *FusionAuthClient) send(ctx context.Context, method, path string, body interface{}, idempotent bool) ([]byte, error) {
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
+		var err error
+		if jsonData, err = json.Marshal(body); err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	if err := c.breaker.Allow(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	for attempt := 1; ; attempt++ {
+		respBody, err := c.attempt(ctx, method, c.baseURL+path, jsonData)
+		if attempt <= c.maxRetries && ctx.Err() == nil && retryable(err, idempotent) {
+			c.breaker.Retried()
+			select {
+			case <-ctx.Done():
+				c.breaker.Abandon()
+				return nil, ctx.Err()
+			case <-time.After(resilience.RetryDelay(c.retryBackoff, attempt)):
+			}
+			continue
+		}
+
+		var apiErr *APIError
+		switch {
+		case ctx.Err() != nil:
+			// The caller gave up, which says nothing about FusionAuth
+			c.breaker.Abandon()
+			return nil, ctx.Err()
+		case err == nil:
+			c.breaker.Record(nil)
+		case errors.As(err, &apiErr):
+			// A 4xx rejection still shows FusionAuth is up
+			if apiErr.StatusCode >= http.StatusInternalServerError {
+				c.breaker.Record(err)
+			} else {
+				c.breaker.Record(nil)
+			}
+		default:
+			c.breaker.Record(err)
+			err = fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+		}
+		return respBody, err
+	}
+}
+
+// retryable reports whether a failed attempt may succeed when retried
+func retryable(err error, idempotent bool) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return idempotent && apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var netErr net.Error
+	return idempotent && !(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// attempt performs a single HTTP request to FusionAuth
+func (c *FusionAuthClient) attempt(ctx context.Context, method, url string, jsonData []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -256,7 +444,16 @@ func (c *FusionAuthClient) doRequest(method, path string, body interface{}) ([]b
 
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("FusionAuth API error (status %d): %s", resp.StatusCode, string(respBody))
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		var parsed struct {
+			FieldErrors   map[string][]ErrorDetail `json:"fieldErrors"`
+			GeneralErrors []ErrorDetail            `json:"generalErrors"`
+		}
+		if json.Unmarshal(respBody, &parsed) == nil {
+			apiErr.FieldErrors = parsed.FieldErrors
+			apiErr.GeneralErrors = parsed.GeneralErrors
+		}
+		return nil, apiErr
 	}
 
 	return respBody, nil
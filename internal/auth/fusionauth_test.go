@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/resilience"
+)
+
+func newTestFusionAuthClient(url string) *FusionAuthClient {
+	return NewFusionAuthClient(&config.AuthConfig{
+		URL: url, APIKey: "key", ApplicationID: "app", Timeout: time.Second,
+		MaxRetries: 2, RetryBackoff: time.Millisecond,
+		BreakerThreshold: 2, BreakerCooldown: time.Minute,
+	})
+}
+
+func TestFusionAuthErrorMapping(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/user/registration":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"fieldErrors":{"user.email":[{"code":"[duplicate]user.email","message":"A User with email already exists."}]}}`))
+		case "/api/login":
+			w.WriteHeader(http.StatusNotFound)
+		case "/api/user/change-password/locked":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusLocked)
+		}
+	}))
+	defer server.Close()
+	client := newTestFusionAuthClient(server.URL)
+
+	_, err := client.Register(ctx, &RegisterRequest{Email: "taken@example.com", Password: "secret"})
+	if !errors.Is(err, ErrEmailTaken) || !IsRejected(err) {
+		t.Errorf("Expected a duplicate email to map to ErrEmailTaken, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.FieldErrors["user.email"]) != 1 {
+		t.Errorf("Expected the field errors to be parsed, got %+v", apiErr)
+	}
+
+	if _, err := client.Login(ctx, &LoginRequest{Email: "a@example.com", Password: "wrong"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected a failed login to map to ErrInvalidCredentials, got %v", err)
+	}
+	if err := client.ChangePassword(ctx, "locked", "wrong", "new"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected a wrong current password to map to ErrInvalidCredentials, got %v", err)
+	}
+	if _, err := client.GetUser(ctx, "locked"); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("Expected 423 to map to ErrAccountLocked, got %v", err)
+	}
+
+	// Rejections show FusionAuth is up and do not open the breaker
+	if stats := client.BreakerStats(); stats.State != resilience.BreakerClosed || stats.Failures != 0 {
+		t.Errorf("Expected rejections not to count as failures, got %+v", stats)
+	}
+}
+
+func TestFusionAuthLoginLocked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	_, err := newTestFusionAuthClient(server.URL).Login(context.Background(), &LoginRequest{Email: "a@example.com", Password: "secret"})
+	if !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("Expected a locked login to map to ErrAccountLocked, got %v", err)
+	}
+}
+
+func TestFusionAuthRetriesAndCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client := newTestFusionAuthClient(server.URL)
+
+	// Reads are retried while FusionAuth is unavailable
+	if _, err := client.GetUser(ctx, "user"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("Expected ErrProviderUnavailable, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls.Load())
+	}
+
+	// Registrations are not retried, and consecutive failures open the breaker
+	_, err := client.Register(ctx, &RegisterRequest{Email: "a@example.com", Password: "secret"})
+	if !errors.Is(err, ErrProviderUnavailable) || IsRejected(err) || IsNotSent(err) {
+		t.Errorf("Expected a failed registration with an unknown outcome, got %v", err)
+	}
+	if calls.Load() != 4 {
+		t.Errorf("Expected the registration not to be retried, got %d calls", calls.Load())
+	}
+
+	_, err = client.Login(ctx, &LoginRequest{Email: "a@example.com", Password: "secret"})
+	if !errors.Is(err, ErrProviderUnavailable) || !IsNotSent(err) {
+		t.Errorf("Expected the open breaker to fail fast, got %v", err)
+	}
+	stats := client.BreakerStats()
+	if stats.State != resilience.BreakerOpen || stats.Retries != 2 || stats.Rejected != 1 || calls.Load() != 4 {
+		t.Errorf("Unexpected stats after %d calls: %+v", calls.Load(), stats)
+	}
+}
+
+func TestFusionAuthCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	client := newTestFusionAuthClient(server.URL)
+
+	// A caller's deadline aborts a slow request without counting it against FusionAuth
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetUser(ctx, "user"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the request to end with its context, got %v", err)
+	}
+	if stats := client.BreakerStats(); stats.Failures != 0 || stats.Retries != 0 {
+		t.Errorf("Expected an abandoned request neither to fail nor to be retried, got %+v", stats)
+	}
+}
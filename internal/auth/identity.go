@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"github.com/techsavvyash/heimdall/internal/resilience"
+	"gorm.io/gorm"
+)
+
+// Supported identity providers
+const (
+	ProviderFusionAuth = "fusionauth"
+	ProviderNative     = "native" // Credentials stored in Heimdall's database
+	ProviderDev        = "dev"    // Any password accepted, only in development mode
+)
+
+// Errors returned by identity providers
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrAccountLocked      = errors.New("account is locked")
+	// ErrProviderUnavailable means the provider could not be reached or failed,
+	// so the request may succeed when tried again later
+	ErrProviderUnavailable = errors.New("identity provider is unavailable")
+)
+
+// IdentityProvider stores user credentials and authenticates users. Heimdall keeps
+// tenants, roles and user metadata itself and delegates identities to a provider.
+type IdentityProvider interface {
+	// Register creates a user, using req.UserID as its ID when set
+	Register(ctx context.Context, req *RegisterRequest) (*IdentityUser, error)
+	// Login checks a user's credentials
+	Login(ctx context.Context, req *LoginRequest) (*IdentityUser, error)
+	GetUser(ctx context.Context, userID string) (*IdentityUser, error)
+	// UpdateUser applies a partial update of firstName, lastName, email or active
+	UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) (*IdentityUser, error)
+	DeleteUser(ctx context.Context, userID string) error
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error
+	// ForgotPassword starts a password reset and emails the user
+	ForgotPassword(ctx context.Context, email string) error
+}
+
+// PasswordResetter is implemented by identity providers whose password resets
+// are completed through Heimdall, with the token ForgotPassword emailed
+type PasswordResetter interface {
+	// ResetTokenUser returns the user a valid reset token was issued to, or
+	// ErrInvalidCredentials
+	ResetTokenUser(ctx context.Context, token string) (*IdentityUser, error)
+	// ResetPassword sets a new password using a reset token
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// IdentityUser represents a user as stored by the identity provider
+type IdentityUser struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Active    bool   `json:"active"`
+	Verified  bool   `json:"verified"`
+
+	Registrations []FusionAuthRegistration `json:"registrations,omitempty"`
+}
+
+// NewIdentityProvider creates the identity provider selected by cfg.Provider.
+// The native provider stores credentials in db and sends password reset emails
+// with mailer, which may be nil.
+func NewIdentityProvider(cfg *config.AuthConfig, db *gorm.DB, mailer notify.Mailer) (IdentityProvider, error) {
+	switch cfg.Provider {
+	case ProviderFusionAuth, "":
+		return NewFusionAuthClient(cfg), nil
+	case ProviderNative:
+		return NewNativeIdentityProvider(db, mailer), nil
+	case ProviderDev:
+		return NewDevIdentityProvider(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported identity provider %q", cfg.Provider)
+	}
+}
+
+// IsNotFound reports whether err means the user does not exist
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 404
+	}
+	return errors.Is(err, ErrUserNotFound)
+}
+
+// IsRejected reports whether the provider rejected a request, meaning it was
+// not applied and retrying it unchanged will not succeed
+func IsRejected(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+	}
+	return errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrEmailTaken) || errors.Is(err, ErrInvalidCredentials) ||
+		errors.Is(err, ErrAccountLocked)
+}
+
+// IsNotSent reports whether a request failed without reaching the provider
+// because its circuit breaker is open, so it was certainly not applied
+func IsNotSent(err error) bool {
+	return errors.Is(err, resilience.ErrCircuitOpen)
+}
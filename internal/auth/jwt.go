@@ -4,6 +4,10 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,9 +17,22 @@ import (
 
 // JWTService handles JWT token operations
 type JWTService struct {
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
-	config     *config.JWTConfig
+	privateKey  *rsa.PrivateKey // Platform key read from files
+	publicKey   *rsa.PublicKey
+	sharedKeyID string
+	keyStore    KeyStore
+	templates   ClaimsTemplateStore
+	config      *config.JWTConfig
+
+	mu       sync.RWMutex
+	platform platformKeys
+}
+
+// platformKeys is the shared platform key set: the primary key signing the
+// tokens of tenants without their own key, and every key verifying them
+type platformKeys struct {
+	primary      *SigningKey
+	verification map[string]*rsa.PublicKey
 }
 
 // TokenClaims represents the JWT claims
@@ -24,7 +41,35 @@ type TokenClaims struct {
 	TenantID string   `json:"tenantId"`
 	Email    string   `json:"email"`
 	Roles    []string `json:"roles,omitempty"`
-	Type     string   `json:"type"` // access or refresh
+	Type     string   `json:"type"` // access, refresh or magic_link
+
+	// AccessScope of access tokens of users: platform or tenant
+	AccessScope string `json:"accessScope,omitempty"`
+
+	// Attributes carries session attributes added by login hooks
+	Attributes map[string]interface{} `json:"attrs,omitempty"`
+
+	// Claims added by the tenant's claims template. PermissionsOmitted is set when
+	// permissions were dropped to respect the size limit and must be looked up
+	// server-side.
+	Permissions        []string               `json:"permissions,omitempty"`
+	PermissionsOmitted bool                   `json:"permissionsOmitted,omitempty"`
+	Tenant             map[string]interface{} `json:"tenant,omitempty"`
+	Custom             map[string]interface{} `json:"-"` // Encoded as top-level claims
+
+	// Set on tokens obtained by token exchange: the space-separated permissions
+	// the token is limited to and the delegation chain it was exchanged through
+	Scope string `json:"scope,omitempty"`
+	Actor *Actor `json:"act,omitempty"`
+
+	// Set on tokens issued to OAuth clients by the client credentials grant,
+	// which have no user and are authorized by their scope alone
+	ClientID string `json:"clientId,omitempty"`
+
+	// Set on break-glass tokens: the operator using emergency access and why
+	Operator string `json:"operator,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+
 	jwt.RegisteredClaims
 }
 
@@ -60,23 +105,101 @@ func NewJWTService(cfg *config.JWTConfig) (*JWTService, error) {
 		return nil, fmt.Errorf("failed to parse public key: %w", err)
 	}
 
-	return &JWTService{
-		privateKey: privateKey,
-		publicKey:  publicKey,
-		config:     cfg,
-	}, nil
+	s := &JWTService{
+		privateKey:  privateKey,
+		publicKey:   publicKey,
+		sharedKeyID: KeyThumbprint(publicKey),
+		config:      cfg,
+	}
+	fileKey := s.FileSigningKey()
+	s.SetPlatformKeys(fileKey, []*SigningKey{fileKey})
+	return s, nil
+}
+
+// SetKeyStore enables tenant-scoped signing keys.
+// Tenants without their own key keep using the shared platform key.
+func (s *JWTService) SetKeyStore(store KeyStore) {
+	s.keyStore = store
+}
+
+// SetClaimsTemplates enables tenant claims templates for access tokens,
+// including the audiences tenants register for their own services
+func (s *JWTService) SetClaimsTemplates(store ClaimsTemplateStore) {
+	s.templates = store
+}
+
+// FileSigningKey returns the platform key read from JWT_PRIVATE_KEY_PATH,
+// which is the primary platform key until the first rotation
+func (s *JWTService) FileSigningKey() *SigningKey {
+	return &SigningKey{KeyID: s.sharedKeyID, PrivateKey: s.privateKey, PublicKey: s.publicKey}
+}
+
+// SetPlatformKeys replaces the shared platform keys: the primary key signing
+// new tokens and the keys verifying existing ones, which include the primary
+func (s *JWTService) SetPlatformKeys(primary *SigningKey, verification []*SigningKey) {
+	keys := platformKeys{
+		primary:      primary,
+		verification: map[string]*rsa.PublicKey{primary.KeyID: primary.PublicKey},
+	}
+	for _, key := range verification {
+		keys.verification[key.KeyID] = key.PublicKey
+	}
+
+	s.mu.Lock()
+	s.platform = keys
+	s.mu.Unlock()
+}
+
+// MaxTokenLifetime returns the lifetime of the longest-lived tokens, after
+// which a retired key no longer verifies any unexpired token
+func (s *JWTService) MaxTokenLifetime() time.Duration {
+	return max(s.config.AccessTokenExpiry, s.config.RefreshTokenExpiry)
+}
+
+// SharedKeyID returns the key ID of the primary shared platform signing key
+func (s *JWTService) SharedKeyID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.platform.primary.KeyID
+}
+
+// SharedJWKS returns the JSON Web Key Set of the shared platform keys, the
+// primary key first
+func (s *JWTService) SharedJWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	primary := s.platform.primary
+	jwks := JWKS{Keys: []JWK{NewJWK(primary.KeyID, primary.PublicKey)}}
+	keyIDs := make([]string, 0, len(s.platform.verification))
+	for keyID := range s.platform.verification {
+		if keyID != primary.KeyID {
+			keyIDs = append(keyIDs, keyID)
+		}
+	}
+	sort.Strings(keyIDs)
+	for _, keyID := range keyIDs {
+		jwks.Keys = append(jwks.Keys, NewJWK(keyID, s.platform.verification[keyID]))
+	}
+	return jwks
 }
 
 // GenerateTokenPair generates both access and refresh tokens
 func (s *JWTService) GenerateTokenPair(userID, tenantID, email string, roles []string) (*TokenPair, error) {
+	return s.GenerateTokenPairWithAttributes(userID, tenantID, email, roles, nil)
+}
+
+// GenerateTokenPairWithAttributes generates tokens carrying additional session attributes.
+// Attributes are included in both tokens so they survive a refresh.
+func (s *JWTService) GenerateTokenPairWithAttributes(userID, tenantID, email string, roles []string, attributes map[string]interface{}) (*TokenPair, error) {
 	// Generate access token
-	accessToken, err := s.generateToken(userID, tenantID, email, roles, "access", s.config.AccessTokenExpiry)
+	accessToken, err := s.generateToken(userID, tenantID, email, roles, attributes, "access", s.config.AccessTokenExpiry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := s.generateToken(userID, tenantID, email, nil, "refresh", s.config.RefreshTokenExpiry)
+	refreshToken, err := s.generateToken(userID, tenantID, email, nil, attributes, "refresh", s.config.RefreshTokenExpiry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -89,31 +212,142 @@ func (s *JWTService) GenerateTokenPair(userID, tenantID, email string, roles []s
 	}, nil
 }
 
-// generateToken generates a JWT token
-func (s *JWTService) generateToken(userID, tenantID, email string, roles []string, tokenType string, expiry time.Duration) (string, error) {
+// generateToken generates a JWT token. Access tokens are shaped by the tenant's
+// claims template, if any.
+func (s *JWTService) generateToken(userID, tenantID, email string, roles []string, attributes map[string]interface{}, tokenType string, expiry time.Duration) (string, error) {
+	if len(attributes) == 0 {
+		attributes = nil
+	}
+
+	// Only access tokens are meant for the tenant's services as well
+	audience := jwt.ClaimStrings{s.config.Audience}
+	if tokenType == "access" {
+		var err error
+		if audience, err = s.Audience(tenantID); err != nil {
+			return "", err
+		}
+	}
+
 	now := time.Now()
-	claims := TokenClaims{
-		UserID:   userID,
-		TenantID: tenantID,
-		Email:    email,
-		Roles:    roles,
-		Type:     tokenType,
+	claims := &TokenClaims{
+		UserID:     userID,
+		TenantID:   tenantID,
+		Email:      email,
+		Roles:      roles,
+		Type:       tokenType,
+		Attributes: attributes,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
 			Subject:   userID,
-			Issuer:    s.config.Issuer,
+			Issuer:    s.Issuer(tenantID),
+			Audience:  audience,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
+	if tokenType == "access" {
+		// From the user's own roles, which a claims template may replace
+		claims.AccessScope = AccessScopeOf(roles)
+	}
+
+	signingKey, keyID, err := s.signingKey(tenantID)
+	if err != nil {
+		return "", err
+	}
+
+	var template *TemplateClaims
+	if s.templates != nil && tokenType == "access" && tenantID != "" {
+		if template, err = s.templates.AccessTokenClaims(userID, tenantID, roles); err != nil {
+			return "", fmt.Errorf("failed to resolve claims template: %w", err)
+		}
+	}
+	if template == nil {
+		return signToken(claims, signingKey, keyID)
+	}
+
+	claims.Roles = template.Roles
+	claims.Permissions = template.Permissions
+	claims.Tenant = template.Tenant
+	claims.Custom = template.Custom
+
+	signedToken, err := signToken(claims, signingKey, keyID)
+	if err != nil || template.MaxSize <= 0 || len(signedToken) <= template.MaxSize {
+		return signedToken, err
+	}
+
+	// Too big: drop the permissions, which are looked up server-side instead
+	if claims.Permissions != nil {
+		claims.Permissions = nil
+		claims.PermissionsOmitted = true
+		if signedToken, err = signToken(claims, signingKey, keyID); err != nil || len(signedToken) <= template.MaxSize {
+			return signedToken, err
+		}
+	}
+
+	// Still too big: drop the tenant metadata and custom claims as well
+	claims.Tenant = nil
+	claims.Custom = nil
+	if signedToken, err = signToken(claims, signingKey, keyID); err != nil || len(signedToken) <= template.MaxSize {
+		return signedToken, err
+	}
+	return "", fmt.Errorf("%w of %d bytes", ErrTokenTooLarge, template.MaxSize)
+}
+
+// Issuer returns the issuer of a tenant's tokens, which is under the platform
+// issuer. Tokens without a tenant are issued by the platform issuer itself.
+func (s *JWTService) Issuer(tenantID string) string {
+	if tenantID == "" {
+		return s.config.Issuer
+	}
+	return strings.TrimSuffix(s.config.Issuer, "/") + "/tenants/" + tenantID
+}
+
+// Audience returns the audiences a tenant's access tokens are accepted by:
+// Heimdall and those the tenant registered in its claims template
+func (s *JWTService) Audience(tenantID string) (jwt.ClaimStrings, error) {
+	audience := jwt.ClaimStrings{s.config.Audience}
+	if s.templates == nil || tenantID == "" {
+		return audience, nil
+	}
+	registered, err := s.templates.Audiences(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve token audience: %w", err)
+	}
+	for _, name := range registered {
+		if !slices.Contains(audience, name) {
+			audience = append(audience, name)
+		}
+	}
+	return audience, nil
+}
+
+// signingKey returns the tenant's active signing key and its ID, falling back to
+// the primary shared key for tenants without their own key
+func (s *JWTService) signingKey(tenantID string) (*rsa.PrivateKey, string, error) {
+	if s.keyStore != nil && tenantID != "" {
+		tenantKey, err := s.keyStore.ActiveSigningKey(tenantID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve tenant signing key: %w", err)
+		}
+		if tenantKey != nil {
+			return tenantKey.PrivateKey, tenantKey.KeyID, nil
+		}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.platform.primary.PrivateKey, s.platform.primary.KeyID, nil
+}
+
+// signToken signs claims with a key, naming the key in the kid header
+func signToken(claims *TokenClaims, signingKey *rsa.PrivateKey, keyID string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	signedToken, err := token.SignedString(s.privateKey)
+	token.Header["kid"] = keyID
+	signedToken, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
-
 	return signedToken, nil
 }
 
@@ -124,7 +358,7 @@ func (s *JWTService) ValidateToken(tokenString string) (*TokenClaims, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return s.publicKey, nil
+		return s.resolveVerificationKey(token)
 	})
 
 	if err != nil {
@@ -140,9 +374,66 @@ func (s *JWTService) ValidateToken(tokenString string) (*TokenClaims, error) {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	if err := s.checkIssuerAndAudience(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
+// checkIssuerAndAudience rejects tokens not issued for the tenant they name,
+// and tokens not meant for Heimdall. Audiences tenants registered only matter
+// to their own services, so tokens exchanged for one of them cannot be
+// replayed against Heimdall. Refresh tokens issued before tokens carried an
+// audience are still accepted, so they rotate into tokens that do.
+func (s *JWTService) checkIssuerAndAudience(claims *TokenClaims) error {
+	if claims.Type == "refresh" && len(claims.Audience) == 0 && claims.Issuer == s.config.Issuer {
+		return nil
+	}
+	if claims.Issuer != s.Issuer(claims.TenantID) {
+		return fmt.Errorf("invalid token issuer: %s", claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, s.config.Audience) {
+		return fmt.Errorf("invalid token audience: %v", []string(claims.Audience))
+	}
+	return nil
+}
+
+// resolveVerificationKey selects the public key for a token based on its kid header.
+// Tokens without a kid predate tenant-scoped keys and were signed with the key read from files.
+func (s *JWTService) resolveVerificationKey(token *jwt.Token) (*rsa.PublicKey, error) {
+	keyID, _ := token.Header["kid"].(string)
+	if keyID == "" {
+		keyID = s.sharedKeyID
+	}
+
+	s.mu.RLock()
+	platformKey := s.platform.verification[keyID]
+	s.mu.RUnlock()
+	if platformKey != nil {
+		return platformKey, nil
+	}
+
+	if s.keyStore == nil {
+		return nil, fmt.Errorf("unknown signing key: %s", keyID)
+	}
+
+	key, err := s.keyStore.VerificationKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key: %s", keyID)
+	}
+
+	// A tenant key may only vouch for tokens issued to that tenant
+	if claims, ok := token.Claims.(*TokenClaims); ok && key.TenantID != "" && claims.TenantID != key.TenantID {
+		return nil, fmt.Errorf("signing key %s does not belong to tenant %s", keyID, claims.TenantID)
+	}
+
+	return key.PublicKey, nil
+}
+
 // ValidateAccessToken validates an access token specifically
 func (s *JWTService) ValidateAccessToken(tokenString string) (*TokenClaims, error) {
 	claims, err := s.ValidateToken(tokenString)
@@ -171,6 +462,26 @@ func (s *JWTService) ValidateRefreshToken(tokenString string) (*TokenClaims, err
 	return claims, nil
 }
 
+// GenerateMagicLinkToken generates the token of an emailed login link. It is
+// accepted neither as an access nor as a refresh token.
+func (s *JWTService) GenerateMagicLinkToken(userID, tenantID, email string, expiry time.Duration) (string, error) {
+	return s.generateToken(userID, tenantID, email, nil, nil, "magic_link", expiry)
+}
+
+// ValidateMagicLinkToken validates a magic link token specifically
+func (s *JWTService) ValidateMagicLinkToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Type != "magic_link" {
+		return nil, fmt.Errorf("invalid token type: expected magic_link, got %s", claims.Type)
+	}
+
+	return claims, nil
+}
+
 // ExtractTokenFromHeader extracts the token from Authorization header
 func ExtractTokenFromHeader(authHeader string) (string, error) {
 	if authHeader == "" {
@@ -189,3 +500,15 @@ func ExtractTokenFromHeader(authHeader string) (string, error) {
 
 	return authHeader[len(bearerPrefix):], nil
 }
+
+// UnverifiedTenantID returns the tenant claim of a token without verifying it,
+// or "" when the token cannot be decoded. It serves checks that must run before
+// authentication, such as a tenant's IP access lists; naming another tenant
+// gains nothing, as the token is still verified afterwards.
+func UnverifiedTenantID(tokenString string) string {
+	claims := &TokenClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return ""
+	}
+	return claims.TenantID
+}
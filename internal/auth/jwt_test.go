@@ -1,10 +1,14 @@
 package auth
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/techsavvyash/heimdall/internal/config"
 )
 
@@ -76,6 +80,42 @@ func TestJWTService_ValidateAccessToken(t *testing.T) {
 	}
 }
 
+func TestJWTService_AccessScope(t *testing.T) {
+	jwtService, cleanup := CreateTestJWTService(t)
+	defer cleanup()
+
+	tenantID := "660e8400-e29b-41d4-a716-446655440000"
+	tests := []struct {
+		roles []string
+		want  string
+	}{
+		{[]string{"user", "admin"}, AccessScopeTenant},
+		{[]string{"admin", PlatformRole}, AccessScopePlatform},
+	}
+	for _, tt := range tests {
+		tokens, err := jwtService.GenerateTokenPair("550e8400-e29b-41d4-a716-446655440000", tenantID, "test@example.com", tt.roles)
+		if err != nil {
+			t.Fatalf("Failed to generate token pair: %v", err)
+		}
+		claims, err := jwtService.ValidateAccessToken(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("Failed to validate access token: %v", err)
+		}
+		if claims.AccessScope != tt.want || claims.IsPlatformScope() != (tt.want == AccessScopePlatform) {
+			t.Errorf("Expected access scope %s for roles %v, got %s", tt.want, tt.roles, claims.AccessScope)
+		}
+
+		// Exchanged tokens keep the scope of their subject
+		exchanged, err := jwtService.GenerateExchangedToken(claims, []string{"users.read"}, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to exchange token: %v", err)
+		}
+		if exchangedClaims, err := jwtService.ValidateAccessToken(exchanged); err != nil || exchangedClaims.AccessScope != tt.want {
+			t.Errorf("Expected exchanged token to keep access scope %s, got %+v %v", tt.want, exchangedClaims, err)
+		}
+	}
+}
+
 func TestJWTService_ValidateRefreshToken(t *testing.T) {
 	jwtService, cleanup := CreateTestJWTService(t)
 	defer cleanup()
@@ -218,6 +258,7 @@ func TestJWTService_TokenExpiry(t *testing.T) {
 		AccessTokenExpiry:  1 * time.Second, // 1 second expiry
 		RefreshTokenExpiry: 2 * time.Second,
 		Issuer:             "heimdall-test",
+		Audience:           "heimdall",
 	}
 
 	jwtService, err := NewJWTService(cfg)
@@ -246,3 +287,301 @@ func TestJWTService_TokenExpiry(t *testing.T) {
 		t.Error("Expected error for expired token, got nil")
 	}
 }
+
+// staticKeyStore is an in-memory KeyStore for tests
+type staticKeyStore struct {
+	active  map[string]*SigningKey
+	revoked map[string]bool
+}
+
+func (s *staticKeyStore) ActiveSigningKey(tenantID string) (*SigningKey, error) {
+	return s.active[tenantID], nil
+}
+
+func (s *staticKeyStore) VerificationKey(keyID string) (*SigningKey, error) {
+	if s.revoked[keyID] {
+		return nil, ErrSigningKeyRevoked
+	}
+	for _, key := range s.active {
+		if key.KeyID == keyID {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestJWTService_TenantSigningKeys(t *testing.T) {
+	jwtService, cleanup := CreateTestJWTService(t)
+	defer cleanup()
+
+	tenantA := "660e8400-e29b-41d4-a716-446655440000"
+	tenantB := "770e8400-e29b-41d4-a716-446655440000"
+
+	privatePEM, _, err := GenerateRSAKeyPEM(2048)
+	if err != nil {
+		t.Fatalf("Failed to generate tenant key: %v", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privatePEM))
+	if err != nil {
+		t.Fatalf("Failed to parse tenant key: %v", err)
+	}
+	tenantKey := &SigningKey{
+		KeyID:      KeyThumbprint(&privateKey.PublicKey),
+		TenantID:   tenantA,
+		PrivateKey: privateKey,
+		PublicKey:  &privateKey.PublicKey,
+	}
+
+	store := &staticKeyStore{
+		active:  map[string]*SigningKey{tenantA: tenantKey},
+		revoked: map[string]bool{},
+	}
+	jwtService.SetKeyStore(store)
+
+	// Tenant with its own key signs with that key
+	tokens, err := jwtService.GenerateTokenPair("user-a", tenantA, "a@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(tokens.AccessToken, &TokenClaims{})
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	if kid := parsed.Header["kid"]; kid != tenantKey.KeyID {
+		t.Errorf("Expected kid '%s', got '%v'", tenantKey.KeyID, kid)
+	}
+	if _, err := jwtService.ValidateAccessToken(tokens.AccessToken); err != nil {
+		t.Errorf("Expected tenant-signed token to validate, got: %v", err)
+	}
+
+	// Tenant without its own key falls back to the shared key
+	sharedTokens, err := jwtService.GenerateTokenPair("user-b", tenantB, "b@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+	parsed, _, _ = jwt.NewParser().ParseUnverified(sharedTokens.AccessToken, &TokenClaims{})
+	if kid := parsed.Header["kid"]; kid != jwtService.SharedKeyID() {
+		t.Errorf("Expected shared kid '%s', got '%v'", jwtService.SharedKeyID(), kid)
+	}
+
+	// A tenant key cannot vouch for another tenant's claims
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, TokenClaims{
+		UserID:   "user-b",
+		TenantID: tenantB,
+		Type:     "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	forged.Header["kid"] = tenantKey.KeyID
+	forgedString, err := forged.SignedString(tenantKey.PrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign forged token: %v", err)
+	}
+	if _, err := jwtService.ValidateAccessToken(forgedString); err == nil {
+		t.Error("Expected cross-tenant token to be rejected")
+	}
+
+	// Revoked keys are rejected
+	store.revoked[tenantKey.KeyID] = true
+	if _, err := jwtService.ValidateAccessToken(tokens.AccessToken); err == nil {
+		t.Error("Expected token signed with revoked key to be rejected")
+	}
+}
+
+// staticClaimsTemplates is an in-memory ClaimsTemplateStore for tests
+type staticClaimsTemplates map[string]*TemplateClaims
+
+func (s staticClaimsTemplates) AccessTokenClaims(userID, tenantID string, roles []string) (*TemplateClaims, error) {
+	return s[tenantID], nil
+}
+
+func (s staticClaimsTemplates) Audiences(tenantID string) ([]string, error) {
+	return nil, nil
+}
+
+// staticAudiences is an in-memory ClaimsTemplateStore registering audiences only
+type staticAudiences map[string][]string
+
+func (s staticAudiences) AccessTokenClaims(userID, tenantID string, roles []string) (*TemplateClaims, error) {
+	return nil, nil
+}
+
+func (s staticAudiences) Audiences(tenantID string) ([]string, error) {
+	return s[tenantID], nil
+}
+
+func TestJWTService_IssuerAndAudience(t *testing.T) {
+	jwtService, cleanup := CreateTestJWTService(t)
+	defer cleanup()
+
+	tenantID := "660e8400-e29b-41d4-a716-446655440000"
+	otherTenantID := "770e8400-e29b-41d4-a716-446655440000"
+	jwtService.SetClaimsTemplates(staticAudiences{tenantID: {"billing-service"}})
+
+	tokens, err := jwtService.GenerateTokenPair("user-a", tenantID, "a@example.com", []string{"user"})
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+	claims, err := jwtService.ValidateAccessToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.Issuer != "heimdall-test/tenants/"+tenantID {
+		t.Errorf("Expected the tenant's issuer, got %s", claims.Issuer)
+	}
+	if !reflect.DeepEqual([]string(claims.Audience), []string{"heimdall", "billing-service"}) {
+		t.Errorf("Expected Heimdall and the registered audience, got %v", claims.Audience)
+	}
+	refreshClaims, err := jwtService.ValidateRefreshToken(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to validate refresh token: %v", err)
+	}
+	if !reflect.DeepEqual([]string(refreshClaims.Audience), []string{"heimdall"}) {
+		t.Errorf("Expected refresh tokens to be meant for Heimdall only, got %v", refreshClaims.Audience)
+	}
+
+	// Tokens meant for Heimdall are accepted; tokens exchanged for a service,
+	// registered or not, are for that service only
+	for audience, wantValid := range map[string]bool{"heimdall": true, "billing-service": false, "reporting-service": false} {
+		exchanged, err := jwtService.GenerateExchangedToken(claims, nil, []string{audience}, time.Minute)
+		if err != nil {
+			t.Fatalf("Failed to exchange token: %v", err)
+		}
+		if _, err := jwtService.ValidateAccessToken(exchanged); (err == nil) != wantValid {
+			t.Errorf("Expected a token for %s to be valid: %v, got %v", audience, wantValid, err)
+		}
+	}
+
+	sign := func(claims *TokenClaims) string {
+		t.Helper()
+		key, keyID, _ := jwtService.signingKey(claims.TenantID)
+		token, err := signToken(claims, key, keyID)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return token
+	}
+	registered := func(issuer string, audience ...string) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			ID:        "token-1",
+			Issuer:    issuer,
+			Audience:  audience,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}
+	}
+
+	// Tokens naming another tenant than their issuer are rejected
+	forged := sign(&TokenClaims{UserID: "user-a", TenantID: otherTenantID, Type: "access", RegisteredClaims: registered("heimdall-test/tenants/"+tenantID, "heimdall")})
+	if _, err := jwtService.ValidateAccessToken(forged); err == nil {
+		t.Error("Expected a token from another tenant's issuer to be rejected")
+	}
+
+	// Refresh tokens issued before tokens carried an audience still rotate
+	legacyRefresh := sign(&TokenClaims{UserID: "user-a", TenantID: tenantID, Type: "refresh", RegisteredClaims: registered("heimdall-test")})
+	if _, err := jwtService.ValidateRefreshToken(legacyRefresh); err != nil {
+		t.Errorf("Expected a refresh token without audience to be accepted, got %v", err)
+	}
+	legacyAccess := sign(&TokenClaims{UserID: "user-a", TenantID: tenantID, Type: "access", RegisteredClaims: registered("heimdall-test")})
+	if _, err := jwtService.ValidateAccessToken(legacyAccess); err == nil {
+		t.Error("Expected an access token without audience to be rejected")
+	}
+}
+
+func TestJWTService_ClaimsTemplate(t *testing.T) {
+	jwtService, cleanup := CreateTestJWTService(t)
+	defer cleanup()
+
+	tenantID := "660e8400-e29b-41d4-a716-446655440000"
+	manyPermissions := make([]string, 200)
+	for i := range manyPermissions {
+		manyPermissions[i] = fmt.Sprintf("resource%d.read", i)
+	}
+
+	tests := []struct {
+		name              string
+		template          *TemplateClaims
+		wantPermissions   []string
+		wantOmitted       bool
+		wantDepartment    interface{}
+		wantTooLargeError bool
+	}{
+		{
+			name: "embeds template claims",
+			template: &TemplateClaims{
+				Roles:       []string{"admin"},
+				Permissions: []string{"users.read"},
+				Tenant:      map[string]interface{}{"plan": "pro"},
+				Custom:      map[string]interface{}{"department": "sales"},
+				MaxSize:     4096,
+			},
+			wantPermissions: []string{"users.read"},
+			wantDepartment:  "sales",
+		},
+		{
+			name: "omits permissions from oversized tokens",
+			template: &TemplateClaims{
+				Roles:       []string{"admin"},
+				Permissions: manyPermissions,
+				Custom:      map[string]interface{}{"department": "sales"},
+				MaxSize:     2048,
+			},
+			wantOmitted:    true,
+			wantDepartment: "sales",
+		},
+		{
+			name: "rejects tokens too large without template claims",
+			template: &TemplateClaims{
+				Roles:   manyPermissions,
+				MaxSize: 1024,
+			},
+			wantTooLargeError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwtService.SetClaimsTemplates(staticClaimsTemplates{tenantID: tt.template})
+
+			tokens, err := jwtService.GenerateTokenPair("user-a", tenantID, "a@example.com", []string{"admin", "user"})
+			if tt.wantTooLargeError {
+				if !errors.Is(err, ErrTokenTooLarge) {
+					t.Fatalf("Expected ErrTokenTooLarge, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to generate token pair: %v", err)
+			}
+			if len(tokens.AccessToken) > tt.template.MaxSize {
+				t.Errorf("Expected token of at most %d bytes, got %d", tt.template.MaxSize, len(tokens.AccessToken))
+			}
+
+			claims, err := jwtService.ValidateAccessToken(tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("Failed to validate token: %v", err)
+			}
+			if !reflect.DeepEqual(claims.Roles, tt.template.Roles) {
+				t.Errorf("Expected roles %v, got %v", tt.template.Roles, claims.Roles)
+			}
+			if !reflect.DeepEqual(claims.Permissions, tt.wantPermissions) {
+				t.Errorf("Expected permissions %v, got %v", tt.wantPermissions, claims.Permissions)
+			}
+			if claims.PermissionsOmitted != tt.wantOmitted {
+				t.Errorf("Expected permissionsOmitted %v, got %v", tt.wantOmitted, claims.PermissionsOmitted)
+			}
+			if department := claims.Custom["department"]; department != tt.wantDepartment {
+				t.Errorf("Expected department claim %v, got %v", tt.wantDepartment, department)
+			}
+
+			// Refresh tokens keep the default claims
+			refreshClaims, err := jwtService.ValidateRefreshToken(tokens.RefreshToken)
+			if err != nil {
+				t.Fatalf("Failed to validate refresh token: %v", err)
+			}
+			if refreshClaims.Permissions != nil || refreshClaims.Custom != nil {
+				t.Errorf("Expected refresh token without template claims, got %+v", refreshClaims)
+			}
+		})
+	}
+}
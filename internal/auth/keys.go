@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+// ErrSigningKeyRevoked is returned when a token references a revoked signing key
+var ErrSigningKeyRevoked = errors.New("signing key has been revoked")
+
+// ErrSigningKeyExpired is returned when a token references a key retired for
+// longer than tokens live
+var ErrSigningKeyExpired = errors.New("signing key has expired")
+
+// SigningKey is an RSA key pair identified by a key ID
+type SigningKey struct {
+	KeyID      string
+	TenantID   string          // Empty for shared platform keys
+	PrivateKey *rsa.PrivateKey // Nil for keys that only verify
+	PublicKey  *rsa.PublicKey
+}
+
+// KeyStore resolves tenant-scoped signing keys
+type KeyStore interface {
+	// ActiveSigningKey returns the key used to sign new tokens for a tenant,
+	// or nil if the tenant still uses the shared platform key
+	ActiveSigningKey(tenantID string) (*SigningKey, error)
+
+	// VerificationKey returns the key with the given key ID, or nil if it is unknown.
+	// It returns ErrSigningKeyRevoked for revoked keys and ErrSigningKeyExpired
+	// for expired ones.
+	VerificationKey(keyID string) (*SigningKey, error)
+}
+
+// JWK represents a JSON Web Key (RFC 7517) for an RSA public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS represents a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK builds a JWK from an RSA public key
+func NewJWK(keyID string, publicKey *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: keyID,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}
+}
+
+// KeyThumbprint computes the RFC 7638 JWK thumbprint of an RSA public key,
+// which is used as a stable key ID
+func KeyThumbprint(publicKey *rsa.PublicKey) string {
+	jwk := NewJWK("", publicKey)
+	// Members must be in lexicographic order with no whitespace
+	canonical := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// EncodePublicKeyPEM encodes an RSA public key as a PEM PUBLIC KEY block
+func EncodePublicKeyPEM(publicKey *rsa.PublicKey) (string, error) {
+	publicBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicBytes,
+	})), nil
+}
+
+// GenerateRSAKeyPEM generates a new RSA key pair and returns it PEM-encoded
+func GenerateRSAKeyPEM(bits int) (privateKeyPEM, publicKeyPEM string, err error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+
+	publicPEM, err := EncodePublicKeyPEM(&privateKey.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(privatePEM), publicPEM, nil
+}
+
+// EnsureKeyFiles generates the JWT key pair when the private key file does not
+// exist, so development setups start without generating keys by hand. It
+// reports whether keys were generated.
+func EnsureKeyFiles(privateKeyPath, publicKeyPath string) (bool, error) {
+	if _, err := os.Stat(privateKeyPath); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	privatePEM, publicPEM, err := GenerateRSAKeyPEM(2048)
+	if err != nil {
+		return false, err
+	}
+	for path, content := range map[string]string{privateKeyPath: privatePEM, publicKeyPath: publicPEM} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return false, fmt.Errorf("failed to create key directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return false, fmt.Errorf("failed to write key: %w", err)
+		}
+	}
+	return true, nil
+}
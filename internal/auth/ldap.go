@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// LDAPUser represents a user entry in an LDAP directory
+type LDAPUser struct {
+	DN        string
+	Email     string
+	FirstName string
+	LastName  string
+	Groups    []string // DNs of the groups the user is a member of
+}
+
+// LDAPConnector authenticates users against an LDAP or Active Directory server.
+// A connection is opened per operation, as logins and syncs are infrequent.
+type LDAPConnector struct {
+	cfg *config.LDAPConfig
+}
+
+// NewLDAPConnector creates a new LDAP connector
+func NewLDAPConnector(cfg *config.LDAPConfig) *LDAPConnector {
+	return &LDAPConnector{cfg: cfg}
+}
+
+// Authenticate finds the user matching username and binds as them with password
+func (c *LDAPConnector) Authenticate(username, password string) (*LDAPUser, error) {
+	// An empty password would be accepted as an unauthenticated bind
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		c.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		c.userFilter(username), c.attributes(), nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("LDAP user filter matched more than one entry for %q", username)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search LDAP users: %w", err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	if len(result.Entries) > 1 {
+		return nil, fmt.Errorf("LDAP user filter matched more than one entry for %q", username)
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind as LDAP user: %w", err)
+	}
+
+	return c.toLDAPUser(entry), nil
+}
+
+// Lookup retrieves a user entry by DN
+func (c *LDAPConnector) Lookup(dn string) (*LDAPUser, error) {
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", c.attributes(), nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up LDAP user: %w", err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return c.toLDAPUser(result.Entries[0]), nil
+}
+
+// connect dials the server and binds as the service account
+func (c *LDAPConnector) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.cfg.InsecureSkipVerify}
+	conn, err := ldap.DialURL(c.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: c.cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(c.cfg.Timeout)
+
+	if c.cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with LDAP server: %w", err)
+		}
+	}
+
+	if c.cfg.BindDN != "" {
+		if err := conn.Bind(c.cfg.BindDN, c.cfg.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind LDAP service account: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// userFilter returns the configured user filter for username, escaped so it
+// cannot change the filter
+func (c *LDAPConnector) userFilter(username string) string {
+	return strings.ReplaceAll(c.cfg.UserFilter, "{username}", ldap.EscapeFilter(username))
+}
+
+func (c *LDAPConnector) attributes() []string {
+	return []string{c.cfg.EmailAttribute, c.cfg.FirstNameAttribute, c.cfg.LastNameAttribute, c.cfg.GroupAttribute}
+}
+
+func (c *LDAPConnector) toLDAPUser(entry *ldap.Entry) *LDAPUser {
+	return &LDAPUser{
+		DN:        entry.DN,
+		Email:     entry.GetAttributeValue(c.cfg.EmailAttribute),
+		FirstName: entry.GetAttributeValue(c.cfg.FirstNameAttribute),
+		LastName:  entry.GetAttributeValue(c.cfg.LastNameAttribute),
+		Groups:    entry.GetAttributeValues(c.cfg.GroupAttribute),
+	}
+}
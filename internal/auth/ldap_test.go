@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+func TestLDAPConnector_UserFilter(t *testing.T) {
+	connector := NewLDAPConnector(&config.LDAPConfig{
+		UserFilter: "(&(objectClass=person)(|(mail={username})(userPrincipalName={username})))",
+	})
+
+	tests := []struct {
+		username string
+		want     string
+	}{
+		{"alice@example.com", "(&(objectClass=person)(|(mail=alice@example.com)(userPrincipalName=alice@example.com)))"},
+		{"*)(mail=*", `(&(objectClass=person)(|(mail=\2a\29\28mail=\2a)(userPrincipalName=\2a\29\28mail=\2a)))`},
+	}
+	for _, tt := range tests {
+		if got := connector.userFilter(tt.username); got != tt.want {
+			t.Errorf("userFilter(%q) = %s, want %s", tt.username, got, tt.want)
+		}
+	}
+}
+
+func TestLDAPConnector_EmptyPassword(t *testing.T) {
+	// Rejected before connecting, as servers accept empty passwords as unauthenticated binds
+	connector := NewLDAPConnector(&config.LDAPConfig{URL: "ldap://127.0.0.1:1"})
+	if _, err := connector.Authenticate("alice@example.com", ""); err != ErrInvalidCredentials {
+		t.Errorf("Expected ErrInvalidCredentials for an empty password, got %v", err)
+	}
+}
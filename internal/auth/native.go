@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// resetTokenTTL is how long a password reset token stays valid
+const resetTokenTTL = time.Hour
+
+// NativeIdentityProvider stores bcrypt password hashes in Heimdall's own
+// database, for deployments that do not run FusionAuth
+type NativeIdentityProvider struct {
+	db     *gorm.DB
+	mailer notify.Mailer
+	cost   int
+
+	// dummyHash is compared against for unknown emails, so they take as long
+	// as wrong passwords
+	dummyHash []byte
+}
+
+var _ IdentityProvider = (*NativeIdentityProvider)(nil)
+
+// NewNativeIdentityProvider creates a new native identity provider. A nil mailer
+// disables password reset emails.
+func NewNativeIdentityProvider(db *gorm.DB, mailer notify.Mailer) *NativeIdentityProvider {
+	if mailer == nil {
+		mailer = notify.NoopMailer{}
+	}
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), bcrypt.DefaultCost)
+	return &NativeIdentityProvider{
+		db:        db,
+		mailer:    mailer,
+		cost:      bcrypt.DefaultCost,
+		dummyHash: dummyHash,
+	}
+}
+
+// Register creates a new user with a hashed password
+func (p *NativeIdentityProvider) Register(ctx context.Context, req *RegisterRequest) (*IdentityUser, error) {
+	userID := uuid.New()
+	if req.UserID != "" {
+		var err error
+		if userID, err = uuid.Parse(req.UserID); err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), p.cost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	email := normalizeEmail(req.Email)
+	var existing int64
+	if err := p.db.WithContext(ctx).Model(&models.UserCredential{}).Where("email = ?", email).Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existing > 0 {
+		return nil, ErrEmailTaken
+	}
+
+	credential := &models.UserCredential{
+		UserID:       userID,
+		Email:        email,
+		PasswordHash: string(hash),
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		Active:       true,
+	}
+	if err := p.db.WithContext(ctx).Create(credential).Error; err != nil {
+		return nil, fmt.Errorf("failed to create credentials: %w", err)
+	}
+
+	return toIdentityUser(credential), nil
+}
+
+// Login checks an email and password
+func (p *NativeIdentityProvider) Login(ctx context.Context, req *LoginRequest) (*IdentityUser, error) {
+	credential, err := p.find(ctx, "email = ?", normalizeEmail(req.Email))
+	if errors.Is(err, ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(p.dummyHash, []byte(req.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(credential.PasswordHash), []byte(req.Password)) != nil || !credential.Active {
+		return nil, ErrInvalidCredentials
+	}
+	return toIdentityUser(credential), nil
+}
+
+// GetUser retrieves a user by ID
+func (p *NativeIdentityProvider) GetUser(ctx context.Context, userID string) (*IdentityUser, error) {
+	credential, err := p.find(ctx, "user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	return toIdentityUser(credential), nil
+}
+
+// UpdateUser applies a partial update of firstName, lastName, email or active
+func (p *NativeIdentityProvider) UpdateUser(ctx context.Context, userID string, updates map[string]interface{}) (*IdentityUser, error) {
+	credential, err := p.find(ctx, "user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]interface{}{}
+	for field, value := range updates {
+		switch field {
+		case "firstName":
+			columns["first_name"] = value
+		case "lastName":
+			columns["last_name"] = value
+		case "email":
+			email, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid email %v", value)
+			}
+			columns["email"] = normalizeEmail(email)
+		case "active":
+			columns["active"] = value
+		default:
+			return nil, fmt.Errorf("unsupported user field %q", field)
+		}
+	}
+
+	if len(columns) > 0 {
+		if err := p.db.WithContext(ctx).Model(credential).Updates(columns).Error; err != nil {
+			return nil, fmt.Errorf("failed to update credentials: %w", err)
+		}
+	}
+	return p.GetUser(ctx, userID)
+}
+
+// DeleteUser deletes a user's credentials
+func (p *NativeIdentityProvider) DeleteUser(ctx context.Context, userID string) error {
+	result := p.db.WithContext(ctx).Delete(&models.UserCredential{}, "user_id = ?", userID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete credentials: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ChangePassword replaces a password after checking the current one
+func (p *NativeIdentityProvider) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	credential, err := p.find(ctx, "user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(credential.PasswordHash), []byte(currentPassword)) != nil {
+		return ErrInvalidCredentials
+	}
+	return p.setPassword(ctx, credential, newPassword)
+}
+
+// ForgotPassword emails a single-use reset token to the user. Unknown emails are
+// ignored so the response does not reveal which emails are registered.
+func (p *NativeIdentityProvider) ForgotPassword(ctx context.Context, email string) error {
+	credential, err := p.find(ctx, "email = ?", normalizeEmail(email))
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	expiresAt := time.Now().Add(resetTokenTTL)
+
+	if err := p.db.WithContext(ctx).Model(credential).Updates(map[string]interface{}{
+		"reset_token_hash":       hashResetToken(token),
+		"reset_token_expires_at": &expiresAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	body := fmt.Sprintf("A password reset was requested for your account.\n\nReset token: %s\n\nThe token expires in %s. If you did not request a reset, ignore this email.", token, resetTokenTTL)
+	if err := p.mailer.Send(ctx, credential.Email, "Reset your password", body); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", credential.UserID, err)
+	}
+	return nil
+}
+
+// ResetTokenUser returns the user a valid token sent by ForgotPassword was issued to
+func (p *NativeIdentityProvider) ResetTokenUser(ctx context.Context, token string) (*IdentityUser, error) {
+	credential, err := p.findResetToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return toIdentityUser(credential), nil
+}
+
+// ResetPassword sets a new password using a token sent by ForgotPassword
+func (p *NativeIdentityProvider) ResetPassword(ctx context.Context, token, newPassword string) error {
+	credential, err := p.findResetToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	return p.setPassword(ctx, credential, newPassword)
+}
+
+// findResetToken returns the credentials of an unexpired reset token
+func (p *NativeIdentityProvider) findResetToken(ctx context.Context, token string) (*models.UserCredential, error) {
+	if token == "" {
+		return nil, ErrInvalidCredentials
+	}
+	credential, err := p.find(ctx, "reset_token_hash = ?", hashResetToken(token))
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if credential.ResetTokenExpiresAt == nil || time.Now().After(*credential.ResetTokenExpiresAt) {
+		return nil, ErrInvalidCredentials
+	}
+	return credential, nil
+}
+
+// setPassword stores a new password hash and invalidates any reset token
+func (p *NativeIdentityProvider) setPassword(ctx context.Context, credential *models.UserCredential, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	return p.db.WithContext(ctx).Model(credential).Updates(map[string]interface{}{
+		"password_hash":          string(hash),
+		"reset_token_hash":       "",
+		"reset_token_expires_at": nil,
+	}).Error
+}
+
+func (p *NativeIdentityProvider) find(ctx context.Context, query string, args ...interface{}) (*models.UserCredential, error) {
+	var credential models.UserCredential
+	err := p.db.WithContext(ctx).Where(query, args...).First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	return &credential, nil
+}
+
+func toIdentityUser(credential *models.UserCredential) *IdentityUser {
+	return &IdentityUser{
+		ID:        credential.UserID.String(),
+		Email:     credential.Email,
+		FirstName: credential.FirstName,
+		LastName:  credential.LastName,
+		Active:    credential.Active,
+		Verified:  credential.Verified,
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
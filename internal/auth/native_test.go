@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingMailer keeps the last message sent
+type recordingMailer struct {
+	to, body string
+}
+
+func (m *recordingMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.to, m.body = to, body
+	return nil
+}
+
+func newTestNativeProvider(t *testing.T) (*NativeIdentityProvider, *recordingMailer) {
+	t.Helper()
+
+	dialector, err := database.Dialector(database.DriverSQLite, filepath.Join(t.TempDir(), "heimdall.db"))
+	if err != nil {
+		t.Fatalf("Failed to configure database: %v", err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	mailer := &recordingMailer{}
+	provider := NewNativeIdentityProvider(db, mailer)
+	provider.cost = 4 // bcrypt.MinCost keeps the tests fast
+	return provider, mailer
+}
+
+func TestNativeIdentityProvider_RegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	provider, _ := newTestNativeProvider(t)
+	userID := uuid.NewString()
+
+	user, err := provider.Register(ctx, &RegisterRequest{
+		UserID:    userID,
+		Email:     "Alice@Example.com",
+		Password:  "SecurePassword123!",
+		FirstName: "Alice",
+	})
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+	if user.ID != userID || user.Email != "alice@example.com" || !user.Active {
+		t.Errorf("Unexpected registered user: %+v", user)
+	}
+
+	if _, err := provider.Register(ctx, &RegisterRequest{Email: "alice@example.com", Password: "AnotherPassword1!"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken for a duplicate email, got %v", err)
+	}
+
+	if user, err := provider.Login(ctx, &LoginRequest{Email: "ALICE@example.com", Password: "SecurePassword123!"}); err != nil || user.ID != userID {
+		t.Errorf("Login = (%+v, %v), want the registered user", user, err)
+	}
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "wrong"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "nobody@example.com", Password: "SecurePassword123!"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown email, got %v", err)
+	}
+
+	// Deactivated users cannot log in
+	if _, err := provider.UpdateUser(ctx, userID, map[string]interface{}{"active": false, "lastName": "Smith"}); err != nil {
+		t.Fatalf("UpdateUser returned error: %v", err)
+	}
+	if user, _ := provider.GetUser(ctx, userID); user == nil || user.LastName != "Smith" {
+		t.Errorf("Expected the last name to be updated, got %+v", user)
+	}
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "SecurePassword123!"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an inactive user, got %v", err)
+	}
+
+	if err := provider.DeleteUser(ctx, userID); err != nil {
+		t.Fatalf("DeleteUser returned error: %v", err)
+	}
+	if _, err := provider.GetUser(ctx, userID); !IsNotFound(err) {
+		t.Errorf("Expected a deleted user to be not found, got %v", err)
+	}
+}
+
+func TestNativeIdentityProvider_Passwords(t *testing.T) {
+	ctx := context.Background()
+	provider, mailer := newTestNativeProvider(t)
+	user, err := provider.Register(ctx, &RegisterRequest{Email: "alice@example.com", Password: "SecurePassword123!"})
+	if err != nil {
+		t.Fatalf("Register returned error: %v", err)
+	}
+
+	if err := provider.ChangePassword(ctx, user.ID, "wrong", "NewPassword456!"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong current password, got %v", err)
+	}
+	if err := provider.ChangePassword(ctx, user.ID, "SecurePassword123!", "NewPassword456!"); err != nil {
+		t.Fatalf("ChangePassword returned error: %v", err)
+	}
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "NewPassword456!"}); err != nil {
+		t.Errorf("Expected login with the new password to succeed, got %v", err)
+	}
+
+	if err := provider.ForgotPassword(ctx, "nobody@example.com"); err != nil || mailer.to != "" {
+		t.Errorf("Expected unknown emails to be ignored, got error %v and mail to %q", err, mailer.to)
+	}
+	if err := provider.ForgotPassword(ctx, "alice@example.com"); err != nil {
+		t.Fatalf("ForgotPassword returned error: %v", err)
+	}
+	if mailer.to != "alice@example.com" {
+		t.Fatalf("Expected a reset email to alice@example.com, got %q", mailer.to)
+	}
+	token := strings.Fields(strings.SplitN(mailer.body, "Reset token: ", 2)[1])[0]
+
+	if err := provider.ResetPassword(ctx, "not-the-token", "ResetPassword789!"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown token, got %v", err)
+	}
+	if err := provider.ResetPassword(ctx, token, "ResetPassword789!"); err != nil {
+		t.Fatalf("ResetPassword returned error: %v", err)
+	}
+	if err := provider.ResetPassword(ctx, token, "AgainPassword000!"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected the reset token to be single-use, got %v", err)
+	}
+	if _, err := provider.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "ResetPassword789!"}); err != nil {
+		t.Errorf("Expected login with the reset password to succeed, got %v", err)
+	}
+}
@@ -73,6 +73,7 @@ func CreateTestJWTService(t *testing.T) (*JWTService, func()) {
 		AccessTokenExpiry:  15 * time.Minute,
 		RefreshTokenExpiry: 7 * 24 * time.Hour,
 		Issuer:             "heimdall-test",
+		Audience:           "heimdall",
 	}
 
 	jwtService, err := NewJWTService(cfg)
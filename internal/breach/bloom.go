@@ -0,0 +1,119 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// bloomMagic starts every bloom filter file
+const bloomMagic = "HBF1"
+
+// BloomFilter is an offline set of breached password hashes. Lookups have no
+// false negatives and a false positive rate chosen when the filter is built,
+// so a small share of safe passwords may be reported breached.
+//
+// Files hold the magic "HBF1", the number of hash functions as a big-endian
+// uint32, the number of bits as a big-endian uint64, then the bits.
+type BloomFilter struct {
+	bits   []byte
+	size   uint64 // Number of bits
+	hashes uint32 // Number of hash functions
+}
+
+// NewBloomFilter creates an empty filter sized for n hashes at a false
+// positive rate, e.g. 0.001
+func NewBloomFilter(n int, falsePositiveRate float64) (*BloomFilter, error) {
+	if n <= 0 || falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("bloom filter needs a positive size and a false positive rate between 0 and 1")
+	}
+	size := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint32(math.Max(1, math.Round(float64(size)/float64(n)*math.Ln2)))
+	return &BloomFilter{bits: make([]byte, (size+7)/8), size: size, hashes: hashes}, nil
+}
+
+// LoadBloomFilter reads a filter file
+func LoadBloomFilter(path string) (*BloomFilter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bloom filter: %w", err)
+	}
+	defer file.Close()
+	return ReadBloomFilter(bufio.NewReader(file))
+}
+
+// ReadBloomFilter decodes a filter
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomMagic)+4+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read bloom filter header: %w", err)
+	}
+	if string(header[:len(bloomMagic)]) != bloomMagic {
+		return nil, errors.New("not a bloom filter file")
+	}
+	filter := &BloomFilter{
+		hashes: binary.BigEndian.Uint32(header[4:8]),
+		size:   binary.BigEndian.Uint64(header[8:16]),
+	}
+	if filter.hashes == 0 || filter.size == 0 {
+		return nil, errors.New("bloom filter is empty")
+	}
+	filter.bits = make([]byte, (filter.size+7)/8)
+	if _, err := io.ReadFull(r, filter.bits); err != nil {
+		return nil, fmt.Errorf("failed to read bloom filter: %w", err)
+	}
+	return filter, nil
+}
+
+// WriteTo encodes the filter
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, len(bloomMagic)+4+8)
+	copy(header, bloomMagic)
+	binary.BigEndian.PutUint32(header[4:8], f.hashes)
+	binary.BigEndian.PutUint64(header[8:16], f.size)
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(f.bits)
+	return int64(n + m), err
+}
+
+// AddHash adds the hex SHA-1 of a breached password
+func (f *BloomFilter) AddHash(hash string) error {
+	digest, err := hex.DecodeString(hash)
+	if err != nil || len(digest) < 16 {
+		return fmt.Errorf("invalid SHA-1 hash %q", hash)
+	}
+	h1, h2 := f.baseHashes(digest)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.size
+		f.bits[bit/8] |= 1 << (bit % 8)
+	}
+	return nil
+}
+
+// Breached reports whether the password's hash may be in the filter
+func (f *BloomFilter) Breached(ctx context.Context, password string) (bool, error) {
+	digest, _ := hex.DecodeString(HashPassword(password))
+	h1, h2 := f.baseHashes(digest)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// baseHashes derives the two hashes that the filter's hash functions combine
+// from a SHA-1 digest, which is already uniformly distributed
+func (f *BloomFilter) baseHashes(digest []byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(digest[:8]), binary.BigEndian.Uint64(digest[8:16]) | 1
+}
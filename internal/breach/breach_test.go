@@ -0,0 +1,101 @@
+package breach
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHIBPChecker_Breached(t *testing.T) {
+	breachedHash := HashPassword("password123")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Add-Padding") != "true" {
+			t.Error("Expected padded responses to be requested")
+		}
+		// Only the prefix of the hash is sent
+		if r.URL.Path != "/range/"+breachedHash[:5] && r.URL.Path != "/range/"+HashPassword("padded")[:5] {
+			return
+		}
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n%s:24230577\r\n%s:0\r\n", breachedHash[5:], HashPassword("padded")[5:])
+	}))
+	defer server.Close()
+
+	checker := NewHIBPChecker(server.URL+"/", time.Second)
+	for password, want := range map[string]bool{
+		"password123":                  true,
+		"padded":                       false,
+		"correct horse battery staple": false,
+	} {
+		breached, err := checker.Breached(context.Background(), password)
+		if err != nil {
+			t.Fatalf("Breached(%q) returned error: %v", password, err)
+		}
+		if breached != want {
+			t.Errorf("Breached(%q) = %v, want %v", password, breached, want)
+		}
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	filter, err := NewBloomFilter(1000, 0.001)
+	if err != nil {
+		t.Fatalf("NewBloomFilter returned error: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if err := filter.AddHash(HashPassword(fmt.Sprintf("breached-%d", i))); err != nil {
+			t.Fatalf("AddHash returned error: %v", err)
+		}
+	}
+	if err := filter.AddHash("not-a-hash"); err == nil {
+		t.Error("Expected an invalid hash to be rejected")
+	}
+
+	var encoded bytes.Buffer
+	if _, err := filter.WriteTo(&encoded); err != nil {
+		t.Fatalf("WriteTo returned error: %v", err)
+	}
+	loaded, err := ReadBloomFilter(&encoded)
+	if err != nil {
+		t.Fatalf("ReadBloomFilter returned error: %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if breached, _ := loaded.Breached(context.Background(), fmt.Sprintf("breached-%d", i)); !breached {
+			t.Fatalf("Expected breached-%d to be in the filter", i)
+		}
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		if breached, _ := loaded.Breached(context.Background(), fmt.Sprintf("safe-%d", i)); breached {
+			falsePositives++
+		}
+	}
+	if falsePositives > 10 {
+		t.Errorf("Expected about 1 false positive in 1000, got %d", falsePositives)
+	}
+}
+
+// failingChecker always fails
+type failingChecker struct{}
+
+func (failingChecker) Breached(ctx context.Context, password string) (bool, error) {
+	return false, errors.New("unreachable")
+}
+
+func TestFallbackChecker(t *testing.T) {
+	filter, _ := NewBloomFilter(10, 0.001)
+	_ = filter.AddHash(HashPassword("password123"))
+
+	checker := NewFallbackChecker(failingChecker{}, filter)
+	if breached, err := checker.Breached(context.Background(), "password123"); err != nil || !breached {
+		t.Errorf("Expected the fallback to report the password breached, got %v %v", breached, err)
+	}
+	if _, err := NewFallbackChecker(failingChecker{}, failingChecker{}).Breached(context.Background(), "x"); err == nil {
+		t.Error("Expected an error when both checkers fail")
+	}
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,34 +16,109 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Auth     AuthConfig
-	SMTP     SMTPConfig
-	OPA      OPAConfig
-	MinIO    MinIOConfig
+	Server         ServerConfig
+	Database       DatabaseConfig
+	Redis          RedisConfig
+	JWT            JWTConfig
+	Auth           AuthConfig
+	SMTP           SMTPConfig
+	OPA            OPAConfig
+	BundleStorage  BundleStorageConfig
+	Security       SecurityConfig
+	Webhooks       WebhookConfig
+	BreakGlass     BreakGlassConfig
+	LoginHooks     LoginHookConfig
+	PolicySync     PolicySyncConfig
+	Outbox         OutboxConfig
+	Jobs           JobConfig
+	Cleanup        CleanupConfig
+	Coordination   CoordinationConfig
+	Startup        StartupConfig
+	LDAP           LDAPConfig
+	SAML           SAMLConfig
+	OAuth          OAuthConfig
+	MagicLink      MagicLinkConfig
+	PasswordBreach PasswordBreachConfig
+	PasswordPolicy PasswordPolicyConfig
+	Headers        HeadersConfig
+	Encryption     EncryptionConfig
+	Alerts         AlertConfig
+	Bootstrap      BootstrapConfig
+	Dev            DevConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port            string
+	GRPCPort        string // Port of the gRPC API, empty to disable it
 	Environment     string
-	AllowedOrigins  []string
-	RateLimitPerMin int
+	AllowedOrigins  []string       // CORS origins of all tenants; tenants add their own in the allowedOrigins setting
+	RateLimitPerMin int            // Requests per minute of routes without their own limit
+	RouteRateLimits map[string]int // Requests per minute of route classes, e.g. "auth" and "authz"
+	AdminUI         bool           // Serve the embedded admin web UI under /admin
+	LoginUI         bool           // Serve hosted login, registration and consent pages under /login
+	LoginRedirects  []string       // URLs hosted login pages may send tokens to, or to paths below them
+	LogLevel        string         // Requests logged: debug and info log all, warn failed ones and error server errors
+	Compression     bool           // Compress responses with brotli or gzip when the client accepts it
+	ETagMinSize     int            // Size in bytes from which GET responses get a generated ETag
+
+	// Client IPs are read from ProxyHeader only on requests from TrustedProxies,
+	// IPs or CIDR ranges of the load balancers in front of the server. Without
+	// trusted proxies the peer address is the client IP.
+	TrustedProxies []string
+	ProxyHeader    string
+
+	// Bodies of routes selected through the admin API are logged, redacted, up
+	// to BodyLogMaxBytes each, for up to BodyLogMaxDuration, and zero disables it.
+	BodyLogMaxBytes    int
+	BodyLogMaxDuration time.Duration
+
+	// Requests are aborted with a 504 after their timeout and rejected with a
+	// 413 when their body exceeds the limit. Route classes, e.g. "auth" and
+	// "upload", have their own; routes of no other class get RequestTimeout and
+	// BodyLimit. Zero disables a limit.
+	RequestTimeout  time.Duration
+	RouteTimeouts   map[string]time.Duration
+	BodyLimit       int
+	RouteBodyLimits map[string]int
+
+	// Graceful shutdown: readiness fails for ShutdownDelay while load balancers
+	// stop routing to the instance, then in-flight requests, background workers
+	// and background writes get DrainTimeout to finish
+	ShutdownDelay time.Duration
+	DrainTimeout  time.Duration
 }
 
+// Log levels
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
+	Driver   string // postgres or sqlite
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Database string
 	SSLMode  string
-	MaxConns int
-	MaxIdle  int
+	DSN      string // Overrides the individual connection settings when set
+
+	// Read replicas serving read-only queries, as DSNs
+	ReplicaDSNs []string
+
+	// Connection pool, applied to the primary and each replica
+	MaxConns        int
+	MaxIdle         int
+	ConnMaxLifetime time.Duration
+
+	// Apply pending migrations at startup instead of with a separate migrate
+	// job; replicas starting together take turns through the migration lock
+	AutoMigrate bool
 }
 
 // RedisConfig holds Redis connection configuration
@@ -47,6 +127,8 @@ type RedisConfig struct {
 	Port     string
 	Password string
 	DB       int
+	Required bool // Fail startup without Redis instead of falling back to an in-process store
+	Enabled  bool // Connect to Redis rather than use the in-process store
 }
 
 // JWTConfig holds JWT configuration
@@ -55,18 +137,34 @@ type JWTConfig struct {
 	PublicKeyPath      string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
-	Issuer             string
+	Issuer             string        // Issuer of platform tokens; tenant tokens are issued under <Issuer>/tenants/<tenantId>
+	Audience           string        // Audience of Heimdall's own API, carried by every token it issues
+	KeyRefreshInterval time.Duration // Interval of reloading rotated platform keys and expiring retired keys
 }
 
-// AuthConfig holds FusionAuth configuration
+// AuthConfig holds identity provider configuration
 type AuthConfig struct {
-	URL             string
-	APIKey          string
-	TenantID        string
-	ApplicationID   string
+	Provider         string // fusionauth, or native to store credentials in Heimdall's database
+	URL              string
+	APIKey           string
+	TenantID         string
+	ApplicationID    string
 	OAuthRedirectURL string
+	Timeout          time.Duration // Per-attempt timeout of FusionAuth requests
+	MaxRetries       int           // Retries of FusionAuth requests that failed transiently
+	RetryBackoff     time.Duration // Base delay between retries, doubled per attempt
+	BreakerThreshold int           // Consecutive failures that open the circuit breaker
+	BreakerCooldown  time.Duration // Time the breaker stays open before a probe request
+
+	// How long passwords FusionAuth verified are cached to sign users in again
+	// while it is unavailable, 0 to disable
+	CredentialCacheTTL time.Duration
 }
 
+// MaxCredentialCacheTTL bounds how long verified passwords may be cached, as
+// the cache only bridges brief outages of the identity provider
+const MaxCredentialCacheTTL = 5 * time.Minute
+
 // SMTPConfig holds email configuration
 type SMTPConfig struct {
 	Host     string
@@ -82,6 +180,88 @@ type OPAConfig struct {
 	PolicyPath  string
 	Timeout     time.Duration
 	EnableCache bool
+	CacheTTL    time.Duration // How long cached decisions are fresh
+	MaxStale    time.Duration // Upper bound on how stale a cached decision clients may request
+	Binary      string        // Path of the opa CLI, which formats policies
+
+	// Connections kept alive to OPA, retries of evaluations while OPA is
+	// unreachable or unavailable, and the circuit breaker failing requests fast
+	// after BreakerThreshold consecutive failures, 0 to never open it
+	MaxIdleConns     int
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Interval of the full push of roles and role assignments to OPA data, 0 to
+	// disable the sync
+	DataSyncInterval time.Duration
+
+	// Interval of reading the permissions guarding the management API from the
+	// admin_api system policy loaded into OPA, 0 to only apply the bundled ones
+	RoutePermissionsInterval time.Duration
+
+	// Sources of the attributes added to authorization inputs, per resource type
+	AttributeSources       []AttributeSourceConfig
+	AttributeSourceTimeout time.Duration
+
+	// Add users' effective permissions to authorization inputs whose access
+	// token does not embed them, cached for PermissionCacheTTL
+	PrefetchPermissions bool
+	PermissionCacheTTL  time.Duration
+
+	// Query only the rows users may read on list endpoints, by partially
+	// evaluating policies with the resource unknown
+	RowFilters bool
+
+	// Run OPA in the server process with the bundled policies instead of
+	// calling the one at URL
+	Embedded bool
+}
+
+// AttributeSourceConfig configures where attributes of a resource type are
+// fetched from before authorization decisions
+type AttributeSourceConfig struct {
+	ResourceType string `json:"resourceType"`     // Resource type, or * for every resource type
+	Source       string `json:"source"`           // database, registry, http or user
+	URL          string `json:"url,omitempty"`    // Endpoint of an http source
+	Secret       string `json:"secret,omitempty"` // Secret signing requests to an http source
+}
+
+// Bundle storage backends
+const (
+	BundleStorageMinIO = "minio"
+	BundleStorageS3    = "s3"
+	BundleStorageGCS   = "gcs"
+	BundleStorageLocal = "local"
+)
+
+// BundleStorageConfig selects where built policy bundles are stored
+type BundleStorageConfig struct {
+	Backend  string // minio, s3, gcs or local
+	MinIO    MinIOConfig
+	S3       S3Config
+	GCS      GCSConfig
+	LocalDir string // Directory of the local backend
+}
+
+// S3Config holds AWS S3 configuration. Without static keys, credentials come
+// from the environment, the shared credentials file or the instance, task or
+// service account IAM role.
+type S3Config struct {
+	Bucket          string
+	Region          string
+	Endpoint        string // Defaults to the regional AWS endpoint
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// GCSConfig holds Google Cloud Storage configuration. GCS is accessed through
+// its S3-compatible XML API with HMAC keys of a service account.
+type GCSConfig struct {
+	Bucket    string
+	AccessKey string
+	SecretKey string
 }
 
 // MinIOConfig holds MinIO configuration
@@ -91,70 +271,601 @@ type MinIOConfig struct {
 	SecretKey string
 	Bucket    string
 	UseSSL    bool
+
+	// Endpoint presigned download URLs point at, when clients cannot reach Endpoint
+	PublicEndpoint string
+	PublicUseSSL   bool
+}
+
+// SecurityConfig holds login brute-force protection configuration
+type SecurityConfig struct {
+	MaxAccountFailures int           // Failed logins per account before lockout
+	MaxIPFailures      int           // Failed logins per IP before the IP is throttled
+	FailureWindow      time.Duration // Window in which failures are counted
+	LockoutDuration    time.Duration // How long an account stays locked
+	BackoffBase        time.Duration // Delay after the first failure, doubled per failure
+	BackoffMax         time.Duration // Upper bound for the backoff delay
+	GeoIPURL           string        // GeoIP lookup URL template containing "{ip}"
+	LoginAlertEmail    bool          // Email users about suspicious logins
+	MaxElevation       time.Duration // Longest temporary elevated access that can be granted
+	RoleExpiryInterval time.Duration // How often expired role assignments are removed, 0 to disable
+	AccessRequestEmail bool          // Email approvers and requesters about access requests
+	AnalyticsInterval  time.Duration // How often login activity is aggregated for analytics, 0 to disable
+	ActionNonceTTL     time.Duration // How long a nonce confirming a destructive action stays valid
+}
+
+// WebhookConfig holds outbound webhook configuration
+type WebhookConfig struct {
+	URLs    []string
+	Secret  string
+	Timeout time.Duration
 }
 
-// Load loads configuration from environment variables
+// BreakGlassConfig holds configuration for emergency access with tokens signed
+// offline by operators
+type BreakGlassConfig struct {
+	PublicKeyPath string        // Public key verifying break-glass tokens, empty to disable
+	MaxTTL        time.Duration // Longest lifetime a break-glass token may have
+	WebhookURLs   []string      // Endpoints paged on break-glass use, in addition to the webhook URLs
+}
+
+// EncryptionConfig holds the keys encrypting sensitive database columns at
+// rest. Columns are stored unencrypted when no keys are configured.
+type EncryptionConfig struct {
+	Keys              []string      // Keys as <id>:<base64 key>; the first encrypts, the others decrypt values of earlier keys
+	KeysFile          string        // File of keys in the same format, one per line after Keys, e.g. written by a KMS or secret manager agent
+	ReencryptInterval time.Duration // Interval of re-encrypting values of earlier keys and unencrypted values, 0 to disable
+}
+
+// AlertConfig holds configuration for the alert rules tenants define on their
+// audit logs
+type AlertConfig struct {
+	EvaluationInterval time.Duration // Interval of evaluating the alert rules, 0 to disable
+	PagerDutyURL       string        // Endpoint of the PagerDuty Events API v2
+}
+
+// BootstrapConfig names the first super admin, created on startup while no
+// user holds the super_admin role
+type BootstrapConfig struct {
+	AdminEmail string // Email of the first super admin, empty to disable
+	TenantSlug string // Tenant of the first super admin
+}
+
+// DevConfig holds configuration for the development mode, which runs Heimdall
+// as a single process: SQLite, the in-process store, an embedded OPA and an
+// identity provider accepting any password
+type DevConfig struct {
+	Enabled bool   // Change the defaults for local development; never in production
+	Fixture string // Fixture seeded at startup in addition to the default data
+}
+
+// LoginHookConfig holds configuration for webhook-based login hooks
+type LoginHookConfig struct {
+	URLs     []string
+	Secret   string
+	Timeout  time.Duration
+	FailOpen bool     // Allow logins when a hook is unreachable
+	Stages   []string // Stages the hooks are called at, pre_auth and post_auth when empty
+}
+
+// PolicySyncConfig holds configuration for syncing policies from a Git repository
+type PolicySyncConfig struct {
+	GitRepoURL    string        // Repository cloned on push, empty to disable Git sync
+	GitBranch     string        // Branch whose pushes trigger a sync
+	GitDirectory  string        // Directory of .rego files within the repository
+	GitTenantID   string        // Tenant the policies are synced into
+	GitDelete     bool          // Delete policies that are no longer in the repository
+	WebhookSecret string        // Secret used to verify push webhooks
+	Timeout       time.Duration // Upper bound for cloning and syncing
+}
+
+// OutboxConfig holds configuration for applying queued identity provider changes,
+// reconciling FusionAuth users with the users table and purging deactivated users
+type OutboxConfig struct {
+	PollInterval      time.Duration // How often pending outbox entries are processed
+	BatchSize         int           // Entries processed per poll
+	MaxAttempts       int           // Attempts before an entry is marked failed
+	ReconcileInterval time.Duration // How often users are reconciled, 0 to disable
+	ReconcileRepair   bool          // Repair drift instead of only reporting it
+	PurgeInterval     time.Duration // How often deactivated users are purged, 0 to disable
+	PurgeRetention    time.Duration // How long deactivated users can be restored
+}
+
+// JobConfig holds configuration for the workers running background jobs such
+// as bundle builds
+type JobConfig struct {
+	Workers      int           // Jobs run concurrently by each instance
+	PollInterval time.Duration // How often due jobs and retries are picked up
+	MaxAttempts  int           // Attempts before a job is marked dead
+	Lease        time.Duration // Longest a job may run before another worker takes it over
+}
+
+// CleanupConfig holds configuration for purging expired and stale data
+type CleanupConfig struct {
+	Interval            time.Duration // How often cleanup runs, 0 to disable
+	SoftDeleteRetention time.Duration // How long deleted policies, roles and bundles are kept, 0 to keep them
+	AuditLogRetention   time.Duration // How long audit log entries are kept, 0 to keep them
+	AuditLogArchive     bool          // Archive purged audit log entries to bundle storage
+	OrphanGracePeriod   time.Duration // Age of unreferenced bundle objects before they are deleted
+}
+
+// CoordinationConfig holds configuration for electing the instance that runs
+// singleton background tasks when several instances share the database
+type CoordinationConfig struct {
+	InstanceID string        // Unique per instance, the hostname with a random suffix by default
+	LeaseTTL   time.Duration // How long a leader holds a task without renewing it before another instance takes over
+}
+
+// MinLeaseTTL is the shortest leader lease. Leaders renew their leases every
+// third of it, so the renewals are at least a second apart.
+const MinLeaseTTL = 3 * time.Second
+
+// Dependencies the server checks at startup and in the background
+const (
+	DependencyOPA           = "opa"
+	DependencyBundleStorage = "bundle_storage"
+)
+
+// StartupConfig holds how long the server waits for its dependencies at
+// startup and how it keeps checking them afterwards
+type StartupConfig struct {
+	// How long connections are retried before startup fails without the
+	// database, falls back to the in-process store without Redis, or
+	// continues without OPA or the bundle storage
+	DatabaseWait      time.Duration
+	RedisWait         time.Duration
+	OPAWait           time.Duration
+	BundleStorageWait time.Duration
+	RetryBackoff      time.Duration // Delay before the first retry, doubled per retry
+
+	// Dependencies, opa or bundle_storage, that fail readiness while unavailable
+	CriticalDependencies []string
+	CheckInterval        time.Duration // How often dependencies are re-checked and recovered, 0 to disable
+}
+
+// LDAPConfig holds configuration for authenticating users against an LDAP or
+// Active Directory server
+type LDAPConfig struct {
+	URL                string // ldap:// or ldaps:// URL, empty to disable the connector
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string // Service account used to search for users
+	BindPassword       string
+	BaseDN             string // Base of user searches
+	UserFilter         string // Filter matching a login email, with {username} replaced
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	GroupAttribute     string // Attribute listing the DNs of a user's groups
+	TenantSlug         string // Tenant new users are provisioned into
+	GroupMappings      []LDAPGroupMapping
+	SyncInterval       time.Duration // How often group memberships are synced, 0 to disable
+	Timeout            time.Duration
+}
+
+// LDAPGroupMapping grants a tenant role to members of a directory group
+type LDAPGroupMapping struct {
+	Group  string `json:"group"`  // Group DN
+	Tenant string `json:"tenant"` // Tenant slug
+	Role   string `json:"role"`   // Role name
+}
+
+// SAMLConfig holds configuration for Heimdall as a SAML 2.0 service provider.
+// Identity providers are configured per tenant.
+type SAMLConfig struct {
+	BaseURL             string   // Public URL of Heimdall, used to build the metadata and ACS URLs
+	CertificatePath     string   // Service provider certificate, optional
+	PrivateKeyPath      string   // Service provider private key, optional
+	AllowedRedirectURLs []string // URLs logins may redirect to with tokens, and paths below them
+}
+
+// OAuthConfig holds configuration for the OAuth device authorization grant
+type OAuthConfig struct {
+	DeviceVerificationURI string        // Page where users enter the user code of a device
+	DeviceCodeExpiry      time.Duration // Lifetime of device and user codes
+	DevicePollInterval    time.Duration // Minimum interval between token requests of a device
+}
+
+// MagicLinkConfig holds configuration for passwordless login by email
+type MagicLinkConfig struct {
+	URL        string        // Page the emailed link opens, with the token in the token query parameter
+	Expiry     time.Duration // Lifetime of a link
+	MaxPerHour int           // Links sent to one email per hour
+}
+
+// PasswordBreachConfig holds configuration for rejecting passwords that appeared
+// in data breaches
+type PasswordBreachConfig struct {
+	Enabled    bool          // Check passwords of tenants that do not set passwordBreachCheck
+	APIURL     string        // Have I Been Pwned range API, empty to use the filter only
+	FilterPath string        // Offline bloom filter of breached hashes, used when the API fails
+	Timeout    time.Duration // Timeout of each range request
+}
+
+// PasswordPolicyConfig holds the default password policy, which tenants may
+// override with their passwordPolicy setting
+type PasswordPolicyConfig struct {
+	MinLength        int  // Minimum number of characters
+	MaxLength        int  // Maximum number of characters, 0 for no limit
+	RequireUppercase bool // Require an uppercase letter
+	RequireLowercase bool // Require a lowercase letter
+	RequireDigit     bool // Require a digit
+	RequireSymbol    bool // Require a character that is not a letter or digit
+	DisallowCommon   bool // Reject common passwords, e.g. "password123"
+	DisallowUserInfo bool // Reject passwords containing the user's email or name
+	HistorySize      int  // Reject reuse of the last N passwords, 0 to keep no history
+}
+
+// HeadersConfig holds the security headers set on all responses
+type HeadersConfig struct {
+	HSTSMaxAge            int    // Strict-Transport-Security max-age in seconds, 0 to omit the header
+	ContentSecurityPolicy string // Content-Security-Policy of API responses, empty to omit it
+	FrameOptions          string // X-Frame-Options, empty to omit it
+}
+
+// Load loads configuration from environment variables and the YAML or TOML
+// config file named by CONFIG_FILE, if any. Environment variables override the
+// file. Invalid values and unknown file keys are reported together.
 func Load() (*Config, error) {
 	// Load .env file if it exists (ignore error if not found)
 	_ = godotenv.Load()
 
+	src, err := newSource(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Development mode changes the defaults, so Heimdall runs without Postgres,
+	// Redis, OPA or FusionAuth; explicitly set values still apply
+	dev := src.getBool("HEIMDALL_DEV", false)
+	devDefault := func(devValue, value string) string {
+		if dev {
+			return devValue
+		}
+		return value
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:            getEnv("PORT", "8080"),
-			Environment:     getEnv("ENVIRONMENT", "development"),
-			AllowedOrigins:  []string{getEnv("ALLOWED_ORIGINS", "*")},
-			RateLimitPerMin: getEnvAsInt("RATE_LIMIT_PER_MIN", 100),
+			Port:               src.get("PORT", "8080"),
+			GRPCPort:           src.get("GRPC_PORT", ""),
+			Environment:        src.get("ENVIRONMENT", "development"),
+			AllowedOrigins:     src.getSlice("ALLOWED_ORIGINS", []string{"*"}),
+			RateLimitPerMin:    src.getInt("RATE_LIMIT_PER_MIN", 100),
+			RouteRateLimits:    map[string]int{"auth": 10, "authz": 1000},
+			AdminUI:            src.getBool("ADMIN_UI_ENABLED", true),
+			LoginUI:            src.getBool("LOGIN_UI_ENABLED", false),
+			LoginRedirects:     src.getSlice("LOGIN_UI_REDIRECT_URLS", nil),
+			LogLevel:           strings.ToLower(src.get("LOG_LEVEL", LogLevelInfo)),
+			Compression:        src.getBool("COMPRESSION_ENABLED", true),
+			ETagMinSize:        src.getInt("ETAG_MIN_SIZE_BYTES", 1024),
+			TrustedProxies:     src.getSlice("TRUSTED_PROXIES", nil),
+			ProxyHeader:        src.get("PROXY_HEADER", "X-Forwarded-For"),
+			BodyLogMaxBytes:    src.getInt("BODY_LOG_MAX_BYTES", 4096),
+			BodyLogMaxDuration: time.Duration(src.getInt("BODY_LOG_MAX_MINUTES", 60)) * time.Minute,
+			RequestTimeout:     time.Duration(src.getInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second,
+			RouteTimeouts:      map[string]time.Duration{"auth": 10 * time.Second, "upload": 2 * time.Minute},
+			BodyLimit:          src.getInt("BODY_LIMIT_BYTES", 1<<20),
+			RouteBodyLimits:    map[string]int{"auth": 16 << 10, "upload": 10 << 20},
+			ShutdownDelay:      time.Duration(src.getInt("SHUTDOWN_DELAY_SECONDS", 5)) * time.Second,
+			DrainTimeout:       time.Duration(src.getInt("SHUTDOWN_DRAIN_TIMEOUT_SECONDS", 30)) * time.Second,
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "heimdall"),
-			Password: getEnv("DB_PASSWORD", ""),
-			Database: getEnv("DB_NAME", "heimdall"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-			MaxConns: getEnvAsInt("DB_MAX_CONNS", 25),
-			MaxIdle:  getEnvAsInt("DB_MAX_IDLE", 5),
+			Driver:   src.get("DB_DRIVER", devDefault("sqlite", "postgres")),
+			Host:     src.get("DB_HOST", "localhost"),
+			Port:     src.get("DB_PORT", "5432"),
+			User:     src.get("DB_USER", "heimdall"),
+			Password: src.get("DB_PASSWORD", ""),
+			Database: src.get("DB_NAME", "heimdall"),
+			SSLMode:  src.get("DB_SSLMODE", "disable"),
+			DSN:      src.get("DB_DSN", ""),
+
+			ReplicaDSNs: src.getSlice("DB_REPLICA_DSNS", nil),
+
+			MaxConns:        src.getInt("DB_MAX_CONNS", 25),
+			MaxIdle:         src.getInt("DB_MAX_IDLE", 5),
+			ConnMaxLifetime: time.Duration(src.getInt("DB_CONN_MAX_LIFETIME_MIN", 60)) * time.Minute,
+
+			AutoMigrate: src.getBool("DB_AUTO_MIGRATE", dev),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvAsInt("REDIS_DB", 0),
+			Host:     src.get("REDIS_HOST", "localhost"),
+			Port:     src.get("REDIS_PORT", "6379"),
+			Password: src.get("REDIS_PASSWORD", ""),
+			DB:       src.getInt("REDIS_DB", 0),
+			Required: src.getBool("REDIS_REQUIRED", false),
+			Enabled:  src.getBool("REDIS_ENABLED", !dev),
 		},
 		JWT: JWTConfig{
-			PrivateKeyPath:     getEnv("JWT_PRIVATE_KEY_PATH", "./keys/private.pem"),
-			PublicKeyPath:      getEnv("JWT_PUBLIC_KEY_PATH", "./keys/public.pem"),
-			AccessTokenExpiry:  time.Duration(getEnvAsInt("JWT_ACCESS_EXPIRY_MIN", 15)) * time.Minute,
-			RefreshTokenExpiry: time.Duration(getEnvAsInt("JWT_REFRESH_EXPIRY_DAYS", 7)) * 24 * time.Hour,
-			Issuer:             getEnv("JWT_ISSUER", "heimdall"),
+			PrivateKeyPath:     src.get("JWT_PRIVATE_KEY_PATH", "./keys/private.pem"),
+			PublicKeyPath:      src.get("JWT_PUBLIC_KEY_PATH", "./keys/public.pem"),
+			AccessTokenExpiry:  time.Duration(src.getInt("JWT_ACCESS_EXPIRY_MIN", 15)) * time.Minute,
+			RefreshTokenExpiry: time.Duration(src.getInt("JWT_REFRESH_EXPIRY_DAYS", 7)) * 24 * time.Hour,
+			Issuer:             src.get("JWT_ISSUER", "heimdall"),
+			Audience:           src.get("JWT_AUDIENCE", "heimdall"),
+			KeyRefreshInterval: time.Duration(src.getInt("JWT_KEY_REFRESH_SECONDS", 60)) * time.Second,
 		},
 		Auth: AuthConfig{
-			URL:              getEnv("FUSIONAUTH_URL", "http://localhost:9011"),
-			APIKey:           getEnv("FUSIONAUTH_API_KEY", ""),
-			TenantID:         getEnv("FUSIONAUTH_TENANT_ID", ""),
-			ApplicationID:    getEnv("FUSIONAUTH_APPLICATION_ID", ""),
-			OAuthRedirectURL: getEnv("OAUTH_REDIRECT_URL", "http://localhost:8080/v1/auth/oauth/callback"),
+			Provider:         src.get("IDENTITY_PROVIDER", devDefault("dev", "fusionauth")),
+			URL:              src.get("FUSIONAUTH_URL", "http://localhost:9011"),
+			APIKey:           src.get("FUSIONAUTH_API_KEY", ""),
+			TenantID:         src.get("FUSIONAUTH_TENANT_ID", ""),
+			ApplicationID:    src.get("FUSIONAUTH_APPLICATION_ID", ""),
+			OAuthRedirectURL: src.get("OAUTH_REDIRECT_URL", "http://localhost:8080/v1/auth/oauth/callback"),
+			Timeout:          time.Duration(src.getInt("FUSIONAUTH_TIMEOUT_SECONDS", 30)) * time.Second,
+			MaxRetries:       src.getInt("FUSIONAUTH_MAX_RETRIES", 2),
+			RetryBackoff:     time.Duration(src.getInt("FUSIONAUTH_RETRY_BACKOFF_MS", 100)) * time.Millisecond,
+			BreakerThreshold: src.getInt("FUSIONAUTH_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  time.Duration(src.getInt("FUSIONAUTH_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+
+			CredentialCacheTTL: time.Duration(src.getInt("FUSIONAUTH_CREDENTIAL_CACHE_SECONDS", 0)) * time.Second,
 		},
 		SMTP: SMTPConfig{
-			Host:     getEnv("SMTP_HOST", "localhost"),
-			Port:     getEnvAsInt("SMTP_PORT", 587),
-			Username: getEnv("SMTP_USERNAME", ""),
-			Password: getEnv("SMTP_PASSWORD", ""),
-			From:     getEnv("SMTP_FROM", "noreply@heimdall.local"),
+			Host:     src.get("SMTP_HOST", "localhost"),
+			Port:     src.getInt("SMTP_PORT", 587),
+			Username: src.get("SMTP_USERNAME", ""),
+			Password: src.get("SMTP_PASSWORD", ""),
+			From:     src.get("SMTP_FROM", "noreply@heimdall.local"),
 		},
 		OPA: OPAConfig{
-			URL:         getEnv("OPA_URL", "http://localhost:8181"),
-			PolicyPath:  getEnv("OPA_POLICY_PATH", "heimdall/authz"),
-			Timeout:     time.Duration(getEnvAsInt("OPA_TIMEOUT_SECONDS", 5)) * time.Second,
-			EnableCache: getEnv("OPA_ENABLE_CACHE", "true") == "true",
+			URL:         src.get("OPA_URL", "http://localhost:8181"),
+			PolicyPath:  src.get("OPA_POLICY_PATH", "heimdall/authz"),
+			Timeout:     time.Duration(src.getInt("OPA_TIMEOUT_SECONDS", 5)) * time.Second,
+			EnableCache: src.getBool("OPA_ENABLE_CACHE", true),
+			CacheTTL:    time.Duration(src.getInt("OPA_CACHE_TTL_SECONDS", 300)) * time.Second,
+			MaxStale:    time.Duration(src.getInt("OPA_CACHE_MAX_STALE_SECONDS", 300)) * time.Second,
+			Binary:      src.get("OPA_BINARY", "opa"),
+
+			MaxIdleConns:     src.getInt("OPA_MAX_IDLE_CONNS", 100),
+			MaxRetries:       src.getInt("OPA_MAX_RETRIES", 2),
+			RetryBackoff:     time.Duration(src.getInt("OPA_RETRY_BACKOFF_MS", 50)) * time.Millisecond,
+			BreakerThreshold: src.getInt("OPA_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  time.Duration(src.getInt("OPA_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+
+			DataSyncInterval:         time.Duration(src.getInt("OPA_DATA_SYNC_INTERVAL_SECONDS", 300)) * time.Second,
+			RoutePermissionsInterval: time.Duration(src.getInt("OPA_ROUTE_PERMISSIONS_INTERVAL_SECONDS", 60)) * time.Second,
+
+			AttributeSources: []AttributeSourceConfig{
+				{ResourceType: "users", Source: "database"},
+				{ResourceType: "roles", Source: "database"},
+				{ResourceType: "policies", Source: "database"},
+				{ResourceType: "bundles", Source: "database"},
+				{ResourceType: "tenants", Source: "database"},
+				{ResourceType: "*", Source: "registry"},
+			},
+			AttributeSourceTimeout: time.Duration(src.getInt("OPA_ATTRIBUTE_SOURCE_TIMEOUT_MS", 500)) * time.Millisecond,
+
+			PrefetchPermissions: src.getBool("OPA_PREFETCH_PERMISSIONS", false),
+			PermissionCacheTTL:  time.Duration(src.getInt("OPA_PERMISSION_CACHE_TTL_SECONDS", 300)) * time.Second,
+			RowFilters:          src.getBool("OPA_ROW_FILTERS", false),
+			Embedded:            src.getBool("OPA_EMBEDDED", dev),
+		},
+		BundleStorage: BundleStorageConfig{
+			Backend: src.get("BUNDLE_STORAGE_BACKEND", devDefault(BundleStorageLocal, BundleStorageMinIO)),
+			MinIO: MinIOConfig{
+				Endpoint:       src.get("MINIO_ENDPOINT", "localhost:9000"),
+				AccessKey:      src.get("MINIO_ACCESS_KEY", "minioadmin"),
+				SecretKey:      src.get("MINIO_SECRET_KEY", "minioadmin"),
+				Bucket:         src.get("MINIO_BUCKET", "bundles"),
+				UseSSL:         src.getBool("MINIO_USE_SSL", false),
+				PublicEndpoint: src.get("MINIO_PUBLIC_ENDPOINT", ""),
+				PublicUseSSL:   src.getBool("MINIO_PUBLIC_USE_SSL", false),
+			},
+			S3: S3Config{
+				Bucket:          src.get("S3_BUCKET", ""),
+				Region:          src.get("S3_REGION", src.get("AWS_REGION", "us-east-1")),
+				Endpoint:        src.get("S3_ENDPOINT", ""),
+				AccessKeyID:     src.get("S3_ACCESS_KEY_ID", ""),
+				SecretAccessKey: src.get("S3_SECRET_ACCESS_KEY", ""),
+			},
+			GCS: GCSConfig{
+				Bucket:    src.get("GCS_BUCKET", ""),
+				AccessKey: src.get("GCS_HMAC_ACCESS_KEY", ""),
+				SecretKey: src.get("GCS_HMAC_SECRET", ""),
+			},
+			LocalDir: src.get("BUNDLE_STORAGE_DIR", "./data/bundles"),
+		},
+		Security: SecurityConfig{
+			MaxAccountFailures: src.getInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      src.getInt("LOGIN_MAX_IP_FAILURES", 20),
+			FailureWindow:      time.Duration(src.getInt("LOGIN_FAILURE_WINDOW_MIN", 15)) * time.Minute,
+			LockoutDuration:    time.Duration(src.getInt("LOGIN_LOCKOUT_MIN", 15)) * time.Minute,
+			BackoffBase:        time.Duration(src.getInt("LOGIN_BACKOFF_BASE_MS", 500)) * time.Millisecond,
+			BackoffMax:         time.Duration(src.getInt("LOGIN_BACKOFF_MAX_SECONDS", 30)) * time.Second,
+			GeoIPURL:           src.get("GEOIP_URL", ""),
+			LoginAlertEmail:    src.getBool("LOGIN_ALERT_EMAIL_ENABLED", false),
+			MaxElevation:       time.Duration(src.getInt("ROLE_ELEVATION_MAX_HOURS", 24)) * time.Hour,
+			RoleExpiryInterval: time.Duration(src.getInt("ROLE_EXPIRY_INTERVAL_SECONDS", 60)) * time.Second,
+			AccessRequestEmail: src.getBool("ACCESS_REQUEST_EMAIL_ENABLED", false),
+			AnalyticsInterval:  time.Duration(src.getInt("AUTH_ANALYTICS_INTERVAL_SECONDS", 900)) * time.Second,
+			ActionNonceTTL:     time.Duration(src.getInt("ACTION_NONCE_TTL_SECONDS", 300)) * time.Second,
+		},
+		Webhooks: WebhookConfig{
+			URLs:    src.getSlice("WEBHOOK_URLS", nil),
+			Secret:  src.get("WEBHOOK_SECRET", ""),
+			Timeout: time.Duration(src.getInt("WEBHOOK_TIMEOUT_SECONDS", 5)) * time.Second,
+		},
+		BreakGlass: BreakGlassConfig{
+			PublicKeyPath: src.get("BREAK_GLASS_PUBLIC_KEY_PATH", ""),
+			MaxTTL:        time.Duration(src.getInt("BREAK_GLASS_MAX_TTL_MINUTES", 60)) * time.Minute,
+			WebhookURLs:   src.getSlice("BREAK_GLASS_WEBHOOK_URLS", nil),
+		},
+		LoginHooks: LoginHookConfig{
+			URLs:     src.getSlice("LOGIN_HOOK_URLS", nil),
+			Secret:   src.get("LOGIN_HOOK_SECRET", ""),
+			Timeout:  time.Duration(src.getInt("LOGIN_HOOK_TIMEOUT_SECONDS", 3)) * time.Second,
+			FailOpen: src.getBool("LOGIN_HOOK_FAIL_OPEN", false),
+			Stages:   src.getSlice("LOGIN_HOOK_STAGES", []string{"pre_auth", "post_auth"}),
+		},
+		PolicySync: PolicySyncConfig{
+			GitRepoURL:    src.get("POLICY_GIT_REPO_URL", ""),
+			GitBranch:     src.get("POLICY_GIT_BRANCH", "main"),
+			GitDirectory:  src.get("POLICY_GIT_DIRECTORY", ""),
+			GitTenantID:   src.get("POLICY_GIT_TENANT_ID", ""),
+			GitDelete:     src.getBool("POLICY_GIT_DELETE", false),
+			WebhookSecret: src.get("POLICY_GIT_WEBHOOK_SECRET", ""),
+			Timeout:       time.Duration(src.getInt("POLICY_GIT_TIMEOUT_SECONDS", 60)) * time.Second,
+		},
+		Outbox: OutboxConfig{
+			PollInterval:      time.Duration(src.getInt("OUTBOX_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+			BatchSize:         src.getInt("OUTBOX_BATCH_SIZE", 50),
+			MaxAttempts:       src.getInt("OUTBOX_MAX_ATTEMPTS", 10),
+			ReconcileInterval: time.Duration(src.getInt("USER_RECONCILE_INTERVAL_MIN", 60)) * time.Minute,
+			ReconcileRepair:   src.getBool("USER_RECONCILE_REPAIR", true),
+			PurgeInterval:     time.Duration(src.getInt("USER_PURGE_INTERVAL_MIN", 60)) * time.Minute,
+			PurgeRetention:    time.Duration(src.getInt("USER_PURGE_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		},
+		Jobs: JobConfig{
+			Workers:      src.getInt("JOB_WORKERS", 4),
+			PollInterval: time.Duration(src.getInt("JOB_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+			MaxAttempts:  src.getInt("JOB_MAX_ATTEMPTS", 5),
+			Lease:        time.Duration(src.getInt("JOB_LEASE_SECONDS", 600)) * time.Second,
+		},
+		Cleanup: CleanupConfig{
+			Interval:            time.Duration(src.getInt("CLEANUP_INTERVAL_MIN", 60)) * time.Minute,
+			SoftDeleteRetention: time.Duration(src.getInt("CLEANUP_SOFT_DELETE_RETENTION_DAYS", 90)) * 24 * time.Hour,
+			AuditLogRetention:   time.Duration(src.getInt("AUDIT_LOG_RETENTION_DAYS", 0)) * 24 * time.Hour,
+			AuditLogArchive:     src.getBool("AUDIT_LOG_ARCHIVE", false),
+			OrphanGracePeriod:   time.Duration(src.getInt("CLEANUP_ORPHAN_GRACE_HOURS", 24)) * time.Hour,
+		},
+		Coordination: CoordinationConfig{
+			InstanceID: src.get("INSTANCE_ID", defaultInstanceID()),
+			LeaseTTL:   time.Duration(src.getInt("LEADER_LEASE_SECONDS", 30)) * time.Second,
 		},
-		MinIO: MinIOConfig{
-			Endpoint:  getEnv("MINIO_ENDPOINT", "localhost:9000"),
-			AccessKey: getEnv("MINIO_ACCESS_KEY", "minioadmin"),
-			SecretKey: getEnv("MINIO_SECRET_KEY", "minioadmin"),
-			Bucket:    getEnv("MINIO_BUCKET", "bundles"),
-			UseSSL:    getEnv("MINIO_USE_SSL", "false") == "true",
+		Startup: StartupConfig{
+			DatabaseWait:         time.Duration(src.getInt("STARTUP_DATABASE_WAIT_SECONDS", 30)) * time.Second,
+			RedisWait:            time.Duration(src.getInt("STARTUP_REDIS_WAIT_SECONDS", 0)) * time.Second,
+			OPAWait:              time.Duration(src.getInt("STARTUP_OPA_WAIT_SECONDS", 0)) * time.Second,
+			BundleStorageWait:    time.Duration(src.getInt("STARTUP_BUNDLE_STORAGE_WAIT_SECONDS", 0)) * time.Second,
+			RetryBackoff:         time.Duration(src.getInt("STARTUP_RETRY_BACKOFF_MS", 500)) * time.Millisecond,
+			CriticalDependencies: src.getSlice("CRITICAL_DEPENDENCIES", nil),
+			CheckInterval:        time.Duration(src.getInt("DEPENDENCY_CHECK_INTERVAL_SECONDS", 30)) * time.Second,
 		},
+		LDAP: LDAPConfig{
+			URL:                src.get("LDAP_URL", ""),
+			StartTLS:           src.getBool("LDAP_START_TLS", false),
+			InsecureSkipVerify: src.getBool("LDAP_INSECURE_SKIP_VERIFY", false),
+			BindDN:             src.get("LDAP_BIND_DN", ""),
+			BindPassword:       src.get("LDAP_BIND_PASSWORD", ""),
+			BaseDN:             src.get("LDAP_BASE_DN", ""),
+			UserFilter:         src.get("LDAP_USER_FILTER", "(&(objectClass=person)(|(mail={username})(userPrincipalName={username})))"),
+			EmailAttribute:     src.get("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			FirstNameAttribute: src.get("LDAP_FIRST_NAME_ATTRIBUTE", "givenName"),
+			LastNameAttribute:  src.get("LDAP_LAST_NAME_ATTRIBUTE", "sn"),
+			GroupAttribute:     src.get("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+			TenantSlug:         src.get("LDAP_TENANT", "default"),
+			SyncInterval:       time.Duration(src.getInt("LDAP_SYNC_INTERVAL_MIN", 30)) * time.Minute,
+			Timeout:            time.Duration(src.getInt("LDAP_TIMEOUT_SECONDS", 10)) * time.Second,
+		},
+		SAML: SAMLConfig{
+			BaseURL:             strings.TrimSuffix(src.get("SAML_BASE_URL", "http://localhost:8080"), "/"),
+			CertificatePath:     src.get("SAML_CERTIFICATE_PATH", ""),
+			PrivateKeyPath:      src.get("SAML_PRIVATE_KEY_PATH", ""),
+			AllowedRedirectURLs: src.getSlice("SAML_ALLOWED_REDIRECT_URLS", nil),
+		},
+		OAuth: OAuthConfig{
+			DeviceVerificationURI: src.get("OAUTH_DEVICE_VERIFICATION_URI", "http://localhost:3000/device"),
+			DeviceCodeExpiry:      time.Duration(src.getInt("OAUTH_DEVICE_CODE_EXPIRY_MIN", 10)) * time.Minute,
+			DevicePollInterval:    time.Duration(src.getInt("OAUTH_DEVICE_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+		},
+		MagicLink: MagicLinkConfig{
+			URL:        src.get("MAGIC_LINK_URL", "http://localhost:3000/auth/magic-link"),
+			Expiry:     time.Duration(src.getInt("MAGIC_LINK_EXPIRY_MIN", 15)) * time.Minute,
+			MaxPerHour: src.getInt("MAGIC_LINK_MAX_PER_HOUR", 5),
+		},
+		PasswordBreach: PasswordBreachConfig{
+			Enabled:    src.getBool("PASSWORD_BREACH_CHECK", false),
+			APIURL:     src.get("PASSWORD_BREACH_API_URL", "https://api.pwnedpasswords.com"),
+			FilterPath: src.get("PASSWORD_BREACH_FILTER_PATH", ""),
+			Timeout:    time.Duration(src.getInt("PASSWORD_BREACH_TIMEOUT_SECONDS", 3)) * time.Second,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:        src.getInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:        src.getInt("PASSWORD_MAX_LENGTH", 128),
+			RequireUppercase: src.getBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase: src.getBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:     src.getBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:    src.getBool("PASSWORD_REQUIRE_SYMBOL", false),
+			DisallowCommon:   src.getBool("PASSWORD_DISALLOW_COMMON", true),
+			DisallowUserInfo: src.getBool("PASSWORD_DISALLOW_USER_INFO", true),
+			HistorySize:      src.getInt("PASSWORD_HISTORY_SIZE", 0),
+		},
+		Headers: HeadersConfig{
+			ContentSecurityPolicy: src.get("SECURITY_CSP", "default-src 'none'; frame-ancestors 'none'"),
+			FrameOptions:          src.get("SECURITY_FRAME_OPTIONS", "DENY"),
+		},
+		Encryption: EncryptionConfig{
+			Keys:              src.getSlice("ENCRYPTION_KEYS", nil),
+			KeysFile:          src.get("ENCRYPTION_KEYS_FILE", ""),
+			ReencryptInterval: time.Duration(src.getInt("ENCRYPTION_REENCRYPT_INTERVAL_MIN", 60)) * time.Minute,
+		},
+		Alerts: AlertConfig{
+			EvaluationInterval: time.Duration(src.getInt("ALERT_EVALUATION_INTERVAL_SECONDS", 60)) * time.Second,
+			PagerDutyURL:       src.get("PAGERDUTY_EVENTS_URL", "https://events.pagerduty.com/v2/enqueue"),
+		},
+		Bootstrap: BootstrapConfig{
+			AdminEmail: src.get("BOOTSTRAP_ADMIN_EMAIL", devDefault("admin@heimdall.dev", "")),
+			TenantSlug: src.get("BOOTSTRAP_TENANT_SLUG", "default"),
+		},
+		Dev: DevConfig{
+			Enabled: dev,
+			Fixture: src.get("HEIMDALL_DEV_FIXTURE", ""),
+		},
+	}
+
+	// The development database is a SQLite file in the working directory
+	if dev && cfg.Database.Driver == "sqlite" && cfg.Database.DSN == "" {
+		cfg.Database.DSN = "heimdall-dev.db"
+	}
+
+	// HSTS is only sent by default in production, where Heimdall is served over HTTPS
+	hstsMaxAge := 0
+	if cfg.Server.Environment == "production" {
+		hstsMaxAge = 31536000
+	}
+	cfg.Headers.HSTSMaxAge = src.getInt("SECURITY_HSTS_MAX_AGE", hstsMaxAge)
+
+	// Group mappings are JSON, as group DNs contain commas
+	if mappings := src.get("LDAP_GROUP_MAPPINGS", ""); mappings != "" {
+		if err := json.Unmarshal([]byte(mappings), &cfg.LDAP.GroupMappings); err != nil {
+			return nil, fmt.Errorf("invalid LDAP_GROUP_MAPPINGS: %w", err)
+		}
+	}
+
+	// Route rate limits are JSON, overriding the defaults of the classes they name
+	if limits := src.get("RATE_LIMIT_ROUTES", ""); limits != "" {
+		if err := json.Unmarshal([]byte(limits), &cfg.Server.RouteRateLimits); err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES: %w", err)
+		}
+	}
+
+	// Route timeouts in seconds and body limits in bytes are JSON too
+	if timeouts := src.get("REQUEST_TIMEOUT_ROUTES", ""); timeouts != "" {
+		var seconds map[string]int
+		if err := json.Unmarshal([]byte(timeouts), &seconds); err != nil {
+			return nil, fmt.Errorf("invalid REQUEST_TIMEOUT_ROUTES: %w", err)
+		}
+		for route, n := range seconds {
+			cfg.Server.RouteTimeouts[route] = time.Duration(n) * time.Second
+		}
+	}
+	if limits := src.get("BODY_LIMIT_ROUTES", ""); limits != "" {
+		if err := json.Unmarshal([]byte(limits), &cfg.Server.RouteBodyLimits); err != nil {
+			return nil, fmt.Errorf("invalid BODY_LIMIT_ROUTES: %w", err)
+		}
+	}
+
+	// Attribute sources are JSON, replacing the database sources of Heimdall's own
+	// resources and the resource registry
+	if sources := src.get("OPA_ATTRIBUTE_SOURCES", ""); sources != "" {
+		cfg.OPA.AttributeSources = nil
+		if err := json.Unmarshal([]byte(sources), &cfg.OPA.AttributeSources); err != nil {
+			return nil, fmt.Errorf("invalid OPA_ATTRIBUTE_SOURCES: %w", err)
+		}
+	}
+
+	if err := src.err(); err != nil {
+		return nil, err
 	}
 
 	// Validate required configuration
@@ -167,19 +878,101 @@ func Load() (*Config, error) {
 
 // Validate checks if required configuration is present
 func (c *Config) Validate() error {
+	switch c.Server.LogLevel {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		return fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.Server.LogLevel)
+	}
+	if c.Server.BodyLogMaxBytes < 0 || c.Server.BodyLogMaxDuration < 0 {
+		return fmt.Errorf("BODY_LOG_MAX_BYTES and BODY_LOG_MAX_MINUTES cannot be negative")
+	}
 	if c.Server.Environment == "production" {
-		if c.Database.Password == "" {
+		if slices.Contains(c.Server.AllowedOrigins, "*") {
+			return fmt.Errorf("ALLOWED_ORIGINS cannot be * in production")
+		}
+		if c.Database.Driver == "postgres" && c.Database.Password == "" && c.Database.DSN == "" {
 			return fmt.Errorf("DB_PASSWORD is required in production")
 		}
-		if c.Auth.APIKey == "" {
+		if c.Auth.Provider == "fusionauth" && c.Auth.APIKey == "" {
 			return fmt.Errorf("FUSIONAUTH_API_KEY is required")
 		}
 	}
+	if c.Dev.Enabled && c.Server.Environment == "production" {
+		return fmt.Errorf("HEIMDALL_DEV cannot be enabled in production")
+	}
+	if c.Auth.Provider == "dev" && !c.Dev.Enabled {
+		return fmt.Errorf("IDENTITY_PROVIDER=dev accepts any password and requires HEIMDALL_DEV=true")
+	}
+	if c.Auth.CredentialCacheTTL < 0 || c.Auth.CredentialCacheTTL > MaxCredentialCacheTTL {
+		return fmt.Errorf("FUSIONAUTH_CREDENTIAL_CACHE_SECONDS must be between 0 and %d", int(MaxCredentialCacheTTL.Seconds()))
+	}
+	if c.Coordination.LeaseTTL < MinLeaseTTL {
+		return fmt.Errorf("LEADER_LEASE_SECONDS must be at least %d", int(MinLeaseTTL.Seconds()))
+	}
+	if c.LDAP.URL != "" && c.LDAP.BaseDN == "" {
+		return fmt.Errorf("LDAP_BASE_DN is required when LDAP_URL is set")
+	}
+	for _, mapping := range c.LDAP.GroupMappings {
+		if mapping.Group == "" || mapping.Tenant == "" || mapping.Role == "" {
+			return fmt.Errorf("LDAP group mappings require group, tenant and role")
+		}
+	}
+	switch c.BundleStorage.Backend {
+	case BundleStorageMinIO, BundleStorageLocal:
+	case BundleStorageS3:
+		if c.BundleStorage.S3.Bucket == "" {
+			return fmt.Errorf("S3_BUCKET is required for the s3 bundle storage backend")
+		}
+	case BundleStorageGCS:
+		if c.BundleStorage.GCS.Bucket == "" || c.BundleStorage.GCS.AccessKey == "" || c.BundleStorage.GCS.SecretKey == "" {
+			return fmt.Errorf("GCS_BUCKET, GCS_HMAC_ACCESS_KEY and GCS_HMAC_SECRET are required for the gcs bundle storage backend")
+		}
+	default:
+		return fmt.Errorf("unknown bundle storage backend %q", c.BundleStorage.Backend)
+	}
+	for _, stage := range c.LoginHooks.Stages {
+		switch stage {
+		case "pre_auth", "post_auth", "pre_register", "post_register", "pre_token_issue":
+		default:
+			return fmt.Errorf("unknown login hook stage %q", stage)
+		}
+	}
+	if policy := c.PasswordPolicy; policy.MinLength < 0 || policy.MaxLength < 0 || (policy.MaxLength != 0 && policy.MaxLength < policy.MinLength) {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH and PASSWORD_MAX_LENGTH cannot be negative, and PASSWORD_MAX_LENGTH must be 0 or at least PASSWORD_MIN_LENGTH")
+	}
+	if c.PasswordPolicy.HistorySize < 0 || c.PasswordPolicy.HistorySize > 24 {
+		return fmt.Errorf("PASSWORD_HISTORY_SIZE must be between 0 and 24")
+	}
+	for _, dependency := range c.Startup.CriticalDependencies {
+		if dependency != DependencyOPA && dependency != DependencyBundleStorage {
+			return fmt.Errorf("unknown critical dependency %q, expected opa or bundle_storage", dependency)
+		}
+	}
+	for _, source := range c.OPA.AttributeSources {
+		if source.ResourceType == "" {
+			return fmt.Errorf("attribute sources require a resource type")
+		}
+		switch source.Source {
+		case "database", "registry", "user":
+		case "http":
+			if source.URL == "" {
+				return fmt.Errorf("http attribute source of %s requires a url", source.ResourceType)
+			}
+		default:
+			return fmt.Errorf("unknown attribute source %q of %s", source.Source, source.ResourceType)
+		}
+	}
 	return nil
 }
 
 // GetDatabaseDSN returns the PostgreSQL connection string
 func (c *Config) GetDatabaseDSN() string {
+	if c.Database.DSN != "" {
+		return c.Database.DSN
+	}
+	if c.Database.Driver == "sqlite" {
+		return "heimdall.db"
+	}
 	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		c.Database.Host,
@@ -196,19 +989,15 @@ func (c *Config) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%s", c.Redis.Host, c.Redis.Port)
 }
 
-// Helper functions
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
-		}
+// defaultInstanceID returns the hostname with a random suffix, so instances
+// sharing a hostname, e.g. on a developer machine, do not share leadership.
+// It is generated once, so reloading the configuration keeps it.
+var defaultInstanceID = sync.OnceValue(func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "heimdall"
 	}
-	return defaultValue
-}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return hostname + "-" + hex.EncodeToString(suffix)
+})
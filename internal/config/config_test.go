@@ -0,0 +1,241 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Run("YAML sections with environment overrides", func(t *testing.T) {
+		writeConfigFile(t, "heimdall.yaml", `
+port: 9090
+log_level: warn
+allowed_origins:
+  - https://app.example.com
+  - https://*.example.com
+db:
+  host: db.internal
+  port: 6543
+rate_limit:
+  per_min: 200
+  routes:
+    auth: 20
+request_timeout:
+  routes:
+    auth: 5
+opa:
+  enable_cache: false
+  cache_ttl_seconds: 60
+  attribute_sources:
+    - resourceType: documents
+      source: registry
+`)
+		t.Setenv("DB_HOST", "db.override")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg.Server.Port != "9090" || cfg.Server.LogLevel != LogLevelWarn || cfg.Server.RateLimitPerMin != 200 {
+			t.Errorf("Unexpected server config %+v", cfg.Server)
+		}
+		if !slices.Equal(cfg.Server.AllowedOrigins, []string{"https://app.example.com", "https://*.example.com"}) {
+			t.Errorf("Unexpected allowed origins %v", cfg.Server.AllowedOrigins)
+		}
+		if cfg.Server.RouteRateLimits["auth"] != 20 || cfg.Server.RouteRateLimits["authz"] != 1000 {
+			t.Errorf("Expected route limits to override the auth default, got %v", cfg.Server.RouteRateLimits)
+		}
+		if cfg.Server.RouteTimeouts["auth"] != 5*time.Second || cfg.Server.RouteTimeouts["upload"] != 2*time.Minute {
+			t.Errorf("Expected route timeouts to override the auth default, got %v", cfg.Server.RouteTimeouts)
+		}
+		if cfg.Database.Host != "db.override" || cfg.Database.Port != "6543" {
+			t.Errorf("Expected the environment to override the file, got %s:%s", cfg.Database.Host, cfg.Database.Port)
+		}
+		if cfg.OPA.EnableCache || cfg.OPA.CacheTTL != time.Minute {
+			t.Errorf("Unexpected OPA cache config %v %v", cfg.OPA.EnableCache, cfg.OPA.CacheTTL)
+		}
+		if len(cfg.OPA.AttributeSources) != 1 || cfg.OPA.AttributeSources[0].ResourceType != "documents" {
+			t.Errorf("Unexpected attribute sources %+v", cfg.OPA.AttributeSources)
+		}
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		writeConfigFile(t, "heimdall.toml", `
+environment = "staging"
+
+[smtp]
+port = 2525
+
+[[ldap.group_mappings]]
+group = "cn=admins,dc=example,dc=com"
+tenant = "default"
+role = "admin"
+`)
+		t.Setenv("LDAP_URL", "ldap://ldap.internal")
+		t.Setenv("LDAP_BASE_DN", "dc=example,dc=com")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg.Server.Environment != "staging" || cfg.SMTP.Port != 2525 {
+			t.Errorf("Unexpected config %s %d", cfg.Server.Environment, cfg.SMTP.Port)
+		}
+		if len(cfg.LDAP.GroupMappings) != 1 || cfg.LDAP.GroupMappings[0].Role != "admin" {
+			t.Errorf("Unexpected group mappings %+v", cfg.LDAP.GroupMappings)
+		}
+	})
+
+	t.Run("reports invalid values and unknown keys", func(t *testing.T) {
+		path := writeConfigFile(t, "heimdall.yml", `
+db:
+  hots: localhost
+  max_conns: many
+redis:
+  required: maybe
+`)
+
+		_, err := Load()
+		if err == nil {
+			t.Fatal("Expected invalid config to fail")
+		}
+		for _, want := range []string{
+			"unknown setting db.hots in " + path + " (did you mean DB_HOST?)",
+			`db.max_conns in ` + path + ` must be a whole number, got "many"`,
+			`redis.required in ` + path + ` must be true or false, got "maybe"`,
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to contain %q, got: %v", want, err)
+			}
+		}
+	})
+
+	t.Run("reports invalid environment values", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", "")
+		t.Setenv("SMTP_PORT", "smtp")
+
+		_, err := Load()
+		if err == nil || !strings.Contains(err.Error(), `SMTP_PORT must be a whole number, got "smtp"`) {
+			t.Errorf("Expected invalid SMTP_PORT to be reported, got %v", err)
+		}
+	})
+
+	t.Run("development mode defaults", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", "")
+		t.Setenv("HEIMDALL_DEV", "true")
+		t.Setenv("BUNDLE_STORAGE_BACKEND", BundleStorageMinIO)
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if cfg.Database.Driver != "sqlite" || cfg.Database.DSN != "heimdall-dev.db" || !cfg.Database.AutoMigrate {
+			t.Errorf("Expected an auto-migrated SQLite database, got %+v", cfg.Database)
+		}
+		if cfg.Redis.Enabled || !cfg.OPA.Embedded || cfg.Auth.Provider != "dev" || cfg.Bootstrap.AdminEmail == "" {
+			t.Errorf("Expected the in-process store, embedded OPA, dev identity provider and an admin, got %v %v %s %q",
+				cfg.Redis.Enabled, cfg.OPA.Embedded, cfg.Auth.Provider, cfg.Bootstrap.AdminEmail)
+		}
+		if cfg.BundleStorage.Backend != BundleStorageMinIO {
+			t.Errorf("Expected explicit settings to override the defaults, got %s", cfg.BundleStorage.Backend)
+		}
+
+		t.Setenv("ENVIRONMENT", "production")
+		if _, err := Load(); err == nil {
+			t.Error("Expected development mode to be rejected in production")
+		}
+		t.Setenv("ENVIRONMENT", "development")
+		t.Setenv("HEIMDALL_DEV", "false")
+		t.Setenv("IDENTITY_PROVIDER", "dev")
+		if _, err := Load(); err == nil {
+			t.Error("Expected the dev identity provider to require development mode")
+		}
+	})
+
+	t.Run("rejects unsupported file types", func(t *testing.T) {
+		writeConfigFile(t, "heimdall.json", `{}`)
+		if _, err := Load(); err == nil {
+			t.Error("Expected a .json config file to be rejected")
+		}
+	})
+}
+
+func TestReloader(t *testing.T) {
+	initial := &Config{
+		Server: ServerConfig{Port: "8080", LogLevel: LogLevelInfo, RateLimitPerMin: 100, AllowedOrigins: []string{"*"}},
+		OPA:    OPAConfig{URL: "http://localhost:8181", CacheTTL: 5 * time.Minute},
+		BundleStorage: BundleStorageConfig{
+			Backend: BundleStorageLocal,
+		},
+		Coordination: CoordinationConfig{LeaseTTL: 30 * time.Second},
+	}
+	next := *initial
+	next.Server.Port = "9090"
+	next.Server.LogLevel = LogLevelError
+	next.Server.RateLimitPerMin = 50
+	next.OPA.URL = "http://opa:8181"
+	next.OPA.CacheTTL = time.Minute
+
+	r := NewReloader(initial)
+	r.load = func() (*Config, error) {
+		reloaded := next
+		return &reloaded, nil
+	}
+	var notified *Config
+	r.OnReload(func(cfg *Config) { notified = cfg })
+
+	restart, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	current := r.Current()
+	if current.Server.LogLevel != LogLevelError || current.Server.RateLimitPerMin != 50 || current.OPA.CacheTTL != time.Minute {
+		t.Errorf("Expected reloadable settings to apply, got %+v %+v", current.Server, current.OPA)
+	}
+	if current.Server.Port != "8080" || current.OPA.URL != "http://localhost:8181" {
+		t.Errorf("Expected other settings to be kept until a restart, got %s %s", current.Server.Port, current.OPA.URL)
+	}
+	if !slices.Equal(restart, []string{"Server", "OPA"}) {
+		t.Errorf("Expected Server and OPA to require a restart, got %v", restart)
+	}
+	if notified != current {
+		t.Error("Expected listeners to be called with the applied configuration")
+	}
+
+	// An invalid configuration leaves the running one in place
+	next.Server.LogLevel = "verbose"
+	if _, err := r.Reload(); err == nil {
+		t.Error("Expected an invalid log level to be rejected")
+	}
+	if r.Current() != current {
+		t.Error("Expected the running configuration to be kept")
+	}
+}
+
+func TestValidateLeaseTTL(t *testing.T) {
+	// Leaders renew every third of the lease, which must not round down to zero
+	for value, valid := range map[string]bool{"30": true, "3": true, "2": false, "0": false, "-5": false} {
+		t.Setenv("LEADER_LEASE_SECONDS", value)
+		_, err := Load()
+		if valid && err != nil {
+			t.Errorf("Expected a lease of %s seconds to be valid, got %v", value, err)
+		}
+		if !valid && (err == nil || !strings.Contains(err.Error(), "LEADER_LEASE_SECONDS")) {
+			t.Errorf("Expected a lease of %s seconds to be rejected, got %v", value, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// Reloader holds the running configuration. Reloading it applies the settings
+// that are safe to change while the server runs: the log level, rate limits,
+// CORS origins and OPA decision cache TTLs. Other changes are reported and
+// take effect after a restart.
+type Reloader struct {
+	load func() (*Config, error)
+
+	mu        sync.Mutex // Serializes reloads
+	current   atomic.Pointer[Config]
+	listeners []func(*Config)
+}
+
+// NewReloader creates a reloader of the configuration loaded at startup
+func NewReloader(cfg *Config) *Reloader {
+	r := &Reloader{load: Load}
+	r.current.Store(cfg)
+	return r
+}
+
+// Current returns the configuration in effect. It must not be modified.
+func (r *Reloader) Current() *Config {
+	return r.current.Load()
+}
+
+// OnReload registers a function called with the configuration after each
+// reload that applied it
+func (r *Reloader) OnReload(fn func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Reload loads the configuration again and applies its reloadable settings.
+// It returns the sections with changes that require a restart. The running
+// configuration is kept when the new one is invalid.
+func (r *Reloader) Reload() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	current := r.Current()
+
+	applied := *current
+	applyReloadable(&applied, next)
+	if err := applied.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Compare the rest of the configuration with the reloadable settings left alone
+	rest := *next
+	applyReloadable(&rest, current)
+	var restart []string
+	currentValue, restValue := reflect.ValueOf(*current), reflect.ValueOf(rest)
+	for i := 0; i < currentValue.NumField(); i++ {
+		if !reflect.DeepEqual(currentValue.Field(i).Interface(), restValue.Field(i).Interface()) {
+			restart = append(restart, currentValue.Type().Field(i).Name)
+		}
+	}
+
+	r.current.Store(&applied)
+	for _, fn := range r.listeners {
+		fn(&applied)
+	}
+	return restart, nil
+}
+
+// Watch reloads the configuration on SIGHUP until ctx is cancelled
+func (r *Reloader) Watch(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		}
+
+		restart, err := r.Reload()
+		if err != nil {
+			log.Printf("Failed to reload configuration, keeping the running one: %v", err)
+			continue
+		}
+		log.Println("Configuration reloaded")
+		if len(restart) > 0 {
+			log.Printf("Configuration changes to %v take effect after a restart", restart)
+		}
+	}
+}
+
+// applyReloadable copies the settings that are safe to change while the
+// server runs from src to dst
+func applyReloadable(dst, src *Config) {
+	dst.Server.LogLevel = src.Server.LogLevel
+	dst.Server.RateLimitPerMin = src.Server.RateLimitPerMin
+	dst.Server.RouteRateLimits = src.Server.RouteRateLimits
+	dst.Server.RequestTimeout = src.Server.RequestTimeout
+	dst.Server.RouteTimeouts = src.Server.RouteTimeouts
+	dst.Server.AllowedOrigins = src.Server.AllowedOrigins
+	dst.OPA.CacheTTL = src.OPA.CacheTTL
+	dst.OPA.MaxStale = src.OPA.MaxStale
+}
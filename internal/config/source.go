@@ -0,0 +1,254 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// source resolves settings by their environment variable names from the
+// environment and an optional YAML or TOML config file. Environment variables
+// override the file.
+//
+// File keys are the variable names in any case, and may be nested in sections
+// that prefix them: DB_HOST may be set as db_host or as host in a db section.
+// Lists of values are joined with commas, and sections and lists of sections
+// are passed on as JSON, so rate_limit.routes sets RATE_LIMIT_ROUTES.
+type source struct {
+	file   string
+	values map[string]any    // File values by setting name
+	keys   map[string]string // File key of each setting name, e.g. db.host
+	used   map[string]bool   // Setting names that were looked up
+	errs   []string
+}
+
+// newSource reads the config file at path, if any
+func newSource(path string) (*source, error) {
+	s := &source{
+		file:   path,
+		values: make(map[string]any),
+		keys:   make(map[string]string),
+		used:   make(map[string]bool),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var tree map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if err := s.flatten("", "", tree); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// flatten records each value and section of a file under its setting name
+func (s *source) flatten(name, key string, section map[string]any) error {
+	for k, value := range section {
+		childName := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		childKey := k
+		if name != "" {
+			childName = name + "_" + childName
+			childKey = key + "." + k
+		}
+		if previous, ok := s.keys[childName]; ok {
+			return fmt.Errorf("config file %s sets %s twice, as %s and %s", s.file, childName, previous, childKey)
+		}
+		s.values[childName] = value
+		s.keys[childName] = childKey
+
+		if child, ok := value.(map[string]any); ok {
+			if err := s.flatten(childName, childKey, child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a setting and where it was set, or ok false
+// when it is not set
+func (s *source) lookup(name string) (value, origin string, ok bool) {
+	s.used[name] = true
+	if value := os.Getenv(name); value != "" {
+		return value, name, true
+	}
+
+	raw, ok := s.values[name]
+	if !ok || raw == nil {
+		return "", "", false
+	}
+	origin = fmt.Sprintf("%s in %s", s.keys[name], s.file)
+	switch v := raw.(type) {
+	case string:
+		value = v
+	case map[string]any, []map[string]any:
+		value = s.json(v, origin)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return s.json(v, origin), origin, true
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		value = strings.Join(items, ",")
+	default:
+		value = fmt.Sprint(v)
+	}
+	return value, origin, value != ""
+}
+
+func (s *source) json(value any, origin string) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Sprintf("%s: %v", origin, err))
+		return ""
+	}
+	return string(encoded)
+}
+
+func (s *source) get(name, defaultValue string) string {
+	if value, _, ok := s.lookup(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (s *source) getInt(name string, defaultValue int) int {
+	value, origin, ok := s.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	intVal, err := strconv.Atoi(value)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Sprintf("%s must be a whole number, got %q", origin, value))
+		return defaultValue
+	}
+	return intVal
+}
+
+func (s *source) getBool(name string, defaultValue bool) bool {
+	value, origin, ok := s.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	boolVal, err := strconv.ParseBool(value)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Sprintf("%s must be true or false, got %q", origin, value))
+		return defaultValue
+	}
+	return boolVal
+}
+
+func (s *source) getSlice(name string, defaultValue []string) []string {
+	value, _, ok := s.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// err reports the invalid values and the file keys that name no setting
+func (s *source) err() error {
+	errs := s.errs
+	for name, key := range s.keys {
+		if s.known(name) {
+			continue
+		}
+		if _, section := s.values[name].(map[string]any); section {
+			// Reported by its unknown keys
+			continue
+		}
+		msg := fmt.Sprintf("unknown setting %s in %s", key, s.file)
+		if suggestion := s.suggest(name); suggestion != "" {
+			msg += fmt.Sprintf(" (did you mean %s?)", suggestion)
+		}
+		errs = append(errs, msg)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("invalid configuration:\n  %s", strings.Join(errs, "\n  "))
+}
+
+// known reports whether a file key names a setting, or a key within a
+// section passed on as JSON
+func (s *source) known(name string) bool {
+	if s.used[name] {
+		return true
+	}
+	key := s.keys[name]
+	for i := strings.LastIndex(key, "."); i >= 0; i = strings.LastIndex(key, ".") {
+		key = key[:i]
+		for section, sectionKey := range s.keys {
+			if sectionKey == key && s.used[section] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// suggest returns the setting name closest to an unknown one, if any is close
+func (s *source) suggest(name string) string {
+	best, bestDistance := "", 4
+	for used := range s.used {
+		if d := editDistance(name, used); d < bestDistance || (d == bestDistance && used < best) {
+			best, bestDistance = used, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
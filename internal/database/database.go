@@ -9,6 +9,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 // DB is the global database instance
@@ -24,8 +25,13 @@ func Connect(cfg *config.Config) error {
 		gormLogger = logger.Default.LogMode(logger.Error)
 	}
 
+	dialector, err := Dialector(cfg.Database.Driver, cfg.GetDatabaseDSN())
+	if err != nil {
+		return err
+	}
+
 	// Connect to database
-	DB, err = gorm.Open(postgres.Open(cfg.GetDatabaseDSN()), &gorm.Config{
+	DB, err = gorm.Open(dialector, &gorm.Config{
 		Logger: gormLogger,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
@@ -45,17 +51,52 @@ func Connect(cfg *config.Config) error {
 	// Set connection pool settings
 	sqlDB.SetMaxOpenConns(cfg.Database.MaxConns)
 	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdle)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if len(cfg.Database.ReplicaDSNs) > 0 {
+		if IsSQLite(DB) {
+			return fmt.Errorf("read replicas are not supported with the %s driver", DriverSQLite)
+		}
+
+		replicas := make([]gorm.Dialector, len(cfg.Database.ReplicaDSNs))
+		for i, dsn := range cfg.Database.ReplicaDSNs {
+			replicas[i] = postgres.Open(dsn)
+		}
+		primary, err := UseReplicas(DB, replicas, &cfg.Database)
+		if err != nil {
+			return err
+		}
+		DB = primary
+		log.Printf("Database read replicas configured: %d", len(replicas))
+	}
+
 	log.Println("Database connection established successfully")
 	return nil
 }
 
+// UseReplicas registers read replicas, with the primary's pool settings, and
+// returns a handle whose queries stay on the primary, as services read their
+// own writes. A read-only query opts in to the replicas with dbresolver.Read;
+// queries in transactions always run on the primary.
+func UseReplicas(db *gorm.DB, replicas []gorm.Dialector, cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxOpenConns(cfg.MaxConns).
+		SetMaxIdleConns(cfg.MaxIdle).
+		SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if err := db.Use(resolver); err != nil {
+		return nil, fmt.Errorf("failed to configure read replicas: %w", err)
+	}
+	return db.Clauses(dbresolver.Write).Session(&gorm.Session{}), nil
+}
+
 // Close closes the database connection
 func Close() error {
 	if DB == nil {
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite" // Lightweight mode for local development and tests
+)
+
+// sqliteDefaults are applied to SQLite DSNs that do not set them. Immediate
+// transactions take the write lock up front, standing in for the row locks
+// (SELECT ... FOR UPDATE) that SQLite does not support.
+var sqliteDefaults = []struct {
+	key   string
+	param string
+}{
+	{"foreign_keys", "_pragma=foreign_keys(1)"},
+	{"busy_timeout", "_pragma=busy_timeout(5000)"},
+	{"journal_mode", "_pragma=journal_mode(WAL)"},
+	{"_txlock", "_txlock=immediate"},
+}
+
+// Dialector returns the GORM dialector for a driver and DSN
+func Dialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case DriverPostgres, "":
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(sqliteDSN(dsn)), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// IsSQLite reports whether db runs on SQLite
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverSQLite
+}
+
+func sqliteDSN(dsn string) string {
+	var params []string
+	for _, d := range sqliteDefaults {
+		if !strings.Contains(dsn, d.key) {
+			params = append(params, d.param)
+		}
+	}
+	if len(params) == 0 {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + strings.Join(params, "&")
+}
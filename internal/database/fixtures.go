@@ -0,0 +1,333 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gopkg.in/yaml.v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+//go:embed fixtures/default.yaml
+var defaultFixture []byte
+
+// Fixture is a declarative dataset of permissions, tenants and their roles and
+// policies, e.g. the default data or a demo environment. Seeding it creates
+// the records that do not exist yet, matched by their natural keys, and leaves
+// existing ones as they are, so it can be applied repeatedly.
+type Fixture struct {
+	Permissions []PermissionFixture `yaml:"permissions"`
+	Tenants     []TenantFixture     `yaml:"tenants"`
+
+	dir string // Directory policy files are read from
+}
+
+// PermissionFixture is a permission, matched by name
+type PermissionFixture struct {
+	Name        string `yaml:"name"`
+	Resource    string `yaml:"resource"`
+	Action      string `yaml:"action"`
+	Scope       string `yaml:"scope"` // own, tenant or global, tenant when empty
+	Description string `yaml:"description"`
+	System      bool   `yaml:"system"`
+}
+
+// TenantFixture is a tenant, matched by slug, with its roles and policies
+type TenantFixture struct {
+	Slug     string                 `yaml:"slug"`
+	Name     string                 `yaml:"name"`
+	MaxUsers int                    `yaml:"maxUsers"`
+	MaxRoles int                    `yaml:"maxRoles"`
+	Settings map[string]interface{} `yaml:"settings"`
+	Roles    []RoleFixture          `yaml:"roles"`
+	Policies []PolicyFixture        `yaml:"policies"`
+}
+
+// RoleFixture is a role of a tenant, matched by name. Its permissions are
+// granted when missing, including to existing roles.
+type RoleFixture struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	System      bool     `yaml:"system"`
+	Permissions []string `yaml:"permissions"` // Permission names
+}
+
+// PolicyFixture is a policy of a tenant, matched by path. Its content is
+// inline or read from a file relative to the fixture file.
+type PolicyFixture struct {
+	Name        string `yaml:"name"`
+	Path        string `yaml:"path"`
+	Description string `yaml:"description"`
+	Status      string `yaml:"status"` // draft or active, draft when empty
+	Content     string `yaml:"content"`
+	File        string `yaml:"file"`
+}
+
+// SeedResult counts the records a seed created
+type SeedResult struct {
+	Permissions int
+	Tenants     int
+	Roles       int
+	Grants      int
+	Policies    int
+}
+
+// DefaultFixture returns the default data: the system permissions checked by
+// the bundled policies and the default tenant
+func DefaultFixture() (*Fixture, error) {
+	return ParseFixture(defaultFixture, "")
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	return ParseFixture(content, filepath.Dir(path))
+}
+
+// ParseFixture parses and validates a YAML fixture, reading policy files
+// relative to dir
+func ParseFixture(content []byte, dir string) (*Fixture, error) {
+	fixture := &Fixture{dir: dir}
+	if err := yaml.Unmarshal(content, fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture: %w", err)
+	}
+	if err := fixture.validate(); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+func (f *Fixture) validate() error {
+	for _, permission := range f.Permissions {
+		if permission.Name == "" || permission.Resource == "" || permission.Action == "" {
+			return fmt.Errorf("permissions require a name, resource and action")
+		}
+	}
+
+	slugs := map[string]bool{}
+	paths := map[string]bool{}
+	for _, tenant := range f.Tenants {
+		if tenant.Slug == "" || tenant.Name == "" {
+			return fmt.Errorf("tenants require a slug and name")
+		}
+		if slugs[tenant.Slug] {
+			return fmt.Errorf("tenant %s is declared twice", tenant.Slug)
+		}
+		slugs[tenant.Slug] = true
+
+		for _, role := range tenant.Roles {
+			if role.Name == "" {
+				return fmt.Errorf("roles of tenant %s require a name", tenant.Slug)
+			}
+		}
+		for _, policy := range tenant.Policies {
+			switch {
+			case policy.Name == "" || policy.Path == "":
+				return fmt.Errorf("policies of tenant %s require a name and path", tenant.Slug)
+			case (policy.Content == "") == (policy.File == ""):
+				return fmt.Errorf("policy %s requires either content or a file", policy.Path)
+			case policy.Status != "" && policy.Status != string(models.PolicyStatusDraft) && policy.Status != string(models.PolicyStatusActive):
+				return fmt.Errorf("policy %s has status %q, expected draft or active", policy.Path, policy.Status)
+			case paths[policy.Path]:
+				return fmt.Errorf("policy %s is declared twice", policy.Path)
+			}
+			paths[policy.Path] = true
+		}
+	}
+	return nil
+}
+
+// SeedFixture creates the records of a fixture that do not exist yet in one
+// transaction. Roles may be granted permissions of the fixture or ones that
+// already exist.
+func SeedFixture(ctx context.Context, db *gorm.DB, fixture *Fixture) (*SeedResult, error) {
+	result := &SeedResult{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, permission := range fixture.Permissions {
+			created, err := seedPermission(tx, permission)
+			if err != nil {
+				return err
+			}
+			if created {
+				result.Permissions++
+			}
+		}
+		for _, tenant := range fixture.Tenants {
+			if err := seedTenant(tx, fixture, tenant, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func seedPermission(tx *gorm.DB, fixture PermissionFixture) (bool, error) {
+	scope := fixture.Scope
+	if scope == "" {
+		scope = "tenant"
+	}
+	permission := models.Permission{
+		Name:        fixture.Name,
+		Resource:    fixture.Resource,
+		Action:      fixture.Action,
+		Scope:       scope,
+		Description: fixture.Description,
+		IsSystem:    fixture.System,
+	}
+	return firstOrCreate(tx, &permission, "name = ?", fixture.Name)
+}
+
+func seedTenant(tx *gorm.DB, fixture *Fixture, tenantFixture TenantFixture, result *SeedResult) error {
+	if tenantFixture.Settings == nil {
+		tenantFixture.Settings = map[string]interface{}{}
+	}
+	settings, err := json.Marshal(tenantFixture.Settings)
+	if err != nil {
+		return fmt.Errorf("invalid settings of tenant %s: %w", tenantFixture.Slug, err)
+	}
+	tenant := models.Tenant{
+		Name:     tenantFixture.Name,
+		Slug:     tenantFixture.Slug,
+		Status:   "active",
+		MaxUsers: tenantFixture.MaxUsers,
+		MaxRoles: tenantFixture.MaxRoles,
+		Settings: datatypes.JSON(settings),
+	}
+	if tenant.MaxUsers == 0 {
+		tenant.MaxUsers = 1000
+	}
+	if tenant.MaxRoles == 0 {
+		tenant.MaxRoles = 50
+	}
+	created, err := firstOrCreate(tx, &tenant, "slug = ?", tenantFixture.Slug)
+	if err != nil {
+		return err
+	}
+	if created {
+		result.Tenants++
+	}
+
+	for _, roleFixture := range tenantFixture.Roles {
+		role := models.Role{
+			TenantID:    tenant.ID,
+			Name:        roleFixture.Name,
+			Description: roleFixture.Description,
+			IsSystem:    roleFixture.System,
+		}
+		created, err := firstOrCreate(tx, &role, "tenant_id = ? AND name = ?", tenant.ID, roleFixture.Name)
+		if err != nil {
+			return err
+		}
+		if created {
+			result.Roles++
+		}
+		for _, name := range roleFixture.Permissions {
+			granted, err := seedGrant(tx, role, name)
+			if err != nil {
+				return err
+			}
+			if granted {
+				result.Grants++
+			}
+		}
+	}
+
+	for _, policyFixture := range tenantFixture.Policies {
+		content := policyFixture.Content
+		if policyFixture.File != "" {
+			file, err := os.ReadFile(filepath.Join(fixture.dir, policyFixture.File))
+			if err != nil {
+				return fmt.Errorf("failed to read policy %s: %w", policyFixture.Path, err)
+			}
+			content = string(file)
+		}
+		status := models.PolicyStatus(policyFixture.Status)
+		if status == "" {
+			status = models.PolicyStatusDraft
+		}
+		policy := models.Policy{
+			TenantID:    tenant.ID,
+			Name:        policyFixture.Name,
+			Description: policyFixture.Description,
+			Version:     1,
+			Path:        policyFixture.Path,
+			Type:        models.PolicyTypeRego,
+			Content:     content,
+			Status:      status,
+		}
+		created, err := firstOrCreate(tx, &policy, "path = ?", policyFixture.Path)
+		if err != nil {
+			return err
+		}
+		if created {
+			result.Policies++
+		} else if policy.TenantID != tenant.ID {
+			return fmt.Errorf("policy %s belongs to another tenant", policyFixture.Path)
+		}
+	}
+	return nil
+}
+
+// seedGrant grants a role a permission it does not have yet
+func seedGrant(tx *gorm.DB, role models.Role, name string) (bool, error) {
+	var permission models.Permission
+	if err := tx.Where("name = ?", name).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, fmt.Errorf("role %s grants unknown permission %s", role.Name, name)
+		}
+		return false, fmt.Errorf("failed to get permission %s: %w", name, err)
+	}
+	grant := models.RolePermission{RoleID: role.ID, PermissionID: permission.ID, GrantedBy: uuid.Nil}
+	return firstOrCreate(tx, &grant, "role_id = ? AND permission_id = ?", role.ID, permission.ID)
+}
+
+// firstOrCreate loads the record matching the query into record, or creates
+// record when there is none, reporting whether it was created
+func firstOrCreate(tx *gorm.DB, record interface{}, query string, args ...interface{}) (bool, error) {
+	err := tx.Where(query, args...).First(record).Error
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to look up %T: %w", record, err)
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return false, fmt.Errorf("failed to create %T: %w", record, err)
+	}
+	return true, nil
+}
+
+// SeedDefaults seeds the default fixture
+func SeedDefaults(db *gorm.DB) error {
+	fixture, err := DefaultFixture()
+	if err != nil {
+		return err
+	}
+	return Seed(db, fixture)
+}
+
+// Seed seeds a fixture, logging the records it created
+func Seed(db *gorm.DB, fixture *Fixture) error {
+	result, err := SeedFixture(context.Background(), db, fixture)
+	if err != nil {
+		return fmt.Errorf("failed to seed: %w", err)
+	}
+	log.Printf("Seeded %d permissions, %d tenants, %d roles, %d role permissions and %d policies", result.Permissions, result.Tenants, result.Roles, result.Grants, result.Policies)
+	return nil
+}
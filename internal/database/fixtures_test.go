@@ -0,0 +1,123 @@
+package database_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+const demoFixture = `
+permissions:
+  - {name: reports.read, resource: reports, action: read, description: "Read reports"}
+tenants:
+  - slug: demo
+    name: Demo
+    roles:
+      - name: analyst
+        permissions: [reports.read, users.read]
+    policies:
+      - name: Demo
+        path: heimdall/demo
+        file: demo.rego
+`
+
+func TestSeedFixture(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := context.Background()
+
+		defaults, err := database.DefaultFixture()
+		if err != nil {
+			t.Fatalf("DefaultFixture returned error: %v", err)
+		}
+		result, err := database.SeedFixture(ctx, db, defaults)
+		if err != nil {
+			t.Fatalf("SeedFixture returned error: %v", err)
+		}
+		if result.Permissions != len(defaults.Permissions) || result.Tenants != 1 {
+			t.Errorf("Expected %d permissions and the default tenant, got %+v", len(defaults.Permissions), result)
+		}
+
+		dir := t.TempDir()
+		rego := "package heimdall.demo\n\ndefault allow := false\n"
+		if err := os.WriteFile(filepath.Join(dir, "demo.rego"), []byte(rego), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "demo.yaml")
+		if err := os.WriteFile(path, []byte(demoFixture), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		demo, err := database.LoadFixture(path)
+		if err != nil {
+			t.Fatalf("LoadFixture returned error: %v", err)
+		}
+		result, err = database.SeedFixture(ctx, db, demo)
+		if err != nil {
+			t.Fatalf("SeedFixture returned error: %v", err)
+		}
+		want := database.SeedResult{Permissions: 1, Tenants: 1, Roles: 1, Grants: 2, Policies: 1}
+		if *result != want {
+			t.Errorf("Expected %+v, got %+v", want, *result)
+		}
+
+		var policy models.Policy
+		if err := db.Where("path = ?", "heimdall/demo").First(&policy).Error; err != nil {
+			t.Fatalf("Expected the demo policy: %v", err)
+		}
+		if policy.Content != rego || policy.Status != models.PolicyStatusDraft {
+			t.Errorf("Expected a draft policy with the file's content, got %q (%s)", policy.Content, policy.Status)
+		}
+
+		// Seeding again creates nothing
+		result, err = database.SeedFixture(ctx, db, demo)
+		if err != nil {
+			t.Fatalf("SeedFixture returned error: %v", err)
+		}
+		if *result != (database.SeedResult{}) {
+			t.Errorf("Expected nothing to be created again, got %+v", *result)
+		}
+
+		// Grants added to the fixture are given to the existing role
+		demo.Tenants[0].Roles[0].Permissions = append(demo.Tenants[0].Roles[0].Permissions, "roles.read")
+		result, err = database.SeedFixture(ctx, db, demo)
+		if err != nil {
+			t.Fatalf("SeedFixture returned error: %v", err)
+		}
+		if *result != (database.SeedResult{Grants: 1}) {
+			t.Errorf("Expected one new grant, got %+v", *result)
+		}
+
+		// Unknown permissions roll the whole seed back
+		demo.Tenants[0].Roles = append(demo.Tenants[0].Roles, database.RoleFixture{Name: "auditor", Permissions: []string{"reports.export"}})
+		if _, err := database.SeedFixture(ctx, db, demo); err == nil {
+			t.Error("Expected an error for an unknown permission")
+		}
+		var count int64
+		db.Model(&models.Role{}).Where("name = ?", "auditor").Count(&count)
+		if count != 0 {
+			t.Errorf("Expected the failed seed to be rolled back, found %d auditor roles", count)
+		}
+	})
+}
+
+func TestParseFixtureRejectsInvalidFixtures(t *testing.T) {
+	tests := map[string]string{
+		"permission without action": `permissions: [{name: a.b, resource: a}]`,
+		"tenant without slug":       `tenants: [{name: Demo}]`,
+		"duplicate tenant":          `tenants: [{slug: demo, name: Demo}, {slug: demo, name: Demo}]`,
+		"policy without content":    `tenants: [{slug: demo, name: Demo, policies: [{name: P, path: p}]}]`,
+		"policy with both":          `tenants: [{slug: demo, name: Demo, policies: [{name: P, path: p, content: x, file: p.rego}]}]`,
+		"unknown policy status":     `tenants: [{slug: demo, name: Demo, policies: [{name: P, path: p, content: x, status: archived}]}]`,
+	}
+	for name, content := range tests {
+		if _, err := database.ParseFixture([]byte(content), ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
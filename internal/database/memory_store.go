@@ -0,0 +1,264 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often expired keys are swept from a MemoryStore
+const memorySweepInterval = time.Minute
+
+// MemoryStore is the in-process Store used when Redis is unavailable. It keeps
+// single-node deployments and local development working, but its contents are
+// lost on restart and not shared between replicas.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	value     string
+	expiresAt time.Time // Zero for keys that never expire
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Set stores a value with expiration, where zero means no expiration
+func (s *MemoryStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, formatValue(value), expiration)
+	return nil
+}
+
+// SetNX stores a value with expiration unless the key exists, and reports whether it was stored
+func (s *MemoryStore) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.set(key, formatValue(value), expiration)
+	return true, nil
+}
+
+// Get retrieves a value, or ErrKeyNotFound
+func (s *MemoryStore) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.get(key)
+	if !ok {
+		return "", ErrKeyNotFound
+	}
+	return entry.value, nil
+}
+
+// Del deletes keys
+func (s *MemoryStore) Del(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// Exists counts the keys that exist
+func (s *MemoryStore) Exists(ctx context.Context, keys ...string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, key := range keys {
+		if _, ok := s.get(key); ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Incr increments a counter, starting its expiration on the first increment
+func (s *MemoryStore) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.get(key)
+	if !ok {
+		s.set(key, "1", expiration)
+		return 1, nil
+	}
+
+	count, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value of %s is not an integer", key)
+	}
+	entry.value = strconv.FormatInt(count+1, 10)
+	s.entries[key] = entry
+	return count + 1, nil
+}
+
+// TTL returns the remaining lifetime of a key, or zero if it does not exist or never expires
+func (s *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.get(key)
+	if !ok || entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return entry.expiresAt.Sub(s.now()), nil
+}
+
+// Keys returns the keys matching a glob pattern, where * matches any run of
+// characters and ? any single character
+func (s *MemoryStore) Keys(ctx context.Context, pattern string) ([]string, error) {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	matcher, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid key pattern %q: %w", pattern, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []string
+	for key := range s.entries {
+		if _, ok := s.get(key); ok && matcher.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// AllowRateLimit counts a request against a limit per sliding window, like the
+// Redis implementation but with this process's clock
+func (s *MemoryStore) AllowRateLimit(ctx context.Context, key string, limit int64, window time.Duration) (*RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	windowMs := window.Milliseconds()
+	nowMs := s.now().UnixMilli()
+	index := nowMs / windowMs
+	elapsed := nowMs - index*windowMs
+	currentKey := fmt.Sprintf("%s:%d", key, index)
+	current := s.counter(currentKey)
+	previous := s.counter(fmt.Sprintf("%s:%d", key, index-1))
+
+	count := float64(previous)*float64(windowMs-elapsed)/float64(windowMs) + float64(current)
+	if count+1 > float64(limit) {
+		var wait float64
+		if current+1 > limit {
+			// Wait for the next window, then for the current one to slide out enough
+			wait = float64(windowMs-elapsed) + math.Ceil(float64(windowMs)*(1-float64(limit-1)/float64(current)))
+		} else {
+			// Wait for the previous window to slide out enough
+			wait = math.Ceil(float64(windowMs)*(1-float64(limit-1-current)/float64(previous))) - float64(elapsed)
+		}
+		return &RateLimitResult{
+			Allowed:    false,
+			RetryAfter: time.Duration(math.Max(wait, 1)) * time.Millisecond,
+		}, nil
+	}
+
+	s.set(currentKey, strconv.FormatInt(current+1, 10), 2*window)
+	return &RateLimitResult{
+		Allowed:   true,
+		Remaining: int64(math.Floor(float64(limit) - count - 1)),
+	}, nil
+}
+
+// Ping always succeeds, as the store is in-process
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close discards the store's contents
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]memoryEntry)
+	return nil
+}
+
+// get returns a key's entry unless it is missing or expired
+func (s *MemoryStore) get(key string) (memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+// set stores a value and sweeps expired keys now and then, so keys that are
+// never read again do not accumulate
+func (s *MemoryStore) set(key, value string, expiration time.Duration) {
+	now := s.now()
+	entry := memoryEntry{value: value}
+	if expiration > 0 {
+		entry.expiresAt = now.Add(expiration)
+	}
+	s.entries[key] = entry
+
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// counter returns the integer value of a key, or zero
+func (s *MemoryStore) counter(key string) int64 {
+	entry, ok := s.get(key)
+	if !ok {
+		return 0
+	}
+	count, _ := strconv.ParseInt(entry.value, 10, 64)
+	return count
+}
+
+// formatValue renders a value the way Redis stores it
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(v)
+	}
+}
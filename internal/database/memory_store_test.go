@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestMemoryClient returns a client on an in-process store with a clock
+// the test controls
+func newTestMemoryClient() (*RedisClient, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return &RedisClient{store: store, backend: StoreBackendMemory}, &now
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	client, now := newTestMemoryClient()
+
+	if !client.Degraded() || client.Backend() != StoreBackendMemory {
+		t.Errorf("Expected the memory backend to be reported as degraded")
+	}
+
+	// Values expire
+	if err := client.Set(ctx, "greeting", "hello", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, err := client.Get(ctx, "greeting"); err != nil || value != "hello" {
+		t.Errorf("Expected hello, got %q, %v", value, err)
+	}
+	if stored, _ := client.SetNX(ctx, "greeting", "bye", time.Minute); stored {
+		t.Error("Expected SetNX not to overwrite an existing key")
+	}
+	*now = now.Add(time.Minute)
+	if _, err := client.Get(ctx, "greeting"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound for an expired key, got %v", err)
+	}
+	if stored, _ := client.SetNX(ctx, "greeting", "bye", time.Minute); !stored {
+		t.Error("Expected SetNX to store an expired key")
+	}
+
+	// Token storage and revocation
+	client.StoreRefreshToken(ctx, "u1", "t1", "fp1", time.Hour)
+	client.StoreRefreshToken(ctx, "u1", "t2", "fp1", time.Hour)
+	client.StoreRefreshToken(ctx, "u2", "t3", "fp2", time.Hour)
+	if valid, fingerprint, _ := client.ValidateRefreshToken(ctx, "u1", "t1"); !valid || fingerprint != "fp1" {
+		t.Errorf("Expected the refresh token to be valid and bound to fp1, got %v %q", valid, fingerprint)
+	}
+	if err := client.RevokeAllUserTokens(ctx, "u1"); err != nil {
+		t.Fatalf("RevokeAllUserTokens failed: %v", err)
+	}
+	if valid, _, _ := client.ValidateRefreshToken(ctx, "u1", "t2"); valid {
+		t.Error("Expected the user's refresh tokens to be revoked")
+	}
+	if valid, _, _ := client.ValidateRefreshToken(ctx, "u2", "t3"); !valid {
+		t.Error("Expected other users' refresh tokens to be kept")
+	}
+
+	client.BlacklistToken(ctx, "jti", time.Minute)
+	if blacklisted, _ := client.IsTokenBlacklisted(ctx, "jti"); !blacklisted {
+		t.Error("Expected the token to be blacklisted")
+	}
+	revokedAt := now.Add(-time.Second)
+	client.RevokeUserSessions(ctx, "u1", revokedAt, time.Hour)
+	if at, err := client.UserSessionsRevokedAt(ctx, "u1"); err != nil || !at.Equal(revokedAt) {
+		t.Errorf("Expected sessions revoked at %v, got %v, %v", revokedAt, at, err)
+	}
+	if at, err := client.UserSessionsRevokedAt(ctx, "u2"); err != nil || !at.IsZero() {
+		t.Errorf("Expected no revocation, got %v, %v", at, err)
+	}
+
+	// Login protection
+	for i := int64(1); i <= 3; i++ {
+		if count, err := client.IncrementLoginFailures(ctx, "a@example.com", time.Minute); err != nil || count != i {
+			t.Fatalf("Expected %d failures, got %d, %v", i, count, err)
+		}
+	}
+	client.LockLogin(ctx, "a@example.com", 15*time.Minute)
+	if ttl, _ := client.GetLoginLockTTL(ctx, "a@example.com"); ttl != 15*time.Minute {
+		t.Errorf("Expected a 15 minute lock, got %v", ttl)
+	}
+	*now = now.Add(time.Minute)
+	if count, _ := client.IncrementLoginFailures(ctx, "a@example.com", time.Minute); count != 1 {
+		t.Errorf("Expected the failure window to restart, got %d", count)
+	}
+
+	// Patterns
+	client.Set(ctx, "opa:decision:u1:a", "1", 0)
+	client.Set(ctx, "opa:decision:u1:b/c", "1", 0)
+	client.Set(ctx, "opa:decision:u2:a", "1", 0)
+	if err := client.DeletePattern(ctx, "opa:decision:u1:*"); err != nil {
+		t.Fatalf("DeletePattern failed: %v", err)
+	}
+	keys, _ := client.store.Keys(ctx, "opa:decision:*")
+	sort.Strings(keys)
+	if len(keys) != 1 || keys[0] != "opa:decision:u2:a" {
+		t.Errorf("Expected only u2's decision to remain, got %v", keys)
+	}
+}
+
+func TestMemoryStore_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	client, now := newTestMemoryClient()
+
+	for i := 0; i < 3; i++ {
+		result, err := client.AllowRateLimit(ctx, "ip:1.2.3.4", 3, time.Minute)
+		if err != nil || !result.Allowed || result.Remaining != int64(2-i) {
+			t.Fatalf("Expected request %d to be allowed, got %+v, %v", i+1, result, err)
+		}
+	}
+	result, _ := client.AllowRateLimit(ctx, "ip:1.2.3.4", 3, time.Minute)
+	if result.Allowed || result.RetryAfter <= 0 {
+		t.Fatalf("Expected the fourth request to be rejected with a retry delay, got %+v", result)
+	}
+	if other, _ := client.AllowRateLimit(ctx, "ip:5.6.7.8", 3, time.Minute); !other.Allowed {
+		t.Error("Expected other keys to have their own limit")
+	}
+
+	*now = now.Add(result.RetryAfter)
+	if result, _ := client.AllowRateLimit(ctx, "ip:1.2.3.4", 3, time.Minute); !result.Allowed {
+		t.Errorf("Expected a request to be allowed after the retry delay, got %+v", result)
+	}
+}
@@ -1,136 +1,70 @@
 package database
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 
-	"github.com/techsavvyash/heimdall/internal/models"
 	"gorm.io/gorm"
 )
 
-// RunMigrations runs GORM AutoMigrate on all models
+// RunMigrations applies all pending versioned migrations
 func RunMigrations(db *gorm.DB) error {
-	log.Println("Running database migrations...")
-
-	// Enable UUID extension for PostgreSQL
-	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error; err != nil {
-		return fmt.Errorf("failed to create uuid extension: %w", err)
-	}
-
-	// Run AutoMigrate on all models
-	if err := models.AutoMigrate(db); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
 	}
-
-	log.Println("Database migrations completed successfully")
-	return nil
+	return migrator.apply(context.Background())
 }
 
-// SeedDefaultData seeds default data like system permissions
-func SeedDefaultData(db *gorm.DB) error {
-	log.Println("Seeding default data...")
-
-	// Check if permissions already exist
-	var count int64
-	db.Model(&models.Permission{}).Count(&count)
-	if count > 0 {
-		log.Println("Default data already exists, skipping seed")
-		return nil
+// MigrateOnStartup applies pending migrations at server startup when
+// autoMigrate is set, holding the migration lock so replicas starting together
+// take turns, and otherwise only warns about them. A failure to apply them is
+// returned, while a failure to check them is only logged.
+func MigrateOnStartup(db *gorm.DB, autoMigrate bool) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
 	}
+	return migrator.startup(context.Background(), autoMigrate)
+}
 
-	// Default system permissions
-	defaultPermissions := []models.Permission{
-		// User permissions
-		{Name: "users.create", Resource: "users", Action: "create", Scope: "tenant", IsSystem: true, Description: "Create users"},
-		{Name: "users.read", Resource: "users", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read user information"},
-		{Name: "users.update", Resource: "users", Action: "update", Scope: "tenant", IsSystem: true, Description: "Update users"},
-		{Name: "users.delete", Resource: "users", Action: "delete", Scope: "tenant", IsSystem: true, Description: "Delete users"},
-		{Name: "users.read.own", Resource: "users", Action: "read", Scope: "own", IsSystem: true, Description: "Read own user information"},
-		{Name: "users.update.own", Resource: "users", Action: "update", Scope: "own", IsSystem: true, Description: "Update own user information"},
-
-		// Role permissions
-		{Name: "roles.create", Resource: "roles", Action: "create", Scope: "tenant", IsSystem: true, Description: "Create roles"},
-		{Name: "roles.read", Resource: "roles", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read roles"},
-		{Name: "roles.update", Resource: "roles", Action: "update", Scope: "tenant", IsSystem: true, Description: "Update roles"},
-		{Name: "roles.delete", Resource: "roles", Action: "delete", Scope: "tenant", IsSystem: true, Description: "Delete roles"},
-
-		// Permission permissions
-		{Name: "permissions.read", Resource: "permissions", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read permissions"},
-		{Name: "permissions.assign", Resource: "permissions", Action: "assign", Scope: "tenant", IsSystem: true, Description: "Assign permissions to roles"},
-
-		// Tenant permissions
-		{Name: "tenants.read", Resource: "tenants", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read tenant information"},
-		{Name: "tenants.update", Resource: "tenants", Action: "update", Scope: "tenant", IsSystem: true, Description: "Update tenant information"},
-
-		// Audit log permissions
-		{Name: "audit.read", Resource: "audit", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read audit logs"},
-
-		// Policy permissions
-		{Name: "policies.create", Resource: "policies", Action: "create", Scope: "tenant", IsSystem: true, Description: "Create policies"},
-		{Name: "policies.read", Resource: "policies", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read policies"},
-		{Name: "policies.update", Resource: "policies", Action: "update", Scope: "tenant", IsSystem: true, Description: "Update policies"},
-		{Name: "policies.delete", Resource: "policies", Action: "delete", Scope: "tenant", IsSystem: true, Description: "Delete policies"},
-		{Name: "policies.publish", Resource: "policies", Action: "publish", Scope: "tenant", IsSystem: true, Description: "Publish policies"},
-		{Name: "policies.test", Resource: "policies", Action: "test", Scope: "tenant", IsSystem: true, Description: "Test policies"},
-
-		// Policy bundle permissions
-		{Name: "bundles.create", Resource: "bundles", Action: "create", Scope: "tenant", IsSystem: true, Description: "Create policy bundles"},
-		{Name: "bundles.read", Resource: "bundles", Action: "read", Scope: "tenant", IsSystem: true, Description: "Read policy bundles"},
-		{Name: "bundles.activate", Resource: "bundles", Action: "activate", Scope: "tenant", IsSystem: true, Description: "Activate policy bundles"},
-		{Name: "bundles.deploy", Resource: "bundles", Action: "deploy", Scope: "tenant", IsSystem: true, Description: "Deploy policy bundles"},
+func (m *Migrator) startup(ctx context.Context, autoMigrate bool) error {
+	if autoMigrate {
+		return m.apply(ctx)
 	}
-
-	// Create permissions in transaction
-	if err := db.Transaction(func(tx *gorm.DB) error {
-		for i := range defaultPermissions {
-			if err := tx.Create(&defaultPermissions[i]).Error; err != nil {
-				return fmt.Errorf("failed to create permission %s: %w", defaultPermissions[i].Name, err)
-			}
-		}
-		return nil
-	}); err != nil {
-		return fmt.Errorf("failed to seed permissions: %w", err)
+	if err := m.check(ctx); err != nil {
+		log.Printf("⚠️  Failed to check pending migrations: %v", err)
 	}
-
-	log.Printf("Successfully seeded %d default permissions", len(defaultPermissions))
 	return nil
 }
 
-// SeedDefaultTenant seeds a default tenant if none exists
-func SeedDefaultTenant(db *gorm.DB) error {
-	log.Println("Seeding default tenant...")
-
-	// Check if any tenants exist
-	var count int64
-	db.Model(&models.Tenant{}).Count(&count)
-	if count > 0 {
-		log.Println("Tenants already exist, skipping default tenant seed")
-		return nil
-	}
+func (m *Migrator) apply(ctx context.Context) error {
+	log.Println("Running database migrations...")
 
-	// Create default tenant
-	settingsMap := map[string]interface{}{
-		"description": "Default tenant for Heimdall",
-	}
-	settingsJSON, err := json.Marshal(settingsMap)
+	applied, err := m.Up(ctx, 0)
 	if err != nil {
-		return fmt.Errorf("failed to marshal settings: %w", err)
+		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	defaultTenant := models.Tenant{
-		Name:     "Default Tenant",
-		Slug:     "default",
-		Status:   "active",
-		MaxUsers: 1000,
-		MaxRoles: 50,
-		Settings: settingsJSON,
-	}
+	log.Printf("Database migrations completed successfully (%d applied)", applied)
+	return nil
+}
 
-	if err := db.Create(&defaultTenant).Error; err != nil {
-		return fmt.Errorf("failed to create default tenant: %w", err)
+// check logs a warning when the schema is dirty or behind the migrations
+// embedded in the binary, so a server started without running them points at
+// the cause of the errors that follow
+func (m *Migrator) check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the schema version: %w", err)
 	}
 
-	log.Printf("Successfully created default tenant with ID: %s", defaultTenant.ID.String())
+	switch {
+	case status.Dirty:
+		log.Printf("⚠️  %v", &DirtyError{Version: status.Version})
+	case len(status.Pending) > 0:
+		log.Printf("⚠️  %d migrations are pending (schema version %d): run migrate up or set DB_AUTO_MIGRATE=true", len(status.Pending), status.Version)
+	}
 	return nil
 }
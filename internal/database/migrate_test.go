@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// advisoryLocks records the calls of the PostgreSQL advisory lock functions,
+// which TestMigrateOnStartup defines in SQLite
+var advisoryLocks struct {
+	sync.Mutex
+	held  bool
+	calls []string
+}
+
+var registerAdvisoryLocks sync.Once
+
+func recordLockCall(call string) {
+	advisoryLocks.Lock()
+	defer advisoryLocks.Unlock()
+	advisoryLocks.calls = append(advisoryLocks.calls, call)
+}
+
+func TestMigrateOnStartup(t *testing.T) {
+	registerAdvisoryLocks.Do(func() {
+		lockFunction := func(name string, held bool) func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+			return func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+				if id, _ := args[0].(int64); id != int64(migrationLockID) {
+					t.Errorf("Expected the migration lock, got %v", args[0])
+				}
+				advisoryLocks.Lock()
+				advisoryLocks.held = held
+				advisoryLocks.Unlock()
+				recordLockCall(name)
+				return true, nil
+			}
+		}
+		sqlite.MustRegisterScalarFunction("pg_advisory_lock", 1, lockFunction("lock", true))
+		sqlite.MustRegisterScalarFunction("pg_advisory_unlock", 1, lockFunction("unlock", false))
+		sqlite.MustRegisterScalarFunction("startup_migration", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+			advisoryLocks.Lock()
+			held := advisoryLocks.held
+			advisoryLocks.Unlock()
+			if held {
+				recordLockCall("migrate")
+			} else {
+				recordLockCall("migrate without lock")
+			}
+			return nil, nil
+		})
+	})
+
+	// A migrator taking the lock as on PostgreSQL, running a migration that
+	// records whether the lock is held
+	newMigrator := func(t *testing.T) (*Migrator, *gorm.DB) {
+		dialector, err := Dialector(DriverSQLite, filepath.Join(t.TempDir(), "heimdall.db"))
+		if err != nil {
+			t.Fatalf("Dialector returned error: %v", err)
+		}
+		db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		sqlDB, _ := db.DB()
+		advisoryLocks.calls = nil
+		return &Migrator{db: sqlDB, migrations: []Migration{{Version: 1, Name: "startup", Up: "SELECT startup_migration()"}}}, db
+	}
+	ctx := context.Background()
+
+	t.Run("enabled", func(t *testing.T) {
+		migrator, _ := newMigrator(t)
+		if err := migrator.startup(ctx, true); err != nil {
+			t.Fatalf("startup returned error: %v", err)
+		}
+		if calls := advisoryLocks.calls; len(calls) != 3 || calls[0] != "lock" || calls[1] != "migrate" || calls[2] != "unlock" {
+			t.Errorf("Expected the migration to run under the advisory lock, got %v", calls)
+		}
+		if status, err := migrator.Status(ctx); err != nil || status.Version != 1 || len(status.Pending) != 0 {
+			t.Errorf("Expected the migration to be applied, got %+v, %v", status, err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		migrator, _ := newMigrator(t)
+		if err := migrator.startup(ctx, false); err != nil {
+			t.Fatalf("startup returned error: %v", err)
+		}
+		if calls := advisoryLocks.calls; len(calls) != 0 {
+			t.Errorf("Expected no migration or lock, got %v", calls)
+		}
+		if status, err := migrator.Status(ctx); err != nil || status.Version != 0 || len(status.Pending) != 1 {
+			t.Errorf("Expected the migration to stay pending, got %+v, %v", status, err)
+		}
+	})
+
+	// The server starts with the embedded migrations
+	t.Run("embedded", func(t *testing.T) {
+		_, db := newMigrator(t)
+		if err := MigrateOnStartup(db, false); err != nil || db.Migrator().HasTable("tenants") {
+			t.Fatalf("Expected no migrations when disabled, got %v", err)
+		}
+		if err := MigrateOnStartup(db, true); err != nil || !db.Migrator().HasTable("tenants") {
+			t.Fatalf("Expected the embedded migrations to be applied, got %v", err)
+		}
+	})
+}
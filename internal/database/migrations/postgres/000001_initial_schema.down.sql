@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS tenant_signing_keys;
+DROP TABLE IF EXISTS bundle_policies;
+DROP TABLE IF EXISTS bundle_deployments;
+DROP TABLE IF EXISTS policy_bundles;
+DROP TABLE IF EXISTS policy_versions;
+DROP TABLE IF EXISTS policies;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,269 @@
+-- Baseline schema. Matches the tables previously created by GORM AutoMigrate, so
+-- every statement is idempotent and existing databases can adopt versioned migrations.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    name varchar(255) NOT NULL,
+    slug varchar(255) NOT NULL,
+    fusion_auth_app_id uuid,
+    fusion_auth_tenant_id uuid,
+    settings jsonb,
+    max_users bigint DEFAULT 1000,
+    max_roles bigint DEFAULT 50,
+    status varchar(50) DEFAULT 'active',
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_slug ON tenants (slug);
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants (deleted_at);
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid NOT NULL,
+    tenant_id uuid NOT NULL,
+    email varchar(255) NOT NULL,
+    metadata jsonb,
+    last_login_at timestamptz,
+    login_count bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    name varchar(100) NOT NULL,
+    description text,
+    parent_role_id uuid,
+    is_system boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenants_roles FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_roles_parent_role FOREIGN KEY (parent_role_id) REFERENCES roles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_roles_tenant_id ON roles (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    name varchar(100) NOT NULL,
+    resource varchar(100) NOT NULL,
+    action varchar(50) NOT NULL,
+    description text,
+    scope varchar(50) DEFAULT 'tenant',
+    is_system boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_name ON permissions (name);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    role_id uuid NOT NULL,
+    assigned_by uuid,
+    assigned_at timestamptz DEFAULT now(),
+    expires_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_roles_user_id ON user_roles (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    role_id uuid NOT NULL,
+    permission_id uuid NOT NULL,
+    granted_by uuid,
+    granted_at timestamptz DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id)
+);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_role_id ON role_permissions (role_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions (permission_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    user_id uuid,
+    event_type varchar(100) NOT NULL,
+    action varchar(100) NOT NULL,
+    resource varchar(100),
+    resource_id uuid,
+    ip_address varchar(45),
+    user_agent text,
+    method varchar(10),
+    path varchar(500),
+    status varchar(50) NOT NULL,
+    status_code integer,
+    message text,
+    metadata jsonb,
+    duration bigint,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenants_audit_logs FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_users_audit_logs FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_event_type ON audit_logs (event_type);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS policies (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    name varchar(200) NOT NULL,
+    description text,
+    version bigint NOT NULL DEFAULT 1,
+    path varchar(500) NOT NULL,
+    type varchar(50) NOT NULL DEFAULT 'rego',
+    content text NOT NULL,
+    status varchar(50) NOT NULL DEFAULT 'draft',
+    is_system boolean DEFAULT false,
+    is_valid boolean DEFAULT false,
+    validation_error text,
+    validated_at timestamptz,
+    test_cases jsonb,
+    metadata jsonb,
+    tags jsonb,
+    published_at timestamptz,
+    published_by uuid,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    created_by uuid,
+    updated_by uuid,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policies_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_policies_tenant_id ON policies (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policies_path ON policies (path);
+CREATE INDEX IF NOT EXISTS idx_policies_deleted_at ON policies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS policy_versions (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    policy_id uuid NOT NULL,
+    version bigint NOT NULL,
+    content text NOT NULL,
+    change_note text,
+    created_at timestamptz,
+    created_by uuid,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policy_versions_policy FOREIGN KEY (policy_id) REFERENCES policies (id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_versions_policy_id ON policy_versions (policy_id);
+
+CREATE TABLE IF NOT EXISTS policy_bundles (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid,
+    name varchar(200) NOT NULL,
+    description text,
+    version varchar(100) NOT NULL,
+    status varchar(50) NOT NULL DEFAULT 'building',
+    is_global boolean DEFAULT false,
+    build_started_at timestamptz,
+    build_completed_at timestamptz,
+    build_error text,
+    build_log text,
+    storage_path varchar(500),
+    storage_bucket varchar(200),
+    size bigint,
+    checksum varchar(256),
+    activated_at timestamptz,
+    activated_by uuid,
+    deactivated_at timestamptz,
+    deactivated_by uuid,
+    manifest jsonb,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    created_by uuid,
+    updated_by uuid,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policy_bundles_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_bundles_tenant_id ON policy_bundles (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_policy_bundles_deleted_at ON policy_bundles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bundle_deployments (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    bundle_id uuid NOT NULL,
+    deployed_at timestamptz,
+    deployed_by uuid,
+    environment varchar(100),
+    status varchar(50) NOT NULL,
+    error_message text,
+    rolled_back_at timestamptz,
+    rolled_back_by uuid,
+    rollback_reason text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policy_bundles_deployments FOREIGN KEY (bundle_id) REFERENCES policy_bundles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_bundle_deployments_bundle_id ON bundle_deployments (bundle_id);
+
+CREATE TABLE IF NOT EXISTS bundle_policies (
+    bundle_id uuid NOT NULL,
+    policy_id uuid NOT NULL,
+    added_at timestamptz,
+    added_by uuid,
+    PRIMARY KEY (bundle_id, policy_id),
+    CONSTRAINT fk_bundle_policies_policy_bundle FOREIGN KEY (bundle_id) REFERENCES policy_bundles (id),
+    CONSTRAINT fk_bundle_policies_policy FOREIGN KEY (policy_id) REFERENCES policies (id)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_signing_keys (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    key_id varchar(100) NOT NULL,
+    algorithm varchar(20) NOT NULL DEFAULT 'RS256',
+    public_key_pem text NOT NULL,
+    private_key_pem text NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'active',
+    retired_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenant_signing_keys_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tenant_signing_keys_tenant_id ON tenant_signing_keys (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_signing_keys_key_id ON tenant_signing_keys (key_id);
+CREATE INDEX IF NOT EXISTS idx_tenant_signing_keys_status ON tenant_signing_keys (status);
+
+CREATE TABLE IF NOT EXISTS login_events (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    tenant_id uuid NOT NULL,
+    ip_address varchar(45),
+    user_agent text,
+    device_fingerprint varchar(64),
+    country varchar(2),
+    region varchar(100),
+    city varchar(100),
+    suspicious boolean DEFAULT false,
+    reasons jsonb,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_created ON login_events (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_events_tenant_id ON login_events (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_login_events_device_fingerprint ON login_events (device_fingerprint);
+CREATE INDEX IF NOT EXISTS idx_login_events_suspicious ON login_events (suspicious);
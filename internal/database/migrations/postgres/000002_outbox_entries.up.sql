@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS outbox_entries (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    operation varchar(50) NOT NULL,
+    aggregate_id uuid NOT NULL,
+    payload jsonb,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    attempts bigint DEFAULT 0,
+    last_error text,
+    next_attempt_at timestamptz,
+    processed_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_aggregate_id ON outbox_entries (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_status_next ON outbox_entries (status, next_attempt_at);
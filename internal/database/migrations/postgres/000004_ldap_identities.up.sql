@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS ldap_identities (
+    user_id uuid NOT NULL,
+    dn varchar(1024) NOT NULL,
+    synced_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ldap_identities_dn ON ldap_identities (dn);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tenant_claims_templates (
+    tenant_id uuid NOT NULL,
+    include_roles boolean DEFAULT true,
+    role_filter jsonb,
+    include_permissions boolean DEFAULT false,
+    tenant_metadata jsonb,
+    custom_claims jsonb,
+    max_token_size bigint DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (tenant_id)
+);
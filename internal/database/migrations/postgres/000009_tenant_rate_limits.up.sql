@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tenant_rate_limits (
+    tenant_id uuid NOT NULL,
+    limits jsonb,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (tenant_id)
+);
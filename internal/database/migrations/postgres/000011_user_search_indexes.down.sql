@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_tenant_last_login_at;
+DROP INDEX IF EXISTS idx_users_last_name_lower;
+DROP INDEX IF EXISTS idx_users_first_name_lower;
+DROP INDEX IF EXISTS idx_users_tenant_email_lower;
@@ -0,0 +1,15 @@
+CREATE INDEX IF NOT EXISTS idx_users_tenant_email_lower ON users (tenant_id, lower(email) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_first_name_lower ON users (lower(metadata->>'firstName') text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_last_name_lower ON users (lower(metadata->>'lastName') text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_last_login_at ON users (tenant_id, last_login_at);
+
+-- Fuzzy email search uses a trigram index when the pg_trgm extension can be
+-- installed, and falls back to scanning the tenant's users otherwise
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (lower(email) gin_trgm_ops);
+EXCEPTION WHEN OTHERS THEN
+    RAISE NOTICE 'Skipping the trigram index on users.email: %', SQLERRM;
+END
+$$;
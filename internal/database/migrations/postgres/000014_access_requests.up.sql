@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS access_requests (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    requester_id uuid NOT NULL,
+    role_id uuid NOT NULL,
+    justification text NOT NULL,
+    duration_minutes integer NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    decided_by uuid,
+    decided_at timestamptz,
+    decision_comment text,
+    granted_until timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_access_requests_tenant_status ON access_requests (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_access_requests_requester_id ON access_requests (requester_id);
@@ -0,0 +1,2 @@
+ALTER TABLE policy_bundles ADD COLUMN IF NOT EXISTS selector jsonb;
+ALTER TABLE policy_bundles ADD COLUMN IF NOT EXISTS auto_rebuild boolean NOT NULL DEFAULT false;
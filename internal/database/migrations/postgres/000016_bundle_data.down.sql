@@ -0,0 +1 @@
+ALTER TABLE policy_bundles DROP COLUMN IF EXISTS include_data;
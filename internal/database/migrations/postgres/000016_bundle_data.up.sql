@@ -0,0 +1 @@
+ALTER TABLE policy_bundles ADD COLUMN IF NOT EXISTS include_data boolean NOT NULL DEFAULT false;
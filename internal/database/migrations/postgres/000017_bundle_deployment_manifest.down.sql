@@ -0,0 +1,2 @@
+ALTER TABLE bundle_deployments DROP COLUMN IF EXISTS manifest;
+ALTER TABLE bundle_deployments DROP COLUMN IF EXISTS revision;
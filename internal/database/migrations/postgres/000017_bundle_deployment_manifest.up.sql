@@ -0,0 +1,2 @@
+ALTER TABLE bundle_deployments ADD COLUMN IF NOT EXISTS revision varchar(256);
+ALTER TABLE bundle_deployments ADD COLUMN IF NOT EXISTS manifest jsonb;
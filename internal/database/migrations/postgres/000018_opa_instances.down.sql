@@ -0,0 +1 @@
+DROP TABLE IF EXISTS opa_instances;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS opa_instances (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    instance_id varchar(200) NOT NULL,
+    version varchar(100),
+    labels jsonb,
+    client_id varchar(255),
+    ip_address varchar(45),
+    bundles jsonb,
+    plugins jsonb,
+    last_seen_at timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_opa_instances_tenant_instance_id ON opa_instances (tenant_id, instance_id);
+CREATE INDEX IF NOT EXISTS idx_opa_instances_last_seen_at ON opa_instances (last_seen_at);
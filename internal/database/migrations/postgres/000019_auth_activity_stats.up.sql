@@ -0,0 +1,16 @@
+ALTER TABLE login_events ADD COLUMN IF NOT EXISTS mfa boolean NOT NULL DEFAULT false;
+CREATE TABLE IF NOT EXISTS auth_activity_stats (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    day timestamptz NOT NULL,
+    active_users bigint NOT NULL DEFAULT 0,
+    weekly_active_users bigint NOT NULL DEFAULT 0,
+    mfa_users bigint NOT NULL DEFAULT 0,
+    logins bigint NOT NULL DEFAULT 0,
+    failed_logins bigint NOT NULL DEFAULT 0,
+    registrations bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_activity_stats_tenant_day ON auth_activity_stats (tenant_id, day);
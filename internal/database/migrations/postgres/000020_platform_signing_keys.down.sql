@@ -0,0 +1 @@
+DROP TABLE IF EXISTS platform_signing_keys;
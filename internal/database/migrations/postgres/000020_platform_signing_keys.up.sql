@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS platform_signing_keys (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    key_id varchar(100) NOT NULL,
+    algorithm varchar(20) NOT NULL DEFAULT 'RS256',
+    public_key_pem text NOT NULL,
+    private_key_pem text,
+    status varchar(20) NOT NULL DEFAULT 'active',
+    retired_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_platform_signing_keys_key_id ON platform_signing_keys (key_id);
+CREATE INDEX IF NOT EXISTS idx_platform_signing_keys_status ON platform_signing_keys (status);
@@ -0,0 +1,3 @@
+-- Fails while encrypted client secret hashes remain
+ALTER TABLE oauth_clients ALTER COLUMN previous_secret_hash TYPE varchar(64);
+ALTER TABLE oauth_clients ALTER COLUMN secret_hash TYPE varchar(64);
@@ -0,0 +1,3 @@
+-- Encrypted client secret hashes are longer than the hashes themselves
+ALTER TABLE oauth_clients ALTER COLUMN secret_hash TYPE text;
+ALTER TABLE oauth_clients ALTER COLUMN previous_secret_hash TYPE text;
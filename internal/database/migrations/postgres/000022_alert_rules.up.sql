@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    name varchar(255) NOT NULL,
+    type varchar(50) NOT NULL,
+    threshold double precision NOT NULL,
+    window_minutes bigint NOT NULL,
+    min_events bigint NOT NULL DEFAULT 0,
+    webhook_url text,
+    pager_duty_routing_key text,
+    severity varchar(20) NOT NULL DEFAULT 'warning',
+    enabled boolean NOT NULL DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant_id ON alert_rules (tenant_id);
+CREATE TABLE IF NOT EXISTS alerts (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    rule_id uuid NOT NULL,
+    subject varchar(255) NOT NULL DEFAULT '',
+    value double precision NOT NULL,
+    threshold double precision NOT NULL,
+    message text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_alerts_rule_subject_created ON alerts (rule_id, subject, created_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS action_nonces (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    action varchar(100) NOT NULL,
+    resource_id varchar(255) NOT NULL,
+    nonce_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_action_nonces_user_id ON action_nonces (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_action_nonces_nonce_hash ON action_nonces (nonce_hash);
@@ -0,0 +1,2 @@
+ALTER TABLE policy_bundles DROP COLUMN IF EXISTS build_job_id;
+DROP TABLE IF EXISTS jobs;
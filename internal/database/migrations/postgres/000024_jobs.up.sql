@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    tenant_id uuid NOT NULL,
+    type varchar(50) NOT NULL,
+    payload jsonb,
+    result jsonb,
+    created_by uuid,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    max_attempts bigint NOT NULL,
+    last_error text,
+    run_at timestamptz NOT NULL,
+    started_at timestamptz,
+    completed_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_id ON jobs (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at ON jobs (status, run_at);
+ALTER TABLE policy_bundles ADD COLUMN IF NOT EXISTS build_job_id uuid;
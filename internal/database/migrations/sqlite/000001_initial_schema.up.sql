@@ -0,0 +1,267 @@
+-- Baseline schema for SQLite. Mirrors the PostgreSQL baseline: UUIDs and JSON are
+-- stored as text and IDs are generated by the application.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id text NOT NULL,
+    name varchar(255) NOT NULL,
+    slug varchar(255) NOT NULL,
+    fusion_auth_app_id text,
+    fusion_auth_tenant_id text,
+    settings text,
+    max_users integer DEFAULT 1000,
+    max_roles integer DEFAULT 50,
+    status varchar(50) DEFAULT 'active',
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_slug ON tenants (slug);
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants (deleted_at);
+
+CREATE TABLE IF NOT EXISTS users (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    email varchar(255) NOT NULL,
+    metadata text,
+    last_login_at datetime,
+    login_count integer DEFAULT 0,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    name varchar(100) NOT NULL,
+    description text,
+    parent_role_id text,
+    is_system numeric DEFAULT false,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenants_roles FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_roles_parent_role FOREIGN KEY (parent_role_id) REFERENCES roles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_roles_tenant_id ON roles (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id text NOT NULL,
+    name varchar(100) NOT NULL,
+    resource varchar(100) NOT NULL,
+    action varchar(50) NOT NULL,
+    description text,
+    scope varchar(50) DEFAULT 'tenant',
+    is_system numeric DEFAULT false,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permissions_name ON permissions (name);
+CREATE INDEX IF NOT EXISTS idx_permissions_deleted_at ON permissions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    id text NOT NULL,
+    user_id text NOT NULL,
+    role_id text NOT NULL,
+    assigned_by text,
+    assigned_at datetime DEFAULT CURRENT_TIMESTAMP,
+    expires_at datetime,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_roles_user_id ON user_roles (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id text NOT NULL,
+    role_id text NOT NULL,
+    permission_id text NOT NULL,
+    granted_by text,
+    granted_at datetime DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id)
+);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_role_id ON role_permissions (role_id);
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions (permission_id);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    user_id text,
+    event_type varchar(100) NOT NULL,
+    action varchar(100) NOT NULL,
+    resource varchar(100),
+    resource_id text,
+    ip_address varchar(45),
+    user_agent text,
+    method varchar(10),
+    path varchar(500),
+    status varchar(50) NOT NULL,
+    status_code integer,
+    message text,
+    metadata text,
+    duration integer,
+    created_at datetime,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenants_audit_logs FOREIGN KEY (tenant_id) REFERENCES tenants (id),
+    CONSTRAINT fk_users_audit_logs FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_tenant_id ON audit_logs (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_event_type ON audit_logs (event_type);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE TABLE IF NOT EXISTS policies (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    name varchar(200) NOT NULL,
+    description text,
+    version integer NOT NULL DEFAULT 1,
+    path varchar(500) NOT NULL,
+    type varchar(50) NOT NULL DEFAULT 'rego',
+    content text NOT NULL,
+    status varchar(50) NOT NULL DEFAULT 'draft',
+    is_system numeric DEFAULT false,
+    is_valid numeric DEFAULT false,
+    validation_error text,
+    validated_at datetime,
+    test_cases text,
+    metadata text,
+    tags text,
+    published_at datetime,
+    published_by text,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    created_by text,
+    updated_by text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policies_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_policies_tenant_id ON policies (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policies_path ON policies (path);
+CREATE INDEX IF NOT EXISTS idx_policies_deleted_at ON policies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS policy_versions (
+    id text NOT NULL,
+    policy_id text NOT NULL,
+    version integer NOT NULL,
+    content text NOT NULL,
+    change_note text,
+    created_at datetime,
+    created_by text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policy_versions_policy FOREIGN KEY (policy_id) REFERENCES policies (id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_versions_policy_id ON policy_versions (policy_id);
+
+CREATE TABLE IF NOT EXISTS policy_bundles (
+    id text NOT NULL,
+    tenant_id text,
+    name varchar(200) NOT NULL,
+    description text,
+    version varchar(100) NOT NULL,
+    status varchar(50) NOT NULL DEFAULT 'building',
+    is_global numeric DEFAULT false,
+    build_started_at datetime,
+    build_completed_at datetime,
+    build_error text,
+    build_log text,
+    storage_path varchar(500),
+    storage_bucket varchar(200),
+    size integer,
+    checksum varchar(256),
+    activated_at datetime,
+    activated_by text,
+    deactivated_at datetime,
+    deactivated_by text,
+    manifest text,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    created_by text,
+    updated_by text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policy_bundles_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_policy_bundles_tenant_id ON policy_bundles (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_policy_bundles_deleted_at ON policy_bundles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bundle_deployments (
+    id text NOT NULL,
+    bundle_id text NOT NULL,
+    deployed_at datetime,
+    deployed_by text,
+    environment varchar(100),
+    status varchar(50) NOT NULL,
+    error_message text,
+    rolled_back_at datetime,
+    rolled_back_by text,
+    rollback_reason text,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_policy_bundles_deployments FOREIGN KEY (bundle_id) REFERENCES policy_bundles (id)
+);
+CREATE INDEX IF NOT EXISTS idx_bundle_deployments_bundle_id ON bundle_deployments (bundle_id);
+
+CREATE TABLE IF NOT EXISTS bundle_policies (
+    bundle_id text NOT NULL,
+    policy_id text NOT NULL,
+    added_at datetime,
+    added_by text,
+    PRIMARY KEY (bundle_id, policy_id),
+    CONSTRAINT fk_bundle_policies_policy_bundle FOREIGN KEY (bundle_id) REFERENCES policy_bundles (id),
+    CONSTRAINT fk_bundle_policies_policy FOREIGN KEY (policy_id) REFERENCES policies (id)
+);
+
+CREATE TABLE IF NOT EXISTS tenant_signing_keys (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    key_id varchar(100) NOT NULL,
+    algorithm varchar(20) NOT NULL DEFAULT 'RS256',
+    public_key_pem text NOT NULL,
+    private_key_pem text NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'active',
+    retired_at datetime,
+    revoked_at datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_tenant_signing_keys_tenant FOREIGN KEY (tenant_id) REFERENCES tenants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tenant_signing_keys_tenant_id ON tenant_signing_keys (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_signing_keys_key_id ON tenant_signing_keys (key_id);
+CREATE INDEX IF NOT EXISTS idx_tenant_signing_keys_status ON tenant_signing_keys (status);
+
+CREATE TABLE IF NOT EXISTS login_events (
+    id text NOT NULL,
+    user_id text NOT NULL,
+    tenant_id text NOT NULL,
+    ip_address varchar(45),
+    user_agent text,
+    device_fingerprint varchar(64),
+    country varchar(2),
+    region varchar(100),
+    city varchar(100),
+    suspicious numeric DEFAULT false,
+    reasons text,
+    created_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_created ON login_events (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_events_tenant_id ON login_events (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_login_events_device_fingerprint ON login_events (device_fingerprint);
+CREATE INDEX IF NOT EXISTS idx_login_events_suspicious ON login_events (suspicious);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS outbox_entries (
+    id text NOT NULL,
+    operation varchar(50) NOT NULL,
+    aggregate_id text NOT NULL,
+    payload text,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    attempts integer DEFAULT 0,
+    last_error text,
+    next_attempt_at datetime,
+    processed_at datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_aggregate_id ON outbox_entries (aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_entries_status_next ON outbox_entries (status, next_attempt_at);
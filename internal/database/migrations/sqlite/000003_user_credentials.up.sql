@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_credentials (
+    user_id text NOT NULL,
+    email varchar(255) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    first_name varchar(255),
+    last_name varchar(255),
+    active numeric DEFAULT true,
+    verified numeric DEFAULT false,
+    reset_token_hash varchar(64),
+    reset_token_expires_at datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_credentials_email ON user_credentials (email);
+CREATE INDEX IF NOT EXISTS idx_user_credentials_reset_token_hash ON user_credentials (reset_token_hash);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS ldap_identities (
+    user_id text NOT NULL,
+    dn varchar(1024) NOT NULL,
+    synced_at datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ldap_identities_dn ON ldap_identities (dn);
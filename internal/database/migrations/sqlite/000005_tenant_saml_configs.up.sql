@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tenant_saml_configs (
+    tenant_id text NOT NULL,
+    enabled numeric DEFAULT true,
+    idp_metadata text NOT NULL,
+    idp_entity_id varchar(1024),
+    email_attribute varchar(255),
+    first_name_attribute varchar(255),
+    last_name_attribute varchar(255),
+    role_attribute varchar(255),
+    role_mappings text,
+    allow_idp_initiated numeric DEFAULT false,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (tenant_id)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tenant_claims_templates (
+    tenant_id text NOT NULL,
+    include_roles numeric DEFAULT true,
+    role_filter text,
+    include_permissions numeric DEFAULT false,
+    tenant_metadata text,
+    custom_claims text,
+    max_token_size integer DEFAULT 0,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (tenant_id)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    client_id varchar(100) NOT NULL,
+    name varchar(255) NOT NULL,
+    secret_hash varchar(64) NOT NULL,
+    previous_secret_hash varchar(64),
+    previous_secret_expires_at datetime,
+    scopes text,
+    secret_rotated_at datetime,
+    last_used_at datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_oauth_clients_tenant_id ON oauth_clients (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_clients_client_id ON oauth_clients (client_id);
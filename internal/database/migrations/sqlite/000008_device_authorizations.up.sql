@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS device_authorizations (
+    id text NOT NULL,
+    device_code_hash varchar(64) NOT NULL,
+    user_code varchar(16) NOT NULL,
+    client_id varchar(100) NOT NULL,
+    status varchar(20) NOT NULL,
+    user_id text,
+    poll_interval integer NOT NULL,
+    last_polled_at datetime,
+    expires_at datetime NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_device_authorizations_device_code_hash ON device_authorizations (device_code_hash);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_device_authorizations_user_code ON device_authorizations (user_code);
+CREATE INDEX IF NOT EXISTS idx_device_authorizations_expires_at ON device_authorizations (expires_at);
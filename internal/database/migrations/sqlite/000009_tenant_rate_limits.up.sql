@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tenant_rate_limits (
+    tenant_id text NOT NULL,
+    limits text,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (tenant_id)
+);
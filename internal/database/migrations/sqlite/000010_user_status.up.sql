@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN status varchar(20) NOT NULL DEFAULT 'active';
+UPDATE users SET status = 'deactivated' WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
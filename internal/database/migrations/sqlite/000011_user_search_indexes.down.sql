@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_tenant_last_login_at;
+DROP INDEX IF EXISTS idx_users_tenant_email_lower;
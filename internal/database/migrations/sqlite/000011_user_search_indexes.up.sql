@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_users_tenant_email_lower ON users (tenant_id, lower(email));
+CREATE INDEX IF NOT EXISTS idx_users_tenant_last_login_at ON users (tenant_id, last_login_at);
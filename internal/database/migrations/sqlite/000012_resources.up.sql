@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS resources (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    type varchar(100) NOT NULL,
+    resource_id varchar(255) NOT NULL,
+    owner_id varchar(255),
+    labels text,
+    attributes text,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resources_tenant_type_resource_id ON resources (tenant_id, type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_resources_tenant_owner_id ON resources (tenant_id, owner_id);
@@ -0,0 +1,2 @@
+ALTER TABLE user_roles ADD COLUMN reason text;
+CREATE INDEX IF NOT EXISTS idx_user_roles_expires_at ON user_roles (expires_at);
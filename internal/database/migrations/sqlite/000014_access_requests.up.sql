@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS access_requests (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    requester_id text NOT NULL,
+    role_id text NOT NULL,
+    justification text NOT NULL,
+    duration_minutes integer NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    decided_by text,
+    decided_at datetime,
+    decision_comment text,
+    granted_until datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_access_requests_tenant_status ON access_requests (tenant_id, status);
+CREATE INDEX IF NOT EXISTS idx_access_requests_requester_id ON access_requests (requester_id);
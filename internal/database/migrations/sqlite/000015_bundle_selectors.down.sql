@@ -0,0 +1,2 @@
+ALTER TABLE policy_bundles DROP COLUMN auto_rebuild;
+ALTER TABLE policy_bundles DROP COLUMN selector;
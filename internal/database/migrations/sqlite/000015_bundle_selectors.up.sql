@@ -0,0 +1,2 @@
+ALTER TABLE policy_bundles ADD COLUMN selector text;
+ALTER TABLE policy_bundles ADD COLUMN auto_rebuild numeric NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+ALTER TABLE policy_bundles DROP COLUMN include_data;
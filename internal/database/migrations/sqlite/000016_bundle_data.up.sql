@@ -0,0 +1 @@
+ALTER TABLE policy_bundles ADD COLUMN include_data numeric NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+ALTER TABLE bundle_deployments DROP COLUMN manifest;
+ALTER TABLE bundle_deployments DROP COLUMN revision;
@@ -0,0 +1,2 @@
+ALTER TABLE bundle_deployments ADD COLUMN revision varchar(256);
+ALTER TABLE bundle_deployments ADD COLUMN manifest text;
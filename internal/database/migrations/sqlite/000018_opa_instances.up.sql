@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS opa_instances (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    instance_id varchar(200) NOT NULL,
+    version varchar(100),
+    labels text,
+    client_id varchar(255),
+    ip_address varchar(45),
+    bundles text,
+    plugins text,
+    last_seen_at datetime NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_opa_instances_tenant_instance_id ON opa_instances (tenant_id, instance_id);
+CREATE INDEX IF NOT EXISTS idx_opa_instances_last_seen_at ON opa_instances (last_seen_at);
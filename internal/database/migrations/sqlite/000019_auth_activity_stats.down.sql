@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auth_activity_stats;
+ALTER TABLE login_events DROP COLUMN mfa;
@@ -0,0 +1,16 @@
+ALTER TABLE login_events ADD COLUMN mfa numeric NOT NULL DEFAULT false;
+CREATE TABLE IF NOT EXISTS auth_activity_stats (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    day datetime NOT NULL,
+    active_users integer NOT NULL DEFAULT 0,
+    weekly_active_users integer NOT NULL DEFAULT 0,
+    mfa_users integer NOT NULL DEFAULT 0,
+    logins integer NOT NULL DEFAULT 0,
+    failed_logins integer NOT NULL DEFAULT 0,
+    registrations integer NOT NULL DEFAULT 0,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_activity_stats_tenant_day ON auth_activity_stats (tenant_id, day);
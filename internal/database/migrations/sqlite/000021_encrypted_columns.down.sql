@@ -0,0 +1,3 @@
+-- SQLite does not enforce varchar lengths, so encrypted client secret hashes
+-- fit the existing columns. Kept to match the PostgreSQL versions.
+SELECT 1;
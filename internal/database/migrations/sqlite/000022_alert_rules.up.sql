@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    name varchar(255) NOT NULL,
+    type varchar(50) NOT NULL,
+    threshold real NOT NULL,
+    window_minutes integer NOT NULL,
+    min_events integer NOT NULL DEFAULT 0,
+    webhook_url text,
+    pager_duty_routing_key text,
+    severity varchar(20) NOT NULL DEFAULT 'warning',
+    enabled numeric NOT NULL DEFAULT true,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant_id ON alert_rules (tenant_id);
+CREATE TABLE IF NOT EXISTS alerts (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    rule_id text NOT NULL,
+    subject varchar(255) NOT NULL DEFAULT '',
+    value real NOT NULL,
+    threshold real NOT NULL,
+    message text,
+    created_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_alerts_rule_subject_created ON alerts (rule_id, subject, created_at);
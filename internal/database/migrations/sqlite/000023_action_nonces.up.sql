@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS action_nonces (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    user_id text NOT NULL,
+    action varchar(100) NOT NULL,
+    resource_id varchar(255) NOT NULL,
+    nonce_hash varchar(64) NOT NULL,
+    expires_at datetime NOT NULL,
+    used_at datetime,
+    created_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_action_nonces_user_id ON action_nonces (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_action_nonces_nonce_hash ON action_nonces (nonce_hash);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id text NOT NULL,
+    tenant_id text NOT NULL,
+    type varchar(50) NOT NULL,
+    payload text,
+    result text,
+    created_by text,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    max_attempts integer NOT NULL,
+    last_error text,
+    run_at datetime NOT NULL,
+    started_at datetime,
+    completed_at datetime,
+    created_at datetime,
+    updated_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_id ON jobs (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_run_at ON jobs (status, run_at);
+ALTER TABLE policy_bundles ADD COLUMN build_job_id text;
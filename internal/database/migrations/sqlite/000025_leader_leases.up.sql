@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS leader_leases (
+    name varchar(100) NOT NULL,
+    holder varchar(255) NOT NULL,
+    expires_at datetime NOT NULL,
+    acquired_at datetime NOT NULL,
+    updated_at datetime,
+    PRIMARY KEY (name)
+);
@@ -0,0 +1 @@
+ALTER TABLE tenant_claims_templates DROP COLUMN audiences;
@@ -0,0 +1,361 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// MigrationsTable is the table recording the schema version. Its layout matches
+// golang-migrate, so the migrate CLI can be used against the same database.
+const MigrationsTable = "schema_migrations"
+
+// migrationLockID is the advisory lock key serializing concurrent migration runs
+const migrationLockID = 7_298_113_205_611_046_183
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change loaded from a pair of
+// <version>_<name>.up.sql and <version>_<name>.down.sql files
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes the schema version of a database
+type MigrationStatus struct {
+	Version uint // 0 when no migration has been applied
+	Dirty   bool // A migration failed part-way and the schema needs manual repair
+	Applied []Migration
+	Pending []Migration
+}
+
+// DirtyError is returned when the database was left in a dirty state by a failed migration
+type DirtyError struct {
+	Version uint
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("database is dirty at version %d: fix the schema manually, then run force %d (or force the previous version to retry it)", e.Version, e.Version)
+}
+
+// LoadMigrations reads the migration files of a directory, sorted by version
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[uint(version)]
+		if !exists {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrator applies and rolls back versioned migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	sqlite     bool
+}
+
+// NewMigrator creates a migrator for the migrations embedded in the binary,
+// using the migration files of the database's dialect
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	dir := "migrations/" + DriverPostgres
+	if IsSQLite(db) {
+		dir = "migrations/" + DriverSQLite
+	}
+	migrations, err := LoadMigrations(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: sqlDB, migrations: migrations, sqlite: IsSQLite(db)}, nil
+}
+
+// Status returns the current schema version and the applied and pending migrations
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{Version: version, Dirty: dirty}
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Up applies up to n pending migrations, or all of them when n <= 0, and returns
+// the number applied
+func (m *Migrator) Up(ctx context.Context, n int) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, version uint) error {
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if n > 0 && applied == n {
+				break
+			}
+
+			log.Printf("Applying migration %d_%s", migration.Version, migration.Name)
+			if err := runMigration(ctx, conn, migration.Up, migration.Version, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back up to n applied migrations, or all of them when n <= 0, and
+// returns the number rolled back
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	rolledBack := 0
+	err := m.withLock(ctx, func(conn *sql.Conn, version uint) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version > version {
+				continue
+			}
+			if n > 0 && rolledBack == n {
+				break
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be rolled back: it has no down file", migration.Version, migration.Name)
+			}
+
+			var previous uint
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			log.Printf("Rolling back migration %d_%s", migration.Version, migration.Name)
+			if err := runMigration(ctx, conn, migration.Down, migration.Version, previous); err != nil {
+				return fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			rolledBack++
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Force records version as the clean schema version without running any migration.
+// It is used to recover from a dirty state after repairing the schema by hand.
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && !m.hasVersion(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer m.unlock(conn)
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	return setVersion(ctx, conn, version, false)
+}
+
+func (m *Migrator) hasVersion(version uint) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// withLock runs fn on a dedicated connection holding the migration lock, after
+// checking that the schema is clean
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, version uint) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer m.unlock(conn)
+
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return &DirtyError{Version: version}
+	}
+	if version != 0 && !m.hasVersion(version) {
+		return fmt.Errorf("database is at version %d, which has no migration file in this build", version)
+	}
+
+	return fn(conn, version)
+}
+
+// runMigration marks the schema dirty at version, executes the migration and
+// records target as the clean version. Migration files may contain statements
+// that cannot run in a transaction, so a failure leaves the schema dirty.
+func runMigration(ctx context.Context, conn *sql.Conn, query string, version, target uint) error {
+	if err := setVersion(ctx, conn, version, true); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	return setVersion(ctx, conn, target, false)
+}
+
+// lock serializes migration runs across processes. SQLite databases are local
+// to one process, so they are not locked.
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	if m.sqlite {
+		return nil
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_lock(%d)", int64(migrationLockID))); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) unlock(conn *sql.Conn) {
+	if m.sqlite {
+		return
+	}
+	if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("SELECT pg_advisory_unlock(%d)", int64(migrationLockID))); err != nil {
+		log.Printf("Failed to release migration lock: %v", err)
+	}
+}
+
+func ensureMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+MigrationsTable+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", MigrationsTable, err)
+	}
+	return nil
+}
+
+func readVersion(ctx context.Context, conn *sql.Conn) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM "+MigrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// setVersion replaces the recorded schema version. Version 0 means no migration
+// is applied and is stored as an empty table.
+func setVersion(ctx context.Context, conn *sql.Conn, version uint, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+MigrationsTable); err != nil {
+		return fmt.Errorf("failed to update schema version: %w", err)
+	}
+	if version != 0 || dirty {
+		// Values are formatted into the statement as placeholder syntax differs between drivers
+		insert := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (%d, %t)", MigrationsTable, version, dirty)
+		if _, err := tx.ExecContext(ctx, insert); err != nil {
+			return fmt.Errorf("failed to update schema version: %w", err)
+		}
+	}
+	return tx.Commit()
+}
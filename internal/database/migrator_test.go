@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/000002_add_index.up.sql":        {Data: []byte("CREATE INDEX")},
+		"m/000010_add_column.up.sql":       {Data: []byte("ALTER TABLE")},
+		"m/000010_add_column.down.sql":     {Data: []byte("ALTER TABLE DROP")},
+		"m/000001_initial_schema.up.sql":   {Data: []byte("CREATE TABLE")},
+		"m/000001_initial_schema.down.sql": {Data: []byte("DROP TABLE")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("LoadMigrations returned error: %v", err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "initial_schema", Up: "CREATE TABLE", Down: "DROP TABLE"},
+		{Version: 2, Name: "add_index", Up: "CREATE INDEX"},
+		{Version: 10, Name: "add_column", Up: "ALTER TABLE", Down: "ALTER TABLE DROP"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("got %d migrations, want %d", len(migrations), len(want))
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":       {"m/create_tenants.sql": {Data: []byte("x")}},
+		"zero version":   {"m/000000_initial.up.sql": {Data: []byte("x")}},
+		"missing up":     {"m/000001_initial.down.sql": {Data: []byte("x")}},
+		"version reused": {"m/000001_a.up.sql": {Data: []byte("x")}, "m/000001_b.up.sql": {Data: []byte("y")}},
+	}
+	for name, fsys := range tests {
+		if _, err := LoadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	postgres, err := LoadMigrations(migrationFiles, "migrations/"+DriverPostgres)
+	if err != nil {
+		t.Fatalf("embedded postgres migrations are invalid: %v", err)
+	}
+	sqlite, err := LoadMigrations(migrationFiles, "migrations/"+DriverSQLite)
+	if err != nil {
+		t.Fatalf("embedded sqlite migrations are invalid: %v", err)
+	}
+
+	if len(postgres) == 0 || postgres[0].Version != 1 {
+		t.Fatal("expected the baseline migration to be version 1")
+	}
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("got %d postgres and %d sqlite migrations, want one per dialect", len(postgres), len(sqlite))
+	}
+	for i, migration := range postgres {
+		if sqlite[i].Version != migration.Version || sqlite[i].Name != migration.Name {
+			t.Errorf("sqlite migration %d_%s does not match postgres migration %d_%s",
+				sqlite[i].Version, sqlite[i].Name, migration.Version, migration.Name)
+		}
+		if migration.Down == "" || sqlite[i].Down == "" {
+			t.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestMigratorSQLite(t *testing.T) {
+	dialector, err := Dialector(DriverSQLite, filepath.Join(t.TempDir(), "heimdall.db"))
+	if err != nil {
+		t.Fatalf("Dialector returned error: %v", err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator returned error: %v", err)
+	}
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if applied != len(migrator.migrations) {
+		t.Errorf("Up applied %d migrations, want %d", applied, len(migrator.migrations))
+	}
+	if !db.Migrator().HasTable("tenants") {
+		t.Error("expected the tenants table to exist after Up")
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if status.Dirty || len(status.Pending) != 0 || status.Version != migrator.migrations[len(migrator.migrations)-1].Version {
+		t.Errorf("unexpected status after Up: %+v", status)
+	}
+
+	if applied, err := migrator.Up(ctx, 0); err != nil || applied != 0 {
+		t.Errorf("second Up = (%d, %v), want nothing to apply", applied, err)
+	}
+
+	if _, err := migrator.Down(ctx, 0); err != nil {
+		t.Fatalf("Down returned error: %v", err)
+	}
+	if db.Migrator().HasTable("tenants") {
+		t.Error("expected the tenants table to be dropped after Down")
+	}
+
+	// A failed migration leaves the schema dirty until it is forced
+	if err := db.Exec("INSERT INTO " + MigrationsTable + " (version, dirty) VALUES (1, true)").Error; err != nil {
+		t.Fatalf("failed to mark schema dirty: %v", err)
+	}
+	var dirtyErr *DirtyError
+	if _, err := migrator.Up(ctx, 0); !errors.As(err, &dirtyErr) || dirtyErr.Version != 1 {
+		t.Fatalf("Up on a dirty schema returned %v, want DirtyError at version 1", err)
+	}
+	if err := migrator.Force(ctx, 0); err != nil {
+		t.Fatalf("Force returned error: %v", err)
+	}
+	if _, err := migrator.Up(ctx, 1); err != nil {
+		t.Errorf("Up after Force returned error: %v", err)
+	}
+}
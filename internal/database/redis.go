@@ -3,17 +3,53 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/techsavvyash/heimdall/internal/config"
 )
 
-// RedisClient wraps the Redis client
+// Store backends
+const (
+	StoreBackendRedis  = "redis"
+	StoreBackendMemory = "memory"
+)
+
+// ErrKeyNotFound is returned by Get for keys that do not exist or have expired
+var ErrKeyNotFound = errors.New("key not found")
+
+// Store is the key-value store behind refresh tokens, token blacklists,
+// sessions, caches, rate limits and login protection. It is Redis when Redis is
+// available, or an in-process MemoryStore otherwise.
+type Store interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	// Get returns ErrKeyNotFound for missing keys
+	Get(ctx context.Context, key string) (string, error)
+	Del(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	// Incr increments a counter, starting its expiration on the first increment
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	// TTL returns the remaining lifetime of a key, or zero if it does not exist or never expires
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Keys returns the keys matching a glob pattern such as "refresh_token:*"
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	// AllowRateLimit counts a request against a limit per sliding window
+	AllowRateLimit(ctx context.Context, key string, limit int64, window time.Duration) (*RateLimitResult, error)
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// RedisClient provides token storage, caching, rate limiting and login
+// protection on top of a Store: Redis, or the in-process fallback when Redis is
+// unavailable
 type RedisClient struct {
-	client *redis.Client
+	store   Store
+	backend string
 }
 
 var redisClient *RedisClient
@@ -31,14 +67,27 @@ func ConnectRedis(cfg *config.Config) error {
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	redisClient = &RedisClient{client: client}
+	redisClient = &RedisClient{store: &redisStore{client: client}, backend: StoreBackendRedis}
 	log.Println("Redis connection established successfully")
 	return nil
 }
 
+// UseMemoryStore falls back to an in-process store when Redis is unavailable.
+// Tokens, blacklists, caches and rate limits then live in this process only,
+// which is correct for a single node but not shared between replicas.
+func UseMemoryStore() {
+	redisClient = NewMemoryClient()
+}
+
+// NewMemoryClient creates a client on a new in-process store
+func NewMemoryClient() *RedisClient {
+	return &RedisClient{store: NewMemoryStore(), backend: StoreBackendMemory}
+}
+
 // GetRedis returns the Redis client instance
 func GetRedis() *RedisClient {
 	return redisClient
@@ -47,29 +96,49 @@ func GetRedis() *RedisClient {
 // CloseRedis closes the Redis connection
 func CloseRedis() error {
 	if redisClient != nil {
-		return redisClient.client.Close()
+		return redisClient.store.Close()
 	}
 	return nil
 }
 
+// Backend returns the backend of the store: redis or memory
+func (r *RedisClient) Backend() string {
+	return r.backend
+}
+
+// Degraded reports whether the in-process fallback is used instead of Redis
+func (r *RedisClient) Degraded() bool {
+	return r.backend != StoreBackendRedis
+}
+
+// Ping checks that the store is reachable
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.store.Ping(ctx)
+}
+
 // Set stores a value with expiration
 func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return r.client.Set(ctx, key, value, expiration).Err()
+	return r.store.Set(ctx, key, value, expiration)
+}
+
+// SetNX stores a value with expiration unless the key exists, and reports whether it was stored
+func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.store.SetNX(ctx, key, value, expiration)
 }
 
 // Get retrieves a value
 func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
-	return r.client.Get(ctx, key).Result()
+	return r.store.Get(ctx, key)
 }
 
 // Del deletes a key
 func (r *RedisClient) Del(ctx context.Context, keys ...string) error {
-	return r.client.Del(ctx, keys...).Err()
+	return r.store.Del(ctx, keys...)
 }
 
 // Exists checks if a key exists
 func (r *RedisClient) Exists(ctx context.Context, keys ...string) (int64, error) {
-	return r.client.Exists(ctx, keys...).Result()
+	return r.store.Exists(ctx, keys...)
 }
 
 // SetJSON stores a JSON-encoded value
@@ -108,20 +177,30 @@ func (r *RedisClient) IsTokenBlacklisted(ctx context.Context, tokenID string) (b
 	return count > 0, nil
 }
 
-// StoreRefreshToken stores a refresh token
-func (r *RedisClient) StoreRefreshToken(ctx context.Context, userID, tokenID string, expiration time.Duration) error {
+// StoreRefreshToken stores a refresh token bound to the fingerprint of the
+// client it was issued to
+func (r *RedisClient) StoreRefreshToken(ctx context.Context, userID, tokenID, fingerprint string, expiration time.Duration) error {
 	key := fmt.Sprintf("refresh_token:%s:%s", userID, tokenID)
-	return r.Set(ctx, key, tokenID, expiration)
+	return r.Set(ctx, key, fingerprint, expiration)
 }
 
-// ValidateRefreshToken checks if a refresh token exists
-func (r *RedisClient) ValidateRefreshToken(ctx context.Context, userID, tokenID string) (bool, error) {
+// ValidateRefreshToken checks if a refresh token exists and returns the
+// fingerprint it is bound to. Tokens stored before they were bound return an
+// empty fingerprint.
+func (r *RedisClient) ValidateRefreshToken(ctx context.Context, userID, tokenID string) (bool, string, error) {
 	key := fmt.Sprintf("refresh_token:%s:%s", userID, tokenID)
-	count, err := r.Exists(ctx, key)
+	fingerprint, err := r.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return false, "", nil
+	}
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
-	return count > 0, nil
+	// Unbound tokens were stored with their own ID
+	if fingerprint == tokenID {
+		fingerprint = ""
+	}
+	return true, fingerprint, nil
 }
 
 // RevokeRefreshToken removes a refresh token
@@ -132,23 +211,32 @@ func (r *RedisClient) RevokeRefreshToken(ctx context.Context, userID, tokenID st
 
 // RevokeAllUserTokens revokes all refresh tokens for a user
 func (r *RedisClient) RevokeAllUserTokens(ctx context.Context, userID string) error {
-	pattern := fmt.Sprintf("refresh_token:%s:*", userID)
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
+	return r.DeletePattern(ctx, fmt.Sprintf("refresh_token:%s:*", userID))
+}
 
-	var keys []string
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
+// RevokeUserSessions rejects the user's access tokens issued up to revokedAt.
+// The expiration should outlast the access tokens issued before then.
+func (r *RedisClient) RevokeUserSessions(ctx context.Context, userID string, revokedAt time.Time, expiration time.Duration) error {
+	key := fmt.Sprintf("user:sessions_revoked:%s", userID)
+	return r.Set(ctx, key, revokedAt.Unix(), expiration)
+}
 
-	if err := iter.Err(); err != nil {
-		return fmt.Errorf("failed to scan keys: %w", err)
+// UserSessionsRevokedAt returns when the user's sessions were last revoked,
+// or the zero time if they were not
+func (r *RedisClient) UserSessionsRevokedAt(ctx context.Context, userID string) (time.Time, error) {
+	key := fmt.Sprintf("user:sessions_revoked:%s", userID)
+	value, err := r.Get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return time.Time{}, nil
 	}
-
-	if len(keys) > 0 {
-		return r.Del(ctx, keys...)
+	if err != nil {
+		return time.Time{}, err
 	}
-
-	return nil
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid session revocation time %q: %w", value, err)
+	}
+	return time.Unix(unix, 0), nil
 }
 
 // --- Session Management ---
@@ -198,45 +286,55 @@ func (r *RedisClient) InvalidateUserPermissions(ctx context.Context, userID stri
 
 // --- Rate Limiting ---
 
-// IncrementRateLimit increments the rate limit counter
-func (r *RedisClient) IncrementRateLimit(ctx context.Context, key string, window time.Duration) (int64, error) {
-	rateLimitKey := fmt.Sprintf("ratelimit:%s", key)
+// RateLimitResult is the outcome of counting a request against a rate limit
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int64
+	RetryAfter time.Duration // Until a request would be allowed, when this one was not
+}
 
-	// Increment counter
-	count, err := r.client.Incr(ctx, rateLimitKey).Result()
-	if err != nil {
-		return 0, err
+// AllowRateLimit counts a request against a limit per sliding window and
+// reports whether it is allowed
+func (r *RedisClient) AllowRateLimit(ctx context.Context, key string, limit int64, window time.Duration) (*RateLimitResult, error) {
+	if limit < 1 || window < time.Millisecond {
+		return nil, fmt.Errorf("invalid rate limit of %d requests per %s", limit, window)
 	}
+	return r.store.AllowRateLimit(ctx, fmt.Sprintf("ratelimit:%s", key), limit, window)
+}
 
-	// Set expiration on first increment
-	if count == 1 {
-		r.client.Expire(ctx, rateLimitKey, window)
-	}
+// --- Login Protection ---
 
-	return count, nil
+// IncrementLoginFailures increments the failed login counter for a key within a window
+func (r *RedisClient) IncrementLoginFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	// The window starts on the first failure
+	return r.store.Incr(ctx, fmt.Sprintf("login:failures:%s", key), window)
 }
 
-// GetRateLimitCount gets the current rate limit count
-func (r *RedisClient) GetRateLimitCount(ctx context.Context, key string) (int64, error) {
-	rateLimitKey := fmt.Sprintf("ratelimit:%s", key)
-	count, err := r.client.Get(ctx, rateLimitKey).Int64()
-	if err == redis.Nil {
-		return 0, nil
-	}
-	return count, err
+// ResetLoginFailures clears the failed login counter for a key
+func (r *RedisClient) ResetLoginFailures(ctx context.Context, key string) error {
+	return r.Del(ctx, fmt.Sprintf("login:failures:%s", key))
 }
 
-// DeletePattern deletes all keys matching a pattern
-func (r *RedisClient) DeletePattern(ctx context.Context, pattern string) error {
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
+// LockLogin blocks login attempts for a key for the given duration
+func (r *RedisClient) LockLogin(ctx context.Context, key string, duration time.Duration) error {
+	return r.Set(ctx, fmt.Sprintf("login:lock:%s", key), "1", duration)
+}
 
-	var keys []string
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
+// GetLoginLockTTL returns the remaining lock duration for a key, or zero if not locked
+func (r *RedisClient) GetLoginLockTTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.store.TTL(ctx, fmt.Sprintf("login:lock:%s", key))
+}
 
-	if err := iter.Err(); err != nil {
-		return fmt.Errorf("failed to scan keys: %w", err)
+// UnlockLogin removes a login lock for a key
+func (r *RedisClient) UnlockLogin(ctx context.Context, key string) error {
+	return r.Del(ctx, fmt.Sprintf("login:lock:%s", key))
+}
+
+// DeletePattern deletes all keys matching a pattern
+func (r *RedisClient) DeletePattern(ctx context.Context, pattern string) error {
+	keys, err := r.store.Keys(ctx, pattern)
+	if err != nil {
+		return err
 	}
 
 	if len(keys) > 0 {
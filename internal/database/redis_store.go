@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore is the Store backed by Redis, shared by all Heimdall replicas
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return s.client.Set(ctx, key, value, expiration).Err()
+}
+
+func (s *redisStore) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, value, expiration).Result()
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (string, error) {
+	value, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrKeyNotFound
+	}
+	return value, err
+}
+
+func (s *redisStore) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *redisStore) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return s.client.Exists(ctx, keys...).Result()
+}
+
+func (s *redisStore) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	count, err := s.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		s.client.Expire(ctx, key, expiration)
+	}
+	return count, nil
+}
+
+func (s *redisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Negative values mean the key does not exist or has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (s *redisStore) Keys(ctx context.Context, pattern string) ([]string, error) {
+	iter := s.client.Scan(ctx, 0, pattern, 0).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan keys: %w", err)
+	}
+	return keys, nil
+}
+
+// slidingWindowScript counts a request against a sliding window rate limit. The
+// count is approximated from the counters of the current and previous fixed
+// windows, weighting the previous one by how much of it still overlaps the
+// sliding window, so bursts at a window boundary cannot exceed the limit.
+// Redis's clock is used so all Heimdall replicas agree on the windows, and
+// rejected requests are not counted. Each counter is a hash of its window's
+// index and count, so the script only touches the keys it is passed, and the
+// latest counter becomes the previous one when a new window starts.
+//
+// KEYS[1]: latest window's counter, KEYS[2]: the one before it
+// ARGV[1]: limit, ARGV[2]: window in milliseconds
+// Returns {allowed, remaining, retry after in milliseconds}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local index = math.floor(now / window)
+local elapsed = now - index * window
+
+local function counter(key)
+	local fields = redis.call('HMGET', key, 'window', 'count')
+	return tonumber(fields[1]), tonumber(fields[2]) or 0
+end
+local latestIndex, latest = counter(KEYS[1])
+local olderIndex, older = counter(KEYS[2])
+
+local current, previous = 0, 0
+if latestIndex == index then
+	current = latest
+	if olderIndex == index - 1 then
+		previous = older
+	end
+elseif latestIndex == index - 1 then
+	previous = latest
+end
+
+local count = previous * (window - elapsed) / window + current
+if count + 1 > limit then
+	local wait
+	if current + 1 > limit then
+		-- Wait for the next window, then for the current one to slide out enough
+		wait = window - elapsed + math.ceil(window * (1 - (limit - 1) / current))
+	else
+		-- Wait for the previous window to slide out enough
+		wait = math.ceil(window * (1 - (limit - 1 - current) / previous)) - elapsed
+	end
+	return {0, 0, math.max(wait, 1)}
+end
+
+if latestIndex == index then
+	redis.call('HINCRBY', KEYS[1], 'count', 1)
+else
+	if latestIndex == index - 1 then
+		redis.call('RENAME', KEYS[1], KEYS[2])
+	else
+		redis.call('DEL', KEYS[2])
+	end
+	redis.call('HSET', KEYS[1], 'window', index, 'count', 1)
+end
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, math.floor(limit - count - 1), 0}
+`)
+
+// AllowRateLimit runs slidingWindowScript on the counters of a key, whose hash
+// tag keeps both in the same Redis Cluster slot
+func (s *redisStore) AllowRateLimit(ctx context.Context, key string, limit int64, window time.Duration) (*RateLimitResult, error) {
+	keys := []string{fmt.Sprintf("{%s}:latest", key), fmt.Sprintf("{%s}:previous", key)}
+	values, err := slidingWindowScript.Run(ctx, s.client, keys, limit, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return &RateLimitResult{
+		Allowed:    values[0] == 1,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+func (s *redisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisClient returns a client on an in-process Redis server with a
+// clock the test advances
+func newTestRedisClient(t *testing.T, now time.Time) (*RedisClient, func(time.Duration), *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	server.SetTime(now)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	advance := func(d time.Duration) {
+		now = now.Add(d)
+		server.SetTime(now)
+		server.FastForward(d)
+	}
+	return &RedisClient{store: &redisStore{client: client}, backend: StoreBackendRedis}, advance, server
+}
+
+func TestRedisStore_RateLimit(t *testing.T) {
+	ctx := context.Background()
+	// The last second of a minute window
+	start := time.Unix(1700000040, 0).Add(59 * time.Second)
+
+	memory, memoryNow := newTestMemoryClient()
+	*memoryNow = start
+	redisClient, advanceRedis, server := newTestRedisClient(t, start)
+	stores := map[string]struct {
+		client  *RedisClient
+		advance func(time.Duration)
+	}{
+		"redis":  {redisClient, advanceRedis},
+		"memory": {memory, func(d time.Duration) { *memoryNow = memoryNow.Add(d) }},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			allow := func(expected bool, remaining int64, retryAfter time.Duration) {
+				t.Helper()
+				result, err := store.client.AllowRateLimit(ctx, "ip:1.2.3.4", 10, time.Minute)
+				if err != nil {
+					t.Fatalf("AllowRateLimit returned error: %v", err)
+				}
+				if result.Allowed != expected || result.Remaining != remaining || result.RetryAfter != retryAfter {
+					t.Fatalf("Expected allowed %v, remaining %d, retry after %s, got %+v", expected, remaining, retryAfter, result)
+				}
+			}
+
+			for i := int64(0); i < 10; i++ {
+				allow(true, 9-i, 0)
+			}
+			// The next window starts in 1s, and the current one must then slide
+			// out to 9 requests, another 6s
+			allow(false, 0, 7*time.Second)
+
+			// A burst right after the boundary still counts the previous window
+			store.advance(time.Second)
+			allow(false, 0, 6*time.Second)
+
+			// After the retry delay 9 requests of the previous window remain
+			store.advance(6 * time.Second)
+			allow(true, 0, 0)
+			// With one request in the current window the previous one must slide
+			// out to 8
+			allow(false, 0, 6*time.Second)
+
+			// Windows without requests reset the count
+			store.advance(3 * time.Minute)
+			allow(true, 9, 0)
+		})
+	}
+
+	// Both counters of a key are hash tagged into one Redis Cluster slot
+	keys := server.Keys()
+	sort.Strings(keys)
+	if len(keys) != 1 || keys[0] != "{ratelimit:ip:1.2.3.4}:latest" {
+		t.Errorf("Expected only the latest counter after idle windows, got %v", keys)
+	}
+	advanceRedis(time.Minute)
+	redisClient.AllowRateLimit(ctx, "ip:1.2.3.4", 10, time.Minute)
+	keys = server.Keys()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "{ratelimit:ip:1.2.3.4}:latest" || keys[1] != "{ratelimit:ip:1.2.3.4}:previous" {
+		t.Errorf("Expected the latest and previous counters, got %v", keys)
+	}
+
+	for _, invalid := range []struct {
+		limit  int64
+		window time.Duration
+	}{{0, time.Minute}, {-1, time.Minute}, {10, 0}} {
+		if _, err := redisClient.AllowRateLimit(ctx, "ip:1.2.3.4", invalid.limit, invalid.window); err == nil {
+			t.Errorf("Expected %d requests per %s to be rejected", invalid.limit, invalid.window)
+		}
+	}
+}
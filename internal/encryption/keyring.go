@@ -0,0 +1,164 @@
+// Package encryption encrypts sensitive database columns at rest with
+// AES-256-GCM. Model fields tagged `gorm:"serializer:encrypted"` are encrypted
+// on write and decrypted on read with the keyring set by SetKeyring.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// prefix marks encrypted values: enc:v1:<key ID>:<base64 nonce and ciphertext>
+const prefix = "enc:v1:"
+
+// ErrUnknownKey is returned when a value was encrypted with a key that is not
+// in the keyring
+var ErrUnknownKey = errors.New("value is encrypted with an unknown key")
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Keyring holds the keys encrypting column values. The primary key encrypts,
+// the others decrypt values written before a key rotation.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from keys formatted as <id>:<base64 key>, the
+// first being the primary key. Keys are 32 random bytes, e.g. generated with
+// `openssl rand -base64 32`.
+func NewKeyring(keys []string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption keys")
+	}
+
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(keys))}
+	for i, spec := range keys {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok || !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("encryption key %d must be <id>:<base64 key> with an ID of letters, digits, - and _", i+1)
+		}
+		if _, exists := k.keys[id]; exists {
+			return nil, fmt.Errorf("encryption key ID %s is used twice", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %s must be 32 base64-encoded bytes", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %s: %w", id, err)
+		}
+		k.keys[id] = aead
+		if i == 0 {
+			k.primary = id
+		}
+	}
+	return k, nil
+}
+
+// FromConfig creates the keyring of the configured keys, or returns nil when
+// none are configured and columns are stored unencrypted
+func FromConfig(cfg *config.EncryptionConfig) (*Keyring, error) {
+	keys := cfg.Keys
+	if cfg.KeysFile != "" {
+		content, err := os.ReadFile(cfg.KeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption keys: %w", err)
+		}
+		for _, line := range strings.FieldsFunc(string(content), func(r rune) bool { return r == '\n' || r == ',' }) {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return NewKeyring(keys)
+}
+
+// PrimaryKeyID returns the ID of the key encrypting new values
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// Seal encrypts a value of a column with the primary key. The column, e.g.
+// users.metadata, is authenticated so values cannot be moved between columns.
+// Values of JSON columns are sealed as a JSON string.
+func (k *Keyring) Seal(column string, plaintext []byte, jsonColumn bool) (string, error) {
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(column))
+	value := prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed)
+	if jsonColumn {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+	return value, nil
+}
+
+// Open decrypts a stored value of a column and returns the ID of the key that
+// encrypted it. Unencrypted values, written before encryption was enabled, are
+// returned as they are with an empty key ID.
+func (k *Keyring) Open(column string, stored []byte, jsonColumn bool) ([]byte, string, error) {
+	value, ok := encryptedValue(stored, jsonColumn)
+	if !ok {
+		return stored, "", nil
+	}
+
+	keyID, encoded, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if k == nil {
+		return nil, keyID, fmt.Errorf("%w %s: no encryption keys are configured", ErrUnknownKey, keyID)
+	}
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, keyID, fmt.Errorf("%w %s", ErrUnknownKey, keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, keyID, fmt.Errorf("malformed encrypted value of %s", column)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(column))
+	if err != nil {
+		return nil, keyID, fmt.Errorf("failed to decrypt value of %s with key %s: %w", column, keyID, err)
+	}
+	return plaintext, keyID, nil
+}
+
+// encryptedValue returns the encrypted form of a stored value, or ok false
+// when it is not encrypted
+func encryptedValue(stored []byte, jsonColumn bool) (string, bool) {
+	if jsonColumn {
+		if len(stored) == 0 || stored[0] != '"' {
+			return "", false
+		}
+		var value string
+		if err := json.Unmarshal(stored, &value); err != nil || !strings.HasPrefix(value, prefix) {
+			return "", false
+		}
+		return value, true
+	}
+	value := string(stored)
+	return value, strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,116 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+func testKey(t *testing.T, id string) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(key)
+}
+
+func TestKeyring(t *testing.T) {
+	oldKey, newKey := testKey(t, "2024-01"), testKey(t, "2024-06")
+	old, err := NewKeyring([]string{oldKey})
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	rotated, err := NewKeyring([]string{newKey, oldKey})
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+
+	sealed, err := old.Seal("users.metadata", []byte(`{"phone":"+1 555 0100"}`), true)
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+	if !strings.HasPrefix(sealed, `"enc:v1:2024-01:`) || strings.Contains(sealed, "555") {
+		t.Errorf("Expected a JSON string of the ciphertext, got %s", sealed)
+	}
+
+	// Values of earlier keys are decrypted after a rotation
+	plaintext, keyID, err := rotated.Open("users.metadata", []byte(sealed), true)
+	if err != nil || keyID != "2024-01" || string(plaintext) != `{"phone":"+1 555 0100"}` {
+		t.Errorf("Expected the value of key 2024-01, got %s %s %v", plaintext, keyID, err)
+	}
+	resealed, _ := rotated.Seal("users.metadata", plaintext, true)
+	if _, _, err := old.Open("users.metadata", []byte(resealed), true); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey for a value of a newer key, got %v", err)
+	}
+
+	// Values are bound to their column
+	if _, _, err := rotated.Open("tenants.settings", []byte(sealed), true); err == nil {
+		t.Error("Expected a value moved to another column to fail to decrypt")
+	}
+
+	// Unencrypted values are returned as they are
+	for _, tt := range []struct {
+		stored     string
+		jsonColumn bool
+	}{
+		{`{"firstName":"Ada"}`, true},
+		{`"a string"`, true},
+		{"5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8", false},
+	} {
+		plaintext, keyID, err := rotated.Open("c.v", []byte(tt.stored), tt.jsonColumn)
+		if err != nil || keyID != "" || string(plaintext) != tt.stored {
+			t.Errorf("Expected %s to be read unencrypted, got %s %q %v", tt.stored, plaintext, keyID, err)
+		}
+	}
+
+	var none *Keyring
+	if _, _, err := none.Open("users.metadata", []byte(sealed), true); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey without keys, got %v", err)
+	}
+}
+
+func TestNewKeyringRejectsInvalidKeys(t *testing.T) {
+	for _, keys := range [][]string{
+		nil,
+		{"no-separator"},
+		{"bad id:" + base64.StdEncoding.EncodeToString(make([]byte, 32))},
+		{"short:" + base64.StdEncoding.EncodeToString(make([]byte, 16))},
+		{"k1:not base64"},
+		{testKey(t, "k1"), testKey(t, "k1")},
+	} {
+		if _, err := NewKeyring(keys); err == nil {
+			t.Errorf("Expected keys %v to be rejected", keys)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	if keyring, err := FromConfig(&config.EncryptionConfig{}); keyring != nil || err != nil {
+		t.Errorf("Expected no keyring without keys, got %v %v", keyring, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# Written by the secret manager agent\n" + testKey(t, "file-1") + "\n\n" + testKey(t, "file-0") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write keys: %v", err)
+	}
+	keyring, err := FromConfig(&config.EncryptionConfig{KeysFile: path})
+	if err != nil {
+		t.Fatalf("Failed to load keys: %v", err)
+	}
+	if keyring.PrimaryKeyID() != "file-1" || len(keyring.keys) != 2 {
+		t.Errorf("Expected file-1 to be the primary of 2 keys, got %s of %d", keyring.PrimaryKeyID(), len(keyring.keys))
+	}
+
+	keyring, err = FromConfig(&config.EncryptionConfig{Keys: []string{testKey(t, "env")}, KeysFile: path})
+	if err != nil || keyring.PrimaryKeyID() != "env" || len(keyring.keys) != 3 {
+		t.Errorf("Expected the configured key to come first, got %v %v", keyring, err)
+	}
+}
@@ -0,0 +1,98 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the GORM serializer encrypting a model field
+const SerializerName = "encrypted"
+
+var current atomic.Pointer[Keyring]
+
+func init() {
+	schema.RegisterSerializer(SerializerName, serializer{})
+}
+
+// SetKeyring sets the keyring of encrypted fields. Without one, new values are
+// stored unencrypted and reading encrypted values fails.
+func SetKeyring(k *Keyring) {
+	current.Store(k)
+}
+
+// Current returns the keyring of encrypted fields, or nil when there is none
+func Current() *Keyring {
+	return current.Load()
+}
+
+// Column returns the name of a field's column that encrypted values are bound
+// to, e.g. users.metadata
+func Column(field *schema.Field) string {
+	return field.Schema.Table + "." + field.DBName
+}
+
+// IsJSON reports whether a field holds JSON, whose encrypted values are
+// stored as JSON strings so JSON columns accept them
+func IsJSON(field *schema.Field) bool {
+	return field.FieldType == reflect.TypeOf(datatypes.JSON{})
+}
+
+// serializer encrypts string and datatypes.JSON fields. Empty values are
+// stored as they are.
+type serializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored []byte
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		stored = v
+	case string:
+		stored = []byte(v)
+	default:
+		return fmt.Errorf("unsupported value %T of encrypted column %s", dbValue, Column(field))
+	}
+
+	plaintext := stored
+	if len(stored) > 0 {
+		var err error
+		if plaintext, _, err = Current().Open(Column(field), stored, IsJSON(field)); err != nil {
+			return err
+		}
+	}
+
+	value := reflect.ValueOf(plaintext).Convert(field.FieldType)
+	if dbValue == nil && IsJSON(field) {
+		value = reflect.Zero(field.FieldType)
+	}
+	field.ReflectValueOf(ctx, dst).Set(value)
+	return nil
+}
+
+// Value implements schema.SerializerValuerInterface
+func (serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext []byte
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = []byte(v)
+	case datatypes.JSON:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		plaintext = v
+	default:
+		return nil, fmt.Errorf("unsupported field %T of encrypted column %s", fieldValue, Column(field))
+	}
+
+	keyring := Current()
+	if keyring == nil || len(plaintext) == 0 {
+		return string(plaintext), nil
+	}
+	return keyring.Seal(Column(field), plaintext, IsJSON(field))
+}
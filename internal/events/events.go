@@ -0,0 +1,60 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types emitted by Heimdall
+const (
+	EventLoginFailed     = "auth.login.failed"
+	EventAccountLocked   = "auth.account.locked"
+	EventAccountUnlocked = "auth.account.unlocked"
+	EventSuspiciousLogin = "auth.login.suspicious"
+	EventRefreshMismatch = "auth.refresh.fingerprint_mismatch"
+
+	EventAccessRequested       = "access.request.created"
+	EventAccessRequestApproved = "access.request.approved"
+	EventAccessRequestDenied   = "access.request.denied"
+
+	EventBreakGlassUsed = "breakglass.used"
+
+	EventAlertFired = "alert.fired"
+)
+
+// Event represents a domain event delivered to subscribers such as webhooks
+type Event struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	TenantID   string                 `json:"tenantId,omitempty"`
+	OccurredAt time.Time              `json:"occurredAt"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// NewEvent creates a new event with a generated ID and timestamp
+func NewEvent(eventType, tenantID string, data map[string]interface{}) Event {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Publisher publishes events to interested subscribers.
+// Publish must not block the caller on delivery.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// NoopPublisher discards all events
+type NoopPublisher struct{}
+
+// Publish discards the event
+func (NoopPublisher) Publish(ctx context.Context, event Event) {}
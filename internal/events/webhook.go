@@ -0,0 +1,122 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/utils"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of the request body
+	SignatureHeader = "X-Heimdall-Signature"
+	// EventTypeHeader carries the event type
+	EventTypeHeader = "X-Heimdall-Event"
+
+	webhookMaxAttempts = 3
+)
+
+// WebhookDispatcher delivers events to configured webhook endpoints
+type WebhookDispatcher struct {
+	urls       []string
+	secret     string
+	httpClient *http.Client
+
+	pending sync.WaitGroup // Deliveries still being attempted
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher
+func NewWebhookDispatcher(cfg *config.WebhookConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		urls:   cfg.URLs,
+		secret: cfg.Secret,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+}
+
+// Publish delivers the event to every configured endpoint in the background
+func (d *WebhookDispatcher) Publish(ctx context.Context, event Event) {
+	if len(d.urls) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal webhook event %s: %v", event.Type, err)
+		return
+	}
+
+	for _, url := range d.urls {
+		d.pending.Add(1)
+		go func() {
+			defer d.pending.Done()
+			d.deliver(url, event.Type, body)
+		}()
+	}
+}
+
+// Drain waits for deliveries still being attempted, or until ctx is done
+func (d *WebhookDispatcher) Drain(ctx context.Context) error {
+	return utils.WaitContext(ctx, &d.pending)
+}
+
+// deliver sends the payload to a single endpoint, retrying with backoff
+func (d *WebhookDispatcher) deliver(url, eventType string, body []byte) {
+	var lastErr error
+	for attempt := 0; attempt < webhookMaxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<attempt) * time.Second)
+		}
+
+		if lastErr = d.send(url, eventType, body); lastErr == nil {
+			return
+		}
+	}
+
+	log.Printf("Failed to deliver webhook %s to %s: %v", eventType, url, lastErr)
+}
+
+// send performs a single delivery attempt
+func (d *WebhookDispatcher) send(url, eventType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, eventType)
+	if d.secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(d.secret, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign computes the hex-encoded HMAC-SHA256 signature of a payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
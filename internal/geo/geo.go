@@ -0,0 +1,95 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Location represents the geographic location of an IP address
+type Location struct {
+	Country string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+// Resolver resolves IP addresses to locations
+type Resolver interface {
+	Resolve(ctx context.Context, ip string) (*Location, error)
+}
+
+// NoopResolver resolves every address to an empty location
+type NoopResolver struct{}
+
+// Resolve returns an empty location
+func (NoopResolver) Resolve(ctx context.Context, ip string) (*Location, error) {
+	return &Location{}, nil
+}
+
+// HTTPResolver resolves locations using an HTTP GeoIP service.
+// The URL template must contain "{ip}", e.g. "https://ipapi.co/{ip}/json/".
+type HTTPResolver struct {
+	urlTemplate string
+	httpClient  *http.Client
+}
+
+// NewHTTPResolver creates a new HTTP GeoIP resolver
+func NewHTTPResolver(urlTemplate string, timeout time.Duration) *HTTPResolver {
+	return &HTTPResolver{
+		urlTemplate: urlTemplate,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Resolve looks up the location of an IP address.
+// Private and loopback addresses resolve to an empty location without a lookup.
+func (r *HTTPResolver) Resolve(ctx context.Context, ip string) (*Location, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsUnspecified() {
+		return &Location{}, nil
+	}
+
+	url := strings.ReplaceAll(r.urlTemplate, "{ip}", ip)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve location: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geoip service returned status %d", resp.StatusCode)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode geoip response: %w", err)
+	}
+
+	// Field names differ between providers, so accept the common variants
+	return &Location{
+		Country: strings.ToUpper(firstString(body, "country_code", "countryCode", "country")),
+		Region:  firstString(body, "region", "regionName", "region_name"),
+		City:    firstString(body, "city"),
+	}, nil
+}
+
+// firstString returns the first non-empty string value among the given keys
+func firstString(body map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := body[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package geo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPResolver_Resolve(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/8.8.8.8/json" {
+			t.Errorf("Expected path '/8.8.8.8/json', got '%s'", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"countryCode":"us","regionName":"California","city":"Mountain View"}`))
+	}))
+	defer server.Close()
+
+	resolver := NewHTTPResolver(server.URL+"/{ip}/json", time.Second)
+
+	location, err := resolver.Resolve(context.Background(), "8.8.8.8")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if location.Country != "US" {
+		t.Errorf("Expected country 'US', got '%s'", location.Country)
+	}
+	if location.Region != "California" || location.City != "Mountain View" {
+		t.Errorf("Expected California/Mountain View, got '%s'/'%s'", location.Region, location.City)
+	}
+
+	// Private addresses are never sent to the provider
+	for _, ip := range []string{"127.0.0.1", "10.0.0.5", "not-an-ip"} {
+		location, err := resolver.Resolve(context.Background(), ip)
+		if err != nil || location.Country != "" {
+			t.Errorf("Expected empty location for %s, got %+v (err=%v)", ip, location, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 provider request, got %d", requests)
+	}
+}
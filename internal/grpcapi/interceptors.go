@@ -0,0 +1,151 @@
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/pkg/heimdallpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without an access token
+var publicMethods = map[string]bool{
+	heimdallpb.Heimdall_Authenticate_FullMethodName:  true,
+	heimdallpb.Heimdall_ValidateToken_FullMethodName: true,
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the caller's access token, or nil
+func ClaimsFromContext(ctx context.Context) *auth.TokenClaims {
+	claims, _ := ctx.Value(claimsKey{}).(*auth.TokenClaims)
+	return claims
+}
+
+// AuthInterceptor validates the bearer token in the "authorization" metadata and
+// stores its claims in the context. Public methods skip the check.
+func AuthInterceptor(jwtService *auth.JWTService, redis *database.RedisClient) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+		tokenString, err := auth.ExtractTokenFromHeader(values[0])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		claims, err := validateAccessToken(ctx, jwtService, redis, tokenString)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, claimsKey{}, claims), req)
+	}
+}
+
+// validateAccessToken validates an access token and checks it was not revoked
+func validateAccessToken(ctx context.Context, jwtService *auth.JWTService, redis *database.RedisClient, tokenString string) (*auth.TokenClaims, error) {
+	if tokenString == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	claims, err := jwtService.ValidateAccessToken(tokenString)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	if redis != nil {
+		// Revoking a token also revokes the tokens exchanged from it
+		for _, tokenID := range claims.TokenIDs() {
+			blacklisted, err := redis.IsTokenBlacklisted(ctx, tokenID)
+			if err == nil && blacklisted {
+				return nil, status.Error(codes.Unauthenticated, "token has been revoked")
+			}
+		}
+		if claims.UserID != "" && claims.IssuedAt != nil {
+			revokedAt, err := redis.UserSessionsRevokedAt(ctx, claims.UserID)
+			if err == nil && !claims.IssuedAt.After(revokedAt) {
+				return nil, status.Error(codes.Unauthenticated, "token has been revoked")
+			}
+		}
+	}
+	return claims, nil
+}
+
+// MethodStats holds the call statistics of one RPC method
+type MethodStats struct {
+	Calls         int64            `json:"calls"`
+	Errors        int64            `json:"errors"`
+	TotalDuration time.Duration    `json:"totalDurationNs"`
+	Codes         map[string]int64 `json:"codes"` // Calls by status code
+}
+
+// Metrics collects call statistics of the gRPC API
+type Metrics struct {
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+}
+
+// NewMetrics creates an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{methods: make(map[string]*MethodStats)}
+}
+
+func (m *Metrics) record(method string, code codes.Code, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.methods[method]
+	if !ok {
+		stats = &MethodStats{Codes: make(map[string]int64)}
+		m.methods[method] = stats
+	}
+	stats.Calls++
+	if code != codes.OK {
+		stats.Errors++
+	}
+	stats.TotalDuration += duration
+	stats.Codes[code.String()]++
+}
+
+// Snapshot returns a copy of the statistics by full method name
+func (m *Metrics) Snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]MethodStats, len(m.methods))
+	for method, stats := range m.methods {
+		copied := *stats
+		copied.Codes = make(map[string]int64, len(stats.Codes))
+		for code, count := range stats.Codes {
+			copied.Codes[code] = count
+		}
+		snapshot[method] = copied
+	}
+	return snapshot
+}
+
+// MetricsInterceptor records the status code and latency of every call and logs
+// it in the same format as the HTTP request log
+func MetricsInterceptor(metrics *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		latency := time.Since(start)
+
+		code := status.Code(err)
+		metrics.record(info.FullMethod, code, latency)
+		log.Printf("%s - %s gRPC %s", code, latency, info.FullMethod)
+		return resp, err
+	}
+}
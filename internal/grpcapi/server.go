@@ -0,0 +1,301 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/pkg/heimdallpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBatchChecks bounds the number of decisions evaluated by one BatchCheck call
+const maxBatchChecks = 100
+
+// Server implements the Heimdall gRPC API on top of the same services as the REST API
+type Server struct {
+	heimdallpb.UnimplementedHeimdallServer
+
+	authService *service.AuthService
+	jwtService  *auth.JWTService
+	evaluator   *opa.Evaluator
+	redis       *database.RedisClient
+	attributes  *service.UserAttributeService
+}
+
+// NewServer creates a new gRPC API server
+func NewServer(authService *service.AuthService, jwtService *auth.JWTService, evaluator *opa.Evaluator, redis *database.RedisClient) *Server {
+	return &Server{
+		authService: authService,
+		jwtService:  jwtService,
+		evaluator:   evaluator,
+		redis:       redis,
+	}
+}
+
+// SetUserAttributeService exposes users' typed attributes to policies as
+// input.user.metadata
+func (s *Server) SetUserAttributeService(attributes *service.UserAttributeService) {
+	s.attributes = attributes
+}
+
+// Register creates a gRPC server with the authentication and metrics
+// interceptors and registers the Heimdall service on it
+func (s *Server) Register(metrics *Metrics) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		MetricsInterceptor(metrics),
+		AuthInterceptor(s.jwtService, s.redis),
+	))
+	heimdallpb.RegisterHeimdallServer(server, s)
+	return server
+}
+
+// Authenticate logs a user in with email and password
+func (s *Server) Authenticate(ctx context.Context, req *heimdallpb.AuthenticateRequest) (*heimdallpb.AuthenticateResponse, error) {
+	if req.GetEmail() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	}
+
+	ipAddress := req.GetIpAddress()
+	if ipAddress == "" {
+		ipAddress = peerIP(ctx)
+	}
+	result, err := s.authService.Login(ctx, &service.LoginRequest{
+		Email:     req.GetEmail(),
+		Password:  req.GetPassword(),
+		IPAddress: ipAddress,
+		UserAgent: req.GetUserAgent(),
+	})
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			return nil, status.Errorf(codes.ResourceExhausted, "too many login attempts, retry after %s", throttled.RetryAfter)
+		}
+		var rejected *service.LoginRejectedError
+		if errors.As(err, &rejected) {
+			if rejected.StepUp {
+				return nil, status.Errorf(codes.Unauthenticated, "additional verification required: %s", rejected.Reason)
+			}
+			return nil, status.Errorf(codes.PermissionDenied, "login rejected: %s", rejected.Reason)
+		}
+		if errors.Is(err, apperrors.ErrForbidden) || errors.Is(err, apperrors.ErrUnavailable) {
+			return nil, toStatus(err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	return &heimdallpb.AuthenticateResponse{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		TokenType:    result.TokenType,
+		ExpiresIn:    result.ExpiresIn,
+		User: &heimdallpb.User{
+			Id:        result.User.ID,
+			Email:     result.User.Email,
+			FirstName: result.User.FirstName,
+			LastName:  result.User.LastName,
+			TenantId:  result.User.TenantID,
+		},
+	}, nil
+}
+
+// ValidateToken validates an access token, including revocation, and returns its claims
+func (s *Server) ValidateToken(ctx context.Context, req *heimdallpb.ValidateTokenRequest) (*heimdallpb.ValidateTokenResponse, error) {
+	claims, err := validateAccessToken(ctx, s.jwtService, s.redis, req.GetToken())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &heimdallpb.ValidateTokenResponse{
+		UserId:   claims.UserID,
+		TenantId: claims.TenantID,
+		Email:    claims.Email,
+		Roles:    claims.Roles,
+		TokenId:  claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
+	}
+	if len(claims.Attributes) > 0 {
+		if resp.Attributes, err = structpb.NewStruct(claims.Attributes); err != nil {
+			return nil, status.Error(codes.Internal, "failed to encode token attributes")
+		}
+	}
+	return resp, nil
+}
+
+// CheckPermission evaluates an authorization decision for the caller
+func (s *Server) CheckPermission(ctx context.Context, req *heimdallpb.CheckPermissionRequest) (*heimdallpb.CheckPermissionResponse, error) {
+	claims := ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "access token is required")
+	}
+	return s.check(ctx, claims, req)
+}
+
+// BatchCheck evaluates several authorization decisions for the caller. A failed
+// check fails the whole batch.
+func (s *Server) BatchCheck(ctx context.Context, req *heimdallpb.BatchCheckRequest) (*heimdallpb.BatchCheckResponse, error) {
+	claims := ClaimsFromContext(ctx)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "access token is required")
+	}
+	if len(req.GetChecks()) > maxBatchChecks {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d checks are allowed per batch", maxBatchChecks)
+	}
+
+	results := make([]*heimdallpb.CheckPermissionResponse, len(req.GetChecks()))
+	for i, check := range req.GetChecks() {
+		result, err := s.check(ctx, claims, check)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return &heimdallpb.BatchCheckResponse{Results: results}, nil
+}
+
+// check builds the authorization input like POST /v1/authz/check does: the subject
+// always comes from the token and the request only describes the resource
+func (s *Server) check(ctx context.Context, claims *auth.TokenClaims, req *heimdallpb.CheckPermissionRequest) (*heimdallpb.CheckPermissionResponse, error) {
+	resource := req.GetResource()
+	if resource.GetType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource type is required")
+	}
+	if req.GetAction() == "" {
+		return nil, status.Error(codes.InvalidArgument, "action is required")
+	}
+
+	// Exchanged tokens are denied anything outside their scope without asking OPA
+	if !claims.AllowsScope(resource.GetType() + "." + req.GetAction()) {
+		return &heimdallpb.CheckPermissionResponse{Allow: false, Reason: "out_of_scope"}, nil
+	}
+
+	tenantID := resource.GetTenantId()
+	if tenantID == "" {
+		tenantID = claims.TenantID
+	}
+
+	// Client tokens have no roles for OPA to evaluate, their scope is the decision
+	if claims.ClientID != "" {
+		if tenantID != claims.TenantID || len(claims.Scopes()) == 0 {
+			return &heimdallpb.CheckPermissionResponse{Allow: false, Reason: "out_of_scope"}, nil
+		}
+		return &heimdallpb.CheckPermissionResponse{Allow: true, Reason: "client_scope"}, nil
+	}
+
+	builder := opa.NewContextBuilder()
+	builder.WithUser(claims.UserID, claims.Email, claims.Roles)
+	builder.WithUserScope(claims.AccessScope)
+	builder.WithUserPermissions(claims.Permissions)
+	builder.WithUserTenant(claims.TenantID)
+	builder.WithUserStatus(models.UserStatusActive) // Suspended users' tokens are rejected
+	builder.WithTenant(claims.TenantID, "", nil)
+	metadata := claims.Attributes
+	if s.attributes != nil {
+		// Typed attributes of the tenant's user attribute schema take precedence
+		attributes, err := s.attributes.UserAttributes(ctx, claims.TenantID, claims.UserID)
+		if err != nil {
+			log.Printf("Failed to load attributes of user %s: %v", claims.UserID, err)
+		} else if len(attributes) > 0 {
+			metadata = opa.MergeMetadata(metadata, attributes)
+		}
+	}
+	if len(metadata) > 0 {
+		builder.WithUserMetadata(metadata)
+	}
+	builder.WithIPAddress(peerIP(ctx))
+	builder.WithResource(resource.GetType(), resource.GetId())
+	builder.WithResourceOwner(resource.GetOwnerId())
+	builder.WithResourceTenant(tenantID)
+	builder.WithResourceAttributes(resource.GetAttributes().AsMap())
+	builder.WithAction(req.GetAction())
+	if err := builder.Enrich(ctx, s.evaluator.Enrichment()); err != nil {
+		return nil, toStatus(apperrors.Wrap(err, "AUTHZ_EVALUATION_FAILED", "Authorization evaluation failed"))
+	}
+	input := builder.Build()
+
+	// Caller-supplied context may add attributes but never override request-derived ones
+	if contextMap, ok := input["context"].(map[string]interface{}); ok {
+		for key, value := range req.GetContext().AsMap() {
+			if _, exists := contextMap[key]; !exists {
+				contextMap[key] = value
+			}
+		}
+	}
+
+	decision, err := s.evaluator.EvaluateWithCacheHints(ctx, claims.UserID, input, opa.CacheHints{})
+	if err != nil {
+		return nil, toStatus(apperrors.Wrap(err, "AUTHZ_EVALUATION_FAILED", "Authorization evaluation failed"))
+	}
+
+	reason := decision.Reason
+	if reason == "" {
+		reason = "access_denied"
+		if decision.Allow {
+			reason = "access_granted"
+		}
+	}
+	return &heimdallpb.CheckPermissionResponse{
+		Allow:       decision.Allow,
+		Reason:      reason,
+		DecisionId:  decision.DecisionID,
+		CacheStatus: decision.CacheStatus,
+	}, nil
+}
+
+// toStatus converts a service error into a gRPC status, keeping internal details private
+func toStatus(err error) error {
+	var typed *apperrors.Error
+	message := "internal error"
+	if errors.As(err, &typed) {
+		message = typed.Message
+	}
+
+	switch {
+	case errors.Is(err, apperrors.ErrValidation):
+		return status.Error(codes.InvalidArgument, message)
+	case errors.Is(err, apperrors.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, message)
+	case errors.Is(err, apperrors.ErrForbidden):
+		return status.Error(codes.PermissionDenied, message)
+	case errors.Is(err, apperrors.ErrNotFound):
+		return status.Error(codes.NotFound, message)
+	case errors.Is(err, apperrors.ErrConflict):
+		return status.Error(codes.AlreadyExists, message)
+	case errors.Is(err, apperrors.ErrPrecondition):
+		return status.Error(codes.FailedPrecondition, message)
+	case errors.Is(err, apperrors.ErrUnavailable):
+		return status.Error(codes.Unavailable, message)
+	case errors.Is(err, apperrors.ErrTimeout):
+		return status.Error(codes.DeadlineExceeded, message)
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
+
+// peerIP returns the IP address of the calling service
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return strings.TrimSpace(p.Addr.String())
+	}
+	return host
+}
@@ -0,0 +1,139 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"github.com/techsavvyash/heimdall/pkg/heimdallpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newFakeOPA allows "read" on any resource of the user's own tenant
+func newFakeOPA(t *testing.T) *opa.Evaluator {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req opa.DecisionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user := req.Input["user"].(map[string]interface{})
+		resource := req.Input["resource"].(map[string]interface{})
+		allow := req.Input["action"] == "read" && user["tenantId"] == resource["tenantId"]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"decision_id": "decision-1",
+			"result":      map[string]interface{}{"allow": allow},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	client := opa.NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: 5 * time.Second})
+	return opa.NewEvaluator(client, nil, false)
+}
+
+func newTestClient(t *testing.T) (heimdallpb.HeimdallClient, *Metrics, string) {
+	t.Helper()
+
+	jwtService, cleanup := testutil.CreateTestJWTService(t)
+	t.Cleanup(cleanup)
+	token := testutil.GenerateTestToken(t, jwtService, "user-1", "tenant-1", "alice@example.com", []string{"viewer"})
+
+	metrics := NewMetrics()
+	server := NewServer(nil, jwtService, newFakeOPA(t), nil).Register(metrics)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return heimdallpb.NewHeimdallClient(conn), metrics, token
+}
+
+func TestServer_ValidateToken(t *testing.T) {
+	client, _, token := newTestClient(t)
+	ctx := context.Background()
+
+	resp, err := client.ValidateToken(ctx, &heimdallpb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		t.Fatalf("ValidateToken returned error: %v", err)
+	}
+	if resp.UserId != "user-1" || resp.TenantId != "tenant-1" || len(resp.Roles) != 1 || resp.ExpiresAt == nil {
+		t.Errorf("Unexpected claims: %+v", resp)
+	}
+
+	_, err = client.ValidateToken(ctx, &heimdallpb.ValidateTokenRequest{Token: "not-a-token"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for an invalid token, got %v", err)
+	}
+	_, err = client.Authenticate(ctx, &heimdallpb.AuthenticateRequest{Email: "alice@example.com"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without a password, got %v", err)
+	}
+}
+
+func TestServer_CheckPermission(t *testing.T) {
+	client, metrics, token := newTestClient(t)
+
+	check := &heimdallpb.CheckPermissionRequest{
+		Resource: &heimdallpb.Resource{Type: "documents", Id: "doc-1"},
+		Action:   "read",
+	}
+	if _, err := client.CheckPermission(context.Background(), check); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	resp, err := client.CheckPermission(ctx, check)
+	if err != nil {
+		t.Fatalf("CheckPermission returned error: %v", err)
+	}
+	if !resp.Allow || resp.Reason != "access_granted" || resp.DecisionId != "decision-1" {
+		t.Errorf("Unexpected decision: %+v", resp)
+	}
+
+	attributes, _ := structpb.NewStruct(map[string]interface{}{"classification": "internal"})
+	batch, err := client.BatchCheck(ctx, &heimdallpb.BatchCheckRequest{Checks: []*heimdallpb.CheckPermissionRequest{
+		check,
+		{Resource: &heimdallpb.Resource{Type: "documents", Attributes: attributes}, Action: "delete"},
+		{Resource: &heimdallpb.Resource{Type: "documents", TenantId: "tenant-2"}, Action: "read"},
+	}})
+	if err != nil {
+		t.Fatalf("BatchCheck returned error: %v", err)
+	}
+	if len(batch.Results) != 3 || !batch.Results[0].Allow || batch.Results[1].Allow || batch.Results[2].Allow {
+		t.Errorf("Unexpected batch results: %+v", batch.Results)
+	}
+
+	_, err = client.CheckPermission(ctx, &heimdallpb.CheckPermissionRequest{Action: "read"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without a resource type, got %v", err)
+	}
+
+	stats := metrics.Snapshot()[heimdallpb.Heimdall_CheckPermission_FullMethodName]
+	if stats.Calls != 3 || stats.Errors != 2 || stats.Codes["Unauthenticated"] != 1 {
+		t.Errorf("Unexpected CheckPermission metrics: %+v", stats)
+	}
+}
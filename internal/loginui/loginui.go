@@ -0,0 +1,144 @@
+// Package loginui serves hosted login, registration and consent pages, for
+// customers who do not build their own. Pages are rendered on the server with
+// the branding and login methods of the tenant named by the tenant query
+// parameter, and submit to the public /v1/auth endpoints.
+package loginui
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/service"
+	"github.com/techsavvyash/heimdall/internal/utils"
+)
+
+// BasePath is the path the pages are served under
+const BasePath = "/login"
+
+//go:embed static templates
+var content embed.FS
+
+// files holds the pages' scripts and styles relative to their base path
+var files, _ = fs.Sub(content, "static")
+
+var pages = template.Must(template.ParseFS(content, "templates/*.html"))
+
+// Tenants looks up what a tenant's pages show
+type Tenants interface {
+	GetPublicTenant(ctx context.Context, slug string) (*service.PublicTenant, error)
+}
+
+// page is what a page is rendered with
+type page struct {
+	Name     string                // login, register, magic-link or consent
+	Tenant   *service.PublicTenant // Nil without tenant, when any user may sign in
+	Query    string                // Query string carrying the tenant and redirect across pages
+	Redirect string                // Allowed URL tokens are sent to after login
+	UserCode string                // Device user code of the consent page
+	Error    string
+}
+
+// Offers reports whether the page offers a login method
+func (p *page) Offers(method string) bool {
+	if p.Tenant == nil {
+		return method != service.LoginMethodSAML
+	}
+	return slices.Contains(p.Tenant.LoginMethods, method)
+}
+
+// Title returns the name the page is titled with
+func (p *page) Title() string {
+	if p.Tenant == nil {
+		return "Heimdall"
+	}
+	return p.Tenant.Name
+}
+
+// RegisterRoutes serves the pages. After login, tokens are sent in the URL
+// fragment to the redirect_uri query parameter, which must have the scheme and
+// host, including the port, of one of allowedRedirects and its path or one
+// below it (see utils.RedirectAllowed); without it, the browser keeps the
+// session for the consent page.
+func RegisterRoutes(app *fiber.App, tenants Tenants, allowedRedirects []string) {
+	render := func(name string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			return renderPage(c, tenants, allowedRedirects, name)
+		}
+	}
+	app.Get(BasePath, render("login"))
+	app.Get(BasePath+"/register", render("register"))
+	app.Get(BasePath+"/magic-link", render("magic-link"))
+	app.Get(BasePath+"/consent", render("consent"))
+	app.Get(BasePath+"/:file.:ext", serveFile)
+}
+
+// renderPage renders a page for the tenant and redirect of the request. Pages
+// that cannot be shown render with an error, so the user is not left with a
+// JSON response.
+func renderPage(c *fiber.Ctx, tenants Tenants, allowedRedirects []string, name string) error {
+	p := &page{Name: name, UserCode: c.Query("user_code")}
+	status := fiber.StatusOK
+
+	query := url.Values{}
+	if slug := c.Query("tenant"); slug != "" {
+		tenant, err := tenants.GetPublicTenant(c.UserContext(), slug)
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			status, p.Error = fiber.StatusNotFound, "This organization was not found."
+		case err != nil:
+			log.Printf("Failed to get tenant %s for the login page: %v", slug, err)
+			status, p.Error = fiber.StatusInternalServerError, "The login page is unavailable, try again later."
+		default:
+			p.Tenant = tenant
+			query.Set("tenant", slug)
+		}
+	}
+	if redirect := c.Query("redirect_uri"); redirect != "" && p.Error == "" {
+		if !utils.RedirectAllowed(redirect, allowedRedirects) {
+			status, p.Error = fiber.StatusBadRequest, "The application you came from is not allowed to use this login page."
+		} else {
+			p.Redirect = redirect
+			query.Set("redirect_uri", redirect)
+		}
+	}
+	if name == "register" && p.Error == "" && !p.Offers(service.LoginMethodPassword) {
+		status, p.Error = fiber.StatusNotFound, "Registration is not available for this organization."
+	}
+	if len(query) > 0 {
+		p.Query = "?" + query.Encode()
+	}
+
+	var body bytes.Buffer
+	if err := pages.ExecuteTemplate(&body, "page.html", p); err != nil {
+		return err
+	}
+	// Pages depend on the tenant's settings and must not be replayed from caches
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Type("html")
+	return c.Status(status).Send(body.Bytes())
+}
+
+// serveFile returns a script or style of the pages
+func serveFile(c *fiber.Ctx) error {
+	name := path.Base(c.Path())
+	data, err := fs.ReadFile(files, name)
+	if err != nil {
+		return fiber.ErrNotFound
+	}
+
+	// Files are not fingerprinted, so browsers revalidate them after upgrades
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Type(strings.TrimPrefix(path.Ext(name), "."))
+	return c.Send(data)
+}
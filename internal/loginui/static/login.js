@@ -0,0 +1,231 @@
+"use strict";
+
+// Hosted login pages. The server renders each page for its tenant; this script
+// submits its forms to the public /v1 API and hands the tokens on.
+
+const page = document.body.dataset;
+
+// The session is kept for the tab only, so the consent page can act as the
+// signed-in user
+const session = {
+  get accessToken() {
+    return sessionStorage.getItem("heimdall.login.accessToken");
+  },
+  save(tokens) {
+    sessionStorage.setItem("heimdall.login.accessToken", tokens.accessToken);
+  },
+};
+
+// Magic links open in a new tab, so where to return after login outlives the tab
+const pending = {
+  save() {
+    localStorage.setItem("heimdall.login.redirect", page.redirect || "");
+  },
+  take() {
+    const redirect = localStorage.getItem("heimdall.login.redirect") || "";
+    localStorage.removeItem("heimdall.login.redirect");
+    return redirect;
+  },
+};
+
+class ApiError extends Error {
+  constructor(status, payload) {
+    super((payload && payload.error && payload.error.message) || `Request failed with status ${status}`);
+    this.status = status;
+    this.code = payload && payload.error && payload.error.code;
+  }
+}
+
+async function api(method, path, body) {
+  const headers = { Accept: "application/json" };
+  if (session.accessToken) {
+    headers.Authorization = `Bearer ${session.accessToken}`;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const response = await fetch(`/v1${path}`, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const payload = await response.json().catch(() => null);
+  if (!response.ok) {
+    throw new ApiError(response.status, payload);
+  }
+  return payload ? payload.data : null;
+}
+
+function notify(message, kind = "error") {
+  const notice = document.getElementById("notice");
+  notice.textContent = message;
+  notice.className = kind;
+  notice.hidden = false;
+}
+
+function formData(form) {
+  return Object.fromEntries(new FormData(form).entries());
+}
+
+// submit runs a form's action, disabling it meanwhile and showing failures
+function submit(id, action) {
+  const form = document.getElementById(id);
+  if (!form) {
+    return;
+  }
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const button = form.querySelector("button");
+    button.disabled = true;
+    try {
+      await action(formData(form));
+    } catch (err) {
+      notify(err.message);
+    } finally {
+      button.disabled = false;
+    }
+  });
+}
+
+// signedIn sends the tokens to the application the user came from, in the URL
+// fragment so they do not reach its server logs, or continues to the consent
+// page that sent the user to sign in
+function signedIn(tokens, redirect = page.redirect) {
+  session.save(tokens);
+  if (redirect) {
+    const fragment = new URLSearchParams({
+      access_token: tokens.accessToken,
+      refresh_token: tokens.refreshToken,
+      token_type: tokens.tokenType,
+      expires_in: String(tokens.expiresIn),
+    });
+    window.location.replace(`${redirect}#${fragment}`);
+    return;
+  }
+  const next = sessionStorage.getItem("heimdall.login.next");
+  if (next) {
+    sessionStorage.removeItem("heimdall.login.next");
+    window.location.replace(next);
+    return;
+  }
+  notify("You are signed in. You can close this page.", "ok");
+  document.querySelectorAll("form, #sso, .alternate").forEach((element) => {
+    element.hidden = true;
+  });
+}
+
+function setupLogin() {
+  submit("login-form", async ({ email, password }) => {
+    signedIn(await api("POST", "/auth/login", { email, password }));
+  });
+  submit("magic-link-form", async ({ email }) => {
+    const body = { email };
+    if (page.tenantId) {
+      body.tenantId = page.tenantId;
+    }
+    await api("POST", "/auth/magic-link", body);
+    pending.save();
+    notify("Check your email for a sign-in link.", "ok");
+  });
+}
+
+function setupRegister() {
+  submit("register-form", async (fields) => {
+    if (page.tenantId) {
+      fields.tenantId = page.tenantId;
+    }
+    signedIn(await api("POST", "/auth/register", fields));
+  });
+}
+
+async function redeemMagicLink() {
+  const status = document.getElementById("magic-link-status");
+  const token = new URLSearchParams(window.location.search).get("token");
+  if (!token) {
+    status.hidden = true;
+    notify("This sign-in link is incomplete. Request a new one.");
+    return;
+  }
+  try {
+    signedIn(await api("POST", "/auth/magic-link/verify", { token }), pending.take());
+    status.hidden = true;
+  } catch (err) {
+    status.hidden = true;
+    notify(err.message);
+  }
+}
+
+async function setupConsent() {
+  if (!session.accessToken) {
+    // Come back once signed in
+    sessionStorage.setItem("heimdall.login.next", window.location.href);
+    document.getElementById("consent-login").hidden = false;
+    return;
+  }
+
+  const codeForm = document.getElementById("code-form");
+  if (!page.userCode) {
+    codeForm.hidden = false;
+    submit("code-form", async ({ userCode }) => {
+      const url = new URL(window.location.href);
+      url.searchParams.set("user_code", userCode.trim());
+      window.location.assign(url);
+    });
+    return;
+  }
+
+  let authorization;
+  try {
+    authorization = await api("GET", `/oauth/device?user_code=${encodeURIComponent(page.userCode)}`);
+  } catch (err) {
+    if (err.status === 401) {
+      sessionStorage.removeItem("heimdall.login.accessToken");
+      return setupConsent();
+    }
+    notify(err.message);
+    return;
+  }
+  if (authorization.status !== "pending") {
+    notify(`This device was already ${authorization.status}.`, "ok");
+    return;
+  }
+
+  document.getElementById("consent-client").textContent = authorization.clientId;
+  document.getElementById("consent-code").textContent = authorization.userCode;
+  const consent = document.getElementById("consent");
+  consent.hidden = false;
+
+  const decide = (decision) => async () => {
+    consent.querySelectorAll("button").forEach((button) => {
+      button.disabled = true;
+    });
+    try {
+      await api("POST", `/oauth/device/${decision}`, { userCode: authorization.userCode });
+      consent.hidden = true;
+      notify(decision === "approve" ? "The device is signed in. You can close this page." : "The device was denied.", "ok");
+    } catch (err) {
+      notify(err.message);
+    }
+  };
+  document.getElementById("approve").addEventListener("click", decide("approve"));
+  document.getElementById("deny").addEventListener("click", decide("deny"));
+}
+
+if (page.color) {
+  document.documentElement.style.setProperty("--accent", page.color);
+}
+
+switch (page.page) {
+  case "login":
+    setupLogin();
+    break;
+  case "register":
+    setupRegister();
+    break;
+  case "magic-link":
+    redeemMagicLink();
+    break;
+  case "consent":
+    setupConsent();
+    break;
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ActionNonceHeader carries the nonce confirming a destructive action
+const ActionNonceHeader = "X-Action-Nonce"
+
+// ActionNonceConsumer accepts the one-time nonces confirming actions
+type ActionNonceConsumer interface {
+	// ConsumeNonce accepts a nonce a user fetched for an action on a resource,
+	// rejecting it once it was used or expired
+	ConsumeNonce(ctx context.Context, userID, action, resourceID, nonce string) error
+}
+
+// RequireActionNonce requires a destructive action to be confirmed with a
+// nonce the user fetched for it, sent in the X-Action-Nonce header. idParam
+// names the route parameter holding the affected resource's ID. It runs after
+// permission checks, so denied requests do not use up their nonce.
+func RequireActionNonce(consumer ActionNonceConsumer, action, idParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := consumer.ConsumeNonce(c.UserContext(), GetUserID(c), action, c.Params(idParam), c.Get(ActionNonceHeader))
+		if err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
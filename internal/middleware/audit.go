@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/datatypes"
+)
+
+// maxAuditedBodySize caps the request and response payloads recorded for an
+// administrative action
+const maxAuditedBodySize = 16 * 1024
+
+// maxAuditedPathLength is the length of the audit log's path column
+const maxAuditedPathLength = 500
+
+// redactedValue replaces sensitive values in audited payloads
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyFragments mark payload keys whose values are never recorded
+var sensitiveKeyFragments = []string{"password", "secret", "token", "key", "credential", "authorization"}
+
+// AdminActionRecorder records sensitive administrative actions
+type AdminActionRecorder interface {
+	// RecordAdminAction stores an administrative action in the audit log
+	RecordAdminAction(ctx context.Context, entry *models.AuditLog) error
+}
+
+// AuditAdminAction records a sensitive administrative action in the audit log,
+// with the acting user, the route and its result, and the request and response
+// payloads with credentials redacted. It must run after authentication and
+// before permission checks, so denied attempts are recorded too. idParam names
+// the route parameter holding the affected resource's ID, if any.
+func AuditAdminAction(recorder AdminActionRecorder, action, resource, idParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		request := redactedPayload(c.Body())
+
+		if err := c.Next(); err != nil {
+			// Render the error now so its status is recorded
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				return handlerErr
+			}
+		}
+
+		tenantID, err := uuid.Parse(GetTenantID(c))
+		if err != nil {
+			return nil
+		}
+
+		statusCode := c.Response().StatusCode()
+		entry := &models.AuditLog{
+			TenantID:   tenantID,
+			Action:     action,
+			Resource:   resource,
+			IPAddress:  c.IP(),
+			UserAgent:  c.Get(fiber.HeaderUserAgent),
+			Method:     c.Method(),
+			Path:       truncate(c.OriginalURL(), maxAuditedPathLength),
+			Status:     auditStatus(statusCode),
+			StatusCode: statusCode,
+			Duration:   time.Since(start).Milliseconds(),
+		}
+		if userID, err := uuid.Parse(GetUserID(c)); err == nil {
+			entry.UserID = &userID
+		}
+		if idParam != "" {
+			if resourceID, err := uuid.Parse(c.Params(idParam)); err == nil {
+				entry.ResourceID = &resourceID
+			}
+		}
+
+		response := redactedPayload(c.Response().Body())
+		if body, ok := response.(map[string]interface{}); ok && statusCode >= fiber.StatusBadRequest {
+			if apiErr, ok := body["error"].(map[string]interface{}); ok {
+				entry.Message, _ = apiErr["code"].(string)
+			}
+		}
+
+		details := map[string]interface{}{}
+		if params := c.AllParams(); len(params) > 0 {
+			details["params"] = params
+		}
+		if clientID := GetClientID(c); clientID != "" {
+			details["clientId"] = clientID
+		}
+		if breakGlass := GetBreakGlass(c); breakGlass != nil {
+			details["breakGlass"] = breakGlassDetails(breakGlass)
+		}
+		if request != nil {
+			details["request"] = request
+		}
+		if response != nil {
+			details["response"] = response
+		}
+		if metadata, err := json.Marshal(details); err == nil {
+			entry.Metadata = datatypes.JSON(metadata)
+		}
+
+		// The action has already happened, so a failure to record it does not
+		// fail the request
+		if err := recorder.RecordAdminAction(c.UserContext(), entry); err != nil {
+			log.Printf("Failed to audit %s %s: %v", c.Method(), c.Path(), err)
+		}
+		return nil
+	}
+}
+
+// auditStatus classifies a response status as success, failure or error
+func auditStatus(statusCode int) string {
+	switch {
+	case statusCode >= fiber.StatusInternalServerError:
+		return "error"
+	case statusCode >= fiber.StatusBadRequest:
+		return "failure"
+	default:
+		return "success"
+	}
+}
+
+// redactedPayload decodes a JSON payload with sensitive values redacted. Empty,
+// oversized or non-JSON payloads are not recorded.
+func redactedPayload(body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	if len(body) > maxAuditedBodySize {
+		return map[string]interface{}{"truncated": true, "size": len(body)}
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil
+	}
+	return redact(payload)
+}
+
+// redact replaces the values of sensitive keys throughout a decoded payload
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redact(nested)
+			}
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isSensitiveKey reports whether a payload key may hold a credential
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, fragment := range sensitiveKeyFragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
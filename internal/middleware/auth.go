@@ -7,11 +7,17 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
 )
 
 // AuthMiddleware validates JWT tokens and sets user context
 func AuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Already authenticated by the break-glass middleware
+		if GetBreakGlass(c) != nil {
+			return c.Next()
+		}
+
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -48,19 +54,15 @@ func AuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 			})
 		}
 
-		// Check if token is blacklisted
-		redis := database.GetRedis()
-		if redis != nil {
-			blacklisted, err := redis.IsTokenBlacklisted(context.Background(), claims.ID)
-			if err == nil && blacklisted {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"success": false,
-					"error": fiber.Map{
-						"message": "Token has been revoked",
-						"code":    "TOKEN_REVOKED",
-					},
-				})
-			}
+		// Check if token, or a token it was exchanged from, is blacklisted
+		if isRevoked(claims) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "Token has been revoked",
+					"code":    "TOKEN_REVOKED",
+				},
+			})
 		}
 
 		// Set user info in context
@@ -68,7 +70,16 @@ func AuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 		c.Locals("tenantID", claims.TenantID)
 		c.Locals("email", claims.Email)
 		c.Locals("roles", claims.Roles)
+		c.Locals("permissions", claims.Permissions)
+		c.Locals("scopes", claims.Scopes())
+		c.Locals("clientID", claims.ClientID)
 		c.Locals("tokenID", claims.ID)
+		c.Locals("sessionAttributes", claims.Attributes)
+		c.Locals("accessScope", claims.AccessScope)
+		if claims.UserID != "" {
+			// Suspending a user revokes their tokens, so any accepted user is active
+			c.Locals("userStatus", models.UserStatusActive)
+		}
 
 		return c.Next()
 	}
@@ -93,17 +104,54 @@ func OptionalAuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 		}
 
 		// Check if token is blacklisted
-		redis := database.GetRedis()
-		if redis != nil {
-			blacklisted, err := redis.IsTokenBlacklisted(context.Background(), claims.ID)
-			if err == nil && !blacklisted {
-				c.Locals("userID", claims.UserID)
-				c.Locals("tenantID", claims.TenantID)
-				c.Locals("email", claims.Email)
-				c.Locals("roles", claims.Roles)
-			}
+		if database.GetRedis() != nil && !isRevoked(claims) {
+			c.Locals("userID", claims.UserID)
+			c.Locals("tenantID", claims.TenantID)
+			c.Locals("email", claims.Email)
+			c.Locals("roles", claims.Roles)
+			c.Locals("scopes", claims.Scopes())
+			c.Locals("clientID", claims.ClientID)
+			c.Locals("accessScope", claims.AccessScope)
+		}
+
+		return c.Next()
+	}
+}
+
+// isRevoked reports whether the token, or a token it was exchanged from, is
+// blacklisted, or was issued before its user's sessions were revoked
+func isRevoked(claims *auth.TokenClaims) bool {
+	redis := database.GetRedis()
+	if redis == nil {
+		return false
+	}
+	for _, tokenID := range claims.TokenIDs() {
+		if blacklisted, err := redis.IsTokenBlacklisted(context.Background(), tokenID); err == nil && blacklisted {
+			return true
 		}
+	}
+	if claims.UserID != "" && claims.IssuedAt != nil {
+		revokedAt, err := redis.UserSessionsRevokedAt(context.Background(), claims.UserID)
+		if err == nil && !claims.IssuedAt.After(revokedAt) {
+			return true
+		}
+	}
+	return false
+}
 
+// RequireUnscopedToken rejects tokens obtained by token exchange, which are limited
+// to the permissions in their scope, on routes that do not check a permission
+func RequireUnscopedToken() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(GetScopes(c)) > 0 {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "Access denied: scoped tokens cannot be used for this endpoint",
+					"code":    "SCOPED_TOKEN_NOT_ALLOWED",
+				},
+			})
+		}
 		return c.Next()
 	}
 }
@@ -197,34 +245,53 @@ func RequireTenant() fiber.Handler {
 	}
 }
 
-// IsolateTenant middleware ensures users can only access resources in their tenant
+// IsolateTenant middleware ensures users can only access resources in their
+// tenant, unless their token has the platform scope
 func IsolateTenant() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get user's tenant ID from JWT claims
-		userTenantID := GetTenantID(c)
-		if userTenantID == "" {
-			// If no user tenant, allow (might be super admin)
-			return c.Next()
+		if !TenantAllowed(c) {
+			return tenantIsolationViolation(c)
 		}
+		return c.Next()
+	}
+}
 
-		// Get requested tenant ID from URL params or body
-		requestedTenantID := c.Params("tenantId")
-
-		// If tenant ID is in the request, verify it matches user's tenant
-		if requestedTenantID != "" && requestedTenantID != userTenantID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"message": "Access denied: cannot access resources from another tenant",
-					"code":    "TENANT_ISOLATION_VIOLATION",
-				},
-			})
+// RequirePlatformScope rejects tokens without the platform scope, on routes
+// operating across tenants such as listing all tenants
+func RequirePlatformScope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Break-glass access is granted without consulting roles
+		if GetBreakGlass(c) != nil || IsPlatformScope(c) {
+			return c.Next()
 		}
-
-		return c.Next()
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Access denied: this endpoint operates across tenants and requires a platform-scoped token",
+				"code":    "PLATFORM_SCOPE_REQUIRED",
+			},
+		})
 	}
 }
 
+// TenantAllowed reports whether the request may access the tenant of its
+// tenantId route parameter: its own tenant, or any tenant with the platform scope
+func TenantAllowed(c *fiber.Ctx) bool {
+	requestedTenantID := c.Params("tenantId")
+	return requestedTenantID == "" || requestedTenantID == GetTenantID(c) || IsPlatformScope(c)
+}
+
+// tenantIsolationViolation rejects a request for another tenant's resources
+func tenantIsolationViolation(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": "Access denied: cannot access resources from another tenant",
+			"code":    "TENANT_ISOLATION_VIOLATION",
+		},
+	})
+}
+
 // GetUserID helper to extract user ID from context
 func GetUserID(c *fiber.Ctx) string {
 	userID, _ := c.Locals("userID").(string)
@@ -249,8 +316,67 @@ func GetRoles(c *fiber.Ctx) []string {
 	return roles
 }
 
+// GetPermissions helper to extract the permissions embedded in the access
+// token from context, nil when the token does not embed them
+func GetPermissions(c *fiber.Ctx) []string {
+	permissions, _ := c.Locals("permissions").([]string)
+	return permissions
+}
+
 // GetTokenID helper to extract token ID from context
 func GetTokenID(c *fiber.Ctx) string {
 	tokenID, _ := c.Locals("tokenID").(string)
 	return tokenID
 }
+
+// GetScopes helper to extract the scopes of an exchanged token from context
+func GetScopes(c *fiber.Ctx) []string {
+	scopes, _ := c.Locals("scopes").([]string)
+	return scopes
+}
+
+// ScopeAllows reports whether the request's token may be used for the permission
+// on a resource, i.e. it has no scope or the permission is in its scope
+func ScopeAllows(c *fiber.Ctx, resource, action string) bool {
+	return auth.ScopeAllows(GetScopes(c), resource+"."+action)
+}
+
+// GetAccessScope helper to extract the access scope of the token from context,
+// platform or tenant. Tokens without a scope are tenant-scoped.
+func GetAccessScope(c *fiber.Ctx) string {
+	if scope, _ := c.Locals("accessScope").(string); scope == auth.AccessScopePlatform {
+		return auth.AccessScopePlatform
+	}
+	return auth.AccessScopeTenant
+}
+
+// IsPlatformScope reports whether the request's token operates across tenants
+func IsPlatformScope(c *fiber.Ctx) bool {
+	return GetAccessScope(c) == auth.AccessScopePlatform
+}
+
+// GetClientID helper to extract the OAuth client of a client credentials token from context
+func GetClientID(c *fiber.Ctx) string {
+	clientID, _ := c.Locals("clientID").(string)
+	return clientID
+}
+
+// ClientAllows reports whether the request's OAuth client token may be used for
+// the permission on a resource of a tenant. Client tokens have no roles for OPA
+// to evaluate, so they are authorized by their scope within their own tenant.
+func ClientAllows(c *fiber.Ctx, resource, action, tenantID string) bool {
+	scopes := GetScopes(c)
+	if GetClientID(c) == "" || len(scopes) == 0 {
+		return false
+	}
+	if tenantID != "" && tenantID != GetTenantID(c) {
+		return false
+	}
+	return auth.ScopeAllows(scopes, resource+"."+action)
+}
+
+// GetSessionAttributes helper to extract login hook session attributes from context
+func GetSessionAttributes(c *fiber.Ctx) map[string]interface{} {
+	attributes, _ := c.Locals("sessionAttributes").(map[string]interface{})
+	return attributes
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLogRules decides which routes have their bodies logged
+type BodyLogRules interface {
+	// LogBodies reports whether the bodies of a route are logged, by its
+	// method and registered path, e.g. /v1/users/:userId
+	LogBodies(method, route string) bool
+}
+
+// sensitivePatterns match credentials in text whatever their key, in string
+// values and in bodies that are neither JSON nor forms. Pairs of a sensitive
+// key and its value keep the key, so the log shows what was redacted.
+var sensitivePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), redactedValue},
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), redactedValue},
+	{regexp.MustCompile(`(?i)\b(bearer|basic) [A-Za-z0-9._~+/=-]+`), "${1} " + redactedValue},
+	{regexp.MustCompile(`(?i)("[a-z_-]*(?:password|secret|token|key|credential)[a-z_-]*"\s*:\s*)"(?:\\.|[^"\\])*"`), `${1}"` + redactedValue + `"`},
+	{regexp.MustCompile(`(?i)\b([a-z_-]*(?:password|secret|token|key|credential)[a-z_-]*=)[^&\s"']+`), "${1}" + redactedValue},
+}
+
+// BodyLogger logs the request and response bodies of the routes rules select,
+// for debugging. Credentials are redacted from them: the values of sensitive
+// JSON and form keys, such as passwords, tokens, refresh tokens and API keys,
+// and values that look like tokens or private keys under any key. Each body
+// is logged up to maxBytes. It must run inside compression, so it sees the
+// uncompressed response.
+func BodyLogger(rules BodyLogRules, maxBytes int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			// Render the error now so its body is logged
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// After the handler ran, the route is the one that handled the request
+		route := c.Route().Path
+		if !rules.LogBodies(c.Method(), route) {
+			return nil
+		}
+		log.Printf("%d %s %s route=%s request=%s response=%s",
+			c.Response().StatusCode(), c.Method(), c.Path(), route,
+			redactBody(c.Get(fiber.HeaderContentType), c.Body(), maxBytes),
+			responseBody(c, maxBytes))
+		return nil
+	}
+}
+
+// responseBody returns the response body for logging, without reading streams
+func responseBody(c *fiber.Ctx, maxBytes int) string {
+	resp := c.Response()
+	if resp.IsBodyStream() {
+		return "[stream]"
+	}
+	return redactBody(string(resp.Header.ContentType()), resp.Body(), maxBytes)
+}
+
+// redactBody returns a body for logging with credentials redacted, truncated
+// to maxBytes
+func redactBody(contentType string, body []byte, maxBytes int) string {
+	if len(body) == 0 {
+		return "-"
+	}
+
+	var redacted []byte
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		var payload interface{}
+		if err := json.Unmarshal(body, &payload); err == nil {
+			redacted, _ = json.Marshal(redactValues(redact(payload)))
+		}
+	case mediaType == fiber.MIMEApplicationForm:
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for key, entries := range values {
+				for i := range entries {
+					if isSensitiveKey(key) {
+						entries[i] = redactedValue
+					} else {
+						entries[i] = redactString(entries[i])
+					}
+				}
+			}
+			redacted = []byte(strings.ReplaceAll(values.Encode(), url.QueryEscape(redactedValue), redactedValue))
+		}
+	}
+	if redacted == nil {
+		if !utf8.Valid(body) {
+			return "[binary]"
+		}
+		redacted = []byte(redactString(string(body)))
+	}
+
+	if len(redacted) > maxBytes {
+		return string(redacted[:maxBytes]) + "...[truncated]"
+	}
+	return string(redacted)
+}
+
+// redactValues redacts credentials from the strings of a decoded payload
+func redactValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			v[key] = redactValues(nested)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValues(v[i])
+		}
+		return v
+	case string:
+		return redactString(v)
+	default:
+		return v
+	}
+}
+
+// redactString replaces what looks like a credential in text
+func redactString(s string) string {
+	for _, p := range sensitivePatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
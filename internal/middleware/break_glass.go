@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/datatypes"
+)
+
+// BreakGlassAuthenticator verifies break-glass tokens and reports their use
+type BreakGlassAuthenticator interface {
+	AdminActionRecorder
+
+	// VerifyBreakGlassToken returns the claims of a valid break-glass token
+	VerifyBreakGlassToken(tokenString string) (*auth.TokenClaims, error)
+
+	// PageBreakGlass alerts operators the first time a break-glass token is used
+	PageBreakGlass(ctx context.Context, claims *auth.TokenClaims, ip string)
+}
+
+// BreakGlassMiddleware accepts break-glass tokens ahead of the authentication
+// middleware. Requests made with one act as the token's emergency user without
+// consulting OPA, page operators on the token's first use and are each recorded
+// in the audit log as a breakglass.request admin action. Other requests are left
+// to the authentication middleware.
+func BreakGlassMiddleware(authenticator BreakGlassAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString, err := auth.ExtractTokenFromHeader(c.Get("Authorization"))
+		if err != nil {
+			return c.Next()
+		}
+		claims, err := authenticator.VerifyBreakGlassToken(tokenString)
+		if err != nil {
+			return c.Next()
+		}
+
+		if isRevoked(claims) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "Token has been revoked",
+					"code":    "TOKEN_REVOKED",
+				},
+			})
+		}
+
+		authenticator.PageBreakGlass(c.UserContext(), claims, c.IP())
+
+		c.Locals("userID", claims.UserID)
+		c.Locals("tenantID", claims.TenantID)
+		c.Locals("tokenID", claims.ID)
+		c.Locals("userStatus", models.UserStatusActive)
+		c.Locals("breakGlass", claims)
+
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			// Render the error now so its status is recorded
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				return handlerErr
+			}
+		}
+
+		userID, _ := uuid.Parse(claims.UserID)
+		tenantID, _ := uuid.Parse(claims.TenantID)
+		statusCode := c.Response().StatusCode()
+		entry := &models.AuditLog{
+			TenantID:   tenantID,
+			UserID:     &userID,
+			Action:     "breakglass.request",
+			Resource:   "break_glass",
+			IPAddress:  c.IP(),
+			UserAgent:  c.Get(fiber.HeaderUserAgent),
+			Method:     c.Method(),
+			Path:       truncate(c.OriginalURL(), maxAuditedPathLength),
+			Status:     auditStatus(statusCode),
+			StatusCode: statusCode,
+			Duration:   time.Since(start).Milliseconds(),
+		}
+		metadata, _ := json.Marshal(breakGlassDetails(claims))
+		entry.Metadata = datatypes.JSON(metadata)
+
+		// Like admin actions, a failure to record the request does not fail it
+		if err := authenticator.RecordAdminAction(c.UserContext(), entry); err != nil {
+			log.Printf("Failed to audit break-glass request %s %s: %v", c.Method(), c.Path(), err)
+		}
+		return nil
+	}
+}
+
+// GetBreakGlass returns the claims of the break-glass token the request was
+// made with, or nil for other requests
+func GetBreakGlass(c *fiber.Ctx) *auth.TokenClaims {
+	claims, _ := c.Locals("breakGlass").(*auth.TokenClaims)
+	return claims
+}
+
+// breakGlassDetails describes a break-glass token for the audit log
+func breakGlassDetails(claims *auth.TokenClaims) map[string]interface{} {
+	details := map[string]interface{}{
+		"operator": claims.Operator,
+		"reason":   claims.Reason,
+		"tokenId":  claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		details["expiresAt"] = claims.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return details
+}
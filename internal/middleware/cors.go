@@ -1,30 +1,79 @@
 package middleware
 
 import (
+	"slices"
+	"strings"
+	"sync/atomic"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/techsavvyash/heimdall/internal/config"
 )
 
-// CORS returns a configured CORS middleware
-func CORS(cfg *config.Config) fiber.Handler {
-	return cors.New(cors.Config{
-		AllowOrigins:     joinStrings(cfg.Server.AllowedOrigins, ","),
-		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Tenant-ID",
-		AllowCredentials: true,
-		ExposeHeaders:    "Content-Length,X-Request-ID",
-		MaxAge:           3600,
-	})
+// TenantOrigins resolves the CORS origins tenants allow
+type TenantOrigins interface {
+	// OriginAllowed reports whether a tenant allows an origin
+	OriginAllowed(origin string) bool
 }
 
-func joinStrings(slice []string, sep string) string {
-	if len(slice) == 0 {
-		return ""
+// CORS returns a configured CORS middleware admitting the configured origins
+// and the origins tenants allow in their settings. Configured origins may match
+// subdomains, as in https://*.example.com. A configured "*" admits any origin,
+// without credentials. Reloaded origins apply to subsequent requests.
+func CORS(settings *config.Reloader, tenants TenantOrigins) fiber.Handler {
+	var handler atomic.Pointer[fiber.Handler]
+	build := func(cfg *config.Config) {
+		h := newCORS(cfg, tenants)
+		handler.Store(&h)
+	}
+	build(settings.Current())
+	settings.OnReload(build)
+
+	return func(c *fiber.Ctx) error {
+		return (*handler.Load())(c)
+	}
+}
+
+// newCORS returns a CORS middleware admitting the origins of a configuration
+func newCORS(cfg *config.Config, tenants TenantOrigins) fiber.Handler {
+	corsConfig := cors.Config{
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:  "Origin,Content-Type,Accept,Authorization,X-Tenant-ID,If-Match,If-None-Match,X-Action-Nonce,X-Device-ID",
+		ExposeHeaders: "Content-Length,X-Request-ID,ETag,X-RateLimit-Limit,X-RateLimit-Remaining,Retry-After",
+		MaxAge:        3600,
+	}
+
+	if slices.Contains(cfg.Server.AllowedOrigins, "*") {
+		corsConfig.AllowOrigins = "*"
+		return cors.New(corsConfig)
+	}
+
+	configured := make([]string, 0, len(cfg.Server.AllowedOrigins))
+	for _, origin := range cfg.Server.AllowedOrigins {
+		configured = append(configured, strings.ToLower(strings.TrimSuffix(origin, "/")))
 	}
-	result := slice[0]
-	for i := 1; i < len(slice); i++ {
-		result += sep + slice[i]
+
+	corsConfig.AllowCredentials = true
+	corsConfig.AllowOriginsFunc = func(origin string) bool {
+		for _, pattern := range configured {
+			if originMatches(pattern, origin) {
+				return true
+			}
+		}
+		return tenants != nil && tenants.OriginAllowed(origin)
+	}
+	return cors.New(corsConfig)
+}
+
+// originMatches reports whether an origin matches a configured origin, which
+// may have a wildcard subdomain
+func originMatches(pattern, origin string) bool {
+	scheme, host, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return pattern == origin
 	}
-	return result
+	prefix := scheme + "://"
+	suffix := "." + host
+	return strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+		len(origin) > len(prefix)+len(suffix)
 }
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+)
+
+// ErrorHandler is the central Fiber error handler. Typed errors are mapped to
+// their HTTP status and stable code; anything else becomes a generic 500 so
+// internal details are never leaked to clients.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		status := apperrors.HTTPStatus(appErr)
+		if status >= fiber.StatusInternalServerError {
+			log.Printf("%s %s failed: %v", c.Method(), c.Path(), err)
+		}
+
+		body := fiber.Map{
+			"message": appErr.Message,
+			"code":    appErr.Code,
+		}
+		if appErr.Details != nil {
+			body["details"] = appErr.Details
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error":   body,
+		})
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": fiberErr.Message,
+				"code":    fiberErrorCode(fiberErr.Code),
+			},
+		})
+	}
+
+	log.Printf("%s %s failed: %v", c.Method(), c.Path(), err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": "Internal server error",
+			"code":    "INTERNAL_ERROR",
+		},
+	})
+}
+
+// fiberErrorCode returns a stable code for errors raised by Fiber itself
+func fiberErrorCode(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return "INVALID_REQUEST"
+	case fiber.StatusNotFound:
+		return "ROUTE_NOT_FOUND"
+	case fiber.StatusMethodNotAllowed:
+		return "METHOD_NOT_ALLOWED"
+	case fiber.StatusRequestEntityTooLarge:
+		return "REQUEST_TOO_LARGE"
+	case fiber.StatusUnsupportedMediaType:
+		return "UNSUPPORTED_MEDIA_TYPE"
+	case fiber.StatusTooManyRequests:
+		return "RATE_LIMIT_EXCEEDED"
+	default:
+		return "INTERNAL_ERROR"
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag answers conditional GET requests. Responses of at least minSize bytes
+// without an ETag of their handler get a weak ETag hashed from their body, so
+// it also holds for their compressed forms. A request whose If-None-Match lists
+// the response's ETag is answered with 304 Not Modified and no body, sparing
+// clients polling large lists from downloading them again.
+func ETag(minSize int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		method := c.Method()
+		resp := c.Response()
+		if (method != fiber.MethodGet && method != fiber.MethodHead) || resp.StatusCode() != fiber.StatusOK {
+			return nil
+		}
+
+		etag := string(resp.Header.Peek(fiber.HeaderETag))
+		if etag == "" {
+			// Responses that must not be stored are never revalidated
+			if resp.IsBodyStream() || strings.Contains(string(resp.Header.Peek(fiber.HeaderCacheControl)), "no-store") {
+				return nil
+			}
+			body := resp.Body()
+			if len(body) == 0 || len(body) < minSize {
+				return nil
+			}
+			sum := sha256.Sum256(body)
+			etag = `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			c.Set(fiber.HeaderETag, etag)
+		}
+
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Context().ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// etagMatches reports whether an If-None-Match header value lists the ETag,
+// using the weak comparison: W/"x" matches "x"
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
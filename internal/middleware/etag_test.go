@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+)
+
+func TestETag(t *testing.T) {
+	large := strings.Repeat(`{"id":"user","email":"user@acme.com"},`, 64)
+
+	// Registered as the server does, compressing after the ETag is computed
+	app := fiber.New()
+	app.Use(compress.New(compress.Config{Level: compress.LevelBestSpeed}))
+	app.Use(ETag(1024))
+	app.Get("/large", func(c *fiber.Ctx) error { return c.SendString(large) })
+	app.Post("/large", func(c *fiber.Ctx) error { return c.SendString(large) })
+	app.Get("/small", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/missing", func(c *fiber.Ctx) error { return c.Status(fiber.StatusNotFound).SendString(large) })
+	app.Get("/no-store", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.SendString(large)
+	})
+	app.Get("/own", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderETag, `"v1"`)
+		return c.SendString("policy")
+	})
+
+	request := func(method, path string, headers map[string]string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	resp, body := request("GET", "/large", nil)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if resp.StatusCode != http.StatusOK || body != large || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("Expected the body with a weak ETag, got %d %q", resp.StatusCode, etag)
+	}
+
+	// Compressed responses carry the ETag of the uncompressed body
+	resp, body = request("GET", "/large", map[string]string{fiber.HeaderAcceptEncoding: "gzip"})
+	if resp.Header.Get(fiber.HeaderContentEncoding) != "gzip" || resp.Header.Get(fiber.HeaderETag) != etag || len(body) >= len(large) {
+		t.Errorf("Expected a gzipped body with ETag %s, got %q with %s", etag, resp.Header.Get(fiber.HeaderContentEncoding), resp.Header.Get(fiber.HeaderETag))
+	}
+
+	notModified := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+	}{
+		{"matching tag", "GET", "/large", map[string]string{fiber.HeaderIfNoneMatch: etag}},
+		{"strong form of the weak tag", "GET", "/large", map[string]string{fiber.HeaderIfNoneMatch: strings.TrimPrefix(etag, "W/")}},
+		{"tag in a list", "GET", "/large", map[string]string{fiber.HeaderIfNoneMatch: `"other", ` + etag}},
+		{"any tag", "GET", "/large", map[string]string{fiber.HeaderIfNoneMatch: "*"}},
+		{"compressed", "GET", "/large", map[string]string{fiber.HeaderIfNoneMatch: etag, fiber.HeaderAcceptEncoding: "gzip"}},
+		{"head request", "HEAD", "/large", map[string]string{fiber.HeaderIfNoneMatch: etag}},
+		{"weak form of the handler's tag", "GET", "/own", map[string]string{fiber.HeaderIfNoneMatch: `W/"v1"`}},
+	}
+	for _, tt := range notModified {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := request(tt.method, tt.path, tt.headers)
+			if resp.StatusCode != http.StatusNotModified || body != "" {
+				t.Errorf("Expected 304 without a body, got %d with %d bytes", resp.StatusCode, len(body))
+			}
+			if resp.Header.Get(fiber.HeaderETag) == "" {
+				t.Error("Expected the ETag on the 304")
+			}
+		})
+	}
+
+	untagged := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"body below the minimum size", "GET", "/small", http.StatusOK},
+		{"error response", "GET", "/missing", http.StatusNotFound},
+		{"response that must not be stored", "GET", "/no-store", http.StatusOK},
+		{"other method", "POST", "/large", http.StatusOK},
+	}
+	for _, tt := range untagged {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := request(tt.method, tt.path, map[string]string{fiber.HeaderIfNoneMatch: "*"})
+			if resp.StatusCode != tt.status || body == "" || resp.Header.Get(fiber.HeaderETag) != "" {
+				t.Errorf("Expected %d with the body and no ETag, got %d with %d bytes and %q", tt.status, resp.StatusCode, len(body), resp.Header.Get(fiber.HeaderETag))
+			}
+		})
+	}
+
+	if resp, body := request("GET", "/large", map[string]string{fiber.HeaderIfNoneMatch: `W/"other"`}); resp.StatusCode != http.StatusOK || body != large {
+		t.Errorf("Expected the body for another tag, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// cidrIPAccess admits the addresses of one CIDR range and records the tenants
+// and IPs it was asked about
+type cidrIPAccess struct {
+	allowed *net.IPNet
+	err     error
+	emails  map[string]string
+	seen    []string
+	tenants []string
+}
+
+func (a *cidrIPAccess) IPAccess(_ context.Context, tenantID, ip string) (bool, map[string]interface{}, error) {
+	a.seen = append(a.seen, ip)
+	a.tenants = append(a.tenants, tenantID)
+	if a.err != nil {
+		return false, nil, a.err
+	}
+	return a.allowed.Contains(net.ParseIP(ip)), map[string]interface{}{"allow": []string{a.allowed.String()}}, nil
+}
+
+func (a *cidrIPAccess) EmailTenant(_ context.Context, email string) (string, error) {
+	return a.emails[email], nil
+}
+
+func TestIPAccessMiddleware(t *testing.T) {
+	_, allowed, _ := net.ParseCIDR("203.0.113.0/24")
+
+	// Requests of app.Test come from 0.0.0.0
+	newApp := func(server *config.ServerConfig, tenants TenantIPAccess) *fiber.App {
+		fc := fiber.Config{ErrorHandler: ErrorHandler}
+		TrustProxies(&fc, server)
+		app := fiber.New(fc)
+		app.Use(IPAccessMiddleware(tenants))
+		app.Get("/", func(c *fiber.Ctx) error {
+			if c.Locals("ipAccess") == nil {
+				t.Error("Expected the IP access lists in the locals")
+			}
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+		return app
+	}
+	request := func(app *fiber.App, forwardedFor string) (int, string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Tenant-ID", "tenant")
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		var body struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Error.Code
+	}
+	proxied := &config.ServerConfig{TrustedProxies: []string{"0.0.0.0"}, ProxyHeader: "X-Forwarded-For"}
+	direct := &config.ServerConfig{TrustedProxies: []string{"10.0.0.0/8"}, ProxyHeader: "X-Forwarded-For"}
+
+	tests := []struct {
+		name         string
+		server       *config.ServerConfig
+		forwardedFor string
+		status       int
+		code         string
+		ip           string
+	}{
+		{"proxied allowed client", proxied, "203.0.113.7, 10.0.0.2", http.StatusNoContent, "", "203.0.113.7"},
+		{"proxied denied client", proxied, "198.51.100.7", http.StatusForbidden, "IP_NOT_ALLOWED", "198.51.100.7"},
+		{"invalid header entries skipped", proxied, "unknown, 203.0.113.8", http.StatusNoContent, "", "203.0.113.8"},
+		{"spoofed header from untrusted peer", direct, "203.0.113.7", http.StatusForbidden, "IP_NOT_ALLOWED", "0.0.0.0"},
+		{"no trusted proxies", &config.ServerConfig{ProxyHeader: "X-Forwarded-For"}, "203.0.113.7", http.StatusForbidden, "IP_NOT_ALLOWED", "0.0.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenants := &cidrIPAccess{allowed: allowed}
+			status, code := request(newApp(tt.server, tenants), tt.forwardedFor)
+			if status != tt.status || code != tt.code {
+				t.Errorf("Expected %d %q, got %d %q", tt.status, tt.code, status, code)
+			}
+			if len(tenants.seen) != 1 || tenants.seen[0] != tt.ip {
+				t.Errorf("Expected the lists to be checked for %s, got %v", tt.ip, tenants.seen)
+			}
+		})
+	}
+
+	// Unauthenticated requests are checked against the tenant their body names
+	t.Run("without token", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			contentType string
+			body        string
+			tenant      string
+		}{
+			{"tenant in body", "application/json", `{"tenantId":"acme","email":"user@other.com"}`, "acme"},
+			{"user's email", "application/json", `{"email":"user@acme.com","password":"guess"}`, "acme"},
+			{"unknown email", "application/json", `{"email":"new@acme.com"}`, ""},
+			{"no tenant or email", "application/json", `{"refreshToken":"token"}`, ""},
+			{"malformed body", "application/json", `{"email":`, ""},
+			{"other content type", "application/x-www-form-urlencoded", "email=user%40acme.com", ""},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tenants := &cidrIPAccess{allowed: allowed, emails: map[string]string{"user@acme.com": "acme"}}
+				app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+				app.Use(IPAccessMiddleware(tenants))
+				app.Post("/v1/auth/login", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+				req := httptest.NewRequest("POST", "/v1/auth/login", strings.NewReader(tt.body))
+				req.Header.Set("Content-Type", tt.contentType)
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatalf("Request failed: %v", err)
+				}
+				if tt.tenant == "" {
+					// Requests naming no tenant, such as sign-ups, cannot be checked
+					if resp.StatusCode != http.StatusNoContent || len(tenants.seen) != 0 {
+						t.Errorf("Expected the request to pass unchecked, got %d after checking %v", resp.StatusCode, tenants.tenants)
+					}
+					return
+				}
+				if resp.StatusCode != http.StatusForbidden || len(tenants.tenants) != 1 || tenants.tenants[0] != tt.tenant {
+					t.Errorf("Expected the lists of %s to reject 0.0.0.0, got %d after checking %v", tt.tenant, resp.StatusCode, tenants.tenants)
+				}
+			})
+		}
+	})
+
+	t.Run("evaluation failure", func(t *testing.T) {
+		status, code := request(newApp(proxied, &cidrIPAccess{allowed: allowed, err: errors.New("redis down")}), "203.0.113.7")
+		if status != http.StatusInternalServerError || code != "IP_ACCESS_EVALUATION_FAILED" {
+			t.Errorf("Expected IP access lists to fail closed, got %d %q", status, code)
+		}
+	})
+}
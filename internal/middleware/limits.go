@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// Route classes with their own request timeouts and body limits. Routes of no
+// other class are limited as LimitRouteDefault.
+const (
+	LimitRouteDefault = "default"
+	LimitRouteAuth    = "auth"
+	LimitRouteUpload  = "upload"
+)
+
+// limitRoutes maps path prefixes to the route class they are limited as
+var limitRoutes = []struct {
+	prefix string
+	route  string
+}{
+	{"/v1/auth/login", LimitRouteAuth},
+	{"/v1/auth/register", LimitRouteAuth},
+	{"/v1/auth/refresh", LimitRouteAuth},
+	{"/v1/auth/magic-link", LimitRouteAuth},
+	{"/v1/auth/password/reset", LimitRouteAuth},
+	{"/v1/oauth/token", LimitRouteAuth},
+	{"/v1/oauth/device/code", LimitRouteAuth},
+	{"/v1/policies", LimitRouteUpload},
+	{"/v1/bundles", LimitRouteUpload},
+	{"/v1/logs", LimitRouteUpload},
+	{"/v1/webhooks/git/", LimitRouteUpload},
+}
+
+// LimitRoute returns the route class of a request path
+func LimitRoute(path string) string {
+	for _, r := range limitRoutes {
+		if strings.HasPrefix(path, r.prefix) {
+			return r.route
+		}
+	}
+	return LimitRouteDefault
+}
+
+// MaxBodyLimit returns the largest body limit of any route class, which the
+// server reads request bodies up to
+func MaxBodyLimit(cfg *config.ServerConfig) int {
+	limit := cfg.BodyLimit
+	for _, routeLimit := range cfg.RouteBodyLimits {
+		limit = max(limit, routeLimit)
+	}
+	return limit
+}
+
+// RequestLimits enforces the body limit and timeout of each request's route
+// class with the current configuration. Bodies over the limit are rejected
+// with 413 before any handler runs. Handlers get a context ending at the
+// timeout from c.UserContext() and pass it on, so slow downstream calls, e.g.
+// to FusionAuth, are cancelled; a request failing after its timeout is answered
+// with 504.
+func RequestLimits(settings *config.Reloader) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := settings.Current()
+		route := LimitRoute(c.Path())
+
+		limit := cfg.Server.BodyLimit
+		if routeLimit, ok := cfg.Server.RouteBodyLimits[route]; ok {
+			limit = routeLimit
+		}
+		if limit > 0 && len(c.Request().Body()) > limit {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds the limit of %d bytes", limit))
+		}
+
+		timeout := cfg.Server.RequestTimeout
+		if routeTimeout, ok := cfg.Server.RouteTimeouts[route]; ok {
+			timeout = routeTimeout
+		}
+		if timeout <= 0 {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && (err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError) {
+			cancel()
+			return apperrors.Timeout("REQUEST_TIMEOUT", fmt.Sprintf("The request did not complete within %s", timeout)).WithCause(err)
+		}
+		if c.Response().IsBodyStream() {
+			// Streamed bodies may still be read with the context, e.g. bundles
+			// from object storage, so it ends at its deadline rather than now
+			time.AfterFunc(timeout, cancel)
+			return err
+		}
+		cancel()
+		return err
+	}
+}
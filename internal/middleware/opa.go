@@ -1,45 +1,81 @@
 package middleware
 
 import (
+	"errors"
+	"log"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/opa"
 )
 
-// RequirePermissionOPA middleware checks if the user has permission using OPA
-func RequirePermissionOPA(evaluator *opa.Evaluator, resource, action string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		userID := GetUserID(c)
-		tenantID := GetTenantID(c)
-		roles := GetRoles(c)
+// evaluationFailureStatus returns 503 when OPA's circuit breaker is open, so
+// clients can tell OPA is unavailable and back off, and 500 otherwise
+func evaluationFailureStatus(err error) int {
+	if errors.Is(err, opa.ErrCircuitOpen) {
+		return fiber.StatusServiceUnavailable
+	}
+	return fiber.StatusInternalServerError
+}
 
-		if userID == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"message": "User not authenticated",
-					"code":    "UNAUTHORIZED",
-				},
-			})
-		}
+// recordDirectives adds the obligations and advice of a decision allowing the
+// request to those of earlier decisions, for the handler. Handlers must fulfil
+// the obligations they act on, e.g. masking fields, before responding.
+func recordDirectives(c *fiber.Ctx, obligations, advice []opa.Directive) {
+	if len(obligations) > 0 {
+		c.Locals("opaObligations", append(GetObligations(c), obligations...))
+	}
+	if len(advice) > 0 {
+		c.Locals("opaAdvice", append(GetAdvice(c), advice...))
+	}
+}
 
-		// Get resource ID from route params if available
-		resourceID := c.Params("id")
-		if resourceID == "" {
-			resourceID = c.Params(resource + "Id")
-		}
+// GetObligations returns the obligations of the policy decisions that allowed
+// the request, nil when there are none
+func GetObligations(c *fiber.Ctx) []opa.Directive {
+	obligations, _ := c.Locals("opaObligations").([]opa.Directive)
+	return obligations
+}
 
-		allowed, err := evaluator.CanAccessResource(
-			c.Context(),
-			userID,
-			tenantID,
-			roles,
-			resource,
-			resourceID,
-			action,
-		)
+// GetAdvice returns the advice of the policy decisions that allowed the
+// request, nil when there is none
+func GetAdvice(c *fiber.Ctx) []opa.Directive {
+	advice, _ := c.Locals("opaAdvice").([]opa.Directive)
+	return advice
+}
+
+// GetFieldMask returns the fields the policy decisions that allowed the request
+// masked, which handlers remove from the resources they return
+func GetFieldMask(c *fiber.Ctx) []string {
+	return opa.FieldMask(GetObligations(c))
+}
+
+// rowFilterExemptRoles see every row of the lists of their tenant, so lists
+// are not filtered by policy for them
+var rowFilterExemptRoles = map[string]bool{"admin": true, auth.PlatformRole: true}
+
+// FilterRowsOPA derives the rows of a resource type the user may read from
+// the default policy when row filters are enabled, for list handlers to query
+// with GetRowFilter. Admins, break-glass access and OAuth clients are not
+// filtered.
+func FilterRowsOPA(evaluator *opa.Evaluator, resource string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !evaluator.RowFiltersEnabled() || GetBreakGlass(c) != nil || GetClientID(c) != "" {
+			return c.Next()
+		}
+		for _, role := range GetRoles(c) {
+			if rowFilterExemptRoles[role] {
+				return c.Next()
+			}
+		}
 
+		builder := opa.NewContextBuilderFromFiber(c)
+		builder.WithResource(resource, "")
+		builder.WithResourceTenant(GetTenantID(c))
+		builder.WithAction("read")
+		filter, err := evaluator.RowFilter(c.UserContext(), builder)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(evaluationFailureStatus(err)).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
 					"message": "Failed to evaluate authorization policy",
@@ -49,27 +85,170 @@ func RequirePermissionOPA(evaluator *opa.Evaluator, resource, action string) fib
 			})
 		}
 
-		if !allowed {
+		c.Locals("opaRowFilter", filter)
+		return c.Next()
+	}
+}
+
+// GetRowFilter returns the rows of the list the user may read, nil when they
+// may read every row
+func GetRowFilter(c *fiber.Ctx) *opa.RowFilter {
+	filter, _ := c.Locals("opaRowFilter").(*opa.RowFilter)
+	if filter == nil || filter.Unrestricted {
+		return nil
+	}
+	return filter
+}
+
+// RequirePermissionOPA middleware checks if the user has permission using OPA
+func RequirePermissionOPA(evaluator *opa.Evaluator, resource, action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return authorizePermission(c, evaluator, resource, action, c.Next)
+	}
+}
+
+// RequireRoutePermissionsOPA middleware checks, using OPA, that the user has
+// every permission the admin_api system policy lists for the matched route.
+// Routes the policy does not list are denied.
+func RequireRoutePermissionsOPA(evaluator *opa.Evaluator, permissions *opa.RoutePermissions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route := c.Route()
+		required, ok := permissions.Lookup(route.Method, route.Path)
+		if !ok {
+			log.Printf("No permissions guard %s %s in the admin API policy, denying access", route.Method, route.Path)
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
-					"message": "Access denied: insufficient permissions",
+					"message": "Access denied: no permission is configured for this route",
 					"code":    "FORBIDDEN",
-					"required": fiber.Map{
-						"resource": resource,
-						"action":   action,
-					},
 				},
 			})
 		}
 
-		return c.Next()
+		// Each permission is checked in turn, the last one continuing to the
+		// handler. Routes needing none still get the tenant checks.
+		if len(required) == 0 {
+			if GetBreakGlass(c) != nil {
+				return c.Next()
+			}
+			if ok, err := authorizeTenant(c); !ok {
+				return err
+			}
+			return c.Next()
+		}
+		var check func(i int) error
+		check = func(i int) error {
+			if i == len(required) {
+				return c.Next()
+			}
+			return authorizePermission(c, evaluator, required[i].Resource, required[i].Action, func() error {
+				return check(i + 1)
+			})
+		}
+		return check(0)
+	}
+}
+
+// authorizeTenant rejects requests without a user or client, and requests for
+// another tenant's resources, which every guarded route does whatever
+// permissions it needs. It reports whether the request may continue.
+func authorizeTenant(c *fiber.Ctx) (bool, error) {
+	if GetUserID(c) == "" && GetClientID(c) == "" {
+		return false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "User not authenticated",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+	if !TenantAllowed(c) {
+		return false, tenantIsolationViolation(c)
+	}
+	// Client tokens never have platform scope
+	if tenantID := c.Params("tenantId"); GetClientID(c) != "" && tenantID != "" && tenantID != GetTenantID(c) {
+		return false, tenantIsolationViolation(c)
 	}
+	return true, nil
+}
+
+// authorizePermission checks if the user has a permission using OPA, calling
+// next when they do and rejecting the request otherwise
+func authorizePermission(c *fiber.Ctx, evaluator *opa.Evaluator, resource, action string, next func() error) error {
+	// Break-glass access is granted without consulting OPA
+	if GetBreakGlass(c) != nil {
+		return next()
+	}
+
+	userID := GetUserID(c)
+	tenantID := GetTenantID(c)
+	roles := GetRoles(c)
+
+	if ok, err := authorizeTenant(c); !ok {
+		return err
+	}
+	if GetClientID(c) != "" {
+		return authorizeClient(c, resource, action, next)
+	}
+	if !ScopeAllows(c, resource, action) {
+		return outOfScope(c, resource, action)
+	}
+
+	// Get resource ID from route params if available
+	resourceID := c.Params("id")
+	if resourceID == "" {
+		resourceID = c.Params(resource + "Id")
+	}
+
+	decision, err := evaluator.DecideResourceAccess(
+		c.UserContext(),
+		userID,
+		tenantID,
+		GetAccessScope(c),
+		roles,
+		GetPermissions(c),
+		resource,
+		resourceID,
+		action,
+	)
+
+	if err != nil {
+		return c.Status(evaluationFailureStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Failed to evaluate authorization policy",
+				"code":    "AUTHZ_EVALUATION_FAILED",
+				"details": err.Error(),
+			},
+		})
+	}
+
+	if !decision.Allow {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "Access denied: insufficient permissions",
+				"code":    "FORBIDDEN",
+				"required": fiber.Map{
+					"resource": resource,
+					"action":   action,
+				},
+			},
+		})
+	}
+
+	recordDirectives(c, decision.Obligations, decision.Advice)
+	return next()
 }
 
 // RequireDecisionOPA evaluates a custom policy path
 func RequireDecisionOPA(evaluator *opa.Evaluator, policyPath string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Break-glass access is granted without consulting OPA
+		if GetBreakGlass(c) != nil {
+			return c.Next()
+		}
+
 		builder := opa.NewContextBuilderFromFiber(c)
 
 		// Extract resource info from route if available
@@ -81,11 +260,30 @@ func RequireDecisionOPA(evaluator *opa.Evaluator, policyPath string) fiber.Handl
 		action := getActionFromMethod(c.Method())
 		builder.WithAction(action)
 
+		// Custom policies have no single permission, so scoped tokens need the
+		// permission on the route's resource type
+		if GetClientID(c) != "" {
+			return authorizeClient(c, c.Params("resourceType"), action, c.Next)
+		}
+		if len(GetScopes(c)) > 0 && (c.Params("resourceType") == "" || !ScopeAllows(c, c.Params("resourceType"), action)) {
+			return outOfScope(c, c.Params("resourceType"), action)
+		}
+
+		if err := builder.Enrich(c.UserContext(), evaluator.Enrichment()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "Failed to evaluate authorization policy",
+					"code":    "AUTHZ_EVALUATION_FAILED",
+					"details": err.Error(),
+				},
+			})
+		}
 		input := builder.Build()
 
-		decision, err := evaluator.EvaluateCustom(c.Context(), policyPath, input)
+		decision, err := evaluator.EvaluateCustom(c.UserContext(), policyPath, input)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(evaluationFailureStatus(err)).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
 					"message": "Failed to evaluate authorization policy",
@@ -104,6 +302,17 @@ func RequireDecisionOPA(evaluator *opa.Evaluator, policyPath string) fiber.Handl
 				allowed = allow
 			}
 		}
+		obligations, advice, err := opa.DecisionDirectives(decision)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "Failed to evaluate authorization policy",
+					"code":    "AUTHZ_EVALUATION_FAILED",
+					"details": err.Error(),
+				},
+			})
+		}
 
 		if !allowed {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -116,6 +325,8 @@ func RequireDecisionOPA(evaluator *opa.Evaluator, policyPath string) fiber.Handl
 			})
 		}
 
+		recordDirectives(c, obligations, advice)
+
 		// Store decision metadata in context for audit logging
 		c.Locals("opaDecisionID", decision.DecisionID)
 		if decision.Metrics != nil {
@@ -129,11 +340,16 @@ func RequireDecisionOPA(evaluator *opa.Evaluator, policyPath string) fiber.Handl
 // RequireOwnership checks if the user owns the resource or has admin rights
 func RequireOwnership(evaluator *opa.Evaluator, resourceType, ownerIDParam string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Break-glass access is granted without consulting OPA
+		if GetBreakGlass(c) != nil {
+			return c.Next()
+		}
+
 		userID := GetUserID(c)
 		tenantID := GetTenantID(c)
 		roles := GetRoles(c)
 
-		if userID == "" {
+		if userID == "" && GetClientID(c) == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -142,30 +358,41 @@ func RequireOwnership(evaluator *opa.Evaluator, resourceType, ownerIDParam strin
 				},
 			})
 		}
+		if !TenantAllowed(c) {
+			return tenantIsolationViolation(c)
+		}
 
 		// Get resource ID and owner ID from params
 		resourceID := c.Params("id")
 		ownerID := c.Params(ownerIDParam)
 
-		// If owner ID is not in params, it might need to be fetched from the database
-		// For now, we'll check if the resource ID matches the user ID for "own" resources
-		if ownerID == "" {
+		// The owner is fetched by the attribute sources of the resource type when
+		// there are any. Otherwise resources are assumed to be owned by the user
+		// whose ID they carry, as for "own" resources.
+		if ownerID == "" && !evaluator.Enrichment().Handles(resourceType) {
 			ownerID = resourceID
 		}
 
 		action := getActionFromMethod(c.Method())
+		if GetClientID(c) != "" {
+			return authorizeClient(c, resourceType, action, c.Next)
+		}
+		if !ScopeAllows(c, resourceType, action) {
+			return outOfScope(c, resourceType, action)
+		}
 
 		// Build context with ownership info
 		builder := opa.NewContextBuilder()
 		builder.WithUser(userID, "", roles)
+		builder.WithUserScope(GetAccessScope(c))
 		builder.WithResource(resourceType, resourceID)
 		builder.WithResourceOwner(ownerID)
 		builder.WithAction(action)
 		builder.WithTenant(tenantID, "", nil)
 
-		allowed, err := evaluator.EvaluateWithFullContext(c.Context(), builder)
+		decision, err := evaluator.DecideWithFullContext(c.UserContext(), builder)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(evaluationFailureStatus(err)).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
 					"message": "Failed to evaluate ownership policy",
@@ -175,7 +402,7 @@ func RequireOwnership(evaluator *opa.Evaluator, resourceType, ownerIDParam strin
 			})
 		}
 
-		if !allowed {
+		if !decision.Allow {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -185,6 +412,7 @@ func RequireOwnership(evaluator *opa.Evaluator, resourceType, ownerIDParam strin
 			})
 		}
 
+		recordDirectives(c, decision.Obligations, decision.Advice)
 		return c.Next()
 	}
 }
@@ -192,11 +420,16 @@ func RequireOwnership(evaluator *opa.Evaluator, resourceType, ownerIDParam strin
 // RequireAnyPermission checks if the user has any of the specified permissions
 func RequireAnyPermission(evaluator *opa.Evaluator, resource string, actions []string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Break-glass access is granted without consulting OPA
+		if GetBreakGlass(c) != nil {
+			return c.Next()
+		}
+
 		userID := GetUserID(c)
 		tenantID := GetTenantID(c)
 		roles := GetRoles(c)
 
-		if userID == "" {
+		if userID == "" && GetClientID(c) == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -205,16 +438,31 @@ func RequireAnyPermission(evaluator *opa.Evaluator, resource string, actions []s
 				},
 			})
 		}
+		if !TenantAllowed(c) {
+			return tenantIsolationViolation(c)
+		}
 
 		resourceID := c.Params("id")
 
 		// Check each action until one is allowed
 		for _, action := range actions {
-			allowed, err := evaluator.CanAccessResource(
-				c.Context(),
+			if GetClientID(c) != "" {
+				if ClientAllows(c, resource, action, c.Params("tenantId")) {
+					return c.Next()
+				}
+				continue
+			}
+			if !ScopeAllows(c, resource, action) {
+				continue
+			}
+
+			decision, err := evaluator.DecideResourceAccess(
+				c.UserContext(),
 				userID,
 				tenantID,
+				GetAccessScope(c),
 				roles,
+				GetPermissions(c),
 				resource,
 				resourceID,
 				action,
@@ -224,7 +472,8 @@ func RequireAnyPermission(evaluator *opa.Evaluator, resource string, actions []s
 				continue
 			}
 
-			if allowed {
+			if decision.Allow {
+				recordDirectives(c, decision.Obligations, decision.Advice)
 				return c.Next()
 			}
 		}
@@ -246,11 +495,16 @@ func RequireAnyPermission(evaluator *opa.Evaluator, resource string, actions []s
 // RequireAllPermissions checks if the user has all of the specified permissions
 func RequireAllPermissions(evaluator *opa.Evaluator, permissions []PermissionRequirement) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Break-glass access is granted without consulting OPA
+		if GetBreakGlass(c) != nil {
+			return c.Next()
+		}
+
 		userID := GetUserID(c)
 		tenantID := GetTenantID(c)
 		roles := GetRoles(c)
 
-		if userID == "" {
+		if userID == "" && GetClientID(c) == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -259,25 +513,40 @@ func RequireAllPermissions(evaluator *opa.Evaluator, permissions []PermissionReq
 				},
 			})
 		}
+		if !TenantAllowed(c) {
+			return tenantIsolationViolation(c)
+		}
 
 		// Check all permissions
 		for _, perm := range permissions {
+			if GetClientID(c) != "" {
+				if !ClientAllows(c, perm.Resource, perm.Action, c.Params("tenantId")) {
+					return outOfScope(c, perm.Resource, perm.Action)
+				}
+				continue
+			}
+			if !ScopeAllows(c, perm.Resource, perm.Action) {
+				return outOfScope(c, perm.Resource, perm.Action)
+			}
+
 			resourceID := c.Params("id")
 			if perm.ResourceIDParam != "" {
 				resourceID = c.Params(perm.ResourceIDParam)
 			}
 
-			allowed, err := evaluator.CanAccessResource(
-				c.Context(),
+			decision, err := evaluator.DecideResourceAccess(
+				c.UserContext(),
 				userID,
 				tenantID,
+				GetAccessScope(c),
 				roles,
+				GetPermissions(c),
 				perm.Resource,
 				resourceID,
 				perm.Action,
 			)
 
-			if err != nil || !allowed {
+			if err != nil || !decision.Allow {
 				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 					"success": false,
 					"error": fiber.Map{
@@ -290,12 +559,40 @@ func RequireAllPermissions(evaluator *opa.Evaluator, permissions []PermissionReq
 					},
 				})
 			}
+			recordDirectives(c, decision.Obligations, decision.Advice)
 		}
 
 		return c.Next()
 	}
 }
 
+// authorizeClient decides a request made with an OAuth client token by the
+// token's scope, confined to the client's tenant, calling next when allowed
+func authorizeClient(c *fiber.Ctx, resource, action string, next func() error) error {
+	if tenantID := c.Params("tenantId"); tenantID != "" && tenantID != GetTenantID(c) {
+		return tenantIsolationViolation(c)
+	}
+	if !ClientAllows(c, resource, action, "") {
+		return outOfScope(c, resource, action)
+	}
+	return next()
+}
+
+// outOfScope rejects a request whose exchanged token does not include the permission in its scope
+func outOfScope(c *fiber.Ctx, resource, action string) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": "Access denied: permission is outside the token's scope",
+			"code":    "OUT_OF_SCOPE",
+			"required": fiber.Map{
+				"resource": resource,
+				"action":   action,
+			},
+		},
+	})
+}
+
 // PermissionRequirement represents a permission requirement
 type PermissionRequirement struct {
 	Resource        string
@@ -329,9 +626,9 @@ func RequireBusinessHours(evaluator *opa.Evaluator, resource, action string) fib
 		// The time context is automatically added by the builder
 		// The policy will check if time.isBusinessHours == true
 
-		allowed, err := evaluator.EvaluateWithFullContext(c.Context(), builder)
+		decision, err := evaluator.DecideWithFullContext(c.UserContext(), builder)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			return c.Status(evaluationFailureStatus(err)).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
 					"message": "Failed to evaluate time-based policy",
@@ -341,7 +638,7 @@ func RequireBusinessHours(evaluator *opa.Evaluator, resource, action string) fib
 			})
 		}
 
-		if !allowed {
+		if !decision.Allow {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -351,6 +648,7 @@ func RequireBusinessHours(evaluator *opa.Evaluator, resource, action string) fib
 			})
 		}
 
+		recordDirectives(c, decision.Obligations, decision.Advice)
 		return c.Next()
 	}
 }
@@ -358,6 +656,12 @@ func RequireBusinessHours(evaluator *opa.Evaluator, resource, action string) fib
 // RequireMFA requires MFA verification for sensitive operations
 func RequireMFA(evaluator *opa.Evaluator, resource, action string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		// Break-glass tokens are signed offline by operators holding the
+		// break-glass key, which stands in for MFA
+		if GetBreakGlass(c) != nil {
+			return c.Next()
+		}
+
 		mfaVerified, ok := c.Locals("mfaVerified").(bool)
 		if !ok || !mfaVerified {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
@@ -374,17 +678,19 @@ func RequireMFA(evaluator *opa.Evaluator, resource, action string) fiber.Handler
 		tenantID := GetTenantID(c)
 		roles := GetRoles(c)
 
-		allowed, err := evaluator.CanAccessResource(
-			c.Context(),
+		decision, err := evaluator.DecideResourceAccess(
+			c.UserContext(),
 			userID,
 			tenantID,
+			GetAccessScope(c),
 			roles,
+			GetPermissions(c),
 			resource,
 			c.Params("id"),
 			action,
 		)
 
-		if err != nil || !allowed {
+		if err != nil || !decision.Allow {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -394,6 +700,7 @@ func RequireMFA(evaluator *opa.Evaluator, resource, action string) fiber.Handler
 			})
 		}
 
+		recordDirectives(c, decision.Obligations, decision.Advice)
 		return c.Next()
 	}
 }
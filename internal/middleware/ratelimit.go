@@ -3,6 +3,9 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,8 +13,49 @@ import (
 	"github.com/techsavvyash/heimdall/internal/database"
 )
 
-// RateLimitMiddleware implements rate limiting using Redis
-func RateLimitMiddleware(cfg *config.Config) fiber.Handler {
+// Route classes with their own rate limits. Routes of no other class are
+// limited as RateLimitRouteDefault.
+const (
+	RateLimitRouteDefault = "default"
+	RateLimitRouteAuth    = "auth"
+	RateLimitRouteAuthz   = "authz"
+)
+
+// rateLimitRoutes maps path prefixes to the route class they are limited as
+var rateLimitRoutes = []struct {
+	prefix string
+	route  string
+}{
+	{"/v1/auth/login", RateLimitRouteAuth},
+	{"/v1/auth/register", RateLimitRouteAuth},
+	{"/v1/auth/refresh", RateLimitRouteAuth},
+	{"/v1/auth/magic-link", RateLimitRouteAuth},
+	{"/v1/auth/password/reset", RateLimitRouteAuth},
+	{"/v1/oauth/token", RateLimitRouteAuth},
+	{"/v1/oauth/device/code", RateLimitRouteAuth},
+	{"/v1/authz/", RateLimitRouteAuthz},
+}
+
+// TenantRateLimits resolves the quotas of tenants
+type TenantRateLimits interface {
+	// TenantRateLimit returns a tenant's requests per minute for a route class,
+	// or 0 when the tenant has no quota for it
+	TenantRateLimit(ctx context.Context, tenantID, route string) (int, error)
+}
+
+// RateLimitRoute returns the route class of a request path
+func RateLimitRoute(path string) string {
+	for _, r := range rateLimitRoutes {
+		if strings.HasPrefix(path, r.prefix) {
+			return r.route
+		}
+	}
+	return RateLimitRouteDefault
+}
+
+// RateLimitMiddleware implements rate limiting per client IP address and route
+// class using Redis, with the limits of the current configuration
+func RateLimitMiddleware(settings *config.Reloader) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		redis := database.GetRedis()
 		if redis == nil {
@@ -19,35 +63,75 @@ func RateLimitMiddleware(cfg *config.Config) fiber.Handler {
 			return c.Next()
 		}
 
+		cfg := settings.Current()
+		route := RateLimitRoute(c.Path())
+		limit := cfg.Server.RateLimitPerMin
+		if routeLimit := cfg.Server.RouteRateLimits[route]; routeLimit > 0 {
+			limit = routeLimit
+		}
+
 		// Get client identifier (IP address)
-		clientIP := c.IP()
-		key := fmt.Sprintf("ip:%s", clientIP)
+		key := fmt.Sprintf("ip:%s:%s", route, c.IP())
+		return enforceRateLimit(c, redis, key, limit, "RATE_LIMIT_EXCEEDED", "Rate limit exceeded. Please try again later.")
+	}
+}
 
-		// Check rate limit
-		ctx := context.Background()
-		count, err := redis.IncrementRateLimit(ctx, key, time.Minute)
-		if err != nil {
-			// If rate limit check fails, allow the request
+// TenantRateLimit enforces the quotas of tenants, counting the requests of all
+// of a tenant's users and clients together. It must run after the
+// authentication middleware.
+func TenantRateLimit(tenants TenantRateLimits) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID := GetTenantID(c)
+		if tenantID == "" {
 			return c.Next()
 		}
 
-		// Set rate limit headers
-		c.Set("X-RateLimit-Limit", fmt.Sprintf("%d", cfg.Server.RateLimitPerMin))
-		c.Set("X-RateLimit-Remaining", fmt.Sprintf("%d", max(0, int64(cfg.Server.RateLimitPerMin)-count)))
+		redis := database.GetRedis()
+		if redis == nil {
+			return c.Next()
+		}
 
-		// Check if limit exceeded
-		if count > int64(cfg.Server.RateLimitPerMin) {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"message": "Rate limit exceeded. Please try again later.",
-					"code":    "RATE_LIMIT_EXCEEDED",
-				},
-			})
+		route := RateLimitRoute(c.Path())
+		limit, err := tenants.TenantRateLimit(c.UserContext(), tenantID, route)
+		if err != nil || limit <= 0 {
+			// If the quota cannot be resolved, allow the request
+			return c.Next()
 		}
 
+		key := fmt.Sprintf("tenant:%s:%s", route, tenantID)
+		return enforceRateLimit(c, redis, key, limit, "TENANT_RATE_LIMIT_EXCEEDED", "Tenant rate limit exceeded. Please try again later.")
+	}
+}
+
+// enforceRateLimit counts a request against a limit per sliding minute. The
+// rate limit headers describe the tightest limit the request was counted against.
+func enforceRateLimit(c *fiber.Ctx, redis *database.RedisClient, key string, limit int, code, message string) error {
+	ctx := context.Background()
+	result, err := redis.AllowRateLimit(ctx, key, int64(limit), time.Minute)
+	if err != nil {
+		// If rate limit check fails, allow the request
 		return c.Next()
 	}
+
+	// Set rate limit headers
+	if previous, err := strconv.ParseInt(c.GetRespHeader("X-RateLimit-Remaining"), 10, 64); err != nil || result.Remaining < previous {
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Set("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+	}
+
+	// Check if limit exceeded
+	if !result.Allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": message,
+				"code":    code,
+			},
+		})
+	}
+
+	return c.Next()
 }
 
 // RateLimitByUser implements per-user rate limiting
@@ -67,12 +151,12 @@ func RateLimitByUser(maxRequests int, window time.Duration) fiber.Handler {
 		key := fmt.Sprintf("user:%s", userID)
 		ctx := context.Background()
 
-		count, err := redis.IncrementRateLimit(ctx, key, window)
+		result, err := redis.AllowRateLimit(ctx, key, int64(maxRequests), window)
 		if err != nil {
 			return c.Next()
 		}
 
-		if count > int64(maxRequests) {
+		if !result.Allowed {
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -85,10 +169,3 @@ func RateLimitByUser(maxRequests int, window time.Duration) fiber.Handler {
 		return c.Next()
 	}
 }
-
-func max(a, b int64) int64 {
-	if a > b {
-		return a
-	}
-	return b
-}
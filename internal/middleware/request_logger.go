@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// RequestLogger logs requests as they complete, following the log level of the
+// current configuration: debug and info log every request, debug with the
+// query and client IP, warn only failed requests and error only server errors.
+func RequestLogger(settings *config.Reloader) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			// Handle the error here, so its status is logged
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		latency := time.Since(start)
+		switch settings.Current().Server.LogLevel {
+		case config.LogLevelDebug:
+			log.Printf("%d - %v %s %s ip=%s", status, latency, c.Method(), c.OriginalURL(), c.IP())
+		case config.LogLevelWarn:
+			if status >= fiber.StatusBadRequest {
+				log.Printf("%d - %v %s %s", status, latency, c.Method(), c.Path())
+			}
+		case config.LogLevelError:
+			if status >= fiber.StatusInternalServerError {
+				log.Printf("%d - %v %s %s", status, latency, c.Method(), c.Path())
+			}
+		default:
+			log.Printf("%d - %v %s %s", status, latency, c.Method(), c.Path())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// docsContentSecurityPolicy admits the scripts and styles of the Swagger UI,
+// which the API's policy would block
+const docsContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+// adminContentSecurityPolicy admits the admin UI's own scripts, styles and API
+// calls, and nothing else
+const adminContentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'"
+
+// loginContentSecurityPolicy admits the hosted login pages' own scripts, styles
+// and API calls, and tenants' logos
+const loginContentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self'; img-src 'self' https: data:; connect-src 'self'; form-action 'self'; frame-ancestors 'none'"
+
+// SecurityHeaders sets security headers on all responses. Handlers may
+// override them.
+func SecurityHeaders(cfg *config.HeadersConfig) fiber.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge) + "; includeSubDomains"
+	}
+
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		c.Set(fiber.HeaderReferrerPolicy, "no-referrer")
+		if cfg.FrameOptions != "" {
+			c.Set(fiber.HeaderXFrameOptions, cfg.FrameOptions)
+		}
+		if hsts != "" {
+			c.Set(fiber.HeaderStrictTransportSecurity, hsts)
+		}
+
+		csp := cfg.ContentSecurityPolicy
+		if c.Path() == "/docs" || strings.HasPrefix(c.Path(), "/docs/") {
+			csp = docsContentSecurityPolicy
+		} else if c.Path() == "/admin" || strings.HasPrefix(c.Path(), "/admin/") {
+			csp = adminContentSecurityPolicy
+		} else if c.Path() == "/login" || strings.HasPrefix(c.Path(), "/login/") {
+			csp = loginContentSecurityPolicy
+		}
+		if csp != "" {
+			c.Set(fiber.HeaderContentSecurityPolicy, csp)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserAttributeStore resolves the typed attributes of users
+type UserAttributeStore interface {
+	// UserAttributes returns the attributes of a user defined by the schema of
+	// the user's tenant, or nil when the tenant has no schema
+	UserAttributes(ctx context.Context, tenantID, userID string) (map[string]interface{}, error)
+}
+
+// UserAttributesMiddleware loads the authenticated user's typed attributes,
+// which policies see as input.user.metadata. It runs after the authentication
+// middleware. Attributes that cannot be loaded are left out, so policies
+// relying on them deny the request.
+func UserAttributesMiddleware(store UserAttributeStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := GetUserID(c)
+		if userID == "" {
+			return c.Next()
+		}
+
+		attributes, err := store.UserAttributes(c.UserContext(), GetTenantID(c), userID)
+		if err != nil {
+			log.Printf("Failed to load attributes of user %s: %v", userID, err)
+			return c.Next()
+		}
+		if attributes != nil {
+			c.Locals("userAttributes", attributes)
+		}
+
+		return c.Next()
+	}
+}
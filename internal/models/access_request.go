@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Access request statuses
+const (
+	AccessRequestStatusPending   = "pending"
+	AccessRequestStatusApproved  = "approved"
+	AccessRequestStatusDenied    = "denied"
+	AccessRequestStatusCancelled = "cancelled"
+)
+
+// AccessRequest is a user's request for just-in-time access to a role. An
+// approved request grants the role until GrantedUntil.
+type AccessRequest struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID `gorm:"type:uuid;not null;index:idx_access_requests_tenant_status" json:"tenantId"`
+	RequesterID uuid.UUID `gorm:"type:uuid;not null;index" json:"requesterId"`
+	RoleID      uuid.UUID `gorm:"type:uuid;not null" json:"roleId"`
+
+	// What was requested and why
+	Justification   string `gorm:"type:text;not null" json:"justification"`
+	DurationMinutes int    `gorm:"not null" json:"durationMinutes"`
+
+	// pending, approved, denied or cancelled
+	Status string `gorm:"type:varchar(20);not null;default:'pending';index:idx_access_requests_tenant_status" json:"status"`
+
+	// Decision
+	DecidedBy       *uuid.UUID `gorm:"type:uuid" json:"decidedBy,omitempty"`
+	DecidedAt       *time.Time `json:"decidedAt,omitempty"`
+	DecisionComment string     `gorm:"type:text" json:"decisionComment,omitempty"`
+	GrantedUntil    *time.Time `json:"grantedUntil,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relationships
+	Requester User `gorm:"foreignKey:RequesterID" json:"-"`
+	Role      Role `gorm:"foreignKey:RoleID" json:"-"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (r *AccessRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Status == "" {
+		r.Status = AccessRequestStatusPending
+	}
+	return nil
+}
+
+// TableName specifies the table name for AccessRequest
+func (AccessRequest) TableName() string {
+	return "access_requests"
+}
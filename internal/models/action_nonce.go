@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ActionNonce is a one-time token confirming a destructive action. A user
+// fetches one for an action on a resource and sends it with the request
+// performing the action before it expires. Only a hash of the nonce is stored.
+type ActionNonce struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null" json:"tenantId"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index" json:"userId"`
+
+	// Action confirmed, e.g. tenants.delete, and the resource it applies to
+	Action     string `gorm:"type:varchar(100);not null" json:"action"`
+	ResourceID string `gorm:"type:varchar(255);not null" json:"resourceId"`
+
+	NonceHash string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"` // Set when the nonce is consumed, rejecting replays
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (n *ActionNonce) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for ActionNonce
+func (ActionNonce) TableName() string {
+	return "action_nonces"
+}
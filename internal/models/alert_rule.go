@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Alert rule types, the conditions evaluated on a tenant's audit log
+const (
+	AlertRuleUserDenies        = "user_denies"         // Requests of a user denied with 403
+	AlertRuleLoginFailures     = "ip_login_failures"   // Failed logins from an IP address
+	AlertRuleDecisionErrorRate = "decision_error_rate" // Percentage of OPA decisions that failed to evaluate
+)
+
+// AlertRule fires an alert when a condition on a tenant's audit log exceeds a
+// threshold within a sliding window
+type AlertRule struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;index" json:"tenantId"`
+	Name     string    `gorm:"type:varchar(255);not null" json:"name"`
+
+	// Condition: the rule type, the count or percentage it must exceed within
+	// the window, and for rates the decisions needed before a rate is evaluated
+	Type          string  `gorm:"type:varchar(50);not null" json:"type"`
+	Threshold     float64 `gorm:"not null" json:"threshold"`
+	WindowMinutes int     `gorm:"not null" json:"windowMinutes"`
+	MinEvents     int     `gorm:"not null;default:0" json:"minEvents"`
+
+	// Channels alerts are delivered to, in addition to the platform webhooks.
+	// Webhook URLs often embed tokens, so both are encrypted at rest.
+	WebhookURL          string `gorm:"type:text;serializer:encrypted" json:"-"`
+	PagerDutyRoutingKey string `gorm:"type:text;serializer:encrypted" json:"-"`
+	Severity            string `gorm:"type:varchar(20);not null;default:'warning'" json:"severity"` // critical, error, warning or info
+
+	Enabled bool `gorm:"not null" json:"enabled"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (r *AlertRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AlertRule
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// Alert is an alert fired by a rule. A rule fires once per subject and window.
+type Alert struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null" json:"tenantId"`
+	RuleID   uuid.UUID `gorm:"type:uuid;not null;index:idx_alerts_rule_subject_created" json:"ruleId"`
+
+	// User ID or IP address the condition held for, empty for tenant-wide rates
+	Subject string `gorm:"type:varchar(255);not null;default:'';index:idx_alerts_rule_subject_created" json:"subject"`
+
+	// Observed count or percentage, and the threshold it exceeded
+	Value     float64 `gorm:"not null" json:"value"`
+	Threshold float64 `gorm:"not null" json:"threshold"`
+	Message   string  `gorm:"type:text" json:"message"`
+
+	// When the alert fired
+	CreatedAt time.Time `gorm:"index:idx_alerts_rule_subject_created" json:"createdAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (a *Alert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Alert
+func (Alert) TableName() string {
+	return "alerts"
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -31,7 +32,7 @@ type AuditLog struct {
 	Message    string         `gorm:"type:text" json:"message,omitempty"`
 
 	// Additional data
-	Metadata   map[string]interface{} `gorm:"type:jsonb" json:"metadata,omitempty"`
+	Metadata   datatypes.JSON `gorm:"type:jsonb" json:"metadata,omitempty"`
 
 	// Duration in milliseconds
 	Duration   int64          `json:"duration,omitempty"`
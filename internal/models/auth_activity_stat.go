@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthActivityStat summarizes a tenant's logins and registrations on a day
+// (UTC). Stats are aggregated in the background from login events, failed
+// logins in the audit log and users, so analytics never scan the raw logs.
+type AuthActivityStat struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_auth_activity_stats_tenant_day" json:"tenantId"`
+	Day      time.Time `gorm:"not null;uniqueIndex:idx_auth_activity_stats_tenant_day" json:"day"` // Midnight UTC
+
+	// Distinct users who logged in on the day, and in the 7 days ending with it
+	ActiveUsers       int64 `gorm:"not null;default:0" json:"activeUsers"`
+	WeeklyActiveUsers int64 `gorm:"not null;default:0" json:"weeklyActiveUsers"`
+	MFAUsers          int64 `gorm:"column:mfa_users;not null;default:0" json:"mfaUsers"` // Active users who logged in with MFA
+
+	// Login attempts and new users
+	Logins        int64 `gorm:"not null;default:0" json:"logins"`
+	FailedLogins  int64 `gorm:"not null;default:0" json:"failedLogins"`
+	Registrations int64 `gorm:"not null;default:0" json:"registrations"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"` // When the day was last aggregated
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (s *AuthActivityStat) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AuthActivityStat
+func (AuthActivityStat) TableName() string {
+	return "auth_activity_stats"
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -38,9 +41,10 @@ type PolicyBundle struct {
 	BuildCompletedAt *time.Time `json:"buildCompletedAt,omitempty"`
 	BuildError      string      `gorm:"type:text" json:"buildError,omitempty"`
 	BuildLog        string      `gorm:"type:text" json:"buildLog,omitempty"`
+	BuildJobID      *uuid.UUID  `gorm:"type:uuid" json:"buildJobId,omitempty"` // Latest build, see GET /v1/jobs/:jobId
 
 	// Storage information
-	StoragePath     string      `gorm:"type:varchar(500)" json:"storagePath,omitempty"` // Path in MinIO
+	StoragePath     string      `gorm:"type:varchar(500)" json:"storagePath,omitempty"` // Path in bundle storage
 	StorageBucket   string      `gorm:"type:varchar(200)" json:"storageBucket,omitempty"`
 	Size            int64       `json:"size,omitempty"` // Size in bytes
 	Checksum        string      `gorm:"type:varchar(256)" json:"checksum,omitempty"` // SHA256 checksum
@@ -51,9 +55,16 @@ type PolicyBundle struct {
 	DeactivatedAt   *time.Time  `json:"deactivatedAt,omitempty"`
 	DeactivatedBy   *uuid.UUID  `gorm:"type:uuid" json:"deactivatedBy,omitempty"`
 
-	// Bundle manifest (stores metadata about included policies)
+	// Bundle manifest as shipped in the bundle (BundleManifest)
 	Manifest        datatypes.JSON `gorm:"type:jsonb" json:"manifest,omitempty"`
 
+	// Policy selection resolved at build time instead of a fixed policy list
+	Selector        datatypes.JSON `gorm:"type:jsonb" json:"selector,omitempty"` // BundleSelector
+	AutoRebuild     bool        `gorm:"default:false;not null" json:"autoRebuild"` // Rebuild when a matching policy is published
+
+	// Ship tenant data documents (roles, settings, IP access lists) alongside the policies
+	IncludeData     bool        `gorm:"default:false;not null" json:"includeData"`
+
 	// Timestamps
 	CreatedAt   time.Time      `json:"createdAt"`
 	UpdatedAt   time.Time      `json:"updatedAt"`
@@ -63,7 +74,7 @@ type PolicyBundle struct {
 
 	// Relationships
 	Tenant      *Tenant        `gorm:"foreignKey:TenantID;references:ID" json:"tenant,omitempty"`
-	Policies    []Policy       `gorm:"many2many:bundle_policies;" json:"policies,omitempty"`
+	Policies    []Policy       `gorm:"many2many:bundle_policies;joinForeignKey:BundleID;joinReferences:PolicyID" json:"policies,omitempty"`
 	Deployments []BundleDeployment `gorm:"foreignKey:BundleID;references:ID" json:"deployments,omitempty"`
 }
 
@@ -89,6 +100,8 @@ type BundleDeployment struct {
 	DeployedAt  time.Time      `json:"deployedAt"`
 	DeployedBy  uuid.UUID      `gorm:"type:uuid" json:"deployedBy"`
 	Environment string         `gorm:"type:varchar(100)" json:"environment,omitempty"` // e.g., "production", "staging"
+	Revision    string         `gorm:"type:varchar(256)" json:"revision,omitempty"` // Revision of the deployed bundle's manifest
+	Manifest    datatypes.JSON `gorm:"type:jsonb" json:"manifest,omitempty"` // BundleManifest of the bundle as deployed
 
 	// Deployment status
 	Status      string         `gorm:"type:varchar(50);not null" json:"status"` // "success", "failed", "rolling_back"
@@ -120,6 +133,46 @@ func (BundleDeployment) TableName() string {
 	return "bundle_deployments"
 }
 
+// BundleManifest is the .manifest file of a bundle in OPA's bundle format
+type BundleManifest struct {
+	Revision string                 `json:"revision"` // SHA-256 of the bundle's policy and data files
+	Roots    []string               `json:"roots"`    // Data paths the bundle owns in OPA
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// BundleSelector selects the active policies of a bundle by tag and path
+type BundleSelector struct {
+	Tags       []string `json:"tags,omitempty" validate:"omitempty,dive,required,max=100"` // Policies must have all of these tags
+	PathPrefix string   `json:"pathPrefix,omitempty" validate:"omitempty,max=500" example:"authz/*"`     // Policies must be under this path; a trailing * is optional
+}
+
+// Prefix returns the path prefix policies must start with
+func (s BundleSelector) Prefix() string {
+	return strings.TrimSuffix(s.PathPrefix, "*")
+}
+
+// Matches reports whether an active policy is selected
+func (s BundleSelector) Matches(policy *Policy) bool {
+	if policy.Status != PolicyStatusActive || !strings.HasPrefix(policy.Path, s.Prefix()) {
+		return false
+	}
+	if len(s.Tags) == 0 {
+		return true
+	}
+	var tags []string
+	if len(policy.Tags) > 0 {
+		if err := json.Unmarshal(policy.Tags, &tags); err != nil {
+			return false
+		}
+	}
+	for _, tag := range s.Tags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
 // BundlePolicy is the join table between bundles and policies
 type BundlePolicy struct {
 	BundleID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"bundleId"`
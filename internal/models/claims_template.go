@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// TenantClaimsTemplate configures which claims a tenant's access tokens carry
+type TenantClaimsTemplate struct {
+	TenantID uuid.UUID `gorm:"type:uuid;primary_key" json:"tenantId"`
+
+	// Roles embedded in tokens, all of the user's roles when RoleFilter is empty
+	IncludeRoles bool           `json:"includeRoles"`
+	RoleFilter   datatypes.JSON `gorm:"type:jsonb" json:"roleFilter,omitempty"`
+
+	// Embed the user's permissions, resolved through their roles
+	IncludePermissions bool `json:"includePermissions"`
+
+	// Keys of the tenant settings embedded under the "tenant" claim, stored as JSONB
+	TenantMetadata datatypes.JSON `gorm:"type:jsonb" json:"tenantMetadata,omitempty"`
+
+	// Custom claims mapped to their source, e.g. "department": "user.metadata.department"
+	CustomClaims datatypes.JSON `gorm:"type:jsonb" json:"customClaims,omitempty"`
+
+	// Audiences of the tenant's own services accepted in addition to Heimdall's,
+	// stored as JSONB
+	Audiences datatypes.JSON `gorm:"type:jsonb" json:"audiences,omitempty"`
+
+	// Maximum size of an access token in bytes, 0 for no limit
+	MaxTokenSize int `json:"maxTokenSize"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for TenantClaimsTemplate
+func (TenantClaimsTemplate) TableName() string {
+	return "tenant_claims_templates"
+}
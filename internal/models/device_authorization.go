@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Statuses of a device authorization
+const (
+	DeviceAuthorizationPending  = "pending"
+	DeviceAuthorizationApproved = "approved"
+	DeviceAuthorizationDenied   = "denied"
+	DeviceAuthorizationConsumed = "consumed"
+)
+
+// DeviceAuthorization is a pending login of a device with the OAuth device
+// authorization grant. The user approves it in a browser with the user code
+// while the device polls for tokens with the device code.
+type DeviceAuthorization struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+
+	// SHA-256 of the device code, the code itself is only returned to the device
+	DeviceCodeHash string `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	UserCode       string `gorm:"type:varchar(16);not null;uniqueIndex" json:"userCode"`
+	ClientID       string `gorm:"type:varchar(100);not null" json:"clientId"`
+	Status         string `gorm:"type:varchar(20);not null" json:"status"`
+
+	// The user who approved or denied the device
+	UserID *uuid.UUID `gorm:"type:uuid" json:"userId,omitempty"`
+
+	// Polling interval in seconds, raised when the device polls too fast
+	PollInterval int        `gorm:"not null" json:"pollInterval"`
+	LastPolledAt *time.Time `json:"lastPolledAt,omitempty"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expiresAt"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (d *DeviceAuthorization) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for DeviceAuthorization
+func (DeviceAuthorization) TableName() string {
+	return "device_authorizations"
+}
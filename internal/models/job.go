@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"   // Waiting for a worker, or for a retry after a failure
+	JobStatusRunning   = "running"   // Claimed by a worker
+	JobStatusCompleted = "completed" // Finished successfully
+	JobStatusDead      = "dead"      // Failed permanently or ran out of attempts
+)
+
+// Job is a unit of asynchronous work, e.g. a bundle build, queued in the
+// database and run by the workers of any instance. Failed jobs are retried
+// with backoff until they run out of attempts and are left dead for inspection.
+type Job struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenantId"` // Nil for platform-wide jobs
+	Type      string         `gorm:"type:varchar(50);not null" json:"type"`    // e.g. bundle.build
+	Payload   datatypes.JSON `gorm:"type:jsonb" json:"payload,omitempty"`
+	Result    datatypes.JSON `gorm:"type:jsonb" json:"result,omitempty"` // Returned by the job's handler on success
+	CreatedBy *uuid.UUID     `gorm:"type:uuid" json:"createdBy,omitempty"`
+
+	// Execution state. RunAt is when a pending job is due, or when the lease of
+	// a running job expires and another worker may take it over.
+	Status      string     `gorm:"type:varchar(20);not null;default:'pending';index:idx_jobs_status_run_at" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"maxAttempts"`
+	LastError   string     `gorm:"type:text" json:"lastError,omitempty"`
+	RunAt       time.Time  `gorm:"not null;index:idx_jobs_status_run_at" json:"runAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID, status and due time if not provided
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if j.Status == "" {
+		j.Status = JobStatusPending
+	}
+	if j.RunAt.IsZero() {
+		j.RunAt = time.Now()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Job
+func (Job) TableName() string {
+	return "jobs"
+}
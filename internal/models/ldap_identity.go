@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LDAPIdentity links a user to the LDAP directory entry they were provisioned from
+type LDAPIdentity struct {
+	UserID   uuid.UUID  `gorm:"type:uuid;primary_key" json:"userId"`
+	DN       string     `gorm:"column:dn;type:varchar(1024);not null;uniqueIndex" json:"dn"`
+	SyncedAt *time.Time `json:"syncedAt,omitempty"` // Last group membership sync
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for LDAPIdentity
+func (LDAPIdentity) TableName() string {
+	return "ldap_identities"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LeaderLease records which instance runs a singleton background task, e.g.
+// the scheduled cleanup, when several instances share the database. The holder
+// renews the lease while it runs the task; once the lease expires any instance
+// may take it over.
+type LeaderLease struct {
+	Name       string    `gorm:"type:varchar(100);primary_key" json:"name"` // e.g. cleanup
+	Holder     string    `gorm:"type:varchar(255);not null" json:"holder"`  // Instance ID of the leader
+	ExpiresAt  time.Time `gorm:"not null" json:"expiresAt"`
+	AcquiredAt time.Time `gorm:"not null" json:"acquiredAt"` // When the current holder took over
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for LeaderLease
+func (LeaderLease) TableName() string {
+	return "leader_leases"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// LoginEvent records the context of a successful login for a user
+type LoginEvent struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index:idx_login_events_user_created" json:"userId"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;index" json:"tenantId"`
+
+	// Request context
+	IPAddress         string `gorm:"type:varchar(45)" json:"ipAddress,omitempty"`
+	UserAgent         string `gorm:"type:text" json:"userAgent,omitempty"`
+	DeviceFingerprint string `gorm:"type:varchar(64);index" json:"deviceFingerprint,omitempty"`
+
+	// Geo location resolved from the IP address
+	Country string `gorm:"type:varchar(2)" json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Region  string `gorm:"type:varchar(100)" json:"region,omitempty"`
+	City    string `gorm:"type:varchar(100)" json:"city,omitempty"`
+
+	// Detection results
+	Suspicious bool           `gorm:"default:false;index" json:"suspicious"`
+	Reasons    datatypes.JSON `gorm:"type:jsonb" json:"reasons,omitempty"` // e.g. ["new_device", "new_country"]
+
+	// Whether a login hook verified a second factor
+	MFA bool `gorm:"column:mfa;default:false" json:"mfa"`
+
+	// Timestamp
+	CreatedAt time.Time `gorm:"index:idx_login_events_user_created" json:"createdAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (e *LoginEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for LoginEvent
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
@@ -1,6 +1,11 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+
+	// Registers the serializer of fields encrypted at rest
+	_ "github.com/techsavvyash/heimdall/internal/encryption"
+)
 
 // AllModels returns all models that need to be migrated
 func AllModels() []interface{} {
@@ -17,6 +22,27 @@ func AllModels() []interface{} {
 		&PolicyBundle{},
 		&BundleDeployment{},
 		&BundlePolicy{},
+		&TenantSigningKey{},
+		&LoginEvent{},
+		&OutboxEntry{},
+		&UserCredential{},
+		&LDAPIdentity{},
+		&TenantSAMLConfig{},
+		&TenantClaimsTemplate{},
+		&OAuthClient{},
+		&DeviceAuthorization{},
+		&TenantRateLimits{},
+		&Resource{},
+		&AccessRequest{},
+		&OPAInstance{},
+		&AuthActivityStat{},
+		&PlatformSigningKey{},
+		&AlertRule{},
+		&Alert{},
+		&ActionNonce{},
+		&Job{},
+		&LeaderLease{},
+		&PasswordHistory{},
 	}
 }
 
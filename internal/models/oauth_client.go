@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// OAuthClient is a machine client of a tenant that authenticates with the OAuth
+// client credentials grant instead of a user account
+type OAuthClient struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;index" json:"tenantId"`
+	ClientID string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"clientId"`
+	Name     string    `gorm:"type:varchar(255);not null" json:"name"`
+
+	// SHA-256 of the client secret, the secret itself is only shown when issued
+	SecretHash string `gorm:"type:text;not null;serializer:encrypted" json:"-"`
+
+	// The secret replaced by the last rotation stays valid until it expires
+	PreviousSecretHash      string     `gorm:"type:text;serializer:encrypted" json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"-"`
+
+	// Permissions the client may request, stored as JSONB
+	Scopes datatypes.JSON `gorm:"type:jsonb" json:"scopes"`
+
+	SecretRotatedAt *time.Time `json:"secretRotatedAt,omitempty"`
+	LastUsedAt      *time.Time `json:"lastUsedAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (c *OAuthClient) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for OAuthClient
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
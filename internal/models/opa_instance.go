@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// OPAInstance is an OPA instance, e.g. a sidecar running Heimdall's bundles,
+// as last reported through OPA's status API
+type OPAInstance struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_opa_instances_tenant_instance_id" json:"tenantId"` // Tenant of the credentials the instance reports with
+
+	// Instance ID OPA generates on startup (labels.id) and its version
+	InstanceID string         `gorm:"type:varchar(200);not null;uniqueIndex:idx_opa_instances_tenant_instance_id" json:"instanceId"`
+	Version    string         `gorm:"type:varchar(100)" json:"version,omitempty"`
+	Labels     datatypes.JSON `gorm:"type:jsonb" json:"labels,omitempty"`
+
+	// Who reported, and from where
+	ClientID  string `gorm:"type:varchar(255)" json:"clientId,omitempty"`
+	IPAddress string `gorm:"type:varchar(45)" json:"ipAddress,omitempty"`
+
+	// Status of the instance's bundles and plugins as last reported
+	Bundles datatypes.JSON `gorm:"type:jsonb" json:"bundles,omitempty"`
+	Plugins datatypes.JSON `gorm:"type:jsonb" json:"plugins,omitempty"`
+
+	// Timestamps
+	LastSeenAt time.Time `gorm:"not null;index" json:"lastSeenAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (i *OPAInstance) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for OPAInstance
+func (OPAInstance) TableName() string {
+	return "opa_instances"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Outbox entry statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusCompleted = "completed"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxEntry is a change to the identity provider that is recorded in
+// the same transaction as the local change it belongs to and applied by a worker
+type OutboxEntry struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Operation   string         `gorm:"type:varchar(50);not null" json:"operation"`  // e.g. identity.delete_user
+	AggregateID uuid.UUID      `gorm:"type:uuid;not null;index" json:"aggregateId"` // User the change applies to
+	Payload     datatypes.JSON `gorm:"type:jsonb" json:"payload,omitempty"`
+
+	// Delivery state
+	Status        string     `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_entries_status_next" json:"status"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"lastError,omitempty"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_entries_status_next" json:"nextAttemptAt"`
+	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID and status if not provided
+func (e *OutboxEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Status == "" {
+		e.Status = OutboxStatusPending
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = time.Now()
+	}
+	return nil
+}
+
+// TableName specifies the table name for OutboxEntry
+func (OutboxEntry) TableName() string {
+	return "outbox_entries"
+}
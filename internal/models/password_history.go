@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory is a bcrypt hash of a password a user set, kept so tenants
+// with a password history can reject reuse of recent passwords
+type PasswordHistory struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"userId"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (h *PasswordHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for PasswordHistory
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -13,7 +13,7 @@ type PolicyType string
 
 const (
 	PolicyTypeRego    PolicyType = "rego"     // Rego policy code
-	PolicyTypeJSON    PolicyType = "json"     // JSON data policy
+	PolicyTypeJSON    PolicyType = "json"     // Structured rules compiled to Rego
 	PolicyTypeWasm    PolicyType = "wasm"     // WebAssembly policy
 )
 
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// TenantRateLimits holds a tenant's request quotas: tenant-wide budgets of
+// requests per route class, enforced on top of the per-IP route limits
+type TenantRateLimits struct {
+	TenantID uuid.UUID `gorm:"type:uuid;primary_key" json:"tenantId"`
+
+	// Requests per minute by route class, e.g. "authz": 5000, stored as JSONB
+	Limits datatypes.JSON `gorm:"type:jsonb" json:"limits"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for TenantRateLimits
+func (TenantRateLimits) TableName() string {
+	return "tenant_rate_limits"
+}
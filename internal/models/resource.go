@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Resource is a resource of another service registered with Heimdall, giving
+// ownership and label-based policies authoritative attributes
+type Resource struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resources_tenant_type_resource_id" json:"tenantId"`
+
+	// Type and ID of the resource in the service owning it, e.g. "documents" and "doc-42"
+	Type       string `gorm:"type:varchar(100);not null;uniqueIndex:idx_resources_tenant_type_resource_id" json:"type"`
+	ResourceID string `gorm:"type:varchar(255);not null;uniqueIndex:idx_resources_tenant_type_resource_id" json:"resourceId"`
+
+	// User owning the resource
+	OwnerID string `gorm:"type:varchar(255)" json:"ownerId,omitempty"`
+
+	// Labels as key/value pairs and free-form attributes, stored as JSONB
+	Labels     datatypes.JSON `gorm:"type:jsonb" json:"labels,omitempty"`
+	Attributes datatypes.JSON `gorm:"type:jsonb" json:"attributes,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (r *Resource) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Resource
+func (Resource) TableName() string {
+	return "resources"
+}
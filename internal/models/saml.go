@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// TenantSAMLConfig configures SAML single sign-on for a tenant through its
+// corporate identity provider
+type TenantSAMLConfig struct {
+	TenantID    uuid.UUID `gorm:"type:uuid;primary_key" json:"tenantId"`
+	Enabled     bool      `json:"enabled"`
+	IdPMetadata string    `gorm:"column:idp_metadata;type:text;not null" json:"-"` // EntityDescriptor XML
+	IdPEntityID string    `gorm:"column:idp_entity_id;type:varchar(1024)" json:"idpEntityId"`
+
+	// Assertion attributes, the NameID is used for the email when EmailAttribute is empty
+	EmailAttribute     string `gorm:"type:varchar(255)" json:"emailAttribute,omitempty"`
+	FirstNameAttribute string `gorm:"type:varchar(255)" json:"firstNameAttribute,omitempty"`
+	LastNameAttribute  string `gorm:"type:varchar(255)" json:"lastNameAttribute,omitempty"`
+	RoleAttribute      string `gorm:"type:varchar(255)" json:"roleAttribute,omitempty"`
+
+	// Role mappings from RoleAttribute values to tenant roles, stored as JSONB
+	RoleMappings datatypes.JSON `gorm:"type:jsonb" json:"roleMappings,omitempty"`
+
+	// Accept responses the IdP sends without a login started by Heimdall
+	AllowIdPInitiated bool `gorm:"column:allow_idp_initiated" json:"allowIdpInitiated"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for TenantSAMLConfig
+func (TenantSAMLConfig) TableName() string {
+	return "tenant_saml_configs"
+}
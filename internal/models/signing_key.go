@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SigningKeyStatus defines the lifecycle state of a signing key
+type SigningKeyStatus string
+
+const (
+	SigningKeyStatusActive  SigningKeyStatus = "active"  // Used to sign new tokens
+	SigningKeyStatusRetired SigningKeyStatus = "retired" // Verifies existing tokens only
+	SigningKeyStatusRevoked SigningKeyStatus = "revoked" // Tokens signed with it are rejected
+	SigningKeyStatusExpired SigningKeyStatus = "expired" // Retired for longer than tokens live; no longer verifies
+)
+
+// TenantSigningKey represents a tenant-scoped JWT signing key pair
+type TenantSigningKey struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID uuid.UUID `gorm:"type:uuid;not null;index" json:"tenantId"`
+
+	// Key material
+	KeyID         string `gorm:"type:varchar(100);uniqueIndex;not null" json:"kid"`
+	Algorithm     string `gorm:"type:varchar(20);not null;default:'RS256'" json:"alg"`
+	PublicKeyPEM  string `gorm:"type:text;not null" json:"publicKey"`
+	PrivateKeyPEM string `gorm:"type:text;not null;serializer:encrypted" json:"-"`
+
+	// Lifecycle
+	Status    SigningKeyStatus `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	RetiredAt *time.Time       `json:"retiredAt,omitempty"`
+	RevokedAt *time.Time       `json:"revokedAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Relationships
+	Tenant Tenant `gorm:"foreignKey:TenantID" json:"-"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (k *TenantSigningKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for TenantSigningKey
+func (TenantSigningKey) TableName() string {
+	return "tenant_signing_keys"
+}
+
+// PlatformSigningKey represents a JWT signing key pair of the shared platform
+// key, which signs the tokens of tenants without their own key. Until the
+// first rotation the platform key is read from JWT_PRIVATE_KEY_PATH; rotating
+// records it as retired without its private key.
+type PlatformSigningKey struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+
+	// Key material
+	KeyID         string `gorm:"type:varchar(100);uniqueIndex;not null" json:"kid"`
+	Algorithm     string `gorm:"type:varchar(20);not null;default:'RS256'" json:"alg"`
+	PublicKeyPEM  string `gorm:"type:text;not null" json:"publicKey"`
+	PrivateKeyPEM string `gorm:"type:text;serializer:encrypted" json:"-"` // Empty for the key read from files
+
+	// Lifecycle
+	Status    SigningKeyStatus `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	RetiredAt *time.Time       `json:"retiredAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// BeforeCreate hook to set UUID if not provided
+func (k *PlatformSigningKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for PlatformSigningKey
+func (PlatformSigningKey) TableName() string {
+	return "platform_signing_keys"
+}
@@ -16,8 +16,8 @@ type Tenant struct {
 	FusionAuthAppID   uuid.UUID      `gorm:"type:uuid" json:"fusionAuthAppId"`
 	FusionAuthTenantID uuid.UUID     `gorm:"type:uuid" json:"fusionAuthTenantId"`
 
-	// Configuration stored as JSONB
-	Settings          datatypes.JSON `gorm:"type:jsonb" json:"settings,omitempty"`
+	// Configuration stored as JSONB, encrypted at rest
+	Settings          datatypes.JSON `gorm:"type:jsonb;serializer:encrypted" json:"settings,omitempty"`
 
 	// Resource quotas
 	MaxUsers          int            `gorm:"default:1000" json:"maxUsers"`
@@ -8,6 +8,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// User statuses. Suspended users keep their account but cannot sign in or use
+// their tokens. Deactivated users are soft deleted and can be restored until
+// they are purged.
+const (
+	UserStatusActive      = "active"
+	UserStatusSuspended   = "suspended"
+	UserStatusDeactivated = "deactivated"
+)
+
 // User represents additional user data beyond FusionAuth
 // Core auth data is stored in FusionAuth, this stores RBAC and custom attributes
 type User struct {
@@ -15,17 +24,18 @@ type User struct {
 	TenantID          uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenantId"`
 	Email             string         `gorm:"type:varchar(255);not null;index" json:"email"`
 
-	// Additional metadata
-	Metadata          datatypes.JSON `gorm:"type:jsonb" json:"metadata,omitempty"`
+	// Additional metadata, encrypted at rest as it holds personal data
+	Metadata          datatypes.JSON `gorm:"type:jsonb;serializer:encrypted" json:"metadata,omitempty"`
 
 	// Account status tracking
+	Status            string         `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
 	LastLoginAt       *time.Time     `json:"lastLoginAt,omitempty"`
 	LoginCount        int            `gorm:"default:0" json:"loginCount"`
 
 	// Timestamps
 	CreatedAt         time.Time      `json:"createdAt"`
 	UpdatedAt         time.Time      `json:"updatedAt"`
-	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"` // When the user was deactivated
 
 	// Relationships
 	Tenant            Tenant         `gorm:"foreignKey:TenantID" json:"tenant,omitempty"`
@@ -33,6 +43,14 @@ type User struct {
 	AuditLogs         []AuditLog     `gorm:"foreignKey:UserID" json:"auditLogs,omitempty"`
 }
 
+// BeforeCreate hook to set the status if not provided
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.Status == "" {
+		u.Status = UserStatusActive
+	}
+	return nil
+}
+
 // TableName specifies the table name for User
 func (User) TableName() string {
 	return "users"
@@ -45,7 +63,8 @@ type UserRole struct {
 	RoleID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"roleId"`
 	AssignedBy uuid.UUID      `gorm:"type:uuid" json:"assignedBy"`
 	AssignedAt time.Time      `gorm:"default:now()" json:"assignedAt"`
-	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`
+	ExpiresAt  *time.Time     `gorm:"index" json:"expiresAt,omitempty"` // Temporary assignments are removed once expired
+	Reason     string         `gorm:"type:text" json:"reason,omitempty"` // Why temporary access was granted
 
 	// Relationships
 	User       User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserCredential stores the identity of a user when Heimdall is its own identity
+// provider instead of FusionAuth
+type UserCredential struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primary_key" json:"userId"`                 // Same as the users table ID
+	Email        string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"email"` // Stored lowercase
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`                 // bcrypt
+	FirstName    string    `gorm:"type:varchar(255)" json:"firstName,omitempty"`
+	LastName     string    `gorm:"type:varchar(255)" json:"lastName,omitempty"`
+	Active       bool      `gorm:"default:true" json:"active"`
+	Verified     bool      `gorm:"default:false" json:"verified"`
+
+	// Pending password reset
+	ResetTokenHash      string     `gorm:"type:varchar(64);index" json:"-"` // SHA-256 of the emailed token
+	ResetTokenExpiresAt *time.Time `json:"-"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for UserCredential
+func (UserCredential) TableName() string {
+	return "user_credentials"
+}
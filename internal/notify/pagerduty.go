@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PagerDutyEvent is an alert triggering a PagerDuty incident
+type PagerDutyEvent struct {
+	RoutingKey string                 // Integration key of the PagerDuty service
+	DedupKey   string                 // Groups events of the same alert into one incident
+	Summary    string                 // Title of the incident
+	Source     string                 // What the alert is about, e.g. a tenant
+	Severity   string                 // critical, error, warning or info
+	Details    map[string]interface{} // Shown with the incident
+}
+
+// PagerDuty triggers incidents through the PagerDuty Events API v2
+type PagerDuty struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewPagerDuty creates a PagerDuty client sending events to the Events API
+// endpoint at url
+func NewPagerDuty(url string, timeout time.Duration) *PagerDuty {
+	return &PagerDuty{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Trigger sends a trigger event, opening an incident or adding to the open
+// incident of its dedup key
+func (p *PagerDuty) Trigger(ctx context.Context, event PagerDutyEvent) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"routing_key":  event.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    event.DedupKey,
+		"payload": map[string]interface{}{
+			"summary":        event.Summary,
+			"source":         event.Source,
+			"severity":       event.Severity,
+			"custom_details": event.Details,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send PagerDuty event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty returned status %d", resp.StatusCode)
+	}
+	return nil
+}
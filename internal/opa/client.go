@@ -4,34 +4,127 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/resilience"
 )
 
-// Client represents an OPA HTTP client
+// ErrCircuitOpen is returned without contacting OPA while its circuit breaker
+// is open after consecutive failures
+var ErrCircuitOpen = resilience.ErrCircuitOpen
+
+// Client represents an OPA HTTP client. Connections to OPA are kept alive and
+// reused, evaluations and reads are retried with jittered backoff when OPA is
+// unreachable or unavailable, and a circuit breaker fails requests fast after
+// consecutive failures.
 type Client struct {
 	baseURL    string
 	policyPath string
 	httpClient *http.Client
 	timeout    time.Duration
+
+	maxRetries   int
+	retryBackoff time.Duration
+	breaker      *resilience.Breaker
 }
 
 // NewClient creates a new OPA client
 func NewClient(cfg *config.OPAConfig) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConns
+
 	return &Client{
 		baseURL:    cfg.URL,
 		policyPath: cfg.PolicyPath,
 		timeout:    cfg.Timeout,
 		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
+			Timeout:   cfg.Timeout,
+			Transport: transport,
 		},
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		breaker:      resilience.NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
 	}
 }
 
+// BreakerStats returns the state of the circuit breaker and the requests sent to OPA
+func (c *Client) BreakerStats() resilience.BreakerStats {
+	return c.breaker.Snapshot()
+}
+
+// send sends a request to OPA through the circuit breaker. Idempotent requests
+// are retried while OPA refuses connections or answers 502, 503 or 504; timed
+// out requests are not, so a slow OPA does not hold callers for several
+// timeouts.
+func (c *Client) send(req *http.Request, idempotent bool) (*http.Response, error) {
+	ctx := req.Context()
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	attempts := 1
+	if idempotent {
+		attempts += c.maxRetries
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		if attempt >= attempts || ctx.Err() != nil || !retryable(resp, err) {
+			switch {
+			case ctx.Err() != nil:
+				c.breaker.Abandon()
+			case err != nil:
+				c.breaker.Record(err)
+			case resp.StatusCode >= http.StatusInternalServerError:
+				c.breaker.Record(fmt.Errorf("OPA returned status %d", resp.StatusCode))
+			default:
+				c.breaker.Record(nil)
+			}
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		c.breaker.Retried()
+		select {
+		case <-ctx.Done():
+			c.breaker.Abandon()
+			return nil, ctx.Err()
+		case <-time.After(resilience.RetryDelay(c.retryBackoff, attempt)):
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				c.breaker.Abandon()
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether a failed attempt may succeed when retried
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return !errors.As(err, &netErr) || !netErr.Timeout()
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
 // DecisionRequest represents a request to OPA for an authorization decision
 type DecisionRequest struct {
 	Input map[string]interface{} `json:"input"`
@@ -43,6 +136,7 @@ type DecisionResponse struct {
 	DecisionID     string        `json:"decision_id,omitempty"`
 	Provenance     *Provenance   `json:"provenance,omitempty"`
 	Metrics        *Metrics      `json:"metrics,omitempty"`
+	Explanation    []TraceEvent  `json:"explanation,omitempty"`
 }
 
 // Provenance contains information about the decision
@@ -61,8 +155,17 @@ type Metrics struct {
 
 // EvaluatePolicy evaluates a policy at a specific path
 func (c *Client) EvaluatePolicy(ctx context.Context, policyPath string, input map[string]interface{}) (*DecisionResponse, error) {
-	url := fmt.Sprintf("%s/v1/data/%s", c.baseURL, policyPath)
+	return c.evaluate(ctx, fmt.Sprintf("%s/v1/data/%s", c.baseURL, policyPath), input)
+}
 
+// ExplainPolicy evaluates a policy at a specific path with OPA's full
+// explanation, returning the evaluation trace in the response's Explanation
+func (c *Client) ExplainPolicy(ctx context.Context, policyPath string, input map[string]interface{}) (*DecisionResponse, error) {
+	return c.evaluate(ctx, fmt.Sprintf("%s/v1/data/%s?explain=full", c.baseURL, policyPath), input)
+}
+
+// evaluate posts an input to an OPA data API URL
+func (c *Client) evaluate(ctx context.Context, url string, input map[string]interface{}) (*DecisionResponse, error) {
 	req := DecisionRequest{Input: input}
 	reqBody, err := json.Marshal(req)
 	if err != nil {
@@ -76,7 +179,7 @@ func (c *Client) EvaluatePolicy(ctx context.Context, policyPath string, input ma
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -104,6 +207,34 @@ func (c *Client) Evaluate(ctx context.Context, input map[string]interface{}) (*D
 	return c.EvaluatePolicy(ctx, c.policyPath, input)
 }
 
+// PolicyPath returns the default policy path, e.g. heimdall/authz
+func (c *Client) PolicyPath() string {
+	return c.policyPath
+}
+
+// DecisionResult extracts the allow flag and, when the policy sets one, the
+// reason of a decision. Policies may return a boolean or an object with allow.
+func DecisionResult(response *DecisionResponse) (bool, string, error) {
+	switch result := response.Result.(type) {
+	case bool:
+		return result, "", nil
+	case map[string]interface{}:
+		allow, ok := result["allow"].(bool)
+		if !ok {
+			return false, "", fmt.Errorf("unexpected result format: %v", response.Result)
+		}
+		reason, _ := result["reason"].(string)
+		return allow, reason, nil
+	default:
+		return false, "", fmt.Errorf("unexpected result format: %v", response.Result)
+	}
+}
+
+// Explain evaluates the default policy path with OPA's full explanation
+func (c *Client) Explain(ctx context.Context, input map[string]interface{}) (*DecisionResponse, error) {
+	return c.ExplainPolicy(ctx, c.policyPath, input)
+}
+
 // CheckPermission is a convenience method to check if an action is allowed
 func (c *Client) CheckPermission(ctx context.Context, input map[string]interface{}) (bool, error) {
 	decision, err := c.Evaluate(ctx, input)
@@ -135,7 +266,7 @@ func (c *Client) HealthCheck(ctx context.Context) error {
 		return fmt.Errorf("failed to create health check request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, true)
 	if err != nil {
 		return fmt.Errorf("failed to execute health check: %w", err)
 	}
@@ -158,7 +289,7 @@ func (c *Client) ListPolicies(ctx context.Context) (map[string]interface{}, erro
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -190,7 +321,7 @@ func (c *Client) GetData(ctx context.Context, path string) (interface{}, error)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -229,7 +360,7 @@ func (c *Client) PutData(ctx context.Context, path string, data interface{}) err
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, false)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -252,7 +383,7 @@ func (c *Client) DeleteData(ctx context.Context, path string) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, false)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -277,7 +408,7 @@ func (c *Client) UpsertPolicy(ctx context.Context, path string, content string)
 
 	httpReq.Header.Set("Content-Type", "text/plain")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, false)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -304,7 +435,7 @@ func (c *Client) DeletePolicy(ctx context.Context, path string) error {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(httpReq, false)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
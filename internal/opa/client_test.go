@@ -0,0 +1,124 @@
+package opa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/resilience"
+)
+
+func TestClientRetriesAndCircuitBreaker(t *testing.T) {
+	var calls atomic.Int64
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.OPAConfig{
+		URL: server.URL, PolicyPath: "heimdall/authz", Timeout: time.Second,
+		MaxIdleConns: 10, MaxRetries: 2, RetryBackoff: time.Millisecond,
+		BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond,
+	})
+	ctx := context.Background()
+	input := map[string]interface{}{"action": "read"}
+
+	// Evaluations are retried while OPA is unavailable
+	if _, err := client.CheckPermission(ctx, input); err == nil {
+		t.Fatal("Expected the evaluation to fail")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls.Load())
+	}
+
+	// Writes are not retried, and consecutive failures open the breaker
+	if err := client.PutData(ctx, "heimdall/roles", map[string]string{}); err == nil {
+		t.Fatal("Expected the write to fail")
+	}
+	if calls.Load() != 4 {
+		t.Errorf("Expected the write not to be retried, got %d calls", calls.Load())
+	}
+	if _, err := client.CheckPermission(ctx, input); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the open breaker to fail fast, got %v", err)
+	}
+	stats := client.BreakerStats()
+	if stats.State != resilience.BreakerOpen || stats.Trips != 1 || stats.Retries != 2 || stats.Rejected != 1 || calls.Load() != 4 {
+		t.Errorf("Unexpected stats after %d calls: %+v", calls.Load(), stats)
+	}
+
+	// After the cooldown a successful probe closes the breaker
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if state := client.BreakerStats().State; state != resilience.BreakerHalfOpen {
+		t.Errorf("Expected the breaker to be half-open, got %s", state)
+	}
+	if allowed, err := client.CheckPermission(ctx, input); err != nil || !allowed {
+		t.Fatalf("Expected the probe to succeed, got %v, %v", allowed, err)
+	}
+	if stats := client.BreakerStats(); stats.State != resilience.BreakerClosed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("Expected the breaker to close, got %+v", stats)
+	}
+}
+
+func TestEvaluatorDeduplicatesConcurrentChecks(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": {"allow": true}, "decision_id": "d-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: time.Second})
+	evaluator := NewEvaluator(client, nil, false)
+	input := map[string]interface{}{"user": map[string]interface{}{"id": "alice"}, "action": "read"}
+
+	// Identical checks in flight share one round trip
+	const checks = 10
+	results := make(chan error, checks)
+	for i := 0; i < checks; i++ {
+		go func() {
+			decision, err := evaluator.EvaluateWithCacheHints(context.Background(), "alice", input, CacheHints{})
+			if err == nil && (!decision.Allow || decision.DecisionID != "d-1") {
+				err = errors.New("unexpected decision")
+			}
+			results <- err
+		}()
+	}
+	for evaluator.DedupeStats().Requests < checks {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	for i := 0; i < checks; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("Check failed: %v", err)
+		}
+	}
+
+	stats := evaluator.DedupeStats()
+	if calls.Load() != 1 || stats.RoundTrips != 1 || stats.Deduplicated != checks-1 || stats.Rate != 0.9 {
+		t.Errorf("Expected one round trip for %d checks, got %d calls and %+v", checks, calls.Load(), stats)
+	}
+
+	// A cancelled caller stops waiting for the shared round trip
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := evaluator.CanAccessResource(ctx, "alice", "", "", nil, nil, "users", "", "read"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled check to stop waiting, got %v", err)
+	}
+}
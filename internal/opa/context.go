@@ -36,6 +36,8 @@ type UserContext struct {
 	Roles       []string `json:"roles"`
 	Permissions []string `json:"permissions,omitempty"`
 	TenantID    string   `json:"tenantId"`
+	Scope       string   `json:"scope,omitempty"`  // platform or tenant, see helpers.is_platform_scope
+	Status      string   `json:"status,omitempty"` // active or suspended
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -115,6 +117,19 @@ func NewContextBuilderFromFiber(c *fiber.Ctx) *ContextBuilder {
 		builder.input.User.Roles = roles
 	}
 
+	// Set when the user's claims template embeds permissions in access tokens
+	if permissions, ok := c.Locals("permissions").([]string); ok {
+		builder.input.User.Permissions = permissions
+	}
+
+	if status, ok := c.Locals("userStatus").(string); ok {
+		builder.input.User.Status = status
+	}
+
+	if scope, ok := c.Locals("accessScope").(string); ok {
+		builder.input.User.Scope = scope
+	}
+
 	if tenantID, ok := c.Locals("tenantID").(string); ok {
 		builder.input.User.TenantID = tenantID
 		builder.input.Tenant.ID = tenantID
@@ -131,6 +146,21 @@ func NewContextBuilderFromFiber(c *fiber.Ctx) *ContextBuilder {
 		builder.input.Context.MFAVerified = mfaVerified
 	}
 
+	// Session attributes added by login hooks are exposed as user metadata
+	if attributes, ok := c.Locals("sessionAttributes").(map[string]interface{}); ok && len(attributes) > 0 {
+		builder.input.User.Metadata = attributes
+	}
+
+	// Typed attributes of the tenant's user attribute schema take precedence
+	if attributes, ok := c.Locals("userAttributes").(map[string]interface{}); ok && len(attributes) > 0 {
+		builder.input.User.Metadata = MergeMetadata(builder.input.User.Metadata, attributes)
+	}
+
+	// IP access lists enforced on the request's tenant
+	if ipAccess, ok := c.Locals("ipAccess").(map[string]interface{}); ok {
+		builder.input.Tenant.Settings = map[string]interface{}{"ipAccess": ipAccess}
+	}
+
 	return builder
 }
 
@@ -142,6 +172,24 @@ func (b *ContextBuilder) WithUser(userID, email string, roles []string) *Context
 	return b
 }
 
+// WithUserTenant sets the tenant the user belongs to
+func (b *ContextBuilder) WithUserTenant(tenantID string) *ContextBuilder {
+	b.input.User.TenantID = tenantID
+	return b
+}
+
+// WithUserScope sets the access scope of the user's token, platform or tenant
+func (b *ContextBuilder) WithUserScope(scope string) *ContextBuilder {
+	b.input.User.Scope = scope
+	return b
+}
+
+// WithUserStatus sets the user's account status
+func (b *ContextBuilder) WithUserStatus(status string) *ContextBuilder {
+	b.input.User.Status = status
+	return b
+}
+
 // WithUserPermissions adds user permissions
 func (b *ContextBuilder) WithUserPermissions(permissions []string) *ContextBuilder {
 	b.input.User.Permissions = permissions
@@ -193,6 +241,19 @@ func (b *ContextBuilder) WithTenant(tenantID, slug string, settings map[string]i
 	return b
 }
 
+// WithTime sets the time of the request, replacing the current time
+func (b *ContextBuilder) WithTime(t time.Time) *ContextBuilder {
+	b.input.Time = TimeContext{
+		Timestamp:       t,
+		DayOfWeek:       t.Weekday().String(),
+		Hour:            t.Hour(),
+		Minute:          t.Minute(),
+		IsWeekend:       t.Weekday() == time.Saturday || t.Weekday() == time.Sunday,
+		IsBusinessHours: isBusinessHours(t),
+	}
+	return b
+}
+
 // WithIPAddress sets the IP address
 func (b *ContextBuilder) WithIPAddress(ip string) *ContextBuilder {
 	b.input.Context.IPAddress = ip
@@ -217,6 +278,11 @@ func (b *ContextBuilder) WithRequestHeaders(headers map[string]string) *ContextB
 	return b
 }
 
+// Enrich adds the attributes of the enrichment pipeline's sources to the input
+func (b *ContextBuilder) Enrich(ctx context.Context, enrichment *Enrichment) error {
+	return enrichment.Enrich(ctx, b.input)
+}
+
 // Build returns the authorization input
 func (b *ContextBuilder) Build() map[string]interface{} {
 	// Convert struct to map for OPA
@@ -229,6 +295,7 @@ func (b *ContextBuilder) Build() map[string]interface{} {
 		"roles":       b.input.User.Roles,
 		"permissions": b.input.User.Permissions,
 		"tenantId":    b.input.User.TenantID,
+		"status":      b.input.User.Status,
 		"metadata":    b.input.User.Metadata,
 	}
 
@@ -294,15 +361,20 @@ func BuildSimpleInput(userID, tenantID, action, resourceType, resourceID string)
 
 // BuildPermissionCheckInput creates input for checking if a user has a specific permission
 func BuildPermissionCheckInput(userID, tenantID string, roles []string, resource, action string) map[string]interface{} {
+	return newPermissionCheckBuilder(userID, tenantID, roles, resource, "", action).Build()
+}
+
+// newPermissionCheckBuilder creates a builder for checking a user's permission on a resource
+func newPermissionCheckBuilder(userID, tenantID string, roles []string, resource, resourceID, action string) *ContextBuilder {
 	builder := NewContextBuilder()
 	builder.WithUser(userID, "", roles)
 	builder.input.User.TenantID = tenantID
 	builder.WithAction(action)
-	builder.WithResource(resource, "")
+	builder.WithResource(resource, resourceID)
 	builder.WithResourceTenant(tenantID) // Set resource tenant for tenant isolation policy
 	builder.WithTenant(tenantID, "", nil)
 
-	return builder.Build()
+	return builder
 }
 
 // BuildOwnershipCheckInput creates input for checking resource ownership
@@ -317,6 +389,19 @@ func BuildOwnershipCheckInput(userID, tenantID, resourceType, resourceID, ownerI
 	return builder.Build()
 }
 
+// MergeMetadata returns user metadata with the values of overrides replacing
+// those of base, without modifying either
+func MergeMetadata(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
+
 // ParseUUID safely parses a UUID string
 func ParseUUID(s string) string {
 	if _, err := uuid.Parse(s); err != nil {
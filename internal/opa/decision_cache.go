@@ -0,0 +1,183 @@
+package opa
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cache status values reported alongside a decision
+const (
+	CacheStatusHit    = "HIT"    // Served from cache within the freshness lifetime
+	CacheStatusStale  = "STALE"  // Served from cache beyond freshness, within the caller's max-stale
+	CacheStatusMiss   = "MISS"   // Evaluated by OPA, no usable cache entry
+	CacheStatusBypass = "BYPASS" // Evaluated by OPA because caching was disabled or skipped
+)
+
+// CacheHints carries client-supplied freshness preferences for a single decision
+type CacheHints struct {
+	NoCache  bool           // Skip the cache lookup and always evaluate
+	NoStore  bool           // Do not write the result back to the cache
+	MaxAge   *time.Duration // Only accept cached decisions younger than this
+	MaxStale time.Duration  // Accept cached decisions this far past their freshness lifetime
+}
+
+// Decision is an authorization decision together with its cache metadata
+type Decision struct {
+	Allow       bool          `json:"allow"`
+	Reason      string        `json:"reason,omitempty"`
+	DecisionID  string        `json:"decisionId,omitempty"`
+	Obligations []Directive   `json:"obligations,omitempty"` // Must be fulfilled to enforce the decision
+	Advice      []Directive   `json:"advice,omitempty"`      // May be acted on or ignored
+	CacheStatus string        `json:"cacheStatus"`
+	Age         time.Duration `json:"-"`
+	MaxStale    time.Duration `json:"-"`
+}
+
+// cachedDecision is the representation stored in Redis
+type cachedDecision struct {
+	Allow       bool        `json:"allow"`
+	Reason      string      `json:"reason,omitempty"`
+	DecisionID  string      `json:"decisionId,omitempty"`
+	Obligations []Directive `json:"obligations,omitempty"`
+	Advice      []Directive `json:"advice,omitempty"`
+	CachedAt    time.Time   `json:"cachedAt"`
+}
+
+// SetMaxStale sets the upper bound on how stale a decision clients may
+// request. It is safe to call while evaluating.
+func (e *Evaluator) SetMaxStale(maxStale time.Duration) {
+	e.maxStale.Store(int64(maxStale))
+}
+
+// MaxStale returns the upper bound on client-requested staleness
+func (e *Evaluator) MaxStale() time.Duration {
+	return time.Duration(e.maxStale.Load())
+}
+
+// EvaluateWithCacheHints evaluates an authorization input, honouring the caller's cache hints.
+// Cached decisions are fresh for the evaluator's cache TTL and retained for an additional
+// max-stale window, which callers may opt into explicitly.
+func (e *Evaluator) EvaluateWithCacheHints(
+	ctx context.Context,
+	userID string,
+	input map[string]interface{},
+	hints CacheHints,
+) (*Decision, error) {
+	cacheEnabled := e.enableCache && e.cache != nil
+	cacheTTL, maxStaleLimit := e.CacheTTL(), e.MaxStale()
+
+	maxStale := hints.MaxStale
+	if maxStale > maxStaleLimit {
+		maxStale = maxStaleLimit
+	}
+	if maxStale < 0 {
+		maxStale = 0
+	}
+
+	var cacheKey string
+	if cacheEnabled {
+		key, err := buildDecisionCacheKey(userID, input)
+		if err != nil {
+			return nil, err
+		}
+		cacheKey = key
+	}
+
+	status := CacheStatusBypass
+	if cacheEnabled && !hints.NoCache {
+		status = CacheStatusMiss
+
+		var cached cachedDecision
+		if err := e.cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+			age := time.Since(cached.CachedAt)
+			if age < 0 {
+				age = 0
+			}
+
+			limit := cacheTTL + maxStale
+			if hints.MaxAge != nil && *hints.MaxAge < limit {
+				limit = *hints.MaxAge
+			}
+
+			if age <= limit {
+				hitStatus := CacheStatusHit
+				if age > cacheTTL {
+					hitStatus = CacheStatusStale
+				}
+				return &Decision{
+					Allow:       cached.Allow,
+					Reason:      cached.Reason,
+					DecisionID:  cached.DecisionID,
+					Obligations: cached.Obligations,
+					Advice:      cached.Advice,
+					CacheStatus: hitStatus,
+					Age:         age,
+					MaxStale:    maxStale,
+				}, nil
+			}
+		}
+	}
+
+	decision, err := e.decide(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	decision.CacheStatus = status
+	decision.MaxStale = maxStale
+
+	if cacheEnabled && !hints.NoStore {
+		entry := cachedDecision{
+			Allow:       decision.Allow,
+			Reason:      decision.Reason,
+			DecisionID:  decision.DecisionID,
+			Obligations: decision.Obligations,
+			Advice:      decision.Advice,
+			CachedAt:    time.Now(),
+		}
+		_ = e.cache.SetJSON(ctx, cacheKey, entry, cacheTTL+maxStaleLimit)
+	}
+
+	return decision, nil
+}
+
+// buildDecisionCacheKey builds a cache key for an arbitrary authorization input
+func buildDecisionCacheKey(userID string, input map[string]interface{}) (string, error) {
+	hash, err := hashInput(input)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("opa:decision:%s:%s", userID, hash), nil
+}
+
+// hashInput hashes the normalized form of an authorization input. Per-request
+// time fields are dropped so that equivalent checks share a hash.
+func hashInput(input map[string]interface{}) (string, error) {
+	normalized := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		normalized[k] = v
+	}
+
+	if timeMap, ok := input["time"].(map[string]interface{}); ok {
+		stable := make(map[string]interface{}, len(timeMap))
+		for k, v := range timeMap {
+			if k == "timestamp" || k == "minute" {
+				continue
+			}
+			stable[k] = v
+		}
+		normalized["time"] = stable
+	}
+
+	// encoding/json sorts map keys, so the encoding is deterministic
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal input: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
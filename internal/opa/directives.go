@@ -0,0 +1,134 @@
+package opa
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// ObligationMaskFields obliges the handler to remove fields from the resources
+// it returns, e.g. {"type": "mask_fields", "fields": ["email", "metadata.ssn"]}.
+// Nested fields are named by dotted paths.
+const ObligationMaskFields = "mask_fields"
+
+// Directive is an obligation or advice a policy returns with its decision,
+// e.g. {"type": "mask_fields", "fields": ["ssn"]}. The fields besides type are
+// its parameters. Policies may also return bare strings such as
+// "log_elevated", which are directives without parameters.
+type Directive struct {
+	Type       string                 `json:"type"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// DecisionDirectives extracts the obligations and advice of a decision, which
+// policies return as lists in the obligations and advice fields of an object
+// result. Obligations must be fulfilled for the decision to be enforced, so a
+// malformed obligation is an error; malformed advice is dropped.
+func DecisionDirectives(response *DecisionResponse) ([]Directive, []Directive, error) {
+	result, ok := response.Result.(map[string]interface{})
+	if !ok {
+		return nil, nil, nil
+	}
+
+	obligations, err := parseDirectives(result["obligations"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid obligations: %w", err)
+	}
+	for _, obligation := range obligations {
+		if obligation.Type != ObligationMaskFields {
+			continue
+		}
+		if _, err := maskedFields(obligation); err != nil {
+			return nil, nil, fmt.Errorf("invalid %s obligation: %w", ObligationMaskFields, err)
+		}
+	}
+	advice, err := parseDirectives(result["advice"])
+	if err != nil {
+		log.Printf("Ignoring invalid advice of decision %s: %v", response.DecisionID, err)
+		advice = nil
+	}
+	return obligations, advice, nil
+}
+
+// parseDirectives parses a list of directives, which is absent when nil
+func parseDirectives(value interface{}) ([]Directive, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+
+	directives := make([]Directive, 0, len(items))
+	for i, item := range items {
+		switch item := item.(type) {
+		case string:
+			if item == "" {
+				return nil, fmt.Errorf("directive %d has no type", i)
+			}
+			directives = append(directives, Directive{Type: item})
+		case map[string]interface{}:
+			directiveType, _ := item["type"].(string)
+			if directiveType == "" {
+				return nil, fmt.Errorf("directive %d has no type", i)
+			}
+			directive := Directive{Type: directiveType}
+			for key, parameter := range item {
+				if key == "type" {
+					continue
+				}
+				if directive.Parameters == nil {
+					directive.Parameters = make(map[string]interface{})
+				}
+				directive.Parameters[key] = parameter
+			}
+			directives = append(directives, directive)
+		default:
+			return nil, fmt.Errorf("directive %d must be a string or an object, got %T", i, item)
+		}
+	}
+	if len(directives) == 0 {
+		return nil, nil
+	}
+	return directives, nil
+}
+
+// FieldMask returns the fields the mask_fields obligations among obligations
+// remove from responses, sorted and without duplicates
+func FieldMask(obligations []Directive) []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, obligation := range obligations {
+		if obligation.Type != ObligationMaskFields {
+			continue
+		}
+		masked, _ := maskedFields(obligation)
+		for _, field := range masked {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// maskedFields returns the fields of a mask_fields obligation, which must be a
+// non-empty list of field paths
+func maskedFields(obligation Directive) ([]string, error) {
+	items, ok := obligation.Parameters["fields"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("fields must be a non-empty list")
+	}
+	fields := make([]string, 0, len(items))
+	for _, item := range items {
+		field, ok := item.(string)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("fields must be non-empty strings, got %v", item)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -0,0 +1,115 @@
+package opa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/database"
+)
+
+func TestDecisionDirectives(t *testing.T) {
+	obligations, advice, err := DecisionDirectives(&DecisionResponse{Result: map[string]interface{}{
+		"allow": true,
+		"obligations": []interface{}{
+			map[string]interface{}{"type": "mask_fields", "fields": []interface{}{"ssn", "salary"}},
+			"require_approval",
+		},
+		"advice": []interface{}{"log_elevated", map[string]interface{}{"fields": "no type"}},
+	}})
+	if err != nil {
+		t.Fatalf("DecisionDirectives returned error: %v", err)
+	}
+	wantObligations := []Directive{
+		{Type: "mask_fields", Parameters: map[string]interface{}{"fields": []interface{}{"ssn", "salary"}}},
+		{Type: "require_approval"},
+	}
+	if !reflect.DeepEqual(obligations, wantObligations) {
+		t.Errorf("Got obligations %+v, want %+v", obligations, wantObligations)
+	}
+	if advice != nil {
+		t.Errorf("Expected malformed advice to be dropped, got %+v", advice)
+	}
+
+	// Obligations must be enforceable, so malformed ones fail the decision
+	for _, malformed := range []interface{}{
+		"mask_fields",
+		[]interface{}{map[string]interface{}{"fields": "ssn"}},
+		[]interface{}{42.0},
+	} {
+		result := map[string]interface{}{"allow": true, "obligations": malformed}
+		if _, _, err := DecisionDirectives(&DecisionResponse{Result: result}); err == nil {
+			t.Errorf("Expected an error for obligations %v", malformed)
+		}
+	}
+
+	// Boolean results carry no directives
+	if obligations, advice, err := DecisionDirectives(&DecisionResponse{Result: true}); obligations != nil || advice != nil || err != nil {
+		t.Errorf("Expected no directives for a boolean result, got %v %v %v", obligations, advice, err)
+	}
+}
+
+func TestEvaluatorCachesDirectives(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": {"allow": true, "obligations": [{"type": "mask_fields", "fields": ["ssn"]}], "advice": ["log_elevated"]}, "decision_id": "d-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: time.Second})
+	evaluator := NewEvaluator(client, database.NewMemoryClient(), true)
+
+	want := &Decision{
+		Allow:       true,
+		DecisionID:  "d-1",
+		Obligations: []Directive{{Type: "mask_fields", Parameters: map[string]interface{}{"fields": []interface{}{"ssn"}}}},
+		Advice:      []Directive{{Type: "log_elevated"}},
+	}
+	for i, status := range []string{CacheStatusBypass, CacheStatusHit} {
+		decision, err := evaluator.DecideResourceAccess(context.Background(), "alice", "acme", "", nil, nil, "employees", "e-1", "read")
+		if err != nil {
+			t.Fatalf("DecideResourceAccess returned error: %v", err)
+		}
+		want.CacheStatus = status
+		if !reflect.DeepEqual(decision, want) {
+			t.Errorf("Check %d: got %+v, want %+v", i, decision, want)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected the cached decision to keep its directives without asking OPA again, got %d calls", calls.Load())
+	}
+}
+
+func TestFieldMask(t *testing.T) {
+	obligations, _, err := DecisionDirectives(&DecisionResponse{Result: map[string]interface{}{
+		"allow": true,
+		"obligations": []interface{}{
+			map[string]interface{}{"type": "mask_fields", "fields": []interface{}{"metadata", "email"}},
+			"require_approval",
+			map[string]interface{}{"type": "mask_fields", "fields": []interface{}{"email", "content"}},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("DecisionDirectives returned error: %v", err)
+	}
+	if got, want := FieldMask(obligations), []string{"content", "email", "metadata"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got field mask %v, want %v", got, want)
+	}
+
+	// A mask that cannot be applied fails the decision rather than exposing fields
+	for _, fields := range []interface{}{nil, "email", []interface{}{}, []interface{}{"email", 3.0}} {
+		result := map[string]interface{}{"allow": true, "obligations": []interface{}{
+			map[string]interface{}{"type": "mask_fields", "fields": fields},
+		}}
+		if _, _, err := DecisionDirectives(&DecisionResponse{Result: result}); err == nil {
+			t.Errorf("Expected an error for mask_fields with fields %v", fields)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package embedded runs an OPA server inside the Heimdall process, so
+// development setups need no separate OPA. It serves the same REST API as a
+// standalone OPA, so the OPA client is used unchanged.
+package embedded
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/runtime"
+)
+
+// startTimeout bounds how long the server may take to start listening
+const startTimeout = 10 * time.Second
+
+// Server is an OPA server running in this process
+type Server struct {
+	// URL is the base URL of the server's REST API
+	URL string
+
+	dir    string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// Start starts an OPA server on a random port of the loopback interface,
+// loaded with the .rego files of policies
+func Start(ctx context.Context, policies fs.FS) (*Server, error) {
+	dir, err := os.MkdirTemp("", "heimdall-opa-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy directory: %w", err)
+	}
+	if err := copyPolicies(policies, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	params := runtime.NewParams()
+	params.Addrs = &[]string{"127.0.0.1:0"}
+	params.Paths = []string{dir}
+	params.Output = io.Discard
+	params.Logging = runtime.LoggingConfig{Level: "error"}
+	params.GracefulShutdownPeriod = 1
+
+	ctx, cancel := context.WithCancel(ctx)
+	rt, err := runtime.NewRuntime(ctx, params)
+	if err != nil {
+		cancel()
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	s := &Server{dir: dir, cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		s.done <- rt.Serve(ctx)
+	}()
+
+	deadline := time.Now().Add(startTimeout)
+	for {
+		if addrs := rt.Addrs(); len(addrs) > 0 {
+			s.URL = "http://" + addrs[0]
+			return s, nil
+		}
+		select {
+		case err := <-s.done:
+			cancel()
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("embedded OPA stopped: %w", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			s.Stop()
+			return nil, fmt.Errorf("embedded OPA did not start within %s", startTimeout)
+		}
+	}
+}
+
+// Stop shuts the server down and removes its policy files
+func (s *Server) Stop() error {
+	s.cancel()
+	err := <-s.done
+	os.RemoveAll(s.dir)
+	return err
+}
+
+// copyPolicies writes the .rego files of policies to dir, where the runtime
+// loads them from
+func copyPolicies(policies fs.FS, dir string) error {
+	paths, err := fs.Glob(policies, "*.rego")
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+	for _, path := range paths {
+		content, err := fs.ReadFile(policies, path)
+		if err != nil {
+			return fmt.Errorf("failed to read policy %s: %w", path, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), content, 0o600); err != nil {
+			return fmt.Errorf("failed to write policy %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package embedded
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/policies"
+)
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	server, err := Start(ctx, policies.Files)
+	if err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	defer server.Stop()
+
+	client := opa.NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: 5 * time.Second})
+	if err := client.HealthCheck(ctx); err != nil {
+		t.Fatalf("HealthCheck returned error: %v", err)
+	}
+
+	input := func(scope string, roles ...string) map[string]interface{} {
+		return map[string]interface{}{
+			"user":     map[string]interface{}{"id": "u1", "tenantId": "t1", "scope": scope, "roles": roles, "permissions": []string{}},
+			"resource": map[string]interface{}{"type": "users", "tenantId": "t1"},
+			"action":   "read",
+			"context":  map[string]interface{}{},
+		}
+	}
+	allowed, err := client.CheckPermission(ctx, input("platform", "super_admin"))
+	if err != nil {
+		t.Fatalf("CheckPermission returned error: %v", err)
+	}
+	if !allowed {
+		t.Error("Expected the bundled policies to allow super admins")
+	}
+	if allowed, _ := client.CheckPermission(ctx, input("tenant")); allowed {
+		t.Error("Expected the bundled policies to deny users without roles")
+	}
+}
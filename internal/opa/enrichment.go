@@ -0,0 +1,83 @@
+package opa
+
+import (
+	"context"
+	"fmt"
+)
+
+// AnyResourceType registers an attribute source for inputs of every resource type
+const AnyResourceType = "*"
+
+// AttributeSource adds attributes to an authorization input before it is
+// evaluated, e.g. the owner of a resource loaded from the database
+type AttributeSource interface {
+	Name() string
+	// Enrich adds attributes to the input. Errors fail the evaluation, so
+	// policies are never evaluated without the attributes they rely on.
+	Enrich(ctx context.Context, input *AuthorizationInput) error
+}
+
+// Enrichment is the pipeline of attribute sources applied to authorization
+// inputs, configured per resource type. Sources run in registration order,
+// those of every resource type first.
+type Enrichment struct {
+	sources map[string][]AttributeSource
+}
+
+// NewEnrichment creates an empty enrichment pipeline
+func NewEnrichment() *Enrichment {
+	return &Enrichment{sources: make(map[string][]AttributeSource)}
+}
+
+// Register adds an attribute source for a resource type, or for every
+// resource type when it is AnyResourceType
+func (e *Enrichment) Register(resourceType string, source AttributeSource) {
+	e.sources[resourceType] = append(e.sources[resourceType], source)
+}
+
+// Handles reports whether a source is registered for the resource type itself
+func (e *Enrichment) Handles(resourceType string) bool {
+	return e != nil && resourceType != "" && len(e.sources[resourceType]) > 0
+}
+
+// Enrich runs the sources registered for the input's resource type
+func (e *Enrichment) Enrich(ctx context.Context, input *AuthorizationInput) error {
+	if e == nil {
+		return nil
+	}
+
+	sources := e.sources[AnyResourceType]
+	if input.Resource.Type != AnyResourceType {
+		sources = append(sources[:len(sources):len(sources)], e.sources[input.Resource.Type]...)
+	}
+	for _, source := range sources {
+		if err := source.Enrich(ctx, input); err != nil {
+			return fmt.Errorf("attribute source %s failed: %w", source.Name(), err)
+		}
+	}
+	return nil
+}
+
+// ResourceAttributes are attributes of a resource supplied by an attribute source
+type ResourceAttributes struct {
+	OwnerID    string                 `json:"ownerId,omitempty"`
+	TenantID   string                 `json:"tenantId,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// ApplyResourceAttributes sets sourced resource attributes on the input. Sourced
+// values replace those derived from the request, which callers may control.
+func ApplyResourceAttributes(input *AuthorizationInput, sourced *ResourceAttributes) {
+	if sourced == nil {
+		return
+	}
+	if sourced.OwnerID != "" {
+		input.Resource.OwnerID = sourced.OwnerID
+	}
+	if sourced.TenantID != "" {
+		input.Resource.TenantID = sourced.TenantID
+	}
+	if len(sourced.Attributes) > 0 {
+		input.Resource.Attributes = MergeMetadata(input.Resource.Attributes, sourced.Attributes)
+	}
+}
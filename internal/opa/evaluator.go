@@ -2,7 +2,9 @@ package opa
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,68 +16,151 @@ type Evaluator struct {
 	client      *Client
 	cache       *database.RedisClient
 	enableCache bool
-	cacheTTL    time.Duration
+	cacheTTL    atomic.Int64 // time.Duration, changed on configuration reloads
+	maxStale    atomic.Int64 // time.Duration, changed on configuration reloads
+	enrichment  *Enrichment
+	flight      flight
+	rowFilters  atomic.Bool
 }
 
 // NewEvaluator creates a new OPA evaluator
 func NewEvaluator(client *Client, cache *database.RedisClient, enableCache bool) *Evaluator {
-	return &Evaluator{
+	e := &Evaluator{
 		client:      client,
 		cache:       cache,
 		enableCache: enableCache,
-		cacheTTL:    5 * time.Minute, // Default cache TTL
 	}
+	e.SetCacheTTL(5 * time.Minute) // Default cache TTL
+	e.SetMaxStale(5 * time.Minute) // Default max-stale tolerance clients may request
+	return e
 }
 
-// SetCacheTTL sets the cache TTL
+// SetCacheTTL sets the cache TTL. It is safe to call while evaluating.
 func (e *Evaluator) SetCacheTTL(ttl time.Duration) {
-	e.cacheTTL = ttl
+	e.cacheTTL.Store(int64(ttl))
 }
 
-// CanAccessResource checks if a user can perform an action on a resource
+// CacheTTL returns how long cached decisions are fresh
+func (e *Evaluator) CacheTTL() time.Duration {
+	return time.Duration(e.cacheTTL.Load())
+}
+
+// SetRowFilters enables filtering the rows of list endpoints by partially
+// evaluating policies. It is safe to call while evaluating.
+func (e *Evaluator) SetRowFilters(enabled bool) {
+	e.rowFilters.Store(enabled)
+}
+
+// RowFiltersEnabled reports whether list endpoints filter their rows by policy
+func (e *Evaluator) RowFiltersEnabled() bool {
+	return e.rowFilters.Load()
+}
+
+// SetEnrichment sets the attribute sources applied to inputs before evaluation
+func (e *Evaluator) SetEnrichment(enrichment *Enrichment) {
+	e.enrichment = enrichment
+}
+
+// Enrichment returns the attribute sources applied to inputs, nil when none are configured
+func (e *Evaluator) Enrichment() *Enrichment {
+	return e.enrichment
+}
+
+// CanAccessResource checks if a user can perform an action on a resource.
+// permissions are the user's effective permissions when the access token embeds
+// them, nil to leave them to the attribute sources.
 func (e *Evaluator) CanAccessResource(
 	ctx context.Context,
-	userID, tenantID string,
+	userID, tenantID, scope string,
 	roles []string,
+	permissions []string,
 	resource, resourceID string,
 	action string,
 ) (bool, error) {
-	input := BuildPermissionCheckInput(userID, tenantID, roles, resource, action)
-
-	if resourceID != "" {
-		inputMap := input
-		if resourceMap, ok := inputMap["resource"].(map[string]interface{}); ok {
-			resourceMap["id"] = resourceID
-		}
+	decision, err := e.DecideResourceAccess(ctx, userID, tenantID, scope, roles, permissions, resource, resourceID, action)
+	if err != nil {
+		return false, err
 	}
+	return decision.Allow, nil
+}
 
-	// Check cache first if enabled
+// DecideResourceAccess decides whether a user can perform an action on a
+// resource, like CanAccessResource, returning the decision with the
+// obligations and advice of the policy
+func (e *Evaluator) DecideResourceAccess(
+	ctx context.Context,
+	userID, tenantID, scope string,
+	roles []string,
+	permissions []string,
+	resource, resourceID string,
+	action string,
+) (*Decision, error) {
+	// Check cache first if enabled. Decisions without directives are cached as
+	// "1" or "0", others as JSON.
 	if e.enableCache && e.cache != nil {
 		cacheKey := buildCacheKey(userID, resource, resourceID, action)
-		if cached, err := e.cache.Get(ctx, cacheKey); err == nil && cached == "1" {
-			return true, nil
-		} else if err == nil && cached == "0" {
-			return false, nil
+		if cached, err := e.cache.Get(ctx, cacheKey); err == nil {
+			switch cached {
+			case "1":
+				return &Decision{Allow: true, CacheStatus: CacheStatusHit}, nil
+			case "0":
+				return &Decision{Allow: false, CacheStatus: CacheStatusHit}, nil
+			default:
+				var entry cachedDecision
+				if json.Unmarshal([]byte(cached), &entry) == nil {
+					return &Decision{
+						Allow:       entry.Allow,
+						Reason:      entry.Reason,
+						DecisionID:  entry.DecisionID,
+						Obligations: entry.Obligations,
+						Advice:      entry.Advice,
+						CacheStatus: CacheStatusHit,
+					}, nil
+				}
+			}
 		}
 	}
 
+	// Attributes are only fetched for decisions that are not cached
+	builder := newPermissionCheckBuilder(userID, tenantID, roles, resource, resourceID, action)
+	builder.WithUserScope(scope)
+	builder.WithUserPermissions(permissions)
+	if err := builder.Enrich(ctx, e.enrichment); err != nil {
+		return nil, err
+	}
+	input := builder.Build()
+
 	// Evaluate with OPA
-	allowed, err := e.client.CheckPermission(ctx, input)
+	decision, err := e.decide(ctx, input)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	// Cache the result if enabled
 	if e.enableCache && e.cache != nil {
 		cacheKey := buildCacheKey(userID, resource, resourceID, action)
 		cacheValue := "0"
-		if allowed {
+		if decision.Allow {
 			cacheValue = "1"
 		}
-		_ = e.cache.Set(ctx, cacheKey, cacheValue, e.cacheTTL)
+		if len(decision.Obligations) > 0 || len(decision.Advice) > 0 {
+			encoded, err := json.Marshal(cachedDecision{
+				Allow:       decision.Allow,
+				Reason:      decision.Reason,
+				DecisionID:  decision.DecisionID,
+				Obligations: decision.Obligations,
+				Advice:      decision.Advice,
+				CachedAt:    time.Now(),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode decision: %w", err)
+			}
+			cacheValue = string(encoded)
+		}
+		_ = e.cache.Set(ctx, cacheKey, cacheValue, e.CacheTTL())
 	}
 
-	return allowed, nil
+	return decision, nil
 }
 
 // CanAccessOwnResource checks if a user can access their own resource
@@ -86,7 +171,7 @@ func (e *Evaluator) CanAccessOwnResource(
 	action string,
 ) (bool, error) {
 	input := BuildOwnershipCheckInput(userID, tenantID, resourceType, resourceID, ownerID, action)
-	return e.client.CheckPermission(ctx, input)
+	return e.checkPermission(ctx, input)
 }
 
 // EvaluateWithContext evaluates a policy with context from Fiber
@@ -98,9 +183,12 @@ func (e *Evaluator) EvaluateWithContext(
 	builder := NewContextBuilderFromFiber(c)
 	builder.WithResource(resource, "")
 	builder.WithAction(action)
+	if err := builder.Enrich(ctx, e.enrichment); err != nil {
+		return false, err
+	}
 
 	input := builder.Build()
-	return e.client.CheckPermission(ctx, input)
+	return e.checkPermission(ctx, input)
 }
 
 // EvaluateCustom evaluates a custom policy with full input control
@@ -115,7 +203,7 @@ func (e *Evaluator) EvaluateCustom(
 // BatchCheckPermissions checks multiple permissions at once
 func (e *Evaluator) BatchCheckPermissions(
 	ctx context.Context,
-	userID, tenantID string,
+	userID, tenantID, scope string,
 	roles []string,
 	permissions []PermissionCheck,
 ) (map[string]bool, error) {
@@ -127,7 +215,9 @@ func (e *Evaluator) BatchCheckPermissions(
 			ctx,
 			userID,
 			tenantID,
+			scope,
 			roles,
+			nil,
 			perm.Resource,
 			perm.ResourceID,
 			perm.Action,
@@ -143,13 +233,23 @@ func (e *Evaluator) BatchCheckPermissions(
 
 // InvalidateUserCache invalidates all cached permissions for a user
 func (e *Evaluator) InvalidateUserCache(ctx context.Context, userID string) error {
-	if !e.enableCache || e.cache == nil {
+	if e.cache == nil {
 		return nil
 	}
 
-	// Delete all keys matching pattern user:{userID}:*
-	pattern := fmt.Sprintf("opa:permission:%s:*", userID)
-	return e.cache.DeletePattern(ctx, pattern)
+	if e.enableCache {
+		// Delete all keys matching pattern user:{userID}:*
+		pattern := fmt.Sprintf("opa:permission:%s:*", userID)
+		if err := e.cache.DeletePattern(ctx, pattern); err != nil {
+			return err
+		}
+		if err := e.cache.DeletePattern(ctx, fmt.Sprintf("opa:decision:%s:*", userID)); err != nil {
+			return err
+		}
+	}
+
+	// Permissions prefetched for the inputs of the user's decisions
+	return e.cache.InvalidateUserPermissions(ctx, userID)
 }
 
 // PermissionCheck represents a single permission check
@@ -171,7 +271,7 @@ func buildCacheKey(userID, resource, resourceID, action string) string {
 // This is useful for list endpoints where you want to filter results based on access
 func (e *Evaluator) FilterAllowed(
 	ctx context.Context,
-	userID, tenantID string,
+	userID, tenantID, scope string,
 	roles []string,
 	resourceType string,
 	resourceIDs []string,
@@ -184,7 +284,9 @@ func (e *Evaluator) FilterAllowed(
 			ctx,
 			userID,
 			tenantID,
+			scope,
 			roles,
+			nil,
 			resourceType,
 			resourceID,
 			action,
@@ -232,6 +334,21 @@ func (e *Evaluator) EvaluateWithFullContext(
 	ctx context.Context,
 	builder *ContextBuilder,
 ) (bool, error) {
-	input := builder.Build()
-	return e.client.CheckPermission(ctx, input)
+	decision, err := e.DecideWithFullContext(ctx, builder)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allow, nil
+}
+
+// DecideWithFullContext evaluates like EvaluateWithFullContext, returning the
+// decision with the obligations and advice of the policy
+func (e *Evaluator) DecideWithFullContext(
+	ctx context.Context,
+	builder *ContextBuilder,
+) (*Decision, error) {
+	if err := builder.Enrich(ctx, e.enrichment); err != nil {
+		return nil, err
+	}
+	return e.decide(ctx, builder.Build())
 }
@@ -0,0 +1,221 @@
+package opa
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TraceEvent is an event of OPA's evaluation trace, as returned with explain=full
+type TraceEvent struct {
+	Op       string      `json:"op"`
+	QueryID  uint64      `json:"query_id"`
+	ParentID uint64      `json:"parent_id"`
+	Type     string      `json:"type"`
+	Node     interface{} `json:"node,omitempty"`
+	Locals   interface{} `json:"locals,omitempty"`
+	Message  string      `json:"message,omitempty"`
+}
+
+// Explanation describes how an authorization decision was reached
+type Explanation struct {
+	Allow      bool                   `json:"allow" example:"false"`
+	Reason     string                 `json:"reason" example:"access_denied"`
+	DecisionID string                 `json:"decisionId,omitempty"`
+	Denials    []string               `json:"denials,omitempty"`  // Why the request was denied
+	Policies   map[string]interface{} `json:"policies,omitempty"` // Result of each policy module, from decision_details
+	Rules      []FiredRule            `json:"rules"`              // Rules that produced a value
+	InputsUsed []string               `json:"inputsUsed"`         // Input paths the evaluation read, e.g. input.user.roles
+	Notes      []string               `json:"notes,omitempty"`    // Messages of trace() calls in policies
+	Input      map[string]interface{} `json:"input"`
+	Trace      []TraceEvent           `json:"trace,omitempty"`
+}
+
+// FiredRule is a rule that produced a value during evaluation
+type FiredRule struct {
+	Name     string `json:"name" example:"allow"`
+	Location string `json:"location,omitempty" example:"rbac.rego:16"`
+	Count    int    `json:"count" example:"1"`
+}
+
+// Explain evaluates an input with OPA's explanation enabled. Decisions are
+// neither read from nor written to the cache. The raw trace is only included
+// when includeTrace is set, as it grows with the size of the policies.
+func (e *Evaluator) Explain(ctx context.Context, input map[string]interface{}, includeTrace bool) (*Explanation, error) {
+	response, err := e.client.Explain(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		DecisionID: response.DecisionID,
+		Input:      input,
+	}
+	explanation.Allow, explanation.Reason, err = DecisionResult(response)
+	if err != nil {
+		return nil, err
+	}
+	result, _ := response.Result.(map[string]interface{})
+	if explanation.Reason == "" {
+		explanation.Reason = "access_denied"
+		if explanation.Allow {
+			explanation.Reason = "access_granted"
+		}
+	}
+
+	if details, ok := result["decision_details"].(map[string]interface{}); ok {
+		if policies, ok := details["evaluated_policies"].(map[string]interface{}); ok {
+			explanation.Policies = make(map[string]interface{}, len(policies))
+			for name, policy := range policies {
+				if summary, ok := policy.(map[string]interface{}); ok {
+					explanation.Policies[name] = summary["result"]
+				}
+			}
+		}
+	}
+
+	explanation.Rules, explanation.InputsUsed, explanation.Notes = summarizeTrace(response.Explanation)
+	if !explanation.Allow {
+		explanation.Denials = denials(explanation.Policies, result)
+	}
+	if includeTrace {
+		explanation.Trace = response.Explanation
+	}
+	return explanation, nil
+}
+
+// summarizeTrace returns the rules that fired, the input paths read and the
+// notes of a trace
+func summarizeTrace(trace []TraceEvent) ([]FiredRule, []string, []string) {
+	rules := []FiredRule{}
+	ruleIndex := make(map[string]int)
+	inputs := make(map[string]bool)
+	notes := []string{}
+
+	for _, event := range trace {
+		switch {
+		case event.Op == "Exit" && event.Type == "rule":
+			name, location := ruleIdentity(event.Node)
+			key := name + "@" + location
+			if i, ok := ruleIndex[key]; ok {
+				rules[i].Count++
+				continue
+			}
+			ruleIndex[key] = len(rules)
+			rules = append(rules, FiredRule{Name: name, Location: location, Count: 1})
+		case event.Op == "Eval" && event.Type == "expr":
+			collectInputRefs(event.Node, inputs)
+		case event.Op == "Note" && event.Message != "":
+			notes = append(notes, event.Message)
+		}
+	}
+
+	inputsUsed := make([]string, 0, len(inputs))
+	for path := range inputs {
+		inputsUsed = append(inputsUsed, path)
+	}
+	sort.Strings(inputsUsed)
+	return rules, inputsUsed, notes
+}
+
+// ruleIdentity returns the name and, when OPA includes it, the location of a rule node
+func ruleIdentity(node interface{}) (string, string) {
+	rule, ok := node.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+
+	name := ""
+	if head, ok := rule["head"].(map[string]interface{}); ok {
+		name, _ = head["name"].(string)
+		if ref, ok := head["ref"].([]interface{}); ok && name == "" {
+			name = refPath(ref, "")
+		}
+	}
+
+	location := ""
+	if loc, ok := rule["location"].(map[string]interface{}); ok {
+		file, _ := loc["file"].(string)
+		if row, ok := loc["row"].(float64); ok {
+			location = fmt.Sprintf("%s:%d", file, int(row))
+		}
+	}
+	return name, location
+}
+
+// collectInputRefs adds the paths of references to input within a node
+func collectInputRefs(node interface{}, paths map[string]bool) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if value["type"] == "ref" {
+			if ref, ok := value["value"].([]interface{}); ok {
+				if path := refPath(ref, "input"); path != "" {
+					paths[path] = true
+				}
+			}
+		}
+		for _, child := range value {
+			collectInputRefs(child, paths)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectInputRefs(child, paths)
+		}
+	}
+}
+
+// refPath renders the leading constant terms of a reference, e.g.
+// input.user.roles for input.user.roles[_]. A non-empty root requires the
+// reference to start with that variable.
+func refPath(ref []interface{}, root string) string {
+	parts := make([]string, 0, len(ref))
+	for i, term := range ref {
+		t, ok := term.(map[string]interface{})
+		if !ok {
+			break
+		}
+		value, ok := t["value"].(string)
+		if !ok || (t["type"] != "string" && !(i == 0 && t["type"] == "var")) {
+			break
+		}
+		parts = append(parts, value)
+	}
+	if len(parts) == 0 || (root != "" && parts[0] != root) {
+		return ""
+	}
+	return strings.Join(parts, ".")
+}
+
+// denials explains a denied decision from the results of the policy modules
+func denials(policies map[string]interface{}, result map[string]interface{}) []string {
+	var reasons []string
+	if policies != nil {
+		if allowed, ok := policies["tenant_isolation"].(bool); ok && !allowed {
+			reasons = append(reasons, "tenant_isolation: the request crosses tenant boundaries or a tenant isolation rule did not match")
+		}
+
+		var evaluated []string
+		anyAllowed := false
+		for name, value := range policies {
+			if name == "tenant_isolation" {
+				continue
+			}
+			evaluated = append(evaluated, name)
+			if allowed, ok := value.(bool); ok && allowed {
+				anyAllowed = true
+			}
+		}
+		sort.Strings(evaluated)
+		if !anyAllowed && len(evaluated) > 0 {
+			reasons = append(reasons, fmt.Sprintf("no policy allowed the action: %s all denied", strings.Join(evaluated, ", ")))
+		}
+	}
+	if deny, ok := result["deny"].(bool); ok && deny {
+		reasons = append(reasons, "deny: an explicit deny rule matched")
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no rule allowed the action")
+	}
+	return reasons
+}
@@ -0,0 +1,96 @@
+package opa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// explainResponse is a denied decision of the heimdall.authz package with an
+// abbreviated trace
+const explainResponse = `{
+  "decision_id": "d-1",
+  "result": {
+    "allow": false,
+    "reason": "access_denied",
+    "deny": true,
+    "decision_details": {
+      "evaluated_policies": {
+        "rbac": {"evaluated": true, "result": false},
+        "ownership": {"evaluated": true, "result": false},
+        "tenant_isolation": {"evaluated": true, "result": true}
+      }
+    }
+  },
+  "explanation": [
+    {"op": "Enter", "query_id": 1, "parent_id": 0, "type": "rule", "node": {"head": {"name": "allow"}}},
+    {"op": "Eval", "query_id": 1, "parent_id": 0, "type": "expr", "node": {"index": 0, "terms": [
+      {"type": "ref", "value": [{"type": "var", "value": "eq"}]},
+      {"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "user"}, {"type": "string", "value": "roles"}, {"type": "var", "value": "__local0__"}]},
+      {"type": "string", "value": "admin"}
+    ]}},
+    {"op": "Eval", "query_id": 1, "parent_id": 0, "type": "expr", "node": {"index": 1, "terms": {"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "user"}, {"type": "string", "value": "status"}]}}},
+    {"op": "Note", "query_id": 1, "parent_id": 0, "type": "expr", "message": "user is suspended"},
+    {"op": "Exit", "query_id": 1, "parent_id": 0, "type": "rule", "node": {"head": {"name": "global_deny"}, "location": {"file": "authz.rego", "row": 58, "col": 1}}},
+    {"op": "Exit", "query_id": 1, "parent_id": 0, "type": "rule", "node": {"head": {"name": "global_deny"}, "location": {"file": "authz.rego", "row": 58, "col": 1}}},
+    {"op": "Exit", "query_id": 1, "parent_id": 0, "type": "rule", "node": {"head": {"ref": [{"type": "var", "value": "deny"}]}}}
+  ]
+}`
+
+func TestEvaluatorExplain(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(explainResponse))
+	}))
+	defer server.Close()
+
+	evaluator := NewEvaluator(NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: time.Second}), nil, false)
+	input := map[string]interface{}{"action": "read"}
+
+	explanation, err := evaluator.Explain(context.Background(), input, false)
+	if err != nil {
+		t.Fatalf("Failed to explain decision: %v", err)
+	}
+	if query != "explain=full" {
+		t.Errorf("Expected the full explanation to be requested, got %q", query)
+	}
+	if explanation.Allow || explanation.Reason != "access_denied" || explanation.DecisionID != "d-1" {
+		t.Errorf("Unexpected decision: %+v", explanation)
+	}
+
+	wantRules := []FiredRule{
+		{Name: "global_deny", Location: "authz.rego:58", Count: 2},
+		{Name: "deny", Count: 1},
+	}
+	if !reflect.DeepEqual(explanation.Rules, wantRules) {
+		t.Errorf("Expected rules %+v, got %+v", wantRules, explanation.Rules)
+	}
+	if want := []string{"input.user.roles", "input.user.status"}; !reflect.DeepEqual(explanation.InputsUsed, want) {
+		t.Errorf("Expected inputs %v, got %v", want, explanation.InputsUsed)
+	}
+	if len(explanation.Notes) != 1 || explanation.Notes[0] != "user is suspended" {
+		t.Errorf("Expected the policy's note, got %v", explanation.Notes)
+	}
+
+	wantDenials := []string{
+		"no policy allowed the action: ownership, rbac all denied",
+		"deny: an explicit deny rule matched",
+	}
+	if !reflect.DeepEqual(explanation.Denials, wantDenials) {
+		t.Errorf("Expected denials %v, got %v", wantDenials, explanation.Denials)
+	}
+	if explanation.Policies["tenant_isolation"] != true || explanation.Trace != nil {
+		t.Errorf("Expected policy results without trace, got %+v", explanation)
+	}
+
+	if explanation, err = evaluator.Explain(context.Background(), input, true); err != nil || len(explanation.Trace) != 7 {
+		t.Errorf("Expected the raw trace to be included, got %v", err)
+	}
+}
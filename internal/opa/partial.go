@@ -0,0 +1,319 @@
+package opa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Operators of row filter conditions
+const (
+	OperatorEqual        = "eq"
+	OperatorNotEqual     = "neq"
+	OperatorLess         = "lt"
+	OperatorLessEqual    = "lte"
+	OperatorGreater      = "gt"
+	OperatorGreaterEqual = "gte"
+	OperatorIn           = "in"
+)
+
+// rowFilterUnknowns are the parts of the input left unknown when partially
+// evaluating a policy for a list, as they differ from row to row
+var rowFilterUnknowns = []string{"input.resource.id", "input.resource.ownerId", "input.resource.attributes"}
+
+// RowFilter constrains the resources of a type a user may read, derived by
+// partially evaluating the authorization policy with the resource unknown.
+// A resource matches when it satisfies every condition of any of the queries;
+// with no queries, no resource matches.
+type RowFilter struct {
+	Unrestricted bool          `json:"unrestricted"` // Every resource matches
+	Queries      [][]Condition `json:"queries,omitempty"`
+}
+
+// Condition compares a field of a resource with a value
+type Condition struct {
+	Field    string      `json:"field"`    // id, ownerId or attributes.<name>
+	Operator string      `json:"operator"` // eq, neq, lt, lte, gt, gte or in
+	Value    interface{} `json:"value"`    // A string, number or boolean, or a list of them for in
+}
+
+// CompileRequest is a request to OPA's Compile API, partially evaluating a
+// query with parts of the input unknown
+type CompileRequest struct {
+	Query    string                 `json:"query"`
+	Input    map[string]interface{} `json:"input"`
+	Unknowns []string               `json:"unknowns"`
+}
+
+// CompileResponse is OPA's answer to a compile request. Each query is a list
+// of expressions on the unknowns that make the compiled query true together;
+// there are no queries when it can never be true.
+type CompileResponse struct {
+	Result struct {
+		Queries [][]CompiledExpression `json:"queries"`
+	} `json:"result"`
+}
+
+// CompiledExpression is an expression of a partially evaluated query in OPA's
+// JSON AST, e.g. a call of eq on a reference and a string
+type CompiledExpression struct {
+	Negated bool            `json:"negated,omitempty"`
+	Terms   json.RawMessage `json:"terms"`
+	With    json.RawMessage `json:"with,omitempty"`
+}
+
+// astTerm is a term of OPA's JSON AST
+type astTerm struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Compile partially evaluates a query with OPA's Compile API
+func (c *Client) Compile(ctx context.Context, query string, input map[string]interface{}, unknowns []string) (*CompileResponse, error) {
+	reqBody, err := json.Marshal(CompileRequest{Query: query, Input: input, Unknowns: unknowns})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/compile", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.send(httpReq, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OPA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var compiled CompileResponse
+	if err := json.Unmarshal(body, &compiled); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &compiled, nil
+}
+
+// RowFilter partially evaluates the default policy's allow rule for the
+// builder's user, action and resource type, with the ID, owner and attributes
+// of the resource unknown, so list endpoints query only the rows the user may
+// read. Queries that cannot be expressed as conditions on those fields are
+// dropped, so the filter never admits rows the policy would deny.
+func (e *Evaluator) RowFilter(ctx context.Context, builder *ContextBuilder) (*RowFilter, error) {
+	if err := builder.Enrich(ctx, e.enrichment); err != nil {
+		return nil, err
+	}
+	input := builder.Build()
+	resource := input["resource"].(map[string]interface{})
+	delete(resource, "id")
+	delete(resource, "ownerId")
+	delete(resource, "attributes")
+
+	query := "data." + strings.ReplaceAll(e.client.PolicyPath(), "/", ".") + ".allow == true"
+	compiled, err := e.client.Compile(ctx, query, input, rowFilterUnknowns)
+	if err != nil {
+		return nil, err
+	}
+	return NewRowFilter(compiled), nil
+}
+
+// NewRowFilter translates the queries of a compile response into a row filter
+func NewRowFilter(compiled *CompileResponse) *RowFilter {
+	filter := &RowFilter{}
+	for _, expressions := range compiled.Result.Queries {
+		if len(expressions) == 0 {
+			return &RowFilter{Unrestricted: true}
+		}
+		conditions := make([]Condition, 0, len(expressions))
+		for _, expression := range expressions {
+			condition, err := translateExpression(expression)
+			if err != nil {
+				log.Printf("Dropping a query of the row filter: %v", err)
+				conditions = nil
+				break
+			}
+			conditions = append(conditions, condition)
+		}
+		if conditions != nil {
+			filter.Queries = append(filter.Queries, conditions)
+		}
+	}
+	return filter
+}
+
+// comparisons maps the built-in comparison functions to condition operators
+var comparisons = map[string]string{
+	"eq":    OperatorEqual,
+	"equal": OperatorEqual,
+	"neq":   OperatorNotEqual,
+	"lt":    OperatorLess,
+	"lte":   OperatorLessEqual,
+	"gt":    OperatorGreater,
+	"gte":   OperatorGreaterEqual,
+}
+
+// mirrored is the operator comparing the operands the other way round
+var mirrored = map[string]string{
+	OperatorEqual:        OperatorEqual,
+	OperatorNotEqual:     OperatorNotEqual,
+	OperatorLess:         OperatorGreater,
+	OperatorLessEqual:    OperatorGreaterEqual,
+	OperatorGreater:      OperatorLess,
+	OperatorGreaterEqual: OperatorLessEqual,
+}
+
+// translateExpression translates an expression comparing a field of the
+// resource with a value, or testing whether a field is in a collection
+func translateExpression(expression CompiledExpression) (Condition, error) {
+	var terms []astTerm
+	if len(expression.With) > 0 || json.Unmarshal(expression.Terms, &terms) != nil || len(terms) != 3 {
+		return Condition{}, fmt.Errorf("unsupported expression %s", expression.Terms)
+	}
+	function, ok := constantRef(terms[0])
+	if !ok {
+		return Condition{}, fmt.Errorf("unsupported expression %s", expression.Terms)
+	}
+
+	if function == "internal.member_2" {
+		field, ok := resourceField(terms[1])
+		if !ok || expression.Negated {
+			return Condition{}, fmt.Errorf("unsupported membership test %s", expression.Terms)
+		}
+		values, err := collectionValue(terms[2])
+		if err != nil {
+			return Condition{}, err
+		}
+		return Condition{Field: field, Operator: OperatorIn, Value: values}, nil
+	}
+
+	operator, ok := comparisons[function]
+	if !ok {
+		return Condition{}, fmt.Errorf("unsupported function %s", function)
+	}
+	if expression.Negated {
+		// Only equality can be negated; a negated comparison also holds for
+		// resources without the field, which the opposite comparison leaves out
+		switch operator {
+		case OperatorEqual:
+			operator = OperatorNotEqual
+		case OperatorNotEqual:
+			operator = OperatorEqual
+		default:
+			return Condition{}, fmt.Errorf("unsupported negated %s", function)
+		}
+	}
+
+	field, ok := resourceField(terms[1])
+	operand := terms[2]
+	if !ok {
+		if field, ok = resourceField(terms[2]); !ok {
+			return Condition{}, fmt.Errorf("expression %s compares no field of the resource", expression.Terms)
+		}
+		operand = terms[1]
+		operator = mirrored[operator]
+	}
+	value, err := scalarValue(operand)
+	if err != nil {
+		return Condition{}, err
+	}
+	return Condition{Field: field, Operator: operator, Value: value}, nil
+}
+
+// constantRef returns the dotted path of a reference made of a variable followed
+// by strings, e.g. internal.member_2
+func constantRef(term astTerm) (string, bool) {
+	if term.Type != "ref" {
+		return "", false
+	}
+	var parts []astTerm
+	if json.Unmarshal(term.Value, &parts) != nil || len(parts) == 0 || parts[0].Type != "var" {
+		return "", false
+	}
+	names := make([]string, 0, len(parts))
+	for i, part := range parts {
+		if i > 0 && part.Type != "string" {
+			return "", false
+		}
+		var name string
+		if json.Unmarshal(part.Value, &name) != nil {
+			return "", false
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, "."), true
+}
+
+// resourceField returns the field of the resource a reference reads, i.e. id,
+// ownerId or attributes.<name>
+func resourceField(term astTerm) (string, bool) {
+	path, ok := constantRef(term)
+	if !ok {
+		return "", false
+	}
+	field, ok := strings.CutPrefix(path, "input.resource.")
+	if !ok {
+		return "", false
+	}
+	switch {
+	case field == "id", field == "ownerId":
+		return field, true
+	case strings.HasPrefix(field, "attributes.") && strings.Count(field, ".") == 1:
+		return field, true
+	}
+	return "", false
+}
+
+// scalarValue returns the value of a string, number or boolean term
+func scalarValue(term astTerm) (interface{}, error) {
+	switch term.Type {
+	case "string", "boolean":
+		var value interface{}
+		if err := json.Unmarshal(term.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid %s term: %w", term.Type, err)
+		}
+		return value, nil
+	case "number":
+		var value json.Number
+		if err := json.Unmarshal(term.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid number term: %w", err)
+		}
+		if integer, err := value.Int64(); err == nil {
+			return integer, nil
+		}
+		return value.Float64()
+	}
+	return nil, fmt.Errorf("unsupported %s operand", term.Type)
+}
+
+// collectionValue returns the values of an array or set of scalars
+func collectionValue(term astTerm) ([]interface{}, error) {
+	if term.Type != "array" && term.Type != "set" {
+		return nil, fmt.Errorf("unsupported %s collection", term.Type)
+	}
+	var items []astTerm
+	if err := json.Unmarshal(term.Value, &items); err != nil {
+		return nil, fmt.Errorf("invalid %s term: %w", term.Type, err)
+	}
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		value, err := scalarValue(item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
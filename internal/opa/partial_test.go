@@ -0,0 +1,108 @@
+package opa
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+// Expressions of a partially evaluated query in OPA's JSON AST
+const (
+	departmentIsSales = `{"index": 0, "terms": [
+		{"type": "ref", "value": [{"type": "var", "value": "eq"}]},
+		{"type": "string", "value": "sales"},
+		{"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "resource"}, {"type": "string", "value": "attributes"}, {"type": "string", "value": "department"}]}]}`
+	statusIsListed = `{"index": 1, "terms": [
+		{"type": "ref", "value": [{"type": "var", "value": "internal"}, {"type": "string", "value": "member_2"}]},
+		{"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "resource"}, {"type": "string", "value": "attributes"}, {"type": "string", "value": "status"}]},
+		{"type": "array", "value": [{"type": "string", "value": "active"}, {"type": "string", "value": "pending"}]}]}`
+	ownedByAlice = `{"index": 0, "negated": true, "terms": [
+		{"type": "ref", "value": [{"type": "var", "value": "neq"}]},
+		{"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "resource"}, {"type": "string", "value": "ownerId"}]},
+		{"type": "string", "value": "alice"}]}`
+	levelBelowThree = `{"index": 0, "terms": [
+		{"type": "ref", "value": [{"type": "var", "value": "gt"}]},
+		{"type": "number", "value": 3},
+		{"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "resource"}, {"type": "string", "value": "attributes"}, {"type": "string", "value": "level"}]}]}`
+	pathStartsWithHR = `{"index": 0, "terms": [
+		{"type": "ref", "value": [{"type": "var", "value": "startswith"}]},
+		{"type": "ref", "value": [{"type": "var", "value": "input"}, {"type": "string", "value": "resource"}, {"type": "string", "value": "attributes"}, {"type": "string", "value": "path"}]},
+		{"type": "string", "value": "hr/"}]}`
+)
+
+func TestEvaluatorRowFilter(t *testing.T) {
+	var request CompileRequest
+	queries := `[[` + departmentIsSales + `, ` + statusIsListed + `], [` + ownedByAlice + `], [` + levelBelowThree + `], [` + pathStartsWithHR + `]]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/compile" {
+			t.Errorf("Expected a request to the Compile API, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode compile request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": {"queries": ` + queries + `}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: time.Second})
+	evaluator := NewEvaluator(client, nil, false)
+
+	builder := NewContextBuilder().WithUser("alice", "alice@acme.com", []string{"user"}).WithUserTenant("acme").
+		WithResource("users", "").WithResourceTenant("acme").WithAction("read")
+	filter, err := evaluator.RowFilter(context.Background(), builder)
+	if err != nil {
+		t.Fatalf("RowFilter returned error: %v", err)
+	}
+
+	if request.Query != "data.heimdall.authz.allow == true" {
+		t.Errorf("Unexpected query %q", request.Query)
+	}
+	if !reflect.DeepEqual(request.Unknowns, rowFilterUnknowns) {
+		t.Errorf("Unexpected unknowns %v", request.Unknowns)
+	}
+	resource := request.Input["resource"].(map[string]interface{})
+	if _, ok := resource["id"]; ok {
+		t.Errorf("Expected the resource ID to be left unknown, got input resource %v", resource)
+	}
+	if resource["type"] != "users" || resource["tenantId"] != "acme" {
+		t.Errorf("Expected the resource type and tenant in the input, got %v", resource)
+	}
+
+	// Queries that cannot be translated are dropped
+	want := &RowFilter{Queries: [][]Condition{
+		{
+			{Field: "attributes.department", Operator: OperatorEqual, Value: "sales"},
+			{Field: "attributes.status", Operator: OperatorIn, Value: []interface{}{"active", "pending"}},
+		},
+		{{Field: "ownerId", Operator: OperatorEqual, Value: "alice"}},
+		{{Field: "attributes.level", Operator: OperatorLess, Value: int64(3)}},
+	}}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("Got row filter %+v, want %+v", filter, want)
+	}
+}
+
+func TestNewRowFilter(t *testing.T) {
+	var unconditional CompileResponse
+	if err := json.Unmarshal([]byte(`{"result": {"queries": [[`+departmentIsSales+`], []]}}`), &unconditional); err != nil {
+		t.Fatalf("Failed to decode compile response: %v", err)
+	}
+	if filter := NewRowFilter(&unconditional); !filter.Unrestricted {
+		t.Errorf("Expected a query without conditions to admit every row, got %+v", filter)
+	}
+
+	var never CompileResponse
+	if err := json.Unmarshal([]byte(`{"result": {}}`), &never); err != nil {
+		t.Fatalf("Failed to decode compile response: %v", err)
+	}
+	if filter := NewRowFilter(&never); filter.Unrestricted || len(filter.Queries) != 0 {
+		t.Errorf("Expected a policy that never allows to admit no rows, got %+v", filter)
+	}
+}
@@ -0,0 +1,170 @@
+package opa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// RoutePermissionsPath is the OPA data path of the permissions guarding
+// Heimdall's management API, defined by the bundled admin_api.rego
+const RoutePermissionsPath = "heimdall/admin_api"
+
+// RoutePermissionsVersion is the version of the route permissions document
+// this server reads
+const RoutePermissionsVersion = 1
+
+// routePermissionsModule is the bundled policy defining the default route
+// permissions
+const routePermissionsModule = "admin_api.rego"
+
+// Permission is a permission guarding a route
+type Permission struct {
+	Resource string
+	Action   string
+}
+
+// routePermissionsDocument is the document of data.heimdall.admin_api
+type routePermissionsDocument struct {
+	Version int                 `json:"version"`
+	Routes  map[string][]string `json:"routes"` // Permission names by "METHOD /path"
+}
+
+// RoutePermissions maps the routes of Heimdall's management API to the
+// permissions guarding them, defined by the admin_api system policy. The
+// bundled policy applies until Refresh reads a copy loaded into OPA, so
+// operators change the permissions without rebuilding the server.
+type RoutePermissions struct {
+	defaults map[string][]Permission
+	routes   atomic.Pointer[map[string][]Permission]
+}
+
+// NewRoutePermissions evaluates the admin_api.rego of the bundled policies
+// for the default route permissions
+func NewRoutePermissions(ctx context.Context, policies fs.FS) (*RoutePermissions, error) {
+	module, err := fs.ReadFile(policies, routePermissionsModule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", routePermissionsModule, err)
+	}
+
+	results, err := rego.New(
+		rego.Query("data."+strings.ReplaceAll(RoutePermissionsPath, "/", ".")),
+		rego.Module(routePermissionsModule, string(module)),
+	).Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s: %w", routePermissionsModule, err)
+	}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return nil, fmt.Errorf("%s defines no route permissions", routePermissionsModule)
+	}
+	defaults, err := parseRoutePermissions(results[0].Expressions[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", routePermissionsModule, err)
+	}
+
+	permissions := &RoutePermissions{defaults: defaults}
+	permissions.routes.Store(&defaults)
+	return permissions, nil
+}
+
+// Lookup returns the permissions guarding a route, by its method and path as
+// registered, and whether the route is mapped
+func (r *RoutePermissions) Lookup(method, path string) ([]Permission, bool) {
+	permissions, ok := (*r.routes.Load())[routeKey(method, path)]
+	return permissions, ok
+}
+
+// Refresh reads the route permissions loaded into OPA, returning to the
+// bundled ones when none are loaded. Invalid route permissions, e.g. of an
+// unsupported version, are rejected and the current ones kept.
+func (r *RoutePermissions) Refresh(ctx context.Context, client *Client) error {
+	document, err := client.GetData(ctx, RoutePermissionsPath)
+	if err != nil {
+		return err
+	}
+	if document == nil {
+		r.routes.Store(&r.defaults)
+		return nil
+	}
+
+	routes, err := parseRoutePermissions(document)
+	if err != nil {
+		return fmt.Errorf("invalid route permissions in OPA: %w", err)
+	}
+	r.routes.Store(&routes)
+	return nil
+}
+
+// Run refreshes the route permissions right away and then every interval until
+// ctx is cancelled
+func (r *RoutePermissions) Run(ctx context.Context, client *Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx, client); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to refresh the route permissions from OPA: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseRoutePermissions parses a route permissions document, as evaluated by
+// OPA
+func parseRoutePermissions(value interface{}) (map[string][]Permission, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var document routePermissionsDocument
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	if document.Version != RoutePermissionsVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected %d", document.Version, RoutePermissionsVersion)
+	}
+
+	routes := make(map[string][]Permission, len(document.Routes))
+	for route, names := range document.Routes {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route %q, expected a method and path", route)
+		}
+		permissions := make([]Permission, 0, len(names))
+		for _, name := range names {
+			resource, action, ok := strings.Cut(name, ".")
+			if !ok || resource == "" || action == "" {
+				return nil, fmt.Errorf("invalid permission %q of route %q, expected resource.action", name, route)
+			}
+			permissions = append(permissions, Permission{Resource: resource, Action: action})
+		}
+		routes[routeKey(method, path)] = permissions
+	}
+	return routes, nil
+}
+
+// routeKey identifies a route by its method and path, ignoring a trailing
+// slash as the router does. HEAD requests are guarded as the GET routes the
+// router registers them along with.
+func routeKey(method, path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	method = strings.ToUpper(method)
+	if method == "HEAD" {
+		method = "GET"
+	}
+	return method + " " + path
+}
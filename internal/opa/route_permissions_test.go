@@ -0,0 +1,91 @@
+package opa
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/policies"
+)
+
+func TestRoutePermissions(t *testing.T) {
+	ctx := context.Background()
+	permissions, err := NewRoutePermissions(ctx, policies.Files)
+	if err != nil {
+		t.Fatalf("NewRoutePermissions returned error: %v", err)
+	}
+
+	// The bundled policy applies, matching routes registered with a trailing slash
+	tests := []struct {
+		method, path string
+		expected     []Permission
+	}{
+		{"GET", "/v1/users/", []Permission{{Resource: "users", Action: "read"}}},
+		{"HEAD", "/v1/users/:userId", []Permission{{Resource: "users", Action: "read"}}},
+		{"PUT", "/v1/roles/:name", []Permission{{Resource: "roles", Action: "create"}, {Resource: "roles", Action: "update"}}},
+		{"POST", "/v1/bundles/:id/activate", []Permission{{Resource: "bundles", Action: "activate"}}},
+	}
+	for _, tt := range tests {
+		if got, ok := permissions.Lookup(tt.method, tt.path); !ok || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Lookup(%s %s) = %v, %v, expected %v", tt.method, tt.path, got, ok, tt.expected)
+		}
+	}
+	if _, ok := permissions.Lookup("GET", "/v1/users/me"); ok {
+		t.Error("Expected self-service routes not to be mapped")
+	}
+
+	var document atomic.Value
+	document.Store(`{"result": {"version": 1, "routes": {"GET /v1/users": ["users.list"], "GET /v1/jobs": []}}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/data/"+RoutePermissionsPath {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(document.Load().(string)))
+	}))
+	defer server.Close()
+	client := NewClient(&config.OPAConfig{URL: server.URL, Timeout: time.Second, MaxIdleConns: 1})
+
+	// The copy loaded into OPA replaces the bundled one
+	if err := permissions.Refresh(ctx, client); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if got, _ := permissions.Lookup("GET", "/v1/users"); !reflect.DeepEqual(got, []Permission{{Resource: "users", Action: "list"}}) {
+		t.Errorf("Expected the loaded permissions, got %v", got)
+	}
+	if got, ok := permissions.Lookup("GET", "/v1/jobs"); !ok || len(got) != 0 {
+		t.Errorf("Expected a route needing no permissions, got %v, %v", got, ok)
+	}
+	if _, ok := permissions.Lookup("GET", "/v1/users/:userId"); ok {
+		t.Error("Expected routes missing from the loaded policy not to be mapped")
+	}
+
+	// Unsupported versions and malformed permissions are rejected
+	for _, invalid := range []string{
+		`{"result": {"version": 2, "routes": {}}}`,
+		`{"result": {"version": 1, "routes": {"GET /v1/users": ["users"]}}}`,
+		`{"result": {"version": 1, "routes": {"/v1/users": ["users.read"]}}}`,
+	} {
+		document.Store(invalid)
+		if err := permissions.Refresh(ctx, client); err == nil {
+			t.Errorf("Expected %s to be rejected", invalid)
+		}
+	}
+	if got, _ := permissions.Lookup("GET", "/v1/users"); !reflect.DeepEqual(got, []Permission{{Resource: "users", Action: "list"}}) {
+		t.Errorf("Expected the last valid permissions to be kept, got %v", got)
+	}
+
+	// Without a copy in OPA the bundled policy applies again
+	document.Store(`{}`)
+	if err := permissions.Refresh(ctx, client); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+	if got, _ := permissions.Lookup("GET", "/v1/users"); !reflect.DeepEqual(got, []Permission{{Resource: "users", Action: "read"}}) {
+		t.Errorf("Expected the bundled permissions, got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package opa
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// flight deduplicates concurrent evaluations of identical inputs, so a burst
+// of the same check costs one round trip to OPA
+type flight struct {
+	group     singleflight.Group
+	requests  atomic.Int64
+	roundTrip atomic.Int64
+}
+
+// DedupeStats reports how many evaluations shared another's round trip to OPA
+type DedupeStats struct {
+	Requests     int64   `json:"requests"`     // Evaluations requested
+	RoundTrips   int64   `json:"roundTrips"`   // Evaluations sent to OPA
+	Deduplicated int64   `json:"deduplicated"` // Evaluations served by a concurrent identical one
+	Rate         float64 `json:"rate"`         // Share of evaluations deduplicated
+}
+
+// DedupeStats returns the evaluator's deduplication counters since it started
+func (e *Evaluator) DedupeStats() DedupeStats {
+	requests := e.flight.requests.Load()
+	stats := DedupeStats{
+		Requests:   requests,
+		RoundTrips: e.flight.roundTrip.Load(),
+	}
+	stats.Deduplicated = requests - stats.RoundTrips
+	if stats.Deduplicated < 0 {
+		// Evaluations still in flight are counted as requested only
+		stats.Deduplicated = 0
+	}
+	if requests > 0 {
+		stats.Rate = float64(stats.Deduplicated) / float64(requests)
+	}
+	return stats
+}
+
+// evaluate evaluates an input with the default policy, joining an identical
+// evaluation already in flight. The shared round trip is not cancelled with
+// the caller that started it, so the others still get its result; it is
+// bounded by the client's timeout, and each caller stops waiting when its own
+// context is done.
+func (e *Evaluator) evaluate(ctx context.Context, input map[string]interface{}) (*DecisionResponse, error) {
+	key, err := hashInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	e.flight.requests.Add(1)
+	shared := context.WithoutCancel(ctx)
+	result := e.flight.group.DoChan(key, func() (interface{}, error) {
+		e.flight.roundTrip.Add(1)
+		return e.client.Evaluate(shared, input)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// The response is shared by all callers and must not be modified
+		return res.Val.(*DecisionResponse), nil
+	}
+}
+
+// decide evaluates an input with the default policy and returns its decision
+// with the obligations and advice of the policy
+func (e *Evaluator) decide(ctx context.Context, input map[string]interface{}) (*Decision, error) {
+	response, err := e.evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	decision := &Decision{DecisionID: response.DecisionID, CacheStatus: CacheStatusBypass}
+	if decision.Allow, decision.Reason, err = DecisionResult(response); err != nil {
+		return nil, err
+	}
+	if decision.Obligations, decision.Advice, err = DecisionDirectives(response); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// checkPermission evaluates an input with the default policy and returns
+// whether it is allowed
+func (e *Evaluator) checkPermission(ctx context.Context, input map[string]interface{}) (bool, error) {
+	decision, err := e.decide(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allow, nil
+}
@@ -0,0 +1,79 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CheckRoutes compares registered routes with the operations of spec, returning
+// a problem for each route that is not documented and each operation that has
+// no route. Only paths under the base path of the spec's servers are compared;
+// paths with servers of their own, such as health checks, are served outside
+// the API.
+func CheckRoutes(spec *openapi3.T, routes []fiber.Route) []string {
+	base := serverBasePath(spec.Servers)
+
+	documented := make(map[string]string) // Normalized route to its spec path
+	for path, item := range spec.Paths.Map() {
+		if len(item.Servers) > 0 {
+			continue
+		}
+		for method := range item.Operations() {
+			documented[method+" "+normalizeRoute(base+path)] = method + " " + path
+		}
+	}
+
+	var problems []string
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		if route.Method == http.MethodHead || !strings.HasPrefix(route.Path, base+"/") {
+			continue
+		}
+		key := route.Method + " " + normalizeRoute(route.Path)
+		if registered[key] {
+			continue
+		}
+		registered[key] = true
+		if _, ok := documented[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s %s is registered but not documented", route.Method, route.Path))
+		}
+	}
+	for key, operation := range documented {
+		if !registered[key] {
+			problems = append(problems, fmt.Sprintf("%s is documented but not registered", operation))
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// serverBasePath returns the path of the first server's URL, e.g. /v1
+func serverBasePath(servers openapi3.Servers) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// normalizeRoute replaces the parameters of Fiber routes (:id and *) and of
+// spec paths ({id}) alike, and drops trailing slashes
+func normalizeRoute(path string) string {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{") || segment == "*" {
+			segments[i] = "{}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
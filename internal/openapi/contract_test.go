@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/api"
+)
+
+func TestCheckRoutes_API(t *testing.T) {
+	for _, problem := range CheckRoutes(NewGenerator().GenerateSpec(), api.Routes()) {
+		t.Error(problem)
+	}
+}
+
+func TestCheckRoutes_ReportsMismatches(t *testing.T) {
+	spec := &openapi3.T{
+		Servers: openapi3.Servers{{URL: "http://localhost:8080/v1"}},
+		Paths:   &openapi3.Paths{},
+	}
+	spec.Paths.Set("/users/{userId}", &openapi3.PathItem{Get: &openapi3.Operation{}, Delete: &openapi3.Operation{}})
+	spec.Paths.Set("/files/{path}", &openapi3.PathItem{Get: &openapi3.Operation{}})
+	spec.Paths.Set("/health", &openapi3.PathItem{
+		Servers: openapi3.Servers{{URL: "http://localhost:8080"}},
+		Get:     &openapi3.Operation{},
+	})
+
+	routes := []fiber.Route{
+		{Method: "GET", Path: "/v1/users/:userId"},
+		{Method: "HEAD", Path: "/v1/users/:userId"},
+		{Method: "PATCH", Path: "/v1/users/:userId"},
+		{Method: "GET", Path: "/v1/files/*"},
+		{Method: "GET", Path: "/metrics"},
+	}
+
+	problems := CheckRoutes(spec, routes)
+	expected := []string{
+		"DELETE /users/{userId} is documented but not registered",
+		"PATCH /v1/users/:userId is registered but not documented",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("CheckRoutes = %q, want %q", problems, expected)
+	}
+}
@@ -1,22 +1,40 @@
 package openapi
 
 import (
+	"encoding/json"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/api"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/policyfs"
 	"github.com/techsavvyash/heimdall/internal/service"
+	"gorm.io/gorm"
+)
+
+var (
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	deletedAtType     = reflect.TypeOf(gorm.DeletedAt{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
 )
 
 // Generator handles OpenAPI specification generation
 type Generator struct {
-	spec *openapi3.T
+	spec       *openapi3.T
+	components map[reflect.Type]string // Go types registered as component schemas
 }
 
 // NewGenerator creates a new OpenAPI generator
 func NewGenerator() *Generator {
 	return &Generator{
+		components: make(map[reflect.Type]string),
 		spec: &openapi3.T{
 			OpenAPI: "3.0.3",
 			Info: &openapi3.Info{
@@ -64,7 +82,23 @@ func NewGenerator() *Generator {
 				{Name: "Authentication", Description: "Authentication endpoints (login, register, etc.)"},
 				{Name: "User Management", Description: "User CRUD operations"},
 				{Name: "Tenants", Description: "Multi-tenant management"},
+				{Name: "Roles", Description: "Role and permission management by name"},
 				{Name: "Password", Description: "Password management operations"},
+				{Name: "Policies", Description: "OPA policy management"},
+				{Name: "Bundles", Description: "Policy bundle builds and deployments"},
+				{Name: "Authorization", Description: "Authorization decisions"},
+				{Name: "Audit", Description: "Audit trail of sensitive administrative actions"},
+				{Name: "Admin", Description: "Overview of all tenants for administrators"},
+				{Name: "Resources", Description: "Registry of resources whose owner and labels are passed to policies"},
+				{Name: "Access Requests", Description: "Just-in-time access requests granting roles for a limited time once approved"},
+				{Name: "Alert Rules", Description: "Alerts on spikes in a tenant's audit log, delivered by webhook and PagerDuty"},
+				{Name: "Action Nonces", Description: "One-time nonces confirming destructive actions"},
+				{Name: "Jobs", Description: "Background jobs running asynchronous operations, such as bundle builds"},
+				{Name: "Break Glass", Description: "Emergency access with tokens signed offline by operators"},
+				{Name: "OAuth", Description: "OAuth 2.0 token endpoint for machine clients"},
+				{Name: "SAML", Description: "SAML single sign-on, driven by browsers and identity providers"},
+				{Name: "Key Discovery", Description: "Public keys verifying Heimdall's tokens, fetched by JWT libraries"},
+				{Name: "Webhooks", Description: "Webhooks called by external services"},
 				{Name: "Health", Description: "Health check endpoints"},
 			},
 		},
@@ -78,8 +112,21 @@ func (g *Generator) GenerateSpec() *openapi3.T {
 
 	// Add all API paths
 	g.addAuthPaths()
+	g.addOAuthPaths()
+	g.addSAMLPaths()
 	g.addUserPaths()
 	g.addTenantPaths()
+	g.addRolePaths()
+	g.addPolicyPaths()
+	g.addBundlePaths()
+	g.addAuthzPaths()
+	g.addAuditPaths()
+	g.addResourcePaths()
+	g.addAccessRequestPaths()
+	g.addAlertRulePaths()
+	g.addActionNoncePaths()
+	g.addJobPaths()
+	g.addBreakGlassPaths()
 	g.addPasswordPaths()
 	g.addHealthPath()
 
@@ -88,18 +135,169 @@ func (g *Generator) GenerateSpec() *openapi3.T {
 
 // registerSchemas registers all schema components
 func (g *Generator) registerSchemas() {
-	// Request schemas
-	g.addSchemaFromType("RegisterRequest", service.RegisterRequest{})
-	g.addSchemaFromType("LoginRequest", service.LoginRequest{})
-	g.addSchemaFromType("UpdateProfileRequest", service.UpdateProfileRequest{})
-	g.addSchemaFromType("CreateTenantRequest", service.CreateTenantRequest{})
-	g.addSchemaFromType("UpdateTenantRequest", service.UpdateTenantRequest{})
-	g.addSchemaFromType("ChangePasswordRequest", service.ChangePasswordRequest{})
-
-	// Response schemas
-	g.addSchemaFromType("AuthResponse", service.AuthResponse{})
-	g.addSchemaFromType("UserProfile", service.UserProfile{})
-	g.addSchemaFromType("TenantResponse", service.TenantResponse{})
+	schemas := []struct {
+		name string
+		obj  interface{}
+	}{
+		// Request schemas
+		{"RegisterRequest", service.RegisterRequest{}},
+		{"LoginRequest", service.LoginRequest{}},
+		{"TokenExchangeRequest", service.TokenExchangeRequest{}},
+		{"UpdateProfileRequest", service.UpdateProfileRequest{}},
+		{"UpdateUserStatusRequest", service.UpdateUserStatusRequest{}},
+		{"CreateTenantRequest", service.CreateTenantRequest{}},
+		{"UpsertTenantRequest", service.UpsertTenantRequest{}},
+		{"UpsertRoleRequest", service.UpsertRoleRequest{}},
+		{"UpsertPermissionRequest", service.UpsertPermissionRequest{}},
+		{"UpsertPolicyRequest", service.UpsertPolicyRequest{}},
+		{"UpsertSAMLConfigRequest", service.UpsertSAMLConfigRequest{}},
+		{"SAMLRoleMapping", service.SAMLRoleMapping{}},
+		{"UpsertClaimsTemplateRequest", service.UpsertClaimsTemplateRequest{}},
+		{"UpsertRateLimitsRequest", service.UpsertRateLimitsRequest{}},
+		{"UpsertIPAccessRequest", service.UpsertIPAccessRequest{}},
+		{"UpdateDefaultRolesRequest", service.UpdateDefaultRolesRequest{}},
+		{"UpsertUserAttributeSchemaRequest", service.UpsertUserAttributeSchemaRequest{}},
+		{"UserAttributeDefinition", service.UserAttributeDefinition{}},
+		{"CreateOAuthClientRequest", service.CreateOAuthClientRequest{}},
+		{"UpdateOAuthClientRequest", service.UpdateOAuthClientRequest{}},
+		{"RotateOAuthClientSecretRequest", service.RotateOAuthClientSecretRequest{}},
+		{"OAuthTokenRequest", api.OAuthTokenRequest{}},
+		{"DeviceCodeRequest", api.DeviceCodeRequest{}},
+		{"DeviceVerificationRequest", service.DeviceVerificationRequest{}},
+		{"UpdateTenantRequest", service.UpdateTenantRequest{}},
+		{"ChangePasswordRequest", service.ChangePasswordRequest{}},
+		{"ForgotPasswordRequest", service.ForgotPasswordRequest{}},
+		{"ResetPasswordRequest", service.ResetPasswordRequest{}},
+		{"ElevateRoleRequest", service.ElevateRoleRequest{}},
+		{"CreateAccessRequestRequest", service.CreateAccessRequestRequest{}},
+		{"AccessRequestDecision", service.AccessRequestDecision{}},
+		{"AlertRuleRequest", service.AlertRuleRequest{}},
+		{"ActionNonceRequest", service.ActionNonceRequest{}},
+		{"EnableBodyLoggingRequest", service.EnableBodyLoggingRequest{}},
+		{"CreatePolicyRequest", service.CreatePolicyRequest{}},
+		{"UpdatePolicyRequest", service.UpdatePolicyRequest{}},
+		{"SyncPoliciesRequest", service.SyncPoliciesRequest{}},
+		{"CreatePolicyFromTemplateRequest", service.CreatePolicyFromTemplateRequest{}},
+		{"DecisionEvent", service.DecisionEvent{}},
+		{"OPAStatusReport", service.OPAStatusReport{}},
+		{"OPABundleStatus", service.OPABundleStatus{}},
+		{"PolicyRuleSet", service.PolicyRuleSet{}},
+		{"PolicyRule", service.PolicyRule{}},
+		{"PolicyRuleSubjects", service.PolicyRuleSubjects{}},
+		{"PolicyRuleCondition", service.PolicyRuleCondition{}},
+		{"PolicyFile", policyfs.File{}},
+		{"CreateBundleRequest", service.CreateBundleRequest{}},
+		{"AuthzCheckRequest", api.AuthzCheckRequest{}},
+		{"AuthzExplainRequest", api.AuthzExplainRequest{}},
+		{"SimulateRequest", service.SimulateRequest{}},
+		{"SimulationContext", service.SimulationContext{}},
+		{"UpsertResourceRequest", service.UpsertResourceRequest{}},
+		{"ResourceContext", opa.ResourceContext{}},
+
+		// Response schemas
+		{"PolicyTemplate", service.PolicyTemplate{}},
+		{"PolicyTemplateVariable", service.PolicyTemplateVariable{}},
+		{"AuthResponse", service.AuthResponse{}},
+		{"MagicLinkRequest", service.MagicLinkRequest{}},
+		{"MagicLinkResponse", service.MagicLinkResponse{}},
+		{"MagicLinkRedeemRequest", service.MagicLinkRedeemRequest{}},
+		{"TokenExchangeResponse", service.TokenExchangeResponse{}},
+		{"UserInfo", service.UserInfo{}},
+		{"UserProfile", service.UserProfile{}},
+		{"TenantResponse", service.TenantResponse{}},
+		{"RoleResponse", service.RoleResponse{}},
+		{"RoleAssignment", service.RoleAssignmentResponse{}},
+		{"AccessRequest", service.AccessRequestResponse{}},
+		{"AlertRule", service.AlertRuleResponse{}},
+		{"Alert", service.AlertResponse{}},
+		{"ActionNonce", service.ActionNonceResponse{}},
+		{"Job", service.JobResponse{}},
+		{"BreakGlassSession", service.BreakGlassSessionResponse{}},
+		{"PasswordPolicy", service.PasswordPolicy{}},
+		{"SAMLConfigResponse", service.SAMLConfigResponse{}},
+		{"ClaimsTemplateResponse", service.ClaimsTemplateResponse{}},
+		{"RateLimitsResponse", service.RateLimitsResponse{}},
+		{"IPAccessResponse", service.IPAccessResponse{}},
+		{"DefaultRolesResponse", service.DefaultRolesResponse{}},
+		{"DefaultRole", service.DefaultRole{}},
+		{"UserAttributeSchemaResponse", service.UserAttributeSchemaResponse{}},
+		{"OAuthClientResponse", service.OAuthClientResponse{}},
+		{"OAuthTokenResponse", service.OAuthTokenResponse{}},
+		{"OAuthErrorResponse", api.OAuthErrorResponse{}},
+		{"DeviceCodeResponse", service.DeviceCodeResponse{}},
+		{"DeviceAuthorizationResponse", service.DeviceAuthorizationResponse{}},
+		{"Permission", models.Permission{}},
+		{"Pagination", pagination.Page{}},
+		{"Policy", models.Policy{}},
+		{"PolicyVersion", models.PolicyVersion{}},
+		{"PolicyTestResult", service.PolicyTestResult{}},
+		{"PolicyTestCase", models.PolicyTestCase{}},
+		{"PolicyDependencies", service.PolicyDependencies{}},
+		{"PolicyContentRequest", service.PolicyContentRequest{}},
+		{"PolicyTestSuiteRequest", service.PolicyTestSuiteRequest{}},
+		{"PolicyTestSuitePolicy", service.PolicyTestSuitePolicy{}},
+		{"PolicyTestSuiteResult", service.PolicyTestSuiteResult{}},
+		{"PolicyTestSuitePolicyResult", service.PolicyTestSuitePolicyResult{}},
+		{"PolicyFormatResult", service.PolicyFormatResult{}},
+		{"PolicyLintIssue", service.PolicyLintIssue{}},
+		{"PolicyImport", service.PolicyImport{}},
+		{"PolicyReference", service.PolicyReference{}},
+		{"PolicyWarning", service.PolicyWarning{}},
+		{"PolicySyncResult", service.PolicySyncResult{}},
+		{"PolicySyncChange", service.PolicySyncChange{}},
+		{"PolicyBundle", models.PolicyBundle{}},
+		{"BundleDeployment", models.BundleDeployment{}},
+		{"BundleDownloadURL", service.BundleDownloadURLResponse{}},
+		{"BundleContents", service.BundleContentsResponse{}},
+		{"BundleFile", service.BundleFile{}},
+		{"BundleSelector", models.BundleSelector{}},
+		{"LoginEvent", models.LoginEvent{}},
+		{"AdminAction", service.AdminActionResponse{}},
+		{"DecisionLog", service.DecisionLogResponse{}},
+		{"OPAInstance", service.OPAInstanceResponse{}},
+		{"AuthzAnalytics", service.AuthzAnalytics{}},
+		{"AuthzSummary", service.AuthzSummary{}},
+		{"DeniedRoute", service.DeniedRoute{}},
+		{"RoleDenyRate", service.RoleDenyRate{}},
+		{"UnusedPermission", service.UnusedPermission{}},
+		{"DormantRole", service.DormantRole{}},
+		{"UnmatchedPolicy", service.UnmatchedPolicy{}},
+		{"AuthAnalytics", service.AuthAnalytics{}},
+		{"AuthActivitySummary", service.AuthActivitySummary{}},
+		{"AuthActivityDay", service.AuthActivityDay{}},
+		{"PlatformSigningKey", models.PlatformSigningKey{}},
+		{"TenantSigningKey", models.TenantSigningKey{}},
+		{"JWKS", auth.JWKS{}},
+		{"PublicTenant", service.PublicTenant{}},
+		{"TenantBranding", service.TenantBranding{}},
+		{"TenantSSO", service.TenantSSO{}},
+		{"AdminOverview", service.AdminOverview{}},
+		{"BodyLoggingRule", service.BodyLoggingRule{}},
+		{"StatusCounts", service.StatusCounts{}},
+		{"UserOverview", service.UserOverview{}},
+		{"BundleOverview", service.BundleOverview{}},
+		{"ActiveBundleRevision", service.ActiveBundleRevision{}},
+		{"OPAOverview", service.OPAOverview{}},
+		{"FailedDeployment", service.FailedDeployment{}},
+		{"OverviewErrorRates", service.OverviewErrorRates{}},
+		{"OPAInstanceBundle", service.OPAInstanceBundle{}},
+		{"OPAPluginStatus", service.OPAPluginStatus{}},
+		{"Resource", service.ResourceResponse{}},
+		{"AuthzExplanation", opa.Explanation{}},
+		{"AuthzSimulation", service.SimulationResponse{}},
+		{"SimulatedDecision", service.SimulatedDecision{}},
+		{"FiredRule", opa.FiredRule{}},
+		{"TraceEvent", opa.TraceEvent{}},
+	}
+
+	// Register all types first so fields of these types can reference each other
+	for _, schema := range schemas {
+		g.components[reflect.TypeOf(schema.obj)] = schema.name
+	}
+	for _, schema := range schemas {
+		g.addSchemaFromType(schema.name, schema.obj)
+	}
+	g.addAuthzDecisionSchema()
 
 	// Add standard response wrappers
 	g.addStandardResponseSchemas()
@@ -128,6 +326,8 @@ func (g *Generator) reflectTypeToSchema(t reflect.Type) *openapi3.Schema {
 
 	// Process struct fields
 	if t.Kind() == reflect.Struct {
+		validated := hasValidateTags(t)
+
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
 
@@ -155,29 +355,114 @@ func (g *Generator) reflectTypeToSchema(t reflect.Type) *openapi3.Schema {
 				}
 			}
 
+			validateTag := field.Tag.Get("validate")
+
+			// Fields of request types are required when validated as such,
+			// response fields when they are always present
+			required := !omitempty && !strings.Contains(validateTag, "omitempty")
+			if validated {
+				required = hasRule(validateTag, "required")
+			}
+			if required {
+				schema.Required = append(schema.Required, fieldName)
+			}
+
+			// Reference registered component types
+			if ref := g.componentRef(field.Type); ref != nil {
+				schema.Properties[fieldName] = ref
+				continue
+			}
+
 			// Create field schema
 			fieldSchema := g.createFieldSchema(field)
 
 			// Add validation constraints from validate tag
-			validateTag := field.Tag.Get("validate")
 			g.applyValidationConstraints(fieldSchema, validateTag)
 
 			// Add example from example tag
 			exampleTag := field.Tag.Get("example")
 			if exampleTag != "" {
-				fieldSchema.Example = exampleTag
+				fieldSchema.Example = typedExample(fieldSchema, exampleTag)
 			}
 
 			schema.Properties[fieldName] = &openapi3.SchemaRef{Value: fieldSchema}
+		}
+	}
 
-			// Add to required if not omitempty
-			if !omitempty && !strings.Contains(validateTag, "omitempty") {
-				schema.Required = append(schema.Required, fieldName)
+	return schema
+}
+
+// typedExample converts an example tag to the schema's type so the spec stays valid
+func typedExample(schema *openapi3.Schema, example string) interface{} {
+	switch {
+	case schema.Type.Is("integer"):
+		if value, err := strconv.Atoi(example); err == nil {
+			return value
+		}
+	case schema.Type.Is("number"):
+		if value, err := strconv.ParseFloat(example, 64); err == nil {
+			return value
+		}
+	case schema.Type.Is("boolean"):
+		if value, err := strconv.ParseBool(example); err == nil {
+			return value
+		}
+	case schema.Type.Is("array"), schema.Type.Is("object"):
+		var value interface{}
+		if err := json.Unmarshal([]byte(example), &value); err == nil {
+			return value
+		}
+	}
+	return example
+}
+
+// componentRef returns a reference to the component schema of a field type,
+// or nil if the type (or its element type) is not a registered component
+func (g *Generator) componentRef(t reflect.Type) *openapi3.SchemaRef {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if name, ok := g.components[t]; ok {
+		return &openapi3.SchemaRef{Ref: "#/components/schemas/" + name}
+	}
+
+	if t.Kind() == reflect.Slice {
+		if items := g.componentRef(t.Elem()); items != nil {
+			return &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type:  &openapi3.Types{"array"},
+					Items: items,
+				},
 			}
 		}
 	}
 
-	return schema
+	return nil
+}
+
+// hasValidateTags reports whether any field of a struct carries validation rules
+func hasValidateTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("validate") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRule reports whether a validate tag applies the given rule to the field itself,
+// ignoring rules applied to its elements after "dive"
+func hasRule(validateTag, rule string) bool {
+	for _, r := range strings.Split(validateTag, ",") {
+		if r == "dive" {
+			return false
+		}
+		if r == rule {
+			return true
+		}
+	}
+	return false
 }
 
 // createFieldSchema creates a schema for a struct field
@@ -191,6 +476,23 @@ func (g *Generator) createFieldSchema(field reflect.StructField) *openapi3.Schem
 
 	schema := &openapi3.Schema{}
 
+	switch fieldType {
+	case uuidType:
+		schema.Type = &openapi3.Types{"string"}
+		schema.Format = "uuid"
+		return schema
+	case deletedAtType:
+		schema.Type = &openapi3.Types{"string"}
+		schema.Format = "date-time"
+		schema.Nullable = true
+		return schema
+	}
+
+	// Raw JSON columns and interface fields can hold any value
+	if (fieldType.Kind() == reflect.Slice && fieldType.Implements(jsonMarshalerType)) || fieldType.Kind() == reflect.Interface {
+		return schema
+	}
+
 	switch fieldType.Kind() {
 	case reflect.String:
 		schema.Type = &openapi3.Types{"string"}
@@ -258,6 +560,12 @@ func (g *Generator) createFieldSchemaFromType(t reflect.Type) *openapi3.Schema {
 		t = t.Elem()
 	}
 
+	if t == uuidType {
+		schema.Type = &openapi3.Types{"string"}
+		schema.Format = "uuid"
+		return schema
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		schema.Type = &openapi3.Types{"string"}
@@ -265,6 +573,8 @@ func (g *Generator) createFieldSchemaFromType(t reflect.Type) *openapi3.Schema {
 		schema.Type = &openapi3.Types{"integer"}
 	case reflect.Bool:
 		schema.Type = &openapi3.Types{"boolean"}
+	case reflect.Struct, reflect.Map:
+		schema.Type = &openapi3.Types{"object"}
 	default:
 		schema.Type = &openapi3.Types{"string"}
 	}
@@ -350,6 +660,64 @@ func (g *Generator) addStandardResponseSchemas() {
 	}
 }
 
+// addAuthzDecisionSchema adds the authorization decision schema returned by /authz/check
+func (g *Generator) addAuthzDecisionSchema() {
+	integer := &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}}}
+	directives := func(description string) *openapi3.SchemaRef {
+		return &openapi3.SchemaRef{Value: &openapi3.Schema{
+			Type:        &openapi3.Types{"array"},
+			Description: description,
+			Items:       schemaRef("PolicyDirective"),
+		}}
+	}
+
+	g.spec.Components.Schemas["PolicyDirective"] = &openapi3.SchemaRef{
+		Value: &openapi3.Schema{
+			Type: &openapi3.Types{"object"},
+			Properties: openapi3.Schemas{
+				"type": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Example: "mask_fields"}},
+				"parameters": {Value: &openapi3.Schema{
+					Type:                 &openapi3.Types{"object"},
+					AdditionalProperties: openapi3.AdditionalProperties{Has: boolPtr(true)},
+				}},
+			},
+			Required: []string{"type"},
+		},
+	}
+
+	g.spec.Components.Schemas["AuthzDecision"] = &openapi3.SchemaRef{
+		Value: &openapi3.Schema{
+			Type: &openapi3.Types{"object"},
+			Properties: openapi3.Schemas{
+				"decision":    {Value: &openapi3.Schema{Type: &openapi3.Types{"boolean"}, Example: true}},
+				"allow":       {Value: &openapi3.Schema{Type: &openapi3.Types{"boolean"}, Example: true}},
+				"reason":      {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Example: "access_granted"}},
+				"obligations": directives("Directives the policy requires the client to fulfil when enforcing the decision"),
+				"advice":      directives("Directives the policy suggests, which the client may ignore"),
+				"metadata": {
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"decisionId": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+							"cache": {
+								Value: &openapi3.Schema{
+									Type: &openapi3.Types{"object"},
+									Properties: openapi3.Schemas{
+										"status":   {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Enum: []interface{}{opa.CacheStatusHit, opa.CacheStatusStale, opa.CacheStatusMiss, opa.CacheStatusBypass}}},
+										"age":      integer,
+										"maxStale": integer,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Required: []string{"decision", "allow", "reason"},
+		},
+	}
+}
+
 // addErrorSchema adds error response schema
 func (g *Generator) addErrorSchema() {
 	g.spec.Components.Schemas["Error"] = &openapi3.SchemaRef{
@@ -0,0 +1,25 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestGenerateSpec_Valid(t *testing.T) {
+	data, err := json.Marshal(NewGenerator().GenerateSpec())
+	if err != nil {
+		t.Fatalf("Failed to marshal spec: %v", err)
+	}
+
+	// Reload the spec so references are resolved the way clients see them
+	spec, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		t.Errorf("Expected a valid spec, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/oasdiff/yaml"
 	"github.com/swaggest/swgui/v5emb"
 )
 
@@ -15,6 +16,7 @@ import (
 type Handler struct {
 	spec     *openapi3.T
 	specJSON []byte
+	specYAML []byte
 	mu       sync.RWMutex
 }
 
@@ -45,6 +47,12 @@ func (h *Handler) Initialize() error {
 	}
 	h.specJSON = specBytes
 
+	yamlBytes, err := yaml.JSONToYAML(specBytes)
+	if err != nil {
+		return err
+	}
+	h.specYAML = yamlBytes
+
 	return nil
 }
 
@@ -64,22 +72,42 @@ func (h *Handler) ServeSpecJSON(c *fiber.Ctx) error {
 	return c.Send(h.specJSON)
 }
 
+// ServeSpecYAML serves the OpenAPI specification as YAML
+func (h *Handler) ServeSpecYAML(c *fiber.Ctx) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	c.Set("Content-Type", "application/yaml")
+	return c.Send(h.specYAML)
+}
+
 // ServeSwaggerUI serves the Swagger UI
 func (h *Handler) ServeSwaggerUI() http.Handler {
 	// Create Swagger UI handler with custom configuration
 	return v5emb.New(
 		"Heimdall API Documentation",
-		"/swagger/spec", // Path where the OpenAPI spec is served
-		"/swagger",      // Base path for the UI
+		"/v1/openapi.json", // Path where the OpenAPI spec is served
+		"/docs",            // Base path for the UI
 	)
 }
 
-// RegisterRoutes registers the OpenAPI and Swagger UI routes
+// RegisterRoutes registers the OpenAPI and Swagger UI routes.
+// They are public, so they must be registered before the authenticated /v1 routes.
 func (h *Handler) RegisterRoutes(app *fiber.App) {
-	// Serve OpenAPI spec as JSON
-	app.Get("/swagger/spec", h.ServeSpecJSON)
+	// Serve OpenAPI spec as JSON and YAML
+	app.Get("/v1/openapi.json", h.ServeSpecJSON)
+	app.Get("/v1/openapi.yaml", h.ServeSpecYAML)
 
 	// Serve Swagger UI using the adaptor for http.Handler
-	swaggerHandler := h.ServeSwaggerUI()
-	app.Get("/swagger/*", adaptor.HTTPHandler(swaggerHandler))
+	swaggerHandler := adaptor.HTTPHandler(h.ServeSwaggerUI())
+	app.Get("/docs", swaggerHandler)
+	app.Get("/docs/*", swaggerHandler)
+
+	// Keep the previous locations working
+	app.Get("/swagger/spec", func(c *fiber.Ctx) error {
+		return c.Redirect("/v1/openapi.json", fiber.StatusMovedPermanently)
+	})
+	app.Get("/swagger/*", func(c *fiber.Ctx) error {
+		return c.Redirect("/docs/", fiber.StatusMovedPermanently)
+	})
 }
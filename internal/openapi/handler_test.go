@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandler_RegisterRoutes(t *testing.T) {
+	handler := NewHandler()
+	if err := handler.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize handler: %v", err)
+	}
+
+	app := fiber.New()
+	handler.RegisterRoutes(app)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/v1/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var spec struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+	for _, path := range []string{"/policies", "/policies/{id}/versions", "/bundles/{id}/deploy", "/authz/check", "/users/me/permissions", "/users/{userId}/roles"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("Expected spec to document %s", path)
+		}
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/v1/openapi.yaml", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusOK || !strings.Contains(string(body), "openapi: 3.0.3") {
+		t.Errorf("Expected YAML spec, got status %d", resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/docs/", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("Expected Swagger UI to be served, got status %d", resp.StatusCode)
+	}
+}
@@ -1,7 +1,12 @@
 package openapi
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/service"
 )
 
 // addAuthPaths adds authentication-related paths
@@ -25,16 +30,7 @@ func (g *Generator) addAuthPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(201, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("User created successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/AuthResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(201, g.dataResponse("User created successfully", schemaRef("AuthResponse"))),
 				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
 				openapi3.WithStatus(409, g.errorResponse("Email already exists")),
 			),
@@ -60,16 +56,7 @@ func (g *Generator) addAuthPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Login successful"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/AuthResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.dataResponse("Login successful", schemaRef("AuthResponse"))),
 				openapi3.WithStatus(401, g.errorResponse("Invalid credentials")),
 			),
 		},
@@ -106,16 +93,7 @@ func (g *Generator) addAuthPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Token refreshed successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/AuthResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.dataResponse("Token refreshed successfully", schemaRef("AuthResponse"))),
 				openapi3.WithStatus(401, g.errorResponse("Invalid refresh token")),
 			),
 		},
@@ -145,6 +123,24 @@ func (g *Generator) addAuthPaths() {
 		},
 	})
 
+	// POST /auth/token/exchange
+	g.spec.Paths.Set("/auth/token/exchange", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Authentication"},
+			Summary:     "Exchange token",
+			Description: "Exchange the bearer token for a short-lived access token limited to a subset of its permissions and, optionally, to an audience (RFC 8693), e.g. to pass to a third-party service. The new token records the exchanged token in its act claim and is revoked with it. Exchanged tokens are denied permissions outside their scope and cannot be used for self-service endpoints.",
+			OperationID: "exchangeToken",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("TokenExchangeRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Token exchanged successfully", schemaRef("TokenExchangeResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid request or unsupported grant type")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Requested scope or audience exceeds the token's")),
+			),
+		},
+	})
+
 	// POST /auth/logout-all
 	g.spec.Paths.Set("/auth/logout-all", &openapi3.PathItem{
 		Post: &openapi3.Operation{
@@ -168,10 +164,316 @@ func (g *Generator) addAuthPaths() {
 			),
 		},
 	})
+
+	// POST /auth/magic-link
+	g.spec.Paths.Set("/auth/magic-link", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Authentication"},
+			Summary:     "Request magic link",
+			Description: "Email a single-use sign-in link to an active user of the tenant, or of the default tenant when none is given. Unknown emails, inactive users and emails over their hourly limit get the same response without an email, so it does not reveal which emails are registered.",
+			OperationID: "requestMagicLink",
+			RequestBody: jsonRequestBody("MagicLinkRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Magic link sent if the email is registered", schemaRef("MagicLinkResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(404, g.errorResponse("Tenant not found or inactive")),
+			),
+		},
+	})
+
+	// POST /auth/magic-link/verify
+	g.spec.Paths.Set("/auth/magic-link/verify", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Authentication"},
+			Summary:     "Redeem magic link",
+			Description: "Sign the user of a magic link in, running the same login hooks as a password login. Each link can be redeemed once.",
+			OperationID: "redeemMagicLink",
+			RequestBody: jsonRequestBody("MagicLinkRedeemRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Login successful", schemaRef("AuthResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Invalid, expired or already used magic link")),
+				openapi3.WithStatus(403, g.errorResponse("Login rejected by a login hook")),
+			),
+		},
+	})
+
+	// GET /.well-known/jwks.json
+	g.spec.Paths.Set("/.well-known/jwks.json", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Key Discovery"},
+			Summary:     "Get platform JWKS",
+			Description: "Public keys of the platform signing keys, verifying tokens of tenants without signing keys of their own. Cacheable for five minutes.",
+			OperationID: "getSharedJWKS",
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("Key set retrieved successfully"),
+						Content:     openapi3.NewContentWithJSONSchemaRef(schemaRef("JWKS")),
+					},
+				}),
+			),
+		},
+	})
+}
+
+// addSAMLPaths adds the SAML single sign-on endpoints, called by browsers and
+// identity providers rather than API clients
+func (g *Generator) addSAMLPaths() {
+	tenantID := uuidPathParameter("tenantId", "Tenant ID")
+
+	// GET /saml/:tenantId/metadata
+	g.spec.Paths.Set("/saml/{tenantId}/metadata", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"SAML"},
+			Summary:     "Get SAML metadata",
+			Description: "Service provider metadata of a tenant, to register Heimdall with the tenant's identity provider",
+			OperationID: "getSAMLMetadata",
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("Service provider metadata"),
+						Content: openapi3.Content{
+							"application/samlmetadata+xml": {Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}}},
+						},
+					},
+				}),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID")),
+				openapi3.WithStatus(404, g.errorResponse("SAML is not configured for the tenant")),
+			),
+		},
+	})
+
+	// GET /saml/:tenantId/login
+	g.spec.Paths.Set("/saml/{tenantId}/login", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"SAML"},
+			Summary:     "Start SAML login",
+			Description: "Redirect the browser to the tenant's identity provider. After a successful login the tokens are sent to redirect_uri, which must be allowed by SAML_ALLOWED_REDIRECT_URLS.",
+			OperationID: "startSAMLLogin",
+			Parameters: openapi3.Parameters{
+				tenantID,
+				queryParameter("redirect_uri", "URL the browser is sent to with the tokens in its fragment", &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+				}),
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(302, &openapi3.ResponseRef{
+					Value: &openapi3.Response{Description: stringPtr("Redirect to the identity provider")},
+				}),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID or redirect URI")),
+				openapi3.WithStatus(404, g.errorResponse("SAML is not configured for the tenant")),
+			),
+		},
+	})
+
+	// POST /saml/:tenantId/acs
+	g.spec.Paths.Set("/saml/{tenantId}/acs", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"SAML"},
+			Summary:     "Consume SAML response",
+			Description: "Assertion consumer service posted to by the tenant's identity provider. Validates the SAML response and issues tokens; when the relay state is an allowed redirect URL the browser is redirected there with the tokens in the URL fragment.",
+			OperationID: "consumeSAMLResponse",
+			Parameters:  openapi3.Parameters{tenantID},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content: openapi3.Content{
+						"application/x-www-form-urlencoded": {
+							Schema: &openapi3.SchemaRef{
+								Value: &openapi3.Schema{
+									Type: &openapi3.Types{"object"},
+									Properties: openapi3.Schemas{
+										"SAMLResponse": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+										"RelayState":   {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+									},
+									Required: []string{"SAMLResponse"},
+								},
+							},
+						},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Login successful", schemaRef("AuthResponse"))),
+				openapi3.WithStatus(303, &openapi3.ResponseRef{
+					Value: &openapi3.Response{Description: stringPtr("Redirect to the relay state with the tokens")},
+				}),
+				openapi3.WithStatus(400, g.errorResponse("Missing or malformed SAML response")),
+				openapi3.WithStatus(401, g.errorResponse("SAML response could not be validated or was already used")),
+				openapi3.WithStatus(403, g.errorResponse("Login rejected by a login hook")),
+			),
+		},
+	})
+}
+
+// addOAuthPaths adds the OAuth token endpoint, which follows RFC 6749 rather than
+// the API's response envelope
+func (g *Generator) addOAuthPaths() {
+	oauthError := func(description string) *openapi3.ResponseRef {
+		return &openapi3.ResponseRef{
+			Value: &openapi3.Response{
+				Description: stringPtr(description),
+				Content: openapi3.Content{
+					"application/json": {Schema: schemaRef("OAuthErrorResponse")},
+				},
+			},
+		}
+	}
+
+	// POST /oauth/token
+	g.spec.Paths.Set("/oauth/token", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"OAuth"},
+			Summary:     "Issue client token",
+			Description: "Issue an access token to an OAuth client with the client credentials grant (RFC 6749 §4.4). Send the client ID and secret with HTTP Basic authentication or in the body. The token is limited to the requested scope, or to all of the client's scopes, and is confined to the client's tenant. Devices poll this endpoint with the device code grant (urn:ietf:params:oauth:grant-type:device_code, RFC 8628 §3.4) and receive the approving user's access and refresh tokens.",
+			OperationID: "issueOAuthToken",
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content: openapi3.Content{
+						"application/x-www-form-urlencoded": {Schema: schemaRef("OAuthTokenRequest")},
+						"application/json":                  {Schema: schemaRef("OAuthTokenRequest")},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("Token issued successfully"),
+						Content: openapi3.Content{
+							"application/json": {Schema: schemaRef("OAuthTokenResponse")},
+						},
+					},
+				}),
+				openapi3.WithStatus(400, oauthError("Invalid request, unsupported grant type, invalid scope or device authorization not approved")),
+				openapi3.WithStatus(401, oauthError("Invalid client credentials")),
+			),
+		},
+	})
+
+	// POST /oauth/device/code
+	g.spec.Paths.Set("/oauth/device/code", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"OAuth"},
+			Summary:     "Start device authorization",
+			Description: "Start a device authorization (RFC 8628 §3.1) for a CLI or other device without a browser. The device shows the user code and verification URI to the user, then polls the token endpoint with the device code at the given interval until the user approves or denies it.",
+			OperationID: "startDeviceAuthorization",
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content: openapi3.Content{
+						"application/x-www-form-urlencoded": {Schema: schemaRef("DeviceCodeRequest")},
+						"application/json":                  {Schema: schemaRef("DeviceCodeRequest")},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("Device authorization started"),
+						Content: openapi3.Content{
+							"application/json": {Schema: schemaRef("DeviceCodeResponse")},
+						},
+					},
+				}),
+				openapi3.WithStatus(400, oauthError("Invalid request")),
+			),
+		},
+	})
+
+	// GET /oauth/device
+	g.spec.Paths.Set("/oauth/device", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Authentication"},
+			Summary:     "Get device authorization",
+			Description: "Look up the device authorization of a user code, so the verification page can show which client asks to sign in",
+			OperationID: "getDeviceAuthorization",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters: openapi3.Parameters{
+				queryParameter("user_code", "User code shown by the device", &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+				}),
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Device authorization retrieved successfully", schemaRef("DeviceAuthorizationResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Missing user code")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Invalid or expired user code")),
+			),
+		},
+	})
+
+	// POST /oauth/device/approve and /oauth/device/deny
+	for _, decision := range []struct {
+		path, summary, description, operationID string
+	}{
+		{"/oauth/device/approve", "Approve device", "Approve the device authorization of a user code. At its next token request the device is signed in as the current user.", "approveDeviceAuthorization"},
+		{"/oauth/device/deny", "Deny device", "Deny the device authorization of a user code. The device's next token request fails with access_denied.", "denyDeviceAuthorization"},
+	} {
+		g.spec.Paths.Set(decision.path, &openapi3.PathItem{
+			Post: &openapi3.Operation{
+				Tags:        []string{"Authentication"},
+				Summary:     decision.summary,
+				Description: decision.description,
+				OperationID: decision.operationID,
+				Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+				RequestBody: jsonRequestBody("DeviceVerificationRequest", true),
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(200, g.dataResponse("Device authorization updated successfully", schemaRef("DeviceAuthorizationResponse"))),
+					openapi3.WithStatus(400, g.errorResponse("Validation error")),
+					openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+					openapi3.WithStatus(403, g.errorResponse("Scoped and client tokens cannot sign devices in")),
+					openapi3.WithStatus(404, g.errorResponse("Invalid or expired user code")),
+					openapi3.WithStatus(409, g.errorResponse("Device authorization already decided")),
+				),
+			},
+		})
+	}
 }
 
 // addUserPaths adds user management paths
 func (g *Generator) addUserPaths() {
+	// GET /users
+	userParams := g.listParameters(service.UserListOptions)
+	userParams = append(userParams,
+		queryParameter("query", "Match an email prefix, or a first name, last name or full name prefix, case-insensitively. Names are not matched once user metadata is encrypted at rest", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("fuzzy", "Match the query anywhere in the email instead of as a prefix", &openapi3.Schema{
+			Type:    &openapi3.Types{"boolean"},
+			Default: false,
+		}),
+		queryParameter("role", "Filter by assigned role name", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("lastLoginAfter", "Only include users who last logged in at or after this time", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "date-time",
+		}),
+		queryParameter("lastLoginBefore", "Only include users who last logged in before this time", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "date-time",
+		}),
+	)
+	g.spec.Paths.Set("/users", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "List users",
+			Description: "List and search users in the current tenant (admin only)",
+			OperationID: "listUsers",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  userParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Users retrieved successfully", "users", "UserProfile")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
 	// GET /users/me
 	g.spec.Paths.Set("/users/me", &openapi3.PathItem{
 		Get: &openapi3.Operation{
@@ -181,16 +483,7 @@ func (g *Generator) addUserPaths() {
 			OperationID: "getCurrentUser",
 			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("User profile retrieved"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/UserProfile"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.dataResponse("User profile retrieved", schemaRef("UserProfile"))),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 			),
 		},
@@ -212,18 +505,61 @@ func (g *Generator) addUserPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Profile updated successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/UserProfile"},
-							},
+				openapi3.WithStatus(200, g.dataResponse("Profile updated successfully", schemaRef("UserProfile"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
+			),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Delete current user",
+			Description: "Deactivate the authenticated user's account. It is purged after the retention period unless an admin restores it.",
+			OperationID: "deleteCurrentUser",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Account deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+			),
+		},
+	})
+
+	// GET /users/me/permissions
+	g.spec.Paths.Set("/users/me/permissions", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Get my permissions",
+			Description: "Get the permissions granted to the authenticated user through their roles",
+			OperationID: "getMyPermissions",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Permissions retrieved successfully", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"permissions": arrayOf(&openapi3.SchemaRef{
+								Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Example: "users:read"},
+							}),
 						},
 					},
-				}),
+				})),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+			),
+		},
+	})
+
+	// GET /users/me/login-history
+	g.spec.Paths.Set("/users/me/login-history", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Get my login history",
+			Description: "List the authenticated user's recent logins, flagging suspicious ones",
+			OperationID: "getMyLoginHistory",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.LoginHistoryListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Login history retrieved successfully", "logins", "LoginEvent")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
-				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
 			),
 		},
 	})
@@ -253,67 +589,38 @@ func (g *Generator) addUserPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("User retrieved successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/UserProfile"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.dataResponse("User retrieved successfully", schemaRef("UserProfile"))),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
 				openapi3.WithStatus(404, g.errorResponse("User not found")),
 			),
 		},
-		Delete: &openapi3.Operation{
+	})
+
+	// POST /users/:userId/roles
+	g.spec.Paths.Set("/users/{userId}/roles", &openapi3.PathItem{
+		Get: &openapi3.Operation{
 			Tags:        []string{"User Management"},
-			Summary:     "Delete user",
-			Description: "Delete a user account (admin only)",
-			OperationID: "deleteUser",
+			Summary:     "List role assignments",
+			Description: "List the roles assigned to a user with who assigned them and when temporary assignments expire. Expired assignments are listed with expired set until they are removed.",
+			OperationID: "listRoleAssignments",
 			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
-			Parameters: openapi3.Parameters{
-				{
-					Value: &openapi3.Parameter{
-						Name:        "userId",
-						In:          "path",
-						Required:    true,
-						Description: "User ID",
-						Schema: &openapi3.SchemaRef{
-							Value: &openapi3.Schema{
-								Type:   &openapi3.Types{"string"},
-								Format: "uuid",
-							},
-						},
-					},
-				},
-			},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID")},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("User deleted successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/MessageResponse"},
-							},
-						},
+				openapi3.WithStatus(200, g.dataResponse("Role assignments", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type:  &openapi3.Types{"array"},
+						Items: schemaRef("RoleAssignment"),
 					},
-				}),
+				})),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
-				openapi3.WithStatus(404, g.errorResponse("User not found")),
 			),
 		},
-	})
-
-	// POST /users/:userId/roles
-	g.spec.Paths.Set("/users/{userId}/roles", &openapi3.PathItem{
 		Post: &openapi3.Operation{
 			Tags:        []string{"User Management"},
 			Summary:     "Assign role to user",
-			Description: "Assign a role to a user (admin only)",
+			Description: "Assign a role to a user (admin only). Set expiresAt to remove the assignment automatically at that time; assigning a role the user already has replaces its expiry.",
 			OperationID: "assignRoleToUser",
 			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
 			Parameters: openapi3.Parameters{
@@ -347,7 +654,14 @@ func (g *Generator) addUserPaths() {
 												Format: "uuid",
 											},
 										},
-									},
+										"expiresAt": {
+											Value: &openapi3.Schema{
+												Type:        &openapi3.Types{"string"},
+												Format:      "date-time",
+												Description: "Remove the assignment at this time, permanent when omitted",
+											},
+										},
+									},
 									Required: []string{"roleId"},
 								},
 							},
@@ -372,6 +686,27 @@ func (g *Generator) addUserPaths() {
 		},
 	})
 
+	// POST /users/:userId/elevations
+	g.spec.Paths.Set("/users/{userId}/elevations", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Grant temporary elevated access",
+			Description: "Grant a role to a user for a limited time, e.g. admin to an on-call engineer for 8 hours, with the reason recorded in the audit log. The assignment is removed automatically once it expires. Durations are capped by ROLE_ELEVATION_MAX_HOURS.",
+			OperationID: "elevateUserRole",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID")},
+			RequestBody: jsonRequestBody("ElevateRoleRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Elevated access granted", schemaRef("RoleAssignment"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("User or role not found")),
+				openapi3.WithStatus(409, g.errorResponse("User already has the role permanently")),
+			),
+		},
+	})
+
 	// DELETE /users/:userId/roles/:roleId
 	g.spec.Paths.Set("/users/{userId}/roles/{roleId}", &openapi3.PathItem{
 		Delete: &openapi3.Operation{
@@ -426,6 +761,126 @@ func (g *Generator) addUserPaths() {
 			),
 		},
 	})
+
+	// POST /users/:userId/deactivate
+	g.spec.Paths.Set("/users/{userId}/deactivate", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Deactivate user",
+			Description: "Deactivate a user, disabling its login. Deactivated users can be restored until they are purged after the retention period. Must be confirmed with a users.deactivate action nonce (admin only)",
+			OperationID: "deactivateUser",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID"), actionNonceHeader()},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("User deactivated successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or the action nonce is missing, invalid or expired")),
+				openapi3.WithStatus(404, g.errorResponse("User not found")),
+				openapi3.WithStatus(409, g.errorResponse("Action nonce already used")),
+			),
+		},
+	})
+
+	// POST /users/:userId/restore
+	g.spec.Paths.Set("/users/{userId}/restore", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Restore user",
+			Description: "Reactivate a deactivated user that has not been purged (admin only)",
+			OperationID: "restoreUser",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("User restored successfully", schemaRef("UserProfile"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("User not found")),
+				openapi3.WithStatus(409, g.errorResponse("User is not deactivated")),
+			),
+		},
+	})
+
+	// POST /users/:userId/unlock
+	g.spec.Paths.Set("/users/{userId}/unlock", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Unlock user",
+			Description: "Clear a user's lockout after too many failed logins (admin only)",
+			OperationID: "unlockUser",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Account unlocked successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// PATCH /users/:userId/status
+	g.spec.Paths.Set("/users/{userId}/status", &openapi3.PathItem{
+		Patch: &openapi3.Operation{
+			Tags:        []string{"User Management"},
+			Summary:     "Update user status",
+			Description: "Suspend or reactivate a user (admin only). Suspended users cannot sign in or refresh tokens, their sessions are revoked and authorization policies deny their requests.",
+			OperationID: "updateUserStatus",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID")},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required:    true,
+					Description: "New status",
+					Content: openapi3.Content{
+						"application/json": {
+							Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/UpdateUserStatusRequest"},
+						},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("User status updated successfully", schemaRef("UserProfile"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid status")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("User not found")),
+			),
+		},
+	})
+
+	// PATCH /users/:userId/attributes
+	g.spec.Paths.Set("/users/{userId}/attributes", &openapi3.PathItem{
+		Patch: &openapi3.Operation{
+			Tags:        []string{"Users"},
+			Summary:     "Update user attributes",
+			Description: "Set attributes of a user defined by the user attribute schema of the user's tenant. Attributes are stored in the user's metadata and passed to policies as input.user.metadata. A null value removes an attribute. Unknown attributes, values that do not match their definition and missing required attributes fail with 400 INVALID_USER_ATTRIBUTES.",
+			OperationID: "updateUserAttributes",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("userId", "User ID")},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required:    true,
+					Description: "Attribute values by name",
+					Content: openapi3.Content{
+						"application/json": {
+							Schema: &openapi3.SchemaRef{
+								Value: &openapi3.Schema{
+									Type:                 &openapi3.Types{"object"},
+									AdditionalProperties: openapi3.AdditionalProperties{Has: boolPtr(true)},
+								},
+							},
+						},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("User attributes updated successfully", schemaRef("UserProfile"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid attributes")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("User or user attribute schema not found")),
+			),
+		},
+	})
 }
 
 // addTenantPaths adds tenant management paths
@@ -438,55 +893,10 @@ func (g *Generator) addTenantPaths() {
 			Description: "Get all tenants (admin only)",
 			OperationID: "listTenants",
 			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
-			Parameters: openapi3.Parameters{
-				{
-					Value: &openapi3.Parameter{
-						Name:        "page",
-						In:          "query",
-						Description: "Page number",
-						Schema: &openapi3.SchemaRef{
-							Value: &openapi3.Schema{
-								Type:    &openapi3.Types{"integer"},
-								Default: 1,
-								Min:     float64Ptr(1),
-							},
-						},
-					},
-				},
-				{
-					Value: &openapi3.Parameter{
-						Name:        "pageSize",
-						In:          "query",
-						Description: "Items per page",
-						Schema: &openapi3.SchemaRef{
-							Value: &openapi3.Schema{
-								Type:    &openapi3.Types{"integer"},
-								Default: 20,
-								Min:     float64Ptr(1),
-								Max:     float64Ptr(100),
-							},
-						},
-					},
-				},
-			},
+			Parameters:  g.listParameters(service.TenantListOptions),
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Tenants retrieved successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{
-									Value: &openapi3.Schema{
-										Type: &openapi3.Types{"array"},
-										Items: &openapi3.SchemaRef{
-											Ref: "#/components/schemas/TenantResponse",
-										},
-									},
-								},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.listResponse("Tenants retrieved successfully", "tenants", "TenantResponse")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
 			),
@@ -508,16 +918,7 @@ func (g *Generator) addTenantPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(201, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Tenant created successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/TenantResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(201, g.dataResponse("Tenant created successfully", schemaRef("TenantResponse"))),
 				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
@@ -550,16 +951,7 @@ func (g *Generator) addTenantPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Tenant retrieved successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/TenantResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, withETag(g.dataResponse("Tenant retrieved successfully", schemaRef("TenantResponse")))),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
 			),
@@ -597,16 +989,7 @@ func (g *Generator) addTenantPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Tenant updated successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/TenantResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.dataResponse("Tenant updated successfully", schemaRef("TenantResponse"))),
 				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
@@ -616,7 +999,7 @@ func (g *Generator) addTenantPaths() {
 		Delete: &openapi3.Operation{
 			Tags:        []string{"Tenants"},
 			Summary:     "Delete tenant",
-			Description: "Delete a tenant (admin only)",
+			Description: "Delete a tenant. Must be confirmed with a tenants.delete action nonce (admin only)",
 			OperationID: "deleteTenant",
 			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
 			Parameters: openapi3.Parameters{
@@ -634,6 +1017,7 @@ func (g *Generator) addTenantPaths() {
 						},
 					},
 				},
+				actionNonceHeader(),
 			},
 			Responses: openapi3.NewResponses(
 				openapi3.WithStatus(200, &openapi3.ResponseRef{
@@ -647,8 +1031,9 @@ func (g *Generator) addTenantPaths() {
 					},
 				}),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
-				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or the action nonce is missing, invalid or expired")),
 				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
+				openapi3.WithStatus(409, g.errorResponse("Action nonce already used")),
 			),
 		},
 	})
@@ -677,20 +1062,36 @@ func (g *Generator) addTenantPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Tenant retrieved successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/TenantResponse"},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, withETag(g.dataResponse("Tenant retrieved successfully", schemaRef("TenantResponse")))),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
 			),
 		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Upsert tenant by slug",
+			Description: "Create or replace the tenant with the given slug. Send If-Match with a previously returned ETag to update only an unchanged tenant, or If-None-Match: * to only create it.",
+			OperationID: "upsertTenant",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{stringPathParameter("slug", "Tenant slug")}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertTenantRequest", true),
+			Responses:   g.upsertResponses("Tenant", schemaRef("TenantResponse")),
+		},
+	})
+
+	// GET /tenants/slug/:slug/public
+	g.spec.Paths.Set("/tenants/slug/{slug}/public", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get public tenant information",
+			Description: "Get the name, branding and login methods of an active tenant without authentication, so hosted login pages can render the tenant's login. Login methods are those the tenant's loginMethods setting allows, all by default, that are available: saml requires an enabled SAML configuration. Responses may be cached for five minutes and revalidated with If-None-Match.",
+			OperationID: "getPublicTenant",
+			Parameters:  openapi3.Parameters{stringPathParameter("slug", "Tenant slug")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Tenant retrieved successfully", schemaRef("PublicTenant")))),
+				openapi3.WithStatus(404, g.errorResponse("Tenant not found or not active")),
+			),
+		},
 	})
 
 	// GET /tenants/:tenantId/stats
@@ -718,112 +1119,2149 @@ func (g *Generator) addTenantPaths() {
 				},
 			},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Statistics retrieved successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{
-									Value: &openapi3.Schema{
-										Type: &openapi3.Types{"object"},
-										AdditionalProperties: openapi3.AdditionalProperties{
-											Has: boolPtr(true),
-										},
-									},
-								},
-							},
-						},
+				openapi3.WithStatus(200, g.dataResponse("Statistics retrieved successfully", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type:                 &openapi3.Types{"object"},
+						AdditionalProperties: openapi3.AdditionalProperties{Has: boolPtr(true)},
 					},
-				}),
+				})),
 				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
 				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
 			),
 		},
 	})
-}
 
-// addPasswordPaths adds password management paths
-func (g *Generator) addPasswordPaths() {
-	// POST /auth/password/change
-	g.spec.Paths.Set("/auth/password/change", &openapi3.PathItem{
-		Post: &openapi3.Operation{
-			Tags:        []string{"Password"},
-			Summary:     "Change password",
-			Description: "Change password for authenticated user",
-			OperationID: "changePassword",
-			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
-			RequestBody: &openapi3.RequestBodyRef{
-				Value: &openapi3.RequestBody{
-					Required: true,
-					Content: openapi3.Content{
-						"application/json": {
-							Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/ChangePasswordRequest"},
-						},
-					},
-				},
+	tenantID := uuidPathParameter("tenantId", "Tenant ID")
+
+	// POST /tenants/:tenantId/suspend and /tenants/:tenantId/activate
+	for _, transition := range []struct {
+		path, summary, description, operationID, result string
+	}{
+		{"/tenants/{tenantId}/suspend", "Suspend tenant", "Suspend a tenant, rejecting the logins and tokens of its users until it is activated again. Requires a platform-scoped token.", "suspendTenant", "Tenant suspended successfully"},
+		{"/tenants/{tenantId}/activate", "Activate tenant", "Activate a suspended tenant. Requires a platform-scoped token.", "activateTenant", "Tenant activated successfully"},
+	} {
+		g.spec.Paths.Set(transition.path, &openapi3.PathItem{
+			Post: &openapi3.Operation{
+				Tags:        []string{"Tenants"},
+				Summary:     transition.summary,
+				Description: transition.description,
+				OperationID: transition.operationID,
+				Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+				Parameters:  openapi3.Parameters{tenantID},
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(200, g.messageResponse(transition.result)),
+					openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+					openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+					openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
+				),
 			},
+		})
+	}
+
+	// GET /tenants/:tenantId/.well-known/jwks.json
+	g.spec.Paths.Set("/tenants/{tenantId}/.well-known/jwks.json", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Key Discovery"},
+			Summary:     "Get tenant JWKS",
+			Description: "Public keys that may have signed a tenant's tokens: its own signing keys and, so tokens issued before it migrated keep verifying, the platform keys. Cacheable for five minutes.",
+			OperationID: "getTenantJWKS",
+			Parameters:  openapi3.Parameters{tenantID},
 			Responses: openapi3.NewResponses(
 				openapi3.WithStatus(200, &openapi3.ResponseRef{
 					Value: &openapi3.Response{
-						Description: stringPtr("Password changed successfully"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/MessageResponse"},
-							},
-						},
+						Description: stringPtr("Key set retrieved successfully"),
+						Content:     openapi3.NewContentWithJSONSchemaRef(schemaRef("JWKS")),
 					},
 				}),
-				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
-				openapi3.WithStatus(401, g.errorResponse("Invalid current password")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID")),
 			),
 		},
 	})
-}
 
-// addHealthPath adds health check path
-func (g *Generator) addHealthPath() {
-	g.spec.Paths.Set("/health", &openapi3.PathItem{
+	// GET, POST /tenants/:tenantId/signing-keys
+	g.spec.Paths.Set("/tenants/{tenantId}/signing-keys", &openapi3.PathItem{
 		Get: &openapi3.Operation{
-			Tags:        []string{"Health"},
-			Summary:     "Health check",
-			Description: "Basic health check endpoint",
-			OperationID: "healthCheck",
+			Tags:        []string{"Tenants"},
+			Summary:     "List tenant signing keys",
+			Description: "List the keys signing a tenant's tokens",
+			OperationID: "listTenantSigningKeys",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
 			Responses: openapi3.NewResponses(
-				openapi3.WithStatus(200, &openapi3.ResponseRef{
-					Value: &openapi3.Response{
-						Description: stringPtr("Service is healthy"),
-						Content: openapi3.Content{
-							"application/json": {
-								Schema: &openapi3.SchemaRef{
-									Value: &openapi3.Schema{
-										Type: &openapi3.Types{"object"},
-										Properties: openapi3.Schemas{
-											"status": {
-												Value: &openapi3.Schema{
-													Type:    &openapi3.Types{"string"},
-													Example: "healthy",
-												},
-											},
-											"timestamp": {
-												Value: &openapi3.Schema{
-													Type:   &openapi3.Types{"string"},
-													Format: "date-time",
-												},
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-				}),
+				openapi3.WithStatus(200, g.dataResponse("Signing keys retrieved successfully", arrayOf(schemaRef("TenantSigningKey")))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Create tenant signing key",
+			Description: "Issue a new active signing key for a tenant, retiring the current one",
+			OperationID: "createTenantSigningKey",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Signing key created successfully", schemaRef("TenantSigningKey"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
 			),
 		},
 	})
-}
 
-// errorResponse creates a standard error response
-func (g *Generator) errorResponse(description string) *openapi3.ResponseRef {
+	// POST /tenants/:tenantId/signing-keys/migrate
+	g.spec.Paths.Set("/tenants/{tenantId}/signing-keys/migrate", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Migrate to tenant signing keys",
+			Description: "Move a tenant from the shared platform key to signing keys of its own by issuing its first key",
+			OperationID: "migrateTenantSigningKeys",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Signing keys migrated successfully", schemaRef("TenantSigningKey"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(409, g.errorResponse("Tenant already uses signing keys of its own")),
+			),
+		},
+	})
+
+	// DELETE /tenants/:tenantId/signing-keys/:kid
+	g.spec.Paths.Set("/tenants/{tenantId}/signing-keys/{kid}", &openapi3.PathItem{
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Revoke tenant signing key",
+			Description: "Revoke a tenant signing key. Tokens it signed are rejected as soon as each instance's key cache refreshes.",
+			OperationID: "revokeTenantSigningKey",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID, stringPathParameter("kid", "Key ID")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Signing key revoked successfully")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid tenant ID")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Signing key not found")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /tenants/:tenantId/saml
+	g.spec.Paths.Set("/tenants/{tenantId}/saml", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get tenant SAML configuration",
+			Description: "Get a tenant's SAML identity provider settings and the service provider URLs to register with the identity provider",
+			OperationID: "getTenantSAMLConfig",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("SAML configuration retrieved successfully", schemaRef("SAMLConfigResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("SAML configuration not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Upsert tenant SAML configuration",
+			Description: "Create or replace a tenant's SAML configuration from its identity provider metadata. Users signing in with SAML are provisioned by email and receive the roles mapped from the role attribute. Send If-Match with a previously returned ETag to update only an unchanged configuration, or If-None-Match: * to only create it.",
+			OperationID: "upsertTenantSAMLConfig",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{tenantID}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertSAMLConfigRequest", true),
+			Responses:   g.upsertResponses("SAML configuration", schemaRef("SAMLConfigResponse")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Delete tenant SAML configuration",
+			Description: "Delete a tenant's SAML configuration, disabling SAML logins for the tenant",
+			OperationID: "deleteTenantSAMLConfig",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("SAML configuration deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("SAML configuration not found")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /tenants/:tenantId/claims-template
+	g.spec.Paths.Set("/tenants/{tenantId}/claims-template", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get tenant claims template",
+			Description: "Get the template controlling which roles, permissions, tenant metadata and custom claims are embedded in the tenant's access tokens",
+			OperationID: "getTenantClaimsTemplate",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Claims template retrieved successfully", schemaRef("ClaimsTemplateResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Claims template not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Upsert tenant claims template",
+			Description: "Create or replace a tenant's claims template. It applies to access tokens issued at the next login or refresh. Tokens larger than maxTokenSize drop their permissions and set permissionsOmitted, leaving permission checks to the server, then drop tenant metadata and custom claims. Send If-Match with a previously returned ETag to update only an unchanged template, or If-None-Match: * to only create it.",
+			OperationID: "upsertTenantClaimsTemplate",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{tenantID}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertClaimsTemplateRequest", true),
+			Responses:   g.upsertResponses("Claims template", schemaRef("ClaimsTemplateResponse")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Delete tenant claims template",
+			Description: "Delete a tenant's claims template, restoring the default access token claims",
+			OperationID: "deleteTenantClaimsTemplate",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Claims template deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Claims template not found")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /tenants/:tenantId/rate-limits
+	g.spec.Paths.Set("/tenants/{tenantId}/rate-limits", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get tenant rate limits",
+			Description: "Get a tenant's quotas of requests per minute by route class, along with the per-IP limits of the route classes",
+			OperationID: "getTenantRateLimits",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Rate limits retrieved successfully", schemaRef("RateLimitsResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Rate limits not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Upsert tenant rate limits",
+			Description: "Create or replace a tenant's quotas. A quota counts the requests of all of the tenant's users and clients to a route class (auth, authz or default) per minute, on top of the per-IP limits. Requests over the quota fail with 429 TENANT_RATE_LIMIT_EXCEEDED and a Retry-After header. Requires the rate_limits update permission. Send If-Match with a previously returned ETag to update only unchanged quotas, or If-None-Match: * to only create them.",
+			OperationID: "upsertTenantRateLimits",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{tenantID}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertRateLimitsRequest", true),
+			Responses:   g.upsertResponses("Rate limits", schemaRef("RateLimitsResponse")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Delete tenant rate limits",
+			Description: "Delete a tenant's quotas, leaving only the per-IP rate limits",
+			OperationID: "deleteTenantRateLimits",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Rate limits deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Rate limits not found")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /tenants/:tenantId/ip-access
+	g.spec.Paths.Set("/tenants/{tenantId}/ip-access", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get tenant IP access lists",
+			Description: "Get the address ranges a tenant admits and rejects API requests and logins from",
+			OperationID: "getTenantIPAccess",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("IP access lists retrieved successfully", schemaRef("IPAccessResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("IP access lists not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Upsert tenant IP access lists",
+			Description: "Create or replace a tenant's IP access lists of CIDR ranges or single addresses, stored in the tenant's ipAccess setting. Requests carrying the tenant's tokens or X-Tenant-ID header, and logins of its users, from a denylisted address or from outside a non-empty allowlist fail with 403 IP_NOT_ALLOWED before authentication. The lists are also passed to policies as input.tenant.settings.ipAccess. Lists that would reject the address of this request fail with IP_ACCESS_LOCKOUT. Send If-Match with a previously returned ETag to update only unchanged lists, or If-None-Match: * to only create them.",
+			OperationID: "upsertTenantIPAccess",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{tenantID}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertIPAccessRequest", true),
+			Responses:   g.upsertResponses("IP access lists", schemaRef("IPAccessResponse")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Delete tenant IP access lists",
+			Description: "Delete a tenant's IP access lists, admitting requests from any address",
+			OperationID: "deleteTenantIPAccess",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("IP access lists deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("IP access lists not found")),
+			),
+		},
+	})
+
+	// GET, PATCH /tenants/:tenantId/default-roles
+	g.spec.Paths.Set("/tenants/{tenantId}/default-roles", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get tenant default roles",
+			Description: "Get the roles given to users when they register with a tenant",
+			OperationID: "getTenantDefaultRoles",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Default roles retrieved successfully", schemaRef("DefaultRolesResponse"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
+			),
+		},
+		Patch: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Update tenant default roles",
+			Description: "Add roles of the tenant to and remove roles from the roles given to users when they register, stored in the tenant's defaultRoles setting. A tenant has at most 20 default roles. Users registered before keep their roles.",
+			OperationID: "updateTenantDefaultRoles",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			RequestBody: jsonRequestBody("UpdateDefaultRolesRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Default roles updated successfully", schemaRef("DefaultRolesResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid request or too many default roles")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Tenant or role not found")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /tenants/:tenantId/user-attributes
+	g.spec.Paths.Set("/tenants/{tenantId}/user-attributes", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get tenant user attribute schema",
+			Description: "Get the typed attributes a tenant defines for its users",
+			OperationID: "getUserAttributeSchema",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("User attribute schema retrieved successfully", schemaRef("UserAttributeSchemaResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("User attribute schema not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Upsert tenant user attribute schema",
+			Description: "Create or replace the typed attributes of a tenant's users, stored in the tenant's userAttributes setting. User metadata is validated against the schema, and the attributes of the authenticated user are passed to policies as input.user.metadata. Only attributes marked userEditable can be changed by users through their own profile. Send If-Match with a previously returned ETag to update only an unchanged schema, or If-None-Match: * to only create it.",
+			OperationID: "upsertUserAttributeSchema",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{tenantID}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertUserAttributeSchemaRequest", true),
+			Responses:   g.upsertResponses("User attribute schema", schemaRef("UserAttributeSchemaResponse")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Delete tenant user attribute schema",
+			Description: "Delete a tenant's user attribute schema. Existing user metadata is kept but no longer validated or passed to policies.",
+			OperationID: "deleteUserAttributeSchema",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("User attribute schema deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("User attribute schema not found")),
+			),
+		},
+	})
+
+	// GET, POST /tenants/:tenantId/oauth-clients
+	clientID := stringPathParameter("clientId", "OAuth client ID")
+	g.spec.Paths.Set("/tenants/{tenantId}/oauth-clients", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "List OAuth clients",
+			Description: "List the OAuth clients of a tenant. Client secrets are never returned.",
+			OperationID: "listTenantOAuthClients",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("OAuth clients retrieved successfully", arrayOf(schemaRef("OAuthClientResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Create OAuth client",
+			Description: "Register an OAuth client that obtains access tokens for the given permission scopes with the client credentials grant at /oauth/token, e.g. for backend jobs. The client secret is only returned in this response. Client tokens cannot manage OAuth clients.",
+			OperationID: "createTenantOAuthClient",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID},
+			RequestBody: jsonRequestBody("CreateOAuthClientRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("OAuth client created successfully", schemaRef("OAuthClientResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error or unknown scope")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
+			),
+		},
+	})
+
+	// GET, PATCH, DELETE /tenants/:tenantId/oauth-clients/:clientId
+	g.spec.Paths.Set("/tenants/{tenantId}/oauth-clients/{clientId}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Get OAuth client",
+			Description: "Get an OAuth client of a tenant",
+			OperationID: "getTenantOAuthClient",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID, clientID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("OAuth client retrieved successfully", schemaRef("OAuthClientResponse"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("OAuth client not found")),
+			),
+		},
+		Patch: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Update OAuth client",
+			Description: "Change the name or scopes of an OAuth client. Tokens already issued keep their scope until they expire.",
+			OperationID: "updateTenantOAuthClient",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID, clientID},
+			RequestBody: jsonRequestBody("UpdateOAuthClientRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("OAuth client updated successfully", schemaRef("OAuthClientResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error or unknown scope")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("OAuth client not found")),
+			),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Delete OAuth client",
+			Description: "Delete an OAuth client. Tokens already issued to it stay valid until they expire.",
+			OperationID: "deleteTenantOAuthClient",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID, clientID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("OAuth client deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("OAuth client not found")),
+			),
+		},
+	})
+
+	// POST /tenants/:tenantId/oauth-clients/:clientId/rotate-secret
+	g.spec.Paths.Set("/tenants/{tenantId}/oauth-clients/{clientId}/rotate-secret", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Tenants"},
+			Summary:     "Rotate OAuth client secret",
+			Description: "Issue a new secret for an OAuth client, returned only in this response. The previous secret stays valid for the optional grace period so deployments can switch over.",
+			OperationID: "rotateTenantOAuthClientSecret",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{tenantID, clientID},
+			RequestBody: jsonRequestBody("RotateOAuthClientSecretRequest", false),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Client secret rotated successfully", schemaRef("OAuthClientResponse"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("OAuth client not found")),
+			),
+		},
+	})
+}
+
+// addRolePaths adds role and permission management paths
+func (g *Generator) addRolePaths() {
+	// GET, PUT, DELETE /roles/:name
+	g.spec.Paths.Set("/roles/{name}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Roles"},
+			Summary:     "Get role",
+			Description: "Get a role of the current tenant by name, with its permissions",
+			OperationID: "getRole",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{stringPathParameter("name", "Role name")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Role retrieved successfully", schemaRef("RoleResponse")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Role not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Roles"},
+			Summary:     "Upsert role",
+			Description: "Create or replace a role of the current tenant by name; its permissions are replaced by the listed ones. Send If-Match with a previously returned ETag to update only an unchanged role, or If-None-Match: * to only create it.",
+			OperationID: "upsertRole",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{stringPathParameter("name", "Role name")}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertRoleRequest", true),
+			Responses:   g.upsertResponses("Role", schemaRef("RoleResponse")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Roles"},
+			Summary:     "Delete role",
+			Description: "Delete a role of the current tenant and remove it from all users",
+			OperationID: "deleteRole",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{stringPathParameter("name", "Role name")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Role deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("System roles cannot be deleted")),
+				openapi3.WithStatus(404, g.errorResponse("Role not found")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /permissions/:name
+	g.spec.Paths.Set("/permissions/{name}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Roles"},
+			Summary:     "Get permission",
+			OperationID: "getPermission",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{stringPathParameter("name", "Permission name, e.g. invoices.read")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Permission retrieved successfully", schemaRef("Permission")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Permission not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Roles"},
+			Summary:     "Upsert permission",
+			Description: "Create or replace a permission by name. Send If-Match with a previously returned ETag to update only an unchanged permission, or If-None-Match: * to only create it.",
+			OperationID: "upsertPermission",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{stringPathParameter("name", "Permission name, e.g. invoices.read")}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertPermissionRequest", true),
+			Responses:   g.upsertResponses("Permission", schemaRef("Permission")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Roles"},
+			Summary:     "Delete permission",
+			Description: "Delete a permission and remove it from all roles",
+			OperationID: "deletePermission",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{stringPathParameter("name", "Permission name, e.g. invoices.read")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Permission deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("System permissions cannot be deleted")),
+				openapi3.WithStatus(404, g.errorResponse("Permission not found")),
+			),
+		},
+	})
+}
+
+// addPolicyPaths adds policy management paths
+func (g *Generator) addPolicyPaths() {
+	policyID := uuidPathParameter("id", "Policy ID")
+
+	// GET, POST /policies
+	g.spec.Paths.Set("/policies", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "List policies",
+			Description: "List the policies of the current tenant",
+			OperationID: "listPolicies",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.PolicyListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Policies retrieved successfully", "policies", "Policy")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Create policy",
+			Description: "Create a draft policy in the current tenant",
+			OperationID: "createPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("CreatePolicyRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Policy created successfully", schemaRef("Policy"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET, PUT /policies/path/*
+	g.spec.Paths.Set("/policies/path/{path}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Get policy by path",
+			OperationID: "getPolicyByPath",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{stringPathParameter("path", "Policy path, e.g. heimdall/authz/users; slashes may be sent unescaped")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Policy retrieved successfully", schemaRef("Policy")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Upsert policy by path",
+			Description: "Create or replace the policy at the given path; content changes create a new version. Send If-Match with a previously returned ETag to update only an unchanged policy, or If-None-Match: * to only create it.",
+			OperationID: "upsertPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(openapi3.Parameters{stringPathParameter("path", "Policy path, e.g. heimdall/authz/users; slashes may be sent unescaped")}, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertPolicyRequest", true),
+			Responses:   g.upsertResponses("Policy", schemaRef("Policy")),
+		},
+	})
+
+	// POST /policies/from-template
+	g.spec.Paths.Set("/policies/from-template", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Create policy from template",
+			Description: "Create a draft Rego policy in the current tenant by filling in the variables of a policy template. The template and variables are recorded in the policy's metadata.",
+			OperationID: "createPolicyFromTemplate",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("CreatePolicyFromTemplateRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Policy created successfully", schemaRef("Policy"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error or invalid template variables")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Policy template not found")),
+			),
+		},
+	})
+
+	// POST /policies/compile
+	g.spec.Paths.Set("/policies/compile", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Compile policy rules",
+			Description: "Compile the structured rules of a JSON policy into the Rego module Heimdall loads for it, without saving them",
+			OperationID: "compilePolicyRules",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("PolicyRuleSet", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy rules compiled successfully", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"content": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+						},
+						Required: []string{"content"},
+					},
+				})),
+				openapi3.WithStatus(400, g.errorResponse("Invalid policy rules")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /policies/test-suite
+	failedSuite := g.errorResponse("A test failed or a policy did not compile; the results are in data")
+	failedSuite.Value.Content["application/json"].Schema = &openapi3.SchemaRef{
+		Value: &openapi3.Schema{
+			AllOf: openapi3.SchemaRefs{
+				schemaRef("Error"),
+				{Value: &openapi3.Schema{
+					Type:       &openapi3.Types{"object"},
+					Properties: openapi3.Schemas{"data": schemaRef("PolicyTestSuiteResult")},
+				}},
+			},
+		},
+	}
+	g.spec.Paths.Set("/policies/test-suite", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Run policy test suite",
+			Description: "Load a set of policies into OPA together without saving them, so they can import each other, and run the test cases of each against its package. exitCode is 0 when every test passed, 1 when a test failed and 2 when a policy did not compile; failing suites are answered with 422 so CI steps gate on them.",
+			OperationID: "runPolicyTestSuite",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("PolicyTestSuiteRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Every test passed", schemaRef("PolicyTestSuiteResult"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid request")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(422, failedSuite),
+			),
+		},
+	})
+
+	// POST /policies/format
+	g.spec.Paths.Set("/policies/format", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Format policy",
+			Description: "Format a Rego module with opa fmt without saving it. changed is true when the module was not formatted.",
+			OperationID: "formatPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("PolicyContentRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Formatted policy", schemaRef("PolicyFormatResult"))),
+				openapi3.WithStatus(400, g.errorResponse("Policy could not be parsed")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(503, g.errorResponse("The opa binary cannot be run")),
+			),
+		},
+	})
+
+	// POST /policies/lint
+	g.spec.Paths.Set("/policies/lint", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Lint policy",
+			Description: "Check a Rego module without saving it for allow rules without conditions (error), allow rules without a default deny and unused local variables (warnings)",
+			OperationID: "lintPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("PolicyContentRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Lint issues", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"issues": arrayOf(schemaRef("PolicyLintIssue")),
+						},
+						Required: []string{"issues"},
+					},
+				})),
+				openapi3.WithStatus(400, g.errorResponse("Invalid request")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /policy-templates
+	g.spec.Paths.Set("/policy-templates", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "List policy templates",
+			Description: "List the library of parameterized policy templates for common authorization patterns",
+			OperationID: "listPolicyTemplates",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy templates retrieved successfully", arrayOf(schemaRef("PolicyTemplate")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /policies/sync
+	g.spec.Paths.Set("/policies/sync", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Sync policies",
+			Description: "Create, update and optionally delete the tenant's Rego policies to match a set of files, matched by path. With dryRun the changes and their diffs are reported without being applied.",
+			OperationID: "syncPolicies",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("SyncPoliciesRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policies synced successfully", schemaRef("PolicySyncResult"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(409, g.errorResponse("Policy path is used by another tenant")),
+			),
+		},
+	})
+
+	// POST /webhooks/git/policies
+	g.spec.Paths.Set("/webhooks/git/policies", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Webhooks"},
+			Summary:     "Git push webhook",
+			Description: "Push webhook of GitHub or GitLab, authenticated by the X-Hub-Signature-256 signature or X-Gitlab-Token. A push to the configured branch starts a background job syncing the repository's .rego files into the policies; pushes to other branches are ignored. Only registered when Git policy sync is configured.",
+			OperationID: "handleGitPush",
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content: openapi3.NewContentWithJSONSchema(&openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"ref": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Example: "refs/heads/main"}},
+						},
+					}),
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Ping answered or push ignored")),
+				openapi3.WithStatus(202, g.dataResponse("Policy sync started", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"jobId": {Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "uuid"}},
+						},
+					},
+				})),
+				openapi3.WithStatus(400, g.errorResponse("Invalid push payload")),
+				openapi3.WithStatus(401, g.errorResponse("Invalid webhook signature")),
+			),
+		},
+	})
+
+	// GET /policies/export
+	g.spec.Paths.Set("/policies/export", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Export policies",
+			Description: "Export the tenant's Rego policies as files, sorted by path",
+			OperationID: "exportPolicies",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policies exported successfully", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"files": arrayOf(schemaRef("PolicyFile")),
+						},
+						Required: []string{"files"},
+					},
+				})),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /policies/:id
+	g.spec.Paths.Set("/policies/{id}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Get policy",
+			OperationID: "getPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Policy retrieved successfully", schemaRef("Policy")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Update policy",
+			Description: "Update a policy; content changes create a new version",
+			OperationID: "updatePolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			RequestBody: jsonRequestBody("UpdatePolicyRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy updated successfully", schemaRef("Policy"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("System policies cannot be modified")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Delete policy",
+			Description: "Soft delete a policy; it can be restored until it is purged. Policies included in a ready or active bundle cannot be deleted, failing with POLICY_IN_BUNDLE and the bundles in the error details.",
+			OperationID: "deletePolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Policy deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("System policies cannot be deleted")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+				openapi3.WithStatus(409, g.errorResponse("Policy is included in a ready or active bundle")),
+			),
+		},
+	})
+
+	// GET /policies/deleted
+	g.spec.Paths.Set("/policies/deleted", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "List deleted policies",
+			Description: "List the deleted policies of the current tenant that can still be restored",
+			OperationID: "listDeletedPolicies",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.DeletedPolicyListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Deleted policies retrieved successfully", "policies", "Policy")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /policies/:id/restore
+	g.spec.Paths.Set("/policies/{id}/restore", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Restore policy",
+			Description: "Restore a deleted policy that has not been purged, with the status and content it was deleted with",
+			OperationID: "restorePolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy restored successfully", schemaRef("Policy"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+				openapi3.WithStatus(409, g.errorResponse("Policy is not deleted")),
+			),
+		},
+	})
+
+	// DELETE /policies/:id/purge
+	g.spec.Paths.Set("/policies/{id}/purge", &openapi3.PathItem{
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Purge policy",
+			Description: "Permanently delete a deleted policy with its versions, removing it from the inactive and failed bundles that included it. Must be confirmed with a policies.purge action nonce",
+			OperationID: "purgePolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID, actionNonceHeader()},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Policy purged successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or the action nonce is missing, invalid or expired")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+				openapi3.WithStatus(409, g.errorResponse("Policy is not deleted, is included in a ready or active bundle, or the action nonce was already used")),
+			),
+		},
+	})
+
+	// POST /policies/:id/publish
+	g.spec.Paths.Set("/policies/{id}/publish", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Publish policy",
+			Description: "Validate a policy and mark it active. Policies importing packages of the tenant that no active, valid policy provides cannot be published, failing with POLICY_DEPENDENCY_UNPUBLISHED and the imports in the error details.",
+			OperationID: "publishPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy published successfully", schemaRef("Policy"))),
+				openapi3.WithStatus(400, g.errorResponse("Policy is invalid")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+				openapi3.WithStatus(409, g.errorResponse("Policy imports unpublished or invalid policies")),
+			),
+		},
+	})
+
+	// POST /policies/:id/archive
+	archived := g.dataResponse("Policy archived successfully", schemaRef("Policy"))
+	archived.Value.Content["application/json"].Schema.Value.Properties["warnings"] = arrayOf(schemaRef("PolicyWarning"))
+	g.spec.Paths.Set("/policies/{id}/archive", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Archive policy",
+			Description: "Mark a policy archived. Archiving a policy whose package other policies import, with no other published policy providing it, succeeds with a POLICY_HAS_DEPENDENTS warning listing them.",
+			OperationID: "archivePolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, archived),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or the policy is a system policy")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+	})
+
+	// GET /policies/:id/dependencies
+	g.spec.Paths.Set("/policies/{id}/dependencies", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Get policy dependencies",
+			Description: "List the packages a policy imports, resolved to the policies of the tenant defining them, and the policies importing its package. Imports no policy of the tenant defines, such as synced data, have no package.",
+			OperationID: "getPolicyDependencies",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy dependencies", schemaRef("PolicyDependencies"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+	})
+
+	// POST /policies/:id/validate
+	g.spec.Paths.Set("/policies/{id}/validate", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Validate policy",
+			Description: "Compile a policy without publishing it",
+			OperationID: "validatePolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Policy is valid")),
+				openapi3.WithStatus(400, g.errorResponse("Policy is invalid")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+	})
+
+	// POST /policies/:id/test
+	g.spec.Paths.Set("/policies/{id}/test", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "Test policy",
+			Description: "Run the policy's test cases",
+			OperationID: "testPolicy",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Test results", arrayOf(schemaRef("PolicyTestResult")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+	})
+
+	// GET /policies/:id/versions
+	g.spec.Paths.Set("/policies/{id}/versions", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Policies"},
+			Summary:     "List policy versions",
+			OperationID: "getPolicyVersions",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{policyID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Policy versions retrieved successfully", arrayOf(schemaRef("PolicyVersion")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Policy not found")),
+			),
+		},
+	})
+}
+
+// addBundlePaths adds policy bundle paths
+func (g *Generator) addBundlePaths() {
+	bundleID := uuidPathParameter("id", "Bundle ID")
+
+	// GET, POST /bundles
+	g.spec.Paths.Set("/bundles", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "List bundles",
+			Description: "List the policy bundles of the current tenant",
+			OperationID: "listBundles",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.BundleListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Bundles retrieved successfully", "bundles", "PolicyBundle")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Create bundle",
+			Description: "Create a bundle from policies; it is built asynchronously by a background job whose ID is returned as buildJobId, polled with GET /jobs/{jobId}",
+			OperationID: "createBundle",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("CreateBundleRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Bundle is being built", schemaRef("PolicyBundle"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET, DELETE /bundles/:id
+	g.spec.Paths.Set("/bundles/{id}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Get bundle",
+			OperationID: "getBundle",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Bundle retrieved successfully", schemaRef("PolicyBundle"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+			),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Delete bundle",
+			Description: "Delete an inactive bundle. Must be confirmed with a bundles.delete action nonce",
+			OperationID: "deleteBundle",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID, actionNonceHeader()},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Bundle deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or the action nonce is missing, invalid or expired")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+				openapi3.WithStatus(409, g.errorResponse("Active bundles cannot be deleted, or the action nonce was already used")),
+			),
+		},
+	})
+
+	// GET /bundles/:id/download
+	g.spec.Paths.Set("/bundles/{id}/download", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Download bundle",
+			Description: "Stream a built bundle through the API. Send a Range header with a single byte range to download part of it, e.g. to resume a large download; If-Range with the bundle's ETag only serves the range from the same bundle.",
+			OperationID: "downloadBundle",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, bundleContentResponse("Bundle tar.gz")),
+				openapi3.WithStatus(206, bundleContentResponse("Requested byte range of the bundle")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+				openapi3.WithStatus(409, g.errorResponse("Bundle has not been built yet")),
+				openapi3.WithStatus(416, g.errorResponse("Requested range is not satisfiable")),
+			),
+		},
+	})
+
+	// GET /bundles/:id/download-url
+	g.spec.Paths.Set("/bundles/{id}/download-url", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Get bundle download URL",
+			Description: "Get a time-limited presigned URL downloading a built bundle directly from MinIO, S3 or GCS instead of through the API. Not available with local bundle storage.",
+			OperationID: "getBundleDownloadURL",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters: openapi3.Parameters{
+				bundleID,
+				queryParameter("expiresIn", "Lifetime of the URL in seconds, from 60 to 604800 (default 900)", &openapi3.Schema{
+					Type: &openapi3.Types{"integer"},
+					Min:  float64Ptr(60),
+					Max:  float64Ptr(604800),
+				}),
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Download URL created successfully", schemaRef("BundleDownloadURL"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid expiry")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+				openapi3.WithStatus(409, g.errorResponse("Bundle has not been built yet, or bundle storage does not support presigned URLs")),
+			),
+		},
+	})
+
+	// GET /bundles/:id/contents
+	g.spec.Paths.Set("/bundles/{id}/contents", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "List bundle contents",
+			Description: "List the files inside a built bundle as it was uploaded, with their sizes, SHA-256 checksums and the policies they were built from",
+			OperationID: "listBundleContents",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Bundle contents retrieved successfully", schemaRef("BundleContents"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+				openapi3.WithStatus(409, g.errorResponse("Bundle has not been built yet")),
+			),
+		},
+	})
+
+	// GET /bundles/:id/contents/*path
+	g.spec.Paths.Set("/bundles/{id}/contents/{path}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Get bundle file",
+			Description: "Get a single file inside a built bundle. The ETag is the file's SHA-256 checksum.",
+			OperationID: "getBundleFile",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters: openapi3.Parameters{
+				bundleID,
+				stringPathParameter("path", "File path inside the bundle, e.g. heimdall/authz/users.rego; slashes may be sent unescaped"),
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("File content"),
+						Content: openapi3.Content{
+							"text/plain": {
+								Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+							},
+						},
+					},
+				}),
+				openapi3.WithStatus(400, g.errorResponse("File is too large to inspect")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle or file not found")),
+				openapi3.WithStatus(409, g.errorResponse("Bundle has not been built yet")),
+			),
+		},
+	})
+
+	// POST /bundles/:id/activate
+	g.spec.Paths.Set("/bundles/{id}/activate", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Activate bundle",
+			Description: "Make a built bundle the active bundle of its tenant, or roll back to an earlier one. Must be confirmed with a bundles.activate action nonce",
+			OperationID: "activateBundle",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID, actionNonceHeader()},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Bundle activated successfully", schemaRef("PolicyBundle"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or the action nonce is missing, invalid or expired")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+				openapi3.WithStatus(409, g.errorResponse("Bundle is not ready, or the action nonce was already used")),
+			),
+		},
+	})
+
+	// GET /bundles/:id/deployments
+	g.spec.Paths.Set("/bundles/{id}/deployments", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "List bundle deployments",
+			Description: "List the deployments of a bundle, newest first, with the manifest revision and roots each deployment shipped",
+			OperationID: "listBundleDeployments",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Bundle deployments retrieved successfully", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type:  &openapi3.Types{"array"},
+						Items: schemaRef("BundleDeployment"),
+					},
+				})),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+			),
+		},
+	})
+
+	// POST /bundles/:id/deploy
+	g.spec.Paths.Set("/bundles/{id}/deploy", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Bundles"},
+			Summary:     "Deploy bundle",
+			Description: "Deploy a built bundle to an environment (default production)",
+			OperationID: "deployBundle",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{bundleID},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Content: openapi3.Content{
+						"application/json": {
+							Schema: &openapi3.SchemaRef{
+								Value: &openapi3.Schema{
+									Type: &openapi3.Types{"object"},
+									Properties: openapi3.Schemas{
+										"environment": {
+											Value: &openapi3.Schema{
+												Type:      &openapi3.Types{"string"},
+												MaxLength: uint64Ptr(100),
+												Example:   "production",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Bundle deployed successfully", schemaRef("BundleDeployment"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Bundle not found")),
+				openapi3.WithStatus(409, g.errorResponse("Bundle has not been built")),
+			),
+		},
+	})
+}
+
+// addAuthzPaths adds authorization check paths
+func (g *Generator) addAuthzPaths() {
+	// POST /authz/check
+	g.spec.Paths.Set("/authz/check", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Authorization"},
+			Summary:     "Check authorization",
+			Description: "Evaluate whether the authenticated user may perform an action on a resource. A Cache-Control header with max-age, max-stale, no-cache or no-store controls use of cached decisions.",
+			OperationID: "checkAuthorization",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("AuthzCheckRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Authorization decision", schemaRef("AuthzDecision"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+			),
+		},
+	})
+
+	// POST /authz/explain
+	g.spec.Paths.Set("/authz/explain", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Authorization"},
+			Summary:     "Explain authorization decision",
+			Description: "Evaluate whether the authenticated user may perform an action on a resource with OPA's explanation enabled, returning the rules that fired, the input paths they read, the result of each policy module and why the request was denied. Set trace to include OPA's raw evaluation trace. Requires the authz.debug permission; decisions are never cached.",
+			OperationID: "explainAuthorization",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("AuthzExplainRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Authorization decision explanation", schemaRef("AuthzExplanation"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /authz/simulate
+	g.spec.Paths.Set("/authz/simulate", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Authorization"},
+			Summary:     "Simulate authorization decision",
+			Description: "Evaluate what a user of the tenant, or a hypothetical set of roles, may do without acting as them. The context overrides the time, IP address, MFA state and session age of the request. With bundleId the request is also evaluated against a draft policy bundle, and changed reports whether its decision differs from the active policies. Requires the authz.simulate permission; decisions are never cached.",
+			OperationID: "simulateAuthorization",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("SimulateRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Simulated authorization decisions", schemaRef("AuthzSimulation"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error or invalid bundle")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("User or bundle not found")),
+			),
+		},
+	})
+}
+
+// addAuditPaths adds audit log paths
+func (g *Generator) addAuditPaths() {
+	// GET /audit/admin-actions
+	params := g.listParameters(service.AdminActionListOptions)
+	params = append(params,
+		queryParameter("action", "Filter by action, e.g. roles.assign or tenants.suspend", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("userId", "Filter by acting user", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "uuid",
+		}),
+	)
+	g.spec.Paths.Set("/audit/admin-actions", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "List admin actions",
+			Description: "List the sensitive administrative actions of the caller's tenant, such as role assignments, policy publishes, tenant suspensions and user deletions, with the acting user, route, result and redacted request and response payloads",
+			OperationID: "listAdminActions",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  params,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Admin actions retrieved successfully", "actions", "AdminAction")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /audit/decisions
+	params = g.listParameters(service.DecisionLogListOptions)
+	params = append(params,
+		queryParameter("instanceId", "Filter by the OPA instance that reported the decisions", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("decisionId", "Filter by decision ID", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+	)
+	g.spec.Paths.Set("/audit/decisions", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "List reported decisions",
+			Description: "List the decisions reported by the caller's tenant's OPA instances through the decision log API, with the reporting instance, input, result and bundle revisions. Filter by status allowed, denied, error or unknown.",
+			OperationID: "listDecisions",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  params,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Decisions retrieved successfully", "decisions", "DecisionLog")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /analytics/authz
+	analytics := g.dataResponse("Analytics computed successfully", schemaRef("AuthzAnalytics"))
+	analytics.Value.Content["text/csv"] = &openapi3.MediaType{
+		Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+	}
+	g.spec.Paths.Set("/analytics/authz", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "Get authorization analytics",
+			Description: "Aggregate the caller's tenant's audit log over a period, 30 days by default and at most 366: the deny rate of administrative actions and reported OPA decisions, the most denied routes, deny rates per role, permissions granted but never used, roles whose members were not active, and active policies no reported decision queried. Send format=csv for a CSV export with one row per item.",
+			OperationID: "getAuthzAnalytics",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters: openapi3.Parameters{
+				queryParameter("from", "Start of the period (RFC 3339), defaults to 30 days before to", &openapi3.Schema{
+					Type:   &openapi3.Types{"string"},
+					Format: "date-time",
+				}),
+				queryParameter("to", "End of the period (RFC 3339), defaults to now", &openapi3.Schema{
+					Type:   &openapi3.Types{"string"},
+					Format: "date-time",
+				}),
+				queryParameter("limit", "Number of denied routes listed, at most 100", &openapi3.Schema{
+					Type: &openapi3.Types{"integer"},
+					Min:  float64Ptr(1),
+					Max:  float64Ptr(100),
+				}),
+				queryParameter("format", "Response format", &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+					Enum: []interface{}{"json", "csv"},
+				}),
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, analytics),
+				openapi3.WithStatus(400, g.errorResponse("Invalid period, limit or format")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /analytics/auth
+	g.spec.Paths.Set("/analytics/auth", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "Get login analytics",
+			Description: "Report the caller's tenant's daily and weekly active users, successful and failed logins, new registrations and MFA adoption per day (UTC) over a period, 30 days by default and at most 366. The report is read from daily stats a background job aggregates every AUTH_ANALYTICS_INTERVAL_SECONDS, so it lags behind the latest logins by up to one interval; aggregatedAt tells when the stats were last aggregated.",
+			OperationID: "getAuthAnalytics",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters: openapi3.Parameters{
+				queryParameter("from", "Start of the period (RFC 3339), rounded down to the day, defaults to 30 days before to", &openapi3.Schema{
+					Type:   &openapi3.Types{"string"},
+					Format: "date-time",
+				}),
+				queryParameter("to", "End of the period (RFC 3339), defaults to now", &openapi3.Schema{
+					Type:   &openapi3.Types{"string"},
+					Format: "date-time",
+				}),
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Analytics computed successfully", schemaRef("AuthAnalytics"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid period")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /admin/overview
+	g.spec.Paths.Set("/admin/overview", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Admin"},
+			Summary:     "Get admin overview",
+			Description: "Snapshot of all tenants for the admin landing page: tenants and users per status, users active in the last 24 hours, active bundles and their revisions, the health of the OPA server and of the OPA instances reporting their status, the most recent failed bundle deployments, and the error rates of administrative actions and reported decisions in the last 24 hours. Requires admin.overview.",
+			OperationID: "getAdminOverview",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Overview computed successfully", schemaRef("AdminOverview"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET/POST /admin/body-logging
+	g.spec.Paths.Set("/admin/body-logging", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Admin"},
+			Summary:     "List body logging rules",
+			Description: "List the routes whose request and response bodies are logged, until their rules expire. Requires admin.logging.",
+			OperationID: "listBodyLoggingRules",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Body logging rules retrieved successfully", arrayOf(schemaRef("BodyLoggingRule")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Admin"},
+			Summary:     "Enable body logging",
+			Description: "Log the request and response bodies of a route, on all instances, for durationMinutes (15 by default, at most BODY_LOG_MAX_MINUTES). The route is given as documented, e.g. /v1/users/{userId}, and covers every request it serves; a rule without method covers all its methods. Passwords, tokens, refresh tokens, API keys and other credentials are redacted from the logged bodies by field name and by pattern. Requires admin.logging.",
+			OperationID: "enableBodyLogging",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("EnableBodyLoggingRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Body logging enabled", schemaRef("BodyLoggingRule"))),
+				openapi3.WithStatus(400, g.errorResponse("Unknown route or duration above the maximum")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden, or body logging disabled")),
+			),
+		},
+	})
+
+	// DELETE /admin/body-logging/:ruleId
+	g.spec.Paths.Set("/admin/body-logging/{ruleId}", &openapi3.PathItem{
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Admin"},
+			Summary:     "Disable body logging",
+			Description: "Delete a body logging rule before it expires. Requires admin.logging.",
+			OperationID: "disableBodyLogging",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("ruleId", "Body logging rule ID")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Body logging disabled successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Body logging rule not found")),
+			),
+		},
+	})
+
+	// GET /signing-keys
+	g.spec.Paths.Set("/signing-keys", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Admin"},
+			Summary:     "List platform signing keys",
+			Description: "List the keys that sign and verify tokens not bound to a tenant's own keys, newest first. The active key signs new tokens; retired keys still verify the tokens they signed until they expire once the longest token lifetime has passed since their retirement. Requires signing_keys.read.",
+			OperationID: "listPlatformSigningKeys",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Signing keys retrieved successfully", arrayOf(schemaRef("PlatformSigningKey")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /signing-keys/rotate
+	g.spec.Paths.Set("/signing-keys/rotate", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Admin"},
+			Summary:     "Rotate the platform signing key",
+			Description: "Generate a new platform signing key and make it the active key. The previous key, including the key read from JWT_PRIVATE_KEY_PATH on the first rotation, is retired: it keeps verifying the tokens it signed until they expire, so active sessions are not invalidated. Other instances pick up the new key within JWT_KEY_REFRESH_SECONDS. Requires signing_keys.rotate.",
+			OperationID: "rotatePlatformSigningKey",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Signing key rotated successfully", schemaRef("PlatformSigningKey"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /logs
+	g.spec.Paths.Set("/logs", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "Report decision logs",
+			Description: "Decision log API for OPA instances, e.g. sidecars running Heimdall's bundles. Accepts a batch of decision events as OPA's decision log plugin sends them, gzip compressed with Content-Encoding: gzip or plain, and stores them in the audit log of the caller's tenant attributed to the reporting instance. Requires decision_logs.write.",
+			OperationID: "reportDecisionLogs",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content:  openapi3.NewContentWithJSONSchemaRef(arrayOf(schemaRef("DecisionEvent"))),
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Decision logs stored successfully", &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"object"},
+						Properties: openapi3.Schemas{
+							"accepted": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"integer"}}},
+						},
+						Required: []string{"accepted"},
+					},
+				})),
+				openapi3.WithStatus(400, g.errorResponse("Invalid or oversized decision log batch")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// POST /status
+	g.spec.Paths.Set("/status", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "Report OPA status",
+			Description: "Status API for OPA instances, e.g. sidecars running Heimdall's bundles. Accepts a status update as OPA's status plugin sends it and records the instance, identified by labels.id, with its loaded bundle revisions and plugin states. Requires opa_instances.report.",
+			OperationID: "reportOPAStatus",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content:  openapi3.NewContentWithJSONSchemaRef(schemaRef("OPAStatusReport")),
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Status recorded successfully")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid or oversized status report")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /opa-instances
+	g.spec.Paths.Set("/opa-instances", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Audit"},
+			Summary:     "List OPA instances",
+			Description: "List the OPA instances reporting their status to the caller's tenant, most recently seen first, with the bundle revisions they have loaded and whether each is the latest build of its Heimdall bundle, their plugin states, and whether they are healthy or have stopped reporting",
+			OperationID: "listOPAInstances",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.OPAInstanceListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("OPA instances retrieved successfully", "instances", "OPAInstance")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination or sort parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+}
+
+// addResourcePaths adds resource registry paths
+func (g *Generator) addResourcePaths() {
+	// GET /resources
+	params := g.listParameters(service.ResourceListOptions)
+	params = append(params,
+		queryParameter("type", "Filter by resource type", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("ownerId", "Filter by owning user", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("label", "Filter by label as a key=value pair, e.g. env=prod", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+	)
+	g.spec.Paths.Set("/resources", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Resources"},
+			Summary:     "List resources",
+			Description: "List the resources registered in the caller's tenant",
+			OperationID: "listResources",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  params,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Resources retrieved successfully", "resources", "Resource")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /resources/:resourceType/:resourceId
+	resourceParams := openapi3.Parameters{
+		stringPathParameter("resourceType", "Resource type, e.g. documents"),
+		stringPathParameter("resourceId", "Resource ID in the service owning it"),
+	}
+	g.spec.Paths.Set("/resources/{resourceType}/{resourceId}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Resources"},
+			Summary:     "Get resource",
+			Description: "Get a resource of the caller's tenant. Resources of Heimdall's own types (users, roles, policies, bundles and tenants) are derived from the database and marked derived; only registered resources have an ETag.",
+			OperationID: "getResource",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  resourceParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, withETag(g.dataResponse("Resource retrieved successfully", schemaRef("Resource")))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Resource not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Resources"},
+			Summary:     "Upsert resource",
+			Description: "Register a resource of the caller's tenant or replace its owner, labels and attributes. Authorization inputs for the resource then carry its owner as input.resource.ownerId, and its labels and attributes in input.resource.attributes. Heimdall's own types cannot be registered. Send If-Match with a previously returned ETag to update only an unchanged resource, or If-None-Match: * to only create it.",
+			OperationID: "upsertResource",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(resourceParams, conditionalHeaders()...),
+			RequestBody: jsonRequestBody("UpsertResourceRequest", true),
+			Responses:   g.upsertResponses("Resource", schemaRef("Resource")),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Resources"},
+			Summary:     "Delete resource",
+			Description: "Remove a registered resource. Policies no longer receive its owner and labels.",
+			OperationID: "deleteResource",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  resourceParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Resource deleted successfully")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid resource type or ID")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Resource not found")),
+			),
+		},
+	})
+}
+
+// addAccessRequestPaths adds just-in-time access request paths
+func (g *Generator) addAccessRequestPaths() {
+	// GET, POST /access-requests
+	params := g.listParameters(service.AccessRequestListOptions)
+	params = append(params,
+		queryParameter("requesterId", "Filter by requesting user", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "uuid",
+		}),
+		queryParameter("roleId", "Filter by requested role", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "uuid",
+		}),
+	)
+	g.spec.Paths.Set("/access-requests", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Access Requests"},
+			Summary:     "List access requests",
+			Description: "List the access requests of the caller's tenant, e.g. ?status=pending for those awaiting a decision. Requires the access_requests.read permission.",
+			OperationID: "listAccessRequests",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  params,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Access requests retrieved successfully", "accessRequests", "AccessRequest")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Access Requests"},
+			Summary:     "Request access",
+			Description: "Request a role of the caller's tenant for a limited time with a justification. The tenant's approvers are notified by webhook and, when enabled, email. Durations are capped by ROLE_ELEVATION_MAX_HOURS.",
+			OperationID: "createAccessRequest",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("CreateAccessRequestRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Access request created", schemaRef("AccessRequest"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Role not found")),
+				openapi3.WithStatus(409, g.errorResponse("A request for the role is pending or the caller already has it permanently")),
+			),
+		},
+	})
+
+	// GET /access-requests/:requestId
+	requestParams := openapi3.Parameters{uuidPathParameter("requestId", "Access request ID")}
+	g.spec.Paths.Set("/access-requests/{requestId}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Access Requests"},
+			Summary:     "Get access request",
+			Description: "Get an access request of the caller's tenant. Requires the access_requests.read permission.",
+			OperationID: "getAccessRequest",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  requestParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Access request retrieved successfully", schemaRef("AccessRequest"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Access request not found")),
+			),
+		},
+	})
+
+	// POST /access-requests/:requestId/approve, /deny and /cancel
+	decisions := []struct {
+		action, summary, description, operationID string
+		body                                      bool
+	}{
+		{"approve", "Approve access request", "Approve a pending request, granting the requested role until the requested duration, or a shorter durationMinutes, has passed. The assignment is removed automatically once it expires. Users cannot approve their own requests. Requires the access_requests.approve permission.", "approveAccessRequest", true},
+		{"deny", "Deny access request", "Deny a pending request. Users cannot deny their own requests. Requires the access_requests.approve permission.", "denyAccessRequest", true},
+		{"cancel", "Cancel access request", "Withdraw one of the caller's pending requests.", "cancelAccessRequest", false},
+	}
+	for _, decision := range decisions {
+		operation := &openapi3.Operation{
+			Tags:        []string{"Access Requests"},
+			Summary:     decision.summary,
+			Description: decision.description,
+			OperationID: decision.operationID,
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  requestParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Access request updated", schemaRef("AccessRequest"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Access request not found")),
+				openapi3.WithStatus(409, g.errorResponse("Access request is no longer pending")),
+			),
+		}
+		if decision.body {
+			operation.RequestBody = jsonRequestBody("AccessRequestDecision", false)
+		}
+		g.spec.Paths.Set("/access-requests/{requestId}/"+decision.action, &openapi3.PathItem{Post: operation})
+	}
+
+	// GET /users/me/access-requests
+	g.spec.Paths.Set("/users/me/access-requests", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Access Requests"},
+			Summary:     "List my access requests",
+			Description: "List the caller's own access requests",
+			OperationID: "listMyAccessRequests",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.AccessRequestListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Access requests retrieved successfully", "accessRequests", "AccessRequest")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination, sort or filter parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+			),
+		},
+	})
+}
+
+// addAlertRulePaths adds the alert rule paths
+func (g *Generator) addAlertRulePaths() {
+	// GET, POST /alert-rules
+	g.spec.Paths.Set("/alert-rules", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Alert Rules"},
+			Summary:     "List alert rules",
+			Description: "List the alert rules of the caller's tenant. Requires the alert_rules.read permission.",
+			OperationID: "listAlertRules",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  g.listParameters(service.AlertRuleListOptions),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Alert rules retrieved successfully", "rules", "AlertRule")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination or sort parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+		Post: &openapi3.Operation{
+			Tags:        []string{"Alert Rules"},
+			Summary:     "Create alert rule",
+			Description: "Create a rule alerting when a condition on the tenant's audit log exceeds a threshold within a window: more than threshold requests of a user denied with 403 (user_denies), more than threshold failed logins from an IP address (ip_login_failures), or more than threshold percent of OPA decisions failing to evaluate (decision_error_rate). Alerts are delivered to the platform webhooks as alert.fired events, and to the rule's webhook and PagerDuty service. Requires the alert_rules.write permission.",
+			OperationID: "createAlertRule",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("AlertRuleRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Alert rule created", schemaRef("AlertRule"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET, PUT, DELETE /alert-rules/:ruleId
+	ruleParams := openapi3.Parameters{uuidPathParameter("ruleId", "Alert rule ID")}
+	g.spec.Paths.Set("/alert-rules/{ruleId}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Alert Rules"},
+			Summary:     "Get alert rule",
+			Description: "Get an alert rule of the caller's tenant. Requires the alert_rules.read permission.",
+			OperationID: "getAlertRule",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  ruleParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Alert rule retrieved successfully", schemaRef("AlertRule"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Alert rule not found")),
+			),
+		},
+		Put: &openapi3.Operation{
+			Tags:        []string{"Alert Rules"},
+			Summary:     "Update alert rule",
+			Description: "Replace the condition and settings of an alert rule. The webhook URL and PagerDuty routing key are kept when omitted and removed when empty. Requires the alert_rules.write permission.",
+			OperationID: "updateAlertRule",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  ruleParams,
+			RequestBody: jsonRequestBody("AlertRuleRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Alert rule updated", schemaRef("AlertRule"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Alert rule not found")),
+			),
+		},
+		Delete: &openapi3.Operation{
+			Tags:        []string{"Alert Rules"},
+			Summary:     "Delete alert rule",
+			Description: "Delete an alert rule and the alerts it fired. Requires the alert_rules.write permission.",
+			OperationID: "deleteAlertRule",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  ruleParams,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Alert rule deleted successfully")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Alert rule not found")),
+			),
+		},
+	})
+
+	// GET /alert-rules/:ruleId/alerts
+	g.spec.Paths.Set("/alert-rules/{ruleId}/alerts", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Alert Rules"},
+			Summary:     "List fired alerts",
+			Description: "List the alerts an alert rule fired. A rule fires once per user or IP address within its window. Requires the alert_rules.read permission.",
+			OperationID: "listAlerts",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  append(append(openapi3.Parameters{}, ruleParams...), g.listParameters(service.AlertListOptions)...),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Alerts retrieved successfully", "alerts", "Alert")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination or sort parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Alert rule not found")),
+			),
+		},
+	})
+}
+
+// addActionNoncePaths adds the action nonce paths
+func (g *Generator) addActionNoncePaths() {
+	// POST /action-nonces
+	g.spec.Paths.Set("/action-nonces", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Action Nonces"},
+			Summary:     "Issue action nonce",
+			Description: "Issue a one-time nonce confirming a destructive action of the caller on a resource: tenants.delete, users.deactivate, bundles.activate, bundles.delete or policies.purge. The action's request sends the nonce in the X-Action-Nonce header before it expires; each nonce is accepted once, so admin UIs fetch one per confirmation.",
+			OperationID: "issueActionNonce",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: jsonRequestBody("ActionNonceRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(201, g.dataResponse("Action nonce issued", schemaRef("ActionNonce"))),
+				openapi3.WithStatus(400, g.errorResponse("Validation error")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+			),
+		},
+	})
+}
+
+// addJobPaths adds background job paths
+func (g *Generator) addJobPaths() {
+	// GET /jobs
+	params := g.listParameters(service.JobListOptions)
+	params = append(params,
+		queryParameter("type", "Filter by job type, e.g. bundle.build or policy.git_sync", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+	)
+	g.spec.Paths.Set("/jobs", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Jobs"},
+			Summary:     "List jobs",
+			Description: "List the background jobs of the caller's tenant. Filter by status dead to find jobs that failed permanently or ran out of attempts. Requires the jobs.read permission.",
+			OperationID: "listJobs",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  params,
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.listResponse("Jobs retrieved successfully", "jobs", "Job")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid pagination or sort parameters")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+			),
+		},
+	})
+
+	// GET /jobs/:jobId
+	g.spec.Paths.Set("/jobs/{jobId}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Jobs"},
+			Summary:     "Get job",
+			Description: "Get a background job of the caller's tenant, or one the caller started, to poll the outcome of an asynchronous operation such as a bundle build. Failed jobs are retried with exponential backoff until they run out of attempts and are marked dead. Requires the jobs.read permission.",
+			OperationID: "getJob",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Parameters:  openapi3.Parameters{uuidPathParameter("jobId", "Job ID")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Job retrieved successfully", schemaRef("Job"))),
+				openapi3.WithStatus(400, g.errorResponse("Invalid job ID")),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+				openapi3.WithStatus(404, g.errorResponse("Job not found")),
+			),
+		},
+	})
+}
+
+// addBreakGlassPaths adds break-glass emergency access paths
+func (g *Generator) addBreakGlassPaths() {
+	// GET /break-glass/session
+	g.spec.Paths.Set("/break-glass/session", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Break Glass"},
+			Summary:     "Get break-glass session",
+			Description: "Describe the break-glass token the request was made with, to check a token before relying on it. Only available when BREAK_GLASS_PUBLIC_KEY_PATH is configured.",
+			OperationID: "getBreakGlassSession",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Break-glass session retrieved successfully", schemaRef("BreakGlassSession"))),
+				openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+				openapi3.WithStatus(404, g.errorResponse("Request was not made with a break-glass token")),
+			),
+		},
+	})
+}
+
+// addPasswordPaths adds password management paths
+func (g *Generator) addPasswordPaths() {
+	// POST /auth/password/change
+	g.spec.Paths.Set("/auth/password/change", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Password"},
+			Summary:     "Change password",
+			Description: "Change password for authenticated user",
+			OperationID: "changePassword",
+			Security:    &openapi3.SecurityRequirements{{"bearerAuth": {}}},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: &openapi3.RequestBody{
+					Required: true,
+					Content: openapi3.Content{
+						"application/json": {
+							Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/ChangePasswordRequest"},
+						},
+					},
+				},
+			},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("Password changed successfully"),
+						Content: openapi3.Content{
+							"application/json": {
+								Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/MessageResponse"},
+							},
+						},
+					},
+				}),
+				openapi3.WithStatus(400, g.errorResponse("Invalid input or a password violating the password policy")),
+				openapi3.WithStatus(401, g.errorResponse("Invalid current password")),
+			),
+		},
+	})
+
+	// POST /auth/password/reset
+	g.spec.Paths.Set("/auth/password/reset", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Password"},
+			Summary:     "Request password reset",
+			Description: "Email a password reset to the user. The response does not reveal whether the email is registered.",
+			OperationID: "forgotPassword",
+			RequestBody: jsonRequestBody("ForgotPasswordRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Password reset sent if the email is registered")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid input")),
+			),
+		},
+	})
+
+	// POST /auth/password/reset/confirm
+	g.spec.Paths.Set("/auth/password/reset/confirm", &openapi3.PathItem{
+		Post: &openapi3.Operation{
+			Tags:        []string{"Password"},
+			Summary:     "Reset password",
+			Description: "Set a new password with the token of a password reset email. Identity providers completing resets on their own pages, such as FusionAuth, return PASSWORD_RESET_NOT_SUPPORTED.",
+			OperationID: "resetPassword",
+			RequestBody: jsonRequestBody("ResetPasswordRequest", true),
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.messageResponse("Password reset successfully")),
+				openapi3.WithStatus(400, g.errorResponse("Invalid or expired token, or a password violating the password policy")),
+				openapi3.WithStatus(409, g.errorResponse("The identity provider completes password resets itself")),
+			),
+		},
+	})
+
+	// GET /tenants/:tenantId/password-policy
+	g.spec.Paths.Set("/tenants/{tenantId}/password-policy", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Tags:        []string{"Password"},
+			Summary:     "Get password policy",
+			Description: "Get the effective password policy of a tenant, so sign-up and password forms can check new passwords before submitting them",
+			OperationID: "getPasswordPolicy",
+			Parameters:  openapi3.Parameters{uuidPathParameter("tenantId", "Tenant ID")},
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, g.dataResponse("Password policy retrieved successfully", schemaRef("PasswordPolicy"))),
+				openapi3.WithStatus(404, g.errorResponse("Tenant not found")),
+			),
+		},
+	})
+}
+
+// addHealthPath adds health check path
+func (g *Generator) addHealthPath() {
+	// Health checks are served at the root rather than under /v1
+	servers := make(openapi3.Servers, 0, len(g.spec.Servers))
+	for _, server := range g.spec.Servers {
+		servers = append(servers, &openapi3.Server{
+			URL:         strings.TrimSuffix(server.URL, "/v1"),
+			Description: server.Description,
+		})
+	}
+	g.spec.Paths.Set("/health", &openapi3.PathItem{
+		Servers: servers,
+		Get: &openapi3.Operation{
+			Tags:        []string{"Health"},
+			Summary:     "Health check",
+			Description: "Basic health check endpoint",
+			OperationID: "healthCheck",
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, &openapi3.ResponseRef{
+					Value: &openapi3.Response{
+						Description: stringPtr("Service is healthy"),
+						Content: openapi3.Content{
+							"application/json": {
+								Schema: &openapi3.SchemaRef{
+									Value: &openapi3.Schema{
+										Type: &openapi3.Types{"object"},
+										Properties: openapi3.Schemas{
+											"status": {
+												Value: &openapi3.Schema{
+													Type:    &openapi3.Types{"string"},
+													Example: "healthy",
+												},
+											},
+											"timestamp": {
+												Value: &openapi3.Schema{
+													Type:   &openapi3.Types{"string"},
+													Format: "date-time",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				}),
+			),
+		},
+	})
+}
+
+// errorResponse creates a standard error response
+func (g *Generator) errorResponse(description string) *openapi3.ResponseRef {
 	return &openapi3.ResponseRef{
 		Value: &openapi3.Response{
 			Description: stringPtr(description),
@@ -836,6 +3274,295 @@ func (g *Generator) errorResponse(description string) *openapi3.ResponseRef {
 	}
 }
 
+// uuidPathParameter creates a required UUID path parameter
+func uuidPathParameter(name, description string) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        name,
+			In:          "path",
+			Required:    true,
+			Description: description,
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type:   &openapi3.Types{"string"},
+					Format: "uuid",
+				},
+			},
+		},
+	}
+}
+
+// stringPathParameter creates a required string path parameter
+func stringPathParameter(name, description string) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        name,
+			In:          "path",
+			Required:    true,
+			Description: description,
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+				},
+			},
+		},
+	}
+}
+
+// conditionalHeaders creates the optimistic concurrency headers accepted by upserts
+func conditionalHeaders() openapi3.Parameters {
+	header := func(name, description string) *openapi3.ParameterRef {
+		return &openapi3.ParameterRef{
+			Value: &openapi3.Parameter{
+				Name:        name,
+				In:          "header",
+				Description: description,
+				Schema: &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"string"},
+					},
+				},
+			},
+		}
+	}
+	return openapi3.Parameters{
+		header("If-Match", "Only update the resource if its current ETag matches"),
+		header("If-None-Match", "Set to * to only create the resource"),
+	}
+}
+
+// actionNonceHeader creates the header carrying the nonce confirming a
+// destructive action
+func actionNonceHeader() *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        "X-Action-Nonce",
+			In:          "header",
+			Required:    true,
+			Description: "One-time nonce issued by POST /action-nonces for this action and resource",
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type: &openapi3.Types{"string"},
+				},
+			},
+		},
+	}
+}
+
+// withETag documents the ETag header on a response
+func withETag(response *openapi3.ResponseRef) *openapi3.ResponseRef {
+	response.Value.Headers = openapi3.Headers{
+		"ETag": {
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: "Version of the resource, for use in If-Match",
+					Schema: &openapi3.SchemaRef{
+						Value: &openapi3.Schema{
+							Type: &openapi3.Types{"string"},
+						},
+					},
+				},
+			},
+		},
+	}
+	return response
+}
+
+// upsertResponses creates the responses of an idempotent PUT upsert
+func (g *Generator) upsertResponses(resource string, data *openapi3.SchemaRef) *openapi3.Responses {
+	return openapi3.NewResponses(
+		openapi3.WithStatus(200, withETag(g.dataResponse(resource+" replaced", data))),
+		openapi3.WithStatus(201, withETag(g.dataResponse(resource+" created", data))),
+		openapi3.WithStatus(400, g.errorResponse("Validation error")),
+		openapi3.WithStatus(401, g.errorResponse("Unauthorized")),
+		openapi3.WithStatus(403, g.errorResponse("Forbidden")),
+		openapi3.WithStatus(412, g.errorResponse("If-Match or If-None-Match precondition failed")),
+	)
+}
+
+// jsonRequestBody creates a JSON request body referencing a component schema
+func jsonRequestBody(schemaName string, required bool) *openapi3.RequestBodyRef {
+	return &openapi3.RequestBodyRef{
+		Value: &openapi3.RequestBody{
+			Required: required,
+			Content: openapi3.Content{
+				"application/json": {
+					Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/" + schemaName},
+				},
+			},
+		},
+	}
+}
+
+// dataResponse creates a success response whose data field holds the given schema
+func (g *Generator) dataResponse(description string, data *openapi3.SchemaRef) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{
+		Value: &openapi3.Response{
+			Description: stringPtr(description),
+			Content: openapi3.Content{
+				"application/json": {
+					Schema: &openapi3.SchemaRef{
+						Value: &openapi3.Schema{
+							Type: &openapi3.Types{"object"},
+							Properties: openapi3.Schemas{
+								"success": {Value: &openapi3.Schema{Type: &openapi3.Types{"boolean"}, Example: true}},
+								"data":    data,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// messageResponse creates a success response carrying only a message
+func (g *Generator) messageResponse(description string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{
+		Value: &openapi3.Response{
+			Description: stringPtr(description),
+			Content: openapi3.Content{
+				"application/json": {
+					Schema: &openapi3.SchemaRef{Ref: "#/components/schemas/MessageResponse"},
+				},
+			},
+		},
+	}
+}
+
+// schemaRef references a component schema
+func schemaRef(name string) *openapi3.SchemaRef {
+	return &openapi3.SchemaRef{Ref: "#/components/schemas/" + name}
+}
+
+// arrayOf creates an array schema of the given items
+func arrayOf(items *openapi3.SchemaRef) *openapi3.SchemaRef {
+	return &openapi3.SchemaRef{
+		Value: &openapi3.Schema{
+			Type:  &openapi3.Types{"array"},
+			Items: items,
+		},
+	}
+}
+
+// listParameters creates the pagination, sorting and filtering query parameters of a list endpoint
+func (g *Generator) listParameters(opts pagination.Options) openapi3.Parameters {
+	sorts := make([]interface{}, 0, len(opts.SortFields)*2)
+	for _, key := range sortedKeys(opts.SortFields) {
+		sorts = append(sorts, key, "-"+key)
+	}
+
+	params := openapi3.Parameters{
+		queryParameter("page", "Page number, ignored when a cursor is given", &openapi3.Schema{
+			Type:    &openapi3.Types{"integer"},
+			Default: 1,
+			Min:     float64Ptr(1),
+		}),
+		queryParameter("pageSize", "Items per page", &openapi3.Schema{
+			Type:    &openapi3.Types{"integer"},
+			Default: pagination.DefaultPageSize,
+			Min:     float64Ptr(1),
+			Max:     float64Ptr(pagination.MaxPageSize),
+		}),
+		queryParameter("cursor", "Opaque cursor from a previous page's nextCursor", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}),
+		queryParameter("sort", "Sort field, prefixed with '-' for descending order", &openapi3.Schema{
+			Type:    &openapi3.Types{"string"},
+			Enum:    sorts,
+			Default: opts.DefaultSort,
+		}),
+	}
+	if opts.StatusColumn != "" {
+		params = append(params, queryParameter("status", "Filter by status", &openapi3.Schema{
+			Type: &openapi3.Types{"string"},
+		}))
+	}
+	params = append(params,
+		queryParameter("createdAfter", "Only include items created at or after this time", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "date-time",
+		}),
+		queryParameter("createdBefore", "Only include items created before this time", &openapi3.Schema{
+			Type:   &openapi3.Types{"string"},
+			Format: "date-time",
+		}),
+	)
+
+	return params
+}
+
+// listResponse creates a paginated list response wrapping items of the given schema
+func (g *Generator) listResponse(description, itemsKey, itemSchema string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{
+		Value: &openapi3.Response{
+			Description: stringPtr(description),
+			Content: openapi3.Content{
+				"application/json": {
+					Schema: &openapi3.SchemaRef{
+						Value: &openapi3.Schema{
+							Type: &openapi3.Types{"object"},
+							Properties: openapi3.Schemas{
+								"success": {Value: &openapi3.Schema{Type: &openapi3.Types{"boolean"}, Example: true}},
+								"data": {
+									Value: &openapi3.Schema{
+										Type: &openapi3.Types{"object"},
+										Properties: openapi3.Schemas{
+											itemsKey: {
+												Value: &openapi3.Schema{
+													Type:  &openapi3.Types{"array"},
+													Items: &openapi3.SchemaRef{Ref: "#/components/schemas/" + itemSchema},
+												},
+											},
+											"pagination": {Ref: "#/components/schemas/Pagination"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// bundleContentResponse creates a response carrying bundle bytes
+func bundleContentResponse(description string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{
+		Value: &openapi3.Response{
+			Description: stringPtr(description),
+			Content: openapi3.Content{
+				"application/gzip": {
+					Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}, Format: "binary"}},
+				},
+			},
+		},
+	}
+}
+
+// queryParameter creates an optional query parameter
+func queryParameter(name, description string, schema *openapi3.Schema) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        name,
+			In:          "query",
+			Description: description,
+			Schema:      &openapi3.SchemaRef{Value: schema},
+		},
+	}
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Utility functions
 func stringPtr(s string) *string {
 	return &s
@@ -0,0 +1,275 @@
+package pagination
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Options describes what a list endpoint supports
+type Options struct {
+	SortFields   map[string]string // API sort key -> column, e.g. "createdAt" -> "created_at"
+	DefaultSort  string            // e.g. "-createdAt"
+	StatusColumn string            // Column filtered by ?status=, empty to disable
+}
+
+// Params are the parsed pagination, sorting and filtering parameters of a list request.
+// When Cursor is set, keyset pagination is used and Page is ignored.
+type Params struct {
+	Page          int
+	PageSize      int
+	Cursor        string
+	Sort          string // API sort key
+	Desc          bool
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// Page is the pagination metadata returned with a list response
+type Page struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"pageSize"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"totalPages"`
+	Sort       string `json:"sort"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// QueryFunc reads a query parameter, matching the signature of fiber.Ctx.Query
+type QueryFunc func(key string, defaultValue ...string) string
+
+// Parse reads pagination parameters from a request's query string:
+// page, pageSize, cursor, sort (prefix with "-" for descending), status,
+// createdAfter and createdBefore (RFC 3339).
+func Parse(query QueryFunc, opts Options) (*Params, error) {
+	params := &Params{Page: 1, PageSize: DefaultPageSize}
+
+	if page, err := strconv.Atoi(query("page", "1")); err == nil && page > 0 {
+		params.Page = page
+	}
+	if pageSize, err := strconv.Atoi(query("pageSize", strconv.Itoa(DefaultPageSize))); err == nil && pageSize > 0 && pageSize <= MaxPageSize {
+		params.PageSize = pageSize
+	}
+
+	sortParam := query("sort", opts.DefaultSort)
+	params.Desc = strings.HasPrefix(sortParam, "-")
+	params.Sort = strings.TrimPrefix(sortParam, "-")
+	if _, ok := opts.SortFields[params.Sort]; !ok {
+		return nil, apperrors.Validation("INVALID_SORT", "Unsupported sort field").
+			WithDetails(map[string]interface{}{"sort": params.Sort, "allowed": sortKeys(opts.SortFields)})
+	}
+
+	params.Cursor = query("cursor")
+	if opts.StatusColumn != "" {
+		params.Status = query("status")
+	}
+
+	for key, target := range map[string]**time.Time{"createdAfter": &params.CreatedAfter, "createdBefore": &params.CreatedBefore} {
+		value := query(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, apperrors.Validation("INVALID_FILTER", key+" must be an RFC 3339 timestamp").WithCause(err)
+		}
+		*target = &parsed
+	}
+
+	return params, nil
+}
+
+// SortString returns the sort in its query string form, e.g. "-createdAt"
+func (p *Params) SortString() string {
+	if p.Desc {
+		return "-" + p.Sort
+	}
+	return p.Sort
+}
+
+// cursor is the opaque position encoded into a cursor token
+type cursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	Time  bool      `json:"t,omitempty"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// encodeCursor creates a cursor token pointing after a row
+func encodeCursor(sortOrder string, value interface{}, id uuid.UUID) (string, error) {
+	c := cursor{Sort: sortOrder, ID: id}
+	switch v := value.(type) {
+	case time.Time:
+		c.Value = v.UTC().Format(time.RFC3339Nano)
+		c.Time = true
+	case gorm.DeletedAt:
+		// Lists of deleted rows sort by their deletion time
+		c.Value = v.Time.UTC().Format(time.RFC3339Nano)
+		c.Time = true
+	default:
+		c.Value = fmt.Sprint(v)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses a cursor token and returns the sort value and row ID it points after
+func decodeCursor(token, sortOrder string) (interface{}, uuid.UUID, error) {
+	invalid := apperrors.Validation("INVALID_CURSOR", "Invalid pagination cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, uuid.Nil, invalid.WithCause(err)
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, uuid.Nil, invalid.WithCause(err)
+	}
+	if c.Sort != sortOrder {
+		return nil, uuid.Nil, apperrors.Validation("INVALID_CURSOR", "Cursor was issued for a different sort order")
+	}
+
+	if c.Time {
+		value, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, uuid.Nil, invalid.WithCause(err)
+		}
+		return value, c.ID, nil
+	}
+	return c.Value, c.ID, nil
+}
+
+// schemaCache caches parsed model schemas used to read cursor values
+var schemaCache = &sync.Map{}
+
+// Paginate applies filters, sorting and pagination to query and loads one page of T.
+// The query must already be scoped to the model, e.g. db.Model(&models.Tenant{}).
+// Rows are ordered by the sort column with the primary key "id" as tie-breaker.
+func Paginate[T any](ctx context.Context, query *gorm.DB, params *Params, opts Options) ([]T, *Page, error) {
+	column, ok := opts.SortFields[params.Sort]
+	if !ok {
+		return nil, nil, apperrors.Validation("INVALID_SORT", "Unsupported sort field")
+	}
+
+	query = query.WithContext(ctx)
+	if params.Status != "" && opts.StatusColumn != "" {
+		query = query.Where(opts.StatusColumn+" = ?", params.Status)
+	}
+	if params.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *params.CreatedAfter)
+	}
+	if params.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *params.CreatedBefore)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count results: %w", err)
+	}
+
+	direction, comparison := "ASC", ">"
+	if params.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	page := query.Session(&gorm.Session{}).
+		Order(fmt.Sprintf("%s %s", column, direction)).
+		Order("id " + direction).
+		Limit(params.PageSize + 1)
+
+	if params.Cursor != "" {
+		value, id, err := decodeCursor(params.Cursor, params.SortString())
+		if err != nil {
+			return nil, nil, err
+		}
+		page = page.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, id)
+	} else {
+		page = page.Offset((params.Page - 1) * params.PageSize)
+	}
+
+	var rows []T
+	if err := page.Find(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list results: %w", err)
+	}
+
+	meta := &Page{
+		PageSize:   params.PageSize,
+		Total:      total,
+		TotalPages: (total + int64(params.PageSize) - 1) / int64(params.PageSize),
+		Sort:       params.SortString(),
+	}
+	if params.Cursor == "" {
+		meta.Page = params.Page
+	}
+
+	if len(rows) > params.PageSize {
+		rows = rows[:params.PageSize]
+		meta.HasMore = true
+
+		next, err := nextCursor(ctx, query, rows[len(rows)-1], column, meta.Sort)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta.NextCursor = next
+	}
+
+	return rows, meta, nil
+}
+
+// nextCursor builds the cursor pointing after the given row
+func nextCursor[T any](ctx context.Context, db *gorm.DB, row T, column, sortOrder string) (string, error) {
+	modelSchema, err := schema.Parse(&row, schemaCache, db.NamingStrategy)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse model schema: %w", err)
+	}
+
+	sortField := modelSchema.LookUpField(column)
+	idField := modelSchema.LookUpField("id")
+	if sortField == nil || idField == nil {
+		return "", fmt.Errorf("model %s has no %s or id column", modelSchema.Name, column)
+	}
+
+	rowValue := reflect.ValueOf(&row).Elem()
+	value, _ := sortField.ValueOf(ctx, rowValue)
+	idValue, _ := idField.ValueOf(ctx, rowValue)
+
+	id, ok := idValue.(uuid.UUID)
+	if !ok {
+		return "", fmt.Errorf("model %s does not use UUID primary keys", modelSchema.Name)
+	}
+
+	return encodeCursor(sortOrder, value, id)
+}
+
+// sortKeys returns the supported sort keys
+func sortKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
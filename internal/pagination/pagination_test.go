@@ -0,0 +1,119 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"gorm.io/gorm"
+)
+
+var testOptions = Options{
+	SortFields:   map[string]string{"createdAt": "created_at", "name": "name"},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+func queryFrom(values map[string]string) QueryFunc {
+	return func(key string, defaultValue ...string) string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+		return ""
+	}
+}
+
+func TestParse(t *testing.T) {
+	params, err := Parse(queryFrom(nil), testOptions)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if params.Page != 1 || params.PageSize != DefaultPageSize {
+		t.Errorf("Expected page 1 of size %d, got page %d of size %d", DefaultPageSize, params.Page, params.PageSize)
+	}
+	if params.Sort != "createdAt" || !params.Desc {
+		t.Errorf("Expected default sort -createdAt, got %s", params.SortString())
+	}
+
+	params, err = Parse(queryFrom(map[string]string{
+		"page":         "3",
+		"pageSize":     "500",
+		"sort":         "name",
+		"status":       "active",
+		"createdAfter": "2024-01-01T00:00:00Z",
+	}), testOptions)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if params.Page != 3 {
+		t.Errorf("Expected page 3, got %d", params.Page)
+	}
+	if params.PageSize != DefaultPageSize {
+		t.Errorf("Expected oversized pageSize to fall back to %d, got %d", DefaultPageSize, params.PageSize)
+	}
+	if params.SortString() != "name" {
+		t.Errorf("Expected sort name, got %s", params.SortString())
+	}
+	if params.Status != "active" {
+		t.Errorf("Expected status active, got %s", params.Status)
+	}
+	if params.CreatedAfter == nil || params.CreatedAfter.Year() != 2024 {
+		t.Errorf("Expected createdAfter in 2024, got %v", params.CreatedAfter)
+	}
+
+	tests := []struct {
+		name  string
+		query map[string]string
+	}{
+		{"unknown sort field", map[string]string{"sort": "password"}},
+		{"invalid timestamp", map[string]string{"createdBefore": "yesterday"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(queryFrom(tt.query), testOptions); !errors.Is(err, apperrors.ErrValidation) {
+				t.Errorf("Expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC)
+
+	token, err := encodeCursor("-createdAt", createdAt, id)
+	if err != nil {
+		t.Fatalf("Failed to encode cursor: %v", err)
+	}
+
+	value, decodedID, err := decodeCursor(token, "-createdAt")
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	if decodedID != id {
+		t.Errorf("Expected ID %s, got %s", id, decodedID)
+	}
+	if decoded, ok := value.(time.Time); !ok || !decoded.Equal(createdAt) {
+		t.Errorf("Expected value %v, got %v", createdAt, value)
+	}
+
+	token, err = encodeCursor("-deletedAt", gorm.DeletedAt{Time: createdAt, Valid: true}, id)
+	if err != nil {
+		t.Fatalf("Failed to encode cursor: %v", err)
+	}
+	if value, _, err := decodeCursor(token, "-deletedAt"); err != nil || !value.(time.Time).Equal(createdAt) {
+		t.Errorf("Expected deletion time %v, got %v, %v", createdAt, value, err)
+	}
+
+	if _, _, err := decodeCursor(token, "name"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for mismatched sort, got %v", err)
+	}
+	if _, _, err := decodeCursor("not-a-cursor", "-createdAt"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for malformed cursor, got %v", err)
+	}
+}
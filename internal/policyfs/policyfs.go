@@ -0,0 +1,107 @@
+// Package policyfs reads and writes policy sets stored as .rego files in a
+// directory tree. A file's path relative to the root, without the .rego
+// extension, is the policy's path, e.g. "heimdall/authz/users.rego" holds the
+// policy "heimdall/authz/users".
+package policyfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extension is the file extension of policy files
+const Extension = ".rego"
+
+// File is a policy stored as a file
+type File struct {
+	Path    string `json:"path" validate:"required,max=500"` // Policy path, e.g. "heimdall/authz/users"
+	Content string `json:"content" validate:"required"`
+}
+
+// ErrInvalidPath is returned for paths that are empty or escape the policy root
+var ErrInvalidPath = errors.New("invalid policy path")
+
+// NormalizePath converts a file or policy path into a clean policy path using
+// forward slashes, without a leading slash or the .rego extension
+func NormalizePath(p string) (string, error) {
+	p = strings.TrimSuffix(filepath.ToSlash(strings.TrimSpace(p)), Extension)
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." || (segment != "." && strings.HasPrefix(segment, ".")) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidPath, p)
+		}
+	}
+
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "", ErrInvalidPath
+	}
+	return p, nil
+}
+
+// Read loads every .rego file below root, sorted by path. Rego unit tests
+// (*_test.rego) and hidden directories such as .git are skipped.
+func Read(root string) ([]File, error) {
+	var files []File
+
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(entry.Name(), Extension) || strings.HasSuffix(entry.Name(), "_test"+Extension) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		policyPath, err := NormalizePath(relative)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+
+		files = append(files, File{Path: policyPath, Content: string(content)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Write stores files below root, creating directories as needed
+func Write(root string, files []File) error {
+	for _, file := range files {
+		policyPath, err := NormalizePath(file.Path)
+		if err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(root, filepath.FromSlash(policyPath)+Extension)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath, []byte(file.Content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package policyfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"heimdall/authz/users.rego", "heimdall/authz/users"},
+		{"/heimdall/authz/users", "heimdall/authz/users"},
+		{"heimdall//authz/./users", "heimdall/authz/users"},
+		{" users.rego ", "users"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePath(tt.input)
+		if err != nil {
+			t.Errorf("NormalizePath(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "/", ".rego", "../outside", "heimdall/../../outside", ".git/config", "heimdall/.hidden"} {
+		if _, err := NormalizePath(input); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("NormalizePath(%q) error = %v, want ErrInvalidPath", input, err)
+		}
+	}
+}
+
+func TestReadWriteRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	files := []File{
+		{Path: "heimdall/authz/users", Content: "package heimdall.authz.users\n"},
+		{Path: "heimdall/rbac", Content: "package heimdall.rbac\n"},
+	}
+	if err := Write(root, files); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// Tests, hidden directories and other files are not policies
+	extra := map[string]string{
+		"heimdall/rbac_test.rego": "package heimdall.rbac_test\n",
+		".git/hooks/x.rego":       "package x\n",
+		"README.md":               "# Policies\n",
+	}
+	for name, content := range extra {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Read(root)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, files) {
+		t.Errorf("Read = %+v, want %+v", got, files)
+	}
+}
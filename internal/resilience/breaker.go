@@ -0,0 +1,132 @@
+// Package resilience protects calls to external services, such as OPA and
+// FusionAuth, with circuit breakers and jittered retries
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the service while its circuit
+// breaker is open after consecutive failures
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Requests are sent to the service
+	BreakerOpen     = "open"      // Requests fail fast until the cooldown passes
+	BreakerHalfOpen = "half-open" // One probe request decides whether to close again
+)
+
+// BreakerStats reports the state of the circuit breaker and the requests it saw
+type BreakerStats struct {
+	State               string `json:"state" example:"closed"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	Trips               int64  `json:"trips"` // Times the breaker opened
+	OpenedAt            string `json:"openedAt,omitempty" example:"2024-01-15T10:30:00Z"`
+	Requests            int64  `json:"requests"`
+	Failures            int64  `json:"failures"`
+	Retries             int64  `json:"retries"`
+	Rejected            int64  `json:"rejected"` // Failed fast while open
+	LastError           string `json:"lastError,omitempty"`
+}
+
+// Breaker stops sending requests to a service after threshold consecutive
+// failures, so callers fail fast instead of each waiting for the timeout. After
+// cooldown a single probe request is let through; its success closes the
+// breaker and its failure opens it again.
+type Breaker struct {
+	threshold int // Consecutive failures opening the breaker, 0 to never open
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	openedAt time.Time
+	probing  bool
+	stats    BreakerStats
+}
+
+// NewBreaker creates a closed circuit breaker opening after threshold
+// consecutive failures, 0 to never open it
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen while
+// the breaker is open or another request is probing the service. Allowed
+// requests must end with Record or Abandon.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+	}
+	switch {
+	case b.state == BreakerOpen, b.state == BreakerHalfOpen && b.probing:
+		b.stats.Rejected++
+		return ErrCircuitOpen
+	case b.state == BreakerHalfOpen:
+		b.probing = true
+	}
+	b.stats.Requests++
+	return nil
+}
+
+// Record records the outcome of an allowed request, after its retries
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.state = BreakerClosed
+		b.stats.ConsecutiveFailures = 0
+		return
+	}
+
+	b.stats.Failures++
+	b.stats.ConsecutiveFailures++
+	b.stats.LastError = err.Error()
+	if b.state == BreakerHalfOpen || (b.threshold > 0 && b.stats.ConsecutiveFailures >= b.threshold && b.state == BreakerClosed) {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.stats.Trips++
+	}
+}
+
+// Abandon ends an allowed request that its caller cancelled, which says
+// nothing about the service
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Retried counts a retry of a request
+func (b *Breaker) Retried() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Retries++
+}
+
+// Snapshot returns the breaker's state and counters
+func (b *Breaker) Snapshot() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats
+	stats.State = b.state
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		stats.State = BreakerHalfOpen
+	}
+	if b.state != BreakerClosed {
+		stats.OpenedAt = b.openedAt.UTC().Format(time.RFC3339)
+	}
+	return stats
+}
@@ -0,0 +1,200 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// maxWaitDoublings bounds how often WaitFor doubles the backoff, so a
+// dependency coming up late is noticed soon after
+const maxWaitDoublings = 5
+
+// WaitFor retries check with jittered backoff until it succeeds or wait
+// passes, so the server can start before a dependency it needs is up. With
+// wait 0, check runs once. It returns the last error of check.
+func WaitFor(ctx context.Context, name string, wait, backoff time.Duration, check func(ctx context.Context) error) error {
+	deadline := time.Now().Add(wait)
+	for attempts := 1; ; attempts++ {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if backoff <= 0 || remaining <= 0 {
+			return err
+		}
+		delay := min(RetryDelay(backoff, min(attempts, maxWaitDoublings)), remaining)
+		log.Printf("Waiting for %s (attempt %d): %v", name, attempts, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Dependency is an external service the server uses, such as OPA or the
+// bundle storage
+type Dependency struct {
+	Name string
+	// Critical dependencies fail readiness while they are unavailable
+	Critical bool
+	// Check reports whether the service is available
+	Check func(ctx context.Context) error
+	// Recover restores functionality once the service is available again,
+	// e.g. creating a missing bucket; the service stays unavailable until it
+	// succeeds. It may be nil.
+	Recover func(ctx context.Context) error
+}
+
+// DependencyStatus reports the availability of a dependency
+type DependencyStatus struct {
+	Status    string `json:"status" example:"ok"` // ok or down
+	Critical  bool   `json:"critical,omitempty"`
+	CheckedAt string `json:"checkedAt,omitempty" example:"2024-01-15T10:30:00Z"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Dependency statuses
+const (
+	DependencyOK   = "ok"
+	DependencyDown = "down"
+)
+
+type dependencyState struct {
+	Dependency
+	available bool
+	checkedAt time.Time
+	lastError error
+}
+
+// Dependencies tracks the availability of the server's dependencies. Those
+// unavailable at startup, or found unavailable later, are re-checked in the
+// background and recovered once they are back.
+type Dependencies struct {
+	timeout time.Duration // Timeout of each check
+
+	mu    sync.Mutex
+	items []*dependencyState
+}
+
+// NewDependencies creates a tracker checking dependencies with timeout
+func NewDependencies(timeout time.Duration) *Dependencies {
+	return &Dependencies{timeout: timeout}
+}
+
+// Add tracks a dependency, available when err, the result of its startup
+// check, is nil
+func (d *Dependencies) Add(dependency Dependency, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items = append(d.items, &dependencyState{
+		Dependency: dependency,
+		available:  err == nil,
+		checkedAt:  time.Now(),
+		lastError:  err,
+	})
+}
+
+// Run re-checks the dependencies every interval until ctx is cancelled
+func (d *Dependencies) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.CheckAll(ctx)
+		}
+	}
+}
+
+// CheckAll checks every dependency, recovering those that came back
+func (d *Dependencies) CheckAll(ctx context.Context) {
+	d.mu.Lock()
+	items := append([]*dependencyState(nil), d.items...)
+	d.mu.Unlock()
+
+	for _, item := range items {
+		err := d.check(ctx, item)
+
+		d.mu.Lock()
+		wasAvailable := item.available
+		item.available = err == nil
+		item.checkedAt = time.Now()
+		item.lastError = err
+		d.mu.Unlock()
+
+		switch {
+		case err != nil && wasAvailable:
+			log.Printf("⚠️  %s became unavailable: %v", item.Name, err)
+		case err == nil && !wasAvailable:
+			log.Printf("✅ %s is available again", item.Name)
+		}
+	}
+}
+
+// check checks a dependency, running its recovery, which may take longer than
+// a check, when it was unavailable
+func (d *Dependencies) check(ctx context.Context, item *dependencyState) error {
+	checkCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	err := item.Check(checkCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	wasAvailable := item.available
+	d.mu.Unlock()
+	if !wasAvailable && item.Recover != nil {
+		if err := item.Recover(ctx); err != nil {
+			return fmt.Errorf("failed to recover: %w", err)
+		}
+	}
+	return nil
+}
+
+// Ready reports whether every critical dependency is available
+func (d *Dependencies) Ready() bool {
+	if d == nil {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, item := range d.items {
+		if item.Critical && !item.available {
+			return false
+		}
+	}
+	return true
+}
+
+// Statuses returns the status of each dependency by name
+func (d *Dependencies) Statuses() map[string]DependencyStatus {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	statuses := make(map[string]DependencyStatus, len(d.items))
+	for _, item := range d.items {
+		status := DependencyStatus{
+			Status:    DependencyOK,
+			Critical:  item.Critical,
+			CheckedAt: item.checkedAt.UTC().Format(time.RFC3339),
+		}
+		if !item.available {
+			status.Status = DependencyDown
+			if item.lastError != nil {
+				status.Error = item.lastError.Error()
+			}
+		}
+		statuses[item.Name] = status
+	}
+	return statuses
+}
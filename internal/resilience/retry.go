@@ -0,0 +1,17 @@
+package resilience
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryDelay returns the delay before retrying after a number of attempts,
+// doubling from base with up to half of it randomized so retries of many
+// callers spread out
+func RetryDelay(base time.Duration, attempts int) time.Duration {
+	delay := base << (attempts - 1)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
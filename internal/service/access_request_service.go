@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccessRequestApprovePermission lets users approve and deny access requests.
+// Admins can too.
+const AccessRequestApprovePermission = "access_requests.approve"
+
+// AccessRequestService manages just-in-time access requests: users request a
+// role for a limited time with a justification, approvers are notified, and an
+// approval grants a temporary assignment that is removed once it expires.
+type AccessRequestService struct {
+	db     *gorm.DB
+	users  *UserService
+	events events.Publisher
+	mailer notify.Mailer
+}
+
+// NewAccessRequestService creates a new access request service. Approvals
+// assign roles through users, which caps their duration. A nil publisher or
+// mailer disables the corresponding notifications.
+func NewAccessRequestService(db *gorm.DB, users *UserService, publisher events.Publisher, mailer notify.Mailer) *AccessRequestService {
+	if publisher == nil {
+		publisher = events.NoopPublisher{}
+	}
+	if mailer == nil {
+		mailer = notify.NoopMailer{}
+	}
+	return &AccessRequestService{
+		db:     db,
+		users:  users,
+		events: publisher,
+		mailer: mailer,
+	}
+}
+
+// CreateAccessRequestRequest represents a request for temporary access to a role
+type CreateAccessRequestRequest struct {
+	RoleID          string `json:"roleId" validate:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	DurationMinutes int    `json:"durationMinutes" validate:"required,min=1" example:"240"`
+	Justification   string `json:"justification" validate:"required,min=1,max=1000" example:"Investigating incident INC-1234"`
+}
+
+// AccessRequestDecision represents an approver's decision on an access request
+type AccessRequestDecision struct {
+	Comment         string `json:"comment,omitempty" validate:"max=1000" example:"Approved for the incident"`
+	DurationMinutes int    `json:"durationMinutes,omitempty" validate:"omitempty,min=1" example:"120"` // Shortens the granted access when approving
+}
+
+// AccessRequestResponse represents an access request
+type AccessRequestResponse struct {
+	ID              string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID        string `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	RequesterID     string `json:"requesterId" example:"550e8400-e29b-41d4-a716-446655440002"`
+	RequesterEmail  string `json:"requesterEmail,omitempty" example:"alice@example.com"`
+	RoleID          string `json:"roleId" example:"550e8400-e29b-41d4-a716-446655440003"`
+	RoleName        string `json:"roleName,omitempty" example:"admin"`
+	Justification   string `json:"justification" example:"Investigating incident INC-1234"`
+	DurationMinutes int    `json:"durationMinutes" example:"240"`
+	Status          string `json:"status" example:"pending"` // pending, approved, denied or cancelled
+	DecidedBy       string `json:"decidedBy,omitempty" example:"550e8400-e29b-41d4-a716-446655440004"`
+	DecidedAt       string `json:"decidedAt,omitempty" example:"2024-01-20T08:05:00Z"`
+	DecisionComment string `json:"decisionComment,omitempty" example:"Approved for the incident"`
+	GrantedUntil    string `json:"grantedUntil,omitempty" example:"2024-01-20T12:05:00Z"` // When the granted role assignment expires
+	CreatedAt       string `json:"createdAt" example:"2024-01-20T08:00:00Z"`
+}
+
+// AccessRequestFilter narrows a listing of access requests
+type AccessRequestFilter struct {
+	RequesterID string
+	RoleID      string
+}
+
+// AccessRequestListOptions describes the sorting and filtering supported when
+// listing access requests
+var AccessRequestListOptions = pagination.Options{
+	SortFields:   map[string]string{"createdAt": "created_at"},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// CreateAccessRequest records a user's request for a role of the user's tenant
+// and notifies the tenant's approvers
+func (s *AccessRequestService) CreateAccessRequest(ctx context.Context, tenantID, requesterID uuid.UUID, req *CreateAccessRequestRequest) (*AccessRequestResponse, error) {
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_ROLE_ID", "Invalid role ID").WithCause(err)
+	}
+	if maxElevation := s.users.maxElevation; maxElevation > 0 && time.Duration(req.DurationMinutes)*time.Minute > maxElevation {
+		return nil, apperrors.Validation("ELEVATION_TOO_LONG", "Requested access cannot exceed the maximum duration").
+			WithDetails(map[string]interface{}{"maxDurationMinutes": int(maxElevation / time.Minute)})
+	}
+
+	var request *models.AccessRequest
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var requester models.User
+		if err := tx.Where("id = ? AND tenant_id = ?", requesterID, tenantID).First(&requester).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		var role models.Role
+		if err := tx.Where("id = ? AND tenant_id = ?", roleID, tenantID).First(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("ROLE_NOT_FOUND", "Role not found")
+			}
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+
+		var permanent int64
+		if err := tx.Model(&models.UserRole{}).
+			Where("user_id = ? AND role_id = ? AND expires_at IS NULL", requesterID, roleID).
+			Count(&permanent).Error; err != nil {
+			return fmt.Errorf("failed to check role assignments: %w", err)
+		}
+		if permanent > 0 {
+			return apperrors.Conflict("ROLE_ALREADY_ASSIGNED", "User already has the role permanently")
+		}
+
+		var pending int64
+		if err := tx.Model(&models.AccessRequest{}).
+			Where("requester_id = ? AND role_id = ? AND status = ?", requesterID, roleID, models.AccessRequestStatusPending).
+			Count(&pending).Error; err != nil {
+			return fmt.Errorf("failed to check pending access requests: %w", err)
+		}
+		if pending > 0 {
+			return apperrors.Conflict("ACCESS_REQUEST_PENDING", "An access request for this role is already pending")
+		}
+
+		request = &models.AccessRequest{
+			TenantID:        tenantID,
+			RequesterID:     requesterID,
+			RoleID:          roleID,
+			Justification:   req.Justification,
+			DurationMinutes: req.DurationMinutes,
+			Status:          models.AccessRequestStatusPending,
+		}
+		if err := tx.Create(request).Error; err != nil {
+			return fmt.Errorf("failed to create access request: %w", err)
+		}
+		request.Requester = requester
+		request.Role = role
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyApprovers(ctx, request)
+	return toAccessRequestResponse(request), nil
+}
+
+// GetAccessRequest retrieves an access request of a tenant
+func (s *AccessRequestService) GetAccessRequest(ctx context.Context, tenantID uuid.UUID, requestID string) (*AccessRequestResponse, error) {
+	id, err := uuid.Parse(requestID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_ACCESS_REQUEST_ID", "Invalid access request ID").WithCause(err)
+	}
+
+	var request models.AccessRequest
+	err = readReplica(s.db).WithContext(ctx).
+		Preload("Requester").Preload("Role", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("ACCESS_REQUEST_NOT_FOUND", "Access request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access request: %w", err)
+	}
+	return toAccessRequestResponse(&request), nil
+}
+
+// ListAccessRequests returns a page of a tenant's access requests
+func (s *AccessRequestService) ListAccessRequests(ctx context.Context, tenantID uuid.UUID, filter AccessRequestFilter, params *pagination.Params) ([]AccessRequestResponse, *pagination.Page, error) {
+	query := readReplica(s.db).Model(&models.AccessRequest{}).
+		Preload("Requester").Preload("Role", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("tenant_id = ?", tenantID)
+	if filter.RequesterID != "" {
+		requesterID, err := uuid.Parse(filter.RequesterID)
+		if err != nil {
+			return nil, nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+		}
+		query = query.Where("requester_id = ?", requesterID)
+	}
+	if filter.RoleID != "" {
+		roleID, err := uuid.Parse(filter.RoleID)
+		if err != nil {
+			return nil, nil, apperrors.Validation("INVALID_ROLE_ID", "Invalid role ID").WithCause(err)
+		}
+		query = query.Where("role_id = ?", roleID)
+	}
+
+	requests, page, err := pagination.Paginate[models.AccessRequest](ctx, query, params, AccessRequestListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list access requests: %w", err)
+	}
+
+	responses := make([]AccessRequestResponse, len(requests))
+	for i := range requests {
+		responses[i] = *toAccessRequestResponse(&requests[i])
+	}
+	return responses, page, nil
+}
+
+// ApproveAccessRequest approves a pending request and grants the requested
+// role until the requested duration, or the approver's shorter one, has
+// passed. Users cannot approve their own requests.
+func (s *AccessRequestService) ApproveAccessRequest(ctx context.Context, tenantID uuid.UUID, requestID string, approverID uuid.UUID, decision *AccessRequestDecision) (*AccessRequestResponse, error) {
+	request, err := s.decide(ctx, tenantID, requestID, approverID, func(tx *gorm.DB, request *models.AccessRequest) error {
+		duration := request.DurationMinutes
+		if decision.DurationMinutes > 0 {
+			if decision.DurationMinutes > request.DurationMinutes {
+				return apperrors.Validation("INVALID_DURATION", "Approved duration cannot exceed the requested duration")
+			}
+			duration = decision.DurationMinutes
+		}
+
+		grantedUntil := time.Now().Add(time.Duration(duration) * time.Minute)
+		reason := fmt.Sprintf("Access request %s: %s", request.ID, request.Justification)
+		if _, err := s.users.assignRole(ctx, tx, request.RequesterID, request.RoleID, approverID, &grantedUntil, reason, false); err != nil {
+			return err
+		}
+		request.Status = models.AccessRequestStatusApproved
+		request.GrantedUntil = &grantedUntil
+		request.DecisionComment = decision.Comment
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.users.rolesChanged(ctx, request.RequesterID)
+	s.notifyRequester(ctx, request, events.EventAccessRequestApproved)
+	return toAccessRequestResponse(request), nil
+}
+
+// DenyAccessRequest denies a pending request
+func (s *AccessRequestService) DenyAccessRequest(ctx context.Context, tenantID uuid.UUID, requestID string, approverID uuid.UUID, decision *AccessRequestDecision) (*AccessRequestResponse, error) {
+	request, err := s.decide(ctx, tenantID, requestID, approverID, func(tx *gorm.DB, request *models.AccessRequest) error {
+		request.Status = models.AccessRequestStatusDenied
+		request.DecisionComment = decision.Comment
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyRequester(ctx, request, events.EventAccessRequestDenied)
+	return toAccessRequestResponse(request), nil
+}
+
+// CancelAccessRequest withdraws a pending request of the requester
+func (s *AccessRequestService) CancelAccessRequest(ctx context.Context, tenantID uuid.UUID, requestID string, requesterID uuid.UUID) (*AccessRequestResponse, error) {
+	var request *models.AccessRequest
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = s.lockPending(tx, tenantID, requestID)
+		if err != nil {
+			return err
+		}
+		if request.RequesterID != requesterID {
+			return apperrors.NotFound("ACCESS_REQUEST_NOT_FOUND", "Access request not found")
+		}
+
+		request.Status = models.AccessRequestStatusCancelled
+		if err := tx.Model(request).Select("status", "updated_at").Updates(request).Error; err != nil {
+			return fmt.Errorf("failed to cancel access request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAccessRequestResponse(request), nil
+}
+
+// decide applies an approver's decision to a pending request in a transaction
+func (s *AccessRequestService) decide(ctx context.Context, tenantID uuid.UUID, requestID string, approverID uuid.UUID, apply func(tx *gorm.DB, request *models.AccessRequest) error) (*models.AccessRequest, error) {
+	var request *models.AccessRequest
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = s.lockPending(tx, tenantID, requestID)
+		if err != nil {
+			return err
+		}
+		if request.RequesterID == approverID {
+			return apperrors.Forbidden("SELF_APPROVAL", "Users cannot decide on their own access requests")
+		}
+
+		if err := apply(tx, request); err != nil {
+			return err
+		}
+		now := time.Now()
+		request.DecidedBy = &approverID
+		request.DecidedAt = &now
+		if err := tx.Model(request).
+			Select("status", "decided_by", "decided_at", "decision_comment", "granted_until", "updated_at").
+			Updates(request).Error; err != nil {
+			return fmt.Errorf("failed to update access request: %w", err)
+		}
+		return nil
+	})
+	return request, err
+}
+
+// lockPending locks a pending request of a tenant for update
+func (s *AccessRequestService) lockPending(tx *gorm.DB, tenantID uuid.UUID, requestID string) (*models.AccessRequest, error) {
+	id, err := uuid.Parse(requestID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_ACCESS_REQUEST_ID", "Invalid access request ID").WithCause(err)
+	}
+
+	var request models.AccessRequest
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("ACCESS_REQUEST_NOT_FOUND", "Access request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access request: %w", err)
+	}
+	if request.Status != models.AccessRequestStatusPending {
+		return nil, apperrors.Conflict("ACCESS_REQUEST_DECIDED", "Access request is no longer pending").
+			WithDetails(map[string]interface{}{"status": request.Status})
+	}
+
+	if err := tx.Preload("Requester").Preload("Role", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&request, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get access request: %w", err)
+	}
+	return &request, nil
+}
+
+// notifyApprovers emits an event for a new request and emails the tenant's
+// approvers: users with the approve permission or the admin role
+func (s *AccessRequestService) notifyApprovers(ctx context.Context, request *models.AccessRequest) {
+	s.events.Publish(ctx, events.NewEvent(events.EventAccessRequested, request.TenantID.String(), accessRequestEventData(request)))
+
+	var emails []string
+	err := s.db.WithContext(ctx).Model(&models.User{}).
+		Distinct("users.email").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id AND roles.deleted_at IS NULL").
+		Joins("LEFT JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Joins("LEFT JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("users.tenant_id = ? AND users.status = ? AND users.id <> ?", request.TenantID, models.UserStatusActive, request.RequesterID).
+		Where(activeRoleAssignment, time.Now()).
+		Where("roles.name = ? OR permissions.name = ?", "admin", AccessRequestApprovePermission).
+		Pluck("users.email", &emails).Error
+	if err != nil {
+		log.Printf("Failed to find approvers of access request %s: %v", request.ID, err)
+		return
+	}
+
+	body := fmt.Sprintf(
+		"%s requested the %s role for %d minutes.\n\nJustification: %s\n\n"+
+			"Approve or deny the request with POST /v1/access-requests/%s/approve or /deny.",
+		request.Requester.Email, request.Role.Name, request.DurationMinutes, request.Justification, request.ID,
+	)
+	for _, email := range emails {
+		if err := s.mailer.Send(ctx, email, "Access request for the "+request.Role.Name+" role", body); err != nil {
+			log.Printf("Failed to send access request notification to %s: %v", email, err)
+		}
+	}
+}
+
+// notifyRequester emits an event for a decision and emails the requester
+func (s *AccessRequestService) notifyRequester(ctx context.Context, request *models.AccessRequest, eventType string) {
+	s.events.Publish(ctx, events.NewEvent(eventType, request.TenantID.String(), accessRequestEventData(request)))
+
+	if request.Requester.Email == "" {
+		return
+	}
+	body := fmt.Sprintf("Your request for the %s role was %s.", request.Role.Name, request.Status)
+	if request.GrantedUntil != nil {
+		body += fmt.Sprintf(" Access expires at %s.", request.GrantedUntil.UTC().Format("2006-01-02 15:04:05 MST"))
+	}
+	if request.DecisionComment != "" {
+		body += "\n\nComment: " + request.DecisionComment
+	}
+	if err := s.mailer.Send(ctx, request.Requester.Email, "Access request "+request.Status, body); err != nil {
+		log.Printf("Failed to send access request decision to %s: %v", request.Requester.Email, err)
+	}
+}
+
+func accessRequestEventData(request *models.AccessRequest) map[string]interface{} {
+	data := map[string]interface{}{
+		"accessRequestId": request.ID.String(),
+		"requesterId":     request.RequesterID.String(),
+		"requesterEmail":  request.Requester.Email,
+		"roleId":          request.RoleID.String(),
+		"roleName":        request.Role.Name,
+		"durationMinutes": request.DurationMinutes,
+		"justification":   request.Justification,
+		"status":          request.Status,
+	}
+	if request.DecidedBy != nil {
+		data["decidedBy"] = request.DecidedBy.String()
+	}
+	if request.GrantedUntil != nil {
+		data["grantedUntil"] = request.GrantedUntil.UTC().Format(time.RFC3339)
+	}
+	return data
+}
+
+func toAccessRequestResponse(request *models.AccessRequest) *AccessRequestResponse {
+	response := &AccessRequestResponse{
+		ID:              request.ID.String(),
+		TenantID:        request.TenantID.String(),
+		RequesterID:     request.RequesterID.String(),
+		RequesterEmail:  request.Requester.Email,
+		RoleID:          request.RoleID.String(),
+		RoleName:        request.Role.Name,
+		Justification:   request.Justification,
+		DurationMinutes: request.DurationMinutes,
+		Status:          request.Status,
+		DecisionComment: request.DecisionComment,
+		CreatedAt:       request.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if request.DecidedBy != nil {
+		response.DecidedBy = request.DecidedBy.String()
+	}
+	if request.DecidedAt != nil {
+		response.DecidedAt = request.DecidedAt.UTC().Format(time.RFC3339)
+	}
+	if request.GrantedUntil != nil {
+		response.GrantedUntil = request.GrantedUntil.UTC().Format(time.RFC3339)
+	}
+	return response
+}
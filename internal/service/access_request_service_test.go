@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// recordingPublisher keeps the types of the events published
+type recordingPublisher struct {
+	types []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) {
+	p.types = append(p.types, event.Type)
+}
+
+// recordingInbox keeps the recipients of the messages sent
+type recordingInbox struct {
+	to []string
+}
+
+func (m *recordingInbox) Send(ctx context.Context, to, subject, body string) error {
+	m.to = append(m.to, to)
+	return nil
+}
+
+func TestAccessRequests(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.com")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.com")
+		carol := testutil.CreateTestUser(t, db, acme, "carol@acme.com")
+		admin := testutil.CreateTestRole(t, db, acme, "admin")
+		approver := testutil.CreateTestRole(t, db, acme, "approver")
+		editor := testutil.CreateTestRole(t, db, acme, "editor")
+		testutil.AssignPermissionToRole(t, db, approver, testutil.CreateTestPermission(t, db, AccessRequestApprovePermission, "access_requests", "approve"))
+		testutil.AssignRoleToUser(t, db, bob, approver)
+		testutil.AssignRoleToUser(t, db, carol, editor)
+
+		userService := NewUserService(db, nil)
+		userService.SetMaxElevation(8 * time.Hour)
+		publisher := &recordingPublisher{}
+		inbox := &recordingInbox{}
+		accessRequests := NewAccessRequestService(db, userService, publisher, inbox)
+
+		// Approvers are notified of new requests
+		request, err := accessRequests.CreateAccessRequest(ctx, acme.ID, alice.ID, &CreateAccessRequestRequest{
+			RoleID:          admin.ID.String(),
+			DurationMinutes: 120,
+			Justification:   "Incident INC-1234",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create access request: %v", err)
+		}
+		if request.Status != models.AccessRequestStatusPending || request.RoleName != "admin" || request.RequesterEmail != "alice@acme.com" {
+			t.Errorf("Expected a pending request for admin, got %+v", request)
+		}
+		if !equalStrings(publisher.types, []string{events.EventAccessRequested}) || !equalStrings(inbox.to, []string{"bob@acme.com"}) {
+			t.Errorf("Expected bob to be notified, got events %v and emails %v", publisher.types, inbox.to)
+		}
+
+		if _, err := accessRequests.CreateAccessRequest(ctx, acme.ID, alice.ID, &CreateAccessRequestRequest{RoleID: admin.ID.String(), DurationMinutes: 60, Justification: "Again"}); !isAppError(err, "ACCESS_REQUEST_PENDING") {
+			t.Errorf("Expected ACCESS_REQUEST_PENDING, got %v", err)
+		}
+		if _, err := accessRequests.CreateAccessRequest(ctx, acme.ID, carol.ID, &CreateAccessRequestRequest{RoleID: editor.ID.String(), DurationMinutes: 60, Justification: "Already an editor"}); !isAppError(err, "ROLE_ALREADY_ASSIGNED") {
+			t.Errorf("Expected ROLE_ALREADY_ASSIGNED, got %v", err)
+		}
+		if _, err := accessRequests.CreateAccessRequest(ctx, acme.ID, alice.ID, &CreateAccessRequestRequest{RoleID: editor.ID.String(), DurationMinutes: 9 * 60, Justification: "Too long"}); !isAppError(err, "ELEVATION_TOO_LONG") {
+			t.Errorf("Expected ELEVATION_TOO_LONG, got %v", err)
+		}
+
+		// Approving grants the role until the approved duration has passed
+		if _, err := accessRequests.ApproveAccessRequest(ctx, acme.ID, request.ID, alice.ID, &AccessRequestDecision{}); !isAppError(err, "SELF_APPROVAL") {
+			t.Errorf("Expected SELF_APPROVAL, got %v", err)
+		}
+		if _, err := accessRequests.ApproveAccessRequest(ctx, acme.ID, request.ID, bob.ID, &AccessRequestDecision{DurationMinutes: 240}); !isAppError(err, "INVALID_DURATION") {
+			t.Errorf("Expected INVALID_DURATION, got %v", err)
+		}
+		publisher.types, inbox.to = nil, nil
+		approved, err := accessRequests.ApproveAccessRequest(ctx, acme.ID, request.ID, bob.ID, &AccessRequestDecision{Comment: "Go ahead", DurationMinutes: 60})
+		if err != nil {
+			t.Fatalf("Failed to approve access request: %v", err)
+		}
+		if approved.Status != models.AccessRequestStatusApproved || approved.DecidedBy != bob.ID.String() || approved.GrantedUntil == "" {
+			t.Errorf("Expected an approved request, got %+v", approved)
+		}
+		if !equalStrings(publisher.types, []string{events.EventAccessRequestApproved}) || !equalStrings(inbox.to, []string{"alice@acme.com"}) {
+			t.Errorf("Expected alice to be notified, got events %v and emails %v", publisher.types, inbox.to)
+		}
+		assignments, err := userService.GetRoleAssignments(ctx, alice.ID.String())
+		if err != nil || len(assignments) != 1 || assignments[0].RoleName != "admin" || assignments[0].ExpiresAt == "" {
+			t.Errorf("Expected a temporary admin assignment, got %+v, %v", assignments, err)
+		}
+		if _, err := accessRequests.DenyAccessRequest(ctx, acme.ID, request.ID, bob.ID, &AccessRequestDecision{}); !isAppError(err, "ACCESS_REQUEST_DECIDED") {
+			t.Errorf("Expected ACCESS_REQUEST_DECIDED, got %v", err)
+		}
+
+		// Denied and cancelled requests grant nothing
+		denied, err := accessRequests.CreateAccessRequest(ctx, acme.ID, carol.ID, &CreateAccessRequestRequest{RoleID: admin.ID.String(), DurationMinutes: 60, Justification: "Curious"})
+		if err != nil {
+			t.Fatalf("Failed to create access request: %v", err)
+		}
+		if response, err := accessRequests.DenyAccessRequest(ctx, acme.ID, denied.ID, bob.ID, &AccessRequestDecision{Comment: "No"}); err != nil || response.Status != models.AccessRequestStatusDenied {
+			t.Errorf("Expected a denied request, got %+v, %v", response, err)
+		}
+
+		cancelled, err := accessRequests.CreateAccessRequest(ctx, acme.ID, carol.ID, &CreateAccessRequestRequest{RoleID: approver.ID.String(), DurationMinutes: 60, Justification: "Reviews"})
+		if err != nil {
+			t.Fatalf("Failed to create access request: %v", err)
+		}
+		if _, err := accessRequests.CancelAccessRequest(ctx, acme.ID, cancelled.ID, alice.ID); !isAppError(err, "ACCESS_REQUEST_NOT_FOUND") {
+			t.Errorf("Expected others' requests to be hidden, got %v", err)
+		}
+		if response, err := accessRequests.CancelAccessRequest(ctx, acme.ID, cancelled.ID, carol.ID); err != nil || response.Status != models.AccessRequestStatusCancelled {
+			t.Errorf("Expected a cancelled request, got %+v, %v", response, err)
+		}
+		if assignments, _ := userService.GetRoleAssignments(ctx, carol.ID.String()); len(assignments) != 1 {
+			t.Errorf("Expected carol to keep only the editor role, got %+v", assignments)
+		}
+
+		params, err := pagination.Parse(func(key string, defaultValue ...string) string {
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}, AccessRequestListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		list, page, err := accessRequests.ListAccessRequests(ctx, acme.ID, AccessRequestFilter{RequesterID: carol.ID.String()}, params)
+		if err != nil || len(list) != 2 || page.Total != 2 {
+			t.Errorf("Expected carol's two requests, got %+v, %v", list, err)
+		}
+	})
+}
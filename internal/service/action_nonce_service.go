@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// ConfirmedActions are the destructive actions that must be confirmed with an
+// action nonce
+var ConfirmedActions = []string{"tenants.delete", "users.deactivate", "bundles.activate", "bundles.delete", "policies.purge"}
+
+// ActionNonceService issues and consumes the one-time nonces confirming
+// destructive actions. Admin UIs fetch a nonce once the user confirmed an
+// action and send it with the request performing the action. A nonce is bound
+// to its user, action and resource, expires after a TTL and is accepted once.
+type ActionNonceService struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewActionNonceService creates a new action nonce service
+func NewActionNonceService(db *gorm.DB, ttl time.Duration) *ActionNonceService {
+	return &ActionNonceService{db: db, ttl: ttl}
+}
+
+// ActionNonceRequest names the action to confirm and the resource it applies to
+type ActionNonceRequest struct {
+	Action     string `json:"action" validate:"required,oneof=tenants.delete users.deactivate bundles.activate bundles.delete policies.purge" example:"tenants.delete"`
+	ResourceID string `json:"resourceId" validate:"required,max=255" example:"550e8400-e29b-41d4-a716-446655440000"` // ID of the tenant, user or bundle
+}
+
+// ActionNonceResponse carries an issued nonce, which is only returned once
+type ActionNonceResponse struct {
+	Nonce      string `json:"nonce" example:"3f1c9a7e5b2d4c6a8e0f1b3d5c7a9e2f4b6d8c0a1e3f5b7d9c2a4e6f8b0d1c3e"` // Sent in the X-Action-Nonce header
+	Action     string `json:"action" example:"tenants.delete"`
+	ResourceID string `json:"resourceId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ExpiresAt  string `json:"expiresAt" example:"2024-01-15T10:35:00Z"`
+}
+
+// IssueNonce issues a nonce confirming an action of a user on a resource. The
+// user's expired and used nonces are removed.
+func (s *ActionNonceService) IssueNonce(ctx context.Context, tenantID, userID uuid.UUID, req *ActionNonceRequest) (*ActionNonceResponse, error) {
+	if !slices.Contains(ConfirmedActions, req.Action) {
+		return nil, apperrors.Validation("INVALID_ACTION", "Action does not require confirmation")
+	}
+
+	nonce, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate action nonce: %w", err)
+	}
+	now := time.Now()
+	record := &models.ActionNonce{
+		TenantID:   tenantID,
+		UserID:     userID,
+		Action:     req.Action,
+		ResourceID: req.ResourceID,
+		NonceHash:  hashSecret(nonce),
+		ExpiresAt:  now.Add(s.ttl),
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND (expires_at <= ? OR used_at IS NOT NULL)", userID, now).
+			Delete(&models.ActionNonce{}).Error; err != nil {
+			return fmt.Errorf("failed to remove stale action nonces: %w", err)
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to create action nonce: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ActionNonceResponse{
+		Nonce:      nonce,
+		Action:     record.Action,
+		ResourceID: record.ResourceID,
+		ExpiresAt:  record.ExpiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// ConsumeNonce accepts a nonce confirming an action of a user on a resource,
+// marking it used so it cannot be replayed. Nonces issued for another user,
+// action or resource are rejected like unknown ones.
+func (s *ActionNonceService) ConsumeNonce(ctx context.Context, userID, action, resourceID, nonce string) error {
+	if nonce == "" {
+		return apperrors.Forbidden("ACTION_NONCE_REQUIRED", "This action must be confirmed with an action nonce")
+	}
+	user, err := uuid.Parse(userID)
+	if err != nil {
+		return apperrors.Forbidden("ACTION_NONCE_INVALID", "Action nonce is invalid or expired")
+	}
+
+	now := time.Now()
+	scope := s.db.WithContext(ctx).Model(&models.ActionNonce{}).
+		Where("nonce_hash = ? AND user_id = ? AND action = ? AND resource_id = ?", hashSecret(nonce), user, action, resourceID)
+
+	// The update is conditional, so of concurrent requests with a nonce one wins
+	result := scope.Session(&gorm.Session{}).
+		Where("used_at IS NULL AND expires_at > ?", now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return fmt.Errorf("failed to consume action nonce: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	var existing models.ActionNonce
+	err = scope.Session(&gorm.Session{}).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get action nonce: %w", err)
+	}
+	if err == nil && existing.UsedAt != nil {
+		return apperrors.Conflict("ACTION_NONCE_USED", "Action nonce was already used")
+	}
+	return apperrors.Forbidden("ACTION_NONCE_INVALID", "Action nonce is invalid or expired")
+}
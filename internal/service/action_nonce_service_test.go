@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestActionNonceService(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.test")
+		nonces := NewActionNonceService(db, 5*time.Minute)
+
+		if _, err := nonces.IssueNonce(ctx, acme.ID, alice.ID, &ActionNonceRequest{Action: "roles.read", ResourceID: "billing"}); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected actions without confirmation to be rejected, got %v", err)
+		}
+
+		issued, err := nonces.IssueNonce(ctx, acme.ID, alice.ID, &ActionNonceRequest{Action: "tenants.delete", ResourceID: acme.ID.String()})
+		if err != nil || len(issued.Nonce) != 64 || issued.ExpiresAt == "" {
+			t.Fatalf("Failed to issue nonce: %+v, %v", issued, err)
+		}
+
+		// Nonces are bound to their user, action and resource
+		for name, consume := range map[string]func() error{
+			"missing":          func() error { return nonces.ConsumeNonce(ctx, alice.ID.String(), "tenants.delete", acme.ID.String(), "") },
+			"unknown":          func() error { return nonces.ConsumeNonce(ctx, alice.ID.String(), "tenants.delete", acme.ID.String(), "0123") },
+			"another user":     func() error { return nonces.ConsumeNonce(ctx, bob.ID.String(), "tenants.delete", acme.ID.String(), issued.Nonce) },
+			"another action":   func() error { return nonces.ConsumeNonce(ctx, alice.ID.String(), "bundles.delete", acme.ID.String(), issued.Nonce) },
+			"another resource": func() error { return nonces.ConsumeNonce(ctx, alice.ID.String(), "tenants.delete", bob.ID.String(), issued.Nonce) },
+		} {
+			if err := consume(); !errors.Is(err, apperrors.ErrForbidden) {
+				t.Errorf("Expected %s nonce to be rejected, got %v", name, err)
+			}
+		}
+
+		if err := nonces.ConsumeNonce(ctx, alice.ID.String(), "tenants.delete", acme.ID.String(), issued.Nonce); err != nil {
+			t.Fatalf("Expected the nonce to be accepted, got %v", err)
+		}
+		if err := nonces.ConsumeNonce(ctx, alice.ID.String(), "tenants.delete", acme.ID.String(), issued.Nonce); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Expected the replayed nonce to be rejected, got %v", err)
+		}
+
+		// Expired nonces are rejected, and removed when the user fetches another
+		expired, err := nonces.IssueNonce(ctx, acme.ID, alice.ID, &ActionNonceRequest{Action: "bundles.activate", ResourceID: "bundle-1"})
+		if err != nil {
+			t.Fatalf("Failed to issue nonce: %v", err)
+		}
+		db.Model(&models.ActionNonce{}).Where("nonce_hash = ?", hashSecret(expired.Nonce)).Update("expires_at", time.Now().Add(-time.Second))
+		if err := nonces.ConsumeNonce(ctx, alice.ID.String(), "bundles.activate", "bundle-1", expired.Nonce); !errors.Is(err, apperrors.ErrForbidden) {
+			t.Errorf("Expected the expired nonce to be rejected, got %v", err)
+		}
+		if _, err := nonces.IssueNonce(ctx, acme.ID, alice.ID, &ActionNonceRequest{Action: "bundles.activate", ResourceID: "bundle-1"}); err != nil {
+			t.Fatalf("Failed to issue nonce: %v", err)
+		}
+		var remaining int64
+		db.Model(&models.ActionNonce{}).Where("user_id = ?", alice.ID).Count(&remaining)
+		if remaining != 1 {
+			t.Errorf("Expected only the new nonce to remain, got %d", remaining)
+		}
+	})
+}
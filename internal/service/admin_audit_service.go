@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// AdminActionEventType is the audit log event type of sensitive administrative
+// actions, such as role assignments and tenant suspensions
+const AdminActionEventType = "admin_action"
+
+// AdminAuditService records sensitive administrative actions in the audit log
+type AdminAuditService struct {
+	db *gorm.DB
+}
+
+// NewAdminAuditService creates a new admin audit service
+func NewAdminAuditService(db *gorm.DB) *AdminAuditService {
+	return &AdminAuditService{db: db}
+}
+
+// AdminActionFilter narrows the administrative actions listed
+type AdminActionFilter struct {
+	Action string // e.g. "roles.assign"
+	UserID string // Acting user
+}
+
+// AdminActionResponse represents a recorded administrative action
+type AdminActionResponse struct {
+	ID         string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID   string                 `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`         // Tenant of the acting user
+	UserID     string                 `json:"userId,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"` // Empty for actions Heimdall takes itself, e.g. roles.expire
+	Action     string                 `json:"action" example:"roles.assign"`
+	Resource   string                 `json:"resource,omitempty" example:"users"`
+	ResourceID string                 `json:"resourceId,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	Method     string                 `json:"method" example:"POST"`
+	Path       string                 `json:"path" example:"/v1/users/550e8400-e29b-41d4-a716-446655440002/roles"`
+	IPAddress  string                 `json:"ipAddress,omitempty" example:"203.0.113.7"`
+	UserAgent  string                 `json:"userAgent,omitempty" example:"Mozilla/5.0"`
+	Status     string                 `json:"status" example:"success"` // success, failure or error
+	StatusCode int                    `json:"statusCode" example:"200"`
+	Message    string                 `json:"message,omitempty" example:"FORBIDDEN"` // Error code of failed actions
+	Details    map[string]interface{} `json:"details,omitempty"`                     // Route parameters and redacted request and response payloads
+	DurationMs int64                  `json:"durationMs" example:"12"`
+	CreatedAt  string                 `json:"createdAt" example:"2024-01-20T14:45:00Z"`
+}
+
+// AdminActionListOptions describes the sorting and filtering supported when
+// listing administrative actions
+var AdminActionListOptions = pagination.Options{
+	SortFields:   map[string]string{"createdAt": "created_at"},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// RecordAdminAction stores an administrative action in the audit log
+func (s *AdminAuditService) RecordAdminAction(ctx context.Context, entry *models.AuditLog) error {
+	entry.EventType = AdminActionEventType
+	if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record admin action: %w", err)
+	}
+	return nil
+}
+
+// ListAdminActions returns a page of the administrative actions of a tenant's users
+func (s *AdminAuditService) ListAdminActions(ctx context.Context, tenantID string, filter AdminActionFilter, params *pagination.Params) ([]AdminActionResponse, *pagination.Page, error) {
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
+	}
+
+	query := readReplica(s.db).Model(&models.AuditLog{}).
+		Where("tenant_id = ? AND event_type = ?", tenantUUID, AdminActionEventType)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.UserID != "" {
+		userUUID, err := uuid.Parse(filter.UserID)
+		if err != nil {
+			return nil, nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+		}
+		query = query.Where("user_id = ?", userUUID)
+	}
+
+	entries, page, err := pagination.Paginate[models.AuditLog](ctx, query, params, AdminActionListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+
+	responses := make([]AdminActionResponse, len(entries))
+	for i := range entries {
+		responses[i] = toAdminActionResponse(&entries[i])
+	}
+	return responses, page, nil
+}
+
+func toAdminActionResponse(entry *models.AuditLog) AdminActionResponse {
+	response := AdminActionResponse{
+		ID:         entry.ID.String(),
+		TenantID:   entry.TenantID.String(),
+		Action:     entry.Action,
+		Resource:   entry.Resource,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+		Status:     entry.Status,
+		StatusCode: entry.StatusCode,
+		Message:    entry.Message,
+		DurationMs: entry.Duration,
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+	}
+	if entry.UserID != nil {
+		response.UserID = entry.UserID.String()
+	}
+	if entry.ResourceID != nil {
+		response.ResourceID = entry.ResourceID.String()
+	}
+	if len(entry.Metadata) > 0 {
+		_ = json.Unmarshal(entry.Metadata, &response.Details)
+	}
+	return response
+}
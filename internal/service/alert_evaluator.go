@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultAlertMinEvents is the number of decisions an error rate needs in its
+// window when a rule sets no minimum, so a few failures do not make a spike
+const defaultAlertMinEvents = 20
+
+// AlertEvaluator evaluates the tenants' alert rules on their audit logs and
+// delivers the alerts they fire: to the platform webhooks, and to the rule's
+// own webhook and PagerDuty service. A rule fires once per subject, a user or
+// an IP address, within its window.
+type AlertEvaluator struct {
+	db        *gorm.DB
+	events    events.Publisher
+	webhooks  config.WebhookConfig
+	pagerDuty *notify.PagerDuty
+}
+
+// NewAlertEvaluator creates a new alert evaluation job. Webhooks of rules are
+// signed with the secret of the platform webhooks. A nil publisher or PagerDuty
+// client disables the corresponding deliveries.
+func NewAlertEvaluator(db *gorm.DB, publisher events.Publisher, webhooks *config.WebhookConfig, pagerDuty *notify.PagerDuty) *AlertEvaluator {
+	if publisher == nil {
+		publisher = events.NoopPublisher{}
+	}
+	return &AlertEvaluator{
+		db:        db,
+		events:    publisher,
+		webhooks:  *webhooks,
+		pagerDuty: pagerDuty,
+	}
+}
+
+// Run evaluates the alert rules every interval until ctx is cancelled
+func (e *AlertEvaluator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fired, err := e.Evaluate(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to evaluate alert rules: %v", err)
+		}
+		if fired > 0 {
+			log.Printf("Fired %d alerts", fired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// alertBreach is a subject a rule's condition holds for, with the observed
+// count or percentage
+type alertBreach struct {
+	Subject string
+	Value   float64
+}
+
+// Evaluate evaluates the enabled alert rules once and returns the number of
+// alerts fired. Rules that fail to evaluate are reported in the error.
+func (e *AlertEvaluator) Evaluate(ctx context.Context) (int, error) {
+	var rules []models.AlertRule
+	if err := e.db.WithContext(ctx).Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		return 0, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+
+	fired := 0
+	var failures []error
+	for i := range rules {
+		alerts, err := e.evaluateRule(ctx, &rules[i], time.Now())
+		if err != nil {
+			if ctx.Err() != nil {
+				return fired, err
+			}
+			failures = append(failures, fmt.Errorf("rule %s: %w", rules[i].ID, err))
+			continue
+		}
+		for j := range alerts {
+			e.deliver(ctx, &rules[i], &alerts[j])
+		}
+		fired += len(alerts)
+	}
+	if len(failures) > 0 {
+		return fired, fmt.Errorf("%d alert rules failed to evaluate, first: %w", len(failures), failures[0])
+	}
+	return fired, nil
+}
+
+// evaluateRule records an alert for each subject the rule's condition holds
+// for that has not fired within the window. Instances evaluating the same rule
+// wait for each other on the rule's row, so each alert is recorded once.
+func (e *AlertEvaluator) evaluateRule(ctx context.Context, rule *models.AlertRule, now time.Time) ([]models.Alert, error) {
+	since := now.Add(-time.Duration(rule.WindowMinutes) * time.Minute)
+
+	var alerts []models.Alert
+	err := e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked models.AlertRule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("id = ? AND enabled = ?", rule.ID, true).First(&locked).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock alert rule: %w", err)
+		}
+
+		breaches, err := alertBreaches(tx, rule, since)
+		if err != nil {
+			return err
+		}
+		for _, breach := range breaches {
+			var recent int64
+			if err := tx.Model(&models.Alert{}).
+				Where("rule_id = ? AND subject = ? AND created_at > ?", rule.ID, breach.Subject, since).
+				Count(&recent).Error; err != nil {
+				return fmt.Errorf("failed to check recent alerts: %w", err)
+			}
+			if recent > 0 {
+				continue
+			}
+
+			alert := models.Alert{
+				TenantID:  rule.TenantID,
+				RuleID:    rule.ID,
+				Subject:   breach.Subject,
+				Value:     breach.Value,
+				Threshold: rule.Threshold,
+				Message:   alertMessage(rule, breach),
+				CreatedAt: now,
+			}
+			if err := tx.Create(&alert).Error; err != nil {
+				return fmt.Errorf("failed to record alert: %w", err)
+			}
+			alerts = append(alerts, alert)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// alertBreaches returns the subjects a rule's condition holds for since the
+// start of its window
+func alertBreaches(tx *gorm.DB, rule *models.AlertRule, since time.Time) ([]alertBreach, error) {
+	var breaches []alertBreach
+	query := tx.Model(&models.AuditLog{}).Where("tenant_id = ? AND created_at >= ?", rule.TenantID, since)
+
+	switch rule.Type {
+	case models.AlertRuleUserDenies:
+		// Requests denied by permission checks, recorded for audited routes
+		err := query.Select("user_id AS subject, COUNT(*) AS value").
+			Where("status_code = ? AND user_id IS NOT NULL", http.StatusForbidden).
+			Group("user_id").Having("COUNT(*) > ?", rule.Threshold).
+			Scan(&breaches).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count denied requests: %w", err)
+		}
+
+	case models.AlertRuleLoginFailures:
+		err := query.Select("ip_address AS subject, COUNT(*) AS value").
+			Where("event_type = ? AND ip_address <> ''", LoginFailureEventType).
+			Group("ip_address").Having("COUNT(*) > ?", rule.Threshold).
+			Scan(&breaches).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count login failures: %w", err)
+		}
+
+	case models.AlertRuleDecisionErrorRate:
+		var counts struct {
+			Total  int64
+			Errors int64
+		}
+		err := query.Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS errors", DecisionError).
+			Where("event_type = ?", DecisionEventType).
+			Scan(&counts).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count decisions: %w", err)
+		}
+
+		minEvents := int64(rule.MinEvents)
+		if minEvents <= 0 {
+			minEvents = defaultAlertMinEvents
+		}
+		if counts.Total >= minEvents {
+			if rate := float64(counts.Errors) * 100 / float64(counts.Total); rate > rule.Threshold {
+				breaches = append(breaches, alertBreach{Value: rate})
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown alert rule type %s", rule.Type)
+	}
+	return breaches, nil
+}
+
+// alertMessage describes a breach of a rule
+func alertMessage(rule *models.AlertRule, breach alertBreach) string {
+	switch rule.Type {
+	case models.AlertRuleUserDenies:
+		return fmt.Sprintf("%s: user %s was denied %.0f requests in %d minutes", rule.Name, breach.Subject, breach.Value, rule.WindowMinutes)
+	case models.AlertRuleLoginFailures:
+		return fmt.Sprintf("%s: %.0f failed logins from %s in %d minutes", rule.Name, breach.Value, breach.Subject, rule.WindowMinutes)
+	default:
+		return fmt.Sprintf("%s: %.1f%% of OPA decisions failed to evaluate in %d minutes", rule.Name, breach.Value, rule.WindowMinutes)
+	}
+}
+
+// deliver sends an alert to the platform webhooks and the rule's channels.
+// Failed deliveries are logged; the alert stays recorded.
+func (e *AlertEvaluator) deliver(ctx context.Context, rule *models.AlertRule, alert *models.Alert) {
+	data := map[string]interface{}{
+		"alertId":       alert.ID.String(),
+		"ruleId":        rule.ID.String(),
+		"ruleName":      rule.Name,
+		"type":          rule.Type,
+		"subject":       alert.Subject,
+		"value":         alert.Value,
+		"threshold":     alert.Threshold,
+		"windowMinutes": rule.WindowMinutes,
+		"severity":      rule.Severity,
+		"message":       alert.Message,
+	}
+	event := events.NewEvent(events.EventAlertFired, rule.TenantID.String(), data)
+
+	e.events.Publish(ctx, event)
+	if rule.WebhookURL != "" {
+		events.NewWebhookDispatcher(&config.WebhookConfig{
+			URLs:    []string{rule.WebhookURL},
+			Secret:  e.webhooks.Secret,
+			Timeout: e.webhooks.Timeout,
+		}).Publish(ctx, event)
+	}
+
+	if rule.PagerDutyRoutingKey != "" && e.pagerDuty != nil {
+		err := e.pagerDuty.Trigger(ctx, notify.PagerDutyEvent{
+			RoutingKey: rule.PagerDutyRoutingKey,
+			DedupKey:   "heimdall-" + rule.ID.String() + "-" + alert.Subject,
+			Summary:    alert.Message,
+			Source:     "heimdall/tenants/" + rule.TenantID.String(),
+			Severity:   rule.Severity,
+			Details:    data,
+		})
+		if err != nil {
+			log.Printf("Failed to deliver alert %s to PagerDuty: %v", alert.ID, err)
+		}
+	}
+}
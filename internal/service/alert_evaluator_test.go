@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestAlertEvaluator(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.test")
+
+		webhook := make(chan *http.Request, 10)
+		webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			webhook <- r
+		}))
+		defer webhookServer.Close()
+		pages := make(chan map[string]interface{}, 10)
+		pagerDutyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			pages <- body
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer pagerDutyServer.Close()
+
+		rules := NewAlertRuleService(db)
+		webhookURL, routingKey := webhookServer.URL, "R0UT1NGK3Y"
+		denies, err := rules.CreateRule(ctx, acme.ID, &AlertRuleRequest{Name: "Denied requests", Type: models.AlertRuleUserDenies, Threshold: 2, WindowMinutes: 5, WebhookURL: &webhookURL})
+		if err != nil {
+			t.Fatalf("Failed to create rule: %v", err)
+		}
+		if _, err := rules.CreateRule(ctx, acme.ID, &AlertRuleRequest{Name: "Credential stuffing", Type: models.AlertRuleLoginFailures, Threshold: 1, WindowMinutes: 10, PagerDutyRoutingKey: &routingKey, Severity: "critical"}); err != nil {
+			t.Fatalf("Failed to create rule: %v", err)
+		}
+		if _, err := rules.CreateRule(ctx, acme.ID, &AlertRuleRequest{Name: "Policy errors", Type: models.AlertRuleDecisionErrorRate, Threshold: 25, WindowMinutes: 5, MinEvents: 4}); err != nil {
+			t.Fatalf("Failed to create rule: %v", err)
+		}
+		disabled := false
+		if _, err := rules.CreateRule(ctx, acme.ID, &AlertRuleRequest{Name: "Disabled", Type: models.AlertRuleUserDenies, Threshold: 1, WindowMinutes: 5, Enabled: &disabled}); err != nil {
+			t.Fatalf("Failed to create rule: %v", err)
+		}
+
+		now := time.Now()
+		entry := func(entry models.AuditLog, age time.Duration) models.AuditLog {
+			entry.TenantID = acme.ID
+			entry.Action = "test"
+			if entry.Status == "" {
+				entry.Status = "failure"
+			}
+			entry.CreatedAt = now.Add(-age)
+			return entry
+		}
+		entries := []models.AuditLog{
+			// Alice is denied 3 times within the window, Bob twice, and once before it
+			entry(models.AuditLog{EventType: AdminActionEventType, UserID: &alice.ID, StatusCode: 403}, time.Minute),
+			entry(models.AuditLog{EventType: AdminActionEventType, UserID: &alice.ID, StatusCode: 403}, 2*time.Minute),
+			entry(models.AuditLog{EventType: AdminActionEventType, UserID: &alice.ID, StatusCode: 403}, 3*time.Minute),
+			entry(models.AuditLog{EventType: AdminActionEventType, UserID: &bob.ID, StatusCode: 403}, time.Minute),
+			entry(models.AuditLog{EventType: AdminActionEventType, UserID: &bob.ID, StatusCode: 403}, 2*time.Minute),
+			entry(models.AuditLog{EventType: AdminActionEventType, UserID: &bob.ID, StatusCode: 403}, time.Hour),
+			// Two login failures from one address, one from another
+			entry(models.AuditLog{EventType: LoginFailureEventType, UserID: &alice.ID, IPAddress: "203.0.113.7"}, time.Minute),
+			entry(models.AuditLog{EventType: LoginFailureEventType, UserID: &bob.ID, IPAddress: "203.0.113.7"}, time.Minute),
+			entry(models.AuditLog{EventType: LoginFailureEventType, UserID: &bob.ID, IPAddress: "198.51.100.1"}, time.Minute),
+			// One of three decisions failed, short of the minimum
+			entry(models.AuditLog{EventType: DecisionEventType, Status: DecisionError}, time.Minute),
+			entry(models.AuditLog{EventType: DecisionEventType, Status: DecisionAllowed}, time.Minute),
+			entry(models.AuditLog{EventType: DecisionEventType, Status: DecisionDenied}, time.Minute),
+		}
+		if err := db.Create(&entries).Error; err != nil {
+			t.Fatalf("Failed to create audit log: %v", err)
+		}
+
+		evaluator := NewAlertEvaluator(db, nil, &config.WebhookConfig{Secret: "s3cret", Timeout: time.Second}, notify.NewPagerDuty(pagerDutyServer.URL, time.Second))
+		fired, err := evaluator.Evaluate(ctx)
+		if err != nil || fired != 2 {
+			t.Fatalf("Expected alerts for Alice and 203.0.113.7, got %d, %v", fired, err)
+		}
+
+		select {
+		case r := <-webhook:
+			if r.Header.Get(events.EventTypeHeader) != events.EventAlertFired || !strings.HasPrefix(r.Header.Get(events.SignatureHeader), "sha256=") {
+				t.Errorf("Expected a signed alert.fired webhook, got %v", r.Header)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Expected the rule's webhook to be called")
+		}
+		page := <-pages
+		payload, _ := page["payload"].(map[string]interface{})
+		if page["routing_key"] != routingKey || payload["severity"] != "critical" || !strings.Contains(payload["summary"].(string), "2 failed logins from 203.0.113.7") {
+			t.Errorf("Unexpected PagerDuty event %v", page)
+		}
+
+		params, err := pagination.Parse(func(key string, defaultValue ...string) string {
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}, AlertListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		alerts, _, err := rules.ListAlerts(ctx, acme.ID, denies.ID, params)
+		if err != nil || len(alerts) != 1 || alerts[0].Subject != alice.ID.String() || alerts[0].Value != 3 {
+			t.Errorf("Expected one alert for Alice's 3 denies, got %+v, %v", alerts, err)
+		}
+
+		// A rule fires once per subject within its window
+		if fired, err := evaluator.Evaluate(ctx); err != nil || fired != 0 {
+			t.Errorf("Expected no repeated alerts, got %d, %v", fired, err)
+		}
+
+		// The error rate is evaluated once enough decisions were made
+		if err := db.Create([]models.AuditLog{
+			entry(models.AuditLog{EventType: DecisionEventType, Status: DecisionError}, time.Minute),
+		}).Error; err != nil {
+			t.Fatalf("Failed to create audit log: %v", err)
+		}
+		if fired, err := evaluator.Evaluate(ctx); err != nil || fired != 1 {
+			t.Errorf("Expected a 50%% error rate alert, got %d, %v", fired, err)
+		}
+	})
+}
+
+func TestAlertRuleService(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		rules := NewAlertRuleService(db)
+
+		invalidURL := "ftp://hooks.example.com"
+		for _, req := range []AlertRuleRequest{
+			{Name: "Rate", Type: models.AlertRuleDecisionErrorRate, Threshold: 100, WindowMinutes: 5},
+			{Name: "Webhook", Type: models.AlertRuleUserDenies, Threshold: 5, WindowMinutes: 5, WebhookURL: &invalidURL},
+		} {
+			if _, err := rules.CreateRule(ctx, acme.ID, &req); err == nil {
+				t.Errorf("Expected rule %s to be rejected", req.Name)
+			}
+		}
+
+		routingKey := "R0UT1NGK3Y"
+		rule, err := rules.CreateRule(ctx, acme.ID, &AlertRuleRequest{Name: "Denies", Type: models.AlertRuleUserDenies, Threshold: 5, WindowMinutes: 5, PagerDutyRoutingKey: &routingKey})
+		if err != nil || !rule.PagerDutyConfigured || rule.Severity != "warning" || !rule.Enabled {
+			t.Fatalf("Expected an enabled rule paging PagerDuty, got %+v, %v", rule, err)
+		}
+
+		// Channels omitted from an update are kept
+		updated, err := rules.UpdateRule(ctx, acme.ID, rule.ID, &AlertRuleRequest{Name: "Denies", Type: models.AlertRuleUserDenies, Threshold: 10, WindowMinutes: 15})
+		if err != nil || updated.Threshold != 10 || !updated.PagerDutyConfigured {
+			t.Errorf("Expected the threshold to change and the routing key to be kept, got %+v, %v", updated, err)
+		}
+
+		if _, err := rules.GetRule(ctx, globex.ID, rule.ID); err == nil {
+			t.Error("Expected rules of other tenants not to be found")
+		}
+		if err := rules.DeleteRule(ctx, acme.ID, rule.ID); err != nil {
+			t.Fatalf("Failed to delete rule: %v", err)
+		}
+		if _, err := rules.GetRule(ctx, acme.ID, rule.ID); err == nil {
+			t.Error("Expected the deleted rule not to be found")
+		}
+	})
+}
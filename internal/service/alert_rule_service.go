@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// AlertRuleService manages the alert rules tenants define on their audit logs
+// and the alerts the rules fired. Rules are evaluated by AlertEvaluator.
+type AlertRuleService struct {
+	db *gorm.DB
+}
+
+// NewAlertRuleService creates a new alert rule service
+func NewAlertRuleService(db *gorm.DB) *AlertRuleService {
+	return &AlertRuleService{db: db}
+}
+
+// AlertRuleRequest represents the desired state of an alert rule
+type AlertRuleRequest struct {
+	Name                string  `json:"name" validate:"required,min=1,max=255" example:"Repeated denied requests"`
+	Type                string  `json:"type" validate:"required,oneof=user_denies ip_login_failures decision_error_rate" example:"user_denies"`
+	Threshold           float64 `json:"threshold" validate:"required,gt=0" example:"10"`                                                 // Count, or percentage for decision_error_rate, the condition must exceed
+	WindowMinutes       int     `json:"windowMinutes" validate:"required,min=1,max=1440" example:"5"`                                    // Sliding window the condition is evaluated over
+	MinEvents           int     `json:"minEvents,omitempty" validate:"min=0" example:"20"`                                               // Decisions needed in the window before an error rate is evaluated, 20 by default
+	WebhookURL          *string `json:"webhookUrl,omitempty" validate:"omitempty,max=2048" example:"https://hooks.example.com/heimdall"` // Omit to keep the current URL on updates, empty to remove it
+	PagerDutyRoutingKey *string `json:"pagerDutyRoutingKey,omitempty" validate:"omitempty,max=255" example:"R0UT1NGK3Y"`                 // Omit to keep the current key on updates, empty to remove it
+	Severity            string  `json:"severity,omitempty" validate:"omitempty,oneof=critical error warning info" example:"warning"`
+	Enabled             *bool   `json:"enabled,omitempty" example:"true"` // Enabled by default
+}
+
+// AlertRuleResponse represents an alert rule. Channels are reported but not
+// returned, as their URLs and keys grant access to them.
+type AlertRuleResponse struct {
+	ID                  string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID            string  `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name                string  `json:"name" example:"Repeated denied requests"`
+	Type                string  `json:"type" example:"user_denies"` // user_denies, ip_login_failures or decision_error_rate
+	Threshold           float64 `json:"threshold" example:"10"`
+	WindowMinutes       int     `json:"windowMinutes" example:"5"`
+	MinEvents           int     `json:"minEvents,omitempty" example:"20"`
+	WebhookConfigured   bool    `json:"webhookConfigured" example:"true"`
+	PagerDutyConfigured bool    `json:"pagerDutyConfigured" example:"false"`
+	Severity            string  `json:"severity" example:"warning"`
+	Enabled             bool    `json:"enabled" example:"true"`
+	CreatedAt           string  `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt           string  `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+}
+
+// AlertResponse represents an alert fired by a rule
+type AlertResponse struct {
+	ID        string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	RuleID    string  `json:"ruleId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Subject   string  `json:"subject,omitempty" example:"203.0.113.7"` // User ID or IP address the condition held for
+	Value     float64 `json:"value" example:"14"`
+	Threshold float64 `json:"threshold" example:"10"`
+	Message   string  `json:"message" example:"Repeated denied requests: 14 failed logins from 203.0.113.7 in 5 minutes"`
+	CreatedAt string  `json:"createdAt" example:"2024-01-20T08:00:00Z"`
+}
+
+// AlertRuleListOptions describes the sorting supported when listing alert rules
+var AlertRuleListOptions = pagination.Options{
+	SortFields:  map[string]string{"createdAt": "created_at", "name": "name"},
+	DefaultSort: "-createdAt",
+}
+
+// AlertListOptions describes the sorting supported when listing fired alerts
+var AlertListOptions = pagination.Options{
+	SortFields:  map[string]string{"createdAt": "created_at"},
+	DefaultSort: "-createdAt",
+}
+
+// CreateRule creates an alert rule of a tenant
+func (s *AlertRuleService) CreateRule(ctx context.Context, tenantID uuid.UUID, req *AlertRuleRequest) (*AlertRuleResponse, error) {
+	rule := &models.AlertRule{TenantID: tenantID, Enabled: true}
+	if err := applyAlertRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return toAlertRuleResponse(rule), nil
+}
+
+// GetRule retrieves an alert rule of a tenant
+func (s *AlertRuleService) GetRule(ctx context.Context, tenantID uuid.UUID, ruleID string) (*AlertRuleResponse, error) {
+	rule, err := s.findRule(readReplica(s.db).WithContext(ctx), tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return toAlertRuleResponse(rule), nil
+}
+
+// ListRules returns a page of a tenant's alert rules
+func (s *AlertRuleService) ListRules(ctx context.Context, tenantID uuid.UUID, params *pagination.Params) ([]AlertRuleResponse, *pagination.Page, error) {
+	query := readReplica(s.db).Model(&models.AlertRule{}).Where("tenant_id = ?", tenantID)
+	rules, page, err := pagination.Paginate[models.AlertRule](ctx, query, params, AlertRuleListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	responses := make([]AlertRuleResponse, len(rules))
+	for i := range rules {
+		responses[i] = *toAlertRuleResponse(&rules[i])
+	}
+	return responses, page, nil
+}
+
+// UpdateRule replaces the condition and settings of an alert rule. Channels
+// omitted from the request are kept.
+func (s *AlertRuleService) UpdateRule(ctx context.Context, tenantID uuid.UUID, ruleID string, req *AlertRuleRequest) (*AlertRuleResponse, error) {
+	rule, err := s.findRule(s.db.WithContext(ctx), tenantID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAlertRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Save(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return toAlertRuleResponse(rule), nil
+}
+
+// DeleteRule removes an alert rule and the alerts it fired
+func (s *AlertRuleService) DeleteRule(ctx context.Context, tenantID uuid.UUID, ruleID string) error {
+	rule, err := s.findRule(s.db.WithContext(ctx), tenantID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&models.Alert{}).Error; err != nil {
+			return fmt.Errorf("failed to delete alerts: %w", err)
+		}
+		if err := tx.Delete(rule).Error; err != nil {
+			return fmt.Errorf("failed to delete alert rule: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListAlerts returns a page of the alerts an alert rule fired
+func (s *AlertRuleService) ListAlerts(ctx context.Context, tenantID uuid.UUID, ruleID string, params *pagination.Params) ([]AlertResponse, *pagination.Page, error) {
+	rule, err := s.findRule(readReplica(s.db).WithContext(ctx), tenantID, ruleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := readReplica(s.db).Model(&models.Alert{}).Where("rule_id = ?", rule.ID)
+	alerts, page, err := pagination.Paginate[models.Alert](ctx, query, params, AlertListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	responses := make([]AlertResponse, len(alerts))
+	for i, alert := range alerts {
+		responses[i] = AlertResponse{
+			ID:        alert.ID.String(),
+			RuleID:    alert.RuleID.String(),
+			Subject:   alert.Subject,
+			Value:     alert.Value,
+			Threshold: alert.Threshold,
+			Message:   alert.Message,
+			CreatedAt: alert.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	return responses, page, nil
+}
+
+// findRule loads an alert rule of a tenant
+func (s *AlertRuleService) findRule(db *gorm.DB, tenantID uuid.UUID, ruleID string) (*models.AlertRule, error) {
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_ALERT_RULE_ID", "Invalid alert rule ID").WithCause(err)
+	}
+
+	var rule models.AlertRule
+	err = db.Where("id = ? AND tenant_id = ?", id, tenantID).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("ALERT_RULE_NOT_FOUND", "Alert rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// applyAlertRuleRequest validates a request and applies it to a rule
+func applyAlertRuleRequest(rule *models.AlertRule, req *AlertRuleRequest) error {
+	if req.Type == models.AlertRuleDecisionErrorRate && req.Threshold >= 100 {
+		return apperrors.Validation("INVALID_ALERT_THRESHOLD", "Error rate thresholds must be percentages below 100")
+	}
+	if req.WebhookURL != nil && *req.WebhookURL != "" {
+		parsed, err := url.Parse(*req.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return apperrors.Validation("INVALID_WEBHOOK_URL", "Webhook URL must be an absolute http or https URL")
+		}
+	}
+
+	rule.Name = req.Name
+	rule.Type = req.Type
+	rule.Threshold = req.Threshold
+	rule.WindowMinutes = req.WindowMinutes
+	rule.MinEvents = req.MinEvents
+	rule.Severity = req.Severity
+	if rule.Severity == "" {
+		rule.Severity = "warning"
+	}
+	if req.WebhookURL != nil {
+		rule.WebhookURL = *req.WebhookURL
+	}
+	if req.PagerDutyRoutingKey != nil {
+		rule.PagerDutyRoutingKey = *req.PagerDutyRoutingKey
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+func toAlertRuleResponse(rule *models.AlertRule) *AlertRuleResponse {
+	return &AlertRuleResponse{
+		ID:                  rule.ID.String(),
+		TenantID:            rule.TenantID.String(),
+		Name:                rule.Name,
+		Type:                rule.Type,
+		Threshold:           rule.Threshold,
+		WindowMinutes:       rule.WindowMinutes,
+		MinEvents:           rule.MinEvents,
+		WebhookConfigured:   rule.WebhookURL != "",
+		PagerDutyConfigured: rule.PagerDutyRoutingKey != "",
+		Severity:            rule.Severity,
+		Enabled:             rule.Enabled,
+		CreatedAt:           rule.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:           rule.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,659 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultAnalyticsPeriod is the period analyzed when no range is given
+	defaultAnalyticsPeriod = 30 * 24 * time.Hour
+	// maxAnalyticsPeriod caps the period of a single report
+	maxAnalyticsPeriod = 366 * 24 * time.Hour
+	// defaultDeniedRoutes and maxDeniedRoutes bound the denied routes listed
+	defaultDeniedRoutes = 10
+	maxDeniedRoutes     = 100
+)
+
+// Sources of authorization decisions in the audit log
+const (
+	AnalyticsSourceAdminAction = AdminActionEventType
+	AnalyticsSourceDecision    = DecisionEventType
+)
+
+// routeID matches UUID path segments, which are replaced with :id so denied
+// requests are counted per route rather than per resource
+var routeID = regexp.MustCompile(`/[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}(/|$)`)
+
+// AnalyticsService aggregates the audit log, i.e. the administrative actions
+// of a tenant's users and the decisions reported by its OPA instances, into
+// authorization analytics for security reviews, and reports login activity
+// from the stats the AuthActivityAggregator maintains
+type AnalyticsService struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+	return &AnalyticsService{db: db}
+}
+
+// AnalyticsQuery selects the period and size of an analytics report
+type AnalyticsQuery struct {
+	From  time.Time // Defaults to 30 days before To
+	To    time.Time // Defaults to now
+	Limit int       // Number of denied routes listed, defaults to 10
+}
+
+// AuthzAnalytics is a report of a tenant's authorization activity over a period
+type AuthzAnalytics struct {
+	From              string             `json:"from" example:"2024-01-01T00:00:00Z"`
+	To                string             `json:"to" example:"2024-01-31T00:00:00Z"`
+	Summary           AuthzSummary       `json:"summary"`
+	TopDeniedRoutes   []DeniedRoute      `json:"topDeniedRoutes"`
+	RoleDenyRates     []RoleDenyRate     `json:"roleDenyRates"`
+	UnusedPermissions []UnusedPermission `json:"unusedPermissions"` // Granted to the tenant's roles but never used in the period
+	DormantRoles      []DormantRole      `json:"dormantRoles"`      // Roles none of whose members were active in the period
+	UnmatchedPolicies []UnmatchedPolicy  `json:"unmatchedPolicies"` // Active policies no reported decision queried in the period
+}
+
+// AuthzSummary counts the authorization decisions of a tenant
+type AuthzSummary struct {
+	Decisions int64   `json:"decisions" example:"12840"`
+	Denied    int64   `json:"denied" example:"312"`
+	DenyRate  float64 `json:"denyRate" example:"0.0243"`
+}
+
+// DeniedRoute counts the denials of an audited route or OPA decision path
+type DeniedRoute struct {
+	Source   string `json:"source" example:"admin_action"`            // admin_action or opa_decision
+	Route    string `json:"route" example:"POST /v1/users/:id/roles"` // Method and route, or the decision's policy path
+	Resource string `json:"resource,omitempty" example:"users"`
+	Action   string `json:"action,omitempty" example:"roles.assign"`
+	Denied   int64  `json:"denied" example:"42"`
+}
+
+// RoleDenyRate counts the decisions made for the members of a role
+type RoleDenyRate struct {
+	Role      string  `json:"role" example:"analyst"`
+	Decisions int64   `json:"decisions" example:"5120"`
+	Denied    int64   `json:"denied" example:"96"`
+	DenyRate  float64 `json:"denyRate" example:"0.0188"`
+}
+
+// UnusedPermission is a permission granted to roles but never used
+type UnusedPermission struct {
+	Name     string   `json:"name" example:"reports.export"`
+	Resource string   `json:"resource" example:"reports"`
+	Action   string   `json:"action" example:"export"`
+	Roles    []string `json:"roles"` // Roles granting the permission
+}
+
+// DormantRole is a role whose members were not active
+type DormantRole struct {
+	ID      string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name    string `json:"name" example:"legacy_auditor"`
+	Members int64  `json:"members" example:"3"`
+}
+
+// UnmatchedPolicy is an active policy no reported decision queried
+type UnmatchedPolicy struct {
+	ID      string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name    string `json:"name" example:"Report access"`
+	Path    string `json:"path" example:"authz/reports"`
+	Package string `json:"package,omitempty" example:"authz"`
+}
+
+// AuthzAnalytics reports a tenant's authorization activity over a period.
+// Decisions are the administrative actions of its users, denied when they
+// were forbidden, and the decisions reported by its OPA instances.
+func (s *AnalyticsService) AuthzAnalytics(ctx context.Context, tenantID uuid.UUID, query AnalyticsQuery) (*AuthzAnalytics, error) {
+	if err := query.resolve(); err != nil {
+		return nil, err
+	}
+	db := readReplica(s.db).WithContext(ctx)
+	inPeriod := func(eventType string) *gorm.DB {
+		return db.Model(&models.AuditLog{}).
+			Where("audit_logs.tenant_id = ? AND audit_logs.event_type = ?", tenantID, eventType).
+			Where("audit_logs.created_at >= ? AND audit_logs.created_at < ?", query.From, query.To)
+	}
+
+	report := &AuthzAnalytics{
+		From: query.From.Format(time.RFC3339),
+		To:   query.To.Format(time.RFC3339),
+	}
+	var err error
+	if report.Summary, err = authzSummary(inPeriod); err != nil {
+		return nil, err
+	}
+	if report.TopDeniedRoutes, err = topDeniedRoutes(inPeriod, query.Limit); err != nil {
+		return nil, err
+	}
+	decisionRoles := make(map[string]bool)
+	if report.RoleDenyRates, err = roleDenyRates(inPeriod, decisionRoles); err != nil {
+		return nil, err
+	}
+	if report.UnusedPermissions, err = unusedPermissions(db, inPeriod, tenantID); err != nil {
+		return nil, err
+	}
+	if report.DormantRoles, err = dormantRoles(db, tenantID, query, decisionRoles); err != nil {
+		return nil, err
+	}
+	if report.UnmatchedPolicies, err = unmatchedPolicies(db, inPeriod, tenantID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// resolve applies the defaults of a query and validates its period
+func (q *AnalyticsQuery) resolve() error {
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultAnalyticsPeriod)
+	}
+	if !q.From.Before(q.To) {
+		return apperrors.Validation("INVALID_TIME_RANGE", "from must be before to")
+	}
+	if q.To.Sub(q.From) > maxAnalyticsPeriod {
+		return apperrors.Validation("INVALID_TIME_RANGE", "The period may span at most 366 days")
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultDeniedRoutes
+	}
+	q.Limit = min(q.Limit, maxDeniedRoutes)
+	return nil
+}
+
+// denialCount counts the entries of an event type and how many were denied
+type denialCount struct {
+	Total  int64
+	Denied int64
+}
+
+// deniedAdminAction is the condition of forbidden administrative actions
+const deniedAdminAction = "CASE WHEN audit_logs.status_code = 403 THEN 1 ELSE 0 END"
+
+// authzSummary counts all decisions of the period
+func authzSummary(inPeriod func(string) *gorm.DB) (AuthzSummary, error) {
+	var summary AuthzSummary
+	for eventType, denied := range map[string]string{
+		AnalyticsSourceAdminAction: deniedAdminAction,
+		AnalyticsSourceDecision:    "CASE WHEN audit_logs.status = '" + DecisionDenied + "' THEN 1 ELSE 0 END",
+	} {
+		var count denialCount
+		if err := inPeriod(eventType).
+			Select("COUNT(*) AS total, COALESCE(SUM(" + denied + "), 0) AS denied").
+			Scan(&count).Error; err != nil {
+			return summary, fmt.Errorf("failed to count decisions: %w", err)
+		}
+		summary.Decisions += count.Total
+		summary.Denied += count.Denied
+	}
+	summary.DenyRate = ratio(summary.Denied, summary.Decisions)
+	return summary, nil
+}
+
+// topDeniedRoutes returns the routes and decision paths denied most often
+func topDeniedRoutes(inPeriod func(string) *gorm.DB, limit int) ([]DeniedRoute, error) {
+	var actions []struct {
+		Method   string
+		Path     string
+		Resource string
+		Action   string
+		Denied   int64
+	}
+	if err := inPeriod(AnalyticsSourceAdminAction).
+		Select("method, path, resource, action, COUNT(*) AS denied").
+		Where("status_code = ?", 403).
+		Group("method, path, resource, action").
+		Scan(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count denied actions: %w", err)
+	}
+
+	var decisions []struct {
+		Path     string
+		Resource string
+		Action   string
+		Denied   int64
+	}
+	if err := inPeriod(AnalyticsSourceDecision).
+		Select("path, resource, action, COUNT(*) AS denied").
+		Where("status = ?", DecisionDenied).
+		Group("path, resource, action").
+		Scan(&decisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count denied decisions: %w", err)
+	}
+
+	// Paths differing only in IDs and query strings are the same route
+	byRoute := make(map[DeniedRoute]int64)
+	for _, action := range actions {
+		path, _, _ := strings.Cut(action.Path, "?")
+		route := DeniedRoute{
+			Source:   AnalyticsSourceAdminAction,
+			Route:    action.Method + " " + routeID.ReplaceAllString(path, "/:id$1"),
+			Resource: action.Resource,
+			Action:   action.Action,
+		}
+		byRoute[route] += action.Denied
+	}
+	for _, decision := range decisions {
+		route := DeniedRoute{Source: AnalyticsSourceDecision, Route: decision.Path, Resource: decision.Resource, Action: decision.Action}
+		byRoute[route] += decision.Denied
+	}
+
+	routes := make([]DeniedRoute, 0, len(byRoute))
+	for route, denied := range byRoute {
+		route.Denied = denied
+		routes = append(routes, route)
+	}
+	slices.SortFunc(routes, func(a, b DeniedRoute) int {
+		if a.Denied != b.Denied {
+			return int(b.Denied - a.Denied)
+		}
+		return strings.Compare(a.Source+" "+a.Route+" "+a.Action, b.Source+" "+b.Route+" "+b.Action)
+	})
+	if len(routes) > limit {
+		routes = routes[:limit]
+	}
+	return routes, nil
+}
+
+// roleDenyRates counts the decisions of each role: administrative actions by
+// the role's current members, and reported decisions whose input lists the
+// role. The roles seen in reported decisions are collected into decisionRoles.
+func roleDenyRates(inPeriod func(string) *gorm.DB, decisionRoles map[string]bool) ([]RoleDenyRate, error) {
+	counts := make(map[string]*denialCount)
+	count := func(role string) *denialCount {
+		if counts[role] == nil {
+			counts[role] = &denialCount{}
+		}
+		return counts[role]
+	}
+
+	var actions []struct {
+		Role   string
+		Total  int64
+		Denied int64
+	}
+	if err := inPeriod(AnalyticsSourceAdminAction).
+		Select("roles.name AS role, COUNT(*) AS total, COALESCE(SUM("+deniedAdminAction+"), 0) AS denied").
+		Joins("JOIN user_roles ON user_roles.user_id = audit_logs.user_id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id AND roles.deleted_at IS NULL").
+		Where(activeRoleAssignment, time.Now()).
+		Group("roles.name").
+		Scan(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to count actions by role: %w", err)
+	}
+	for _, action := range actions {
+		c := count(action.Role)
+		c.Total += action.Total
+		c.Denied += action.Denied
+	}
+
+	// Roles are a list in the reported input, so they are counted as read
+	decisions := inPeriod(AnalyticsSourceDecision)
+	rows, err := decisions.Select("audit_logs.status, " + jsonText(decisions, "audit_logs.metadata", "input.user.roles")).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decisions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var encoded sql.NullString
+		if err := rows.Scan(&status, &encoded); err != nil {
+			return nil, fmt.Errorf("failed to read decision: %w", err)
+		}
+		var roles []string
+		if !encoded.Valid || json.Unmarshal([]byte(encoded.String), &roles) != nil {
+			continue
+		}
+		for _, role := range roles {
+			decisionRoles[role] = true
+			c := count(role)
+			c.Total++
+			if status == DecisionDenied {
+				c.Denied++
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read decisions: %w", err)
+	}
+
+	rates := make([]RoleDenyRate, 0, len(counts))
+	for _, role := range sortedKeys(counts) {
+		c := counts[role]
+		rates = append(rates, RoleDenyRate{Role: role, Decisions: c.Total, Denied: c.Denied, DenyRate: ratio(c.Denied, c.Total)})
+	}
+	slices.SortStableFunc(rates, func(a, b RoleDenyRate) int {
+		switch {
+		case a.DenyRate > b.DenyRate:
+			return -1
+		case a.DenyRate < b.DenyRate:
+			return 1
+		}
+		return 0
+	})
+	return rates, nil
+}
+
+// unusedPermissions returns the permissions granted to the tenant's roles
+// that neither an allowed decision nor a successful administrative action used
+func unusedPermissions(db *gorm.DB, inPeriod func(string) *gorm.DB, tenantID uuid.UUID) ([]UnusedPermission, error) {
+	var grants []struct {
+		Name     string
+		Resource string
+		Action   string
+		Role     string
+	}
+	if err := db.Model(&models.RolePermission{}).
+		Select("permissions.name, permissions.resource, permissions.action, roles.name AS role").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Where("roles.tenant_id = ?", tenantID).
+		Scan(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	var allowed []struct {
+		Resource string
+		Action   string
+	}
+	if err := inPeriod(AnalyticsSourceDecision).
+		Distinct("resource", "action").
+		Where("status = ?", DecisionAllowed).
+		Scan(&allowed).Error; err != nil {
+		return nil, fmt.Errorf("failed to get allowed decisions: %w", err)
+	}
+	var performed []string
+	if err := inPeriod(AnalyticsSourceAdminAction).
+		Where("status_code < ?", 400).
+		Distinct().
+		Pluck("action", &performed).Error; err != nil {
+		return nil, fmt.Errorf("failed to get admin actions: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, decision := range allowed {
+		used[decision.Resource+"."+decision.Action] = true
+	}
+	for _, action := range performed {
+		used[action] = true
+	}
+
+	unused := make(map[string]*UnusedPermission)
+	for _, grant := range grants {
+		if used[grant.Name] || used[grant.Resource+"."+grant.Action] {
+			continue
+		}
+		if unused[grant.Name] == nil {
+			unused[grant.Name] = &UnusedPermission{Name: grant.Name, Resource: grant.Resource, Action: grant.Action}
+		}
+		unused[grant.Name].Roles = append(unused[grant.Name].Roles, grant.Role)
+	}
+
+	permissions := make([]UnusedPermission, 0, len(unused))
+	for _, name := range sortedKeys(unused) {
+		permission := unused[name]
+		slices.Sort(permission.Roles)
+		permissions = append(permissions, *permission)
+	}
+	return permissions, nil
+}
+
+// dormantRoles returns the tenant's roles none of whose current members
+// signed in or acted in the period, and that no reported decision listed
+func dormantRoles(db *gorm.DB, tenantID uuid.UUID, query AnalyticsQuery, decisionRoles map[string]bool) ([]DormantRole, error) {
+	var roles []models.Role
+	if err := db.Where("tenant_id = ?", tenantID).Order("name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	if len(roles) == 0 {
+		return []DormantRole{}, nil
+	}
+
+	now := time.Now()
+	var members []struct {
+		RoleID  uuid.UUID
+		Members int64
+	}
+	roleIDs := make([]uuid.UUID, len(roles))
+	for i, role := range roles {
+		roleIDs[i] = role.ID
+	}
+	if err := db.Model(&models.UserRole{}).
+		Select("role_id, COUNT(*) AS members").
+		Where("role_id IN ?", roleIDs).
+		Where(activeRoleAssignment, now).
+		Group("role_id").
+		Scan(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to count role members: %w", err)
+	}
+	memberCounts := make(map[uuid.UUID]int64, len(members))
+	for _, m := range members {
+		memberCounts[m.RoleID] = m.Members
+	}
+
+	auditUsers := db.Model(&models.AuditLog{}).Select("user_id").
+		Where("tenant_id = ? AND user_id IS NOT NULL AND created_at >= ? AND created_at < ?", tenantID, query.From, query.To)
+	loginUsers := db.Model(&models.LoginEvent{}).Select("user_id").
+		Where("tenant_id = ? AND created_at >= ? AND created_at < ?", tenantID, query.From, query.To)
+	var activeRoleIDs []uuid.UUID
+	if err := db.Model(&models.UserRole{}).
+		Where("role_id IN ?", roleIDs).
+		Where(activeRoleAssignment, now).
+		Where("user_id IN (?) OR user_id IN (?)", auditUsers, loginUsers).
+		Distinct().
+		Pluck("role_id", &activeRoleIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active roles: %w", err)
+	}
+
+	dormant := make([]DormantRole, 0)
+	for _, role := range roles {
+		if slices.Contains(activeRoleIDs, role.ID) || decisionRoles[role.Name] {
+			continue
+		}
+		dormant = append(dormant, DormantRole{ID: role.ID.String(), Name: role.Name, Members: memberCounts[role.ID]})
+	}
+	return dormant, nil
+}
+
+// unmatchedPolicies returns the tenant's active policies whose package no
+// reported decision queried, whether by its namespaced or original path
+func unmatchedPolicies(db *gorm.DB, inPeriod func(string) *gorm.DB, tenantID uuid.UUID) ([]UnmatchedPolicy, error) {
+	var tenant models.Tenant
+	if err := db.Unscoped().Select("id", "slug").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	var policies []models.Policy
+	if err := db.Where("tenant_id = ? AND status = ?", tenantID, models.PolicyStatusActive).Order("path").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to get policies: %w", err)
+	}
+	var paths []string
+	if err := inPeriod(AnalyticsSourceDecision).Distinct().Pluck("path", &paths).Error; err != nil {
+		return nil, fmt.Errorf("failed to get decision paths: %w", err)
+	}
+
+	queried := func(root string) bool {
+		for _, path := range paths {
+			if path == root || strings.HasPrefix(path, root+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	unmatched := make([]UnmatchedPolicy, 0)
+	for i := range policies {
+		policy := &policies[i]
+		roots := []string{tenantPolicyRoot + "/" + tenant.Slug + "/" + policy.Path}
+		pkg := ""
+		if compiled, err := regoPolicy(policy); err == nil && compiled.Type != models.PolicyTypeWasm {
+			if pkg, err = modulePackage(compiled); err == nil {
+				roots = []string{tenantPolicyPath(tenant.Slug, pkg), strings.ReplaceAll(pkg, ".", "/")}
+			}
+		}
+		if slices.ContainsFunc(roots, queried) {
+			continue
+		}
+		unmatched = append(unmatched, UnmatchedPolicy{ID: policy.ID.String(), Name: policy.Name, Path: policy.Path, Package: pkg})
+	}
+	return unmatched, nil
+}
+
+// ratio returns the share of a total, e.g. of denied decisions, rounded to 4 decimals
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+// WriteCSV writes the report as one CSV table, with a row per item of each of
+// its sections
+func (a *AuthzAnalytics) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	count := func(n int64) string { return strconv.FormatInt(n, 10) }
+	rate := func(r float64) string { return strconv.FormatFloat(r, 'f', 4, 64) }
+
+	rows := [][]string{
+		{"report", "name", "resource", "action", "count", "total", "rate", "detail"},
+		{"summary", "", "", "", count(a.Summary.Denied), count(a.Summary.Decisions), rate(a.Summary.DenyRate), a.From + "/" + a.To},
+	}
+	for _, route := range a.TopDeniedRoutes {
+		rows = append(rows, []string{"denied_route", route.Route, route.Resource, route.Action, count(route.Denied), "", "", route.Source})
+	}
+	for _, role := range a.RoleDenyRates {
+		rows = append(rows, []string{"role_deny_rate", role.Role, "", "", count(role.Denied), count(role.Decisions), rate(role.DenyRate), ""})
+	}
+	for _, permission := range a.UnusedPermissions {
+		rows = append(rows, []string{"unused_permission", permission.Name, permission.Resource, permission.Action, "", "", "", strings.Join(permission.Roles, ";")})
+	}
+	for _, role := range a.DormantRoles {
+		rows = append(rows, []string{"dormant_role", role.Name, "", "", count(role.Members), "", "", role.ID})
+	}
+	for _, policy := range a.UnmatchedPolicies {
+		rows = append(rows, []string{"unmatched_policy", policy.Name, "", "", "", "", "", policy.Path})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write analytics: %w", err)
+	}
+	return nil
+}
+
+// AuthAnalytics is a report of a tenant's logins and registrations over a
+// period, read from the daily auth activity stats
+type AuthAnalytics struct {
+	From         string              `json:"from" example:"2024-01-01T00:00:00Z"`
+	To           string              `json:"to" example:"2024-01-31T00:00:00Z"`
+	AggregatedAt string              `json:"aggregatedAt,omitempty" example:"2024-01-30T23:45:00Z"` // When the stats were last aggregated, empty before the first aggregation
+	Summary      AuthActivitySummary `json:"summary"`
+	Daily        []AuthActivityDay   `json:"daily"` // One entry per day (UTC) of the period
+}
+
+// AuthActivitySummary sums up a tenant's auth activity over a period
+type AuthActivitySummary struct {
+	Logins            int64   `json:"logins" example:"5120"`
+	FailedLogins      int64   `json:"failedLogins" example:"230"`
+	LoginSuccessRate  float64 `json:"loginSuccessRate" example:"0.957"`
+	Registrations     int64   `json:"registrations" example:"48"`
+	DailyActiveUsers  float64 `json:"dailyActiveUsers" example:"162.5"` // Average over the days of the period
+	WeeklyActiveUsers int64   `json:"weeklyActiveUsers" example:"410"`  // In the 7 days ending with the last day of the period
+	MFAAdoption       float64 `json:"mfaAdoption" example:"0.3821"`     // Share of daily active users who logged in with MFA
+}
+
+// AuthActivityDay is a tenant's auth activity on a day
+type AuthActivityDay struct {
+	Day               string  `json:"day" example:"2024-01-20"`
+	ActiveUsers       int64   `json:"activeUsers" example:"170"`
+	WeeklyActiveUsers int64   `json:"weeklyActiveUsers" example:"402"`
+	Logins            int64   `json:"logins" example:"190"`
+	FailedLogins      int64   `json:"failedLogins" example:"6"`
+	LoginSuccessRate  float64 `json:"loginSuccessRate" example:"0.9694"`
+	Registrations     int64   `json:"registrations" example:"2"`
+	MFAUsers          int64   `json:"mfaUsers" example:"64"`
+	MFAAdoption       float64 `json:"mfaAdoption" example:"0.3765"`
+}
+
+// AuthAnalytics reports a tenant's logins and registrations over a period.
+// The report covers whole days (UTC) and is read from the stats aggregated in
+// the background, so it lags behind the latest logins by up to one
+// aggregation interval.
+func (s *AnalyticsService) AuthAnalytics(ctx context.Context, tenantID uuid.UUID, query AnalyticsQuery) (*AuthAnalytics, error) {
+	if err := query.resolve(); err != nil {
+		return nil, err
+	}
+	from := startOfDay(query.From)
+	db := readReplica(s.db).WithContext(ctx)
+
+	var stats []models.AuthActivityStat
+	if err := db.Where("tenant_id = ? AND day >= ? AND day < ?", tenantID, from, query.To).
+		Order("day ASC").
+		Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to get auth activity stats: %w", err)
+	}
+	byDay := make(map[string]models.AuthActivityStat, len(stats))
+	for _, stat := range stats {
+		byDay[stat.Day.UTC().Format(time.DateOnly)] = stat
+	}
+
+	var lastAggregated models.AuthActivityStat
+	if err := db.Order("updated_at DESC").Limit(1).Find(&lastAggregated).Error; err != nil {
+		return nil, fmt.Errorf("failed to get last aggregation: %w", err)
+	}
+
+	report := &AuthAnalytics{
+		From:  from.Format(time.RFC3339),
+		To:    query.To.Format(time.RFC3339),
+		Daily: []AuthActivityDay{},
+	}
+	if lastAggregated.ID != uuid.Nil {
+		report.AggregatedAt = lastAggregated.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	var activeUsers, mfaUsers int64
+	for day := from; day.Before(query.To); day = day.AddDate(0, 0, 1) {
+		stat := byDay[day.Format(time.DateOnly)]
+		report.Daily = append(report.Daily, AuthActivityDay{
+			Day:               day.Format(time.DateOnly),
+			ActiveUsers:       stat.ActiveUsers,
+			WeeklyActiveUsers: stat.WeeklyActiveUsers,
+			Logins:            stat.Logins,
+			FailedLogins:      stat.FailedLogins,
+			LoginSuccessRate:  ratio(stat.Logins, stat.Logins+stat.FailedLogins),
+			Registrations:     stat.Registrations,
+			MFAUsers:          stat.MFAUsers,
+			MFAAdoption:       ratio(stat.MFAUsers, stat.ActiveUsers),
+		})
+		report.Summary.Logins += stat.Logins
+		report.Summary.FailedLogins += stat.FailedLogins
+		report.Summary.Registrations += stat.Registrations
+		report.Summary.WeeklyActiveUsers = stat.WeeklyActiveUsers
+		activeUsers += stat.ActiveUsers
+		mfaUsers += stat.MFAUsers
+	}
+
+	summary := &report.Summary
+	summary.LoginSuccessRate = ratio(summary.Logins, summary.Logins+summary.FailedLogins)
+	summary.DailyActiveUsers = math.Round(float64(activeUsers)/float64(len(report.Daily))*100) / 100
+	summary.MFAAdoption = ratio(mfaUsers, activeUsers)
+	return report, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestAuthzAnalytics(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.test")
+		analyst := testutil.CreateTestRole(t, db, acme, "analyst")
+		auditor := testutil.CreateTestRole(t, db, acme, "auditor")
+		testutil.CreateTestRole(t, db, acme, "legacy")
+		testutil.CreateTestRole(t, db, acme, "viewer")
+		testutil.AssignRoleToUser(t, db, alice, analyst)
+		testutil.AssignRoleToUser(t, db, bob, auditor)
+		for _, permission := range []*models.Permission{
+			testutil.CreateTestPermission(t, db, "reports.read", "reports", "read"),
+			testutil.CreateTestPermission(t, db, "reports.export", "reports", "export"),
+			testutil.CreateTestPermission(t, db, "roles.assign", "roles", "assign"),
+		} {
+			testutil.AssignPermissionToRole(t, db, analyst, permission)
+		}
+
+		for _, policy := range []models.Policy{
+			{TenantID: acme.ID, Name: "Reports", Path: "authz/reports", Type: models.PolicyTypeRego, Status: models.PolicyStatusActive, Content: "package authz\n\nallow if input.action == \"read\"\n"},
+			{TenantID: acme.ID, Name: "Invoices", Path: "billing/invoices", Type: models.PolicyTypeRego, Status: models.PolicyStatusActive, Content: "package billing\n\nallow if false\n"},
+		} {
+			if err := db.Create(&policy).Error; err != nil {
+				t.Fatalf("Failed to create policy: %v", err)
+			}
+		}
+
+		now := time.Now()
+		entries := []models.AuditLog{
+			{EventType: AdminActionEventType, UserID: &alice.ID, Action: "roles.assign", Resource: "users", Method: "POST", Path: "/v1/users/" + uuid.NewString() + "/roles", Status: "failure", StatusCode: 403},
+			{EventType: AdminActionEventType, UserID: &alice.ID, Action: "roles.assign", Resource: "users", Method: "POST", Path: "/v1/users/" + uuid.NewString() + "/roles?dryRun=true", Status: "failure", StatusCode: 403},
+			{EventType: AdminActionEventType, UserID: &alice.ID, Action: "roles.assign", Resource: "users", Method: "POST", Path: "/v1/users/" + uuid.NewString() + "/roles", Status: "success", StatusCode: 200},
+			{EventType: DecisionEventType, Action: "read", Resource: "reports", Path: "tenants/acme/authz/allow", Status: DecisionAllowed, Metadata: []byte(`{"input": {"user": {"roles": ["analyst"]}}}`)},
+			{EventType: DecisionEventType, Action: "export", Resource: "reports", Path: "tenants/acme/authz/allow", Status: DecisionDenied, Metadata: []byte(`{"input": {"user": {"roles": ["analyst"]}}}`)},
+			{EventType: DecisionEventType, Action: "delete", Resource: "reports", Path: "tenants/acme/authz/allow", Status: DecisionDenied, Metadata: []byte(`{"input": {"user": {"roles": ["viewer"]}}}`)},
+		}
+		for i := range entries {
+			entries[i].TenantID = acme.ID
+			entries[i].CreatedAt = now.Add(-time.Hour)
+		}
+		// Activity before the period is left out
+		entries = append(entries, models.AuditLog{TenantID: acme.ID, EventType: AdminActionEventType, UserID: &bob.ID, Action: "users.status", Method: "PUT", Path: "/v1/users/x/status", Status: "failure", StatusCode: 403, CreatedAt: now.AddDate(0, -2, 0)})
+		if err := db.Create(&entries).Error; err != nil {
+			t.Fatalf("Failed to create audit log: %v", err)
+		}
+
+		service := NewAnalyticsService(db)
+		report, err := service.AuthzAnalytics(ctx, acme.ID, AnalyticsQuery{})
+		if err != nil {
+			t.Fatalf("AuthzAnalytics failed: %v", err)
+		}
+
+		if report.Summary != (AuthzSummary{Decisions: 6, Denied: 4, DenyRate: 0.6667}) {
+			t.Errorf("Unexpected summary %+v", report.Summary)
+		}
+
+		wantRoutes := []DeniedRoute{
+			{Source: AnalyticsSourceAdminAction, Route: "POST /v1/users/:id/roles", Resource: "users", Action: "roles.assign", Denied: 2},
+			{Source: AnalyticsSourceDecision, Route: "tenants/acme/authz/allow", Resource: "reports", Action: "delete", Denied: 1},
+			{Source: AnalyticsSourceDecision, Route: "tenants/acme/authz/allow", Resource: "reports", Action: "export", Denied: 1},
+		}
+		if len(report.TopDeniedRoutes) != len(wantRoutes) {
+			t.Fatalf("Unexpected denied routes %+v", report.TopDeniedRoutes)
+		}
+		for i, want := range wantRoutes {
+			if report.TopDeniedRoutes[i] != want {
+				t.Errorf("Denied route %d = %+v, want %+v", i, report.TopDeniedRoutes[i], want)
+			}
+		}
+
+		wantRates := []RoleDenyRate{
+			{Role: "viewer", Decisions: 1, Denied: 1, DenyRate: 1},
+			{Role: "analyst", Decisions: 5, Denied: 3, DenyRate: 0.6},
+		}
+		if len(report.RoleDenyRates) != len(wantRates) || report.RoleDenyRates[0] != wantRates[0] || report.RoleDenyRates[1] != wantRates[1] {
+			t.Errorf("Unexpected role deny rates %+v", report.RoleDenyRates)
+		}
+
+		if len(report.UnusedPermissions) != 1 || report.UnusedPermissions[0].Name != "reports.export" || !equalStrings(report.UnusedPermissions[0].Roles, []string{"analyst"}) {
+			t.Errorf("Expected reports.export to be unused, got %+v", report.UnusedPermissions)
+		}
+
+		if len(report.DormantRoles) != 2 || report.DormantRoles[0].Name != "auditor" || report.DormantRoles[0].Members != 1 || report.DormantRoles[1].Name != "legacy" {
+			t.Errorf("Expected auditor and legacy to be dormant, got %+v", report.DormantRoles)
+		}
+
+		if len(report.UnmatchedPolicies) != 1 || report.UnmatchedPolicies[0].Path != "billing/invoices" || report.UnmatchedPolicies[0].Package != "billing" {
+			t.Errorf("Expected the billing policy to be unmatched, got %+v", report.UnmatchedPolicies)
+		}
+
+		var buf bytes.Buffer
+		if err := report.WriteCSV(&buf); err != nil {
+			t.Fatalf("WriteCSV failed: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if lines[0] != "report,name,resource,action,count,total,rate,detail" || len(lines) != 2+3+2+1+2+1 {
+			t.Errorf("Unexpected CSV:\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "unused_permission,reports.export,reports,export,,,,analyst\n") {
+			t.Errorf("Expected the unused permission in the CSV:\n%s", buf.String())
+		}
+
+		// The period includes the earlier denial when widened
+		report, err = service.AuthzAnalytics(ctx, acme.ID, AnalyticsQuery{From: now.AddDate(0, -3, 0), Limit: 1})
+		if err != nil {
+			t.Fatalf("AuthzAnalytics failed: %v", err)
+		}
+		if report.Summary.Denied != 5 || len(report.TopDeniedRoutes) != 1 {
+			t.Errorf("Expected 5 denials and one route, got %+v and %+v", report.Summary, report.TopDeniedRoutes)
+		}
+	})
+}
+
+func TestAuthAnalytics(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		now := time.Now()
+		today := startOfDay(now)
+		yesterday := today.AddDate(0, 0, -1)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.test")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		gary := testutil.CreateTestUser(t, db, globex, "gary@globex.test")
+
+		logins := []models.LoginEvent{
+			{UserID: alice.ID, TenantID: acme.ID, MFA: true, CreatedAt: today.Add(time.Minute)},
+			{UserID: alice.ID, TenantID: acme.ID, CreatedAt: yesterday.Add(time.Hour)},
+			{UserID: bob.ID, TenantID: acme.ID, CreatedAt: today.Add(time.Minute)},
+			{UserID: gary.ID, TenantID: globex.ID, CreatedAt: today.Add(time.Minute)},
+		}
+		if err := db.Create(&logins).Error; err != nil {
+			t.Fatalf("Failed to create login events: %v", err)
+		}
+		failure := models.AuditLog{TenantID: acme.ID, UserID: &bob.ID, EventType: LoginFailureEventType, Action: "login", Status: "failure", CreatedAt: today.Add(time.Minute)}
+		if err := db.Create(&failure).Error; err != nil {
+			t.Fatalf("Failed to create audit log: %v", err)
+		}
+
+		aggregator := NewAuthActivityAggregator(db)
+		days, err := aggregator.Aggregate(ctx, now)
+		if err != nil || days != authActivityBackfillDays {
+			t.Fatalf("Expected %d days aggregated, got %d: %v", authActivityBackfillDays, days, err)
+		}
+
+		service := NewAnalyticsService(db)
+		report, err := service.AuthAnalytics(ctx, acme.ID, AnalyticsQuery{From: yesterday.Add(time.Hour), To: now})
+		if err != nil {
+			t.Fatalf("AuthAnalytics failed: %v", err)
+		}
+		if report.AggregatedAt == "" || len(report.Daily) != 2 {
+			t.Fatalf("Expected two aggregated days, got %+v", report)
+		}
+		wantDays := []AuthActivityDay{
+			{Day: yesterday.Format(time.DateOnly), ActiveUsers: 1, WeeklyActiveUsers: 1, Logins: 1, LoginSuccessRate: 1},
+			{Day: today.Format(time.DateOnly), ActiveUsers: 2, WeeklyActiveUsers: 2, Logins: 2, FailedLogins: 1, LoginSuccessRate: 0.6667, Registrations: 2, MFAUsers: 1, MFAAdoption: 0.5},
+		}
+		for i, want := range wantDays {
+			if report.Daily[i] != want {
+				t.Errorf("Day %d = %+v, want %+v", i, report.Daily[i], want)
+			}
+		}
+		wantSummary := AuthActivitySummary{Logins: 3, FailedLogins: 1, LoginSuccessRate: 0.75, Registrations: 2, DailyActiveUsers: 1.5, WeeklyActiveUsers: 2, MFAAdoption: 0.3333}
+		if report.Summary != wantSummary {
+			t.Errorf("Summary = %+v, want %+v", report.Summary, wantSummary)
+		}
+
+		// Later runs recompute the last aggregated day only
+		if err := db.Create(&models.LoginEvent{UserID: bob.ID, TenantID: acme.ID, MFA: true, CreatedAt: today.Add(2 * time.Minute)}).Error; err != nil {
+			t.Fatalf("Failed to create login event: %v", err)
+		}
+		days, err = aggregator.Aggregate(ctx, now)
+		if err != nil || days != 1 {
+			t.Fatalf("Expected today to be aggregated again, got %d: %v", days, err)
+		}
+		report, err = service.AuthAnalytics(ctx, acme.ID, AnalyticsQuery{From: today, To: now})
+		if err != nil {
+			t.Fatalf("AuthAnalytics failed: %v", err)
+		}
+		if day := report.Daily[0]; day.Logins != 3 || day.ActiveUsers != 2 || day.MFAUsers != 2 {
+			t.Errorf("Expected bob's login to be counted, got %+v", day)
+		}
+
+		// Other tenants' activity is reported separately
+		report, err = service.AuthAnalytics(ctx, globex.ID, AnalyticsQuery{From: today, To: now})
+		if err != nil || report.Summary.Logins != 1 || report.Summary.Registrations != 1 {
+			t.Errorf("Expected globex's login and registration, got %+v: %v", report, err)
+		}
+	})
+}
+
+func TestAuthzAnalyticsPeriod(t *testing.T) {
+	now := time.Now()
+	for name, query := range map[string]AnalyticsQuery{
+		"reversed": {From: now, To: now.Add(-time.Hour)},
+		"too long": {From: now.AddDate(-2, 0, 0), To: now},
+		"empty":    {From: now, To: now},
+	} {
+		if err := query.resolve(); !isAppError(err, "INVALID_TIME_RANGE") {
+			t.Errorf("%s: expected INVALID_TIME_RANGE, got %v", name, err)
+		}
+	}
+
+	query := AnalyticsQuery{Limit: 500}
+	if err := query.resolve(); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if query.To.Sub(query.From) != defaultAnalyticsPeriod || query.Limit != maxDeniedRoutes {
+		t.Errorf("Unexpected defaults %+v", query)
+	}
+}
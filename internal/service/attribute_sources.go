@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"gorm.io/gorm"
+)
+
+// Attribute source kinds of the OPA_ATTRIBUTE_SOURCES configuration
+const (
+	AttributeSourceDatabase = "database"
+	AttributeSourceHTTP     = "http"
+	AttributeSourceUser     = "user"
+)
+
+// NewEnrichment builds the pipeline of attribute sources applied to
+// authorization inputs from the configured sources
+func NewEnrichment(db *gorm.DB, cfg *config.OPAConfig, userAttributes *UserAttributeService, registry *ResourceService) (*opa.Enrichment, error) {
+	enrichment := opa.NewEnrichment()
+	database := NewDatabaseAttributeSource(db)
+	database.typedAttributes = userAttributes
+
+	for _, sourceCfg := range cfg.AttributeSources {
+		var source opa.AttributeSource
+		switch sourceCfg.Source {
+		case AttributeSourceDatabase:
+			if !database.Supports(sourceCfg.ResourceType) {
+				return nil, fmt.Errorf("resource type %s has no database attributes", sourceCfg.ResourceType)
+			}
+			source = database
+		case AttributeSourceHTTP:
+			source = NewHTTPAttributeSource(sourceCfg.URL, sourceCfg.Secret, cfg.AttributeSourceTimeout)
+		case AttributeSourceUser:
+			source = NewUserAttributeSource(userAttributes)
+		case AttributeSourceRegistry:
+			source = registry
+		default:
+			return nil, fmt.Errorf("unknown attribute source: %s", sourceCfg.Source)
+		}
+		enrichment.Register(sourceCfg.ResourceType, source)
+	}
+
+	return enrichment, nil
+}
+
+// DatabaseAttributeSource loads the owner, tenant and attributes of Heimdall's own
+// resources from the database
+type DatabaseAttributeSource struct {
+	db              *gorm.DB
+	loaders         map[string]func(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error)
+	typedAttributes *UserAttributeService // Optional, typed attributes of users
+}
+
+// NewDatabaseAttributeSource creates an attribute source for users, roles,
+// policies, bundles and tenants
+func NewDatabaseAttributeSource(db *gorm.DB) *DatabaseAttributeSource {
+	s := &DatabaseAttributeSource{db: db}
+	s.loaders = map[string]func(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error){
+		"users":    s.userAttributes,
+		"roles":    s.roleAttributes,
+		"policies": s.policyAttributes,
+		"bundles":  s.bundleAttributes,
+		"tenants":  s.tenantAttributes,
+	}
+	return s
+}
+
+// Name returns the source name
+func (s *DatabaseAttributeSource) Name() string {
+	return AttributeSourceDatabase
+}
+
+// Supports reports whether the source can load attributes of the resource type
+func (s *DatabaseAttributeSource) Supports(resourceType string) bool {
+	_, ok := s.loaders[resourceType]
+	return ok
+}
+
+// Enrich sets the attributes of the input's resource. Resources that do not
+// exist get no attributes, so policies relying on their owner deny access.
+func (s *DatabaseAttributeSource) Enrich(ctx context.Context, input *opa.AuthorizationInput) error {
+	load, ok := s.loaders[input.Resource.Type]
+	if !ok || input.Resource.ID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(input.Resource.ID)
+	if err != nil {
+		return nil
+	}
+
+	attributes, err := load(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load %s attributes: %w", input.Resource.Type, err)
+	}
+	opa.ApplyResourceAttributes(input, attributes)
+	return nil
+}
+
+// userAttributes loads a user, who owns their own account. Besides their status,
+// the user's typed attributes, e.g. their department, are exposed as
+// attributes, as for the row filters of user lists.
+func (s *DatabaseAttributeSource) userAttributes(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error) {
+	var user models.User
+	if err := readReplica(s.db).WithContext(ctx).Select("id", "tenant_id", "status").First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	attributes := map[string]interface{}{}
+	if s.typedAttributes != nil {
+		typed, err := s.typedAttributes.UserAttributes(ctx, user.TenantID.String(), user.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range typed {
+			attributes[name] = value
+		}
+	}
+	attributes["status"] = user.Status
+	return &opa.ResourceAttributes{
+		OwnerID:    user.ID.String(),
+		TenantID:   user.TenantID.String(),
+		Attributes: attributes,
+	}, nil
+}
+
+// roleAttributes loads a role
+func (s *DatabaseAttributeSource) roleAttributes(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error) {
+	var role models.Role
+	if err := readReplica(s.db).WithContext(ctx).Select("id", "tenant_id", "name", "is_system").First(&role, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &opa.ResourceAttributes{
+		TenantID:   role.TenantID.String(),
+		Attributes: map[string]interface{}{"name": role.Name, "isSystem": role.IsSystem},
+	}, nil
+}
+
+// policyAttributes loads a policy, owned by its author
+func (s *DatabaseAttributeSource) policyAttributes(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error) {
+	var policy models.Policy
+	if err := readReplica(s.db).WithContext(ctx).Select("id", "tenant_id", "path", "status", "is_system", "tags", "created_by").First(&policy, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	if len(policy.Tags) > 0 {
+		if err := json.Unmarshal(policy.Tags, &tags); err != nil {
+			return nil, fmt.Errorf("failed to parse policy tags: %w", err)
+		}
+	}
+
+	attributes := &opa.ResourceAttributes{
+		TenantID: policy.TenantID.String(),
+		Attributes: map[string]interface{}{
+			"path":     policy.Path,
+			"status":   string(policy.Status),
+			"isSystem": policy.IsSystem,
+			"tags":     tags,
+		},
+	}
+	if policy.CreatedBy != uuid.Nil {
+		attributes.OwnerID = policy.CreatedBy.String()
+	}
+	return attributes, nil
+}
+
+// bundleAttributes loads a bundle. Global bundles belong to no tenant.
+func (s *DatabaseAttributeSource) bundleAttributes(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error) {
+	var bundle models.PolicyBundle
+	if err := readReplica(s.db).WithContext(ctx).Select("id", "tenant_id", "status", "is_global").First(&bundle, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	attributes := &opa.ResourceAttributes{
+		Attributes: map[string]interface{}{"status": string(bundle.Status), "isGlobal": bundle.IsGlobal},
+	}
+	if bundle.TenantID != uuid.Nil {
+		attributes.TenantID = bundle.TenantID.String()
+	}
+	return attributes, nil
+}
+
+// tenantAttributes loads a tenant, which is its own tenant
+func (s *DatabaseAttributeSource) tenantAttributes(ctx context.Context, id uuid.UUID) (*opa.ResourceAttributes, error) {
+	var tenant models.Tenant
+	if err := readReplica(s.db).WithContext(ctx).Select("id", "slug", "status").First(&tenant, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &opa.ResourceAttributes{
+		TenantID:   tenant.ID.String(),
+		Attributes: map[string]interface{}{"slug": tenant.Slug, "status": tenant.Status},
+	}, nil
+}
+
+// HTTPAttributeSource fetches resource attributes from an external attribute
+// service. The service receives an AttributeRequest and responds with
+// opa.ResourceAttributes.
+type HTTPAttributeSource struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// AttributeRequest is posted to external attribute services
+type AttributeRequest struct {
+	Action   string                 `json:"action"`
+	Resource AttributeRequestEntity `json:"resource"`
+	User     AttributeRequestEntity `json:"user"`
+}
+
+// AttributeRequestEntity identifies the resource or user of an attribute request
+type AttributeRequestEntity struct {
+	Type     string `json:"type,omitempty"`
+	ID       string `json:"id"`
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// NewHTTPAttributeSource creates an attribute source backed by an HTTP endpoint
+func NewHTTPAttributeSource(url, secret string, timeout time.Duration) *HTTPAttributeSource {
+	return &HTTPAttributeSource{
+		url:    url,
+		secret: secret,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Name returns the source name
+func (s *HTTPAttributeSource) Name() string {
+	return "http:" + s.url
+}
+
+// Enrich posts the resource to the attribute service and applies its attributes
+func (s *HTTPAttributeSource) Enrich(ctx context.Context, input *opa.AuthorizationInput) error {
+	body, err := json.Marshal(AttributeRequest{
+		Action:   input.Action,
+		Resource: AttributeRequestEntity{Type: input.Resource.Type, ID: input.Resource.ID, TenantID: input.Resource.TenantID},
+		User:     AttributeRequestEntity{ID: input.User.ID, TenantID: input.User.TenantID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal attribute request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create attribute request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		req.Header.Set(events.SignatureHeader, "sha256="+events.Sign(s.secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call attribute service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read attribute response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("attribute service returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var attributes opa.ResourceAttributes
+	if err := json.Unmarshal(respBody, &attributes); err != nil {
+		return fmt.Errorf("failed to decode attribute response: %w", err)
+	}
+	opa.ApplyResourceAttributes(input, &attributes)
+	return nil
+}
+
+// UserAttributeSource adds the typed attributes of the user's tenant schema to
+// input.user.metadata, for inputs built without UserAttributesMiddleware
+type UserAttributeSource struct {
+	attributes *UserAttributeService
+}
+
+// NewUserAttributeSource creates an attribute source for users' typed attributes
+func NewUserAttributeSource(attributes *UserAttributeService) *UserAttributeSource {
+	return &UserAttributeSource{attributes: attributes}
+}
+
+// Name returns the source name
+func (s *UserAttributeSource) Name() string {
+	return AttributeSourceUser
+}
+
+// Enrich merges the user's typed attributes into the user metadata
+func (s *UserAttributeSource) Enrich(ctx context.Context, input *opa.AuthorizationInput) error {
+	if input.User.ID == "" || input.User.TenantID == "" {
+		return nil
+	}
+
+	attributes, err := s.attributes.UserAttributes(ctx, input.User.TenantID, input.User.ID)
+	if err != nil {
+		return err
+	}
+	if len(attributes) > 0 {
+		input.User.Metadata = opa.MergeMetadata(input.User.Metadata, attributes)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestDatabaseAttributeSource(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		author := testutil.CreateTestUser(t, db, globex, "author@globex.com")
+		policy := &models.Policy{
+			TenantID:  globex.ID,
+			Name:      "documents",
+			Path:      "globex/documents",
+			Content:   "package globex.documents",
+			Tags:      datatypes.JSON(`["pii","finance"]`),
+			CreatedBy: author.ID,
+		}
+		if err := db.Create(policy).Error; err != nil {
+			t.Fatalf("Failed to create policy: %v", err)
+		}
+
+		enrichment, err := NewEnrichment(db, &config.OPAConfig{AttributeSources: []config.AttributeSourceConfig{
+			{ResourceType: "policies", Source: AttributeSourceDatabase},
+		}}, NewUserAttributeService(db), NewResourceService(db))
+		if err != nil {
+			t.Fatalf("Failed to build enrichment: %v", err)
+		}
+		if !enrichment.Handles("policies") || enrichment.Handles("documents") {
+			t.Fatalf("Expected only policies to be handled")
+		}
+
+		// Request-derived owner and tenant are replaced by the stored ones
+		input := &opa.AuthorizationInput{Resource: opa.ResourceContext{
+			Type:     "policies",
+			ID:       policy.ID.String(),
+			OwnerID:  "forged",
+			TenantID: acme.ID.String(),
+		}}
+		if err := enrichment.Enrich(ctx, input); err != nil {
+			t.Fatalf("Failed to enrich input: %v", err)
+		}
+		if input.Resource.OwnerID != author.ID.String() || input.Resource.TenantID != globex.ID.String() {
+			t.Errorf("Expected owner %s in tenant %s, got %+v", author.ID, globex.ID, input.Resource)
+		}
+		tags, _ := input.Resource.Attributes["tags"].([]string)
+		if len(tags) != 2 || tags[0] != "pii" {
+			t.Errorf("Expected policy tags, got %v", input.Resource.Attributes["tags"])
+		}
+
+		// Unknown resources keep their request-derived attributes
+		missing := &opa.AuthorizationInput{Resource: opa.ResourceContext{Type: "policies", ID: uuid.NewString(), TenantID: acme.ID.String()}}
+		if err := enrichment.Enrich(ctx, missing); err != nil || missing.Resource.TenantID != acme.ID.String() || missing.Resource.OwnerID != "" {
+			t.Errorf("Expected a missing policy to be left alone, got %+v, %v", missing.Resource, err)
+		}
+
+		if _, err := NewEnrichment(db, &config.OPAConfig{AttributeSources: []config.AttributeSourceConfig{
+			{ResourceType: "documents", Source: AttributeSourceDatabase},
+		}}, nil, nil); err == nil {
+			t.Errorf("Expected database source of an unknown resource type to be rejected")
+		}
+	})
+}
+
+func TestHTTPAttributeSource(t *testing.T) {
+	var received AttributeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode attribute request: %v", err)
+		}
+		if received.Resource.ID == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if received.Resource.ID == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(opa.ResourceAttributes{
+			OwnerID:    "user-2",
+			Attributes: map[string]interface{}{"classification": "secret"},
+		})
+	}))
+	defer server.Close()
+
+	enrichment := opa.NewEnrichment()
+	enrichment.Register("documents", NewHTTPAttributeSource(server.URL, "secret", time.Second))
+	ctx := testutil.CreateTestContext(t)
+
+	input := &opa.AuthorizationInput{
+		Action:   "read",
+		User:     opa.UserContext{ID: "user-1", TenantID: "tenant-1"},
+		Resource: opa.ResourceContext{Type: "documents", ID: "doc-1", Attributes: map[string]interface{}{"classification": "public", "pages": 3}},
+	}
+	if err := enrichment.Enrich(ctx, input); err != nil {
+		t.Fatalf("Failed to enrich input: %v", err)
+	}
+	if received.Resource.Type != "documents" || received.User.ID != "user-1" || received.Action != "read" {
+		t.Errorf("Unexpected attribute request: %+v", received)
+	}
+	if input.Resource.OwnerID != "user-2" || input.Resource.Attributes["classification"] != "secret" || input.Resource.Attributes["pages"] != 3 {
+		t.Errorf("Expected sourced attributes merged over request attributes, got %+v", input.Resource)
+	}
+
+	// Other resource types are not sent to the service
+	received = AttributeRequest{}
+	if err := enrichment.Enrich(ctx, &opa.AuthorizationInput{Resource: opa.ResourceContext{Type: "invoices", ID: "inv-1"}}); err != nil || received.Resource.ID != "" {
+		t.Errorf("Expected invoices to skip the documents source, got %+v, %v", received, err)
+	}
+
+	if err := enrichment.Enrich(ctx, &opa.AuthorizationInput{Resource: opa.ResourceContext{Type: "documents", ID: "missing"}}); err != nil {
+		t.Errorf("Expected unknown resources to be left alone, got %v", err)
+	}
+	if err := enrichment.Enrich(ctx, &opa.AuthorizationInput{Resource: opa.ResourceContext{Type: "documents", ID: "broken"}}); err == nil {
+		t.Errorf("Expected attribute service failures to fail enrichment")
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// authActivityBackfillDays is how many days the first aggregation covers
+	authActivityBackfillDays = 90
+	// activeUserWindowDays is the window weekly active users are counted over
+	activeUserWindowDays = 7
+)
+
+// AuthActivityAggregator aggregates logins, failed logins and registrations
+// into daily auth activity stats per tenant, which the auth analytics are read
+// from. Each run recomputes the days since the last aggregated day, so logins
+// recorded after a day was last aggregated are counted too.
+type AuthActivityAggregator struct {
+	db *gorm.DB
+}
+
+// NewAuthActivityAggregator creates a new auth activity aggregation job
+func NewAuthActivityAggregator(db *gorm.DB) *AuthActivityAggregator {
+	return &AuthActivityAggregator{db: db}
+}
+
+// Run aggregates auth activity every interval until ctx is cancelled
+func (a *AuthActivityAggregator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.Aggregate(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to aggregate auth activity: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Aggregate recomputes the stats of the days from the last aggregated day, or
+// from 90 days ago on the first run, through the day of now. It returns the
+// number of days aggregated.
+func (a *AuthActivityAggregator) Aggregate(ctx context.Context, now time.Time) (int, error) {
+	today := startOfDay(now)
+	day := today.AddDate(0, 0, -authActivityBackfillDays+1)
+
+	var latest models.AuthActivityStat
+	err := a.db.WithContext(ctx).Order("day DESC").Limit(1).Find(&latest).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last aggregated day: %w", err)
+	}
+	if latest.ID != uuid.Nil && startOfDay(latest.Day).After(day) {
+		day = startOfDay(latest.Day)
+	}
+
+	aggregated := 0
+	for ; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if err := a.AggregateDay(ctx, day); err != nil {
+			return aggregated, err
+		}
+		aggregated++
+	}
+	return aggregated, nil
+}
+
+// tenantCount is a count grouped by tenant
+type tenantCount struct {
+	TenantID uuid.UUID
+	Count    int64
+}
+
+// AggregateDay replaces the stats of a day with ones computed from the
+// tenants' login events, failed logins and users
+func (a *AuthActivityAggregator) AggregateDay(ctx context.Context, day time.Time) error {
+	day = startOfDay(day)
+	end := day.AddDate(0, 0, 1)
+	db := a.db.WithContext(ctx)
+
+	stats := make(map[uuid.UUID]*models.AuthActivityStat)
+	stat := func(tenantID uuid.UUID) *models.AuthActivityStat {
+		if stats[tenantID] == nil {
+			stats[tenantID] = &models.AuthActivityStat{TenantID: tenantID, Day: day}
+		}
+		return stats[tenantID]
+	}
+
+	var logins []struct {
+		TenantID    uuid.UUID
+		Logins      int64
+		ActiveUsers int64
+		MFAUsers    int64
+	}
+	if err := db.Model(&models.LoginEvent{}).
+		Select("tenant_id, COUNT(*) AS logins, COUNT(DISTINCT user_id) AS active_users, COUNT(DISTINCT CASE WHEN mfa = ? THEN user_id END) AS mfa_users", true).
+		Where("created_at >= ? AND created_at < ?", day, end).
+		Group("tenant_id").
+		Scan(&logins).Error; err != nil {
+		return fmt.Errorf("failed to count logins: %w", err)
+	}
+	for _, count := range logins {
+		s := stat(count.TenantID)
+		s.Logins = count.Logins
+		s.ActiveUsers = count.ActiveUsers
+		s.MFAUsers = count.MFAUsers
+	}
+
+	var weekly []tenantCount
+	if err := db.Model(&models.LoginEvent{}).
+		Select("tenant_id, COUNT(DISTINCT user_id) AS count").
+		Where("created_at >= ? AND created_at < ?", end.AddDate(0, 0, -activeUserWindowDays), end).
+		Group("tenant_id").
+		Scan(&weekly).Error; err != nil {
+		return fmt.Errorf("failed to count weekly active users: %w", err)
+	}
+	for _, count := range weekly {
+		stat(count.TenantID).WeeklyActiveUsers = count.Count
+	}
+
+	var failed []tenantCount
+	if err := db.Model(&models.AuditLog{}).
+		Select("tenant_id, COUNT(*) AS count").
+		Where("event_type = ? AND created_at >= ? AND created_at < ?", LoginFailureEventType, day, end).
+		Group("tenant_id").
+		Scan(&failed).Error; err != nil {
+		return fmt.Errorf("failed to count failed logins: %w", err)
+	}
+	for _, count := range failed {
+		stat(count.TenantID).FailedLogins = count.Count
+	}
+
+	// Users deleted since registering still registered on the day
+	var registrations []tenantCount
+	if err := db.Unscoped().Model(&models.User{}).
+		Select("tenant_id, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", day, end).
+		Group("tenant_id").
+		Scan(&registrations).Error; err != nil {
+		return fmt.Errorf("failed to count registrations: %w", err)
+	}
+	for _, count := range registrations {
+		stat(count.TenantID).Registrations = count.Count
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("day = ?", day).Delete(&models.AuthActivityStat{}).Error; err != nil {
+			return fmt.Errorf("failed to clear auth activity stats: %w", err)
+		}
+		for _, s := range stats {
+			if err := tx.Create(s).Error; err != nil {
+				return fmt.Errorf("failed to store auth activity stats: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// startOfDay returns midnight UTC of the day of t
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
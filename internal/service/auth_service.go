@@ -2,49 +2,135 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/auth"
 	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/events"
 	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/utils"
 	"gorm.io/gorm"
 )
 
+// LoginFailureEventType is the audit log event type of failed logins
+const LoginFailureEventType = "login_failure"
+
 // AuthService handles authentication business logic
 type AuthService struct {
 	db             *gorm.DB
-	fusionAuth     *auth.FusionAuthClient
+	identity       auth.IdentityProvider
 	jwtService     *auth.JWTService
 	redis          *database.RedisClient
+	throttler      *LoginThrottler
+	events         events.Publisher
+	loginHooks     []LoginHook
+	loginHistory   *LoginHistoryService
+	ldap           *LDAPService
+	permissions    *PermissionCache
+	rbacSync       *RBACDataSync
+	passwordBreach *PasswordBreachPolicy
+	passwordPolicy *PasswordPolicyService
+	credentials    *CredentialCache
 	userRepository *UserRepository
+
+	background sync.WaitGroup // Logins being recorded and permissions prefetched after the response
 }
 
 // NewAuthService creates a new auth service
 func NewAuthService(
 	db *gorm.DB,
-	fusionAuth *auth.FusionAuthClient,
+	identity auth.IdentityProvider,
 	jwtService *auth.JWTService,
 	redis *database.RedisClient,
+	throttler *LoginThrottler,
+	publisher events.Publisher,
 ) *AuthService {
+	if publisher == nil {
+		publisher = events.NoopPublisher{}
+	}
 	return &AuthService{
 		db:             db,
-		fusionAuth:     fusionAuth,
+		identity:       identity,
 		jwtService:     jwtService,
 		redis:          redis,
+		throttler:      throttler,
+		events:         publisher,
 		userRepository: NewUserRepository(db),
 	}
 }
 
+// AddLoginHook registers a hook invoked before and after authentication.
+// Hooks implementing RegistrationHook or TokenIssueHook also run around
+// registration and before tokens are issued. Hooks run in registration order.
+func (s *AuthService) AddLoginHook(hook LoginHook) {
+	s.loginHooks = append(s.loginHooks, hook)
+}
+
+// Drain waits for logins still being recorded in the background and permissions
+// still being prefetched, or until
+// ctx is done
+func (s *AuthService) Drain(ctx context.Context) error {
+	return utils.WaitContext(ctx, &s.background)
+}
+
+// SetLoginHistoryService enables login history recording and suspicious login detection
+func (s *AuthService) SetLoginHistoryService(loginHistory *LoginHistoryService) {
+	s.loginHistory = loginHistory
+}
+
+// SetLDAPService enables logging in with LDAP directory credentials. The
+// directory is tried first and the identity provider for users it does not know.
+func (s *AuthService) SetLDAPService(ldap *LDAPService) {
+	s.ldap = ldap
+}
+
+// SetPermissionCache enables prefetching the effective permissions of users
+// logging in, so their first authorization decisions do not look them up
+func (s *AuthService) SetPermissionCache(permissions *PermissionCache) {
+	s.permissions = permissions
+}
+
+// SetPasswordBreachPolicy rejects registrations with breached passwords
+func (s *AuthService) SetPasswordBreachPolicy(passwordBreach *PasswordBreachPolicy) {
+	s.passwordBreach = passwordBreach
+}
+
+// SetPasswordPolicyService evaluates passwords of registering users against the
+// password policy of their tenant
+func (s *AuthService) SetPasswordPolicyService(passwordPolicy *PasswordPolicyService) {
+	s.passwordPolicy = passwordPolicy
+}
+
+// SetCredentialCache signs users in with recently verified credentials while
+// the identity provider is unavailable
+func (s *AuthService) SetCredentialCache(credentials *CredentialCache) {
+	s.credentials = credentials
+}
+
+// SetRBACDataSync pushes the default roles given to registering users to OPA
+func (s *AuthService) SetRBACDataSync(rbacSync *RBACDataSync) {
+	s.rbacSync = rbacSync
+}
+
 // RegisterRequest represents registration data
 type RegisterRequest struct {
 	Email     string `json:"email" validate:"required,email" example:"user@example.com"`
-	Password  string `json:"password" validate:"required,min=8" example:"SecurePassword123!"`
+	Password  string `json:"password" validate:"required" example:"SecurePassword123!"`
 	FirstName string `json:"firstName" validate:"required" example:"John"`
 	LastName  string `json:"lastName" validate:"required" example:"Doe"`
 	TenantID  string `json:"tenantId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+	DeviceID  string `json:"-"`
 }
 
 // LoginRequest represents login credentials
@@ -52,6 +138,25 @@ type LoginRequest struct {
 	Email      string `json:"email" validate:"required,email" example:"user@example.com"`
 	Password   string `json:"password" validate:"required" example:"SecurePassword123!"`
 	RememberMe bool   `json:"rememberMe" example:"false"`
+	IPAddress  string `json:"-"`
+	UserAgent  string `json:"-"`
+	DeviceID   string `json:"-"`
+}
+
+// RefreshTokenRequest represents a token refresh. The refresh token must come
+// from the client it was issued to.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+	IPAddress    string `json:"-"`
+	UserAgent    string `json:"-"`
+	DeviceID     string `json:"-"`
+}
+
+// ClientFingerprint identifies the client refresh tokens are bound to, from
+// its user agent and the optional device ID it sends
+func ClientFingerprint(userAgent, deviceID string) string {
+	hash := sha256.Sum256([]byte(userAgent + "\x00" + deviceID))
+	return hex.EncodeToString(hash[:])
 }
 
 // AuthResponse represents authentication response
@@ -72,19 +177,10 @@ type UserInfo struct {
 	TenantID  string `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
 }
 
-// Register creates a new user account
+// Register creates a new user account. The local user and an outbox entry are
+// committed before the identity provider is called, so a registration interrupted part-way
+// is completed or rolled back by the outbox worker instead of leaving an orphan.
 func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*AuthResponse, error) {
-	// Create user in FusionAuth
-	faUser, err := s.fusionAuth.Register(&auth.RegisterRequest{
-		Email:     req.Email,
-		Password:  req.Password,
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create user in FusionAuth: %w", err)
-	}
-
 	// Parse tenant ID or get default tenant
 	tenantID := req.TenantID
 	var tenantUUID uuid.UUID
@@ -102,28 +198,44 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 		var err error
 		tenantUUID, err = uuid.Parse(tenantID)
 		if err != nil {
-			return nil, fmt.Errorf("invalid tenant ID: %w", err)
+			return nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 		}
 
 		// Verify tenant exists and is active
 		var tenant models.Tenant
 		if err := s.db.Where("id = ? AND status = ?", tenantUUID, "active").First(&tenant).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return nil, fmt.Errorf("tenant not found or inactive")
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found or inactive")
 			}
 			return nil, fmt.Errorf("failed to verify tenant: %w", err)
 		}
 	}
 
-	userUUID, err := uuid.Parse(faUser.ID)
+	owner := PasswordOwner{Email: req.Email, FirstName: req.FirstName, LastName: req.LastName}
+	if err := s.passwordPolicy.Evaluate(ctx, tenantUUID, owner, req.Password); err != nil {
+		return nil, err
+	}
+	if err := s.passwordBreach.Check(ctx, tenantUUID, req.Password); err != nil {
+		return nil, err
+	}
+
+	// Let pre-register hooks reject the registration, e.g. outside a domain allowlist
+	hookCtx := &LoginHookContext{
+		Stage:     LoginHookStagePreRegister,
+		Email:     req.Email,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+		TenantID:  tenantID,
+	}
+	sessionAttributes, err := runLoginHooks(ctx, s.loginHooks, hookCtx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID from FusionAuth: %w", err)
+		return nil, err
 	}
 
 	// Create user record in Heimdall database
 	metadataMap := map[string]interface{}{
-		"firstName": faUser.FirstName,
-		"lastName":  faUser.LastName,
+		"firstName": req.FirstName,
+		"lastName":  req.LastName,
 	}
 	metadataJSON, err := json.Marshal(metadataMap)
 	if err != nil {
@@ -131,20 +243,93 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 	}
 
 	user := &models.User{
-		ID:       userUUID,
+		ID:       uuid.New(),
 		TenantID: tenantUUID,
-		Email:    faUser.Email,
+		Email:    req.Email,
 		Metadata: metadataJSON,
 	}
 
-	if err := s.userRepository.Create(ctx, user); err != nil {
-		// Rollback: delete user from FusionAuth
-		_ = s.fusionAuth.DeleteUser(faUser.ID)
-		return nil, fmt.Errorf("failed to create user record: %w", err)
+	// Users start with the default roles of their tenant
+	var entry *models.OutboxEntry
+	var roles []models.Role
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("failed to create user record: %w", err)
+		}
+		var err error
+		if roles, err = assignDefaultRoles(tx, user); err != nil {
+			return err
+		}
+		entry, err = enqueueOutbox(tx, OutboxOpRegisterUser, user.ID, registerUserPayload{
+			Email:    req.Email,
+			TenantID: tenantID,
+		}, time.Now().Add(registrationTimeout))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create user in the identity provider with the same ID
+	identityUser, err := s.identity.Register(ctx, &auth.RegisterRequest{
+		UserID:    user.ID.String(),
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
+	if err != nil {
+		if auth.IsRejected(err) || auth.IsNotSent(err) {
+			// The provider did not create the user, so the local record is removed now.
+			// Otherwise the outcome is unknown and the worker checks the provider.
+			if rollbackErr := rollbackRegistration(ctx, s.db, entry, err.Error()); rollbackErr != nil {
+				log.Printf("Failed to roll back registration of user %s: %v", user.ID, rollbackErr)
+			}
+		}
+		if errors.Is(err, auth.ErrEmailTaken) {
+			return nil, apperrors.Conflict("USER_EMAIL_EXISTS", "A user with this email already exists")
+		}
+		if errors.Is(err, auth.ErrProviderUnavailable) {
+			return nil, apperrors.Unavailable("IDENTITY_PROVIDER_UNAVAILABLE", "Registration is temporarily unavailable, please try again shortly").WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to create user in identity provider: %w", err)
+	}
+
+	if err := finishOutbox(ctx, s.db, entry.ID, ""); err != nil {
+		// The worker finds the user in the provider and completes the entry
+		log.Printf("Failed to complete registration of user %s: %v", user.ID, err)
+	}
+	s.passwordPolicy.Record(ctx, user.ID.String(), req.Password)
+	roleNames := make([]string, len(roles))
+	for i, role := range roles {
+		roleNames[i] = role.Name
+	}
+	if len(roles) > 0 {
+		s.rbacSync.TenantChanged(ctx, tenantUUID)
+	}
+
+	// Post-register hooks run in the background; the user exists, so they cannot reject
+	hookCtx.UserID = identityUser.ID
+	hookCtx.Roles = roleNames
+	if len(s.loginHooks) > 0 {
+		postCtx := *hookCtx
+		postCtx.Stage = LoginHookStagePostRegister
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			notifyLoginHooks(context.Background(), s.loginHooks, &postCtx)
+		}()
+	}
+
+	// Let pre-token-issue hooks reject the sign-in or enrich the session. The
+	// user stays registered and can sign in once the hooks allow it.
+	sessionAttributes, err = runTokenIssueHooks(ctx, s.loginHooks, hookCtx, sessionAttributes)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate tokens
-	tokens, err := s.jwtService.GenerateTokenPair(faUser.ID, tenantID, faUser.Email, []string{})
+	tokens, err := s.jwtService.GenerateTokenPairWithAttributes(identityUser.ID, tenantID, identityUser.Email, roleNames, sessionAttributes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
@@ -153,7 +338,8 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 	if s.redis != nil {
 		tokenClaims, _ := s.jwtService.ValidateRefreshToken(tokens.RefreshToken)
 		if tokenClaims != nil {
-			_ = s.redis.StoreRefreshToken(ctx, faUser.ID, tokenClaims.ID, time.Duration(tokens.ExpiresIn)*time.Second)
+			fingerprint := ClientFingerprint(req.UserAgent, req.DeviceID)
+			_ = s.redis.StoreRefreshToken(ctx, identityUser.ID, tokenClaims.ID, fingerprint, time.Duration(tokens.ExpiresIn)*time.Second)
 		}
 	}
 
@@ -163,10 +349,10 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 		TokenType:    tokens.TokenType,
 		ExpiresIn:    tokens.ExpiresIn,
 		User: &UserInfo{
-			ID:        faUser.ID,
-			Email:     faUser.Email,
-			FirstName: faUser.FirstName,
-			LastName:  faUser.LastName,
+			ID:        identityUser.ID,
+			Email:     identityUser.Email,
+			FirstName: identityUser.FirstName,
+			LastName:  identityUser.LastName,
 			TenantID:  tenantID,
 		},
 	}, nil
@@ -174,20 +360,108 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 
 // Login authenticates a user and returns tokens
 func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
-	// Authenticate with FusionAuth
-	faUser, err := s.fusionAuth.Login(&auth.LoginRequest{
-		Email:    req.Email,
-		Password: req.Password,
-	})
+	// Reject attempts from locked accounts or throttled IPs before contacting the identity provider
+	if err := s.throttler.Check(ctx, req.Email, req.IPAddress); err != nil {
+		return nil, err
+	}
+
+	// Let pre-auth hooks reject the attempt before credentials are checked
+	hookCtx := &LoginHookContext{
+		Stage:     LoginHookStagePre,
+		Email:     req.Email,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	}
+	sessionAttributes, err := runLoginHooks(ctx, s.loginHooks, hookCtx)
 	if err != nil {
+		return nil, err
+	}
+
+	// Authenticate with the directory or the identity provider
+	identityUser, err := s.authenticate(ctx, req)
+	if errors.Is(err, auth.ErrProviderUnavailable) {
+		cached, cacheErr := s.credentials.Verify(ctx, req.Email, req.Password)
+		if cacheErr != nil {
+			// A mismatch with the cached password counts as a failure so guesses
+			// are throttled, but the provider has the final say once it is back
+			if errors.Is(cacheErr, auth.ErrInvalidCredentials) {
+				s.recordLoginFailure(ctx, req)
+			}
+			return nil, apperrors.Unavailable("IDENTITY_PROVIDER_UNAVAILABLE", "Sign-in is temporarily unavailable, please try again shortly").WithCause(err)
+		}
+		log.Printf("Signing in user %s with cached credentials: %v", cached.ID, err)
+		identityUser, err = cached, nil
+	}
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			s.credentials.Forget(ctx, req.Email)
+		}
+		s.recordLoginFailure(ctx, req)
+		if errors.Is(err, auth.ErrAccountLocked) {
+			return nil, apperrors.Forbidden("ACCOUNT_LOCKED", "Account is locked").WithCause(err)
+		}
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
+	s.throttler.RecordSuccess(ctx, req.Email)
+
+	return s.completeLogin(ctx, identityUser, req, hookCtx, sessionAttributes)
+}
 
+// LoginExternal issues tokens for a user authenticated by an external identity
+// provider, such as a tenant's SAML IdP, running the same hooks as Login
+func (s *AuthService) LoginExternal(ctx context.Context, identityUser *auth.IdentityUser, req *LoginRequest) (*AuthResponse, error) {
+	hookCtx := &LoginHookContext{
+		Stage:     LoginHookStagePre,
+		Email:     identityUser.Email,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	}
+	sessionAttributes, err := runLoginHooks(ctx, s.loginHooks, hookCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, identityUser, req, hookCtx, sessionAttributes)
+}
+
+// completeLogin runs post-auth hooks for an authenticated user, records the
+// login and issues tokens
+func (s *AuthService) completeLogin(ctx context.Context, identityUser *auth.IdentityUser, req *LoginRequest, hookCtx *LoginHookContext, sessionAttributes map[string]interface{}) (*AuthResponse, error) {
 	// Get user from database
-	userUUID, _ := uuid.Parse(faUser.ID)
+	userUUID, _ := uuid.Parse(identityUser.ID)
 	user, err := s.userRepository.GetByID(ctx, userUUID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found in database: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Status == models.UserStatusSuspended {
+		return nil, apperrors.Forbidden("USER_SUSPENDED", "User account is suspended")
+	}
+
+	// Get user roles
+	roles, _ := s.userRepository.GetUserRoles(ctx, userUUID)
+	roleNames := make([]string, len(roles))
+	for i, role := range roles {
+		roleNames[i] = role.Name
+	}
+
+	// Let post-auth hooks reject, require step-up or enrich the session
+	hookCtx.Stage = LoginHookStagePost
+	hookCtx.UserID = identityUser.ID
+	hookCtx.TenantID = user.TenantID.String()
+	hookCtx.Roles = roleNames
+	postAttributes, err := runLoginHooks(ctx, s.loginHooks, hookCtx)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range postAttributes {
+		sessionAttributes[k] = v
+	}
+	// Pre-token-issue hooks see the same context, so they apply to every login path
+	if sessionAttributes, err = runTokenIssueHooks(ctx, s.loginHooks, hookCtx, sessionAttributes); err != nil {
+		return nil, err
 	}
 
 	// Update last login time
@@ -196,15 +470,37 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthRespon
 	user.LoginCount++
 	_ = s.userRepository.Update(ctx, user)
 
-	// Get user roles
-	roles, _ := s.userRepository.GetUserRoles(ctx, userUUID)
-	roleNames := make([]string, len(roles))
-	for i, role := range roles {
-		roleNames[i] = role.Name
+	// Record login context in the background; geo lookups must not delay the response
+	if s.loginHistory != nil {
+		mfaVerified, _ := sessionAttributes["mfaVerified"].(bool)
+		login := &LoginContext{
+			UserID:    user.ID,
+			TenantID:  user.TenantID,
+			Email:     user.Email,
+			IPAddress: req.IPAddress,
+			UserAgent: req.UserAgent,
+			MFA:       mfaVerified,
+		}
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			if _, err := s.loginHistory.RecordLogin(context.Background(), login); err != nil {
+				log.Printf("Failed to record login for user %s: %v", login.UserID, err)
+			}
+		}()
+	}
+	if s.permissions != nil {
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			if _, err := s.permissions.Refresh(context.Background(), identityUser.ID); err != nil {
+				log.Printf("Failed to prefetch permissions of user %s: %v", identityUser.ID, err)
+			}
+		}()
 	}
 
 	// Generate tokens
-	tokens, err := s.jwtService.GenerateTokenPair(faUser.ID, user.TenantID.String(), faUser.Email, roleNames)
+	tokens, err := s.jwtService.GenerateTokenPairWithAttributes(identityUser.ID, user.TenantID.String(), identityUser.Email, roleNames, sessionAttributes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
@@ -217,7 +513,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthRespon
 			if req.RememberMe {
 				expiry = 30 * 24 * time.Hour // 30 days
 			}
-			_ = s.redis.StoreRefreshToken(ctx, faUser.ID, tokenClaims.ID, expiry)
+			_ = s.redis.StoreRefreshToken(ctx, identityUser.ID, tokenClaims.ID, ClientFingerprint(req.UserAgent, req.DeviceID), expiry)
 		}
 	}
 
@@ -237,8 +533,8 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthRespon
 		TokenType:    tokens.TokenType,
 		ExpiresIn:    tokens.ExpiresIn,
 		User: &UserInfo{
-			ID:        faUser.ID,
-			Email:     faUser.Email,
+			ID:        identityUser.ID,
+			Email:     identityUser.Email,
 			FirstName: firstName,
 			LastName:  lastName,
 			TenantID:  user.TenantID.String(),
@@ -246,19 +542,116 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthRespon
 	}, nil
 }
 
-// RefreshToken generates a new access token from a refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*AuthResponse, error) {
+// authenticate checks credentials against the LDAP directory when enabled and
+// falls back to the identity provider for users the directory does not accept
+func (s *AuthService) authenticate(ctx context.Context, req *LoginRequest) (*auth.IdentityUser, error) {
+	if s.ldap != nil {
+		identityUser, err := s.ldap.Login(ctx, req.Email, req.Password)
+		if !errors.Is(err, auth.ErrInvalidCredentials) {
+			return identityUser, err
+		}
+	}
+	identityUser, err := s.identity.Login(ctx, &auth.LoginRequest{
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err == nil {
+		s.credentials.Remember(ctx, req.Email, req.Password, identityUser)
+	}
+	return identityUser, err
+}
+
+// recordLoginFailure updates brute-force counters, records failed logins of
+// known users in the audit log and emits login failure events
+func (s *AuthService) recordLoginFailure(ctx context.Context, req *LoginRequest) {
+	result := s.throttler.RecordFailure(ctx, req.Email, req.IPAddress)
+
+	// Attempts for unknown emails belong to no tenant and are only throttled
+	if user, err := s.userRepository.GetByEmail(ctx, req.Email); err == nil {
+		entry := &models.AuditLog{
+			TenantID:  user.TenantID,
+			UserID:    &user.ID,
+			EventType: LoginFailureEventType,
+			Action:    "login",
+			Resource:  "users",
+			IPAddress: req.IPAddress,
+			UserAgent: req.UserAgent,
+			Status:    "failure",
+		}
+		if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+			log.Printf("Failed to record login failure for user %s: %v", user.ID, err)
+		}
+	}
+
+	s.events.Publish(ctx, events.NewEvent(events.EventLoginFailed, "", map[string]interface{}{
+		"email":           req.Email,
+		"ipAddress":       req.IPAddress,
+		"userAgent":       req.UserAgent,
+		"accountFailures": result.AccountFailures,
+		"ipFailures":      result.IPFailures,
+	}))
+
+	if result.Locked {
+		s.events.Publish(ctx, events.NewEvent(events.EventAccountLocked, "", map[string]interface{}{
+			"email":     req.Email,
+			"ipAddress": req.IPAddress,
+		}))
+	}
+}
+
+// UnlockAccount clears login lockout and failure counters for a user
+func (s *AuthService) UnlockAccount(ctx context.Context, userID string) error {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+
+	user, err := s.userRepository.GetByID(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.throttler.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.NewEvent(events.EventAccountUnlocked, user.TenantID.String(), map[string]interface{}{
+		"userId": user.ID.String(),
+		"email":  user.Email,
+	}))
+
+	return nil
+}
+
+// RefreshToken generates a new access token from a refresh token. A token
+// presented by another client than it was issued to is revoked, as it was
+// likely stolen.
+func (s *AuthService) RefreshToken(ctx context.Context, req *RefreshTokenRequest) (*AuthResponse, error) {
 	// Validate refresh token
-	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	claims, err := s.jwtService.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, apperrors.Unauthorized("INVALID_REFRESH_TOKEN", "Invalid refresh token").WithCause(err)
 	}
 
-	// Check if refresh token is valid in Redis
+	// Check if refresh token is valid in Redis and bound to this client
+	fingerprint := ClientFingerprint(req.UserAgent, req.DeviceID)
 	if s.redis != nil {
-		valid, err := s.redis.ValidateRefreshToken(ctx, claims.UserID, claims.ID)
+		valid, bound, err := s.redis.ValidateRefreshToken(ctx, claims.UserID, claims.ID)
 		if err != nil || !valid {
-			return nil, fmt.Errorf("refresh token not found or expired")
+			return nil, apperrors.Unauthorized("INVALID_REFRESH_TOKEN", "Refresh token not found or expired")
+		}
+		// Tokens issued before binding are bound when rotated
+		if bound != "" && bound != fingerprint {
+			_ = s.redis.RevokeRefreshToken(ctx, claims.UserID, claims.ID)
+			s.events.Publish(ctx, events.NewEvent(events.EventRefreshMismatch, claims.TenantID, map[string]interface{}{
+				"userId":    claims.UserID,
+				"ipAddress": req.IPAddress,
+				"userAgent": req.UserAgent,
+			}))
+			return nil, apperrors.Unauthorized("REFRESH_TOKEN_MISMATCH", "Refresh token was issued to another client")
 		}
 	}
 
@@ -266,7 +659,13 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 	userUUID, _ := uuid.Parse(claims.UserID)
 	user, err := s.userRepository.GetByID(ctx, userUUID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Unauthorized("INVALID_REFRESH_TOKEN", "User no longer exists")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Status == models.UserStatusSuspended {
+		return nil, apperrors.Forbidden("USER_SUSPENDED", "User account is suspended")
 	}
 
 	// Get user roles
@@ -276,8 +675,19 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 		roleNames[i] = role.Name
 	}
 
+	// Let pre-token-issue hooks reject the refresh or update the session
+	sessionAttributes, err := runTokenIssueHooks(ctx, s.loginHooks, &LoginHookContext{
+		Email:    claims.Email,
+		UserID:   claims.UserID,
+		TenantID: claims.TenantID,
+		Roles:    roleNames,
+	}, claims.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate new token pair
-	tokens, err := s.jwtService.GenerateTokenPair(claims.UserID, claims.TenantID, claims.Email, roleNames)
+	tokens, err := s.jwtService.GenerateTokenPairWithAttributes(claims.UserID, claims.TenantID, claims.Email, roleNames, sessionAttributes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
@@ -287,7 +697,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*A
 		_ = s.redis.RevokeRefreshToken(ctx, claims.UserID, claims.ID)
 		newClaims, _ := s.jwtService.ValidateRefreshToken(tokens.RefreshToken)
 		if newClaims != nil {
-			_ = s.redis.StoreRefreshToken(ctx, claims.UserID, newClaims.ID, time.Duration(tokens.ExpiresIn)*time.Second)
+			_ = s.redis.StoreRefreshToken(ctx, claims.UserID, newClaims.ID, fingerprint, time.Duration(tokens.ExpiresIn)*time.Second)
 		}
 	}
 
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/database"
+)
+
+// bodyLoggingKey holds the body logging rules of all instances
+const bodyLoggingKey = "body_logging:rules"
+
+// defaultBodyLoggingDuration is how long a rule applies when none is requested
+const defaultBodyLoggingDuration = 15 * time.Minute
+
+// BodyLoggingService manages the routes whose request and response bodies are
+// logged, for debugging. Rules are shared by all instances through Redis and
+// expire, so bodies are not logged longer than needed.
+type BodyLoggingService struct {
+	cache       *database.RedisClient
+	maxDuration time.Duration
+
+	mu       sync.RWMutex
+	rules    []BodyLoggingRule
+	loadedAt time.Time
+
+	refreshMu       sync.Mutex
+	refreshInterval time.Duration
+}
+
+// NewBodyLoggingService creates a new body logging service. Rules may apply
+// for up to maxDuration, and zero disables body logging.
+func NewBodyLoggingService(cache *database.RedisClient, maxDuration time.Duration) *BodyLoggingService {
+	return &BodyLoggingService{
+		cache:           cache,
+		maxDuration:     maxDuration,
+		refreshInterval: 10 * time.Second,
+	}
+}
+
+// BodyLoggingRule enables body logging of a route until it expires
+type BodyLoggingRule struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Method    string    `json:"method,omitempty" example:"POST"` // Any method when empty
+	Path      string    `json:"path" example:"/v1/users/:userId/roles"`
+	CreatedBy string    `json:"createdBy" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	ExpiresAt time.Time `json:"expiresAt" example:"2024-01-15T10:45:00Z"`
+}
+
+// EnableBodyLoggingRequest names the route whose bodies to log
+type EnableBodyLoggingRequest struct {
+	Method          string `json:"method,omitempty" validate:"omitempty,oneof=GET POST PUT PATCH DELETE" example:"POST"`
+	Path            string `json:"path" validate:"required,startswith=/,max=500" example:"/v1/users/{userId}/roles"` // Route as documented or registered, e.g. /v1/users/:userId/roles
+	DurationMinutes int    `json:"durationMinutes,omitempty" validate:"omitempty,min=1" example:"15"`                // 15 minutes when omitted
+}
+
+// ListRules lists the rules that have not expired
+func (s *BodyLoggingService) ListRules(ctx context.Context) ([]BodyLoggingRule, error) {
+	return s.load(ctx)
+}
+
+// EnableRule logs the bodies of a route until the rule expires. Routes are
+// matched by their registered path, e.g. /v1/users/:userId, so one rule covers
+// every user or resource the route serves.
+func (s *BodyLoggingService) EnableRule(ctx context.Context, userID string, req *EnableBodyLoggingRequest) (*BodyLoggingRule, error) {
+	if s.maxDuration <= 0 {
+		return nil, apperrors.Forbidden("BODY_LOGGING_DISABLED", "Body logging is disabled by BODY_LOG_MAX_MINUTES")
+	}
+	duration := defaultBodyLoggingDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration > s.maxDuration {
+		return nil, apperrors.Validation("INVALID_DURATION", fmt.Sprintf("Body logging may be enabled for up to %d minutes", int(s.maxDuration.Minutes())))
+	}
+
+	rules, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	rule := BodyLoggingRule{
+		ID:        uuid.NewString(),
+		Method:    req.Method,
+		Path:      req.Path,
+		CreatedBy: userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := s.save(ctx, append(rules, rule)); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DisableRule deletes a rule, stopping its body logging
+func (s *BodyLoggingService) DisableRule(ctx context.Context, id string) error {
+	rules, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(rules, func(rule BodyLoggingRule) bool { return rule.ID == id })
+	if i < 0 {
+		return apperrors.NotFound("BODY_LOGGING_RULE_NOT_FOUND", "Body logging rule not found")
+	}
+	return s.save(ctx, slices.Delete(rules, i, i+1))
+}
+
+// LogBodies reports whether the bodies of requests to a route are logged. The
+// rules are reloaded at most every ten seconds, so changes made on other
+// instances apply within seconds.
+func (s *BodyLoggingService) LogBodies(method, route string) bool {
+	if s.maxDuration <= 0 {
+		return false
+	}
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > s.refreshInterval
+	s.mu.RUnlock()
+	if stale {
+		// If the rules cannot be reloaded, the previous ones keep applying
+		_ = s.refresh(context.Background())
+	}
+
+	now := time.Now()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rule := range s.rules {
+		if rule.Path == route && (rule.Method == "" || rule.Method == method) && now.Before(rule.ExpiresAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh reloads the rules, unless another request reloaded them meanwhile
+func (s *BodyLoggingService) refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > s.refreshInterval
+	s.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+
+	_, err := s.load(ctx)
+	if err != nil {
+		// Retry with the next refresh rather than on every request
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// load reads the rules that have not expired and caches them
+func (s *BodyLoggingService) load(ctx context.Context) ([]BodyLoggingRule, error) {
+	var rules []BodyLoggingRule
+	err := s.cache.GetJSON(ctx, bodyLoggingKey, &rules)
+	if err != nil && !errors.Is(err, database.ErrKeyNotFound) {
+		return nil, fmt.Errorf("failed to load body logging rules: %w", err)
+	}
+	now := time.Now()
+	rules = slices.DeleteFunc(rules, func(rule BodyLoggingRule) bool { return !now.Before(rule.ExpiresAt) })
+	if rules == nil {
+		rules = []BodyLoggingRule{}
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return slices.Clone(rules), nil
+}
+
+// save stores the rules until the last of them expires
+func (s *BodyLoggingService) save(ctx context.Context, rules []BodyLoggingRule) error {
+	var err error
+	if len(rules) == 0 {
+		err = s.cache.Del(ctx, bodyLoggingKey)
+	} else {
+		expiresAt := rules[0].ExpiresAt
+		for _, rule := range rules[1:] {
+			if rule.ExpiresAt.After(expiresAt) {
+				expiresAt = rule.ExpiresAt
+			}
+		}
+		err = s.cache.SetJSON(ctx, bodyLoggingKey, rules, time.Until(expiresAt))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save body logging rules: %w", err)
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
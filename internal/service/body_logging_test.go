@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/database"
+)
+
+func TestBodyLoggingService(t *testing.T) {
+	ctx := context.Background()
+	cache := database.NewMemoryClient()
+	service := NewBodyLoggingService(cache, time.Hour)
+	// Another instance sharing the rules through Redis
+	other := NewBodyLoggingService(cache, time.Hour)
+
+	if other.LogBodies("POST", "/v1/users/:userId/roles") {
+		t.Fatal("Expected no bodies to be logged before a rule is enabled")
+	}
+
+	var appErr *apperrors.Error
+	_, err := service.EnableRule(ctx, "admin", &EnableBodyLoggingRequest{Path: "/v1/users/:userId/roles", DurationMinutes: 61})
+	if !errors.As(err, &appErr) || appErr.Code != "INVALID_DURATION" {
+		t.Fatalf("Expected INVALID_DURATION above the maximum, got %v", err)
+	}
+
+	rule, err := service.EnableRule(ctx, "admin", &EnableBodyLoggingRequest{Method: "POST", Path: "/v1/users/:userId/roles"})
+	if err != nil {
+		t.Fatalf("EnableRule returned error: %v", err)
+	}
+	if got := rule.ExpiresAt.Sub(rule.CreatedAt); got != defaultBodyLoggingDuration {
+		t.Errorf("Rule applies for %s, want %s by default", got, defaultBodyLoggingDuration)
+	}
+
+	// The other instance picks the rule up on its next refresh
+	other.refreshInterval = 0
+	if !other.LogBodies("POST", "/v1/users/:userId/roles") {
+		t.Error("Expected bodies of the route to be logged on other instances")
+	}
+	if other.LogBodies("DELETE", "/v1/users/:userId/roles") || other.LogBodies("POST", "/v1/users") {
+		t.Error("Expected bodies of other methods and routes not to be logged")
+	}
+
+	rules, err := other.ListRules(ctx)
+	if err != nil || len(rules) != 1 || rules[0].ID != rule.ID {
+		t.Fatalf("ListRules = (%+v, %v), want the enabled rule", rules, err)
+	}
+
+	if err := other.DisableRule(ctx, rule.ID); err != nil {
+		t.Fatalf("DisableRule returned error: %v", err)
+	}
+	service.refreshInterval = 0
+	if service.LogBodies("POST", "/v1/users/:userId/roles") {
+		t.Error("Expected bodies not to be logged once the rule is disabled")
+	}
+	if err := other.DisableRule(ctx, rule.ID); !errors.As(err, &appErr) || appErr.Code != "BODY_LOGGING_RULE_NOT_FOUND" {
+		t.Errorf("Expected BODY_LOGGING_RULE_NOT_FOUND for a deleted rule, got %v", err)
+	}
+
+	// Expired rules no longer apply
+	if err := service.save(ctx, []BodyLoggingRule{{ID: "expired", Path: "/v1/users", ExpiresAt: time.Now().Add(-time.Second)}}); err != nil {
+		t.Fatalf("Failed to save rules: %v", err)
+	}
+	if service.LogBodies("GET", "/v1/users") {
+		t.Error("Expected an expired rule not to apply")
+	}
+
+	disabled := NewBodyLoggingService(cache, 0)
+	if _, err := disabled.EnableRule(ctx, "admin", &EnableBodyLoggingRequest{Path: "/v1/users"}); !errors.As(err, &appErr) || appErr.Code != "BODY_LOGGING_DISABLED" {
+		t.Errorf("Expected BODY_LOGGING_DISABLED with a zero maximum, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// System roles checked by the bundled policies, e.g. helpers.is_super_admin
+const (
+	RoleSuperAdmin = auth.PlatformRole
+	RoleAdmin      = "admin"
+)
+
+// BootstrapRequest names the first super admin of a deployment
+type BootstrapRequest struct {
+	Email      string
+	FirstName  string
+	LastName   string
+	TenantSlug string // Tenant of the admin, "default" when empty
+}
+
+// BootstrapResult is the outcome of a bootstrap
+type BootstrapResult struct {
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+	Email    string
+	// Created is false when a super admin already existed and nothing was done
+	Created bool
+	// Password is the one-time password of a created admin. It is not stored
+	// anywhere else, so it must be shown once and changed after signing in.
+	// Empty when an existing user was made super admin.
+	Password string
+}
+
+// Bootstrapper creates the system roles and the first super admin, so a new
+// deployment can be managed without editing the database by hand
+type Bootstrapper struct {
+	db       *gorm.DB
+	identity auth.IdentityProvider
+}
+
+// NewBootstrapper creates a new bootstrapper
+func NewBootstrapper(db *gorm.DB, identity auth.IdentityProvider) *Bootstrapper {
+	return &Bootstrapper{db: db, identity: identity}
+}
+
+// Bootstrap ensures the tenant has the super_admin and admin system roles and,
+// unless a user already holds super_admin, makes the user with req.Email super
+// admin, creating them with a one-time password when they do not exist. It is
+// safe to run on every startup.
+func (b *Bootstrapper) Bootstrap(ctx context.Context, req *BootstrapRequest) (*BootstrapResult, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, apperrors.Validation("INVALID_EMAIL", "A valid email is required to bootstrap an admin")
+	}
+	slug := req.TenantSlug
+	if slug == "" {
+		slug = "default"
+	}
+
+	db := b.db.WithContext(ctx)
+	var tenant models.Tenant
+	if err := db.Where("slug = ?", slug).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", fmt.Sprintf("Tenant %q not found, run the seed first", slug))
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	superAdmin, err := b.ensureSystemRoles(db, &tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the first admin is bootstrapped; later admins are managed through the API
+	var existing models.User
+	err = db.Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Where("user_roles.role_id = ?", superAdmin.ID).
+		Order("users.created_at").
+		First(&existing).Error
+	if err == nil {
+		return &BootstrapResult{TenantID: tenant.ID, UserID: existing.ID, Email: existing.Email}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to find super admins: %w", err)
+	}
+
+	result := &BootstrapResult{TenantID: tenant.ID, Email: email, Created: true}
+	var user models.User
+	err = db.Where("tenant_id = ? AND lower(email) = ?", tenant.ID, email).First(&user).Error
+	switch {
+	case err == nil:
+		// Existing users keep their credentials
+		if err := db.Create(&models.UserRole{UserID: user.ID, RoleID: superAdmin.ID}).Error; err != nil {
+			return nil, fmt.Errorf("failed to assign super admin role: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if result.Password, err = oneTimePassword(); err != nil {
+			return nil, err
+		}
+		if user, err = b.createAdmin(ctx, &tenant, superAdmin, email, req, result.Password); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	result.UserID = user.ID
+	return result, nil
+}
+
+// ensureSystemRoles creates the super_admin and admin roles of a tenant when
+// missing and grants them the system permissions they lack: super admins get
+// every system permission, admins the tenant-scoped ones. It returns the
+// super_admin role.
+func (b *Bootstrapper) ensureSystemRoles(db *gorm.DB, tenant *models.Tenant) (*models.Role, error) {
+	var permissions []models.Permission
+	if err := db.Where("is_system = ?", true).Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get system permissions: %w", err)
+	}
+	if len(permissions) == 0 {
+		return nil, apperrors.PreconditionFailed("SYSTEM_PERMISSIONS_MISSING", "System permissions are missing, run the seed first")
+	}
+
+	roles := []struct {
+		name        string
+		description string
+		grants      func(models.Permission) bool
+	}{
+		{RoleSuperAdmin, "Manages every tenant and the platform", func(models.Permission) bool { return true }},
+		{RoleAdmin, "Manages the tenant", func(p models.Permission) bool { return p.Scope != "global" }},
+	}
+
+	var superAdmin models.Role
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, r := range roles {
+			role := models.Role{TenantID: tenant.ID, Name: r.name}
+			if err := tx.Where(&role).Attrs(models.Role{Description: r.description, IsSystem: true}).FirstOrCreate(&role).Error; err != nil {
+				return fmt.Errorf("failed to create role %s: %w", r.name, err)
+			}
+
+			var granted []string
+			if err := tx.Model(&models.RolePermission{}).Where("role_id = ?", role.ID).Pluck("permission_id", &granted).Error; err != nil {
+				return fmt.Errorf("failed to get permissions of role %s: %w", r.name, err)
+			}
+			has := make(map[string]bool, len(granted))
+			for _, id := range granted {
+				has[id] = true
+			}
+			for _, permission := range permissions {
+				if !r.grants(permission) || has[permission.ID.String()] {
+					continue
+				}
+				if err := tx.Create(&models.RolePermission{RoleID: role.ID, PermissionID: permission.ID}).Error; err != nil {
+					return fmt.Errorf("failed to grant %s to role %s: %w", permission.Name, r.name, err)
+				}
+			}
+			if r.name == RoleSuperAdmin {
+				superAdmin = role
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &superAdmin, nil
+}
+
+// createAdmin creates a super admin in Heimdall and the identity provider,
+// removing the local record again when the provider rejects the user
+func (b *Bootstrapper) createAdmin(ctx context.Context, tenant *models.Tenant, superAdmin *models.Role, email string, req *BootstrapRequest, password string) (models.User, error) {
+	metadata, err := json.Marshal(map[string]interface{}{
+		"firstName": req.FirstName,
+		"lastName":  req.LastName,
+	})
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	user := models.User{ID: uuid.New(), TenantID: tenant.ID, Email: email, Metadata: metadata}
+
+	db := b.db.WithContext(ctx)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user record: %w", err)
+		}
+		if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: superAdmin.ID}).Error; err != nil {
+			return fmt.Errorf("failed to assign super admin role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if _, err := b.identity.Register(ctx, &auth.RegisterRequest{
+		UserID:    user.ID.String(),
+		Email:     email,
+		Password:  password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}); err != nil {
+		if rollbackErr := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserRole{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&user).Error
+		}); rollbackErr != nil {
+			log.Printf("Failed to roll back bootstrap admin %s: %v", user.ID, rollbackErr)
+		}
+		if errors.Is(err, auth.ErrEmailTaken) {
+			return models.User{}, apperrors.Conflict("USER_EMAIL_EXISTS", "The identity provider already has a user with this email; choose another email")
+		}
+		return models.User{}, fmt.Errorf("failed to create admin in identity provider: %w", err)
+	}
+	return user, nil
+}
+
+// oneTimePassword generates a random password of 24 URL-safe characters
+func oneTimePassword() (string, error) {
+	raw := make([]byte, 18)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestBootstrap(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Default Tenant", "default")
+		fake, fusionAuth := newFakeFusionAuth(t)
+		bootstrapper := NewBootstrapper(db, fusionAuth)
+
+		// System permissions are seeded before bootstrapping
+		req := &BootstrapRequest{Email: "Admin@Example.com"}
+		if _, err := bootstrapper.Bootstrap(ctx, req); !isAppError(err, "SYSTEM_PERMISSIONS_MISSING") {
+			t.Fatalf("Expected SYSTEM_PERMISSIONS_MISSING, got %v", err)
+		}
+		for _, p := range []models.Permission{
+			{Name: "policies.create", Resource: "policies", Action: "create", Scope: "tenant", IsSystem: true},
+			{Name: "signing_keys.rotate", Resource: "signing_keys", Action: "rotate", Scope: "global", IsSystem: true},
+		} {
+			if err := db.Create(&p).Error; err != nil {
+				t.Fatalf("Failed to create permission: %v", err)
+			}
+		}
+
+		result, err := bootstrapper.Bootstrap(ctx, req)
+		if err != nil {
+			t.Fatalf("Bootstrap failed: %v", err)
+		}
+		if !result.Created || len(result.Password) != 24 || result.Email != "admin@example.com" || result.TenantID != tenant.ID {
+			t.Fatalf("Expected a super admin with a one-time password, got %+v", result)
+		}
+		granted := func(name string) int64 {
+			var count int64
+			db.Model(&models.RolePermission{}).
+				Joins("JOIN roles ON roles.id = role_permissions.role_id").
+				Where("roles.tenant_id = ? AND roles.name = ? AND roles.is_system = ?", tenant.ID, name, true).
+				Count(&count)
+			return count
+		}
+		if granted(RoleSuperAdmin) != 2 || granted(RoleAdmin) != 1 {
+			t.Errorf("Expected super_admin with every system permission and admin with the tenant ones, got %d and %d", granted(RoleSuperAdmin), granted(RoleAdmin))
+		}
+		if !fake.hasUser(result.UserID.String()) {
+			t.Error("Expected the admin to be created in the identity provider")
+		}
+
+		// Bootstrapping again changes nothing
+		again, err := bootstrapper.Bootstrap(ctx, &BootstrapRequest{Email: "other@example.com"})
+		if err != nil || again.Created || again.Password != "" || again.UserID != result.UserID {
+			t.Errorf("Expected the second bootstrap to keep the existing admin, got %+v %v", again, err)
+		}
+		var users, roles int64
+		db.Model(&models.User{}).Count(&users)
+		db.Model(&models.Role{}).Count(&roles)
+		if users != 1 || roles != 2 {
+			t.Errorf("Expected one user and two roles after bootstrapping twice, got %d and %d", users, roles)
+		}
+	})
+}
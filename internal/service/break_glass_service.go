@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+)
+
+// BreakGlassService accepts break-glass tokens, pages operators when one is
+// used and records its requests in the audit log
+type BreakGlassService struct {
+	verifier *auth.BreakGlassVerifier
+	audit    *AdminAuditService
+	events   events.Publisher
+
+	mu    sync.Mutex
+	paged map[string]time.Time // IDs of the tokens already paged for, until they expire
+}
+
+// NewBreakGlassService creates a new break-glass service. A nil publisher
+// disables paging.
+func NewBreakGlassService(verifier *auth.BreakGlassVerifier, audit *AdminAuditService, publisher events.Publisher) *BreakGlassService {
+	if publisher == nil {
+		publisher = events.NoopPublisher{}
+	}
+	return &BreakGlassService{
+		verifier: verifier,
+		audit:    audit,
+		events:   publisher,
+		paged:    make(map[string]time.Time),
+	}
+}
+
+// BreakGlassSessionResponse describes the break-glass token a request was made with
+type BreakGlassSessionResponse struct {
+	UserID    string `json:"userId" example:"550e8400-e29b-41d4-a716-446655440000"` // Emergency user the token acts as
+	TenantID  string `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Operator  string `json:"operator" example:"alice@ops"`
+	Reason    string `json:"reason" example:"FusionAuth outage INC-1234"`
+	TokenID   string `json:"tokenId" example:"550e8400-e29b-41d4-a716-446655440002"`
+	IssuedAt  string `json:"issuedAt" example:"2024-01-20T08:00:00Z"`
+	ExpiresAt string `json:"expiresAt" example:"2024-01-20T09:00:00Z"`
+}
+
+// VerifyBreakGlassToken returns the claims of a valid break-glass token
+func (s *BreakGlassService) VerifyBreakGlassToken(tokenString string) (*auth.TokenClaims, error) {
+	return s.verifier.Verify(tokenString)
+}
+
+// PageBreakGlass publishes a breakglass.used event the first time a token is
+// used, so operators are paged as soon as emergency access starts
+func (s *BreakGlassService) PageBreakGlass(ctx context.Context, claims *auth.TokenClaims, ip string) {
+	if !s.firstUse(claims) {
+		return
+	}
+
+	session := toBreakGlassSessionResponse(claims)
+	log.Printf("🚨 Break-glass access by %s as user %s of tenant %s from %s: %s", session.Operator, session.UserID, session.TenantID, ip, session.Reason)
+	s.events.Publish(ctx, events.NewEvent(events.EventBreakGlassUsed, session.TenantID, map[string]interface{}{
+		"userId":    session.UserID,
+		"operator":  session.Operator,
+		"reason":    session.Reason,
+		"tokenId":   session.TokenID,
+		"ipAddress": ip,
+		"issuedAt":  session.IssuedAt,
+		"expiresAt": session.ExpiresAt,
+	}))
+}
+
+// RecordAdminAction stores a request made with a break-glass token in the audit log
+func (s *BreakGlassService) RecordAdminAction(ctx context.Context, entry *models.AuditLog) error {
+	return s.audit.RecordAdminAction(ctx, entry)
+}
+
+// firstUse reports whether a token has not been paged for yet, forgetting the
+// tokens that have expired
+func (s *BreakGlassService) firstUse(claims *auth.TokenClaims) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for tokenID, expiresAt := range s.paged {
+		if now.After(expiresAt) {
+			delete(s.paged, tokenID)
+		}
+	}
+	if _, ok := s.paged[claims.ID]; ok {
+		return false
+	}
+	s.paged[claims.ID] = claims.ExpiresAt.Time
+	return true
+}
+
+// Session describes the break-glass token a request was made with
+func (s *BreakGlassService) Session(claims *auth.TokenClaims) *BreakGlassSessionResponse {
+	return toBreakGlassSessionResponse(claims)
+}
+
+func toBreakGlassSessionResponse(claims *auth.TokenClaims) *BreakGlassSessionResponse {
+	return &BreakGlassSessionResponse{
+		UserID:    claims.UserID,
+		TenantID:  claims.TenantID,
+		Operator:  claims.Operator,
+		Reason:    claims.Reason,
+		TokenID:   claims.ID,
+		IssuedAt:  claims.IssuedAt.UTC().Format(time.RFC3339),
+		ExpiresAt: claims.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
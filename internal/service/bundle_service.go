@@ -8,224 +8,438 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
-	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/storage"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 // BundleService handles policy bundle operations
 type BundleService struct {
-	db          *gorm.DB
-	minioClient *minio.Client
-	bucket      string
+	db    *gorm.DB
+	store storage.ObjectStore
+	rbac  *RBACDataSync // Builds the tenant data documents of bundles
+	jobs  *JobQueue     // Runs bundle builds
 }
 
-// NewBundleService creates a new bundle service
-func NewBundleService(db *gorm.DB, cfg *config.MinIOConfig) (*BundleService, error) {
-	// Initialize MinIO client
-	minioClient, err := minio.New(cfg.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
-		Secure: cfg.UseSSL,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
-	}
-
-	return &BundleService{
-		db:          db,
-		minioClient: minioClient,
-		bucket:      cfg.Bucket,
-	}, nil
+// NewBundleService creates a new bundle service storing bundles in an object
+// store. Bundles are built by the job queue's workers.
+func NewBundleService(db *gorm.DB, store storage.ObjectStore, jobs *JobQueue) *BundleService {
+	s := &BundleService{
+		db:    db,
+		store: store,
+		rbac:  NewRBACDataSync(db, nil),
+		jobs:  jobs,
+	}
+	jobs.Register(JobBundleBuild, s.runBuildJob)
+	return s
 }
 
-// EnsureBucket ensures the MinIO bucket exists
-func (s *BundleService) EnsureBucket(ctx context.Context) error {
-	exists, err := s.minioClient.BucketExists(ctx, s.bucket)
-	if err != nil {
-		return fmt.Errorf("failed to check bucket existence: %w", err)
-	}
+// bundleBuildPayload identifies the bundle a bundle.build job builds
+type bundleBuildPayload struct {
+	BundleID uuid.UUID `json:"bundleId"`
+	UserID   uuid.UUID `json:"userId"` // Attributed with the policies a selector resolves to
+}
 
-	if !exists {
-		if err := s.minioClient.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{}); err != nil {
-			return fmt.Errorf("failed to create bucket: %w", err)
-		}
-	}
+// bundleBuildResult is reported by a completed bundle.build job
+type bundleBuildResult struct {
+	BundleID uuid.UUID `json:"bundleId"`
+	Checksum string    `json:"checksum"`
+	Size     int64     `json:"size"`
+}
 
-	return nil
+// EnsureBucket ensures the bundle storage bucket exists
+func (s *BundleService) EnsureBucket(ctx context.Context) error {
+	return s.store.EnsureBucket(ctx)
 }
 
-// CreateBundleRequest represents a request to create a bundle
+// CreateBundleRequest represents a request to create a bundle. A bundle holds
+// either a fixed list of policies or the active policies matching a selector.
 type CreateBundleRequest struct {
-	TenantID    *uuid.UUID `json:"tenantId,omitempty"` // nil for global bundles
-	Name        string     `json:"name" validate:"required"`
-	Description string     `json:"description"`
-	Version     string     `json:"version" validate:"required"`
-	PolicyIDs   []uuid.UUID `json:"policyIds" validate:"required,min=1"`
-	IsGlobal    bool       `json:"isGlobal"`
+	TenantID    *uuid.UUID             `json:"tenantId,omitempty"` // nil for global bundles
+	Name        string                 `json:"name" validate:"required,max=200"`
+	Description string                 `json:"description"`
+	Version     string                 `json:"version" validate:"required,max=100"`
+	PolicyIDs   []uuid.UUID            `json:"policyIds,omitempty" validate:"required_without=Selector,excluded_with=Selector"`
+	Selector    *models.BundleSelector `json:"selector,omitempty" validate:"required_without=PolicyIDs"`
+	AutoRebuild bool                   `json:"autoRebuild"` // Rebuild whenever a matching policy is published; requires a selector
+	IncludeData bool                   `json:"includeData"` // Ship the tenant data documents policies read, for offline evaluation
+	IsGlobal    bool                   `json:"isGlobal"`
 }
 
 // CreateBundle creates a new policy bundle
 func (s *BundleService) CreateBundle(ctx context.Context, userID uuid.UUID, req *CreateBundleRequest) (*models.PolicyBundle, error) {
 	bundle := &models.PolicyBundle{
-		Name:        req.Name,
-		Description: req.Description,
-		Version:     req.Version,
-		Status:      models.BundleStatusBuilding,
-		IsGlobal:    req.IsGlobal,
-		CreatedBy:   userID,
-		UpdatedBy:   userID,
-		StorageBucket: s.bucket,
+		Name:          req.Name,
+		Description:   req.Description,
+		Version:       req.Version,
+		Status:        models.BundleStatusBuilding,
+		IsGlobal:      req.IsGlobal,
+		CreatedBy:     userID,
+		UpdatedBy:     userID,
+		StorageBucket: s.store.Bucket(),
+		IncludeData:   req.IncludeData,
 	}
 
 	if req.TenantID != nil {
 		bundle.TenantID = *req.TenantID
 	}
 
+	if req.Selector != nil {
+		if len(req.Selector.Tags) == 0 && req.Selector.Prefix() == "" {
+			return nil, apperrors.Validation("INVALID_SELECTOR", "Selector requires tags or a path prefix")
+		}
+		selector, err := json.Marshal(req.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal selector: %w", err)
+		}
+		bundle.Selector = datatypes.JSON(selector)
+		bundle.AutoRebuild = req.AutoRebuild
+	} else if req.AutoRebuild {
+		return nil, apperrors.Validation("AUTO_REBUILD_REQUIRES_SELECTOR", "Only bundles defined by a selector can be rebuilt automatically")
+	}
+
 	// Create bundle record
 	if err := s.db.WithContext(ctx).Create(bundle).Error; err != nil {
 		return nil, fmt.Errorf("failed to create bundle: %w", err)
 	}
 
-	// Associate policies with bundle
-	now := time.Now()
-	for _, policyID := range req.PolicyIDs {
-		bundlePolicy := &models.BundlePolicy{
-			BundleID: bundle.ID,
-			PolicyID: policyID,
-			AddedAt:  now,
-			AddedBy:  userID,
-		}
-		if err := s.db.WithContext(ctx).Create(bundlePolicy).Error; err != nil {
-			return nil, fmt.Errorf("failed to associate policy: %w", err)
-		}
+	// Associate policies with bundle; selector bundles are associated when built
+	if err := s.setBundlePolicies(ctx, s.db, bundle.ID, req.PolicyIDs, userID); err != nil {
+		return nil, err
 	}
 
 	// Build the bundle asynchronously
-	go s.buildBundle(context.Background(), bundle.ID, userID)
+	jobID, err := s.enqueueBuild(ctx, bundle, userID)
+	if err != nil {
+		s.updateBundleError(ctx, bundle.ID, err.Error())
+		return nil, err
+	}
+	bundle.BuildJobID = &jobID
 
 	return bundle, nil
 }
 
-// buildBundle builds the OPA bundle tar.gz file and uploads to MinIO
-func (s *BundleService) buildBundle(ctx context.Context, bundleID, userID uuid.UUID) {
+// PolicyPublished rebuilds the auto-rebuilding bundles whose selector matches a
+// newly published policy, so they never drift from the policy set
+func (s *BundleService) PolicyPublished(ctx context.Context, policy *models.Policy) {
+	var bundles []*models.PolicyBundle
+	if err := s.db.WithContext(ctx).
+		Where("auto_rebuild = ? AND (tenant_id = ? OR is_global = ?)", true, policy.TenantID, true).
+		Find(&bundles).Error; err != nil {
+		log.Printf("Failed to find bundles to rebuild for policy %s: %v", policy.ID, err)
+		return
+	}
+
+	for _, bundle := range bundles {
+		selector, err := bundleSelector(bundle)
+		if err != nil || selector == nil || !selector.Matches(policy) {
+			continue
+		}
+		log.Printf("Rebuilding bundle %s (%s) after policy %s was published", bundle.ID, bundle.Name, policy.Path)
+		if _, err := s.enqueueBuild(ctx, bundle, policy.UpdatedBy); err != nil {
+			log.Printf("Failed to rebuild bundle %s: %v", bundle.ID, err)
+		}
+	}
+}
+
+// enqueueBuild queues a build of a bundle and records the job on the bundle
+func (s *BundleService) enqueueBuild(ctx context.Context, bundle *models.PolicyBundle, userID uuid.UUID) (uuid.UUID, error) {
+	job, err := s.jobs.Enqueue(ctx, JobBundleBuild, bundle.TenantID, userID, bundleBuildPayload{BundleID: bundle.ID, UserID: userID})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := s.db.WithContext(ctx).Model(&models.PolicyBundle{}).Where("id = ?", bundle.ID).
+		Update("build_job_id", job.ID).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record build job: %w", err)
+	}
+	return job.ID, nil
+}
+
+// runBuildJob runs a bundle.build job
+func (s *BundleService) runBuildJob(ctx context.Context, job *models.Job) (interface{}, error) {
+	var payload bundleBuildPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, &permanentError{err: fmt.Errorf("invalid payload: %w", err)}
+	}
+	if err := s.buildBundle(ctx, payload.BundleID, payload.UserID); err != nil {
+		return nil, err
+	}
+
+	var bundle models.PolicyBundle
+	if err := s.db.WithContext(ctx).First(&bundle, "id = ?", payload.BundleID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load built bundle: %w", err)
+	}
+	return bundleBuildResult{BundleID: bundle.ID, Checksum: bundle.Checksum, Size: bundle.Size}, nil
+}
+
+// buildBundle builds the OPA bundle tar.gz file and uploads it to bundle
+// storage. Selector bundles are first resolved to their current policies.
+// Rebuilding a built bundle keeps its status and, if the build fails, its
+// previous contents. Failures are recorded on the bundle and returned; those
+// a retry cannot fix are permanent.
+func (s *BundleService) buildBundle(ctx context.Context, bundleID, userID uuid.UUID) error {
+	var current models.PolicyBundle
+	err := s.db.WithContext(ctx).First(&current, "id = ?", bundleID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &permanentError{err: fmt.Errorf("bundle %s no longer exists", bundleID)}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load bundle %s to build: %w", bundleID, err)
+	}
+	rebuild := current.StoragePath != ""
+
 	// Update status
 	now := time.Now()
-	s.db.Model(&models.PolicyBundle{}).Where("id = ?", bundleID).Updates(map[string]interface{}{
-		"build_started_at": now,
-		"status":           models.BundleStatusBuilding,
-	})
+	updates := map[string]interface{}{"build_started_at": now}
+	if !rebuild {
+		updates["status"] = models.BundleStatusBuilding
+	}
+	s.db.Model(&models.PolicyBundle{}).Where("id = ?", bundleID).Updates(updates)
+
+	// fail records a failure on the bundle and returns it, as permanent unless
+	// a retry may succeed
+	fail := func(message string, retryable bool) error {
+		if rebuild {
+			s.db.Model(&models.PolicyBundle{}).Where("id = ?", bundleID).Updates(map[string]interface{}{
+				"build_completed_at": time.Now(),
+				"build_error":        message,
+			})
+		} else {
+			s.updateBundleError(ctx, bundleID, message)
+		}
+		if retryable {
+			return errors.New(message)
+		}
+		return &permanentError{err: errors.New(message)}
+	}
+
+	if err := s.resolveSelector(ctx, &current, userID); err != nil {
+		return fail(fmt.Sprintf("Failed to resolve selector: %v", err), true)
+	}
 
 	// Get bundle with policies
 	var bundle models.PolicyBundle
 	if err := s.db.Preload("Policies").First(&bundle, "id = ?", bundleID).Error; err != nil {
-		s.updateBundleError(ctx, bundleID, fmt.Sprintf("Failed to load bundle: %v", err))
-		return
+		return fail(fmt.Sprintf("Failed to load bundle: %v", err), true)
+	}
+
+	// Build the data documents policies need to evaluate offline
+	var data map[string]interface{}
+	if bundle.IncludeData {
+		var err error
+		if data, err = s.bundleData(ctx, &bundle); err != nil {
+			return fail(fmt.Sprintf("Failed to build data documents: %v", err), true)
+		}
+	}
+
+	// Move each policy under its tenant's namespace
+	policies, err := namespacePolicies(ctx, s.db, bundle.Policies)
+	if err != nil {
+		return fail(fmt.Sprintf("Failed to namespace policies: %v", err), true)
 	}
 
 	// Create bundle tar.gz
-	bundleData, checksum, err := s.createBundleTarGz(&bundle)
+	bundleData, checksum, manifest, err := s.createBundleTarGz(&bundle, policies, data)
 	if err != nil {
-		s.updateBundleError(ctx, bundleID, fmt.Sprintf("Failed to create bundle: %v", err))
-		return
+		return fail(fmt.Sprintf("Failed to create bundle: %v", err), false)
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return fail(fmt.Sprintf("Failed to marshal manifest: %v", err), false)
 	}
 
-	// Upload to MinIO
+	// Upload to bundle storage
 	storagePath := fmt.Sprintf("bundles/heimdall-%s.tar.gz", bundle.Version)
-	_, err = s.minioClient.PutObject(
-		ctx,
-		s.bucket,
-		storagePath,
-		bytes.NewReader(bundleData),
-		int64(len(bundleData)),
-		minio.PutObjectOptions{ContentType: "application/gzip"},
-	)
-	if err != nil {
-		s.updateBundleError(ctx, bundleID, fmt.Sprintf("Failed to upload bundle: %v", err))
-		return
+	if err := s.store.Put(ctx, storagePath, bundleData, "application/gzip"); err != nil {
+		return fail(fmt.Sprintf("Failed to upload bundle: %v", err), true)
 	}
 
 	// Update bundle with success
 	completedAt := time.Now()
-	s.db.Model(&models.PolicyBundle{}).Where("id = ?", bundleID).Updates(map[string]interface{}{
-		"status":              models.BundleStatusReady,
-		"build_completed_at":  completedAt,
-		"storage_path":        storagePath,
-		"size":                len(bundleData),
-		"checksum":            checksum,
+	updates = map[string]interface{}{
+		"build_completed_at": completedAt,
+		"build_error":        "",
+		"storage_path":       storagePath,
+		"size":               len(bundleData),
+		"checksum":           checksum,
+		"manifest":           datatypes.JSON(manifestJSON),
+	}
+	if !rebuild {
+		updates["status"] = models.BundleStatusReady
+	}
+	if err := s.db.Model(&models.PolicyBundle{}).Where("id = ?", bundleID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record build of bundle %s: %w", bundleID, err)
+	}
+	return nil
+}
+
+// resolveSelector replaces the policies of a selector bundle with the active
+// policies currently matching its selector. Tenant bundles select their
+// tenant's policies and global bundles those of all tenants.
+func (s *BundleService) resolveSelector(ctx context.Context, bundle *models.PolicyBundle, userID uuid.UUID) error {
+	selector, err := bundleSelector(bundle)
+	if err != nil || selector == nil {
+		return err
+	}
+
+	query := s.db.WithContext(ctx).Where("status = ?", models.PolicyStatusActive)
+	if !bundle.IsGlobal {
+		query = query.Where("tenant_id = ?", bundle.TenantID)
+	}
+	if prefix := selector.Prefix(); prefix != "" {
+		query = query.Where("path LIKE ?"+likeEscape(s.db), likeEscaper.Replace(prefix)+"%")
+	}
+	var candidates []*models.Policy
+	if err := query.Order("path").Find(&candidates).Error; err != nil {
+		return err
+	}
+
+	var policyIDs []uuid.UUID
+	for _, policy := range candidates {
+		if selector.Matches(policy) {
+			policyIDs = append(policyIDs, policy.ID)
+		}
+	}
+	if len(policyIDs) == 0 {
+		return fmt.Errorf("no active policies match the selector")
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundle.ID).Delete(&models.BundlePolicy{}).Error; err != nil {
+			return err
+		}
+		return s.setBundlePolicies(ctx, tx, bundle.ID, policyIDs, userID)
 	})
 }
 
-// createBundleTarGz creates a tar.gz bundle from policies
-func (s *BundleService) createBundleTarGz(bundle *models.PolicyBundle) ([]byte, string, error) {
-	var buf bytes.Buffer
-	gzWriter := gzip.NewWriter(&buf)
-	tarWriter := tar.NewWriter(gzWriter)
+// setBundlePolicies associates policies with a bundle
+func (s *BundleService) setBundlePolicies(ctx context.Context, db *gorm.DB, bundleID uuid.UUID, policyIDs []uuid.UUID, userID uuid.UUID) error {
+	now := time.Now()
+	for _, policyID := range policyIDs {
+		bundlePolicy := &models.BundlePolicy{
+			BundleID: bundleID,
+			PolicyID: policyID,
+			AddedAt:  now,
+			AddedBy:  userID,
+		}
+		if err := db.WithContext(ctx).Create(bundlePolicy).Error; err != nil {
+			return fmt.Errorf("failed to associate policy: %w", err)
+		}
+	}
+	return nil
+}
 
-	// Create manifest
-	manifest := map[string]interface{}{
-		"name":     bundle.Name,
-		"version":  bundle.Version,
-		"policies": []string{},
+// bundleSelector returns the selector of a bundle, or nil for bundles of fixed policies
+func bundleSelector(bundle *models.PolicyBundle) (*models.BundleSelector, error) {
+	if len(bundle.Selector) == 0 || string(bundle.Selector) == "null" {
+		return nil, nil
 	}
+	var selector models.BundleSelector
+	if err := json.Unmarshal(bundle.Selector, &selector); err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	return &selector, nil
+}
 
-	// Add each policy to the bundle
-	for _, policy := range bundle.Policies {
-		// Add policy content
-		content := []byte(policy.Content)
-		fileName := fmt.Sprintf("%s.rego", policy.Path)
+// createBundleTarGz creates a tar.gz bundle from policies and data documents,
+// following the OPA bundle layout: each policy as a .rego file at its path in
+// its tenant's namespace, each data document at its data path as data.json,
+// and a .manifest claiming the roots of their packages and data paths
+func (s *BundleService) createBundleTarGz(bundle *models.PolicyBundle, policies []*NamespacedPolicy, data map[string]interface{}) ([]byte, string, *models.BundleManifest, error) {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzWriter)
+	revision := sha256.New()
 
+	writeFile := func(name string, content []byte) error {
 		header := &tar.Header{
-			Name: fileName,
+			Name: name,
 			Mode: 0644,
 			Size: int64(len(content)),
 		}
-
 		if err := tarWriter.WriteHeader(header); err != nil {
-			return nil, "", fmt.Errorf("failed to write tar header: %w", err)
+			return fmt.Errorf("failed to write tar header: %w", err)
 		}
-
 		if _, err := tarWriter.Write(content); err != nil {
-			return nil, "", fmt.Errorf("failed to write policy content: %w", err)
+			return fmt.Errorf("failed to write %s: %w", name, err)
 		}
-
-		manifest["policies"] = append(manifest["policies"].([]string), fileName)
+		return nil
+	}
+	// addFile writes a policy or data file, which the revision covers
+	addFile := func(name string, content []byte) error {
+		fmt.Fprintf(revision, "%s\x00%d\x00", name, len(content))
+		revision.Write(content)
+		return writeFile(name, content)
+	}
+
+	policyFiles := []string{}
+	dataFiles := []string{}
+	var roots []string
+
+	// Add each policy to the bundle, in path order so equal contents give equal revisions
+	policies = append([]*NamespacedPolicy(nil), policies...)
+	sort.Slice(policies, func(i, j int) bool { return policies[i].File() < policies[j].File() })
+	for _, policy := range policies {
+		fileName := policy.File()
+		if err := addFile(fileName, []byte(policy.Content)); err != nil {
+			return nil, "", nil, err
+		}
+		policyFiles = append(policyFiles, fileName)
+		roots = append(roots, policy.Root())
 	}
 
-	// Add manifest.json
-	manifestData, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to marshal manifest: %w", err)
+	// Add each data document
+	for _, dataPath := range sortedKeys(data) {
+		content, err := json.Marshal(data[dataPath])
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to marshal data document %s: %w", dataPath, err)
+		}
+		fileName := dataPath + "/data.json"
+		if err := addFile(fileName, content); err != nil {
+			return nil, "", nil, err
+		}
+		dataFiles = append(dataFiles, fileName)
+		roots = append(roots, dataPath)
 	}
 
-	manifestHeader := &tar.Header{
-		Name: ".manifest",
-		Mode: 0644,
-		Size: int64(len(manifestData)),
+	// Add .manifest
+	manifest := &models.BundleManifest{
+		Revision: hex.EncodeToString(revision.Sum(nil)),
+		Roots:    bundleRoots(roots),
+		Metadata: map[string]interface{}{
+			"name":     bundle.Name,
+			"version":  bundle.Version,
+			"policies": policyFiles,
+			"data":     dataFiles,
+		},
 	}
-
-	if err := tarWriter.WriteHeader(manifestHeader); err != nil {
-		return nil, "", fmt.Errorf("failed to write manifest header: %w", err)
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to marshal manifest: %w", err)
 	}
-
-	if _, err := tarWriter.Write(manifestData); err != nil {
-		return nil, "", fmt.Errorf("failed to write manifest: %w", err)
+	if err := writeFile(".manifest", manifestData); err != nil {
+		return nil, "", nil, err
 	}
 
 	// Close writers
 	if err := tarWriter.Close(); err != nil {
-		return nil, "", fmt.Errorf("failed to close tar writer: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to close tar writer: %w", err)
 	}
 
 	if err := gzWriter.Close(); err != nil {
-		return nil, "", fmt.Errorf("failed to close gzip writer: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to close gzip writer: %w", err)
 	}
 
 	// Calculate checksum
@@ -233,7 +447,71 @@ func (s *BundleService) createBundleTarGz(bundle *models.PolicyBundle) ([]byte,
 	hash := sha256.Sum256(bundleData)
 	checksum := hex.EncodeToString(hash[:])
 
-	return bundleData, checksum, nil
+	return bundleData, checksum, manifest, nil
+}
+
+// bundleRoots returns the sorted roots a bundle claims, leaving out those
+// already under another root
+func bundleRoots(paths []string) []string {
+	sort.Strings(paths)
+	roots := []string{}
+	for _, path := range paths {
+		if len(roots) > 0 {
+			last := roots[len(roots)-1]
+			if path == last || strings.HasPrefix(path, last+"/") {
+				continue
+			}
+		}
+		roots = append(roots, path)
+	}
+	return roots
+}
+
+// BundleTenantData is the data document of a tenant shipped in bundles, so
+// policies evaluated offline find the same data.heimdall.tenants[tenantId]
+// document that is pushed to OPA
+type BundleTenantData struct {
+	RBACData
+	Settings map[string]interface{} `json:"settings"` // The tenant's settings
+	IPAccess IPAccessLists          `json:"ipAccess"` // The tenant's IP allowlist and denylist
+}
+
+// bundleData builds the data documents of a bundle by data path: the document
+// of the bundle's tenant, or of every tenant for global bundles
+func (s *BundleService) bundleData(ctx context.Context, bundle *models.PolicyBundle) (map[string]interface{}, error) {
+	query := s.db.WithContext(ctx)
+	if !bundle.IsGlobal {
+		query = query.Where("id = ?", bundle.TenantID)
+	}
+	var tenants []*models.Tenant
+	if err := query.Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tenants: %w", err)
+	}
+
+	data := make(map[string]interface{}, len(tenants))
+	for _, tenant := range tenants {
+		rbac, err := s.rbac.TenantData(ctx, tenant.ID)
+		if err != nil {
+			return nil, err
+		}
+		settings, err := tenantSettings(tenant)
+		if err != nil {
+			return nil, err
+		}
+		lists, err := ipAccessLists(settings)
+		if err != nil {
+			return nil, err
+		}
+		if lists == nil {
+			lists = &IPAccessLists{Allowlist: []string{}, Denylist: []string{}}
+		}
+		data[rbacDataPath+"/"+tenant.ID.String()] = &BundleTenantData{
+			RBACData: *rbac,
+			Settings: settings,
+			IPAccess: *lists,
+		}
+	}
+	return data, nil
 }
 
 // updateBundleError updates bundle with error status
@@ -250,8 +528,8 @@ func (s *BundleService) updateBundleError(ctx context.Context, bundleID uuid.UUI
 func (s *BundleService) GetBundle(ctx context.Context, bundleID uuid.UUID) (*models.PolicyBundle, error) {
 	var bundle models.PolicyBundle
 	if err := s.db.WithContext(ctx).Preload("Policies").First(&bundle, "id = ?", bundleID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("bundle not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("BUNDLE_NOT_FOUND", "Bundle not found")
 		}
 		return nil, fmt.Errorf("failed to get bundle: %w", err)
 	}
@@ -259,10 +537,23 @@ func (s *BundleService) GetBundle(ctx context.Context, bundleID uuid.UUID) (*mod
 	return &bundle, nil
 }
 
+// BundleTenant returns the tenant of a bundle and whether it is global, so
+// handlers confine tokens to their tenant's bundles before acting on one
+func (s *BundleService) BundleTenant(ctx context.Context, bundleID uuid.UUID) (uuid.UUID, bool, error) {
+	var bundle models.PolicyBundle
+	if err := s.db.WithContext(ctx).Select("tenant_id", "is_global").First(&bundle, "id = ?", bundleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, false, apperrors.NotFound("BUNDLE_NOT_FOUND", "Bundle not found")
+		}
+		return uuid.Nil, false, fmt.Errorf("failed to get bundle: %w", err)
+	}
+	return bundle.TenantID, bundle.IsGlobal, nil
+}
+
 // GetBundles retrieves all bundles, optionally filtered by tenant
 func (s *BundleService) GetBundles(ctx context.Context, tenantID *uuid.UUID) ([]*models.PolicyBundle, error) {
 	var bundles []*models.PolicyBundle
-	query := s.db.WithContext(ctx)
+	query := readReplica(s.db).WithContext(ctx)
 
 	if tenantID != nil {
 		query = query.Where("tenant_id = ? OR is_global = ?", *tenantID, true)
@@ -275,6 +566,33 @@ func (s *BundleService) GetBundles(ctx context.Context, tenantID *uuid.UUID) ([]
 	return bundles, nil
 }
 
+// BundleListOptions describes the sorting and filtering supported when listing bundles
+var BundleListOptions = pagination.Options{
+	SortFields: map[string]string{
+		"createdAt": "created_at",
+		"updatedAt": "updated_at",
+		"name":      "name",
+		"version":   "version",
+	},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// ListBundles retrieves a page of bundles, optionally filtered by tenant.
+// Global bundles are included for every tenant.
+func (s *BundleService) ListBundles(ctx context.Context, tenantID *uuid.UUID, params *pagination.Params) ([]models.PolicyBundle, *pagination.Page, error) {
+	query := readReplica(s.db).Model(&models.PolicyBundle{})
+	if tenantID != nil {
+		query = query.Where("tenant_id = ? OR is_global = ?", *tenantID, true)
+	}
+
+	bundles, page, err := pagination.Paginate[models.PolicyBundle](ctx, query, params, BundleListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list bundles: %w", err)
+	}
+	return bundles, page, nil
+}
+
 // ActivateBundle activates a bundle (sets it as the active bundle)
 func (s *BundleService) ActivateBundle(ctx context.Context, bundleID, userID uuid.UUID) (*models.PolicyBundle, error) {
 	bundle, err := s.GetBundle(ctx, bundleID)
@@ -283,7 +601,7 @@ func (s *BundleService) ActivateBundle(ctx context.Context, bundleID, userID uui
 	}
 
 	if bundle.Status != models.BundleStatusReady {
-		return nil, fmt.Errorf("bundle must be in ready status to activate")
+		return nil, apperrors.Conflict("BUNDLE_NOT_READY", "Bundle must be in ready status to activate")
 	}
 
 	// Deactivate other active bundles for the same tenant
@@ -314,7 +632,7 @@ func (s *BundleService) DeployBundle(ctx context.Context, bundleID, userID uuid.
 	}
 
 	if bundle.Status != models.BundleStatusActive && bundle.Status != models.BundleStatusReady {
-		return nil, fmt.Errorf("bundle must be ready or active to deploy")
+		return nil, apperrors.Conflict("BUNDLE_NOT_READY", "Bundle must be ready or active to deploy")
 	}
 
 	deployment := &models.BundleDeployment{
@@ -324,6 +642,7 @@ func (s *BundleService) DeployBundle(ctx context.Context, bundleID, userID uuid.
 		Status:      "success",
 		DeployedAt:  time.Now(),
 	}
+	recordManifest(deployment, bundle)
 
 	if err := s.db.WithContext(ctx).Create(deployment).Error; err != nil {
 		return nil, fmt.Errorf("failed to create deployment: %w", err)
@@ -347,7 +666,8 @@ func (s *BundleService) RollbackBundle(ctx context.Context, bundleID, targetBund
 	}
 
 	// Activate target bundle
-	if _, err := s.ActivateBundle(ctx, targetBundleID, userID); err != nil {
+	target, err := s.ActivateBundle(ctx, targetBundleID, userID)
+	if err != nil {
 		return fmt.Errorf("failed to activate target bundle: %w", err)
 	}
 
@@ -359,6 +679,7 @@ func (s *BundleService) RollbackBundle(ctx context.Context, bundleID, targetBund
 		DeployedAt:     time.Now(),
 		RollbackReason: reason,
 	}
+	recordManifest(deployment, target)
 
 	if err := s.db.WithContext(ctx).Create(deployment).Error; err != nil {
 		return fmt.Errorf("failed to create rollback deployment: %w", err)
@@ -367,10 +688,33 @@ func (s *BundleService) RollbackBundle(ctx context.Context, bundleID, targetBund
 	return nil
 }
 
+// recordManifest snapshots the manifest of the bundle a deployment ships, so
+// the deployment reports what OPA should be serving even after rebuilds
+func recordManifest(deployment *models.BundleDeployment, bundle *models.PolicyBundle) {
+	if len(bundle.Manifest) == 0 {
+		return
+	}
+	var manifest models.BundleManifest
+	if err := json.Unmarshal(bundle.Manifest, &manifest); err != nil {
+		log.Printf("Failed to parse manifest of bundle %s: %v", bundle.ID, err)
+		return
+	}
+	deployment.Revision = manifest.Revision
+	deployment.Manifest = bundle.Manifest
+}
+
 // GetBundleDeployments retrieves deployment history for a bundle
 func (s *BundleService) GetBundleDeployments(ctx context.Context, bundleID uuid.UUID) ([]*models.BundleDeployment, error) {
+	var count int64
+	if err := readReplica(s.db).WithContext(ctx).Model(&models.PolicyBundle{}).Where("id = ?", bundleID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bundle: %w", err)
+	}
+	if count == 0 {
+		return nil, apperrors.NotFound("BUNDLE_NOT_FOUND", "Bundle not found")
+	}
+
 	var deployments []*models.BundleDeployment
-	if err := s.db.WithContext(ctx).
+	if err := readReplica(s.db).WithContext(ctx).
 		Where("bundle_id = ?", bundleID).
 		Order("deployed_at DESC").
 		Find(&deployments).Error; err != nil {
@@ -380,23 +724,231 @@ func (s *BundleService) GetBundleDeployments(ctx context.Context, bundleID uuid.
 	return deployments, nil
 }
 
-// DownloadBundle downloads a bundle from MinIO
-func (s *BundleService) DownloadBundle(ctx context.Context, bundleID uuid.UUID) (io.Reader, error) {
+// Bounds of the lifetime of presigned bundle download URLs
+const (
+	DefaultBundleDownloadURLExpiry = 15 * time.Minute
+	MinBundleDownloadURLExpiry     = time.Minute
+	MaxBundleDownloadURLExpiry     = 7 * 24 * time.Hour // Longest lifetime S3 accepts
+)
+
+// BundleDownloadURLResponse is a presigned URL downloading a bundle directly from storage
+type BundleDownloadURLResponse struct {
+	URL       string    `json:"url" example:"https://heimdall-bundles.s3.us-east-1.amazonaws.com/bundles/heimdall-1.0.0.tar.gz?X-Amz-Signature=..."`
+	ExpiresAt time.Time `json:"expiresAt" example:"2024-01-20T08:15:00Z"`
+	Size      int64     `json:"size" example:"2048"`
+	Checksum  string    `json:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"` // SHA-256 of the bundle
+}
+
+// DownloadBundle opens a built bundle in bundle storage. The caller closes the returned object.
+func (s *BundleService) DownloadBundle(ctx context.Context, bundleID uuid.UUID) (*models.PolicyBundle, io.ReadSeekCloser, error) {
+	bundle, err := s.builtBundle(ctx, bundleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := s.store.Get(ctx, bundle.StoragePath)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, nil, apperrors.NotFound("BUNDLE_FILE_NOT_FOUND", "Bundle file not found in storage")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download bundle: %w", err)
+	}
+
+	return bundle, object, nil
+}
+
+// BundleDownloadURL returns a time-limited presigned URL downloading a built
+// bundle directly from storage, so large bundles are not proxied by the API
+func (s *BundleService) BundleDownloadURL(ctx context.Context, bundleID uuid.UUID, expiry time.Duration) (*BundleDownloadURLResponse, error) {
+	if expiry < MinBundleDownloadURLExpiry || expiry > MaxBundleDownloadURLExpiry {
+		return nil, apperrors.Validation("INVALID_EXPIRY", fmt.Sprintf("Expiry must be between %s and %s", MinBundleDownloadURLExpiry, MaxBundleDownloadURLExpiry))
+	}
+
+	bundle, err := s.builtBundle(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	url, err := s.store.PresignGet(ctx, bundle.StoragePath, expiry)
+	if errors.Is(err, storage.ErrPresignNotSupported) {
+		return nil, apperrors.Conflict("PRESIGNED_URLS_NOT_SUPPORTED", "Bundle storage does not support presigned URLs; use the download endpoint instead")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign bundle download: %w", err)
+	}
+
+	return &BundleDownloadURLResponse{
+		URL:       url,
+		ExpiresAt: expiresAt.UTC(),
+		Size:      bundle.Size,
+		Checksum:  bundle.Checksum,
+	}, nil
+}
+
+// builtBundle returns a bundle that has been uploaded to bundle storage
+func (s *BundleService) builtBundle(ctx context.Context, bundleID uuid.UUID) (*models.PolicyBundle, error) {
 	bundle, err := s.GetBundle(ctx, bundleID)
 	if err != nil {
 		return nil, err
 	}
 
 	if bundle.StoragePath == "" {
-		return nil, fmt.Errorf("bundle has not been built yet")
+		return nil, apperrors.Conflict("BUNDLE_NOT_BUILT", "Bundle has not been built yet")
+	}
+	return bundle, nil
+}
+
+// maxBundleFileSize bounds how much of a single bundle file is read into memory
+const maxBundleFileSize = 10 << 20
+
+// BundleFile describes a file inside a built bundle
+type BundleFile struct {
+	Path       string  `json:"path" example:"tenants/acme/authz/users.rego"`
+	Size       int64   `json:"size" example:"512"`
+	Checksum   string  `json:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"` // SHA-256 of the file
+	PolicyID   *string `json:"policyId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`                // Policy the file was built from
+	PolicyName string  `json:"policyName,omitempty" example:"User access"`
+}
+
+// BundleContentsResponse lists the files inside a built bundle
+type BundleContentsResponse struct {
+	BundleID string        `json:"bundleId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Version  string        `json:"version" example:"1.0.0"`
+	Checksum string        `json:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"` // SHA-256 of the tar.gz
+	Files    []*BundleFile `json:"files"`
+}
+
+// ListBundleContents lists the files inside a built bundle as it was uploaded
+// to storage, so operators can verify exactly what was shipped
+func (s *BundleService) ListBundleContents(ctx context.Context, bundleID uuid.UUID) (*BundleContentsResponse, error) {
+	bundle, object, err := s.DownloadBundle(ctx, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	files, err := s.bundlePolicyFiles(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &BundleContentsResponse{
+		BundleID: bundle.ID.String(),
+		Version:  bundle.Version,
+		Checksum: bundle.Checksum,
+		Files:    []*BundleFile{},
+	}
+	err = walkBundle(object, func(header *tar.Header, content io.Reader) (bool, error) {
+		hash := sha256.New()
+		size, err := io.Copy(hash, content)
+		if err != nil {
+			return false, err
+		}
+		file := &BundleFile{Path: header.Name, Size: size, Checksum: hex.EncodeToString(hash.Sum(nil))}
+		describeBundleFile(files, file)
+		contents.Files = append(contents.Files, file)
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	return contents, nil
+}
+
+// GetBundleFile returns a file inside a built bundle
+func (s *BundleService) GetBundleFile(ctx context.Context, bundleID uuid.UUID, path string) (*BundleFile, []byte, error) {
+	bundle, object, err := s.DownloadBundle(ctx, bundleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer object.Close()
+	files, err := s.bundlePolicyFiles(ctx, bundle)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	object, err := s.minioClient.GetObject(ctx, s.bucket, bundle.StoragePath, minio.GetObjectOptions{})
+	var file *BundleFile
+	var data []byte
+	err = walkBundle(object, func(header *tar.Header, content io.Reader) (bool, error) {
+		if header.Name != path {
+			return true, nil
+		}
+		if header.Size > maxBundleFileSize {
+			return false, apperrors.Validation("BUNDLE_FILE_TOO_LARGE", "Bundle file is too large to inspect; download the bundle instead")
+		}
+		data, err = io.ReadAll(io.LimitReader(content, maxBundleFileSize))
+		if err != nil {
+			return false, err
+		}
+		hash := sha256.Sum256(data)
+		file = &BundleFile{Path: header.Name, Size: int64(len(data)), Checksum: hex.EncodeToString(hash[:])}
+		describeBundleFile(files, file)
+		return false, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to download bundle: %w", err)
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
 	}
+	if file == nil {
+		return nil, nil, apperrors.NotFound("BUNDLE_FILE_NOT_FOUND", "File not found in bundle")
+	}
+
+	return file, data, nil
+}
 
-	return object, nil
+// walkBundle calls visit with each regular file of a tar.gz bundle until it returns false
+func walkBundle(r io.Reader, visit func(header *tar.Header, content io.Reader) (bool, error)) error {
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		next, err := visit(header, tarReader)
+		if err != nil || !next {
+			return err
+		}
+	}
+}
+
+// bundlePolicyFiles returns the policies of a bundle by the path of their file in it
+func (s *BundleService) bundlePolicyFiles(ctx context.Context, bundle *models.PolicyBundle) (map[string]*models.Policy, error) {
+	slugs, err := tenantSlugs(ctx, s.db, bundle.Policies)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*models.Policy, len(bundle.Policies))
+	for i := range bundle.Policies {
+		policy := &bundle.Policies[i]
+		namespaced := NamespacedPolicy{Policy: policy, Slug: slugs[policy.TenantID]}
+		files[namespaced.File()] = policy
+	}
+	return files, nil
+}
+
+// describeBundleFile links a bundle file to the policy it was built from
+func describeBundleFile(files map[string]*models.Policy, file *BundleFile) {
+	if policy, ok := files[file.Path]; ok {
+		policyID := policy.ID.String()
+		file.PolicyID = &policyID
+		file.PolicyName = policy.Name
+	}
 }
 
 // DeleteBundle soft deletes a bundle
@@ -407,7 +959,7 @@ func (s *BundleService) DeleteBundle(ctx context.Context, bundleID uuid.UUID) er
 	}
 
 	if bundle.Status == models.BundleStatusActive {
-		return fmt.Errorf("cannot delete active bundle")
+		return apperrors.Conflict("BUNDLE_ACTIVE", "Cannot delete active bundle")
 	}
 
 	if err := s.db.WithContext(ctx).Delete(bundle).Error; err != nil {
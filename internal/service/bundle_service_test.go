@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/storage"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestBundleContents(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		policy := &models.Policy{
+			TenantID:  tenant.ID,
+			Name:      "User access",
+			Path:      "authz/users",
+			Content:   "package authz.users\n\ndefault allow := false\n",
+			CreatedBy: author.ID,
+		}
+		if err := db.Create(policy).Error; err != nil {
+			t.Fatalf("Failed to create policy: %v", err)
+		}
+
+		store, err := storage.NewLocalStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		service := NewBundleService(db, store, NewJobQueue(db, nil))
+		bundle := &models.PolicyBundle{TenantID: tenant.ID, Name: "release", Version: "1.0.0", CreatedBy: author.ID, UpdatedBy: author.ID}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		if err := db.Create(&models.BundlePolicy{BundleID: bundle.ID, PolicyID: policy.ID, AddedBy: author.ID}).Error; err != nil {
+			t.Fatalf("Failed to add policy to bundle: %v", err)
+		}
+
+		if _, err := service.ListBundleContents(ctx, bundle.ID); !isAppError(err, "BUNDLE_NOT_BUILT") {
+			t.Errorf("Expected BUNDLE_NOT_BUILT before the build, got %v", err)
+		}
+
+		service.buildBundle(ctx, bundle.ID, author.ID)
+
+		contents, err := service.ListBundleContents(ctx, bundle.ID)
+		if err != nil {
+			t.Fatalf("ListBundleContents failed: %v", err)
+		}
+		if contents.Version != "1.0.0" || len(contents.Files) != 2 {
+			t.Fatalf("Expected the policy and the manifest, got %+v", contents)
+		}
+		// Policies are shipped in their tenant's namespace
+		namespaced := "package tenants.acme.authz.users\n\ndefault allow := false\n"
+		hash := sha256.Sum256([]byte(namespaced))
+		file := contents.Files[0]
+		if file.Path != "tenants/acme/authz/users.rego" || file.Size != int64(len(namespaced)) || file.Checksum != hex.EncodeToString(hash[:]) ||
+			file.PolicyID == nil || *file.PolicyID != policy.ID.String() || file.PolicyName != "User access" {
+			t.Errorf("Unexpected policy file: %+v", file)
+		}
+		if manifest := contents.Files[1]; manifest.Path != ".manifest" || manifest.PolicyID != nil {
+			t.Errorf("Unexpected manifest file: %+v", manifest)
+		}
+
+		file, data, err := service.GetBundleFile(ctx, bundle.ID, "tenants/acme/authz/users.rego")
+		if err != nil {
+			t.Fatalf("GetBundleFile failed: %v", err)
+		}
+		if string(data) != namespaced || file.Checksum != contents.Files[0].Checksum {
+			t.Errorf("Unexpected file %+v: %q", file, data)
+		}
+
+		if _, _, err := service.GetBundleFile(ctx, bundle.ID, "tenants/acme/authz/missing.rego"); !isAppError(err, "BUNDLE_FILE_NOT_FOUND") {
+			t.Errorf("Expected BUNDLE_FILE_NOT_FOUND, got %v", err)
+		}
+		if _, err := service.ListBundleContents(ctx, uuid.New()); !isAppError(err, "BUNDLE_NOT_FOUND") {
+			t.Errorf("Expected BUNDLE_NOT_FOUND, got %v", err)
+		}
+	})
+}
+
+func TestBundleDataDocuments(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		if err := db.Model(tenant).Update("settings", datatypes.JSON(`{"region":"eu","ipAccess":{"allowlist":["10.0.0.0/8"]}}`)).Error; err != nil {
+			t.Fatalf("Failed to update tenant settings: %v", err)
+		}
+		policy := &models.Policy{
+			TenantID:  tenant.ID,
+			Name:      "User access",
+			Path:      "authz/users",
+			Content:   "package authz.users\n",
+			CreatedBy: author.ID,
+		}
+		if err := db.Create(policy).Error; err != nil {
+			t.Fatalf("Failed to create policy: %v", err)
+		}
+
+		store, err := storage.NewLocalStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		service := NewBundleService(db, store, NewJobQueue(db, nil))
+		bundle := &models.PolicyBundle{TenantID: tenant.ID, Name: "offline", Version: "2.0.0", IncludeData: true, CreatedBy: author.ID, UpdatedBy: author.ID}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		if err := db.Create(&models.BundlePolicy{BundleID: bundle.ID, PolicyID: policy.ID, AddedBy: author.ID}).Error; err != nil {
+			t.Fatalf("Failed to add policy to bundle: %v", err)
+		}
+		service.buildBundle(ctx, bundle.ID, author.ID)
+
+		contents, err := service.ListBundleContents(ctx, bundle.ID)
+		if err != nil {
+			t.Fatalf("ListBundleContents failed: %v", err)
+		}
+		dataPath := "heimdall/tenants/" + tenant.ID.String() + "/data.json"
+		var paths []string
+		for _, file := range contents.Files {
+			paths = append(paths, file.Path)
+		}
+		sort.Strings(paths)
+		if want := []string{".manifest", dataPath, "tenants/acme/authz/users.rego"}; !equalStrings(paths, want) {
+			t.Fatalf("Expected files %v, got %v", want, paths)
+		}
+
+		// Only the bundle's tenant is shipped
+		_, content, err := service.GetBundleFile(ctx, bundle.ID, dataPath)
+		if err != nil {
+			t.Fatalf("GetBundleFile failed: %v", err)
+		}
+		var document BundleTenantData
+		if err := json.Unmarshal(content, &document); err != nil {
+			t.Fatalf("Failed to parse data document: %v", err)
+		}
+		if document.Settings["region"] != "eu" || !equalStrings(document.IPAccess.Allowlist, []string{"10.0.0.0/8"}) ||
+			document.IPAccess.Denylist == nil || document.Roles == nil || document.Users == nil {
+			t.Errorf("Unexpected data document: %+v", document)
+		}
+		if strings.Contains(strings.Join(paths, ","), other.ID.String()) {
+			t.Errorf("Expected no data of other tenants, got %v", paths)
+		}
+
+		// The manifest claims the policy and data roots
+		_, content, err = service.GetBundleFile(ctx, bundle.ID, ".manifest")
+		if err != nil {
+			t.Fatalf("GetBundleFile failed: %v", err)
+		}
+		var manifest models.BundleManifest
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			t.Fatalf("Failed to parse manifest: %v", err)
+		}
+		if want := []string{"heimdall/tenants/" + tenant.ID.String(), "tenants/acme/authz/users"}; !equalStrings(manifest.Roots, want) {
+			t.Errorf("Expected roots %v, got %+v", want, manifest)
+		}
+	})
+}
+
+func TestBundleManifest(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		for _, policy := range []*models.Policy{
+			{TenantID: tenant.ID, Name: "Users", Path: "policies/users", Content: "# User access\npackage acme.authz.users\n", CreatedBy: author.ID},
+			{TenantID: tenant.ID, Name: "Roles", Path: "policies/roles", Content: "package acme.authz\n", CreatedBy: author.ID},
+			{TenantID: tenant.ID, Name: "Limits", Path: "wasm/limits", Type: models.PolicyTypeWasm, Content: "AGFzbQEAAAA=", CreatedBy: author.ID},
+		} {
+			if err := db.Create(policy).Error; err != nil {
+				t.Fatalf("Failed to create policy: %v", err)
+			}
+		}
+		var policyIDs []uuid.UUID
+		db.Model(&models.Policy{}).Pluck("id", &policyIDs)
+
+		store, err := storage.NewLocalStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		service := NewBundleService(db, store, NewJobQueue(db, nil))
+		build := func(version string) (*models.PolicyBundle, *models.BundleManifest) {
+			bundle := &models.PolicyBundle{TenantID: tenant.ID, Name: "release", Version: version, CreatedBy: author.ID, UpdatedBy: author.ID}
+			if err := db.Create(bundle).Error; err != nil {
+				t.Fatalf("Failed to create bundle: %v", err)
+			}
+			if err := service.setBundlePolicies(ctx, db, bundle.ID, policyIDs, author.ID); err != nil {
+				t.Fatalf("Failed to add policies to bundle: %v", err)
+			}
+			service.buildBundle(ctx, bundle.ID, author.ID)
+
+			built, err := service.GetBundle(ctx, bundle.ID)
+			if err != nil {
+				t.Fatalf("GetBundle failed: %v", err)
+			}
+			_, content, err := service.GetBundleFile(ctx, bundle.ID, ".manifest")
+			if err != nil {
+				t.Fatalf("GetBundleFile failed: %v", err)
+			}
+			var shipped, stored models.BundleManifest
+			if err := json.Unmarshal(content, &shipped); err != nil {
+				t.Fatalf("Failed to parse manifest: %v", err)
+			}
+			if err := json.Unmarshal(built.Manifest, &stored); err != nil {
+				t.Fatalf("Failed to parse stored manifest: %v", err)
+			}
+			if shipped.Revision != stored.Revision || !equalStrings(shipped.Roots, stored.Roots) {
+				t.Errorf("Expected the stored manifest %+v to match the shipped one %+v", stored, shipped)
+			}
+			return built, &shipped
+		}
+
+		// Roots come from the policies' packages in the tenant's namespace, or
+		// the paths of WebAssembly policies
+		bundle, manifest := build("1.0.0")
+		if want := []string{"tenants/acme/acme/authz", "tenants/acme/wasm/limits"}; !equalStrings(manifest.Roots, want) {
+			t.Errorf("Expected roots %v, got %v", want, manifest.Roots)
+		}
+		if len(manifest.Revision) != 64 || manifest.Revision == bundle.Checksum {
+			t.Errorf("Expected a SHA-256 revision of the bundle contents, got %q", manifest.Revision)
+		}
+		// The revision only changes with the contents
+		if _, again := build("1.0.1"); again.Revision != manifest.Revision {
+			t.Errorf("Expected equal contents to give the revision %s, got %s", manifest.Revision, again.Revision)
+		}
+
+		deployment, err := service.DeployBundle(ctx, bundle.ID, author.ID, "staging")
+		if err != nil {
+			t.Fatalf("DeployBundle failed: %v", err)
+		}
+		if deployment.Revision != manifest.Revision {
+			t.Errorf("Expected the deployment to record revision %s, got %s", manifest.Revision, deployment.Revision)
+		}
+		deployments, err := service.GetBundleDeployments(ctx, bundle.ID)
+		if err != nil {
+			t.Fatalf("GetBundleDeployments failed: %v", err)
+		}
+		var deployed models.BundleManifest
+		if len(deployments) != 1 || json.Unmarshal(deployments[0].Manifest, &deployed) != nil || deployed.Revision != manifest.Revision {
+			t.Errorf("Expected the deployment with its manifest, got %+v", deployments)
+		}
+		if _, err := service.GetBundleDeployments(ctx, uuid.New()); !isAppError(err, "BUNDLE_NOT_FOUND") {
+			t.Errorf("Expected BUNDLE_NOT_FOUND, got %v", err)
+		}
+	})
+}
+
+func TestBundleRoots(t *testing.T) {
+	roots := bundleRoots([]string{"heimdall/tenants/a", "heimdall", "acme/authz", "acme/authz/users", "acme/authzx"})
+	if want := []string{"acme/authz", "acme/authzx", "heimdall"}; !equalStrings(roots, want) {
+		t.Errorf("Expected roots %v, got %v", want, roots)
+	}
+}
+
+func TestBundleSelectors(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		createPolicy := func(tenant *models.Tenant, path, tags string, status models.PolicyStatus) *models.Policy {
+			policy := &models.Policy{
+				TenantID:  tenant.ID,
+				Name:      path,
+				Path:      path,
+				Content:   "package " + strings.ReplaceAll(path, "/", "."),
+				Tags:      datatypes.JSON(tags),
+				Status:    status,
+				IsValid:   true,
+				CreatedBy: author.ID,
+			}
+			if err := db.Create(policy).Error; err != nil {
+				t.Fatalf("Failed to create policy: %v", err)
+			}
+			return policy
+		}
+		users := createPolicy(tenant, "authz/users", `["authz","core"]`, models.PolicyStatusActive)
+		createPolicy(tenant, "authz/roles", `["authz"]`, models.PolicyStatusActive)
+		createPolicy(tenant, "authz/drafts", `["authz"]`, models.PolicyStatusDraft)
+		createPolicy(tenant, "billing/invoices", `["authz"]`, models.PolicyStatusActive)
+		createPolicy(other, "authz/other", `["authz"]`, models.PolicyStatusActive)
+		pending := createPolicy(tenant, "authz/pending", `["authz"]`, models.PolicyStatusDraft)
+		untagged := createPolicy(tenant, "authz/untagged", `[]`, models.PolicyStatusDraft)
+
+		store, err := storage.NewLocalStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		// Rebuilds run as jobs, picked up by the queue's workers
+		jobs := NewJobQueue(db, nil)
+		workers, stop := context.WithCancel(ctx)
+		defer stop()
+		go jobs.Run(workers)
+		bundles := NewBundleService(db, store, jobs)
+		policies := NewPolicyService(db, nil)
+		policies.SetBundleRebuilder(bundles)
+
+		tenantID := tenant.ID
+		if _, err := bundles.CreateBundle(ctx, author.ID, &CreateBundleRequest{
+			TenantID: &tenantID, Name: "fixed", Version: "0.1.0", PolicyIDs: []uuid.UUID{users.ID}, AutoRebuild: true,
+		}); !isAppError(err, "AUTO_REBUILD_REQUIRES_SELECTOR") {
+			t.Errorf("Expected AUTO_REBUILD_REQUIRES_SELECTOR, got %v", err)
+		}
+		if _, err := bundles.CreateBundle(ctx, author.ID, &CreateBundleRequest{
+			TenantID: &tenantID, Name: "everything", Version: "0.2.0", Selector: &models.BundleSelector{PathPrefix: "*"},
+		}); !isAppError(err, "INVALID_SELECTOR") {
+			t.Errorf("Expected INVALID_SELECTOR, got %v", err)
+		}
+
+		bundle := &models.PolicyBundle{
+			TenantID: tenant.ID, Name: "authz", Version: "1.0.0", Status: models.BundleStatusBuilding,
+			Selector: datatypes.JSON(`{"tags":["authz"],"pathPrefix":"authz/*"}`), AutoRebuild: true,
+			CreatedBy: author.ID, UpdatedBy: author.ID,
+		}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		bundles.buildBundle(ctx, bundle.ID, author.ID)
+
+		bundlePaths := func() []string {
+			built, err := bundles.GetBundle(ctx, bundle.ID)
+			if err != nil {
+				t.Fatalf("GetBundle failed: %v", err)
+			}
+			var paths []string
+			for _, policy := range built.Policies {
+				paths = append(paths, policy.Path)
+			}
+			sort.Strings(paths)
+			return paths
+		}
+		if paths := bundlePaths(); !equalStrings(paths, []string{"authz/roles", "authz/users"}) {
+			t.Fatalf("Expected the active authz policies of the tenant, got %v", paths)
+		}
+		if _, err := bundles.ActivateBundle(ctx, bundle.ID, author.ID); err != nil {
+			t.Fatalf("ActivateBundle failed: %v", err)
+		}
+
+		// Publishing a policy the selector does not match leaves the bundle alone
+		if _, err := policies.PublishPolicy(ctx, untagged.ID, author.ID); err != nil {
+			t.Fatalf("PublishPolicy failed: %v", err)
+		}
+		// Publishing a matching policy rebuilds it, keeping it active
+		if _, err := policies.PublishPolicy(ctx, pending.ID, author.ID); err != nil {
+			t.Fatalf("PublishPolicy failed: %v", err)
+		}
+		want := []string{"authz/pending", "authz/roles", "authz/users"}
+		deadline := time.Now().Add(5 * time.Second)
+		for !equalStrings(bundlePaths(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected the bundle to be rebuilt with %v, got %v", want, bundlePaths())
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		deadline = time.Now().Add(5 * time.Second)
+		for {
+			contents, err := bundles.ListBundleContents(ctx, bundle.ID)
+			if err == nil && len(contents.Files) == 4 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected the rebuilt bundle to contain 3 policies and the manifest, got %+v, %v", contents, err)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		rebuilt, _ := bundles.GetBundle(ctx, bundle.ID)
+		if rebuilt.Status != models.BundleStatusActive {
+			t.Errorf("Expected the rebuilt bundle to stay active, got %s", rebuilt.Status)
+		}
+	})
+}
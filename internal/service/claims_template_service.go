@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultMaxTokenSize keeps tokens of tenants with a claims template within the
+// common 8 KB request header limit of proxies
+const defaultMaxTokenSize = 4096
+
+// Sources of custom claims
+const (
+	claimSourceUserID        = "user.id"
+	claimSourceUserEmail     = "user.email"
+	claimSourceUserMetadata  = "user.metadata."
+	claimSourceTenantID      = "tenant.id"
+	claimSourceTenantSlug    = "tenant.slug"
+	claimSourceTenantName    = "tenant.name"
+	claimSourceTenantSetting = "tenant.settings."
+)
+
+// ClaimsTemplateService manages tenant claims templates and resolves the claims
+// they embed in access tokens
+type ClaimsTemplateService struct {
+	db *gorm.DB
+}
+
+// NewClaimsTemplateService creates a new claims template service
+func NewClaimsTemplateService(db *gorm.DB) *ClaimsTemplateService {
+	return &ClaimsTemplateService{db: db}
+}
+
+// UpsertClaimsTemplateRequest represents the desired claims template of a tenant
+type UpsertClaimsTemplateRequest struct {
+	IncludeRoles       *bool             `json:"includeRoles,omitempty" example:"true"` // Defaults to true
+	RoleFilter         []string          `json:"roleFilter,omitempty" validate:"dive,required,max=100" example:"[\"admin\",\"billing_admin\"]"`
+	IncludePermissions bool              `json:"includePermissions" example:"true"`
+	TenantMetadata     []string          `json:"tenantMetadata,omitempty" validate:"dive,required,max=100" example:"[\"plan\"]"`
+	CustomClaims       map[string]string `json:"customClaims,omitempty" example:"{\"department\":\"user.metadata.department\"}"`
+	Audiences          []string          `json:"audiences,omitempty" validate:"max=20,dive,required,max=255" example:"[\"billing-service\"]"` // Accepted in addition to Heimdall's
+	MaxTokenSize       int               `json:"maxTokenSize,omitempty" validate:"omitempty,min=1024,max=65536" example:"4096"`               // Defaults to 4096
+}
+
+// ClaimsTemplateResponse represents a tenant's claims template
+type ClaimsTemplateResponse struct {
+	TenantID           string            `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IncludeRoles       bool              `json:"includeRoles" example:"true"`
+	RoleFilter         []string          `json:"roleFilter" example:"[\"admin\",\"billing_admin\"]"`
+	IncludePermissions bool              `json:"includePermissions" example:"true"`
+	TenantMetadata     []string          `json:"tenantMetadata" example:"[\"plan\"]"`
+	CustomClaims       map[string]string `json:"customClaims" example:"{\"department\":\"user.metadata.department\"}"`
+	Audiences          []string          `json:"audiences" example:"[\"billing-service\"]"`
+	MaxTokenSize       int               `json:"maxTokenSize" example:"4096"`
+	CreatedAt          string            `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt          string            `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+	ETag               string            `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// GetTemplate retrieves a tenant's claims template
+func (s *ClaimsTemplateService) GetTemplate(ctx context.Context, tenantID uuid.UUID) (*ClaimsTemplateResponse, error) {
+	template, err := s.findTemplate(readReplica(s.db).WithContext(ctx), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, apperrors.NotFound("CLAIMS_TEMPLATE_NOT_FOUND", "Claims template not found")
+	}
+	return toClaimsTemplateResponse(template)
+}
+
+// UpsertTemplate creates or replaces a tenant's claims template. It reports
+// whether the template was created.
+func (s *ClaimsTemplateService) UpsertTemplate(ctx context.Context, tenantID uuid.UUID, req *UpsertClaimsTemplateRequest, pre Precondition) (*ClaimsTemplateResponse, bool, error) {
+	for name, source := range req.CustomClaims {
+		if name == "" || auth.ReservedClaims[name] {
+			return nil, false, apperrors.Validation("RESERVED_CLAIM", "Custom claim name is empty or reserved").
+				WithDetails(map[string]interface{}{"claim": name})
+		}
+		if !validClaimSource(source) {
+			return nil, false, apperrors.Validation("INVALID_CLAIM_SOURCE", "Unknown custom claim source").
+				WithDetails(map[string]interface{}{"claim": name, "source": source})
+		}
+	}
+
+	roleFilter, err := json.Marshal(req.RoleFilter)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal role filter: %w", err)
+	}
+	tenantMetadata, err := json.Marshal(req.TenantMetadata)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal tenant metadata: %w", err)
+	}
+	customClaims, err := json.Marshal(req.CustomClaims)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal custom claims: %w", err)
+	}
+	audiences, err := json.Marshal(req.Audiences)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal audiences: %w", err)
+	}
+
+	var response *ClaimsTemplateResponse
+	created := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Select("id").First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		var template models.TenantClaimsTemplate
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, "tenant_id = ?", tenantID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get claims template: %w", err)
+		}
+		created = err != nil
+
+		current := ""
+		if !created {
+			current = ResourceETag(template.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+
+		template.TenantID = tenantID
+		template.IncludeRoles = req.IncludeRoles == nil || *req.IncludeRoles
+		template.RoleFilter = roleFilter
+		template.IncludePermissions = req.IncludePermissions
+		template.TenantMetadata = tenantMetadata
+		template.CustomClaims = customClaims
+		template.Audiences = audiences
+		template.MaxTokenSize = req.MaxTokenSize
+		if template.MaxTokenSize == 0 {
+			template.MaxTokenSize = defaultMaxTokenSize
+		}
+
+		if created {
+			err = tx.Create(&template).Error
+		} else {
+			err = tx.Save(&template).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save claims template: %w", err)
+		}
+		response, err = toClaimsTemplateResponse(&template)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, created, nil
+}
+
+// DeleteTemplate removes a tenant's claims template, restoring the default claims
+func (s *ClaimsTemplateService) DeleteTemplate(ctx context.Context, tenantID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&models.TenantClaimsTemplate{}, "tenant_id = ?", tenantID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete claims template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound("CLAIMS_TEMPLATE_NOT_FOUND", "Claims template not found")
+	}
+	return nil
+}
+
+// AccessTokenClaims resolves the claims of a user's access token under the tenant's
+// template. It returns nil if the tenant has no template.
+func (s *ClaimsTemplateService) AccessTokenClaims(userID, tenantID string, roles []string) (*auth.TemplateClaims, error) {
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	db := s.db.WithContext(ctx)
+	template, err := s.findTemplate(db, tenantUUID)
+	if err != nil || template == nil {
+		return nil, err
+	}
+	response, err := toClaimsTemplateResponse(template)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &auth.TemplateClaims{MaxSize: template.MaxTokenSize}
+	if template.IncludeRoles {
+		claims.Roles = filterRoles(roles, response.RoleFilter)
+	}
+
+	if template.IncludePermissions {
+		permissions, err := NewUserRepository(s.db).GetUserPermissions(ctx, userUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions: %w", err)
+		}
+		claims.Permissions = make([]string, 0, len(permissions))
+		for _, permission := range permissions {
+			claims.Permissions = append(claims.Permissions, permission.Name)
+		}
+		sort.Strings(claims.Permissions)
+	}
+
+	if len(response.TenantMetadata) == 0 && len(response.CustomClaims) == 0 {
+		return claims, nil
+	}
+
+	var tenant models.Tenant
+	if err := db.First(&tenant, "id = ?", tenantUUID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	settings := jsonObject(tenant.Settings)
+	for _, key := range response.TenantMetadata {
+		if value, ok := settings[key]; ok {
+			if claims.Tenant == nil {
+				claims.Tenant = make(map[string]interface{})
+			}
+			claims.Tenant[key] = value
+		}
+	}
+
+	if len(response.CustomClaims) == 0 {
+		return claims, nil
+	}
+	var user models.User
+	if err := db.First(&user, "id = ?", userUUID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	metadata := jsonObject(user.Metadata)
+	for name, source := range response.CustomClaims {
+		var value interface{}
+		switch {
+		case source == claimSourceUserID:
+			value = user.ID.String()
+		case source == claimSourceUserEmail:
+			value = user.Email
+		case strings.HasPrefix(source, claimSourceUserMetadata):
+			value = metadata[strings.TrimPrefix(source, claimSourceUserMetadata)]
+		case source == claimSourceTenantID:
+			value = tenant.ID.String()
+		case source == claimSourceTenantSlug:
+			value = tenant.Slug
+		case source == claimSourceTenantName:
+			value = tenant.Name
+		case strings.HasPrefix(source, claimSourceTenantSetting):
+			value = settings[strings.TrimPrefix(source, claimSourceTenantSetting)]
+		}
+		if value == nil {
+			continue
+		}
+		if claims.Custom == nil {
+			claims.Custom = make(map[string]interface{})
+		}
+		claims.Custom[name] = value
+	}
+
+	return claims, nil
+}
+
+// Audiences returns the audiences the tenant's template accepts in addition to
+// Heimdall's, or nil if the tenant has no template
+func (s *ClaimsTemplateService) Audiences(tenantID string) ([]string, error) {
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+	template, err := s.findTemplate(s.db.WithContext(context.Background()), tenantUUID)
+	if err != nil || template == nil {
+		return nil, err
+	}
+	response, err := toClaimsTemplateResponse(template)
+	if err != nil {
+		return nil, err
+	}
+	return response.Audiences, nil
+}
+
+// findTemplate returns a tenant's claims template, or nil if it has none
+func (s *ClaimsTemplateService) findTemplate(db *gorm.DB, tenantID uuid.UUID) (*models.TenantClaimsTemplate, error) {
+	var template models.TenantClaimsTemplate
+	if err := db.First(&template, "tenant_id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get claims template: %w", err)
+	}
+	return &template, nil
+}
+
+// validClaimSource reports whether a custom claim source is supported
+func validClaimSource(source string) bool {
+	switch source {
+	case claimSourceUserID, claimSourceUserEmail, claimSourceTenantID, claimSourceTenantSlug, claimSourceTenantName:
+		return true
+	}
+	for _, prefix := range []string{claimSourceUserMetadata, claimSourceTenantSetting} {
+		if strings.HasPrefix(source, prefix) && len(source) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRoles returns the roles in the filter, or all roles when the filter is empty
+func filterRoles(roles, filter []string) []string {
+	if len(filter) == 0 {
+		return roles
+	}
+	allowed := make(map[string]bool, len(filter))
+	for _, role := range filter {
+		allowed[role] = true
+	}
+	filtered := []string{}
+	for _, role := range roles {
+		if allowed[role] {
+			filtered = append(filtered, role)
+		}
+	}
+	return filtered
+}
+
+// jsonObject decodes a JSON object column, returning nil for other values
+func jsonObject(data []byte) map[string]interface{} {
+	var object map[string]interface{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &object)
+	}
+	return object
+}
+
+func toClaimsTemplateResponse(template *models.TenantClaimsTemplate) (*ClaimsTemplateResponse, error) {
+	response := &ClaimsTemplateResponse{
+		TenantID:           template.TenantID.String(),
+		IncludeRoles:       template.IncludeRoles,
+		RoleFilter:         []string{},
+		IncludePermissions: template.IncludePermissions,
+		TenantMetadata:     []string{},
+		CustomClaims:       map[string]string{},
+		Audiences:          []string{},
+		MaxTokenSize:       template.MaxTokenSize,
+		CreatedAt:          template.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          template.UpdatedAt.Format(time.RFC3339),
+		ETag:               ResourceETag(template.UpdatedAt),
+	}
+	for _, field := range []struct {
+		data   []byte
+		target interface{}
+		name   string
+	}{
+		{template.RoleFilter, &response.RoleFilter, "role filter"},
+		{template.TenantMetadata, &response.TenantMetadata, "tenant metadata"},
+		{template.CustomClaims, &response.CustomClaims, "custom claims"},
+		{template.Audiences, &response.Audiences, "audiences"},
+	} {
+		if len(field.data) == 0 || string(field.data) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(field.data, field.target); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", field.name, err)
+		}
+	}
+	return response, nil
+}
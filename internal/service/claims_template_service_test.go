@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestClaimsTemplateService_AccessTokenClaims(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		admin := testutil.CreateTestRole(t, db, tenant, "admin")
+		testutil.AssignPermissionToRole(t, db, admin, testutil.CreateTestPermission(t, db, "users.update", "users", "update"))
+		testutil.AssignPermissionToRole(t, db, admin, testutil.CreateTestPermission(t, db, "users.read", "users", "read"))
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		testutil.AssignRoleToUser(t, db, alice, admin)
+
+		service := NewClaimsTemplateService(db)
+
+		// Tenants without a template keep the default claims
+		claims, err := service.AccessTokenClaims(alice.ID.String(), tenant.ID.String(), []string{"admin", "user"})
+		if err != nil || claims != nil {
+			t.Fatalf("Expected no template claims, got %+v, %v", claims, err)
+		}
+
+		_, created, err := service.UpsertTemplate(ctx, tenant.ID, &UpsertClaimsTemplateRequest{
+			RoleFilter:         []string{"admin"},
+			IncludePermissions: true,
+			TenantMetadata:     []string{"test"},
+			CustomClaims: map[string]string{
+				"firstName":  "user.metadata.firstName",
+				"tenantSlug": "tenant.slug",
+				"missing":    "user.metadata.missing",
+			},
+			Audiences: []string{"billing-service"},
+		}, Precondition{})
+		if err != nil || !created {
+			t.Fatalf("Failed to create claims template: created=%v, err=%v", created, err)
+		}
+		if audiences, err := service.Audiences(tenant.ID.String()); err != nil || !reflect.DeepEqual(audiences, []string{"billing-service"}) {
+			t.Errorf("Expected the registered audiences, got %v, %v", audiences, err)
+		}
+
+		claims, err = service.AccessTokenClaims(alice.ID.String(), tenant.ID.String(), []string{"admin", "user"})
+		if err != nil {
+			t.Fatalf("Failed to resolve template claims: %v", err)
+		}
+		if !reflect.DeepEqual(claims.Roles, []string{"admin"}) {
+			t.Errorf("Expected filtered roles [admin], got %v", claims.Roles)
+		}
+		if !reflect.DeepEqual(claims.Permissions, []string{"users.read", "users.update"}) {
+			t.Errorf("Expected sorted permissions, got %v", claims.Permissions)
+		}
+		if !reflect.DeepEqual(claims.Tenant, map[string]interface{}{"test": true}) {
+			t.Errorf("Expected tenant metadata, got %v", claims.Tenant)
+		}
+		wantCustom := map[string]interface{}{"firstName": "Test", "tenantSlug": "acme"}
+		if !reflect.DeepEqual(claims.Custom, wantCustom) {
+			t.Errorf("Expected custom claims %v, got %v", wantCustom, claims.Custom)
+		}
+		if claims.MaxSize != defaultMaxTokenSize {
+			t.Errorf("Expected default max size %d, got %d", defaultMaxTokenSize, claims.MaxSize)
+		}
+	})
+}
+
+func TestClaimsTemplateService_UpsertValidation(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		service := NewClaimsTemplateService(db)
+
+		tests := []struct {
+			name     string
+			claims   map[string]string
+			wantCode string
+		}{
+			{"reserved claim", map[string]string{"roles": "user.email"}, "RESERVED_CLAIM"},
+			{"registered claim", map[string]string{"sub": "user.id"}, "RESERVED_CLAIM"},
+			{"unknown source", map[string]string{"department": "user.password"}, "INVALID_CLAIM_SOURCE"},
+			{"empty metadata key", map[string]string{"department": "user.metadata."}, "INVALID_CLAIM_SOURCE"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := service.UpsertTemplate(ctx, tenant.ID, &UpsertClaimsTemplateRequest{CustomClaims: tt.claims}, Precondition{})
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("Expected %s, got %v", tt.wantCode, err)
+				}
+			})
+		}
+
+		// Excluding roles is stored rather than replaced by the column default
+		excludeRoles := false
+		template, _, err := service.UpsertTemplate(ctx, tenant.ID, &UpsertClaimsTemplateRequest{IncludeRoles: &excludeRoles}, Precondition{})
+		if err != nil {
+			t.Fatalf("Failed to create claims template: %v", err)
+		}
+		if template, err = service.GetTemplate(ctx, tenant.ID); err != nil || template.IncludeRoles {
+			t.Errorf("Expected roles to be excluded, got %+v, %v", template, err)
+		}
+	})
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Cleanup tasks, reported by name in the cleanup stats
+const (
+	CleanupExpiredTokens = "expired_tokens" // Expired action nonces, device authorizations and password reset tokens
+	CleanupSoftDeleted   = "soft_deleted"   // Deleted policies, roles and bundles past retention
+	CleanupAuditLogs     = "audit_logs"     // Audit log entries past retention
+	CleanupBundleObjects = "bundle_objects" // Bundle storage objects no bundle references
+)
+
+const (
+	// cleanupBatchSize caps the rows deleted per statement, so a cleanup
+	// catching up on a large backlog does not hold long locks
+	cleanupBatchSize = 500
+
+	// auditArchivePrefix is where archived audit log entries are stored in
+	// bundle storage
+	auditArchivePrefix = "audit-archive/"
+
+	// bundleObjectPrefix is where built bundles are stored in bundle storage
+	bundleObjectPrefix = "bundles/"
+)
+
+// CleanupStats reports the rows or objects a cleanup task removed
+type CleanupStats struct {
+	LastRunAt    string `json:"lastRunAt,omitempty" example:"2024-01-15T10:30:00Z"`
+	LastCleaned  int64  `json:"lastCleaned" example:"42"` // Removed by the last run
+	TotalCleaned int64  `json:"totalCleaned" example:"1280"`
+	LastError    string `json:"lastError,omitempty"`
+}
+
+// Cleaner periodically purges expired and stale data: expired one-time tokens,
+// soft-deleted records past retention, old audit log entries, optionally
+// archived to bundle storage first, and bundle objects left behind by purged
+// bundles.
+//
+// The token blacklist and other short-lived keys live in Redis, or the
+// in-process store, which expire them on their own.
+type Cleaner struct {
+	db    *gorm.DB
+	store storage.ObjectStore // Nil when bundle storage is unavailable
+	cfg   *config.CleanupConfig
+
+	mu    sync.Mutex
+	stats map[string]*CleanupStats
+}
+
+// NewCleaner creates a new cleaner
+func NewCleaner(db *gorm.DB, store storage.ObjectStore, cfg *config.CleanupConfig) *Cleaner {
+	return &Cleaner{
+		db:    db,
+		store: store,
+		cfg:   cfg,
+		stats: make(map[string]*CleanupStats),
+	}
+}
+
+// Run cleans up every interval until ctx is cancelled
+func (c *Cleaner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Clean(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Clean runs every cleanup task and returns the rows or objects each removed.
+// A failing task does not stop the others.
+func (c *Cleaner) Clean(ctx context.Context) map[string]int64 {
+	tasks := []struct {
+		name string
+		run  func(ctx context.Context) (int64, error)
+	}{
+		{CleanupExpiredTokens, c.cleanExpiredTokens},
+		{CleanupSoftDeleted, c.cleanSoftDeleted},
+		{CleanupAuditLogs, c.cleanAuditLogs},
+		{CleanupBundleObjects, c.cleanBundleObjects},
+	}
+
+	cleaned := make(map[string]int64, len(tasks))
+	for _, task := range tasks {
+		count, err := task.run(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Cleanup of %s failed: %v", task.name, err)
+		}
+		if count > 0 {
+			log.Printf("Cleanup of %s removed %d", task.name, count)
+		}
+		c.record(task.name, count, err)
+		cleaned[task.name] = count
+	}
+	return cleaned
+}
+
+// Snapshot returns the stats of each cleanup task
+func (c *Cleaner) Snapshot() map[string]CleanupStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]CleanupStats, len(c.stats))
+	for name, stats := range c.stats {
+		snapshot[name] = *stats
+	}
+	return snapshot
+}
+
+func (c *Cleaner) record(task string, count int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats, ok := c.stats[task]
+	if !ok {
+		stats = &CleanupStats{}
+		c.stats[task] = stats
+	}
+	stats.LastRunAt = time.Now().UTC().Format(time.RFC3339)
+	stats.LastCleaned = count
+	stats.TotalCleaned += count
+	stats.LastError = ""
+	if err != nil {
+		stats.LastError = err.Error()
+	}
+}
+
+// cleanExpiredTokens deletes expired action nonces and device authorizations,
+// and clears expired password reset tokens
+func (c *Cleaner) cleanExpiredTokens(ctx context.Context) (int64, error) {
+	db := c.db.WithContext(ctx)
+	now := time.Now()
+
+	result := db.Where("expires_at < ?", now).Delete(&models.ActionNonce{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired action nonces: %w", result.Error)
+	}
+	cleaned := result.RowsAffected
+
+	result = db.Where("expires_at < ?", now).Delete(&models.DeviceAuthorization{})
+	if result.Error != nil {
+		return cleaned, fmt.Errorf("failed to delete expired device authorizations: %w", result.Error)
+	}
+	cleaned += result.RowsAffected
+
+	result = db.Model(&models.UserCredential{}).
+		Where("reset_token_expires_at < ?", now).
+		Updates(map[string]interface{}{"reset_token_hash": "", "reset_token_expires_at": nil})
+	if result.Error != nil {
+		return cleaned, fmt.Errorf("failed to clear expired password reset tokens: %w", result.Error)
+	}
+	return cleaned + result.RowsAffected, nil
+}
+
+// cleanSoftDeleted permanently deletes policies, roles and bundles deleted
+// longer ago than the retention period, along with the rows referencing them.
+// Roles still referenced by access requests are kept for their history, and
+// deactivated users are left to the user purger.
+func (c *Cleaner) cleanSoftDeleted(ctx context.Context) (int64, error) {
+	if c.cfg.SoftDeleteRetention <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-c.cfg.SoftDeleteRetention)
+	db := c.db.WithContext(ctx)
+
+	var cleaned int64
+	purge := func(model interface{}, query *gorm.DB, children func(tx *gorm.DB, ids []uuid.UUID) error) error {
+		for {
+			var ids []uuid.UUID
+			if err := query.Session(&gorm.Session{}).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := children(tx, ids); err != nil {
+					return err
+				}
+				result := tx.Unscoped().Where("id IN ?", ids).Delete(model)
+				cleaned += result.RowsAffected
+				return result.Error
+			})
+			if err != nil || len(ids) < cleanupBatchSize {
+				return err
+			}
+		}
+	}
+
+	deleted := func(model interface{}) *gorm.DB {
+		return db.Unscoped().Model(model).Where("deleted_at < ?", cutoff).Limit(cleanupBatchSize)
+	}
+
+	if err := purge(&models.PolicyBundle{}, deleted(&models.PolicyBundle{}), func(tx *gorm.DB, ids []uuid.UUID) error {
+		if err := tx.Where("bundle_id IN ?", ids).Delete(&models.BundlePolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("bundle_id IN ?", ids).Delete(&models.BundleDeployment{}).Error
+	}); err != nil {
+		return cleaned, fmt.Errorf("failed to purge deleted bundles: %w", err)
+	}
+
+	if err := purge(&models.Policy{}, deleted(&models.Policy{}), func(tx *gorm.DB, ids []uuid.UUID) error {
+		if err := tx.Where("policy_id IN ?", ids).Delete(&models.BundlePolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("policy_id IN ?", ids).Delete(&models.PolicyVersion{}).Error
+	}); err != nil {
+		return cleaned, fmt.Errorf("failed to purge deleted policies: %w", err)
+	}
+
+	roles := deleted(&models.Role{}).Where("id NOT IN (?)", db.Model(&models.AccessRequest{}).Select("role_id"))
+	if err := purge(&models.Role{}, roles, func(tx *gorm.DB, ids []uuid.UUID) error {
+		if err := tx.Unscoped().Model(&models.Role{}).Where("parent_role_id IN ?", ids).Update("parent_role_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id IN ?", ids).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("role_id IN ?", ids).Delete(&models.UserRole{}).Error
+	}); err != nil {
+		return cleaned, fmt.Errorf("failed to purge deleted roles: %w", err)
+	}
+
+	return cleaned, nil
+}
+
+// archivedAuditLog is an audit log entry as archived. Its tenant and user
+// relationships are not loaded, so they are left out.
+type archivedAuditLog struct {
+	models.AuditLog
+	Tenant *struct{} `json:"tenant,omitempty"`
+	User   *struct{} `json:"user,omitempty"`
+}
+
+// cleanAuditLogs deletes audit log entries older than the retention period,
+// oldest first. With archiving, each batch is first written to bundle storage
+// as gzipped JSON lines, and kept when the upload fails.
+func (c *Cleaner) cleanAuditLogs(ctx context.Context) (int64, error) {
+	if c.cfg.AuditLogRetention <= 0 {
+		return 0, nil
+	}
+	if c.cfg.AuditLogArchive && c.store == nil {
+		return 0, fmt.Errorf("audit log archiving needs bundle storage, which is unavailable")
+	}
+	cutoff := time.Now().Add(-c.cfg.AuditLogRetention)
+	db := c.db.WithContext(ctx)
+
+	var cleaned int64
+	for {
+		var entries []models.AuditLog
+		if err := db.Where("created_at < ?", cutoff).
+			Order("created_at ASC, id ASC").
+			Limit(cleanupBatchSize).
+			Find(&entries).Error; err != nil {
+			return cleaned, fmt.Errorf("failed to load old audit logs: %w", err)
+		}
+		if len(entries) == 0 {
+			return cleaned, nil
+		}
+
+		if c.cfg.AuditLogArchive {
+			if err := c.archiveAuditLogs(ctx, entries); err != nil {
+				return cleaned, err
+			}
+		}
+
+		ids := make([]uuid.UUID, len(entries))
+		for i := range entries {
+			ids[i] = entries[i].ID
+		}
+		result := db.Where("id IN ?", ids).Delete(&models.AuditLog{})
+		if result.Error != nil {
+			return cleaned, fmt.Errorf("failed to delete old audit logs: %w", result.Error)
+		}
+		cleaned += result.RowsAffected
+		if len(entries) < cleanupBatchSize {
+			return cleaned, nil
+		}
+	}
+}
+
+// archiveAuditLogs uploads a batch of audit log entries. The key is derived
+// from the batch's first entry, so instances archiving the same batch write
+// the same object.
+func (c *Cleaner) archiveAuditLogs(ctx context.Context, entries []models.AuditLog) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for i := range entries {
+		if err := encoder.Encode(archivedAuditLog{AuditLog: entries[i]}); err != nil {
+			return fmt.Errorf("failed to encode audit log: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress audit logs: %w", err)
+	}
+
+	first := entries[0]
+	key := fmt.Sprintf("%s%s/%s-%s.jsonl.gz", auditArchivePrefix,
+		first.CreatedAt.UTC().Format("2006-01-02"), first.CreatedAt.UTC().Format("150405"), first.ID)
+	if err := c.store.Put(ctx, key, buf.Bytes(), "application/gzip"); err != nil {
+		return fmt.Errorf("failed to archive audit logs: %w", err)
+	}
+	return nil
+}
+
+// cleanBundleObjects deletes built bundles in bundle storage that no bundle,
+// deleted or not, references any more. Objects younger than the grace period
+// are kept, as a build uploads its bundle before recording it.
+func (c *Cleaner) cleanBundleObjects(ctx context.Context) (int64, error) {
+	if c.store == nil {
+		return 0, nil
+	}
+
+	objects, err := c.store.List(ctx, bundleObjectPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list bundle objects: %w", err)
+	}
+
+	var paths []string
+	if err := c.db.WithContext(ctx).Unscoped().Model(&models.PolicyBundle{}).
+		Where("storage_path <> ''").
+		Pluck("storage_path", &paths).Error; err != nil {
+		return 0, fmt.Errorf("failed to load bundle paths: %w", err)
+	}
+	referenced := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		referenced[path] = true
+	}
+
+	cutoff := time.Now().Add(-c.cfg.OrphanGracePeriod)
+	var cleaned int64
+	for _, object := range objects {
+		if !strings.HasPrefix(object.Key, bundleObjectPrefix) || referenced[object.Key] || object.LastModified.After(cutoff) {
+			continue
+		}
+		if err := c.store.Delete(ctx, object.Key); err != nil {
+			return cleaned, fmt.Errorf("failed to delete bundle object %s: %w", object.Key, err)
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/storage"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestCleaner(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		longAgo := time.Now().Add(-60 * 24 * time.Hour)
+
+		create := func(value interface{}) {
+			t.Helper()
+			if err := db.Create(value).Error; err != nil {
+				t.Fatalf("Failed to create %T: %v", value, err)
+			}
+		}
+		softDelete := func(value interface{}, at time.Time) {
+			t.Helper()
+			if err := db.Delete(value).Error; err != nil {
+				t.Fatalf("Failed to delete %T: %v", value, err)
+			}
+			db.Unscoped().Model(value).Update("deleted_at", at)
+		}
+		exists := func(model interface{}, id uuid.UUID) bool {
+			var count int64
+			db.Unscoped().Model(model).Where("id = ?", id).Count(&count)
+			return count == 1
+		}
+
+		// Expired one-time tokens
+		create(&models.ActionNonce{TenantID: acme.ID, UserID: alice.ID, Action: "tenants.delete", NonceHash: "expired", ExpiresAt: time.Now().Add(-time.Minute)})
+		valid := &models.ActionNonce{TenantID: acme.ID, UserID: alice.ID, Action: "tenants.delete", NonceHash: "valid", ExpiresAt: time.Now().Add(time.Minute)}
+		create(valid)
+
+		// Policies and roles deleted past retention, with the rows referencing them
+		stale := &models.Policy{TenantID: acme.ID, Name: "stale", Path: "authz/stale", Content: "package authz.stale", CreatedBy: alice.ID}
+		recent := &models.Policy{TenantID: acme.ID, Name: "recent", Path: "authz/recent", Content: "package authz.recent", CreatedBy: alice.ID}
+		create(stale)
+		create(recent)
+		create(&models.PolicyVersion{PolicyID: stale.ID, Version: 1, Content: stale.Content, CreatedBy: alice.ID})
+		softDelete(stale, longAgo)
+		softDelete(recent, time.Now())
+
+		oldRole := testutil.CreateTestRole(t, db, acme, "old")
+		requestedRole := testutil.CreateTestRole(t, db, acme, "requested")
+		create(&models.UserRole{UserID: alice.ID, RoleID: oldRole.ID, AssignedBy: alice.ID})
+		create(&models.AccessRequest{TenantID: acme.ID, RequesterID: alice.ID, RoleID: requestedRole.ID, Justification: "on call", DurationMinutes: 60})
+		softDelete(oldRole, longAgo)
+		softDelete(requestedRole, longAgo)
+
+		// Audit logs past retention
+		oldLog := &models.AuditLog{TenantID: acme.ID, EventType: "login", Action: "login", Status: "success", CreatedAt: longAgo}
+		newLog := &models.AuditLog{TenantID: acme.ID, EventType: "login", Action: "login", Status: "success"}
+		create(oldLog)
+		create(newLog)
+
+		// Bundle objects, one of which no bundle references any more
+		dir := t.TempDir()
+		store, err := storage.NewLocalStore(dir)
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		for _, key := range []string{"bundles/heimdall-1.0.0.tar.gz", "bundles/heimdall-0.9.0.tar.gz", "bundles/heimdall-1.1.0.tar.gz"} {
+			if err := store.Put(ctx, key, []byte("bundle"), "application/gzip"); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+		}
+		create(&models.PolicyBundle{TenantID: acme.ID, Name: "release", Version: "1.0.0", StoragePath: "bundles/heimdall-1.0.0.tar.gz", CreatedBy: alice.ID, UpdatedBy: alice.ID})
+		for _, key := range []string{"bundles/heimdall-1.0.0.tar.gz", "bundles/heimdall-0.9.0.tar.gz"} {
+			if err := os.Chtimes(filepath.Join(dir, key), longAgo, longAgo); err != nil {
+				t.Fatalf("Failed to age %s: %v", key, err)
+			}
+		}
+
+		cleaner := NewCleaner(db, store, &config.CleanupConfig{
+			SoftDeleteRetention: 30 * 24 * time.Hour,
+			AuditLogRetention:   30 * 24 * time.Hour,
+			AuditLogArchive:     true,
+			OrphanGracePeriod:   time.Hour,
+		})
+		cleaned := cleaner.Clean(ctx)
+		want := map[string]int64{CleanupExpiredTokens: 1, CleanupSoftDeleted: 2, CleanupAuditLogs: 1, CleanupBundleObjects: 1}
+		for task, count := range want {
+			if cleaned[task] != count {
+				t.Errorf("Expected %s to remove %d, removed %d", task, count, cleaned[task])
+			}
+		}
+
+		if !exists(&models.ActionNonce{}, valid.ID) {
+			t.Error("Expected the valid nonce to be kept")
+		}
+		if exists(&models.Policy{}, stale.ID) || !exists(&models.Policy{}, recent.ID) {
+			t.Error("Expected only the policy deleted past retention to be purged")
+		}
+		var versions, assignments int64
+		db.Model(&models.PolicyVersion{}).Where("policy_id = ?", stale.ID).Count(&versions)
+		db.Model(&models.UserRole{}).Where("role_id = ?", oldRole.ID).Count(&assignments)
+		if versions != 0 || assignments != 0 {
+			t.Errorf("Expected the purged rows' references to be deleted, got %d versions and %d assignments", versions, assignments)
+		}
+		if exists(&models.Role{}, oldRole.ID) || !exists(&models.Role{}, requestedRole.ID) {
+			t.Error("Expected roles referenced by access requests to be kept")
+		}
+
+		if exists(&models.AuditLog{}, oldLog.ID) || !exists(&models.AuditLog{}, newLog.ID) {
+			t.Error("Expected only the audit log past retention to be deleted")
+		}
+		archives, err := store.List(ctx, "audit-archive/")
+		if err != nil || len(archives) != 1 {
+			t.Fatalf("Expected one audit log archive, got %+v, %v", archives, err)
+		}
+		object, err := store.Get(ctx, archives[0].Key)
+		if err != nil {
+			t.Fatalf("Failed to open archive: %v", err)
+		}
+		defer object.Close()
+		gz, err := gzip.NewReader(object)
+		if err != nil {
+			t.Fatalf("Failed to decompress archive: %v", err)
+		}
+		var lines []string
+		for scanner := bufio.NewScanner(gz); scanner.Scan(); {
+			lines = append(lines, scanner.Text())
+		}
+		var archived map[string]interface{}
+		if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &archived) != nil || archived["id"] != oldLog.ID.String() || archived["tenant"] != nil {
+			t.Errorf("Expected the archive to hold the old audit log, got %v", lines)
+		}
+
+		bundles, _ := store.List(ctx, "bundles/")
+		var keys []string
+		for _, object := range bundles {
+			keys = append(keys, object.Key)
+		}
+		if got := strings.Join(keys, ","); got != "bundles/heimdall-1.0.0.tar.gz,bundles/heimdall-1.1.0.tar.gz" {
+			t.Errorf("Expected the referenced and the recent bundle to be kept, got %s", got)
+		}
+
+		// Stats accumulate across runs
+		cleaner.Clean(ctx)
+		stats := cleaner.Snapshot()[CleanupSoftDeleted]
+		if stats.LastCleaned != 0 || stats.TotalCleaned != 2 || stats.LastRunAt == "" {
+			t.Errorf("Unexpected stats: %+v", stats)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// allowedOriginsSetting is the tenant settings key holding the CORS origins of
+// the tenant's browser applications
+const allowedOriginsSetting = "allowedOrigins"
+
+// CORSOriginService resolves the CORS origins tenants allow in their settings.
+// Browsers send no credentials with preflight requests, so an origin allowed by
+// any active tenant is allowed for all requests.
+type CORSOriginService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	origins  map[string]struct{}
+	loadedAt time.Time
+
+	refreshMu       sync.Mutex
+	refreshInterval time.Duration
+}
+
+// NewCORSOriginService creates a new CORS origin service
+func NewCORSOriginService(db *gorm.DB) *CORSOriginService {
+	return &CORSOriginService{
+		db:              db,
+		origins:         make(map[string]struct{}),
+		refreshInterval: time.Minute,
+	}
+}
+
+// OriginAllowed reports whether a tenant allows an origin. The origins of all
+// tenants are reloaded at most once a minute, so changes to tenant settings
+// apply within a minute.
+func (s *CORSOriginService) OriginAllowed(origin string) bool {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > s.refreshInterval
+	s.mu.RUnlock()
+	if stale {
+		// If the origins cannot be reloaded, the previous ones keep applying
+		_ = s.refresh(context.Background())
+	}
+
+	normalized, err := normalizeOrigin(origin)
+	if err != nil {
+		return false
+	}
+
+	s.mu.RLock()
+	_, ok := s.origins[normalized]
+	s.mu.RUnlock()
+	return ok
+}
+
+// refresh reloads the origins of all active tenants, unless another request
+// reloaded them meanwhile
+func (s *CORSOriginService) refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > s.refreshInterval
+	s.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+
+	var tenants []models.Tenant
+	err := readReplica(s.db).WithContext(ctx).
+		Select("id", "settings").
+		Where("status = ?", "active").
+		Find(&tenants).Error
+	if err != nil {
+		// Retry with the next refresh rather than on every request
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+		return fmt.Errorf("failed to load tenant origins: %w", err)
+	}
+
+	origins := make(map[string]struct{})
+	for i := range tenants {
+		settings, err := tenantSettings(&tenants[i])
+		if err != nil {
+			continue
+		}
+		entries, _ := settings[allowedOriginsSetting].([]interface{})
+		for _, entry := range entries {
+			value, _ := entry.(string)
+			if normalized, err := normalizeOrigin(value); err == nil {
+				origins[normalized] = struct{}{}
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.origins = origins
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// validateAllowedOriginsSetting checks the CORS origins of tenant settings
+func validateAllowedOriginsSetting(settings map[string]interface{}) error {
+	value, ok := settings[allowedOriginsSetting]
+	if !ok || value == nil {
+		return nil
+	}
+
+	entries, ok := value.([]interface{})
+	if !ok {
+		return apperrors.Validation("INVALID_ORIGIN", "The allowedOrigins setting must be an array of origins")
+	}
+	var invalid []interface{}
+	for _, entry := range entries {
+		origin, _ := entry.(string)
+		if _, err := normalizeOrigin(origin); err != nil {
+			invalid = append(invalid, entry)
+		}
+	}
+	if len(invalid) > 0 {
+		return apperrors.Validation("INVALID_ORIGIN", "Origins must be http or https URLs without a path, e.g. https://app.example.com").
+			WithDetails(map[string]interface{}{"origins": invalid})
+	}
+	return nil
+}
+
+// normalizeOrigin validates an origin and returns it in the lower-case form
+// browsers send in the Origin header
+func normalizeOrigin(origin string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+	if err != nil {
+		return "", err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+		return "", fmt.Errorf("invalid origin: %s", origin)
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host), nil
+}
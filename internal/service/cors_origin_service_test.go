@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestCORSOriginService_OriginAllowed(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		tenantService := NewTenantService(db)
+		service := NewCORSOriginService(db)
+
+		if service.OriginAllowed("https://app.acme.com") {
+			t.Fatal("Expected no origin to be allowed before tenants add any")
+		}
+
+		var appErr *apperrors.Error
+		_, err := tenantService.UpdateTenant(ctx, tenant.ID.String(), &UpdateTenantRequest{Settings: map[string]interface{}{
+			"allowedOrigins": []interface{}{"https://app.acme.com", "https://acme.com/login"},
+		}})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_ORIGIN" {
+			t.Fatalf("Expected INVALID_ORIGIN for an origin with a path, got %v", err)
+		}
+
+		_, err = tenantService.UpdateTenant(ctx, tenant.ID.String(), &UpdateTenantRequest{Settings: map[string]interface{}{
+			"allowedOrigins": []interface{}{"https://App.Acme.com/", "http://localhost:3000"},
+		}})
+		if err != nil {
+			t.Fatalf("Failed to update tenant origins: %v", err)
+		}
+
+		// Origins are cached until the next refresh
+		if service.OriginAllowed("https://app.acme.com") {
+			t.Error("Expected cached origins to apply until the next refresh")
+		}
+		service.loadedAt = time.Time{}
+		for origin, want := range map[string]bool{
+			"https://app.acme.com":  true,
+			"http://localhost:3000": true,
+			"http://app.acme.com":   false,
+			"https://evil.com":      false,
+		} {
+			if got := service.OriginAllowed(origin); got != want {
+				t.Errorf("Expected %s allowed %v, got %v", origin, want, got)
+			}
+		}
+
+		// Suspended tenants no longer allow their origins
+		if err := tenantService.SuspendTenant(ctx, tenant.ID.String()); err != nil {
+			t.Fatalf("Failed to suspend tenant: %v", err)
+		}
+		service.loadedAt = time.Time{}
+		if service.OriginAllowed("https://app.acme.com") {
+			t.Error("Expected the origins of a suspended tenant to be dropped")
+		}
+	})
+}
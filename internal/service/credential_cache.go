@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrCredentialNotCached means no recent verification of an email is cached
+var ErrCredentialNotCached = errors.New("credentials are not cached")
+
+// CredentialCache remembers passwords the identity provider verified recently,
+// so users who signed in moments ago can sign in again while the provider is
+// briefly unavailable. Only a bcrypt hash of the password is cached, for a
+// short TTL, and entries are dropped when the provider rejects the password or
+// the user changes it. Cached credentials are never consulted while the
+// provider is reachable.
+type CredentialCache struct {
+	cache *database.RedisClient
+	ttl   time.Duration
+	cost  int
+}
+
+// cachedCredential is the cached outcome of a successful password verification
+type cachedCredential struct {
+	PasswordHash string            `json:"passwordHash"`
+	User         auth.IdentityUser `json:"user"`
+}
+
+// NewCredentialCache creates a credential cache keeping verifications for ttl
+func NewCredentialCache(cache *database.RedisClient, ttl time.Duration) *CredentialCache {
+	return &CredentialCache{cache: cache, ttl: ttl, cost: bcrypt.DefaultCost}
+}
+
+// Remember caches a password the identity provider accepted for a user
+func (c *CredentialCache) Remember(ctx context.Context, email, password string, user *auth.IdentityUser) {
+	if c == nil || user == nil {
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), c.cost)
+	if err != nil {
+		log.Printf("Failed to hash credentials of user %s: %v", user.ID, err)
+		return
+	}
+	key := credentialKey(email)
+	if err := c.cache.SetJSON(ctx, key, cachedCredential{PasswordHash: string(hash), User: *user}, c.ttl); err != nil {
+		log.Printf("Failed to cache credentials of user %s: %v", user.ID, err)
+		return
+	}
+	_ = c.cache.Set(ctx, credentialUserKey(user.ID), key, c.ttl)
+}
+
+// Verify checks a password against the cached verification of an email,
+// returning the user it was verified for. It returns auth.ErrInvalidCredentials
+// when the password does not match and ErrCredentialNotCached when the email
+// has no recent verification.
+func (c *CredentialCache) Verify(ctx context.Context, email, password string) (*auth.IdentityUser, error) {
+	if c == nil {
+		return nil, ErrCredentialNotCached
+	}
+	var cached cachedCredential
+	if err := c.cache.GetJSON(ctx, credentialKey(email), &cached); err != nil {
+		return nil, ErrCredentialNotCached
+	}
+	if bcrypt.CompareHashAndPassword([]byte(cached.PasswordHash), []byte(password)) != nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return &cached.User, nil
+}
+
+// Forget drops the cached verification of an email
+func (c *CredentialCache) Forget(ctx context.Context, email string) {
+	if c == nil {
+		return
+	}
+	_ = c.cache.Del(ctx, credentialKey(email))
+}
+
+// ForgetUser drops the cached verification of a user, e.g. when their
+// password changes
+func (c *CredentialCache) ForgetUser(ctx context.Context, userID string) {
+	if c == nil {
+		return
+	}
+	if key, err := c.cache.Get(ctx, credentialUserKey(userID)); err == nil {
+		_ = c.cache.Del(ctx, key)
+	}
+	_ = c.cache.Del(ctx, credentialUserKey(userID))
+}
+
+func credentialKey(email string) string {
+	return "credentials:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func credentialUserKey(userID string) string {
+	return "credentials:user:" + userID
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// flakyIdentityProvider accepts a single password and fails every login while down
+type flakyIdentityProvider struct {
+	auth.IdentityProvider
+	user     auth.IdentityUser
+	password string
+	down     bool
+}
+
+func (p *flakyIdentityProvider) Login(ctx context.Context, req *auth.LoginRequest) (*auth.IdentityUser, error) {
+	if p.down {
+		return nil, fmt.Errorf("%w: connection refused", auth.ErrProviderUnavailable)
+	}
+	if !strings.EqualFold(req.Email, p.user.Email) || req.Password != p.password {
+		return nil, auth.ErrInvalidCredentials
+	}
+	user := p.user
+	return &user, nil
+}
+
+func TestAuthService_CredentialCache(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		provider := &flakyIdentityProvider{
+			user:     auth.IdentityUser{ID: alice.ID.String(), Email: alice.Email, Active: true},
+			password: "SecurePassword123!",
+		}
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		authService := NewAuthService(db, provider, jwtService, nil, nil, nil)
+		login := func(password string) (*AuthResponse, error) {
+			return authService.Login(ctx, &LoginRequest{Email: "Alice@acme.com", Password: password})
+		}
+		unavailable := func(err error) bool {
+			var appErr *apperrors.Error
+			return errors.As(err, &appErr) && appErr.Code == "IDENTITY_PROVIDER_UNAVAILABLE"
+		}
+
+		// Without the cache, logins fail while the provider is down
+		provider.down = true
+		if _, err := login(provider.password); !unavailable(err) {
+			t.Fatalf("Expected IDENTITY_PROVIDER_UNAVAILABLE without the cache, got %v", err)
+		}
+
+		credentials := NewCredentialCache(database.NewMemoryClient(), time.Minute)
+		credentials.cost = bcrypt.MinCost
+		authService.SetCredentialCache(credentials)
+
+		// Users who never signed in while the provider was up are not cached
+		if _, err := login(provider.password); !unavailable(err) {
+			t.Fatalf("Expected IDENTITY_PROVIDER_UNAVAILABLE before a successful login, got %v", err)
+		}
+
+		provider.down = false
+		if _, err := login(provider.password); err != nil {
+			t.Fatalf("Login returned error: %v", err)
+		}
+
+		provider.down = true
+		resp, err := login(provider.password)
+		if err != nil {
+			t.Fatalf("Expected cached credentials to sign alice in, got %v", err)
+		}
+		if resp.User.ID != alice.ID.String() {
+			t.Errorf("Expected tokens for alice, got %+v", resp.User)
+		}
+		if _, err := login("WrongPassword123!"); !unavailable(err) {
+			t.Errorf("Expected IDENTITY_PROVIDER_UNAVAILABLE for a password that does not match the cache, got %v", err)
+		}
+
+		// A changed password drops the cached one
+		credentials.ForgetUser(ctx, alice.ID.String())
+		if _, err := login(provider.password); !unavailable(err) {
+			t.Errorf("Expected IDENTITY_PROVIDER_UNAVAILABLE after the password changed, got %v", err)
+		}
+
+		// So does a password the provider rejects
+		provider.down = false
+		if _, err := login(provider.password); err != nil {
+			t.Fatalf("Login returned error: %v", err)
+		}
+		provider.password = "RotatedPassword456!"
+		if _, err := login("SecurePassword123!"); err == nil || unavailable(err) {
+			t.Fatalf("Expected the provider to reject the old password, got %v", err)
+		}
+		provider.down = true
+		if _, err := login("SecurePassword123!"); !unavailable(err) {
+			t.Errorf("Expected IDENTITY_PROVIDER_UNAVAILABLE after the provider rejected the password, got %v", err)
+		}
+		if err := authService.Drain(ctx); err != nil {
+			t.Fatalf("Drain returned error: %v", err)
+		}
+	})
+}
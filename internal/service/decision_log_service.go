@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// DecisionEventType is the audit log event type of decisions reported by OPA
+// instances through the decision log API
+const DecisionEventType = "opa_decision"
+
+const (
+	// maxDecisionLogBytes caps the decompressed size of an uploaded batch
+	maxDecisionLogBytes = 32 << 20
+	// decisionLogBatchSize is the number of decisions inserted per statement
+	decisionLogBatchSize = 500
+)
+
+// Outcomes of reported decisions, stored as the audit log status
+const (
+	DecisionAllowed = "allowed"
+	DecisionDenied  = "denied"
+	DecisionError   = "error"
+	DecisionUnknown = "unknown" // The result is not an allow decision, e.g. a partial evaluation
+)
+
+// DecisionLogService stores the decision logs of OPA instances, e.g. sidecars
+// running Heimdall's bundles, in the audit log
+type DecisionLogService struct {
+	db *gorm.DB
+}
+
+// NewDecisionLogService creates a new decision log service
+func NewDecisionLogService(db *gorm.DB) *DecisionLogService {
+	return &DecisionLogService{db: db}
+}
+
+// DecisionEvent is a decision as OPA's decision log plugin reports it
+type DecisionEvent struct {
+	DecisionID  string                 `json:"decision_id"`
+	Labels      map[string]string      `json:"labels"` // Includes the instance's id and version
+	Path        string                 `json:"path"`
+	Query       string                 `json:"query,omitempty"`
+	Input       interface{}            `json:"input,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	Error       interface{}            `json:"error,omitempty"`
+	RequestedBy string                 `json:"requested_by,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Metrics     map[string]interface{} `json:"metrics,omitempty"`
+	Bundles     map[string]struct {
+		Revision string `json:"revision"`
+	} `json:"bundles,omitempty"`
+	Erased []string `json:"erased,omitempty"`
+	Masked []string `json:"masked,omitempty"`
+}
+
+// DecisionLogSource identifies who uploaded a batch of decision logs
+type DecisionLogSource struct {
+	TenantID  uuid.UUID
+	ClientID  string // OAuth client the instance authenticated as, if any
+	UserID    string // User the instance authenticated as, if any
+	IPAddress string
+	UserAgent string
+}
+
+// DecisionLogFilter narrows the decisions listed
+type DecisionLogFilter struct {
+	InstanceID string // OPA instance that reported the decision
+	DecisionID string
+}
+
+// DecisionLogResponse represents a decision reported by an OPA instance
+type DecisionLogResponse struct {
+	ID          string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	DecisionID  string                 `json:"decisionId" example:"4ca636c1-55e4-417a-b1d8-4aceb67960d1"`
+	InstanceID  string                 `json:"instanceId" example:"b1cd0c39-7d3f-4c1e-9c4b-3f6a1c52b0d1"` // OPA instance that made the decision
+	Labels      map[string]string      `json:"labels,omitempty"`
+	ClientID    string                 `json:"clientId,omitempty" example:"opa-sidecar"` // OAuth client the instance reported as
+	Path        string                 `json:"path" example:"heimdall/authz/allow"`
+	Status      string                 `json:"status" example:"allowed"` // allowed, denied, error or unknown
+	Action      string                 `json:"action,omitempty" example:"read"`
+	Resource    string                 `json:"resource,omitempty" example:"reports"`
+	Input       interface{}            `json:"input,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	Error       interface{}            `json:"error,omitempty"`
+	Bundles     map[string]string      `json:"bundles,omitempty"` // Revisions of the instance's bundles by name
+	Metrics     map[string]interface{} `json:"metrics,omitempty"`
+	RequestedBy string                 `json:"requestedBy,omitempty" example:"10.0.3.7:52144"`
+	IPAddress   string                 `json:"ipAddress,omitempty" example:"10.0.3.7"` // Address the instance reported from
+	Timestamp   string                 `json:"timestamp" example:"2024-01-20T14:45:00Z"`
+}
+
+// DecisionLogListOptions describes the sorting and filtering supported when
+// listing reported decisions
+var DecisionLogListOptions = pagination.Options{
+	SortFields:   map[string]string{"timestamp": "created_at"},
+	DefaultSort:  "-timestamp",
+	StatusColumn: "status",
+}
+
+// decisionLogMetadata is the audit log metadata of a reported decision
+type decisionLogMetadata struct {
+	DecisionID  string                 `json:"decisionId"`
+	InstanceID  string                 `json:"instanceId"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	ClientID    string                 `json:"clientId,omitempty"`
+	ReporterID  string                 `json:"reporterId,omitempty"` // User the instance reported as
+	Query       string                 `json:"query,omitempty"`
+	Input       interface{}            `json:"input,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	Error       interface{}            `json:"error,omitempty"`
+	Bundles     map[string]string      `json:"bundles,omitempty"`
+	Metrics     map[string]interface{} `json:"metrics,omitempty"`
+	RequestedBy string                 `json:"requestedBy,omitempty"`
+	Erased      []string               `json:"erased,omitempty"`
+	Masked      []string               `json:"masked,omitempty"`
+}
+
+// IngestDecisionLogs stores a batch of decisions uploaded by an OPA instance,
+// gzip compressed as OPA sends them unless configured otherwise, in the audit
+// log of the uploader's tenant. It returns the number of decisions stored.
+func (s *DecisionLogService) IngestDecisionLogs(ctx context.Context, source *DecisionLogSource, body []byte, gzipped bool) (int, error) {
+	events, err := decodeDecisionLogs(body, gzipped)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	entries := make([]models.AuditLog, 0, len(events))
+	for i := range events {
+		entry, err := decisionAuditLog(source, &events[i])
+		if err != nil {
+			return 0, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := s.db.WithContext(ctx).CreateInBatches(entries, decisionLogBatchSize).Error; err != nil {
+		return 0, fmt.Errorf("failed to store decision logs: %w", err)
+	}
+	return len(entries), nil
+}
+
+// decodeDecisionLogs reads a batch of decision events
+func decodeDecisionLogs(body []byte, gzipped bool) ([]DecisionEvent, error) {
+	var reader io.Reader = bytes.NewReader(body)
+	if gzipped {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, apperrors.Validation("INVALID_DECISION_LOGS", "Decision logs are not valid gzip").WithCause(err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, maxDecisionLogBytes+1))
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_DECISION_LOGS", "Decision logs could not be read").WithCause(err)
+	}
+	if len(decoded) > maxDecisionLogBytes {
+		return nil, apperrors.Validation("DECISION_LOGS_TOO_LARGE", "Decision log batch exceeds 32 MiB").
+			WithDetails(map[string]interface{}{"maxBytes": maxDecisionLogBytes})
+	}
+
+	var events []DecisionEvent
+	if err := json.Unmarshal(decoded, &events); err != nil {
+		return nil, apperrors.Validation("INVALID_DECISION_LOGS", "Decision logs must be a JSON array of decision events").WithCause(err)
+	}
+	for i, event := range events {
+		if event.DecisionID == "" {
+			return nil, apperrors.Validation("INVALID_DECISION_LOGS", "Decision events must have a decision_id").
+				WithDetails(map[string]interface{}{"index": i})
+		}
+	}
+	return events, nil
+}
+
+// decisionAuditLog converts a reported decision to an audit log entry
+func decisionAuditLog(source *DecisionLogSource, event *DecisionEvent) (*models.AuditLog, error) {
+	metadata := decisionLogMetadata{
+		DecisionID:  event.DecisionID,
+		InstanceID:  event.Labels["id"],
+		Labels:      event.Labels,
+		ClientID:    source.ClientID,
+		ReporterID:  source.UserID,
+		Query:       event.Query,
+		Input:       event.Input,
+		Result:      event.Result,
+		Error:       event.Error,
+		Metrics:     event.Metrics,
+		RequestedBy: event.RequestedBy,
+		Erased:      event.Erased,
+		Masked:      event.Masked,
+	}
+	if len(event.Bundles) > 0 {
+		metadata.Bundles = make(map[string]string, len(event.Bundles))
+		for name, bundle := range event.Bundles {
+			metadata.Bundles[name] = bundle.Revision
+		}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal decision %s: %w", event.DecisionID, err)
+	}
+
+	// The input is reported by the instance, so it only describes the decision;
+	// users it names are not linked to the audit log entry
+	input, _ := event.Input.(map[string]interface{})
+	action, _ := input["action"].(string)
+	if action == "" {
+		action = "evaluate"
+	}
+	resource, _ := input["resource"].(map[string]interface{})
+	resourceType, _ := resource["type"].(string)
+
+	createdAt := event.Timestamp
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	var durationMs int64
+	if ns, ok := event.Metrics["timer_server_handler_ns"].(float64); ok {
+		durationMs = int64(ns / float64(time.Millisecond))
+	}
+
+	return &models.AuditLog{
+		TenantID:  source.TenantID,
+		EventType: DecisionEventType,
+		Action:    truncateText(action, 100),
+		Resource:  truncateText(resourceType, 100),
+		IPAddress: source.IPAddress,
+		UserAgent: source.UserAgent,
+		Path:      truncateText(event.Path, 500),
+		Status:    decisionStatus(event),
+		Message:   decisionError(event.Error),
+		Metadata:  datatypes.JSON(encoded),
+		Duration:  durationMs,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// decisionStatus returns the outcome of a decision whose result is an allow
+// boolean, or an object with an allow field as Heimdall's policies return
+func decisionStatus(event *DecisionEvent) string {
+	if event.Error != nil {
+		return DecisionError
+	}
+	result := event.Result
+	if object, ok := result.(map[string]interface{}); ok {
+		result = object["allow"]
+	}
+	switch result {
+	case true:
+		return DecisionAllowed
+	case false:
+		return DecisionDenied
+	default:
+		return DecisionUnknown
+	}
+}
+
+// decisionError returns the message of a decision's error
+func decisionError(err interface{}) string {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case string:
+		return e
+	case map[string]interface{}:
+		if message, ok := e["message"].(string); ok {
+			return message
+		}
+	}
+	encoded, _ := json.Marshal(err)
+	return string(encoded)
+}
+
+// truncateText shortens a string to a column's length
+func truncateText(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+// ListDecisions returns a page of the decisions reported by the OPA instances of a tenant
+func (s *DecisionLogService) ListDecisions(ctx context.Context, tenantID uuid.UUID, filter DecisionLogFilter, params *pagination.Params) ([]DecisionLogResponse, *pagination.Page, error) {
+	db := readReplica(s.db)
+	query := db.Model(&models.AuditLog{}).
+		Where("tenant_id = ? AND event_type = ?", tenantID, DecisionEventType)
+	if filter.InstanceID != "" {
+		query = query.Where(jsonText(db, "metadata", "instanceId")+" = ?", filter.InstanceID)
+	}
+	if filter.DecisionID != "" {
+		query = query.Where(jsonText(db, "metadata", "decisionId")+" = ?", filter.DecisionID)
+	}
+
+	entries, page, err := pagination.Paginate[models.AuditLog](ctx, query, params, DecisionLogListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+
+	responses := make([]DecisionLogResponse, len(entries))
+	for i := range entries {
+		responses[i] = toDecisionLogResponse(&entries[i])
+	}
+	return responses, page, nil
+}
+
+func toDecisionLogResponse(entry *models.AuditLog) DecisionLogResponse {
+	var metadata decisionLogMetadata
+	if len(entry.Metadata) > 0 {
+		_ = json.Unmarshal(entry.Metadata, &metadata)
+	}
+	return DecisionLogResponse{
+		ID:          entry.ID.String(),
+		DecisionID:  metadata.DecisionID,
+		InstanceID:  metadata.InstanceID,
+		Labels:      metadata.Labels,
+		ClientID:    metadata.ClientID,
+		Path:        entry.Path,
+		Status:      entry.Status,
+		Action:      entry.Action,
+		Resource:    entry.Resource,
+		Input:       metadata.Input,
+		Result:      metadata.Result,
+		Error:       metadata.Error,
+		Bundles:     metadata.Bundles,
+		Metrics:     metadata.Metrics,
+		RequestedBy: metadata.RequestedBy,
+		IPAddress:   entry.IPAddress,
+		Timestamp:   entry.CreatedAt.Format(time.RFC3339),
+	}
+}
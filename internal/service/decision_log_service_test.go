@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func gzipBytes(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecisionLogs(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		service := NewDecisionLogService(db)
+		source := &DecisionLogSource{TenantID: acme.ID, ClientID: "opa-sidecar", IPAddress: "10.0.3.7"}
+
+		batch := `[
+			{"decision_id": "d1", "labels": {"id": "opa-1", "version": "1.0.0"}, "path": "heimdall/authz/allow",
+			 "input": {"action": "read", "resource": {"type": "reports"}, "user": {"id": "u-1"}}, "result": true,
+			 "bundles": {"authz": {"revision": "abc"}}, "timestamp": "2024-01-20T14:45:00Z", "metrics": {"timer_server_handler_ns": 3000000}},
+			{"decision_id": "d2", "labels": {"id": "opa-1"}, "path": "heimdall/authz", "result": {"allow": false}, "timestamp": "2024-01-20T14:46:00Z"},
+			{"decision_id": "d3", "labels": {"id": "opa-2"}, "path": "heimdall/authz/allow", "error": {"code": "eval_error", "message": "boom"}, "timestamp": "2024-01-20T14:47:00Z"}
+		]`
+		accepted, err := service.IngestDecisionLogs(ctx, source, gzipBytes(t, batch), true)
+		if err != nil || accepted != 3 {
+			t.Fatalf("Expected 3 decisions stored, got %d: %v", accepted, err)
+		}
+		// Uncompressed batches are accepted too, and other tenants' decisions are separate
+		if _, err := service.IngestDecisionLogs(ctx, &DecisionLogSource{TenantID: globex.ID}, []byte(`[{"decision_id": "g1", "labels": {"id": "opa-9"}, "result": true}]`), false); err != nil {
+			t.Fatalf("IngestDecisionLogs failed: %v", err)
+		}
+
+		params := &pagination.Params{Page: 1, PageSize: 20, Sort: "timestamp"}
+		decisions, _, err := service.ListDecisions(ctx, acme.ID, DecisionLogFilter{}, params)
+		if err != nil {
+			t.Fatalf("ListDecisions failed: %v", err)
+		}
+		if len(decisions) != 3 {
+			t.Fatalf("Expected acme's 3 decisions, got %+v", decisions)
+		}
+		first := decisions[0]
+		if first.DecisionID != "d1" || first.InstanceID != "opa-1" || first.ClientID != "opa-sidecar" || first.IPAddress != "10.0.3.7" ||
+			first.Status != DecisionAllowed || first.Action != "read" || first.Resource != "reports" ||
+			first.Bundles["authz"] != "abc" || first.Timestamp != "2024-01-20T14:45:00Z" {
+			t.Errorf("Unexpected first decision %+v", first)
+		}
+		if decisions[1].Status != DecisionDenied || decisions[1].Action != "evaluate" || decisions[2].Status != DecisionError {
+			t.Errorf("Unexpected outcomes %s and %s", decisions[1].Status, decisions[2].Status)
+		}
+
+		var entry models.AuditLog
+		db.Where("event_type = ? AND status = ?", DecisionEventType, DecisionError).First(&entry)
+		if entry.Message != "boom" {
+			t.Errorf("Expected the error message to be recorded, got %q", entry.Message)
+		}
+
+		// Decisions are attributed to the instance that reported them
+		decisions, _, err = service.ListDecisions(ctx, acme.ID, DecisionLogFilter{InstanceID: "opa-2"}, params)
+		if err != nil || len(decisions) != 1 || decisions[0].DecisionID != "d3" {
+			t.Errorf("Expected opa-2's decision, got %+v: %v", decisions, err)
+		}
+		decisions, _, err = service.ListDecisions(ctx, acme.ID, DecisionLogFilter{}, &pagination.Params{Page: 1, PageSize: 20, Sort: "timestamp", Status: DecisionDenied})
+		if err != nil || len(decisions) != 1 || decisions[0].DecisionID != "d2" {
+			t.Errorf("Expected the denied decision, got %+v: %v", decisions, err)
+		}
+	})
+}
+
+func TestDecisionLogsInvalid(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		service := NewDecisionLogService(db)
+		source := &DecisionLogSource{TenantID: acme.ID}
+
+		for name, body := range map[string][]byte{
+			"not gzip":       []byte(`[]`),
+			"not an array":   gzipBytes(t, `{"decision_id": "d1"}`),
+			"no decision id": gzipBytes(t, `[{"labels": {"id": "opa-1"}}]`),
+		} {
+			if _, err := service.IngestDecisionLogs(ctx, source, body, true); !isAppError(err, "INVALID_DECISION_LOGS") {
+				t.Errorf("%s: expected INVALID_DECISION_LOGS, got %v", name, err)
+			}
+		}
+
+		// Batches are capped after decompression
+		huge := fmt.Sprintf(`[{"decision_id": "d1", "input": "%s"}]`, strings.Repeat("a", maxDecisionLogBytes))
+		if _, err := service.IngestDecisionLogs(ctx, source, gzipBytes(t, huge), true); !isAppError(err, "DECISION_LOGS_TOO_LARGE") {
+			t.Errorf("Expected DECISION_LOGS_TOO_LARGE, got %v", err)
+		}
+
+		var count int64
+		db.Model(&models.AuditLog{}).Where("event_type = ?", DecisionEventType).Count(&count)
+		if count != 0 {
+			t.Errorf("Expected no decisions stored, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultRolesSetting is the tenant settings key holding the IDs of the roles
+// given to users registering with the tenant
+const defaultRolesSetting = "defaultRoles"
+
+// maxDefaultRoles bounds the default roles of a tenant
+const maxDefaultRoles = 20
+
+// UpdateDefaultRolesRequest adds roles to and removes roles from a tenant's
+// default roles. Roles in both lists are removed.
+type UpdateDefaultRolesRequest struct {
+	Add    []string `json:"add" validate:"max=20,dive,uuid" example:"[\"550e8400-e29b-41d4-a716-446655440000\"]"`
+	Remove []string `json:"remove" validate:"max=20,dive,uuid"`
+}
+
+// DefaultRole is a role given to users registering with a tenant
+type DefaultRole struct {
+	ID   string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name string `json:"name" example:"viewer"`
+}
+
+// DefaultRolesResponse represents a tenant's default roles
+type DefaultRolesResponse struct {
+	TenantID string        `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Roles    []DefaultRole `json:"roles"`
+}
+
+// GetDefaultRoles retrieves the roles given to users registering with a tenant
+func (s *TenantService) GetDefaultRoles(ctx context.Context, tenantID uuid.UUID) (*DefaultRolesResponse, error) {
+	db := readReplica(s.db).WithContext(ctx)
+	var tenant models.Tenant
+	if err := db.First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	roles, err := defaultRoles(db, &tenant)
+	if err != nil {
+		return nil, err
+	}
+	return toDefaultRolesResponse(&tenant, roles), nil
+}
+
+// UpdateDefaultRoles changes the roles given to users registering with a
+// tenant. Added roles must belong to the tenant.
+func (s *TenantService) UpdateDefaultRoles(ctx context.Context, tenantID uuid.UUID, req *UpdateDefaultRolesRequest) (*DefaultRolesResponse, error) {
+	var response *DefaultRolesResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		added := make(map[string]bool, len(req.Add))
+		for _, id := range req.Add {
+			added[id] = true
+		}
+		if len(added) > 0 {
+			var found int64
+			if err := tx.Model(&models.Role{}).Where("tenant_id = ? AND id IN ?", tenantID, req.Add).Count(&found).Error; err != nil {
+				return fmt.Errorf("failed to get roles: %w", err)
+			}
+			if int(found) != len(added) {
+				return apperrors.NotFound("ROLE_NOT_FOUND", "Default roles must be roles of the tenant")
+			}
+		}
+
+		current, err := defaultRoles(tx, &tenant)
+		if err != nil {
+			return err
+		}
+		ids := make(map[string]bool, len(current)+len(req.Add))
+		for _, role := range current {
+			ids[role.ID.String()] = true
+		}
+		for id := range added {
+			ids[id] = true
+		}
+		for _, id := range req.Remove {
+			delete(ids, id)
+		}
+		if len(ids) > maxDefaultRoles {
+			return apperrors.Validation("TOO_MANY_DEFAULT_ROLES", fmt.Sprintf("A tenant can have at most %d default roles", maxDefaultRoles))
+		}
+
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			delete(settings, defaultRolesSetting)
+		} else {
+			list := make([]string, 0, len(ids))
+			for id := range ids {
+				list = append(list, id)
+			}
+			sort.Strings(list)
+			settings[defaultRolesSetting] = list
+		}
+		if err := saveTenantSettings(tx, &tenant, settings); err != nil {
+			return err
+		}
+
+		roles, err := defaultRoles(tx, &tenant)
+		if err != nil {
+			return err
+		}
+		response = toDefaultRolesResponse(&tenant, roles)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// defaultRoles returns the default roles of a tenant that still exist, ordered
+// by name
+func defaultRoles(db *gorm.DB, tenant *models.Tenant) ([]models.Role, error) {
+	settings, err := tenantSettings(tenant)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := settings[defaultRolesSetting].([]interface{})
+	if len(values) == 0 {
+		return []models.Role{}, nil
+	}
+	ids := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	var roles []models.Role
+	if err := db.Where("tenant_id = ? AND id IN ?", tenant.ID, ids).Order("name").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get default roles: %w", err)
+	}
+	return roles, nil
+}
+
+// assignDefaultRoles gives a new user the default roles of their tenant and
+// returns them
+func assignDefaultRoles(tx *gorm.DB, user *models.User) ([]models.Role, error) {
+	var tenant models.Tenant
+	if err := tx.First(&tenant, "id = ?", user.TenantID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	roles, err := defaultRoles(tx, &tenant)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: role.ID}).Error; err != nil {
+			return nil, fmt.Errorf("failed to assign default role %s: %w", role.Name, err)
+		}
+	}
+	return roles, nil
+}
+
+// toDefaultRolesResponse converts a tenant's default roles to a response
+func toDefaultRolesResponse(tenant *models.Tenant, roles []models.Role) *DefaultRolesResponse {
+	response := &DefaultRolesResponse{
+		TenantID: tenant.ID.String(),
+		Roles:    make([]DefaultRole, len(roles)),
+	}
+	for i, role := range roles {
+		response.Roles[i] = DefaultRole{ID: role.ID.String(), Name: role.Name}
+	}
+	return response
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestDefaultRoles(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		viewer := testutil.CreateTestRole(t, db, acme, "viewer")
+		member := testutil.CreateTestRole(t, db, acme, "member")
+		foreign := testutil.CreateTestRole(t, db, globex, "viewer")
+		tenants := NewTenantService(db)
+
+		// Only roles of the tenant can be default roles
+		if _, err := tenants.UpdateDefaultRoles(ctx, acme.ID, &UpdateDefaultRolesRequest{Add: []string{foreign.ID.String()}}); !isAppError(err, "ROLE_NOT_FOUND") {
+			t.Errorf("Expected ROLE_NOT_FOUND for a role of another tenant, got %v", err)
+		}
+		defaults, err := tenants.UpdateDefaultRoles(ctx, acme.ID, &UpdateDefaultRolesRequest{Add: []string{viewer.ID.String(), member.ID.String()}})
+		if err != nil || len(defaults.Roles) != 2 || defaults.Roles[0].Name != "member" {
+			t.Fatalf("Expected two default roles, got %+v %v", defaults, err)
+		}
+		defaults, err = tenants.UpdateDefaultRoles(ctx, acme.ID, &UpdateDefaultRolesRequest{Remove: []string{member.ID.String()}})
+		if err != nil || len(defaults.Roles) != 1 || defaults.Roles[0].ID != viewer.ID.String() {
+			t.Fatalf("Expected viewer to remain the default role, got %+v %v", defaults, err)
+		}
+		if defaults, err := tenants.GetDefaultRoles(ctx, globex.ID); err != nil || len(defaults.Roles) != 0 {
+			t.Errorf("Expected no default roles for Globex, got %+v %v", defaults, err)
+		}
+
+		// Registering users get the default roles, also in their tokens
+		fake, fusionAuth := newFakeFusionAuth(t)
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		authService := NewAuthService(db, fusionAuth, jwtService, nil, nil, nil)
+		resp, err := authService.Register(ctx, &RegisterRequest{
+			Email:     "alice@acme.com",
+			Password:  "SecurePassword123!",
+			FirstName: "Alice",
+			LastName:  "Smith",
+			TenantID:  acme.ID.String(),
+		})
+		if err != nil {
+			t.Fatalf("Register returned error: %v", err)
+		}
+		var assigned []string
+		db.Model(&models.UserRole{}).Where("user_id = ?", resp.User.ID).Pluck("role_id", &assigned)
+		if len(assigned) != 1 || assigned[0] != viewer.ID.String() {
+			t.Errorf("Expected the user to have the viewer role, got %v", assigned)
+		}
+		claims, err := jwtService.ValidateAccessToken(resp.AccessToken)
+		if err != nil || len(claims.Roles) != 1 || claims.Roles[0] != "viewer" {
+			t.Errorf("Expected the access token to carry the viewer role, got %+v %v", claims, err)
+		}
+
+		// Rejected registrations are rolled back with their roles
+		fake.registerStatus = http.StatusBadRequest
+		if _, err := authService.Register(ctx, &RegisterRequest{
+			Email:     "bob@acme.com",
+			Password:  "SecurePassword123!",
+			FirstName: "Bob",
+			LastName:  "Smith",
+			TenantID:  acme.ID.String(),
+		}); err == nil {
+			t.Fatal("Expected an error when FusionAuth rejects the registration")
+		}
+		var users int64
+		db.Unscoped().Model(&models.User{}).Where("email = ?", "bob@acme.com").Count(&users)
+		if users != 0 {
+			t.Errorf("Expected the rejected user to be rolled back, found %d", users)
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeviceCodeGrantType is the OAuth grant type of the device authorization grant (RFC 8628)
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// User codes are drawn from consonants only, so they cannot spell words and
+// are easy to type on any keyboard (RFC 8628 §6.1)
+const (
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+	deviceCodeBytes  = 32
+)
+
+// slowDownIncrement is added to a device's polling interval each time it polls
+// too fast (RFC 8628 §3.5)
+const slowDownIncrement = 5
+
+// DeviceAuthorizationService implements the OAuth device authorization grant:
+// a device without a browser requests a user code, the user approves it while
+// signed in elsewhere, and the device polls the token endpoint until then
+type DeviceAuthorizationService struct {
+	db  *gorm.DB
+	cfg *config.OAuthConfig
+}
+
+// NewDeviceAuthorizationService creates a new device authorization service
+func NewDeviceAuthorizationService(db *gorm.DB, cfg *config.OAuthConfig) *DeviceAuthorizationService {
+	return &DeviceAuthorizationService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// DeviceCodeResponse represents a device authorization, in the form of RFC 8628 §3.2
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code" example:"3f79bb7b435b05321651daefd374cdc681dc06faa65e374e38337b88ca046dea"`
+	UserCode                string `json:"user_code" example:"WDJB-MJHT"`
+	VerificationURI         string `json:"verification_uri" example:"https://heimdall.example.com/device"`
+	VerificationURIComplete string `json:"verification_uri_complete" example:"https://heimdall.example.com/device?user_code=WDJB-MJHT"`
+	ExpiresIn               int64  `json:"expires_in" example:"600"`
+	Interval                int    `json:"interval" example:"5"` // Seconds the device must wait between token requests
+}
+
+// DeviceAuthorizationResponse represents a device authorization as shown to the
+// user on the verification page
+type DeviceAuthorizationResponse struct {
+	UserCode  string `json:"userCode" example:"WDJB-MJHT"`
+	ClientID  string `json:"clientId" example:"heimdallctl"`
+	Status    string `json:"status" example:"pending"`
+	ExpiresAt string `json:"expiresAt" example:"2024-01-15T10:40:00Z"`
+}
+
+// DeviceVerificationRequest represents a user's decision on a device authorization
+type DeviceVerificationRequest struct {
+	UserCode string `json:"userCode" validate:"required,max=16" example:"WDJB-MJHT"`
+}
+
+// StartAuthorization starts a device authorization for a client, returning the
+// device code the device polls with and the user code the user enters
+func (s *DeviceAuthorizationService) StartAuthorization(ctx context.Context, clientID string) (*DeviceCodeResponse, error) {
+	if clientID == "" || len(clientID) > 100 {
+		return nil, apperrors.Validation("INVALID_REQUEST", "client_id is required and must be at most 100 characters")
+	}
+
+	deviceCode, err := randomHex(deviceCodeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate device code: %w", err)
+	}
+
+	now := time.Now()
+	db := s.db.WithContext(ctx)
+
+	// Expired authorizations are cleaned up so their user codes can be reused
+	if err := db.Where("expires_at < ?", now).Delete(&models.DeviceAuthorization{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete expired device authorizations: %w", err)
+	}
+
+	var userCode string
+	for attempt := 0; attempt < 5 && userCode == ""; attempt++ {
+		code, err := generateUserCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate user code: %w", err)
+		}
+		var count int64
+		if err := db.Model(&models.DeviceAuthorization{}).Where("user_code = ?", code).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to check user code: %w", err)
+		}
+		if count == 0 {
+			userCode = code
+		}
+	}
+	if userCode == "" {
+		return nil, fmt.Errorf("failed to generate a unique user code")
+	}
+
+	interval := int(s.cfg.DevicePollInterval.Seconds())
+	if interval < 1 {
+		interval = 1
+	}
+	authorization := &models.DeviceAuthorization{
+		DeviceCodeHash: hashSecret(deviceCode),
+		UserCode:       userCode,
+		ClientID:       clientID,
+		Status:         models.DeviceAuthorizationPending,
+		PollInterval:   interval,
+		ExpiresAt:      now.Add(s.cfg.DeviceCodeExpiry),
+	}
+	if err := db.Create(authorization).Error; err != nil {
+		return nil, fmt.Errorf("failed to create device authorization: %w", err)
+	}
+
+	separator := "?"
+	if strings.Contains(s.cfg.DeviceVerificationURI, "?") {
+		separator = "&"
+	}
+	displayCode := formatUserCode(userCode)
+	return &DeviceCodeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                displayCode,
+		VerificationURI:         s.cfg.DeviceVerificationURI,
+		VerificationURIComplete: s.cfg.DeviceVerificationURI + separator + "user_code=" + displayCode,
+		ExpiresIn:               int64(s.cfg.DeviceCodeExpiry.Seconds()),
+		Interval:                interval,
+	}, nil
+}
+
+// GetAuthorization retrieves the device authorization of a user code, so the
+// verification page can show the user which client is asking for access
+func (s *DeviceAuthorizationService) GetAuthorization(ctx context.Context, userCode string) (*DeviceAuthorizationResponse, error) {
+	authorization, err := s.findByUserCode(s.db.WithContext(ctx), userCode)
+	if err != nil {
+		return nil, err
+	}
+	return toDeviceAuthorizationResponse(authorization), nil
+}
+
+// ApproveAuthorization approves a pending device authorization, letting the
+// device sign in as the user at its next token request
+func (s *DeviceAuthorizationService) ApproveAuthorization(ctx context.Context, userCode string, userID uuid.UUID) (*DeviceAuthorizationResponse, error) {
+	return s.decide(ctx, userCode, userID, models.DeviceAuthorizationApproved)
+}
+
+// DenyAuthorization denies a pending device authorization
+func (s *DeviceAuthorizationService) DenyAuthorization(ctx context.Context, userCode string, userID uuid.UUID) (*DeviceAuthorizationResponse, error) {
+	return s.decide(ctx, userCode, userID, models.DeviceAuthorizationDenied)
+}
+
+// PollAuthorization handles a token request of a device. It returns the user
+// who approved the device, once, or the RFC 8628 §3.5 error telling the device
+// to keep polling, slow down or give up.
+func (s *DeviceAuthorizationService) PollAuthorization(ctx context.Context, clientID, deviceCode string) (*auth.IdentityUser, error) {
+	if deviceCode == "" {
+		return nil, apperrors.Validation("INVALID_REQUEST", "device_code is required")
+	}
+
+	var userID uuid.UUID
+	var pollErr error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var authorization models.DeviceAuthorization
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&authorization, "device_code_hash = ?", hashSecret(deviceCode)).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				pollErr = apperrors.Validation("INVALID_GRANT", "Invalid device code")
+				return nil
+			}
+			return fmt.Errorf("failed to get device authorization: %w", err)
+		}
+		if authorization.ClientID != clientID {
+			pollErr = apperrors.Validation("INVALID_GRANT", "Device code was issued to another client")
+			return nil
+		}
+
+		now := time.Now()
+		if now.After(authorization.ExpiresAt) {
+			pollErr = apperrors.Validation("EXPIRED_TOKEN", "Device code has expired")
+			return nil
+		}
+
+		switch authorization.Status {
+		case models.DeviceAuthorizationDenied:
+			pollErr = apperrors.Forbidden("ACCESS_DENIED", "The user denied the device")
+			return nil
+		case models.DeviceAuthorizationConsumed:
+			pollErr = apperrors.Validation("INVALID_GRANT", "Device code has already been used")
+			return nil
+		case models.DeviceAuthorizationApproved:
+			authorization.Status = models.DeviceAuthorizationConsumed
+			if err := tx.Save(&authorization).Error; err != nil {
+				return fmt.Errorf("failed to update device authorization: %w", err)
+			}
+			userID = *authorization.UserID
+			return nil
+		}
+
+		// Still pending: the poll is recorded even when the device is told to wait
+		pollErr = apperrors.Validation("AUTHORIZATION_PENDING", "The user has not yet approved the device")
+		if authorization.LastPolledAt != nil &&
+			now.Sub(*authorization.LastPolledAt) < time.Duration(authorization.PollInterval)*time.Second {
+			authorization.PollInterval += slowDownIncrement
+			pollErr = apperrors.Validation("SLOW_DOWN", "The device is polling too fast").
+				WithDetails(map[string]interface{}{"interval": authorization.PollInterval})
+		}
+		authorization.LastPolledAt = &now
+		if err := tx.Save(&authorization).Error; err != nil {
+			return fmt.Errorf("failed to update device authorization: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pollErr != nil {
+		return nil, pollErr
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "email").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Validation("INVALID_GRANT", "The user who approved the device no longer exists")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &auth.IdentityUser{ID: user.ID.String(), Email: user.Email}, nil
+}
+
+// decide records a user's decision on a pending device authorization
+func (s *DeviceAuthorizationService) decide(ctx context.Context, userCode string, userID uuid.UUID, status string) (*DeviceAuthorizationResponse, error) {
+	var response *DeviceAuthorizationResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		authorization, err := s.findByUserCode(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userCode)
+		if err != nil {
+			return err
+		}
+		if authorization.Status != models.DeviceAuthorizationPending {
+			return apperrors.Conflict("DEVICE_AUTHORIZATION_DECIDED", "Device authorization has already been decided")
+		}
+
+		authorization.Status = status
+		authorization.UserID = &userID
+		if err := tx.Save(authorization).Error; err != nil {
+			return fmt.Errorf("failed to update device authorization: %w", err)
+		}
+		response = toDeviceAuthorizationResponse(authorization)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// findByUserCode loads the unexpired device authorization of a user code
+func (s *DeviceAuthorizationService) findByUserCode(db *gorm.DB, userCode string) (*models.DeviceAuthorization, error) {
+	var authorization models.DeviceAuthorization
+	if err := db.First(&authorization, "user_code = ? AND expires_at > ?", normalizeUserCode(userCode), time.Now()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("DEVICE_AUTHORIZATION_NOT_FOUND", "Invalid or expired user code")
+		}
+		return nil, fmt.Errorf("failed to get device authorization: %w", err)
+	}
+	return &authorization, nil
+}
+
+func toDeviceAuthorizationResponse(authorization *models.DeviceAuthorization) *DeviceAuthorizationResponse {
+	return &DeviceAuthorizationResponse{
+		UserCode:  formatUserCode(authorization.UserCode),
+		ClientID:  authorization.ClientID,
+		Status:    authorization.Status,
+		ExpiresAt: authorization.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	alphabetSize := big.NewInt(int64(len(userCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// normalizeUserCode uppercases a user code as typed and drops the separator and
+// any other characters that are not letters
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}, code)
+}
+
+// formatUserCode splits a user code in two halves for display
+func formatUserCode(code string) string {
+	if len(code) != userCodeLength {
+		return code
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestDeviceAuthorizationService_Flow(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "alice@example.com")
+
+		service := NewDeviceAuthorizationService(db, &config.OAuthConfig{
+			DeviceVerificationURI: "https://heimdall.example.com/device",
+			DeviceCodeExpiry:      10 * time.Minute,
+			DevicePollInterval:    5 * time.Second,
+		})
+
+		var appErr *apperrors.Error
+		if _, err := service.StartAuthorization(ctx, ""); !errors.As(err, &appErr) || appErr.Code != "INVALID_REQUEST" {
+			t.Fatalf("Expected INVALID_REQUEST without a client ID, got %v", err)
+		}
+
+		started, err := service.StartAuthorization(ctx, "heimdallctl")
+		if err != nil {
+			t.Fatalf("Failed to start device authorization: %v", err)
+		}
+		if len(started.UserCode) != 9 || started.UserCode[4] != '-' || started.DeviceCode == "" {
+			t.Fatalf("Unexpected device authorization %+v", started)
+		}
+		if started.VerificationURIComplete != "https://heimdall.example.com/device?user_code="+started.UserCode {
+			t.Errorf("Unexpected complete verification URI %q", started.VerificationURIComplete)
+		}
+		if started.ExpiresIn != 600 || started.Interval != 5 {
+			t.Errorf("Expected 600s expiry and 5s interval, got %d and %d", started.ExpiresIn, started.Interval)
+		}
+
+		// Until the user decides, the device is told to wait, and to slow down
+		// when it polls faster than the interval
+		_, err = service.PollAuthorization(ctx, "heimdallctl", started.DeviceCode)
+		if !errors.As(err, &appErr) || appErr.Code != "AUTHORIZATION_PENDING" {
+			t.Fatalf("Expected AUTHORIZATION_PENDING, got %v", err)
+		}
+		_, err = service.PollAuthorization(ctx, "heimdallctl", started.DeviceCode)
+		if !errors.As(err, &appErr) || appErr.Code != "SLOW_DOWN" {
+			t.Fatalf("Expected SLOW_DOWN when polling too fast, got %v", err)
+		}
+		var stored models.DeviceAuthorization
+		db.First(&stored, "client_id = ?", "heimdallctl")
+		if stored.PollInterval != 10 {
+			t.Errorf("Expected the polling interval to be raised to 10s, got %d", stored.PollInterval)
+		}
+
+		_, err = service.PollAuthorization(ctx, "other-client", started.DeviceCode)
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_GRANT" {
+			t.Fatalf("Expected INVALID_GRANT for another client, got %v", err)
+		}
+		_, err = service.PollAuthorization(ctx, "heimdallctl", "unknown")
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_GRANT" {
+			t.Fatalf("Expected INVALID_GRANT for an unknown device code, got %v", err)
+		}
+
+		// User codes are accepted as typed, in lower case and without the dash
+		typed := strings.ToLower(strings.ReplaceAll(started.UserCode, "-", " "))
+		authorization, err := service.GetAuthorization(ctx, typed)
+		if err != nil {
+			t.Fatalf("Failed to get device authorization: %v", err)
+		}
+		if authorization.ClientID != "heimdallctl" || authorization.Status != models.DeviceAuthorizationPending {
+			t.Errorf("Unexpected device authorization %+v", authorization)
+		}
+
+		if _, err := service.ApproveAuthorization(ctx, started.UserCode, user.ID); err != nil {
+			t.Fatalf("Failed to approve device: %v", err)
+		}
+		if _, err := service.DenyAuthorization(ctx, started.UserCode, user.ID); !errors.Is(err, apperrors.ErrConflict) {
+			t.Fatalf("Expected conflict deciding twice, got %v", err)
+		}
+
+		// The approved device code is exchanged once
+		identityUser, err := service.PollAuthorization(ctx, "heimdallctl", started.DeviceCode)
+		if err != nil {
+			t.Fatalf("Failed to poll approved device: %v", err)
+		}
+		if identityUser.ID != user.ID.String() || identityUser.Email != user.Email {
+			t.Errorf("Expected the approving user, got %+v", identityUser)
+		}
+		_, err = service.PollAuthorization(ctx, "heimdallctl", started.DeviceCode)
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_GRANT" {
+			t.Fatalf("Expected INVALID_GRANT for a used device code, got %v", err)
+		}
+
+		// Denied devices are told to give up
+		denied, err := service.StartAuthorization(ctx, "heimdallctl")
+		if err != nil {
+			t.Fatalf("Failed to start device authorization: %v", err)
+		}
+		if _, err := service.DenyAuthorization(ctx, denied.UserCode, user.ID); err != nil {
+			t.Fatalf("Failed to deny device: %v", err)
+		}
+		_, err = service.PollAuthorization(ctx, "heimdallctl", denied.DeviceCode)
+		if !errors.As(err, &appErr) || appErr.Code != "ACCESS_DENIED" {
+			t.Fatalf("Expected ACCESS_DENIED, got %v", err)
+		}
+
+		// Expired codes can neither be approved nor polled
+		expired, err := service.StartAuthorization(ctx, "heimdallctl")
+		if err != nil {
+			t.Fatalf("Failed to start device authorization: %v", err)
+		}
+		db.Model(&models.DeviceAuthorization{}).
+			Where("user_code = ?", strings.ReplaceAll(expired.UserCode, "-", "")).
+			Update("expires_at", time.Now().Add(-time.Minute))
+		if _, err := service.ApproveAuthorization(ctx, expired.UserCode, user.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found approving an expired code, got %v", err)
+		}
+		_, err = service.PollAuthorization(ctx, "heimdallctl", expired.DeviceCode)
+		if !errors.As(err, &appErr) || appErr.Code != "EXPIRED_TOKEN" {
+			t.Fatalf("Expected EXPIRED_TOKEN, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/database"
+	"gorm.io/gorm"
+)
+
+// caseInsensitiveLike returns the case-insensitive LIKE operator of the database's
+// dialect. SQLite's LIKE already ignores case for ASCII text and has no ILIKE.
+func caseInsensitiveLike(db *gorm.DB) string {
+	if database.IsSQLite(db) {
+		return "LIKE"
+	}
+	return "ILIKE"
+}
+
+// jsonText returns an expression reading a text field of a JSON column in the
+// database's dialect. Dotted fields read nested objects, e.g. input.user.roles;
+// fields holding objects or arrays read as their JSON text.
+func jsonText(db *gorm.DB, column, field string) string {
+	if database.IsSQLite(db) {
+		return "json_extract(" + column + ", '$." + field + "')"
+	}
+	if strings.Contains(field, ".") {
+		return column + " #>> '{" + strings.ReplaceAll(field, ".", ",") + "}'"
+	}
+	return column + "->>'" + field + "'"
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns with backslashes
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likeEscape returns the ESCAPE clause making backslashes escape LIKE
+// wildcards. It is PostgreSQL's default, where an explicit clause would keep
+// prefix matches from using indexes.
+func likeEscape(db *gorm.DB) string {
+	if database.IsSQLite(db) {
+		return ` ESCAPE '\'`
+	}
+	return ""
+}
@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ipAccessSetting is the tenant settings key holding the tenant's IP access lists
+const ipAccessSetting = "ipAccess"
+
+// IPAccessService manages the IP access lists of tenants and enforces them on
+// API requests and logins
+type IPAccessService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	byTenant map[string]cachedIPAccess
+	cacheTTL time.Duration
+}
+
+// cachedIPAccess is a tenant's parsed lists (or a negative lookup) held in memory
+type cachedIPAccess struct {
+	rules     *ipAccessRules
+	expiresAt time.Time
+}
+
+// ipAccessRules are a tenant's IP access lists, parsed for matching
+type ipAccessRules struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+	lists IPAccessLists
+}
+
+// IPAccessLists are the address ranges a tenant admits and rejects, as stored
+// in the tenant's settings
+type IPAccessLists struct {
+	Allowlist []string `json:"allowlist"`
+	Denylist  []string `json:"denylist"`
+}
+
+// NewIPAccessService creates a new IP access service
+func NewIPAccessService(db *gorm.DB) *IPAccessService {
+	return &IPAccessService{
+		db:       db,
+		byTenant: make(map[string]cachedIPAccess),
+		cacheTTL: time.Minute,
+	}
+}
+
+// UpsertIPAccessRequest represents the desired IP access lists of a tenant.
+// Entries are CIDR ranges or single addresses.
+type UpsertIPAccessRequest struct {
+	Allowlist []string `json:"allowlist" validate:"max=100" example:"[\"203.0.113.0/24\",\"2001:db8::/32\"]"` // When set, only these ranges are admitted
+	Denylist  []string `json:"denylist" validate:"max=100" example:"[\"203.0.113.66\"]"`                      // Always rejected, even when allowlisted
+
+	// Address of the request making the change, which must stay admitted
+	ClientIP string `json:"-"`
+}
+
+// IPAccessResponse represents a tenant's IP access lists
+type IPAccessResponse struct {
+	TenantID  string   `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Allowlist []string `json:"allowlist" example:"[\"203.0.113.0/24\",\"2001:db8::/32\"]"`
+	Denylist  []string `json:"denylist" example:"[\"203.0.113.66/32\"]"`
+	UpdatedAt string   `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+	ETag      string   `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// GetIPAccess retrieves a tenant's IP access lists
+func (s *IPAccessService) GetIPAccess(ctx context.Context, tenantID uuid.UUID) (*IPAccessResponse, error) {
+	var tenant models.Tenant
+	if err := readReplica(s.db).WithContext(ctx).First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	settings, err := tenantSettings(&tenant)
+	if err != nil {
+		return nil, err
+	}
+	lists, err := ipAccessLists(settings)
+	if err != nil {
+		return nil, err
+	}
+	if lists == nil {
+		return nil, apperrors.NotFound("IP_ACCESS_NOT_FOUND", "IP access lists not found")
+	}
+	return toIPAccessResponse(&tenant, lists), nil
+}
+
+// UpsertIPAccess creates or replaces a tenant's IP access lists. It reports
+// whether they were created. Lists that would reject the request's own address
+// are refused, so administrators cannot lock themselves out.
+func (s *IPAccessService) UpsertIPAccess(ctx context.Context, tenantID uuid.UUID, req *UpsertIPAccessRequest, pre Precondition) (*IPAccessResponse, bool, error) {
+	rules, err := parseIPAccessLists(IPAccessLists{Allowlist: req.Allowlist, Denylist: req.Denylist})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(rules.allow) == 0 && len(rules.deny) == 0 {
+		return nil, false, apperrors.Validation("INVALID_REQUEST", "An allowlist or a denylist is required")
+	}
+	if req.ClientIP != "" && !rules.allows(req.ClientIP) {
+		return nil, false, apperrors.Validation("IP_ACCESS_LOCKOUT", "The IP access lists would reject the address of this request").
+			WithDetails(map[string]interface{}{"ipAddress": req.ClientIP})
+	}
+
+	var response *IPAccessResponse
+	created := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return err
+		}
+		current, err := ipAccessLists(settings)
+		if err != nil {
+			return err
+		}
+		created = current == nil
+
+		etag := ""
+		if !created {
+			etag = ResourceETag(tenant.UpdatedAt)
+		}
+		if err := pre.Check(etag); err != nil {
+			return err
+		}
+
+		settings[ipAccessSetting] = rules.lists
+		if err := saveTenantSettings(tx, &tenant, settings); err != nil {
+			return err
+		}
+		response = toIPAccessResponse(&tenant, &rules.lists)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.invalidate(tenantID.String())
+	return response, created, nil
+}
+
+// DeleteIPAccess removes a tenant's IP access lists, admitting requests from
+// any address
+func (s *IPAccessService) DeleteIPAccess(ctx context.Context, tenantID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return err
+		}
+		if _, ok := settings[ipAccessSetting]; !ok {
+			return apperrors.NotFound("IP_ACCESS_NOT_FOUND", "IP access lists not found")
+		}
+
+		delete(settings, ipAccessSetting)
+		return saveTenantSettings(tx, &tenant, settings)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate(tenantID.String())
+	return nil
+}
+
+// IPAccess reports whether a tenant admits requests from an IP address, and
+// returns the tenant's lists for policy input, or nil when it has none. Lists
+// are cached for a minute on each instance; changes made through this instance
+// apply immediately.
+func (s *IPAccessService) IPAccess(ctx context.Context, tenantID, ip string) (bool, map[string]interface{}, error) {
+	rules, err := s.tenantRules(ctx, tenantID)
+	if err != nil || rules == nil {
+		return err == nil, nil, err
+	}
+
+	return rules.allows(ip), map[string]interface{}{
+		"allowlist": rules.lists.Allowlist,
+		"denylist":  rules.lists.Denylist,
+	}, nil
+}
+
+// EmailTenant returns the tenant of the user with an email address, or "" when
+// there is none, so the IP access middleware can check logins before their
+// credentials
+func (s *IPAccessService) EmailTenant(ctx context.Context, email string) (string, error) {
+	var user models.User
+	err := readReplica(s.db).WithContext(ctx).Select("tenant_id").Where("email = ?", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.TenantID.String(), nil
+}
+
+// Name identifies the IP access lists among login hooks
+func (s *IPAccessService) Name() string {
+	return "ip_access"
+}
+
+// PreAuthenticate allows the attempt, as the IP access middleware has already
+// checked the tenant of the user the email belongs to
+func (s *IPAccessService) PreAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return nil, nil
+}
+
+// PostAuthenticate rejects logins from addresses the user's tenant does not
+// admit, before tokens are issued
+func (s *IPAccessService) PostAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	allowed, _, err := s.IPAccess(ctx, hookCtx.TenantID, hookCtx.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return &LoginHookResult{Action: LoginHookActionReject, Reason: "IP address not allowed by the tenant"}, nil
+	}
+	return nil, nil
+}
+
+// tenantRules returns a tenant's parsed lists, or nil when it has none
+func (s *IPAccessService) tenantRules(ctx context.Context, tenantID string) (*ipAccessRules, error) {
+	s.mu.RLock()
+	cached, ok := s.byTenant[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.rules, nil
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+
+	entry := cachedIPAccess{expiresAt: time.Now().Add(s.cacheTTL)}
+	var tenant models.Tenant
+	err = readReplica(s.db).WithContext(ctx).Select("id", "settings").First(&tenant, "id = ?", tenantUUID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if err == nil {
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return nil, err
+		}
+		// Lists are validated when saved, so entries that do not parse
+		// predate validation and are ignored
+		if lists, err := ipAccessLists(settings); err == nil && lists != nil {
+			entry.rules = parseIPAccessListsLenient(*lists)
+		}
+	}
+
+	s.mu.Lock()
+	s.byTenant[tenantID] = entry
+	s.mu.Unlock()
+
+	return entry.rules, nil
+}
+
+func (s *IPAccessService) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.byTenant, tenantID)
+	s.mu.Unlock()
+}
+
+// allows reports whether the rules admit an address. Denied ranges take
+// precedence; when an allowlist is set, only its ranges are admitted.
+func (r *ipAccessRules) allows(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return len(r.allow) == 0
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range r.deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if len(r.allow) == 0 {
+		return true
+	}
+	for _, prefix := range r.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPAccessLists validates and normalizes lists, rejecting invalid entries
+func parseIPAccessLists(lists IPAccessLists) (*ipAccessRules, error) {
+	rules := &ipAccessRules{lists: IPAccessLists{Allowlist: []string{}, Denylist: []string{}}}
+	var invalid []string
+	for _, entry := range lists.Allowlist {
+		prefix, err := parseIPRange(entry)
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		rules.allow = append(rules.allow, prefix)
+		rules.lists.Allowlist = append(rules.lists.Allowlist, prefix.String())
+	}
+	for _, entry := range lists.Denylist {
+		prefix, err := parseIPRange(entry)
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+		rules.deny = append(rules.deny, prefix)
+		rules.lists.Denylist = append(rules.lists.Denylist, prefix.String())
+	}
+	if len(invalid) > 0 {
+		return nil, apperrors.Validation("INVALID_IP_RANGE", "Entries must be CIDR ranges or IP addresses").
+			WithDetails(map[string]interface{}{"entries": invalid})
+	}
+	return rules, nil
+}
+
+// parseIPAccessListsLenient parses stored lists, skipping invalid entries
+func parseIPAccessListsLenient(lists IPAccessLists) *ipAccessRules {
+	rules := &ipAccessRules{lists: lists}
+	for _, entry := range lists.Allowlist {
+		if prefix, err := parseIPRange(entry); err == nil {
+			rules.allow = append(rules.allow, prefix)
+		}
+	}
+	for _, entry := range lists.Denylist {
+		if prefix, err := parseIPRange(entry); err == nil {
+			rules.deny = append(rules.deny, prefix)
+		}
+	}
+	return rules
+}
+
+// parseIPRange parses a CIDR range or a single address, normalizing the range
+// to its network address
+func parseIPRange(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if !strings.Contains(entry, "/") {
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if prefix.Addr().Is4In6() {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// validateIPAccessSetting checks the IP access lists of tenant settings written
+// directly, rather than through this service
+func validateIPAccessSetting(settings map[string]interface{}) error {
+	lists, err := ipAccessLists(settings)
+	if err != nil {
+		return apperrors.Validation("INVALID_IP_ACCESS_SETTINGS", "The ipAccess setting must have allowlist and denylist arrays")
+	}
+	if lists == nil {
+		return nil
+	}
+	_, err = parseIPAccessLists(*lists)
+	return err
+}
+
+// tenantSettings decodes a tenant's settings, returning an empty map when it has none
+func tenantSettings(tenant *models.Tenant) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	if len(tenant.Settings) > 0 {
+		if err := json.Unmarshal(tenant.Settings, &settings); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tenant settings: %w", err)
+		}
+		if settings == nil {
+			settings = make(map[string]interface{})
+		}
+	}
+	return settings, nil
+}
+
+// saveTenantSettings stores a tenant's settings
+func saveTenantSettings(tx *gorm.DB, tenant *models.Tenant, settings map[string]interface{}) error {
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant settings: %w", err)
+	}
+	tenant.Settings = encoded
+	if err := tx.Save(tenant).Error; err != nil {
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	return nil
+}
+
+// ipAccessLists extracts the IP access lists from tenant settings, or nil when
+// the tenant has none
+func ipAccessLists(settings map[string]interface{}) (*IPAccessLists, error) {
+	value, ok := settings[ipAccessSetting]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal IP access lists: %w", err)
+	}
+	lists := &IPAccessLists{}
+	if err := json.Unmarshal(encoded, lists); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal IP access lists: %w", err)
+	}
+	if lists.Allowlist == nil {
+		lists.Allowlist = []string{}
+	}
+	if lists.Denylist == nil {
+		lists.Denylist = []string{}
+	}
+	return lists, nil
+}
+
+func toIPAccessResponse(tenant *models.Tenant, lists *IPAccessLists) *IPAccessResponse {
+	return &IPAccessResponse{
+		TenantID:  tenant.ID.String(),
+		Allowlist: lists.Allowlist,
+		Denylist:  lists.Denylist,
+		UpdatedAt: tenant.UpdatedAt.Format(time.RFC3339),
+		ETag:      ResourceETag(tenant.UpdatedAt),
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestIPAccessService_Lists(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		service := NewIPAccessService(db)
+
+		if _, err := service.GetIPAccess(ctx, tenant.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found without lists, got %v", err)
+		}
+		if allowed, lists, err := service.IPAccess(ctx, tenant.ID.String(), "198.51.100.1"); err != nil || !allowed || lists != nil {
+			t.Fatalf("Expected any address to be admitted without lists, got %v, %v, %v", allowed, lists, err)
+		}
+
+		var appErr *apperrors.Error
+		_, _, err := service.UpsertIPAccess(ctx, tenant.ID, &UpsertIPAccessRequest{Allowlist: []string{"203.0.113.0/24", "corp-vpn"}}, Precondition{})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_IP_RANGE" {
+			t.Fatalf("Expected INVALID_IP_RANGE for an invalid entry, got %v", err)
+		}
+		_, _, err = service.UpsertIPAccess(ctx, tenant.ID, &UpsertIPAccessRequest{Allowlist: []string{"203.0.113.0/24"}, ClientIP: "198.51.100.1"}, Precondition{})
+		if !errors.As(err, &appErr) || appErr.Code != "IP_ACCESS_LOCKOUT" {
+			t.Fatalf("Expected IP_ACCESS_LOCKOUT for lists rejecting the caller, got %v", err)
+		}
+		_, _, err = service.UpsertIPAccess(ctx, uuid.New(), &UpsertIPAccessRequest{Allowlist: []string{"203.0.113.0/24"}}, Precondition{})
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found for an unknown tenant, got %v", err)
+		}
+
+		// Entries are normalized to network ranges
+		lists, created, err := service.UpsertIPAccess(ctx, tenant.ID, &UpsertIPAccessRequest{
+			Allowlist: []string{"203.0.113.10/24", "2001:db8::/32"},
+			Denylist:  []string{"203.0.113.66"},
+			ClientIP:  "203.0.113.5",
+		}, Precondition{})
+		if err != nil || !created {
+			t.Fatalf("Failed to create lists: %v", err)
+		}
+		if lists.Allowlist[0] != "203.0.113.0/24" || lists.Denylist[0] != "203.0.113.66/32" {
+			t.Errorf("Unexpected lists %+v", lists)
+		}
+
+		// Lookups reflect changes made through the service immediately
+		for ip, want := range map[string]bool{
+			"203.0.113.5":        true,
+			"::ffff:203.0.113.5": true,
+			"2001:db8::1":        true,
+			"203.0.113.66":       false,
+			"198.51.100.1":       false,
+			"not-an-ip":          false,
+		} {
+			allowed, policyLists, err := service.IPAccess(ctx, tenant.ID.String(), ip)
+			if err != nil || allowed != want {
+				t.Errorf("Expected %s admitted %v, got %v, %v", ip, want, allowed, err)
+			}
+			if policyLists == nil {
+				t.Errorf("Expected the lists to be returned for policies")
+			}
+		}
+
+		// Logins of the tenant's users are rejected from outside the lists
+		result, err := service.PostAuthenticate(ctx, &LoginHookContext{Stage: LoginHookStagePost, TenantID: tenant.ID.String(), IPAddress: "198.51.100.1"})
+		if err != nil || result == nil || result.Action != LoginHookActionReject {
+			t.Errorf("Expected the login to be rejected, got %+v, %v", result, err)
+		}
+		result, err = service.PostAuthenticate(ctx, &LoginHookContext{Stage: LoginHookStagePost, TenantID: tenant.ID.String(), IPAddress: "203.0.113.5"})
+		if err != nil || result != nil {
+			t.Errorf("Expected the login to be allowed, got %+v, %v", result, err)
+		}
+
+		// Users' emails name their tenant for checks before credentials
+		user := testutil.CreateTestUser(t, db, tenant, "user@acme.com")
+		if tenantID, err := service.EmailTenant(ctx, user.Email); err != nil || tenantID != tenant.ID.String() {
+			t.Errorf("Expected the user's tenant %s, got %q, %v", tenant.ID, tenantID, err)
+		}
+		if tenantID, err := service.EmailTenant(ctx, "unknown@acme.com"); err != nil || tenantID != "" {
+			t.Errorf("Expected no tenant for an unknown email, got %q, %v", tenantID, err)
+		}
+
+		_, _, err = service.UpsertIPAccess(ctx, tenant.ID, &UpsertIPAccessRequest{Denylist: []string{"198.51.100.0/24"}}, Precondition{IfNoneMatch: "*"})
+		if !errors.Is(err, apperrors.ErrPrecondition) {
+			t.Fatalf("Expected precondition failure creating existing lists, got %v", err)
+		}
+		lists, created, err = service.UpsertIPAccess(ctx, tenant.ID, &UpsertIPAccessRequest{Denylist: []string{"198.51.100.0/24"}}, Precondition{IfMatch: lists.ETag})
+		if err != nil || created {
+			t.Fatalf("Failed to replace lists: %v", err)
+		}
+		if allowed, _, _ := service.IPAccess(ctx, tenant.ID.String(), "192.0.2.1"); !allowed {
+			t.Errorf("Expected a denylist alone to admit other addresses")
+		}
+
+		// Lists written through the tenant settings are validated too
+		tenantService := NewTenantService(db)
+		_, err = tenantService.UpdateTenant(ctx, tenant.ID.String(), &UpdateTenantRequest{Settings: map[string]interface{}{
+			"ipAccess": map[string]interface{}{"allowlist": []string{"10.0.0.0/33"}},
+		}})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_IP_RANGE" {
+			t.Fatalf("Expected INVALID_IP_RANGE updating the tenant settings, got %v", err)
+		}
+
+		if err := service.DeleteIPAccess(ctx, tenant.ID); err != nil {
+			t.Fatalf("Failed to delete lists: %v", err)
+		}
+		if allowed, _, _ := service.IPAccess(ctx, tenant.ID.String(), "198.51.100.1"); !allowed {
+			t.Errorf("Expected any address to be admitted after deletion")
+		}
+		if err := service.DeleteIPAccess(ctx, tenant.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found deleting twice, got %v", err)
+		}
+	})
+}
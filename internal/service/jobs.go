@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Job types run by the job queue
+const (
+	JobBundleBuild   = "bundle.build"    // Builds a policy bundle and uploads it to bundle storage
+	JobPolicyGitSync = "policy.git_sync" // Syncs policies from the configured Git repository
+)
+
+const (
+	// jobClaimBatch is the number of due jobs a worker considers per claim, so
+	// workers racing for the oldest job fall back to the next ones
+	jobClaimBatch = 10
+
+	jobMaxBackoff = 10 * time.Minute
+)
+
+// JobHandler runs a job of a type and returns its result, stored as JSON for
+// clients polling the job. Jobs whose handler returns a permanentError are
+// marked dead without being retried.
+type JobHandler func(ctx context.Context, job *models.Job) (interface{}, error)
+
+// JobQueue runs asynchronous work, such as bundle builds, from a queue in the
+// database. Jobs are run by the workers of any instance, retried with
+// exponential backoff and marked dead once they run out of attempts. A worker
+// holds a lease on the job it runs; jobs of workers that died are taken over
+// once their lease expires.
+type JobQueue struct {
+	db  *gorm.DB
+	cfg *config.JobConfig
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+
+	// wake signals an idle worker that a job was enqueued
+	wake chan struct{}
+}
+
+// NewJobQueue creates a new job queue. A nil config runs 4 workers and
+// retries each job up to 5 times.
+func NewJobQueue(db *gorm.DB, cfg *config.JobConfig) *JobQueue {
+	if cfg == nil {
+		cfg = &config.JobConfig{Workers: 4, PollInterval: 5 * time.Second, MaxAttempts: 5, Lease: 10 * time.Minute}
+	}
+	return &JobQueue{
+		db:       db,
+		cfg:      cfg,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// JobResponse represents a job as reported to clients polling it
+type JobResponse struct {
+	ID          string      `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID    string      `json:"tenantId,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"` // Empty for platform-wide jobs
+	Type        string      `json:"type" example:"bundle.build"`
+	Status      string      `json:"status" example:"completed"` // pending, running, completed or dead
+	Attempts    int         `json:"attempts" example:"1"`
+	MaxAttempts int         `json:"maxAttempts" example:"5"`
+	LastError   string      `json:"lastError,omitempty" example:"failed to upload bundle: connection refused"`
+	Payload     interface{} `json:"payload,omitempty"`
+	Result      interface{} `json:"result,omitempty"`
+	CreatedBy   string      `json:"createdBy,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	RunAt       string      `json:"runAt" example:"2024-01-15T10:30:00Z"` // When a pending job is due, e.g. for its next retry
+	StartedAt   string      `json:"startedAt,omitempty" example:"2024-01-15T10:30:00Z"`
+	CompletedAt string      `json:"completedAt,omitempty" example:"2024-01-15T10:30:02Z"`
+	CreatedAt   string      `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt   string      `json:"updatedAt" example:"2024-01-15T10:30:02Z"`
+}
+
+// JobFilter narrows the jobs listed to a type
+type JobFilter struct {
+	Type string
+}
+
+// JobListOptions describes the sorting and filtering supported when listing jobs
+var JobListOptions = pagination.Options{
+	SortFields:   map[string]string{"createdAt": "created_at", "runAt": "run_at"},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// Register sets the handler running the jobs of a type
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue records a job and wakes an idle worker. tenantID is uuid.Nil for
+// platform-wide jobs and createdBy for jobs no user started.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType string, tenantID, createdBy uuid.UUID, payload interface{}) (*models.Job, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	job := &models.Job{
+		TenantID:    tenantID,
+		Type:        jobType,
+		Payload:     payloadJSON,
+		MaxAttempts: q.cfg.MaxAttempts,
+	}
+	if createdBy != uuid.Nil {
+		job.CreatedBy = &createdBy
+	}
+	if err := q.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Run starts the workers, which run due jobs until ctx is cancelled
+func (q *JobQueue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < max(q.cfg.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work runs due jobs one at a time, waiting for an enqueued job or the next
+// poll once none is due
+func (q *JobQueue) work(ctx context.Context) {
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			ran, err := q.RunNext(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to run job: %v", err)
+			}
+			if !ran || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// RunNext claims a due job of a registered type, runs it and records the
+// outcome. It reports whether a job ran. Jobs of types this instance has no
+// handler for are left to other instances.
+func (q *JobQueue) RunNext(ctx context.Context) (bool, error) {
+	q.mu.RLock()
+	types := make([]string, 0, len(q.handlers))
+	for jobType := range q.handlers {
+		types = append(types, jobType)
+	}
+	q.mu.RUnlock()
+	if len(types) == 0 {
+		return false, nil
+	}
+
+	var due []models.Job
+	if err := q.db.WithContext(ctx).
+		Where("status IN ? AND run_at <= ?", []string{models.JobStatusPending, models.JobStatusRunning}, time.Now()).
+		Where("type IN ?", types).
+		Order("run_at ASC").
+		Limit(jobClaimBatch).
+		Find(&due).Error; err != nil {
+		return false, fmt.Errorf("failed to load due jobs: %w", err)
+	}
+
+	for i := range due {
+		job := &due[i]
+		claimed, err := q.claim(ctx, job)
+		if err != nil {
+			return false, err
+		}
+		if claimed {
+			return true, q.run(ctx, job)
+		}
+	}
+	return false, nil
+}
+
+// claim takes a job for this worker by bumping its attempt count and leasing
+// it. Another worker that loaded the same job fails to claim it.
+func (q *JobQueue) claim(ctx context.Context, job *models.Job) (bool, error) {
+	now := time.Now()
+	result := q.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, job.Status, job.Attempts).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusRunning,
+			"attempts":   job.Attempts + 1,
+			"run_at":     now.Add(q.cfg.Lease),
+			"started_at": now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim job: %w", result.Error)
+	}
+	job.Status = models.JobStatusRunning
+	job.Attempts++
+	job.StartedAt = &now
+	return result.RowsAffected == 1, nil
+}
+
+// run runs a claimed job within its lease and records the outcome. Jobs whose
+// worker lost the lease are left to the worker that took them over.
+func (q *JobQueue) run(ctx context.Context, job *models.Job) error {
+	var result interface{}
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// The previous worker died running the last attempt
+		err = &permanentError{err: errors.New("job lease expired on its last attempt")}
+	} else {
+		result, err = q.call(ctx, job)
+	}
+
+	// Record the outcome even when shutting down, or the job waits for its lease
+	ctx = context.WithoutCancel(ctx)
+	updates := map[string]interface{}{}
+	now := time.Now()
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		resultJSON, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			return fmt.Errorf("failed to marshal result of job %s: %w", job.ID, marshalErr)
+		}
+		updates["status"] = models.JobStatusCompleted
+		updates["result"] = datatypes.JSON(resultJSON)
+		updates["last_error"] = ""
+		updates["completed_at"] = now
+
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		log.Printf("Job %s (%s) failed after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+		updates["status"] = models.JobStatusDead
+		updates["last_error"] = err.Error()
+		updates["completed_at"] = now
+
+	default:
+		updates["status"] = models.JobStatusPending
+		updates["last_error"] = err.Error()
+		updates["run_at"] = now.Add(retryBackoff(job.Attempts, jobMaxBackoff))
+	}
+
+	if err := q.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND attempts = ?", job.ID, job.Attempts).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record outcome of job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// call runs a job's handler, bounded by the job's lease. Panics fail the attempt.
+func (q *JobQueue) call(ctx context.Context, job *models.Job) (result interface{}, err error) {
+	q.mu.RLock()
+	handler := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if handler == nil {
+		return nil, &permanentError{err: fmt.Errorf("unknown job type %q", job.Type)}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.cfg.Lease)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// GetJob retrieves a job of the caller's tenant, or one the caller started
+func (q *JobQueue) GetJob(ctx context.Context, tenantID, userID uuid.UUID, jobID string) (*JobResponse, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_JOB_ID", "Invalid job ID").WithCause(err)
+	}
+
+	var job models.Job
+	err = readReplica(q.db).WithContext(ctx).
+		Where("id = ? AND (tenant_id = ? OR created_by = ?)", id, tenantID, userID).
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("JOB_NOT_FOUND", "Job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return toJobResponse(&job), nil
+}
+
+// ListJobs returns a page of the jobs of the caller's tenant, optionally
+// filtered by type and, through the pagination parameters, by status
+func (q *JobQueue) ListJobs(ctx context.Context, tenantID uuid.UUID, filter JobFilter, params *pagination.Params) ([]JobResponse, *pagination.Page, error) {
+	query := readReplica(q.db).Model(&models.Job{}).Where("tenant_id = ?", tenantID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	jobs, page, err := pagination.Paginate[models.Job](ctx, query, params, JobListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	responses := make([]JobResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = *toJobResponse(&jobs[i])
+	}
+	return responses, page, nil
+}
+
+func toJobResponse(job *models.Job) *JobResponse {
+	response := &JobResponse{
+		ID:          job.ID.String(),
+		Type:        job.Type,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		RunAt:       job.RunAt.UTC().Format(time.RFC3339),
+		CreatedAt:   job.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   job.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if job.TenantID != uuid.Nil {
+		response.TenantID = job.TenantID.String()
+	}
+	if job.CreatedBy != nil {
+		response.CreatedBy = job.CreatedBy.String()
+	}
+	if job.StartedAt != nil {
+		response.StartedAt = job.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if job.CompletedAt != nil {
+		response.CompletedAt = job.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	if len(job.Payload) > 0 {
+		json.Unmarshal(job.Payload, &response.Payload)
+	}
+	if len(job.Result) > 0 {
+		json.Unmarshal(job.Result, &response.Result)
+	}
+	return response
+}
+
+// retryBackoff returns the delay before retrying after a number of failed
+// attempts, doubling from a second up to ceiling
+func retryBackoff(attempts int, ceiling time.Duration) time.Duration {
+	if attempts < 10 && time.Duration(1<<attempts)*time.Second < ceiling {
+		return time.Duration(1<<attempts) * time.Second
+	}
+	return ceiling
+}
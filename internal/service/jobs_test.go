@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestJobQueue(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		bob := testutil.CreateTestUser(t, db, globex, "bob@globex.test")
+
+		queue := NewJobQueue(db, &config.JobConfig{Workers: 1, PollInterval: time.Second, MaxAttempts: 2, Lease: time.Minute})
+		failures := 0
+		queue.Register("test.echo", func(ctx context.Context, job *models.Job) (interface{}, error) {
+			return map[string]string{"echo": string(job.Payload)}, nil
+		})
+		queue.Register("test.flaky", func(ctx context.Context, job *models.Job) (interface{}, error) {
+			failures++
+			return nil, errors.New("connection refused")
+		})
+		queue.Register("test.invalid", func(ctx context.Context, job *models.Job) (interface{}, error) {
+			return nil, &permanentError{err: errors.New("malformed payload")}
+		})
+
+		load := func(id uuid.UUID) *models.Job {
+			var job models.Job
+			if err := db.First(&job, "id = ?", id).Error; err != nil {
+				t.Fatalf("Failed to load job: %v", err)
+			}
+			return &job
+		}
+		runNext := func() bool {
+			ran, err := queue.RunNext(ctx)
+			if err != nil {
+				t.Fatalf("RunNext failed: %v", err)
+			}
+			return ran
+		}
+
+		// Completed jobs keep their handler's result
+		echo, err := queue.Enqueue(ctx, "test.echo", acme.ID, alice.ID, "hello")
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		if !runNext() {
+			t.Fatal("Expected the due job to run")
+		}
+		if job := load(echo.ID); job.Status != models.JobStatusCompleted || job.Attempts != 1 || job.CompletedAt == nil ||
+			string(job.Result) != `{"echo":"\"hello\""}` {
+			t.Errorf("Expected the job to complete with its result, got %+v", job)
+		}
+
+		// Failed jobs are retried with backoff until they run out of attempts
+		flaky, err := queue.Enqueue(ctx, "test.flaky", acme.ID, alice.ID, nil)
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		runNext()
+		job := load(flaky.ID)
+		if job.Status != models.JobStatusPending || job.LastError != "connection refused" || !job.RunAt.After(time.Now()) {
+			t.Fatalf("Expected the job to be retried later, got %+v", job)
+		}
+		if runNext() {
+			t.Error("Expected the job to wait for its backoff")
+		}
+		db.Model(&models.Job{}).Where("id = ?", flaky.ID).Update("run_at", time.Now().Add(-time.Second))
+		runNext()
+		if job := load(flaky.ID); job.Status != models.JobStatusDead || job.Attempts != 2 || failures != 2 {
+			t.Errorf("Expected the job to die after 2 attempts, got %+v after %d failures", job, failures)
+		}
+
+		// Permanent failures are not retried
+		invalid, err := queue.Enqueue(ctx, "test.invalid", acme.ID, uuid.Nil, nil)
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		runNext()
+		if job := load(invalid.ID); job.Status != models.JobStatusDead || job.Attempts != 1 || job.LastError != "malformed payload" {
+			t.Errorf("Expected the job to die on its first attempt, got %+v", job)
+		}
+
+		// Jobs of workers that died are taken over once their lease expires
+		stale, err := queue.Enqueue(ctx, "test.echo", acme.ID, alice.ID, "again")
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		db.Model(&models.Job{}).Where("id = ?", stale.ID).Updates(map[string]interface{}{
+			"status": models.JobStatusRunning, "attempts": 1, "run_at": time.Now().Add(-time.Second),
+		})
+		runNext()
+		if job := load(stale.ID); job.Status != models.JobStatusCompleted || job.Attempts != 2 {
+			t.Errorf("Expected the job to be taken over, got %+v", job)
+		}
+
+		// Jobs of types without a handler are left to other instances
+		if _, err := queue.Enqueue(ctx, "test.elsewhere", acme.ID, alice.ID, nil); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		if runNext() {
+			t.Error("Expected jobs of unknown types to be left alone")
+		}
+
+		// Jobs are visible to their tenant and to the user who started them
+		if got, err := queue.GetJob(ctx, acme.ID, alice.ID, echo.ID.String()); err != nil || got.Status != models.JobStatusCompleted {
+			t.Errorf("Expected the tenant's job, got %+v, %v", got, err)
+		}
+		if _, err := queue.GetJob(ctx, globex.ID, bob.ID, echo.ID.String()); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected another tenant's job to be hidden, got %v", err)
+		}
+		platform, err := queue.Enqueue(ctx, "test.echo", uuid.Nil, bob.ID, "platform")
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+		if _, err := queue.GetJob(ctx, globex.ID, bob.ID, platform.ID.String()); err != nil {
+			t.Errorf("Expected the user's platform job, got %v", err)
+		}
+
+		params, err := pagination.Parse(func(key string, defaultValue ...string) string {
+			if key == "status" {
+				return models.JobStatusDead
+			}
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}, JobListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		dead, page, err := queue.ListJobs(ctx, acme.ID, JobFilter{Type: "test.flaky"}, params)
+		if err != nil || page.Total != 1 || dead[0].ID != flaky.ID.String() {
+			t.Errorf("Expected the dead flaky job, got %+v, %v", dead, err)
+		}
+	})
+}
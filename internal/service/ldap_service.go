@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// LDAPDirectory authenticates and looks up users in an LDAP directory
+type LDAPDirectory interface {
+	Authenticate(username, password string) (*auth.LDAPUser, error)
+	Lookup(dn string) (*auth.LDAPUser, error)
+}
+
+// LDAPSyncReport summarizes a group membership sync
+type LDAPSyncReport struct {
+	Users   int `json:"users"`
+	Missing int `json:"missing"` // Users no longer in the directory, whose mapped roles were removed
+	Failed  int `json:"failed"`
+}
+
+// LDAPService authenticates users against an LDAP directory, provisions local
+// users on first login and keeps their roles in line with the directory groups
+// mapped to roles. Roles that no mapping mentions are never changed.
+type LDAPService struct {
+	db         *gorm.DB
+	directory  LDAPDirectory
+	tenantSlug string
+	mappings   []config.LDAPGroupMapping
+	rbacSync   *RBACDataSync
+}
+
+// NewLDAPService creates a new LDAP service
+func NewLDAPService(db *gorm.DB, directory LDAPDirectory, cfg *config.LDAPConfig) *LDAPService {
+	return &LDAPService{
+		db:         db,
+		directory:  directory,
+		tenantSlug: cfg.TenantSlug,
+		mappings:   cfg.GroupMappings,
+	}
+}
+
+// SetRBACDataSync pushes role changes made by group syncs to OPA
+func (s *LDAPService) SetRBACDataSync(rbacSync *RBACDataSync) {
+	s.rbacSync = rbacSync
+}
+
+// Login authenticates a user against the directory and returns the local user,
+// provisioning it on first login. Unknown users and wrong passwords both return
+// auth.ErrInvalidCredentials.
+func (s *LDAPService) Login(ctx context.Context, email, password string) (*auth.IdentityUser, error) {
+	entry, err := s.directory.Authenticate(email, password)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Email == "" {
+		entry.Email = email
+	}
+
+	var user *models.User
+	var rolesChanged bool
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = s.provision(tx, entry); err != nil {
+			return err
+		}
+		rolesChanged, err = s.syncRoles(tx, user, entry.Groups)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rolesChanged {
+		s.rbacSync.TenantChanged(ctx, user.TenantID)
+	}
+
+	return &auth.IdentityUser{
+		ID:        user.ID.String(),
+		Email:     user.Email,
+		FirstName: entry.FirstName,
+		LastName:  entry.LastName,
+		Active:    true,
+		Verified:  true,
+	}, nil
+}
+
+// provision returns the local user linked to a directory entry. Unlinked entries
+// are linked to the tenant's user with the same email, or a new user is created.
+func (s *LDAPService) provision(tx *gorm.DB, entry *auth.LDAPUser) (*models.User, error) {
+	var identity models.LDAPIdentity
+	err := tx.Where("dn = ?", entry.DN).First(&identity).Error
+	if err == nil {
+		var user models.User
+		if err := tx.First(&user, "id = ?", identity.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperrors.Forbidden("USER_DELETED", "User account has been deleted")
+			}
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if !strings.EqualFold(user.Email, entry.Email) {
+			if err := tx.Model(&user).Update("email", entry.Email).Error; err != nil {
+				return nil, fmt.Errorf("failed to update user email: %w", err)
+			}
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get LDAP identity: %w", err)
+	}
+
+	var tenant models.Tenant
+	if err := tx.Where("slug = ? AND status = ?", s.tenantSlug, "active").First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "LDAP tenant not found or inactive")
+		}
+		return nil, fmt.Errorf("failed to get LDAP tenant: %w", err)
+	}
+
+	var user models.User
+	err = tx.Where("tenant_id = ? AND LOWER(email) = ?", tenant.ID, strings.ToLower(entry.Email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		metadata, err := json.Marshal(map[string]interface{}{
+			"firstName": entry.FirstName,
+			"lastName":  entry.LastName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		user = models.User{
+			ID:       uuid.New(),
+			TenantID: tenant.ID,
+			Email:    entry.Email,
+			Metadata: metadata,
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return nil, fmt.Errorf("failed to create user record: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := tx.Create(&models.LDAPIdentity{UserID: user.ID, DN: entry.DN}).Error; err != nil {
+		return nil, fmt.Errorf("failed to link LDAP identity: %w", err)
+	}
+	return &user, nil
+}
+
+// syncRoles assigns the mapped roles of the user's tenant for the groups it is a
+// member of and removes the mapped roles for groups it is not. It reports whether
+// the user's roles changed.
+func (s *LDAPService) syncRoles(tx *gorm.DB, user *models.User, groups []string) (bool, error) {
+	var tenant models.Tenant
+	if err := tx.Select("id", "slug").First(&tenant, "id = ?", user.TenantID).Error; err != nil {
+		return false, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	memberOf := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		memberOf[strings.ToLower(group)] = true
+	}
+	var managed []string
+	desired := map[string]bool{}
+	for _, mapping := range s.mappings {
+		if mapping.Tenant != tenant.Slug {
+			continue
+		}
+		managed = append(managed, mapping.Role)
+		if memberOf[strings.ToLower(mapping.Group)] {
+			desired[mapping.Role] = true
+		}
+	}
+	return syncMappedRoles(tx, user.ID, tenant.ID, managed, desired)
+}
+
+// Run syncs group memberships every interval until ctx is cancelled
+func (s *LDAPService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := s.Sync(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to sync LDAP groups: %v", err)
+			}
+			continue
+		}
+		if report.Missing > 0 || report.Failed > 0 {
+			log.Printf("LDAP group sync: %d users, %d no longer in the directory, %d failed",
+				report.Users, report.Missing, report.Failed)
+		}
+	}
+}
+
+// Sync looks up every user provisioned from the directory and applies their
+// current group memberships. Users removed from the directory lose their mapped
+// roles but are not deleted.
+func (s *LDAPService) Sync(ctx context.Context) (*LDAPSyncReport, error) {
+	var identities []models.LDAPIdentity
+	if err := s.db.WithContext(ctx).Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to load LDAP identities: %w", err)
+	}
+
+	report := &LDAPSyncReport{Users: len(identities)}
+	changedTenants := map[uuid.UUID]bool{}
+	for _, identity := range identities {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var groups []string
+		entry, err := s.directory.Lookup(identity.DN)
+		switch {
+		case errors.Is(err, auth.ErrUserNotFound):
+			report.Missing++
+		case err != nil:
+			log.Printf("Failed to look up LDAP user %s: %v", identity.DN, err)
+			report.Failed++
+			continue
+		default:
+			groups = entry.Groups
+		}
+
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var user models.User
+			if err := tx.First(&user, "id = ?", identity.UserID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			changed, err := s.syncRoles(tx, &user, groups)
+			if err != nil {
+				return err
+			}
+			if changed {
+				changedTenants[user.TenantID] = true
+			}
+			return tx.Model(&identity).Update("synced_at", time.Now()).Error
+		})
+		if err != nil {
+			log.Printf("Failed to sync LDAP groups of user %s: %v", identity.UserID, err)
+			report.Failed++
+		}
+	}
+
+	for tenantID := range changedTenants {
+		s.rbacSync.TenantChanged(ctx, tenantID)
+	}
+	return report, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+const (
+	ldapAdminsGroup  = "CN=Admins,OU=Groups,DC=example,DC=com"
+	ldapViewersGroup = "CN=Viewers,OU=Groups,DC=example,DC=com"
+)
+
+// fakeDirectory is an in-memory LDAP directory keyed by email
+type fakeDirectory struct {
+	users     map[string]*auth.LDAPUser
+	passwords map[string]string
+}
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{users: map[string]*auth.LDAPUser{}, passwords: map[string]string{}}
+}
+
+func (d *fakeDirectory) addUser(email, password string, groups ...string) *auth.LDAPUser {
+	user := &auth.LDAPUser{
+		DN:        "CN=" + email + ",OU=People,DC=example,DC=com",
+		Email:     email,
+		FirstName: "Directory",
+		LastName:  "User",
+		Groups:    groups,
+	}
+	d.users[email] = user
+	d.passwords[email] = password
+	return user
+}
+
+func (d *fakeDirectory) Authenticate(username, password string) (*auth.LDAPUser, error) {
+	user, ok := d.users[username]
+	if !ok || d.passwords[username] != password {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func (d *fakeDirectory) Lookup(dn string) (*auth.LDAPUser, error) {
+	for _, user := range d.users {
+		if user.DN == dn {
+			return user, nil
+		}
+	}
+	return nil, auth.ErrUserNotFound
+}
+
+func newTestLDAPService(db *gorm.DB, directory *fakeDirectory) *LDAPService {
+	return NewLDAPService(db, directory, &config.LDAPConfig{
+		TenantSlug: "test-corp",
+		GroupMappings: []config.LDAPGroupMapping{
+			{Group: ldapAdminsGroup, Tenant: "test-corp", Role: "admin"},
+			{Group: strings.ToLower(ldapViewersGroup), Tenant: "test-corp", Role: "viewer"},
+			{Group: ldapAdminsGroup, Tenant: "other-corp", Role: "admin"},
+		},
+	})
+}
+
+func roleNamesOf(t *testing.T, db *gorm.DB, userID uuid.UUID) []string {
+	t.Helper()
+	roles, err := NewUserRepository(db).GetUserRoles(testutil.CreateTestContext(t), userID)
+	if err != nil {
+		t.Fatalf("Failed to get user roles: %v", err)
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestAuthService_Login_LDAP(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		_, fusionAuth := newFakeFusionAuth(t)
+		authService := NewAuthService(db, fusionAuth, jwtService, nil, nil, nil)
+		directory := newFakeDirectory()
+		authService.SetLDAPService(newTestLDAPService(db, directory))
+
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		testutil.CreateTestRole(t, db, tenant, "admin")
+		testutil.CreateTestRole(t, db, tenant, "viewer")
+		manual := testutil.CreateTestRole(t, db, tenant, "manual")
+		ctx := testutil.CreateTestContext(t)
+
+		entry := directory.addUser("alice@example.com", "DirectoryPassword1!", ldapAdminsGroup)
+
+		resp, err := authService.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "DirectoryPassword1!"})
+		if err != nil {
+			t.Fatalf("Login returned error: %v", err)
+		}
+		if resp.User.TenantID != tenant.ID.String() || resp.User.FirstName != "Directory" {
+			t.Errorf("Unexpected user info: %+v", resp.User)
+		}
+		userID := uuid.MustParse(resp.User.ID)
+		var identity models.LDAPIdentity
+		if err := db.First(&identity, "user_id = ?", userID).Error; err != nil || identity.DN != entry.DN {
+			t.Fatalf("Expected the user to be linked to %s, got %+v (%v)", entry.DN, identity, err)
+		}
+		if roles := roleNamesOf(t, db, userID); len(roles) != 1 || roles[0] != "admin" {
+			t.Errorf("Expected the admin role from the directory, got %v", roles)
+		}
+
+		// Group changes apply on the next login, leaving unmapped roles alone
+		testutil.AssignRoleToUser(t, db, &models.User{ID: userID}, manual)
+		entry.Groups = []string{ldapViewersGroup}
+		resp, err = authService.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "DirectoryPassword1!"})
+		if err != nil {
+			t.Fatalf("Second login returned error: %v", err)
+		}
+		if resp.User.ID != userID.String() {
+			t.Errorf("Expected the same user on the second login, got %s", resp.User.ID)
+		}
+		if roles := roleNamesOf(t, db, userID); strings.Join(roles, ",") != "manual,viewer" {
+			t.Errorf("Expected roles manual,viewer, got %v", roles)
+		}
+
+		var count int64
+		db.Model(&models.User{}).Where("email = ?", "alice@example.com").Count(&count)
+		if count != 1 {
+			t.Errorf("Expected one provisioned user, got %d", count)
+		}
+
+		if _, err := authService.Login(ctx, &LoginRequest{Email: "alice@example.com", Password: "wrong"}); err == nil {
+			t.Error("Expected login with a wrong password to fail")
+		}
+	})
+}
+
+func TestLDAPService_LinksExistingUser(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		directory := newFakeDirectory()
+		ldapService := newTestLDAPService(db, directory)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		existing := testutil.CreateTestUser(t, db, tenant, "bob@example.com")
+		directory.addUser("Bob@Example.com", "DirectoryPassword1!")
+
+		user, err := ldapService.Login(testutil.CreateTestContext(t), "Bob@Example.com", "DirectoryPassword1!")
+		if err != nil {
+			t.Fatalf("Login returned error: %v", err)
+		}
+		if user.ID != existing.ID.String() {
+			t.Errorf("Expected the existing user %s to be linked, got %s", existing.ID, user.ID)
+		}
+	})
+}
+
+func TestLDAPService_Sync(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		directory := newFakeDirectory()
+		ldapService := newTestLDAPService(db, directory)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		testutil.CreateTestRole(t, db, tenant, "admin")
+		testutil.CreateTestRole(t, db, tenant, "viewer")
+		ctx := testutil.CreateTestContext(t)
+
+		alice := directory.addUser("alice@example.com", "DirectoryPassword1!", ldapAdminsGroup)
+		directory.addUser("carol@example.com", "DirectoryPassword1!", ldapAdminsGroup)
+		aliceUser, err := ldapService.Login(ctx, "alice@example.com", "DirectoryPassword1!")
+		if err != nil {
+			t.Fatalf("Login returned error: %v", err)
+		}
+		carolUser, err := ldapService.Login(ctx, "carol@example.com", "DirectoryPassword1!")
+		if err != nil {
+			t.Fatalf("Login returned error: %v", err)
+		}
+
+		alice.Groups = []string{ldapAdminsGroup, ldapViewersGroup}
+		delete(directory.users, "carol@example.com")
+
+		report, err := ldapService.Sync(ctx)
+		if err != nil {
+			t.Fatalf("Sync returned error: %v", err)
+		}
+		if report.Users != 2 || report.Missing != 1 || report.Failed != 0 {
+			t.Errorf("Unexpected sync report: %+v", report)
+		}
+		if roles := roleNamesOf(t, db, uuid.MustParse(aliceUser.ID)); strings.Join(roles, ",") != "admin,viewer" {
+			t.Errorf("Expected roles admin,viewer, got %v", roles)
+		}
+		if roles := roleNamesOf(t, db, uuid.MustParse(carolUser.ID)); len(roles) != 0 {
+			t.Errorf("Expected a user removed from the directory to lose mapped roles, got %v", roles)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// leaderReleaseTimeout bounds releasing a lease on shutdown, when the task's
+// context is already cancelled
+const leaderReleaseTimeout = 5 * time.Second
+
+// LeaderElector runs singleton background tasks, such as the scheduled
+// cleanup, on one instance at a time when several instances share the
+// database. Each task is guarded by a lease in the leader_leases table that its
+// leader renews; when the leader stops or loses its connection to the
+// database, another instance takes over once the lease expires.
+//
+// Instances compare lease expiry with their own clocks, which must be kept in
+// sync to well within the lease TTL.
+type LeaderElector struct {
+	db     *gorm.DB
+	holder string
+	ttl    time.Duration
+}
+
+// NewLeaderElector creates a new leader elector for the instance holder
+func NewLeaderElector(db *gorm.DB, holder string, ttl time.Duration) *LeaderElector {
+	return &LeaderElector{
+		db:     db,
+		holder: holder,
+		ttl:    ttl,
+	}
+}
+
+// Acquire acquires or renews the lease of the task name and reports whether
+// this instance holds it
+func (e *LeaderElector) Acquire(ctx context.Context, name string) (bool, error) {
+	db := e.db.WithContext(ctx)
+	now := time.Now()
+	expiresAt := now.Add(e.ttl)
+
+	// Renew the lease this instance holds
+	result := db.Model(&models.LeaderLease{}).
+		Where("name = ? AND holder = ?", name, e.holder).
+		Update("expires_at", expiresAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to renew lease: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// Take over the lease of a leader that stopped renewing it
+	result = db.Model(&models.LeaderLease{}).
+		Where("name = ? AND expires_at < ?", name, now).
+		Updates(map[string]interface{}{"holder": e.holder, "expires_at": expiresAt, "acquired_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to take over lease: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// Create the lease of a task no instance has run yet
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LeaderLease{
+		Name:       name,
+		Holder:     e.holder,
+		ExpiresAt:  expiresAt,
+		AcquiredAt: now,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create lease: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release gives up the lease of the task name, if this instance holds it, so
+// another instance can take over right away
+func (e *LeaderElector) Release(ctx context.Context, name string) error {
+	err := e.db.WithContext(ctx).Model(&models.LeaderLease{}).
+		Where("name = ? AND holder = ?", name, e.holder).
+		Update("expires_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// Run runs task while this instance leads the task name, until ctx is
+// cancelled. The lease is renewed every third of its TTL; task's context is
+// cancelled, and Run waits for task to return, when the lease is lost or can
+// no longer be renewed before it expires.
+func (e *LeaderElector) Run(ctx context.Context, name string, task func(ctx context.Context)) {
+	renewEvery := e.ttl / 3
+	ticker := time.NewTicker(renewEvery)
+	defer ticker.Stop()
+
+	var (
+		stop    context.CancelFunc
+		done    chan struct{}
+		renewed time.Time
+	)
+	resign := func() {
+		stop()
+		<-done
+		stop = nil
+	}
+	defer func() {
+		if stop == nil {
+			return
+		}
+		resign()
+		releaseCtx, cancel := context.WithTimeout(context.Background(), leaderReleaseTimeout)
+		defer cancel()
+		if err := e.Release(releaseCtx, name); err != nil {
+			log.Printf("Failed to release leadership of %s: %v", name, err)
+		}
+	}()
+
+	for {
+		leader, err := e.Acquire(ctx, name)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to acquire leadership of %s: %v", name, err)
+		}
+
+		now := time.Now()
+		switch {
+		case err == nil && leader:
+			renewed = now
+			if stop == nil {
+				log.Printf("Instance %s is now the leader of %s", e.holder, name)
+				taskCtx, cancel := context.WithCancel(ctx)
+				stop = cancel
+				done = make(chan struct{})
+				go func() {
+					defer close(done)
+					task(taskCtx)
+				}()
+			}
+		case stop != nil && (err == nil || now.Add(renewEvery).After(renewed.Add(e.ttl))):
+			// Another instance took over, or it may before the next renewal
+			log.Printf("Instance %s is no longer the leader of %s", e.holder, name)
+			resign()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestLeaderElector(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		first := NewLeaderElector(db, "heimdall-1", time.Minute)
+		second := NewLeaderElector(db, "heimdall-2", time.Minute)
+		acquire := func(elector *LeaderElector) bool {
+			t.Helper()
+			leader, err := elector.Acquire(ctx, "cleanup")
+			if err != nil {
+				t.Fatalf("Acquire failed: %v", err)
+			}
+			return leader
+		}
+
+		// Only one instance leads a task, and keeps it while it renews the lease
+		if !acquire(first) {
+			t.Fatal("Expected the first instance to lead")
+		}
+		if acquire(second) {
+			t.Error("Expected the second instance not to lead")
+		}
+		if !acquire(first) {
+			t.Error("Expected the leader to renew its lease")
+		}
+		if leader, err := second.Acquire(ctx, "reencrypt"); err != nil || !leader {
+			t.Errorf("Expected other tasks to be led independently, got %v, %v", leader, err)
+		}
+
+		// Expired leases are taken over
+		db.Model(&models.LeaderLease{}).Where("name = ?", "cleanup").Update("expires_at", time.Now().Add(-time.Second))
+		if !acquire(second) {
+			t.Fatal("Expected the second instance to take over the expired lease")
+		}
+		if acquire(first) {
+			t.Error("Expected the former leader not to lead")
+		}
+
+		// Released leases are taken over right away
+		if err := second.Release(ctx, "cleanup"); err != nil {
+			t.Fatalf("Release failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if !acquire(first) {
+			t.Error("Expected the released lease to be taken over")
+		}
+
+		// Run runs the task only while leading it, and releases it when stopped
+		runCtx, stop := context.WithCancel(ctx)
+		started := make(chan struct{})
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			NewLeaderElector(db, "heimdall-3", time.Minute).Run(runCtx, "purge", func(ctx context.Context) {
+				close(started)
+				<-ctx.Done()
+			})
+		}()
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the task to start")
+		}
+		if leader, _ := first.Acquire(ctx, "purge"); leader {
+			t.Error("Expected the running leader to keep the task")
+		}
+		stop()
+		<-finished
+		time.Sleep(10 * time.Millisecond)
+		if leader, _ := first.Acquire(ctx, "purge"); !leader {
+			t.Error("Expected the task to be released when its leader stops")
+		}
+	})
+}
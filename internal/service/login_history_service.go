@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/geo"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/gorm"
+)
+
+// Suspicious login reasons
+const (
+	LoginReasonNewDevice  = "new_device"
+	LoginReasonNewCountry = "new_country"
+)
+
+// LoginContext describes a successful login to be recorded
+type LoginContext struct {
+	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	Email     string
+	IPAddress string
+	UserAgent string
+	MFA       bool // A login hook verified a second factor
+}
+
+// LoginHistoryService records login context and detects suspicious logins
+type LoginHistoryService struct {
+	db          *gorm.DB
+	geoResolver geo.Resolver
+	events      events.Publisher
+	mailer      notify.Mailer
+}
+
+// NewLoginHistoryService creates a new login history service.
+// A nil mailer disables email notifications.
+func NewLoginHistoryService(db *gorm.DB, geoResolver geo.Resolver, publisher events.Publisher, mailer notify.Mailer) *LoginHistoryService {
+	if geoResolver == nil {
+		geoResolver = geo.NoopResolver{}
+	}
+	if publisher == nil {
+		publisher = events.NoopPublisher{}
+	}
+	if mailer == nil {
+		mailer = notify.NoopMailer{}
+	}
+	return &LoginHistoryService{
+		db:          db,
+		geoResolver: geoResolver,
+		events:      publisher,
+		mailer:      mailer,
+	}
+}
+
+// RecordLogin stores the login context and flags logins from unseen devices or countries
+func (s *LoginHistoryService) RecordLogin(ctx context.Context, login *LoginContext) (*models.LoginEvent, error) {
+	location, err := s.geoResolver.Resolve(ctx, login.IPAddress)
+	if err != nil {
+		// Geo lookup is best effort; continue without a location
+		location = &geo.Location{}
+	}
+
+	event := &models.LoginEvent{
+		UserID:            login.UserID,
+		TenantID:          login.TenantID,
+		IPAddress:         login.IPAddress,
+		UserAgent:         login.UserAgent,
+		DeviceFingerprint: DeviceFingerprint(login.UserAgent),
+		Country:           location.Country,
+		Region:            location.Region,
+		City:              location.City,
+		MFA:               login.MFA,
+	}
+
+	reasons, err := s.detectAnomalies(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if len(reasons) > 0 {
+		event.Suspicious = true
+		reasonsJSON, err := json.Marshal(reasons)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal reasons: %w", err)
+		}
+		event.Reasons = reasonsJSON
+	}
+
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		return nil, fmt.Errorf("failed to record login: %w", err)
+	}
+
+	if event.Suspicious {
+		s.notifySuspiciousLogin(ctx, login, event, reasons)
+	}
+
+	return event, nil
+}
+
+// LoginHistoryListOptions describes the sorting supported when listing login history
+var LoginHistoryListOptions = pagination.Options{
+	SortFields:  map[string]string{"createdAt": "created_at"},
+	DefaultSort: "-createdAt",
+}
+
+// GetLoginHistory returns a page of a user's logins
+func (s *LoginHistoryService) GetLoginHistory(ctx context.Context, userID string, params *pagination.Params) ([]models.LoginEvent, *pagination.Page, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+
+	query := readReplica(s.db).Model(&models.LoginEvent{}).Where("user_id = ?", userUUID)
+	history, page, err := pagination.Paginate[models.LoginEvent](ctx, query, params, LoginHistoryListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get login history: %w", err)
+	}
+
+	return history, page, nil
+}
+
+// detectAnomalies compares a login against the user's previous logins.
+// The first recorded login is never flagged since there is no baseline.
+func (s *LoginHistoryService) detectAnomalies(ctx context.Context, event *models.LoginEvent) ([]string, error) {
+	var previous int64
+	if err := s.db.WithContext(ctx).Model(&models.LoginEvent{}).
+		Where("user_id = ?", event.UserID).
+		Count(&previous).Error; err != nil {
+		return nil, fmt.Errorf("failed to check login history: %w", err)
+	}
+	if previous == 0 {
+		return nil, nil
+	}
+
+	var reasons []string
+
+	var seenDevice int64
+	if err := s.db.WithContext(ctx).Model(&models.LoginEvent{}).
+		Where("user_id = ? AND device_fingerprint = ?", event.UserID, event.DeviceFingerprint).
+		Count(&seenDevice).Error; err != nil {
+		return nil, fmt.Errorf("failed to check known devices: %w", err)
+	}
+	if seenDevice == 0 {
+		reasons = append(reasons, LoginReasonNewDevice)
+	}
+
+	if event.Country != "" {
+		var seenCountry int64
+		if err := s.db.WithContext(ctx).Model(&models.LoginEvent{}).
+			Where("user_id = ? AND country = ?", event.UserID, event.Country).
+			Count(&seenCountry).Error; err != nil {
+			return nil, fmt.Errorf("failed to check known countries: %w", err)
+		}
+		if seenCountry == 0 {
+			reasons = append(reasons, LoginReasonNewCountry)
+		}
+	}
+
+	return reasons, nil
+}
+
+// notifySuspiciousLogin emits a security event and emails the user
+func (s *LoginHistoryService) notifySuspiciousLogin(ctx context.Context, login *LoginContext, event *models.LoginEvent, reasons []string) {
+	s.events.Publish(ctx, events.NewEvent(events.EventSuspiciousLogin, login.TenantID.String(), map[string]interface{}{
+		"userId":    login.UserID.String(),
+		"email":     login.Email,
+		"ipAddress": event.IPAddress,
+		"userAgent": event.UserAgent,
+		"country":   event.Country,
+		"city":      event.City,
+		"reasons":   reasons,
+	}))
+
+	if login.Email == "" {
+		return
+	}
+
+	location := strings.Trim(strings.Join([]string{event.City, event.Country}, ", "), ", ")
+	if location == "" {
+		location = "unknown location"
+	}
+
+	body := fmt.Sprintf(
+		"We noticed a new sign-in to your account.\n\n"+
+			"Time: %s\nIP address: %s\nLocation: %s\nDevice: %s\n\n"+
+			"If this was you, no action is needed. Otherwise, change your password immediately.",
+		event.CreatedAt.UTC().Format("2006-01-02 15:04:05 MST"),
+		event.IPAddress,
+		location,
+		event.UserAgent,
+	)
+	if err := s.mailer.Send(ctx, login.Email, "New sign-in to your account", body); err != nil {
+		log.Printf("Failed to send suspicious login notification to %s: %v", login.Email, err)
+	}
+}
+
+// DeviceFingerprint derives a stable identifier for a device from its user agent
+func DeviceFingerprint(userAgent string) string {
+	normalized := strings.ToLower(strings.TrimSpace(userAgent))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/events"
+)
+
+// loginHookTimeout bounds the total time spent in hooks for one stage
+const loginHookTimeout = 10 * time.Second
+
+// LoginHookStage identifies when a login hook is invoked
+type LoginHookStage string
+
+const (
+	LoginHookStagePre           LoginHookStage = "pre_auth"        // Before credentials are checked
+	LoginHookStagePost          LoginHookStage = "post_auth"       // After credentials are checked, before tokens are issued
+	LoginHookStagePreRegister   LoginHookStage = "pre_register"    // Before a user is created
+	LoginHookStagePostRegister  LoginHookStage = "post_register"   // After a user is created, for side effects only
+	LoginHookStagePreTokenIssue LoginHookStage = "pre_token_issue" // Before tokens are issued at login, registration or refresh
+)
+
+// LoginHookAction is the decision returned by a login hook
+type LoginHookAction string
+
+const (
+	LoginHookActionAllow  LoginHookAction = "allow"   // Continue the login
+	LoginHookActionReject LoginHookAction = "reject"  // Abort the login
+	LoginHookActionStepUp LoginHookAction = "step_up" // Require additional verification
+)
+
+// LoginHookContext describes the login attempt passed to hooks.
+// User fields are populated from the post-auth stage on, and at registration
+// from post-register on; TenantID is also set at pre-register.
+type LoginHookContext struct {
+	Stage     LoginHookStage `json:"stage"`
+	Email     string         `json:"email"`
+	IPAddress string         `json:"ipAddress,omitempty"`
+	UserAgent string         `json:"userAgent,omitempty"`
+	UserID    string         `json:"userId,omitempty"`
+	TenantID  string         `json:"tenantId,omitempty"`
+	Roles     []string       `json:"roles,omitempty"`
+}
+
+// LoginHookResult is returned by a login hook
+type LoginHookResult struct {
+	Action     LoginHookAction        `json:"action"`
+	Reason     string                 `json:"reason,omitempty"`
+	Methods    []string               `json:"methods,omitempty"`    // Accepted step-up methods
+	Attributes map[string]interface{} `json:"attributes,omitempty"` // Session attributes to add to the token
+}
+
+// LoginHook lets deployments enforce custom rules around authentication.
+// Hooks may return a nil result to allow the login unchanged.
+type LoginHook interface {
+	Name() string
+	PreAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error)
+	PostAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error)
+}
+
+// RegistrationHook is implemented by login hooks that also run around
+// registration. Post-register results are ignored, as the user already exists.
+type RegistrationHook interface {
+	PreRegister(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error)
+	PostRegister(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error)
+}
+
+// TokenIssueHook is implemented by login hooks that run before tokens are
+// issued, including on refresh, to reject the issue or add session attributes
+type TokenIssueHook interface {
+	PreTokenIssue(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error)
+}
+
+// LoginRejectedError is returned when a login hook rejects a login or requires step-up
+type LoginRejectedError struct {
+	Hook    string
+	Reason  string
+	StepUp  bool
+	Methods []string
+}
+
+func (e *LoginRejectedError) Error() string {
+	if e.StepUp {
+		return fmt.Sprintf("login requires step-up verification (%s): %s", e.Hook, e.Reason)
+	}
+	return fmt.Sprintf("login rejected by %s: %s", e.Hook, e.Reason)
+}
+
+// runLoginHooks invokes each hook in order, stopping at the first reject or step-up.
+// Attributes from all allowing hooks are merged, later hooks taking precedence.
+func runLoginHooks(ctx context.Context, hooks []LoginHook, hookCtx *LoginHookContext) (map[string]interface{}, error) {
+	attributes := make(map[string]interface{})
+	if len(hooks) == 0 {
+		return attributes, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, loginHookTimeout)
+	defer cancel()
+
+	for _, hook := range hooks {
+		result, err := invokeLoginHook(ctx, hook, hookCtx)
+		if err != nil {
+			return nil, fmt.Errorf("login hook %s failed: %w", hook.Name(), err)
+		}
+		if result == nil {
+			continue
+		}
+
+		switch result.Action {
+		case LoginHookActionReject:
+			return nil, &LoginRejectedError{Hook: hook.Name(), Reason: result.Reason}
+		case LoginHookActionStepUp:
+			return nil, &LoginRejectedError{Hook: hook.Name(), Reason: result.Reason, StepUp: true, Methods: result.Methods}
+		}
+
+		for k, v := range result.Attributes {
+			attributes[k] = v
+		}
+	}
+
+	return attributes, nil
+}
+
+// runTokenIssueHooks runs the pre-token-issue hooks and returns the session
+// attributes with the hooks' attributes added
+func runTokenIssueHooks(ctx context.Context, hooks []LoginHook, hookCtx *LoginHookContext, sessionAttributes map[string]interface{}) (map[string]interface{}, error) {
+	hookCtx.Stage = LoginHookStagePreTokenIssue
+	attributes, err := runLoginHooks(ctx, hooks, hookCtx)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{}, len(sessionAttributes)+len(attributes))
+	for k, v := range sessionAttributes {
+		merged[k] = v
+	}
+	for k, v := range attributes {
+		merged[k] = v
+	}
+	return merged, nil
+}
+
+// notifyLoginHooks invokes every hook for a stage that cannot be rejected,
+// such as post-register, logging failures and ignoring results
+func notifyLoginHooks(ctx context.Context, hooks []LoginHook, hookCtx *LoginHookContext) {
+	ctx, cancel := context.WithTimeout(ctx, loginHookTimeout)
+	defer cancel()
+
+	for _, hook := range hooks {
+		if _, err := invokeLoginHook(ctx, hook, hookCtx); err != nil {
+			log.Printf("Login hook %s failed at %s: %v", hook.Name(), hookCtx.Stage, err)
+		}
+	}
+}
+
+// invokeLoginHook calls the method of a hook for the stage of hookCtx. Hooks
+// that do not implement the stage allow it unchanged.
+func invokeLoginHook(ctx context.Context, hook LoginHook, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	switch hookCtx.Stage {
+	case LoginHookStagePre:
+		return hook.PreAuthenticate(ctx, hookCtx)
+	case LoginHookStagePost:
+		return hook.PostAuthenticate(ctx, hookCtx)
+	case LoginHookStagePreRegister:
+		if h, ok := hook.(RegistrationHook); ok {
+			return h.PreRegister(ctx, hookCtx)
+		}
+	case LoginHookStagePostRegister:
+		if h, ok := hook.(RegistrationHook); ok {
+			return h.PostRegister(ctx, hookCtx)
+		}
+	case LoginHookStagePreTokenIssue:
+		if h, ok := hook.(TokenIssueHook); ok {
+			return h.PreTokenIssue(ctx, hookCtx)
+		}
+	}
+	return nil, nil
+}
+
+// WebhookLoginHook delegates login decisions to an external HTTP endpoint.
+// The endpoint receives a LoginHookContext and responds with a LoginHookResult.
+// It is only called at the configured stages.
+type WebhookLoginHook struct {
+	url        string
+	secret     string
+	failOpen   bool
+	stages     map[LoginHookStage]bool
+	httpClient *http.Client
+}
+
+// NewWebhookLoginHook creates a webhook-backed login hook
+func NewWebhookLoginHook(url string, cfg *config.LoginHookConfig) *WebhookLoginHook {
+	stages := make(map[LoginHookStage]bool)
+	for _, stage := range cfg.Stages {
+		stages[LoginHookStage(stage)] = true
+	}
+	if len(stages) == 0 {
+		stages[LoginHookStagePre] = true
+		stages[LoginHookStagePost] = true
+	}
+	return &WebhookLoginHook{
+		url:      url,
+		secret:   cfg.Secret,
+		failOpen: cfg.FailOpen,
+		stages:   stages,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+}
+
+// Name returns the hook name
+func (h *WebhookLoginHook) Name() string {
+	return "webhook:" + h.url
+}
+
+// PreAuthenticate calls the webhook before credentials are checked
+func (h *WebhookLoginHook) PreAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.call(ctx, LoginHookStagePre, hookCtx)
+}
+
+// PostAuthenticate calls the webhook after credentials are checked
+func (h *WebhookLoginHook) PostAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.call(ctx, LoginHookStagePost, hookCtx)
+}
+
+// PreRegister calls the webhook before a user is created
+func (h *WebhookLoginHook) PreRegister(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.call(ctx, LoginHookStagePreRegister, hookCtx)
+}
+
+// PostRegister calls the webhook after a user is created
+func (h *WebhookLoginHook) PostRegister(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.call(ctx, LoginHookStagePostRegister, hookCtx)
+}
+
+// PreTokenIssue calls the webhook before tokens are issued
+func (h *WebhookLoginHook) PreTokenIssue(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.call(ctx, LoginHookStagePreTokenIssue, hookCtx)
+}
+
+// call posts the login context to the webhook and decodes its decision,
+// skipping stages the webhook is not configured for
+func (h *WebhookLoginHook) call(ctx context.Context, stage LoginHookStage, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	if !h.stages[stage] {
+		return nil, nil
+	}
+	result, err := h.doCall(ctx, hookCtx)
+	if err != nil && h.failOpen {
+		return nil, nil
+	}
+	return result, err
+}
+
+// doCall performs a single webhook request
+func (h *WebhookLoginHook) doCall(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	body, err := json.Marshal(hookCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hook request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.secret != "" {
+		req.Header.Set(events.SignatureHeader, "sha256="+events.Sign(h.secret, body))
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call hook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result LoginHookResult
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode hook response: %w", err)
+	}
+
+	switch result.Action {
+	case "", LoginHookActionAllow, LoginHookActionReject, LoginHookActionStepUp:
+	default:
+		return nil, fmt.Errorf("hook returned unknown action: %s", result.Action)
+	}
+
+	return &result, nil
+}
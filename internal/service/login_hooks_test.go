@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// funcLoginHook adapts a function into a LoginHook for tests
+type funcLoginHook struct {
+	name string
+	fn   func(hookCtx *LoginHookContext) (*LoginHookResult, error)
+}
+
+func (h *funcLoginHook) Name() string { return h.name }
+
+func (h *funcLoginHook) PreAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.fn(hookCtx)
+}
+
+func (h *funcLoginHook) PostAuthenticate(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.fn(hookCtx)
+}
+
+// funcRegistrationHook also runs the function around registration and token issue
+type funcRegistrationHook struct {
+	funcLoginHook
+}
+
+func (h *funcRegistrationHook) PreRegister(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.fn(hookCtx)
+}
+
+func (h *funcRegistrationHook) PostRegister(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.fn(hookCtx)
+}
+
+func (h *funcRegistrationHook) PreTokenIssue(ctx context.Context, hookCtx *LoginHookContext) (*LoginHookResult, error) {
+	return h.fn(hookCtx)
+}
+
+func TestRunLoginHooks(t *testing.T) {
+	enrich := &funcLoginHook{name: "enrich", fn: func(hookCtx *LoginHookContext) (*LoginHookResult, error) {
+		return &LoginHookResult{Action: LoginHookActionAllow, Attributes: map[string]interface{}{"department": "finance"}}, nil
+	}}
+	stepUp := &funcLoginHook{name: "hr", fn: func(hookCtx *LoginHookContext) (*LoginHookResult, error) {
+		if hookCtx.Stage == LoginHookStagePost {
+			return &LoginHookResult{Action: LoginHookActionStepUp, Reason: "new device", Methods: []string{"totp"}}, nil
+		}
+		return nil, nil
+	}}
+
+	attributes, err := runLoginHooks(context.Background(), []LoginHook{enrich, stepUp}, &LoginHookContext{Stage: LoginHookStagePre})
+	if err != nil {
+		t.Fatalf("Expected pre-auth hooks to allow, got: %v", err)
+	}
+	if attributes["department"] != "finance" {
+		t.Errorf("Expected department attribute 'finance', got '%v'", attributes["department"])
+	}
+
+	_, err = runLoginHooks(context.Background(), []LoginHook{enrich, stepUp}, &LoginHookContext{Stage: LoginHookStagePost})
+	var rejected *LoginRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("Expected LoginRejectedError, got: %v", err)
+	}
+	if !rejected.StepUp || rejected.Hook != "hr" {
+		t.Errorf("Expected step-up from 'hr', got stepUp=%v hook=%s", rejected.StepUp, rejected.Hook)
+	}
+}
+
+func TestWebhookLoginHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hookCtx LoginHookContext
+		_ = json.NewDecoder(r.Body).Decode(&hookCtx)
+
+		action := LoginHookActionAllow
+		if hookCtx.Email == "terminated@example.com" {
+			action = LoginHookActionReject
+		}
+		_ = json.NewEncoder(w).Encode(LoginHookResult{Action: action, Reason: "employment ended"})
+	}))
+	defer server.Close()
+
+	hook := NewWebhookLoginHook(server.URL, &config.LoginHookConfig{Timeout: time.Second})
+
+	result, err := hook.PreAuthenticate(context.Background(), &LoginHookContext{Email: "user@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Action != LoginHookActionAllow {
+		t.Errorf("Expected action 'allow', got '%s'", result.Action)
+	}
+
+	result, err = hook.PreAuthenticate(context.Background(), &LoginHookContext{Email: "terminated@example.com"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Action != LoginHookActionReject {
+		t.Errorf("Expected action 'reject', got '%s'", result.Action)
+	}
+
+	// Unreachable hooks fail closed unless configured otherwise
+	server.Close()
+	if _, err := hook.PreAuthenticate(context.Background(), &LoginHookContext{}); err == nil {
+		t.Error("Expected error for unreachable hook")
+	}
+	failOpen := NewWebhookLoginHook(server.URL, &config.LoginHookConfig{Timeout: time.Second, FailOpen: true})
+	if result, err := failOpen.PreAuthenticate(context.Background(), &LoginHookContext{}); err != nil || result != nil {
+		t.Errorf("Expected fail-open hook to allow, got result=%v err=%v", result, err)
+	}
+}
+
+func TestAuthService_RegistrationHooks(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		_, fusionAuth := newFakeFusionAuth(t)
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		authService := NewAuthService(db, fusionAuth, jwtService, nil, nil, nil)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		ctx := testutil.CreateTestContext(t)
+
+		// Hooks without the registration stages are skipped there
+		authService.AddLoginHook(&funcLoginHook{name: "auth-only", fn: func(hookCtx *LoginHookContext) (*LoginHookResult, error) {
+			return &LoginHookResult{Action: LoginHookActionReject, Reason: "auth only"}, nil
+		}})
+		var synced atomic.Value
+		authService.AddLoginHook(&funcRegistrationHook{funcLoginHook{name: "allowlist", fn: func(hookCtx *LoginHookContext) (*LoginHookResult, error) {
+			switch hookCtx.Stage {
+			case LoginHookStagePreRegister:
+				if hookCtx.TenantID != tenant.ID.String() || hookCtx.Email != "alice@example.com" {
+					return &LoginHookResult{Action: LoginHookActionReject, Reason: "domain not allowed"}, nil
+				}
+			case LoginHookStagePostRegister:
+				synced.Store(hookCtx.UserID)
+			case LoginHookStagePreTokenIssue:
+				return &LoginHookResult{Action: LoginHookActionAllow, Attributes: map[string]interface{}{"crmId": "42"}}, nil
+			}
+			return nil, nil
+		}}})
+
+		register := func(email string) (*AuthResponse, error) {
+			return authService.Register(ctx, &RegisterRequest{
+				Email:     email,
+				Password:  "SecurePassword123!",
+				FirstName: "Alice",
+				LastName:  "Smith",
+				TenantID:  tenant.ID.String(),
+			})
+		}
+
+		var rejected *LoginRejectedError
+		if _, err := register("mallory@example.org"); !errors.As(err, &rejected) || rejected.Hook != "allowlist" {
+			t.Fatalf("Expected the allowlist to reject the registration, got %v", err)
+		}
+		var users int64
+		db.Model(&models.User{}).Count(&users)
+		if users != 0 {
+			t.Errorf("Expected no user after a rejected registration, found %d", users)
+		}
+
+		resp, err := register("alice@example.com")
+		if err != nil {
+			t.Fatalf("Register returned error: %v", err)
+		}
+		claims, err := jwtService.ValidateAccessToken(resp.AccessToken)
+		if err != nil {
+			t.Fatalf("Failed to validate access token: %v", err)
+		}
+		if claims.Attributes["crmId"] != "42" {
+			t.Errorf("Expected the pre-token-issue hook to add crmId, got %v", claims.Attributes)
+		}
+		if err := authService.Drain(ctx); err != nil {
+			t.Fatalf("Drain returned error: %v", err)
+		}
+		if synced.Load() != resp.User.ID {
+			t.Errorf("Expected the post-register hook to receive user %s, got %v", resp.User.ID, synced.Load())
+		}
+	})
+}
+
+func TestWebhookLoginHook_Stages(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = json.NewEncoder(w).Encode(LoginHookResult{Action: LoginHookActionReject, Reason: "not allowed"})
+	}))
+	defer server.Close()
+
+	// Webhooks are called before and after authentication unless configured otherwise
+	hook := NewWebhookLoginHook(server.URL, &config.LoginHookConfig{Timeout: time.Second})
+	if result, err := hook.PreRegister(context.Background(), &LoginHookContext{Stage: LoginHookStagePreRegister}); err != nil || result != nil || calls.Load() != 0 {
+		t.Errorf("Expected pre-register to be skipped, got result=%v err=%v calls=%d", result, err, calls.Load())
+	}
+
+	hook = NewWebhookLoginHook(server.URL, &config.LoginHookConfig{Timeout: time.Second, Stages: []string{"pre_register"}})
+	result, err := hook.PreRegister(context.Background(), &LoginHookContext{Stage: LoginHookStagePreRegister})
+	if err != nil || result == nil || result.Action != LoginHookActionReject {
+		t.Fatalf("Expected the webhook to reject the registration, got result=%v err=%v", result, err)
+	}
+	if result, err := hook.PreAuthenticate(context.Background(), &LoginHookContext{Stage: LoginHookStagePre}); err != nil || result != nil || calls.Load() != 1 {
+		t.Errorf("Expected pre-auth to be skipped, got result=%v err=%v calls=%d", result, err, calls.Load())
+	}
+}
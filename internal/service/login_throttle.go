@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/database"
+)
+
+// LoginThrottledError is returned when a login attempt is rejected by brute-force protection
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // true when the account itself is locked out
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account temporarily locked, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginFailureResult describes the state after a failed login is recorded
+type LoginFailureResult struct {
+	AccountFailures int64
+	IPFailures      int64
+	Locked          bool
+	Backoff         time.Duration
+}
+
+// LoginThrottler tracks failed logins per account and per IP in Redis
+type LoginThrottler struct {
+	redis *database.RedisClient
+	cfg   config.SecurityConfig
+}
+
+// NewLoginThrottler creates a new login throttler.
+// A nil Redis client disables throttling.
+func NewLoginThrottler(redis *database.RedisClient, cfg *config.SecurityConfig) *LoginThrottler {
+	return &LoginThrottler{
+		redis: redis,
+		cfg:   *cfg,
+	}
+}
+
+// Check returns a *LoginThrottledError if the account or IP may not attempt a login right now
+func (t *LoginThrottler) Check(ctx context.Context, email, ipAddress string) error {
+	if t == nil || t.redis == nil {
+		return nil
+	}
+
+	account := accountKey(email)
+
+	if ttl, err := t.redis.GetLoginLockTTL(ctx, account); err == nil && ttl > 0 {
+		return &LoginThrottledError{RetryAfter: ttl, Locked: true}
+	}
+
+	if ttl, err := t.redis.GetLoginLockTTL(ctx, backoffKey(email)); err == nil && ttl > 0 {
+		return &LoginThrottledError{RetryAfter: ttl}
+	}
+
+	if ipAddress != "" {
+		if ttl, err := t.redis.GetLoginLockTTL(ctx, ipKey(ipAddress)); err == nil && ttl > 0 {
+			return &LoginThrottledError{RetryAfter: ttl}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure records a failed login, applying backoff and lockout as thresholds are reached
+func (t *LoginThrottler) RecordFailure(ctx context.Context, email, ipAddress string) *LoginFailureResult {
+	result := &LoginFailureResult{}
+	if t == nil || t.redis == nil {
+		return result
+	}
+
+	account := accountKey(email)
+
+	if count, err := t.redis.IncrementLoginFailures(ctx, account, t.cfg.FailureWindow); err == nil {
+		result.AccountFailures = count
+	}
+
+	if ipAddress != "" {
+		ip := ipKey(ipAddress)
+		if count, err := t.redis.IncrementLoginFailures(ctx, ip, t.cfg.FailureWindow); err == nil {
+			result.IPFailures = count
+			if t.cfg.MaxIPFailures > 0 && count >= int64(t.cfg.MaxIPFailures) {
+				_ = t.redis.LockLogin(ctx, ip, t.cfg.FailureWindow)
+			}
+		}
+	}
+
+	if t.cfg.MaxAccountFailures > 0 && result.AccountFailures >= int64(t.cfg.MaxAccountFailures) {
+		_ = t.redis.LockLogin(ctx, account, t.cfg.LockoutDuration)
+		_ = t.redis.ResetLoginFailures(ctx, account)
+		result.Locked = true
+		return result
+	}
+
+	result.Backoff = backoffDelay(t.cfg.BackoffBase, t.cfg.BackoffMax, result.AccountFailures)
+	if result.Backoff > 0 {
+		_ = t.redis.LockLogin(ctx, backoffKey(email), result.Backoff)
+	}
+
+	return result
+}
+
+// RecordSuccess clears the failure counter for an account after a successful login
+func (t *LoginThrottler) RecordSuccess(ctx context.Context, email string) {
+	if t == nil || t.redis == nil {
+		return
+	}
+
+	_ = t.redis.ResetLoginFailures(ctx, accountKey(email))
+	_ = t.redis.UnlockLogin(ctx, backoffKey(email))
+}
+
+// Unlock removes any lockout, backoff and failure count for an account
+func (t *LoginThrottler) Unlock(ctx context.Context, email string) error {
+	if t == nil || t.redis == nil {
+		return nil
+	}
+
+	if err := t.redis.UnlockLogin(ctx, accountKey(email)); err != nil {
+		return fmt.Errorf("failed to remove account lock: %w", err)
+	}
+	if err := t.redis.UnlockLogin(ctx, backoffKey(email)); err != nil {
+		return fmt.Errorf("failed to remove login backoff: %w", err)
+	}
+	if err := t.redis.ResetLoginFailures(ctx, accountKey(email)); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+// IsLocked reports whether an account is currently locked out
+func (t *LoginThrottler) IsLocked(ctx context.Context, email string) (bool, time.Duration) {
+	if t == nil || t.redis == nil {
+		return false, 0
+	}
+
+	ttl, err := t.redis.GetLoginLockTTL(ctx, accountKey(email))
+	if err != nil || ttl <= 0 {
+		return false, 0
+	}
+	return true, ttl
+}
+
+// backoffDelay returns base * 2^(failures-1), capped at max
+func backoffDelay(base, max time.Duration, failures int64) time.Duration {
+	if base <= 0 || failures <= 0 {
+		return 0
+	}
+
+	delay := base
+	for i := int64(1); i < failures; i++ {
+		delay *= 2
+		if max > 0 && delay >= max {
+			return max
+		}
+	}
+
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func backoffKey(email string) string {
+	return "backoff:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	base := 500 * time.Millisecond
+	max := 4 * time.Second
+
+	tests := []struct {
+		failures int64
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 4 * time.Second},
+		{10, 4 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := backoffDelay(base, max, tt.failures); got != tt.expected {
+			t.Errorf("Expected backoff %v for %d failures, got %v", tt.expected, tt.failures, got)
+		}
+	}
+}
+
+func TestLoginThrottler_NilRedisIsNoop(t *testing.T) {
+	throttler := NewLoginThrottler(nil, &config.SecurityConfig{MaxAccountFailures: 1})
+
+	if err := throttler.Check(context.Background(), "user@example.com", "127.0.0.1"); err != nil {
+		t.Errorf("Expected no error without Redis, got %v", err)
+	}
+
+	result := throttler.RecordFailure(context.Background(), "user@example.com", "127.0.0.1")
+	if result.Locked {
+		t.Error("Expected account not to be locked without Redis")
+	}
+}
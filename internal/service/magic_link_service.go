@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/notify"
+	"gorm.io/gorm"
+)
+
+// MagicLinkService implements passwordless login: users request a signed login
+// link by email and redeem it once for the usual token pair
+type MagicLinkService struct {
+	db         *gorm.DB
+	jwtService *auth.JWTService
+	redis      *database.RedisClient
+	auth       *AuthService
+	mailer     notify.Mailer
+	cfg        *config.MagicLinkConfig
+}
+
+// NewMagicLinkService creates a new magic link service. A nil mailer sends no
+// links, which is only useful in tests.
+func NewMagicLinkService(db *gorm.DB, jwtService *auth.JWTService, redis *database.RedisClient, authService *AuthService, mailer notify.Mailer, cfg *config.MagicLinkConfig) *MagicLinkService {
+	if mailer == nil {
+		mailer = notify.NoopMailer{}
+	}
+	return &MagicLinkService{
+		db:         db,
+		jwtService: jwtService,
+		redis:      redis,
+		auth:       authService,
+		mailer:     mailer,
+		cfg:        cfg,
+	}
+}
+
+// MagicLinkRequest represents a request for a login link
+type MagicLinkRequest struct {
+	Email    string `json:"email" validate:"required,email" example:"user@example.com"`
+	TenantID string `json:"tenantId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
+
+// MagicLinkResponse tells how long a requested link is valid
+type MagicLinkResponse struct {
+	ExpiresIn int64 `json:"expiresIn" example:"900"`
+}
+
+// MagicLinkRedeemRequest represents the redemption of a login link
+type MagicLinkRedeemRequest struct {
+	Token string `json:"token" validate:"required" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// RequestLink emails a login link to an active user of the tenant, or of the
+// default tenant when none is given. Unknown emails, inactive users and emails
+// over their hourly limit get the same response without an email, so the
+// response does not reveal which emails are registered.
+func (s *MagicLinkService) RequestLink(ctx context.Context, req *MagicLinkRequest) (*MagicLinkResponse, error) {
+	response := &MagicLinkResponse{ExpiresIn: int64(s.cfg.Expiry.Seconds())}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	db := s.db.WithContext(ctx)
+	var tenant models.Tenant
+	var err error
+	if req.TenantID == "" {
+		err = db.Where("slug = ?", "default").First(&tenant).Error
+	} else {
+		tenantUUID, parseErr := uuid.Parse(req.TenantID)
+		if parseErr != nil {
+			return nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(parseErr)
+		}
+		err = db.Where("id = ? AND status = ?", tenantUUID, "active").First(&tenant).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found or inactive")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	var user models.User
+	err = db.Where("tenant_id = ? AND lower(email) = ?", tenant.ID, email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Status == models.UserStatusSuspended {
+		return response, nil
+	}
+
+	// Limit the links sent to one inbox
+	limit, err := s.redis.AllowRateLimit(ctx, "magic_link:"+user.ID.String(), int64(s.cfg.MaxPerHour), time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check magic link limit: %w", err)
+	}
+	if !limit.Allowed {
+		return response, nil
+	}
+
+	token, err := s.jwtService.GenerateMagicLinkToken(user.ID.String(), tenant.ID.String(), user.Email, s.cfg.Expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate magic link: %w", err)
+	}
+	separator := "?"
+	if strings.Contains(s.cfg.URL, "?") {
+		separator = "&"
+	}
+	link := s.cfg.URL + separator + "token=" + url.QueryEscape(token)
+
+	body := fmt.Sprintf("Use this link to sign in:\n\n%s\n\nThe link expires in %s and can be used once. If you did not request it, ignore this email.", link, s.cfg.Expiry)
+	if err := s.mailer.Send(ctx, user.Email, "Your sign-in link", body); err != nil {
+		log.Printf("Failed to send magic link to user %s: %v", user.ID, err)
+	}
+	return response, nil
+}
+
+// Redeem signs in the user of a login link, running the same hooks as any
+// other login. Each link can be redeemed once.
+func (s *MagicLinkService) Redeem(ctx context.Context, token string, req *LoginRequest) (*AuthResponse, error) {
+	claims, err := s.jwtService.ValidateMagicLinkToken(token)
+	if err != nil {
+		return nil, apperrors.Unauthorized("INVALID_MAGIC_LINK", "Invalid or expired magic link").WithCause(err)
+	}
+
+	// Remember the link until it expires, so it cannot be redeemed again
+	ttl := time.Minute
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time) + time.Minute
+	}
+	first, err := s.redis.SetNX(ctx, "magic_link_used:"+claims.ID, "1", ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem magic link: %w", err)
+	}
+	if !first {
+		return nil, apperrors.Unauthorized("INVALID_MAGIC_LINK", "Magic link has already been used")
+	}
+
+	// Links of users whose email changed since are no longer valid
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "email").First(&user, "id = ?", claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Unauthorized("INVALID_MAGIC_LINK", "User no longer exists")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, claims.Email) {
+		return nil, apperrors.Unauthorized("INVALID_MAGIC_LINK", "Invalid or expired magic link")
+	}
+
+	req.Email = user.Email
+	return s.auth.LoginExternal(ctx, &auth.IdentityUser{ID: user.ID.String(), Email: user.Email}, req)
+}
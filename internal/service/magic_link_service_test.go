@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// linkInbox keeps the bodies of the messages sent
+type linkInbox struct {
+	bodies []string
+}
+
+func (m *linkInbox) Send(ctx context.Context, to, subject, body string) error {
+	m.bodies = append(m.bodies, body)
+	return nil
+}
+
+var magicLinkToken = regexp.MustCompile(`token=(\S+)`)
+
+func TestMagicLinkService(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+
+		inbox := &linkInbox{}
+		authService := NewAuthService(db, nil, jwtService, nil, nil, nil)
+		magicLinks := NewMagicLinkService(db, jwtService, database.NewMemoryClient(), authService, inbox, &config.MagicLinkConfig{
+			URL:        "https://app.example.com/magic?lang=en",
+			Expiry:     15 * time.Minute,
+			MaxPerHour: 2,
+		})
+
+		// Unknown emails get the same response without an email
+		req := &MagicLinkRequest{Email: "mallory@acme.com", TenantID: tenant.ID.String()}
+		if resp, err := magicLinks.RequestLink(ctx, req); err != nil || resp.ExpiresIn != 900 {
+			t.Fatalf("Expected a link valid for 900s, got %+v %v", resp, err)
+		}
+		if len(inbox.bodies) != 0 {
+			t.Fatalf("Expected no email for an unknown user, got %d", len(inbox.bodies))
+		}
+
+		req.Email = "Alice@Acme.com"
+		if _, err := magicLinks.RequestLink(ctx, req); err != nil {
+			t.Fatalf("RequestLink returned error: %v", err)
+		}
+		if len(inbox.bodies) != 1 {
+			t.Fatalf("Expected one email, got %d", len(inbox.bodies))
+		}
+		match := magicLinkToken.FindStringSubmatch(inbox.bodies[0])
+		if match == nil {
+			t.Fatalf("Expected a link with a token, got %q", inbox.bodies[0])
+		}
+		token, _ := url.QueryUnescape(match[1])
+
+		// Magic link tokens are not access tokens
+		if _, err := jwtService.ValidateAccessToken(token); err == nil {
+			t.Error("Expected the magic link token to be rejected as an access token")
+		}
+
+		resp, err := magicLinks.Redeem(ctx, token, &LoginRequest{IPAddress: "203.0.113.7"})
+		if err != nil {
+			t.Fatalf("Redeem returned error: %v", err)
+		}
+		claims, err := jwtService.ValidateAccessToken(resp.AccessToken)
+		if err != nil || claims.UserID != alice.ID.String() || claims.TenantID != tenant.ID.String() {
+			t.Fatalf("Expected an access token of alice, got %+v %v", claims, err)
+		}
+
+		// Links are single-use
+		if _, err := magicLinks.Redeem(ctx, token, &LoginRequest{}); !isAppError(err, "INVALID_MAGIC_LINK") {
+			t.Errorf("Expected INVALID_MAGIC_LINK for a used link, got %v", err)
+		}
+		if _, err := magicLinks.Redeem(ctx, "not-a-token", &LoginRequest{}); !isAppError(err, "INVALID_MAGIC_LINK") {
+			t.Errorf("Expected INVALID_MAGIC_LINK for a forged link, got %v", err)
+		}
+
+		// Links sent to one user are limited per hour
+		for i := 0; i < 2; i++ {
+			if _, err := magicLinks.RequestLink(ctx, req); err != nil {
+				t.Fatalf("RequestLink returned error: %v", err)
+			}
+		}
+		if len(inbox.bodies) != 2 {
+			t.Errorf("Expected the hourly limit to stop the third email, got %d emails", len(inbox.bodies))
+		}
+	})
+}
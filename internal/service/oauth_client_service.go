@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sizes of generated client credentials in random bytes
+const (
+	clientIDBytes     = 16
+	clientSecretBytes = 32
+)
+
+// OAuthClientService manages the OAuth clients of tenants and issues access
+// tokens to them with the client credentials grant
+type OAuthClientService struct {
+	db         *gorm.DB
+	jwtService *auth.JWTService
+}
+
+// NewOAuthClientService creates a new OAuth client service
+func NewOAuthClientService(db *gorm.DB, jwtService *auth.JWTService) *OAuthClientService {
+	return &OAuthClientService{
+		db:         db,
+		jwtService: jwtService,
+	}
+}
+
+// CreateOAuthClientRequest represents a request to create an OAuth client
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name" validate:"required,max=255" example:"Nightly billing export"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required,max=100" example:"[\"invoices.read\",\"invoices.export\"]"` // Permissions the client may request
+}
+
+// UpdateOAuthClientRequest represents a partial update of an OAuth client
+type UpdateOAuthClientRequest struct {
+	Name   *string  `json:"name,omitempty" validate:"omitempty,min=1,max=255" example:"Nightly billing export"`
+	Scopes []string `json:"scopes,omitempty" validate:"omitempty,min=1,dive,required,max=100" example:"[\"invoices.read\"]"`
+}
+
+// RotateOAuthClientSecretRequest represents a request to issue a new client secret
+type RotateOAuthClientSecretRequest struct {
+	GracePeriod int64 `json:"gracePeriod,omitempty" validate:"omitempty,min=0,max=604800" example:"3600"` // Seconds the previous secret stays valid, defaults to 0
+}
+
+// OAuthClientResponse represents an OAuth client
+type OAuthClientResponse struct {
+	ID              string   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID        string   `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	ClientID        string   `json:"clientId" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	ClientSecret    string   `json:"clientSecret,omitempty" example:"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"` // Only returned when the secret is issued
+	Name            string   `json:"name" example:"Nightly billing export"`
+	Scopes          []string `json:"scopes" example:"[\"invoices.read\",\"invoices.export\"]"`
+	SecretRotatedAt string   `json:"secretRotatedAt,omitempty" example:"2024-01-20T14:45:00Z"`
+	LastUsedAt      string   `json:"lastUsedAt,omitempty" example:"2024-01-21T02:00:00Z"`
+	CreatedAt       string   `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt       string   `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+}
+
+// OAuthTokenResponse represents tokens issued by the OAuth token endpoint, in
+// the form of RFC 6749 §5.1
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token,omitempty" example:"eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9..."` // Only issued to users, not to OAuth clients
+	Scope        string `json:"scope,omitempty" example:"invoices.read invoices.export"`
+}
+
+// ListClients lists a tenant's OAuth clients
+func (s *OAuthClientService) ListClients(ctx context.Context, tenantID uuid.UUID) ([]OAuthClientResponse, error) {
+	var clients []models.OAuthClient
+	if err := readReplica(s.db).WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&clients).Error; err != nil {
+		return nil, fmt.Errorf("failed to list OAuth clients: %w", err)
+	}
+
+	responses := make([]OAuthClientResponse, 0, len(clients))
+	for i := range clients {
+		response, err := toOAuthClientResponse(&clients[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// GetClient retrieves an OAuth client of a tenant
+func (s *OAuthClientService) GetClient(ctx context.Context, tenantID uuid.UUID, clientID string) (*OAuthClientResponse, error) {
+	client, err := s.findClient(readReplica(s.db).WithContext(ctx), tenantID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return toOAuthClientResponse(client)
+}
+
+// CreateClient registers an OAuth client for a tenant. The client secret is only
+// returned in the response and cannot be retrieved later.
+func (s *OAuthClientService) CreateClient(ctx context.Context, tenantID uuid.UUID, req *CreateOAuthClientRequest) (*OAuthClientResponse, error) {
+	var tenant models.Tenant
+	if err := s.db.WithContext(ctx).Select("id").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	scopes, err := s.validateScopes(ctx, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, err := randomHex(clientIDBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client ID: %w", err)
+	}
+	secret, err := randomHex(clientSecretBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client secret: %w", err)
+	}
+
+	client := &models.OAuthClient{
+		TenantID:   tenantID,
+		ClientID:   clientID,
+		Name:       req.Name,
+		SecretHash: hashSecret(secret),
+		Scopes:     scopes,
+	}
+	if err := s.db.WithContext(ctx).Create(client).Error; err != nil {
+		return nil, fmt.Errorf("failed to create OAuth client: %w", err)
+	}
+
+	response, err := toOAuthClientResponse(client)
+	if err != nil {
+		return nil, err
+	}
+	response.ClientSecret = secret
+	return response, nil
+}
+
+// UpdateClient changes the name or allowed scopes of an OAuth client. Tokens
+// already issued keep their scope until they expire.
+func (s *OAuthClientService) UpdateClient(ctx context.Context, tenantID uuid.UUID, clientID string, req *UpdateOAuthClientRequest) (*OAuthClientResponse, error) {
+	var scopes []byte
+	if req.Scopes != nil {
+		var err error
+		if scopes, err = s.validateScopes(ctx, req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	var response *OAuthClientResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		client, err := s.findClient(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tenantID, clientID)
+		if err != nil {
+			return err
+		}
+
+		if req.Name != nil {
+			client.Name = *req.Name
+		}
+		if scopes != nil {
+			client.Scopes = scopes
+		}
+		if err := tx.Save(client).Error; err != nil {
+			return fmt.Errorf("failed to update OAuth client: %w", err)
+		}
+		response, err = toOAuthClientResponse(client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RotateSecret issues a new secret for an OAuth client. The previous secret stays
+// valid for the grace period so deployments can switch over without downtime.
+func (s *OAuthClientService) RotateSecret(ctx context.Context, tenantID uuid.UUID, clientID string, req *RotateOAuthClientSecretRequest) (*OAuthClientResponse, error) {
+	secret, err := randomHex(clientSecretBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client secret: %w", err)
+	}
+
+	var response *OAuthClientResponse
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		client, err := s.findClient(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tenantID, clientID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		client.PreviousSecretHash = ""
+		client.PreviousSecretExpiresAt = nil
+		if req.GracePeriod > 0 {
+			expiresAt := now.Add(time.Duration(req.GracePeriod) * time.Second)
+			client.PreviousSecretHash = client.SecretHash
+			client.PreviousSecretExpiresAt = &expiresAt
+		}
+		client.SecretHash = hashSecret(secret)
+		client.SecretRotatedAt = &now
+
+		if err := tx.Save(client).Error; err != nil {
+			return fmt.Errorf("failed to rotate client secret: %w", err)
+		}
+		response, err = toOAuthClientResponse(client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response.ClientSecret = secret
+	return response, nil
+}
+
+// DeleteClient removes an OAuth client. Tokens already issued to it stay valid
+// until they expire.
+func (s *OAuthClientService) DeleteClient(ctx context.Context, tenantID uuid.UUID, clientID string) error {
+	result := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND client_id = ?", tenantID, clientID).
+		Delete(&models.OAuthClient{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete OAuth client: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound("OAUTH_CLIENT_NOT_FOUND", "OAuth client not found")
+	}
+	return nil
+}
+
+// IssueToken authenticates an OAuth client with its credentials and issues an
+// access token for the requested scopes, or for all of the client's scopes when
+// none are requested (RFC 6749 §4.4)
+func (s *OAuthClientService) IssueToken(ctx context.Context, clientID, clientSecret, scope string) (*OAuthTokenResponse, error) {
+	client, err := s.authenticate(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed []string
+	if len(client.Scopes) > 0 {
+		if err := json.Unmarshal(client.Scopes, &allowed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal client scopes: %w", err)
+		}
+	}
+
+	scopes := allowed
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		seen := make(map[string]bool, len(requested))
+		scopes = nil
+		var denied []string
+		for _, name := range requested {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if !slices.Contains(allowed, name) {
+				denied = append(denied, name)
+				continue
+			}
+			scopes = append(scopes, name)
+		}
+		if len(denied) > 0 {
+			return nil, apperrors.Forbidden("INVALID_SCOPE", "Requested scopes exceed the client's scopes").
+				WithDetails(map[string]interface{}{"scopes": denied})
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, apperrors.Forbidden("INVALID_SCOPE", "Client has no scopes")
+	}
+	sort.Strings(scopes)
+
+	token, expiry, err := s.jwtService.GenerateClientToken(client.ClientID, client.TenantID.String(), scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client token: %w", err)
+	}
+
+	// Usage tracking must not fail the token request
+	_ = s.db.WithContext(ctx).Model(client).UpdateColumn("last_used_at", time.Now()).Error
+
+	return &OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(expiry.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
+
+// authenticate verifies a client's credentials against its current secret and,
+// during a rotation's grace period, its previous one
+func (s *OAuthClientService) authenticate(ctx context.Context, clientID, clientSecret string) (*models.OAuthClient, error) {
+	invalid := apperrors.Unauthorized("INVALID_CLIENT", "Invalid client credentials")
+	if clientID == "" || clientSecret == "" {
+		return nil, invalid
+	}
+
+	var client models.OAuthClient
+	if err := s.db.WithContext(ctx).First(&client, "client_id = ?", clientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+
+	hash := hashSecret(clientSecret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) == 1 {
+		return &client, nil
+	}
+	if client.PreviousSecretHash != "" && client.PreviousSecretExpiresAt != nil &&
+		time.Now().Before(*client.PreviousSecretExpiresAt) &&
+		subtle.ConstantTimeCompare([]byte(hash), []byte(client.PreviousSecretHash)) == 1 {
+		return &client, nil
+	}
+	return nil, invalid
+}
+
+// validateScopes checks that the scopes name existing permissions and returns
+// them deduplicated and sorted as JSON
+func (s *OAuthClientService) validateScopes(ctx context.Context, scopes []string) ([]byte, error) {
+	names := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, name := range scopes {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var known []string
+	if err := s.db.WithContext(ctx).Model(&models.Permission{}).
+		Where("name IN ?", names).
+		Pluck("name", &known).Error; err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	var unknown []string
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, apperrors.Validation("INVALID_SCOPE", "Scopes must name existing permissions").
+			WithDetails(map[string]interface{}{"scopes": unknown})
+	}
+
+	encoded, err := json.Marshal(names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+	return encoded, nil
+}
+
+// findClient loads an OAuth client of a tenant
+func (s *OAuthClientService) findClient(db *gorm.DB, tenantID uuid.UUID, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := db.First(&client, "tenant_id = ? AND client_id = ?", tenantID, clientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("OAUTH_CLIENT_NOT_FOUND", "OAuth client not found")
+		}
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+	return &client, nil
+}
+
+func toOAuthClientResponse(client *models.OAuthClient) (*OAuthClientResponse, error) {
+	scopes := []string{}
+	if len(client.Scopes) > 0 {
+		if err := json.Unmarshal(client.Scopes, &scopes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal client scopes: %w", err)
+		}
+	}
+
+	response := &OAuthClientResponse{
+		ID:        client.ID.String(),
+		TenantID:  client.TenantID.String(),
+		ClientID:  client.ClientID,
+		Name:      client.Name,
+		Scopes:    scopes,
+		CreatedAt: client.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: client.UpdatedAt.Format(time.RFC3339),
+	}
+	if client.SecretRotatedAt != nil {
+		response.SecretRotatedAt = client.SecretRotatedAt.Format(time.RFC3339)
+	}
+	if client.LastUsedAt != nil {
+		response.LastUsedAt = client.LastUsedAt.Format(time.RFC3339)
+	}
+	return response, nil
+}
+
+func randomHex(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestOAuthClientService_IssueToken(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		testutil.CreateTestPermission(t, db, "invoices.read", "invoices", "read")
+		testutil.CreateTestPermission(t, db, "invoices.export", "invoices", "export")
+		testutil.CreateTestPermission(t, db, "users.delete", "users", "delete")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		service := NewOAuthClientService(db, jwtService)
+
+		var appErr *apperrors.Error
+		_, err := service.CreateClient(ctx, tenant.ID, &CreateOAuthClientRequest{Name: "Export job", Scopes: []string{"invoices.read", "invoices.delete"}})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_SCOPE" {
+			t.Fatalf("Expected INVALID_SCOPE for an unknown permission, got %v", err)
+		}
+
+		client, err := service.CreateClient(ctx, tenant.ID, &CreateOAuthClientRequest{
+			Name:   "Export job",
+			Scopes: []string{"invoices.read", "invoices.export", "invoices.read"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		if client.ClientID == "" || client.ClientSecret == "" {
+			t.Fatalf("Expected generated client credentials, got %+v", client)
+		}
+		if !reflect.DeepEqual(client.Scopes, []string{"invoices.export", "invoices.read"}) {
+			t.Errorf("Expected deduplicated sorted scopes, got %v", client.Scopes)
+		}
+
+		// The secret is only returned when it is issued
+		stored, err := service.GetClient(ctx, tenant.ID, client.ClientID)
+		if err != nil || stored.ClientSecret != "" {
+			t.Fatalf("Expected stored client without secret, got %+v, %v", stored, err)
+		}
+
+		_, err = service.IssueToken(ctx, client.ClientID, "wrong-secret", "")
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_CLIENT" {
+			t.Fatalf("Expected INVALID_CLIENT for a wrong secret, got %v", err)
+		}
+		_, err = service.IssueToken(ctx, "unknown", client.ClientSecret, "")
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_CLIENT" {
+			t.Fatalf("Expected INVALID_CLIENT for an unknown client, got %v", err)
+		}
+		_, err = service.IssueToken(ctx, client.ClientID, client.ClientSecret, "invoices.read users.delete")
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_SCOPE" {
+			t.Fatalf("Expected INVALID_SCOPE beyond the client's scopes, got %v", err)
+		}
+
+		// Without a scope the token carries all of the client's scopes
+		token, err := service.IssueToken(ctx, client.ClientID, client.ClientSecret, "")
+		if err != nil {
+			t.Fatalf("Failed to issue token: %v", err)
+		}
+		if token.Scope != "invoices.export invoices.read" || token.TokenType != "Bearer" || token.ExpiresIn <= 0 {
+			t.Errorf("Unexpected token response %+v", token)
+		}
+
+		token, err = service.IssueToken(ctx, client.ClientID, client.ClientSecret, "invoices.read")
+		if err != nil {
+			t.Fatalf("Failed to issue narrowed token: %v", err)
+		}
+		claims, err := jwtService.ValidateAccessToken(token.AccessToken)
+		if err != nil {
+			t.Fatalf("Failed to validate client token: %v", err)
+		}
+		if claims.ClientID != client.ClientID || claims.Subject != client.ClientID || claims.UserID != "" {
+			t.Errorf("Expected client subject without user, got client=%q sub=%q user=%q", claims.ClientID, claims.Subject, claims.UserID)
+		}
+		if claims.TenantID != tenant.ID.String() || claims.Scope != "invoices.read" {
+			t.Errorf("Expected tenant-bound token with scope invoices.read, got tenant=%q scope=%q", claims.TenantID, claims.Scope)
+		}
+
+		used, _ := service.GetClient(ctx, tenant.ID, client.ClientID)
+		if used.LastUsedAt == "" {
+			t.Error("Expected last use to be recorded")
+		}
+	})
+}
+
+func TestOAuthClientService_Manage(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		testutil.CreateTestPermission(t, db, "invoices.read", "invoices", "read")
+		testutil.CreateTestPermission(t, db, "invoices.export", "invoices", "export")
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		service := NewOAuthClientService(db, jwtService)
+
+		client, err := service.CreateClient(ctx, tenant.ID, &CreateOAuthClientRequest{Name: "Export job", Scopes: []string{"invoices.read"}})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		// Clients are only visible within their tenant
+		if _, err := service.GetClient(ctx, other.ID, client.ClientID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found from another tenant, got %v", err)
+		}
+		if clients, err := service.ListClients(ctx, other.ID); err != nil || len(clients) != 0 {
+			t.Fatalf("Expected no clients for another tenant, got %v, %v", clients, err)
+		}
+
+		name := "Nightly export"
+		updated, err := service.UpdateClient(ctx, tenant.ID, client.ClientID, &UpdateOAuthClientRequest{Name: &name, Scopes: []string{"invoices.export"}})
+		if err != nil {
+			t.Fatalf("Failed to update client: %v", err)
+		}
+		if updated.Name != name || !reflect.DeepEqual(updated.Scopes, []string{"invoices.export"}) {
+			t.Errorf("Unexpected updated client %+v", updated)
+		}
+
+		// During the grace period both secrets are accepted
+		rotated, err := service.RotateSecret(ctx, tenant.ID, client.ClientID, &RotateOAuthClientSecretRequest{GracePeriod: 3600})
+		if err != nil {
+			t.Fatalf("Failed to rotate secret: %v", err)
+		}
+		if rotated.ClientSecret == "" || rotated.ClientSecret == client.ClientSecret || rotated.SecretRotatedAt == "" {
+			t.Fatalf("Expected a new secret, got %+v", rotated)
+		}
+		for _, secret := range []string{client.ClientSecret, rotated.ClientSecret} {
+			if _, err := service.IssueToken(ctx, client.ClientID, secret, ""); err != nil {
+				t.Errorf("Expected secret to be accepted during the grace period: %v", err)
+			}
+		}
+
+		// Without a grace period only the newest secret is accepted
+		final, err := service.RotateSecret(ctx, tenant.ID, client.ClientID, &RotateOAuthClientSecretRequest{})
+		if err != nil {
+			t.Fatalf("Failed to rotate secret: %v", err)
+		}
+		for _, secret := range []string{client.ClientSecret, rotated.ClientSecret} {
+			if _, err := service.IssueToken(ctx, client.ClientID, secret, ""); !errors.Is(err, apperrors.ErrUnauthorized) {
+				t.Errorf("Expected replaced secret to be rejected, got %v", err)
+			}
+		}
+		if _, err := service.IssueToken(ctx, client.ClientID, final.ClientSecret, ""); err != nil {
+			t.Errorf("Expected newest secret to be accepted: %v", err)
+		}
+
+		if err := service.DeleteClient(ctx, other.ID, client.ClientID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found deleting from another tenant, got %v", err)
+		}
+		if err := service.DeleteClient(ctx, tenant.ID, client.ClientID); err != nil {
+			t.Fatalf("Failed to delete client: %v", err)
+		}
+		if _, err := service.IssueToken(ctx, client.ClientID, final.ClientSecret, ""); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Errorf("Expected deleted client to be rejected, got %v", err)
+		}
+	})
+}
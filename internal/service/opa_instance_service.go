@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxOPAStatusBytes caps the size of a status report
+	maxOPAStatusBytes = 1 << 20
+	// opaInstanceStaleAfter is how long an instance may go without reporting
+	// before it is listed as stale; OPA reports at least every few seconds
+	// while its bundles are polled
+	opaInstanceStaleAfter = 5 * time.Minute
+)
+
+// OPAPluginStateOK is the state of a healthy OPA plugin; the others are
+// NOT_READY, WARN and ERR
+const OPAPluginStateOK = "OK"
+
+// OPAInstanceService tracks the OPA instances, e.g. sidecars running
+// Heimdall's bundles, that report their status through OPA's status API
+type OPAInstanceService struct {
+	db *gorm.DB
+}
+
+// NewOPAInstanceService creates a new OPA instance service
+func NewOPAInstanceService(db *gorm.DB) *OPAInstanceService {
+	return &OPAInstanceService{db: db}
+}
+
+// OPAStatusReport is a status update as OPA's status plugin reports it
+type OPAStatusReport struct {
+	Labels  map[string]string          `json:"labels"` // Includes the instance's id and version
+	Bundles map[string]OPABundleStatus `json:"bundles,omitempty"`
+	Plugins map[string]OPAPluginStatus `json:"plugins,omitempty"`
+}
+
+// OPABundleStatus is the status of a bundle in an OPA status report
+type OPABundleStatus struct {
+	Name                     string     `json:"name"`
+	ActiveRevision           string     `json:"active_revision,omitempty"`
+	LastSuccessfulDownload   *time.Time `json:"last_successful_download,omitempty"`
+	LastSuccessfulActivation *time.Time `json:"last_successful_activation,omitempty"`
+	Code                     string     `json:"code,omitempty"`
+	Message                  string     `json:"message,omitempty"`
+}
+
+// OPAPluginStatus is the state of an OPA plugin, e.g. bundle or decision_logs
+type OPAPluginStatus struct {
+	State   string `json:"state" example:"OK"` // OK, NOT_READY, WARN or ERR
+	Message string `json:"message,omitempty"`
+}
+
+// OPAInstanceSource identifies who reported an instance's status
+type OPAInstanceSource struct {
+	TenantID  uuid.UUID
+	ClientID  string // OAuth client the instance authenticated as, if any
+	IPAddress string
+}
+
+// OPAInstanceBundle is a bundle loaded by an OPA instance
+type OPAInstanceBundle struct {
+	Name                     string `json:"name" example:"authz"` // Name of the bundle in the instance's configuration
+	ActiveRevision           string `json:"activeRevision,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	LastSuccessfulDownload   string `json:"lastSuccessfulDownload,omitempty" example:"2024-01-20T14:45:00Z"`
+	LastSuccessfulActivation string `json:"lastSuccessfulActivation,omitempty" example:"2024-01-20T14:45:00Z"`
+	Error                    string `json:"error,omitempty" example:"server replied with not found"`           // Last download or activation error
+	BundleID                 string `json:"bundleId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Heimdall bundle the revision was built from, if known
+	BundleName               string `json:"bundleName,omitempty" example:"production-policies"`
+	BundleVersion            string `json:"bundleVersion,omitempty" example:"1.2.0"`
+	UpToDate                 *bool  `json:"upToDate,omitempty"` // Whether the revision is the bundle's latest build; unset for unknown revisions
+}
+
+// OPAInstanceResponse represents an OPA instance as last reported
+type OPAInstanceResponse struct {
+	ID          string                     `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	InstanceID  string                     `json:"instanceId" example:"b1cd0c39-7d3f-4c1e-9c4b-3f6a1c52b0d1"`
+	Version     string                     `json:"version,omitempty" example:"1.0.0"` // OPA version
+	Labels      map[string]string          `json:"labels,omitempty"`
+	ClientID    string                     `json:"clientId,omitempty" example:"opa-sidecar"` // OAuth client the instance reported as
+	IPAddress   string                     `json:"ipAddress,omitempty" example:"10.0.3.7"`   // Address the instance reported from
+	Bundles     []OPAInstanceBundle        `json:"bundles"`
+	Plugins     map[string]OPAPluginStatus `json:"plugins,omitempty"`
+	Healthy     bool                       `json:"healthy"` // All plugins are OK and no bundle reported an error
+	Stale       bool                       `json:"stale"`   // The instance has not reported for over 5 minutes
+	FirstSeenAt string                     `json:"firstSeenAt" example:"2024-01-20T14:00:00Z"`
+	LastSeenAt  string                     `json:"lastSeenAt" example:"2024-01-20T14:45:00Z"`
+}
+
+// OPAInstanceListOptions describes the sorting and filtering supported when
+// listing OPA instances
+var OPAInstanceListOptions = pagination.Options{
+	SortFields:  map[string]string{"lastSeenAt": "last_seen_at", "instanceId": "instance_id", "createdAt": "created_at"},
+	DefaultSort: "-lastSeenAt",
+}
+
+// ReportStatus records a status update sent by an OPA instance's status
+// plugin, creating the instance on its first report
+func (s *OPAInstanceService) ReportStatus(ctx context.Context, source *OPAInstanceSource, body []byte) error {
+	if len(body) > maxOPAStatusBytes {
+		return apperrors.Validation("OPA_STATUS_TOO_LARGE", "Status report exceeds 1 MiB").
+			WithDetails(map[string]interface{}{"maxBytes": maxOPAStatusBytes})
+	}
+	var report OPAStatusReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return apperrors.Validation("INVALID_OPA_STATUS", "Status report must be a JSON object").WithCause(err)
+	}
+	instanceID := report.Labels["id"]
+	if instanceID == "" || len(instanceID) > 200 {
+		return apperrors.Validation("INVALID_OPA_STATUS", "Status reports must have a labels.id of at most 200 characters")
+	}
+
+	labels, err := json.Marshal(report.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+	bundles, err := json.Marshal(report.Bundles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundles: %w", err)
+	}
+	plugins, err := json.Marshal(report.Plugins)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugins: %w", err)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var instance models.OPAInstance
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND instance_id = ?", source.TenantID, instanceID).
+			First(&instance).Error
+		created := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !created {
+			return fmt.Errorf("failed to get OPA instance: %w", err)
+		}
+		if created {
+			instance = models.OPAInstance{TenantID: source.TenantID, InstanceID: instanceID}
+		}
+
+		instance.Version = truncateText(report.Labels["version"], 100)
+		instance.Labels = datatypes.JSON(labels)
+		instance.ClientID = source.ClientID
+		instance.IPAddress = source.IPAddress
+		instance.Bundles = datatypes.JSON(bundles)
+		instance.Plugins = datatypes.JSON(plugins)
+		instance.LastSeenAt = time.Now()
+		if created {
+			err = tx.Create(&instance).Error
+		} else {
+			err = tx.Save(&instance).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save OPA instance: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListInstances returns a page of the OPA instances reporting to a tenant,
+// with the Heimdall bundles their loaded revisions were built from
+func (s *OPAInstanceService) ListInstances(ctx context.Context, tenantID uuid.UUID, params *pagination.Params) ([]OPAInstanceResponse, *pagination.Page, error) {
+	db := readReplica(s.db)
+	query := db.Model(&models.OPAInstance{}).Where("tenant_id = ?", tenantID)
+
+	instances, page, err := pagination.Paginate[models.OPAInstance](ctx, query, params, OPAInstanceListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list OPA instances: %w", err)
+	}
+
+	responses := make([]OPAInstanceResponse, len(instances))
+	revisions := make(map[string]bool)
+	for i := range instances {
+		responses[i] = toOPAInstanceResponse(&instances[i])
+		for _, bundle := range responses[i].Bundles {
+			if bundle.ActiveRevision != "" {
+				revisions[bundle.ActiveRevision] = true
+			}
+		}
+	}
+
+	builds, err := bundleRevisions(ctx, db, tenantID, sortedKeys(revisions))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range responses {
+		for j := range responses[i].Bundles {
+			bundle := &responses[i].Bundles[j]
+			build, ok := builds[bundle.ActiveRevision]
+			if !ok {
+				continue
+			}
+			upToDate := build.latest
+			bundle.BundleID = build.bundle.ID.String()
+			bundle.BundleName = build.bundle.Name
+			bundle.BundleVersion = build.bundle.Version
+			bundle.UpToDate = &upToDate
+		}
+	}
+	return responses, page, nil
+}
+
+// bundleBuild is the Heimdall bundle a revision was built from
+type bundleBuild struct {
+	bundle models.PolicyBundle
+	latest bool // The revision is the bundle's latest build
+}
+
+// bundleRevisions finds the tenant's or global bundles whose latest build or
+// a past deployment had the given revisions
+func bundleRevisions(ctx context.Context, db *gorm.DB, tenantID uuid.UUID, revisions []string) (map[string]bundleBuild, error) {
+	builds := make(map[string]bundleBuild)
+	if len(revisions) == 0 {
+		return builds, nil
+	}
+	visible := db.WithContext(ctx).Where("tenant_id = ? OR is_global = ?", tenantID, true)
+
+	var bundles []models.PolicyBundle
+	if err := visible.Session(&gorm.Session{}).
+		Where(jsonText(db, "manifest", "revision")+" IN ?", revisions).
+		Find(&bundles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bundles: %w", err)
+	}
+	for _, bundle := range bundles {
+		var manifest models.BundleManifest
+		if err := json.Unmarshal(bundle.Manifest, &manifest); err == nil {
+			builds[manifest.Revision] = bundleBuild{bundle: bundle, latest: true}
+		}
+	}
+
+	var deployments []models.BundleDeployment
+	if err := db.WithContext(ctx).Preload("Bundle").
+		Where("revision IN ?", revisions).
+		Where("bundle_id IN (?)", visible.Session(&gorm.Session{}).Model(&models.PolicyBundle{}).Select("id")).
+		Order("deployed_at DESC").
+		Find(&deployments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bundle deployments: %w", err)
+	}
+	for _, deployment := range deployments {
+		if _, ok := builds[deployment.Revision]; ok || deployment.Bundle == nil {
+			continue
+		}
+		builds[deployment.Revision] = bundleBuild{bundle: *deployment.Bundle}
+	}
+	return builds, nil
+}
+
+func toOPAInstanceResponse(instance *models.OPAInstance) OPAInstanceResponse {
+	var labels map[string]string
+	var bundles map[string]OPABundleStatus
+	var plugins map[string]OPAPluginStatus
+	if len(instance.Labels) > 0 {
+		_ = json.Unmarshal(instance.Labels, &labels)
+	}
+	if len(instance.Bundles) > 0 {
+		_ = json.Unmarshal(instance.Bundles, &bundles)
+	}
+	if len(instance.Plugins) > 0 {
+		_ = json.Unmarshal(instance.Plugins, &plugins)
+	}
+
+	healthy := true
+	for _, plugin := range plugins {
+		if plugin.State != OPAPluginStateOK {
+			healthy = false
+		}
+	}
+
+	response := OPAInstanceResponse{
+		ID:          instance.ID.String(),
+		InstanceID:  instance.InstanceID,
+		Version:     instance.Version,
+		Labels:      labels,
+		ClientID:    instance.ClientID,
+		IPAddress:   instance.IPAddress,
+		Bundles:     make([]OPAInstanceBundle, 0, len(bundles)),
+		Plugins:     plugins,
+		Stale:       time.Since(instance.LastSeenAt) > opaInstanceStaleAfter,
+		FirstSeenAt: instance.CreatedAt.Format(time.RFC3339),
+		LastSeenAt:  instance.LastSeenAt.Format(time.RFC3339),
+	}
+	for _, name := range sortedKeys(bundles) {
+		status := bundles[name]
+		bundle := OPAInstanceBundle{
+			Name:                     name,
+			ActiveRevision:           status.ActiveRevision,
+			LastSuccessfulDownload:   formatOptionalTime(status.LastSuccessfulDownload),
+			LastSuccessfulActivation: formatOptionalTime(status.LastSuccessfulActivation),
+			Error:                    status.Message,
+		}
+		if status.Code != "" {
+			healthy = false
+			if bundle.Error == "" {
+				bundle.Error = status.Code
+			}
+		}
+		response.Bundles = append(response.Bundles, bundle)
+	}
+	response.Healthy = healthy
+	return response
+}
+
+// formatOptionalTime formats a reported time, or returns an empty string
+func formatOptionalTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
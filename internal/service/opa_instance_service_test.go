@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestOPAInstanceStatus(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		author := testutil.CreateTestUser(t, db, acme, "author@acme.test")
+		bundle := &models.PolicyBundle{
+			TenantID: acme.ID, Name: "release", Version: "1.1.0", Status: models.BundleStatusActive,
+			Manifest:  []byte(`{"revision": "status-rev-2", "roots": ["tenants/acme"]}`),
+			CreatedBy: author.ID, UpdatedBy: author.ID,
+		}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		deployment := &models.BundleDeployment{BundleID: bundle.ID, DeployedAt: time.Now(), DeployedBy: author.ID, Revision: "status-rev-1", Status: "success"}
+		if err := db.Create(deployment).Error; err != nil {
+			t.Fatalf("Failed to create deployment: %v", err)
+		}
+
+		service := NewOPAInstanceService(db)
+		source := &OPAInstanceSource{TenantID: acme.ID, ClientID: "opa-sidecar", IPAddress: "10.0.3.7"}
+		reports := []string{
+			`{"labels": {"id": "opa-1", "version": "1.0.0"},
+			  "bundles": {"authz": {"name": "authz", "active_revision": "status-rev-1", "last_successful_activation": "2024-01-20T14:40:00Z"}},
+			  "plugins": {"bundle": {"state": "OK"}, "status": {"state": "OK"}}}`,
+			`{"labels": {"id": "opa-2", "version": "1.0.0"},
+			  "bundles": {"authz": {"name": "authz", "active_revision": "unknown-rev", "code": "bundle_error", "message": "server replied with not found"}},
+			  "plugins": {"bundle": {"state": "NOT_READY"}}}`,
+			// Later reports replace the instance's status
+			`{"labels": {"id": "opa-1", "version": "1.0.1"},
+			  "bundles": {"authz": {"name": "authz", "active_revision": "status-rev-2", "last_successful_activation": "2024-01-20T14:45:00Z"}},
+			  "plugins": {"bundle": {"state": "OK"}, "status": {"state": "OK"}}}`,
+		}
+		for _, report := range reports {
+			if err := service.ReportStatus(ctx, source, []byte(report)); err != nil {
+				t.Fatalf("ReportStatus failed: %v", err)
+			}
+		}
+
+		params := &pagination.Params{Page: 1, PageSize: 20, Sort: "instanceId"}
+		instances, _, err := service.ListInstances(ctx, acme.ID, params)
+		if err != nil {
+			t.Fatalf("ListInstances failed: %v", err)
+		}
+		if len(instances) != 2 {
+			t.Fatalf("Expected 2 instances, got %+v", instances)
+		}
+
+		current := instances[0]
+		if current.InstanceID != "opa-1" || current.Version != "1.0.1" || current.ClientID != "opa-sidecar" || !current.Healthy || current.Stale {
+			t.Errorf("Unexpected instance %+v", current)
+		}
+		if len(current.Bundles) != 1 {
+			t.Fatalf("Expected one bundle, got %+v", current.Bundles)
+		}
+		loaded := current.Bundles[0]
+		if loaded.ActiveRevision != "status-rev-2" || loaded.BundleID != bundle.ID.String() || loaded.BundleVersion != "1.1.0" ||
+			loaded.UpToDate == nil || !*loaded.UpToDate || loaded.LastSuccessfulActivation != "2024-01-20T14:45:00Z" {
+			t.Errorf("Expected the latest build of the bundle, got %+v", loaded)
+		}
+
+		failing := instances[1]
+		if failing.Healthy || failing.Bundles[0].Error != "server replied with not found" || failing.Bundles[0].UpToDate != nil {
+			t.Errorf("Expected an unhealthy instance with an unknown revision, got %+v", failing)
+		}
+
+		// Revisions of past deployments are known but out of date
+		if err := service.ReportStatus(ctx, source, []byte(`{"labels": {"id": "opa-2"}, "bundles": {"authz": {"active_revision": "status-rev-1"}}, "plugins": {"bundle": {"state": "OK"}}}`)); err != nil {
+			t.Fatalf("ReportStatus failed: %v", err)
+		}
+		instances, _, _ = service.ListInstances(ctx, acme.ID, params)
+		outdated := instances[1].Bundles[0]
+		if outdated.BundleID != bundle.ID.String() || outdated.UpToDate == nil || *outdated.UpToDate || !instances[1].Healthy {
+			t.Errorf("Expected a previous build of the bundle, got %+v", outdated)
+		}
+
+		// Instances that stopped reporting are stale
+		db.Model(&models.OPAInstance{}).Where("instance_id = ?", "opa-2").Update("last_seen_at", time.Now().Add(-time.Hour))
+		instances, _, _ = service.ListInstances(ctx, acme.ID, params)
+		if !instances[1].Stale {
+			t.Errorf("Expected opa-2 to be stale")
+		}
+
+		// Other tenants do not see the instances
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		instances, _, err = service.ListInstances(ctx, globex.ID, params)
+		if err != nil || len(instances) != 0 {
+			t.Errorf("Expected no instances for globex, got %+v: %v", instances, err)
+		}
+	})
+}
+
+func TestOPAInstanceStatusInvalid(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		service := NewOPAInstanceService(db)
+		source := &OPAInstanceSource{TenantID: acme.ID}
+
+		for name, body := range map[string]string{
+			"not an object": `[]`,
+			"no instance":   `{"labels": {"version": "1.0.0"}}`,
+		} {
+			if err := service.ReportStatus(ctx, source, []byte(body)); !isAppError(err, "INVALID_OPA_STATUS") {
+				t.Errorf("%s: expected INVALID_OPA_STATUS, got %v", name, err)
+			}
+		}
+
+		var count int64
+		db.Model(&models.OPAInstance{}).Count(&count)
+		if count != 0 {
+			t.Errorf("Expected no instances stored, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// Outbox operations applied to the identity provider
+const (
+	OutboxOpRegisterUser = "identity.register_user"
+	OutboxOpUpdateUser   = "identity.update_user"
+	OutboxOpDeleteUser   = "identity.delete_user"
+)
+
+const (
+	// registrationTimeout is how long a registration is left to the request that
+	// started it before the worker checks its outcome in the identity provider
+	registrationTimeout = 2 * time.Minute
+
+	// outboxLease hides a claimed entry from other workers while it is applied
+	outboxLease = time.Minute
+
+	outboxMaxBackoff = 10 * time.Minute
+)
+
+// registerUserPayload is recorded with a registration. The password is never
+// stored, so a registration that did not reach the provider is rolled back rather
+// than retried.
+type registerUserPayload struct {
+	Email    string `json:"email"`
+	TenantID string `json:"tenantId"`
+}
+
+// updateUserPayload holds the user fields to update in the identity provider
+type updateUserPayload struct {
+	Updates map[string]interface{} `json:"updates"`
+}
+
+// permanentError marks an outbox failure that retrying will not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// enqueueOutbox records an identity provider change in tx. The entry is applied once
+// the transaction commits and notBefore has passed.
+func enqueueOutbox(tx *gorm.DB, operation string, aggregateID uuid.UUID, payload interface{}, notBefore time.Time) (*models.OutboxEntry, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	entry := &models.OutboxEntry{
+		Operation:     operation,
+		AggregateID:   aggregateID,
+		Payload:       payloadJSON,
+		NextAttemptAt: notBefore,
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to record outbox entry: %w", err)
+	}
+	return entry, nil
+}
+
+// finishOutbox marks an entry completed, or failed when lastError is set
+func finishOutbox(ctx context.Context, db *gorm.DB, entryID uuid.UUID, lastError string) error {
+	status := models.OutboxStatusCompleted
+	if lastError != "" {
+		status = models.OutboxStatusFailed
+	}
+	now := time.Now()
+	return db.WithContext(ctx).Model(&models.OutboxEntry{}).
+		Where("id = ?", entryID).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"last_error":   lastError,
+			"processed_at": &now,
+		}).Error
+}
+
+// rollbackRegistration removes the local user of a registration that the identity
+// provider did not create and marks its outbox entry failed
+func rollbackRegistration(ctx context.Context, db *gorm.DB, entry *models.OutboxEntry, reason string) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", entry.AggregateID).Delete(&models.UserRole{}).Error; err != nil {
+			return fmt.Errorf("failed to remove user roles: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&models.User{}, "id = ?", entry.AggregateID).Error; err != nil {
+			return fmt.Errorf("failed to remove user: %w", err)
+		}
+		return finishOutbox(ctx, tx, entry.ID, reason)
+	})
+}
+
+// OutboxProcessor applies pending outbox entries to the identity provider, retrying
+// failures with exponential backoff
+type OutboxProcessor struct {
+	db       *gorm.DB
+	identity auth.IdentityProvider
+	cfg      *config.OutboxConfig
+}
+
+// NewOutboxProcessor creates a new outbox processor. A nil config retries each
+// entry up to 10 times.
+func NewOutboxProcessor(db *gorm.DB, identity auth.IdentityProvider, cfg *config.OutboxConfig) *OutboxProcessor {
+	if cfg == nil {
+		cfg = &config.OutboxConfig{PollInterval: 5 * time.Second, BatchSize: 50, MaxAttempts: 10}
+	}
+	return &OutboxProcessor{
+		db:       db,
+		identity: identity,
+		cfg:      cfg,
+	}
+}
+
+// Run processes pending entries every poll interval until ctx is cancelled
+func (p *OutboxProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.ProcessPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to process outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending applies the entries that are due and returns the number applied
+// or given up on. Entries that fail are rescheduled.
+func (p *OutboxProcessor) ProcessPending(ctx context.Context) (int, error) {
+	var entries []models.OutboxEntry
+	if err := p.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, time.Now()).
+		Order("next_attempt_at ASC").
+		Limit(p.cfg.BatchSize).
+		Find(&entries).Error; err != nil {
+		return 0, fmt.Errorf("failed to load outbox entries: %w", err)
+	}
+
+	processed := 0
+	for i := range entries {
+		entry := &entries[i]
+		claimed, err := p.claim(ctx, entry)
+		if err != nil {
+			return processed, err
+		}
+		if !claimed {
+			continue
+		}
+
+		done, err := p.process(ctx, entry)
+		if err != nil {
+			return processed, err
+		}
+		if done {
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+// Dispatch applies an entry right after the transaction that recorded it commits,
+// so the identity provider is usually updated before the request returns. Failures are left
+// to the worker.
+func (p *OutboxProcessor) Dispatch(ctx context.Context, entry *models.OutboxEntry) {
+	claimed, err := p.claim(ctx, entry)
+	if err == nil && claimed {
+		_, err = p.process(ctx, entry)
+	}
+	if err != nil {
+		log.Printf("Failed to dispatch outbox entry %s: %v", entry.ID, err)
+	}
+}
+
+// claim takes an entry for this worker by bumping its attempt count. Another
+// worker that loaded the same entry fails to claim it, and entries wait for the
+// earlier pending entries of their user so changes reach the provider in order.
+func (p *OutboxProcessor) claim(ctx context.Context, entry *models.OutboxEntry) (bool, error) {
+	result := p.db.WithContext(ctx).Model(&models.OutboxEntry{}).
+		Where("id = ? AND status = ? AND attempts = ?", entry.ID, models.OutboxStatusPending, entry.Attempts).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_entries earlier WHERE earlier.aggregate_id = ? AND earlier.status = ? AND earlier.created_at < ?)",
+			entry.AggregateID, models.OutboxStatusPending, entry.CreatedAt).
+		Updates(map[string]interface{}{
+			"attempts":        entry.Attempts + 1,
+			"next_attempt_at": time.Now().Add(outboxLease),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim outbox entry: %w", result.Error)
+	}
+	entry.Attempts++
+	return result.RowsAffected == 1, nil
+}
+
+// process applies a claimed entry and records the outcome. It reports whether
+// the entry is finished.
+func (p *OutboxProcessor) process(ctx context.Context, entry *models.OutboxEntry) (bool, error) {
+	err := p.apply(ctx, entry)
+	if err == nil {
+		return true, finishOutbox(ctx, p.db, entry.ID, "")
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || entry.Attempts >= p.cfg.MaxAttempts {
+		log.Printf("Outbox entry %s (%s for %s) failed after %d attempts: %v",
+			entry.ID, entry.Operation, entry.AggregateID, entry.Attempts, err)
+		return true, finishOutbox(ctx, p.db, entry.ID, err.Error())
+	}
+
+	return false, p.db.WithContext(ctx).Model(&models.OutboxEntry{}).
+		Where("id = ?", entry.ID).
+		Updates(map[string]interface{}{
+			"last_error":      err.Error(),
+			"next_attempt_at": time.Now().Add(retryBackoff(entry.Attempts, outboxMaxBackoff)),
+		}).Error
+}
+
+// apply performs the identity provider call of an entry
+func (p *OutboxProcessor) apply(ctx context.Context, entry *models.OutboxEntry) error {
+	userID := entry.AggregateID.String()
+
+	switch entry.Operation {
+	case OutboxOpRegisterUser:
+		// The request that registered the user did not record the outcome, so
+		// the provider decides whether the registration happened
+		_, err := p.identity.GetUser(ctx, userID)
+		if auth.IsNotFound(err) {
+			reason := "registration did not reach the identity provider"
+			if err := rollbackRegistration(ctx, p.db, entry, reason); err != nil {
+				return err
+			}
+			return &permanentError{err: errors.New(reason)}
+		}
+		return err
+
+	case OutboxOpUpdateUser:
+		var payload updateUserPayload
+		if err := json.Unmarshal(entry.Payload, &payload); err != nil {
+			return &permanentError{err: fmt.Errorf("invalid payload: %w", err)}
+		}
+		_, err := p.identity.UpdateUser(ctx, userID, payload.Updates)
+		if auth.IsRejected(err) {
+			return &permanentError{err: err}
+		}
+		return err
+
+	case OutboxOpDeleteUser:
+		err := p.identity.DeleteUser(ctx, userID)
+		if auth.IsNotFound(err) {
+			return nil
+		}
+		if auth.IsRejected(err) {
+			return &permanentError{err: err}
+		}
+		return err
+
+	default:
+		return &permanentError{err: fmt.Errorf("unknown outbox operation %q", entry.Operation)}
+	}
+}
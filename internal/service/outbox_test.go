@@ -0,0 +1,384 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+const fakeApplicationID = "heimdall-app"
+
+// fakeFusionAuth serves the subset of the FusionAuth user API used by the outbox
+type fakeFusionAuth struct {
+	mu             sync.Mutex
+	users          map[string]auth.IdentityUser
+	registerStatus int // Status returned by registrations, 200 when zero
+	failDeletes    int // Number of deletes that fail with a 500 before succeeding
+}
+
+func newFakeFusionAuth(t *testing.T) (*fakeFusionAuth, *auth.FusionAuthClient) {
+	fake := &fakeFusionAuth{users: map[string]auth.IdentityUser{}}
+	server := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(server.Close)
+	return fake, auth.NewFusionAuthClient(&config.AuthConfig{URL: server.URL, ApplicationID: fakeApplicationID})
+}
+
+func (f *fakeFusionAuth) addUser(id, email string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users[id] = auth.IdentityUser{
+		ID:            id,
+		Email:         email,
+		Active:        true,
+		Registrations: []auth.FusionAuthRegistration{{ApplicationID: fakeApplicationID}},
+	}
+}
+
+func (f *fakeFusionAuth) hasUser(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.users[id]
+	return ok
+}
+
+func (f *fakeFusionAuth) isActive(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.users[id].Active
+}
+
+func (f *fakeFusionAuth) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/user/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/user/registration":
+		if f.registerStatus != 0 {
+			w.WriteHeader(f.registerStatus)
+			return
+		}
+		var body struct {
+			User auth.IdentityUser `json:"user"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		body.User.Registrations = []auth.FusionAuthRegistration{{ApplicationID: fakeApplicationID}}
+		f.users[body.User.ID] = body.User
+		json.NewEncoder(w).Encode(map[string]interface{}{"user": body.User})
+
+	case r.Method == http.MethodPost && r.URL.Path == "/api/user/search":
+		users := []auth.IdentityUser{}
+		for _, user := range f.users {
+			users = append(users, user)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"total": len(users), "users": users})
+
+	case r.Method == http.MethodPatch:
+		user, ok := f.users[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body struct {
+			User struct {
+				Active *bool `json:"active"`
+			} `json:"user"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.User.Active != nil {
+			user.Active = *body.User.Active
+		}
+		f.users[id] = user
+		json.NewEncoder(w).Encode(map[string]interface{}{"user": user})
+
+	case r.Method == http.MethodDelete:
+		if f.failDeletes > 0 {
+			f.failDeletes--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if _, ok := f.users[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.users, id)
+
+	case r.Method == http.MethodGet:
+		user, ok := f.users[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"user": user})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func outboxEntryFor(t *testing.T, db *gorm.DB, userID uuid.UUID) models.OutboxEntry {
+	t.Helper()
+	var entry models.OutboxEntry
+	if err := db.Where("aggregate_id = ?", userID).Order("created_at DESC").First(&entry).Error; err != nil {
+		t.Fatalf("Failed to load outbox entry: %v", err)
+	}
+	return entry
+}
+
+func TestAuthService_Register_Outbox(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		fake, fusionAuth := newFakeFusionAuth(t)
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		authService := NewAuthService(db, fusionAuth, jwtService, nil, nil, nil)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		ctx := testutil.CreateTestContext(t)
+
+		resp, err := authService.Register(ctx, &RegisterRequest{
+			Email:     "alice@example.com",
+			Password:  "SecurePassword123!",
+			FirstName: "Alice",
+			LastName:  "Smith",
+			TenantID:  tenant.ID.String(),
+		})
+		if err != nil {
+			t.Fatalf("Register returned error: %v", err)
+		}
+
+		userID := uuid.MustParse(resp.User.ID)
+		if !fake.hasUser(resp.User.ID) {
+			t.Error("Expected the user to be created in FusionAuth")
+		}
+		if entry := outboxEntryFor(t, db, userID); entry.Status != models.OutboxStatusCompleted {
+			t.Errorf("Expected the registration entry to be completed, got %q", entry.Status)
+		}
+
+		// A registration rejected by FusionAuth leaves no local user behind
+		fake.registerStatus = http.StatusBadRequest
+		if _, err := authService.Register(ctx, &RegisterRequest{
+			Email:     "bob@example.com",
+			Password:  "SecurePassword123!",
+			FirstName: "Bob",
+			LastName:  "Smith",
+			TenantID:  tenant.ID.String(),
+		}); err == nil {
+			t.Fatal("Expected an error when FusionAuth rejects the registration")
+		}
+
+		var users int64
+		db.Unscoped().Model(&models.User{}).Where("email = ?", "bob@example.com").Count(&users)
+		if users != 0 {
+			t.Errorf("Expected the rejected user to be rolled back, found %d", users)
+		}
+		var failed int64
+		db.Model(&models.OutboxEntry{}).Where("status = ?", models.OutboxStatusFailed).Count(&failed)
+		if failed != 1 {
+			t.Errorf("Expected 1 failed registration entry, got %d", failed)
+		}
+	})
+}
+
+func TestOutboxProcessor_RegistrationOutcome(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		fake, fusionAuth := newFakeFusionAuth(t)
+		processor := NewOutboxProcessor(db, fusionAuth, nil)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		ctx := testutil.CreateTestContext(t)
+
+		// Two registrations interrupted after the local commit: one reached FusionAuth
+		reached := testutil.CreateTestUser(t, db, tenant, "reached@example.com")
+		lost := testutil.CreateTestUser(t, db, tenant, "lost@example.com")
+		fake.addUser(reached.ID.String(), reached.Email)
+		for _, user := range []*models.User{reached, lost} {
+			if _, err := enqueueOutbox(db, OutboxOpRegisterUser, user.ID, registerUserPayload{Email: user.Email}, time.Now()); err != nil {
+				t.Fatalf("Failed to enqueue registration: %v", err)
+			}
+		}
+
+		processed, err := processor.ProcessPending(ctx)
+		if err != nil {
+			t.Fatalf("ProcessPending returned error: %v", err)
+		}
+		if processed != 2 {
+			t.Errorf("Expected 2 entries processed, got %d", processed)
+		}
+
+		if entry := outboxEntryFor(t, db, reached.ID); entry.Status != models.OutboxStatusCompleted {
+			t.Errorf("Expected the registration in FusionAuth to be completed, got %q", entry.Status)
+		}
+		if entry := outboxEntryFor(t, db, lost.ID); entry.Status != models.OutboxStatusFailed {
+			t.Errorf("Expected the lost registration to be failed, got %q", entry.Status)
+		}
+		var users int64
+		db.Unscoped().Model(&models.User{}).Where("id = ?", lost.ID).Count(&users)
+		if users != 0 {
+			t.Error("Expected the user of the lost registration to be removed")
+		}
+	})
+}
+
+func TestUserService_DeactivateRestorePurge(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		fake, fusionAuth := newFakeFusionAuth(t)
+		userService := NewUserService(db, fusionAuth)
+		processor := NewOutboxProcessor(db, fusionAuth, nil)
+		purger := NewUserPurger(db, fusionAuth, 24*time.Hour)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		user := testutil.CreateTestUser(t, db, tenant, "alice@example.com")
+		fake.addUser(user.ID.String(), user.Email)
+		ctx := testutil.CreateTestContext(t)
+
+		// Deactivation disables the FusionAuth user rather than deleting it
+		if err := userService.DeactivateUser(ctx, user.ID.String()); err != nil {
+			t.Fatalf("DeactivateUser returned error: %v", err)
+		}
+		if !fake.hasUser(user.ID.String()) || fake.isActive(user.ID.String()) {
+			t.Fatal("Expected the FusionAuth user to be kept and deactivated")
+		}
+		if _, err := userService.GetUserProfile(ctx, user.ID.String()); err == nil {
+			t.Error("Expected a deactivated user to be hidden")
+		}
+		if err := userService.DeactivateUser(ctx, user.ID.String()); err == nil {
+			t.Error("Expected deactivating a deactivated user to fail")
+		}
+
+		profile, err := userService.RestoreUser(ctx, user.ID.String())
+		if err != nil {
+			t.Fatalf("RestoreUser returned error: %v", err)
+		}
+		if profile.Status != models.UserStatusActive || profile.DeactivatedAt != "" || !fake.isActive(user.ID.String()) {
+			t.Errorf("Expected an active user after restore, got %+v", profile)
+		}
+		if _, err := userService.RestoreUser(ctx, user.ID.String()); err == nil {
+			t.Error("Expected restoring an active user to fail")
+		}
+
+		// Users are purged once their retention has passed
+		if err := userService.DeactivateUser(ctx, user.ID.String()); err != nil {
+			t.Fatalf("DeactivateUser returned error: %v", err)
+		}
+		if purged, err := purger.Purge(ctx); err != nil || purged != 0 {
+			t.Fatalf("Expected no users purged within the retention, got %d (%v)", purged, err)
+		}
+		db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+		fake.failDeletes = 1
+		if purged, err := purger.Purge(ctx); err != nil || purged != 1 {
+			t.Fatalf("Expected 1 user purged, got %d (%v)", purged, err)
+		}
+		var users int64
+		db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Count(&users)
+		if users != 0 {
+			t.Error("Expected the purged user to be deleted locally")
+		}
+
+		// The immediate attempt failed, so the entry waits for a retry
+		entry := outboxEntryFor(t, db, user.ID)
+		if entry.Operation != OutboxOpDeleteUser || entry.Status != models.OutboxStatusPending || entry.Attempts != 1 {
+			t.Fatalf("Expected a pending delete after 1 attempt, got %s %q after %d", entry.Operation, entry.Status, entry.Attempts)
+		}
+		if !fake.hasUser(user.ID.String()) {
+			t.Fatal("Expected the failed delete to leave the FusionAuth user")
+		}
+
+		db.Model(&models.OutboxEntry{}).Where("id = ?", entry.ID).Update("next_attempt_at", time.Now().Add(-time.Second))
+		if _, err := processor.ProcessPending(ctx); err != nil {
+			t.Fatalf("ProcessPending returned error: %v", err)
+		}
+
+		if entry := outboxEntryFor(t, db, user.ID); entry.Status != models.OutboxStatusCompleted {
+			t.Errorf("Expected the delete to be completed on retry, got %q", entry.Status)
+		}
+		if fake.hasUser(user.ID.String()) {
+			t.Error("Expected the user to be deleted from FusionAuth")
+		}
+	})
+}
+
+func TestUserReconciler_Reconcile(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		fake, fusionAuth := newFakeFusionAuth(t)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		ctx := testutil.CreateTestContext(t)
+
+		inSync := testutil.CreateTestUser(t, db, tenant, "in-sync@example.com")
+		missingRemote := testutil.CreateTestUser(t, db, tenant, "missing@example.com")
+		renamed := testutil.CreateTestUser(t, db, tenant, "old@example.com")
+		recent := testutil.CreateTestUser(t, db, tenant, "recent@example.com")
+		deactivated := testutil.CreateTestUser(t, db, tenant, "deactivated@example.com")
+		orphan := uuid.New().String()
+
+		// Only users older than the grace period are reconciled
+		old := time.Now().Add(-time.Hour)
+		db.Model(&models.User{}).Where("id <> ?", recent.ID).Update("created_at", old)
+
+		fake.addUser(inSync.ID.String(), inSync.Email)
+		fake.addUser(renamed.ID.String(), "new@example.com")
+		fake.addUser(orphan, "orphan@example.com")
+		fake.addUser(deactivated.ID.String(), deactivated.Email)
+		db.Model(&models.User{}).Where("id = ?", deactivated.ID).Update("status", models.UserStatusDeactivated)
+		db.Delete(&models.User{}, "id = ?", deactivated.ID)
+
+		report, err := NewUserReconciler(db, fusionAuth, true).Reconcile(ctx)
+		if err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+
+		drift := map[string]string{}
+		for _, d := range report.Drift {
+			drift[d.UserID] = d.Kind
+			if !d.Repaired {
+				t.Errorf("Expected drift of %s to be repaired", d.UserID)
+			}
+		}
+		want := map[string]string{
+			missingRemote.ID.String(): DriftMissingInFusionAuth,
+			renamed.ID.String():       DriftEmailMismatch,
+			orphan:                    DriftMissingLocally,
+			deactivated.ID.String():   DriftDeletedLocally,
+		}
+		if len(drift) != len(want) {
+			t.Errorf("Expected drift %v, got %v", want, drift)
+		}
+		for id, kind := range want {
+			if drift[id] != kind {
+				t.Errorf("Expected %s drift for %s, got %q", kind, id, drift[id])
+			}
+		}
+
+		var remaining models.User
+		if err := db.Where("id = ?", renamed.ID).First(&remaining).Error; err != nil || remaining.Email != "new@example.com" {
+			t.Errorf("Expected the local email to be updated from FusionAuth, got %q (%v)", remaining.Email, err)
+		}
+		var missing int64
+		db.Model(&models.User{}).Where("id = ?", missingRemote.ID).Count(&missing)
+		if missing != 0 {
+			t.Error("Expected the user missing from FusionAuth to be deleted")
+		}
+		if entry := outboxEntryFor(t, db, uuid.MustParse(orphan)); entry.Operation != OutboxOpDeleteUser {
+			t.Errorf("Expected the orphaned FusionAuth user to be queued for deletion, got %q", entry.Operation)
+		}
+		if entry := outboxEntryFor(t, db, deactivated.ID); entry.Operation != OutboxOpUpdateUser {
+			t.Errorf("Expected the deactivated user to be queued for deactivation in FusionAuth, got %q", entry.Operation)
+		}
+	})
+}
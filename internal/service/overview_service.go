@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// overviewWindow is the period recent activity and error rates cover
+	overviewWindow = 24 * time.Hour
+	// overviewListSize caps the bundles and failed deployments listed
+	overviewListSize = 10
+	// opaHealthTimeout bounds the OPA health check of an overview
+	opaHealthTimeout = 2 * time.Second
+)
+
+// OPAHealthChecker checks that the OPA server Heimdall evaluates policies with is up
+type OPAHealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// OverviewService summarizes the state of all tenants for the admin landing page
+type OverviewService struct {
+	db  *gorm.DB
+	opa OPAHealthChecker
+}
+
+// NewOverviewService creates a new overview service. opa may be nil.
+func NewOverviewService(db *gorm.DB, opa OPAHealthChecker) *OverviewService {
+	return &OverviewService{db: db, opa: opa}
+}
+
+// AdminOverview is a snapshot of the tenants, users, bundles and OPA
+// instances of all tenants, and of recent failures
+type AdminOverview struct {
+	GeneratedAt       string             `json:"generatedAt" example:"2024-01-20T14:45:00Z"`
+	Tenants           StatusCounts       `json:"tenants"`
+	Users             UserOverview       `json:"users"`
+	Bundles           BundleOverview     `json:"bundles"`
+	OPA               OPAOverview        `json:"opa"`
+	FailedDeployments []FailedDeployment `json:"failedDeployments"` // Most recent first
+	ErrorRates        OverviewErrorRates `json:"errorRates"`
+}
+
+// StatusCounts counts records in total and per status
+type StatusCounts struct {
+	Total    int64            `json:"total" example:"12"`
+	ByStatus map[string]int64 `json:"byStatus"` // e.g. {"active": 11, "suspended": 1}
+}
+
+// UserOverview counts the users of all tenants
+type UserOverview struct {
+	Total         int64            `json:"total" example:"1250"`
+	ByStatus      map[string]int64 `json:"byStatus"`                    // e.g. {"active": 1200, "suspended": 50}
+	ActiveLast24h int64            `json:"activeLast24h" example:"840"` // Users who logged in in the last 24 hours
+}
+
+// BundleOverview lists the active policy bundles
+type BundleOverview struct {
+	Active int64                  `json:"active" example:"14"`
+	Recent []ActiveBundleRevision `json:"recent"` // Most recently activated first
+}
+
+// ActiveBundleRevision is an active policy bundle and the revision it was built with
+type ActiveBundleRevision struct {
+	BundleID    string `json:"bundleId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID    string `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Name        string `json:"name" example:"production"`
+	Version     string `json:"version" example:"1.2.0"`
+	Revision    string `json:"revision,omitempty" example:"9f86d081884c7d65"`
+	ActivatedAt string `json:"activatedAt,omitempty" example:"2024-01-20T14:45:00Z"`
+}
+
+// OPAOverview describes the health of OPA: the server Heimdall evaluates
+// policies with, and the instances reporting their status
+type OPAOverview struct {
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty" example:"connection refused"`
+	Instances int64  `json:"instances" example:"6"`
+	Healthy   int64  `json:"healthy" example:"5"`   // Reporting, with all plugins OK and no bundle errors
+	Unhealthy int64  `json:"unhealthy" example:"0"` // Reporting, with a plugin or bundle error
+	Stale     int64  `json:"stale" example:"1"`     // Not reported for over 5 minutes
+}
+
+// FailedDeployment is a policy bundle deployment that failed
+type FailedDeployment struct {
+	ID          string `json:"id" example:"550e8400-e29b-41d4-a716-446655440002"`
+	BundleID    string `json:"bundleId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	BundleName  string `json:"bundleName" example:"production"`
+	TenantID    string `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Environment string `json:"environment,omitempty" example:"production"`
+	Error       string `json:"error,omitempty" example:"bundle upload failed"`
+	DeployedAt  string `json:"deployedAt" example:"2024-01-20T14:45:00Z"`
+}
+
+// OverviewErrorRates are the error rates of the last 24 hours
+type OverviewErrorRates struct {
+	AdminActions         int64   `json:"adminActions" example:"320"`
+	AdminActionErrors    int64   `json:"adminActionErrors" example:"2"` // Failed with a server error
+	AdminActionErrorRate float64 `json:"adminActionErrorRate" example:"0.0063"`
+	Decisions            int64   `json:"decisions" example:"48210"`
+	DecisionErrors       int64   `json:"decisionErrors" example:"12"` // Reported decisions OPA failed to evaluate
+	DecisionErrorRate    float64 `json:"decisionErrorRate" example:"0.0002"`
+}
+
+// Overview returns a snapshot of all tenants. Counts are read from the read
+// replica, and the OPA health check is bounded to 2 seconds.
+func (s *OverviewService) Overview(ctx context.Context) (*AdminOverview, error) {
+	db := readReplica(s.db).WithContext(ctx)
+	now := time.Now()
+	overview := &AdminOverview{GeneratedAt: now.UTC().Format(time.RFC3339)}
+
+	var err error
+	if overview.Tenants, err = statusCounts(db.Model(&models.Tenant{})); err != nil {
+		return nil, fmt.Errorf("failed to count tenants: %w", err)
+	}
+	users, err := statusCounts(db.Model(&models.User{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	overview.Users.Total, overview.Users.ByStatus = users.Total, users.ByStatus
+	if err := db.Model(&models.User{}).
+		Where("last_login_at >= ?", now.Add(-overviewWindow)).
+		Count(&overview.Users.ActiveLast24h).Error; err != nil {
+		return nil, fmt.Errorf("failed to count active users: %w", err)
+	}
+
+	if overview.Bundles, err = activeBundles(db); err != nil {
+		return nil, err
+	}
+	if overview.OPA, err = s.opaOverview(ctx, db, now); err != nil {
+		return nil, err
+	}
+	if overview.FailedDeployments, err = failedDeployments(db); err != nil {
+		return nil, err
+	}
+	if overview.ErrorRates, err = overviewErrorRates(db, now.Add(-overviewWindow)); err != nil {
+		return nil, err
+	}
+	return overview, nil
+}
+
+// statusCounts counts the records of a model per status
+func statusCounts(query *gorm.DB) (StatusCounts, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := query.Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return StatusCounts{}, err
+	}
+	counts := StatusCounts{ByStatus: make(map[string]int64, len(rows))}
+	for _, row := range rows {
+		counts.Total += row.Count
+		counts.ByStatus[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// activeBundles counts the active bundles and lists the most recently activated
+func activeBundles(db *gorm.DB) (BundleOverview, error) {
+	overview := BundleOverview{Recent: []ActiveBundleRevision{}}
+	active := func() *gorm.DB {
+		return db.Model(&models.PolicyBundle{}).Where("status = ?", models.BundleStatusActive)
+	}
+	if err := active().Count(&overview.Active).Error; err != nil {
+		return overview, fmt.Errorf("failed to count active bundles: %w", err)
+	}
+
+	var bundles []models.PolicyBundle
+	if err := active().
+		Select("id, tenant_id, name, version, manifest, activated_at, updated_at").
+		Order("COALESCE(activated_at, updated_at) DESC").
+		Limit(overviewListSize).
+		Find(&bundles).Error; err != nil {
+		return overview, fmt.Errorf("failed to list active bundles: %w", err)
+	}
+	for _, bundle := range bundles {
+		var manifest struct {
+			Revision string `json:"revision"`
+		}
+		if len(bundle.Manifest) > 0 {
+			_ = json.Unmarshal(bundle.Manifest, &manifest)
+		}
+		overview.Recent = append(overview.Recent, ActiveBundleRevision{
+			BundleID:    bundle.ID.String(),
+			TenantID:    bundle.TenantID.String(),
+			Name:        bundle.Name,
+			Version:     bundle.Version,
+			Revision:    manifest.Revision,
+			ActivatedAt: formatOptionalTime(bundle.ActivatedAt),
+		})
+	}
+	return overview, nil
+}
+
+// opaOverview checks the OPA server and the health of the reporting instances
+func (s *OverviewService) opaOverview(ctx context.Context, db *gorm.DB, now time.Time) (OPAOverview, error) {
+	var overview OPAOverview
+	if s.opa != nil {
+		healthCtx, cancel := context.WithTimeout(ctx, opaHealthTimeout)
+		defer cancel()
+		if err := s.opa.Health(healthCtx); err != nil {
+			overview.Error = err.Error()
+		} else {
+			overview.Reachable = true
+		}
+	}
+
+	var instances []models.OPAInstance
+	if err := db.Select("id, bundles, plugins, last_seen_at").Find(&instances).Error; err != nil {
+		return overview, fmt.Errorf("failed to list OPA instances: %w", err)
+	}
+	overview.Instances = int64(len(instances))
+	for i := range instances {
+		switch instance := toOPAInstanceResponse(&instances[i]); {
+		case now.Sub(instances[i].LastSeenAt) > opaInstanceStaleAfter:
+			overview.Stale++
+		case instance.Healthy:
+			overview.Healthy++
+		default:
+			overview.Unhealthy++
+		}
+	}
+	return overview, nil
+}
+
+// failedDeployments lists the most recent failed bundle deployments
+func failedDeployments(db *gorm.DB) ([]FailedDeployment, error) {
+	var rows []struct {
+		ID           uuid.UUID
+		BundleID     uuid.UUID
+		BundleName   string
+		TenantID     uuid.UUID
+		Environment  string
+		ErrorMessage string
+		DeployedAt   time.Time
+	}
+	if err := db.Model(&models.BundleDeployment{}).
+		Select("bundle_deployments.id, bundle_deployments.bundle_id, policy_bundles.name AS bundle_name, policy_bundles.tenant_id, "+
+			"bundle_deployments.environment, bundle_deployments.error_message, bundle_deployments.deployed_at").
+		Joins("JOIN policy_bundles ON policy_bundles.id = bundle_deployments.bundle_id").
+		Where("bundle_deployments.status = ?", "failed").
+		Order("bundle_deployments.deployed_at DESC").
+		Limit(overviewListSize).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list failed deployments: %w", err)
+	}
+
+	deployments := make([]FailedDeployment, 0, len(rows))
+	for _, row := range rows {
+		deployments = append(deployments, FailedDeployment{
+			ID:          row.ID.String(),
+			BundleID:    row.BundleID.String(),
+			BundleName:  row.BundleName,
+			TenantID:    row.TenantID.String(),
+			Environment: row.Environment,
+			Error:       row.ErrorMessage,
+			DeployedAt:  row.DeployedAt.Format(time.RFC3339),
+		})
+	}
+	return deployments, nil
+}
+
+// overviewErrorRates counts the administrative actions that failed with a
+// server error and the reported decisions OPA failed to evaluate since a time
+func overviewErrorRates(db *gorm.DB, since time.Time) (OverviewErrorRates, error) {
+	var rates OverviewErrorRates
+	for eventType, counts := range map[string]struct {
+		errored       string
+		total, errors *int64
+	}{
+		AdminActionEventType: {"CASE WHEN status_code >= 500 THEN 1 ELSE 0 END", &rates.AdminActions, &rates.AdminActionErrors},
+		DecisionEventType:    {"CASE WHEN status = '" + DecisionError + "' THEN 1 ELSE 0 END", &rates.Decisions, &rates.DecisionErrors},
+	} {
+		var count struct {
+			Total  int64
+			Errors int64
+		}
+		if err := db.Model(&models.AuditLog{}).
+			Select("COUNT(*) AS total, COALESCE(SUM("+counts.errored+"), 0) AS errors").
+			Where("event_type = ? AND created_at >= ?", eventType, since).
+			Scan(&count).Error; err != nil {
+			return rates, fmt.Errorf("failed to count errors: %w", err)
+		}
+		*counts.total, *counts.errors = count.Total, count.Errors
+	}
+	rates.AdminActionErrorRate = ratio(rates.AdminActionErrors, rates.AdminActions)
+	rates.DecisionErrorRate = ratio(rates.DecisionErrors, rates.Decisions)
+	return rates, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// fakeOPAHealth reports a fixed OPA health
+type fakeOPAHealth struct {
+	err error
+}
+
+func (f fakeOPAHealth) Health(ctx context.Context) error {
+	return f.err
+}
+
+func TestAdminOverview(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		now := time.Now()
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		db.Model(globex).Update("status", "suspended")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.test")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.test")
+		testutil.CreateTestUser(t, db, globex, "gary@globex.test")
+		db.Model(alice).Update("last_login_at", now.Add(-time.Hour))
+		db.Model(bob).Update("status", models.UserStatusSuspended)
+
+		bundle := &models.PolicyBundle{
+			TenantID: acme.ID, Name: "release", Version: "1.1.0", Status: models.BundleStatusActive, ActivatedAt: &now,
+			Manifest:  []byte(`{"revision": "overview-rev-1"}`),
+			CreatedBy: alice.ID, UpdatedBy: alice.ID,
+		}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		deployments := []models.BundleDeployment{
+			{BundleID: bundle.ID, DeployedAt: now.Add(-2 * time.Hour), DeployedBy: alice.ID, Status: "success"},
+			{BundleID: bundle.ID, DeployedAt: now.Add(-time.Hour), DeployedBy: alice.ID, Environment: "production", Status: "failed", ErrorMessage: "upload failed"},
+		}
+		if err := db.Create(&deployments).Error; err != nil {
+			t.Fatalf("Failed to create deployments: %v", err)
+		}
+
+		instances := []models.OPAInstance{
+			{TenantID: acme.ID, InstanceID: "opa-1", Plugins: []byte(`{"bundle": {"state": "OK"}}`), LastSeenAt: now},
+			{TenantID: acme.ID, InstanceID: "opa-2", Plugins: []byte(`{"bundle": {"state": "NOT_READY"}}`), LastSeenAt: now},
+			{TenantID: globex.ID, InstanceID: "opa-3", Plugins: []byte(`{"bundle": {"state": "OK"}}`), LastSeenAt: now.Add(-time.Hour)},
+		}
+		if err := db.Create(&instances).Error; err != nil {
+			t.Fatalf("Failed to create OPA instances: %v", err)
+		}
+
+		entries := []models.AuditLog{
+			{TenantID: acme.ID, EventType: AdminActionEventType, Action: "roles.assign", Status: "success", StatusCode: 200, CreatedAt: now.Add(-time.Hour)},
+			{TenantID: acme.ID, EventType: AdminActionEventType, Action: "roles.assign", Status: "error", StatusCode: 500, CreatedAt: now.Add(-time.Hour)},
+			{TenantID: acme.ID, EventType: AdminActionEventType, Action: "roles.assign", Status: "error", StatusCode: 500, CreatedAt: now.AddDate(0, 0, -2)},
+			{TenantID: acme.ID, EventType: DecisionEventType, Action: "read", Status: DecisionAllowed, CreatedAt: now.Add(-time.Hour)},
+			{TenantID: globex.ID, EventType: DecisionEventType, Action: "read", Status: DecisionError, CreatedAt: now.Add(-time.Hour)},
+		}
+		if err := db.Create(&entries).Error; err != nil {
+			t.Fatalf("Failed to create audit log: %v", err)
+		}
+
+		service := NewOverviewService(db, fakeOPAHealth{err: errors.New("connection refused")})
+		overview, err := service.Overview(ctx)
+		if err != nil {
+			t.Fatalf("Overview failed: %v", err)
+		}
+
+		if overview.Tenants.Total != 2 || overview.Tenants.ByStatus["active"] != 1 || overview.Tenants.ByStatus["suspended"] != 1 {
+			t.Errorf("Unexpected tenant counts %+v", overview.Tenants)
+		}
+		if overview.Users.Total != 3 || overview.Users.ByStatus[models.UserStatusSuspended] != 1 || overview.Users.ActiveLast24h != 1 {
+			t.Errorf("Unexpected user counts %+v", overview.Users)
+		}
+		if overview.Bundles.Active != 1 || len(overview.Bundles.Recent) != 1 || overview.Bundles.Recent[0].Revision != "overview-rev-1" {
+			t.Errorf("Unexpected bundles %+v", overview.Bundles)
+		}
+		wantOPA := OPAOverview{Error: "connection refused", Instances: 3, Healthy: 1, Unhealthy: 1, Stale: 1}
+		if overview.OPA != wantOPA {
+			t.Errorf("OPA = %+v, want %+v", overview.OPA, wantOPA)
+		}
+		if len(overview.FailedDeployments) != 1 || overview.FailedDeployments[0].BundleName != "release" ||
+			overview.FailedDeployments[0].Error != "upload failed" || overview.FailedDeployments[0].TenantID != acme.ID.String() {
+			t.Errorf("Unexpected failed deployments %+v", overview.FailedDeployments)
+		}
+		wantRates := OverviewErrorRates{AdminActions: 2, AdminActionErrors: 1, AdminActionErrorRate: 0.5, Decisions: 2, DecisionErrors: 1, DecisionErrorRate: 0.5}
+		if overview.ErrorRates != wantRates {
+			t.Errorf("Error rates = %+v, want %+v", overview.ErrorRates, wantRates)
+		}
+
+		overview, err = NewOverviewService(db, fakeOPAHealth{}).Overview(ctx)
+		if err != nil || !overview.OPA.Reachable || overview.OPA.Error != "" {
+			t.Errorf("Expected OPA to be reachable, got %+v: %v", overview.OPA, err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/breach"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// passwordBreachCheckSetting is the tenant settings key turning the breached
+// password check on or off for the tenant, overriding the deployment default
+const passwordBreachCheckSetting = "passwordBreachCheck"
+
+// PasswordBreachPolicy rejects new passwords that appeared in data breaches,
+// for the tenants that check them
+type PasswordBreachPolicy struct {
+	db               *gorm.DB
+	checker          breach.Checker
+	enabledByDefault bool
+}
+
+// NewPasswordBreachPolicy creates a new breached password policy. Tenants
+// without the passwordBreachCheck setting are checked when enabledByDefault is
+// set. A nil checker disables the policy.
+func NewPasswordBreachPolicy(db *gorm.DB, checker breach.Checker, enabledByDefault bool) *PasswordBreachPolicy {
+	return &PasswordBreachPolicy{
+		db:               db,
+		checker:          checker,
+		enabledByDefault: enabledByDefault,
+	}
+}
+
+// Check returns PASSWORD_BREACHED when the tenant checks passwords and the
+// password appeared in a breach. Passwords are allowed when the breach
+// sources are unavailable, so an outage does not block sign-ups.
+func (p *PasswordBreachPolicy) Check(ctx context.Context, tenantID uuid.UUID, password string) error {
+	if p == nil || p.checker == nil {
+		return nil
+	}
+
+	var tenant models.Tenant
+	if err := p.db.WithContext(ctx).Select("id", "settings").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+	settings, err := tenantSettings(&tenant)
+	if err != nil {
+		return err
+	}
+	enabled := p.enabledByDefault
+	if value, ok := settings[passwordBreachCheckSetting].(bool); ok {
+		enabled = value
+	}
+	if !enabled {
+		return nil
+	}
+
+	breached, err := p.checker.Breached(ctx, password)
+	if err != nil {
+		log.Printf("Failed to check password against breaches, allowing it: %v", err)
+		return nil
+	}
+	if breached {
+		return apperrors.Validation("PASSWORD_BREACHED", "This password has appeared in a data breach, choose a different password")
+	}
+	return nil
+}
+
+// CheckUser checks a new password of a user against the policy of their tenant
+func (p *PasswordBreachPolicy) CheckUser(ctx context.Context, userID, password string) error {
+	if p == nil || p.checker == nil {
+		return nil
+	}
+
+	var user models.User
+	if err := p.db.WithContext(ctx).Select("id", "tenant_id").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return p.Check(ctx, user.TenantID, password)
+}
+
+// validatePasswordBreachSetting checks that the passwordBreachCheck setting,
+// if present, is a boolean
+func validatePasswordBreachSetting(settings map[string]interface{}) error {
+	value, ok := settings[passwordBreachCheckSetting]
+	if !ok || value == nil {
+		return nil
+	}
+	if _, ok := value.(bool); !ok {
+		return apperrors.Validation("INVALID_PASSWORD_BREACH_SETTING", passwordBreachCheckSetting+" must be true or false")
+	}
+	return nil
+}
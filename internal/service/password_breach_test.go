@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/breach"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestPasswordBreachPolicy(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		checked := testutil.CreateTestTenant(t, db, "Consumer", "consumer")
+		unchecked := testutil.CreateTestTenant(t, db, "Internal", "internal")
+		if err := db.Model(&models.Tenant{}).Where("id = ?", checked.ID).
+			Update("settings", []byte(`{"passwordBreachCheck": true}`)).Error; err != nil {
+			t.Fatalf("Failed to update tenant settings: %v", err)
+		}
+		alice := testutil.CreateTestUser(t, db, checked, "alice@consumer.com")
+
+		filter, _ := breach.NewBloomFilter(10, 0.001)
+		_ = filter.AddHash(breach.HashPassword("password123"))
+		policy := NewPasswordBreachPolicy(db, filter, false)
+
+		if err := policy.Check(ctx, checked.ID, "password123"); !isAppError(err, "PASSWORD_BREACHED") {
+			t.Errorf("Expected PASSWORD_BREACHED for a tenant checking passwords, got %v", err)
+		}
+		if err := policy.Check(ctx, checked.ID, "a unique passphrase"); err != nil {
+			t.Errorf("Expected an unbreached password to be allowed, got %v", err)
+		}
+		if err := policy.Check(ctx, unchecked.ID, "password123"); err != nil {
+			t.Errorf("Expected tenants without the setting to be unchecked by default, got %v", err)
+		}
+		if err := policy.CheckUser(ctx, alice.ID.String(), "password123"); !isAppError(err, "PASSWORD_BREACHED") {
+			t.Errorf("Expected PASSWORD_BREACHED for a user of a checking tenant, got %v", err)
+		}
+
+		// Tenants without the setting follow the deployment default
+		if err := NewPasswordBreachPolicy(db, filter, true).Check(ctx, unchecked.ID, "password123"); !isAppError(err, "PASSWORD_BREACHED") {
+			t.Errorf("Expected PASSWORD_BREACHED when checks are enabled by default, got %v", err)
+		}
+
+		// Unavailable breach sources do not block passwords
+		if err := NewPasswordBreachPolicy(db, unavailableChecker{}, true).Check(ctx, checked.ID, "password123"); err != nil {
+			t.Errorf("Expected passwords to be allowed when the check fails, got %v", err)
+		}
+
+		if err := validateTenantSettings(map[string]interface{}{"passwordBreachCheck": "yes"}); !isAppError(err, "INVALID_PASSWORD_BREACH_SETTING") {
+			t.Errorf("Expected INVALID_PASSWORD_BREACH_SETTING, got %v", err)
+		}
+	})
+}
+
+// unavailableChecker fails every check
+type unavailableChecker struct{}
+
+func (unavailableChecker) Breached(ctx context.Context, password string) (bool, error) {
+	return false, context.DeadlineExceeded
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// passwordPolicySetting is the tenant settings key holding the tenant's
+// password policy, whose fields override the deployment defaults
+const passwordPolicySetting = "passwordPolicy"
+
+// maxPasswordHistory caps the passwords remembered per user, as each one is
+// compared with a slow bcrypt hash when a password is set
+const maxPasswordHistory = 24
+
+// minCommonPasswordBase is the shortest common password matched after trailing
+// digits and symbols are removed, so short words such as "test" only match the
+// whole password
+const minCommonPasswordBase = 6
+
+// Rules a password can violate, listed in PASSWORD_POLICY_VIOLATION details
+const (
+	PasswordRuleMinLength        = "minLength"
+	PasswordRuleMaxLength        = "maxLength"
+	PasswordRuleRequireUppercase = "requireUppercase"
+	PasswordRuleRequireLowercase = "requireLowercase"
+	PasswordRuleRequireDigit     = "requireDigit"
+	PasswordRuleRequireSymbol    = "requireSymbol"
+	PasswordRuleDisallowCommon   = "disallowCommon"
+	PasswordRuleDisallowUserInfo = "disallowUserInfo"
+	PasswordRuleHistory          = "historySize"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList []byte
+
+// commonPasswords holds the lowercase common passwords of common_passwords.txt
+var commonPasswords = func() map[string]bool {
+	passwords := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(commonPasswordList))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			passwords[line] = true
+		}
+	}
+	return passwords
+}()
+
+// PasswordPolicy is the set of rules new passwords of a tenant's users must meet
+type PasswordPolicy struct {
+	MinLength        int  `json:"minLength" example:"8"`
+	MaxLength        int  `json:"maxLength" example:"128"` // 0 for no limit
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	DisallowCommon   bool `json:"disallowCommon"`          // Reject common passwords
+	DisallowUserInfo bool `json:"disallowUserInfo"`        // Reject passwords containing the user's email or name
+	HistorySize      int  `json:"historySize" example:"5"` // Reject reuse of the last N passwords
+}
+
+// legacyPasswordPolicy is applied when no password policy is configured,
+// matching the minimum length registrations were validated with before
+var legacyPasswordPolicy = PasswordPolicy{MinLength: 8}
+
+// PasswordOwner is the user a password is set for. UserID is nil for users
+// still registering, who have no password history.
+type PasswordOwner struct {
+	UserID    uuid.UUID
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// PasswordPolicyService evaluates new passwords against the password policy of
+// their tenant and keeps the history of passwords users set
+type PasswordPolicyService struct {
+	db       *gorm.DB
+	defaults PasswordPolicy
+	cost     int // bcrypt cost of password history hashes
+}
+
+// NewPasswordPolicyService creates a new password policy service applying
+// defaults to tenants without a passwordPolicy setting
+func NewPasswordPolicyService(db *gorm.DB, defaults PasswordPolicy) *PasswordPolicyService {
+	return &PasswordPolicyService{
+		db:       db,
+		defaults: defaults,
+		cost:     bcrypt.DefaultCost,
+	}
+}
+
+// GetPolicy returns the effective password policy of a tenant
+func (s *PasswordPolicyService) GetPolicy(ctx context.Context, tenantID uuid.UUID) (*PasswordPolicy, error) {
+	var tenant models.Tenant
+	if err := s.db.WithContext(ctx).Select("id", "settings").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	settings, err := tenantSettings(&tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := s.defaults
+	if override, ok := settings[passwordPolicySetting]; ok && override != nil {
+		encoded, err := json.Marshal(override)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode password policy: %w", err)
+		}
+		if err := json.Unmarshal(encoded, &policy); err != nil {
+			return nil, fmt.Errorf("failed to decode password policy of tenant %s: %w", tenantID, err)
+		}
+	}
+	return &policy, nil
+}
+
+// Evaluate returns PASSWORD_POLICY_VIOLATION, listing the violated rules, when
+// a new password does not meet the policy of the tenant
+func (s *PasswordPolicyService) Evaluate(ctx context.Context, tenantID uuid.UUID, owner PasswordOwner, password string) error {
+	if s == nil {
+		return policyViolation(&legacyPasswordPolicy, legacyPasswordPolicy.violations(password, owner))
+	}
+
+	policy, err := s.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	violations := policy.violations(password, owner)
+	if policy.HistorySize > 0 && owner.UserID != uuid.Nil {
+		reused, err := s.reused(ctx, owner.UserID, password, policy.HistorySize)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, PasswordRuleHistory)
+		}
+	}
+	return policyViolation(policy, violations)
+}
+
+// EvaluateUser evaluates a new password of an existing user
+func (s *PasswordPolicyService) EvaluateUser(ctx context.Context, userID, password string) error {
+	if s == nil {
+		return s.Evaluate(ctx, uuid.Nil, PasswordOwner{}, password)
+	}
+	tenantID, owner, err := s.owner(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.Evaluate(ctx, tenantID, owner, password)
+}
+
+// Record adds a password a user set to their history, when their tenant keeps
+// one, forgetting passwords beyond the history size. Failures are logged, as
+// the password has already been changed.
+func (s *PasswordPolicyService) Record(ctx context.Context, userID, password string) {
+	if s == nil {
+		return
+	}
+	if err := s.record(ctx, userID, password); err != nil {
+		log.Printf("Failed to record password history of user %s: %v", userID, err)
+	}
+}
+
+func (s *PasswordPolicyService) record(ctx context.Context, userID, password string) error {
+	tenantID, owner, err := s.owner(ctx, userID)
+	if err != nil {
+		return err
+	}
+	policy, err := s.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if policy.HistorySize <= 0 {
+		return nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.PasswordHistory{UserID: owner.UserID, PasswordHash: string(hash)}).Error; err != nil {
+			return fmt.Errorf("failed to store password history: %w", err)
+		}
+		var expired []uuid.UUID
+		if err := tx.Model(&models.PasswordHistory{}).
+			Where("user_id = ?", owner.UserID).
+			Order("created_at DESC").
+			Offset(historyLimit(policy.HistorySize)).
+			Pluck("id", &expired).Error; err != nil {
+			return fmt.Errorf("failed to list password history: %w", err)
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		return tx.Where("id IN ?", expired).Delete(&models.PasswordHistory{}).Error
+	})
+}
+
+// reused reports whether the password is among the last passwords of a user
+func (s *PasswordPolicyService) reused(ctx context.Context, userID uuid.UUID, password string, historySize int) (bool, error) {
+	var history []models.PasswordHistory
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(historyLimit(historySize)).
+		Find(&history).Error; err != nil {
+		return false, fmt.Errorf("failed to get password history: %w", err)
+	}
+	for _, entry := range history {
+		if bcrypt.CompareHashAndPassword([]byte(entry.PasswordHash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// owner loads the tenant, email and name of a user
+func (s *PasswordPolicyService) owner(ctx context.Context, userID string) (uuid.UUID, PasswordOwner, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "tenant_id", "email", "metadata").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, PasswordOwner{}, apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return uuid.Nil, PasswordOwner{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	owner := PasswordOwner{UserID: user.ID, Email: user.Email}
+	var metadata map[string]interface{}
+	if len(user.Metadata) > 0 && json.Unmarshal(user.Metadata, &metadata) == nil {
+		owner.FirstName, _ = metadata["firstName"].(string)
+		owner.LastName, _ = metadata["lastName"].(string)
+	}
+	return user.TenantID, owner, nil
+}
+
+// violations returns the rules a password violates, leaving out the history
+func (p *PasswordPolicy) violations(password string, owner PasswordOwner) []string {
+	var violations []string
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, PasswordRuleMinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, PasswordRuleMaxLength)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		violations = append(violations, PasswordRuleRequireUppercase)
+	}
+	if p.RequireLowercase && !lower {
+		violations = append(violations, PasswordRuleRequireLowercase)
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, PasswordRuleRequireDigit)
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, PasswordRuleRequireSymbol)
+	}
+	if p.DisallowCommon && isCommonPassword(password) {
+		violations = append(violations, PasswordRuleDisallowCommon)
+	}
+	if p.DisallowUserInfo && containsUserInfo(password, owner) {
+		violations = append(violations, PasswordRuleDisallowUserInfo)
+	}
+	return violations
+}
+
+// policyViolation returns PASSWORD_POLICY_VIOLATION for violated rules, or nil
+func policyViolation(policy *PasswordPolicy, violations []string) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return apperrors.Validation("PASSWORD_POLICY_VIOLATION", "Password does not meet the password policy").WithDetails(map[string]interface{}{
+		"violations": violations,
+		"policy":     policy,
+	})
+}
+
+// isCommonPassword reports whether a password is a common password, ignoring
+// case and trailing digits and symbols
+func isCommonPassword(password string) bool {
+	lowered := strings.ToLower(password)
+	if commonPasswords[lowered] {
+		return true
+	}
+	base := strings.TrimRightFunc(lowered, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return utf8.RuneCountInString(base) >= minCommonPasswordBase && commonPasswords[base]
+}
+
+// containsUserInfo reports whether a password contains the user's name, email
+// or a part of the email's local part of at least 3 characters
+func containsUserInfo(password string, owner PasswordOwner) bool {
+	lowered := strings.ToLower(password)
+	local, _, _ := strings.Cut(strings.ToLower(owner.Email), "@")
+	candidates := []string{local, strings.ToLower(owner.FirstName), strings.ToLower(owner.LastName)}
+	candidates = append(candidates, strings.FieldsFunc(local, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})...)
+	for _, candidate := range candidates {
+		if utf8.RuneCountInString(candidate) >= 3 && strings.Contains(lowered, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// historyLimit caps a history size at maxPasswordHistory
+func historyLimit(historySize int) int {
+	if historySize > maxPasswordHistory {
+		return maxPasswordHistory
+	}
+	return historySize
+}
+
+// validatePasswordPolicySetting checks that the passwordPolicy setting, if
+// present, is an object of known rules with valid values
+func validatePasswordPolicySetting(settings map[string]interface{}) error {
+	value, ok := settings[passwordPolicySetting]
+	if !ok || value == nil {
+		return nil
+	}
+	invalid := func(message string) *apperrors.Error {
+		return apperrors.Validation("INVALID_PASSWORD_POLICY", passwordPolicySetting+" "+message)
+	}
+	rules, ok := value.(map[string]interface{})
+	if !ok {
+		return invalid("must be an object")
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return invalid("is invalid")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	var policy PasswordPolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return invalid("has an unknown rule or a value of the wrong type").WithCause(err)
+	}
+
+	if _, ok := rules[PasswordRuleMinLength]; ok && policy.MinLength < 1 {
+		return invalid("minLength must be at least 1")
+	}
+	if policy.MaxLength < 0 {
+		return invalid("maxLength must be 0 or more")
+	}
+	if _, ok := rules[PasswordRuleMaxLength]; ok && policy.MaxLength > 0 && policy.MaxLength < policy.MinLength {
+		return invalid("maxLength must be at least minLength")
+	}
+	if policy.HistorySize < 0 || policy.HistorySize > maxPasswordHistory {
+		return invalid(fmt.Sprintf("historySize must be between 0 and %d", maxPasswordHistory))
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// policyViolations returns the rules listed by a PASSWORD_POLICY_VIOLATION
+func policyViolations(t *testing.T, err error) []string {
+	t.Helper()
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code != "PASSWORD_POLICY_VIOLATION" {
+		t.Fatalf("Expected PASSWORD_POLICY_VIOLATION, got %v", err)
+	}
+	return appErr.Details.(map[string]interface{})["violations"].([]string)
+}
+
+func TestPasswordPolicyService(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		strict := testutil.CreateTestTenant(t, db, "Bank", "bank")
+		relaxed := testutil.CreateTestTenant(t, db, "Blog", "blog")
+		if err := db.Model(&models.Tenant{}).Where("id = ?", strict.ID).
+			Update("settings", []byte(`{"passwordPolicy": {"minLength": 12, "requireSymbol": true, "disallowUserInfo": true, "historySize": 2}}`)).Error; err != nil {
+			t.Fatalf("Failed to update tenant settings: %v", err)
+		}
+		alice := testutil.CreateTestUser(t, db, strict, "alice.smith@bank.com")
+
+		policies := NewPasswordPolicyService(db, PasswordPolicy{MinLength: 8, MaxLength: 64, RequireDigit: true, DisallowCommon: true})
+		policies.cost = bcrypt.MinCost
+
+		policy, err := policies.GetPolicy(ctx, strict.ID)
+		if err != nil {
+			t.Fatalf("GetPolicy returned error: %v", err)
+		}
+		want := PasswordPolicy{MinLength: 12, MaxLength: 64, RequireDigit: true, RequireSymbol: true, DisallowCommon: true, DisallowUserInfo: true, HistorySize: 2}
+		if *policy != want {
+			t.Errorf("Expected the tenant's rules over the defaults, got %+v", policy)
+		}
+		if _, err := policies.GetPolicy(ctx, uuid.New()); !isAppError(err, "TENANT_NOT_FOUND") {
+			t.Errorf("Expected TENANT_NOT_FOUND, got %v", err)
+		}
+
+		for _, tt := range []struct {
+			password string
+			want     []string
+		}{
+			{"Password123!", []string{PasswordRuleDisallowCommon}},
+			{"short1", []string{PasswordRuleMinLength}},
+			{strings.Repeat("a1", 33), []string{PasswordRuleMaxLength}},
+			{"correct horse battery", []string{PasswordRuleRequireDigit}},
+			{"Test123456!", nil}, // Short common words only match whole passwords
+		} {
+			err := policies.Evaluate(ctx, relaxed.ID, PasswordOwner{}, tt.password)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Evaluate(%q) returned error: %v", tt.password, err)
+				}
+				continue
+			}
+			if got := policyViolations(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate(%q) violated %v, want %v", tt.password, got, tt.want)
+			}
+		}
+
+		if got := policyViolations(t, policies.EvaluateUser(ctx, alice.ID.String(), "smith-and-sons-1")); !reflect.DeepEqual(got, []string{PasswordRuleDisallowUserInfo}) {
+			t.Errorf("Expected a password containing the email to violate disallowUserInfo, got %v", got)
+		}
+
+		// The last two passwords cannot be reused
+		for _, password := range []string{"first-Passphrase-1", "second-Passphrase-2"} {
+			policies.Record(ctx, alice.ID.String(), password)
+		}
+		if got := policyViolations(t, policies.EvaluateUser(ctx, alice.ID.String(), "first-Passphrase-1")); !reflect.DeepEqual(got, []string{PasswordRuleHistory}) {
+			t.Errorf("Expected a recent password to violate historySize, got %v", got)
+		}
+		policies.Record(ctx, alice.ID.String(), "third-Passphrase-3")
+		if err := policies.EvaluateUser(ctx, alice.ID.String(), "first-Passphrase-1"); err != nil {
+			t.Errorf("Expected passwords beyond the history to be allowed, got %v", err)
+		}
+		var remembered int64
+		db.Model(&models.PasswordHistory{}).Where("user_id = ?", alice.ID).Count(&remembered)
+		if remembered != 2 {
+			t.Errorf("Expected 2 remembered passwords, got %d", remembered)
+		}
+
+		// Without a policy service, registrations keep the former minimum length
+		var unconfigured *PasswordPolicyService
+		if got := policyViolations(t, unconfigured.Evaluate(ctx, relaxed.ID, PasswordOwner{}, "123")); !reflect.DeepEqual(got, []string{PasswordRuleMinLength}) {
+			t.Errorf("Expected the legacy minimum length, got %v", got)
+		}
+
+		for _, setting := range []interface{}{
+			"strict",
+			map[string]interface{}{"minLength": 0.0},
+			map[string]interface{}{"minLength": 12.0, "maxLength": 10.0},
+			map[string]interface{}{"historySize": 100.0},
+			map[string]interface{}{"requireEmoji": true},
+			map[string]interface{}{"requireSymbol": "yes"},
+		} {
+			if err := validateTenantSettings(map[string]interface{}{"passwordPolicy": setting}); !isAppError(err, "INVALID_PASSWORD_POLICY") {
+				t.Errorf("Expected INVALID_PASSWORD_POLICY for %v, got %v", setting, err)
+			}
+		}
+		if err := validateTenantSettings(map[string]interface{}{"passwordPolicy": map[string]interface{}{"minLength": 10.0, "requireSymbol": true}}); err != nil {
+			t.Errorf("Expected a valid policy to be accepted, got %v", err)
+		}
+	})
+}
+
+func TestPasswordService_ResetPassword(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		if err := db.Model(&models.Tenant{}).Where("id = ?", tenant.ID).
+			Update("settings", []byte(`{"passwordPolicy": {"historySize": 3}}`)).Error; err != nil {
+			t.Fatalf("Failed to update tenant settings: %v", err)
+		}
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+
+		inbox := &linkInbox{}
+		provider := auth.NewNativeIdentityProvider(db, inbox)
+		if _, err := provider.Register(ctx, &auth.RegisterRequest{UserID: alice.ID.String(), Email: alice.Email, Password: "Original-Passphrase-1"}); err != nil {
+			t.Fatalf("Register returned error: %v", err)
+		}
+		policies := NewPasswordPolicyService(db, PasswordPolicy{MinLength: 8, DisallowCommon: true})
+		policies.cost = bcrypt.MinCost
+		policies.Record(ctx, alice.ID.String(), "Original-Passphrase-1")
+
+		passwords := NewPasswordService(provider)
+		passwords.SetPasswordPolicyService(policies)
+
+		if err := passwords.ForgotPassword(ctx, &ForgotPasswordRequest{Email: alice.Email}); err != nil {
+			t.Fatalf("ForgotPassword returned error: %v", err)
+		}
+		if len(inbox.bodies) != 1 {
+			t.Fatalf("Expected one reset email, got %d", len(inbox.bodies))
+		}
+		token := strings.Fields(strings.SplitN(inbox.bodies[0], "Reset token: ", 2)[1])[0]
+
+		if err := passwords.ResetPassword(ctx, &ResetPasswordRequest{Token: "not-the-token", NewPassword: "Another-Passphrase-2"}); !isAppError(err, "INVALID_RESET_TOKEN") {
+			t.Errorf("Expected INVALID_RESET_TOKEN, got %v", err)
+		}
+		if err := passwords.ResetPassword(ctx, &ResetPasswordRequest{Token: token, NewPassword: "Password1"}); !isAppError(err, "PASSWORD_POLICY_VIOLATION") {
+			t.Errorf("Expected a common password to be rejected, got %v", err)
+		}
+		if err := passwords.ResetPassword(ctx, &ResetPasswordRequest{Token: token, NewPassword: "Original-Passphrase-1"}); !isAppError(err, "PASSWORD_POLICY_VIOLATION") {
+			t.Errorf("Expected a reused password to be rejected, got %v", err)
+		}
+		if err := passwords.ResetPassword(ctx, &ResetPasswordRequest{Token: token, NewPassword: "Another-Passphrase-2"}); err != nil {
+			t.Fatalf("ResetPassword returned error: %v", err)
+		}
+
+		// The new password joins the history
+		err := passwords.ChangePassword(ctx, alice.ID.String(), &ChangePasswordRequest{
+			CurrentPassword: "Another-Passphrase-2",
+			NewPassword:     "Original-Passphrase-1",
+			ConfirmPassword: "Original-Passphrase-1",
+		})
+		if !isAppError(err, "PASSWORD_POLICY_VIOLATION") {
+			t.Errorf("Expected a reused password to be rejected on change, got %v", err)
+		}
+		var remembered int64
+		db.Model(&models.PasswordHistory{}).Where("user_id = ?", alice.ID).Count(&remembered)
+		if remembered != 2 {
+			t.Errorf("Expected 2 remembered passwords, got %d", remembered)
+		}
+
+		// Providers completing resets themselves cannot reset through Heimdall
+		_, client := newFakeFusionAuth(t)
+		if err := NewPasswordService(client).ResetPassword(ctx, &ResetPasswordRequest{Token: token, NewPassword: "Another-Passphrase-3"}); !isAppError(err, "PASSWORD_RESET_NOT_SUPPORTED") {
+			t.Errorf("Expected PASSWORD_RESET_NOT_SUPPORTED, got %v", err)
+		}
+	})
+}
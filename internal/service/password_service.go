@@ -2,27 +2,59 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/auth"
 )
 
 // PasswordService handles password-related operations
 type PasswordService struct {
-	fusionAuth *auth.FusionAuthClient
+	identity       auth.IdentityProvider
+	passwordBreach *PasswordBreachPolicy
+	passwordPolicy *PasswordPolicyService
+	credentials    *CredentialCache
 }
 
 // NewPasswordService creates a new password service
-func NewPasswordService(fusionAuth *auth.FusionAuthClient) *PasswordService {
+func NewPasswordService(identity auth.IdentityProvider) *PasswordService {
 	return &PasswordService{
-		fusionAuth: fusionAuth,
+		identity: identity,
 	}
 }
 
+// SetPasswordBreachPolicy rejects new passwords that appeared in breaches
+func (s *PasswordService) SetPasswordBreachPolicy(passwordBreach *PasswordBreachPolicy) {
+	s.passwordBreach = passwordBreach
+}
+
+// SetPasswordPolicyService evaluates new passwords against the password policy
+// of the user's tenant and records them in the user's password history
+func (s *PasswordService) SetPasswordPolicyService(passwordPolicy *PasswordPolicyService) {
+	s.passwordPolicy = passwordPolicy
+}
+
+// SetCredentialCache drops users' cached credentials when their password changes
+func (s *PasswordService) SetCredentialCache(credentials *CredentialCache) {
+	s.credentials = credentials
+}
+
+// GetPasswordPolicy returns the effective password policy of a tenant, so
+// clients can check new passwords before submitting them
+func (s *PasswordService) GetPasswordPolicy(ctx context.Context, tenantID uuid.UUID) (*PasswordPolicy, error) {
+	if s.passwordPolicy == nil {
+		policy := legacyPasswordPolicy
+		return &policy, nil
+	}
+	return s.passwordPolicy.GetPolicy(ctx, tenantID)
+}
+
 // ChangePasswordRequest represents a password change request
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"currentPassword" validate:"required" example:"OldPassword123!"`
-	NewPassword     string `json:"newPassword" validate:"required,min=8" example:"NewSecurePassword456!"`
+	NewPassword     string `json:"newPassword" validate:"required" example:"NewSecurePassword456!"`
 	ConfirmPassword string `json:"confirmPassword" validate:"required" example:"NewSecurePassword456!"`
 }
 
@@ -30,18 +62,85 @@ type ChangePasswordRequest struct {
 func (s *PasswordService) ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) error {
 	// Validate that new password and confirm password match
 	if req.NewPassword != req.ConfirmPassword {
-		return fmt.Errorf("new password and confirm password do not match")
+		return apperrors.Validation("PASSWORD_MISMATCH", "New password and confirm password do not match")
 	}
 
 	// Validate that new password is different from current password
 	if req.CurrentPassword == req.NewPassword {
-		return fmt.Errorf("new password must be different from current password")
+		return apperrors.Validation("PASSWORD_UNCHANGED", "New password must be different from current password")
+	}
+
+	if err := s.passwordPolicy.EvaluateUser(ctx, userID, req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.passwordBreach.CheckUser(ctx, userID, req.NewPassword); err != nil {
+		return err
 	}
 
-	// Change password in FusionAuth
-	if err := s.fusionAuth.ChangePassword(userID, req.CurrentPassword, req.NewPassword); err != nil {
+	// Change password in the identity provider
+	if err := s.identity.ChangePassword(ctx, userID, req.CurrentPassword, req.NewPassword); err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return apperrors.Validation("INVALID_CURRENT_PASSWORD", "Current password is incorrect")
+		}
 		return fmt.Errorf("failed to change password: %w", err)
 	}
+	s.passwordPolicy.Record(ctx, userID, req.NewPassword)
+	s.credentials.ForgetUser(ctx, userID)
+
+	return nil
+}
+
+// ForgotPasswordRequest represents a password reset request
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" example:"user@example.com"`
+}
+
+// ForgotPassword emails a password reset to the user. Unknown emails succeed
+// too, so the response does not reveal which emails are registered.
+func (s *PasswordService) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	if err := s.identity.ForgotPassword(ctx, req.Email); err != nil && !auth.IsNotFound(err) {
+		return fmt.Errorf("failed to start password reset: %w", err)
+	}
+	return nil
+}
+
+// ResetPasswordRequest represents a password reset with an emailed token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required" example:"3f9a1c..."`
+	NewPassword string `json:"newPassword" validate:"required" example:"NewSecurePassword456!"`
+}
+
+// ResetPassword sets a new password with a reset token. Identity providers
+// that complete resets in their own pages, such as FusionAuth, return
+// PASSWORD_RESET_NOT_SUPPORTED.
+func (s *PasswordService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	resetter, ok := s.identity.(auth.PasswordResetter)
+	if !ok {
+		return apperrors.Conflict("PASSWORD_RESET_NOT_SUPPORTED", "Password resets are completed with the identity provider's reset link")
+	}
+
+	user, err := resetter.ResetTokenUser(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return apperrors.Validation("INVALID_RESET_TOKEN", "Password reset token is invalid or expired")
+		}
+		return fmt.Errorf("failed to check reset token: %w", err)
+	}
+	if err := s.passwordPolicy.EvaluateUser(ctx, user.ID, req.NewPassword); err != nil {
+		return err
+	}
+	if err := s.passwordBreach.CheckUser(ctx, user.ID, req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := resetter.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return apperrors.Validation("INVALID_RESET_TOKEN", "Password reset token is invalid or expired")
+		}
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	s.passwordPolicy.Record(ctx, user.ID, req.NewPassword)
+	s.credentials.ForgetUser(ctx, user.ID)
 
 	return nil
 }
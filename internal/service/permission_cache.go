@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"gorm.io/gorm"
+)
+
+// AttributeSourcePermissions is the name of the attribute source adding users'
+// effective permissions to authorization inputs
+const AttributeSourcePermissions = "permissions"
+
+// PermissionCache resolves users' effective permissions, those of their active
+// role assignments, and caches them so authorization decisions do not look
+// them up in the database. Cached permissions are dropped when the user's
+// roles change and otherwise expire after the TTL, which bounds how long
+// changes to a role's permissions take to apply.
+type PermissionCache struct {
+	users *UserRepository
+	cache *database.RedisClient
+	ttl   time.Duration
+}
+
+// NewPermissionCache creates a permission cache. cache may be nil to resolve
+// permissions on every lookup.
+func NewPermissionCache(db *gorm.DB, cache *database.RedisClient, ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		users: NewUserRepository(db),
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+// UserPermissions returns the names of a user's effective permissions, sorted
+func (p *PermissionCache) UserPermissions(ctx context.Context, userID string) ([]string, error) {
+	if p.cache != nil {
+		if permissions, err := p.cache.GetCachedUserPermissions(ctx, userID); err == nil {
+			return permissions, nil
+		}
+	}
+	return p.Refresh(ctx, userID)
+}
+
+// Refresh resolves a user's effective permissions from the database and caches
+// them, e.g. when issuing the user's tokens so their first request is fast
+func (p *PermissionCache) Refresh(ctx context.Context, userID string) ([]string, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	permissions, err := p.users.GetUserPermissions(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	sort.Strings(names)
+
+	if p.cache != nil {
+		_ = p.cache.CacheUserPermissions(ctx, userID, names, p.ttl)
+	}
+	return names, nil
+}
+
+// Name returns the source name
+func (p *PermissionCache) Name() string {
+	return AttributeSourcePermissions
+}
+
+// Enrich sets the user's effective permissions on inputs whose access token
+// did not embed them
+func (p *PermissionCache) Enrich(ctx context.Context, input *opa.AuthorizationInput) error {
+	if input.User.ID == "" || input.User.Permissions != nil {
+		return nil
+	}
+
+	permissions, err := p.UserPermissions(ctx, input.User.ID)
+	if err != nil {
+		return err
+	}
+	input.User.Permissions = permissions
+	return nil
+}
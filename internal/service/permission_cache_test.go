@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// seedUserPermissions creates a user with a role granting the given permissions
+func seedUserPermissions(t testing.TB, db *gorm.DB, names ...string) (*models.User, *models.Role) {
+	tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+	user := testutil.CreateTestUser(t, db, tenant, "alice@acme.test")
+	role := testutil.CreateTestRole(t, db, tenant, "editor")
+	testutil.AssignRoleToUser(t, db, user, role)
+	for _, name := range names {
+		permission := testutil.CreateTestPermission(t, db, name, "policies", name)
+		testutil.AssignPermissionToRole(t, db, role, permission)
+	}
+	return user, role
+}
+
+func TestPermissionCache(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+		user, role := seedUserPermissions(t, db, "policies.write", "policies.read")
+
+		redis := database.NewMemoryClient()
+		permissions := NewPermissionCache(db, redis, time.Minute)
+		want := []string{"policies.read", "policies.write"}
+
+		input := &opa.AuthorizationInput{User: opa.UserContext{ID: user.ID.String()}}
+		if err := permissions.Enrich(ctx, input); err != nil {
+			t.Fatalf("Enrich failed: %v", err)
+		}
+		if !reflect.DeepEqual(input.User.Permissions, want) {
+			t.Errorf("Expected %v, got %v", want, input.User.Permissions)
+		}
+
+		// Later lookups are served from the cache
+		if err := db.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			t.Fatalf("Failed to revoke permissions: %v", err)
+		}
+		if cached, err := permissions.UserPermissions(ctx, user.ID.String()); err != nil || !reflect.DeepEqual(cached, want) {
+			t.Errorf("Expected the cached permissions, got %v, %v", cached, err)
+		}
+
+		// Permissions embedded in the access token are kept
+		embedded := &opa.AuthorizationInput{User: opa.UserContext{ID: user.ID.String(), Permissions: []string{"roles.read"}}}
+		if err := permissions.Enrich(ctx, embedded); err != nil || !reflect.DeepEqual(embedded.User.Permissions, []string{"roles.read"}) {
+			t.Errorf("Expected the embedded permissions to be kept, got %v, %v", embedded.User.Permissions, err)
+		}
+
+		// Invalidating the user's decisions drops the cached permissions
+		if err := opa.NewEvaluator(nil, redis, true).InvalidateUserCache(ctx, user.ID.String()); err != nil {
+			t.Fatalf("InvalidateUserCache failed: %v", err)
+		}
+		if fresh, err := permissions.UserPermissions(ctx, user.ID.String()); err != nil || len(fresh) != 0 {
+			t.Errorf("Expected the revoked permissions to be gone, got %v, %v", fresh, err)
+		}
+	})
+}
+
+// BenchmarkUserPermissions compares resolving a user's permissions from the
+// database per decision with prefetching them into the cache. The in-memory
+// cache leaves out the round trip to Redis, a fraction of the query's.
+func BenchmarkUserPermissions(b *testing.B) {
+	db := testutil.SetupTestDB(b)
+	defer testutil.CleanupTestDB(b, db)
+	testutil.TruncateTables(b, db)
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = string(rune('a'+i)) + ".read"
+	}
+	user, _ := seedUserPermissions(b, db, names...)
+	ctx := context.Background()
+
+	for name, cache := range map[string]*database.RedisClient{"database": nil, "cached": database.NewMemoryClient()} {
+		b.Run(name, func(b *testing.B) {
+			permissions := NewPermissionCache(db, cache, time.Hour)
+			for i := 0; i < b.N; i++ {
+				if _, err := permissions.UserPermissions(ctx, user.ID.String()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// regoImport matches the imports of data documents in a Rego module, e.g.
+// import data.authz.roles or import data.authz.roles.admin as admin
+var regoImport = regexp.MustCompile(`(?m)^\s*import\s+data\.([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)`)
+
+// moduleImports returns the data paths a Rego module imports, e.g. authz.roles,
+// sorted and without duplicates
+func moduleImports(content string) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	for _, match := range regoImport.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			imports = append(imports, match[1])
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// PolicyReference identifies a policy in a tenant's dependency graph
+type PolicyReference struct {
+	ID      uuid.UUID           `json:"id"`
+	Name    string              `json:"name"`
+	Path    string              `json:"path"`
+	Package string              `json:"package,omitempty"` // Empty for WebAssembly policies and policies without a valid package
+	Status  models.PolicyStatus `json:"status"`
+	IsValid bool                `json:"isValid"`
+}
+
+// published reports whether the policy can satisfy the imports of a published policy
+func (r PolicyReference) published() bool {
+	return r.Status == models.PolicyStatusActive && r.IsValid
+}
+
+// PolicyImport is an import of a policy with the policies of the tenant
+// defining the imported package
+type PolicyImport struct {
+	Import   string            `json:"import"`            // Imported data path, e.g. authz.roles
+	Package  string            `json:"package,omitempty"` // Package the import resolves to, empty for data no policy of the tenant defines
+	Policies []PolicyReference `json:"policies,omitempty"`
+}
+
+// PolicyDependencies lists the policies a policy imports and the policies
+// importing it
+type PolicyDependencies struct {
+	Policy     PolicyReference   `json:"policy"`
+	Imports    []PolicyImport    `json:"imports"`
+	Dependents []PolicyReference `json:"dependents"`
+}
+
+// PolicyWarning is a problem of a policy change that did not prevent it
+type PolicyWarning struct {
+	Code       string            `json:"code"`
+	Message    string            `json:"message"`
+	Dependents []PolicyReference `json:"dependents,omitempty"`
+}
+
+// policyNode is a policy in a tenant's dependency graph
+type policyNode struct {
+	ref     PolicyReference
+	imports []string
+}
+
+// policyGraph is the dependency graph of a tenant's policies, whose edges are
+// the imports of one policy's package by another. It is built from the
+// policies as stored, so it always reflects their current content.
+type policyGraph struct {
+	nodes    map[uuid.UUID]*policyNode
+	packages map[string][]*policyNode // Policies by package; a package may span several modules
+}
+
+// loadPolicyGraph builds the dependency graph of a tenant's policies
+func loadPolicyGraph(ctx context.Context, db *gorm.DB, tenantID uuid.UUID) (*policyGraph, error) {
+	var policies []models.Policy
+	if err := db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("path").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to get policies: %w", err)
+	}
+
+	graph := &policyGraph{
+		nodes:    make(map[uuid.UUID]*policyNode, len(policies)),
+		packages: make(map[string][]*policyNode),
+	}
+	for i := range policies {
+		policy := &policies[i]
+		node := &policyNode{ref: PolicyReference{
+			ID:      policy.ID,
+			Name:    policy.Name,
+			Path:    policy.Path,
+			Status:  policy.Status,
+			IsValid: policy.IsValid,
+		}}
+		graph.nodes[policy.ID] = node
+
+		if policy.Type == models.PolicyTypeWasm {
+			continue
+		}
+		// Policies that do not compile or declare no usable package neither
+		// import nor provide anything
+		compiled, err := regoPolicy(policy)
+		if err != nil {
+			continue
+		}
+		pkg, err := modulePackage(compiled)
+		if err != nil {
+			continue
+		}
+		node.ref.Package = pkg
+		node.imports = moduleImports(compiled.Content)
+		graph.packages[pkg] = append(graph.packages[pkg], node)
+	}
+	return graph, nil
+}
+
+// resolve returns the package of the tenant an imported data path refers to,
+// the longest package the path is or starts with, and the policies defining it
+func (g *policyGraph) resolve(path string) (string, []*policyNode) {
+	for candidate := path; candidate != ""; {
+		if nodes, ok := g.packages[candidate]; ok {
+			return candidate, nodes
+		}
+		i := strings.LastIndex(candidate, ".")
+		if i < 0 {
+			break
+		}
+		candidate = candidate[:i]
+	}
+	return "", nil
+}
+
+// imports returns the imports of a policy, resolved to the tenant's policies.
+// Imports of the policy's own package are left out.
+func (g *policyGraph) imports(node *policyNode) []PolicyImport {
+	imports := make([]PolicyImport, 0, len(node.imports))
+	for _, path := range node.imports {
+		pkg, providers := g.resolve(path)
+		if pkg != "" && pkg == node.ref.Package {
+			continue
+		}
+		imported := PolicyImport{Import: path, Package: pkg}
+		for _, provider := range providers {
+			imported.Policies = append(imported.Policies, provider.ref)
+		}
+		imports = append(imports, imported)
+	}
+	return imports
+}
+
+// dependents returns the policies importing a policy's package, other than
+// the policies of the same package
+func (g *policyGraph) dependents(node *policyNode) []PolicyReference {
+	dependents := []PolicyReference{}
+	if node.ref.Package == "" {
+		return dependents
+	}
+	for _, other := range g.nodes {
+		if other.ref.Package == node.ref.Package {
+			continue
+		}
+		for _, path := range other.imports {
+			if pkg, _ := g.resolve(path); pkg == node.ref.Package {
+				dependents = append(dependents, other.ref)
+				break
+			}
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i].Path < dependents[j].Path })
+	return dependents
+}
+
+// GetPolicyDependencies returns the policies a policy imports and the policies
+// of its tenant importing it
+func (s *PolicyService) GetPolicyDependencies(ctx context.Context, policyID uuid.UUID) (*PolicyDependencies, error) {
+	policy, err := s.GetPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := loadPolicyGraph(ctx, readReplica(s.db), policy.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	node := graph.nodes[policy.ID]
+	return &PolicyDependencies{
+		Policy:     node.ref,
+		Imports:    graph.imports(node),
+		Dependents: graph.dependents(node),
+	}, nil
+}
+
+// checkPolicyImports fails with POLICY_DEPENDENCY_UNPUBLISHED when a package
+// the policy imports from its tenant has no active, valid policy, so the
+// published policy would reference rules OPA does not serve
+func checkPolicyImports(graph *policyGraph, policy *models.Policy) error {
+	node, ok := graph.nodes[policy.ID]
+	if !ok {
+		return nil
+	}
+	var unpublished []PolicyImport
+	for _, imported := range graph.imports(node) {
+		if imported.Package == "" {
+			// Data synced by Heimdall or loaded into OPA by other means
+			continue
+		}
+		published := false
+		for _, provider := range imported.Policies {
+			published = published || provider.published()
+		}
+		if !published {
+			unpublished = append(unpublished, imported)
+		}
+	}
+	if len(unpublished) > 0 {
+		return apperrors.Conflict("POLICY_DEPENDENCY_UNPUBLISHED", "Policy imports packages without a published, valid policy; publish them first").
+			WithDetails(map[string]interface{}{"imports": unpublished})
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/policyfs"
+)
+
+// GitPolicySyncer syncs a tenant's policies from the .rego files of a Git repository
+type GitPolicySyncer struct {
+	policyService *PolicyService
+	cfg           *config.PolicySyncConfig
+	tenantID      uuid.UUID
+	jobs          *JobQueue
+
+	// mu serializes syncs so overlapping pushes are applied in order
+	mu sync.Mutex
+}
+
+// NewGitPolicySyncer creates a new Git policy syncer. Syncs started by pushes
+// are run by the job queue's workers.
+func NewGitPolicySyncer(policyService *PolicyService, cfg *config.PolicySyncConfig, jobs *JobQueue) (*GitPolicySyncer, error) {
+	tenantID, err := uuid.Parse(cfg.GitTenantID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid POLICY_GIT_TENANT_ID: %w", err)
+	}
+
+	s := &GitPolicySyncer{
+		policyService: policyService,
+		cfg:           cfg,
+		tenantID:      tenantID,
+		jobs:          jobs,
+	}
+	jobs.Register(JobPolicyGitSync, func(ctx context.Context, job *models.Job) (interface{}, error) {
+		return s.Sync(ctx)
+	})
+	return s, nil
+}
+
+// Branch returns the branch whose pushes trigger a sync
+func (s *GitPolicySyncer) Branch() string {
+	return s.cfg.GitBranch
+}
+
+// VerifySignature checks a push webhook against the configured secret. GitHub
+// signs the body in X-Hub-Signature-256 while GitLab sends the secret itself in
+// X-Gitlab-Token; either is accepted.
+func (s *GitPolicySyncer) VerifySignature(body []byte, githubSignature, gitlabToken string) bool {
+	if s.cfg.WebhookSecret == "" {
+		return false
+	}
+	if githubSignature != "" {
+		expected := "sha256=" + events.Sign(s.cfg.WebhookSecret, body)
+		return hmac.Equal([]byte(githubSignature), []byte(expected))
+	}
+	if gitlabToken != "" {
+		return hmac.Equal([]byte(gitlabToken), []byte(s.cfg.WebhookSecret))
+	}
+	return false
+}
+
+// EnqueueSync queues a sync, returning the policy.git_sync job running it.
+// Cloning can outlast the timeout of webhook senders.
+func (s *GitPolicySyncer) EnqueueSync(ctx context.Context) (*models.Job, error) {
+	return s.jobs.Enqueue(ctx, JobPolicyGitSync, s.tenantID, uuid.Nil, struct{}{})
+}
+
+// Sync clones the configured branch and syncs the policies found in the configured directory
+func (s *GitPolicySyncer) Sync(ctx context.Context) (*PolicySyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "heimdall-policies-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkout directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1", "--branch", s.cfg.GitBranch, s.cfg.GitRepoURL, dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to clone policy repository: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	files, err := policyfs.Read(filepath.Join(dir, filepath.FromSlash(s.cfg.GitDirectory)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies: %w", err)
+	}
+
+	// Policies synced from Git are not attributed to a user
+	return s.policyService.SyncPolicies(ctx, s.tenantID, uuid.Nil, &SyncPoliciesRequest{
+		Files:  files,
+		Delete: s.cfg.GitDelete,
+	})
+}
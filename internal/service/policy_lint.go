@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+)
+
+// PolicyContentRequest carries the content of a Rego module to format or lint
+type PolicyContentRequest struct {
+	Content string `json:"content" validate:"required"`
+}
+
+// PolicyFormatResult is a Rego module formatted with opa fmt
+type PolicyFormatResult struct {
+	Content string `json:"content"`
+	Changed bool   `json:"changed"` // Whether formatting changed the module, i.e. it was not formatted
+}
+
+// Severities of lint issues
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// PolicyLintIssue is a style or safety problem found in a Rego module
+type PolicyLintIssue struct {
+	Rule     string `json:"rule"` // unconditional-allow, missing-default-deny or unused-variable
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+// FormatPolicy formats a Rego module with opa fmt. It fails with
+// POLICY_SYNTAX_ERROR when the module does not parse, and with
+// POLICY_FORMATTER_UNAVAILABLE when the opa binary cannot be run.
+func (s *PolicyService) FormatPolicy(ctx context.Context, content string) (*PolicyFormatResult, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.opaBinary, "fmt")
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, apperrors.Validation("POLICY_SYNTAX_ERROR", "Policy could not be parsed").
+				WithDetails(map[string]interface{}{"errors": strings.TrimSpace(stderr.String())})
+		}
+		return nil, apperrors.Unavailable("POLICY_FORMATTER_UNAVAILABLE", "The opa binary formatting policies cannot be run").
+			WithCause(fmt.Errorf("failed to run %s fmt: %w", s.opaBinary, err))
+	}
+	return &PolicyFormatResult{
+		Content: stdout.String(),
+		Changed: stdout.String() != content,
+	}, nil
+}
+
+var (
+	regoRuleName   = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)`)
+	regoDefault    = regexp.MustCompile(`^default\s+([A-Za-z_][A-Za-z0-9_]*)\s*:?=\s*(.*?)\s*$`)
+	regoBareAllow  = regexp.MustCompile(`^allow\s*(?::?=\s*true)?\s*$`)
+	regoAssignment = regexp.MustCompile(`(?m)(?:^|;)\s*([A-Za-z_][A-Za-z0-9_]*)\s*:=`)
+	regoSome       = regexp.MustCompile(`\bsome\s+([A-Za-z_][A-Za-z0-9_]*(?:\s*,\s*[A-Za-z_][A-Za-z0-9_]*)*)`)
+	regoHeadIf     = regexp.MustCompile(`\sif\s`)
+)
+
+// regoStatement is a top level statement of a Rego module, e.g. a rule
+type regoStatement struct {
+	line int    // Line the statement starts on
+	text string // Text with comments and the contents of strings blanked out
+}
+
+// lineOf returns the line of an offset in the statement
+func (st regoStatement) lineOf(offset int) int {
+	return st.line + strings.Count(st.text[:offset], "\n")
+}
+
+// body returns the offset and text of the statement's rule body, either the
+// braces following the head or the expression following if, and whether it
+// has one
+func (st regoStatement) body() (int, string, bool) {
+	depth := 0
+	for i, r := range st.text {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '{':
+			if depth == 0 && !isRegoOperand(st.text[:i]) {
+				end := matchingBrace(st.text, i)
+				return i + 1, st.text[i+1 : end], true
+			}
+			depth++
+		}
+	}
+	if loc := regoHeadIf.FindStringIndex(st.text); loc != nil {
+		return loc[1], st.text[loc[1]:], true
+	}
+	return 0, "", false
+}
+
+// isRegoOperand reports whether a brace following the text opens a value,
+// e.g. x := {"a": 1}, rather than a rule body
+func isRegoOperand(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, operator := range []string{"=", ",", "(", "[", "{", "|"} {
+		if strings.HasSuffix(text, operator) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingBrace returns the offset of the brace closing the one at open, or
+// the end of the text if it is not closed
+func matchingBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
+
+// blankRego replaces comments and the contents of strings with spaces, keeping
+// offsets and lines, so braces and names in them are not mistaken for code
+func blankRego(content string) string {
+	out := []byte(content)
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '#':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case '"', '`':
+			quote := out[i]
+			for i++; i < len(out) && out[i] != quote; i++ {
+				if quote == '"' && out[i] == '\\' && i+1 < len(out) {
+					out[i] = ' '
+					i++
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+	return string(out)
+}
+
+// regoStatements splits a Rego module into its top level statements
+func regoStatements(content string) []regoStatement {
+	var statements []regoStatement
+	var current []string
+	start, depth := 0, 0
+	for i, line := range strings.Split(blankRego(content), "\n") {
+		if len(current) == 0 && strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(current) == 0 {
+			start = i + 1
+		}
+		current = append(current, line)
+		depth += strings.Count(line, "{") + strings.Count(line, "[") + strings.Count(line, "(") -
+			strings.Count(line, "}") - strings.Count(line, "]") - strings.Count(line, ")")
+		if depth <= 0 {
+			statements = append(statements, regoStatement{line: start, text: strings.TrimSpace(strings.Join(current, "\n"))})
+			current, depth = nil, 0
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, regoStatement{line: start, text: strings.TrimSpace(strings.Join(current, "\n"))})
+	}
+	return statements
+}
+
+// LintPolicy checks a Rego module for rules allowing unconditionally, allow
+// rules without a default deny, and local variables that are never used. It
+// only reads the module's text, so it also lints modules that do not compile.
+func LintPolicy(content string) []PolicyLintIssue {
+	issues := []PolicyLintIssue{}
+	allowLine, hasDefault := 0, false
+	for _, st := range regoStatements(content) {
+		if strings.HasPrefix(st.text, "package ") || strings.HasPrefix(st.text, "import ") {
+			continue
+		}
+		if match := regoDefault.FindStringSubmatch(st.text); match != nil {
+			if match[1] == "allow" {
+				hasDefault = true
+				if match[2] == "true" {
+					issues = append(issues, PolicyLintIssue{
+						Rule: "unconditional-allow", Severity: LintSeverityError, Line: st.line,
+						Message: "allow defaults to true, so every request no rule denies is allowed",
+					})
+				}
+			}
+			continue
+		}
+
+		name := regoRuleName.FindString(st.text)
+		offset, body, ok := st.body()
+		if name == "allow" {
+			if allowLine == 0 {
+				allowLine = st.line
+			}
+			if (!ok && regoBareAllow.MatchString(st.text)) || (ok && unconditionalBody(body)) {
+				issues = append(issues, PolicyLintIssue{
+					Rule: "unconditional-allow", Severity: LintSeverityError, Line: st.line,
+					Message: "allow rule has no conditions, so every request is allowed",
+				})
+			}
+		}
+		if ok {
+			issues = append(issues, unusedVariables(st, offset, body)...)
+		}
+	}
+	if allowLine > 0 && !hasDefault {
+		issues = append(issues, PolicyLintIssue{
+			Rule: "missing-default-deny", Severity: LintSeverityWarning, Line: allowLine,
+			Message: "allow has no default; add default allow := false so requests no rule allows are denied",
+		})
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// unconditionalBody reports whether a rule body holds only true expressions
+func unconditionalBody(body string) bool {
+	for _, expr := range strings.FieldsFunc(body, func(r rune) bool { return r == '\n' || r == ';' }) {
+		if expr = strings.TrimSpace(expr); expr != "" && expr != "true" {
+			return false
+		}
+	}
+	return true
+}
+
+// unusedVariables returns the local variables a rule body declares with :=
+// or some but never refers to again
+func unusedVariables(st regoStatement, offset int, body string) []PolicyLintIssue {
+	type declaration struct {
+		name   string
+		offset int
+	}
+	var declared []declaration
+	for _, match := range regoAssignment.FindAllStringSubmatchIndex(body, -1) {
+		declared = append(declared, declaration{body[match[2]:match[3]], offset + match[2]})
+	}
+	for _, match := range regoSome.FindAllStringSubmatchIndex(body, -1) {
+		for _, name := range strings.Split(body[match[2]:match[3]], ",") {
+			declared = append(declared, declaration{strings.TrimSpace(name), offset + match[2]})
+		}
+	}
+
+	var issues []PolicyLintIssue
+	for _, d := range declared {
+		if strings.HasPrefix(d.name, "_") || regoReferences(st.text, d.name) > 1 {
+			continue
+		}
+		issues = append(issues, PolicyLintIssue{
+			Rule: "unused-variable", Severity: LintSeverityWarning, Line: st.lineOf(d.offset),
+			Message: fmt.Sprintf("Variable %s is declared but never used", d.name),
+		})
+	}
+	return issues
+}
+
+// regoReferences counts the occurrences of a variable in a statement, leaving
+// out fields of the same name such as input.name
+func regoReferences(text, name string) int {
+	count := 0
+	for _, loc := range regexp.MustCompile(`\b`+regexp.QuoteMeta(name)+`\b`).FindAllStringIndex(text, -1) {
+		if loc[0] == 0 || text[loc[0]-1] != '.' {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []PolicyLintIssue
+	}{
+		{
+			name:    "clean",
+			content: "package authz\n\ndefault allow := false\n\nallow if {\n\trole := input.user.role\n\trole == \"admin\" # allow admins\n}\n",
+		},
+		{
+			name:    "unconditional allow",
+			content: "package authz\n\ndefault allow := false\n\nallow := true\n\nallow if {\n\ttrue\n}\n",
+			want: []PolicyLintIssue{
+				{Rule: "unconditional-allow", Severity: LintSeverityError, Line: 5},
+				{Rule: "unconditional-allow", Severity: LintSeverityError, Line: 7},
+			},
+		},
+		{
+			name:    "default allow",
+			content: "package authz\n\ndefault allow := true\n",
+			want:    []PolicyLintIssue{{Rule: "unconditional-allow", Severity: LintSeverityError, Line: 3}},
+		},
+		{
+			name:    "missing default deny",
+			content: "package authz\n\nallow if input.user.role == \"admin\"\n",
+			want:    []PolicyLintIssue{{Rule: "missing-default-deny", Severity: LintSeverityWarning, Line: 3}},
+		},
+		{
+			name:    "unused variables",
+			content: "package authz\n\ndefault allow := false\n\nallow if {\n\tuser := input.user\n\tsome role in input.roles\n\tname := \"user\"\n\tinput.user.name == name\n}\n\ndeny contains msg if {\n\tmsg := \"denied\"\n}\n",
+			want: []PolicyLintIssue{
+				{Rule: "unused-variable", Severity: LintSeverityWarning, Line: 6},
+				{Rule: "unused-variable", Severity: LintSeverityWarning, Line: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := LintPolicy(tt.content)
+			if len(issues) != len(tt.want) {
+				t.Fatalf("Expected %d issues, got %+v", len(tt.want), issues)
+			}
+			for i, want := range tt.want {
+				if issues[i].Rule != want.Rule || issues[i].Severity != want.Severity || issues[i].Line != want.Line {
+					t.Errorf("Expected %+v, got %+v", want, issues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatPolicy(t *testing.T) {
+	ctx := context.Background()
+	service := NewPolicyService(nil, nil)
+
+	service.SetOPABinary(filepath.Join(t.TempDir(), "missing"))
+	if _, err := service.FormatPolicy(ctx, "package authz\n"); !isAppError(err, "POLICY_FORMATTER_UNAVAILABLE") {
+		t.Errorf("Expected POLICY_FORMATTER_UNAVAILABLE without an opa binary, got %v", err)
+	}
+
+	// A stand-in for opa fmt normalizing indentation, failing on empty modules
+	binary := filepath.Join(t.TempDir(), "opa")
+	script := "#!/bin/sh\ninput=$(cat)\n[ -n \"$input\" ] || { echo '1 error occurred: rego_parse_error: empty module' >&2; exit 2; }\nprintf '%s\\n' \"$input\" | sed 's/^  */\t/'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write opa stand-in: %v", err)
+	}
+	service.SetOPABinary(binary)
+
+	result, err := service.FormatPolicy(ctx, "package authz\n\nallow if {\n  true\n}\n")
+	if err != nil || !result.Changed || result.Content != "package authz\n\nallow if {\n\ttrue\n}\n" {
+		t.Errorf("Expected the policy to be formatted, got %+v %v", result, err)
+	}
+	if result, err := service.FormatPolicy(ctx, result.Content); err != nil || result.Changed {
+		t.Errorf("Expected a formatted policy to be unchanged, got %+v %v", result, err)
+	}
+	if _, err := service.FormatPolicy(ctx, ""); !isAppError(err, "POLICY_SYNTAX_ERROR") {
+		t.Errorf("Expected POLICY_SYNTAX_ERROR, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// tenantPolicyRoot is the OPA data path under which each tenant's policies are
+// loaded, i.e. the policies of tenant acme define data.tenants.acme
+const tenantPolicyRoot = "tenants"
+
+// regoIdentifier matches the names Rego accepts as plain package path segments
+var regoIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// tenantPackage returns the package prefix of a tenant's policies, e.g.
+// tenants.acme, or tenants["acme-corp"] for slugs that are not identifiers
+func tenantPackage(slug string) string {
+	if regoIdentifier.MatchString(slug) {
+		return tenantPolicyRoot + "." + slug
+	}
+	return fmt.Sprintf("%s[%q]", tenantPolicyRoot, slug)
+}
+
+// tenantPolicyPath returns the OPA data path of a tenant's package, e.g.
+// tenants/acme/authz for package authz of tenant acme
+func tenantPolicyPath(slug, pkg string) string {
+	return tenantPolicyRoot + "/" + slug + "/" + strings.ReplaceAll(pkg, ".", "/")
+}
+
+// dottedPackage matches packages made of identifiers only, e.g. acme.authz
+var dottedPackage = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// modulePackage returns the package a policy's Rego module declares, which
+// must be a dotted name so it can be moved under the tenant's namespace
+func modulePackage(policy *models.Policy) (string, error) {
+	match := regoPackage.FindStringSubmatch(policy.Content)
+	if match == nil || !dottedPackage.MatchString(match[1]) {
+		return "", apperrors.Validation("INVALID_POLICY_PACKAGE", "Policy must declare a dotted package name, e.g. package acme.authz").
+			WithDetails(map[string]interface{}{"path": policy.Path})
+	}
+	return match[1], nil
+}
+
+// NamespacedPolicy is a policy as it is loaded into OPA, with its package moved
+// under its tenant's namespace
+type NamespacedPolicy struct {
+	Policy  *models.Policy
+	Slug    string // Slug of the policy's tenant
+	Package string // Package as written or compiled, e.g. authz; empty for WebAssembly policies
+	Content string // Rego content with the package and references between the tenant's packages rewritten
+}
+
+// Root returns the OPA data path the policy defines rules under, e.g.
+// tenants/acme/authz, or the tenant path of its file for WebAssembly policies
+func (p *NamespacedPolicy) Root() string {
+	if p.Package == "" {
+		return tenantPolicyRoot + "/" + p.Slug + "/" + p.Policy.Path
+	}
+	return tenantPolicyPath(p.Slug, p.Package)
+}
+
+// File returns the path of the policy in bundles, e.g. tenants/acme/authz/users.rego
+func (p *NamespacedPolicy) File() string {
+	return tenantPolicyRoot + "/" + p.Slug + "/" + p.Policy.Path + ".rego"
+}
+
+// namespacePolicies moves the packages of Rego policies under their tenants'
+// namespaces, so one tenant's policy can never shadow or extend the rules of
+// Heimdall or of another tenant in a shared OPA. References between a tenant's
+// packages are rewritten along; references to other data are kept.
+func namespacePolicies(ctx context.Context, db *gorm.DB, policies []models.Policy) ([]*NamespacedPolicy, error) {
+	slugs, err := tenantSlugs(ctx, db, policies)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaced := make([]*NamespacedPolicy, len(policies))
+	byTenant := make(map[uuid.UUID][]int)
+	for i := range policies {
+		policy := &policies[i]
+		slug, ok := slugs[policy.TenantID]
+		if !ok {
+			return nil, fmt.Errorf("tenant %s of policy %s not found", policy.TenantID, policy.Path)
+		}
+		namespaced[i] = &NamespacedPolicy{Policy: policy, Slug: slug, Content: policy.Content}
+		if policy.Type != models.PolicyTypeRego && policy.Type != models.PolicyTypeJSON && policy.Type != "" {
+			continue
+		}
+		// JSON policies are loaded as the Rego compiled from their rules
+		compiled, err := regoPolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		pkg, err := modulePackage(compiled)
+		if err != nil {
+			return nil, err
+		}
+		namespaced[i].Package = pkg
+		namespaced[i].Content = compiled.Content
+		byTenant[policy.TenantID] = append(byTenant[policy.TenantID], i)
+	}
+
+	for _, indexes := range byTenant {
+		modules := make([]string, len(indexes))
+		for j, i := range indexes {
+			modules[j] = namespaced[i].Content
+		}
+		rewritten := sandboxModules(modules, tenantPackage(namespaced[indexes[0]].Slug))
+		for j, i := range indexes {
+			namespaced[i].Content = rewritten[j]
+		}
+	}
+
+	return namespaced, nil
+}
+
+// tenantSlugs returns the slugs of the tenants of policies by tenant ID
+func tenantSlugs(ctx context.Context, db *gorm.DB, policies []models.Policy) (map[uuid.UUID]string, error) {
+	tenantIDs := make([]uuid.UUID, 0, len(policies))
+	for _, policy := range policies {
+		tenantIDs = append(tenantIDs, policy.TenantID)
+	}
+	var tenants []models.Tenant
+	if len(tenantIDs) > 0 {
+		if err := db.WithContext(ctx).Unscoped().Select("id", "slug").Where("id IN ?", tenantIDs).Find(&tenants).Error; err != nil {
+			return nil, fmt.Errorf("failed to get policy tenants: %w", err)
+		}
+	}
+	slugs := make(map[uuid.UUID]string, len(tenants))
+	for _, tenant := range tenants {
+		slugs[tenant.ID] = tenant.Slug
+	}
+	return slugs, nil
+}
+
+// tempModule moves the package of a module validated or tested at an OPA policy
+// ID such as temp/testing/<policyId> under a package unique to that ID
+func tempModule(tempPath, content string) string {
+	return sandboxModules([]string{content}, tempPackage(tempPath))[0]
+}
+
+// tempPackagePath returns the OPA data path of a package of a temporary module
+func tempPackagePath(tempPath, pkg string) string {
+	return strings.ReplaceAll(tempPackage(tempPath)+"."+pkg, ".", "/")
+}
+
+// tempPackage returns the package prefix of temporary modules at an OPA policy
+// ID, e.g. temp.testing.p<hex> for temp/testing/<uuid>
+func tempPackage(tempPath string) string {
+	segments := strings.Split(tempPath, "/")
+	last := len(segments) - 1
+	segments[last] = "p" + strings.ReplaceAll(segments[last], "-", "")
+	return strings.Join(segments, ".")
+}
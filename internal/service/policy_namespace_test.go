@@ -0,0 +1,78 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestTenantPackage(t *testing.T) {
+	for slug, want := range map[string]string{
+		"acme":      "tenants.acme",
+		"acme-corp": `tenants["acme-corp"]`,
+		"3m":        `tenants["3m"]`,
+	} {
+		if got := tenantPackage(slug); got != want {
+			t.Errorf("tenantPackage(%q) = %s, want %s", slug, got, want)
+		}
+	}
+	if got := tenantPolicyPath("acme-corp", "authz.users"); got != "tenants/acme-corp/authz/users" {
+		t.Errorf("Unexpected tenant policy path %s", got)
+	}
+}
+
+func TestNamespacePolicies(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex-corp")
+		policies := []models.Policy{
+			{TenantID: acme.ID, Path: "authz", Content: "package authz\n\nimport data.common\n\nallow if { common.is_admin; data.heimdall.tenants[input.tenant.id] }\n"},
+			{TenantID: acme.ID, Path: "common", Content: "# Shared helpers\npackage common\n\nis_admin if { input.user.roles[_] == \"admin\" }\n"},
+			{TenantID: globex.ID, Path: "authz", Content: "package heimdall.authz\n\nallow := true\n"},
+		}
+
+		namespaced, err := namespacePolicies(ctx, db, policies)
+		if err != nil {
+			t.Fatalf("namespacePolicies failed: %v", err)
+		}
+
+		// References between a tenant's packages follow them; other data is kept
+		for _, want := range []string{"package tenants.acme.authz\n", "import data.tenants.acme.common\n", "data.heimdall.tenants[input.tenant.id]"} {
+			if !strings.Contains(namespaced[0].Content, want) {
+				t.Errorf("Expected %q in %q", want, namespaced[0].Content)
+			}
+		}
+		if namespaced[0].File() != "tenants/acme/authz.rego" || namespaced[0].Root() != "tenants/acme/authz" {
+			t.Errorf("Unexpected file %s or root %s", namespaced[0].File(), namespaced[0].Root())
+		}
+		if !strings.HasPrefix(namespaced[1].Content, "# Shared helpers\npackage tenants.acme.common\n") {
+			t.Errorf("Expected the helpers in acme's namespace, got %q", namespaced[1].Content)
+		}
+
+		// A tenant cannot override Heimdall's rules
+		if !strings.HasPrefix(namespaced[2].Content, `package tenants["globex-corp"].heimdall.authz`) || namespaced[2].Root() != "tenants/globex-corp/heimdall/authz" {
+			t.Errorf("Expected globex's policy in its namespace, got %q at %s", namespaced[2].Content, namespaced[2].Root())
+		}
+
+		invalid := []models.Policy{{TenantID: acme.ID, Path: "invalid", Content: "package foo[\"bar\"]\n"}}
+		if _, err := namespacePolicies(ctx, db, invalid); !isAppError(err, "INVALID_POLICY_PACKAGE") {
+			t.Errorf("Expected INVALID_POLICY_PACKAGE, got %v", err)
+		}
+	})
+}
+
+func TestTempModule(t *testing.T) {
+	module := tempModule("temp/testing/3f2a-11ee", "package authz\n\nallow := true\n")
+	if module != "package temp.testing.p3f2a11ee.authz\n\nallow := true\n" {
+		t.Errorf("Unexpected temporary module %q", module)
+	}
+	if path := tempPackagePath("temp/testing/3f2a-11ee", "authz"); path != "temp/testing/p3f2a11ee/authz" {
+		t.Errorf("Unexpected temporary package path %s", path)
+	}
+}
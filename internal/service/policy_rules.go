@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+)
+
+// Operators of policy rule conditions
+const (
+	RuleOperatorEquals      = "equals"
+	RuleOperatorNotEquals   = "notEquals"
+	RuleOperatorIn          = "in"
+	RuleOperatorNotIn       = "notIn"
+	RuleOperatorContains    = "contains"
+	RuleOperatorGreaterThan = "greaterThan"
+	RuleOperatorLessThan    = "lessThan"
+	RuleOperatorExists      = "exists"
+	RuleOperatorInCIDR      = "inCidr"
+)
+
+// ruleWildcard matches any resource type or action
+const ruleWildcard = "*"
+
+// PolicyRuleSet is the content of a JSON policy: structured rules that
+// Heimdall compiles into a Rego module when the policy is validated, tested
+// or bundled. A request is allowed when any rule matches it.
+type PolicyRuleSet struct {
+	Package string       `json:"package,omitempty" example:"authz"` // Package of the compiled module; defaults to authz
+	Rules   []PolicyRule `json:"rules"`
+}
+
+// PolicyRule allows actions on resource types to subjects when all conditions hold
+type PolicyRule struct {
+	Description string                `json:"description,omitempty" example:"Analysts read reports from the office"`
+	Subjects    PolicyRuleSubjects    `json:"subjects"`  // Any subject matches when empty
+	Resources   []string              `json:"resources"` // Resource types, or * for any
+	Actions     []string              `json:"actions"`   // Actions, or * for any
+	Conditions  []PolicyRuleCondition `json:"conditions,omitempty"`
+}
+
+// PolicyRuleSubjects matches users with any of the roles or permissions, or
+// any of the listed users
+type PolicyRuleSubjects struct {
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	Users       []string `json:"users,omitempty"` // User IDs
+}
+
+// PolicyRuleCondition compares an attribute of the authorization input, e.g.
+// context.ipAddress, to a value or to another attribute
+type PolicyRuleCondition struct {
+	Attribute string      `json:"attribute" example:"user.attributes.department"`
+	Operator  string      `json:"operator" enums:"equals,notEquals,in,notIn,contains,greaterThan,lessThan,exists,inCidr" example:"equals"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueFrom string      `json:"valueFrom,omitempty" example:"resource.owner"` // Attribute to compare to instead of a value
+}
+
+// ruleAttribute matches attributes of the authorization input rules may refer to
+var ruleAttribute = regexp.MustCompile(`^(user|resource|action|tenant|context|time)(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// regoPolicy returns a policy as Rego: Rego policies as they are, and JSON
+// policies with their rules compiled into their content
+func regoPolicy(policy *models.Policy) (*models.Policy, error) {
+	if policy.Type != models.PolicyTypeJSON {
+		return policy, nil
+	}
+	content, err := compilePolicyRules(policy.Content)
+	if err != nil {
+		return nil, err
+	}
+	compiled := *policy
+	compiled.Type = models.PolicyTypeRego
+	compiled.Content = content
+	return &compiled, nil
+}
+
+// compilePolicyRules compiles the content of a JSON policy into a Rego module
+func compilePolicyRules(content string) (string, error) {
+	var ruleSet PolicyRuleSet
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ruleSet); err != nil {
+		return "", apperrors.Validation("INVALID_POLICY_RULES", "Policy rules are not a valid rule set").WithCause(err)
+	}
+	return ruleSet.Compile()
+}
+
+// Compile validates the rules and renders them as a Rego module, with values
+// written as literals so they cannot change the module's structure
+func (r *PolicyRuleSet) Compile() (string, error) {
+	pkg := r.Package
+	if pkg == "" {
+		pkg = defaultTemplatePackage
+	}
+	problems := make(map[string]interface{})
+	if !dottedPackage.MatchString(pkg) {
+		problems["package"] = "Must be a dotted name, e.g. acme.authz"
+	}
+	if len(r.Rules) == 0 {
+		problems["rules"] = "At least one rule is required"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s\n\nimport data.heimdall.helpers\n", pkg)
+	for i, rule := range r.Rules {
+		field := fmt.Sprintf("rules[%d]", i)
+		if rule.validate(field, problems) {
+			continue
+		}
+		rule.render(&b, i)
+	}
+
+	if len(problems) > 0 {
+		return "", apperrors.Validation("INVALID_POLICY_RULES", "Invalid policy rules").WithDetails(problems)
+	}
+	return b.String(), nil
+}
+
+// validate records the problems of a rule, reporting whether there were any
+func (r *PolicyRule) validate(field string, problems map[string]interface{}) bool {
+	count := len(problems)
+	if len(r.Resources) == 0 || slices.Contains(r.Resources, "") {
+		problems[field+".resources"] = "Must list resource types, or * for any"
+	}
+	if len(r.Actions) == 0 || slices.Contains(r.Actions, "") {
+		problems[field+".actions"] = "Must list actions, or * for any"
+	}
+	for j, condition := range r.Conditions {
+		if problem := condition.validate(); problem != "" {
+			problems[fmt.Sprintf("%s.conditions[%d]", field, j)] = problem
+		}
+	}
+	return len(problems) > count
+}
+
+// validate returns the problem with a condition, if any
+func (c *PolicyRuleCondition) validate() string {
+	if !ruleAttribute.MatchString(c.Attribute) {
+		return fmt.Sprintf("Attribute %q must be a dotted path under user, resource, action, tenant, context or time", c.Attribute)
+	}
+	if c.ValueFrom != "" {
+		if !ruleAttribute.MatchString(c.ValueFrom) {
+			return fmt.Sprintf("valueFrom %q must be a dotted path under user, resource, action, tenant, context or time", c.ValueFrom)
+		}
+		if c.Value != nil {
+			return "Set either value or valueFrom"
+		}
+	}
+
+	switch c.Operator {
+	case RuleOperatorExists:
+		if c.Value != nil || c.ValueFrom != "" {
+			return "exists takes no value"
+		}
+	case RuleOperatorEquals, RuleOperatorNotEquals, RuleOperatorContains:
+		if c.Value == nil && c.ValueFrom == "" {
+			return c.Operator + " requires a value"
+		}
+	case RuleOperatorGreaterThan, RuleOperatorLessThan:
+		if _, ok := c.Value.(float64); !ok && c.ValueFrom == "" {
+			return c.Operator + " requires a number"
+		}
+	case RuleOperatorIn, RuleOperatorNotIn:
+		if items, ok := c.Value.([]interface{}); (!ok || len(items) == 0) && c.ValueFrom == "" {
+			return c.Operator + " requires a non-empty list"
+		}
+	case RuleOperatorInCIDR:
+		items, ok := c.Value.([]interface{})
+		if !ok || len(items) == 0 {
+			return "inCidr requires a non-empty list of CIDR ranges"
+		}
+		for _, item := range items {
+			cidr, _ := item.(string)
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Sprintf("%v is not a CIDR range", item)
+			}
+		}
+	default:
+		return fmt.Sprintf("Unknown operator %q", c.Operator)
+	}
+	return ""
+}
+
+// render writes a rule as allow rules, one per kind of subject it matches
+func (r *PolicyRule) render(b *strings.Builder, index int) {
+	var subjects []string
+	if len(r.Subjects.Roles) > 0 {
+		subjects = append(subjects, "helpers.has_any_role("+regoLiteral(r.Subjects.Roles)+")")
+	}
+	if len(r.Subjects.Permissions) > 0 {
+		subjects = append(subjects, "helpers.has_any_permission("+regoLiteral(r.Subjects.Permissions)+")")
+	}
+	if len(r.Subjects.Users) > 0 {
+		subjects = append(subjects, "input.user.id in "+regoLiteral(r.Subjects.Users))
+	}
+	if len(subjects) == 0 {
+		subjects = []string{""}
+	}
+
+	var body []string
+	if !slices.Contains(r.Resources, ruleWildcard) {
+		body = append(body, "input.resource.type in "+regoLiteral(r.Resources))
+	}
+	if !slices.Contains(r.Actions, ruleWildcard) {
+		body = append(body, "input.action in "+regoLiteral(r.Actions))
+	}
+	for _, condition := range r.Conditions {
+		body = append(body, condition.render()...)
+	}
+
+	description := fmt.Sprintf("Rule %d", index+1)
+	if r.Description != "" {
+		description += ": " + strings.Join(strings.Fields(r.Description), " ")
+	}
+	for _, subject := range subjects {
+		fmt.Fprintf(b, "\n# %s\nallow if {\n", description)
+		if subject != "" {
+			fmt.Fprintf(b, "    %s\n", subject)
+		}
+		for _, expr := range body {
+			fmt.Fprintf(b, "    %s\n", expr)
+		}
+		if subject == "" && len(body) == 0 {
+			b.WriteString("    true\n")
+		}
+		b.WriteString("}\n")
+	}
+}
+
+// render returns the Rego expressions of a condition
+func (c *PolicyRuleCondition) render() []string {
+	attribute := "input." + c.Attribute
+	value := regoLiteral(c.Value)
+	if c.ValueFrom != "" {
+		value = "input." + c.ValueFrom
+	}
+
+	switch c.Operator {
+	case RuleOperatorExists:
+		return []string{attribute}
+	case RuleOperatorNotEquals:
+		return []string{attribute + " != " + value}
+	case RuleOperatorIn:
+		return []string{attribute + " in " + value}
+	case RuleOperatorNotIn:
+		return []string{"not " + attribute + " in " + value}
+	case RuleOperatorContains:
+		return []string{value + " in " + attribute}
+	case RuleOperatorGreaterThan:
+		return []string{attribute + " > " + value}
+	case RuleOperatorLessThan:
+		return []string{attribute + " < " + value}
+	case RuleOperatorInCIDR:
+		return []string{"count(net.cidr_contains_matches(" + value + ", " + attribute + ")) > 0"}
+	default:
+		return []string{attribute + " == " + value}
+	}
+}
+
+// ruleProblems describes why the rules of a JSON policy failed to compile
+func ruleProblems(err error) string {
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		return err.Error()
+	}
+	problems, _ := appErr.Details.(map[string]interface{})
+	if len(problems) == 0 {
+		return appErr.Error()
+	}
+	descriptions := make([]string, 0, len(problems))
+	for _, field := range sortedKeys(problems) {
+		descriptions = append(descriptions, fmt.Sprintf("%s: %v", field, problems[field]))
+	}
+	return appErr.Message + ": " + strings.Join(descriptions, "; ")
+}
+
+// regoLiteral writes a JSON value as a Rego literal
+func regoLiteral(value interface{}) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestCompilePolicyRules(t *testing.T) {
+	content, err := compilePolicyRules(`{
+		"rules": [
+			{
+				"description": "Analysts read reports\nfrom the office",
+				"subjects": {"roles": ["analyst"], "users": ["u-1"]},
+				"resources": ["reports"],
+				"actions": ["read", "export"],
+				"conditions": [
+					{"attribute": "context.ipAddress", "operator": "inCidr", "value": ["10.0.0.0/8"]},
+					{"attribute": "user.attributes.department", "operator": "notIn", "value": ["sales"]}
+				]
+			},
+			{
+				"resources": ["*"],
+				"actions": ["*"],
+				"conditions": [{"attribute": "resource.owner", "operator": "equals", "valueFrom": "user.id"}]
+			}
+		]
+	}`)
+	if err != nil {
+		t.Fatalf("compilePolicyRules failed: %v", err)
+	}
+
+	want := `package authz
+
+import data.heimdall.helpers
+
+# Rule 1: Analysts read reports from the office
+allow if {
+    helpers.has_any_role(["analyst"])
+    input.resource.type in ["reports"]
+    input.action in ["read","export"]
+    count(net.cidr_contains_matches(["10.0.0.0/8"], input.context.ipAddress)) > 0
+    not input.user.attributes.department in ["sales"]
+}
+
+# Rule 1: Analysts read reports from the office
+allow if {
+    input.user.id in ["u-1"]
+    input.resource.type in ["reports"]
+    input.action in ["read","export"]
+    count(net.cidr_contains_matches(["10.0.0.0/8"], input.context.ipAddress)) > 0
+    not input.user.attributes.department in ["sales"]
+}
+
+# Rule 2
+allow if {
+    input.resource.owner == input.user.id
+}
+`
+	if content != want {
+		t.Errorf("Unexpected Rego:\n%s\nwant:\n%s", content, want)
+	}
+}
+
+func TestCompilePolicyRulesErrors(t *testing.T) {
+	// Values are written as literals, so they cannot inject rules
+	content, err := compilePolicyRules(`{"rules": [{"resources": ["x\"]\nallow := true\n#"], "actions": ["read"]}]}`)
+	if err != nil {
+		t.Fatalf("compilePolicyRules failed: %v", err)
+	}
+	if strings.Contains(content, "\nallow := true") {
+		t.Errorf("Expected the value to stay a string literal:\n%s", content)
+	}
+
+	for name, rules := range map[string]string{
+		"not a rule set":   `package authz`,
+		"unknown field":    `{"rules": [{"resources": ["a"], "actions": ["read"], "effect": "deny"}]}`,
+		"no rules":         `{"rules": []}`,
+		"bad package":      `{"package": "acme-corp", "rules": [{"resources": ["a"], "actions": ["read"]}]}`,
+		"no actions":       `{"rules": [{"resources": ["a"], "actions": []}]}`,
+		"bad attribute":    `{"rules": [{"resources": ["a"], "actions": ["read"], "conditions": [{"attribute": "data.secrets", "operator": "exists"}]}]}`,
+		"bad operator":     `{"rules": [{"resources": ["a"], "actions": ["read"], "conditions": [{"attribute": "user.id", "operator": "matches", "value": "x"}]}]}`,
+		"bad cidr":         `{"rules": [{"resources": ["a"], "actions": ["read"], "conditions": [{"attribute": "context.ipAddress", "operator": "inCidr", "value": ["10.0.0.0/33"]}]}]}`,
+		"value and source": `{"rules": [{"resources": ["a"], "actions": ["read"], "conditions": [{"attribute": "resource.owner", "operator": "equals", "value": "x", "valueFrom": "user.id"}]}]}`,
+	} {
+		if _, err := compilePolicyRules(rules); !isAppError(err, "INVALID_POLICY_RULES") {
+			t.Errorf("%s: expected INVALID_POLICY_RULES, got %v", name, err)
+		}
+	}
+
+	_, err = compilePolicyRules(`{"rules": [{"resources": [], "actions": ["read"]}, {"resources": ["a"], "actions": ["read"], "conditions": [{"attribute": "user.age", "operator": "greaterThan", "value": "18"}]}]}`)
+	if got, want := ruleProblems(err), "Invalid policy rules: rules[0].resources: Must list resource types, or * for any; rules[1].conditions[0]: greaterThan requires a number"; got != want {
+		t.Errorf("ruleProblems = %q, want %q", got, want)
+	}
+}
+
+func TestNamespaceJSONPolicies(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		policies := []models.Policy{
+			{TenantID: acme.ID, Path: "authz/reports", Type: models.PolicyTypeJSON, Content: `{"rules": [{"resources": ["reports"], "actions": ["read"]}]}`},
+		}
+
+		namespaced, err := namespacePolicies(ctx, db, policies)
+		if err != nil {
+			t.Fatalf("namespacePolicies failed: %v", err)
+		}
+		if namespaced[0].Package != "authz" || namespaced[0].Root() != "tenants/acme/authz" {
+			t.Errorf("Unexpected package %s at %s", namespaced[0].Package, namespaced[0].Root())
+		}
+		if !strings.HasPrefix(namespaced[0].Content, "package tenants.acme.authz\n") || !strings.Contains(namespaced[0].Content, `input.resource.type in ["reports"]`) {
+			t.Errorf("Expected the compiled rules in acme's namespace, got %q", namespaced[0].Content)
+		}
+
+		policies[0].Content = `{"rules": []}`
+		if _, err := namespacePolicies(ctx, db, policies); !isAppError(err, "INVALID_POLICY_RULES") {
+			t.Errorf("Expected INVALID_POLICY_RULES, got %v", err)
+		}
+	})
+}
@@ -3,20 +3,32 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/models"
 	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/policyfs"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PolicyService handles policy-related business logic
 type PolicyService struct {
 	db        *gorm.DB
 	opaClient *opa.Client
+	bundles   PolicyPublishListener
+	opaBinary string // opa CLI formatting policies
+}
+
+// PolicyPublishListener is notified when a policy is published
+type PolicyPublishListener interface {
+	PolicyPublished(ctx context.Context, policy *models.Policy)
 }
 
 // NewPolicyService creates a new policy service
@@ -24,9 +36,20 @@ func NewPolicyService(db *gorm.DB, opaClient *opa.Client) *PolicyService {
 	return &PolicyService{
 		db:        db,
 		opaClient: opaClient,
+		opaBinary: "opa",
 	}
 }
 
+// SetOPABinary sets the path of the opa CLI that formats policies
+func (s *PolicyService) SetOPABinary(path string) {
+	s.opaBinary = path
+}
+
+// SetBundleRebuilder rebuilds the bundles selecting a policy when it is published
+func (s *PolicyService) SetBundleRebuilder(bundles PolicyPublishListener) {
+	s.bundles = bundles
+}
+
 // CreatePolicyRequest represents a request to create a policy
 type CreatePolicyRequest struct {
 	TenantID    uuid.UUID              `json:"-"` // Set from authenticated user's context, not from request body
@@ -35,22 +58,34 @@ type CreatePolicyRequest struct {
 	Path        string                 `json:"path"`
 	Type        models.PolicyType      `json:"type" validate:"omitempty,oneof=rego json wasm"`
 	Content     string                 `json:"content" validate:"required"`
-	Tags        []string               `json:"tags"`
+	Tags        []string               `json:"tags" validate:"omitempty,dive,required,max=100"`
 	Metadata    map[string]interface{} `json:"metadata"`
 	TestCases   []models.PolicyTestCase `json:"testCases"`
 }
 
 // UpdatePolicyRequest represents a request to update a policy
 type UpdatePolicyRequest struct {
-	Name        *string                 `json:"name,omitempty"`
+	Name        *string                 `json:"name,omitempty" validate:"omitempty,min=3,max=200"`
 	Description *string                 `json:"description,omitempty"`
-	Content     *string                 `json:"content,omitempty"`
-	Status      *models.PolicyStatus    `json:"status,omitempty"`
-	Tags        []string                `json:"tags,omitempty"`
+	Content     *string                 `json:"content,omitempty" validate:"omitempty,min=1"`
+	Status      *models.PolicyStatus    `json:"status,omitempty" validate:"omitempty,oneof=draft active inactive archived"`
+	Tags        []string                `json:"tags,omitempty" validate:"omitempty,dive,required,max=100"`
 	Metadata    map[string]interface{}  `json:"metadata,omitempty"`
 	TestCases   []models.PolicyTestCase `json:"testCases,omitempty"`
 }
 
+// UpsertPolicyRequest represents the desired state of a policy identified by its path.
+// Omitted fields are cleared rather than left unchanged.
+type UpsertPolicyRequest struct {
+	Name        string                  `json:"name" validate:"required,min=3,max=200"`
+	Description string                  `json:"description"`
+	Type        models.PolicyType       `json:"type" validate:"omitempty,oneof=rego json wasm"`
+	Content     string                  `json:"content" validate:"required"`
+	Tags        []string                `json:"tags" validate:"omitempty,dive,required,max=100"`
+	Metadata    map[string]interface{}  `json:"metadata"`
+	TestCases   []models.PolicyTestCase `json:"testCases"`
+}
+
 // CreatePolicy creates a new policy
 func (s *PolicyService) CreatePolicy(ctx context.Context, userID uuid.UUID, req *CreatePolicyRequest) (*models.Policy, error) {
 	// Convert tags to JSON
@@ -111,8 +146,8 @@ func (s *PolicyService) CreatePolicy(ctx context.Context, userID uuid.UUID, req
 func (s *PolicyService) GetPolicy(ctx context.Context, policyID uuid.UUID) (*models.Policy, error) {
 	var policy models.Policy
 	if err := s.db.WithContext(ctx).First(&policy, "id = ?", policyID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("policy not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("POLICY_NOT_FOUND", "Policy not found")
 		}
 		return nil, fmt.Errorf("failed to get policy: %w", err)
 	}
@@ -120,6 +155,19 @@ func (s *PolicyService) GetPolicy(ctx context.Context, policyID uuid.UUID) (*mod
 	return &policy, nil
 }
 
+// PolicyTenant returns the tenant of a policy, including a deleted one, so
+// handlers confine tokens to their tenant's policies before acting on one
+func (s *PolicyService) PolicyTenant(ctx context.Context, policyID uuid.UUID) (uuid.UUID, error) {
+	var policy models.Policy
+	if err := s.db.WithContext(ctx).Unscoped().Select("tenant_id").First(&policy, "id = ?", policyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, apperrors.NotFound("POLICY_NOT_FOUND", "Policy not found")
+		}
+		return uuid.Nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+	return policy.TenantID, nil
+}
+
 // GetPoliciesByTenant retrieves all policies for a tenant
 func (s *PolicyService) GetPoliciesByTenant(ctx context.Context, tenantID uuid.UUID, status *models.PolicyStatus) ([]*models.Policy, error) {
 	var policies []*models.Policy
@@ -136,6 +184,29 @@ func (s *PolicyService) GetPoliciesByTenant(ctx context.Context, tenantID uuid.U
 	return policies, nil
 }
 
+// PolicyListOptions describes the sorting and filtering supported when listing policies
+var PolicyListOptions = pagination.Options{
+	SortFields: map[string]string{
+		"createdAt": "created_at",
+		"updatedAt": "updated_at",
+		"name":      "name",
+	},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// ListPolicies retrieves a page of policies for a tenant, restricted to the
+// rows of filter when the caller may not read them all
+func (s *PolicyService) ListPolicies(ctx context.Context, tenantID uuid.UUID, filter *opa.RowFilter, params *pagination.Params) ([]models.Policy, *pagination.Page, error) {
+	query := readReplica(s.db).Model(&models.Policy{}).Where("tenant_id = ?", tenantID)
+	query = applyRowFilter(query, filter, policyRowFilterColumn)
+	policies, page, err := pagination.Paginate[models.Policy](ctx, query, params, PolicyListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	return policies, page, nil
+}
+
 // UpdatePolicy updates an existing policy
 func (s *PolicyService) UpdatePolicy(ctx context.Context, policyID, userID uuid.UUID, req *UpdatePolicyRequest) (*models.Policy, error) {
 	policy, err := s.GetPolicy(ctx, policyID)
@@ -194,7 +265,134 @@ func (s *PolicyService) UpdatePolicy(ctx context.Context, policyID, userID uuid.
 	return policy, nil
 }
 
-// DeletePolicy soft deletes a policy
+// GetPolicyByPath retrieves a tenant's policy by path
+func (s *PolicyService) GetPolicyByPath(ctx context.Context, tenantID uuid.UUID, policyPath string) (*models.Policy, error) {
+	var policy models.Policy
+	if err := s.db.WithContext(ctx).First(&policy, "tenant_id = ? AND path = ?", tenantID, policyPath).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("POLICY_NOT_FOUND", "Policy not found")
+		}
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// UpsertPolicy creates or replaces a tenant's policy at the given path. Content changes
+// create a new version and require revalidation, exactly as with UpdatePolicy. The
+// existing policy is locked while the precondition is checked, and a policy deleted
+// earlier at the same path is restored. It reports whether the policy was created.
+func (s *PolicyService) UpsertPolicy(ctx context.Context, tenantID, userID uuid.UUID, policyPath string, req *UpsertPolicyRequest, pre Precondition) (*models.Policy, bool, error) {
+	policyPath, err := policyfs.NormalizePath(policyPath)
+	if err != nil {
+		return nil, false, apperrors.Validation("INVALID_POLICY_PATH", "Invalid policy path").WithCause(err)
+	}
+
+	policyType := req.Type
+	if policyType == "" {
+		policyType = models.PolicyTypeRego
+	}
+
+	var policy *models.Policy
+	created := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txService := s.withDB(tx)
+
+		var existing models.Policy
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("path = ?", policyPath).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get policy: %w", err)
+		}
+		found := err == nil
+		if found && existing.TenantID != tenantID {
+			return apperrors.Conflict("POLICY_PATH_TAKEN", "Policy path is used by another tenant").
+				WithDetails(map[string]interface{}{"path": policyPath})
+		}
+		created = !found || existing.DeletedAt.Valid
+
+		current := ""
+		if !created {
+			current = ResourceETag(existing.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+		if !created && existing.IsSystem {
+			return apperrors.Forbidden("SYSTEM_POLICY_IMMUTABLE", "Cannot modify system policy")
+		}
+
+		if !found {
+			policy, err = txService.CreatePolicy(ctx, userID, &CreatePolicyRequest{
+				TenantID:    tenantID,
+				Name:        req.Name,
+				Description: req.Description,
+				Path:        policyPath,
+				Type:        policyType,
+				Content:     req.Content,
+				Tags:        req.Tags,
+				Metadata:    req.Metadata,
+				TestCases:   req.TestCases,
+			})
+			return err
+		}
+
+		if existing.DeletedAt.Valid {
+			if err := tx.Unscoped().Model(&existing).Update("deleted_at", nil).Error; err != nil {
+				return fmt.Errorf("failed to restore policy: %w", err)
+			}
+		}
+
+		// Replace rather than merge the optional collections
+		tags, metadata, testCases := req.Tags, req.Metadata, req.TestCases
+		if tags == nil {
+			tags = []string{}
+		}
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		if testCases == nil {
+			testCases = []models.PolicyTestCase{}
+		}
+		policy, err = txService.UpdatePolicy(ctx, existing.ID, userID, &UpdatePolicyRequest{
+			Name:        &req.Name,
+			Description: &req.Description,
+			Content:     &req.Content,
+			Tags:        tags,
+			Metadata:    metadata,
+			TestCases:   testCases,
+		})
+		if err != nil {
+			return err
+		}
+		if policy.Type != policyType {
+			if err := tx.Model(policy).Update("type", policyType).Error; err != nil {
+				return fmt.Errorf("failed to update policy type: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Reload so the ETag reflects the stored timestamp
+	policy, err = s.GetPolicy(ctx, policy.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	return policy, created, nil
+}
+
+// withDB returns a copy of the service that runs its queries on db, e.g. a transaction
+func (s *PolicyService) withDB(db *gorm.DB) *PolicyService {
+	txService := *s
+	txService.db = db
+	return &txService
+}
+
+// DeletePolicy soft deletes a policy. Deleted policies can be restored until
+// they are purged, and policies included in a ready or active bundle cannot be
+// deleted while the bundle could still be served.
 func (s *PolicyService) DeletePolicy(ctx context.Context, policyID uuid.UUID) error {
 	policy, err := s.GetPolicy(ctx, policyID)
 	if err != nil {
@@ -202,16 +400,139 @@ func (s *PolicyService) DeletePolicy(ctx context.Context, policyID uuid.UUID) er
 	}
 
 	if policy.IsSystem {
-		return fmt.Errorf("cannot delete system policy")
+		return apperrors.Forbidden("SYSTEM_POLICY_IMMUTABLE", "Cannot delete system policy")
 	}
 
-	if err := s.db.WithContext(ctx).Delete(policy).Error; err != nil {
-		return fmt.Errorf("failed to delete policy: %w", err)
-	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkPolicyNotBundled(tx, policy.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(policy).Error; err != nil {
+			return fmt.Errorf("failed to delete policy: %w", err)
+		}
+		return nil
+	})
+}
+
+// PolicyBundleReference is a bundle including a policy
+type PolicyBundleReference struct {
+	ID      uuid.UUID           `json:"id"`
+	Name    string              `json:"name"`
+	Version string              `json:"version"`
+	Status  models.BundleStatus `json:"status"`
+}
 
+// checkPolicyNotBundled fails with POLICY_IN_BUNDLE, listing the bundles, when
+// a ready or active bundle includes the policy
+func checkPolicyNotBundled(tx *gorm.DB, policyID uuid.UUID) error {
+	var bundles []PolicyBundleReference
+	err := tx.Model(&models.PolicyBundle{}).
+		Select("policy_bundles.id", "policy_bundles.name", "policy_bundles.version", "policy_bundles.status").
+		Joins("JOIN bundle_policies ON bundle_policies.bundle_id = policy_bundles.id").
+		Where("bundle_policies.policy_id = ? AND policy_bundles.status IN ?", policyID,
+			[]models.BundleStatus{models.BundleStatusReady, models.BundleStatusActive}).
+		Order("policy_bundles.created_at").
+		Scan(&bundles).Error
+	if err != nil {
+		return fmt.Errorf("failed to check bundles: %w", err)
+	}
+	if len(bundles) > 0 {
+		return apperrors.Conflict("POLICY_IN_BUNDLE", "Policy is included in a ready or active bundle; delete or deactivate the bundle first").
+			WithDetails(map[string]interface{}{"bundles": bundles})
+	}
 	return nil
 }
 
+// DeletedPolicyListOptions describes the sorting and filtering supported when
+// listing deleted policies
+var DeletedPolicyListOptions = pagination.Options{
+	SortFields: map[string]string{
+		"deletedAt": "deleted_at",
+		"createdAt": "created_at",
+		"name":      "name",
+	},
+	DefaultSort:  "-deletedAt",
+	StatusColumn: "status",
+}
+
+// ListDeletedPolicies retrieves a page of a tenant's deleted policies that have
+// not been purged yet
+func (s *PolicyService) ListDeletedPolicies(ctx context.Context, tenantID uuid.UUID, params *pagination.Params) ([]models.Policy, *pagination.Page, error) {
+	query := readReplica(s.db).Unscoped().Model(&models.Policy{}).
+		Where("tenant_id = ? AND deleted_at IS NOT NULL", tenantID)
+	policies, page, err := pagination.Paginate[models.Policy](ctx, query, params, DeletedPolicyListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list deleted policies: %w", err)
+	}
+	return policies, page, nil
+}
+
+// getDeletedPolicy retrieves a soft deleted policy, failing with
+// POLICY_NOT_DELETED for one that is not deleted
+func getDeletedPolicy(tx *gorm.DB, policyID uuid.UUID) (*models.Policy, error) {
+	var policy models.Policy
+	err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&policy, "id = ?", policyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperrors.NotFound("POLICY_NOT_FOUND", "Policy not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+	if !policy.DeletedAt.Valid {
+		return nil, apperrors.Conflict("POLICY_NOT_DELETED", "Policy is not deleted")
+	}
+	return &policy, nil
+}
+
+// RestorePolicy restores a deleted policy that has not been purged, with the
+// status and content it was deleted with
+func (s *PolicyService) RestorePolicy(ctx context.Context, policyID, userID uuid.UUID) (*models.Policy, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		policy, err := getDeletedPolicy(tx, policyID)
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(policy).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_by": userID,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to restore policy: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPolicy(ctx, policyID)
+}
+
+// PurgePolicy permanently deletes a deleted policy with its versions, and
+// removes it from the bundles that included it. Ready and active bundles
+// including it must be removed first.
+func (s *PolicyService) PurgePolicy(ctx context.Context, policyID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		policy, err := getDeletedPolicy(tx, policyID)
+		if err != nil {
+			return err
+		}
+		if err := checkPolicyNotBundled(tx, policy.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.BundlePolicy{}).Error; err != nil {
+			return fmt.Errorf("failed to remove policy from bundles: %w", err)
+		}
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.PolicyVersion{}).Error; err != nil {
+			return fmt.Errorf("failed to delete policy versions: %w", err)
+		}
+		if err := tx.Unscoped().Delete(policy).Error; err != nil {
+			return fmt.Errorf("failed to purge policy: %w", err)
+		}
+		return nil
+	})
+}
+
 // ValidatePolicy validates a policy's Rego syntax
 func (s *PolicyService) ValidatePolicy(ctx context.Context, policyID uuid.UUID) error {
 	policy, err := s.GetPolicy(ctx, policyID)
@@ -229,8 +550,8 @@ func (s *PolicyService) ValidatePolicy(ctx context.Context, policyID uuid.UUID)
 		return nil
 	}
 
-	// Only validate Rego policies
-	if policy.Type != models.PolicyTypeRego {
+	// Only validate Rego policies, and the Rego compiled from JSON policies
+	if policy.Type != models.PolicyTypeRego && policy.Type != models.PolicyTypeJSON {
 		policy.ValidationError = ""
 		policy.IsValid = true
 		now := time.Now()
@@ -242,11 +563,18 @@ func (s *PolicyService) ValidatePolicy(ctx context.Context, policyID uuid.UUID)
 	}
 
 	// Validate Rego syntax by uploading to OPA
-	// Use a temporary path for validation
+	// Use a temporary path and package for validation, so the draft cannot shadow live rules
 	tempPath := fmt.Sprintf("temp/validation/%s", policyID.String())
 
 	// Try to upload the policy to OPA - this will validate syntax
-	if err := s.opaClient.UpsertPolicy(ctx, tempPath, policy.Content); err != nil {
+	compiled, err := regoPolicy(policy)
+	if err != nil {
+		policy.ValidationError = ruleProblems(err)
+		policy.IsValid = false
+	} else if _, err := modulePackage(compiled); err != nil {
+		policy.ValidationError = "Rego syntax error: policy must declare a dotted package name, e.g. package acme.authz"
+		policy.IsValid = false
+	} else if err := s.opaClient.UpsertPolicy(ctx, tempPath, tempModule(tempPath, compiled.Content)); err != nil {
 		policy.ValidationError = fmt.Sprintf("Rego syntax error: %v", err)
 		policy.IsValid = false
 	} else {
@@ -274,7 +602,15 @@ func (s *PolicyService) PublishPolicy(ctx context.Context, policyID, userID uuid
 	}
 
 	if !policy.IsValid {
-		return nil, fmt.Errorf("cannot publish invalid policy")
+		return nil, apperrors.Validation("POLICY_INVALID", "Cannot publish invalid policy")
+	}
+
+	graph, err := loadPolicyGraph(ctx, s.db, policy.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPolicyImports(graph, policy); err != nil {
+		return nil, err
 	}
 
 	policy.Status = models.PolicyStatusActive
@@ -287,34 +623,65 @@ func (s *PolicyService) PublishPolicy(ctx context.Context, policyID, userID uuid
 		return nil, fmt.Errorf("failed to publish policy: %w", err)
 	}
 
+	if s.bundles != nil {
+		s.bundles.PolicyPublished(ctx, policy)
+	}
+
 	return policy, nil
 }
 
-// ArchivePolicy archives a policy
-func (s *PolicyService) ArchivePolicy(ctx context.Context, policyID, userID uuid.UUID) (*models.Policy, error) {
+// ArchivePolicy archives a policy. Archiving a policy that other policies of
+// the tenant import is allowed, and reported with a POLICY_HAS_DEPENDENTS
+// warning listing them.
+func (s *PolicyService) ArchivePolicy(ctx context.Context, policyID, userID uuid.UUID) (*models.Policy, []PolicyWarning, error) {
 	policy, err := s.GetPolicy(ctx, policyID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if policy.IsSystem {
-		return nil, fmt.Errorf("cannot archive system policy")
+		return nil, nil, apperrors.Forbidden("SYSTEM_POLICY_IMMUTABLE", "Cannot archive system policy")
 	}
 
 	policy.Status = models.PolicyStatusArchived
 	policy.UpdatedBy = userID
 
 	if err := s.db.WithContext(ctx).Save(policy).Error; err != nil {
-		return nil, fmt.Errorf("failed to archive policy: %w", err)
+		return nil, nil, fmt.Errorf("failed to archive policy: %w", err)
 	}
 
-	return policy, nil
+	warnings := []PolicyWarning{}
+	graph, err := loadPolicyGraph(ctx, s.db, policy.TenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Other policies of the package still provide it
+	for _, provider := range graph.packages[graph.nodes[policy.ID].ref.Package] {
+		if provider.ref.ID != policy.ID && provider.ref.published() {
+			return policy, warnings, nil
+		}
+	}
+	var dependents []PolicyReference
+	for _, dependent := range graph.dependents(graph.nodes[policy.ID]) {
+		if dependent.Status != models.PolicyStatusArchived {
+			dependents = append(dependents, dependent)
+		}
+	}
+	if len(dependents) > 0 {
+		warnings = append(warnings, PolicyWarning{
+			Code:       "POLICY_HAS_DEPENDENTS",
+			Message:    "Other policies import this policy's package, which no published policy provides any more",
+			Dependents: dependents,
+		})
+	}
+
+	return policy, warnings, nil
 }
 
 // GetPolicyVersions retrieves all versions of a policy
 func (s *PolicyService) GetPolicyVersions(ctx context.Context, policyID uuid.UUID) ([]*models.PolicyVersion, error) {
 	var versions []*models.PolicyVersion
-	if err := s.db.WithContext(ctx).
+	if err := readReplica(s.db).WithContext(ctx).
 		Where("policy_id = ?", policyID).
 		Order("version DESC").
 		Find(&versions).Error; err != nil {
@@ -331,8 +698,8 @@ func (s *PolicyService) RollbackToVersion(ctx context.Context, policyID uuid.UUI
 	if err := s.db.WithContext(ctx).
 		Where("policy_id = ? AND version = ?", policyID, version).
 		First(&targetVersion).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("version not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("POLICY_VERSION_NOT_FOUND", "Policy version not found")
 		}
 		return nil, fmt.Errorf("failed to get policy version: %w", err)
 	}
@@ -397,14 +764,22 @@ func (s *PolicyService) TestPolicy(ctx context.Context, policyID uuid.UUID) ([]P
 		return []PolicyTestResult{}, nil
 	}
 
-	// Only test Rego policies
-	if policy.Type != models.PolicyTypeRego {
-		return nil, fmt.Errorf("testing is only supported for Rego policies")
+	// Only test Rego policies, and the Rego compiled from JSON policies
+	if policy.Type != models.PolicyTypeRego && policy.Type != models.PolicyTypeJSON {
+		return nil, apperrors.Validation("POLICY_TYPE_NOT_TESTABLE", "Testing is only supported for Rego and JSON policies")
+	}
+	compiled, err := regoPolicy(policy)
+	if err != nil {
+		return nil, err
 	}
 
-	// Upload policy to OPA temporarily for testing
+	// Upload policy to OPA temporarily for testing, under a temporary package
+	pkg, err := modulePackage(compiled)
+	if err != nil {
+		return nil, err
+	}
 	tempPath := fmt.Sprintf("temp/testing/%s", policyID.String())
-	if err := s.opaClient.UpsertPolicy(ctx, tempPath, policy.Content); err != nil {
+	if err := s.opaClient.UpsertPolicy(ctx, tempPath, tempModule(tempPath, compiled.Content)); err != nil {
 		return nil, fmt.Errorf("failed to upload policy for testing: %w", err)
 	}
 
@@ -421,7 +796,7 @@ func (s *PolicyService) TestPolicy(ctx context.Context, policyID uuid.UUID) ([]P
 		}
 
 		// Evaluate the policy with the test input
-		decision, err := s.opaClient.EvaluatePolicy(ctx, tempPath, tc.Input)
+		decision, err := s.opaClient.EvaluatePolicy(ctx, tempPackagePath(tempPath, pkg), tc.Input)
 		if err != nil {
 			result.Passed = false
 			result.Message = fmt.Sprintf("Failed to evaluate policy: %v", err)
@@ -581,9 +956,10 @@ type PolicyTestResult struct {
 func (s *PolicyService) SearchPolicies(ctx context.Context, tenantID uuid.UUID, query string) ([]*models.Policy, error) {
 	var policies []*models.Policy
 	searchPattern := "%" + query + "%"
+	like := caseInsensitiveLike(s.db)
 
-	if err := s.db.WithContext(ctx).
-		Where("tenant_id = ? AND (name ILIKE ? OR description ILIKE ?)", tenantID, searchPattern, searchPattern).
+	if err := readReplica(s.db).WithContext(ctx).
+		Where("tenant_id = ? AND (name "+like+" ? OR description "+like+" ?)", tenantID, searchPattern, searchPattern).
 		Order("created_at DESC").
 		Find(&policies).Error; err != nil {
 		return nil, fmt.Errorf("failed to search policies: %w", err)
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestPolicyDeleteRestorePurge(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		policy := &models.Policy{
+			TenantID:  tenant.ID,
+			Name:      "User access",
+			Path:      "authz/users",
+			Content:   "package authz.users\n\ndefault allow := false\n",
+			Status:    models.PolicyStatusActive,
+			CreatedBy: author.ID,
+		}
+		if err := db.Create(policy).Error; err != nil {
+			t.Fatalf("Failed to create policy: %v", err)
+		}
+		bundle := &models.PolicyBundle{TenantID: tenant.ID, Name: "release", Version: "1.0.0", Status: models.BundleStatusActive, CreatedBy: author.ID, UpdatedBy: author.ID}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		if err := db.Create(&models.BundlePolicy{BundleID: bundle.ID, PolicyID: policy.ID, AddedBy: author.ID}).Error; err != nil {
+			t.Fatalf("Failed to add policy to bundle: %v", err)
+		}
+		service := NewPolicyService(db, nil)
+
+		// Policies served by an active bundle cannot be deleted
+		if err := service.DeletePolicy(ctx, policy.ID); !isAppError(err, "POLICY_IN_BUNDLE") {
+			t.Fatalf("Expected POLICY_IN_BUNDLE, got %v", err)
+		}
+		if err := db.Model(bundle).Update("status", models.BundleStatusInactive).Error; err != nil {
+			t.Fatalf("Failed to deactivate bundle: %v", err)
+		}
+
+		if err := service.PurgePolicy(ctx, policy.ID); !isAppError(err, "POLICY_NOT_DELETED") {
+			t.Errorf("Expected only deleted policies to be purgeable, got %v", err)
+		}
+		if err := service.DeletePolicy(ctx, policy.ID); err != nil {
+			t.Fatalf("DeletePolicy failed: %v", err)
+		}
+		params := &pagination.Params{Page: 1, PageSize: 20, Sort: "deletedAt", Desc: true}
+		deleted, page, err := service.ListDeletedPolicies(ctx, tenant.ID, params)
+		if err != nil || len(deleted) != 1 || deleted[0].ID != policy.ID || page.Total != 1 {
+			t.Fatalf("Expected the deleted policy to be listed, got %v %+v %v", deleted, page, err)
+		}
+
+		// Restored policies keep their status and leave the deleted list
+		restored, err := service.RestorePolicy(ctx, policy.ID, author.ID)
+		if err != nil || restored.Status != models.PolicyStatusActive || restored.DeletedAt.Valid {
+			t.Fatalf("Expected the policy to be restored, got %+v %v", restored, err)
+		}
+		if _, err := service.RestorePolicy(ctx, policy.ID, author.ID); !isAppError(err, "POLICY_NOT_DELETED") {
+			t.Errorf("Expected POLICY_NOT_DELETED restoring a live policy, got %v", err)
+		}
+		if deleted, _, _ := service.ListDeletedPolicies(ctx, tenant.ID, params); len(deleted) != 0 {
+			t.Errorf("Expected no deleted policies, got %d", len(deleted))
+		}
+
+		// Purging removes the policy and its bundle references for good
+		if err := service.DeletePolicy(ctx, policy.ID); err != nil {
+			t.Fatalf("DeletePolicy failed: %v", err)
+		}
+		if err := service.PurgePolicy(ctx, policy.ID); err != nil {
+			t.Fatalf("PurgePolicy failed: %v", err)
+		}
+		var remaining int64
+		db.Unscoped().Model(&models.Policy{}).Where("id = ?", policy.ID).Count(&remaining)
+		if remaining != 0 {
+			t.Error("Expected the purged policy to be gone")
+		}
+		db.Model(&models.BundlePolicy{}).Where("policy_id = ?", policy.ID).Count(&remaining)
+		if remaining != 0 {
+			t.Error("Expected the purged policy to be removed from its bundles")
+		}
+		if _, err := service.RestorePolicy(ctx, policy.ID, author.ID); !isAppError(err, "POLICY_NOT_FOUND") {
+			t.Errorf("Expected POLICY_NOT_FOUND restoring a purged policy, got %v", err)
+		}
+	})
+}
+
+func TestPolicyDependencies(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		createPolicy := func(path, content string, status models.PolicyStatus) *models.Policy {
+			policy := &models.Policy{
+				TenantID:  tenant.ID,
+				Name:      path,
+				Path:      path,
+				Type:      models.PolicyTypeRego,
+				Content:   content,
+				Status:    status,
+				IsValid:   true,
+				CreatedBy: author.ID,
+			}
+			if err := db.Create(policy).Error; err != nil {
+				t.Fatalf("Failed to create policy: %v", err)
+			}
+			return policy
+		}
+		roles := createPolicy("authz/roles", "package authz.roles\n\nadmin := input.user.role == \"admin\"\n", models.PolicyStatusDraft)
+		users := createPolicy("authz/users", "package authz.users\n\nimport data.authz.roles.admin\nimport data.tenants\n\nallow if admin\n", models.PolicyStatusDraft)
+		service := NewPolicyService(db, nil)
+
+		// Imports resolve to the policies defining the package; other data is left unresolved
+		deps, err := service.GetPolicyDependencies(ctx, users.ID)
+		if err != nil {
+			t.Fatalf("GetPolicyDependencies failed: %v", err)
+		}
+		if len(deps.Imports) != 2 || deps.Imports[0].Package != "authz.roles" || len(deps.Imports[0].Policies) != 1 || deps.Imports[0].Policies[0].ID != roles.ID || deps.Imports[1].Package != "" {
+			t.Errorf("Unexpected imports: %+v", deps.Imports)
+		}
+		deps, err = service.GetPolicyDependencies(ctx, roles.ID)
+		if err != nil || len(deps.Dependents) != 1 || deps.Dependents[0].ID != users.ID {
+			t.Errorf("Expected authz/users to depend on authz/roles, got %+v %v", deps, err)
+		}
+
+		// Policies cannot be published before the policies they import
+		if _, err := service.PublishPolicy(ctx, users.ID, author.ID); !isAppError(err, "POLICY_DEPENDENCY_UNPUBLISHED") {
+			t.Fatalf("Expected POLICY_DEPENDENCY_UNPUBLISHED, got %v", err)
+		}
+		if _, err := service.PublishPolicy(ctx, roles.ID, author.ID); err != nil {
+			t.Fatalf("PublishPolicy failed: %v", err)
+		}
+		if _, err := service.PublishPolicy(ctx, users.ID, author.ID); err != nil {
+			t.Fatalf("Expected the policy to publish once its imports are, got %v", err)
+		}
+
+		// Archiving an imported policy succeeds with a warning
+		_, warnings, err := service.ArchivePolicy(ctx, roles.ID, author.ID)
+		if err != nil {
+			t.Fatalf("ArchivePolicy failed: %v", err)
+		}
+		if len(warnings) != 1 || warnings[0].Code != "POLICY_HAS_DEPENDENTS" || len(warnings[0].Dependents) != 1 || warnings[0].Dependents[0].ID != users.ID {
+			t.Errorf("Expected a POLICY_HAS_DEPENDENTS warning, got %+v", warnings)
+		}
+		if _, warnings, err := service.ArchivePolicy(ctx, users.ID, author.ID); err != nil || len(warnings) != 0 {
+			t.Errorf("Expected no warnings archiving a policy nothing imports, got %+v %v", warnings, err)
+		}
+	})
+}
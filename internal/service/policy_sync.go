@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/policyfs"
+	"github.com/techsavvyash/heimdall/internal/utils"
+	"gorm.io/gorm"
+)
+
+// PolicySyncAction describes what a sync does to a single policy
+type PolicySyncAction string
+
+const (
+	PolicySyncCreate    PolicySyncAction = "create"
+	PolicySyncUpdate    PolicySyncAction = "update"
+	PolicySyncDelete    PolicySyncAction = "delete"
+	PolicySyncUnchanged PolicySyncAction = "unchanged"
+)
+
+// SyncPoliciesRequest represents a request to make a tenant's policies match a set of files
+type SyncPoliciesRequest struct {
+	Files  []policyfs.File `json:"files" validate:"required,dive"`
+	Delete bool            `json:"delete"` // Delete policies whose path is not among the files
+	DryRun bool            `json:"dryRun"` // Report the changes without applying them
+}
+
+// PolicySyncChange describes the change made (or planned) for one policy
+type PolicySyncChange struct {
+	Path     string           `json:"path"`
+	Action   PolicySyncAction `json:"action"`
+	PolicyID *uuid.UUID       `json:"policyId,omitempty"`
+	Diff     string           `json:"diff,omitempty"`
+}
+
+// PolicySyncResult is the outcome of a policy sync
+type PolicySyncResult struct {
+	DryRun    bool               `json:"dryRun"`
+	Changes   []PolicySyncChange `json:"changes"`
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Deleted   int                `json:"deleted"`
+	Unchanged int                `json:"unchanged"`
+}
+
+// SyncPolicies creates, updates and (optionally) deletes a tenant's Rego policies so that
+// they match the given files, matching policies by path. Created policies start as drafts
+// and updated ones must be revalidated, exactly as when edited through the API.
+// System policies are never deleted.
+func (s *PolicyService) SyncPolicies(ctx context.Context, tenantID, userID uuid.UUID, req *SyncPoliciesRequest) (*PolicySyncResult, error) {
+	files := make(map[string]string, len(req.Files))
+	for _, file := range req.Files {
+		policyPath, err := policyfs.NormalizePath(file.Path)
+		if err != nil {
+			return nil, apperrors.Validation("INVALID_POLICY_PATH", "Invalid policy path").
+				WithDetails(map[string]interface{}{"path": file.Path})
+		}
+		if _, duplicate := files[policyPath]; duplicate {
+			return nil, apperrors.Validation("DUPLICATE_POLICY_PATH", "Policy path appears more than once").
+				WithDetails(map[string]interface{}{"path": policyPath})
+		}
+		files[policyPath] = file.Content
+	}
+
+	var existing []*models.Policy
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND type = ?", tenantID, models.PolicyTypeRego).
+		Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+	byPath := make(map[string]*models.Policy, len(existing))
+	for _, policy := range existing {
+		byPath[policy.Path] = policy
+	}
+
+	result := &PolicySyncResult{DryRun: req.DryRun, Changes: []PolicySyncChange{}}
+
+	for _, policyPath := range sortedKeys(files) {
+		content := files[policyPath]
+		policy, ok := byPath[policyPath]
+
+		switch {
+		case !ok:
+			change := PolicySyncChange{Path: policyPath, Action: PolicySyncCreate, Diff: utils.LineDiff("", content)}
+			if !req.DryRun {
+				created, err := s.createSyncedPolicy(ctx, tenantID, userID, policyPath, content)
+				if err != nil {
+					return nil, err
+				}
+				change.PolicyID = &created.ID
+			}
+			result.Changes = append(result.Changes, change)
+			result.Created++
+
+		case policy.Content != content:
+			change := PolicySyncChange{Path: policyPath, Action: PolicySyncUpdate, PolicyID: &policy.ID, Diff: utils.LineDiff(policy.Content, content)}
+			if !req.DryRun {
+				if _, err := s.UpdatePolicy(ctx, policy.ID, userID, &UpdatePolicyRequest{Content: &content}); err != nil {
+					return nil, fmt.Errorf("failed to update policy %s: %w", policyPath, err)
+				}
+			}
+			result.Changes = append(result.Changes, change)
+			result.Updated++
+
+		default:
+			result.Changes = append(result.Changes, PolicySyncChange{Path: policyPath, Action: PolicySyncUnchanged, PolicyID: &policy.ID})
+			result.Unchanged++
+		}
+	}
+
+	if req.Delete {
+		for _, policyPath := range sortedKeys(byPath) {
+			policy := byPath[policyPath]
+			if _, keep := files[policyPath]; keep || policy.IsSystem {
+				continue
+			}
+
+			change := PolicySyncChange{Path: policyPath, Action: PolicySyncDelete, PolicyID: &policy.ID, Diff: utils.LineDiff(policy.Content, "")}
+			if !req.DryRun {
+				if err := s.DeletePolicy(ctx, policy.ID); err != nil {
+					return nil, fmt.Errorf("failed to delete policy %s: %w", policyPath, err)
+				}
+			}
+			result.Changes = append(result.Changes, change)
+			result.Deleted++
+		}
+	}
+
+	return result, nil
+}
+
+// createSyncedPolicy creates a policy for a synced file. A policy previously deleted at the
+// same path is restored instead, since paths stay reserved by soft-deleted policies.
+func (s *PolicyService) createSyncedPolicy(ctx context.Context, tenantID, userID uuid.UUID, policyPath, content string) (*models.Policy, error) {
+	var deleted models.Policy
+	err := s.db.WithContext(ctx).Unscoped().Where("path = ?", policyPath).First(&deleted).Error
+	switch {
+	case err == nil && deleted.TenantID != tenantID:
+		return nil, apperrors.Conflict("POLICY_PATH_TAKEN", "Policy path is used by another tenant").
+			WithDetails(map[string]interface{}{"path": policyPath})
+	case err == nil:
+		if err := s.db.WithContext(ctx).Unscoped().Model(&deleted).Update("deleted_at", nil).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore policy %s: %w", policyPath, err)
+		}
+		return s.UpdatePolicy(ctx, deleted.ID, userID, &UpdatePolicyRequest{Content: &content})
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to look up policy %s: %w", policyPath, err)
+	}
+
+	policy, err := s.CreatePolicy(ctx, userID, &CreatePolicyRequest{
+		TenantID: tenantID,
+		Name:     path.Base(policyPath),
+		Path:     policyPath,
+		Type:     models.PolicyTypeRego,
+		Content:  content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create policy %s: %w", policyPath, err)
+	}
+	return policy, nil
+}
+
+// ExportPolicies returns a tenant's Rego policies as files, sorted by path
+func (s *PolicyService) ExportPolicies(ctx context.Context, tenantID uuid.UUID) ([]policyfs.File, error) {
+	var policies []*models.Policy
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND type = ?", tenantID, models.PolicyTypeRego).
+		Order("path ASC").
+		Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to export policies: %w", err)
+	}
+
+	files := make([]policyfs.File, 0, len(policies))
+	for _, policy := range policies {
+		files = append(files, policyfs.File{Path: policy.Path, Content: policy.Content})
+	}
+	return files, nil
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
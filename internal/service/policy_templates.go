@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+)
+
+//go:embed policy_templates/*.rego
+var policyTemplateFiles embed.FS
+
+// Types of policy template variables
+const (
+	TemplateVariableString     = "string"
+	TemplateVariableInteger    = "integer"
+	TemplateVariableStringList = "stringList"
+)
+
+// defaultTemplatePackage is the package template policies are created in by
+// default, whose allow rule Heimdall consults in the tenant's namespace
+const defaultTemplatePackage = "authz"
+
+// PolicyTemplateVariable is a parameter substituted into a policy template
+type PolicyTemplateVariable struct {
+	Name        string      `json:"name" example:"resourceType"`
+	Description string      `json:"description" example:"Resource type the rule applies to"`
+	Type        string      `json:"type" enums:"string,integer,stringList" example:"string"`
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default,omitempty"`
+	Format      string      `json:"format,omitempty" example:"cidr"` // Format of string values, e.g. cidr
+	Minimum     *int        `json:"minimum,omitempty"`
+	Maximum     *int        `json:"maximum,omitempty"`
+}
+
+// PolicyTemplate is a parameterized Rego policy for a common authorization pattern
+type PolicyTemplate struct {
+	ID          string                   `json:"id" example:"mfa-required"`
+	Name        string                   `json:"name" example:"MFA required"`
+	Description string                   `json:"description" example:"Allow actions to users with a permission after they verified MFA"`
+	Variables   []PolicyTemplateVariable `json:"variables"`
+	Content     string                   `json:"content"` // Rego source with {{variable}} placeholders
+}
+
+// CreatePolicyFromTemplateRequest creates a draft policy by filling in a template
+type CreatePolicyFromTemplateRequest struct {
+	TenantID    uuid.UUID              `json:"-"` // Set from authenticated user's context, not from request body
+	TemplateID  string                 `json:"templateId" validate:"required" example:"mfa-required"`
+	Name        string                 `json:"name" validate:"required,min=3,max=200" example:"Reports need MFA"`
+	Description string                 `json:"description"`
+	Path        string                 `json:"path" example:"authz/reports_mfa"`
+	Package     string                 `json:"package" validate:"omitempty,max=200" example:"authz"` // Defaults to authz
+	Variables   map[string]interface{} `json:"variables"`
+	Tags        []string               `json:"tags" validate:"omitempty,dive,required,max=100"`
+}
+
+// Variables shared by the templates
+var (
+	resourceTypeVariable = PolicyTemplateVariable{Name: "resourceType", Description: "Resource type the rule applies to, e.g. reports", Type: TemplateVariableString, Required: true}
+	actionsVariable      = PolicyTemplateVariable{Name: "actions", Description: "Actions the rule allows, e.g. [\"read\"]", Type: TemplateVariableStringList, Required: true}
+	permissionVariable   = PolicyTemplateVariable{Name: "permission", Description: "Permission users need, e.g. reports.read", Type: TemplateVariableString, Required: true}
+)
+
+// policyTemplates is the starter library of policy templates
+var policyTemplates = []*PolicyTemplate{
+	{
+		ID:          "rbac-permission",
+		Name:        "RBAC by permission",
+		Description: "Allow actions on a resource type to users with a permission",
+		Variables:   []PolicyTemplateVariable{resourceTypeVariable, actionsVariable, permissionVariable},
+	},
+	{
+		ID:          "ownership",
+		Name:        "Resource ownership",
+		Description: "Allow actions on resources of a type to the users who own them",
+		Variables:   []PolicyTemplateVariable{resourceTypeVariable, actionsVariable},
+	},
+	{
+		ID:          "tenant-isolation",
+		Name:        "Tenant isolation",
+		Description: "Allow actions to users with one of the roles on resources of their own tenant only",
+		Variables: []PolicyTemplateVariable{resourceTypeVariable, actionsVariable,
+			{Name: "roles", Description: "Roles allowed to perform the actions, e.g. [\"editor\"]", Type: TemplateVariableStringList, Required: true}},
+	},
+	{
+		ID:          "business-hours",
+		Name:        "Business hours",
+		Description: "Allow actions to users with a permission on working days between two hours of Heimdall's clock",
+		Variables: []PolicyTemplateVariable{resourceTypeVariable, actionsVariable, permissionVariable,
+			{Name: "days", Description: "Days the actions are allowed on", Type: TemplateVariableStringList,
+				Default: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}},
+			{Name: "startHour", Description: "First hour the actions are allowed in", Type: TemplateVariableInteger, Default: 9, Minimum: intPtr(0), Maximum: intPtr(23)},
+			{Name: "endHour", Description: "Hour the actions are no longer allowed from", Type: TemplateVariableInteger, Default: 17, Minimum: intPtr(1), Maximum: intPtr(24)}},
+	},
+	{
+		ID:          "ip-allowlist",
+		Name:        "IP allowlist",
+		Description: "Allow actions to users with a permission from listed networks only",
+		Variables: []PolicyTemplateVariable{resourceTypeVariable, actionsVariable, permissionVariable,
+			{Name: "cidrs", Description: "Networks the actions are allowed from, e.g. [\"10.0.0.0/8\"]", Type: TemplateVariableStringList, Required: true, Format: "cidr"}},
+	},
+	{
+		ID:          "mfa-required",
+		Name:        "MFA required",
+		Description: "Allow actions to users with a permission after they verified MFA",
+		Variables:   []PolicyTemplateVariable{resourceTypeVariable, actionsVariable, permissionVariable},
+	},
+}
+
+func init() {
+	for _, template := range policyTemplates {
+		content, err := policyTemplateFiles.ReadFile(fmt.Sprintf("policy_templates/%s.rego", templateFileName(template.ID)))
+		if err != nil {
+			panic(fmt.Sprintf("missing policy template %s: %v", template.ID, err))
+		}
+		template.Content = string(content)
+	}
+}
+
+// templateFileName returns the name of a template's Rego file, e.g. mfa_required for mfa-required
+func templateFileName(id string) string {
+	return strings.ReplaceAll(id, "-", "_")
+}
+
+// templatePlaceholder matches the {{variable}} placeholders of policy templates
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9]*)\s*\}\}`)
+
+// ListPolicyTemplates returns the library of policy templates
+func (s *PolicyService) ListPolicyTemplates() []*PolicyTemplate {
+	return policyTemplates
+}
+
+// policyTemplate returns a template by ID
+func policyTemplate(id string) (*PolicyTemplate, error) {
+	for _, template := range policyTemplates {
+		if template.ID == id {
+			return template, nil
+		}
+	}
+	return nil, apperrors.NotFound("POLICY_TEMPLATE_NOT_FOUND", "Policy template not found")
+}
+
+// CreatePolicyFromTemplate creates a draft policy from a template, substituting
+// its variables. The template and variables are recorded in the policy's metadata.
+func (s *PolicyService) CreatePolicyFromTemplate(ctx context.Context, userID uuid.UUID, req *CreatePolicyFromTemplateRequest) (*models.Policy, error) {
+	template, err := policyTemplate(req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	pkg := req.Package
+	if pkg == "" {
+		pkg = defaultTemplatePackage
+	}
+	values, err := template.resolve(req.Variables)
+	if err != nil {
+		return nil, err
+	}
+	content, err := template.Render(pkg, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CreatePolicy(ctx, userID, &CreatePolicyRequest{
+		TenantID:    req.TenantID,
+		Name:        req.Name,
+		Description: req.Description,
+		Path:        req.Path,
+		Type:        models.PolicyTypeRego,
+		Content:     content,
+		Tags:        req.Tags,
+		Metadata:    map[string]interface{}{"template": template.ID, "templateVariables": values},
+	})
+}
+
+// Render fills in a template's package and variable values, which are written
+// as Rego literals so they cannot change the policy's structure
+func (t *PolicyTemplate) Render(pkg string, values map[string]interface{}) (string, error) {
+	if !dottedPackage.MatchString(pkg) {
+		return "", apperrors.Validation("INVALID_POLICY_PACKAGE", "Package must be a dotted name, e.g. acme.authz")
+	}
+
+	var missing error
+	content := templatePlaceholder.ReplaceAllStringFunc(t.Content, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+		if name == "package" {
+			return pkg
+		}
+		value, ok := values[name]
+		if !ok {
+			missing = fmt.Errorf("policy template %s has no value for %s", t.ID, name)
+			return placeholder
+		}
+		literal, err := json.Marshal(value)
+		if err != nil {
+			missing = fmt.Errorf("failed to encode %s: %w", name, err)
+			return placeholder
+		}
+		return string(literal)
+	})
+	if missing != nil {
+		return "", missing
+	}
+	return content, nil
+}
+
+// resolve validates variable values against a template, filling in defaults
+func (t *PolicyTemplate) resolve(variables map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(t.Variables))
+	problems := make(map[string]interface{})
+	known := make(map[string]bool, len(t.Variables))
+
+	for _, variable := range t.Variables {
+		known[variable.Name] = true
+		raw, ok := variables[variable.Name]
+		if !ok || raw == nil {
+			if variable.Required {
+				problems[variable.Name] = "This variable is required"
+			} else {
+				values[variable.Name] = variable.Default
+			}
+			continue
+		}
+		value, problem := variable.parse(raw)
+		if problem != "" {
+			problems[variable.Name] = problem
+			continue
+		}
+		values[variable.Name] = value
+	}
+	for _, name := range sortedKeys(variables) {
+		if !known[name] {
+			problems[name] = "Unknown variable"
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, apperrors.Validation("INVALID_TEMPLATE_VARIABLES", "Invalid template variables").WithDetails(problems)
+	}
+	return values, nil
+}
+
+// parse checks a variable value, returning a description of the problem when invalid
+func (v *PolicyTemplateVariable) parse(raw interface{}) (interface{}, string) {
+	switch v.Type {
+	case TemplateVariableInteger:
+		number, ok := raw.(float64)
+		if !ok || number != math.Trunc(number) {
+			return nil, "Must be an integer"
+		}
+		value := int(number)
+		if (v.Minimum != nil && value < *v.Minimum) || (v.Maximum != nil && value > *v.Maximum) {
+			return nil, fmt.Sprintf("Must be between %d and %d", *v.Minimum, *v.Maximum)
+		}
+		return value, ""
+	case TemplateVariableStringList:
+		items, ok := raw.([]interface{})
+		if !ok || len(items) == 0 {
+			return nil, "Must be a non-empty list of strings"
+		}
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			value, problem := v.parseString(item)
+			if problem != "" {
+				return nil, problem
+			}
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		return values, ""
+	default:
+		return v.parseString(raw)
+	}
+}
+
+// parseString checks a string value or list item
+func (v *PolicyTemplateVariable) parseString(raw interface{}) (string, string) {
+	value, ok := raw.(string)
+	if !ok || value == "" {
+		return "", "Must be a non-empty string"
+	}
+	if v.Format == "cidr" {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return "", fmt.Sprintf("%q is not a CIDR range", value)
+		}
+	}
+	return value, ""
+}
+
+func intPtr(v int) *int {
+	return &v
+}
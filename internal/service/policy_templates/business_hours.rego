@@ -0,0 +1,13 @@
+package {{package}}
+
+import data.heimdall.helpers
+
+# Users with the permission may perform the actions on the listed days between
+# the start hour (inclusive) and the end hour (exclusive)
+allow if {
+    input.resource.type == {{resourceType}}
+    input.action in {{actions}}
+    helpers.has_permission({{permission}})
+    input.time.dayOfWeek in {{days}}
+    helpers.in_time_window({{startHour}}, {{endHour}})
+}
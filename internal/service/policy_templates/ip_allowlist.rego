@@ -0,0 +1,12 @@
+package {{package}}
+
+import data.heimdall.helpers
+
+# Users with the permission may perform the actions from the listed networks only
+allow if {
+    input.resource.type == {{resourceType}}
+    input.action in {{actions}}
+    helpers.has_permission({{permission}})
+    some cidr in {{cidrs}}
+    net.cidr_contains(cidr, input.context.ipAddress)
+}
@@ -0,0 +1,11 @@
+package {{package}}
+
+import data.heimdall.helpers
+
+# Users with the permission may perform the actions after verifying MFA only
+allow if {
+    input.resource.type == {{resourceType}}
+    input.action in {{actions}}
+    helpers.has_permission({{permission}})
+    helpers.is_mfa_verified
+}
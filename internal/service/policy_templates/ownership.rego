@@ -0,0 +1,10 @@
+package {{package}}
+
+import data.heimdall.helpers
+
+# Owners may perform the actions on their own resources of the resource type
+allow if {
+    input.resource.type == {{resourceType}}
+    input.action in {{actions}}
+    helpers.is_owner
+}
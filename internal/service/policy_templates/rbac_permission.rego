@@ -0,0 +1,10 @@
+package {{package}}
+
+import data.heimdall.helpers
+
+# Users with the permission may perform the actions on the resource type
+allow if {
+    input.resource.type == {{resourceType}}
+    input.action in {{actions}}
+    helpers.has_permission({{permission}})
+}
@@ -0,0 +1,13 @@
+package {{package}}
+
+import data.heimdall.helpers
+
+# Users with one of the roles may perform the actions on resources of their own
+# tenant only
+allow if {
+    input.resource.type == {{resourceType}}
+    input.action in {{actions}}
+    helpers.has_any_role({{roles}})
+    helpers.same_tenant
+    helpers.in_tenant
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// templateVariables decodes variables as they arrive in request bodies
+func templateVariables(t *testing.T, raw string) map[string]interface{} {
+	var variables map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &variables); err != nil {
+		t.Fatalf("Invalid variables %s: %v", raw, err)
+	}
+	return variables
+}
+
+func TestPolicyTemplatesRender(t *testing.T) {
+	samples := map[string]string{
+		"rbac-permission":  `{"resourceType":"reports","actions":["read"],"permission":"reports.read"}`,
+		"ownership":        `{"resourceType":"documents","actions":["update","delete"]}`,
+		"tenant-isolation": `{"resourceType":"projects","actions":["read"],"roles":["member"]}`,
+		"business-hours":   `{"resourceType":"payroll","actions":["update"],"permission":"payroll.update","startHour":8}`,
+		"ip-allowlist":     `{"resourceType":"admin","actions":["read"],"permission":"admin.read","cidrs":["10.0.0.0/8"]}`,
+		"mfa-required":     `{"resourceType":"secrets","actions":["read"],"permission":"secrets.read"}`,
+	}
+	if len(samples) != len(policyTemplates) {
+		t.Fatalf("Expected a sample for each of the %d templates", len(policyTemplates))
+	}
+
+	for _, template := range policyTemplates {
+		values, err := template.resolve(templateVariables(t, samples[template.ID]))
+		if err != nil {
+			t.Fatalf("Template %s rejected its sample: %v", template.ID, err)
+		}
+		content, err := template.Render("acme.authz", values)
+		if err != nil {
+			t.Fatalf("Template %s failed to render: %v", template.ID, err)
+		}
+		if strings.Contains(content, "{{") || !strings.HasPrefix(content, "package acme.authz\n") {
+			t.Errorf("Template %s rendered incompletely:\n%s", template.ID, content)
+		}
+	}
+
+	business, _ := policyTemplate("business-hours")
+	values, _ := business.resolve(templateVariables(t, samples["business-hours"]))
+	content, _ := business.Render("authz", values)
+	for _, want := range []string{`input.action in ["update"]`, `helpers.has_permission("payroll.update")`, `helpers.in_time_window(8, 17)`, `"Friday"`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in\n%s", want, content)
+		}
+	}
+}
+
+func TestPolicyTemplateVariables(t *testing.T) {
+	template, err := policyTemplate("ip-allowlist")
+	if err != nil {
+		t.Fatalf("policyTemplate failed: %v", err)
+	}
+
+	// Values are written as literals, so they cannot inject rules
+	values, err := template.resolve(templateVariables(t, `{"resourceType":"x\"\nallow := true\n#","actions":["read"],"permission":"p","cidrs":["10.0.0.0/8"]}`))
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	content, _ := template.Render("authz", values)
+	if strings.Contains(content, "\nallow := true") {
+		t.Errorf("Expected the value to stay a string literal:\n%s", content)
+	}
+
+	_, err = template.resolve(templateVariables(t, `{"resourceType":"","actions":"read","cidrs":["10.0.0.0/33"],"extra":1}`))
+	var appErr *apperrors.Error
+	if !isAppError(err, "INVALID_TEMPLATE_VARIABLES") || !errors.As(err, &appErr) {
+		t.Fatalf("Expected INVALID_TEMPLATE_VARIABLES, got %v", err)
+	}
+	problems, _ := appErr.Details.(map[string]interface{})
+	for _, name := range []string{"resourceType", "actions", "permission", "cidrs", "extra"} {
+		if _, ok := problems[name]; !ok {
+			t.Errorf("Expected a problem with %s, got %v", name, appErr.Details)
+		}
+	}
+
+	business, _ := policyTemplate("business-hours")
+	if _, err := business.resolve(templateVariables(t, `{"resourceType":"r","actions":["read"],"permission":"p","endHour":25}`)); !isAppError(err, "INVALID_TEMPLATE_VARIABLES") {
+		t.Errorf("Expected INVALID_TEMPLATE_VARIABLES for an hour out of range, got %v", err)
+	}
+	if _, err := template.Render("acme-corp", values); !isAppError(err, "INVALID_POLICY_PACKAGE") {
+		t.Errorf("Expected INVALID_POLICY_PACKAGE, got %v", err)
+	}
+	if _, err := policyTemplate("unknown"); !isAppError(err, "POLICY_TEMPLATE_NOT_FOUND") {
+		t.Errorf("Expected POLICY_TEMPLATE_NOT_FOUND, got %v", err)
+	}
+}
+
+func TestCreatePolicyFromTemplate(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		author := testutil.CreateTestUser(t, db, tenant, "author@acme.com")
+		policies := NewPolicyService(db, nil)
+
+		policy, err := policies.CreatePolicyFromTemplate(ctx, author.ID, &CreatePolicyFromTemplateRequest{
+			TenantID:   tenant.ID,
+			TemplateID: "mfa-required",
+			Name:       "Secrets need MFA",
+			Path:       "authz/secrets",
+			Variables:  templateVariables(t, `{"resourceType":"secrets","actions":["read"],"permission":"secrets.read"}`),
+		})
+		if err != nil {
+			t.Fatalf("CreatePolicyFromTemplate failed: %v", err)
+		}
+		if !strings.HasPrefix(policy.Content, "package authz\n") || !strings.Contains(policy.Content, `helpers.has_permission("secrets.read")`) {
+			t.Errorf("Unexpected policy content:\n%s", policy.Content)
+		}
+
+		var metadata struct {
+			Template          string                 `json:"template"`
+			TemplateVariables map[string]interface{} `json:"templateVariables"`
+		}
+		if err := json.Unmarshal(policy.Metadata, &metadata); err != nil {
+			t.Fatalf("Invalid metadata: %v", err)
+		}
+		if metadata.Template != "mfa-required" || metadata.TemplateVariables["permission"] != "secrets.read" {
+			t.Errorf("Unexpected metadata %s", policy.Metadata)
+		}
+	})
+}
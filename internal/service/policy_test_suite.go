@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+)
+
+// Exit codes of policy test suites, as a CI step running them would exit with
+const (
+	TestSuitePassed  = 0 // Every test passed
+	TestSuiteFailed  = 1 // A test failed
+	TestSuiteInvalid = 2 // A policy could not be compiled, so its tests did not run
+)
+
+// PolicyTestSuiteRequest is a set of policies with their test cases, run
+// together without saving them
+type PolicyTestSuiteRequest struct {
+	Policies []PolicyTestSuitePolicy `json:"policies" validate:"required,min=1,max=100,dive"`
+}
+
+// PolicyTestSuitePolicy is a policy of a test suite and the test cases run
+// against its package
+type PolicyTestSuitePolicy struct {
+	Path      string                  `json:"path" validate:"required,max=500"`
+	Type      models.PolicyType       `json:"type" validate:"omitempty,oneof=rego json"`
+	Content   string                  `json:"content" validate:"required"`
+	TestCases []models.PolicyTestCase `json:"testCases" validate:"max=1000"`
+}
+
+// PolicyTestSuiteResult is the outcome of a test suite
+type PolicyTestSuiteResult struct {
+	Passed   bool                          `json:"passed"`
+	ExitCode int                           `json:"exitCode"` // 0 when every test passed, 1 when a test failed, 2 when a policy did not compile
+	Total    int                           `json:"total"`
+	Failed   int                           `json:"failed"`
+	Policies []PolicyTestSuitePolicyResult `json:"policies"`
+}
+
+// PolicyTestSuitePolicyResult is the outcome of the tests of one policy of a suite
+type PolicyTestSuitePolicyResult struct {
+	Path    string             `json:"path"`
+	Package string             `json:"package,omitempty"`
+	Error   string             `json:"error,omitempty"` // Why the policy did not compile
+	Results []PolicyTestResult `json:"results"`
+}
+
+// RunTestSuite loads the policies of a suite into OPA together, under a
+// temporary package so they can import each other without shadowing live
+// rules, and runs the test cases of each against its package. Policies that
+// do not compile are reported with their error and the suite's exit code 2.
+func (s *PolicyService) RunTestSuite(ctx context.Context, req *PolicyTestSuiteRequest) (*PolicyTestSuiteResult, error) {
+	tempPath := fmt.Sprintf("temp/testsuite/%s", uuid.New().String())
+	suite := &PolicyTestSuiteResult{Policies: make([]PolicyTestSuitePolicyResult, len(req.Policies))}
+
+	var modules []string
+	var loaded []int // Indexes of the policies of the modules
+	for i, p := range req.Policies {
+		result := &suite.Policies[i]
+		result.Path = p.Path
+		result.Results = []PolicyTestResult{}
+
+		policyType := p.Type
+		if policyType == "" {
+			policyType = models.PolicyTypeRego
+		}
+		compiled, err := regoPolicy(&models.Policy{Path: p.Path, Type: policyType, Content: p.Content})
+		if err != nil {
+			result.Error = ruleProblems(err)
+			continue
+		}
+		if result.Package, err = modulePackage(compiled); err != nil {
+			result.Error = "Policy must declare a dotted package name, e.g. package acme.authz"
+			continue
+		}
+		modules = append(modules, compiled.Content)
+		loaded = append(loaded, i)
+	}
+
+	// Clean up after testing, even when the request timed out
+	var uploaded []string
+	defer func() {
+		for _, id := range uploaded {
+			_ = s.opaClient.DeletePolicy(context.WithoutCancel(ctx), id)
+		}
+	}()
+	for j, module := range sandboxModules(modules, tempPackage(tempPath)) {
+		id := fmt.Sprintf("%s/%d", tempPath, loaded[j])
+		if err := s.opaClient.UpsertPolicy(ctx, id, module); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			suite.Policies[loaded[j]].Error = err.Error()
+			continue
+		}
+		uploaded = append(uploaded, id)
+	}
+
+	for i, p := range req.Policies {
+		result := &suite.Policies[i]
+		if result.Error != "" {
+			suite.ExitCode = TestSuiteInvalid
+			continue
+		}
+		for _, tc := range p.TestCases {
+			testResult := PolicyTestResult{TestName: tc.Name}
+			decision, err := s.opaClient.EvaluatePolicy(ctx, tempPackagePath(tempPath, result.Package), tc.Input)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				testResult.Message = fmt.Sprintf("Failed to evaluate policy: %v", err)
+			} else {
+				testResult.Passed, testResult.Message = compareResults(decision.Result, tc.Expected)
+				if tc.Note != "" && testResult.Passed {
+					testResult.Message = tc.Note
+				}
+			}
+
+			suite.Total++
+			if !testResult.Passed {
+				suite.Failed++
+				if suite.ExitCode == TestSuitePassed {
+					suite.ExitCode = TestSuiteFailed
+				}
+			}
+			result.Results = append(result.Results, testResult)
+		}
+	}
+	suite.Passed = suite.ExitCode == TestSuitePassed
+	return suite, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+)
+
+func TestRunTestSuite(t *testing.T) {
+	var mu sync.Mutex
+	modules := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := strings.TrimPrefix(r.URL.Path, "/v1/policies/")
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			modules[id] = string(body)
+		case http.MethodDelete:
+			delete(modules, id)
+		case http.MethodPost:
+			// Suite policies allow admins
+			var req opa.DecisionRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			role, _ := req.Input["role"].(string)
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"allow": role == "admin"}})
+		}
+	}))
+	defer server.Close()
+	service := NewPolicyService(nil, opa.NewClient(&config.OPAConfig{URL: server.URL, Timeout: time.Second}))
+	ctx := context.Background()
+
+	req := &PolicyTestSuiteRequest{Policies: []PolicyTestSuitePolicy{
+		{Path: "authz/roles", Content: "package authz.roles\n\nadmin if input.role == \"admin\"\n"},
+		{
+			Path:    "authz/users",
+			Content: "package authz.users\n\nimport data.authz.roles\n\ndefault allow := false\n\nallow if roles.admin\n",
+			TestCases: []models.PolicyTestCase{
+				{Name: "admin", Input: map[string]interface{}{"role": "admin"}, Expected: map[string]interface{}{"allow": true}},
+				{Name: "viewer", Input: map[string]interface{}{"role": "viewer"}, Expected: map[string]interface{}{"allow": false}},
+			},
+		},
+	}}
+	result, err := service.RunTestSuite(ctx, req)
+	if err != nil {
+		t.Fatalf("RunTestSuite failed: %v", err)
+	}
+	if !result.Passed || result.ExitCode != TestSuitePassed || result.Total != 2 || result.Failed != 0 {
+		t.Errorf("Expected the suite to pass, got %+v", result)
+	}
+	if users := result.Policies[1]; users.Package != "authz.users" || len(users.Results) != 2 {
+		t.Errorf("Unexpected results of authz/users: %+v", users)
+	}
+	if len(modules) != 0 {
+		t.Errorf("Expected the suite's modules to be removed from OPA, got %v", modules)
+	}
+
+	// Failing tests and policies without a package fail the suite
+	req.Policies[1].TestCases[1].Expected["allow"] = true
+	result, err = service.RunTestSuite(ctx, req)
+	if err != nil || result.Passed || result.ExitCode != TestSuiteFailed || result.Failed != 1 {
+		t.Errorf("Expected a failed test to fail the suite, got %+v %v", result, err)
+	}
+	req.Policies = append(req.Policies, PolicyTestSuitePolicy{Path: "broken", Content: "allow := true\n"})
+	result, err = service.RunTestSuite(ctx, req)
+	if err != nil || result.ExitCode != TestSuiteInvalid || result.Policies[2].Error == "" {
+		t.Errorf("Expected a policy without a package to make the suite invalid, got %+v %v", result, err)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+)
+
+// Precondition holds the optimistic concurrency checks of an upsert, taken from
+// the If-Match and If-None-Match request headers
+type Precondition struct {
+	IfMatch     string // ETags the existing resource must have, or "*" for any existing resource
+	IfNoneMatch string // "*" to only create the resource
+}
+
+// ResourceETag returns the entity tag of a resource last updated at updatedAt.
+// Timestamps are stored with microsecond precision, so the tag is stable across reads.
+func ResourceETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`"%x"`, updatedAt.UnixMicro())
+}
+
+// Check verifies the precondition against a resource's current ETag, which is
+// empty when the resource does not exist yet
+func (p Precondition) Check(current string) error {
+	if current == "" {
+		if p.IfMatch != "" {
+			return apperrors.PreconditionFailed("RESOURCE_NOT_FOUND", "Resource does not exist")
+		}
+		return nil
+	}
+
+	if strings.TrimSpace(p.IfNoneMatch) == "*" || etagListContains(p.IfNoneMatch, current) {
+		return apperrors.PreconditionFailed("RESOURCE_EXISTS", "Resource already exists")
+	}
+	if p.IfMatch != "" && strings.TrimSpace(p.IfMatch) != "*" && !etagListContains(p.IfMatch, current) {
+		return apperrors.PreconditionFailed("ETAG_MISMATCH", "Resource was modified since it was read").
+			WithDetails(map[string]interface{}{"etag": current})
+	}
+	return nil
+}
+
+// etagListContains reports whether a comma-separated header value lists the given ETag.
+// Weak validators compare equal to their strong counterparts.
+func etagListContains(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
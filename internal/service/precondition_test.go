@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+)
+
+func TestResourceETag(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.UTC)
+
+	// Stored timestamps lose the nanoseconds, which must not change the tag
+	if ResourceETag(updatedAt) != ResourceETag(updatedAt.Truncate(time.Microsecond)) {
+		t.Error("Expected ETag to ignore sub-microsecond precision")
+	}
+	if ResourceETag(updatedAt) == ResourceETag(updatedAt.Add(time.Microsecond)) {
+		t.Error("Expected ETag to change when the resource is updated")
+	}
+}
+
+func TestPreconditionCheck(t *testing.T) {
+	current := `"5f0a"`
+
+	tests := []struct {
+		name    string
+		pre     Precondition
+		current string
+		code    string // Expected error code, empty for success
+	}{
+		{"unconditional create", Precondition{}, "", ""},
+		{"unconditional replace", Precondition{}, current, ""},
+		{"create only", Precondition{IfNoneMatch: "*"}, "", ""},
+		{"create only but exists", Precondition{IfNoneMatch: "*"}, current, "RESOURCE_EXISTS"},
+		{"matching etag", Precondition{IfMatch: current}, current, ""},
+		{"weak matching etag", Precondition{IfMatch: "W/" + current}, current, ""},
+		{"etag in list", Precondition{IfMatch: `"1", ` + current}, current, ""},
+		{"any existing", Precondition{IfMatch: "*"}, current, ""},
+		{"stale etag", Precondition{IfMatch: `"1"`}, current, "ETAG_MISMATCH"},
+		{"update but missing", Precondition{IfMatch: current}, "", "RESOURCE_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pre.Check(tt.current)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) || !errors.Is(err, apperrors.ErrPrecondition) {
+				t.Fatalf("Expected precondition error, got %v", err)
+			}
+			if appErr.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, appErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultRateLimitRoute is the route class of routes without their own limit
+const defaultRateLimitRoute = "default"
+
+// RateLimitService manages tenant request quotas and resolves them for the
+// rate limiting middleware
+type RateLimitService struct {
+	db  *gorm.DB
+	cfg *config.ServerConfig
+
+	mu       sync.RWMutex
+	byTenant map[string]cachedRateLimits
+	cacheTTL time.Duration
+}
+
+// cachedRateLimits is a tenant's resolved quotas (or a negative lookup) held in memory
+type cachedRateLimits struct {
+	limits    map[string]int
+	expiresAt time.Time
+}
+
+// NewRateLimitService creates a new rate limit service
+func NewRateLimitService(db *gorm.DB, cfg *config.ServerConfig) *RateLimitService {
+	return &RateLimitService{
+		db:       db,
+		cfg:      cfg,
+		byTenant: make(map[string]cachedRateLimits),
+		cacheTTL: time.Minute,
+	}
+}
+
+// UpsertRateLimitsRequest represents the desired quotas of a tenant
+type UpsertRateLimitsRequest struct {
+	Limits map[string]int `json:"limits" validate:"required,min=1,dive,min=1,max=1000000" example:"{\"authz\":5000,\"default\":500}"` // Requests per minute by route class
+}
+
+// RateLimitsResponse represents a tenant's quotas
+type RateLimitsResponse struct {
+	TenantID  string         `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Limits    map[string]int `json:"limits" example:"{\"authz\":5000,\"default\":500}"`
+	Defaults  map[string]int `json:"defaults" example:"{\"auth\":10,\"authz\":1000,\"default\":100}"` // Per-IP limits of the route classes
+	CreatedAt string         `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt string         `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+	ETag      string         `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// GetLimits retrieves a tenant's quotas
+func (s *RateLimitService) GetLimits(ctx context.Context, tenantID uuid.UUID) (*RateLimitsResponse, error) {
+	limits, err := s.findLimits(readReplica(s.db).WithContext(ctx), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if limits == nil {
+		return nil, apperrors.NotFound("RATE_LIMITS_NOT_FOUND", "Rate limits not found")
+	}
+	return s.toRateLimitsResponse(limits)
+}
+
+// UpsertLimits creates or replaces a tenant's quotas. It reports whether they
+// were created.
+func (s *RateLimitService) UpsertLimits(ctx context.Context, tenantID uuid.UUID, req *UpsertRateLimitsRequest, pre Precondition) (*RateLimitsResponse, bool, error) {
+	defaults := s.defaults()
+	var unknown []string
+	for route := range req.Limits {
+		if _, ok := defaults[route]; !ok {
+			unknown = append(unknown, route)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, false, apperrors.Validation("INVALID_RATE_LIMIT_ROUTE", "Unknown route class").
+			WithDetails(map[string]interface{}{"routes": unknown})
+	}
+
+	encoded, err := json.Marshal(req.Limits)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal rate limits: %w", err)
+	}
+
+	var response *RateLimitsResponse
+	created := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Select("id").First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		var limits models.TenantRateLimits
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&limits, "tenant_id = ?", tenantID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get rate limits: %w", err)
+		}
+		created = err != nil
+
+		current := ""
+		if !created {
+			current = ResourceETag(limits.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+
+		limits.TenantID = tenantID
+		limits.Limits = encoded
+		if created {
+			err = tx.Create(&limits).Error
+		} else {
+			err = tx.Save(&limits).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save rate limits: %w", err)
+		}
+		response, err = s.toRateLimitsResponse(&limits)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.invalidate(tenantID.String())
+	return response, created, nil
+}
+
+// DeleteLimits removes a tenant's quotas, leaving only the per-IP route limits
+func (s *RateLimitService) DeleteLimits(ctx context.Context, tenantID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&models.TenantRateLimits{}, "tenant_id = ?", tenantID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete rate limits: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound("RATE_LIMITS_NOT_FOUND", "Rate limits not found")
+	}
+
+	s.invalidate(tenantID.String())
+	return nil
+}
+
+// TenantRateLimit returns a tenant's quota of requests per minute for a route
+// class, or 0 when the tenant has none. Quotas are cached for a minute on each
+// instance; changes made through this instance apply immediately.
+func (s *RateLimitService) TenantRateLimit(ctx context.Context, tenantID, route string) (int, error) {
+	s.mu.RLock()
+	cached, ok := s.byTenant[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.limits[route], nil
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return 0, nil
+	}
+
+	entry := cachedRateLimits{expiresAt: time.Now().Add(s.cacheTTL)}
+	limits, err := s.findLimits(readReplica(s.db).WithContext(ctx), tenantUUID)
+	if err != nil {
+		return 0, err
+	}
+	if limits != nil && len(limits.Limits) > 0 {
+		if err := json.Unmarshal(limits.Limits, &entry.limits); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal rate limits: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.byTenant[tenantID] = entry
+	s.mu.Unlock()
+
+	return entry.limits[route], nil
+}
+
+// SetServerConfig replaces the server configuration the per-IP limits are
+// read from, as on configuration reloads
+func (s *RateLimitService) SetServerConfig(cfg *config.ServerConfig) {
+	s.mu.Lock()
+	s.cfg = cfg
+	s.mu.Unlock()
+}
+
+// defaults returns the per-IP limits of the route classes
+func (s *RateLimitService) defaults() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	defaults := map[string]int{defaultRateLimitRoute: s.cfg.RateLimitPerMin}
+	for route, limit := range s.cfg.RouteRateLimits {
+		defaults[route] = limit
+	}
+	return defaults
+}
+
+func (s *RateLimitService) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.byTenant, tenantID)
+	s.mu.Unlock()
+}
+
+func (s *RateLimitService) findLimits(db *gorm.DB, tenantID uuid.UUID) (*models.TenantRateLimits, error) {
+	var limits models.TenantRateLimits
+	if err := db.First(&limits, "tenant_id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rate limits: %w", err)
+	}
+	return &limits, nil
+}
+
+func (s *RateLimitService) toRateLimitsResponse(limits *models.TenantRateLimits) (*RateLimitsResponse, error) {
+	response := &RateLimitsResponse{
+		TenantID:  limits.TenantID.String(),
+		Limits:    map[string]int{},
+		Defaults:  s.defaults(),
+		CreatedAt: limits.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: limits.UpdatedAt.Format(time.RFC3339),
+		ETag:      ResourceETag(limits.UpdatedAt),
+	}
+	if len(limits.Limits) > 0 {
+		if err := json.Unmarshal(limits.Limits, &response.Limits); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rate limits: %w", err)
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestRateLimitService_Limits(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		service := NewRateLimitService(db, &config.ServerConfig{
+			RateLimitPerMin: 100,
+			RouteRateLimits: map[string]int{"auth": 10, "authz": 1000},
+		})
+
+		if _, err := service.GetLimits(ctx, tenant.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found without quotas, got %v", err)
+		}
+		if limit, err := service.TenantRateLimit(ctx, tenant.ID.String(), "authz"); err != nil || limit != 0 {
+			t.Fatalf("Expected no quota, got %d, %v", limit, err)
+		}
+
+		var appErr *apperrors.Error
+		_, _, err := service.UpsertLimits(ctx, tenant.ID, &UpsertRateLimitsRequest{Limits: map[string]int{"authz": 5000, "billing": 10}}, Precondition{})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_RATE_LIMIT_ROUTE" {
+			t.Fatalf("Expected INVALID_RATE_LIMIT_ROUTE for an unknown route class, got %v", err)
+		}
+		_, _, err = service.UpsertLimits(ctx, uuid.New(), &UpsertRateLimitsRequest{Limits: map[string]int{"authz": 5000}}, Precondition{})
+		if !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found for an unknown tenant, got %v", err)
+		}
+
+		limits, created, err := service.UpsertLimits(ctx, tenant.ID, &UpsertRateLimitsRequest{Limits: map[string]int{"authz": 5000, "default": 500}}, Precondition{})
+		if err != nil || !created {
+			t.Fatalf("Failed to create quotas: %v", err)
+		}
+		if limits.Limits["authz"] != 5000 || limits.Defaults["default"] != 100 || limits.Defaults["auth"] != 10 {
+			t.Errorf("Unexpected quotas %+v", limits)
+		}
+
+		// Lookups reflect changes made through the service immediately
+		if limit, err := service.TenantRateLimit(ctx, tenant.ID.String(), "authz"); err != nil || limit != 5000 {
+			t.Fatalf("Expected authz quota 5000, got %d, %v", limit, err)
+		}
+		if limit, _ := service.TenantRateLimit(ctx, tenant.ID.String(), "auth"); limit != 0 {
+			t.Errorf("Expected no auth quota, got %d", limit)
+		}
+
+		_, _, err = service.UpsertLimits(ctx, tenant.ID, &UpsertRateLimitsRequest{Limits: map[string]int{"authz": 100}}, Precondition{IfNoneMatch: "*"})
+		if !errors.Is(err, apperrors.ErrPrecondition) {
+			t.Fatalf("Expected precondition failure creating existing quotas, got %v", err)
+		}
+		limits, created, err = service.UpsertLimits(ctx, tenant.ID, &UpsertRateLimitsRequest{Limits: map[string]int{"authz": 2000}}, Precondition{IfMatch: limits.ETag})
+		if err != nil || created {
+			t.Fatalf("Failed to replace quotas: %v", err)
+		}
+		if limit, _ := service.TenantRateLimit(ctx, tenant.ID.String(), "default"); limit != 0 {
+			t.Errorf("Expected replaced quotas to drop the default quota, got %d", limit)
+		}
+
+		if err := service.DeleteLimits(ctx, tenant.ID); err != nil {
+			t.Fatalf("Failed to delete quotas: %v", err)
+		}
+		if limit, _ := service.TenantRateLimit(ctx, tenant.ID.String(), "authz"); limit != 0 {
+			t.Errorf("Expected no quota after deletion, got %d", limit)
+		}
+		if err := service.DeleteLimits(ctx, tenant.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found deleting twice, got %v", err)
+		}
+	})
+}
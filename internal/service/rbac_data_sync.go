@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"gorm.io/gorm"
+)
+
+// rbacDataPath is the OPA data path of the tenant documents, i.e. policies read a
+// tenant's roles from data.heimdall.tenants[tenantId]
+const rbacDataPath = "heimdall/tenants"
+
+// RBACRoleData is a role in a tenant's OPA data document
+type RBACRoleData struct {
+	Parent      string   `json:"parent,omitempty"`
+	Permissions []string `json:"permissions"` // Including those inherited from parent roles
+}
+
+// RBACUserData is a user in a tenant's OPA data document
+type RBACUserData struct {
+	Roles []string `json:"roles"`
+}
+
+// RBACData is the OPA data document of a tenant's roles and its active users'
+// role assignments
+type RBACData struct {
+	Slug  string                  `json:"slug"` // Names the tenant's policy namespace, data.tenants[slug]
+	Roles map[string]RBACRoleData `json:"roles"`
+	Users map[string]RBACUserData `json:"users"`
+}
+
+// RBACDataSync pushes each tenant's role→permission mappings and user→role
+// assignments to OPA, so policies look them up instead of relying on the
+// permissions embedded in the input or token. Services push a tenant's document
+// after changing its roles, and Run periodically pushes every tenant's document
+// to repair failed pushes and drop expired assignments.
+type RBACDataSync struct {
+	db        *gorm.DB
+	opaClient *opa.Client
+}
+
+// NewRBACDataSync creates a new RBAC data sync
+func NewRBACDataSync(db *gorm.DB, opaClient *opa.Client) *RBACDataSync {
+	return &RBACDataSync{
+		db:        db,
+		opaClient: opaClient,
+	}
+}
+
+// Run pushes every tenant's document right away and then every interval until
+// ctx is cancelled
+func (s *RBACDataSync) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncAll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to sync RBAC data to OPA: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll pushes the document of every tenant and removes the documents of
+// deleted tenants
+func (s *RBACDataSync) SyncAll(ctx context.Context) error {
+	var tenantIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Pluck("id", &tenantIDs).Error; err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	var failed int
+	exists := make(map[string]bool, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		exists[tenantID.String()] = true
+		if err := s.SyncTenant(ctx, tenantID); err != nil {
+			log.Printf("Failed to sync RBAC data of tenant %s to OPA: %v", tenantID, err)
+			failed++
+		}
+	}
+
+	pushed, err := s.opaClient.GetData(ctx, rbacDataPath)
+	if err != nil {
+		return fmt.Errorf("failed to get RBAC data: %w", err)
+	}
+	documents, _ := pushed.(map[string]interface{})
+	for tenantID := range documents {
+		if exists[tenantID] {
+			continue
+		}
+		if err := s.opaClient.DeleteData(ctx, rbacDataPath+"/"+tenantID); err != nil {
+			log.Printf("Failed to remove RBAC data of deleted tenant %s from OPA: %v", tenantID, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tenants failed to sync", failed, len(tenantIDs))
+	}
+	return nil
+}
+
+// SyncTenant pushes a tenant's document
+func (s *RBACDataSync) SyncTenant(ctx context.Context, tenantID uuid.UUID) error {
+	data, err := s.TenantData(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := s.opaClient.PutData(ctx, rbacDataPath+"/"+tenantID.String(), data); err != nil {
+		return fmt.Errorf("failed to push RBAC data: %w", err)
+	}
+	return nil
+}
+
+// TenantChanged pushes the documents of tenants whose roles or assignments just
+// changed. Failures are logged and left to Run. It does nothing on a nil sync, so
+// services work without OPA data sync.
+func (s *RBACDataSync) TenantChanged(ctx context.Context, tenantIDs ...uuid.UUID) {
+	if s == nil {
+		return
+	}
+	for _, tenantID := range tenantIDs {
+		if err := s.SyncTenant(ctx, tenantID); err != nil {
+			log.Printf("Failed to sync RBAC data of tenant %s to OPA: %v", tenantID, err)
+		}
+	}
+}
+
+// TenantData builds a tenant's document from the database
+func (s *RBACDataSync) TenantData(ctx context.Context, tenantID uuid.UUID) (*RBACData, error) {
+	db := s.db.WithContext(ctx)
+
+	var tenant models.Tenant
+	if err := db.Unscoped().Select("id", "slug").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	var roles []models.Role
+	if err := db.Where("tenant_id = ?", tenantID).Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	var grants []struct {
+		RoleID uuid.UUID
+		Name   string
+	}
+	if err := db.Model(&models.RolePermission{}).
+		Select("role_permissions.role_id, permissions.name").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id AND permissions.deleted_at IS NULL").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.tenant_id = ?", tenantID).
+		Scan(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	var assignments []struct {
+		UserID uuid.UUID
+		RoleID uuid.UUID
+	}
+	if err := db.Model(&models.UserRole{}).
+		Select("user_roles.user_id, user_roles.role_id").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Where("users.tenant_id = ? AND users.status = ?", tenantID, models.UserStatusActive).
+		Where(activeRoleAssignment, time.Now()).
+		Scan(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get role assignments: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*models.Role, len(roles))
+	for i := range roles {
+		byID[roles[i].ID] = &roles[i]
+	}
+	granted := make(map[uuid.UUID][]string)
+	for _, grant := range grants {
+		granted[grant.RoleID] = append(granted[grant.RoleID], grant.Name)
+	}
+
+	data := &RBACData{
+		Slug:  tenant.Slug,
+		Roles: make(map[string]RBACRoleData, len(roles)),
+		Users: make(map[string]RBACUserData),
+	}
+	for _, role := range roles {
+		roleData := RBACRoleData{Permissions: inheritedPermissions(role.ID, byID, granted)}
+		if role.ParentRoleID != nil {
+			if parent, ok := byID[*role.ParentRoleID]; ok {
+				roleData.Parent = parent.Name
+			}
+		}
+		data.Roles[role.Name] = roleData
+	}
+
+	for _, assignment := range assignments {
+		role, ok := byID[assignment.RoleID]
+		if !ok {
+			continue
+		}
+		userID := assignment.UserID.String()
+		user := data.Users[userID]
+		user.Roles = append(user.Roles, role.Name)
+		data.Users[userID] = user
+	}
+	for userID, user := range data.Users {
+		sort.Strings(user.Roles)
+		data.Users[userID] = user
+	}
+
+	return data, nil
+}
+
+// inheritedPermissions returns the sorted permissions of a role and its ancestors
+func inheritedPermissions(roleID uuid.UUID, roles map[uuid.UUID]*models.Role, granted map[uuid.UUID][]string) []string {
+	seen := make(map[string]bool)
+	visited := make(map[uuid.UUID]bool)
+	permissions := []string{}
+
+	for id := &roleID; id != nil && !visited[*id]; {
+		visited[*id] = true
+		for _, name := range granted[*id] {
+			if !seen[name] {
+				seen[name] = true
+				permissions = append(permissions, name)
+			}
+		}
+
+		role, ok := roles[*id]
+		if !ok {
+			break
+		}
+		id = role.ParentRoleID
+	}
+
+	sort.Strings(permissions)
+	return permissions
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// fakeOPAData stores the documents written to OPA's data API
+type fakeOPAData struct {
+	mu        sync.Mutex
+	documents map[string]json.RawMessage
+}
+
+func newFakeOPAData(t *testing.T) (*fakeOPAData, *opa.Client) {
+	fake := &fakeOPAData{documents: map[string]json.RawMessage{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		path := strings.TrimPrefix(r.URL.Path, "/v1/data/")
+		switch r.Method {
+		case http.MethodPut:
+			var document json.RawMessage
+			if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fake.documents[path] = document
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			delete(fake.documents, path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			result := map[string]json.RawMessage{}
+			for documentPath, document := range fake.documents {
+				if key, ok := strings.CutPrefix(documentPath, path+"/"); ok {
+					result[key] = document
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return fake, opa.NewClient(&config.OPAConfig{URL: server.URL})
+}
+
+func (f *fakeOPAData) tenant(t *testing.T, tenantID uuid.UUID) *RBACData {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	document, ok := f.documents[rbacDataPath+"/"+tenantID.String()]
+	if !ok {
+		return nil
+	}
+	var data RBACData
+	if err := json.Unmarshal(document, &data); err != nil {
+		t.Fatalf("Failed to decode RBAC data: %v", err)
+	}
+	return &data
+}
+
+func TestRBACDataSync_TenantData(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		viewer := testutil.CreateTestRole(t, db, tenant, "viewer")
+		editor := testutil.CreateTestRole(t, db, tenant, "editor")
+		if err := db.Model(editor).Update("parent_role_id", viewer.ID).Error; err != nil {
+			t.Fatalf("Failed to set parent role: %v", err)
+		}
+		testutil.AssignPermissionToRole(t, db, viewer, testutil.CreateTestPermission(t, db, "documents.read", "documents", "read"))
+		testutil.AssignPermissionToRole(t, db, editor, testutil.CreateTestPermission(t, db, "documents.update", "documents", "update"))
+
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		testutil.AssignRoleToUser(t, db, alice, editor)
+		testutil.CreateTestUser(t, db, tenant, "bob@acme.com")
+
+		_, opaClient := newFakeOPAData(t)
+		data, err := NewRBACDataSync(db, opaClient).TenantData(ctx, tenant.ID)
+		if err != nil {
+			t.Fatalf("Failed to build RBAC data: %v", err)
+		}
+
+		want := &RBACData{
+			Slug: "acme",
+			Roles: map[string]RBACRoleData{
+				"viewer": {Permissions: []string{"documents.read"}},
+				"editor": {Parent: "viewer", Permissions: []string{"documents.read", "documents.update"}},
+			},
+			Users: map[string]RBACUserData{
+				alice.ID.String(): {Roles: []string{"editor"}},
+			},
+		}
+		if !reflect.DeepEqual(data, want) {
+			t.Errorf("Unexpected RBAC data:\n got %+v\nwant %+v", data, want)
+		}
+	})
+}
+
+func TestRBACDataSync_PushesChanges(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		testutil.CreateTestPermission(t, db, "invoices.read", "invoices", "read")
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+
+		fake, opaClient := newFakeOPAData(t)
+		rbacSync := NewRBACDataSync(db, opaClient)
+		roleService := NewRoleService(db)
+		roleService.SetRBACDataSync(rbacSync)
+		userService := NewUserService(db, nil)
+		userService.SetRBACDataSync(rbacSync)
+
+		role, _, err := roleService.UpsertRole(ctx, tenant.ID, "billing", &UpsertRoleRequest{Permissions: []string{"invoices.read"}}, Precondition{}, alice.ID)
+		if err != nil {
+			t.Fatalf("Failed to upsert role: %v", err)
+		}
+		data := fake.tenant(t, tenant.ID)
+		if data == nil || !reflect.DeepEqual(data.Roles["billing"].Permissions, []string{"invoices.read"}) {
+			t.Fatalf("Expected the new role to be pushed, got %+v", data)
+		}
+
+		if err := userService.AssignRoleToUser(ctx, alice.ID.String(), role.ID, alice.ID.String(), nil); err != nil {
+			t.Fatalf("Failed to assign role: %v", err)
+		}
+		if roles := fake.tenant(t, tenant.ID).Users[alice.ID.String()].Roles; !reflect.DeepEqual(roles, []string{"billing"}) {
+			t.Errorf("Expected the assignment to be pushed, got %v", roles)
+		}
+
+		if err := roleService.DeletePermission(ctx, "invoices.read"); err != nil {
+			t.Fatalf("Failed to delete permission: %v", err)
+		}
+		if permissions := fake.tenant(t, tenant.ID).Roles["billing"].Permissions; len(permissions) != 0 {
+			t.Errorf("Expected the deleted permission to be removed, got %v", permissions)
+		}
+
+		if err := userService.RemoveRoleFromUser(ctx, alice.ID.String(), role.ID); err != nil {
+			t.Fatalf("Failed to remove role: %v", err)
+		}
+		if _, ok := fake.tenant(t, tenant.ID).Users[alice.ID.String()]; ok {
+			t.Error("Expected the removed assignment to be pushed")
+		}
+	})
+}
+
+func TestRBACDataSync_SyncAllRemovesDeletedTenants(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		fake, opaClient := newFakeOPAData(t)
+		deletedTenantID := uuid.New()
+		fake.documents[rbacDataPath+"/"+deletedTenantID.String()] = json.RawMessage(`{"roles":{},"users":{}}`)
+
+		if err := NewRBACDataSync(db, opaClient).SyncAll(ctx); err != nil {
+			t.Fatalf("SyncAll returned error: %v", err)
+		}
+		if fake.tenant(t, tenant.ID) == nil {
+			t.Error("Expected the tenant's document to be pushed")
+		}
+		if fake.tenant(t, deletedTenantID) != nil {
+			t.Error("Expected the deleted tenant's document to be removed")
+		}
+	})
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// readReplica returns a handle that routes queries to a read replica when replicas
+// are configured. Replicas lag behind the primary, so it is only used for read-only
+// requests whose results are never written back.
+func readReplica(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Read).Session(&gorm.Session{})
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type replicaItem struct {
+	ID   int
+	Name string
+}
+
+func TestReadReplica(t *testing.T) {
+	// Two SQLite databases stand in for a primary and its replica, holding
+	// different rows so each query shows where it ran
+	open := func(name string) (*gorm.DB, gorm.Dialector) {
+		path := filepath.Join(t.TempDir(), name+".db")
+		dialector, err := database.Dialector(database.DriverSQLite, path)
+		if err != nil {
+			t.Fatalf("Failed to create dialector: %v", err)
+		}
+		db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", name, err)
+		}
+		if err := db.AutoMigrate(&replicaItem{}); err != nil {
+			t.Fatalf("Failed to migrate %s: %v", name, err)
+		}
+		if err := db.Create(&replicaItem{ID: 1, Name: name}).Error; err != nil {
+			t.Fatalf("Failed to seed %s: %v", name, err)
+		}
+		// The resolver opens its own connections to the replica
+		replica, _ := database.Dialector(database.DriverSQLite, path)
+		return db, replica
+	}
+	primary, _ := open("primary")
+	replicaDB, replica := open("replica")
+
+	db, err := database.UseReplicas(primary, []gorm.Dialector{replica}, &config.DatabaseConfig{MaxConns: 2, MaxIdle: 1})
+	if err != nil {
+		t.Fatalf("UseReplicas returned error: %v", err)
+	}
+	name := func(query *gorm.DB, id int) string {
+		t.Helper()
+		var item replicaItem
+		if err := query.First(&item, id).Error; err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		return item.Name
+	}
+
+	if got := name(readReplica(db), 1); got != "replica" {
+		t.Errorf("Expected read-only lookups on the replica, got %s", got)
+	}
+	if got := name(db, 1); got != "primary" {
+		t.Errorf("Expected other queries on the primary, got %s", got)
+	}
+
+	// Writes go to the primary, even through a read replica handle
+	if err := readReplica(db).Create(&replicaItem{ID: 2, Name: "written"}).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	var count int64
+	replicaDB.Model(&replicaItem{}).Count(&count)
+	if got := name(db, 2); got != "written" || count != 1 {
+		t.Errorf("Expected the write on the primary only, got %s and %d replica rows", got, count)
+	}
+
+	// Transactions read their own writes on the primary
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&replicaItem{}).Where("id = ?", 1).Update("name", "updated").Error; err != nil {
+			return err
+		}
+		if got := name(readReplica(tx), 1); got != "updated" {
+			t.Errorf("Expected reads in a transaction on the primary, got %s", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/encryption"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// reencryptionBatchSize caps the rows of a column read at once
+const reencryptionBatchSize = 500
+
+// Reencryptor rewrites the encrypted columns of all models with the primary
+// key of the keyring: values encrypted with earlier keys after a key rotation,
+// and values written before encryption was enabled. Once a run rewrote no
+// values under an earlier key, that key can be removed from the keyring.
+type Reencryptor struct {
+	db      *gorm.DB
+	keyring *encryption.Keyring
+}
+
+// NewReencryptor creates a new re-encryption job
+func NewReencryptor(db *gorm.DB, keyring *encryption.Keyring) *Reencryptor {
+	return &Reencryptor{db: db, keyring: keyring}
+}
+
+// Run re-encrypts values every interval until ctx is cancelled
+func (r *Reencryptor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		rewritten, err := r.Reencrypt(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to re-encrypt columns: %v", err)
+		}
+		if rewritten > 0 {
+			log.Printf("Re-encrypted %d values with key %s", rewritten, r.keyring.PrimaryKeyID())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// encryptedColumn is a column of a model field encrypted at rest
+type encryptedColumn struct {
+	table      string
+	primaryKey string
+	field      *schema.Field
+}
+
+// encryptedRow is the stored value of an encrypted column
+type encryptedRow struct {
+	ID    string
+	Value []byte
+}
+
+// Reencrypt rewrites the values of encrypted columns that are not encrypted
+// with the primary key and returns the number rewritten. Values that cannot be
+// decrypted are skipped and reported in the error.
+func (r *Reencryptor) Reencrypt(ctx context.Context) (int64, error) {
+	columns, err := r.encryptedColumns()
+	if err != nil {
+		return 0, err
+	}
+
+	var rewritten int64
+	var failures []error
+	for _, column := range columns {
+		n, err := r.reencryptColumn(ctx, column)
+		rewritten += n
+		if err != nil {
+			if ctx.Err() != nil {
+				return rewritten, err
+			}
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return rewritten, fmt.Errorf("%d columns were not fully re-encrypted, first: %w", len(failures), failures[0])
+	}
+	return rewritten, nil
+}
+
+// encryptedColumns returns the columns of the model fields using the
+// encrypted serializer
+func (r *Reencryptor) encryptedColumns() ([]encryptedColumn, error) {
+	var columns []encryptedColumn
+	for _, model := range models.AllModels() {
+		stmt := &gorm.Statement{DB: r.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.TagSettings["SERIALIZER"] != encryption.SerializerName {
+				continue
+			}
+			columns = append(columns, encryptedColumn{
+				table:      stmt.Schema.Table,
+				primaryKey: stmt.Schema.PrioritizedPrimaryField.DBName,
+				field:      field,
+			})
+		}
+	}
+	return columns, nil
+}
+
+// reencryptColumn rewrites the values of one column in batches. Rows are read
+// without scopes, so soft deleted rows are re-encrypted too.
+func (r *Reencryptor) reencryptColumn(ctx context.Context, column encryptedColumn) (int64, error) {
+	name := encryption.Column(column.field)
+	isJSON := encryption.IsJSON(column.field)
+	dbName := column.field.DBName
+
+	var rewritten, failed int64
+	var firstErr error
+	lastID := ""
+	for {
+		query := r.db.WithContext(ctx).Table(column.table).
+			Select(column.primaryKey + " AS id, " + dbName + " AS value").
+			Where(dbName + " IS NOT NULL")
+		if lastID != "" {
+			query = query.Where(column.primaryKey+" > ?", lastID)
+		}
+		var rows []encryptedRow
+		if err := query.Order(column.primaryKey).Limit(reencryptionBatchSize).Scan(&rows).Error; err != nil {
+			return rewritten, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		for _, row := range rows {
+			if len(row.Value) == 0 {
+				continue
+			}
+			plaintext, keyID, err := r.keyring.Open(name, row.Value, isJSON)
+			if err != nil {
+				failed++
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s of %s: %w", name, row.ID, err)
+				}
+				continue
+			}
+			if keyID == r.keyring.PrimaryKeyID() {
+				continue
+			}
+
+			sealed, err := r.keyring.Seal(name, plaintext, isJSON)
+			if err != nil {
+				return rewritten, err
+			}
+			// Values changed since they were read are left to the next run
+			result := r.db.WithContext(ctx).Table(column.table).
+				Where(column.primaryKey+" = ? AND "+dbName+" = ?", row.ID, string(row.Value)).
+				Update(dbName, sealed)
+			if result.Error != nil {
+				return rewritten, fmt.Errorf("failed to re-encrypt %s of %s: %w", name, row.ID, result.Error)
+			}
+			rewritten += result.RowsAffected
+		}
+
+		if len(rows) < reencryptionBatchSize {
+			break
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+
+	if failed > 0 {
+		return rewritten, fmt.Errorf("failed to decrypt %d values, first: %w", failed, firstErr)
+	}
+	return rewritten, nil
+}
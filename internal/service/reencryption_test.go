@@ -0,0 +1,169 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/encryption"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func newTestKeyring(t *testing.T, ids ...string) *encryption.Keyring {
+	t.Helper()
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		key := make([]byte, 32)
+		// Derive the key from its ID, so keyrings sharing an ID share the key
+		copy(key, id)
+		keys = append(keys, id+":"+base64.StdEncoding.EncodeToString(key))
+	}
+	keyring, err := encryption.NewKeyring(keys)
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	return keyring
+}
+
+// storedValue reads a column as stored, without decrypting it
+func storedValue(t *testing.T, db *gorm.DB, table, column, id string) string {
+	t.Helper()
+	var value string
+	if err := db.Table(table).Select(column).Where("id = ?", id).Scan(&value).Error; err != nil {
+		t.Fatalf("Failed to read %s.%s: %v", table, column, err)
+	}
+	return value
+}
+
+func TestReencryptor(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+		t.Cleanup(func() { encryption.SetKeyring(nil) })
+
+		// Rows written before encryption was enabled
+		encryption.SetKeyring(nil)
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "ada@example.com")
+		if stored := storedValue(t, db, "users", "metadata", user.ID.String()); !strings.Contains(stored, "Test") {
+			t.Fatalf("Expected unencrypted metadata, got %s", stored)
+		}
+
+		first := newTestKeyring(t, "k1")
+		encryption.SetKeyring(first)
+
+		// Unencrypted rows stay readable until they are re-encrypted
+		var loaded models.User
+		if err := db.First(&loaded, "id = ?", user.ID).Error; err != nil || !strings.Contains(string(loaded.Metadata), `"firstName":"Test"`) {
+			t.Fatalf("Expected unencrypted metadata to be read, got %s, %v", loaded.Metadata, err)
+		}
+
+		rewritten, err := NewReencryptor(db, first).Reencrypt(ctx)
+		if err != nil || rewritten != 2 {
+			t.Fatalf("Expected the user's metadata and the tenant's settings to be encrypted, got %d, %v", rewritten, err)
+		}
+		for _, stored := range []string{
+			storedValue(t, db, "users", "metadata", user.ID.String()),
+			storedValue(t, db, "tenants", "settings", tenant.ID.String()),
+		} {
+			if !strings.HasPrefix(stored, `"enc:v1:k1:`) {
+				t.Errorf("Expected a value encrypted with k1, got %s", stored)
+			}
+		}
+		if err := db.First(&loaded, "id = ?", user.ID).Error; err != nil || !strings.Contains(string(loaded.Metadata), `"firstName":"Test"`) {
+			t.Fatalf("Expected encrypted metadata to be decrypted, got %s, %v", loaded.Metadata, err)
+		}
+
+		// New values are encrypted as they are written
+		client, err := NewOAuthClientService(db, nil).CreateClient(ctx, tenant.ID, &CreateOAuthClientRequest{Name: "Export job"})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		var stored models.OAuthClient
+		if err := db.First(&stored, "client_id = ?", client.ClientID).Error; err != nil {
+			t.Fatalf("Failed to load client: %v", err)
+		}
+		if raw := storedValue(t, db, "oauth_clients", "secret_hash", stored.ID.String()); !strings.HasPrefix(raw, "enc:v1:k1:") {
+			t.Errorf("Expected an encrypted secret hash, got %s", raw)
+		}
+		if stored.SecretHash != hashSecret(client.ClientSecret) {
+			t.Error("Expected the secret hash to be decrypted")
+		}
+
+		// After a rotation, values of the earlier key are rewritten with the new one
+		second := newTestKeyring(t, "k2", "k1")
+		encryption.SetKeyring(second)
+		if rewritten, err := NewReencryptor(db, second).Reencrypt(ctx); err != nil || rewritten != 3 {
+			t.Fatalf("Expected 3 values to be re-encrypted, got %d, %v", rewritten, err)
+		}
+		if raw := storedValue(t, db, "users", "metadata", user.ID.String()); !strings.HasPrefix(raw, `"enc:v1:k2:`) {
+			t.Errorf("Expected metadata encrypted with k2, got %s", raw)
+		}
+		if rewritten, err := NewReencryptor(db, second).Reencrypt(ctx); err != nil || rewritten != 0 {
+			t.Errorf("Expected nothing left to re-encrypt, got %d, %v", rewritten, err)
+		}
+
+		// Once re-encrypted, the earlier key is no longer needed
+		encryption.SetKeyring(newTestKeyring(t, "k2"))
+		if err := db.First(&loaded, "id = ?", user.ID).Error; err != nil || !strings.Contains(string(loaded.Metadata), `"lastName":"User"`) {
+			t.Errorf("Expected metadata to be read with k2 alone, got %s, %v", loaded.Metadata, err)
+		}
+
+		// Values of unknown keys are reported without stopping the run
+		encryption.SetKeyring(newTestKeyring(t, "k3"))
+		if _, err := NewReencryptor(db, newTestKeyring(t, "k3")).Reencrypt(ctx); err == nil || !strings.Contains(err.Error(), "unknown key k2") {
+			t.Errorf("Expected values of k2 to be reported, got %v", err)
+		}
+	})
+}
+
+func TestEncryptedUserSearch(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+		t.Cleanup(func() { encryption.SetKeyring(nil) })
+
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		keyring, err := encryption.NewKeyring([]string{"k1:" + base64.StdEncoding.EncodeToString(key)})
+		if err != nil {
+			t.Fatalf("Failed to create keyring: %v", err)
+		}
+		encryption.SetKeyring(keyring)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "ada@example.com")
+		repo := NewUserRepository(db)
+		if err := repo.UpdateMetadata(ctx, user.ID, map[string]interface{}{"firstName": "Ada", "lastName": "Lovelace"}); err != nil {
+			t.Fatalf("Failed to update metadata: %v", err)
+		}
+		if raw := storedValue(t, db, "users", "metadata", user.ID.String()); strings.Contains(raw, "Lovelace") {
+			t.Errorf("Expected updated metadata to be encrypted, got %s", raw)
+		}
+
+		// Encrypted names cannot be searched in the database, emails still can
+		params, err := pagination.Parse(func(key string, defaultValue ...string) string {
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}, UserListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		users, _, err := repo.ListUsers(ctx, tenant.ID, &UserSearch{Query: "ada"}, params)
+		if err != nil || len(users) != 1 || !strings.Contains(string(users[0].Metadata), "Lovelace") {
+			t.Errorf("Expected the user to be found by email with decrypted metadata, got %+v, %v", users, err)
+		}
+		users, _, err = repo.ListUsers(ctx, tenant.ID, &UserSearch{Query: "lovelace"}, params)
+		if err != nil || len(users) != 0 {
+			t.Errorf("Expected encrypted names not to match, got %d users, %v", len(users), err)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/events"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestAuthService_RefreshToken_Binding(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		_, fusionAuth := newFakeFusionAuth(t)
+		redis := database.NewMemoryClient()
+		publisher := &recordingPublisher{}
+		authService := NewAuthService(db, fusionAuth, jwtService, redis, nil, publisher)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		identityUser := &auth.IdentityUser{ID: user.ID.String(), Email: user.Email}
+
+		session, err := authService.LoginExternal(ctx, identityUser, &LoginRequest{Email: user.Email, UserAgent: "Mozilla/5.0", DeviceID: "laptop"})
+		if err != nil {
+			t.Fatalf("LoginExternal returned error: %v", err)
+		}
+
+		// The client the token was issued to can refresh it
+		refreshed, err := authService.RefreshToken(ctx, &RefreshTokenRequest{RefreshToken: session.RefreshToken, UserAgent: "Mozilla/5.0", DeviceID: "laptop"})
+		if err != nil {
+			t.Fatalf("RefreshToken returned error: %v", err)
+		}
+
+		// Another client is rejected, and the stolen token revoked
+		var appErr *apperrors.Error
+		stolen := &RefreshTokenRequest{RefreshToken: refreshed.RefreshToken, UserAgent: "curl/8.0", IPAddress: "203.0.113.9"}
+		if _, err := authService.RefreshToken(ctx, stolen); !errors.As(err, &appErr) || appErr.Code != "REFRESH_TOKEN_MISMATCH" {
+			t.Fatalf("Expected REFRESH_TOKEN_MISMATCH from another client, got %v", err)
+		}
+		if !equalStrings(publisher.types, []string{events.EventRefreshMismatch}) {
+			t.Errorf("Expected a fingerprint mismatch event, got %v", publisher.types)
+		}
+		if _, err := authService.RefreshToken(ctx, &RefreshTokenRequest{RefreshToken: refreshed.RefreshToken, UserAgent: "Mozilla/5.0", DeviceID: "laptop"}); !errors.As(err, &appErr) || appErr.Code != "INVALID_REFRESH_TOKEN" {
+			t.Errorf("Expected the mismatched token to be revoked, got %v", err)
+		}
+
+		// Tokens stored before binding are accepted and bound when rotated
+		session, err = authService.LoginExternal(ctx, identityUser, &LoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("LoginExternal returned error: %v", err)
+		}
+		claims, _ := jwtService.ValidateRefreshToken(session.RefreshToken)
+		redis.StoreRefreshToken(ctx, claims.UserID, claims.ID, claims.ID, time.Hour)
+		refreshed, err = authService.RefreshToken(ctx, &RefreshTokenRequest{RefreshToken: session.RefreshToken, UserAgent: "curl/8.0"})
+		if err != nil {
+			t.Fatalf("Expected an unbound token to refresh, got %v", err)
+		}
+		if _, err := authService.RefreshToken(ctx, &RefreshTokenRequest{RefreshToken: refreshed.RefreshToken, UserAgent: "Mozilla/5.0"}); !errors.As(err, &appErr) || appErr.Code != "REFRESH_TOKEN_MISMATCH" {
+			t.Errorf("Expected the rotated token to be bound, got %v", err)
+		}
+	})
+}
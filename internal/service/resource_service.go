@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttributeSourceRegistry is the attribute source kind of the resource registry
+const AttributeSourceRegistry = "registry"
+
+// resourceType matches valid types of registered resources
+var resourceType = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,99}$`)
+
+// resourceLabelKey matches valid label keys, which are used as JSON paths when
+// filtering resources by label
+var resourceLabelKey = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,62}$`)
+
+// ResourceService manages the registry of resources other services register
+// with Heimdall. Heimdall's own entities are not registered; their attributes
+// are derived from the database.
+type ResourceService struct {
+	db      *gorm.DB
+	derived *DatabaseAttributeSource
+}
+
+// UpsertResourceRequest represents the desired attributes of a registered resource
+type UpsertResourceRequest struct {
+	OwnerID    string                 `json:"ownerId,omitempty" validate:"max=255" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Labels     map[string]string      `json:"labels,omitempty" validate:"max=64,dive,max=255" example:"{\"env\":\"prod\"}"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// ResourceResponse represents a registered or derived resource
+type ResourceResponse struct {
+	Type       string                 `json:"type" example:"documents"`
+	ResourceID string                 `json:"resourceId" example:"doc-42"`
+	TenantID   string                 `json:"tenantId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	OwnerID    string                 `json:"ownerId,omitempty" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Derived    bool                   `json:"derived" example:"false"` // Derived from Heimdall's own entities rather than registered
+	CreatedAt  string                 `json:"createdAt,omitempty" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt  string                 `json:"updatedAt,omitempty" example:"2024-01-20T14:45:00Z"`
+	ETag       string                 `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// ResourceFilter narrows a listing of registered resources
+type ResourceFilter struct {
+	Type    string
+	OwnerID string
+	Label   string // key=value
+}
+
+// ResourceListOptions describes the sorting supported when listing registered resources
+var ResourceListOptions = pagination.Options{
+	SortFields:  map[string]string{"createdAt": "created_at", "updatedAt": "updated_at"},
+	DefaultSort: "-createdAt",
+}
+
+// NewResourceService creates a new resource registry service
+func NewResourceService(db *gorm.DB) *ResourceService {
+	return &ResourceService{
+		db:      db,
+		derived: NewDatabaseAttributeSource(db),
+	}
+}
+
+// GetResource retrieves a resource of a tenant. Resources of Heimdall's own
+// types are derived from the database.
+func (s *ResourceService) GetResource(ctx context.Context, tenantID uuid.UUID, typ, id string) (*ResourceResponse, error) {
+	if s.derived.Supports(typ) {
+		return s.derivedResource(ctx, tenantID, typ, id)
+	}
+
+	resource, err := s.findResource(readReplica(s.db).WithContext(ctx), tenantID, typ, id)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, apperrors.NotFound("RESOURCE_NOT_FOUND", "Resource not found")
+	}
+	return toResourceResponse(resource)
+}
+
+// UpsertResource registers a resource or replaces its attributes and reports
+// whether it was created
+func (s *ResourceService) UpsertResource(ctx context.Context, tenantID uuid.UUID, typ, id string, req *UpsertResourceRequest, pre Precondition) (*ResourceResponse, bool, error) {
+	if err := s.validateKey(typ, id); err != nil {
+		return nil, false, err
+	}
+	for key := range req.Labels {
+		if !resourceLabelKey.MatchString(key) {
+			return nil, false, apperrors.Validation("INVALID_RESOURCE_LABEL", "Label keys must start with a letter and contain only letters, digits and underscores").
+				WithDetails(map[string]interface{}{"label": key})
+		}
+	}
+
+	labels, err := json.Marshal(req.Labels)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal labels: %w", err)
+	}
+	attributes, err := json.Marshal(req.Attributes)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal attributes: %w", err)
+	}
+
+	var response *ResourceResponse
+	created := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Select("id").First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		resource, err := s.findResource(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tenantID, typ, id)
+		if err != nil {
+			return err
+		}
+		created = resource == nil
+
+		current := ""
+		if !created {
+			current = ResourceETag(resource.UpdatedAt)
+		} else {
+			resource = &models.Resource{TenantID: tenantID, Type: typ, ResourceID: id}
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+
+		resource.OwnerID = req.OwnerID
+		resource.Labels = labels
+		resource.Attributes = attributes
+		if created {
+			err = tx.Create(resource).Error
+		} else {
+			err = tx.Save(resource).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save resource: %w", err)
+		}
+		response, err = toResourceResponse(resource)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, created, nil
+}
+
+// DeleteResource removes a registered resource
+func (s *ResourceService) DeleteResource(ctx context.Context, tenantID uuid.UUID, typ, id string) error {
+	if err := s.validateKey(typ, id); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Delete(&models.Resource{}, "tenant_id = ? AND type = ? AND resource_id = ?", tenantID, typ, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete resource: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound("RESOURCE_NOT_FOUND", "Resource not found")
+	}
+	return nil
+}
+
+// ListResources returns a page of a tenant's registered resources
+func (s *ResourceService) ListResources(ctx context.Context, tenantID uuid.UUID, filter ResourceFilter, params *pagination.Params) ([]ResourceResponse, *pagination.Page, error) {
+	db := readReplica(s.db)
+	query := db.Model(&models.Resource{}).Where("tenant_id = ?", tenantID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.OwnerID != "" {
+		query = query.Where("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Label != "" {
+		key, value, ok := strings.Cut(filter.Label, "=")
+		if !ok || !resourceLabelKey.MatchString(key) {
+			return nil, nil, apperrors.Validation("INVALID_FILTER", "label must be a key=value pair")
+		}
+		query = query.Where(jsonText(db, "labels", key)+" = ?", value)
+	}
+
+	resources, page, err := pagination.Paginate[models.Resource](ctx, query, params, ResourceListOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	responses := make([]ResourceResponse, len(resources))
+	for i := range resources {
+		response, err := toResourceResponse(&resources[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		responses[i] = *response
+	}
+	return responses, page, nil
+}
+
+// Name returns the attribute source name
+func (s *ResourceService) Name() string {
+	return AttributeSourceRegistry
+}
+
+// Enrich sets the owner, labels and attributes of a registered resource on the
+// input. Resources are looked up in the tenant of the input's resource, which
+// defaults to the user's tenant.
+func (s *ResourceService) Enrich(ctx context.Context, input *opa.AuthorizationInput) error {
+	if input.Resource.ID == "" || s.derived.Supports(input.Resource.Type) {
+		return nil
+	}
+	tenantID := input.Resource.TenantID
+	if tenantID == "" {
+		tenantID = input.User.TenantID
+	}
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil
+	}
+
+	resource, err := s.findResource(readReplica(s.db).WithContext(ctx), tenantUUID, input.Resource.Type, input.Resource.ID)
+	if err != nil || resource == nil {
+		return err
+	}
+	response, err := toResourceResponse(resource)
+	if err != nil {
+		return err
+	}
+
+	attributes := response.Attributes
+	if response.Labels != nil {
+		attributes = opa.MergeMetadata(attributes, map[string]interface{}{"labels": response.Labels})
+	}
+	opa.ApplyResourceAttributes(input, &opa.ResourceAttributes{
+		OwnerID:    response.OwnerID,
+		TenantID:   response.TenantID,
+		Attributes: attributes,
+	})
+	return nil
+}
+
+// derivedResource returns one of Heimdall's own entities as a resource of the tenant
+func (s *ResourceService) derivedResource(ctx context.Context, tenantID uuid.UUID, typ, id string) (*ResourceResponse, error) {
+	input := &opa.AuthorizationInput{Resource: opa.ResourceContext{Type: typ, ID: id}}
+	if err := s.derived.Enrich(ctx, input); err != nil {
+		return nil, err
+	}
+
+	// Global bundles belong to no tenant and are visible to every tenant
+	found := input.Resource.Attributes != nil
+	if !found || (input.Resource.TenantID != "" && input.Resource.TenantID != tenantID.String()) {
+		return nil, apperrors.NotFound("RESOURCE_NOT_FOUND", "Resource not found")
+	}
+	return &ResourceResponse{
+		Type:       typ,
+		ResourceID: id,
+		TenantID:   input.Resource.TenantID,
+		OwnerID:    input.Resource.OwnerID,
+		Attributes: input.Resource.Attributes,
+		Derived:    true,
+	}, nil
+}
+
+// validateKey checks the type and ID of a registered resource
+func (s *ResourceService) validateKey(typ, id string) error {
+	if s.derived.Supports(typ) {
+		return apperrors.Validation("RESERVED_RESOURCE_TYPE", "Resources of Heimdall's own types are derived and cannot be registered").
+			WithDetails(map[string]interface{}{"type": typ})
+	}
+	if !resourceType.MatchString(typ) {
+		return apperrors.Validation("INVALID_RESOURCE_TYPE", "Resource types must start with a lowercase letter and contain only lowercase letters, digits, '_', '.' and '-'")
+	}
+	if id == "" || len(id) > 255 {
+		return apperrors.Validation("INVALID_RESOURCE_ID", "Resource IDs must be between 1 and 255 characters")
+	}
+	return nil
+}
+
+// findResource loads a registered resource, returning nil when it does not exist
+func (s *ResourceService) findResource(db *gorm.DB, tenantID uuid.UUID, typ, id string) (*models.Resource, error) {
+	var resource models.Resource
+	err := db.Where("tenant_id = ? AND type = ? AND resource_id = ?", tenantID, typ, id).First(&resource).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+	return &resource, nil
+}
+
+func toResourceResponse(resource *models.Resource) (*ResourceResponse, error) {
+	response := &ResourceResponse{
+		Type:       resource.Type,
+		ResourceID: resource.ResourceID,
+		TenantID:   resource.TenantID.String(),
+		OwnerID:    resource.OwnerID,
+		CreatedAt:  resource.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  resource.UpdatedAt.Format(time.RFC3339),
+		ETag:       ResourceETag(resource.UpdatedAt),
+	}
+	if len(resource.Labels) > 0 {
+		if err := json.Unmarshal(resource.Labels, &response.Labels); err != nil {
+			return nil, fmt.Errorf("failed to parse resource labels: %w", err)
+		}
+	}
+	if len(resource.Attributes) > 0 {
+		if err := json.Unmarshal(resource.Attributes, &response.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to parse resource attributes: %w", err)
+		}
+	}
+	return response, nil
+}
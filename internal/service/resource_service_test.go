@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestResourceService(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.com")
+		service := NewResourceService(db)
+
+		doc, created, err := service.UpsertResource(ctx, acme.ID, "documents", "doc-1", &UpsertResourceRequest{
+			OwnerID:    alice.ID.String(),
+			Labels:     map[string]string{"env": "prod"},
+			Attributes: map[string]interface{}{"classification": "secret"},
+		}, Precondition{})
+		if err != nil || !created {
+			t.Fatalf("Failed to register resource: created=%v, err=%v", created, err)
+		}
+		if _, _, err := service.UpsertResource(ctx, acme.ID, "documents", "doc-2", &UpsertResourceRequest{
+			Labels: map[string]string{"env": "staging"},
+		}, Precondition{}); err != nil {
+			t.Fatalf("Failed to register resource: %v", err)
+		}
+
+		var appErr *apperrors.Error
+		_, _, err = service.UpsertResource(ctx, acme.ID, "documents", "doc-1", &UpsertResourceRequest{}, Precondition{IfMatch: `"stale"`})
+		if !errors.As(err, &appErr) || appErr.Code != "ETAG_MISMATCH" {
+			t.Errorf("Expected ETAG_MISMATCH for a stale ETag, got %v", err)
+		}
+		if _, created, err = service.UpsertResource(ctx, acme.ID, "documents", "doc-1", &UpsertResourceRequest{
+			OwnerID:    alice.ID.String(),
+			Labels:     map[string]string{"env": "prod"},
+			Attributes: map[string]interface{}{"classification": "secret"},
+		}, Precondition{IfMatch: doc.ETag}); err != nil || created {
+			t.Errorf("Expected the resource to be updated, created=%v, err=%v", created, err)
+		}
+
+		tests := []struct {
+			name     string
+			typ, id  string
+			req      *UpsertResourceRequest
+			wantCode string
+		}{
+			{"own type", "users", alice.ID.String(), &UpsertResourceRequest{}, "RESERVED_RESOURCE_TYPE"},
+			{"invalid type", "Documents!", "doc-1", &UpsertResourceRequest{}, "INVALID_RESOURCE_TYPE"},
+			{"invalid label", "documents", "doc-3", &UpsertResourceRequest{Labels: map[string]string{"env'": "prod"}}, "INVALID_RESOURCE_LABEL"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := service.UpsertResource(ctx, acme.ID, tt.typ, tt.id, tt.req, Precondition{})
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("Expected %s, got %v", tt.wantCode, err)
+				}
+			})
+		}
+
+		params, err := pagination.Parse(func(key string, defaultValue ...string) string {
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}, ResourceListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		resources, _, err := service.ListResources(ctx, acme.ID, ResourceFilter{Label: "env=prod"}, params)
+		if err != nil || len(resources) != 1 || resources[0].ResourceID != "doc-1" {
+			t.Errorf("Expected doc-1 labelled env=prod, got %+v, %v", resources, err)
+		}
+		resources, _, err = service.ListResources(ctx, acme.ID, ResourceFilter{OwnerID: alice.ID.String()}, params)
+		if err != nil || len(resources) != 1 {
+			t.Errorf("Expected one resource owned by alice, got %+v, %v", resources, err)
+		}
+		if resources, _, err = service.ListResources(ctx, globex.ID, ResourceFilter{}, params); err != nil || len(resources) != 0 {
+			t.Errorf("Expected no resources of another tenant, got %+v, %v", resources, err)
+		}
+
+		// Heimdall's own entities are derived within their tenant
+		user, err := service.GetResource(ctx, acme.ID, "users", alice.ID.String())
+		if err != nil || !user.Derived || user.OwnerID != alice.ID.String() {
+			t.Errorf("Expected alice to be derived as her own resource, got %+v, %v", user, err)
+		}
+		if _, err := service.GetResource(ctx, globex.ID, "users", alice.ID.String()); !errors.As(err, &appErr) || appErr.Code != "RESOURCE_NOT_FOUND" {
+			t.Errorf("Expected RESOURCE_NOT_FOUND for a user of another tenant, got %v", err)
+		}
+
+		// Registered resources enrich authorization inputs in the user's tenant
+		input := &opa.AuthorizationInput{
+			User:     opa.UserContext{ID: alice.ID.String(), TenantID: acme.ID.String()},
+			Resource: opa.ResourceContext{Type: "documents", ID: "doc-1", OwnerID: "forged"},
+		}
+		if err := service.Enrich(ctx, input); err != nil {
+			t.Fatalf("Failed to enrich input: %v", err)
+		}
+		labels, _ := input.Resource.Attributes["labels"].(map[string]string)
+		if input.Resource.OwnerID != alice.ID.String() || labels["env"] != "prod" || input.Resource.Attributes["classification"] != "secret" {
+			t.Errorf("Expected registered owner, labels and attributes, got %+v", input.Resource)
+		}
+		other := &opa.AuthorizationInput{
+			User:     opa.UserContext{ID: alice.ID.String(), TenantID: globex.ID.String()},
+			Resource: opa.ResourceContext{Type: "documents", ID: "doc-1"},
+		}
+		if err := service.Enrich(ctx, other); err != nil || other.Resource.OwnerID != "" {
+			t.Errorf("Expected resources of another tenant to be left alone, got %+v, %v", other.Resource, err)
+		}
+
+		if err := service.DeleteResource(ctx, acme.ID, "documents", "doc-1"); err != nil {
+			t.Fatalf("Failed to delete resource: %v", err)
+		}
+		if _, err := service.GetResource(ctx, acme.ID, "documents", "doc-1"); !errors.As(err, &appErr) || appErr.Code != "RESOURCE_NOT_FOUND" {
+			t.Errorf("Expected RESOURCE_NOT_FOUND after deletion, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// roleExpiryBatchSize caps the assignments removed per run
+const roleExpiryBatchSize = 500
+
+// RoleExpiry removes expired role assignments. Expired assignments are already
+// ignored when roles are looked up; removing them records their expiry in the
+// audit log, drops the users' cached decisions and pushes their tenants' roles
+// to OPA.
+type RoleExpiry struct {
+	db            *gorm.DB
+	rbacSync      *RBACDataSync
+	decisionCache DecisionCacheInvalidator
+}
+
+// NewRoleExpiry creates a new role expiry job. rbacSync and decisionCache may be nil.
+func NewRoleExpiry(db *gorm.DB, rbacSync *RBACDataSync, decisionCache DecisionCacheInvalidator) *RoleExpiry {
+	return &RoleExpiry{
+		db:            db,
+		rbacSync:      rbacSync,
+		decisionCache: decisionCache,
+	}
+}
+
+// Run removes expired assignments every interval until ctx is cancelled
+func (e *RoleExpiry) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := e.Expire(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to remove expired role assignments: %v", err)
+		}
+		if expired > 0 {
+			log.Printf("Removed %d expired role assignments", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expiredAssignment is an expired role assignment with its user's tenant
+type expiredAssignment struct {
+	models.UserRole
+	TenantID uuid.UUID
+	RoleName string
+}
+
+// Expire removes the assignments that have expired and returns the number removed
+func (e *RoleExpiry) Expire(ctx context.Context) (int, error) {
+	var assignments []expiredAssignment
+	if err := e.db.WithContext(ctx).Model(&models.UserRole{}).
+		Select("user_roles.*, users.tenant_id, roles.name AS role_name").
+		Joins("JOIN users ON users.id = user_roles.user_id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.expires_at <= ?", time.Now()).
+		Order("user_roles.expires_at ASC").
+		Limit(roleExpiryBatchSize).
+		Scan(&assignments).Error; err != nil {
+		return 0, fmt.Errorf("failed to load expired role assignments: %w", err)
+	}
+
+	removed := 0
+	users := make(map[uuid.UUID]bool)
+	tenants := make(map[uuid.UUID]bool)
+	var expireErr error
+	for i := range assignments {
+		ok, err := e.expire(ctx, &assignments[i])
+		if err != nil {
+			expireErr = err
+			break
+		}
+		if ok {
+			removed++
+			users[assignments[i].UserID] = true
+			tenants[assignments[i].TenantID] = true
+		}
+	}
+
+	if e.decisionCache != nil {
+		for userID := range users {
+			if err := e.decisionCache.InvalidateUserCache(ctx, userID.String()); err != nil {
+				log.Printf("Failed to invalidate cached decisions of user %s: %v", userID, err)
+			}
+		}
+	}
+	for tenantID := range tenants {
+		e.rbacSync.TenantChanged(ctx, tenantID)
+	}
+	return removed, expireErr
+}
+
+// expire deletes an assignment and records its expiry in the audit log in the
+// same transaction. It removes nothing when the assignment was extended or
+// removed meanwhile.
+func (e *RoleExpiry) expire(ctx context.Context, assignment *expiredAssignment) (bool, error) {
+	removed := false
+	err := e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND expires_at <= ?", assignment.ID, time.Now()).Delete(&models.UserRole{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove role assignment: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		removed = true
+
+		details, _ := json.Marshal(map[string]interface{}{
+			"roleId":     assignment.RoleID.String(),
+			"roleName":   assignment.RoleName,
+			"assignedBy": assignment.AssignedBy.String(),
+			"assignedAt": assignment.AssignedAt.UTC().Format(time.RFC3339),
+			"expiresAt":  assignment.ExpiresAt.UTC().Format(time.RFC3339),
+			"reason":     assignment.Reason,
+		})
+		userID := assignment.UserID
+		return NewAdminAuditService(tx).RecordAdminAction(ctx, &models.AuditLog{
+			TenantID:   assignment.TenantID,
+			Action:     "roles.expire",
+			Resource:   "users",
+			ResourceID: &userID,
+			Status:     "success",
+			Metadata:   datatypes.JSON(details),
+		})
+	})
+	return removed, err
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// recordingDecisionCache records the users whose cached decisions were dropped
+type recordingDecisionCache struct {
+	users []string
+}
+
+func (c *recordingDecisionCache) InvalidateUserCache(ctx context.Context, userID string) error {
+	c.users = append(c.users, userID)
+	return nil
+}
+
+func TestRoleExpiry(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.com")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.com")
+		admin := testutil.CreateTestRole(t, db, acme, "admin")
+		editor := testutil.CreateTestRole(t, db, acme, "editor")
+		foreign := testutil.CreateTestRole(t, db, globex, "admin")
+		testutil.AssignPermissionToRole(t, db, admin, testutil.CreateTestPermission(t, db, "users.delete", "users", "delete"))
+		testutil.AssignRoleToUser(t, db, alice, editor)
+
+		cache := &recordingDecisionCache{}
+		userService := NewUserService(db, nil)
+		userService.SetDecisionCache(cache)
+		userService.SetMaxElevation(8 * time.Hour)
+
+		// Elevated access is granted for a limited time
+		assignment, err := userService.ElevateRole(ctx, alice.ID.String(), bob.ID.String(), &ElevateRoleRequest{
+			RoleID:          admin.ID.String(),
+			DurationMinutes: 60,
+			Reason:          "On-call",
+		})
+		if err != nil {
+			t.Fatalf("Failed to elevate role: %v", err)
+		}
+		if assignment.RoleName != "admin" || assignment.ExpiresAt == "" || assignment.Reason != "On-call" || assignment.Expired {
+			t.Errorf("Expected a temporary admin assignment, got %+v", assignment)
+		}
+		if len(cache.users) != 1 || cache.users[0] != alice.ID.String() {
+			t.Errorf("Expected alice's cached decisions to be dropped, got %v", cache.users)
+		}
+		if permissions, _ := userService.GetUserPermissions(ctx, alice.ID.String()); len(permissions) != 1 {
+			t.Errorf("Expected the elevated role's permissions, got %v", permissions)
+		}
+
+		if _, err := userService.ElevateRole(ctx, alice.ID.String(), bob.ID.String(), &ElevateRoleRequest{RoleID: admin.ID.String(), DurationMinutes: 9 * 60, Reason: "Too long"}); !isAppError(err, "ELEVATION_TOO_LONG") {
+			t.Errorf("Expected ELEVATION_TOO_LONG, got %v", err)
+		}
+		if _, err := userService.ElevateRole(ctx, alice.ID.String(), bob.ID.String(), &ElevateRoleRequest{RoleID: editor.ID.String(), DurationMinutes: 60, Reason: "Already an editor"}); !isAppError(err, "ROLE_ALREADY_ASSIGNED") {
+			t.Errorf("Expected ROLE_ALREADY_ASSIGNED for a permanent role, got %v", err)
+		}
+		if _, err := userService.ElevateRole(ctx, alice.ID.String(), bob.ID.String(), &ElevateRoleRequest{RoleID: foreign.ID.String(), DurationMinutes: 60, Reason: "Other tenant"}); !isAppError(err, "ROLE_NOT_FOUND") {
+			t.Errorf("Expected ROLE_NOT_FOUND for another tenant's role, got %v", err)
+		}
+		past := time.Now().Add(-time.Minute)
+		if err := userService.AssignRoleToUser(ctx, bob.ID.String(), admin.ID.String(), alice.ID.String(), &past); !isAppError(err, "INVALID_EXPIRY") {
+			t.Errorf("Expected INVALID_EXPIRY for an expiry in the past, got %v", err)
+		}
+
+		// Expired assignments are ignored right away, and removed with an audit entry
+		db.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", alice.ID, admin.ID).Update("expires_at", time.Now().Add(-time.Second))
+		assignments, err := userService.GetRoleAssignments(ctx, alice.ID.String())
+		if err != nil || len(assignments) != 2 || !assignments[1].Expired {
+			t.Errorf("Expected the expired assignment to be listed as expired, got %+v, %v", assignments, err)
+		}
+		if roles, _ := NewUserRepository(db).GetUserRoles(ctx, alice.ID); len(roles) != 1 || roles[0].Name != "editor" {
+			t.Errorf("Expected only the permanent role, got %+v", roles)
+		}
+
+		cache.users = nil
+		expired, err := NewRoleExpiry(db, nil, cache).Expire(ctx)
+		if err != nil || expired != 1 {
+			t.Fatalf("Expected one assignment to be removed, got %d, %v", expired, err)
+		}
+		if len(cache.users) != 1 || cache.users[0] != alice.ID.String() {
+			t.Errorf("Expected alice's cached decisions to be dropped, got %v", cache.users)
+		}
+		if assignments, _ := userService.GetRoleAssignments(ctx, alice.ID.String()); len(assignments) != 1 || assignments[0].RoleName != "editor" {
+			t.Errorf("Expected only the permanent assignment to remain, got %+v", assignments)
+		}
+
+		params, err := pagination.Parse(func(key string, defaultValue ...string) string {
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}, AdminActionListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		actions, _, err := NewAdminAuditService(db).ListAdminActions(ctx, acme.ID.String(), AdminActionFilter{Action: "roles.expire"}, params)
+		if err != nil || len(actions) != 1 {
+			t.Fatalf("Expected the expiry to be audited, got %+v, %v", actions, err)
+		}
+		if actions[0].ResourceID != alice.ID.String() || actions[0].UserID != "" || actions[0].Details["roleName"] != "admin" || actions[0].Details["reason"] != "On-call" {
+			t.Errorf("Unexpected audit entry: %+v", actions[0])
+		}
+
+		if expired, err := NewRoleExpiry(db, nil, nil).Expire(ctx); err != nil || expired != 0 {
+			t.Errorf("Expected nothing left to expire, got %d, %v", expired, err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// syncMappedRoles gives a user the roles named in desired and removes the other
+// roles named in managed, all within the user's tenant. Roles not in managed are
+// left alone, so roles assigned by hand survive external group and attribute syncs.
+// It reports whether the user's roles changed.
+func syncMappedRoles(tx *gorm.DB, userID, tenantID uuid.UUID, managed []string, desired map[string]bool) (bool, error) {
+	if len(managed) == 0 {
+		return false, nil
+	}
+
+	var roles []models.Role
+	if err := tx.Where("tenant_id = ? AND name IN ?", tenantID, managed).Find(&roles).Error; err != nil {
+		return false, fmt.Errorf("failed to get mapped roles: %w", err)
+	}
+	var assigned []uuid.UUID
+	if err := tx.Model(&models.UserRole{}).Where("user_id = ?", userID).Pluck("role_id", &assigned).Error; err != nil {
+		return false, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	has := make(map[uuid.UUID]bool, len(assigned))
+	for _, roleID := range assigned {
+		has[roleID] = true
+	}
+
+	changed := false
+	for _, role := range roles {
+		switch {
+		case desired[role.Name] && !has[role.ID]:
+			if err := tx.Create(&models.UserRole{UserID: userID, RoleID: role.ID}).Error; err != nil {
+				return false, fmt.Errorf("failed to assign role %s: %w", role.Name, err)
+			}
+			changed = true
+		case !desired[role.Name] && has[role.ID]:
+			if err := tx.Where("user_id = ? AND role_id = ?", userID, role.ID).Delete(&models.UserRole{}).Error; err != nil {
+				return false, fmt.Errorf("failed to remove role %s: %w", role.Name, err)
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
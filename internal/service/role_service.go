@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// validRBACName matches role and permission names, e.g. "billing_admin" or "policies.read"
+var validRBACName = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,100}$`)
+
+// RoleService manages tenant roles and the global permission catalog by name, so that
+// declarative tools can converge them with idempotent upserts
+type RoleService struct {
+	db       *gorm.DB
+	rbacSync *RBACDataSync
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(db *gorm.DB) *RoleService {
+	return &RoleService{db: db}
+}
+
+// SetRBACDataSync pushes role and permission changes to OPA
+func (s *RoleService) SetRBACDataSync(rbacSync *RBACDataSync) {
+	s.rbacSync = rbacSync
+}
+
+// UpsertRoleRequest represents the desired state of a role identified by its name.
+// The role's permissions are replaced by the listed ones.
+type UpsertRoleRequest struct {
+	Description string   `json:"description,omitempty" example:"Manages billing"`
+	ParentRole  string   `json:"parentRole,omitempty" validate:"omitempty,max=100" example:"user"` // Name of the parent role in the same tenant
+	Permissions []string `json:"permissions" validate:"dive,required,max=100" example:"[\"policies.read\"]"`
+}
+
+// RoleResponse represents a role with its permissions by name
+type RoleResponse struct {
+	ID          string   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID    string   `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name        string   `json:"name" example:"billing_admin"`
+	Description string   `json:"description,omitempty" example:"Manages billing"`
+	ParentRole  string   `json:"parentRole,omitempty" example:"user"`
+	Permissions []string `json:"permissions" example:"[\"policies.read\"]"`
+	IsSystem    bool     `json:"isSystem"`
+	CreatedAt   string   `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt   string   `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+	ETag        string   `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// UpsertPermissionRequest represents the desired state of a permission identified by its name
+type UpsertPermissionRequest struct {
+	Resource    string `json:"resource" validate:"required,max=100" example:"invoices"`
+	Action      string `json:"action" validate:"required,max=50" example:"read"`
+	Description string `json:"description,omitempty" example:"Read invoices"`
+	Scope       string `json:"scope,omitempty" validate:"omitempty,oneof=own tenant global" example:"tenant"`
+}
+
+// GetRole retrieves a tenant's role by name
+func (s *RoleService) GetRole(ctx context.Context, tenantID uuid.UUID, name string) (*RoleResponse, error) {
+	var role models.Role
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND name = ?", tenantID, name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("ROLE_NOT_FOUND", "Role not found")
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return s.toRoleResponse(ctx, s.db, &role)
+}
+
+// UpsertRole creates or replaces a tenant's role with the given name. The existing role
+// is locked while the precondition is checked. It reports whether the role was created.
+func (s *RoleService) UpsertRole(ctx context.Context, tenantID uuid.UUID, name string, req *UpsertRoleRequest, pre Precondition, userID uuid.UUID) (*RoleResponse, bool, error) {
+	if !validRBACName.MatchString(name) {
+		return nil, false, apperrors.Validation("INVALID_ROLE_NAME", "Invalid role name: use up to 100 letters, numbers, '_', '.', ':' or '-'")
+	}
+
+	var response *RoleResponse
+	created := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND name = ?", tenantID, name).
+			First(&role).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+		created = err != nil
+
+		current := ""
+		if !created {
+			current = ResourceETag(role.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+		if role.IsSystem {
+			return apperrors.Forbidden("SYSTEM_ROLE_IMMUTABLE", "Cannot modify system role")
+		}
+		// The platform role spans tenants, so only bootstrapping creates it
+		if created && name == RoleSuperAdmin {
+			return apperrors.Forbidden("RESERVED_ROLE_NAME", fmt.Sprintf("%s is a reserved system role", name))
+		}
+
+		permissions, err := s.findPermissions(tx, req.Permissions)
+		if err != nil {
+			return err
+		}
+
+		role.ParentRoleID = nil
+		if req.ParentRole != "" {
+			var parent models.Role
+			if err := tx.Where("tenant_id = ? AND name = ?", tenantID, req.ParentRole).First(&parent).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return apperrors.Validation("PARENT_ROLE_NOT_FOUND", "Parent role not found").
+						WithDetails(map[string]interface{}{"parentRole": req.ParentRole})
+				}
+				return fmt.Errorf("failed to get parent role: %w", err)
+			}
+			if parent.ID == role.ID {
+				return apperrors.Validation("INVALID_PARENT_ROLE", "A role cannot be its own parent")
+			}
+			role.ParentRoleID = &parent.ID
+		}
+
+		role.Description = req.Description
+		if created {
+			if err := s.checkRoleQuota(tx, tenantID); err != nil {
+				return err
+			}
+			role.TenantID = tenantID
+			role.Name = name
+			err = tx.Omit(clause.Associations).Create(&role).Error
+		} else {
+			err = tx.Omit(clause.Associations).Save(&role).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save role: %w", err)
+		}
+
+		if err := s.replaceRolePermissions(tx, role.ID, permissions, userID); err != nil {
+			return err
+		}
+
+		// Reload so the ETag reflects the stored timestamp
+		if err := tx.First(&role, "id = ?", role.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload role: %w", err)
+		}
+		response, err = s.toRoleResponse(ctx, tx, &role)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	s.rbacSync.TenantChanged(ctx, tenantID)
+
+	return response, created, nil
+}
+
+// DeleteRole deletes a tenant's role by name
+func (s *RoleService) DeleteRole(ctx context.Context, tenantID uuid.UUID, name string) error {
+	var role models.Role
+	if err := s.db.WithContext(ctx).Where("tenant_id = ? AND name = ?", tenantID, name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("ROLE_NOT_FOUND", "Role not found")
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if role.IsSystem {
+		return apperrors.Forbidden("SYSTEM_ROLE_IMMUTABLE", "Cannot delete system role")
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("failed to delete role permissions: %w", err)
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.UserRole{}).Error; err != nil {
+			return fmt.Errorf("failed to delete role assignments: %w", err)
+		}
+		if err := tx.Delete(&role).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.rbacSync.TenantChanged(ctx, tenantID)
+	return nil
+}
+
+// GetPermission retrieves a permission by name
+func (s *RoleService) GetPermission(ctx context.Context, name string) (*models.Permission, error) {
+	var permission models.Permission
+	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&permission).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("PERMISSION_NOT_FOUND", "Permission not found")
+		}
+		return nil, fmt.Errorf("failed to get permission: %w", err)
+	}
+
+	return &permission, nil
+}
+
+// UpsertPermission creates or replaces the permission with the given name. A permission
+// deleted earlier under the same name is restored. It reports whether the permission was created.
+func (s *RoleService) UpsertPermission(ctx context.Context, name string, req *UpsertPermissionRequest, pre Precondition) (*models.Permission, bool, error) {
+	if !validRBACName.MatchString(name) {
+		return nil, false, apperrors.Validation("INVALID_PERMISSION_NAME", "Invalid permission name: use up to 100 letters, numbers, '_', '.', ':' or '-'")
+	}
+
+	var permission models.Permission
+	created := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).First(&permission).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get permission: %w", err)
+		}
+		created = err != nil || permission.DeletedAt.Valid
+
+		current := ""
+		if !created {
+			current = ResourceETag(permission.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+		if permission.IsSystem {
+			return apperrors.Forbidden("SYSTEM_PERMISSION_IMMUTABLE", "Cannot modify system permission")
+		}
+
+		permission.Name = name
+		permission.Resource = req.Resource
+		permission.Action = req.Action
+		permission.Description = req.Description
+		permission.Scope = req.Scope
+		if permission.Scope == "" {
+			permission.Scope = "tenant"
+		}
+		permission.DeletedAt = gorm.DeletedAt{}
+
+		if permission.ID == uuid.Nil {
+			err = tx.Omit(clause.Associations).Create(&permission).Error
+		} else {
+			err = tx.Unscoped().Omit(clause.Associations).Save(&permission).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save permission: %w", err)
+		}
+
+		// Reload so the ETag reflects the stored timestamp
+		return tx.First(&permission, "id = ?", permission.ID).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &permission, created, nil
+}
+
+// DeletePermission deletes a permission by name and removes it from all roles
+func (s *RoleService) DeletePermission(ctx context.Context, name string) error {
+	permission, err := s.GetPermission(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if permission.IsSystem {
+		return apperrors.Forbidden("SYSTEM_PERMISSION_IMMUTABLE", "Cannot delete system permission")
+	}
+
+	// Tenants with roles granting the permission need their roles pushed again
+	var tenantIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.Role{}).
+		Distinct("roles.tenant_id").
+		Joins("JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Where("role_permissions.permission_id = ?", permission.ID).
+		Pluck("roles.tenant_id", &tenantIDs).Error; err != nil {
+		return fmt.Errorf("failed to get affected tenants: %w", err)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", permission.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("failed to delete role permissions: %w", err)
+		}
+		if err := tx.Delete(permission).Error; err != nil {
+			return fmt.Errorf("failed to delete permission: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.rbacSync.TenantChanged(ctx, tenantIDs...)
+	return nil
+}
+
+// findPermissions loads permissions by name, failing if any of them does not exist
+func (s *RoleService) findPermissions(tx *gorm.DB, names []string) ([]models.Permission, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var permissions []models.Permission
+	if err := tx.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Name] = true
+	}
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperrors.Validation("PERMISSION_NOT_FOUND", "Unknown permissions").
+			WithDetails(map[string]interface{}{"permissions": missing})
+	}
+
+	return permissions, nil
+}
+
+// replaceRolePermissions grants exactly the given permissions to a role
+func (s *RoleService) replaceRolePermissions(tx *gorm.DB, roleID uuid.UUID, permissions []models.Permission, grantedBy uuid.UUID) error {
+	var existing []models.RolePermission
+	if err := tx.Where("role_id = ?", roleID).Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(permissions))
+	for _, permission := range permissions {
+		wanted[permission.ID] = true
+	}
+
+	granted := make(map[uuid.UUID]bool, len(existing))
+	for _, grant := range existing {
+		if !wanted[grant.PermissionID] {
+			if err := tx.Delete(&models.RolePermission{}, "id = ?", grant.ID).Error; err != nil {
+				return fmt.Errorf("failed to revoke permission: %w", err)
+			}
+			continue
+		}
+		granted[grant.PermissionID] = true
+	}
+
+	for _, permission := range permissions {
+		if granted[permission.ID] {
+			continue
+		}
+		grant := models.RolePermission{RoleID: roleID, PermissionID: permission.ID, GrantedBy: grantedBy, GrantedAt: time.Now()}
+		if err := tx.Omit(clause.Associations).Create(&grant).Error; err != nil {
+			return fmt.Errorf("failed to grant permission %s: %w", permission.Name, err)
+		}
+		granted[permission.ID] = true
+	}
+
+	return nil
+}
+
+// checkRoleQuota fails when a tenant already has its maximum number of roles
+func (s *RoleService) checkRoleQuota(tx *gorm.DB, tenantID uuid.UUID) error {
+	var tenant models.Tenant
+	if err := tx.Select("max_roles").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	var count int64
+	if err := tx.Model(&models.Role{}).Where("tenant_id = ?", tenantID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count roles: %w", err)
+	}
+	if tenant.MaxRoles > 0 && count >= int64(tenant.MaxRoles) {
+		return apperrors.Conflict("ROLE_LIMIT_REACHED", fmt.Sprintf("Tenant has reached its limit of %d roles", tenant.MaxRoles))
+	}
+	return nil
+}
+
+func (s *RoleService) toRoleResponse(ctx context.Context, db *gorm.DB, role *models.Role) (*RoleResponse, error) {
+	var permissions []string
+	if err := db.WithContext(ctx).
+		Model(&models.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Where("role_permissions.role_id = ?", role.ID).
+		Pluck("permissions.name", &permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	sort.Strings(permissions)
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	parentRole := ""
+	if role.ParentRoleID != nil {
+		var parent models.Role
+		if err := db.WithContext(ctx).Select("name").First(&parent, "id = ?", *role.ParentRoleID).Error; err == nil {
+			parentRole = parent.Name
+		}
+	}
+
+	return &RoleResponse{
+		ID:          role.ID.String(),
+		TenantID:    role.TenantID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		ParentRole:  parentRole,
+		Permissions: permissions,
+		IsSystem:    role.IsSystem,
+		CreatedAt:   role.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   role.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ETag:        ResourceETag(role.UpdatedAt),
+	}, nil
+}
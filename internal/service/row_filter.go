@@ -0,0 +1,133 @@
+package service
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"gorm.io/gorm"
+)
+
+// rowFilterColumn maps a field of row filter conditions to an SQL expression
+// of a table, reporting whether it reads text from a JSON column. ok is false
+// for fields without a column.
+type rowFilterColumn func(field string) (expression string, jsonField bool, ok bool)
+
+// rowFilterOperators are the SQL operators of row filter conditions
+var rowFilterOperators = map[string]string{
+	opa.OperatorEqual:        "=",
+	opa.OperatorNotEqual:     "<>",
+	opa.OperatorLess:         "<",
+	opa.OperatorLessEqual:    "<=",
+	opa.OperatorGreater:      ">",
+	opa.OperatorGreaterEqual: ">=",
+	opa.OperatorIn:           "IN",
+}
+
+// attributeName matches the attribute names row filters may read from JSON
+// columns, which are written into the query
+var attributeName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// applyRowFilter restricts a query to the rows a row filter admits. Queries
+// of the filter with a condition the table cannot evaluate, e.g. on a field
+// without a column, are left out, so the filter never admits more rows than
+// the policy; without any query left, no row is admitted.
+func applyRowFilter(query *gorm.DB, filter *opa.RowFilter, column rowFilterColumn) *gorm.DB {
+	if filter == nil || filter.Unrestricted {
+		return query
+	}
+
+	var clauses []string
+	var args []interface{}
+	for _, conditions := range filter.Queries {
+		clause, clauseArgs, ok := rowFilterClause(conditions, column)
+		if !ok {
+			continue
+		}
+		clauses = append(clauses, clause)
+		args = append(args, clauseArgs...)
+	}
+	if len(clauses) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where("("+strings.Join(clauses, " OR ")+")", args...)
+}
+
+// rowFilterClause returns the SQL conjunction of the conditions of a query
+func rowFilterClause(conditions []opa.Condition, column rowFilterColumn) (string, []interface{}, bool) {
+	parts := make([]string, 0, len(conditions))
+	args := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		expression, jsonField, ok := column(condition.Field)
+		operator, known := rowFilterOperators[condition.Operator]
+		if !ok || !known {
+			log.Printf("Dropping a query of the row filter: no column for %s %s", condition.Field, condition.Operator)
+			return "", nil, false
+		}
+		// JSON fields are compared as text, which only matches strings
+		if jsonField && !textCondition(condition) {
+			log.Printf("Dropping a query of the row filter: %s cannot be compared with %v", condition.Field, condition.Value)
+			return "", nil, false
+		}
+		parts = append(parts, expression+" "+operator+" ?")
+		args = append(args, condition.Value)
+	}
+	return "(" + strings.Join(parts, " AND ") + ")", args, true
+}
+
+// textCondition reports whether a condition tests the equality of a field
+// with strings only
+func textCondition(condition opa.Condition) bool {
+	switch condition.Operator {
+	case opa.OperatorEqual, opa.OperatorNotEqual:
+		_, ok := condition.Value.(string)
+		return ok
+	case opa.OperatorIn:
+		values, _ := condition.Value.([]interface{})
+		for _, value := range values {
+			if _, ok := value.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// userRowFilterColumn maps the fields of users' row filters to columns. Users
+// own their accounts, and attributes other than status are read from their
+// metadata, which cannot be matched while it is encrypted.
+func userRowFilterColumn(db *gorm.DB) rowFilterColumn {
+	return func(field string) (string, bool, bool) {
+		switch field {
+		case "id", "ownerId":
+			return "id", false, true
+		case "attributes.status":
+			return "status", false, true
+		}
+		name, ok := strings.CutPrefix(field, "attributes.")
+		if !ok || !attributeName.MatchString(name) {
+			return "", false, false
+		}
+		return jsonText(db, "metadata", name), true, true
+	}
+}
+
+// policyRowFilterColumn maps the fields of policies' row filters to columns.
+// Policies are owned by their authors.
+func policyRowFilterColumn(field string) (string, bool, bool) {
+	switch field {
+	case "id":
+		return "id", false, true
+	case "ownerId":
+		return "created_by", false, true
+	case "attributes.path":
+		return "path", false, true
+	case "attributes.status":
+		return "status", false, true
+	case "attributes.isSystem":
+		return "is_system", false, true
+	}
+	return "", false, false
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestApplyRowFilter(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.com")
+		bob := testutil.CreateTestUser(t, db, acme, "bob@acme.com")
+		carol := testutil.CreateTestUser(t, db, acme, "carol@acme.com")
+		db.Model(&models.User{}).Where("id = ?", alice.ID).Update("metadata", `{"department":"sales"}`)
+		db.Model(&models.User{}).Where("id = ?", bob.ID).Update("metadata", `{"department":"engineering"}`)
+
+		defaults := func(key string, defaultValue ...string) string {
+			if len(defaultValue) > 0 {
+				return defaultValue[0]
+			}
+			return ""
+		}
+		userParams, err := pagination.Parse(defaults, UserListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+
+		userService := NewUserService(db, nil)
+		listUsers := func(filter *opa.RowFilter) []string {
+			t.Helper()
+			users, _, err := userService.ListUsers(ctx, acme.ID.String(), &UserSearch{RowFilter: filter}, userParams)
+			if err != nil {
+				t.Fatalf("ListUsers returned error: %v", err)
+			}
+			emails := make([]string, len(users))
+			for i, user := range users {
+				emails[i] = user.Email
+			}
+			sort.Strings(emails)
+			return emails
+		}
+
+		tests := []struct {
+			name   string
+			filter *opa.RowFilter
+			want   []string
+		}{
+			{"no filter", nil, []string{"alice@acme.com", "bob@acme.com", "carol@acme.com"}},
+			{"unrestricted", &opa.RowFilter{Unrestricted: true}, []string{"alice@acme.com", "bob@acme.com", "carol@acme.com"}},
+			{"no queries", &opa.RowFilter{}, []string{}},
+			{"metadata attribute", &opa.RowFilter{Queries: [][]opa.Condition{
+				{{Field: "attributes.department", Operator: opa.OperatorEqual, Value: "sales"}},
+			}}, []string{"alice@acme.com"}},
+			{"any query", &opa.RowFilter{Queries: [][]opa.Condition{
+				{{Field: "attributes.department", Operator: opa.OperatorIn, Value: []interface{}{"engineering", "legal"}}},
+				{{Field: "ownerId", Operator: opa.OperatorEqual, Value: carol.ID.String()}},
+			}}, []string{"bob@acme.com", "carol@acme.com"}},
+			// Queries the table cannot evaluate admit no rows rather than all
+			{"numeric metadata comparison", &opa.RowFilter{Queries: [][]opa.Condition{
+				{{Field: "attributes.level", Operator: opa.OperatorLess, Value: int64(3)}},
+			}}, []string{}},
+			{"unsafe attribute name", &opa.RowFilter{Queries: [][]opa.Condition{
+				{{Field: "attributes.x') OR ('1", Operator: opa.OperatorEqual, Value: "1"}},
+			}}, []string{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := listUsers(tt.filter); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Got users %v, want %v", got, tt.want)
+				}
+			})
+		}
+
+		// Policies are owned by their authors
+		for _, author := range []*models.User{alice, bob} {
+			policy := &models.Policy{TenantID: acme.ID, Name: author.Email, Path: "acme/" + author.ID.String(), Content: "package authz", CreatedBy: author.ID}
+			if err := db.Create(policy).Error; err != nil {
+				t.Fatalf("Failed to create policy: %v", err)
+			}
+		}
+		policyParams, err := pagination.Parse(defaults, PolicyListOptions)
+		if err != nil {
+			t.Fatalf("Failed to parse pagination: %v", err)
+		}
+		policies, page, err := NewPolicyService(db, nil).ListPolicies(ctx, acme.ID, &opa.RowFilter{Queries: [][]opa.Condition{
+			{{Field: "ownerId", Operator: opa.OperatorEqual, Value: bob.ID.String()}},
+		}}, policyParams)
+		if err != nil {
+			t.Fatalf("ListPolicies returned error: %v", err)
+		}
+		if len(policies) != 1 || policies[0].CreatedBy != bob.ID || page.Total != 1 {
+			t.Errorf("Expected only bob's policy, got %+v", policies)
+		}
+	})
+}
@@ -0,0 +1,550 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/google/uuid"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SAMLService makes Heimdall a SAML 2.0 service provider. Each tenant has its own
+// service provider, under /v1/saml/{tenantId}, trusting the tenant's identity provider.
+type SAMLService struct {
+	db    *gorm.DB
+	redis *database.RedisClient
+	cfg   *config.SAMLConfig
+
+	rbacSync *RBACDataSync
+
+	// Service provider key pair, nil when not configured
+	key         crypto.Signer
+	certificate *x509.Certificate
+}
+
+// NewSAMLService creates a new SAML service, loading the service provider key
+// pair when configured
+func NewSAMLService(db *gorm.DB, redis *database.RedisClient, cfg *config.SAMLConfig) (*SAMLService, error) {
+	s := &SAMLService{db: db, redis: redis, cfg: cfg}
+	if cfg.CertificatePath == "" || cfg.PrivateKeyPath == "" {
+		return s, nil
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(cfg.CertificatePath, cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SAML key pair: %w", err)
+	}
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported SAML private key type %T", keyPair.PrivateKey)
+	}
+	certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SAML certificate: %w", err)
+	}
+	s.key = key
+	s.certificate = certificate
+	return s, nil
+}
+
+// SetRBACDataSync pushes role changes made by SAML logins to OPA
+func (s *SAMLService) SetRBACDataSync(rbacSync *RBACDataSync) {
+	s.rbacSync = rbacSync
+}
+
+// SAMLRoleMapping grants a tenant role to users whose role attribute has a value
+type SAMLRoleMapping struct {
+	Value string `json:"value" validate:"required" example:"Heimdall Admins"`
+	Role  string `json:"role" validate:"required,max=100" example:"admin"`
+}
+
+// UpsertSAMLConfigRequest represents the desired SAML configuration of a tenant
+type UpsertSAMLConfigRequest struct {
+	Enabled            *bool             `json:"enabled,omitempty" example:"true"` // Defaults to true
+	IdPMetadata        string            `json:"idpMetadata" validate:"required" example:"<EntityDescriptor ...>"`
+	EmailAttribute     string            `json:"emailAttribute,omitempty" validate:"max=255" example:"email"` // NameID when empty
+	FirstNameAttribute string            `json:"firstNameAttribute,omitempty" validate:"max=255" example:"firstName"`
+	LastNameAttribute  string            `json:"lastNameAttribute,omitempty" validate:"max=255" example:"lastName"`
+	RoleAttribute      string            `json:"roleAttribute,omitempty" validate:"max=255" example:"groups"`
+	RoleMappings       []SAMLRoleMapping `json:"roleMappings,omitempty" validate:"dive"`
+	AllowIdPInitiated  bool              `json:"allowIdpInitiated" example:"false"`
+}
+
+// SAMLConfigResponse represents a tenant's SAML configuration with the service
+// provider URLs to register with the identity provider
+type SAMLConfigResponse struct {
+	TenantID           string            `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Enabled            bool              `json:"enabled" example:"true"`
+	IdPEntityID        string            `json:"idpEntityId" example:"https://idp.example.com/metadata"`
+	EmailAttribute     string            `json:"emailAttribute,omitempty" example:"email"`
+	FirstNameAttribute string            `json:"firstNameAttribute,omitempty" example:"firstName"`
+	LastNameAttribute  string            `json:"lastNameAttribute,omitempty" example:"lastName"`
+	RoleAttribute      string            `json:"roleAttribute,omitempty" example:"groups"`
+	RoleMappings       []SAMLRoleMapping `json:"roleMappings"`
+	AllowIdPInitiated  bool              `json:"allowIdpInitiated" example:"false"`
+	EntityID           string            `json:"entityId" example:"https://heimdall.example.com/v1/saml/550e8400-e29b-41d4-a716-446655440000/metadata"`
+	ACSURL             string            `json:"acsUrl" example:"https://heimdall.example.com/v1/saml/550e8400-e29b-41d4-a716-446655440000/acs"`
+	CreatedAt          string            `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt          string            `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+	ETag               string            `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// GetConfig retrieves a tenant's SAML configuration
+func (s *SAMLService) GetConfig(ctx context.Context, tenantID uuid.UUID) (*SAMLConfigResponse, error) {
+	samlConfig, err := s.findConfig(readReplica(s.db).WithContext(ctx), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toConfigResponse(samlConfig)
+}
+
+// UpsertConfig creates or replaces a tenant's SAML configuration after validating
+// the identity provider metadata. It reports whether the configuration was created.
+func (s *SAMLService) UpsertConfig(ctx context.Context, tenantID uuid.UUID, req *UpsertSAMLConfigRequest, pre Precondition) (*SAMLConfigResponse, bool, error) {
+	idpMetadata, err := parseIdPMetadata(req.IdPMetadata)
+	if err != nil {
+		return nil, false, apperrors.Validation("INVALID_IDP_METADATA", "Invalid identity provider metadata").WithCause(err)
+	}
+	if len(req.RoleMappings) > 0 && req.RoleAttribute == "" {
+		return nil, false, apperrors.Validation("ROLE_ATTRIBUTE_REQUIRED", "Role mappings require a role attribute")
+	}
+	roleMappings, err := json.Marshal(req.RoleMappings)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal role mappings: %w", err)
+	}
+
+	var response *SAMLConfigResponse
+	created := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Select("id").First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		var samlConfig models.TenantSAMLConfig
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&samlConfig, "tenant_id = ?", tenantID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get SAML configuration: %w", err)
+		}
+		created = err != nil
+
+		current := ""
+		if !created {
+			current = ResourceETag(samlConfig.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+
+		samlConfig.TenantID = tenantID
+		samlConfig.Enabled = req.Enabled == nil || *req.Enabled
+		samlConfig.IdPMetadata = req.IdPMetadata
+		samlConfig.IdPEntityID = idpMetadata.EntityID
+		samlConfig.EmailAttribute = req.EmailAttribute
+		samlConfig.FirstNameAttribute = req.FirstNameAttribute
+		samlConfig.LastNameAttribute = req.LastNameAttribute
+		samlConfig.RoleAttribute = req.RoleAttribute
+		samlConfig.RoleMappings = roleMappings
+		samlConfig.AllowIdPInitiated = req.AllowIdPInitiated
+
+		if created {
+			err = tx.Create(&samlConfig).Error
+		} else {
+			err = tx.Save(&samlConfig).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save SAML configuration: %w", err)
+		}
+		response, err = s.toConfigResponse(&samlConfig)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, created, nil
+}
+
+// DeleteConfig removes a tenant's SAML configuration, disabling SAML logins
+func (s *SAMLService) DeleteConfig(ctx context.Context, tenantID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&models.TenantSAMLConfig{}, "tenant_id = ?", tenantID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete SAML configuration: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound("SAML_CONFIG_NOT_FOUND", "SAML configuration not found")
+	}
+	return nil
+}
+
+// Metadata returns the service provider metadata of a tenant, for upload to its
+// identity provider
+func (s *SAMLService) Metadata(ctx context.Context, tenantID uuid.UUID) ([]byte, error) {
+	var tenant models.Tenant
+	if err := readReplica(s.db).WithContext(ctx).Select("id").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	sp, err := s.serviceProvider(tenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SAML metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// LoginRedirect starts a login at the tenant's identity provider. It returns the
+// URL to redirect the browser to and the ID of the authentication request, which
+// the response must answer.
+func (s *SAMLService) LoginRedirect(ctx context.Context, tenantID uuid.UUID, redirectURL string) (string, string, error) {
+	if redirectURL != "" && !s.AllowedRedirect(redirectURL) {
+		return "", "", apperrors.Validation("INVALID_REDIRECT_URL", "Redirect URL is not allowed")
+	}
+
+	samlConfig, err := s.findEnabledConfig(readReplica(s.db).WithContext(ctx), tenantID)
+	if err != nil {
+		return "", "", err
+	}
+	sp, err := s.serviceProvider(tenantID, samlConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	request, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create SAML authentication request: %w", err)
+	}
+	loginURL, err := request.Redirect(redirectURL, sp)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create SAML redirect: %w", err)
+	}
+	return loginURL.String(), request.ID, nil
+}
+
+// ConsumeResponse validates a SAML response posted to the tenant's assertion
+// consumer service and returns the local user, provisioning it on first login
+// and syncing its mapped roles. requestID is the ID of the authentication request
+// started by LoginRedirect, or empty for identity provider initiated logins.
+func (s *SAMLService) ConsumeResponse(ctx context.Context, tenantID uuid.UUID, samlResponse, requestID string) (*auth.IdentityUser, error) {
+	samlConfig, err := s.findEnabledConfig(s.db.WithContext(ctx), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := s.serviceProvider(tenantID, samlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_SAML_RESPONSE", "Invalid SAML response").WithCause(err)
+	}
+	var requestIDs []string
+	if requestID != "" {
+		requestIDs = []string{requestID}
+	}
+	assertion, err := sp.ParseXMLResponse(decoded, requestIDs, sp.AcsURL)
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) {
+			err = invalid.PrivateErr
+		}
+		return nil, apperrors.Unauthorized("INVALID_SAML_RESPONSE", "SAML response could not be validated").WithCause(err)
+	}
+
+	if err := s.claimAssertion(ctx, assertion); err != nil {
+		return nil, err
+	}
+
+	email := assertionNameID(assertion)
+	if samlConfig.EmailAttribute != "" {
+		email = firstAttributeValue(assertion, samlConfig.EmailAttribute)
+	}
+	if email == "" {
+		return nil, apperrors.Unauthorized("SAML_EMAIL_MISSING", "SAML assertion does not contain an email")
+	}
+	firstName := firstAttributeValue(assertion, samlConfig.FirstNameAttribute)
+	lastName := firstAttributeValue(assertion, samlConfig.LastNameAttribute)
+
+	var user *models.User
+	var rolesChanged bool
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = s.provision(tx, tenantID, email, firstName, lastName); err != nil {
+			return err
+		}
+		rolesChanged, err = s.syncRoles(tx, user, samlConfig, assertion)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rolesChanged {
+		s.rbacSync.TenantChanged(ctx, tenantID)
+	}
+
+	return &auth.IdentityUser{
+		ID:        user.ID.String(),
+		Email:     user.Email,
+		FirstName: firstName,
+		LastName:  lastName,
+		Active:    true,
+		Verified:  true,
+	}, nil
+}
+
+// AllowedRedirect reports whether a login may redirect to a URL with tokens
+func (s *SAMLService) AllowedRedirect(redirectURL string) bool {
+	return utils.RedirectAllowed(redirectURL, s.cfg.AllowedRedirectURLs)
+}
+
+// claimAssertion rejects assertions that were already used, until they expire.
+// Replays are only detected when Redis is available.
+func (s *SAMLService) claimAssertion(ctx context.Context, assertion *saml.Assertion) error {
+	if s.redis == nil {
+		return nil
+	}
+	ttl := 5 * time.Minute
+	if assertion.Conditions != nil && !assertion.Conditions.NotOnOrAfter.IsZero() {
+		ttl = time.Until(assertion.Conditions.NotOnOrAfter) + time.Minute
+	}
+	claimed, err := s.redis.SetNX(ctx, "saml:assertion:"+assertion.ID, "1", ttl)
+	if err != nil {
+		return fmt.Errorf("failed to record SAML assertion: %w", err)
+	}
+	if !claimed {
+		return apperrors.Unauthorized("SAML_ASSERTION_REPLAYED", "SAML assertion was already used")
+	}
+	return nil
+}
+
+// provision returns the tenant's user with the given email, creating it on first login
+func (s *SAMLService) provision(tx *gorm.DB, tenantID uuid.UUID, email, firstName, lastName string) (*models.User, error) {
+	var tenant models.Tenant
+	if err := tx.Where("id = ? AND status = ?", tenantID, "active").First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found or inactive")
+		}
+		return nil, fmt.Errorf("failed to verify tenant: %w", err)
+	}
+
+	var user models.User
+	err := tx.Where("tenant_id = ? AND LOWER(email) = ?", tenantID, strings.ToLower(email)).First(&user).Error
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	metadata, err := json.Marshal(map[string]interface{}{
+		"firstName": firstName,
+		"lastName":  lastName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	user = models.User{
+		ID:       uuid.New(),
+		TenantID: tenantID,
+		Email:    email,
+		Metadata: metadata,
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to create user record: %w", err)
+	}
+	return &user, nil
+}
+
+// syncRoles applies the role mappings for the values of the role attribute. It
+// reports whether the user's roles changed.
+func (s *SAMLService) syncRoles(tx *gorm.DB, user *models.User, samlConfig *models.TenantSAMLConfig, assertion *saml.Assertion) (bool, error) {
+	if samlConfig.RoleAttribute == "" {
+		return false, nil
+	}
+	var mappings []SAMLRoleMapping
+	if len(samlConfig.RoleMappings) > 0 {
+		if err := json.Unmarshal(samlConfig.RoleMappings, &mappings); err != nil {
+			return false, fmt.Errorf("failed to unmarshal role mappings: %w", err)
+		}
+	}
+
+	values := map[string]bool{}
+	for _, value := range attributeValues(assertion, samlConfig.RoleAttribute) {
+		values[value] = true
+	}
+	managed := make([]string, 0, len(mappings))
+	desired := map[string]bool{}
+	for _, mapping := range mappings {
+		managed = append(managed, mapping.Role)
+		if values[mapping.Value] {
+			desired[mapping.Role] = true
+		}
+	}
+	return syncMappedRoles(tx, user.ID, user.TenantID, managed, desired)
+}
+
+// serviceProvider builds the tenant's service provider. samlConfig may be nil
+// when only the service provider metadata is needed.
+func (s *SAMLService) serviceProvider(tenantID uuid.UUID, samlConfig *models.TenantSAMLConfig) (*saml.ServiceProvider, error) {
+	base := fmt.Sprintf("%s/v1/saml/%s", s.cfg.BaseURL, tenantID)
+	metadataURL, err := url.Parse(base + "/metadata")
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAML base URL: %w", err)
+	}
+	acsURL, err := url.Parse(base + "/acs")
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAML base URL: %w", err)
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID:          metadataURL.String(),
+		Key:               s.key,
+		Certificate:       s.certificate,
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		AuthnNameIDFormat: saml.EmailAddressNameIDFormat,
+	}
+	if _, ok := s.key.(*rsa.PrivateKey); ok {
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	}
+
+	if samlConfig != nil {
+		idpMetadata, err := parseIdPMetadata(samlConfig.IdPMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored identity provider metadata: %w", err)
+		}
+		sp.IDPMetadata = idpMetadata
+		sp.AllowIDPInitiated = samlConfig.AllowIdPInitiated
+	}
+	return sp, nil
+}
+
+func (s *SAMLService) findConfig(db *gorm.DB, tenantID uuid.UUID) (*models.TenantSAMLConfig, error) {
+	var samlConfig models.TenantSAMLConfig
+	if err := db.First(&samlConfig, "tenant_id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("SAML_CONFIG_NOT_FOUND", "SAML configuration not found")
+		}
+		return nil, fmt.Errorf("failed to get SAML configuration: %w", err)
+	}
+	return &samlConfig, nil
+}
+
+func (s *SAMLService) findEnabledConfig(db *gorm.DB, tenantID uuid.UUID) (*models.TenantSAMLConfig, error) {
+	samlConfig, err := s.findConfig(db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !samlConfig.Enabled {
+		return nil, apperrors.Forbidden("SAML_DISABLED", "SAML login is disabled for this tenant")
+	}
+	return samlConfig, nil
+}
+
+func (s *SAMLService) toConfigResponse(samlConfig *models.TenantSAMLConfig) (*SAMLConfigResponse, error) {
+	sp, err := s.serviceProvider(samlConfig.TenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	mappings := []SAMLRoleMapping{}
+	if len(samlConfig.RoleMappings) > 0 {
+		if err := json.Unmarshal(samlConfig.RoleMappings, &mappings); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal role mappings: %w", err)
+		}
+	}
+
+	return &SAMLConfigResponse{
+		TenantID:           samlConfig.TenantID.String(),
+		Enabled:            samlConfig.Enabled,
+		IdPEntityID:        samlConfig.IdPEntityID,
+		EmailAttribute:     samlConfig.EmailAttribute,
+		FirstNameAttribute: samlConfig.FirstNameAttribute,
+		LastNameAttribute:  samlConfig.LastNameAttribute,
+		RoleAttribute:      samlConfig.RoleAttribute,
+		RoleMappings:       mappings,
+		AllowIdPInitiated:  samlConfig.AllowIdPInitiated,
+		EntityID:           sp.EntityID,
+		ACSURL:             sp.AcsURL.String(),
+		CreatedAt:          samlConfig.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          samlConfig.UpdatedAt.Format(time.RFC3339),
+		ETag:               ResourceETag(samlConfig.UpdatedAt),
+	}, nil
+}
+
+// parseIdPMetadata parses identity provider metadata, which must describe an
+// identity provider with a single sign-on service
+func parseIdPMetadata(metadata string) (*saml.EntityDescriptor, error) {
+	descriptor, err := samlsp.ParseMetadata([]byte(metadata))
+	if err != nil {
+		return nil, err
+	}
+	if len(descriptor.IDPSSODescriptors) == 0 || len(descriptor.IDPSSODescriptors[0].SingleSignOnServices) == 0 {
+		return nil, errors.New("metadata does not describe an identity provider with a single sign-on service")
+	}
+	return descriptor, nil
+}
+
+func assertionNameID(assertion *saml.Assertion) string {
+	if assertion.Subject == nil || assertion.Subject.NameID == nil {
+		return ""
+	}
+	return strings.TrimSpace(assertion.Subject.NameID.Value)
+}
+
+// attributeValues returns the values of an assertion attribute, matched by name
+// or friendly name
+func attributeValues(assertion *saml.Assertion, name string) []string {
+	if name == "" {
+		return nil
+	}
+	var values []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			if attribute.Name != name && attribute.FriendlyName != name {
+				continue
+			}
+			for _, value := range attribute.Values {
+				values = append(values, strings.TrimSpace(value.Value))
+			}
+		}
+	}
+	return values
+}
+
+func firstAttributeValue(assertion *saml.Assertion, name string) string {
+	if values := attributeValues(assertion, name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// newTestIdP creates an identity provider with a self-signed certificate
+func newTestIdP(t *testing.T) *saml.IdentityProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate IdP key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create IdP certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse IdP certificate: %v", err)
+	}
+
+	metadataURL, _ := url.Parse("https://idp.example.com/metadata")
+	ssoURL, _ := url.Parse("https://idp.example.com/sso")
+	return &saml.IdentityProvider{
+		Key:         key,
+		Certificate: certificate,
+		MetadataURL: *metadataURL,
+		SSOURL:      *ssoURL,
+	}
+}
+
+func idpMetadataXML(t *testing.T, idp *saml.IdentityProvider) string {
+	t.Helper()
+	metadata, err := xml.Marshal(idp.Metadata())
+	if err != nil {
+		t.Fatalf("Failed to marshal IdP metadata: %v", err)
+	}
+	return string(metadata)
+}
+
+// signedResponse has the identity provider answer requestID for a session,
+// returning the base64-encoded response the browser would post
+func signedResponse(t *testing.T, idp *saml.IdentityProvider, spMetadata []byte, requestID string, session *saml.Session) string {
+	t.Helper()
+
+	descriptor, err := samlsp.ParseMetadata(spMetadata)
+	if err != nil {
+		t.Fatalf("Failed to parse SP metadata: %v", err)
+	}
+	req := &saml.IdpAuthnRequest{
+		IDP:                     idp,
+		HTTPRequest:             httptest.NewRequest("POST", idp.SSOURL.String(), nil),
+		Now:                     time.Now(),
+		Request:                 saml.AuthnRequest{ID: requestID, IssueInstant: time.Now()},
+		ServiceProviderMetadata: descriptor,
+		SPSSODescriptor:         &descriptor.SPSSODescriptors[0],
+		ACSEndpoint:             &descriptor.SPSSODescriptors[0].AssertionConsumerServices[0],
+	}
+	if err := (saml.DefaultAssertionMaker{}).MakeAssertion(req, session); err != nil {
+		t.Fatalf("Failed to make assertion: %v", err)
+	}
+	if err := req.MakeResponse(); err != nil {
+		t.Fatalf("Failed to make response: %v", err)
+	}
+
+	doc := etree.NewDocument()
+	doc.SetRoot(req.ResponseEl)
+	response, err := doc.WriteToBytes()
+	if err != nil {
+		t.Fatalf("Failed to serialize response: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(response)
+}
+
+func newTestSAMLService(t *testing.T, db *gorm.DB) *SAMLService {
+	t.Helper()
+	samlService, err := NewSAMLService(db, nil, &config.SAMLConfig{
+		BaseURL:             "https://heimdall.example.com",
+		AllowedRedirectURLs: []string{"https://app.example.com/"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create SAML service: %v", err)
+	}
+	return samlService
+}
+
+func TestSAMLService_UpsertConfig(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		samlService := newTestSAMLService(t, db)
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		ctx := testutil.CreateTestContext(t)
+		metadata := idpMetadataXML(t, newTestIdP(t))
+
+		_, _, err := samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{IdPMetadata: "<not-metadata/>"}, Precondition{})
+		if appErr := (*apperrors.Error)(nil); !errors.As(err, &appErr) || appErr.Code != "INVALID_IDP_METADATA" {
+			t.Errorf("Expected INVALID_IDP_METADATA, got %v", err)
+		}
+		_, _, err = samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{
+			IdPMetadata:  metadata,
+			RoleMappings: []SAMLRoleMapping{{Value: "staff", Role: "viewer"}},
+		}, Precondition{})
+		if appErr := (*apperrors.Error)(nil); !errors.As(err, &appErr) || appErr.Code != "ROLE_ATTRIBUTE_REQUIRED" {
+			t.Errorf("Expected ROLE_ATTRIBUTE_REQUIRED, got %v", err)
+		}
+
+		samlConfig, created, err := samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{IdPMetadata: metadata}, Precondition{})
+		if err != nil {
+			t.Fatalf("UpsertConfig returned error: %v", err)
+		}
+		if !created || !samlConfig.Enabled || samlConfig.IdPEntityID != "https://idp.example.com/metadata" {
+			t.Errorf("Unexpected created configuration (created=%v): %+v", created, samlConfig)
+		}
+		wantACS := "https://heimdall.example.com/v1/saml/" + tenant.ID.String() + "/acs"
+		if samlConfig.ACSURL != wantACS {
+			t.Errorf("ACSURL = %q, want %q", samlConfig.ACSURL, wantACS)
+		}
+
+		disabled := false
+		_, _, err = samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{IdPMetadata: metadata, Enabled: &disabled}, Precondition{IfMatch: `"stale"`})
+		if !errors.Is(err, apperrors.ErrPrecondition) {
+			t.Errorf("Expected a failed precondition for a stale ETag, got %v", err)
+		}
+		samlConfig, created, err = samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{IdPMetadata: metadata, Enabled: &disabled}, Precondition{IfMatch: samlConfig.ETag})
+		if err != nil {
+			t.Fatalf("UpsertConfig returned error: %v", err)
+		}
+		if created || samlConfig.Enabled {
+			t.Errorf("Expected the configuration to be replaced and disabled, got created=%v %+v", created, samlConfig)
+		}
+		if _, _, err := samlService.LoginRedirect(ctx, tenant.ID, ""); err == nil {
+			t.Error("Expected login to fail while SAML is disabled")
+		}
+
+		if err := samlService.DeleteConfig(ctx, tenant.ID); err != nil {
+			t.Fatalf("DeleteConfig returned error: %v", err)
+		}
+		if _, err := samlService.GetConfig(ctx, tenant.ID); err == nil {
+			t.Error("Expected a deleted configuration to be not found")
+		}
+
+		// A configuration created disabled stays disabled
+		if _, _, err := samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{IdPMetadata: metadata, Enabled: &disabled}, Precondition{}); err != nil {
+			t.Fatalf("UpsertConfig returned error: %v", err)
+		}
+		if samlConfig, err = samlService.GetConfig(ctx, tenant.ID); err != nil || samlConfig.Enabled {
+			t.Errorf("Expected a disabled configuration, got %+v, %v", samlConfig, err)
+		}
+	})
+}
+
+func TestSAMLService_ConsumeResponse(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		_, fusionAuth := newFakeFusionAuth(t)
+		authService := NewAuthService(db, fusionAuth, jwtService, nil, nil, nil)
+		samlService := newTestSAMLService(t, db)
+
+		tenant := testutil.CreateTestTenant(t, db, "Test Corp", "test-corp")
+		testutil.CreateTestRole(t, db, tenant, "admin")
+		testutil.CreateTestRole(t, db, tenant, "viewer")
+		manual := testutil.CreateTestRole(t, db, tenant, "manual")
+		ctx := testutil.CreateTestContext(t)
+
+		idp := newTestIdP(t)
+		_, _, err := samlService.UpsertConfig(ctx, tenant.ID, &UpsertSAMLConfigRequest{
+			IdPMetadata:        idpMetadataXML(t, idp),
+			EmailAttribute:     "mail",
+			FirstNameAttribute: "givenName",
+			RoleAttribute:      "eduPersonAffiliation",
+			RoleMappings: []SAMLRoleMapping{
+				{Value: "admins", Role: "admin"},
+				{Value: "staff", Role: "viewer"},
+			},
+		}, Precondition{})
+		if err != nil {
+			t.Fatalf("UpsertConfig returned error: %v", err)
+		}
+		spMetadata, err := samlService.Metadata(ctx, tenant.ID)
+		if err != nil {
+			t.Fatalf("Metadata returned error: %v", err)
+		}
+
+		for _, redirect := range []string{
+			"https://evil.example.com/",
+			"https://app.example.com.evil.io/",
+			"https://app.example.com@evil.io/",
+			"http://app.example.com/",
+			"https://app.example.com:8443/",
+		} {
+			if _, _, err := samlService.LoginRedirect(ctx, tenant.ID, redirect); err == nil {
+				t.Errorf("Expected the redirect URL %s to be rejected", redirect)
+			}
+			if samlService.AllowedRedirect(redirect) {
+				t.Errorf("Expected RelayState %s not to be redirected to", redirect)
+			}
+		}
+		loginURL, requestID, err := samlService.LoginRedirect(ctx, tenant.ID, "https://app.example.com/callback")
+		if err != nil {
+			t.Fatalf("LoginRedirect returned error: %v", err)
+		}
+		if !strings.HasPrefix(loginURL, "https://idp.example.com/sso?") || requestID == "" {
+			t.Errorf("Unexpected login redirect %q with request ID %q", loginURL, requestID)
+		}
+
+		session := &saml.Session{
+			ID:            uuid.NewString(),
+			NameID:        "alice",
+			UserEmail:     "Alice@Example.com",
+			UserGivenName: "Alice",
+			Groups:        []string{"admins"},
+		}
+		response := signedResponse(t, idp, spMetadata, requestID, session)
+
+		// Responses to other requests, and unsolicited ones, are rejected
+		if _, err := samlService.ConsumeResponse(ctx, tenant.ID, response, "id-other"); err == nil {
+			t.Error("Expected a response to another request to be rejected")
+		}
+		if _, err := samlService.ConsumeResponse(ctx, tenant.ID, response, ""); err == nil {
+			t.Error("Expected an unsolicited response to be rejected")
+		}
+
+		identityUser, err := samlService.ConsumeResponse(ctx, tenant.ID, response, requestID)
+		if err != nil {
+			t.Fatalf("ConsumeResponse returned error: %v", err)
+		}
+		if identityUser.Email != "Alice@Example.com" || identityUser.FirstName != "Alice" {
+			t.Errorf("Unexpected identity user: %+v", identityUser)
+		}
+		userID := uuid.MustParse(identityUser.ID)
+		if roles := roleNamesOf(t, db, userID); strings.Join(roles, ",") != "admin" {
+			t.Errorf("Expected the admin role from the assertion, got %v", roles)
+		}
+
+		resp, err := authService.LoginExternal(ctx, identityUser, &LoginRequest{Email: identityUser.Email})
+		if err != nil {
+			t.Fatalf("LoginExternal returned error: %v", err)
+		}
+		if resp.AccessToken == "" || resp.User.TenantID != tenant.ID.String() {
+			t.Errorf("Unexpected auth response: %+v", resp.User)
+		}
+
+		// Later logins reuse the user and only change mapped roles
+		testutil.AssignRoleToUser(t, db, &models.User{ID: userID}, manual)
+		session.Groups = []string{"staff"}
+		identityUser, err = samlService.ConsumeResponse(ctx, tenant.ID, signedResponse(t, idp, spMetadata, requestID, session), requestID)
+		if err != nil {
+			t.Fatalf("Second ConsumeResponse returned error: %v", err)
+		}
+		if identityUser.ID != userID.String() {
+			t.Errorf("Expected the same user on the second login, got %s", identityUser.ID)
+		}
+		if roles := roleNamesOf(t, db, userID); strings.Join(roles, ",") != "manual,viewer" {
+			t.Errorf("Expected roles manual,viewer, got %v", roles)
+		}
+
+		// Responses signed by another identity provider are rejected
+		if _, err := samlService.ConsumeResponse(ctx, tenant.ID, signedResponse(t, newTestIdP(t), spMetadata, requestID, session), requestID); err == nil {
+			t.Error("Expected a response signed by another identity provider to be rejected")
+		}
+	})
+}
@@ -0,0 +1,524 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const signingKeyBits = 2048
+
+// SigningKeyService manages tenant-scoped and shared platform JWT signing keys
+// and implements auth.KeyStore. Retired keys keep verifying the tokens they
+// signed until those have expired, after which they expire too.
+type SigningKeyService struct {
+	db         *gorm.DB
+	jwtService *auth.JWTService
+
+	mu             sync.RWMutex
+	activeByTenant map[string]cachedSigningKey
+	byKeyID        map[string]cachedSigningKey
+	cacheTTL       time.Duration
+}
+
+// cachedSigningKey is a resolved key (or a negative lookup) held in memory
+type cachedSigningKey struct {
+	key       *auth.SigningKey
+	err       error // auth.ErrSigningKeyRevoked or auth.ErrSigningKeyExpired
+	expiresAt time.Time
+}
+
+// NewSigningKeyService creates a new signing key service
+func NewSigningKeyService(db *gorm.DB, jwtService *auth.JWTService) *SigningKeyService {
+	return &SigningKeyService{
+		db:             db,
+		jwtService:     jwtService,
+		activeByTenant: make(map[string]cachedSigningKey),
+		byKeyID:        make(map[string]cachedSigningKey),
+		cacheTTL:       time.Minute,
+	}
+}
+
+// ActiveSigningKey returns the active signing key for a tenant, or nil if the tenant uses the shared key
+func (s *SigningKeyService) ActiveSigningKey(tenantID string) (*auth.SigningKey, error) {
+	s.mu.RLock()
+	cached, ok := s.activeByTenant[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.key, nil
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+
+	var record models.TenantSigningKey
+	err = s.db.Where("tenant_id = ? AND status = ?", tenantUUID, models.SigningKeyStatusActive).
+		Order("created_at DESC").
+		First(&record).Error
+
+	var key *auth.SigningKey
+	if err == nil {
+		key, err = toSigningKey(&record)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	s.mu.Lock()
+	s.activeByTenant[tenantID] = cachedSigningKey{key: key, expiresAt: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return key, nil
+}
+
+// VerificationKey returns the tenant or platform signing key with the given
+// key ID. Platform keys are found here when another instance rotated them
+// before this one reloaded its platform keys.
+func (s *SigningKeyService) VerificationKey(keyID string) (*auth.SigningKey, error) {
+	s.mu.RLock()
+	cached, ok := s.byKeyID[keyID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.key, cached.err
+	}
+
+	entry := cachedSigningKey{expiresAt: time.Now().Add(s.cacheTTL)}
+	var record models.TenantSigningKey
+	err := s.db.Where("key_id = ?", keyID).First(&record).Error
+	if err == nil {
+		entry.err = signingKeyStatusError(record.Status)
+		if entry.err == nil {
+			if entry.key, err = toSigningKey(&record); err != nil {
+				return nil, err
+			}
+		}
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		var platformRecord models.PlatformSigningKey
+		err = s.db.Where("key_id = ?", keyID).First(&platformRecord).Error
+		if err == nil {
+			entry.err = signingKeyStatusError(platformRecord.Status)
+			if entry.err == nil {
+				if entry.key, err = toPlatformSigningKey(&platformRecord); err != nil {
+					return nil, err
+				}
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load signing key: %w", err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+
+	s.mu.Lock()
+	s.byKeyID[keyID] = entry
+	s.mu.Unlock()
+
+	return entry.key, entry.err
+}
+
+// signingKeyStatusError returns the error of verifying with a key of a status
+func signingKeyStatusError(status models.SigningKeyStatus) error {
+	switch status {
+	case models.SigningKeyStatusRevoked:
+		return auth.ErrSigningKeyRevoked
+	case models.SigningKeyStatusExpired:
+		return auth.ErrSigningKeyExpired
+	}
+	return nil
+}
+
+// CreateSigningKey generates a new active signing key for a tenant, retiring the previous one.
+// Tokens signed with retired keys remain valid until they expire.
+func (s *SigningKeyService) CreateSigningKey(ctx context.Context, tenantID uuid.UUID) (*models.TenantSigningKey, error) {
+	if err := s.ensureTenantExists(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	privatePEM, publicPEM, err := auth.GenerateRSAKeyPEM(signingKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated public key: %w", err)
+	}
+
+	record := &models.TenantSigningKey{
+		TenantID:      tenantID,
+		KeyID:         auth.KeyThumbprint(publicKey),
+		Algorithm:     "RS256",
+		PublicKeyPEM:  publicPEM,
+		PrivateKeyPEM: privatePEM,
+		Status:        models.SigningKeyStatusActive,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.TenantSigningKey{}).
+			Where("tenant_id = ? AND status = ?", tenantID, models.SigningKeyStatusActive).
+			Updates(map[string]interface{}{
+				"status":     models.SigningKeyStatusRetired,
+				"retired_at": now,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to retire previous signing key: %w", err)
+		}
+
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to create signing key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateTenant(tenantID.String())
+	return record, nil
+}
+
+// ListSigningKeys lists all signing keys for a tenant, newest first
+func (s *SigningKeyService) ListSigningKeys(ctx context.Context, tenantID uuid.UUID) ([]models.TenantSigningKey, error) {
+	var keys []models.TenantSigningKey
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeSigningKey revokes a tenant signing key; tokens signed with it are rejected
+// as soon as each instance's key cache refreshes
+func (s *SigningKeyService) RevokeSigningKey(ctx context.Context, tenantID uuid.UUID, keyID string) error {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&models.TenantSigningKey{}).
+		Where("tenant_id = ? AND key_id = ? AND status <> ?", tenantID, keyID, models.SigningKeyStatusRevoked).
+		Updates(map[string]interface{}{
+			"status":     models.SigningKeyStatusRevoked,
+			"revoked_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke signing key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound("SIGNING_KEY_NOT_FOUND", "Signing key not found")
+	}
+
+	s.invalidateTenant(tenantID.String())
+	s.mu.Lock()
+	delete(s.byKeyID, keyID)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// GetTenantJWKS returns the public keys that may have signed a tenant's tokens.
+// The shared keys are included so tokens issued before migration keep verifying.
+func (s *SigningKeyService) GetTenantJWKS(ctx context.Context, tenantID uuid.UUID) (*auth.JWKS, error) {
+	if err := s.ensureTenantExists(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	var records []models.TenantSigningKey
+	if err := s.db.WithContext(ctx).
+		Where("tenant_id = ? AND status IN ?", tenantID, []models.SigningKeyStatus{models.SigningKeyStatusActive, models.SigningKeyStatusRetired}).
+		Order("created_at DESC").
+		Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	jwks := &auth.JWKS{Keys: make([]auth.JWK, 0, len(records)+1)}
+	for i := range records {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(records[i].PublicKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %w", records[i].KeyID, err)
+		}
+		jwks.Keys = append(jwks.Keys, auth.NewJWK(records[i].KeyID, publicKey))
+	}
+	jwks.Keys = append(jwks.Keys, s.jwtService.SharedJWKS().Keys...)
+
+	return jwks, nil
+}
+
+// MigrateFromSharedKey moves a tenant off the shared platform key by issuing its first tenant key
+func (s *SigningKeyService) MigrateFromSharedKey(ctx context.Context, tenantID uuid.UUID) (*models.TenantSigningKey, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.TenantSigningKey{}).
+		Where("tenant_id = ? AND status <> ?", tenantID, models.SigningKeyStatusRevoked).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing signing keys: %w", err)
+	}
+	if count > 0 {
+		return nil, apperrors.Conflict("SIGNING_KEYS_EXIST", "Tenant already uses tenant-scoped signing keys")
+	}
+
+	return s.CreateSigningKey(ctx, tenantID)
+}
+
+// MigrateAllTenants issues tenant keys for every active tenant still on the shared key
+func (s *SigningKeyService) MigrateAllTenants(ctx context.Context) (int, error) {
+	var tenantIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.Tenant{}).
+		Where("status = ?", "active").
+		Where("id NOT IN (?)", s.db.Model(&models.TenantSigningKey{}).
+			Select("tenant_id").
+			Where("status <> ?", models.SigningKeyStatusRevoked)).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find tenants to migrate: %w", err)
+	}
+
+	for i, tenantID := range tenantIDs {
+		if _, err := s.CreateSigningKey(ctx, tenantID); err != nil {
+			return i, fmt.Errorf("failed to migrate tenant %s: %w", tenantID, err)
+		}
+	}
+
+	return len(tenantIDs), nil
+}
+
+// ListPlatformKeys lists the shared platform signing keys, newest first. It is
+// empty until the platform key is first rotated.
+func (s *SigningKeyService) ListPlatformKeys(ctx context.Context) ([]models.PlatformSigningKey, error) {
+	var keys []models.PlatformSigningKey
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list platform signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RotatePlatformKey generates a new primary platform signing key, retiring the
+// current one. The first rotation records the key read from files as retired.
+// Other instances sign with the new key once they reload the platform keys, and
+// verify its tokens right away.
+func (s *SigningKeyService) RotatePlatformKey(ctx context.Context) (*models.PlatformSigningKey, error) {
+	privatePEM, publicPEM, err := auth.GenerateRSAKeyPEM(signingKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse generated public key: %w", err)
+	}
+
+	record := &models.PlatformSigningKey{
+		KeyID:         auth.KeyThumbprint(publicKey),
+		Algorithm:     "RS256",
+		PublicKeyPEM:  publicPEM,
+		PrivateKeyPEM: privatePEM,
+		Status:        models.SigningKeyStatusActive,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var active int64
+		if err := tx.Model(&models.PlatformSigningKey{}).
+			Where("status = ?", models.SigningKeyStatusActive).
+			Count(&active).Error; err != nil {
+			return fmt.Errorf("failed to find the primary platform signing key: %w", err)
+		}
+		if active == 0 {
+			if err := s.retireFileKey(tx, now); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.PlatformSigningKey{}).
+			Where("status = ?", models.SigningKeyStatusActive).
+			Updates(map[string]interface{}{
+				"status":     models.SigningKeyStatusRetired,
+				"retired_at": now,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to retire previous platform signing key: %w", err)
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to create platform signing key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.LoadPlatformKeys(ctx); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// retireFileKey records the platform key read from files as retired, unless
+// it was recorded before
+func (s *SigningKeyService) retireFileKey(tx *gorm.DB, now time.Time) error {
+	fileKey := s.jwtService.FileSigningKey()
+	publicPEM, err := auth.EncodePublicKeyPEM(fileKey.PublicKey)
+	if err != nil {
+		return err
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PlatformSigningKey{
+		KeyID:        fileKey.KeyID,
+		Algorithm:    "RS256",
+		PublicKeyPEM: publicPEM,
+		Status:       models.SigningKeyStatusRetired,
+		RetiredAt:    &now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record the platform signing key read from files: %w", err)
+	}
+	return nil
+}
+
+// LoadPlatformKeys applies the stored platform keys to the JWT service. The
+// key read from files stays primary until the first rotation.
+func (s *SigningKeyService) LoadPlatformKeys(ctx context.Context) error {
+	var records []models.PlatformSigningKey
+	if err := s.db.WithContext(ctx).
+		Where("status IN ?", []models.SigningKeyStatus{models.SigningKeyStatusActive, models.SigningKeyStatusRetired}).
+		Order("created_at DESC").
+		Find(&records).Error; err != nil {
+		return fmt.Errorf("failed to load platform signing keys: %w", err)
+	}
+
+	var primary *auth.SigningKey
+	verification := make([]*auth.SigningKey, 0, len(records))
+	for i := range records {
+		key, err := toPlatformSigningKey(&records[i])
+		if err != nil {
+			return err
+		}
+		if records[i].Status == models.SigningKeyStatusActive && primary == nil && key.PrivateKey != nil {
+			primary = key
+		}
+		verification = append(verification, key)
+	}
+	if primary == nil {
+		primary = s.jwtService.FileSigningKey()
+	}
+
+	s.jwtService.SetPlatformKeys(primary, verification)
+	return nil
+}
+
+// ExpireRetiredKeys expires the tenant and platform keys retired for longer
+// than the longest-lived tokens, so they no longer verify. It returns the
+// number of keys expired.
+func (s *SigningKeyService) ExpireRetiredKeys(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.Add(-s.jwtService.MaxTokenLifetime())
+	expire := map[string]interface{}{"status": models.SigningKeyStatusExpired}
+
+	tenantKeys := s.db.WithContext(ctx).Model(&models.TenantSigningKey{}).
+		Where("status = ? AND retired_at < ?", models.SigningKeyStatusRetired, cutoff).
+		Updates(expire)
+	if tenantKeys.Error != nil {
+		return 0, fmt.Errorf("failed to expire tenant signing keys: %w", tenantKeys.Error)
+	}
+	platformKeys := s.db.WithContext(ctx).Model(&models.PlatformSigningKey{}).
+		Where("status = ? AND retired_at < ?", models.SigningKeyStatusRetired, cutoff).
+		Updates(expire)
+	if platformKeys.Error != nil {
+		return 0, fmt.Errorf("failed to expire platform signing keys: %w", platformKeys.Error)
+	}
+
+	expired := tenantKeys.RowsAffected + platformKeys.RowsAffected
+	if expired > 0 {
+		s.mu.Lock()
+		s.byKeyID = make(map[string]cachedSigningKey)
+		s.mu.Unlock()
+	}
+	return expired, nil
+}
+
+// Run expires retired keys and reloads the platform keys every interval until
+// ctx is cancelled, so rotations on other instances apply here
+func (s *SigningKeyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if expired, err := s.ExpireRetiredKeys(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to expire retired signing keys: %v", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d retired signing keys", expired)
+		}
+		if err := s.LoadPlatformKeys(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to reload platform signing keys: %v", err)
+		}
+	}
+}
+
+// ensureTenantExists verifies that a tenant exists
+func (s *SigningKeyService) ensureTenantExists(ctx context.Context, tenantID uuid.UUID) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("id = ?", tenantID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to verify tenant: %w", err)
+	}
+	if count == 0 {
+		return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+	}
+	return nil
+}
+
+// invalidateTenant drops the cached active key for a tenant
+func (s *SigningKeyService) invalidateTenant(tenantID string) {
+	s.mu.Lock()
+	delete(s.activeByTenant, tenantID)
+	s.mu.Unlock()
+}
+
+// toPlatformSigningKey parses a stored platform key, which has no private key
+// when it was read from files
+func toPlatformSigningKey(record *models.PlatformSigningKey) (*auth.SigningKey, error) {
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(record.PublicKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", record.KeyID, err)
+	}
+
+	key := &auth.SigningKey{KeyID: record.KeyID, PublicKey: publicKey}
+	if record.PrivateKeyPEM != "" {
+		if key.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(record.PrivateKeyPEM)); err != nil {
+			return nil, fmt.Errorf("failed to parse private key %s: %w", record.KeyID, err)
+		}
+	}
+	return key, nil
+}
+
+// toSigningKey parses a stored key pair
+func toSigningKey(record *models.TenantSigningKey) (*auth.SigningKey, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(record.PrivateKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", record.KeyID, err)
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(record.PublicKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", record.KeyID, err)
+	}
+
+	return &auth.SigningKey{
+		KeyID:      record.KeyID,
+		TenantID:   record.TenantID.String(),
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func tokenKeyID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &auth.TokenClaims{})
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	keyID, _ := parsed.Header["kid"].(string)
+	return keyID
+}
+
+func TestSigningKeyService_RotatePlatformKey(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		service := NewSigningKeyService(db, jwtService)
+		jwtService.SetKeyStore(service)
+		if err := service.LoadPlatformKeys(ctx); err != nil {
+			t.Fatalf("Failed to load platform keys: %v", err)
+		}
+
+		fileKeyID := jwtService.SharedKeyID()
+		before, err := jwtService.GenerateTokenPair("user-1", "", "user@example.com", nil)
+		if err != nil {
+			t.Fatalf("Failed to generate token pair: %v", err)
+		}
+
+		rotated, err := service.RotatePlatformKey(ctx)
+		if err != nil {
+			t.Fatalf("Failed to rotate platform key: %v", err)
+		}
+		if rotated.KeyID == fileKeyID || jwtService.SharedKeyID() != rotated.KeyID {
+			t.Fatalf("Expected %s to become the primary key, got %s", rotated.KeyID, jwtService.SharedKeyID())
+		}
+
+		keys, err := service.ListPlatformKeys(ctx)
+		if err != nil {
+			t.Fatalf("Failed to list platform keys: %v", err)
+		}
+		statuses := make(map[string]models.SigningKeyStatus)
+		for _, key := range keys {
+			statuses[key.KeyID] = key.Status
+		}
+		if len(keys) != 2 || statuses[fileKeyID] != models.SigningKeyStatusRetired || statuses[rotated.KeyID] != models.SigningKeyStatusActive {
+			t.Errorf("Expected the file key retired and the new key active, got %v", statuses)
+		}
+		if jwks := jwtService.SharedJWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].Kid != rotated.KeyID {
+			t.Errorf("Expected the JWKS to publish the primary key first and the retired key, got %+v", jwks.Keys)
+		}
+
+		// New tokens are signed with the new key, existing ones keep verifying
+		after, err := jwtService.GenerateTokenPair("user-1", "", "user@example.com", nil)
+		if err != nil {
+			t.Fatalf("Failed to generate token pair: %v", err)
+		}
+		if keyID := tokenKeyID(t, after.AccessToken); keyID != rotated.KeyID {
+			t.Errorf("Expected kid %s, got %s", rotated.KeyID, keyID)
+		}
+		for _, token := range []string{before.AccessToken, after.AccessToken} {
+			if _, err := jwtService.ValidateAccessToken(token); err != nil {
+				t.Errorf("Expected token to validate after rotation, got: %v", err)
+			}
+		}
+
+		// Another instance verifies tokens of keys it has not loaded yet
+		otherJWT, otherCleanup := testutil.CreateTestJWTService(t)
+		defer otherCleanup()
+		other := NewSigningKeyService(db, otherJWT)
+		otherJWT.SetKeyStore(other)
+		for _, token := range []string{before.AccessToken, after.AccessToken} {
+			if _, err := otherJWT.ValidateAccessToken(token); err != nil {
+				t.Errorf("Expected another instance to validate the token, got: %v", err)
+			}
+		}
+
+		// A second rotation retires the previous primary
+		second, err := service.RotatePlatformKey(ctx)
+		if err != nil {
+			t.Fatalf("Failed to rotate platform key again: %v", err)
+		}
+		var record models.PlatformSigningKey
+		if err := db.Where("key_id = ?", rotated.KeyID).First(&record).Error; err != nil || record.Status != models.SigningKeyStatusRetired || record.RetiredAt == nil {
+			t.Errorf("Expected %s to be retired, got %+v, %v", rotated.KeyID, record, err)
+		}
+		if jwtService.SharedKeyID() != second.KeyID {
+			t.Errorf("Expected %s to become the primary key, got %s", second.KeyID, jwtService.SharedKeyID())
+		}
+
+		// Retired keys expire once the longest-lived tokens signed with them have expired
+		expired, err := service.ExpireRetiredKeys(ctx, time.Now())
+		if err != nil || expired != 0 {
+			t.Fatalf("Expected no key to expire yet, got %d, %v", expired, err)
+		}
+		expired, err = service.ExpireRetiredKeys(ctx, time.Now().Add(jwtService.MaxTokenLifetime()+time.Minute))
+		if err != nil || expired != 2 {
+			t.Fatalf("Expected both retired keys to expire, got %d, %v", expired, err)
+		}
+		if err := service.LoadPlatformKeys(ctx); err != nil {
+			t.Fatalf("Failed to reload platform keys: %v", err)
+		}
+		if _, err := jwtService.ValidateAccessToken(before.AccessToken); !errors.Is(err, auth.ErrSigningKeyExpired) {
+			t.Errorf("Expected a token of an expired key to be rejected, got: %v", err)
+		}
+		if len(jwtService.SharedJWKS().Keys) != 1 {
+			t.Errorf("Expected expired keys to leave the JWKS, got %+v", jwtService.SharedJWKS().Keys)
+		}
+	})
+}
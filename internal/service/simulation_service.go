@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"gorm.io/gorm"
+)
+
+// simulationRoot is the OPA data path under which draft bundles are evaluated
+const simulationRoot = "simulations"
+
+// regoPackage matches the package declaration of a Rego module, including
+// string segments such as tenants["acme-corp"].authz
+var regoPackage = regexp.MustCompile(`(?m)^\s*package\s+([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*|\["[^"\n]*"\])*)`)
+
+// SimulationService evaluates what-if authorization requests on behalf of
+// arbitrary users of a tenant, against the active policies and draft bundles
+type SimulationService struct {
+	db         *gorm.DB
+	opaClient  *opa.Client
+	enrichment *opa.Enrichment
+	ipAccess   *IPAccessService
+}
+
+// SimulateRequest describes the subject, resource, action and context of a
+// simulated authorization request. Roles replace the roles of the user when
+// both are set.
+type SimulateRequest struct {
+	UserID   string              `json:"userId,omitempty" validate:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440001"`
+	Roles    []string            `json:"roles,omitempty" validate:"max=50,dive,required,max=100" example:"[\"editor\"]"`
+	Resource opa.ResourceContext `json:"resource"`
+	Action   string              `json:"action" validate:"required,max=100" example:"update"`
+	Context  SimulationContext   `json:"context"`
+	BundleID string              `json:"bundleId,omitempty" validate:"omitempty,uuid"` // Draft bundle to evaluate besides the active policies
+}
+
+// SimulationContext overrides the request context of a simulation
+type SimulationContext struct {
+	Time        *time.Time `json:"time,omitempty" example:"2024-01-15T22:30:00Z"` // Defaults to now
+	IPAddress   string     `json:"ipAddress,omitempty" validate:"omitempty,ip" example:"203.0.113.7"`
+	MFAVerified bool       `json:"mfaVerified,omitempty"`
+	SessionAge  int64      `json:"sessionAge,omitempty" validate:"min=0"` // Seconds since login
+}
+
+// SimulationResponse represents the decisions of a simulated request
+type SimulationResponse struct {
+	Input   map[string]interface{} `json:"input"`
+	Active  SimulatedDecision      `json:"active"`
+	Bundle  *SimulatedDecision     `json:"bundle,omitempty"`
+	Changed bool                   `json:"changed"` // The bundle decides differently than the active policies
+}
+
+// SimulatedDecision is the decision of a simulated request
+type SimulatedDecision struct {
+	Allow    bool   `json:"allow" example:"true"`
+	Reason   string `json:"reason" example:"access_granted"`
+	BundleID string `json:"bundleId,omitempty"`
+}
+
+// NewSimulationService creates a new simulation service
+func NewSimulationService(db *gorm.DB, opaClient *opa.Client, enrichment *opa.Enrichment, ipAccess *IPAccessService) *SimulationService {
+	return &SimulationService{
+		db:         db,
+		opaClient:  opaClient,
+		enrichment: enrichment,
+		ipAccess:   ipAccess,
+	}
+}
+
+// Simulate evaluates a request of a tenant's user, or of a set of roles,
+// without the user's credentials. Decisions are never cached.
+func (s *SimulationService) Simulate(ctx context.Context, tenantID uuid.UUID, req *SimulateRequest) (*SimulationResponse, error) {
+	if req.UserID == "" && len(req.Roles) == 0 {
+		return nil, apperrors.Validation("INVALID_SIMULATION", "Either userId or roles is required")
+	}
+	if req.Resource.Type == "" {
+		return nil, apperrors.Validation("VALIDATION_ERROR", "Resource type is required").
+			WithDetails(map[string]interface{}{"resource.type": "This field is required"})
+	}
+
+	input, err := s.buildInput(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests from addresses outside the tenant's IP access lists never reach policies
+	ipAllowed := true
+	if req.Context.IPAddress != "" && s.ipAccess != nil {
+		allowed, lists, err := s.ipAccess.IPAccess(ctx, tenantID.String(), req.Context.IPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate IP access lists: %w", err)
+		}
+		ipAllowed = allowed
+		if tenant, ok := input["tenant"].(map[string]interface{}); ok && lists != nil {
+			tenant["settings"] = map[string]interface{}{"ipAccess": lists}
+		}
+	}
+
+	response := &SimulationResponse{Input: input}
+	active, err := s.opaClient.Evaluate(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate active policies: %w", err)
+	}
+	if response.Active, err = simulatedDecision(active, ipAllowed); err != nil {
+		return nil, err
+	}
+
+	if req.BundleID != "" {
+		bundleID, err := uuid.Parse(req.BundleID)
+		if err != nil {
+			return nil, apperrors.Validation("INVALID_BUNDLE_ID", "Invalid bundle ID")
+		}
+		bundle, err := s.evaluateBundle(ctx, tenantID, bundleID, input)
+		if err != nil {
+			return nil, err
+		}
+		decision, err := simulatedDecision(bundle, ipAllowed)
+		if err != nil {
+			return nil, err
+		}
+		decision.BundleID = req.BundleID
+		response.Bundle = &decision
+		response.Changed = decision.Allow != response.Active.Allow
+	}
+
+	return response, nil
+}
+
+// buildInput builds the authorization input of a simulated request
+func (s *SimulationService) buildInput(ctx context.Context, tenantID uuid.UUID, req *SimulateRequest) (map[string]interface{}, error) {
+	db := readReplica(s.db).WithContext(ctx)
+
+	var tenant models.Tenant
+	if err := db.Select("id", "slug").First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	builder := opa.NewContextBuilder()
+	builder.WithTenant(tenant.ID.String(), tenant.Slug, nil)
+	builder.WithUserTenant(tenant.ID.String())
+
+	roles := req.Roles
+	if req.UserID != "" {
+		var user models.User
+		if err := db.First(&user, "id = ? AND tenant_id = ?", req.UserID, tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, apperrors.NotFound("USER_NOT_FOUND", "User not found")
+			}
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if roles == nil {
+			userRoles, err := NewUserRepository(db).GetUserRoles(ctx, user.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get user roles: %w", err)
+			}
+			roles = make([]string, len(userRoles))
+			for i, role := range userRoles {
+				roles[i] = role.Name
+			}
+		}
+		builder.WithUser(user.ID.String(), user.Email, roles)
+		builder.WithUserStatus(user.Status)
+		if len(user.Metadata) > 0 {
+			var metadata map[string]interface{}
+			if err := json.Unmarshal(user.Metadata, &metadata); err == nil {
+				builder.WithUserMetadata(metadata)
+			}
+		}
+	} else {
+		builder.WithUser("", "", roles)
+	}
+
+	// Simulated users get the access scope their roles would give their tokens
+	builder.WithUserScope(auth.AccessScopeOf(roles))
+
+	permissions, err := s.rolePermissions(db, tenantID, roles)
+	if err != nil {
+		return nil, err
+	}
+	builder.WithUserPermissions(permissions)
+
+	resourceTenant := req.Resource.TenantID
+	if resourceTenant == "" {
+		resourceTenant = tenantID.String()
+	}
+	builder.WithResource(req.Resource.Type, req.Resource.ID)
+	builder.WithResourceOwner(req.Resource.OwnerID)
+	builder.WithResourceTenant(resourceTenant)
+	builder.WithResourceAttributes(req.Resource.Attributes)
+	builder.WithAction(req.Action)
+
+	if req.Context.Time != nil {
+		builder.WithTime(*req.Context.Time)
+	}
+	builder.WithIPAddress(req.Context.IPAddress)
+	builder.WithMFAVerified(req.Context.MFAVerified)
+	builder.WithSessionAge(req.Context.SessionAge)
+
+	if err := builder.Enrich(ctx, s.enrichment); err != nil {
+		return nil, fmt.Errorf("failed to enrich input: %w", err)
+	}
+	return builder.Build(), nil
+}
+
+// rolePermissions returns the names of the permissions granted to a tenant's roles
+func (s *SimulationService) rolePermissions(db *gorm.DB, tenantID uuid.UUID, roles []string) ([]string, error) {
+	permissions := []string{}
+	if len(roles) == 0 {
+		return permissions, nil
+	}
+	err := db.Model(&models.Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.tenant_id = ? AND roles.name IN ?", tenantID, roles).
+		Order("permissions.name").
+		Pluck("permissions.name", &permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// evaluateBundle evaluates an input against a draft bundle. The bundle's
+// modules, and the active modules of packages the bundle does not define, are
+// uploaded to OPA under a namespace of their own, so the active policies are
+// unaffected. The modules are removed once evaluated.
+func (s *SimulationService) evaluateBundle(ctx context.Context, tenantID, bundleID uuid.UUID, input map[string]interface{}) (*opa.DecisionResponse, error) {
+	db := readReplica(s.db).WithContext(ctx)
+	var bundle models.PolicyBundle
+	if err := db.First(&bundle, "id = ?", bundleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("BUNDLE_NOT_FOUND", "Bundle not found")
+		}
+		return nil, fmt.Errorf("failed to get bundle: %w", err)
+	}
+	if !bundle.IsGlobal && bundle.TenantID != tenantID {
+		return nil, apperrors.NotFound("BUNDLE_NOT_FOUND", "Bundle not found")
+	}
+
+	var policies []models.Policy
+	if err := db.Joins("JOIN bundle_policies ON bundle_policies.policy_id = policies.id").
+		Where("bundle_policies.bundle_id = ?", bundleID).
+		Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bundle policies: %w", err)
+	}
+
+	// Bundle policies are simulated under their tenants' namespaces, as deployed
+	namespaced, err := namespacePolicies(ctx, db, policies)
+	if err != nil {
+		return nil, err
+	}
+	modules := make([]string, 0, len(namespaced))
+	bundlePackages := make(map[string]bool)
+	for _, policy := range namespaced {
+		if policy.Package == "" {
+			continue
+		}
+		if match := regoPackage.FindStringSubmatch(policy.Content); match != nil {
+			bundlePackages[match[1]] = true
+		}
+		modules = append(modules, policy.Content)
+	}
+	if len(modules) == 0 {
+		return nil, apperrors.Validation("EMPTY_BUNDLE", "Bundle has no Rego policies to simulate")
+	}
+
+	active, err := s.activeModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range active {
+		if match := regoPackage.FindStringSubmatch(module); match != nil && !bundlePackages[match[1]] {
+			modules = append(modules, module)
+		}
+	}
+
+	namespace := "s" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	sandboxed := sandboxModules(modules, simulationRoot+"."+namespace, tenantPolicyRoot)
+
+	ids := make([]string, 0, len(sandboxed))
+	defer func() {
+		for _, id := range ids {
+			_ = s.opaClient.DeletePolicy(context.WithoutCancel(ctx), id)
+		}
+	}()
+	for i, module := range sandboxed {
+		id := fmt.Sprintf("%s/%s/%d", simulationRoot, namespace, i)
+		if err := s.opaClient.UpsertPolicy(ctx, id, module); err != nil {
+			return nil, apperrors.Validation("INVALID_BUNDLE", "Bundle policies failed to compile").WithCause(err)
+		}
+		ids = append(ids, id)
+	}
+
+	response, err := s.opaClient.EvaluatePolicy(ctx, simulationRoot+"/"+namespace+"/"+s.opaClient.PolicyPath(), input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate bundle: %w", err)
+	}
+	return response, nil
+}
+
+// activeModules returns the source of the modules loaded in OPA, except
+// temporary modules of policy tests and simulations
+func (s *SimulationService) activeModules(ctx context.Context) ([]string, error) {
+	policies, err := s.opaClient.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active policies: %w", err)
+	}
+
+	var modules []string
+	list, _ := policies["result"].([]interface{})
+	for _, entry := range list {
+		module, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := module["id"].(string)
+		raw, _ := module["raw"].(string)
+		if raw == "" || strings.HasPrefix(id, "temp/") || strings.HasPrefix(id, simulationRoot+"/") {
+			continue
+		}
+		modules = append(modules, raw)
+	}
+	return modules, nil
+}
+
+// sandboxModules moves the packages of modules under a prefix, rewriting
+// references between them and to the given data roots. References to other
+// data, such as the RBAC data synced by Heimdall, are kept.
+func sandboxModules(modules []string, prefix string, roots ...string) []string {
+	packages := make(map[string]bool)
+	for _, module := range modules {
+		if match := regoPackage.FindStringSubmatch(module); match != nil {
+			packages[match[1]] = true
+		}
+	}
+	for _, root := range roots {
+		packages[root] = true
+	}
+	if len(packages) == 0 {
+		return modules
+	}
+
+	// Longer packages first, so heimdall.rbac is not rewritten as heimdall
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	reference := regexp.MustCompile(`\bdata\.(` + strings.Join(names, "|") + `)\b`)
+
+	sandboxed := make([]string, len(modules))
+	for i, module := range modules {
+		module = regoPackage.ReplaceAllString(module, "package "+prefix+".$1")
+		sandboxed[i] = reference.ReplaceAllString(module, "data."+prefix+".$1")
+	}
+	return sandboxed
+}
+
+// simulatedDecision converts an OPA response to a simulated decision. Requests
+// rejected by the tenant's IP access lists are denied regardless of policies.
+func simulatedDecision(response *opa.DecisionResponse, ipAllowed bool) (SimulatedDecision, error) {
+	allow, reason, err := opa.DecisionResult(response)
+	if err != nil {
+		return SimulatedDecision{}, err
+	}
+	if !ipAllowed {
+		return SimulatedDecision{Allow: false, Reason: "ip_not_allowed"}, nil
+	}
+	if reason == "" {
+		reason = "access_denied"
+		if allow {
+			reason = "access_granted"
+		}
+	}
+	return SimulatedDecision{Allow: allow, Reason: reason}, nil
+}
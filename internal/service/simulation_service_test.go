@@ -0,0 +1,216 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/config"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// fakeSimulationOPA serves active modules and records the modules uploaded for
+// simulations. Active policies allow admins; simulated bundles allow editors.
+type fakeSimulationOPA struct {
+	mu       sync.Mutex
+	uploaded map[string]string
+	sandbox  []string // Modules uploaded when a simulation was evaluated
+	inputs   []map[string]interface{}
+}
+
+func (f *fakeSimulationOPA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/policies":
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": []map[string]interface{}{
+			{"id": "policies/rbac.rego", "raw": "package heimdall.rbac\n\nallow if { input.user.roles[_] == \"admin\" }\n"},
+			{"id": "policies/authz.rego", "raw": "package heimdall.authz\n\nimport data.heimdall.rbac\n\nallow if { rbac.allow; data.heimdall.tenants[input.user.tenantId] }\n\nallow if { data.tenants[data.heimdall.tenants[input.tenant.id].slug].authz.allow }\n"},
+			{"id": "bundles/tenants/acme/authz.rego", "raw": "package tenants.acme.authz\n\nallow if { input.user.roles[_] == \"owner\" }\n"},
+			{"id": "temp/testing/1", "raw": "package temp.testing"},
+		}})
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v1/policies/"):
+		body, _ := io.ReadAll(r.Body)
+		f.uploaded[strings.TrimPrefix(r.URL.Path, "/v1/policies/")] = string(body)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/policies/"):
+		delete(f.uploaded, strings.TrimPrefix(r.URL.Path, "/v1/policies/"))
+	case r.Method == http.MethodPost:
+		var req opa.DecisionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.inputs = append(f.inputs, req.Input)
+
+		want := "admin"
+		if strings.HasPrefix(r.URL.Path, "/v1/data/"+simulationRoot+"/") {
+			want = "editor"
+			for _, module := range f.uploaded {
+				f.sandbox = append(f.sandbox, module)
+			}
+		}
+		allow := false
+		roles, _ := req.Input["user"].(map[string]interface{})["roles"].([]interface{})
+		for _, role := range roles {
+			allow = allow || role == want
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"allow": allow}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSimulationService(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		acme := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		globex := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		alice := testutil.CreateTestUser(t, db, acme, "alice@acme.com")
+		editor := testutil.CreateTestRole(t, db, acme, "editor")
+		testutil.AssignRoleToUser(t, db, alice, editor)
+		testutil.AssignPermissionToRole(t, db, editor, testutil.CreateTestPermission(t, db, "documents.update", "documents", "update"))
+
+		fake := &fakeSimulationOPA{uploaded: make(map[string]string)}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client := opa.NewClient(&config.OPAConfig{URL: server.URL, PolicyPath: "heimdall/authz", Timeout: time.Second})
+		service := NewSimulationService(db, client, opa.NewEnrichment(), nil)
+
+		// The user's roles and permissions are loaded, and the context overridden
+		at := time.Date(2024, 1, 13, 22, 30, 0, 0, time.UTC)
+		simulation, err := service.Simulate(ctx, acme.ID, &SimulateRequest{
+			UserID:   alice.ID.String(),
+			Resource: opa.ResourceContext{Type: "documents", ID: "doc-1"},
+			Action:   "update",
+			Context:  SimulationContext{Time: &at, IPAddress: "203.0.113.7", MFAVerified: true},
+		})
+		if err != nil {
+			t.Fatalf("Failed to simulate request: %v", err)
+		}
+		if simulation.Active.Allow || simulation.Active.Reason != "access_denied" || simulation.Bundle != nil {
+			t.Errorf("Expected an editor to be denied by the active policies, got %+v", simulation)
+		}
+		user := simulation.Input["user"].(map[string]interface{})
+		if user["id"] != alice.ID.String() || !equalStrings(user["roles"].([]string), []string{"editor"}) || !equalStrings(user["permissions"].([]string), []string{"documents.update"}) {
+			t.Errorf("Expected alice's roles and permissions, got %+v", user)
+		}
+		timeInput := simulation.Input["time"].(map[string]interface{})
+		requestContext := simulation.Input["context"].(map[string]interface{})
+		if timeInput["isWeekend"] != true || timeInput["hour"] != 22 || requestContext["ipAddress"] != "203.0.113.7" || requestContext["mfaVerified"] != true {
+			t.Errorf("Expected the simulated context, got %+v and %+v", timeInput, requestContext)
+		}
+
+		// Roles replace the user's roles
+		simulation, err = service.Simulate(ctx, acme.ID, &SimulateRequest{
+			Roles:    []string{"admin"},
+			Resource: opa.ResourceContext{Type: "documents"},
+			Action:   "update",
+		})
+		if err != nil || !simulation.Active.Allow {
+			t.Errorf("Expected the admin role to be allowed, got %+v, %v", simulation, err)
+		}
+
+		// Draft bundles are evaluated in a namespace of their own, with their
+		// policies in the tenant's namespace as when deployed
+		rbac := &models.Policy{
+			TenantID:  acme.ID,
+			Name:      "authz",
+			Path:      "acme/authz",
+			Type:      models.PolicyTypeRego,
+			Content:   "package authz\n\nallow if { input.user.roles[_] == \"editor\" }\n",
+			CreatedBy: alice.ID,
+		}
+		if err := db.Create(rbac).Error; err != nil {
+			t.Fatalf("Failed to create policy: %v", err)
+		}
+		bundle := &models.PolicyBundle{TenantID: acme.ID, Name: "draft", Version: "2.0.0", Status: models.BundleStatusReady, CreatedBy: alice.ID, UpdatedBy: alice.ID}
+		if err := db.Create(bundle).Error; err != nil {
+			t.Fatalf("Failed to create bundle: %v", err)
+		}
+		if err := db.Create(&models.BundlePolicy{BundleID: bundle.ID, PolicyID: rbac.ID, AddedAt: time.Now(), AddedBy: alice.ID}).Error; err != nil {
+			t.Fatalf("Failed to add policy to bundle: %v", err)
+		}
+
+		fake.inputs = nil
+		simulation, err = service.Simulate(ctx, acme.ID, &SimulateRequest{
+			UserID:   alice.ID.String(),
+			Resource: opa.ResourceContext{Type: "documents", ID: "doc-1"},
+			Action:   "update",
+			BundleID: bundle.ID.String(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to simulate bundle: %v", err)
+		}
+		if simulation.Active.Allow || simulation.Bundle == nil || !simulation.Bundle.Allow || !simulation.Changed {
+			t.Errorf("Expected the draft bundle to allow what the active policies deny, got %+v", simulation)
+		}
+		sandbox := strings.Join(fake.sandbox, "\n")
+		if len(fake.sandbox) != 3 || !strings.Contains(sandbox, `"editor"`) || strings.Contains(sandbox, `"owner"`) || strings.Contains(sandbox, "temp.testing") {
+			t.Errorf("Expected the bundle's module in place of the tenant's active one, and the active heimdall modules, got %v", fake.sandbox)
+		}
+		for _, want := range []string{".tenants.acme.authz\n", ".heimdall.rbac\n", "allow if { data.simulations.s"} {
+			if !strings.Contains(sandbox, want) {
+				t.Errorf("Expected %q in the sandboxed modules %v", want, fake.sandbox)
+			}
+		}
+		if len(fake.uploaded) != 0 {
+			t.Errorf("Expected simulation modules to be removed, got %v", fake.uploaded)
+		}
+		if len(fake.inputs) != 2 {
+			t.Errorf("Expected active and bundle evaluations, got %d", len(fake.inputs))
+		}
+
+		if _, err := service.Simulate(ctx, globex.ID, &SimulateRequest{Roles: []string{"admin"}, Resource: opa.ResourceContext{Type: "documents"}, Action: "read", BundleID: bundle.ID.String()}); !isAppError(err, "BUNDLE_NOT_FOUND") {
+			t.Errorf("Expected BUNDLE_NOT_FOUND for another tenant's bundle, got %v", err)
+		}
+		if _, err := service.Simulate(ctx, globex.ID, &SimulateRequest{UserID: alice.ID.String(), Resource: opa.ResourceContext{Type: "documents"}, Action: "read"}); !isAppError(err, "USER_NOT_FOUND") {
+			t.Errorf("Expected USER_NOT_FOUND for another tenant's user, got %v", err)
+		}
+		if _, err := service.Simulate(ctx, acme.ID, &SimulateRequest{Resource: opa.ResourceContext{Type: "documents"}, Action: "read"}); !isAppError(err, "INVALID_SIMULATION") {
+			t.Errorf("Expected INVALID_SIMULATION without a user or roles, got %v", err)
+		}
+	})
+}
+
+func TestSandboxModules(t *testing.T) {
+	modules := sandboxModules([]string{
+		"package heimdall.rbac\n\nallow if { data.heimdall.rbac_data.enabled }\n",
+		"# Entry point\npackage heimdall.authz\n\nimport data.heimdall.rbac\nimport data.heimdall.helpers\n\nallow if { rbac.allow; data.heimdall.tenants[input.user.tenantId] }\n",
+	}, "simulations.s1")
+
+	if !strings.HasPrefix(modules[0], "package simulations.s1.heimdall.rbac\n") || !strings.Contains(modules[0], "data.heimdall.rbac_data.enabled") {
+		t.Errorf("Expected only the package to be moved, got %q", modules[0])
+	}
+	for _, want := range []string{"package simulations.s1.heimdall.authz", "import data.simulations.s1.heimdall.rbac\n", "import data.heimdall.helpers", "data.heimdall.tenants["} {
+		if !strings.Contains(modules[1], want) {
+			t.Errorf("Expected %q in %q", want, modules[1])
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isAppError(err error, code string) bool {
+	var appErr *apperrors.Error
+	return errors.As(err, &appErr) && appErr.Code == code
+}
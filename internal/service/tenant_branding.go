@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// Tenant settings keys of what hosted login pages show
+const (
+	brandingSetting     = "branding"
+	loginMethodsSetting = "loginMethods"
+)
+
+// Login methods hosted login pages may offer
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodSAML      = "saml"
+)
+
+// hexColorPattern matches CSS hex colors, e.g. #1a73e8
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// TenantBranding is how hosted login pages present a tenant, stored in the
+// branding setting
+type TenantBranding struct {
+	LogoURL      string `json:"logoUrl,omitempty" example:"https://cdn.acme.com/logo.svg"`
+	PrimaryColor string `json:"primaryColor,omitempty" example:"#1a73e8"`
+}
+
+// TenantSSO describes the single sign-on of a tenant
+type TenantSSO struct {
+	Available bool   `json:"available" example:"true"`
+	Protocol  string `json:"protocol,omitempty" example:"saml"`
+	LoginURL  string `json:"loginUrl,omitempty" example:"/v1/saml/550e8400-e29b-41d4-a716-446655440000/login"` // Relative to the API host
+}
+
+// PublicTenant is what hosted login pages need to render a tenant's login,
+// served without authentication. It holds nothing beyond what the login page
+// shows anyway.
+type PublicTenant struct {
+	ID           string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name         string         `json:"name" example:"Acme Corporation"`
+	Slug         string         `json:"slug" example:"acme-corp"`
+	Branding     TenantBranding `json:"branding"`
+	LoginMethods []string       `json:"loginMethods"`
+	SSO          TenantSSO      `json:"sso"`
+	ETag         string         `json:"-"` // Sent in the ETag header, so login pages revalidate cheaply
+}
+
+// GetPublicTenant retrieves the branding and login methods of an active
+// tenant. Login methods are those the tenant enabled in its loginMethods
+// setting, all by default, that are available: SAML requires an enabled SAML
+// configuration.
+func (s *TenantService) GetPublicTenant(ctx context.Context, slug string) (*PublicTenant, error) {
+	tenant, err := s.tenantRepository.readOnly().GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	// Users of suspended tenants cannot log in, so there is no login to render
+	if tenant.Status != "active" {
+		return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+	}
+	settings, err := tenantSettings(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	public := &PublicTenant{
+		ID:           tenant.ID.String(),
+		Name:         tenant.Name,
+		Slug:         tenant.Slug,
+		LoginMethods: []string{},
+	}
+	if value, ok := settings[brandingSetting]; ok && value != nil {
+		// Validated when saved
+		raw, _ := json.Marshal(value)
+		_ = json.Unmarshal(raw, &public.Branding)
+	}
+
+	var samlConfigs int64
+	if err := readReplica(s.db).WithContext(ctx).Model(&models.TenantSAMLConfig{}).Where("tenant_id = ? AND enabled = ?", tenant.ID, true).Count(&samlConfigs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get SAML configuration: %w", err)
+	}
+
+	enabled := []string{LoginMethodPassword, LoginMethodMagicLink, LoginMethodSAML}
+	if values, ok := settings[loginMethodsSetting].([]interface{}); ok {
+		enabled = enabled[:0]
+		for _, value := range values {
+			method, _ := value.(string)
+			enabled = append(enabled, method)
+		}
+	}
+	for _, method := range []string{LoginMethodPassword, LoginMethodMagicLink, LoginMethodSAML} {
+		if !slices.Contains(enabled, method) || (method == LoginMethodSAML && samlConfigs == 0) {
+			continue
+		}
+		public.LoginMethods = append(public.LoginMethods, method)
+	}
+	if slices.Contains(public.LoginMethods, LoginMethodSAML) {
+		public.SSO = TenantSSO{Available: true, Protocol: LoginMethodSAML, LoginURL: "/v1/saml/" + tenant.ID.String() + "/login"}
+	}
+
+	body, err := json.Marshal(public)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tenant: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	public.ETag = fmt.Sprintf(`"%x"`, sum[:16])
+	return public, nil
+}
+
+// validateBrandingSetting checks that the branding setting, if present, has
+// an https logo URL and a hex primary color
+func validateBrandingSetting(settings map[string]interface{}) error {
+	value, ok := settings[brandingSetting]
+	if !ok || value == nil {
+		return nil
+	}
+
+	branding, ok := value.(map[string]interface{})
+	if !ok {
+		return apperrors.Validation("INVALID_BRANDING", "The branding setting must be an object with logoUrl and primaryColor")
+	}
+	for key, entry := range branding {
+		text, isString := entry.(string)
+		switch key {
+		case "logoUrl":
+			parsed, err := url.Parse(text)
+			if !isString || err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+				return apperrors.Validation("INVALID_BRANDING", "branding.logoUrl must be an https URL")
+			}
+		case "primaryColor":
+			if !isString || !hexColorPattern.MatchString(text) {
+				return apperrors.Validation("INVALID_BRANDING", "branding.primaryColor must be a hex color, e.g. #1a73e8")
+			}
+		default:
+			return apperrors.Validation("INVALID_BRANDING", "Unknown branding setting "+key)
+		}
+	}
+	return nil
+}
+
+// validateLoginMethodsSetting checks that the loginMethods setting, if present,
+// lists known login methods
+func validateLoginMethodsSetting(settings map[string]interface{}) error {
+	value, ok := settings[loginMethodsSetting]
+	if !ok || value == nil {
+		return nil
+	}
+
+	entries, ok := value.([]interface{})
+	if !ok {
+		return apperrors.Validation("INVALID_LOGIN_METHODS", "The loginMethods setting must be an array of login methods")
+	}
+	for _, entry := range entries {
+		method, _ := entry.(string)
+		if method != LoginMethodPassword && method != LoginMethodMagicLink && method != LoginMethodSAML {
+			return apperrors.Validation("INVALID_LOGIN_METHODS", "Login methods must be password, magic_link or saml").
+				WithDetails(map[string]interface{}{"method": entry})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestTenantService_GetPublicTenant(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		service := NewTenantService(db)
+
+		public, err := service.GetPublicTenant(ctx, "acme")
+		if err != nil {
+			t.Fatalf("GetPublicTenant returned error: %v", err)
+		}
+		if public.Name != "Acme" || !slices.Equal(public.LoginMethods, []string{LoginMethodPassword, LoginMethodMagicLink}) || public.SSO.Available {
+			t.Errorf("Unexpected public tenant without SAML %+v", public)
+		}
+		etag := public.ETag
+
+		var appErr *apperrors.Error
+		_, err = service.UpdateTenant(ctx, tenant.ID.String(), &UpdateTenantRequest{Settings: map[string]interface{}{
+			"branding": map[string]interface{}{"logoUrl": "http://cdn.acme.com/logo.svg"},
+		}})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_BRANDING" {
+			t.Fatalf("Expected INVALID_BRANDING for a logo URL without https, got %v", err)
+		}
+		_, err = service.UpdateTenant(ctx, tenant.ID.String(), &UpdateTenantRequest{Settings: map[string]interface{}{
+			"loginMethods": []interface{}{"password", "passkey"},
+		}})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_LOGIN_METHODS" {
+			t.Fatalf("Expected INVALID_LOGIN_METHODS for an unknown method, got %v", err)
+		}
+
+		_, err = service.UpdateTenant(ctx, tenant.ID.String(), &UpdateTenantRequest{Settings: map[string]interface{}{
+			"branding":     map[string]interface{}{"logoUrl": "https://cdn.acme.com/logo.svg", "primaryColor": "#1a73e8"},
+			"loginMethods": []interface{}{"saml", "password"},
+		}})
+		if err != nil {
+			t.Fatalf("Failed to update tenant settings: %v", err)
+		}
+		if err := db.Create(&models.TenantSAMLConfig{TenantID: tenant.ID, Enabled: true, IdPMetadata: "<EntityDescriptor/>"}).Error; err != nil {
+			t.Fatalf("Failed to create SAML configuration: %v", err)
+		}
+
+		public, err = service.GetPublicTenant(ctx, "acme")
+		if err != nil {
+			t.Fatalf("GetPublicTenant returned error: %v", err)
+		}
+		if public.Branding.LogoURL != "https://cdn.acme.com/logo.svg" || public.Branding.PrimaryColor != "#1a73e8" {
+			t.Errorf("Unexpected branding %+v", public.Branding)
+		}
+		if !slices.Equal(public.LoginMethods, []string{LoginMethodPassword, LoginMethodSAML}) {
+			t.Errorf("LoginMethods = %v, want password and saml", public.LoginMethods)
+		}
+		if !public.SSO.Available || public.SSO.LoginURL != "/v1/saml/"+tenant.ID.String()+"/login" {
+			t.Errorf("Unexpected SSO %+v", public.SSO)
+		}
+		if public.ETag == etag {
+			t.Error("Expected the ETag to change with the tenant's login page")
+		}
+
+		// Suspended tenants have no login to render
+		if err := db.Model(&models.Tenant{}).Where("id = ?", tenant.ID).Update("status", "suspended").Error; err != nil {
+			t.Fatalf("Failed to suspend tenant: %v", err)
+		}
+		if _, err := service.GetPublicTenant(ctx, "acme"); !errors.As(err, &appErr) || appErr.Code != "TENANT_NOT_FOUND" {
+			t.Errorf("Expected TENANT_NOT_FOUND for a suspended tenant, got %v", err)
+		}
+	})
+}
@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +21,12 @@ func NewTenantRepository(db *gorm.DB) *TenantRepository {
 	return &TenantRepository{db: db}
 }
 
+// readOnly returns a copy of the repository that reads from a replica when
+// replicas are configured
+func (r *TenantRepository) readOnly() *TenantRepository {
+	return &TenantRepository{db: readReplica(r.db)}
+}
+
 // Create creates a new tenant
 func (r *TenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
 	return r.db.WithContext(ctx).Create(tenant).Error
@@ -54,29 +62,9 @@ func (r *TenantRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.Tenant{}, id).Error
 }
 
-// List retrieves a paginated list of tenants
-func (r *TenantRepository) List(ctx context.Context, page, pageSize int) ([]models.Tenant, int64, error) {
-	var tenants []models.Tenant
-	var total int64
-
-	// Count total
-	if err := r.db.WithContext(ctx).Model(&models.Tenant{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// Get paginated results
-	offset := (page - 1) * pageSize
-	err := r.db.WithContext(ctx).
-		Offset(offset).
-		Limit(pageSize).
-		Order("created_at DESC").
-		Find(&tenants).Error
-
-	if err != nil {
-		return nil, 0, err
-	}
-
-	return tenants, total, nil
+// List retrieves a page of tenants
+func (r *TenantRepository) List(ctx context.Context, params *pagination.Params) ([]models.Tenant, *pagination.Page, error) {
+	return pagination.Paginate[models.Tenant](ctx, r.db.Model(&models.Tenant{}), params, TenantListOptions)
 }
 
 // UpdateStatus updates a tenant's status
@@ -89,10 +77,16 @@ func (r *TenantRepository) UpdateStatus(ctx context.Context, id uuid.UUID, statu
 
 // UpdateSettings updates tenant settings
 func (r *TenantRepository) UpdateSettings(ctx context.Context, id uuid.UUID, settings map[string]interface{}) error {
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant settings: %w", err)
+	}
+	// Updated from a struct so the settings are encrypted
 	return r.db.WithContext(ctx).
 		Model(&models.Tenant{}).
 		Where("id = ?", id).
-		Update("settings", settings).Error
+		Select("settings").
+		Updates(&models.Tenant{Settings: encoded}).Error
 }
 
 // GetUserCount returns the number of users in a tenant
@@ -3,13 +3,17 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TenantService handles tenant-related business logic
@@ -55,6 +59,17 @@ type TenantResponse struct {
 	CreatedAt string                 `json:"createdAt" example:"2024-01-15T10:30:00Z"`
 	UpdatedAt string                 `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
 	Stats     map[string]interface{} `json:"stats,omitempty"`
+	ETag      string                 `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// UpsertTenantRequest represents the desired state of a tenant identified by its slug.
+// Omitted fields are reset to their defaults rather than left unchanged.
+type UpsertTenantRequest struct {
+	Name     string                 `json:"name" validate:"required,min=2,max=255" example:"Acme Corporation"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	MaxUsers int                    `json:"maxUsers,omitempty" validate:"omitempty,min=1" example:"1000"`
+	MaxRoles int                    `json:"maxRoles,omitempty" validate:"omitempty,min=1" example:"50"`
+	Status   string                 `json:"status,omitempty" validate:"omitempty,oneof=active suspended" example:"active"`
 }
 
 // CreateTenant creates a new tenant
@@ -62,7 +77,7 @@ func (s *TenantService) CreateTenant(ctx context.Context, req *CreateTenantReque
 	// Validate and normalize slug
 	slug := normalizeSlug(req.Slug)
 	if !isValidSlug(slug) {
-		return nil, fmt.Errorf("invalid slug: must contain only lowercase letters, numbers, and hyphens")
+		return nil, apperrors.Validation("INVALID_SLUG", "Invalid slug: must contain only lowercase letters, numbers, and hyphens")
 	}
 
 	// Check if slug already exists
@@ -71,7 +86,7 @@ func (s *TenantService) CreateTenant(ctx context.Context, req *CreateTenantReque
 		return nil, fmt.Errorf("failed to check slug: %w", err)
 	}
 	if exists {
-		return nil, fmt.Errorf("tenant with slug '%s' already exists", slug)
+		return nil, apperrors.Conflict("TENANT_SLUG_EXISTS", fmt.Sprintf("Tenant with slug '%s' already exists", slug))
 	}
 
 	// Set defaults
@@ -96,6 +111,9 @@ func (s *TenantService) CreateTenant(ctx context.Context, req *CreateTenantReque
 
 	// Marshal settings to JSON
 	if req.Settings != nil {
+		if err := validateTenantSettings(req.Settings); err != nil {
+			return nil, err
+		}
 		settingsJSON, err := json.Marshal(req.Settings)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal settings: %w", err)
@@ -114,34 +132,36 @@ func (s *TenantService) CreateTenant(ctx context.Context, req *CreateTenantReque
 func (s *TenantService) GetTenant(ctx context.Context, tenantID string) (*TenantResponse, error) {
 	id, err := uuid.Parse(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tenant ID: %w", err)
+		return nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
-	tenant, err := s.tenantRepository.GetByID(ctx, id)
+	tenants := s.tenantRepository.readOnly()
+	tenant, err := tenants.GetByID(ctx, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("tenant not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
 		}
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
 	}
 
 	// Get tenant stats
-	stats, _ := s.tenantRepository.GetTenantStats(ctx, id)
+	stats, _ := tenants.GetTenantStats(ctx, id)
 
 	return s.toTenantResponse(tenant, stats), nil
 }
 
 // GetTenantBySlug retrieves a tenant by slug
 func (s *TenantService) GetTenantBySlug(ctx context.Context, slug string) (*TenantResponse, error) {
-	tenant, err := s.tenantRepository.GetBySlug(ctx, slug)
+	tenants := s.tenantRepository.readOnly()
+	tenant, err := tenants.GetBySlug(ctx, slug)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("tenant not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
 		}
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
 	}
 
-	stats, _ := s.tenantRepository.GetTenantStats(ctx, tenant.ID)
+	stats, _ := tenants.GetTenantStats(ctx, tenant.ID)
 
 	return s.toTenantResponse(tenant, stats), nil
 }
@@ -150,13 +170,13 @@ func (s *TenantService) GetTenantBySlug(ctx context.Context, slug string) (*Tena
 func (s *TenantService) UpdateTenant(ctx context.Context, tenantID string, req *UpdateTenantRequest) (*TenantResponse, error) {
 	id, err := uuid.Parse(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tenant ID: %w", err)
+		return nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
 	tenant, err := s.tenantRepository.GetByID(ctx, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("tenant not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
 		}
 		return nil, fmt.Errorf("failed to get tenant: %w", err)
 	}
@@ -167,6 +187,10 @@ func (s *TenantService) UpdateTenant(ctx context.Context, tenantID string, req *
 	}
 
 	if req.Settings != nil {
+		if err := validateTenantSettings(req.Settings); err != nil {
+			return nil, err
+		}
+
 		// Parse existing settings
 		var existingSettings map[string]interface{}
 		if len(tenant.Settings) > 0 {
@@ -207,18 +231,92 @@ func (s *TenantService) UpdateTenant(ctx context.Context, tenantID string, req *
 	return s.toTenantResponse(tenant, stats), nil
 }
 
+// UpsertTenant creates or replaces the tenant with the given slug. The existing tenant is
+// locked while the precondition is checked, so concurrent upserts cannot overwrite each
+// other. A tenant deleted earlier under the same slug is restored. It reports whether
+// the tenant was created.
+func (s *TenantService) UpsertTenant(ctx context.Context, slug string, req *UpsertTenantRequest, pre Precondition) (*TenantResponse, bool, error) {
+	slug = normalizeSlug(slug)
+	if !isValidSlug(slug) {
+		return nil, false, apperrors.Validation("INVALID_SLUG", "Invalid slug: must contain only lowercase letters, numbers, and hyphens")
+	}
+
+	var settingsJSON []byte
+	if req.Settings != nil {
+		if err := validateTenantSettings(req.Settings); err != nil {
+			return nil, false, err
+		}
+		var err error
+		if settingsJSON, err = json.Marshal(req.Settings); err != nil {
+			return nil, false, fmt.Errorf("failed to marshal settings: %w", err)
+		}
+	}
+
+	var tenant models.Tenant
+	created := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Where("slug = ?", slug).First(&tenant).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+		created = err != nil || tenant.DeletedAt.Valid
+
+		current := ""
+		if !created {
+			current = ResourceETag(tenant.UpdatedAt)
+		}
+		if err := pre.Check(current); err != nil {
+			return err
+		}
+
+		tenant.Slug = slug
+		tenant.Name = req.Name
+		tenant.Settings = settingsJSON
+		tenant.MaxUsers = req.MaxUsers
+		if tenant.MaxUsers == 0 {
+			tenant.MaxUsers = 1000
+		}
+		tenant.MaxRoles = req.MaxRoles
+		if tenant.MaxRoles == 0 {
+			tenant.MaxRoles = 50
+		}
+		tenant.Status = req.Status
+		if tenant.Status == "" {
+			tenant.Status = "active"
+		}
+		tenant.DeletedAt = gorm.DeletedAt{}
+
+		if tenant.ID == uuid.Nil {
+			err = tx.Create(&tenant).Error
+		} else {
+			err = tx.Unscoped().Save(&tenant).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save tenant: %w", err)
+		}
+
+		// Reload so the ETag reflects the stored timestamp
+		return tx.First(&tenant, "id = ?", tenant.ID).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s.toTenantResponse(&tenant, nil), created, nil
+}
+
 // DeleteTenant deletes a tenant
 func (s *TenantService) DeleteTenant(ctx context.Context, tenantID string) error {
 	id, err := uuid.Parse(tenantID)
 	if err != nil {
-		return fmt.Errorf("invalid tenant ID: %w", err)
+		return apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
 	// Check if tenant exists
 	tenant, err := s.tenantRepository.GetByID(ctx, id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("tenant not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
 		}
 		return fmt.Errorf("failed to get tenant: %w", err)
 	}
@@ -230,7 +328,7 @@ func (s *TenantService) DeleteTenant(ctx context.Context, tenantID string) error
 	}
 
 	if userCount > 0 {
-		return fmt.Errorf("cannot delete tenant with existing users (count: %d)", userCount)
+		return apperrors.Conflict("TENANT_HAS_USERS", fmt.Sprintf("Cannot delete tenant with existing users (count: %d)", userCount))
 	}
 
 	// Soft delete tenant
@@ -244,11 +342,38 @@ func (s *TenantService) DeleteTenant(ctx context.Context, tenantID string) error
 	return nil
 }
 
-// ListTenants retrieves a paginated list of tenants
+// TenantListOptions describes the sorting and filtering supported when listing tenants
+var TenantListOptions = pagination.Options{
+	SortFields: map[string]string{
+		"createdAt": "created_at",
+		"updatedAt": "updated_at",
+		"name":      "name",
+		"slug":      "slug",
+	},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// ListTenants retrieves a paginated list of tenants, newest first
 func (s *TenantService) ListTenants(ctx context.Context, page, pageSize int) ([]TenantResponse, int64, error) {
-	tenants, total, err := s.tenantRepository.List(ctx, page, pageSize)
+	tenants, meta, err := s.ListTenantsPage(ctx, &pagination.Params{
+		Page:     page,
+		PageSize: pageSize,
+		Sort:     "createdAt",
+		Desc:     true,
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list tenants: %w", err)
+		return nil, 0, err
+	}
+
+	return tenants, meta.Total, nil
+}
+
+// ListTenantsPage retrieves a page of tenants with sorting, filtering and cursor support
+func (s *TenantService) ListTenantsPage(ctx context.Context, params *pagination.Params) ([]TenantResponse, *pagination.Page, error) {
+	tenants, page, err := s.tenantRepository.readOnly().List(ctx, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tenants: %w", err)
 	}
 
 	responses := make([]TenantResponse, len(tenants))
@@ -256,14 +381,14 @@ func (s *TenantService) ListTenants(ctx context.Context, page, pageSize int) ([]
 		responses[i] = *s.toTenantResponse(&tenant, nil)
 	}
 
-	return responses, total, nil
+	return responses, page, nil
 }
 
 // SuspendTenant suspends a tenant
 func (s *TenantService) SuspendTenant(ctx context.Context, tenantID string) error {
 	id, err := uuid.Parse(tenantID)
 	if err != nil {
-		return fmt.Errorf("invalid tenant ID: %w", err)
+		return apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
 	return s.tenantRepository.UpdateStatus(ctx, id, "suspended")
@@ -273,7 +398,7 @@ func (s *TenantService) SuspendTenant(ctx context.Context, tenantID string) erro
 func (s *TenantService) ActivateTenant(ctx context.Context, tenantID string) error {
 	id, err := uuid.Parse(tenantID)
 	if err != nil {
-		return fmt.Errorf("invalid tenant ID: %w", err)
+		return apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
 	return s.tenantRepository.UpdateStatus(ctx, id, "active")
@@ -283,10 +408,10 @@ func (s *TenantService) ActivateTenant(ctx context.Context, tenantID string) err
 func (s *TenantService) GetTenantStats(ctx context.Context, tenantID string) (map[string]interface{}, error) {
 	id, err := uuid.Parse(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tenant ID: %w", err)
+		return nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
-	return s.tenantRepository.GetTenantStats(ctx, id)
+	return s.tenantRepository.readOnly().GetTenantStats(ctx, id)
 }
 
 // Helper functions
@@ -311,7 +436,32 @@ func (s *TenantService) toTenantResponse(tenant *models.Tenant, stats map[string
 		CreatedAt: tenant.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: tenant.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		Stats:     stats,
+		ETag:      ResourceETag(tenant.UpdatedAt),
+	}
+}
+
+// validateTenantSettings checks the settings Heimdall itself enforces, such as
+// IP access lists, CORS origins, the user attribute schema and login branding
+func validateTenantSettings(settings map[string]interface{}) error {
+	if err := validateIPAccessSetting(settings); err != nil {
+		return err
+	}
+	if err := validatePasswordBreachSetting(settings); err != nil {
+		return err
+	}
+	if err := validatePasswordPolicySetting(settings); err != nil {
+		return err
+	}
+	if err := validateUserAttributesSetting(settings); err != nil {
+		return err
+	}
+	if err := validateBrandingSetting(settings); err != nil {
+		return err
+	}
+	if err := validateLoginMethodsSetting(settings); err != nil {
+		return err
 	}
+	return validateAllowedOriginsSetting(settings)
 }
 
 // normalizeSlug normalizes a slug to lowercase and replaces spaces with hyphens
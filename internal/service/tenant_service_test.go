@@ -83,9 +83,9 @@ func TestTenantService_CreateTenant_InvalidSlug(t *testing.T) {
 		ctx := testutil.CreateTestContext(t)
 
 		invalidSlugs := []string{
-			"Test Slug",     // spaces
+			"test.slug",     // dots
 			"test_slug_",    // trailing underscore
-			"Test-Slug",     // uppercase
+			"test/slug",     // slashes
 			"test@slug",     // special chars
 			"-test-slug",    // leading hyphen
 			"test--slug",    // double hyphen
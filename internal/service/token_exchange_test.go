@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestAuthService_ExchangeToken(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		authService := NewAuthService(db, nil, jwtService, nil, nil, nil)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		billing := testutil.CreateTestRole(t, db, tenant, "billing")
+		testutil.AssignPermissionToRole(t, db, billing, testutil.CreateTestPermission(t, db, "invoices.read", "invoices", "read"))
+		testutil.AssignPermissionToRole(t, db, billing, testutil.CreateTestPermission(t, db, "invoices.export", "invoices", "export"))
+		testutil.CreateTestPermission(t, db, "users.delete", "users", "delete")
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		testutil.AssignRoleToUser(t, db, alice, billing)
+
+		// Tokens are only exchanged for audiences the tenant registered
+		claimsTemplates := NewClaimsTemplateService(db)
+		jwtService.SetClaimsTemplates(claimsTemplates)
+		if _, _, err := claimsTemplates.UpsertTemplate(ctx, tenant.ID, &UpsertClaimsTemplateRequest{Audiences: []string{"billing-service"}}, Precondition{}); err != nil {
+			t.Fatalf("UpsertTemplate returned error: %v", err)
+		}
+
+		tokens, err := jwtService.GenerateTokenPair(alice.ID.String(), tenant.ID.String(), alice.Email, []string{"billing"})
+		if err != nil {
+			t.Fatalf("Failed to generate token pair: %v", err)
+		}
+		subject, _ := jwtService.ValidateAccessToken(tokens.AccessToken)
+
+		exchanged, err := authService.ExchangeToken(ctx, tokens.AccessToken, &TokenExchangeRequest{
+			GrantType: auth.TokenExchangeGrantType,
+			Scope:     "invoices.read invoices.export invoices.read",
+			Audience:  "billing-service",
+			ExpiresIn: 60,
+		})
+		if err != nil {
+			t.Fatalf("ExchangeToken returned error: %v", err)
+		}
+		if exchanged.Scope != "invoices.export invoices.read" || exchanged.ExpiresIn != 60 || exchanged.IssuedTokenType != auth.AccessTokenType {
+			t.Errorf("Unexpected exchange response: %+v", exchanged)
+		}
+
+		// The token is meant for the billing service, which Heimdall does not
+		// accept tokens of, so its claims are read without validating them
+		claims := &auth.TokenClaims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(exchanged.AccessToken, claims); err != nil {
+			t.Fatalf("Failed to parse exchanged token: %v", err)
+		}
+		if _, err := jwtService.ValidateAccessToken(exchanged.AccessToken); err == nil {
+			t.Error("Expected a token for the billing service to be rejected by Heimdall")
+		}
+		if claims.UserID != alice.ID.String() || !reflect.DeepEqual([]string(claims.Audience), []string{"billing-service"}) {
+			t.Errorf("Unexpected exchanged claims: %+v", claims)
+		}
+		if claims.Actor == nil || claims.Actor.TokenID != subject.ID || claims.Actor.Subject != alice.ID.String() {
+			t.Errorf("Expected the subject token as actor, got %+v", claims.Actor)
+		}
+		if !claims.AllowsScope("invoices.read") || claims.AllowsScope("users.delete") {
+			t.Errorf("Expected the token to be limited to its scope, got %q", claims.Scope)
+		}
+		if ids := claims.TokenIDs(); !reflect.DeepEqual(ids, []string{claims.ID, subject.ID}) {
+			t.Errorf("Expected the token IDs of the chain, got %v", ids)
+		}
+
+		// Exchanged tokens can only be narrowed further
+		scoped, err := authService.ExchangeToken(ctx, tokens.AccessToken, &TokenExchangeRequest{Scope: "invoices.read invoices.export", ExpiresIn: 60})
+		if err != nil {
+			t.Fatalf("ExchangeToken returned error: %v", err)
+		}
+		scopedClaims, _ := jwtService.ValidateAccessToken(scoped.AccessToken)
+		narrowed, err := authService.ExchangeToken(ctx, scoped.AccessToken, &TokenExchangeRequest{Scope: "invoices.read"})
+		if err != nil {
+			t.Fatalf("ExchangeToken returned error for a narrower scope: %v", err)
+		}
+		narrowedClaims, _ := jwtService.ValidateAccessToken(narrowed.AccessToken)
+		if narrowedClaims.Actor.Depth() != 2 || narrowedClaims.Actor.TokenID != scopedClaims.ID {
+			t.Errorf("Expected a delegation chain of two tokens, got %+v", narrowedClaims.Actor)
+		}
+		if !reflect.DeepEqual([]string(narrowedClaims.Audience), []string{"heimdall", "billing-service"}) {
+			t.Errorf("Expected the audience to be inherited, got %v", narrowedClaims.Audience)
+		}
+		if narrowed.ExpiresIn > 60 {
+			t.Errorf("Expected the token not to outlive its subject token, got %d seconds", narrowed.ExpiresIn)
+		}
+
+		tests := []struct {
+			name     string
+			token    string
+			req      TokenExchangeRequest
+			wantCode string
+		}{
+			{"permission the user lacks", tokens.AccessToken, TokenExchangeRequest{Scope: "users.delete"}, "INVALID_SCOPE"},
+			{"broader than subject scope", scoped.AccessToken, TokenExchangeRequest{Scope: "invoices.read users.delete"}, "INVALID_SCOPE"},
+			{"empty scope", tokens.AccessToken, TokenExchangeRequest{Scope: " "}, "INVALID_SCOPE"},
+			{"other audience", scoped.AccessToken, TokenExchangeRequest{Scope: "invoices.read", Audience: "reporting-service"}, "INVALID_AUDIENCE"},
+			{"token for a service", exchanged.AccessToken, TokenExchangeRequest{Scope: "invoices.read"}, "INVALID_TOKEN"},
+			{"unregistered audience", tokens.AccessToken, TokenExchangeRequest{Scope: "invoices.read", Audience: "reporting-service"}, "INVALID_AUDIENCE"},
+			{"unsupported grant type", tokens.AccessToken, TokenExchangeRequest{GrantType: "refresh_token", Scope: "invoices.read"}, "UNSUPPORTED_GRANT_TYPE"},
+			{"refresh token", tokens.RefreshToken, TokenExchangeRequest{Scope: "invoices.read"}, "INVALID_TOKEN"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := authService.ExchangeToken(ctx, tt.token, &tt.req)
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Errorf("Expected %s, got %v", tt.wantCode, err)
+				}
+			})
+		}
+
+		// Delegation chains are bounded
+		token := tokens.AccessToken
+		for i := 0; i < maxDelegationDepth; i++ {
+			next, err := authService.ExchangeToken(ctx, token, &TokenExchangeRequest{Scope: "invoices.read"})
+			if err != nil {
+				t.Fatalf("ExchangeToken returned error at depth %d: %v", i, err)
+			}
+			token = next.AccessToken
+		}
+		_, err = authService.ExchangeToken(ctx, token, &TokenExchangeRequest{Scope: "invoices.read"})
+		if appErr := (*apperrors.Error)(nil); !errors.As(err, &appErr) || appErr.Code != "DELEGATION_DEPTH_EXCEEDED" {
+			t.Errorf("Expected DELEGATION_DEPTH_EXCEEDED, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// userAttributesSetting is the tenant settings key holding the tenant's user
+// attribute schema
+const userAttributesSetting = "userAttributes"
+
+// User attribute types
+const (
+	UserAttributeTypeString  = "string"
+	UserAttributeTypeNumber  = "number"
+	UserAttributeTypeInteger = "integer"
+	UserAttributeTypeBoolean = "boolean"
+)
+
+// userAttributeName matches valid attribute names, which are used as keys of
+// user metadata and policy input
+var userAttributeName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// reservedUserAttributes are metadata keys managed by Heimdall itself
+var reservedUserAttributes = map[string]bool{"firstName": true, "lastName": true}
+
+// UserAttributeService manages the typed user attribute schemas of tenants,
+// validates user metadata against them and resolves the attributes exposed to
+// policies as input.user.metadata
+type UserAttributeService struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
+	byTenant map[string]cachedUserAttributeSchema
+	cacheTTL time.Duration
+}
+
+// cachedUserAttributeSchema is a tenant's schema (or a negative lookup) held in memory
+type cachedUserAttributeSchema struct {
+	attributes map[string]UserAttributeDefinition
+	expiresAt  time.Time
+}
+
+// UserAttributeDefinition describes an attribute of a tenant's users
+type UserAttributeDefinition struct {
+	Name         string   `json:"name" validate:"required" example:"clearanceLevel"`
+	Type         string   `json:"type" validate:"required,oneof=string number integer boolean" example:"integer"`
+	Description  string   `json:"description,omitempty" validate:"max=255" example:"Highest document classification the user may read"`
+	Required     bool     `json:"required,omitempty" example:"false"`                      // Must be set when an admin updates the user's attributes
+	Values       []string `json:"values,omitempty" example:"[\"emea\",\"amer\",\"apac\"]"` // Allowed values of a string attribute
+	Minimum      *float64 `json:"minimum,omitempty" example:"0"`                           // Bounds of a number or integer attribute
+	Maximum      *float64 `json:"maximum,omitempty" example:"5"`
+	UserEditable bool     `json:"userEditable,omitempty" example:"false"` // Users may set it on their own profile
+}
+
+// UpsertUserAttributeSchemaRequest represents the desired user attribute schema of a tenant
+type UpsertUserAttributeSchemaRequest struct {
+	Attributes []UserAttributeDefinition `json:"attributes" validate:"required,max=50,dive"`
+}
+
+// UserAttributeSchemaResponse represents a tenant's user attribute schema
+type UserAttributeSchemaResponse struct {
+	TenantID   string                    `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Attributes []UserAttributeDefinition `json:"attributes"`
+	UpdatedAt  string                    `json:"updatedAt" example:"2024-01-20T14:45:00Z"`
+	ETag       string                    `json:"-"` // Sent in the ETag header for optimistic concurrency
+}
+
+// NewUserAttributeService creates a new user attribute service
+func NewUserAttributeService(db *gorm.DB) *UserAttributeService {
+	return &UserAttributeService{
+		db:       db,
+		byTenant: make(map[string]cachedUserAttributeSchema),
+		cacheTTL: time.Minute,
+	}
+}
+
+// GetSchema retrieves a tenant's user attribute schema
+func (s *UserAttributeService) GetSchema(ctx context.Context, tenantID uuid.UUID) (*UserAttributeSchemaResponse, error) {
+	var tenant models.Tenant
+	if err := readReplica(s.db).WithContext(ctx).First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	settings, err := tenantSettings(&tenant)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := userAttributeDefinitions(settings)
+	if err != nil {
+		return nil, err
+	}
+	if attributes == nil {
+		return nil, apperrors.NotFound("USER_ATTRIBUTE_SCHEMA_NOT_FOUND", "User attribute schema not found")
+	}
+	return toUserAttributeSchemaResponse(&tenant, attributes), nil
+}
+
+// UpsertSchema creates or replaces a tenant's user attribute schema and
+// reports whether it was created. Values already stored on users are checked
+// against the new schema the next time they are updated.
+func (s *UserAttributeService) UpsertSchema(ctx context.Context, tenantID uuid.UUID, req *UpsertUserAttributeSchemaRequest, pre Precondition) (*UserAttributeSchemaResponse, bool, error) {
+	if err := validateUserAttributeDefinitions(req.Attributes); err != nil {
+		return nil, false, err
+	}
+
+	var response *UserAttributeSchemaResponse
+	created := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return err
+		}
+		current, err := userAttributeDefinitions(settings)
+		if err != nil {
+			return err
+		}
+		created = current == nil
+
+		etag := ""
+		if !created {
+			etag = ResourceETag(tenant.UpdatedAt)
+		}
+		if err := pre.Check(etag); err != nil {
+			return err
+		}
+
+		settings[userAttributesSetting] = req.Attributes
+		if err := saveTenantSettings(tx, &tenant, settings); err != nil {
+			return err
+		}
+		response = toUserAttributeSchemaResponse(&tenant, req.Attributes)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.invalidate(tenantID.String())
+	return response, created, nil
+}
+
+// DeleteSchema removes a tenant's user attribute schema. Attribute values stay
+// in user metadata but are no longer validated or exposed to policies.
+func (s *UserAttributeService) DeleteSchema(ctx context.Context, tenantID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tenant models.Tenant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tenant, "id = ?", tenantID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("TENANT_NOT_FOUND", "Tenant not found")
+			}
+			return fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return err
+		}
+		if _, ok := settings[userAttributesSetting]; !ok {
+			return apperrors.NotFound("USER_ATTRIBUTE_SCHEMA_NOT_FOUND", "User attribute schema not found")
+		}
+
+		delete(settings, userAttributesSetting)
+		return saveTenantSettings(tx, &tenant, settings)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate(tenantID.String())
+	return nil
+}
+
+// UserAttributes returns the attributes of a user defined by the schema of the
+// user's tenant, for policy input, or nil when the tenant has no schema.
+// Schemas are cached for a minute on each instance; changes made through this
+// instance apply immediately.
+func (s *UserAttributeService) UserAttributes(ctx context.Context, tenantID, userID string) (map[string]interface{}, error) {
+	schema, err := s.tenantSchema(ctx, tenantID)
+	if err != nil || schema == nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, nil
+	}
+	var user models.User
+	err = readReplica(s.db).WithContext(ctx).Select("id", "metadata").First(&user, "id = ?", userUUID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	metadata := userMetadata(&user)
+	attributes := make(map[string]interface{}, len(schema))
+	for name := range schema {
+		if value, ok := metadata[name]; ok && value != nil {
+			attributes[name] = value
+		}
+	}
+	return attributes, nil
+}
+
+// CheckProfileMetadata validates metadata a user sets on their own profile.
+// Attributes of the tenant's schema must be user-editable and match their
+// definition; other keys are free-form.
+func (s *UserAttributeService) CheckProfileMetadata(ctx context.Context, tenantID uuid.UUID, updates map[string]interface{}) error {
+	schema, err := s.tenantSchema(ctx, tenantID.String())
+	if err != nil || schema == nil {
+		return err
+	}
+
+	var readOnly []string
+	for name := range updates {
+		if definition, ok := schema[name]; ok && !definition.UserEditable {
+			readOnly = append(readOnly, name)
+		}
+	}
+	if len(readOnly) > 0 {
+		return apperrors.Forbidden("USER_ATTRIBUTE_NOT_EDITABLE", "These attributes can only be set by an administrator").
+			WithDetails(map[string]interface{}{"attributes": readOnly})
+	}
+
+	return checkUserAttributes(schema, updates, nil)
+}
+
+// CheckAttributes validates attributes an admin sets on a user against the
+// schema of the user's tenant. updates only holds attributes of the schema,
+// with null values removing them, and the resulting metadata must have all
+// required attributes.
+func (s *UserAttributeService) CheckAttributes(ctx context.Context, tenantID uuid.UUID, updates, merged map[string]interface{}) error {
+	schema, err := s.tenantSchema(ctx, tenantID.String())
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return apperrors.NotFound("USER_ATTRIBUTE_SCHEMA_NOT_FOUND", "User attribute schema not found")
+	}
+
+	invalid := make(map[string]string)
+	for name := range updates {
+		if _, ok := schema[name]; !ok {
+			invalid[name] = "not defined by the tenant's schema"
+		}
+	}
+	if len(invalid) > 0 {
+		return apperrors.Validation("INVALID_USER_ATTRIBUTES", "User attributes do not match the tenant's schema").WithDetails(invalid)
+	}
+
+	return checkUserAttributes(schema, updates, merged)
+}
+
+// tenantSchema returns a tenant's attribute definitions by name, or nil when
+// it has no schema
+func (s *UserAttributeService) tenantSchema(ctx context.Context, tenantID string) (map[string]UserAttributeDefinition, error) {
+	s.mu.RLock()
+	cached, ok := s.byTenant[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.attributes, nil
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, nil
+	}
+
+	entry := cachedUserAttributeSchema{expiresAt: time.Now().Add(s.cacheTTL)}
+	var tenant models.Tenant
+	err = readReplica(s.db).WithContext(ctx).Select("id", "settings").First(&tenant, "id = ?", tenantUUID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	if err == nil {
+		settings, err := tenantSettings(&tenant)
+		if err != nil {
+			return nil, err
+		}
+		definitions, err := userAttributeDefinitions(settings)
+		if err != nil {
+			return nil, err
+		}
+		if definitions != nil {
+			entry.attributes = make(map[string]UserAttributeDefinition, len(definitions))
+			for _, definition := range definitions {
+				entry.attributes[definition.Name] = definition
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.byTenant[tenantID] = entry
+	s.mu.Unlock()
+
+	return entry.attributes, nil
+}
+
+func (s *UserAttributeService) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.byTenant, tenantID)
+	s.mu.Unlock()
+}
+
+// checkUserAttributes validates attribute values against their definitions.
+// Null values remove an attribute. When merged is given, it is the resulting
+// metadata, which must have every required attribute.
+func checkUserAttributes(schema map[string]UserAttributeDefinition, updates, merged map[string]interface{}) error {
+	invalid := make(map[string]string)
+	for name, value := range updates {
+		definition, ok := schema[name]
+		if !ok || value == nil {
+			continue
+		}
+		if reason := definition.check(value); reason != "" {
+			invalid[name] = reason
+		}
+	}
+	if merged != nil {
+		for name, definition := range schema {
+			if definition.Required && merged[name] == nil {
+				invalid[name] = "is required"
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return apperrors.Validation("INVALID_USER_ATTRIBUTES", "User attributes do not match the tenant's schema").WithDetails(invalid)
+	}
+	return nil
+}
+
+// check returns why a value does not match the definition, or "" if it does
+func (d *UserAttributeDefinition) check(value interface{}) string {
+	switch d.Type {
+	case UserAttributeTypeString:
+		text, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if len(d.Values) == 0 {
+			return ""
+		}
+		for _, allowed := range d.Values {
+			if text == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %v", d.Values)
+	case UserAttributeTypeNumber, UserAttributeTypeInteger:
+		number, ok := value.(float64)
+		if !ok {
+			return "must be a " + d.Type
+		}
+		if d.Type == UserAttributeTypeInteger && number != math.Trunc(number) {
+			return "must be an integer"
+		}
+		if d.Minimum != nil && number < *d.Minimum {
+			return fmt.Sprintf("must be at least %v", *d.Minimum)
+		}
+		if d.Maximum != nil && number > *d.Maximum {
+			return fmt.Sprintf("must be at most %v", *d.Maximum)
+		}
+		return ""
+	case UserAttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+		return ""
+	}
+	return "has an unknown type"
+}
+
+// validateUserAttributeDefinitions checks a schema's definitions are well-formed
+// and consistent with their types
+func validateUserAttributeDefinitions(definitions []UserAttributeDefinition) error {
+	invalid := make(map[string]string)
+	seen := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		name := definition.Name
+		switch {
+		case !userAttributeName.MatchString(name):
+			invalid[name] = "name must start with a letter and have at most 64 letters, digits or underscores"
+		case reservedUserAttributes[name]:
+			invalid[name] = "name is reserved"
+		case seen[name]:
+			invalid[name] = "is defined more than once"
+		case len(definition.Values) > 0 && definition.Type != UserAttributeTypeString:
+			invalid[name] = "only string attributes can have allowed values"
+		case (definition.Minimum != nil || definition.Maximum != nil) &&
+			definition.Type != UserAttributeTypeNumber && definition.Type != UserAttributeTypeInteger:
+			invalid[name] = "only number and integer attributes can have bounds"
+		case definition.Minimum != nil && definition.Maximum != nil && *definition.Minimum > *definition.Maximum:
+			invalid[name] = "minimum must not exceed maximum"
+		}
+		seen[name] = true
+	}
+	if len(invalid) > 0 {
+		return apperrors.Validation("INVALID_USER_ATTRIBUTE_SCHEMA", "Invalid user attribute definitions").WithDetails(invalid)
+	}
+	return nil
+}
+
+// validateUserAttributesSetting checks the user attribute schema of tenant
+// settings written directly, rather than through this service
+func validateUserAttributesSetting(settings map[string]interface{}) error {
+	definitions, err := userAttributeDefinitions(settings)
+	if err != nil {
+		return apperrors.Validation("INVALID_USER_ATTRIBUTES_SETTINGS", "The userAttributes setting must be an array of attribute definitions")
+	}
+	if definitions == nil {
+		return nil
+	}
+	for _, definition := range definitions {
+		switch definition.Type {
+		case UserAttributeTypeString, UserAttributeTypeNumber, UserAttributeTypeInteger, UserAttributeTypeBoolean:
+		default:
+			return apperrors.Validation("INVALID_USER_ATTRIBUTE_SCHEMA", "Invalid user attribute definitions").
+				WithDetails(map[string]string{definition.Name: "type must be string, number, integer or boolean"})
+		}
+	}
+	return validateUserAttributeDefinitions(definitions)
+}
+
+// userAttributeDefinitions extracts the user attribute schema from tenant
+// settings, or nil when the tenant has none
+func userAttributeDefinitions(settings map[string]interface{}) ([]UserAttributeDefinition, error) {
+	value, ok := settings[userAttributesSetting]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user attribute schema: %w", err)
+	}
+	definitions := []UserAttributeDefinition{}
+	if err := json.Unmarshal(encoded, &definitions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user attribute schema: %w", err)
+	}
+	return definitions, nil
+}
+
+// userMetadata decodes a user's metadata, returning an empty map when it has none
+func userMetadata(user *models.User) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if len(user.Metadata) > 0 {
+		if err := json.Unmarshal(user.Metadata, &metadata); err != nil || metadata == nil {
+			return make(map[string]interface{})
+		}
+	}
+	return metadata
+}
+
+func toUserAttributeSchemaResponse(tenant *models.Tenant, attributes []UserAttributeDefinition) *UserAttributeSchemaResponse {
+	return &UserAttributeSchemaResponse{
+		TenantID:   tenant.ID.String(),
+		Attributes: attributes,
+		UpdatedAt:  tenant.UpdatedAt.Format(time.RFC3339),
+		ETag:       ResourceETag(tenant.UpdatedAt),
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestUserAttributeService_Schema(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		attributeService := NewUserAttributeService(db)
+		_, fusionAuth := newFakeFusionAuth(t)
+		userService := NewUserService(db, fusionAuth)
+		userService.SetUserAttributeService(attributeService)
+
+		if _, err := attributeService.GetSchema(ctx, tenant.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Fatalf("Expected not found without a schema, got %v", err)
+		}
+		if attributes, err := attributeService.UserAttributes(ctx, tenant.ID.String(), user.ID.String()); err != nil || attributes != nil {
+			t.Fatalf("Expected no attributes without a schema, got %v, %v", attributes, err)
+		}
+		if err := attributeService.CheckProfileMetadata(ctx, tenant.ID, map[string]interface{}{"clearanceLevel": 5.0}); err != nil {
+			t.Fatalf("Expected free-form metadata without a schema, got %v", err)
+		}
+
+		var appErr *apperrors.Error
+		zero, five, one := 0.0, 5.0, 1.0
+		for _, invalid := range [][]UserAttributeDefinition{
+			{{Name: "1st", Type: UserAttributeTypeString}},
+			{{Name: "firstName", Type: UserAttributeTypeString}},
+			{{Name: "region", Type: UserAttributeTypeString}, {Name: "region", Type: UserAttributeTypeString}},
+			{{Name: "clearanceLevel", Type: UserAttributeTypeInteger, Values: []string{"1"}}},
+			{{Name: "region", Type: UserAttributeTypeString, Minimum: &zero}},
+			{{Name: "clearanceLevel", Type: UserAttributeTypeInteger, Minimum: &five, Maximum: &one}},
+		} {
+			_, _, err := attributeService.UpsertSchema(ctx, tenant.ID, &UpsertUserAttributeSchemaRequest{Attributes: invalid}, Precondition{})
+			if !errors.As(err, &appErr) || appErr.Code != "INVALID_USER_ATTRIBUTE_SCHEMA" {
+				t.Errorf("Expected INVALID_USER_ATTRIBUTE_SCHEMA for %+v, got %v", invalid, err)
+			}
+		}
+
+		schema, created, err := attributeService.UpsertSchema(ctx, tenant.ID, &UpsertUserAttributeSchemaRequest{Attributes: []UserAttributeDefinition{
+			{Name: "department", Type: UserAttributeTypeString, Required: true},
+			{Name: "clearanceLevel", Type: UserAttributeTypeInteger, Minimum: &zero, Maximum: &five},
+			{Name: "region", Type: UserAttributeTypeString, Values: []string{"emea", "amer"}, UserEditable: true},
+			{Name: "contractor", Type: UserAttributeTypeBoolean},
+		}}, Precondition{})
+		if err != nil || !created {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+		if _, _, err := attributeService.UpsertSchema(ctx, tenant.ID, &UpsertUserAttributeSchemaRequest{}, Precondition{IfNoneMatch: "*"}); !errors.As(err, &appErr) || appErr.Code != "RESOURCE_EXISTS" {
+			t.Errorf("Expected precondition failure for If-None-Match on an existing schema, got %v", err)
+		}
+		if fetched, err := attributeService.GetSchema(ctx, tenant.ID); err != nil || fetched.ETag != schema.ETag || len(fetched.Attributes) != 4 {
+			t.Errorf("Expected the created schema, got %+v, %v", fetched, err)
+		}
+
+		// Users may only set editable attributes on their own profile
+		if err := attributeService.CheckProfileMetadata(ctx, tenant.ID, map[string]interface{}{"clearanceLevel": 5.0}); !errors.As(err, &appErr) || appErr.Code != "USER_ATTRIBUTE_NOT_EDITABLE" {
+			t.Errorf("Expected USER_ATTRIBUTE_NOT_EDITABLE, got %v", err)
+		}
+		if err := attributeService.CheckProfileMetadata(ctx, tenant.ID, map[string]interface{}{"region": "apac"}); !errors.As(err, &appErr) || appErr.Code != "INVALID_USER_ATTRIBUTES" {
+			t.Errorf("Expected INVALID_USER_ATTRIBUTES for a value outside the allowed ones, got %v", err)
+		}
+		if err := attributeService.CheckProfileMetadata(ctx, tenant.ID, map[string]interface{}{"region": "emea", "nickname": "Al"}); err != nil {
+			t.Errorf("Expected editable attributes and free-form metadata to be accepted, got %v", err)
+		}
+
+		for _, invalid := range []map[string]interface{}{
+			{"department": "eng", "clearanceLevel": "high"},
+			{"department": "eng", "clearanceLevel": 2.5},
+			{"department": "eng", "clearanceLevel": 6.0},
+			{"department": "eng", "contractor": "yes"},
+			{"department": "eng", "team": "platform"},
+			{"clearanceLevel": 3.0}, // department is required
+		} {
+			if _, err := userService.UpdateUserAttributes(ctx, user.ID.String(), invalid); !errors.As(err, &appErr) || appErr.Code != "INVALID_USER_ATTRIBUTES" {
+				t.Errorf("Expected INVALID_USER_ATTRIBUTES for %v, got %v", invalid, err)
+			}
+		}
+		if _, err := userService.UpdateUserAttributes(ctx, uuid.NewString(), map[string]interface{}{"department": "eng"}); !errors.As(err, &appErr) || appErr.Code != "USER_NOT_FOUND" {
+			t.Errorf("Expected USER_NOT_FOUND, got %v", err)
+		}
+
+		if _, err := userService.UpdateUserAttributes(ctx, user.ID.String(), map[string]interface{}{
+			"department": "eng", "clearanceLevel": 3.0, "contractor": true,
+		}); err != nil {
+			t.Fatalf("UpdateUserAttributes returned error: %v", err)
+		}
+		profile, err := userService.UpdateUserAttributes(ctx, user.ID.String(), map[string]interface{}{"contractor": nil})
+		if err != nil {
+			t.Fatalf("UpdateUserAttributes returned error: %v", err)
+		}
+		if _, ok := profile.Metadata["contractor"]; ok {
+			t.Errorf("Expected a null value to remove the attribute, got %v", profile.Metadata)
+		}
+
+		// Policies see the attributes of the schema only
+		db.Exec(`UPDATE users SET metadata = '{"department":"eng","clearanceLevel":3,"nickname":"Al"}' WHERE id = ?`, user.ID)
+		attributes, err := attributeService.UserAttributes(ctx, tenant.ID.String(), user.ID.String())
+		if err != nil {
+			t.Fatalf("UserAttributes returned error: %v", err)
+		}
+		if len(attributes) != 2 || attributes["department"] != "eng" || attributes["clearanceLevel"] != 3.0 {
+			t.Errorf("Expected the schema's attributes, got %v", attributes)
+		}
+
+		if err := attributeService.DeleteSchema(ctx, tenant.ID); err != nil {
+			t.Fatalf("DeleteSchema returned error: %v", err)
+		}
+		if attributes, err := attributeService.UserAttributes(ctx, tenant.ID.String(), user.ID.String()); err != nil || attributes != nil {
+			t.Errorf("Expected no attributes after deleting the schema, got %v, %v", attributes, err)
+		}
+		if err := attributeService.DeleteSchema(ctx, tenant.ID); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found deleting a missing schema, got %v", err)
+		}
+	})
+}
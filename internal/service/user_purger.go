@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purgeBatchSize caps the users purged per run
+const purgeBatchSize = 100
+
+// UserPurger permanently deletes users that were deactivated longer ago than
+// the retention period, both locally and in the identity provider. Their audit
+// log entries are kept without the user.
+type UserPurger struct {
+	db        *gorm.DB
+	outbox    *OutboxProcessor
+	retention time.Duration
+}
+
+// NewUserPurger creates a new user purger
+func NewUserPurger(db *gorm.DB, identity auth.IdentityProvider, retention time.Duration) *UserPurger {
+	return &UserPurger{
+		db:        db,
+		outbox:    NewOutboxProcessor(db, identity, nil),
+		retention: retention,
+	}
+}
+
+// Run purges users every interval until ctx is cancelled
+func (p *UserPurger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.Purge(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to purge deactivated users: %v", err)
+		}
+		if purged > 0 {
+			log.Printf("Purged %d deactivated users", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the users whose retention period has passed and returns the
+// number deleted
+func (p *UserPurger) Purge(ctx context.Context) (int, error) {
+	var userIDs []uuid.UUID
+	if err := p.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("status = ? AND deleted_at < ?", models.UserStatusDeactivated, time.Now().Add(-p.retention)).
+		Order("deleted_at ASC").
+		Limit(purgeBatchSize).
+		Pluck("id", &userIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load deactivated users: %w", err)
+	}
+
+	purged := 0
+	for _, userID := range userIDs {
+		entry, err := p.purge(ctx, userID)
+		if err != nil {
+			return purged, err
+		}
+		if entry != nil {
+			p.outbox.Dispatch(ctx, entry)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// purge deletes a user and queues its deletion in the identity provider in the
+// same transaction. It returns no entry when the user was restored meanwhile.
+func (p *UserPurger) purge(ctx context.Context, userID uuid.UUID) (*models.OutboxEntry, error) {
+	var entry *models.OutboxEntry
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", userID, models.UserStatusDeactivated).
+			First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		// Rows referencing the user go first
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRole{}).Error; err != nil {
+			return fmt.Errorf("failed to purge user roles: %w", err)
+		}
+		if err := tx.Where("requester_id = ?", userID).Delete(&models.AccessRequest{}).Error; err != nil {
+			return fmt.Errorf("failed to purge access requests: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.LoginEvent{}).Error; err != nil {
+			return fmt.Errorf("failed to purge login history: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.LDAPIdentity{}).Error; err != nil {
+			return fmt.Errorf("failed to purge LDAP identity: %w", err)
+		}
+		if err := tx.Model(&models.AuditLog{}).Where("user_id = ?", userID).Update("user_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to detach audit logs: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&user).Error; err != nil {
+			return fmt.Errorf("failed to purge user: %w", err)
+		}
+
+		entry, err = enqueueOutbox(tx, OutboxOpDeleteUser, userID, nil, time.Now())
+		return err
+	})
+	return entry, err
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"gorm.io/gorm"
+)
+
+// Kinds of drift between FusionAuth and the users table
+const (
+	DriftMissingInFusionAuth = "missing_in_fusionauth" // Local user without a FusionAuth user
+	DriftMissingLocally      = "missing_locally"       // FusionAuth user registered to Heimdall without a local user
+	DriftDeletedLocally      = "deleted_locally"       // Deactivated user still active in FusionAuth
+	DriftEmailMismatch       = "email_mismatch"        // Local email differs from FusionAuth
+)
+
+const (
+	// reconcileGracePeriod skips recently created users, whose registration may
+	// still be in flight
+	reconcileGracePeriod = 10 * time.Minute
+
+	reconcilePageSize = 500
+)
+
+// UserDrift describes a user that differs between FusionAuth and the users table
+type UserDrift struct {
+	UserID   string `json:"userId"`
+	Email    string `json:"email"`
+	Kind     string `json:"kind"`
+	Repaired bool   `json:"repaired"`
+}
+
+// ReconcileReport summarizes a reconciliation run
+type ReconcileReport struct {
+	FusionAuthUsers int         `json:"fusionAuthUsers"`
+	LocalUsers      int         `json:"localUsers"`
+	Drift           []UserDrift `json:"drift"`
+}
+
+// UserReconciler detects and repairs drift between FusionAuth users and the
+// users table. FusionAuth is the source of truth for identities: users missing
+// from it are deleted locally, and FusionAuth users registered to Heimdall
+// without a local user are queued for deletion through the outbox. Deactivated
+// users keep a disabled FusionAuth user until they are purged.
+type UserReconciler struct {
+	db         *gorm.DB
+	fusionAuth *auth.FusionAuthClient
+	repair     bool
+}
+
+// NewUserReconciler creates a new user reconciler. Drift is only reported
+// unless repair is set.
+func NewUserReconciler(db *gorm.DB, fusionAuth *auth.FusionAuthClient, repair bool) *UserReconciler {
+	return &UserReconciler{
+		db:         db,
+		fusionAuth: fusionAuth,
+		repair:     repair,
+	}
+}
+
+// Run reconciles users every interval until ctx is cancelled
+func (r *UserReconciler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := r.Reconcile(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to reconcile users: %v", err)
+			}
+			continue
+		}
+		if len(report.Drift) > 0 {
+			log.Printf("User reconciliation found %d drifted users (%d FusionAuth users, %d local users)",
+				len(report.Drift), report.FusionAuthUsers, report.LocalUsers)
+		}
+	}
+}
+
+// localUser holds the columns of a user needed for reconciliation
+type localUser struct {
+	ID        uuid.UUID
+	Email     string
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+// Reconcile compares FusionAuth users with the users table and repairs drift
+// when enabled. Users with pending outbox entries are skipped, as the outbox
+// worker is still applying their changes, as are users provisioned from LDAP.
+func (r *UserReconciler) Reconcile(ctx context.Context) (*ReconcileReport, error) {
+	faUsers, err := r.fusionAuthUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locals []localUser
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Select("id", "email", "created_at", "deleted_at").
+		Find(&locals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	var pendingIDs []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&models.OutboxEntry{}).
+		Where("status = ?", models.OutboxStatusPending).
+		Distinct().
+		Pluck("aggregate_id", &pendingIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pending outbox entries: %w", err)
+	}
+	pending := make(map[uuid.UUID]bool, len(pendingIDs))
+	for _, id := range pendingIDs {
+		pending[id] = true
+	}
+
+	// Users provisioned from an LDAP directory are managed by the directory
+	var ldapIDs []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&models.LDAPIdentity{}).Pluck("user_id", &ldapIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load LDAP identities: %w", err)
+	}
+	external := make(map[uuid.UUID]bool, len(ldapIDs))
+	for _, id := range ldapIDs {
+		external[id] = true
+	}
+
+	report := &ReconcileReport{FusionAuthUsers: len(faUsers)}
+	cutoff := time.Now().Add(-reconcileGracePeriod)
+	seen := make(map[string]bool, len(locals))
+
+	for _, local := range locals {
+		id := local.ID.String()
+		seen[id] = true
+		if !local.DeletedAt.Valid {
+			report.LocalUsers++
+		}
+		if pending[local.ID] || external[local.ID] {
+			continue
+		}
+
+		faUser, inFusionAuth := faUsers[id]
+		switch {
+		case local.DeletedAt.Valid:
+			if inFusionAuth && faUser.Active && r.registered(faUser) {
+				report.add(id, local.Email, DriftDeletedLocally, r.repairWith(func() error {
+					_, err := enqueueOutbox(r.db.WithContext(ctx), OutboxOpUpdateUser, local.ID,
+						updateUserPayload{Updates: map[string]interface{}{"active": false}}, time.Now())
+					return err
+				}))
+			}
+		case !inFusionAuth:
+			if local.CreatedAt.Before(cutoff) {
+				report.add(id, local.Email, DriftMissingInFusionAuth, r.repairWith(func() error {
+					return r.db.WithContext(ctx).Delete(&models.User{}, "id = ?", local.ID).Error
+				}))
+			}
+		case faUser.Email != "" && !strings.EqualFold(faUser.Email, local.Email):
+			report.add(id, local.Email, DriftEmailMismatch, r.repairWith(func() error {
+				return r.db.WithContext(ctx).Model(&models.User{}).
+					Where("id = ?", local.ID).
+					Update("email", faUser.Email).Error
+			}))
+		}
+	}
+
+	for id, faUser := range faUsers {
+		if seen[id] || !r.registered(faUser) {
+			continue
+		}
+		userID, err := uuid.Parse(id)
+		if err != nil || pending[userID] {
+			continue
+		}
+		report.add(id, faUser.Email, DriftMissingLocally, r.repairWith(func() error {
+			return r.enqueueDelete(ctx, userID)
+		}))
+	}
+
+	return report, nil
+}
+
+// fusionAuthUsers loads every user of the FusionAuth tenant, keyed by ID
+func (r *UserReconciler) fusionAuthUsers(ctx context.Context) (map[string]auth.IdentityUser, error) {
+	users := map[string]auth.IdentityUser{}
+	for startRow := 0; ; startRow += reconcilePageSize {
+		page, total, err := r.fusionAuth.SearchUsers(ctx, startRow, reconcilePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list FusionAuth users: %w", err)
+		}
+		for _, user := range page {
+			users[user.ID] = user
+		}
+		if len(page) == 0 || startRow+len(page) >= total {
+			return users, nil
+		}
+	}
+}
+
+// registered reports whether a FusionAuth user is registered to Heimdall's
+// application. Other users of the tenant are never deleted.
+func (r *UserReconciler) registered(user auth.IdentityUser) bool {
+	for _, registration := range user.Registrations {
+		if registration.ApplicationID == r.fusionAuth.ApplicationID() {
+			return true
+		}
+	}
+	return false
+}
+
+// repairWith runs fix when repairs are enabled and reports whether it succeeded
+func (r *UserReconciler) repairWith(fix func() error) bool {
+	if !r.repair {
+		return false
+	}
+	if err := fix(); err != nil {
+		log.Printf("Failed to repair user drift: %v", err)
+		return false
+	}
+	return true
+}
+
+func (r *UserReconciler) enqueueDelete(ctx context.Context, userID uuid.UUID) error {
+	_, err := enqueueOutbox(r.db.WithContext(ctx), OutboxOpDeleteUser, userID, nil, time.Now())
+	return err
+}
+
+func (report *ReconcileReport) add(userID, email, kind string, repaired bool) {
+	report.Drift = append(report.Drift, UserDrift{
+		UserID:   userID,
+		Email:    email,
+		Kind:     kind,
+		Repaired: repaired,
+	})
+}
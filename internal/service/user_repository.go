@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +23,12 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// readOnly returns a copy of the repository that reads from a replica when
+// replicas are configured
+func (r *UserRepository) readOnly() *UserRepository {
+	return &UserRepository{db: readReplica(r.db)}
+}
+
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
@@ -54,12 +64,16 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
 }
 
-// GetUserRoles retrieves all roles for a user
+// activeRoleAssignment matches the user_roles rows that have not expired
+const activeRoleAssignment = "(user_roles.expires_at IS NULL OR user_roles.expires_at > ?)"
+
+// GetUserRoles retrieves all roles for a user, leaving out expired assignments
 func (r *UserRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]models.Role, error) {
 	var roles []models.Role
 	err := r.db.WithContext(ctx).
 		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
 		Where("user_roles.user_id = ?", userID).
+		Where(activeRoleAssignment, time.Now()).
 		Find(&roles).Error
 	if err != nil {
 		return nil, err
@@ -67,14 +81,35 @@ func (r *UserRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 	return roles, nil
 }
 
-// AssignRole assigns a role to a user
-func (r *UserRepository) AssignRole(ctx context.Context, userID, roleID, assignedBy uuid.UUID) error {
+// AssignRole assigns a role to a user until expiresAt, or permanently when
+// expiresAt is nil
+func (r *UserRepository) AssignRole(ctx context.Context, userID, roleID, assignedBy uuid.UUID, expiresAt *time.Time, reason string) (*models.UserRole, error) {
 	userRole := &models.UserRole{
 		UserID:     userID,
 		RoleID:     roleID,
 		AssignedBy: assignedBy,
+		ExpiresAt:  expiresAt,
+		Reason:     reason,
+	}
+	if err := r.db.WithContext(ctx).Create(userRole).Error; err != nil {
+		return nil, err
+	}
+	return userRole, nil
+}
+
+// GetRoleAssignments retrieves a user's role assignments with their roles,
+// including expired assignments that have not been removed yet
+func (r *UserRepository) GetRoleAssignments(ctx context.Context, userID uuid.UUID) ([]models.UserRole, error) {
+	var assignments []models.UserRole
+	err := r.db.WithContext(ctx).
+		Preload("Role").
+		Where("user_id = ?", userID).
+		Order("assigned_at ASC").
+		Find(&assignments).Error
+	if err != nil {
+		return nil, err
 	}
-	return r.db.WithContext(ctx).Create(userRole).Error
+	return assignments, nil
 }
 
 // RemoveRole removes a role from a user
@@ -92,6 +127,7 @@ func (r *UserRepository) GetUserPermissions(ctx context.Context, userID uuid.UUI
 		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
 		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
 		Where("user_roles.user_id = ?", userID).
+		Where(activeRoleAssignment, time.Now()).
 		Find(&permissions).Error
 	if err != nil {
 		return nil, err
@@ -107,6 +143,7 @@ func (r *UserRepository) HasPermission(ctx context.Context, userID uuid.UUID, pe
 		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
 		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
 		Where("user_roles.user_id = ? AND permissions.name = ?", userID, permissionName).
+		Where(activeRoleAssignment, time.Now()).
 		Count(&count).Error
 
 	if err != nil {
@@ -115,37 +152,59 @@ func (r *UserRepository) HasPermission(ctx context.Context, userID uuid.UUID, pe
 	return count > 0, nil
 }
 
-// ListUsers retrieves a paginated list of users for a tenant
-func (r *UserRepository) ListUsers(ctx context.Context, tenantID uuid.UUID, page, pageSize int) ([]models.User, int64, error) {
-	var users []models.User
-	var total int64
-
-	// Count total
-	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("tenant_id = ?", tenantID).Count(&total).Error; err != nil {
-		return nil, 0, err
+// ListUsers retrieves a page of the users of a tenant matching a search.
+// Deactivated users are only listed when filtering by their status.
+func (r *UserRepository) ListUsers(ctx context.Context, tenantID uuid.UUID, search *UserSearch, params *pagination.Params) ([]models.User, *pagination.Page, error) {
+	db := r.db
+	if params.Status == models.UserStatusDeactivated {
+		db = db.Unscoped()
 	}
+	query := db.Model(&models.User{}).Where("tenant_id = ?", tenantID)
 
-	// Get paginated results
-	offset := (page - 1) * pageSize
-	err := r.db.WithContext(ctx).
-		Where("tenant_id = ?", tenantID).
-		Offset(offset).
-		Limit(pageSize).
-		Find(&users).Error
-
-	if err != nil {
-		return nil, 0, err
+	if search.Query != "" {
+		term := strings.ToLower(likeEscaper.Replace(search.Query))
+		emailPattern := term + "%"
+		if search.Fuzzy {
+			emailPattern = "%" + term + "%"
+		}
+		firstName := "lower(" + jsonText(r.db, "metadata", "firstName") + ")"
+		lastName := "lower(" + jsonText(r.db, "metadata", "lastName") + ")"
+		like := "LIKE ?" + likeEscape(r.db)
+		query = query.Where(
+			"(lower(email) "+like+" OR "+firstName+" "+like+" OR "+lastName+" "+like+" OR "+
+				firstName+" || ' ' || "+lastName+" "+like+")",
+			emailPattern, term+"%", term+"%", term+"%")
 	}
+	if search.Role != "" {
+		query = query.Where("id IN (?)", r.db.Model(&models.UserRole{}).
+			Select("user_roles.user_id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ?", search.Role).
+			Where(activeRoleAssignment, time.Now()))
+	}
+	if search.LastLoginAfter != nil {
+		query = query.Where("last_login_at >= ?", *search.LastLoginAfter)
+	}
+	if search.LastLoginBefore != nil {
+		query = query.Where("last_login_at < ?", *search.LastLoginBefore)
+	}
+	query = applyRowFilter(query, search.RowFilter, userRowFilterColumn(r.db))
 
-	return users, total, nil
+	return pagination.Paginate[models.User](ctx, query, params, UserListOptions)
 }
 
 // UpdateMetadata updates user metadata
 func (r *UserRepository) UpdateMetadata(ctx context.Context, userID uuid.UUID, metadata map[string]interface{}) error {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	// Updated from a struct so the metadata is encrypted
 	return r.db.WithContext(ctx).
 		Model(&models.User{}).
 		Where("id = ?", userID).
-		Update("metadata", metadata).Error
+		Select("metadata").
+		Updates(&models.User{Metadata: encoded}).Error
 }
 
 // BulkCreate creates multiple users
@@ -3,46 +3,101 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
 	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/database"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/opa"
+	"github.com/techsavvyash/heimdall/internal/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserService handles user-related business logic
 type UserService struct {
 	db             *gorm.DB
-	fusionAuth     *auth.FusionAuthClient
+	identity       auth.IdentityProvider
 	userRepository *UserRepository
+	outbox         *OutboxProcessor
+	rbacSync       *RBACDataSync
+	redis          *database.RedisClient
+	accessTokenTTL time.Duration
+	attributes     *UserAttributeService
+	decisionCache  DecisionCacheInvalidator
+	maxElevation   time.Duration
+}
+
+// DecisionCacheInvalidator drops the cached authorization decisions of a user
+type DecisionCacheInvalidator interface {
+	InvalidateUserCache(ctx context.Context, userID string) error
 }
 
 // NewUserService creates a new user service
-func NewUserService(db *gorm.DB, fusionAuth *auth.FusionAuthClient) *UserService {
+func NewUserService(db *gorm.DB, identity auth.IdentityProvider) *UserService {
 	return &UserService{
 		db:             db,
-		fusionAuth:     fusionAuth,
+		identity:       identity,
+		outbox:         NewOutboxProcessor(db, identity, nil),
 		userRepository: NewUserRepository(db),
 	}
 }
 
+// SetRBACDataSync pushes role assignment changes to OPA
+func (s *UserService) SetRBACDataSync(rbacSync *RBACDataSync) {
+	s.rbacSync = rbacSync
+}
+
+// SetSessionStore enables revoking the sessions of suspended users. Access
+// tokens issued before the suspension are rejected until they expire after
+// accessTokenTTL.
+func (s *UserService) SetSessionStore(redis *database.RedisClient, accessTokenTTL time.Duration) {
+	s.redis = redis
+	s.accessTokenTTL = accessTokenTTL
+}
+
+// SetUserAttributeService validates user metadata against the user attribute
+// schemas of tenants
+func (s *UserService) SetUserAttributeService(attributes *UserAttributeService) {
+	s.attributes = attributes
+}
+
+// SetDecisionCache invalidates the cached decisions of users whose role
+// assignments change
+func (s *UserService) SetDecisionCache(cache DecisionCacheInvalidator) {
+	s.decisionCache = cache
+}
+
+// SetMaxElevation caps the duration of temporary elevated access
+func (s *UserService) SetMaxElevation(maxElevation time.Duration) {
+	s.maxElevation = maxElevation
+}
+
 // UserProfile represents a user profile
 type UserProfile struct {
-	ID         string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Email      string                 `json:"email" example:"user@example.com"`
-	FirstName  string                 `json:"firstName,omitempty" example:"John"`
-	LastName   string                 `json:"lastName,omitempty" example:"Doe"`
-	TenantID   string                 `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Metadata   map[string]interface{} `json:"metadata,omitempty"`
-	Roles      []string               `json:"roles,omitempty" example:"[\"user\",\"admin\"]"`
-	LoginCount int                    `json:"loginCount" example:"42"`
-	CreatedAt  string                 `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	ID            string                 `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email         string                 `json:"email" example:"user@example.com"`
+	FirstName     string                 `json:"firstName,omitempty" example:"John"`
+	LastName      string                 `json:"lastName,omitempty" example:"Doe"`
+	TenantID      string                 `json:"tenantId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Roles         []string               `json:"roles,omitempty" example:"[\"user\",\"admin\"]"`
+	Status        string                 `json:"status" example:"active"` // active, suspended or deactivated
+	LoginCount    int                    `json:"loginCount" example:"42"`
+	LastLoginAt   string                 `json:"lastLoginAt,omitempty" example:"2024-01-20T08:15:00Z"`
+	CreatedAt     string                 `json:"createdAt" example:"2024-01-15T10:30:00Z"`
+	DeactivatedAt string                 `json:"deactivatedAt,omitempty" example:"2024-02-01T09:00:00Z"` // Deactivated users can be restored until they are purged
 }
 
 // UpdateProfileRequest represents a profile update request
 type UpdateProfileRequest struct {
-	FirstName *string                `json:"firstName,omitempty" example:"Jane"`
-	LastName  *string                `json:"lastName,omitempty" example:"Smith"`
+	FirstName *string                `json:"firstName,omitempty" validate:"omitempty,min=1,max=100" example:"Jane"`
+	LastName  *string                `json:"lastName,omitempty" validate:"omitempty,min=1,max=100" example:"Smith"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -50,26 +105,37 @@ type UpdateProfileRequest struct {
 func (s *UserService) GetUserProfile(ctx context.Context, userID string) (*UserProfile, error) {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
 	}
 
 	// Get user from database
-	user, err := s.userRepository.GetByID(ctx, uid)
+	users := s.userRepository.readOnly()
+	user, err := users.GetByID(ctx, uid)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Get user from FusionAuth for additional details
-	faUser, err := s.fusionAuth.GetUser(userID)
+	return s.toUserProfile(ctx, users, user), nil
+}
+
+// toUserProfile combines a user with its identity provider details and roles
+func (s *UserService) toUserProfile(ctx context.Context, users *UserRepository, user *models.User) *UserProfile {
+	userID := user.ID.String()
+
+	// Get user from the identity provider for additional details
+	identityUser, err := s.identity.GetUser(ctx, userID)
 	if err != nil {
-		// If FusionAuth fails, continue with database data
-		faUser = &auth.FusionAuthUser{
+		// If the provider fails, continue with database data
+		identityUser = &auth.IdentityUser{
 			Email: user.Email,
 		}
 	}
 
 	// Get user roles
-	roles, _ := s.userRepository.GetUserRoles(ctx, uid)
+	roles, _ := users.GetUserRoles(ctx, user.ID)
 	roleNames := make([]string, len(roles))
 	for i, role := range roles {
 		roleNames[i] = role.Name
@@ -85,38 +151,60 @@ func (s *UserService) GetUserProfile(ctx context.Context, userID string) (*UserP
 		}
 	}
 
-	// Override with FusionAuth data if available
-	if faUser.FirstName != "" {
-		firstName = faUser.FirstName
+	// Override with identity provider data if available
+	if identityUser.FirstName != "" {
+		firstName = identityUser.FirstName
 	}
-	if faUser.LastName != "" {
-		lastName = faUser.LastName
+	if identityUser.LastName != "" {
+		lastName = identityUser.LastName
 	}
 
 	return &UserProfile{
-		ID:         user.ID.String(),
-		Email:      user.Email,
-		FirstName:  firstName,
-		LastName:   lastName,
-		TenantID:   user.TenantID.String(),
-		Metadata:   metadataMap,
-		Roles:      roleNames,
-		LoginCount: user.LoginCount,
-		CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-	}, nil
+		ID:            user.ID.String(),
+		Email:         user.Email,
+		FirstName:     firstName,
+		LastName:      lastName,
+		TenantID:      user.TenantID.String(),
+		Metadata:      metadataMap,
+		Roles:         roleNames,
+		Status:        user.Status,
+		LoginCount:    user.LoginCount,
+		LastLoginAt:   lastLoginAt(user),
+		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		DeactivatedAt: deactivatedAt(user),
+	}
+}
+
+// lastLoginAt formats the time of a user's last login
+func lastLoginAt(user *models.User) string {
+	if user.LastLoginAt == nil {
+		return ""
+	}
+	return user.LastLoginAt.Format("2006-01-02T15:04:05Z07:00")
+}
+
+// deactivatedAt formats the deactivation time of a deactivated user
+func deactivatedAt(user *models.User) string {
+	if !user.DeletedAt.Valid {
+		return ""
+	}
+	return user.DeletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
 }
 
 // UpdateUserProfile updates the user's profile
 func (s *UserService) UpdateUserProfile(ctx context.Context, userID string, req *UpdateProfileRequest) (*UserProfile, error) {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
 	}
 
 	// Get current user
 	user, err := s.userRepository.GetByID(ctx, uid)
 	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Unmarshal existing metadata
@@ -129,27 +217,24 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID string, req
 		metadataMap = make(map[string]interface{})
 	}
 
-	// Prepare updates for FusionAuth
-	faUpdates := make(map[string]interface{})
+	// Prepare updates for the identity provider
+	identityUpdates := make(map[string]interface{})
 	if req.FirstName != nil {
-		faUpdates["firstName"] = *req.FirstName
+		identityUpdates["firstName"] = *req.FirstName
 		metadataMap["firstName"] = *req.FirstName
 	}
 	if req.LastName != nil {
-		faUpdates["lastName"] = *req.LastName
+		identityUpdates["lastName"] = *req.LastName
 		metadataMap["lastName"] = *req.LastName
 	}
 
-	// Update in FusionAuth if there are changes
-	if len(faUpdates) > 0 {
-		_, err = s.fusionAuth.UpdateUser(userID, faUpdates)
-		if err != nil {
-			return nil, fmt.Errorf("failed to update user in FusionAuth: %w", err)
-		}
-	}
-
 	// Update metadata in database
 	if req.Metadata != nil {
+		if s.attributes != nil {
+			if err := s.attributes.CheckProfileMetadata(ctx, user.TenantID, req.Metadata); err != nil {
+				return nil, err
+			}
+		}
 		for k, v := range req.Metadata {
 			metadataMap[k] = v
 		}
@@ -162,45 +247,253 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID string, req
 	}
 	user.Metadata = metadataJSON
 
-	// Save to database
-	if err := s.userRepository.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+	// Save to database and queue the identity provider update in the same transaction
+	var entry *models.OutboxEntry
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if len(identityUpdates) > 0 {
+			var err error
+			entry, err = enqueueOutbox(tx, OutboxOpUpdateUser, uid, updateUserPayload{Updates: identityUpdates}, time.Now())
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		s.outbox.Dispatch(ctx, entry)
 	}
 
-	// Return updated profile
-	return s.GetUserProfile(ctx, userID)
+	// Return the updated profile without re-reading it from a lagging replica
+	return s.toUserProfile(ctx, s.userRepository, user), nil
 }
 
-// DeleteUser deletes a user account
-func (s *UserService) DeleteUser(ctx context.Context, userID string) error {
+// UpdateUserAttributes sets attributes of the tenant's user attribute schema
+// on a user's metadata (admin function). Null values remove an attribute.
+func (s *UserService) UpdateUserAttributes(ctx context.Context, userID string, attributes map[string]interface{}) (*UserProfile, error) {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+	if s.attributes == nil {
+		return nil, apperrors.NotFound("USER_ATTRIBUTE_SCHEMA_NOT_FOUND", "User attribute schema not found")
+	}
+
+	var user models.User
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", uid).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		metadata := userMetadata(&user)
+		for name, value := range attributes {
+			if value == nil {
+				delete(metadata, name)
+			} else {
+				metadata[name] = value
+			}
+		}
+		if err := s.attributes.CheckAttributes(ctx, user.TenantID, attributes, metadata); err != nil {
+			return err
+		}
+
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		user.Metadata = encoded
+		if err := tx.Model(&user).Select("metadata").Updates(&models.User{Metadata: encoded}).Error; err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Delete from FusionAuth
-	if err := s.fusionAuth.DeleteUser(userID); err != nil {
-		return fmt.Errorf("failed to delete user from FusionAuth: %w", err)
+	return s.toUserProfile(ctx, s.userRepository, &user), nil
+}
+
+// DeactivateUser deactivates a user account. The user is soft deleted and
+// disabled in the identity provider, so it can no longer sign in, and can be
+// restored until it is purged after the retention period.
+func (s *UserService) DeactivateUser(ctx context.Context, userID string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
 	}
 
-	// Soft delete from database
-	if err := s.userRepository.Delete(ctx, uid); err != nil {
-		return fmt.Errorf("failed to delete user from database: %w", err)
+	// Soft delete from database and queue disabling the identity in the same transaction
+	var entry *models.OutboxEntry
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", uid).Update("status", models.UserStatusDeactivated).Error; err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+		result := tx.Delete(&models.User{}, "id = ?", uid)
+		if result.Error != nil {
+			return fmt.Errorf("failed to deactivate user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		var err error
+		entry, err = enqueueOutbox(tx, OutboxOpUpdateUser, uid, updateUserPayload{Updates: map[string]interface{}{"active": false}}, time.Now())
+		return err
+	})
+	if err != nil {
+		return err
 	}
+	s.outbox.Dispatch(ctx, entry)
+	s.rolesChanged(ctx, uid)
 
 	return nil
 }
 
-// ListUsers retrieves a list of users (admin function)
-func (s *UserService) ListUsers(ctx context.Context, tenantID string, page, pageSize int) ([]UserProfile, int64, error) {
+// RestoreUser reactivates a deactivated user account that has not been purged
+func (s *UserService) RestoreUser(ctx context.Context, userID string) (*UserProfile, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+
+	var user models.User
+	var entry *models.OutboxEntry
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", uid).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user.Status != models.UserStatusDeactivated {
+			return apperrors.Conflict("USER_NOT_DEACTIVATED", "User is not deactivated")
+		}
+
+		user.Status = models.UserStatusActive
+		user.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Model(&user).Select("status", "deleted_at").Updates(&user).Error; err != nil {
+			return fmt.Errorf("failed to restore user: %w", err)
+		}
+		entry, err = enqueueOutbox(tx, OutboxOpUpdateUser, uid, updateUserPayload{Updates: map[string]interface{}{"active": true}}, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.outbox.Dispatch(ctx, entry)
+	s.rolesChanged(ctx, uid)
+
+	return s.toUserProfile(ctx, s.userRepository, &user), nil
+}
+
+// UpdateUserStatusRequest represents a user status change
+type UpdateUserStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active suspended" example:"suspended"`
+}
+
+// UpdateUserStatus suspends or reactivates a user account. Suspended users
+// cannot sign in or refresh tokens, and their existing sessions are revoked.
+func (s *UserService) UpdateUserStatus(ctx context.Context, userID, status string) (*UserProfile, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+	if status != models.UserStatusActive && status != models.UserStatusSuspended {
+		return nil, apperrors.Validation("INVALID_STATUS", "Status must be active or suspended")
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", uid).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NotFound("USER_NOT_FOUND", "User not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Status != status {
+		user.Status = status
+		if err := s.db.WithContext(ctx).Model(&user).Update("status", status).Error; err != nil {
+			return nil, fmt.Errorf("failed to update user status: %w", err)
+		}
+		s.rolesChanged(ctx, uid)
+	}
+
+	// Repeated when the status is unchanged, so retrying repairs a failed revocation
+	if status == models.UserStatusSuspended && s.redis != nil {
+		if err := s.redis.RevokeUserSessions(ctx, userID, time.Now(), s.accessTokenTTL); err != nil {
+			return nil, fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+		if err := s.redis.RevokeAllUserTokens(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+	}
+
+	return s.toUserProfile(ctx, s.userRepository, &user), nil
+}
+
+// UserSearch narrows the users listed, on top of the status and creation time
+// filters of pagination.Params
+type UserSearch struct {
+	Query           string // Prefix of the email, first name, last name or full name
+	Fuzzy           bool   // Match the query anywhere in the email
+	Role            string // Name of a role the users have
+	LastLoginAfter  *time.Time
+	LastLoginBefore *time.Time
+	RowFilter       *opa.RowFilter // Rows the caller may read by policy, nil when unrestricted
+}
+
+// ParseUserSearch reads a user search from a request's query string: query,
+// fuzzy, role, lastLoginAfter and lastLoginBefore (RFC 3339)
+func ParseUserSearch(query pagination.QueryFunc) (*UserSearch, error) {
+	search := &UserSearch{
+		Query: strings.TrimSpace(query("query")),
+		Fuzzy: query("fuzzy") == "true",
+		Role:  query("role"),
+	}
+
+	for key, target := range map[string]**time.Time{"lastLoginAfter": &search.LastLoginAfter, "lastLoginBefore": &search.LastLoginBefore} {
+		value := query(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, apperrors.Validation("INVALID_FILTER", key+" must be an RFC 3339 timestamp").WithCause(err)
+		}
+		*target = &parsed
+	}
+
+	return search, nil
+}
+
+// UserListOptions describes the sorting and filtering supported when listing users
+var UserListOptions = pagination.Options{
+	SortFields: map[string]string{
+		"createdAt": "created_at",
+		"updatedAt": "updated_at",
+		"email":     "email",
+	},
+	DefaultSort:  "-createdAt",
+	StatusColumn: "status",
+}
+
+// ListUsers retrieves a page of the users matching a search (admin function)
+func (s *UserService) ListUsers(ctx context.Context, tenantID string, search *UserSearch, params *pagination.Params) ([]UserProfile, *pagination.Page, error) {
 	tid, err := uuid.Parse(tenantID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("invalid tenant ID: %w", err)
+		return nil, nil, apperrors.Validation("INVALID_TENANT_ID", "Invalid tenant ID").WithCause(err)
 	}
 
-	users, total, err := s.userRepository.ListUsers(ctx, tid, page, pageSize)
+	users, page, err := s.userRepository.readOnly().ListUsers(ctx, tid, search, params)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		return nil, nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
 	profiles := make([]UserProfile, len(users))
@@ -215,24 +508,27 @@ func (s *UserService) ListUsers(ctx context.Context, tenantID string, page, page
 		}
 
 		profiles[i] = UserProfile{
-			ID:         user.ID.String(),
-			Email:      user.Email,
-			FirstName:  firstName,
-			LastName:   lastName,
-			TenantID:   user.TenantID.String(),
-			LoginCount: user.LoginCount,
-			CreatedAt:  user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:            user.ID.String(),
+			Email:         user.Email,
+			FirstName:     firstName,
+			LastName:      lastName,
+			TenantID:      user.TenantID.String(),
+			Status:        user.Status,
+			LoginCount:    user.LoginCount,
+			LastLoginAt:   lastLoginAt(&user),
+			CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			DeactivatedAt: deactivatedAt(&user),
 		}
 	}
 
-	return profiles, total, nil
+	return profiles, page, nil
 }
 
 // GetUserPermissions retrieves all permissions for a user
 func (s *UserService) GetUserPermissions(ctx context.Context, userID string) ([]string, error) {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
 	}
 
 	permissions, err := s.userRepository.GetUserPermissions(ctx, uid)
@@ -248,37 +544,211 @@ func (s *UserService) GetUserPermissions(ctx context.Context, userID string) ([]
 	return permissionNames, nil
 }
 
-// AssignRoleToUser assigns a role to a user
-func (s *UserService) AssignRoleToUser(ctx context.Context, userID, roleID, assignedByID string) error {
+// ElevateRoleRequest represents a request for temporary elevated access
+type ElevateRoleRequest struct {
+	RoleID          string `json:"roleId" validate:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	DurationMinutes int    `json:"durationMinutes" validate:"required,min=1" example:"480"`
+	Reason          string `json:"reason" validate:"required,min=1,max=500" example:"On-call for incident INC-1234"`
+}
+
+// RoleAssignmentResponse represents a role assigned to a user
+type RoleAssignmentResponse struct {
+	ID         string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	RoleID     string `json:"roleId" example:"550e8400-e29b-41d4-a716-446655440001"`
+	RoleName   string `json:"roleName" example:"admin"`
+	AssignedBy string `json:"assignedBy,omitempty" example:"550e8400-e29b-41d4-a716-446655440002"`
+	AssignedAt string `json:"assignedAt" example:"2024-01-20T08:00:00Z"`
+	ExpiresAt  string `json:"expiresAt,omitempty" example:"2024-01-20T16:00:00Z"` // Temporary assignments are removed at this time
+	Reason     string `json:"reason,omitempty" example:"On-call for incident INC-1234"`
+	Expired    bool   `json:"expired"` // Expired, awaiting removal
+}
+
+// AssignRoleToUser assigns a role to a user until expiresAt, or permanently
+// when expiresAt is nil. Assigning a role the user already has replaces the
+// expiry of the assignment.
+func (s *UserService) AssignRoleToUser(ctx context.Context, userID, roleID, assignedByID string, expiresAt *time.Time) error {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
 	}
 
 	rid, err := uuid.Parse(roleID)
 	if err != nil {
-		return fmt.Errorf("invalid role ID: %w", err)
+		return apperrors.Validation("INVALID_ROLE_ID", "Invalid role ID").WithCause(err)
 	}
 
 	aid, err := uuid.Parse(assignedByID)
 	if err != nil {
-		return fmt.Errorf("invalid assigned by ID: %w", err)
+		return apperrors.Validation("INVALID_ASSIGNED_BY_ID", "Invalid assigned by ID").WithCause(err)
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return apperrors.Validation("INVALID_EXPIRY", "Expiry must be in the future")
+	}
+
+	if _, err := s.assignRole(ctx, s.db, uid, rid, aid, expiresAt, "", true); err != nil {
+		return err
+	}
+	s.rolesChanged(ctx, uid)
+	return nil
+}
+
+// ElevateRole grants a role to a user for a limited time, e.g. admin to an
+// on-call engineer for 8 hours. The assignment is removed automatically once
+// it expires. Users who already have the role permanently cannot be elevated.
+func (s *UserService) ElevateRole(ctx context.Context, userID, grantedByID string, req *ElevateRoleRequest) (*RoleAssignmentResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+
+	rid, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_ROLE_ID", "Invalid role ID").WithCause(err)
+	}
+
+	gid, err := uuid.Parse(grantedByID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_ASSIGNED_BY_ID", "Invalid assigned by ID").WithCause(err)
+	}
+
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if s.maxElevation > 0 && duration > s.maxElevation {
+		return nil, apperrors.Validation("ELEVATION_TOO_LONG", "Elevated access cannot exceed the maximum duration").
+			WithDetails(map[string]interface{}{"maxDurationMinutes": int(s.maxElevation / time.Minute)})
 	}
 
-	return s.userRepository.AssignRole(ctx, uid, rid, aid)
+	expiresAt := time.Now().Add(duration)
+	assignment, err := s.assignRole(ctx, s.db, uid, rid, gid, &expiresAt, req.Reason, false)
+	if err != nil {
+		return nil, err
+	}
+	s.rolesChanged(ctx, uid)
+	return toRoleAssignmentResponse(assignment), nil
+}
+
+// assignRole creates or updates a user's assignment of a role of the user's
+// tenant. Permanent assignments are only replaced when replacePermanent is set.
+// Callers push the change with rolesChanged once db's transaction, if any, commits.
+func (s *UserService) assignRole(ctx context.Context, db *gorm.DB, userID, roleID, assignedBy uuid.UUID, expiresAt *time.Time, reason string, replacePermanent bool) (*models.UserRole, error) {
+	var assignment *models.UserRole
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id", "tenant_id").First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("USER_NOT_FOUND", "User not found")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		var role models.Role
+		if err := tx.Where("id = ? AND tenant_id = ?", roleID, user.TenantID).First(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperrors.NotFound("ROLE_NOT_FOUND", "Role not found")
+			}
+			return fmt.Errorf("failed to get role: %w", err)
+		}
+
+		var existing models.UserRole
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND role_id = ?", userID, roleID).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			assignment, err = NewUserRepository(tx).AssignRole(ctx, userID, roleID, assignedBy, expiresAt, reason)
+			if err != nil {
+				return fmt.Errorf("failed to assign role: %w", err)
+			}
+			assignment.Role = role
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get role assignment: %w", err)
+		}
+		if existing.ExpiresAt == nil && !replacePermanent {
+			return apperrors.Conflict("ROLE_ALREADY_ASSIGNED", "User already has the role permanently")
+		}
+
+		existing.AssignedBy = assignedBy
+		existing.AssignedAt = time.Now()
+		existing.ExpiresAt = expiresAt
+		existing.Reason = reason
+		if err := tx.Model(&existing).Select("assigned_by", "assigned_at", "expires_at", "reason").Updates(&existing).Error; err != nil {
+			return fmt.Errorf("failed to update role assignment: %w", err)
+		}
+		existing.Role = role
+		assignment = &existing
+		return nil
+	})
+	return assignment, err
+}
+
+// GetRoleAssignments lists the roles assigned to a user with their expiry
+func (s *UserService) GetRoleAssignments(ctx context.Context, userID string) ([]RoleAssignmentResponse, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
+	}
+
+	assignments, err := NewUserRepository(readReplica(s.db)).GetRoleAssignments(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role assignments: %w", err)
+	}
+
+	responses := make([]RoleAssignmentResponse, len(assignments))
+	for i := range assignments {
+		responses[i] = *toRoleAssignmentResponse(&assignments[i])
+	}
+	return responses, nil
+}
+
+func toRoleAssignmentResponse(assignment *models.UserRole) *RoleAssignmentResponse {
+	response := &RoleAssignmentResponse{
+		ID:         assignment.ID.String(),
+		RoleID:     assignment.RoleID.String(),
+		RoleName:   assignment.Role.Name,
+		AssignedAt: assignment.AssignedAt.UTC().Format(time.RFC3339),
+		Reason:     assignment.Reason,
+	}
+	if assignment.AssignedBy != uuid.Nil {
+		response.AssignedBy = assignment.AssignedBy.String()
+	}
+	if assignment.ExpiresAt != nil {
+		response.ExpiresAt = assignment.ExpiresAt.UTC().Format(time.RFC3339)
+		response.Expired = !assignment.ExpiresAt.After(time.Now())
+	}
+	return response
 }
 
 // RemoveRoleFromUser removes a role from a user
 func (s *UserService) RemoveRoleFromUser(ctx context.Context, userID, roleID string) error {
 	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %w", err)
+		return apperrors.Validation("INVALID_USER_ID", "Invalid user ID").WithCause(err)
 	}
 
 	rid, err := uuid.Parse(roleID)
 	if err != nil {
-		return fmt.Errorf("invalid role ID: %w", err)
+		return apperrors.Validation("INVALID_ROLE_ID", "Invalid role ID").WithCause(err)
+	}
+
+	if err := s.userRepository.RemoveRole(ctx, uid, rid); err != nil {
+		return err
 	}
+	s.rolesChanged(ctx, uid)
+	return nil
+}
 
-	return s.userRepository.RemoveRole(ctx, uid, rid)
+// rolesChanged drops the user's cached decisions and pushes the roles of the
+// user's tenant to OPA
+func (s *UserService) rolesChanged(ctx context.Context, userID uuid.UUID) {
+	if s.decisionCache != nil {
+		s.decisionCache.InvalidateUserCache(ctx, userID.String())
+	}
+	if s.rbacSync == nil {
+		return
+	}
+	var user models.User
+	if err := s.db.WithContext(ctx).Unscoped().Select("tenant_id").First(&user, "id = ?", userID).Error; err != nil {
+		return
+	}
+	s.rbacSync.TenantChanged(ctx, user.TenantID)
 }
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techsavvyash/heimdall/internal/apperrors"
+	"github.com/techsavvyash/heimdall/internal/auth"
+	"github.com/techsavvyash/heimdall/internal/models"
+	"github.com/techsavvyash/heimdall/internal/pagination"
+	"github.com/techsavvyash/heimdall/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestUserService_ListUsers_Search(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		other := testutil.CreateTestTenant(t, db, "Globex", "globex")
+		alice := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		bob := testutil.CreateTestUser(t, db, tenant, "bob.alison@acme.com")
+		carol := testutil.CreateTestUser(t, db, tenant, "carol_1@acme.com")
+		testutil.CreateTestUser(t, db, other, "alice@globex.com")
+
+		db.Model(&models.User{}).Where("id = ?", alice.ID).Update("metadata", `{"firstName":"Alice","lastName":"Smith"}`)
+		db.Model(&models.User{}).Where("id = ?", bob.ID).Update("metadata", `{"firstName":"Bob","lastName":"Alison"}`)
+		db.Model(&models.User{}).Where("id = ?", alice.ID).Update("last_login_at", time.Now().Add(-48*time.Hour))
+		db.Model(&models.User{}).Where("id = ?", bob.ID).Update("last_login_at", time.Now().Add(-time.Hour))
+
+		admin := testutil.CreateTestRole(t, db, tenant, "admin")
+		testutil.AssignRoleToUser(t, db, carol, admin)
+
+		userService := NewUserService(db, nil)
+		search := func(rawQuery string) []string {
+			t.Helper()
+			values, _ := url.ParseQuery(rawQuery)
+			query := func(key string, defaultValue ...string) string {
+				if value := values.Get(key); value != "" {
+					return value
+				}
+				if len(defaultValue) > 0 {
+					return defaultValue[0]
+				}
+				return ""
+			}
+			params, err := pagination.Parse(query, UserListOptions)
+			if err != nil {
+				t.Fatalf("Failed to parse pagination of %q: %v", rawQuery, err)
+			}
+			userSearch, err := ParseUserSearch(query)
+			if err != nil {
+				t.Fatalf("Failed to parse search %q: %v", rawQuery, err)
+			}
+			users, _, err := userService.ListUsers(ctx, tenant.ID.String(), userSearch, params)
+			if err != nil {
+				t.Fatalf("ListUsers(%q) returned error: %v", rawQuery, err)
+			}
+			emails := make([]string, len(users))
+			for i, user := range users {
+				emails[i] = user.Email
+			}
+			sort.Strings(emails)
+			return emails
+		}
+
+		lastLogin := url.QueryEscape(time.Now().Add(-24 * time.Hour).Format(time.RFC3339))
+		for rawQuery, want := range map[string][]string{
+			"":                                    {"alice@acme.com", "bob.alison@acme.com", "carol_1@acme.com"},
+			"query=ALI":                           {"alice@acme.com", "bob.alison@acme.com"}, // Email prefix and last name
+			"query=bob+ali":                       {"bob.alison@acme.com"},                   // Full name
+			"query=smith":                         {"alice@acme.com"},
+			"query=lison":                         {},
+			"query=lison&fuzzy=true":              {"bob.alison@acme.com"},
+			"query=_":                             {}, // Wildcards match literally
+			"query=carol_&fuzzy=true":             {"carol_1@acme.com"},
+			"role=admin":                          {"carol_1@acme.com"},
+			"lastLoginBefore=" + lastLogin:        {"alice@acme.com"},
+			"lastLoginAfter=" + lastLogin:         {"bob.alison@acme.com"},
+			"query=a&lastLoginAfter=" + lastLogin: {"bob.alison@acme.com"},
+		} {
+			got := search(rawQuery)
+			if len(got) != len(want) {
+				t.Errorf("Expected %q to find %v, got %v", rawQuery, want, got)
+				continue
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("Expected %q to find %v, got %v", rawQuery, want, got)
+					break
+				}
+			}
+		}
+
+		var appErr *apperrors.Error
+		_, err := ParseUserSearch(func(key string, defaultValue ...string) string {
+			if key == "lastLoginAfter" {
+				return "yesterday"
+			}
+			return ""
+		})
+		if !errors.As(err, &appErr) || appErr.Code != "INVALID_FILTER" {
+			t.Errorf("Expected INVALID_FILTER for an invalid lastLoginAfter, got %v", err)
+		}
+	})
+}
+
+func TestUserService_UpdateUserStatus(t *testing.T) {
+	testutil.WithTestDB(t, func(t *testing.T, db *gorm.DB) {
+		testutil.TruncateTables(t, db)
+		ctx := testutil.CreateTestContext(t)
+
+		jwtService, cleanup := testutil.CreateTestJWTService(t)
+		defer cleanup()
+		_, fusionAuth := newFakeFusionAuth(t)
+		authService := NewAuthService(db, fusionAuth, jwtService, nil, nil, nil)
+		userService := NewUserService(db, fusionAuth)
+
+		tenant := testutil.CreateTestTenant(t, db, "Acme", "acme")
+		user := testutil.CreateTestUser(t, db, tenant, "alice@acme.com")
+		identityUser := &auth.IdentityUser{ID: user.ID.String(), Email: user.Email}
+
+		session, err := authService.LoginExternal(ctx, identityUser, &LoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("LoginExternal returned error: %v", err)
+		}
+
+		profile, err := userService.UpdateUserStatus(ctx, user.ID.String(), models.UserStatusSuspended)
+		if err != nil {
+			t.Fatalf("UpdateUserStatus returned error: %v", err)
+		}
+		if profile.Status != models.UserStatusSuspended {
+			t.Errorf("Expected status suspended, got %q", profile.Status)
+		}
+
+		// Suspended users can neither sign in nor refresh their tokens
+		var appErr *apperrors.Error
+		if _, err := authService.LoginExternal(ctx, identityUser, &LoginRequest{Email: user.Email}); !errors.As(err, &appErr) || appErr.Code != "USER_SUSPENDED" {
+			t.Errorf("Expected USER_SUSPENDED on login, got %v", err)
+		}
+		if _, err := authService.RefreshToken(ctx, &RefreshTokenRequest{RefreshToken: session.RefreshToken}); !errors.As(err, &appErr) || appErr.Code != "USER_SUSPENDED" {
+			t.Errorf("Expected USER_SUSPENDED on refresh, got %v", err)
+		}
+
+		if _, err := userService.UpdateUserStatus(ctx, user.ID.String(), models.UserStatusDeactivated); !errors.As(err, &appErr) || appErr.Code != "INVALID_STATUS" {
+			t.Errorf("Expected INVALID_STATUS, got %v", err)
+		}
+		if _, err := userService.UpdateUserStatus(ctx, uuid.NewString(), models.UserStatusActive); !errors.As(err, &appErr) || appErr.Code != "USER_NOT_FOUND" {
+			t.Errorf("Expected USER_NOT_FOUND, got %v", err)
+		}
+
+		if _, err := userService.UpdateUserStatus(ctx, user.ID.String(), models.UserStatusActive); err != nil {
+			t.Fatalf("UpdateUserStatus returned error: %v", err)
+		}
+		if _, err := authService.LoginExternal(ctx, identityUser, &LoginRequest{Email: user.Email}); err != nil {
+			t.Errorf("Expected reactivated user to sign in, got %v", err)
+		}
+	})
+}
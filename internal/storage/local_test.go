@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "bundles")
+	store, err := New(&config.BundleStorageConfig{Backend: config.BundleStorageLocal, LocalDir: dir})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if err := store.EnsureBucket(ctx); err != nil {
+		t.Fatalf("EnsureBucket returned error: %v", err)
+	}
+	if store.Bucket() != dir {
+		t.Errorf("Bucket() = %q, want %q", store.Bucket(), dir)
+	}
+
+	key := "bundles/heimdall-1.0.0.tar.gz"
+	if err := store.Put(ctx, key, []byte("v1"), "application/gzip"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if err := store.Put(ctx, key, []byte("v2"), "application/gzip"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	object, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	data, _ := io.ReadAll(object)
+	object.Close()
+	if string(data) != "v2" {
+		t.Errorf("Get returned %q, want the replaced object", data)
+	}
+	if err := store.Put(ctx, "audit-archive/2024-01-15.jsonl.gz", []byte("x"), "application/gzip"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	objects, err := store.List(ctx, "bundles/")
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != key || objects[0].Size != 2 || objects[0].LastModified.IsZero() {
+		t.Errorf("List returned %+v, want only the bundle", objects)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing object returned error: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get of a deleted object returned %v, want ErrObjectNotFound", err)
+	}
+
+	for _, key := range []string{"../escape", "bundles/../../escape", ""} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err == nil {
+			t.Errorf("Put(%q) succeeded, want an error for a key outside the directory", key)
+		}
+	}
+}
+
+func TestNew_UnknownBackend(t *testing.T) {
+	if _, err := New(&config.BundleStorageConfig{Backend: "ftp"}); err == nil {
+		t.Error("New succeeded for an unknown backend")
+	}
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+const (
+	// gcsEndpoint is the S3-compatible XML API of Google Cloud Storage
+	gcsEndpoint = "storage.googleapis.com"
+
+	// minioDefaultRegion is the region MinIO servers use unless configured otherwise
+	minioDefaultRegion = "us-east-1"
+)
+
+// S3Store stores objects in an S3-compatible bucket: MinIO, AWS S3 or GCS
+type S3Store struct {
+	client  *minio.Client
+	presign *minio.Client // Client of the endpoint presigned URLs point at
+	bucket  string
+	region  string
+	create  bool // Whether EnsureBucket creates a missing bucket
+}
+
+// NewMinIOStore creates a store on a MinIO server, which creates its bucket on startup
+func NewMinIOStore(cfg *config.MinIOConfig) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
+	}
+
+	// Presigned URLs are signed for the host they are used with, which differs
+	// from the endpoint when MinIO is only reachable by the server internally
+	presign := client
+	if cfg.PublicEndpoint != "" {
+		presign, err = minio.New(cfg.PublicEndpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+			Secure: cfg.PublicUseSSL,
+			// Signing needs the region, which must not be looked up from the public endpoint
+			Region: minioDefaultRegion,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MinIO presigning client: %w", err)
+		}
+	}
+	return &S3Store{client: client, presign: presign, bucket: cfg.Bucket, create: true}, nil
+}
+
+// NewS3Store creates a store on AWS S3. Static keys are used when configured,
+// otherwise the standard AWS credential chain: AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY, the shared credentials file, then the IAM role of the
+// EC2 instance, ECS task or EKS service account. The bucket must already exist.
+func NewS3Store(cfg *config.S3Config) (*S3Store, error) {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("s3.%s.amazonaws.com", cfg.Region)
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+	})
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: true,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return &S3Store{client: client, presign: client, bucket: cfg.Bucket, region: cfg.Region}, nil
+}
+
+// NewGCSStore creates a store on Google Cloud Storage, using HMAC keys of a
+// service account with its S3-compatible XML API. The bucket must already exist.
+func NewGCSStore(cfg *config.GCSConfig) (*S3Store, error) {
+	client, err := minio.New(gcsEndpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	return &S3Store{client: client, presign: client, bucket: cfg.Bucket}, nil
+}
+
+// Bucket returns the name of the bucket
+func (s *S3Store) Bucket() string {
+	return s.bucket
+}
+
+// EnsureBucket creates the bucket on MinIO, and checks that it exists elsewhere
+func (s *S3Store) EnsureBucket(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	if !s.create {
+		return fmt.Errorf("bucket %s does not exist", s.bucket)
+	}
+
+	if err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: s.region}); err != nil {
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
+	return nil
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	// GetObject is lazy, so check the object exists to report a missing one now
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+// PresignGet returns a presigned GET URL of an object
+func (s *S3Store) PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	presigned, err := s.presign.PresignedGetObject(ctx, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return presigned.String(), nil
+}
+
+// Delete removes an object
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// List lists the objects under a prefix
+func (s *S3Store) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		objects = append(objects, ObjectInfo{Key: object.Key, Size: object.Size, LastModified: object.LastModified})
+	}
+	return objects, nil
+}
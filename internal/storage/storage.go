@@ -0,0 +1,69 @@
+// Package storage stores policy bundles in object storage
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/techsavvyash/heimdall/internal/config"
+)
+
+var (
+	// ErrObjectNotFound is returned when an object does not exist
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrPresignNotSupported is returned by backends that cannot issue presigned URLs
+	ErrPresignNotSupported = errors.New("presigned URLs are not supported by this storage backend")
+)
+
+// ObjectStore stores objects by key in a single bucket or directory
+type ObjectStore interface {
+	// Bucket names where objects are stored, recorded alongside each bundle
+	Bucket() string
+
+	// EnsureBucket creates the bucket if the backend manages it, or checks that it exists
+	EnsureBucket(ctx context.Context) error
+
+	// Put stores an object, replacing any existing object with the same key
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Get opens an object for reading, or returns ErrObjectNotFound. The object
+	// is seekable so byte ranges can be served from it.
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, error)
+
+	// PresignGet returns a URL that downloads an object without credentials
+	// until it expires, or ErrPresignNotSupported
+	PresignGet(ctx context.Context, key string, expiry time.Duration) (string, error)
+
+	// Delete removes an object, succeeding if it does not exist
+	Delete(ctx context.Context, key string) error
+
+	// List returns the objects whose keys start with a prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// New creates the object store of the configured backend
+func New(cfg *config.BundleStorageConfig) (ObjectStore, error) {
+	switch cfg.Backend {
+	case config.BundleStorageMinIO, "":
+		return NewMinIOStore(&cfg.MinIO)
+	case config.BundleStorageS3:
+		return NewS3Store(&cfg.S3)
+	case config.BundleStorageGCS:
+		return NewGCSStore(&cfg.GCS)
+	case config.BundleStorageLocal:
+		return NewLocalStore(cfg.LocalDir)
+	default:
+		return nil, fmt.Errorf("unknown bundle storage backend %q", cfg.Backend)
+	}
+}
@@ -4,23 +4,42 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/techsavvyash/heimdall/internal/database"
 	"github.com/techsavvyash/heimdall/internal/models"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// SetupTestDB creates a test database connection
-func SetupTestDB(t *testing.T) *gorm.DB {
+// SetupTestDB creates a test database connection. Tests run against a fresh SQLite
+// database by default; set TEST_DB_DRIVER=postgres (and optionally TEST_DATABASE_DSN)
+// to run them against PostgreSQL.
+func SetupTestDB(t testing.TB) *gorm.DB {
 	t.Helper()
 
-	// Use a test database
-	dsn := "host=localhost port=5432 user=heimdall password=heimdall_password dbname=heimdall_test sslmode=disable"
+	driver := os.Getenv("TEST_DB_DRIVER")
+	if driver == "" {
+		driver = database.DriverSQLite
+	}
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" && driver == database.DriverSQLite {
+		dsn = filepath.Join(t.TempDir(), "heimdall_test.db")
+	} else if dsn == "" {
+		// Use a test database
+		dsn = "host=localhost port=5432 user=heimdall password=heimdall_password dbname=heimdall_test sslmode=disable"
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	dialector, err := database.Dialector(driver, dsn)
+	if err != nil {
+		t.Fatalf("Failed to configure test database: %v", err)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent), // Silent during tests
 	})
 	if err != nil {
@@ -36,11 +55,12 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 }
 
 // CleanupTestDB cleans up the test database
-func CleanupTestDB(t *testing.T, db *gorm.DB) {
+func CleanupTestDB(t testing.TB, db *gorm.DB) {
 	t.Helper()
 
 	// Drop all tables
 	tables := []interface{}{
+		&models.OutboxEntry{},
 		&models.AuditLog{},
 		&models.RolePermission{},
 		&models.UserRole{},
@@ -56,6 +76,11 @@ func CleanupTestDB(t *testing.T, db *gorm.DB) {
 		}
 	}
 
+	// Reset the schema version so the next run recreates the dropped tables
+	if err := db.Migrator().DropTable(database.MigrationsTable); err != nil {
+		t.Logf("Warning: Failed to drop table: %v", err)
+	}
+
 	sqlDB, _ := db.DB()
 	if sqlDB != nil {
 		sqlDB.Close()
@@ -63,10 +88,29 @@ func CleanupTestDB(t *testing.T, db *gorm.DB) {
 }
 
 // TruncateTables truncates all tables (faster than dropping)
-func TruncateTables(t *testing.T, db *gorm.DB) {
+func TruncateTables(t testing.TB, db *gorm.DB) {
 	t.Helper()
 
 	tables := []string{
+		"password_histories",
+		"leader_leases",
+		"jobs",
+		"action_nonces",
+		"alerts",
+		"alert_rules",
+		"outbox_entries",
+		"opa_instances",
+		"auth_activity_stats",
+		"platform_signing_keys",
+		"access_requests",
+		"oauth_clients",
+		"resources",
+		"device_authorizations",
+		"tenant_rate_limits",
+		"ldap_identities",
+		"user_credentials",
+		"tenant_saml_configs",
+		"tenant_claims_templates",
 		"audit_logs",
 		"role_permissions",
 		"user_roles",
@@ -77,12 +121,16 @@ func TruncateTables(t *testing.T, db *gorm.DB) {
 	}
 
 	for _, table := range tables {
+		if database.IsSQLite(db) {
+			db.Exec(fmt.Sprintf("DELETE FROM %s", table))
+			continue
+		}
 		db.Exec(fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table))
 	}
 }
 
 // CreateTestTenant creates a test tenant
-func CreateTestTenant(t *testing.T, db *gorm.DB, name, slug string) *models.Tenant {
+func CreateTestTenant(t testing.TB, db *gorm.DB, name, slug string) *models.Tenant {
 	t.Helper()
 
 	settingsJSON, _ := json.Marshal(map[string]interface{}{
@@ -105,7 +153,7 @@ func CreateTestTenant(t *testing.T, db *gorm.DB, name, slug string) *models.Tena
 }
 
 // CreateTestUser creates a test user
-func CreateTestUser(t *testing.T, db *gorm.DB, tenant *models.Tenant, email string) *models.User {
+func CreateTestUser(t testing.TB, db *gorm.DB, tenant *models.Tenant, email string) *models.User {
 	t.Helper()
 
 	metadataJSON, _ := json.Marshal(map[string]interface{}{
@@ -113,6 +161,7 @@ func CreateTestUser(t *testing.T, db *gorm.DB, tenant *models.Tenant, email stri
 		"lastName":  "User",
 	})
 	user := &models.User{
+		ID:       uuid.New(),
 		TenantID: tenant.ID,
 		Email:    email,
 		Metadata: metadataJSON,
@@ -126,7 +175,7 @@ func CreateTestUser(t *testing.T, db *gorm.DB, tenant *models.Tenant, email stri
 }
 
 // CreateTestRole creates a test role
-func CreateTestRole(t *testing.T, db *gorm.DB, tenant *models.Tenant, name string) *models.Role {
+func CreateTestRole(t testing.TB, db *gorm.DB, tenant *models.Tenant, name string) *models.Role {
 	t.Helper()
 
 	role := &models.Role{
@@ -144,7 +193,7 @@ func CreateTestRole(t *testing.T, db *gorm.DB, tenant *models.Tenant, name strin
 }
 
 // CreateTestPermission creates a test permission
-func CreateTestPermission(t *testing.T, db *gorm.DB, name, resource, action string) *models.Permission {
+func CreateTestPermission(t testing.TB, db *gorm.DB, name, resource, action string) *models.Permission {
 	t.Helper()
 
 	permission := &models.Permission{
@@ -164,7 +213,7 @@ func CreateTestPermission(t *testing.T, db *gorm.DB, name, resource, action stri
 }
 
 // AssignRoleToUser assigns a role to a user
-func AssignRoleToUser(t *testing.T, db *gorm.DB, user *models.User, role *models.Role) {
+func AssignRoleToUser(t testing.TB, db *gorm.DB, user *models.User, role *models.Role) {
 	t.Helper()
 
 	userRole := &models.UserRole{
@@ -178,7 +227,7 @@ func AssignRoleToUser(t *testing.T, db *gorm.DB, user *models.User, role *models
 }
 
 // AssignPermissionToRole assigns a permission to a role
-func AssignPermissionToRole(t *testing.T, db *gorm.DB, role *models.Role, permission *models.Permission) {
+func AssignPermissionToRole(t testing.TB, db *gorm.DB, role *models.Role, permission *models.Permission) {
 	t.Helper()
 
 	rolePermission := &models.RolePermission{
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/middleware"
 )
 
 // HTTPTestCase represents a test case for HTTP endpoints
@@ -25,18 +26,7 @@ type HTTPTestCase struct {
 // CreateTestApp creates a Fiber app for testing
 func CreateTestApp() *fiber.App {
 	return fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-			return c.Status(code).JSON(fiber.Map{
-				"success": false,
-				"error": fiber.Map{
-					"message": err.Error(),
-				},
-			})
-		},
+		ErrorHandler: middleware.ErrorHandler,
 	})
 }
 
@@ -68,6 +58,9 @@ func MakeRequest(t *testing.T, app *fiber.App, method, path string, body interfa
 
 	recorder := httptest.NewRecorder()
 	recorder.Code = resp.StatusCode
+	for key, values := range resp.Header {
+		recorder.Header()[key] = values
+	}
 
 	// Copy response body
 	bodyBytes, _ := io.ReadAll(resp.Body)
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the size of the table used to compare texts line by line
+const maxDiffCells = 4_000_000
+
+// LineDiff returns a unified-style line diff turning oldText into newText.
+// Removed lines are prefixed with "-", added lines with "+" and unchanged lines with a space.
+// It returns an empty string when both texts are equal.
+func LineDiff(oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+	if len(oldLines)*len(newLines) > maxDiffCells {
+		return fmt.Sprintf("- (%d lines)\n+ (%d lines)\n", len(oldLines), len(newLines))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			b.WriteString("  " + oldLines[i] + "\n")
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			b.WriteString("- " + oldLines[i] + "\n")
+			i++
+		default:
+			b.WriteString("+ " + newLines[j] + "\n")
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		b.WriteString("- " + oldLines[i] + "\n")
+	}
+	for ; j < len(newLines); j++ {
+		b.WriteString("+ " + newLines[j] + "\n")
+	}
+
+	return b.String()
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
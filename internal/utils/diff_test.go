@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"added file", "", "a\nb\n", "+ a\n+ b\n"},
+		{"removed file", "a\n", "", "- a\n"},
+		{"changed line", "a\nb\nc\n", "a\nx\nc\n", "  a\n- b\n+ x\n  c\n"},
+		{"inserted line", "a\nc", "a\nb\nc", "  a\n+ b\n  c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LineDiff(tt.old, tt.new); got != tt.want {
+				t.Errorf("LineDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// RedirectAllowed reports whether a URL logins send tokens to is one of the
+// allowed URLs. The scheme and host, including the port, must match exactly and
+// the path must be the allowed path or below it, so look-alike hosts such as
+// https://app.example.com.evil.io or https://app.example.com@evil.io do not
+// match https://app.example.com.
+func RedirectAllowed(redirect string, allowed []string) bool {
+	// Browsers read backslashes as slashes, which url.Parse does not
+	if strings.Contains(redirect, `\`) {
+		return false
+	}
+	target, err := url.Parse(redirect)
+	if err != nil || target.Scheme == "" || target.Host == "" || target.User != nil || target.Opaque != "" {
+		return false
+	}
+	targetPath := path.Clean("/" + target.Path)
+
+	for _, entry := range allowed {
+		allowedURL, err := url.Parse(entry)
+		if err != nil || allowedURL.Scheme == "" || allowedURL.Host == "" {
+			continue
+		}
+		if !strings.EqualFold(target.Scheme, allowedURL.Scheme) || !strings.EqualFold(target.Host, allowedURL.Host) {
+			continue
+		}
+		allowedPath := strings.TrimSuffix(path.Clean("/"+allowedURL.Path), "/")
+		if allowedPath == "" || targetPath == allowedPath || strings.HasPrefix(targetPath, allowedPath+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -8,33 +11,84 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+
+	// Report fields by their JSON names so error details match the request body
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
 }
 
-// ValidateStruct validates a struct using validator tags
+// ValidateStruct validates a struct using validator tags.
+// Errors are keyed by the field's JSON path, e.g. "name" or "resource.type".
 func ValidateStruct(s interface{}) map[string]string {
 	err := validate.Struct(s)
 	if err == nil {
 		return nil
 	}
 
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return map[string]string{"body": err.Error()}
+	}
+
 	errors := make(map[string]string)
-	for _, err := range err.(validator.ValidationErrors) {
-		field := err.Field()
-		tag := err.Tag()
-
-		switch tag {
-		case "required":
-			errors[field] = field + " is required"
-		case "email":
-			errors[field] = field + " must be a valid email address"
-		case "min":
-			errors[field] = field + " must be at least " + err.Param() + " characters"
-		case "max":
-			errors[field] = field + " must be at most " + err.Param() + " characters"
-		default:
-			errors[field] = field + " is invalid"
-		}
+	for _, err := range validationErrors {
+		errors[fieldPath(err)] = fieldMessage(err)
 	}
 
 	return errors
 }
+
+// fieldPath returns the JSON path of a field without the top-level struct name
+func fieldPath(err validator.FieldError) string {
+	namespace := err.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return err.Field()
+}
+
+// fieldMessage returns a human readable message for a failed validation
+func fieldMessage(err validator.FieldError) string {
+	field := err.Field()
+
+	switch err.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "uuid", "uuid4":
+		return field + " must be a valid UUID"
+	case "url":
+		return field + " must be a valid URL"
+	case "oneof":
+		return field + " must be one of: " + strings.Join(strings.Fields(err.Param()), ", ")
+	case "min", "gte":
+		return field + " must be at least " + err.Param() + sizeUnit(err.Kind())
+	case "max", "lte":
+		return field + " must be at most " + err.Param() + sizeUnit(err.Kind())
+	case "len":
+		return field + " must be exactly " + err.Param() + sizeUnit(err.Kind())
+	default:
+		return field + " is invalid"
+	}
+}
+
+// sizeUnit describes what a size constraint counts for the given kind
+func sizeUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// WaitContext waits for wg, or until ctx is done, in which case it returns
+// ctx's error and leaves the remaining goroutines running
+func WaitContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -25,6 +25,9 @@ curl -s -X PUT "http://localhost:8181/v1/policies/time_based" --data-binary "@po
 echo "Loading tenant_isolation.rego..."
 curl -s -X PUT "http://localhost:8181/v1/policies/tenant_isolation" --data-binary "@policies/tenant_isolation.rego"
 
+echo "Loading admin_api.rego..."
+curl -s -X PUT "http://localhost:8181/v1/policies/admin_api" --data-binary "@policies/admin_api.rego"
+
 echo ""
 echo "✅ All policies loaded successfully!"
 echo ""
@@ -0,0 +1,3916 @@
+// Code generated by genclient from the OpenAPI specification. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AccessRequest is the AccessRequest schema of the Heimdall API
+type AccessRequest struct {
+	CreatedAt       string `json:"createdAt"`
+	DecidedAt       string `json:"decidedAt,omitempty"`
+	DecidedBy       string `json:"decidedBy,omitempty"`
+	DecisionComment string `json:"decisionComment,omitempty"`
+	DurationMinutes int    `json:"durationMinutes"`
+	GrantedUntil    string `json:"grantedUntil,omitempty"`
+	ID              string `json:"id"`
+	Justification   string `json:"justification"`
+	RequesterEmail  string `json:"requesterEmail,omitempty"`
+	RequesterID     string `json:"requesterId"`
+	RoleID          string `json:"roleId"`
+	RoleName        string `json:"roleName,omitempty"`
+	Status          string `json:"status"`
+	TenantID        string `json:"tenantId"`
+}
+
+// AccessRequestDecision is the AccessRequestDecision schema of the Heimdall API
+type AccessRequestDecision struct {
+	Comment         *string `json:"comment,omitempty"`
+	DurationMinutes *int    `json:"durationMinutes,omitempty"`
+}
+
+// ActionNonce is the ActionNonce schema of the Heimdall API
+type ActionNonce struct {
+	Action     string `json:"action"`
+	ExpiresAt  string `json:"expiresAt"`
+	Nonce      string `json:"nonce"`
+	ResourceID string `json:"resourceId"`
+}
+
+// ActionNonceRequest is the ActionNonceRequest schema of the Heimdall API
+type ActionNonceRequest struct {
+	Action     string `json:"action"`
+	ResourceID string `json:"resourceId"`
+}
+
+// ActiveBundleRevision is the ActiveBundleRevision schema of the Heimdall API
+type ActiveBundleRevision struct {
+	ActivatedAt string `json:"activatedAt,omitempty"`
+	BundleID    string `json:"bundleId"`
+	Name        string `json:"name"`
+	Revision    string `json:"revision,omitempty"`
+	TenantID    string `json:"tenantId"`
+	Version     string `json:"version"`
+}
+
+// AdminAction is the AdminAction schema of the Heimdall API
+type AdminAction struct {
+	Action     string                 `json:"action"`
+	CreatedAt  string                 `json:"createdAt"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	DurationMs int                    `json:"durationMs"`
+	ID         string                 `json:"id"`
+	IPAddress  string                 `json:"ipAddress,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	Resource   string                 `json:"resource,omitempty"`
+	ResourceID string                 `json:"resourceId,omitempty"`
+	Status     string                 `json:"status"`
+	StatusCode int                    `json:"statusCode"`
+	TenantID   string                 `json:"tenantId"`
+	UserAgent  string                 `json:"userAgent,omitempty"`
+	UserID     string                 `json:"userId,omitempty"`
+}
+
+// AdminOverview is the AdminOverview schema of the Heimdall API
+type AdminOverview struct {
+	Bundles           BundleOverview     `json:"bundles"`
+	ErrorRates        OverviewErrorRates `json:"errorRates"`
+	FailedDeployments []FailedDeployment `json:"failedDeployments"`
+	GeneratedAt       string             `json:"generatedAt"`
+	Opa               OPAOverview        `json:"opa"`
+	Tenants           StatusCounts       `json:"tenants"`
+	Users             UserOverview       `json:"users"`
+}
+
+// Alert is the Alert schema of the Heimdall API
+type Alert struct {
+	CreatedAt string  `json:"createdAt"`
+	ID        string  `json:"id"`
+	Message   string  `json:"message"`
+	RuleID    string  `json:"ruleId"`
+	Subject   string  `json:"subject,omitempty"`
+	Threshold float64 `json:"threshold"`
+	Value     float64 `json:"value"`
+}
+
+// AlertRule is the AlertRule schema of the Heimdall API
+type AlertRule struct {
+	CreatedAt           string  `json:"createdAt"`
+	Enabled             bool    `json:"enabled"`
+	ID                  string  `json:"id"`
+	MinEvents           int     `json:"minEvents,omitempty"`
+	Name                string  `json:"name"`
+	PagerDutyConfigured bool    `json:"pagerDutyConfigured"`
+	Severity            string  `json:"severity"`
+	TenantID            string  `json:"tenantId"`
+	Threshold           float64 `json:"threshold"`
+	Type                string  `json:"type"`
+	UpdatedAt           string  `json:"updatedAt"`
+	WebhookConfigured   bool    `json:"webhookConfigured"`
+	WindowMinutes       int     `json:"windowMinutes"`
+}
+
+// AlertRuleRequest is the AlertRuleRequest schema of the Heimdall API
+type AlertRuleRequest struct {
+	Enabled             *bool   `json:"enabled,omitempty"`
+	MinEvents           *int    `json:"minEvents,omitempty"`
+	Name                string  `json:"name"`
+	PagerDutyRoutingKey *string `json:"pagerDutyRoutingKey,omitempty"`
+	Severity            *string `json:"severity,omitempty"`
+	Threshold           float64 `json:"threshold"`
+	Type                string  `json:"type"`
+	WebhookURL          *string `json:"webhookUrl,omitempty"`
+	WindowMinutes       int     `json:"windowMinutes"`
+}
+
+// AssignRoleToUserRequest is the AssignRoleToUserRequest schema of the Heimdall API
+type AssignRoleToUserRequest struct {
+	// Remove the assignment at this time, permanent when omitted
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	RoleID    string     `json:"roleId"`
+}
+
+// AuthActivityDay is the AuthActivityDay schema of the Heimdall API
+type AuthActivityDay struct {
+	ActiveUsers       int     `json:"activeUsers"`
+	Day               string  `json:"day"`
+	FailedLogins      int     `json:"failedLogins"`
+	LoginSuccessRate  float64 `json:"loginSuccessRate"`
+	Logins            int     `json:"logins"`
+	MfaAdoption       float64 `json:"mfaAdoption"`
+	MfaUsers          int     `json:"mfaUsers"`
+	Registrations     int     `json:"registrations"`
+	WeeklyActiveUsers int     `json:"weeklyActiveUsers"`
+}
+
+// AuthActivitySummary is the AuthActivitySummary schema of the Heimdall API
+type AuthActivitySummary struct {
+	DailyActiveUsers  float64 `json:"dailyActiveUsers"`
+	FailedLogins      int     `json:"failedLogins"`
+	LoginSuccessRate  float64 `json:"loginSuccessRate"`
+	Logins            int     `json:"logins"`
+	MfaAdoption       float64 `json:"mfaAdoption"`
+	Registrations     int     `json:"registrations"`
+	WeeklyActiveUsers int     `json:"weeklyActiveUsers"`
+}
+
+// AuthAnalytics is the AuthAnalytics schema of the Heimdall API
+type AuthAnalytics struct {
+	AggregatedAt string              `json:"aggregatedAt,omitempty"`
+	Daily        []AuthActivityDay   `json:"daily"`
+	From         string              `json:"from"`
+	Summary      AuthActivitySummary `json:"summary"`
+	To           string              `json:"to"`
+}
+
+// AuthResponse is the AuthResponse schema of the Heimdall API
+type AuthResponse struct {
+	AccessToken  string   `json:"accessToken"`
+	ExpiresIn    int      `json:"expiresIn"`
+	RefreshToken string   `json:"refreshToken"`
+	TokenType    string   `json:"tokenType"`
+	User         UserInfo `json:"user"`
+}
+
+// AuthzAnalytics is the AuthzAnalytics schema of the Heimdall API
+type AuthzAnalytics struct {
+	DormantRoles      []DormantRole      `json:"dormantRoles"`
+	From              string             `json:"from"`
+	RoleDenyRates     []RoleDenyRate     `json:"roleDenyRates"`
+	Summary           AuthzSummary       `json:"summary"`
+	To                string             `json:"to"`
+	TopDeniedRoutes   []DeniedRoute      `json:"topDeniedRoutes"`
+	UnmatchedPolicies []UnmatchedPolicy  `json:"unmatchedPolicies"`
+	UnusedPermissions []UnusedPermission `json:"unusedPermissions"`
+}
+
+// AuthzCheckRequest is the AuthzCheckRequest schema of the Heimdall API
+type AuthzCheckRequest struct {
+	Action   string                 `json:"action"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+	Resource *ResourceContext       `json:"resource,omitempty"`
+}
+
+// AuthzDecision is the AuthzDecision schema of the Heimdall API
+type AuthzDecision struct {
+	// Directives the policy suggests, which the client may ignore
+	Advice   []PolicyDirective      `json:"advice,omitempty"`
+	Allow    bool                   `json:"allow"`
+	Decision bool                   `json:"decision"`
+	Metadata *AuthzDecisionMetadata `json:"metadata,omitempty"`
+	// Directives the policy requires the client to fulfil when enforcing the decision
+	Obligations []PolicyDirective `json:"obligations,omitempty"`
+	Reason      string            `json:"reason"`
+}
+
+// AuthzDecisionMetadata is the AuthzDecisionMetadata schema of the Heimdall API
+type AuthzDecisionMetadata struct {
+	Cache      *AuthzDecisionMetadataCache `json:"cache,omitempty"`
+	DecisionID string                      `json:"decisionId,omitempty"`
+}
+
+// AuthzDecisionMetadataCache is the AuthzDecisionMetadataCache schema of the Heimdall API
+type AuthzDecisionMetadataCache struct {
+	Age      int    `json:"age,omitempty"`
+	MaxStale int    `json:"maxStale,omitempty"`
+	Status   string `json:"status,omitempty"`
+}
+
+// AuthzExplainRequest is the AuthzExplainRequest schema of the Heimdall API
+type AuthzExplainRequest struct {
+	Action   string                 `json:"action"`
+	Context  map[string]interface{} `json:"context,omitempty"`
+	Resource *ResourceContext       `json:"resource,omitempty"`
+	Trace    *bool                  `json:"trace,omitempty"`
+}
+
+// AuthzExplanation is the AuthzExplanation schema of the Heimdall API
+type AuthzExplanation struct {
+	Allow      bool                   `json:"allow"`
+	DecisionID string                 `json:"decisionId,omitempty"`
+	Denials    []string               `json:"denials,omitempty"`
+	Input      map[string]interface{} `json:"input"`
+	InputsUsed []string               `json:"inputsUsed"`
+	Notes      []string               `json:"notes,omitempty"`
+	Policies   map[string]interface{} `json:"policies,omitempty"`
+	Reason     string                 `json:"reason"`
+	Rules      []FiredRule            `json:"rules"`
+	Trace      []TraceEvent           `json:"trace,omitempty"`
+}
+
+// AuthzSimulation is the AuthzSimulation schema of the Heimdall API
+type AuthzSimulation struct {
+	Active  SimulatedDecision      `json:"active"`
+	Bundle  *SimulatedDecision     `json:"bundle,omitempty"`
+	Changed bool                   `json:"changed"`
+	Input   map[string]interface{} `json:"input"`
+}
+
+// AuthzSummary is the AuthzSummary schema of the Heimdall API
+type AuthzSummary struct {
+	Decisions int     `json:"decisions"`
+	Denied    int     `json:"denied"`
+	DenyRate  float64 `json:"denyRate"`
+}
+
+// BodyLoggingRule is the BodyLoggingRule schema of the Heimdall API
+type BodyLoggingRule struct {
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	ID        string    `json:"id"`
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path"`
+}
+
+// BreakGlassSession is the BreakGlassSession schema of the Heimdall API
+type BreakGlassSession struct {
+	ExpiresAt string `json:"expiresAt"`
+	IssuedAt  string `json:"issuedAt"`
+	Operator  string `json:"operator"`
+	Reason    string `json:"reason"`
+	TenantID  string `json:"tenantId"`
+	TokenID   string `json:"tokenId"`
+	UserID    string `json:"userId"`
+}
+
+// BundleContents is the BundleContents schema of the Heimdall API
+type BundleContents struct {
+	BundleID string       `json:"bundleId"`
+	Checksum string       `json:"checksum"`
+	Files    []BundleFile `json:"files"`
+	Version  string       `json:"version"`
+}
+
+// BundleDeployment is the BundleDeployment schema of the Heimdall API
+type BundleDeployment struct {
+	Bundle         *PolicyBundle `json:"bundle,omitempty"`
+	BundleID       string        `json:"bundleId"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	DeployedAt     time.Time     `json:"deployedAt"`
+	DeployedBy     string        `json:"deployedBy"`
+	Environment    string        `json:"environment,omitempty"`
+	ErrorMessage   string        `json:"errorMessage,omitempty"`
+	ID             string        `json:"id"`
+	Manifest       interface{}   `json:"manifest,omitempty"`
+	Revision       string        `json:"revision,omitempty"`
+	RollbackReason string        `json:"rollbackReason,omitempty"`
+	RolledBackAt   *time.Time    `json:"rolledBackAt,omitempty"`
+	RolledBackBy   string        `json:"rolledBackBy,omitempty"`
+	Status         string        `json:"status"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+// BundleDownloadURL is the BundleDownloadURL schema of the Heimdall API
+type BundleDownloadURL struct {
+	Checksum  string    `json:"checksum"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Size      int       `json:"size"`
+	URL       string    `json:"url"`
+}
+
+// BundleFile is the BundleFile schema of the Heimdall API
+type BundleFile struct {
+	Checksum   string `json:"checksum"`
+	Path       string `json:"path"`
+	PolicyID   string `json:"policyId,omitempty"`
+	PolicyName string `json:"policyName,omitempty"`
+	Size       int    `json:"size"`
+}
+
+// BundleOverview is the BundleOverview schema of the Heimdall API
+type BundleOverview struct {
+	Active int                    `json:"active"`
+	Recent []ActiveBundleRevision `json:"recent"`
+}
+
+// BundleSelector is the BundleSelector schema of the Heimdall API
+type BundleSelector struct {
+	PathPrefix *string  `json:"pathPrefix,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// ChangePasswordRequest is the ChangePasswordRequest schema of the Heimdall API
+type ChangePasswordRequest struct {
+	ConfirmPassword string `json:"confirmPassword"`
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// ClaimsTemplateResponse is the ClaimsTemplateResponse schema of the Heimdall API
+type ClaimsTemplateResponse struct {
+	Audiences          []string               `json:"audiences"`
+	CreatedAt          string                 `json:"createdAt"`
+	CustomClaims       map[string]interface{} `json:"customClaims"`
+	IncludePermissions bool                   `json:"includePermissions"`
+	IncludeRoles       bool                   `json:"includeRoles"`
+	MaxTokenSize       int                    `json:"maxTokenSize"`
+	RoleFilter         []string               `json:"roleFilter"`
+	TenantID           string                 `json:"tenantId"`
+	TenantMetadata     []string               `json:"tenantMetadata"`
+	UpdatedAt          string                 `json:"updatedAt"`
+}
+
+// CompilePolicyRulesResult is the CompilePolicyRulesResult schema of the Heimdall API
+type CompilePolicyRulesResult struct {
+	Content string `json:"content"`
+}
+
+// CreateAccessRequestRequest is the CreateAccessRequestRequest schema of the Heimdall API
+type CreateAccessRequestRequest struct {
+	DurationMinutes int    `json:"durationMinutes"`
+	Justification   string `json:"justification"`
+	RoleID          string `json:"roleId"`
+}
+
+// CreateBundleRequest is the CreateBundleRequest schema of the Heimdall API
+type CreateBundleRequest struct {
+	AutoRebuild *bool           `json:"autoRebuild,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	IncludeData *bool           `json:"includeData,omitempty"`
+	IsGlobal    *bool           `json:"isGlobal,omitempty"`
+	Name        string          `json:"name"`
+	PolicyIDs   []string        `json:"policyIds,omitempty"`
+	Selector    *BundleSelector `json:"selector,omitempty"`
+	TenantID    *string         `json:"tenantId,omitempty"`
+	Version     string          `json:"version"`
+}
+
+// CreateOAuthClientRequest is the CreateOAuthClientRequest schema of the Heimdall API
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreatePolicyFromTemplateRequest is the CreatePolicyFromTemplateRequest schema of the Heimdall API
+type CreatePolicyFromTemplateRequest struct {
+	Description *string                `json:"description,omitempty"`
+	Name        string                 `json:"name"`
+	Package     *string                `json:"package,omitempty"`
+	Path        *string                `json:"path,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	TemplateID  string                 `json:"templateId"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+}
+
+// CreatePolicyRequest is the CreatePolicyRequest schema of the Heimdall API
+type CreatePolicyRequest struct {
+	Content     string                 `json:"content"`
+	Description *string                `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Name        string                 `json:"name"`
+	Path        *string                `json:"path,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	TestCases   []PolicyTestCase       `json:"testCases,omitempty"`
+	Type        *string                `json:"type,omitempty"`
+}
+
+// CreateTenantRequest is the CreateTenantRequest schema of the Heimdall API
+type CreateTenantRequest struct {
+	MaxRoles *int                   `json:"maxRoles,omitempty"`
+	MaxUsers *int                   `json:"maxUsers,omitempty"`
+	Name     string                 `json:"name"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Slug     string                 `json:"slug"`
+}
+
+// DecisionEvent is the DecisionEvent schema of the Heimdall API
+type DecisionEvent struct {
+	Bundles     map[string]interface{} `json:"bundles,omitempty"`
+	DecisionID  string                 `json:"decision_id"`
+	Erased      []string               `json:"erased,omitempty"`
+	Error       interface{}            `json:"error,omitempty"`
+	Input       interface{}            `json:"input,omitempty"`
+	Labels      map[string]interface{} `json:"labels"`
+	Masked      []string               `json:"masked,omitempty"`
+	Metrics     map[string]interface{} `json:"metrics,omitempty"`
+	Path        string                 `json:"path"`
+	Query       *string                `json:"query,omitempty"`
+	RequestedBy *string                `json:"requested_by,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// DecisionLog is the DecisionLog schema of the Heimdall API
+type DecisionLog struct {
+	Action      string                 `json:"action,omitempty"`
+	Bundles     map[string]interface{} `json:"bundles,omitempty"`
+	ClientID    string                 `json:"clientId,omitempty"`
+	DecisionID  string                 `json:"decisionId"`
+	Error       interface{}            `json:"error,omitempty"`
+	ID          string                 `json:"id"`
+	Input       interface{}            `json:"input,omitempty"`
+	InstanceID  string                 `json:"instanceId"`
+	IPAddress   string                 `json:"ipAddress,omitempty"`
+	Labels      map[string]interface{} `json:"labels,omitempty"`
+	Metrics     map[string]interface{} `json:"metrics,omitempty"`
+	Path        string                 `json:"path"`
+	RequestedBy string                 `json:"requestedBy,omitempty"`
+	Resource    string                 `json:"resource,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	Status      string                 `json:"status"`
+	Timestamp   string                 `json:"timestamp"`
+}
+
+// DefaultRole is the DefaultRole schema of the Heimdall API
+type DefaultRole struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// DefaultRolesResponse is the DefaultRolesResponse schema of the Heimdall API
+type DefaultRolesResponse struct {
+	Roles    []DefaultRole `json:"roles"`
+	TenantID string        `json:"tenantId"`
+}
+
+// DeniedRoute is the DeniedRoute schema of the Heimdall API
+type DeniedRoute struct {
+	Action   string `json:"action,omitempty"`
+	Denied   int    `json:"denied"`
+	Resource string `json:"resource,omitempty"`
+	Route    string `json:"route"`
+	Source   string `json:"source"`
+}
+
+// DeployBundleRequest is the DeployBundleRequest schema of the Heimdall API
+type DeployBundleRequest struct {
+	Environment *string `json:"environment,omitempty"`
+}
+
+// DeviceAuthorizationResponse is the DeviceAuthorizationResponse schema of the Heimdall API
+type DeviceAuthorizationResponse struct {
+	ClientID  string `json:"clientId"`
+	ExpiresAt string `json:"expiresAt"`
+	Status    string `json:"status"`
+	UserCode  string `json:"userCode"`
+}
+
+// DeviceCodeRequest is the DeviceCodeRequest schema of the Heimdall API
+type DeviceCodeRequest struct {
+	ClientID string `json:"client_id"`
+}
+
+// DeviceCodeResponse is the DeviceCodeResponse schema of the Heimdall API
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+}
+
+// DeviceVerificationRequest is the DeviceVerificationRequest schema of the Heimdall API
+type DeviceVerificationRequest struct {
+	UserCode string `json:"userCode"`
+}
+
+// DormantRole is the DormantRole schema of the Heimdall API
+type DormantRole struct {
+	ID      string `json:"id"`
+	Members int    `json:"members"`
+	Name    string `json:"name"`
+}
+
+// ElevateRoleRequest is the ElevateRoleRequest schema of the Heimdall API
+type ElevateRoleRequest struct {
+	DurationMinutes int    `json:"durationMinutes"`
+	Reason          string `json:"reason"`
+	RoleID          string `json:"roleId"`
+}
+
+// EnableBodyLoggingRequest is the EnableBodyLoggingRequest schema of the Heimdall API
+type EnableBodyLoggingRequest struct {
+	DurationMinutes *int    `json:"durationMinutes,omitempty"`
+	Method          *string `json:"method,omitempty"`
+	Path            string  `json:"path"`
+}
+
+// ExportPoliciesResult is the ExportPoliciesResult schema of the Heimdall API
+type ExportPoliciesResult struct {
+	Files []PolicyFile `json:"files"`
+}
+
+// FailedDeployment is the FailedDeployment schema of the Heimdall API
+type FailedDeployment struct {
+	BundleID    string `json:"bundleId"`
+	BundleName  string `json:"bundleName"`
+	DeployedAt  string `json:"deployedAt"`
+	Environment string `json:"environment,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ID          string `json:"id"`
+	TenantID    string `json:"tenantId"`
+}
+
+// FiredRule is the FiredRule schema of the Heimdall API
+type FiredRule struct {
+	Count    int    `json:"count"`
+	Location string `json:"location,omitempty"`
+	Name     string `json:"name"`
+}
+
+// ForgotPasswordRequest is the ForgotPasswordRequest schema of the Heimdall API
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// GetAuthAnalyticsParams holds the query parameters of GetAuthAnalytics
+type GetAuthAnalyticsParams struct {
+	// Start of the period (RFC 3339), rounded down to the day, defaults to 30 days before to
+	From *time.Time `json:"from,omitempty"`
+	// End of the period (RFC 3339), defaults to now
+	To *time.Time `json:"to,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *GetAuthAnalyticsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.From != nil {
+		query.Set("from", p.From.Format(time.RFC3339))
+	}
+	if p.To != nil {
+		query.Set("to", p.To.Format(time.RFC3339))
+	}
+	return query
+}
+
+// GetAuthzAnalyticsParams holds the query parameters of GetAuthzAnalytics
+type GetAuthzAnalyticsParams struct {
+	// Start of the period (RFC 3339), defaults to 30 days before to
+	From *time.Time `json:"from,omitempty"`
+	// End of the period (RFC 3339), defaults to now
+	To *time.Time `json:"to,omitempty"`
+	// Number of denied routes listed, at most 100
+	Limit int `json:"limit,omitempty"`
+	// Response format
+	Format string `json:"format,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *GetAuthzAnalyticsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.From != nil {
+		query.Set("from", p.From.Format(time.RFC3339))
+	}
+	if p.To != nil {
+		query.Set("to", p.To.Format(time.RFC3339))
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Format != "" {
+		query.Set("format", p.Format)
+	}
+	return query
+}
+
+// GetBundleDownloadURLParams holds the query parameters of GetBundleDownloadURL
+type GetBundleDownloadURLParams struct {
+	// Lifetime of the URL in seconds, from 60 to 604800 (default 900)
+	ExpiresIn int `json:"expiresIn,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *GetBundleDownloadURLParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.ExpiresIn != 0 {
+		query.Set("expiresIn", strconv.Itoa(p.ExpiresIn))
+	}
+	return query
+}
+
+// GetDeviceAuthorizationParams holds the query parameters of GetDeviceAuthorization
+type GetDeviceAuthorizationParams struct {
+	// User code shown by the device
+	UserCode string `json:"user_code,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *GetDeviceAuthorizationParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.UserCode != "" {
+		query.Set("user_code", p.UserCode)
+	}
+	return query
+}
+
+// GetMyLoginHistoryParams holds the query parameters of GetMyLoginHistory
+type GetMyLoginHistoryParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *GetMyLoginHistoryParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// GetMyLoginHistoryResult is the GetMyLoginHistoryResult schema of the Heimdall API
+type GetMyLoginHistoryResult struct {
+	Logins     []LoginEvent `json:"logins,omitempty"`
+	Pagination *Pagination  `json:"pagination,omitempty"`
+}
+
+// GetMyPermissionsResult is the GetMyPermissionsResult schema of the Heimdall API
+type GetMyPermissionsResult struct {
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// IPAccessResponse is the IPAccessResponse schema of the Heimdall API
+type IPAccessResponse struct {
+	Allowlist []string `json:"allowlist"`
+	Denylist  []string `json:"denylist"`
+	TenantID  string   `json:"tenantId"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+// JWKS is the JWKS schema of the Heimdall API
+type JWKS struct {
+	Keys []map[string]interface{} `json:"keys"`
+}
+
+// Job is the Job schema of the Heimdall API
+type Job struct {
+	Attempts    int         `json:"attempts"`
+	CompletedAt string      `json:"completedAt,omitempty"`
+	CreatedAt   string      `json:"createdAt"`
+	CreatedBy   string      `json:"createdBy,omitempty"`
+	ID          string      `json:"id"`
+	LastError   string      `json:"lastError,omitempty"`
+	MaxAttempts int         `json:"maxAttempts"`
+	Payload     interface{} `json:"payload,omitempty"`
+	Result      interface{} `json:"result,omitempty"`
+	RunAt       string      `json:"runAt"`
+	StartedAt   string      `json:"startedAt,omitempty"`
+	Status      string      `json:"status"`
+	TenantID    string      `json:"tenantId,omitempty"`
+	Type        string      `json:"type"`
+	UpdatedAt   string      `json:"updatedAt"`
+}
+
+// LintPolicyResult is the LintPolicyResult schema of the Heimdall API
+type LintPolicyResult struct {
+	Issues []PolicyLintIssue `json:"issues"`
+}
+
+// ListAccessRequestsParams holds the query parameters of ListAccessRequests
+type ListAccessRequestsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Filter by requesting user
+	RequesterID string `json:"requesterId,omitempty"`
+	// Filter by requested role
+	RoleID string `json:"roleId,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListAccessRequestsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.RequesterID != "" {
+		query.Set("requesterId", p.RequesterID)
+	}
+	if p.RoleID != "" {
+		query.Set("roleId", p.RoleID)
+	}
+	return query
+}
+
+// ListAccessRequestsResult is the ListAccessRequestsResult schema of the Heimdall API
+type ListAccessRequestsResult struct {
+	AccessRequests []AccessRequest `json:"accessRequests,omitempty"`
+	Pagination     *Pagination     `json:"pagination,omitempty"`
+}
+
+// ListAdminActionsParams holds the query parameters of ListAdminActions
+type ListAdminActionsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Filter by action, e.g. roles.assign or tenants.suspend
+	Action string `json:"action,omitempty"`
+	// Filter by acting user
+	UserID string `json:"userId,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListAdminActionsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.Action != "" {
+		query.Set("action", p.Action)
+	}
+	if p.UserID != "" {
+		query.Set("userId", p.UserID)
+	}
+	return query
+}
+
+// ListAdminActionsResult is the ListAdminActionsResult schema of the Heimdall API
+type ListAdminActionsResult struct {
+	Actions    []AdminAction `json:"actions,omitempty"`
+	Pagination *Pagination   `json:"pagination,omitempty"`
+}
+
+// ListAlertRulesParams holds the query parameters of ListAlertRules
+type ListAlertRulesParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListAlertRulesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListAlertRulesResult is the ListAlertRulesResult schema of the Heimdall API
+type ListAlertRulesResult struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Rules      []AlertRule `json:"rules,omitempty"`
+}
+
+// ListAlertsParams holds the query parameters of ListAlerts
+type ListAlertsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListAlertsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListAlertsResult is the ListAlertsResult schema of the Heimdall API
+type ListAlertsResult struct {
+	Alerts     []Alert     `json:"alerts,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// ListBundlesParams holds the query parameters of ListBundles
+type ListBundlesParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListBundlesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListBundlesResult is the ListBundlesResult schema of the Heimdall API
+type ListBundlesResult struct {
+	Bundles    []PolicyBundle `json:"bundles,omitempty"`
+	Pagination *Pagination    `json:"pagination,omitempty"`
+}
+
+// ListDecisionsParams holds the query parameters of ListDecisions
+type ListDecisionsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Filter by the OPA instance that reported the decisions
+	InstanceID string `json:"instanceId,omitempty"`
+	// Filter by decision ID
+	DecisionID string `json:"decisionId,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListDecisionsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.InstanceID != "" {
+		query.Set("instanceId", p.InstanceID)
+	}
+	if p.DecisionID != "" {
+		query.Set("decisionId", p.DecisionID)
+	}
+	return query
+}
+
+// ListDecisionsResult is the ListDecisionsResult schema of the Heimdall API
+type ListDecisionsResult struct {
+	Decisions  []DecisionLog `json:"decisions,omitempty"`
+	Pagination *Pagination   `json:"pagination,omitempty"`
+}
+
+// ListDeletedPoliciesParams holds the query parameters of ListDeletedPolicies
+type ListDeletedPoliciesParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListDeletedPoliciesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListDeletedPoliciesResult is the ListDeletedPoliciesResult schema of the Heimdall API
+type ListDeletedPoliciesResult struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Policies   []Policy    `json:"policies,omitempty"`
+}
+
+// ListJobsParams holds the query parameters of ListJobs
+type ListJobsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Filter by job type, e.g. bundle.build or policy.git_sync
+	Type string `json:"type,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListJobsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.Type != "" {
+		query.Set("type", p.Type)
+	}
+	return query
+}
+
+// ListJobsResult is the ListJobsResult schema of the Heimdall API
+type ListJobsResult struct {
+	Jobs       []Job       `json:"jobs,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// ListMyAccessRequestsParams holds the query parameters of ListMyAccessRequests
+type ListMyAccessRequestsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListMyAccessRequestsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListMyAccessRequestsResult is the ListMyAccessRequestsResult schema of the Heimdall API
+type ListMyAccessRequestsResult struct {
+	AccessRequests []AccessRequest `json:"accessRequests,omitempty"`
+	Pagination     *Pagination     `json:"pagination,omitempty"`
+}
+
+// ListOPAInstancesParams holds the query parameters of ListOPAInstances
+type ListOPAInstancesParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListOPAInstancesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListOPAInstancesResult is the ListOPAInstancesResult schema of the Heimdall API
+type ListOPAInstancesResult struct {
+	Instances  []OPAInstance `json:"instances,omitempty"`
+	Pagination *Pagination   `json:"pagination,omitempty"`
+}
+
+// ListPoliciesParams holds the query parameters of ListPolicies
+type ListPoliciesParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListPoliciesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListPoliciesResult is the ListPoliciesResult schema of the Heimdall API
+type ListPoliciesResult struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Policies   []Policy    `json:"policies,omitempty"`
+}
+
+// ListResourcesParams holds the query parameters of ListResources
+type ListResourcesParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Filter by resource type
+	Type string `json:"type,omitempty"`
+	// Filter by owning user
+	OwnerID string `json:"ownerId,omitempty"`
+	// Filter by label as a key=value pair, e.g. env=prod
+	Label string `json:"label,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListResourcesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.Type != "" {
+		query.Set("type", p.Type)
+	}
+	if p.OwnerID != "" {
+		query.Set("ownerId", p.OwnerID)
+	}
+	if p.Label != "" {
+		query.Set("label", p.Label)
+	}
+	return query
+}
+
+// ListResourcesResult is the ListResourcesResult schema of the Heimdall API
+type ListResourcesResult struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Resources  []Resource  `json:"resources,omitempty"`
+}
+
+// ListTenantsParams holds the query parameters of ListTenants
+type ListTenantsParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListTenantsParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListTenantsResult is the ListTenantsResult schema of the Heimdall API
+type ListTenantsResult struct {
+	Pagination *Pagination      `json:"pagination,omitempty"`
+	Tenants    []TenantResponse `json:"tenants,omitempty"`
+}
+
+// ListUsersParams holds the query parameters of ListUsers
+type ListUsersParams struct {
+	// Page number, ignored when a cursor is given
+	Page int `json:"page,omitempty"`
+	// Items per page
+	PageSize int `json:"pageSize,omitempty"`
+	// Opaque cursor from a previous page's nextCursor
+	Cursor string `json:"cursor,omitempty"`
+	// Sort field, prefixed with '-' for descending order
+	Sort string `json:"sort,omitempty"`
+	// Filter by status
+	Status string `json:"status,omitempty"`
+	// Only include items created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter,omitempty"`
+	// Only include items created before this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+	// Match an email prefix, or a first name, last name or full name prefix, case-insensitively. Names are not matched once user metadata is encrypted at rest
+	Query string `json:"query,omitempty"`
+	// Match the query anywhere in the email instead of as a prefix
+	Fuzzy bool `json:"fuzzy,omitempty"`
+	// Filter by assigned role name
+	Role string `json:"role,omitempty"`
+	// Only include users who last logged in at or after this time
+	LastLoginAfter *time.Time `json:"lastLoginAfter,omitempty"`
+	// Only include users who last logged in before this time
+	LastLoginBefore *time.Time `json:"lastLoginBefore,omitempty"`
+}
+
+// values encodes the parameters set in p as a query string
+func (p *ListUsersParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if p.Page != 0 {
+		query.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PageSize != 0 {
+		query.Set("pageSize", strconv.Itoa(p.PageSize))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.CreatedAfter != nil {
+		query.Set("createdAfter", p.CreatedAfter.Format(time.RFC3339))
+	}
+	if p.CreatedBefore != nil {
+		query.Set("createdBefore", p.CreatedBefore.Format(time.RFC3339))
+	}
+	if p.Query != "" {
+		query.Set("query", p.Query)
+	}
+	if p.Fuzzy {
+		query.Set("fuzzy", strconv.FormatBool(p.Fuzzy))
+	}
+	if p.Role != "" {
+		query.Set("role", p.Role)
+	}
+	if p.LastLoginAfter != nil {
+		query.Set("lastLoginAfter", p.LastLoginAfter.Format(time.RFC3339))
+	}
+	if p.LastLoginBefore != nil {
+		query.Set("lastLoginBefore", p.LastLoginBefore.Format(time.RFC3339))
+	}
+	return query
+}
+
+// ListUsersResult is the ListUsersResult schema of the Heimdall API
+type ListUsersResult struct {
+	Pagination *Pagination   `json:"pagination,omitempty"`
+	Users      []UserProfile `json:"users,omitempty"`
+}
+
+// LoginEvent is the LoginEvent schema of the Heimdall API
+type LoginEvent struct {
+	City              string      `json:"city,omitempty"`
+	Country           string      `json:"country,omitempty"`
+	CreatedAt         time.Time   `json:"createdAt"`
+	DeviceFingerprint string      `json:"deviceFingerprint,omitempty"`
+	ID                string      `json:"id"`
+	IPAddress         string      `json:"ipAddress,omitempty"`
+	Mfa               bool        `json:"mfa"`
+	Reasons           interface{} `json:"reasons,omitempty"`
+	Region            string      `json:"region,omitempty"`
+	Suspicious        bool        `json:"suspicious"`
+	TenantID          string      `json:"tenantId"`
+	UserAgent         string      `json:"userAgent,omitempty"`
+	UserID            string      `json:"userId"`
+}
+
+// LoginRequest is the LoginRequest schema of the Heimdall API
+type LoginRequest struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	RememberMe *bool  `json:"rememberMe,omitempty"`
+}
+
+// MagicLinkRedeemRequest is the MagicLinkRedeemRequest schema of the Heimdall API
+type MagicLinkRedeemRequest struct {
+	Token string `json:"token"`
+}
+
+// MagicLinkRequest is the MagicLinkRequest schema of the Heimdall API
+type MagicLinkRequest struct {
+	Email    string  `json:"email"`
+	TenantID *string `json:"tenantId,omitempty"`
+}
+
+// MagicLinkResponse is the MagicLinkResponse schema of the Heimdall API
+type MagicLinkResponse struct {
+	ExpiresIn int `json:"expiresIn"`
+}
+
+// OAuthClientResponse is the OAuthClientResponse schema of the Heimdall API
+type OAuthClientResponse struct {
+	ClientID        string   `json:"clientId"`
+	ClientSecret    string   `json:"clientSecret,omitempty"`
+	CreatedAt       string   `json:"createdAt"`
+	ID              string   `json:"id"`
+	LastUsedAt      string   `json:"lastUsedAt,omitempty"`
+	Name            string   `json:"name"`
+	Scopes          []string `json:"scopes"`
+	SecretRotatedAt string   `json:"secretRotatedAt,omitempty"`
+	TenantID        string   `json:"tenantId"`
+	UpdatedAt       string   `json:"updatedAt"`
+}
+
+// OAuthErrorResponse is the OAuthErrorResponse schema of the Heimdall API
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthTokenRequest is the OAuthTokenRequest schema of the Heimdall API
+type OAuthTokenRequest struct {
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	DeviceCode   string `json:"device_code,omitempty"`
+	GrantType    string `json:"grant_type"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// OAuthTokenResponse is the OAuthTokenResponse schema of the Heimdall API
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	TokenType    string `json:"token_type"`
+}
+
+// OPABundleStatus is the OPABundleStatus schema of the Heimdall API
+type OPABundleStatus struct {
+	ActiveRevision           string     `json:"active_revision,omitempty"`
+	Code                     string     `json:"code,omitempty"`
+	LastSuccessfulActivation *time.Time `json:"last_successful_activation,omitempty"`
+	LastSuccessfulDownload   *time.Time `json:"last_successful_download,omitempty"`
+	Message                  string     `json:"message,omitempty"`
+	Name                     string     `json:"name"`
+}
+
+// OPAInstance is the OPAInstance schema of the Heimdall API
+type OPAInstance struct {
+	Bundles     []OPAInstanceBundle    `json:"bundles"`
+	ClientID    string                 `json:"clientId,omitempty"`
+	FirstSeenAt string                 `json:"firstSeenAt"`
+	Healthy     bool                   `json:"healthy"`
+	ID          string                 `json:"id"`
+	InstanceID  string                 `json:"instanceId"`
+	IPAddress   string                 `json:"ipAddress,omitempty"`
+	Labels      map[string]interface{} `json:"labels,omitempty"`
+	LastSeenAt  string                 `json:"lastSeenAt"`
+	Plugins     map[string]interface{} `json:"plugins,omitempty"`
+	Stale       bool                   `json:"stale"`
+	Version     string                 `json:"version,omitempty"`
+}
+
+// OPAInstanceBundle is the OPAInstanceBundle schema of the Heimdall API
+type OPAInstanceBundle struct {
+	ActiveRevision           string `json:"activeRevision,omitempty"`
+	BundleID                 string `json:"bundleId,omitempty"`
+	BundleName               string `json:"bundleName,omitempty"`
+	BundleVersion            string `json:"bundleVersion,omitempty"`
+	Error                    string `json:"error,omitempty"`
+	LastSuccessfulActivation string `json:"lastSuccessfulActivation,omitempty"`
+	LastSuccessfulDownload   string `json:"lastSuccessfulDownload,omitempty"`
+	Name                     string `json:"name"`
+	UpToDate                 bool   `json:"upToDate,omitempty"`
+}
+
+// OPAOverview is the OPAOverview schema of the Heimdall API
+type OPAOverview struct {
+	Error     string `json:"error,omitempty"`
+	Healthy   int    `json:"healthy"`
+	Instances int    `json:"instances"`
+	Reachable bool   `json:"reachable"`
+	Stale     int    `json:"stale"`
+	Unhealthy int    `json:"unhealthy"`
+}
+
+// OPAPluginStatus is the OPAPluginStatus schema of the Heimdall API
+type OPAPluginStatus struct {
+	Message string `json:"message,omitempty"`
+	State   string `json:"state"`
+}
+
+// OPAStatusReport is the OPAStatusReport schema of the Heimdall API
+type OPAStatusReport struct {
+	Bundles map[string]interface{} `json:"bundles,omitempty"`
+	Labels  map[string]interface{} `json:"labels"`
+	Plugins map[string]interface{} `json:"plugins,omitempty"`
+}
+
+// OverviewErrorRates is the OverviewErrorRates schema of the Heimdall API
+type OverviewErrorRates struct {
+	AdminActionErrorRate float64 `json:"adminActionErrorRate"`
+	AdminActionErrors    int     `json:"adminActionErrors"`
+	AdminActions         int     `json:"adminActions"`
+	DecisionErrorRate    float64 `json:"decisionErrorRate"`
+	DecisionErrors       int     `json:"decisionErrors"`
+	Decisions            int     `json:"decisions"`
+}
+
+// Pagination is the Pagination schema of the Heimdall API
+type Pagination struct {
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"pageSize"`
+	Sort       string `json:"sort"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"totalPages"`
+}
+
+// PasswordPolicy is the PasswordPolicy schema of the Heimdall API
+type PasswordPolicy struct {
+	DisallowCommon   bool `json:"disallowCommon"`
+	DisallowUserInfo bool `json:"disallowUserInfo"`
+	HistorySize      int  `json:"historySize"`
+	MaxLength        int  `json:"maxLength"`
+	MinLength        int  `json:"minLength"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	RequireUppercase bool `json:"requireUppercase"`
+}
+
+// Permission is the Permission schema of the Heimdall API
+type Permission struct {
+	Action      string                   `json:"action"`
+	CreatedAt   time.Time                `json:"createdAt"`
+	DeletedAt   *time.Time               `json:"deletedAt,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	ID          string                   `json:"id"`
+	IsSystem    bool                     `json:"isSystem"`
+	Name        string                   `json:"name"`
+	Resource    string                   `json:"resource"`
+	Roles       []map[string]interface{} `json:"roles,omitempty"`
+	Scope       string                   `json:"scope"`
+	UpdatedAt   time.Time                `json:"updatedAt"`
+}
+
+// PlatformSigningKey is the PlatformSigningKey schema of the Heimdall API
+type PlatformSigningKey struct {
+	Alg       string     `json:"alg"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ID        string     `json:"id"`
+	Kid       string     `json:"kid"`
+	PublicKey string     `json:"publicKey"`
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+	Status    string     `json:"status"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// Policy is the Policy schema of the Heimdall API
+type Policy struct {
+	Bundles         []PolicyBundle         `json:"bundles,omitempty"`
+	Content         string                 `json:"content"`
+	CreatedAt       time.Time              `json:"createdAt"`
+	CreatedBy       string                 `json:"createdBy"`
+	DeletedAt       *time.Time             `json:"deletedAt,omitempty"`
+	Description     string                 `json:"description,omitempty"`
+	ID              string                 `json:"id"`
+	IsSystem        bool                   `json:"isSystem"`
+	IsValid         bool                   `json:"isValid"`
+	Metadata        interface{}            `json:"metadata,omitempty"`
+	Name            string                 `json:"name"`
+	Path            string                 `json:"path"`
+	PublishedAt     *time.Time             `json:"publishedAt,omitempty"`
+	PublishedBy     string                 `json:"publishedBy,omitempty"`
+	Status          string                 `json:"status"`
+	Tags            interface{}            `json:"tags,omitempty"`
+	Tenant          map[string]interface{} `json:"tenant,omitempty"`
+	TenantID        string                 `json:"tenantId"`
+	TestCases       interface{}            `json:"testCases,omitempty"`
+	Type            string                 `json:"type"`
+	UpdatedAt       time.Time              `json:"updatedAt"`
+	UpdatedBy       string                 `json:"updatedBy"`
+	ValidatedAt     *time.Time             `json:"validatedAt,omitempty"`
+	ValidationError string                 `json:"validationError,omitempty"`
+	Version         int                    `json:"version"`
+}
+
+// PolicyBundle is the PolicyBundle schema of the Heimdall API
+type PolicyBundle struct {
+	ActivatedAt      *time.Time             `json:"activatedAt,omitempty"`
+	ActivatedBy      string                 `json:"activatedBy,omitempty"`
+	AutoRebuild      bool                   `json:"autoRebuild"`
+	BuildCompletedAt *time.Time             `json:"buildCompletedAt,omitempty"`
+	BuildError       string                 `json:"buildError,omitempty"`
+	BuildJobID       string                 `json:"buildJobId,omitempty"`
+	BuildLog         string                 `json:"buildLog,omitempty"`
+	BuildStartedAt   *time.Time             `json:"buildStartedAt,omitempty"`
+	Checksum         string                 `json:"checksum,omitempty"`
+	CreatedAt        time.Time              `json:"createdAt"`
+	CreatedBy        string                 `json:"createdBy"`
+	DeactivatedAt    *time.Time             `json:"deactivatedAt,omitempty"`
+	DeactivatedBy    string                 `json:"deactivatedBy,omitempty"`
+	DeletedAt        *time.Time             `json:"deletedAt,omitempty"`
+	Deployments      []BundleDeployment     `json:"deployments,omitempty"`
+	Description      string                 `json:"description,omitempty"`
+	ID               string                 `json:"id"`
+	IncludeData      bool                   `json:"includeData"`
+	IsGlobal         bool                   `json:"isGlobal"`
+	Manifest         interface{}            `json:"manifest,omitempty"`
+	Name             string                 `json:"name"`
+	Policies         []Policy               `json:"policies,omitempty"`
+	Selector         interface{}            `json:"selector,omitempty"`
+	Size             int                    `json:"size,omitempty"`
+	Status           string                 `json:"status"`
+	StorageBucket    string                 `json:"storageBucket,omitempty"`
+	StoragePath      string                 `json:"storagePath,omitempty"`
+	Tenant           map[string]interface{} `json:"tenant,omitempty"`
+	TenantID         string                 `json:"tenantId,omitempty"`
+	UpdatedAt        time.Time              `json:"updatedAt"`
+	UpdatedBy        string                 `json:"updatedBy"`
+	Version          string                 `json:"version"`
+}
+
+// PolicyContentRequest is the PolicyContentRequest schema of the Heimdall API
+type PolicyContentRequest struct {
+	Content string `json:"content"`
+}
+
+// PolicyDependencies is the PolicyDependencies schema of the Heimdall API
+type PolicyDependencies struct {
+	Dependents []PolicyReference `json:"dependents"`
+	Imports    []PolicyImport    `json:"imports"`
+	Policy     PolicyReference   `json:"policy"`
+}
+
+// PolicyDirective is the PolicyDirective schema of the Heimdall API
+type PolicyDirective struct {
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Type       string                 `json:"type"`
+}
+
+// PolicyFile is the PolicyFile schema of the Heimdall API
+type PolicyFile struct {
+	Content string `json:"content"`
+	Path    string `json:"path"`
+}
+
+// PolicyFormatResult is the PolicyFormatResult schema of the Heimdall API
+type PolicyFormatResult struct {
+	Changed bool   `json:"changed"`
+	Content string `json:"content"`
+}
+
+// PolicyImport is the PolicyImport schema of the Heimdall API
+type PolicyImport struct {
+	Import   string            `json:"import"`
+	Package  string            `json:"package,omitempty"`
+	Policies []PolicyReference `json:"policies,omitempty"`
+}
+
+// PolicyLintIssue is the PolicyLintIssue schema of the Heimdall API
+type PolicyLintIssue struct {
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+}
+
+// PolicyReference is the PolicyReference schema of the Heimdall API
+type PolicyReference struct {
+	ID      string `json:"id"`
+	IsValid bool   `json:"isValid"`
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+}
+
+// PolicyRule is the PolicyRule schema of the Heimdall API
+type PolicyRule struct {
+	Actions     []string              `json:"actions"`
+	Conditions  []PolicyRuleCondition `json:"conditions,omitempty"`
+	Description *string               `json:"description,omitempty"`
+	Resources   []string              `json:"resources"`
+	Subjects    PolicyRuleSubjects    `json:"subjects"`
+}
+
+// PolicyRuleCondition is the PolicyRuleCondition schema of the Heimdall API
+type PolicyRuleCondition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueFrom *string     `json:"valueFrom,omitempty"`
+}
+
+// PolicyRuleSet is the PolicyRuleSet schema of the Heimdall API
+type PolicyRuleSet struct {
+	Package *string      `json:"package,omitempty"`
+	Rules   []PolicyRule `json:"rules"`
+}
+
+// PolicyRuleSubjects is the PolicyRuleSubjects schema of the Heimdall API
+type PolicyRuleSubjects struct {
+	Permissions []string `json:"permissions,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Users       []string `json:"users,omitempty"`
+}
+
+// PolicySyncChange is the PolicySyncChange schema of the Heimdall API
+type PolicySyncChange struct {
+	Action   string `json:"action"`
+	Diff     string `json:"diff,omitempty"`
+	Path     string `json:"path"`
+	PolicyID string `json:"policyId,omitempty"`
+}
+
+// PolicySyncResult is the PolicySyncResult schema of the Heimdall API
+type PolicySyncResult struct {
+	Changes   []PolicySyncChange `json:"changes"`
+	Created   int                `json:"created"`
+	Deleted   int                `json:"deleted"`
+	DryRun    bool               `json:"dryRun"`
+	Unchanged int                `json:"unchanged"`
+	Updated   int                `json:"updated"`
+}
+
+// PolicyTemplate is the PolicyTemplate schema of the Heimdall API
+type PolicyTemplate struct {
+	Content     string                   `json:"content"`
+	Description string                   `json:"description"`
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Variables   []PolicyTemplateVariable `json:"variables"`
+}
+
+// PolicyTemplateVariable is the PolicyTemplateVariable schema of the Heimdall API
+type PolicyTemplateVariable struct {
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description"`
+	Format      string      `json:"format,omitempty"`
+	Maximum     int         `json:"maximum,omitempty"`
+	Minimum     int         `json:"minimum,omitempty"`
+	Name        string      `json:"name"`
+	Required    bool        `json:"required"`
+	Type        string      `json:"type"`
+}
+
+// PolicyTestCase is the PolicyTestCase schema of the Heimdall API
+type PolicyTestCase struct {
+	Expected map[string]interface{} `json:"expected"`
+	Input    map[string]interface{} `json:"input"`
+	Name     string                 `json:"name"`
+	Note     *string                `json:"note,omitempty"`
+}
+
+// PolicyTestResult is the PolicyTestResult schema of the Heimdall API
+type PolicyTestResult struct {
+	Message  string `json:"message,omitempty"`
+	Passed   bool   `json:"passed"`
+	TestName string `json:"testName"`
+}
+
+// PolicyTestSuitePolicy is the PolicyTestSuitePolicy schema of the Heimdall API
+type PolicyTestSuitePolicy struct {
+	Content   string           `json:"content"`
+	Path      string           `json:"path"`
+	TestCases []PolicyTestCase `json:"testCases,omitempty"`
+	Type      *string          `json:"type,omitempty"`
+}
+
+// PolicyTestSuitePolicyResult is the PolicyTestSuitePolicyResult schema of the Heimdall API
+type PolicyTestSuitePolicyResult struct {
+	Error   string             `json:"error,omitempty"`
+	Package string             `json:"package,omitempty"`
+	Path    string             `json:"path"`
+	Results []PolicyTestResult `json:"results"`
+}
+
+// PolicyTestSuiteRequest is the PolicyTestSuiteRequest schema of the Heimdall API
+type PolicyTestSuiteRequest struct {
+	Policies []PolicyTestSuitePolicy `json:"policies"`
+}
+
+// PolicyTestSuiteResult is the PolicyTestSuiteResult schema of the Heimdall API
+type PolicyTestSuiteResult struct {
+	ExitCode int                           `json:"exitCode"`
+	Failed   int                           `json:"failed"`
+	Passed   bool                          `json:"passed"`
+	Policies []PolicyTestSuitePolicyResult `json:"policies"`
+	Total    int                           `json:"total"`
+}
+
+// PolicyVersion is the PolicyVersion schema of the Heimdall API
+type PolicyVersion struct {
+	ChangeNote string    `json:"changeNote,omitempty"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"createdAt"`
+	CreatedBy  string    `json:"createdBy"`
+	ID         string    `json:"id"`
+	Policy     *Policy   `json:"policy,omitempty"`
+	PolicyID   string    `json:"policyId"`
+	Version    int       `json:"version"`
+}
+
+// PolicyWarning is the PolicyWarning schema of the Heimdall API
+type PolicyWarning struct {
+	Code       string            `json:"code"`
+	Dependents []PolicyReference `json:"dependents,omitempty"`
+	Message    string            `json:"message"`
+}
+
+// PublicTenant is the PublicTenant schema of the Heimdall API
+type PublicTenant struct {
+	Branding     TenantBranding `json:"branding"`
+	ID           string         `json:"id"`
+	LoginMethods []string       `json:"loginMethods"`
+	Name         string         `json:"name"`
+	Slug         string         `json:"slug"`
+	Sso          TenantSSO      `json:"sso"`
+}
+
+// RateLimitsResponse is the RateLimitsResponse schema of the Heimdall API
+type RateLimitsResponse struct {
+	CreatedAt string                 `json:"createdAt"`
+	Defaults  map[string]interface{} `json:"defaults"`
+	Limits    map[string]interface{} `json:"limits"`
+	TenantID  string                 `json:"tenantId"`
+	UpdatedAt string                 `json:"updatedAt"`
+}
+
+// RefreshTokenRequest is the RefreshTokenRequest schema of the Heimdall API
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RegisterRequest is the RegisterRequest schema of the Heimdall API
+type RegisterRequest struct {
+	Email     string  `json:"email"`
+	FirstName string  `json:"firstName"`
+	LastName  string  `json:"lastName"`
+	Password  string  `json:"password"`
+	TenantID  *string `json:"tenantId,omitempty"`
+}
+
+// ReportDecisionLogsResult is the ReportDecisionLogsResult schema of the Heimdall API
+type ReportDecisionLogsResult struct {
+	Accepted int `json:"accepted"`
+}
+
+// ResetPasswordRequest is the ResetPasswordRequest schema of the Heimdall API
+type ResetPasswordRequest struct {
+	NewPassword string `json:"newPassword"`
+	Token       string `json:"token"`
+}
+
+// Resource is the Resource schema of the Heimdall API
+type Resource struct {
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	CreatedAt  string                 `json:"createdAt,omitempty"`
+	Derived    bool                   `json:"derived"`
+	Labels     map[string]interface{} `json:"labels,omitempty"`
+	OwnerID    string                 `json:"ownerId,omitempty"`
+	ResourceID string                 `json:"resourceId"`
+	TenantID   string                 `json:"tenantId,omitempty"`
+	Type       string                 `json:"type"`
+	UpdatedAt  string                 `json:"updatedAt,omitempty"`
+}
+
+// ResourceContext is the ResourceContext schema of the Heimdall API
+type ResourceContext struct {
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	ID         *string                `json:"id,omitempty"`
+	OwnerID    *string                `json:"ownerId,omitempty"`
+	TenantID   *string                `json:"tenantId,omitempty"`
+	Type       string                 `json:"type"`
+}
+
+// RoleAssignment is the RoleAssignment schema of the Heimdall API
+type RoleAssignment struct {
+	AssignedAt string `json:"assignedAt"`
+	AssignedBy string `json:"assignedBy,omitempty"`
+	Expired    bool   `json:"expired"`
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+	ID         string `json:"id"`
+	Reason     string `json:"reason,omitempty"`
+	RoleID     string `json:"roleId"`
+	RoleName   string `json:"roleName"`
+}
+
+// RoleDenyRate is the RoleDenyRate schema of the Heimdall API
+type RoleDenyRate struct {
+	Decisions int     `json:"decisions"`
+	Denied    int     `json:"denied"`
+	DenyRate  float64 `json:"denyRate"`
+	Role      string  `json:"role"`
+}
+
+// RoleResponse is the RoleResponse schema of the Heimdall API
+type RoleResponse struct {
+	CreatedAt   string   `json:"createdAt"`
+	Description string   `json:"description,omitempty"`
+	ID          string   `json:"id"`
+	IsSystem    bool     `json:"isSystem"`
+	Name        string   `json:"name"`
+	ParentRole  string   `json:"parentRole,omitempty"`
+	Permissions []string `json:"permissions"`
+	TenantID    string   `json:"tenantId"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+// RotateOAuthClientSecretRequest is the RotateOAuthClientSecretRequest schema of the Heimdall API
+type RotateOAuthClientSecretRequest struct {
+	GracePeriod *int `json:"gracePeriod,omitempty"`
+}
+
+// SAMLConfigResponse is the SAMLConfigResponse schema of the Heimdall API
+type SAMLConfigResponse struct {
+	AcsURL             string            `json:"acsUrl"`
+	AllowIdpInitiated  bool              `json:"allowIdpInitiated"`
+	CreatedAt          string            `json:"createdAt"`
+	EmailAttribute     string            `json:"emailAttribute,omitempty"`
+	Enabled            bool              `json:"enabled"`
+	EntityID           string            `json:"entityId"`
+	FirstNameAttribute string            `json:"firstNameAttribute,omitempty"`
+	IdpEntityID        string            `json:"idpEntityId"`
+	LastNameAttribute  string            `json:"lastNameAttribute,omitempty"`
+	RoleAttribute      string            `json:"roleAttribute,omitempty"`
+	RoleMappings       []SAMLRoleMapping `json:"roleMappings"`
+	TenantID           string            `json:"tenantId"`
+	UpdatedAt          string            `json:"updatedAt"`
+}
+
+// SAMLRoleMapping is the SAMLRoleMapping schema of the Heimdall API
+type SAMLRoleMapping struct {
+	Role  string `json:"role"`
+	Value string `json:"value"`
+}
+
+// SimulateRequest is the SimulateRequest schema of the Heimdall API
+type SimulateRequest struct {
+	Action   string             `json:"action"`
+	BundleID *string            `json:"bundleId,omitempty"`
+	Context  *SimulationContext `json:"context,omitempty"`
+	Resource *ResourceContext   `json:"resource,omitempty"`
+	Roles    []string           `json:"roles,omitempty"`
+	UserID   *string            `json:"userId,omitempty"`
+}
+
+// SimulatedDecision is the SimulatedDecision schema of the Heimdall API
+type SimulatedDecision struct {
+	Allow    bool   `json:"allow"`
+	BundleID string `json:"bundleId,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// SimulationContext is the SimulationContext schema of the Heimdall API
+type SimulationContext struct {
+	IPAddress   *string    `json:"ipAddress,omitempty"`
+	MfaVerified *bool      `json:"mfaVerified,omitempty"`
+	SessionAge  *int       `json:"sessionAge,omitempty"`
+	Time        *time.Time `json:"time,omitempty"`
+}
+
+// StatusCounts is the StatusCounts schema of the Heimdall API
+type StatusCounts struct {
+	ByStatus map[string]interface{} `json:"byStatus"`
+	Total    int                    `json:"total"`
+}
+
+// SyncPoliciesRequest is the SyncPoliciesRequest schema of the Heimdall API
+type SyncPoliciesRequest struct {
+	Delete *bool        `json:"delete,omitempty"`
+	DryRun *bool        `json:"dryRun,omitempty"`
+	Files  []PolicyFile `json:"files"`
+}
+
+// TenantBranding is the TenantBranding schema of the Heimdall API
+type TenantBranding struct {
+	LogoURL      string `json:"logoUrl,omitempty"`
+	PrimaryColor string `json:"primaryColor,omitempty"`
+}
+
+// TenantResponse is the TenantResponse schema of the Heimdall API
+type TenantResponse struct {
+	CreatedAt string                 `json:"createdAt"`
+	ID        string                 `json:"id"`
+	MaxRoles  int                    `json:"maxRoles"`
+	MaxUsers  int                    `json:"maxUsers"`
+	Name      string                 `json:"name"`
+	Settings  map[string]interface{} `json:"settings,omitempty"`
+	Slug      string                 `json:"slug"`
+	Stats     map[string]interface{} `json:"stats,omitempty"`
+	Status    string                 `json:"status"`
+	UpdatedAt string                 `json:"updatedAt"`
+}
+
+// TenantSSO is the TenantSSO schema of the Heimdall API
+type TenantSSO struct {
+	Available bool   `json:"available"`
+	LoginURL  string `json:"loginUrl,omitempty"`
+	Protocol  string `json:"protocol,omitempty"`
+}
+
+// TenantSigningKey is the TenantSigningKey schema of the Heimdall API
+type TenantSigningKey struct {
+	Alg       string     `json:"alg"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ID        string     `json:"id"`
+	Kid       string     `json:"kid"`
+	PublicKey string     `json:"publicKey"`
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	Status    string     `json:"status"`
+	TenantID  string     `json:"tenantId"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// TokenExchangeRequest is the TokenExchangeRequest schema of the Heimdall API
+type TokenExchangeRequest struct {
+	Audience  *string `json:"audience,omitempty"`
+	ExpiresIn *int    `json:"expiresIn,omitempty"`
+	GrantType *string `json:"grantType,omitempty"`
+	Scope     string  `json:"scope"`
+}
+
+// TokenExchangeResponse is the TokenExchangeResponse schema of the Heimdall API
+type TokenExchangeResponse struct {
+	AccessToken     string `json:"accessToken"`
+	ExpiresIn       int    `json:"expiresIn"`
+	IssuedTokenType string `json:"issuedTokenType"`
+	Scope           string `json:"scope"`
+	TokenType       string `json:"tokenType"`
+}
+
+// TraceEvent is the TraceEvent schema of the Heimdall API
+type TraceEvent struct {
+	Locals   interface{} `json:"locals,omitempty"`
+	Message  string      `json:"message,omitempty"`
+	Node     interface{} `json:"node,omitempty"`
+	Op       string      `json:"op"`
+	ParentID string      `json:"parent_id"`
+	QueryID  string      `json:"query_id"`
+	Type     string      `json:"type"`
+}
+
+// UnmatchedPolicy is the UnmatchedPolicy schema of the Heimdall API
+type UnmatchedPolicy struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Path    string `json:"path"`
+}
+
+// UnusedPermission is the UnusedPermission schema of the Heimdall API
+type UnusedPermission struct {
+	Action   string   `json:"action"`
+	Name     string   `json:"name"`
+	Resource string   `json:"resource"`
+	Roles    []string `json:"roles"`
+}
+
+// UpdateDefaultRolesRequest is the UpdateDefaultRolesRequest schema of the Heimdall API
+type UpdateDefaultRolesRequest struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// UpdateOAuthClientRequest is the UpdateOAuthClientRequest schema of the Heimdall API
+type UpdateOAuthClientRequest struct {
+	Name   *string  `json:"name,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// UpdatePolicyRequest is the UpdatePolicyRequest schema of the Heimdall API
+type UpdatePolicyRequest struct {
+	Content     *string                `json:"content,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Name        *string                `json:"name,omitempty"`
+	Status      *string                `json:"status,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	TestCases   []PolicyTestCase       `json:"testCases,omitempty"`
+}
+
+// UpdateProfileRequest is the UpdateProfileRequest schema of the Heimdall API
+type UpdateProfileRequest struct {
+	FirstName *string                `json:"firstName,omitempty"`
+	LastName  *string                `json:"lastName,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// UpdateTenantRequest is the UpdateTenantRequest schema of the Heimdall API
+type UpdateTenantRequest struct {
+	MaxRoles *int                   `json:"maxRoles,omitempty"`
+	MaxUsers *int                   `json:"maxUsers,omitempty"`
+	Name     *string                `json:"name,omitempty"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+// UpdateUserStatusRequest is the UpdateUserStatusRequest schema of the Heimdall API
+type UpdateUserStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// UpsertClaimsTemplateRequest is the UpsertClaimsTemplateRequest schema of the Heimdall API
+type UpsertClaimsTemplateRequest struct {
+	Audiences          []string               `json:"audiences,omitempty"`
+	CustomClaims       map[string]interface{} `json:"customClaims,omitempty"`
+	IncludePermissions *bool                  `json:"includePermissions,omitempty"`
+	IncludeRoles       *bool                  `json:"includeRoles,omitempty"`
+	MaxTokenSize       *int                   `json:"maxTokenSize,omitempty"`
+	RoleFilter         []string               `json:"roleFilter,omitempty"`
+	TenantMetadata     []string               `json:"tenantMetadata,omitempty"`
+}
+
+// UpsertIPAccessRequest is the UpsertIPAccessRequest schema of the Heimdall API
+type UpsertIPAccessRequest struct {
+	Allowlist []string `json:"allowlist,omitempty"`
+	Denylist  []string `json:"denylist,omitempty"`
+}
+
+// UpsertPermissionRequest is the UpsertPermissionRequest schema of the Heimdall API
+type UpsertPermissionRequest struct {
+	Action      string  `json:"action"`
+	Description *string `json:"description,omitempty"`
+	Resource    string  `json:"resource"`
+	Scope       *string `json:"scope,omitempty"`
+}
+
+// UpsertPolicyRequest is the UpsertPolicyRequest schema of the Heimdall API
+type UpsertPolicyRequest struct {
+	Content     string                 `json:"content"`
+	Description *string                `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Name        string                 `json:"name"`
+	Tags        []string               `json:"tags,omitempty"`
+	TestCases   []PolicyTestCase       `json:"testCases,omitempty"`
+	Type        *string                `json:"type,omitempty"`
+}
+
+// UpsertRateLimitsRequest is the UpsertRateLimitsRequest schema of the Heimdall API
+type UpsertRateLimitsRequest struct {
+	Limits map[string]interface{} `json:"limits"`
+}
+
+// UpsertResourceRequest is the UpsertResourceRequest schema of the Heimdall API
+type UpsertResourceRequest struct {
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Labels     map[string]interface{} `json:"labels,omitempty"`
+	OwnerID    *string                `json:"ownerId,omitempty"`
+}
+
+// UpsertRoleRequest is the UpsertRoleRequest schema of the Heimdall API
+type UpsertRoleRequest struct {
+	Description *string  `json:"description,omitempty"`
+	ParentRole  *string  `json:"parentRole,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// UpsertSAMLConfigRequest is the UpsertSAMLConfigRequest schema of the Heimdall API
+type UpsertSAMLConfigRequest struct {
+	AllowIdpInitiated  *bool             `json:"allowIdpInitiated,omitempty"`
+	EmailAttribute     *string           `json:"emailAttribute,omitempty"`
+	Enabled            *bool             `json:"enabled,omitempty"`
+	FirstNameAttribute *string           `json:"firstNameAttribute,omitempty"`
+	IdpMetadata        string            `json:"idpMetadata"`
+	LastNameAttribute  *string           `json:"lastNameAttribute,omitempty"`
+	RoleAttribute      *string           `json:"roleAttribute,omitempty"`
+	RoleMappings       []SAMLRoleMapping `json:"roleMappings,omitempty"`
+}
+
+// UpsertTenantRequest is the UpsertTenantRequest schema of the Heimdall API
+type UpsertTenantRequest struct {
+	MaxRoles *int                   `json:"maxRoles,omitempty"`
+	MaxUsers *int                   `json:"maxUsers,omitempty"`
+	Name     string                 `json:"name"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Status   *string                `json:"status,omitempty"`
+}
+
+// UpsertUserAttributeSchemaRequest is the UpsertUserAttributeSchemaRequest schema of the Heimdall API
+type UpsertUserAttributeSchemaRequest struct {
+	Attributes []UserAttributeDefinition `json:"attributes"`
+}
+
+// UserAttributeDefinition is the UserAttributeDefinition schema of the Heimdall API
+type UserAttributeDefinition struct {
+	Description  *string  `json:"description,omitempty"`
+	Maximum      *float64 `json:"maximum,omitempty"`
+	Minimum      *float64 `json:"minimum,omitempty"`
+	Name         string   `json:"name"`
+	Required     *bool    `json:"required,omitempty"`
+	Type         string   `json:"type"`
+	UserEditable *bool    `json:"userEditable,omitempty"`
+	Values       []string `json:"values,omitempty"`
+}
+
+// UserAttributeSchemaResponse is the UserAttributeSchemaResponse schema of the Heimdall API
+type UserAttributeSchemaResponse struct {
+	Attributes []UserAttributeDefinition `json:"attributes"`
+	TenantID   string                    `json:"tenantId"`
+	UpdatedAt  string                    `json:"updatedAt"`
+}
+
+// UserInfo is the UserInfo schema of the Heimdall API
+type UserInfo struct {
+	Email     string `json:"email"`
+	FirstName string `json:"firstName,omitempty"`
+	ID        string `json:"id"`
+	LastName  string `json:"lastName,omitempty"`
+	TenantID  string `json:"tenantId"`
+}
+
+// UserOverview is the UserOverview schema of the Heimdall API
+type UserOverview struct {
+	ActiveLast24h int                    `json:"activeLast24h"`
+	ByStatus      map[string]interface{} `json:"byStatus"`
+	Total         int                    `json:"total"`
+}
+
+// UserProfile is the UserProfile schema of the Heimdall API
+type UserProfile struct {
+	CreatedAt     string                 `json:"createdAt"`
+	DeactivatedAt string                 `json:"deactivatedAt,omitempty"`
+	Email         string                 `json:"email"`
+	FirstName     string                 `json:"firstName,omitempty"`
+	ID            string                 `json:"id"`
+	LastLoginAt   string                 `json:"lastLoginAt,omitempty"`
+	LastName      string                 `json:"lastName,omitempty"`
+	LoginCount    int                    `json:"loginCount"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Roles         []string               `json:"roles,omitempty"`
+	Status        string                 `json:"status"`
+	TenantID      string                 `json:"tenantId"`
+}
+
+// ListAccessRequests calls GET /v1/access-requests: list access requests
+//
+// List the access requests of the caller's tenant, e.g. ?status=pending for those awaiting a decision. Requires the access_requests.read permission.
+func (c *Client) ListAccessRequests(ctx context.Context, params *ListAccessRequestsParams) (*ListAccessRequestsResult, error) {
+	var result ListAccessRequestsResult
+	if err := c.do(ctx, "GET", "/v1/access-requests", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateAccessRequest calls POST /v1/access-requests: request access
+//
+// Request a role of the caller's tenant for a limited time with a justification. The tenant's approvers are notified by webhook and, when enabled, email. Durations are capped by ROLE_ELEVATION_MAX_HOURS.
+func (c *Client) CreateAccessRequest(ctx context.Context, req *CreateAccessRequestRequest) (*AccessRequest, error) {
+	var result AccessRequest
+	if err := c.do(ctx, "POST", "/v1/access-requests", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAccessRequest calls GET /v1/access-requests/{requestId}: get access request
+//
+// Get an access request of the caller's tenant. Requires the access_requests.read permission.
+func (c *Client) GetAccessRequest(ctx context.Context, requestId string) (*AccessRequest, error) {
+	var result AccessRequest
+	if err := c.do(ctx, "GET", "/v1/access-requests/"+url.PathEscape(requestId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApproveAccessRequest calls POST /v1/access-requests/{requestId}/approve: approve access request
+//
+// Approve a pending request, granting the requested role until the requested duration, or a shorter durationMinutes, has passed. The assignment is removed automatically once it expires. Users cannot approve their own requests. Requires the access_requests.approve permission.
+func (c *Client) ApproveAccessRequest(ctx context.Context, requestId string, req *AccessRequestDecision) (*AccessRequest, error) {
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	var result AccessRequest
+	if err := c.do(ctx, "POST", "/v1/access-requests/"+url.PathEscape(requestId)+"/approve", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CancelAccessRequest calls POST /v1/access-requests/{requestId}/cancel: cancel access request
+//
+// Withdraw one of the caller's pending requests.
+func (c *Client) CancelAccessRequest(ctx context.Context, requestId string) (*AccessRequest, error) {
+	var result AccessRequest
+	if err := c.do(ctx, "POST", "/v1/access-requests/"+url.PathEscape(requestId)+"/cancel", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DenyAccessRequest calls POST /v1/access-requests/{requestId}/deny: deny access request
+//
+// Deny a pending request. Users cannot deny their own requests. Requires the access_requests.approve permission.
+func (c *Client) DenyAccessRequest(ctx context.Context, requestId string, req *AccessRequestDecision) (*AccessRequest, error) {
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	var result AccessRequest
+	if err := c.do(ctx, "POST", "/v1/access-requests/"+url.PathEscape(requestId)+"/deny", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IssueActionNonce calls POST /v1/action-nonces: issue action nonce
+//
+// Issue a one-time nonce confirming a destructive action of the caller on a resource: tenants.delete, users.deactivate, bundles.activate, bundles.delete or policies.purge. The action's request sends the nonce in the X-Action-Nonce header before it expires; each nonce is accepted once, so admin UIs fetch one per confirmation.
+func (c *Client) IssueActionNonce(ctx context.Context, req *ActionNonceRequest) (*ActionNonce, error) {
+	var result ActionNonce
+	if err := c.do(ctx, "POST", "/v1/action-nonces", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBodyLoggingRules calls GET /v1/admin/body-logging: list body logging rules
+//
+// List the routes whose request and response bodies are logged, until their rules expire. Requires admin.logging.
+func (c *Client) ListBodyLoggingRules(ctx context.Context) ([]BodyLoggingRule, error) {
+	var result []BodyLoggingRule
+	if err := c.do(ctx, "GET", "/v1/admin/body-logging", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// EnableBodyLogging calls POST /v1/admin/body-logging: enable body logging
+//
+// Log the request and response bodies of a route, on all instances, for durationMinutes (15 by default, at most BODY_LOG_MAX_MINUTES). The route is given as documented, e.g. /v1/users/{userId}, and covers every request it serves; a rule without method covers all its methods. Passwords, tokens, refresh tokens, API keys and other credentials are redacted from the logged bodies by field name and by pattern. Requires admin.logging.
+func (c *Client) EnableBodyLogging(ctx context.Context, req *EnableBodyLoggingRequest) (*BodyLoggingRule, error) {
+	var result BodyLoggingRule
+	if err := c.do(ctx, "POST", "/v1/admin/body-logging", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DisableBodyLogging calls DELETE /v1/admin/body-logging/{ruleId}: disable body logging
+//
+// Delete a body logging rule before it expires. Requires admin.logging.
+func (c *Client) DisableBodyLogging(ctx context.Context, ruleId string) error {
+	return c.do(ctx, "DELETE", "/v1/admin/body-logging/"+url.PathEscape(ruleId), nil, nil, nil)
+}
+
+// GetAdminOverview calls GET /v1/admin/overview: get admin overview
+//
+// Snapshot of all tenants for the admin landing page: tenants and users per status, users active in the last 24 hours, active bundles and their revisions, the health of the OPA server and of the OPA instances reporting their status, the most recent failed bundle deployments, and the error rates of administrative actions and reported decisions in the last 24 hours. Requires admin.overview.
+func (c *Client) GetAdminOverview(ctx context.Context) (*AdminOverview, error) {
+	var result AdminOverview
+	if err := c.do(ctx, "GET", "/v1/admin/overview", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAlertRules calls GET /v1/alert-rules: list alert rules
+//
+// List the alert rules of the caller's tenant. Requires the alert_rules.read permission.
+func (c *Client) ListAlertRules(ctx context.Context, params *ListAlertRulesParams) (*ListAlertRulesResult, error) {
+	var result ListAlertRulesResult
+	if err := c.do(ctx, "GET", "/v1/alert-rules", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateAlertRule calls POST /v1/alert-rules: create alert rule
+//
+// Create a rule alerting when a condition on the tenant's audit log exceeds a threshold within a window: more than threshold requests of a user denied with 403 (user_denies), more than threshold failed logins from an IP address (ip_login_failures), or more than threshold percent of OPA decisions failing to evaluate (decision_error_rate). Alerts are delivered to the platform webhooks as alert.fired events, and to the rule's webhook and PagerDuty service. Requires the alert_rules.write permission.
+func (c *Client) CreateAlertRule(ctx context.Context, req *AlertRuleRequest) (*AlertRule, error) {
+	var result AlertRule
+	if err := c.do(ctx, "POST", "/v1/alert-rules", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAlertRule calls GET /v1/alert-rules/{ruleId}: get alert rule
+//
+// Get an alert rule of the caller's tenant. Requires the alert_rules.read permission.
+func (c *Client) GetAlertRule(ctx context.Context, ruleId string) (*AlertRule, error) {
+	var result AlertRule
+	if err := c.do(ctx, "GET", "/v1/alert-rules/"+url.PathEscape(ruleId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateAlertRule calls PUT /v1/alert-rules/{ruleId}: update alert rule
+//
+// Replace the condition and settings of an alert rule. The webhook URL and PagerDuty routing key are kept when omitted and removed when empty. Requires the alert_rules.write permission.
+func (c *Client) UpdateAlertRule(ctx context.Context, ruleId string, req *AlertRuleRequest) (*AlertRule, error) {
+	var result AlertRule
+	if err := c.do(ctx, "PUT", "/v1/alert-rules/"+url.PathEscape(ruleId), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAlertRule calls DELETE /v1/alert-rules/{ruleId}: delete alert rule
+//
+// Delete an alert rule and the alerts it fired. Requires the alert_rules.write permission.
+func (c *Client) DeleteAlertRule(ctx context.Context, ruleId string) error {
+	return c.do(ctx, "DELETE", "/v1/alert-rules/"+url.PathEscape(ruleId), nil, nil, nil)
+}
+
+// ListAlerts calls GET /v1/alert-rules/{ruleId}/alerts: list fired alerts
+//
+// List the alerts an alert rule fired. A rule fires once per user or IP address within its window. Requires the alert_rules.read permission.
+func (c *Client) ListAlerts(ctx context.Context, ruleId string, params *ListAlertsParams) (*ListAlertsResult, error) {
+	var result ListAlertsResult
+	if err := c.do(ctx, "GET", "/v1/alert-rules/"+url.PathEscape(ruleId)+"/alerts", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAuthAnalytics calls GET /v1/analytics/auth: get login analytics
+//
+// Report the caller's tenant's daily and weekly active users, successful and failed logins, new registrations and MFA adoption per day (UTC) over a period, 30 days by default and at most 366. The report is read from daily stats a background job aggregates every AUTH_ANALYTICS_INTERVAL_SECONDS, so it lags behind the latest logins by up to one interval; aggregatedAt tells when the stats were last aggregated.
+func (c *Client) GetAuthAnalytics(ctx context.Context, params *GetAuthAnalyticsParams) (*AuthAnalytics, error) {
+	var result AuthAnalytics
+	if err := c.do(ctx, "GET", "/v1/analytics/auth", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAuthzAnalytics calls GET /v1/analytics/authz: get authorization analytics
+//
+// Aggregate the caller's tenant's audit log over a period, 30 days by default and at most 366: the deny rate of administrative actions and reported OPA decisions, the most denied routes, deny rates per role, permissions granted but never used, roles whose members were not active, and active policies no reported decision queried. Send format=csv for a CSV export with one row per item.
+func (c *Client) GetAuthzAnalytics(ctx context.Context, params *GetAuthzAnalyticsParams) (*AuthzAnalytics, error) {
+	var result AuthzAnalytics
+	if err := c.do(ctx, "GET", "/v1/analytics/authz", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAdminActions calls GET /v1/audit/admin-actions: list admin actions
+//
+// List the sensitive administrative actions of the caller's tenant, such as role assignments, policy publishes, tenant suspensions and user deletions, with the acting user, route, result and redacted request and response payloads
+func (c *Client) ListAdminActions(ctx context.Context, params *ListAdminActionsParams) (*ListAdminActionsResult, error) {
+	var result ListAdminActionsResult
+	if err := c.do(ctx, "GET", "/v1/audit/admin-actions", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListDecisions calls GET /v1/audit/decisions: list reported decisions
+//
+// List the decisions reported by the caller's tenant's OPA instances through the decision log API, with the reporting instance, input, result and bundle revisions. Filter by status allowed, denied, error or unknown.
+func (c *Client) ListDecisions(ctx context.Context, params *ListDecisionsParams) (*ListDecisionsResult, error) {
+	var result ListDecisionsResult
+	if err := c.do(ctx, "GET", "/v1/audit/decisions", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Login calls POST /v1/auth/login: login with email and password
+//
+// Authenticate user and return access tokens
+func (c *Client) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
+	var result AuthResponse
+	if err := c.do(ctx, "POST", "/v1/auth/login", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Logout calls POST /v1/auth/logout: logout user
+//
+// Invalidate current session and tokens
+func (c *Client) Logout(ctx context.Context) error {
+	return c.do(ctx, "POST", "/v1/auth/logout", nil, nil, nil)
+}
+
+// LogoutAll calls POST /v1/auth/logout-all: logout from all devices
+//
+// Invalidate all sessions and tokens for the user
+func (c *Client) LogoutAll(ctx context.Context) error {
+	return c.do(ctx, "POST", "/v1/auth/logout-all", nil, nil, nil)
+}
+
+// RequestMagicLink calls POST /v1/auth/magic-link: request magic link
+//
+// Email a single-use sign-in link to an active user of the tenant, or of the default tenant when none is given. Unknown emails, inactive users and emails over their hourly limit get the same response without an email, so it does not reveal which emails are registered.
+func (c *Client) RequestMagicLink(ctx context.Context, req *MagicLinkRequest) (*MagicLinkResponse, error) {
+	var result MagicLinkResponse
+	if err := c.do(ctx, "POST", "/v1/auth/magic-link", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RedeemMagicLink calls POST /v1/auth/magic-link/verify: redeem magic link
+//
+// Sign the user of a magic link in, running the same login hooks as a password login. Each link can be redeemed once.
+func (c *Client) RedeemMagicLink(ctx context.Context, req *MagicLinkRedeemRequest) (*AuthResponse, error) {
+	var result AuthResponse
+	if err := c.do(ctx, "POST", "/v1/auth/magic-link/verify", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ChangePassword calls POST /v1/auth/password/change: change password
+//
+// Change password for authenticated user
+func (c *Client) ChangePassword(ctx context.Context, req *ChangePasswordRequest) error {
+	return c.do(ctx, "POST", "/v1/auth/password/change", nil, req, nil)
+}
+
+// ForgotPassword calls POST /v1/auth/password/reset: request password reset
+//
+// Email a password reset to the user. The response does not reveal whether the email is registered.
+func (c *Client) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	return c.do(ctx, "POST", "/v1/auth/password/reset", nil, req, nil)
+}
+
+// ResetPassword calls POST /v1/auth/password/reset/confirm: reset password
+//
+// Set a new password with the token of a password reset email. Identity providers completing resets on their own pages, such as FusionAuth, return PASSWORD_RESET_NOT_SUPPORTED.
+func (c *Client) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	return c.do(ctx, "POST", "/v1/auth/password/reset/confirm", nil, req, nil)
+}
+
+// RefreshToken calls POST /v1/auth/refresh: refresh access token
+//
+// Obtain a new access token using refresh token
+func (c *Client) RefreshToken(ctx context.Context, req *RefreshTokenRequest) (*AuthResponse, error) {
+	var result AuthResponse
+	if err := c.do(ctx, "POST", "/v1/auth/refresh", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RegisterUser calls POST /v1/auth/register: register a new user
+//
+// Create a new user account with email and password
+func (c *Client) RegisterUser(ctx context.Context, req *RegisterRequest) (*AuthResponse, error) {
+	var result AuthResponse
+	if err := c.do(ctx, "POST", "/v1/auth/register", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExchangeToken calls POST /v1/auth/token/exchange: exchange token
+//
+// Exchange the bearer token for a short-lived access token limited to a subset of its permissions and, optionally, to an audience (RFC 8693), e.g. to pass to a third-party service. The new token records the exchanged token in its act claim and is revoked with it. Exchanged tokens are denied permissions outside their scope and cannot be used for self-service endpoints.
+func (c *Client) ExchangeToken(ctx context.Context, req *TokenExchangeRequest) (*TokenExchangeResponse, error) {
+	var result TokenExchangeResponse
+	if err := c.do(ctx, "POST", "/v1/auth/token/exchange", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CheckAuthorization calls POST /v1/authz/check: check authorization
+//
+// Evaluate whether the authenticated user may perform an action on a resource. A Cache-Control header with max-age, max-stale, no-cache or no-store controls use of cached decisions.
+func (c *Client) CheckAuthorization(ctx context.Context, req *AuthzCheckRequest) (*AuthzDecision, error) {
+	var result AuthzDecision
+	if err := c.do(ctx, "POST", "/v1/authz/check", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExplainAuthorization calls POST /v1/authz/explain: explain authorization decision
+//
+// Evaluate whether the authenticated user may perform an action on a resource with OPA's explanation enabled, returning the rules that fired, the input paths they read, the result of each policy module and why the request was denied. Set trace to include OPA's raw evaluation trace. Requires the authz.debug permission; decisions are never cached.
+func (c *Client) ExplainAuthorization(ctx context.Context, req *AuthzExplainRequest) (*AuthzExplanation, error) {
+	var result AuthzExplanation
+	if err := c.do(ctx, "POST", "/v1/authz/explain", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SimulateAuthorization calls POST /v1/authz/simulate: simulate authorization decision
+//
+// Evaluate what a user of the tenant, or a hypothetical set of roles, may do without acting as them. The context overrides the time, IP address, MFA state and session age of the request. With bundleId the request is also evaluated against a draft policy bundle, and changed reports whether its decision differs from the active policies. Requires the authz.simulate permission; decisions are never cached.
+func (c *Client) SimulateAuthorization(ctx context.Context, req *SimulateRequest) (*AuthzSimulation, error) {
+	var result AuthzSimulation
+	if err := c.do(ctx, "POST", "/v1/authz/simulate", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetBreakGlassSession calls GET /v1/break-glass/session: get break-glass session
+//
+// Describe the break-glass token the request was made with, to check a token before relying on it. Only available when BREAK_GLASS_PUBLIC_KEY_PATH is configured.
+func (c *Client) GetBreakGlassSession(ctx context.Context) (*BreakGlassSession, error) {
+	var result BreakGlassSession
+	if err := c.do(ctx, "GET", "/v1/break-glass/session", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBundles calls GET /v1/bundles: list bundles
+//
+// List the policy bundles of the current tenant
+func (c *Client) ListBundles(ctx context.Context, params *ListBundlesParams) (*ListBundlesResult, error) {
+	var result ListBundlesResult
+	if err := c.do(ctx, "GET", "/v1/bundles", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateBundle calls POST /v1/bundles: create bundle
+//
+// Create a bundle from policies; it is built asynchronously by a background job whose ID is returned as buildJobId, polled with GET /jobs/{jobId}
+func (c *Client) CreateBundle(ctx context.Context, req *CreateBundleRequest) (*PolicyBundle, error) {
+	var result PolicyBundle
+	if err := c.do(ctx, "POST", "/v1/bundles", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetBundle calls GET /v1/bundles/{id}: get bundle
+func (c *Client) GetBundle(ctx context.Context, id string) (*PolicyBundle, error) {
+	var result PolicyBundle
+	if err := c.do(ctx, "GET", "/v1/bundles/"+url.PathEscape(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteBundle calls DELETE /v1/bundles/{id}: delete bundle
+//
+// Delete an inactive bundle. Must be confirmed with a bundles.delete action nonce
+func (c *Client) DeleteBundle(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/v1/bundles/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ActivateBundle calls POST /v1/bundles/{id}/activate: activate bundle
+//
+// Make a built bundle the active bundle of its tenant, or roll back to an earlier one. Must be confirmed with a bundles.activate action nonce
+func (c *Client) ActivateBundle(ctx context.Context, id string) (*PolicyBundle, error) {
+	var result PolicyBundle
+	if err := c.do(ctx, "POST", "/v1/bundles/"+url.PathEscape(id)+"/activate", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBundleContents calls GET /v1/bundles/{id}/contents: list bundle contents
+//
+// List the files inside a built bundle as it was uploaded, with their sizes, SHA-256 checksums and the policies they were built from
+func (c *Client) ListBundleContents(ctx context.Context, id string) (*BundleContents, error) {
+	var result BundleContents
+	if err := c.do(ctx, "GET", "/v1/bundles/"+url.PathEscape(id)+"/contents", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeployBundle calls POST /v1/bundles/{id}/deploy: deploy bundle
+//
+// Deploy a built bundle to an environment (default production)
+func (c *Client) DeployBundle(ctx context.Context, id string, req *DeployBundleRequest) (*BundleDeployment, error) {
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	var result BundleDeployment
+	if err := c.do(ctx, "POST", "/v1/bundles/"+url.PathEscape(id)+"/deploy", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBundleDeployments calls GET /v1/bundles/{id}/deployments: list bundle deployments
+//
+// List the deployments of a bundle, newest first, with the manifest revision and roots each deployment shipped
+func (c *Client) ListBundleDeployments(ctx context.Context, id string) ([]BundleDeployment, error) {
+	var result []BundleDeployment
+	if err := c.do(ctx, "GET", "/v1/bundles/"+url.PathEscape(id)+"/deployments", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBundleDownloadURL calls GET /v1/bundles/{id}/download-url: get bundle download URL
+//
+// Get a time-limited presigned URL downloading a built bundle directly from MinIO, S3 or GCS instead of through the API. Not available with local bundle storage.
+func (c *Client) GetBundleDownloadURL(ctx context.Context, id string, params *GetBundleDownloadURLParams) (*BundleDownloadURL, error) {
+	var result BundleDownloadURL
+	if err := c.do(ctx, "GET", "/v1/bundles/"+url.PathEscape(id)+"/download-url", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListJobs calls GET /v1/jobs: list jobs
+//
+// List the background jobs of the caller's tenant. Filter by status dead to find jobs that failed permanently or ran out of attempts. Requires the jobs.read permission.
+func (c *Client) ListJobs(ctx context.Context, params *ListJobsParams) (*ListJobsResult, error) {
+	var result ListJobsResult
+	if err := c.do(ctx, "GET", "/v1/jobs", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetJob calls GET /v1/jobs/{jobId}: get job
+//
+// Get a background job of the caller's tenant, or one the caller started, to poll the outcome of an asynchronous operation such as a bundle build. Failed jobs are retried with exponential backoff until they run out of attempts and are marked dead. Requires the jobs.read permission.
+func (c *Client) GetJob(ctx context.Context, jobId string) (*Job, error) {
+	var result Job
+	if err := c.do(ctx, "GET", "/v1/jobs/"+url.PathEscape(jobId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReportDecisionLogs calls POST /v1/logs: report decision logs
+//
+// Decision log API for OPA instances, e.g. sidecars running Heimdall's bundles. Accepts a batch of decision events as OPA's decision log plugin sends them, gzip compressed with Content-Encoding: gzip or plain, and stores them in the audit log of the caller's tenant attributed to the reporting instance. Requires decision_logs.write.
+func (c *Client) ReportDecisionLogs(ctx context.Context, req []DecisionEvent) (*ReportDecisionLogsResult, error) {
+	var result ReportDecisionLogsResult
+	if err := c.do(ctx, "POST", "/v1/logs", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetDeviceAuthorization calls GET /v1/oauth/device: get device authorization
+//
+// Look up the device authorization of a user code, so the verification page can show which client asks to sign in
+func (c *Client) GetDeviceAuthorization(ctx context.Context, params *GetDeviceAuthorizationParams) (*DeviceAuthorizationResponse, error) {
+	var result DeviceAuthorizationResponse
+	if err := c.do(ctx, "GET", "/v1/oauth/device", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApproveDeviceAuthorization calls POST /v1/oauth/device/approve: approve device
+//
+// Approve the device authorization of a user code. At its next token request the device is signed in as the current user.
+func (c *Client) ApproveDeviceAuthorization(ctx context.Context, req *DeviceVerificationRequest) (*DeviceAuthorizationResponse, error) {
+	var result DeviceAuthorizationResponse
+	if err := c.do(ctx, "POST", "/v1/oauth/device/approve", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DenyDeviceAuthorization calls POST /v1/oauth/device/deny: deny device
+//
+// Deny the device authorization of a user code. The device's next token request fails with access_denied.
+func (c *Client) DenyDeviceAuthorization(ctx context.Context, req *DeviceVerificationRequest) (*DeviceAuthorizationResponse, error) {
+	var result DeviceAuthorizationResponse
+	if err := c.do(ctx, "POST", "/v1/oauth/device/deny", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListOPAInstances calls GET /v1/opa-instances: list OPA instances
+//
+// List the OPA instances reporting their status to the caller's tenant, most recently seen first, with the bundle revisions they have loaded and whether each is the latest build of its Heimdall bundle, their plugin states, and whether they are healthy or have stopped reporting
+func (c *Client) ListOPAInstances(ctx context.Context, params *ListOPAInstancesParams) (*ListOPAInstancesResult, error) {
+	var result ListOPAInstancesResult
+	if err := c.do(ctx, "GET", "/v1/opa-instances", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPermission calls GET /v1/permissions/{name}: get permission
+func (c *Client) GetPermission(ctx context.Context, name string) (*Permission, error) {
+	var result Permission
+	if err := c.do(ctx, "GET", "/v1/permissions/"+url.PathEscape(name), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertPermission calls PUT /v1/permissions/{name}: upsert permission
+//
+// Create or replace a permission by name. Send If-Match with a previously returned ETag to update only an unchanged permission, or If-None-Match: * to only create it.
+func (c *Client) UpsertPermission(ctx context.Context, name string, req *UpsertPermissionRequest) (*Permission, error) {
+	var result Permission
+	if err := c.do(ctx, "PUT", "/v1/permissions/"+url.PathEscape(name), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePermission calls DELETE /v1/permissions/{name}: delete permission
+//
+// Delete a permission and remove it from all roles
+func (c *Client) DeletePermission(ctx context.Context, name string) error {
+	return c.do(ctx, "DELETE", "/v1/permissions/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListPolicies calls GET /v1/policies: list policies
+//
+// List the policies of the current tenant
+func (c *Client) ListPolicies(ctx context.Context, params *ListPoliciesParams) (*ListPoliciesResult, error) {
+	var result ListPoliciesResult
+	if err := c.do(ctx, "GET", "/v1/policies", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreatePolicy calls POST /v1/policies: create policy
+//
+// Create a draft policy in the current tenant
+func (c *Client) CreatePolicy(ctx context.Context, req *CreatePolicyRequest) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "POST", "/v1/policies", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CompilePolicyRules calls POST /v1/policies/compile: compile policy rules
+//
+// Compile the structured rules of a JSON policy into the Rego module Heimdall loads for it, without saving them
+func (c *Client) CompilePolicyRules(ctx context.Context, req *PolicyRuleSet) (*CompilePolicyRulesResult, error) {
+	var result CompilePolicyRulesResult
+	if err := c.do(ctx, "POST", "/v1/policies/compile", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListDeletedPolicies calls GET /v1/policies/deleted: list deleted policies
+//
+// List the deleted policies of the current tenant that can still be restored
+func (c *Client) ListDeletedPolicies(ctx context.Context, params *ListDeletedPoliciesParams) (*ListDeletedPoliciesResult, error) {
+	var result ListDeletedPoliciesResult
+	if err := c.do(ctx, "GET", "/v1/policies/deleted", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportPolicies calls GET /v1/policies/export: export policies
+//
+// Export the tenant's Rego policies as files, sorted by path
+func (c *Client) ExportPolicies(ctx context.Context) (*ExportPoliciesResult, error) {
+	var result ExportPoliciesResult
+	if err := c.do(ctx, "GET", "/v1/policies/export", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FormatPolicy calls POST /v1/policies/format: format policy
+//
+// Format a Rego module with opa fmt without saving it. changed is true when the module was not formatted.
+func (c *Client) FormatPolicy(ctx context.Context, req *PolicyContentRequest) (*PolicyFormatResult, error) {
+	var result PolicyFormatResult
+	if err := c.do(ctx, "POST", "/v1/policies/format", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreatePolicyFromTemplate calls POST /v1/policies/from-template: create policy from template
+//
+// Create a draft Rego policy in the current tenant by filling in the variables of a policy template. The template and variables are recorded in the policy's metadata.
+func (c *Client) CreatePolicyFromTemplate(ctx context.Context, req *CreatePolicyFromTemplateRequest) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "POST", "/v1/policies/from-template", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// LintPolicy calls POST /v1/policies/lint: lint policy
+//
+// Check a Rego module without saving it for allow rules without conditions (error), allow rules without a default deny and unused local variables (warnings)
+func (c *Client) LintPolicy(ctx context.Context, req *PolicyContentRequest) (*LintPolicyResult, error) {
+	var result LintPolicyResult
+	if err := c.do(ctx, "POST", "/v1/policies/lint", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPolicyByPath calls GET /v1/policies/path/{path}: get policy by path
+func (c *Client) GetPolicyByPath(ctx context.Context, path string) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "GET", "/v1/policies/path/"+url.PathEscape(path), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertPolicy calls PUT /v1/policies/path/{path}: upsert policy by path
+//
+// Create or replace the policy at the given path; content changes create a new version. Send If-Match with a previously returned ETag to update only an unchanged policy, or If-None-Match: * to only create it.
+func (c *Client) UpsertPolicy(ctx context.Context, path string, req *UpsertPolicyRequest) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "PUT", "/v1/policies/path/"+url.PathEscape(path), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SyncPolicies calls POST /v1/policies/sync: sync policies
+//
+// Create, update and optionally delete the tenant's Rego policies to match a set of files, matched by path. With dryRun the changes and their diffs are reported without being applied.
+func (c *Client) SyncPolicies(ctx context.Context, req *SyncPoliciesRequest) (*PolicySyncResult, error) {
+	var result PolicySyncResult
+	if err := c.do(ctx, "POST", "/v1/policies/sync", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RunPolicyTestSuite calls POST /v1/policies/test-suite: run policy test suite
+//
+// Load a set of policies into OPA together without saving them, so they can import each other, and run the test cases of each against its package. exitCode is 0 when every test passed, 1 when a test failed and 2 when a policy did not compile; failing suites are answered with 422 so CI steps gate on them.
+func (c *Client) RunPolicyTestSuite(ctx context.Context, req *PolicyTestSuiteRequest) (*PolicyTestSuiteResult, error) {
+	var result PolicyTestSuiteResult
+	if err := c.do(ctx, "POST", "/v1/policies/test-suite", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPolicy calls GET /v1/policies/{id}: get policy
+func (c *Client) GetPolicy(ctx context.Context, id string) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "GET", "/v1/policies/"+url.PathEscape(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePolicy calls PUT /v1/policies/{id}: update policy
+//
+// Update a policy; content changes create a new version
+func (c *Client) UpdatePolicy(ctx context.Context, id string, req *UpdatePolicyRequest) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "PUT", "/v1/policies/"+url.PathEscape(id), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePolicy calls DELETE /v1/policies/{id}: delete policy
+//
+// Soft delete a policy; it can be restored until it is purged. Policies included in a ready or active bundle cannot be deleted, failing with POLICY_IN_BUNDLE and the bundles in the error details.
+func (c *Client) DeletePolicy(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/v1/policies/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ArchivePolicy calls POST /v1/policies/{id}/archive: archive policy
+//
+// Mark a policy archived. Archiving a policy whose package other policies import, with no other published policy providing it, succeeds with a POLICY_HAS_DEPENDENTS warning listing them.
+func (c *Client) ArchivePolicy(ctx context.Context, id string) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "POST", "/v1/policies/"+url.PathEscape(id)+"/archive", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPolicyDependencies calls GET /v1/policies/{id}/dependencies: get policy dependencies
+//
+// List the packages a policy imports, resolved to the policies of the tenant defining them, and the policies importing its package. Imports no policy of the tenant defines, such as synced data, have no package.
+func (c *Client) GetPolicyDependencies(ctx context.Context, id string) (*PolicyDependencies, error) {
+	var result PolicyDependencies
+	if err := c.do(ctx, "GET", "/v1/policies/"+url.PathEscape(id)+"/dependencies", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PublishPolicy calls POST /v1/policies/{id}/publish: publish policy
+//
+// Validate a policy and mark it active. Policies importing packages of the tenant that no active, valid policy provides cannot be published, failing with POLICY_DEPENDENCY_UNPUBLISHED and the imports in the error details.
+func (c *Client) PublishPolicy(ctx context.Context, id string) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "POST", "/v1/policies/"+url.PathEscape(id)+"/publish", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PurgePolicy calls DELETE /v1/policies/{id}/purge: purge policy
+//
+// Permanently delete a deleted policy with its versions, removing it from the inactive and failed bundles that included it. Must be confirmed with a policies.purge action nonce
+func (c *Client) PurgePolicy(ctx context.Context, id string) error {
+	return c.do(ctx, "DELETE", "/v1/policies/"+url.PathEscape(id)+"/purge", nil, nil, nil)
+}
+
+// RestorePolicy calls POST /v1/policies/{id}/restore: restore policy
+//
+// Restore a deleted policy that has not been purged, with the status and content it was deleted with
+func (c *Client) RestorePolicy(ctx context.Context, id string) (*Policy, error) {
+	var result Policy
+	if err := c.do(ctx, "POST", "/v1/policies/"+url.PathEscape(id)+"/restore", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TestPolicy calls POST /v1/policies/{id}/test: test policy
+//
+// Run the policy's test cases
+func (c *Client) TestPolicy(ctx context.Context, id string) ([]PolicyTestResult, error) {
+	var result []PolicyTestResult
+	if err := c.do(ctx, "POST", "/v1/policies/"+url.PathEscape(id)+"/test", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ValidatePolicy calls POST /v1/policies/{id}/validate: validate policy
+//
+// Compile a policy without publishing it
+func (c *Client) ValidatePolicy(ctx context.Context, id string) error {
+	return c.do(ctx, "POST", "/v1/policies/"+url.PathEscape(id)+"/validate", nil, nil, nil)
+}
+
+// GetPolicyVersions calls GET /v1/policies/{id}/versions: list policy versions
+func (c *Client) GetPolicyVersions(ctx context.Context, id string) ([]PolicyVersion, error) {
+	var result []PolicyVersion
+	if err := c.do(ctx, "GET", "/v1/policies/"+url.PathEscape(id)+"/versions", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListPolicyTemplates calls GET /v1/policy-templates: list policy templates
+//
+// List the library of parameterized policy templates for common authorization patterns
+func (c *Client) ListPolicyTemplates(ctx context.Context) ([]PolicyTemplate, error) {
+	var result []PolicyTemplate
+	if err := c.do(ctx, "GET", "/v1/policy-templates", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListResources calls GET /v1/resources: list resources
+//
+// List the resources registered in the caller's tenant
+func (c *Client) ListResources(ctx context.Context, params *ListResourcesParams) (*ListResourcesResult, error) {
+	var result ListResourcesResult
+	if err := c.do(ctx, "GET", "/v1/resources", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetResource calls GET /v1/resources/{resourceType}/{resourceId}: get resource
+//
+// Get a resource of the caller's tenant. Resources of Heimdall's own types (users, roles, policies, bundles and tenants) are derived from the database and marked derived; only registered resources have an ETag.
+func (c *Client) GetResource(ctx context.Context, resourceType string, resourceId string) (*Resource, error) {
+	var result Resource
+	if err := c.do(ctx, "GET", "/v1/resources/"+url.PathEscape(resourceType)+"/"+url.PathEscape(resourceId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertResource calls PUT /v1/resources/{resourceType}/{resourceId}: upsert resource
+//
+// Register a resource of the caller's tenant or replace its owner, labels and attributes. Authorization inputs for the resource then carry its owner as input.resource.ownerId, and its labels and attributes in input.resource.attributes. Heimdall's own types cannot be registered. Send If-Match with a previously returned ETag to update only an unchanged resource, or If-None-Match: * to only create it.
+func (c *Client) UpsertResource(ctx context.Context, resourceType string, resourceId string, req *UpsertResourceRequest) (*Resource, error) {
+	var result Resource
+	if err := c.do(ctx, "PUT", "/v1/resources/"+url.PathEscape(resourceType)+"/"+url.PathEscape(resourceId), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteResource calls DELETE /v1/resources/{resourceType}/{resourceId}: delete resource
+//
+// Remove a registered resource. Policies no longer receive its owner and labels.
+func (c *Client) DeleteResource(ctx context.Context, resourceType string, resourceId string) error {
+	return c.do(ctx, "DELETE", "/v1/resources/"+url.PathEscape(resourceType)+"/"+url.PathEscape(resourceId), nil, nil, nil)
+}
+
+// GetRole calls GET /v1/roles/{name}: get role
+//
+// Get a role of the current tenant by name, with its permissions
+func (c *Client) GetRole(ctx context.Context, name string) (*RoleResponse, error) {
+	var result RoleResponse
+	if err := c.do(ctx, "GET", "/v1/roles/"+url.PathEscape(name), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertRole calls PUT /v1/roles/{name}: upsert role
+//
+// Create or replace a role of the current tenant by name; its permissions are replaced by the listed ones. Send If-Match with a previously returned ETag to update only an unchanged role, or If-None-Match: * to only create it.
+func (c *Client) UpsertRole(ctx context.Context, name string, req *UpsertRoleRequest) (*RoleResponse, error) {
+	var result RoleResponse
+	if err := c.do(ctx, "PUT", "/v1/roles/"+url.PathEscape(name), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteRole calls DELETE /v1/roles/{name}: delete role
+//
+// Delete a role of the current tenant and remove it from all users
+func (c *Client) DeleteRole(ctx context.Context, name string) error {
+	return c.do(ctx, "DELETE", "/v1/roles/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListPlatformSigningKeys calls GET /v1/signing-keys: list platform signing keys
+//
+// List the keys that sign and verify tokens not bound to a tenant's own keys, newest first. The active key signs new tokens; retired keys still verify the tokens they signed until they expire once the longest token lifetime has passed since their retirement. Requires signing_keys.read.
+func (c *Client) ListPlatformSigningKeys(ctx context.Context) ([]PlatformSigningKey, error) {
+	var result []PlatformSigningKey
+	if err := c.do(ctx, "GET", "/v1/signing-keys", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RotatePlatformSigningKey calls POST /v1/signing-keys/rotate: rotate the platform signing key
+//
+// Generate a new platform signing key and make it the active key. The previous key, including the key read from JWT_PRIVATE_KEY_PATH on the first rotation, is retired: it keeps verifying the tokens it signed until they expire, so active sessions are not invalidated. Other instances pick up the new key within JWT_KEY_REFRESH_SECONDS. Requires signing_keys.rotate.
+func (c *Client) RotatePlatformSigningKey(ctx context.Context) (*PlatformSigningKey, error) {
+	var result PlatformSigningKey
+	if err := c.do(ctx, "POST", "/v1/signing-keys/rotate", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReportOPAStatus calls POST /v1/status: report OPA status
+//
+// Status API for OPA instances, e.g. sidecars running Heimdall's bundles. Accepts a status update as OPA's status plugin sends it and records the instance, identified by labels.id, with its loaded bundle revisions and plugin states. Requires opa_instances.report.
+func (c *Client) ReportOPAStatus(ctx context.Context, req *OPAStatusReport) error {
+	return c.do(ctx, "POST", "/v1/status", nil, req, nil)
+}
+
+// ListTenants calls GET /v1/tenants: list tenants
+//
+// Get all tenants (admin only)
+func (c *Client) ListTenants(ctx context.Context, params *ListTenantsParams) (*ListTenantsResult, error) {
+	var result ListTenantsResult
+	if err := c.do(ctx, "GET", "/v1/tenants", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateTenant calls POST /v1/tenants: create tenant
+//
+// Create a new tenant (admin only)
+func (c *Client) CreateTenant(ctx context.Context, req *CreateTenantRequest) (*TenantResponse, error) {
+	var result TenantResponse
+	if err := c.do(ctx, "POST", "/v1/tenants", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTenantBySlug calls GET /v1/tenants/slug/{slug}: get tenant by slug
+//
+// Get tenant details by slug
+func (c *Client) GetTenantBySlug(ctx context.Context, slug string) (*TenantResponse, error) {
+	var result TenantResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/slug/"+url.PathEscape(slug), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertTenant calls PUT /v1/tenants/slug/{slug}: upsert tenant by slug
+//
+// Create or replace the tenant with the given slug. Send If-Match with a previously returned ETag to update only an unchanged tenant, or If-None-Match: * to only create it.
+func (c *Client) UpsertTenant(ctx context.Context, slug string, req *UpsertTenantRequest) (*TenantResponse, error) {
+	var result TenantResponse
+	if err := c.do(ctx, "PUT", "/v1/tenants/slug/"+url.PathEscape(slug), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPublicTenant calls GET /v1/tenants/slug/{slug}/public: get public tenant information
+//
+// Get the name, branding and login methods of an active tenant without authentication, so hosted login pages can render the tenant's login. Login methods are those the tenant's loginMethods setting allows, all by default, that are available: saml requires an enabled SAML configuration. Responses may be cached for five minutes and revalidated with If-None-Match.
+func (c *Client) GetPublicTenant(ctx context.Context, slug string) (*PublicTenant, error) {
+	var result PublicTenant
+	if err := c.do(ctx, "GET", "/v1/tenants/slug/"+url.PathEscape(slug)+"/public", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTenantByID calls GET /v1/tenants/{tenantId}: get tenant by ID
+//
+// Get specific tenant details
+func (c *Client) GetTenantByID(ctx context.Context, tenantId string) (*TenantResponse, error) {
+	var result TenantResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateTenant calls PATCH /v1/tenants/{tenantId}: update tenant
+//
+// Update tenant details (admin only)
+func (c *Client) UpdateTenant(ctx context.Context, tenantId string, req *UpdateTenantRequest) (*TenantResponse, error) {
+	var result TenantResponse
+	if err := c.do(ctx, "PATCH", "/v1/tenants/"+url.PathEscape(tenantId), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTenant calls DELETE /v1/tenants/{tenantId}: delete tenant
+//
+// Delete a tenant. Must be confirmed with a tenants.delete action nonce (admin only)
+func (c *Client) DeleteTenant(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId), nil, nil, nil)
+}
+
+// ActivateTenant calls POST /v1/tenants/{tenantId}/activate: activate tenant
+//
+// Activate a suspended tenant. Requires a platform-scoped token.
+func (c *Client) ActivateTenant(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "POST", "/v1/tenants/"+url.PathEscape(tenantId)+"/activate", nil, nil, nil)
+}
+
+// GetTenantClaimsTemplate calls GET /v1/tenants/{tenantId}/claims-template: get tenant claims template
+//
+// Get the template controlling which roles, permissions, tenant metadata and custom claims are embedded in the tenant's access tokens
+func (c *Client) GetTenantClaimsTemplate(ctx context.Context, tenantId string) (*ClaimsTemplateResponse, error) {
+	var result ClaimsTemplateResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/claims-template", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertTenantClaimsTemplate calls PUT /v1/tenants/{tenantId}/claims-template: upsert tenant claims template
+//
+// Create or replace a tenant's claims template. It applies to access tokens issued at the next login or refresh. Tokens larger than maxTokenSize drop their permissions and set permissionsOmitted, leaving permission checks to the server, then drop tenant metadata and custom claims. Send If-Match with a previously returned ETag to update only an unchanged template, or If-None-Match: * to only create it.
+func (c *Client) UpsertTenantClaimsTemplate(ctx context.Context, tenantId string, req *UpsertClaimsTemplateRequest) (*ClaimsTemplateResponse, error) {
+	var result ClaimsTemplateResponse
+	if err := c.do(ctx, "PUT", "/v1/tenants/"+url.PathEscape(tenantId)+"/claims-template", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTenantClaimsTemplate calls DELETE /v1/tenants/{tenantId}/claims-template: delete tenant claims template
+//
+// Delete a tenant's claims template, restoring the default access token claims
+func (c *Client) DeleteTenantClaimsTemplate(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/claims-template", nil, nil, nil)
+}
+
+// GetTenantDefaultRoles calls GET /v1/tenants/{tenantId}/default-roles: get tenant default roles
+//
+// Get the roles given to users when they register with a tenant
+func (c *Client) GetTenantDefaultRoles(ctx context.Context, tenantId string) (*DefaultRolesResponse, error) {
+	var result DefaultRolesResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/default-roles", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateTenantDefaultRoles calls PATCH /v1/tenants/{tenantId}/default-roles: update tenant default roles
+//
+// Add roles of the tenant to and remove roles from the roles given to users when they register, stored in the tenant's defaultRoles setting. A tenant has at most 20 default roles. Users registered before keep their roles.
+func (c *Client) UpdateTenantDefaultRoles(ctx context.Context, tenantId string, req *UpdateDefaultRolesRequest) (*DefaultRolesResponse, error) {
+	var result DefaultRolesResponse
+	if err := c.do(ctx, "PATCH", "/v1/tenants/"+url.PathEscape(tenantId)+"/default-roles", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTenantIPAccess calls GET /v1/tenants/{tenantId}/ip-access: get tenant IP access lists
+//
+// Get the address ranges a tenant admits and rejects API requests and logins from
+func (c *Client) GetTenantIPAccess(ctx context.Context, tenantId string) (*IPAccessResponse, error) {
+	var result IPAccessResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/ip-access", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertTenantIPAccess calls PUT /v1/tenants/{tenantId}/ip-access: upsert tenant IP access lists
+//
+// Create or replace a tenant's IP access lists of CIDR ranges or single addresses, stored in the tenant's ipAccess setting. Requests carrying the tenant's tokens or X-Tenant-ID header, and logins of its users, from a denylisted address or from outside a non-empty allowlist fail with 403 IP_NOT_ALLOWED before authentication. The lists are also passed to policies as input.tenant.settings.ipAccess. Lists that would reject the address of this request fail with IP_ACCESS_LOCKOUT. Send If-Match with a previously returned ETag to update only unchanged lists, or If-None-Match: * to only create them.
+func (c *Client) UpsertTenantIPAccess(ctx context.Context, tenantId string, req *UpsertIPAccessRequest) (*IPAccessResponse, error) {
+	var result IPAccessResponse
+	if err := c.do(ctx, "PUT", "/v1/tenants/"+url.PathEscape(tenantId)+"/ip-access", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTenantIPAccess calls DELETE /v1/tenants/{tenantId}/ip-access: delete tenant IP access lists
+//
+// Delete a tenant's IP access lists, admitting requests from any address
+func (c *Client) DeleteTenantIPAccess(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/ip-access", nil, nil, nil)
+}
+
+// ListTenantOAuthClients calls GET /v1/tenants/{tenantId}/oauth-clients: list OAuth clients
+//
+// List the OAuth clients of a tenant. Client secrets are never returned.
+func (c *Client) ListTenantOAuthClients(ctx context.Context, tenantId string) ([]OAuthClientResponse, error) {
+	var result []OAuthClientResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/oauth-clients", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateTenantOAuthClient calls POST /v1/tenants/{tenantId}/oauth-clients: create OAuth client
+//
+// Register an OAuth client that obtains access tokens for the given permission scopes with the client credentials grant at /oauth/token, e.g. for backend jobs. The client secret is only returned in this response. Client tokens cannot manage OAuth clients.
+func (c *Client) CreateTenantOAuthClient(ctx context.Context, tenantId string, req *CreateOAuthClientRequest) (*OAuthClientResponse, error) {
+	var result OAuthClientResponse
+	if err := c.do(ctx, "POST", "/v1/tenants/"+url.PathEscape(tenantId)+"/oauth-clients", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTenantOAuthClient calls GET /v1/tenants/{tenantId}/oauth-clients/{clientId}: get OAuth client
+//
+// Get an OAuth client of a tenant
+func (c *Client) GetTenantOAuthClient(ctx context.Context, tenantId string, clientId string) (*OAuthClientResponse, error) {
+	var result OAuthClientResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/oauth-clients/"+url.PathEscape(clientId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateTenantOAuthClient calls PATCH /v1/tenants/{tenantId}/oauth-clients/{clientId}: update OAuth client
+//
+// Change the name or scopes of an OAuth client. Tokens already issued keep their scope until they expire.
+func (c *Client) UpdateTenantOAuthClient(ctx context.Context, tenantId string, clientId string, req *UpdateOAuthClientRequest) (*OAuthClientResponse, error) {
+	var result OAuthClientResponse
+	if err := c.do(ctx, "PATCH", "/v1/tenants/"+url.PathEscape(tenantId)+"/oauth-clients/"+url.PathEscape(clientId), nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTenantOAuthClient calls DELETE /v1/tenants/{tenantId}/oauth-clients/{clientId}: delete OAuth client
+//
+// Delete an OAuth client. Tokens already issued to it stay valid until they expire.
+func (c *Client) DeleteTenantOAuthClient(ctx context.Context, tenantId string, clientId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/oauth-clients/"+url.PathEscape(clientId), nil, nil, nil)
+}
+
+// RotateTenantOAuthClientSecret calls POST /v1/tenants/{tenantId}/oauth-clients/{clientId}/rotate-secret: rotate OAuth client secret
+//
+// Issue a new secret for an OAuth client, returned only in this response. The previous secret stays valid for the optional grace period so deployments can switch over.
+func (c *Client) RotateTenantOAuthClientSecret(ctx context.Context, tenantId string, clientId string, req *RotateOAuthClientSecretRequest) (*OAuthClientResponse, error) {
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	var result OAuthClientResponse
+	if err := c.do(ctx, "POST", "/v1/tenants/"+url.PathEscape(tenantId)+"/oauth-clients/"+url.PathEscape(clientId)+"/rotate-secret", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPasswordPolicy calls GET /v1/tenants/{tenantId}/password-policy: get password policy
+//
+// Get the effective password policy of a tenant, so sign-up and password forms can check new passwords before submitting them
+func (c *Client) GetPasswordPolicy(ctx context.Context, tenantId string) (*PasswordPolicy, error) {
+	var result PasswordPolicy
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/password-policy", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTenantRateLimits calls GET /v1/tenants/{tenantId}/rate-limits: get tenant rate limits
+//
+// Get a tenant's quotas of requests per minute by route class, along with the per-IP limits of the route classes
+func (c *Client) GetTenantRateLimits(ctx context.Context, tenantId string) (*RateLimitsResponse, error) {
+	var result RateLimitsResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/rate-limits", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertTenantRateLimits calls PUT /v1/tenants/{tenantId}/rate-limits: upsert tenant rate limits
+//
+// Create or replace a tenant's quotas. A quota counts the requests of all of the tenant's users and clients to a route class (auth, authz or default) per minute, on top of the per-IP limits. Requests over the quota fail with 429 TENANT_RATE_LIMIT_EXCEEDED and a Retry-After header. Requires the rate_limits update permission. Send If-Match with a previously returned ETag to update only unchanged quotas, or If-None-Match: * to only create them.
+func (c *Client) UpsertTenantRateLimits(ctx context.Context, tenantId string, req *UpsertRateLimitsRequest) (*RateLimitsResponse, error) {
+	var result RateLimitsResponse
+	if err := c.do(ctx, "PUT", "/v1/tenants/"+url.PathEscape(tenantId)+"/rate-limits", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTenantRateLimits calls DELETE /v1/tenants/{tenantId}/rate-limits: delete tenant rate limits
+//
+// Delete a tenant's quotas, leaving only the per-IP rate limits
+func (c *Client) DeleteTenantRateLimits(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/rate-limits", nil, nil, nil)
+}
+
+// GetTenantSAMLConfig calls GET /v1/tenants/{tenantId}/saml: get tenant SAML configuration
+//
+// Get a tenant's SAML identity provider settings and the service provider URLs to register with the identity provider
+func (c *Client) GetTenantSAMLConfig(ctx context.Context, tenantId string) (*SAMLConfigResponse, error) {
+	var result SAMLConfigResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/saml", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertTenantSAMLConfig calls PUT /v1/tenants/{tenantId}/saml: upsert tenant SAML configuration
+//
+// Create or replace a tenant's SAML configuration from its identity provider metadata. Users signing in with SAML are provisioned by email and receive the roles mapped from the role attribute. Send If-Match with a previously returned ETag to update only an unchanged configuration, or If-None-Match: * to only create it.
+func (c *Client) UpsertTenantSAMLConfig(ctx context.Context, tenantId string, req *UpsertSAMLConfigRequest) (*SAMLConfigResponse, error) {
+	var result SAMLConfigResponse
+	if err := c.do(ctx, "PUT", "/v1/tenants/"+url.PathEscape(tenantId)+"/saml", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTenantSAMLConfig calls DELETE /v1/tenants/{tenantId}/saml: delete tenant SAML configuration
+//
+// Delete a tenant's SAML configuration, disabling SAML logins for the tenant
+func (c *Client) DeleteTenantSAMLConfig(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/saml", nil, nil, nil)
+}
+
+// ListTenantSigningKeys calls GET /v1/tenants/{tenantId}/signing-keys: list tenant signing keys
+//
+// List the keys signing a tenant's tokens
+func (c *Client) ListTenantSigningKeys(ctx context.Context, tenantId string) ([]TenantSigningKey, error) {
+	var result []TenantSigningKey
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/signing-keys", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateTenantSigningKey calls POST /v1/tenants/{tenantId}/signing-keys: create tenant signing key
+//
+// Issue a new active signing key for a tenant, retiring the current one
+func (c *Client) CreateTenantSigningKey(ctx context.Context, tenantId string) (*TenantSigningKey, error) {
+	var result TenantSigningKey
+	if err := c.do(ctx, "POST", "/v1/tenants/"+url.PathEscape(tenantId)+"/signing-keys", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MigrateTenantSigningKeys calls POST /v1/tenants/{tenantId}/signing-keys/migrate: migrate to tenant signing keys
+//
+// Move a tenant from the shared platform key to signing keys of its own by issuing its first key
+func (c *Client) MigrateTenantSigningKeys(ctx context.Context, tenantId string) (*TenantSigningKey, error) {
+	var result TenantSigningKey
+	if err := c.do(ctx, "POST", "/v1/tenants/"+url.PathEscape(tenantId)+"/signing-keys/migrate", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeTenantSigningKey calls DELETE /v1/tenants/{tenantId}/signing-keys/{kid}: revoke tenant signing key
+//
+// Revoke a tenant signing key. Tokens it signed are rejected as soon as each instance's key cache refreshes.
+func (c *Client) RevokeTenantSigningKey(ctx context.Context, tenantId string, kid string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/signing-keys/"+url.PathEscape(kid), nil, nil, nil)
+}
+
+// GetTenantStats calls GET /v1/tenants/{tenantId}/stats: get tenant statistics
+//
+// Get statistics for a tenant
+func (c *Client) GetTenantStats(ctx context.Context, tenantId string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/stats", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SuspendTenant calls POST /v1/tenants/{tenantId}/suspend: suspend tenant
+//
+// Suspend a tenant, rejecting the logins and tokens of its users until it is activated again. Requires a platform-scoped token.
+func (c *Client) SuspendTenant(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "POST", "/v1/tenants/"+url.PathEscape(tenantId)+"/suspend", nil, nil, nil)
+}
+
+// GetUserAttributeSchema calls GET /v1/tenants/{tenantId}/user-attributes: get tenant user attribute schema
+//
+// Get the typed attributes a tenant defines for its users
+func (c *Client) GetUserAttributeSchema(ctx context.Context, tenantId string) (*UserAttributeSchemaResponse, error) {
+	var result UserAttributeSchemaResponse
+	if err := c.do(ctx, "GET", "/v1/tenants/"+url.PathEscape(tenantId)+"/user-attributes", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpsertUserAttributeSchema calls PUT /v1/tenants/{tenantId}/user-attributes: upsert tenant user attribute schema
+//
+// Create or replace the typed attributes of a tenant's users, stored in the tenant's userAttributes setting. User metadata is validated against the schema, and the attributes of the authenticated user are passed to policies as input.user.metadata. Only attributes marked userEditable can be changed by users through their own profile. Send If-Match with a previously returned ETag to update only an unchanged schema, or If-None-Match: * to only create it.
+func (c *Client) UpsertUserAttributeSchema(ctx context.Context, tenantId string, req *UpsertUserAttributeSchemaRequest) (*UserAttributeSchemaResponse, error) {
+	var result UserAttributeSchemaResponse
+	if err := c.do(ctx, "PUT", "/v1/tenants/"+url.PathEscape(tenantId)+"/user-attributes", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteUserAttributeSchema calls DELETE /v1/tenants/{tenantId}/user-attributes: delete tenant user attribute schema
+//
+// Delete a tenant's user attribute schema. Existing user metadata is kept but no longer validated or passed to policies.
+func (c *Client) DeleteUserAttributeSchema(ctx context.Context, tenantId string) error {
+	return c.do(ctx, "DELETE", "/v1/tenants/"+url.PathEscape(tenantId)+"/user-attributes", nil, nil, nil)
+}
+
+// ListUsers calls GET /v1/users: list users
+//
+// List and search users in the current tenant (admin only)
+func (c *Client) ListUsers(ctx context.Context, params *ListUsersParams) (*ListUsersResult, error) {
+	var result ListUsersResult
+	if err := c.do(ctx, "GET", "/v1/users", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetCurrentUser calls GET /v1/users/me: get current user profile
+//
+// Get authenticated user's profile information
+func (c *Client) GetCurrentUser(ctx context.Context) (*UserProfile, error) {
+	var result UserProfile
+	if err := c.do(ctx, "GET", "/v1/users/me", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateCurrentUser calls PATCH /v1/users/me: update current user profile
+//
+// Update authenticated user's profile information
+func (c *Client) UpdateCurrentUser(ctx context.Context, req *UpdateProfileRequest) (*UserProfile, error) {
+	var result UserProfile
+	if err := c.do(ctx, "PATCH", "/v1/users/me", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteCurrentUser calls DELETE /v1/users/me: delete current user
+//
+// Deactivate the authenticated user's account. It is purged after the retention period unless an admin restores it.
+func (c *Client) DeleteCurrentUser(ctx context.Context) error {
+	return c.do(ctx, "DELETE", "/v1/users/me", nil, nil, nil)
+}
+
+// ListMyAccessRequests calls GET /v1/users/me/access-requests: list my access requests
+//
+// List the caller's own access requests
+func (c *Client) ListMyAccessRequests(ctx context.Context, params *ListMyAccessRequestsParams) (*ListMyAccessRequestsResult, error) {
+	var result ListMyAccessRequestsResult
+	if err := c.do(ctx, "GET", "/v1/users/me/access-requests", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMyLoginHistory calls GET /v1/users/me/login-history: get my login history
+//
+// List the authenticated user's recent logins, flagging suspicious ones
+func (c *Client) GetMyLoginHistory(ctx context.Context, params *GetMyLoginHistoryParams) (*GetMyLoginHistoryResult, error) {
+	var result GetMyLoginHistoryResult
+	if err := c.do(ctx, "GET", "/v1/users/me/login-history", params.values(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMyPermissions calls GET /v1/users/me/permissions: get my permissions
+//
+// Get the permissions granted to the authenticated user through their roles
+func (c *Client) GetMyPermissions(ctx context.Context) (*GetMyPermissionsResult, error) {
+	var result GetMyPermissionsResult
+	if err := c.do(ctx, "GET", "/v1/users/me/permissions", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetUserByID calls GET /v1/users/{userId}: get user by ID
+//
+// Get specific user's profile (admin only)
+func (c *Client) GetUserByID(ctx context.Context, userId string) (*UserProfile, error) {
+	var result UserProfile
+	if err := c.do(ctx, "GET", "/v1/users/"+url.PathEscape(userId), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateUserAttributes calls PATCH /v1/users/{userId}/attributes: update user attributes
+//
+// Set attributes of a user defined by the user attribute schema of the user's tenant. Attributes are stored in the user's metadata and passed to policies as input.user.metadata. A null value removes an attribute. Unknown attributes, values that do not match their definition and missing required attributes fail with 400 INVALID_USER_ATTRIBUTES.
+func (c *Client) UpdateUserAttributes(ctx context.Context, userId string, req map[string]interface{}) (*UserProfile, error) {
+	var result UserProfile
+	if err := c.do(ctx, "PATCH", "/v1/users/"+url.PathEscape(userId)+"/attributes", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeactivateUser calls POST /v1/users/{userId}/deactivate: deactivate user
+//
+// Deactivate a user, disabling its login. Deactivated users can be restored until they are purged after the retention period. Must be confirmed with a users.deactivate action nonce (admin only)
+func (c *Client) DeactivateUser(ctx context.Context, userId string) error {
+	return c.do(ctx, "POST", "/v1/users/"+url.PathEscape(userId)+"/deactivate", nil, nil, nil)
+}
+
+// ElevateUserRole calls POST /v1/users/{userId}/elevations: grant temporary elevated access
+//
+// Grant a role to a user for a limited time, e.g. admin to an on-call engineer for 8 hours, with the reason recorded in the audit log. The assignment is removed automatically once it expires. Durations are capped by ROLE_ELEVATION_MAX_HOURS.
+func (c *Client) ElevateUserRole(ctx context.Context, userId string, req *ElevateRoleRequest) (*RoleAssignment, error) {
+	var result RoleAssignment
+	if err := c.do(ctx, "POST", "/v1/users/"+url.PathEscape(userId)+"/elevations", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RestoreUser calls POST /v1/users/{userId}/restore: restore user
+//
+// Reactivate a deactivated user that has not been purged (admin only)
+func (c *Client) RestoreUser(ctx context.Context, userId string) (*UserProfile, error) {
+	var result UserProfile
+	if err := c.do(ctx, "POST", "/v1/users/"+url.PathEscape(userId)+"/restore", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListRoleAssignments calls GET /v1/users/{userId}/roles: list role assignments
+//
+// List the roles assigned to a user with who assigned them and when temporary assignments expire. Expired assignments are listed with expired set until they are removed.
+func (c *Client) ListRoleAssignments(ctx context.Context, userId string) ([]RoleAssignment, error) {
+	var result []RoleAssignment
+	if err := c.do(ctx, "GET", "/v1/users/"+url.PathEscape(userId)+"/roles", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AssignRoleToUser calls POST /v1/users/{userId}/roles: assign role to user
+//
+// Assign a role to a user (admin only). Set expiresAt to remove the assignment automatically at that time; assigning a role the user already has replaces its expiry.
+func (c *Client) AssignRoleToUser(ctx context.Context, userId string, req *AssignRoleToUserRequest) error {
+	return c.do(ctx, "POST", "/v1/users/"+url.PathEscape(userId)+"/roles", nil, req, nil)
+}
+
+// RemoveRoleFromUser calls DELETE /v1/users/{userId}/roles/{roleId}: remove role from user
+//
+// Remove a role from a user (admin only)
+func (c *Client) RemoveRoleFromUser(ctx context.Context, userId string, roleId string) error {
+	return c.do(ctx, "DELETE", "/v1/users/"+url.PathEscape(userId)+"/roles/"+url.PathEscape(roleId), nil, nil, nil)
+}
+
+// UpdateUserStatus calls PATCH /v1/users/{userId}/status: update user status
+//
+// Suspend or reactivate a user (admin only). Suspended users cannot sign in or refresh tokens, their sessions are revoked and authorization policies deny their requests.
+func (c *Client) UpdateUserStatus(ctx context.Context, userId string, req *UpdateUserStatusRequest) (*UserProfile, error) {
+	var result UserProfile
+	if err := c.do(ctx, "PATCH", "/v1/users/"+url.PathEscape(userId)+"/status", nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UnlockUser calls POST /v1/users/{userId}/unlock: unlock user
+//
+// Clear a user's lockout after too many failed logins (admin only)
+func (c *Client) UnlockUser(ctx context.Context, userId string) error {
+	return c.do(ctx, "POST", "/v1/users/"+url.PathEscape(userId)+"/unlock", nil, nil, nil)
+}
@@ -0,0 +1,193 @@
+// Package client is a typed Go client for the Heimdall API.
+//
+// The request, response and operation methods in api_gen.go are generated from
+// the server's OpenAPI specification by cmd/genclient; regenerate them with
+// "go run ./cmd/genclient" after changing the API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client calls the Heimdall API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	tenantID   string
+
+	mu          sync.RWMutex
+	accessToken string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAccessToken sets the bearer token sent with every request
+func WithAccessToken(token string) Option {
+	return func(c *Client) {
+		c.accessToken = token
+	}
+}
+
+// WithTenantID sets the tenant sent in the X-Tenant-ID header
+func WithTenantID(tenantID string) Option {
+	return func(c *Client) {
+		c.tenantID = tenantID
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "https://api.heimdall.example.com"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetAccessToken replaces the bearer token, e.g. after logging in or refreshing tokens
+func (c *Client) SetAccessToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = token
+}
+
+// requestHeadersKey and responseHeadersKey are the context keys used by WithHeader and WithResponseHeaders
+type (
+	requestHeadersKey  struct{}
+	responseHeadersKey struct{}
+)
+
+// WithHeader returns a context whose requests carry an additional header, e.g. If-Match
+// to make an upsert conditional on a previously returned ETag:
+//
+//	ctx = client.WithHeader(ctx, "If-Match", etag)
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	headers := http.Header{}
+	if existing, ok := ctx.Value(requestHeadersKey{}).(http.Header); ok {
+		headers = existing.Clone()
+	}
+	headers.Set(key, value)
+	return context.WithValue(ctx, requestHeadersKey{}, headers)
+}
+
+// WithResponseHeaders returns a context whose responses' headers are copied into
+// headers, e.g. to read the ETag of a resource:
+//
+//	headers := http.Header{}
+//	role, err := c.GetRole(client.WithResponseHeaders(ctx, headers), "billing_admin")
+//	etag := headers.Get("ETag")
+func WithResponseHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, responseHeadersKey{}, headers)
+}
+
+// Error is an error response returned by the API
+type Error struct {
+	StatusCode int                    `json:"-"`
+	Code       string                 `json:"code"`
+	Message    string                 `json:"message"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("heimdall: %s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// envelope is the wrapper around every API response
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *Error          `json:"error"`
+}
+
+// do sends a request and decodes the response's data field into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.mu.RLock()
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+	c.mu.RUnlock()
+	if c.tenantID != "" {
+		req.Header.Set("X-Tenant-ID", c.tenantID)
+	}
+	if headers, ok := ctx.Value(requestHeadersKey{}).(http.Header); ok {
+		for key, values := range headers {
+			req.Header[key] = values
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if headers, ok := ctx.Value(responseHeadersKey{}).(http.Header); ok {
+		for key, values := range resp.Header {
+			headers[key] = values
+		}
+	}
+
+	var result envelope
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := result.Error
+		if apiErr == nil {
+			apiErr = &Error{Code: "REQUEST_FAILED", Message: http.StatusText(resp.StatusCode)}
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+
+	if out != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return nil
+}
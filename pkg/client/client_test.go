@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success":false,"error":{"code":"UNAUTHORIZED","message":"Missing token"}}`))
+			return
+		}
+		if r.URL.Path != "/v1/policies" || r.URL.Query().Get("sort") != "-name" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"policies":[{"id":"p1","name":"allow-admins"}],"pagination":{"pageSize":20,"total":1,"totalPages":1,"sort":"-name","hasMore":false}}}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	_, err := c.ListPolicies(context.Background(), nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "UNAUTHORIZED" {
+		t.Errorf("Expected 401 UNAUTHORIZED, got %d %s", apiErr.StatusCode, apiErr.Code)
+	}
+
+	c.SetAccessToken("token")
+	result, err := c.ListPolicies(context.Background(), &ListPoliciesParams{Sort: "-name"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Policies) != 1 || result.Policies[0].Name != "allow-admins" {
+		t.Errorf("Expected one policy named allow-admins, got %+v", result.Policies)
+	}
+	if result.Pagination == nil || result.Pagination.Total != 1 {
+		t.Errorf("Expected pagination total 1, got %+v", result.Pagination)
+	}
+}
+
+func TestClient_ConditionalHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("If-Match") != `"2"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`{"success":false,"error":{"code":"ETAG_MISMATCH","message":"Resource was modified since it was read"}}`))
+			return
+		}
+		w.Header().Set("ETag", `"3"`)
+		w.Write([]byte(`{"success":true,"data":{"name":"billing_admin","permissions":["invoices.read"]}}`))
+	}))
+	defer server.Close()
+
+	c := New(server.URL)
+	req := &UpsertRoleRequest{Permissions: []string{"invoices.read"}}
+
+	_, err := c.UpsertRole(WithHeader(context.Background(), "If-Match", `"1"`), "billing_admin", req)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("Expected 412 error, got %v", err)
+	}
+
+	headers := http.Header{}
+	ctx := WithResponseHeaders(WithHeader(context.Background(), "If-Match", `"2"`), headers)
+	role, err := c.UpsertRole(ctx, "billing_admin", req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if role.Name != "billing_admin" {
+		t.Errorf("Expected role billing_admin, got %+v", role)
+	}
+	if etag := headers.Get("ETag"); etag != `"3"` {
+		t.Errorf("Expected ETag \"3\", got %q", etag)
+	}
+}
@@ -0,0 +1,398 @@
+// Package heimdalltest runs Heimdall with its Postgres, Redis and OPA
+// dependencies in Docker containers, so downstream services can write
+// integration tests against a real server instead of mocks:
+//
+//	func TestReports(t *testing.T) {
+//		h := heimdalltest.Run(t, heimdalltest.WithFixture("testdata/heimdall.yaml"))
+//		svc := newReportService(h.URL)
+//		...
+//	}
+//
+// The server uses the native identity provider and local bundle storage, so
+// neither FusionAuth nor MinIO is needed. Containers are started with
+// testcontainers-go and need a Docker daemon.
+package heimdalltest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techsavvyash/heimdall/pkg/client"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Default images of the dependencies
+const (
+	DefaultPostgresImage = "postgres:14-alpine"
+	DefaultRedisImage    = "redis:7-alpine"
+	DefaultOPAImage      = "openpolicyagent/opa:latest"
+)
+
+// Default admin created in every instance
+const (
+	DefaultAdminEmail = "admin@heimdall.test"
+	DefaultTenantSlug = "default"
+)
+
+// oneTimePassword matches the password printed by migrate bootstrap
+var oneTimePassword = regexp.MustCompile(`One-time password: (\S+)`)
+
+// Instance is a running Heimdall server
+type Instance struct {
+	// URL is the base URL of the API, e.g. "http://localhost:32768"
+	URL string
+	// Client is signed in as the admin, a super admin of the admin tenant
+	Client *client.Client
+
+	AdminEmail    string
+	AdminPassword string
+	AdminID       string
+	TenantID      string
+
+	server     testcontainers.Container
+	containers []testcontainers.Container
+	network    *testcontainers.DockerNetwork
+}
+
+type options struct {
+	image         string
+	postgresImage string
+	redisImage    string
+	opaImage      string
+	adminEmail    string
+	tenantSlug    string
+	fixture       string
+	env           map[string]string
+	startTimeout  time.Duration
+	buildLog      io.Writer
+}
+
+// Option configures an Instance
+type Option func(*options)
+
+// WithImage runs a prebuilt Heimdall image instead of building one from the
+// Dockerfile of this module
+func WithImage(image string) Option {
+	return func(o *options) {
+		o.image = image
+	}
+}
+
+// WithDependencyImages overrides the Postgres, Redis and OPA images; empty
+// values keep the defaults
+func WithDependencyImages(postgres, redis, opa string) Option {
+	return func(o *options) {
+		if postgres != "" {
+			o.postgresImage = postgres
+		}
+		if redis != "" {
+			o.redisImage = redis
+		}
+		if opa != "" {
+			o.opaImage = opa
+		}
+	}
+}
+
+// WithAdmin sets the email of the admin and the slug of their tenant. A
+// tenant other than "default" must be declared by the fixture.
+func WithAdmin(email, tenantSlug string) Option {
+	return func(o *options) {
+		o.adminEmail = email
+		o.tenantSlug = tenantSlug
+	}
+}
+
+// WithFixture seeds a YAML fixture (see migrate seed --file) before the admin
+// is created. Policy files it references are read relative to it, so the
+// whole directory of the fixture is copied into the server.
+func WithFixture(path string) Option {
+	return func(o *options) {
+		o.fixture = path
+	}
+}
+
+// WithEnv sets an environment variable of the server, e.g. to enable
+// OPA_ROW_FILTERS
+func WithEnv(key, value string) Option {
+	return func(o *options) {
+		o.env[key] = value
+	}
+}
+
+// WithStartTimeout bounds how long the server may take to become ready once
+// its container started, 2 minutes by default
+func WithStartTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.startTimeout = timeout
+	}
+}
+
+// WithBuildLog writes the output of building the Heimdall image to w
+func WithBuildLog(w io.Writer) Option {
+	return func(o *options) {
+		o.buildLog = w
+	}
+}
+
+// Run starts an Instance for a test, skipping the test when Docker is not
+// available and terminating the containers when the test finishes
+func Run(t *testing.T, opts ...Option) *Instance {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	instance, err := Start(context.Background(), opts...)
+	if instance != nil {
+		t.Cleanup(func() {
+			if err := instance.Terminate(context.Background()); err != nil {
+				t.Logf("Failed to terminate Heimdall: %v", err)
+			}
+		})
+	}
+	if err != nil {
+		t.Fatalf("Failed to start Heimdall: %v", err)
+	}
+	return instance
+}
+
+// Start starts Heimdall and its dependencies, seeds the fixture and the admin
+// and signs the admin in. On error, the returned Instance, when not nil, holds
+// the containers started so far and must still be terminated.
+func Start(ctx context.Context, opts ...Option) (*Instance, error) {
+	o := &options{
+		postgresImage: DefaultPostgresImage,
+		redisImage:    DefaultRedisImage,
+		opaImage:      DefaultOPAImage,
+		adminEmail:    DefaultAdminEmail,
+		tenantSlug:    DefaultTenantSlug,
+		env:           map[string]string{},
+		startTimeout:  2 * time.Minute,
+		buildLog:      io.Discard,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	root, err := moduleRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerNetwork, err := network.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network: %w", err)
+	}
+	instance := &Instance{network: dockerNetwork, AdminEmail: o.adminEmail}
+	alias := func(name string) map[string][]string {
+		return map[string][]string{dockerNetwork.Name: {name}}
+	}
+
+	postgres := testcontainers.ContainerRequest{
+		Image:          o.postgresImage,
+		Networks:       []string{dockerNetwork.Name},
+		NetworkAliases: alias("postgres"),
+		Env: map[string]string{
+			"POSTGRES_USER":     "heimdall",
+			"POSTGRES_PASSWORD": "heimdall",
+			"POSTGRES_DB":       "heimdall",
+		},
+		// Postgres restarts once after initializing the database
+		WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+	}
+	redis := testcontainers.ContainerRequest{
+		Image:          o.redisImage,
+		Networks:       []string{dockerNetwork.Name},
+		NetworkAliases: alias("redis"),
+		WaitingFor:     wait.ForLog("Ready to accept connections"),
+	}
+	policies, err := policyFiles(filepath.Join(root, "policies"))
+	if err != nil {
+		return instance, err
+	}
+	opa := testcontainers.ContainerRequest{
+		Image:          o.opaImage,
+		Networks:       []string{dockerNetwork.Name},
+		NetworkAliases: alias("opa"),
+		ExposedPorts:   []string{"8181/tcp"},
+		Cmd:            []string{"run", "--server", "--addr=0.0.0.0:8181", "/policies"},
+		Files:          policies,
+		WaitingFor:     wait.ForHTTP("/health").WithPort("8181/tcp"),
+	}
+	for _, req := range []testcontainers.ContainerRequest{postgres, redis, opa} {
+		container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{ContainerRequest: req, Started: true})
+		if container != nil {
+			instance.containers = append(instance.containers, container)
+		}
+		if err != nil {
+			return instance, fmt.Errorf("failed to start %s: %w", req.Image, err)
+		}
+	}
+
+	env := map[string]string{
+		"ENVIRONMENT":            "development",
+		"DB_HOST":                "postgres",
+		"DB_PORT":                "5432",
+		"DB_USER":                "heimdall",
+		"DB_PASSWORD":            "heimdall",
+		"DB_NAME":                "heimdall",
+		"DB_SSLMODE":             "disable",
+		"REDIS_HOST":             "redis",
+		"REDIS_PORT":             "6379",
+		"REDIS_REQUIRED":         "true",
+		"OPA_URL":                "http://opa:8181",
+		"IDENTITY_PROVIDER":      "native",
+		"BUNDLE_STORAGE_BACKEND": "local",
+		"BUNDLE_STORAGE_DIR":     "/tmp/bundles",
+		"RATE_LIMIT_PER_MIN":     "10000",
+	}
+	for key, value := range o.env {
+		env[key] = value
+	}
+	server := testcontainers.ContainerRequest{
+		Image:        o.image,
+		Networks:     []string{dockerNetwork.Name},
+		ExposedPorts: []string{"8080/tcp"},
+		Env:          env,
+		WaitingFor:   wait.ForHTTP("/health/ready").WithPort("8080/tcp").WithStartupTimeout(o.startTimeout),
+	}
+	if o.image == "" {
+		server.FromDockerfile = testcontainers.FromDockerfile{
+			Context:        root,
+			Dockerfile:     "Dockerfile",
+			KeepImage:      true,
+			BuildLogWriter: o.buildLog,
+		}
+	}
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{ContainerRequest: server, Started: true})
+	if container != nil {
+		instance.server = container
+		instance.containers = append(instance.containers, container)
+	}
+	if err != nil {
+		return instance, fmt.Errorf("failed to start Heimdall: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "8080/tcp", "http")
+	if err != nil {
+		return instance, fmt.Errorf("failed to get the Heimdall endpoint: %w", err)
+	}
+	instance.URL = endpoint
+
+	if err := instance.seed(ctx, o); err != nil {
+		return instance, err
+	}
+	if err := instance.signIn(ctx); err != nil {
+		return instance, err
+	}
+	return instance, nil
+}
+
+// seed seeds the fixture and bootstraps the admin with the migrate tool of the
+// server image, which already applied the migrations and the default data
+func (i *Instance) seed(ctx context.Context, o *options) error {
+	if o.fixture != "" {
+		dir, err := filepath.Abs(filepath.Dir(o.fixture))
+		if err != nil {
+			return fmt.Errorf("invalid fixture path: %w", err)
+		}
+		target := "/tmp/" + filepath.Base(dir)
+		if err := i.server.CopyDirToContainer(ctx, dir, target, 0o644); err != nil {
+			return fmt.Errorf("failed to copy the fixture: %w", err)
+		}
+		if _, err := i.Exec(ctx, "/app/migrate", "seed", "--file", target+"/"+filepath.Base(o.fixture)); err != nil {
+			return fmt.Errorf("failed to seed the fixture: %w", err)
+		}
+	}
+
+	output, err := i.Exec(ctx, "/app/migrate", "bootstrap", "--email", o.adminEmail, "--tenant", o.tenantSlug)
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap the admin: %w", err)
+	}
+	match := oneTimePassword.FindStringSubmatch(output)
+	if match == nil {
+		return fmt.Errorf("failed to bootstrap the admin: no password in %q", output)
+	}
+	i.AdminPassword = match[1]
+	return nil
+}
+
+// signIn signs the admin in with the one-time password
+func (i *Instance) signIn(ctx context.Context) error {
+	c := client.New(i.URL)
+	resp, err := c.Login(ctx, &client.LoginRequest{Email: i.AdminEmail, Password: i.AdminPassword})
+	if err != nil {
+		return fmt.Errorf("failed to sign in the admin: %w", err)
+	}
+	i.AdminID = resp.User.ID
+	i.TenantID = resp.User.TenantID
+	i.Client = client.New(i.URL, client.WithAccessToken(resp.AccessToken), client.WithTenantID(i.TenantID))
+	return nil
+}
+
+// Exec runs a command in the Heimdall container, e.g. the migrate tool to
+// seed another fixture, returning its combined output
+func (i *Instance) Exec(ctx context.Context, cmd ...string) (string, error) {
+	code, reader, err := i.server.Exec(ctx, cmd, tcexec.Multiplexed())
+	if err != nil {
+		return "", err
+	}
+	var output bytes.Buffer
+	if _, err := io.Copy(&output, reader); err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return output.String(), fmt.Errorf("%s exited with code %d: %s", cmd[0], code, strings.TrimSpace(output.String()))
+	}
+	return output.String(), nil
+}
+
+// Terminate stops and removes the containers and the network
+func (i *Instance) Terminate(ctx context.Context) error {
+	var errs []error
+	for j := len(i.containers) - 1; j >= 0; j-- {
+		errs = append(errs, testcontainers.TerminateContainer(i.containers[j], testcontainers.StopContext(ctx)))
+	}
+	if i.network != nil {
+		errs = append(errs, i.network.Remove(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// moduleRoot returns the root of this module, holding the Dockerfile and the
+// bundled policies, also when it is read from the module cache
+func moduleRoot() (string, error) {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "", fmt.Errorf("failed to locate the heimdall module")
+	}
+	return filepath.Join(filepath.Dir(file), "..", ".."), nil
+}
+
+// policyFiles returns the bundled policies to copy into OPA
+func policyFiles(dir string) ([]testcontainers.ContainerFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.rego"))
+	if err != nil || len(paths) == 0 {
+		return nil, fmt.Errorf("failed to find the bundled policies in %s", dir)
+	}
+	files := make([]testcontainers.ContainerFile, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, testcontainers.ContainerFile{
+			HostFilePath:      path,
+			ContainerFilePath: "/policies/" + filepath.Base(path),
+			FileMode:          0o644,
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,43 @@
+package heimdalltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/techsavvyash/heimdall/pkg/client"
+)
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Starting Heimdall in containers is slow")
+	}
+	h := Run(t, WithFixture("testdata/fixture.yaml"), WithAdmin("owner@acme.test", "acme"))
+	ctx := context.Background()
+
+	me, err := h.Client.GetCurrentUser(ctx)
+	if err != nil {
+		t.Fatalf("GetCurrentUser returned error: %v", err)
+	}
+	if me.Email != "owner@acme.test" || me.ID != h.AdminID {
+		t.Errorf("Expected the admin to be signed in, got %+v", me)
+	}
+
+	role, err := h.Client.GetRole(ctx, "analyst")
+	if err != nil {
+		t.Fatalf("Expected the fixture's role, got %v", err)
+	}
+	if len(role.Permissions) != 1 || role.Permissions[0] != "reports.read" {
+		t.Errorf("Expected analyst to grant reports.read, got %+v", role.Permissions)
+	}
+
+	decision, err := h.Client.CheckAuthorization(ctx, &client.AuthzCheckRequest{
+		Action:   "read",
+		Resource: &client.ResourceContext{Type: "users"},
+	})
+	if err != nil {
+		t.Fatalf("CheckAuthorization returned error: %v", err)
+	}
+	if !decision.Allow {
+		t.Errorf("Expected the super admin to be allowed, got %+v", decision)
+	}
+}
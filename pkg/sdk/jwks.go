@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval limits how often an unknown key ID triggers a JWKS fetch,
+// so tokens with made-up key IDs cannot flood the Heimdall server
+const minRefreshInterval = time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type verificationKey struct {
+	publicKey *rsa.PublicKey
+	tenantID  string // Empty for the shared platform key
+}
+
+// keySet caches the public keys published by the shared and tenant JWKS endpoints
+type keySet struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]verificationKey
+	sharedKeyID string
+	fetchedAt   map[string]time.Time // Last fetch per JWKS URL
+}
+
+func newKeySet(baseURL string, httpClient *http.Client) *keySet {
+	return &keySet{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		keys:       make(map[string]verificationKey),
+		fetchedAt:  make(map[string]time.Time),
+	}
+}
+
+// key returns the public key for a token's kid header. Tokens without a kid are
+// verified with the shared key, and tenant keys only vouch for their own tenant.
+func (s *keySet) key(ctx context.Context, keyID, tenantID string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.lookup(keyID); ok {
+		return key.checkTenant(keyID, tenantID)
+	}
+
+	if err := s.refresh(ctx, s.baseURL+"/v1/.well-known/jwks.json", ""); err != nil {
+		return nil, err
+	}
+	if tenantID != "" {
+		tenantURL := s.baseURL + "/v1/tenants/" + url.PathEscape(tenantID) + "/.well-known/jwks.json"
+		if err := s.refresh(ctx, tenantURL, tenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok := s.lookup(keyID)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", keyID)
+	}
+	return key.checkTenant(keyID, tenantID)
+}
+
+func (s *keySet) lookup(keyID string) (verificationKey, bool) {
+	if keyID == "" {
+		keyID = s.sharedKeyID
+	}
+	key, ok := s.keys[keyID]
+	return key, ok && keyID != ""
+}
+
+func (k verificationKey) checkTenant(keyID, tenantID string) (*rsa.PublicKey, error) {
+	if k.tenantID != "" && k.tenantID != tenantID {
+		return nil, fmt.Errorf("signing key %s does not belong to tenant %s", keyID, tenantID)
+	}
+	return k.publicKey, nil
+}
+
+// refresh fetches a JWKS unless it was fetched recently. The caller holds s.mu.
+func (s *keySet) refresh(ctx context.Context, jwksURL, tenantID string) error {
+	if time.Since(s.fetchedAt[jwksURL]) < minRefreshInterval {
+		return nil
+	}
+	s.fetchedAt[jwksURL] = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Tenants without their own keys are not an error, their tokens use the shared key
+		if tenantID != "" && resp.StatusCode < http.StatusInternalServerError {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch signing keys: %s returned %d", jwksURL, resp.StatusCode)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode signing keys: %w", err)
+	}
+
+	for _, key := range set.Keys {
+		publicKey, err := key.rsaPublicKey()
+		if err != nil {
+			continue
+		}
+		s.keys[key.Kid] = verificationKey{publicKey: publicKey, tenantID: tenantID}
+		if tenantID == "" {
+			s.sharedKeyID = key.Kid
+		}
+	}
+	return nil
+}
+
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,155 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/pkg/client"
+)
+
+// claimsLocalsKey is the Fiber locals key for the verified claims
+const claimsLocalsKey = "heimdallClaims"
+
+type claimsContextKey struct{}
+
+// Authenticate returns Fiber middleware that rejects requests without a valid
+// access token and stores its claims for ClaimsFromFiber
+func (a *Authorizer) Authenticate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := a.authenticateFiber(c); err != nil {
+			return writeFiberError(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired access token")
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermission returns Fiber middleware that authenticates the request and
+// requires a permission like "users.read"
+func (a *Authorizer) RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, err := a.authenticateFiber(c)
+		if err != nil {
+			return writeFiberError(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired access token")
+		}
+
+		allow, err := a.Check(c.UserContext(), bearerToken(c.Get(fiber.HeaderAuthorization)), claims, permission)
+		if err != nil {
+			status, code, message := checkFailure(err)
+			return writeFiberError(c, status, code, message)
+		}
+		if !allow {
+			return writeFiberError(c, fiber.StatusForbidden, "FORBIDDEN", "Insufficient permissions")
+		}
+		return c.Next()
+	}
+}
+
+// ClaimsFromFiber returns the claims stored by Authenticate or RequirePermission
+func ClaimsFromFiber(c *fiber.Ctx) (*Claims, bool) {
+	claims, ok := c.Locals(claimsLocalsKey).(*Claims)
+	return claims, ok
+}
+
+func (a *Authorizer) authenticateFiber(c *fiber.Ctx) (*Claims, error) {
+	if claims, ok := ClaimsFromFiber(c); ok {
+		return claims, nil
+	}
+
+	claims, err := a.Verify(c.UserContext(), bearerToken(c.Get(fiber.HeaderAuthorization)))
+	if err != nil {
+		return nil, err
+	}
+	c.Locals(claimsLocalsKey, claims)
+	c.SetUserContext(context.WithValue(c.UserContext(), claimsContextKey{}, claims))
+	return claims, nil
+}
+
+// checkFailure maps a failed permission check to a response. Heimdall rejects
+// revoked tokens, which pass local verification.
+func checkFailure(err error) (int, string, string) {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		return http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired access token"
+	}
+	return http.StatusServiceUnavailable, "AUTHORIZATION_UNAVAILABLE", "Failed to check permission"
+}
+
+func writeFiberError(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": message,
+			"code":    code,
+		},
+	})
+}
+
+// AuthenticateHandler wraps a net/http handler so it rejects requests without a
+// valid access token. The claims are available through ClaimsFromContext.
+func (a *Authorizer) AuthenticateHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, _, err := a.authenticateHTTP(r)
+		if err != nil {
+			writeHTTPError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired access token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermissionHandler wraps a net/http handler so it authenticates the
+// request and requires a permission like "users.read"
+func (a *Authorizer) RequirePermissionHandler(permission string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, claims, err := a.authenticateHTTP(r)
+		if err != nil {
+			writeHTTPError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired access token")
+			return
+		}
+
+		allow, err := a.Check(r.Context(), bearerToken(r.Header.Get("Authorization")), claims, permission)
+		if err != nil {
+			status, code, message := checkFailure(err)
+			writeHTTPError(w, status, code, message)
+			return
+		}
+		if !allow {
+			writeHTTPError(w, http.StatusForbidden, "FORBIDDEN", "Insufficient permissions")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClaimsFromContext returns the claims of the authenticated request
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+func (a *Authorizer) authenticateHTTP(r *http.Request) (*http.Request, *Claims, error) {
+	if claims, ok := ClaimsFromContext(r.Context()); ok {
+		return r, claims, nil
+	}
+
+	claims, err := a.Verify(r.Context(), bearerToken(r.Header.Get("Authorization")))
+	if err != nil {
+		return r, nil, err
+	}
+	return r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims)), claims, nil
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error": map[string]string{
+			"message": message,
+			"code":    code,
+		},
+	})
+}
@@ -0,0 +1,291 @@
+// Package sdk lets downstream Go services authenticate and authorize requests
+// with Heimdall.
+//
+// Access tokens are verified locally against the server's JWKS, and permission
+// checks are delegated to POST /v1/authz/check on behalf of the caller, with
+// decisions cached briefly in memory. With Fiber:
+//
+//	authz := sdk.New("https://heimdall.example.com")
+//	app.Get("/users", authz.RequirePermission("users.read"), listUsers)
+//
+// With net/http:
+//
+//	mux.Handle("/users", authz.RequirePermissionHandler("users.read", listUsers))
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/techsavvyash/heimdall/pkg/client"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultAudience is the audience of Heimdall's own API, the JWT_AUDIENCE
+// setting of the server. Tokens naming an audience must include it unless the
+// authorizer is created with another audience.
+const DefaultAudience = "heimdall"
+
+// Errors returned by Authorizer methods
+var (
+	ErrMissingToken = errors.New("heimdall: access token is required")
+	ErrInvalidToken = errors.New("heimdall: invalid or expired access token")
+)
+
+// Claims are the claims of a Heimdall access token
+type Claims struct {
+	UserID     string                 `json:"userId"`
+	TenantID   string                 `json:"tenantId"`
+	Email      string                 `json:"email"`
+	Roles      []string               `json:"roles,omitempty"`
+	Type       string                 `json:"type"`
+	Attributes map[string]interface{} `json:"attrs,omitempty"`
+
+	// Set on tokens obtained by token exchange: the space-separated permissions
+	// the token is limited to and the token it was exchanged from
+	Scope string `json:"scope,omitempty"`
+	Actor *Actor `json:"act,omitempty"`
+
+	// Set on tokens issued to OAuth clients, which have no user and carry a scope
+	ClientID string `json:"clientId,omitempty"`
+
+	jwt.RegisteredClaims
+}
+
+// Actor is a link in the delegation chain of an exchanged token
+type Actor struct {
+	Subject string `json:"sub"`
+	TokenID string `json:"jti,omitempty"`
+	Actor   *Actor `json:"act,omitempty"`
+}
+
+// AllowsScope reports whether the token may be used for a permission, i.e. the
+// token has no scope or the permission is in it
+func (c *Claims) AllowsScope(permission string) bool {
+	scopes := strings.Fields(c.Scope)
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the token grants a role
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer verifies Heimdall access tokens and checks permissions
+type Authorizer struct {
+	api         *client.Client
+	keys        *keySet
+	decisionTTL time.Duration
+	audience    string
+	heimdall    string // Audience of Heimdall's own API
+
+	group     singleflight.Group
+	mu        sync.Mutex
+	decisions map[string]cachedDecision
+}
+
+type cachedDecision struct {
+	allow     bool
+	expiresAt time.Time
+}
+
+// Option configures an Authorizer
+type Option func(*Authorizer)
+
+// WithHTTPClient sets the HTTP client used to fetch keys and check permissions
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(a *Authorizer) {
+		a.api = client.New(a.keys.baseURL, client.WithHTTPClient(httpClient))
+		a.keys.httpClient = httpClient
+	}
+}
+
+// WithDecisionTTL sets how long permission decisions are cached, 0 to disable caching
+func WithDecisionTTL(ttl time.Duration) Option {
+	return func(a *Authorizer) {
+		a.decisionTTL = ttl
+	}
+}
+
+// WithAudience names the service in the audience of tokens, instead of
+// DefaultAudience. Tokens are then only accepted if their audience includes this
+// name, i.e. the tenant registered it in its claims template or the token was
+// exchanged for it.
+func WithAudience(audience string) Option {
+	return func(a *Authorizer) {
+		a.audience = audience
+	}
+}
+
+// WithHeimdallAudience sets the audience of Heimdall's own API, the server's
+// JWT_AUDIENCE, when it is not DefaultAudience. Heimdall only accepts tokens
+// naming it, so tokens that do not are never sent to it.
+func WithHeimdallAudience(audience string) Option {
+	return func(a *Authorizer) {
+		a.heimdall = audience
+	}
+}
+
+// New creates an authorizer for the Heimdall server at baseURL, e.g.
+// "https://heimdall.example.com"
+func New(baseURL string, opts ...Option) *Authorizer {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	a := &Authorizer{
+		api:         client.New(baseURL, client.WithHTTPClient(httpClient)),
+		keys:        newKeySet(baseURL, httpClient),
+		decisionTTL: 30 * time.Second,
+		audience:    DefaultAudience,
+		heimdall:    DefaultAudience,
+		decisions:   make(map[string]cachedDecision),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Verify verifies an access token's signature, expiry and audience and returns its
+// claims. Revoked tokens are only rejected by Heimdall itself, e.g. by Check.
+func (a *Authorizer) Verify(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		keyID, _ := t.Header["kid"].(string)
+		return a.keys.key(ctx, keyID, claims.TenantID)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithExpirationRequired())
+	if err != nil || !parsed.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Type != "access" {
+		return nil, fmt.Errorf("%w: not an access token", ErrInvalidToken)
+	}
+	// Tokens of servers predating audiences have none
+	if len(claims.Audience) > 0 && !slices.Contains(claims.Audience, a.audience) {
+		return nil, fmt.Errorf("%w: token is meant for another audience", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// Check asks Heimdall whether the token's user has a permission, named
+// "<resource>.<action>" like "users.read". Decisions are cached per token for
+// the decision TTL, and concurrent checks of the same decision share one request.
+func (a *Authorizer) Check(ctx context.Context, token string, claims *Claims, permission string) (bool, error) {
+	resource, action, ok := splitPermission(permission)
+	if !ok {
+		return false, fmt.Errorf("heimdall: invalid permission %q, expected <resource>.<action>", permission)
+	}
+
+	// Exchanged tokens are denied anything outside their scope without asking Heimdall
+	if !claims.AllowsScope(permission) {
+		return false, nil
+	}
+
+	// Heimdall rejects tokens not meant for it. Tokens exchanged for this service
+	// alone are decided by their scope, which Heimdall checked against the
+	// user's permissions when it issued them.
+	if len(claims.Audience) > 0 && !slices.Contains(claims.Audience, a.heimdall) {
+		if claims.Scope != "" {
+			return true, nil
+		}
+		return false, fmt.Errorf("%w: token is not accepted by Heimdall", ErrInvalidToken)
+	}
+
+	cacheKey := claims.ID + "|" + claims.UserID + "|" + permission
+	if allow, ok := a.cachedDecision(cacheKey); ok {
+		return allow, nil
+	}
+
+	result, err, _ := a.group.Do(cacheKey, func() (interface{}, error) {
+		decision, err := a.api.CheckAuthorization(client.WithHeader(ctx, "Authorization", "Bearer "+token), &client.AuthzCheckRequest{
+			Resource: &client.ResourceContext{Type: resource},
+			Action:   action,
+		})
+		if err != nil {
+			return false, err
+		}
+		a.storeDecision(cacheKey, decision.Allow)
+		return decision.Allow, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
+
+func (a *Authorizer) cachedDecision(key string) (bool, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	decision, ok := a.decisions[key]
+	if !ok {
+		return false, false
+	}
+	if time.Now().After(decision.expiresAt) {
+		delete(a.decisions, key)
+		return false, false
+	}
+	return decision.allow, true
+}
+
+func (a *Authorizer) storeDecision(key string, allow bool) {
+	if a.decisionTTL <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Drop expired decisions once the cache grows, so it is bounded by the
+	// number of active tokens
+	now := time.Now()
+	if len(a.decisions) >= 10000 {
+		for k, decision := range a.decisions {
+			if now.After(decision.expiresAt) {
+				delete(a.decisions, k)
+			}
+		}
+	}
+	a.decisions[key] = cachedDecision{allow: allow, expiresAt: now.Add(a.decisionTTL)}
+}
+
+// splitPermission splits "users.read" into the resource "users" and action "read"
+func splitPermission(permission string) (string, string, bool) {
+	i := strings.LastIndex(permission, ".")
+	if i <= 0 || i == len(permission)-1 {
+		return "", "", false
+	}
+	return permission[:i], permission[i+1:], true
+}
+
+// bearerToken extracts the token from an Authorization header
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
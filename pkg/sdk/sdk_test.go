@@ -0,0 +1,272 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/techsavvyash/heimdall/internal/auth"
+)
+
+// fakeHeimdall serves the shared JWKS and allows "read" checks
+type fakeHeimdall struct {
+	*httptest.Server
+	checks      atomic.Int32
+	jwksFetches atomic.Int32
+	release     chan struct{}
+}
+
+func newFakeHeimdall(t *testing.T, jwtService *auth.JWTService) *fakeHeimdall {
+	t.Helper()
+
+	f := &fakeHeimdall{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		f.jwksFetches.Add(1)
+		_ = json.NewEncoder(w).Encode(jwtService.SharedJWKS())
+	})
+	mux.HandleFunc("GET /v1/tenants/{tenantId}/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(auth.JWKS{Keys: []auth.JWK{}})
+	})
+	mux.HandleFunc("POST /v1/authz/check", func(w http.ResponseWriter, r *http.Request) {
+		f.checks.Add(1)
+		if f.release != nil {
+			<-f.release
+		}
+		if _, err := jwtService.ValidateAccessToken(bearerToken(r.Header.Get("Authorization"))); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"error":   map[string]string{"code": "UNAUTHORIZED", "message": "Invalid token"},
+			})
+			return
+		}
+		var req struct {
+			Action string `json:"action"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"allow": req.Action == "read"},
+		})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func newTestAuthorizer(t *testing.T) (*Authorizer, *fakeHeimdall, string) {
+	t.Helper()
+
+	jwtService, cleanup := auth.CreateTestJWTService(t)
+	t.Cleanup(cleanup)
+	token := auth.GenerateTestToken(t, jwtService, "user-1", "tenant-1", "alice@example.com", []string{"viewer"})
+
+	heimdall := newFakeHeimdall(t, jwtService)
+	return New(heimdall.URL), heimdall, token
+}
+
+func TestAuthorizer_Verify(t *testing.T) {
+	authz, heimdall, token := newTestAuthorizer(t)
+	ctx := context.Background()
+
+	claims, err := authz.Verify(ctx, token)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.TenantID != "tenant-1" || !claims.HasRole("viewer") {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err := authz.Verify(ctx, token); err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if _, err := authz.Verify(ctx, ""); err != ErrMissingToken {
+		t.Errorf("Expected ErrMissingToken, got %v", err)
+	}
+
+	// Tokens signed by another key are rejected without refetching the JWKS
+	otherService, cleanup := auth.CreateTestJWTService(t)
+	defer cleanup()
+	forged := auth.GenerateTestToken(t, otherService, "user-1", "tenant-1", "alice@example.com", nil)
+	if _, err := authz.Verify(ctx, forged); err == nil {
+		t.Error("Expected a token signed by another key to be rejected")
+	}
+	if fetches := heimdall.jwksFetches.Load(); fetches != 1 {
+		t.Errorf("Expected the JWKS to be fetched once, got %d", fetches)
+	}
+}
+
+func TestAuthorizer_CheckCachesDecisions(t *testing.T) {
+	authz, heimdall, token := newTestAuthorizer(t)
+	ctx := context.Background()
+
+	claims, err := authz.Verify(ctx, token)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+
+	heimdall.release = make(chan struct{})
+	var wg sync.WaitGroup
+	results := make([]bool, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = authz.Check(ctx, token, claims, "users.read")
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(heimdall.release)
+	wg.Wait()
+
+	for i, allow := range results {
+		if !allow {
+			t.Errorf("Expected check %d to be allowed", i)
+		}
+	}
+	if allow, _ := authz.Check(ctx, token, claims, "users.read"); !allow {
+		t.Error("Expected the cached decision to be allowed")
+	}
+	if checks := heimdall.checks.Load(); checks != 1 {
+		t.Errorf("Expected concurrent and repeated checks to share one request, got %d", checks)
+	}
+
+	if allow, err := authz.Check(ctx, token, claims, "users.delete"); err != nil || allow {
+		t.Errorf("Expected users.delete to be denied, got %v, %v", allow, err)
+	}
+	if _, err := authz.Check(ctx, token, claims, "users"); err == nil {
+		t.Error("Expected an error for a permission without an action")
+	}
+}
+
+// registeredAudiences is a claims template store registering audiences only
+type registeredAudiences []string
+
+func (r registeredAudiences) AccessTokenClaims(userID, tenantID string, roles []string) (*auth.TemplateClaims, error) {
+	return nil, nil
+}
+
+func (r registeredAudiences) Audiences(tenantID string) ([]string, error) {
+	return r, nil
+}
+
+func TestAuthorizer_ExchangedTokens(t *testing.T) {
+	jwtService, cleanup := auth.CreateTestJWTService(t)
+	defer cleanup()
+	jwtService.SetClaimsTemplates(registeredAudiences{"billing-service"})
+	heimdall := newFakeHeimdall(t, jwtService)
+	ctx := context.Background()
+
+	token := auth.GenerateTestToken(t, jwtService, "user-1", "tenant-1", "alice@example.com", []string{"viewer"})
+	subject, err := jwtService.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	exchanged, err := jwtService.GenerateExchangedToken(subject, []string{"invoices.read"}, []string{"billing-service"}, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to exchange token: %v", err)
+	}
+
+	// Tokens restricted to an audience are only accepted by that service
+	if _, err := New(heimdall.URL).Verify(ctx, exchanged); err == nil {
+		t.Error("Expected a token with an audience to be rejected without WithAudience")
+	}
+	if _, err := New(heimdall.URL, WithAudience("reporting-service")).Verify(ctx, exchanged); err == nil {
+		t.Error("Expected a token for another audience to be rejected")
+	}
+	authz := New(heimdall.URL, WithAudience("billing-service"))
+	claims, err := authz.Verify(ctx, exchanged)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if claims.Actor == nil || claims.Actor.TokenID != subject.ID {
+		t.Errorf("Expected the subject token as actor, got %+v", claims.Actor)
+	}
+
+	// Permissions outside the scope are denied without asking Heimdall
+	if allow, err := authz.Check(ctx, exchanged, claims, "users.read"); err != nil || allow {
+		t.Errorf("Expected users.read to be out of scope, got %v, %v", allow, err)
+	}
+	if checks := heimdall.checks.Load(); checks != 0 {
+		t.Errorf("Expected no check request for an out-of-scope permission, got %d", checks)
+	}
+	// Heimdall does not accept tokens meant for the service only, so permissions
+	// in their scope are allowed without asking it
+	if allow, err := authz.Check(ctx, exchanged, claims, "invoices.read"); err != nil || !allow {
+		t.Errorf("Expected invoices.read to be allowed, got %v, %v", allow, err)
+	}
+	if checks := heimdall.checks.Load(); checks != 0 {
+		t.Errorf("Expected no check request for a token meant for the service, got %d", checks)
+	}
+}
+
+func TestRequirePermission_Fiber(t *testing.T) {
+	authz, _, token := newTestAuthorizer(t)
+
+	app := fiber.New()
+	app.Get("/users", authz.RequirePermission("users.read"), func(c *fiber.Ctx) error {
+		claims, _ := ClaimsFromFiber(c)
+		return c.SendString(claims.UserID)
+	})
+	app.Delete("/users", authz.RequirePermission("users.delete"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		method string
+		token  string
+		want   int
+	}{
+		{http.MethodGet, token, fiber.StatusOK},
+		{http.MethodGet, "", fiber.StatusUnauthorized},
+		{http.MethodGet, "not-a-token", fiber.StatusUnauthorized},
+		{http.MethodDelete, token, fiber.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/users", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s with token %q: expected %d, got %d", tt.method, tt.token, tt.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestRequirePermissionHandler_HTTP(t *testing.T) {
+	authz, _, token := newTestAuthorizer(t)
+
+	handler := authz.RequirePermissionHandler("users.read", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok {
+			t.Error("Expected claims in the request context")
+			return
+		}
+		_, _ = w.Write([]byte(claims.UserID))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "user-1" {
+		t.Errorf("Expected 200 user-1, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a token, got %d", rec.Code)
+	}
+}
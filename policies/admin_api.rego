@@ -0,0 +1,164 @@
+package heimdall.admin_api
+
+# Permissions guarding Heimdall's own management API. Heimdall reads this
+# document from data.heimdall.admin_api, so operators change which permission
+# guards which endpoint by loading a modified copy of this module into OPA,
+# without rebuilding the server. The copy bundled with the server applies
+# until one is loaded, or when the loaded one is invalid.
+#
+# Routes are keyed by method and path as registered, e.g. "GET /v1/users/:userId",
+# and list permissions by name, resource.action. A request needs every
+# permission listed for its route, and a guarded route that is not listed is
+# denied.
+
+# Version of the document's format, bumped on incompatible changes. Heimdall
+# rejects versions it does not support.
+version := 1
+
+routes := {
+    # Users
+    "GET /v1/users": ["users.read"],
+    "GET /v1/users/:userId": ["users.read"],
+    "GET /v1/users/:userId/roles": ["users.read"],
+    "POST /v1/users/:userId/roles": ["roles.assign"],
+    "DELETE /v1/users/:userId/roles/:roleId": ["roles.assign"],
+    "POST /v1/users/:userId/elevations": ["roles.assign"],
+    "POST /v1/users/:userId/deactivate": ["users.delete"],
+    "POST /v1/users/:userId/restore": ["users.delete"],
+    "PATCH /v1/users/:userId/status": ["users.update"],
+    "PATCH /v1/users/:userId/attributes": ["users.update"],
+    "POST /v1/users/:userId/unlock": ["users.update"],
+
+    # Tenants and their settings
+    "GET /v1/tenants": ["tenants.read"],
+    "POST /v1/tenants": ["tenants.create"],
+    "GET /v1/tenants/slug/:slug": ["tenants.read"],
+    "PUT /v1/tenants/slug/:slug": ["tenants.create", "tenants.update"],
+    "GET /v1/tenants/:tenantId": ["tenants.read"],
+    "PATCH /v1/tenants/:tenantId": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId": ["tenants.delete"],
+    "POST /v1/tenants/:tenantId/suspend": ["tenants.suspend"],
+    "POST /v1/tenants/:tenantId/activate": ["tenants.activate"],
+    "GET /v1/tenants/:tenantId/stats": ["tenants.read"],
+    "GET /v1/tenants/:tenantId/default-roles": ["tenants.read"],
+    "PATCH /v1/tenants/:tenantId/default-roles": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/signing-keys": ["tenants.read"],
+    "POST /v1/tenants/:tenantId/signing-keys": ["tenants.update"],
+    "POST /v1/tenants/:tenantId/signing-keys/migrate": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId/signing-keys/:kid": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/saml": ["tenants.read"],
+    "PUT /v1/tenants/:tenantId/saml": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId/saml": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/claims-template": ["tenants.read"],
+    "PUT /v1/tenants/:tenantId/claims-template": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId/claims-template": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/rate-limits": ["rate_limits.read"],
+    "PUT /v1/tenants/:tenantId/rate-limits": ["rate_limits.update"],
+    "DELETE /v1/tenants/:tenantId/rate-limits": ["rate_limits.update"],
+    "GET /v1/tenants/:tenantId/ip-access": ["tenants.read"],
+    "PUT /v1/tenants/:tenantId/ip-access": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId/ip-access": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/user-attributes": ["tenants.read"],
+    "PUT /v1/tenants/:tenantId/user-attributes": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId/user-attributes": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/oauth-clients": ["tenants.read"],
+    "POST /v1/tenants/:tenantId/oauth-clients": ["tenants.update"],
+    "GET /v1/tenants/:tenantId/oauth-clients/:clientId": ["tenants.read"],
+    "PATCH /v1/tenants/:tenantId/oauth-clients/:clientId": ["tenants.update"],
+    "DELETE /v1/tenants/:tenantId/oauth-clients/:clientId": ["tenants.update"],
+    "POST /v1/tenants/:tenantId/oauth-clients/:clientId/rotate-secret": ["tenants.update"],
+
+    # Roles and permissions, addressed by name
+    "GET /v1/roles/:name": ["roles.read"],
+    "PUT /v1/roles/:name": ["roles.create", "roles.update"],
+    "DELETE /v1/roles/:name": ["roles.delete"],
+    "GET /v1/permissions/:name": ["permissions.read"],
+    "PUT /v1/permissions/:name": ["permissions.create", "permissions.update"],
+    "DELETE /v1/permissions/:name": ["permissions.delete"],
+
+    # Policies and policy templates
+    "GET /v1/policies": ["policies.read"],
+    "POST /v1/policies": ["policies.create"],
+    "POST /v1/policies/from-template": ["policies.create"],
+    "POST /v1/policies/compile": ["policies.read"],
+    "POST /v1/policies/test-suite": ["policies.test"],
+    "POST /v1/policies/format": ["policies.read"],
+    "POST /v1/policies/lint": ["policies.read"],
+    "POST /v1/policies/sync": ["policies.sync"],
+    "GET /v1/policies/export": ["policies.read"],
+    "GET /v1/policies/path/*": ["policies.read"],
+    "PUT /v1/policies/path/*": ["policies.create", "policies.update"],
+    "GET /v1/policies/deleted": ["policies.read"],
+    "GET /v1/policies/:id": ["policies.read"],
+    "PUT /v1/policies/:id": ["policies.update"],
+    "DELETE /v1/policies/:id": ["policies.delete"],
+    "POST /v1/policies/:id/restore": ["policies.delete"],
+    "DELETE /v1/policies/:id/purge": ["policies.delete"],
+    "POST /v1/policies/:id/publish": ["policies.publish"],
+    "POST /v1/policies/:id/archive": ["policies.update"],
+    "GET /v1/policies/:id/dependencies": ["policies.read"],
+    "POST /v1/policies/:id/validate": ["policies.test"],
+    "POST /v1/policies/:id/test": ["policies.test"],
+    "GET /v1/policies/:id/versions": ["policies.read"],
+    "GET /v1/policy-templates": ["policies.read"],
+
+    # Audit log and analytics
+    "GET /v1/audit/admin-actions": ["audit.read"],
+    "GET /v1/audit/decisions": ["audit.read"],
+    "GET /v1/analytics/authz": ["audit.read"],
+    "GET /v1/analytics/auth": ["audit.read"],
+
+    # Platform administration
+    "GET /v1/admin/overview": ["admin.overview"],
+    "GET /v1/admin/body-logging": ["admin.logging"],
+    "POST /v1/admin/body-logging": ["admin.logging"],
+    "DELETE /v1/admin/body-logging/:ruleId": ["admin.logging"],
+    "GET /v1/signing-keys": ["signing_keys.read"],
+    "POST /v1/signing-keys/rotate": ["signing_keys.rotate"],
+
+    # Decision logs and status reports of OPA instances
+    "POST /v1/logs": ["decision_logs.write"],
+    "POST /v1/status": ["opa_instances.report"],
+    "GET /v1/opa-instances": ["opa_instances.read"],
+
+    # Resource registry
+    "GET /v1/resources": ["resources.read"],
+    "GET /v1/resources/:resourceType/:resourceId": ["resources.read"],
+    "PUT /v1/resources/:resourceType/:resourceId": ["resources.update"],
+    "DELETE /v1/resources/:resourceType/:resourceId": ["resources.delete"],
+
+    # Background jobs
+    "GET /v1/jobs": ["jobs.read"],
+    "GET /v1/jobs/:jobId": ["jobs.read"],
+
+    # Alert rules
+    "GET /v1/alert-rules": ["alert_rules.read"],
+    "POST /v1/alert-rules": ["alert_rules.write"],
+    "GET /v1/alert-rules/:ruleId": ["alert_rules.read"],
+    "PUT /v1/alert-rules/:ruleId": ["alert_rules.write"],
+    "DELETE /v1/alert-rules/:ruleId": ["alert_rules.write"],
+    "GET /v1/alert-rules/:ruleId/alerts": ["alert_rules.read"],
+
+    # Access requests
+    "GET /v1/access-requests": ["access_requests.read"],
+    "GET /v1/access-requests/:requestId": ["access_requests.read"],
+    "POST /v1/access-requests/:requestId/approve": ["access_requests.approve"],
+    "POST /v1/access-requests/:requestId/deny": ["access_requests.approve"],
+
+    # Authorization debugging
+    "POST /v1/authz/explain": ["authz.debug"],
+    "POST /v1/authz/simulate": ["authz.simulate"],
+
+    # Policy bundles
+    "GET /v1/bundles": ["bundles.read"],
+    "POST /v1/bundles": ["bundles.create"],
+    "GET /v1/bundles/:id": ["bundles.read"],
+    "GET /v1/bundles/:id/download": ["bundles.read"],
+    "GET /v1/bundles/:id/download-url": ["bundles.read"],
+    "GET /v1/bundles/:id/contents": ["bundles.read"],
+    "GET /v1/bundles/:id/contents/*": ["bundles.read"],
+    "POST /v1/bundles/:id/activate": ["bundles.activate"],
+    "GET /v1/bundles/:id/deployments": ["bundles.read"],
+    "POST /v1/bundles/:id/deploy": ["bundles.deploy"],
+    "DELETE /v1/bundles/:id": ["bundles.delete"]
+}
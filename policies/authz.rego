@@ -39,6 +39,32 @@ any_policy_allows if {
     time_based.decision
 }
 
+# Custom policies of the request's tenant, loaded under data.tenants[<slug>],
+# can grant access within the tenant but never shadow these rules
+any_policy_allows if {
+    slug := data.heimdall.tenants[input.tenant.id].slug
+    data.tenants[slug].authz.allow
+}
+
+# Obligations the enforcing service must fulfil with the decision. Fields
+# masked by mask_fields obligations are removed from the resources returned.
+obligations contains obligation if {
+    # Callers reading other users without an admin role do not see their
+    # email address and attributes
+    input.resource.type == "users"
+    input.action == "read"
+    input.resource.id != input.user.id
+    not helpers.is_admin
+    not helpers.is_super_admin
+    obligation := {"type": "mask_fields", "fields": ["email", "metadata"]}
+}
+
+# Custom policies of the request's tenant can add obligations too
+obligations contains obligation if {
+    slug := data.heimdall.tenants[input.tenant.id].slug
+    obligation := data.tenants[slug].authz.obligations[_]
+}
+
 # Explicit global denials (these override everything)
 deny if {
     global_deny
@@ -55,11 +81,21 @@ global_deny if {
     input.user.metadata.deleted == true
 }
 
+global_deny if {
+    # User account is suspended
+    input.user.status == "suspended"
+}
+
 global_deny if {
     # IP is blacklisted
     is_blacklisted_ip
 }
 
+global_deny if {
+    # IP is outside the tenant's IP access lists
+    is_blocked_by_ip_access
+}
+
 global_deny if {
     # Tenant is suspended
     input.tenant.settings.suspended == true
@@ -74,6 +110,12 @@ global_deny if {
     not helpers.is_super_admin
 }
 
+global_deny if {
+    # Tenant admins are confined to their tenant
+    helpers.is_platform_operation
+    not helpers.is_platform_scope
+}
+
 global_deny if {
     # Cannot modify super admin role
     input.resource.type == "roles"
@@ -89,6 +131,24 @@ is_blacklisted_ip if {
     input.context.ipAddress == blacklist[_]
 }
 
+# Helpers to check the tenant's IP access lists, managed at
+# /v1/tenants/{tenantId}/ip-access. Denied ranges take precedence; when an
+# allowlist is set, only its ranges are admitted.
+is_blocked_by_ip_access if {
+    cidr := input.tenant.settings.ipAccess.denylist[_]
+    net.cidr_contains(cidr, input.context.ipAddress)
+}
+
+is_blocked_by_ip_access if {
+    count(input.tenant.settings.ipAccess.allowlist) > 0
+    not is_allowlisted_ip
+}
+
+is_allowlisted_ip if {
+    cidr := input.tenant.settings.ipAccess.allowlist[_]
+    net.cidr_contains(cidr, input.context.ipAddress)
+}
+
 # Policy evaluation metadata (for debugging and audit)
 evaluation_context := {
     "timestamp": input.time.timestamp,
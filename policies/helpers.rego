@@ -2,11 +2,29 @@ package heimdall.helpers
 
 # Helper functions used across all policies
 
+# Roles and permissions synced by Heimdall into data.heimdall.tenants. Once a
+# tenant is synced they replace the roles and permissions in the input, so
+# decisions follow role changes without waiting for new tokens.
+tenant_rbac := data.heimdall.tenants[input.user.tenantId]
+
+rbac_synced if {
+    tenant_rbac
+}
+
+synced_roles contains role if {
+    some role in tenant_rbac.users[input.user.id].roles
+}
+
 # Check if user has a specific role
 has_role(role) if {
+    not rbac_synced
     role == input.user.roles[_]
 }
 
+has_role(role) if {
+    synced_roles[role]
+}
+
 # Check if user has any of the specified roles
 has_any_role(roles) if {
     some role in roles
@@ -20,9 +38,15 @@ has_all_roles(roles) if {
 
 # Check if user has a specific permission
 has_permission(permission) if {
+    not rbac_synced
     permission == input.user.permissions[_]
 }
 
+has_permission(permission) if {
+    some role in synced_roles
+    permission in tenant_rbac.roles[role].permissions
+}
+
 # Check if user has any of the specified permissions
 has_any_permission(permissions) if {
     some perm in permissions
@@ -93,9 +117,32 @@ is_admin if {
     has_role("admin")
 }
 
-# Check if user is super admin
+# Check if user is super admin. Super admins act across tenants only with a
+# platform-scoped token; a super_admin role alone does not cross tenants.
 is_super_admin if {
     has_role("super_admin")
+    is_platform_scope
+}
+
+# Check if the user's token operates across tenants (input.user.scope is
+# platform or tenant, and tokens without a scope are tenant-scoped)
+is_platform_scope if {
+    input.user.scope == "platform"
+}
+
+# Resources and actions of the platform rather than a tenant, which require a
+# platform-scoped token even when a tenant role grants them
+platform_resources := {"admin", "signing_keys"}
+
+platform_tenant_actions := {"create", "delete", "suspend", "activate"}
+
+is_platform_operation if {
+    platform_resources[input.resource.type]
+}
+
+is_platform_operation if {
+    input.resource.type == "tenants"
+    platform_tenant_actions[input.action]
 }
 
 # Check if session is fresh (less than specified seconds old)
@@ -115,6 +162,8 @@ is_write_operation if {
     is_action("update")
 } else if {
     is_action("delete")
+} else if {
+    is_action("sync")
 }
 
 # Check if user is accessing their own resource
@@ -0,0 +1,10 @@
+// Package policies embeds the bundled Rego policies, which the server loads
+// into its embedded OPA in development mode
+package policies
+
+import "embed"
+
+// Files holds the bundled policies, one module per .rego file
+//
+//go:embed *.rego
+var Files embed.FS
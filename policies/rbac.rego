@@ -160,6 +160,21 @@ allow if {
     helpers.in_tenant
 }
 
+# Resource registry - users can read registered resources within their
+# tenant, only admins can register or remove them
+allow if {
+    input.resource.type == "resources"
+    input.action == "read"
+    helpers.in_tenant
+}
+
+allow if {
+    input.resource.type == "resources"
+    helpers.is_write_operation
+    helpers.is_admin
+    helpers.in_tenant
+}
+
 # Deny rules (explicit denials take precedence)
 deny if {
     # Cannot delete system permissions
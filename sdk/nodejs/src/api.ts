@@ -0,0 +1,3326 @@
+// Code generated by genclient from the OpenAPI specification. DO NOT EDIT.
+
+import { AxiosInstance, AxiosRequestConfig } from 'axios';
+import { HeimdallError } from './types';
+
+export interface AccessRequest {
+  createdAt: string;
+  decidedAt?: string;
+  decidedBy?: string;
+  decisionComment?: string;
+  durationMinutes: number;
+  grantedUntil?: string;
+  id: string;
+  justification: string;
+  requesterEmail?: string;
+  requesterId: string;
+  roleId: string;
+  roleName?: string;
+  status: string;
+  tenantId: string;
+}
+
+export interface AccessRequestDecision {
+  comment?: string;
+  durationMinutes?: number;
+}
+
+export interface ActionNonce {
+  action: string;
+  expiresAt: string;
+  nonce: string;
+  resourceId: string;
+}
+
+export interface ActionNonceRequest {
+  action: string;
+  resourceId: string;
+}
+
+export interface ActiveBundleRevision {
+  activatedAt?: string;
+  bundleId: string;
+  name: string;
+  revision?: string;
+  tenantId: string;
+  version: string;
+}
+
+export interface AdminAction {
+  action: string;
+  createdAt: string;
+  details?: Record<string, any>;
+  durationMs: number;
+  id: string;
+  ipAddress?: string;
+  message?: string;
+  method: string;
+  path: string;
+  resource?: string;
+  resourceId?: string;
+  status: string;
+  statusCode: number;
+  tenantId: string;
+  userAgent?: string;
+  userId?: string;
+}
+
+export interface AdminOverview {
+  bundles: BundleOverview;
+  errorRates: OverviewErrorRates;
+  failedDeployments: FailedDeployment[];
+  generatedAt: string;
+  opa: OPAOverview;
+  tenants: StatusCounts;
+  users: UserOverview;
+}
+
+export interface Alert {
+  createdAt: string;
+  id: string;
+  message: string;
+  ruleId: string;
+  subject?: string;
+  threshold: number;
+  value: number;
+}
+
+export interface AlertRule {
+  createdAt: string;
+  enabled: boolean;
+  id: string;
+  minEvents?: number;
+  name: string;
+  pagerDutyConfigured: boolean;
+  severity: string;
+  tenantId: string;
+  threshold: number;
+  type: string;
+  updatedAt: string;
+  webhookConfigured: boolean;
+  windowMinutes: number;
+}
+
+export interface AlertRuleRequest {
+  enabled?: boolean;
+  minEvents?: number;
+  name: string;
+  pagerDutyRoutingKey?: string;
+  severity?: string;
+  threshold: number;
+  type: string;
+  webhookUrl?: string;
+  windowMinutes: number;
+}
+
+export interface AssignRoleToUserRequest {
+  /** Remove the assignment at this time, permanent when omitted */
+  expiresAt?: string;
+  roleId: string;
+}
+
+export interface AuthActivityDay {
+  activeUsers: number;
+  day: string;
+  failedLogins: number;
+  loginSuccessRate: number;
+  logins: number;
+  mfaAdoption: number;
+  mfaUsers: number;
+  registrations: number;
+  weeklyActiveUsers: number;
+}
+
+export interface AuthActivitySummary {
+  dailyActiveUsers: number;
+  failedLogins: number;
+  loginSuccessRate: number;
+  logins: number;
+  mfaAdoption: number;
+  registrations: number;
+  weeklyActiveUsers: number;
+}
+
+export interface AuthAnalytics {
+  aggregatedAt?: string;
+  daily: AuthActivityDay[];
+  from: string;
+  summary: AuthActivitySummary;
+  to: string;
+}
+
+export interface AuthResponse {
+  accessToken: string;
+  expiresIn: number;
+  refreshToken: string;
+  tokenType: string;
+  user: UserInfo;
+}
+
+export interface AuthzAnalytics {
+  dormantRoles: DormantRole[];
+  from: string;
+  roleDenyRates: RoleDenyRate[];
+  summary: AuthzSummary;
+  to: string;
+  topDeniedRoutes: DeniedRoute[];
+  unmatchedPolicies: UnmatchedPolicy[];
+  unusedPermissions: UnusedPermission[];
+}
+
+export interface AuthzCheckRequest {
+  action: string;
+  context?: Record<string, any>;
+  resource?: ResourceContext;
+}
+
+export interface AuthzDecision {
+  /** Directives the policy suggests, which the client may ignore */
+  advice?: PolicyDirective[];
+  allow: boolean;
+  decision: boolean;
+  metadata?: AuthzDecisionMetadata;
+  /** Directives the policy requires the client to fulfil when enforcing the decision */
+  obligations?: PolicyDirective[];
+  reason: string;
+}
+
+export interface AuthzDecisionMetadata {
+  cache?: AuthzDecisionMetadataCache;
+  decisionId?: string;
+}
+
+export interface AuthzDecisionMetadataCache {
+  age?: number;
+  maxStale?: number;
+  status?: 'HIT' | 'STALE' | 'MISS' | 'BYPASS';
+}
+
+export interface AuthzExplainRequest {
+  action: string;
+  context?: Record<string, any>;
+  resource?: ResourceContext;
+  trace?: boolean;
+}
+
+export interface AuthzExplanation {
+  allow: boolean;
+  decisionId?: string;
+  denials?: string[];
+  input: Record<string, any>;
+  inputsUsed: string[];
+  notes?: string[];
+  policies?: Record<string, any>;
+  reason: string;
+  rules: FiredRule[];
+  trace?: TraceEvent[];
+}
+
+export interface AuthzSimulation {
+  active: SimulatedDecision;
+  bundle?: SimulatedDecision;
+  changed: boolean;
+  input: Record<string, any>;
+}
+
+export interface AuthzSummary {
+  decisions: number;
+  denied: number;
+  denyRate: number;
+}
+
+export interface BodyLoggingRule {
+  createdAt: string;
+  createdBy: string;
+  expiresAt: string;
+  id: string;
+  method?: string;
+  path: string;
+}
+
+export interface BreakGlassSession {
+  expiresAt: string;
+  issuedAt: string;
+  operator: string;
+  reason: string;
+  tenantId: string;
+  tokenId: string;
+  userId: string;
+}
+
+export interface BundleContents {
+  bundleId: string;
+  checksum: string;
+  files: BundleFile[];
+  version: string;
+}
+
+export interface BundleDeployment {
+  bundle?: PolicyBundle;
+  bundleId: string;
+  createdAt: string;
+  deployedAt: string;
+  deployedBy: string;
+  environment?: string;
+  errorMessage?: string;
+  id: string;
+  manifest?: any;
+  revision?: string;
+  rollbackReason?: string;
+  rolledBackAt?: string;
+  rolledBackBy?: string;
+  status: string;
+  updatedAt: string;
+}
+
+export interface BundleDownloadURL {
+  checksum: string;
+  expiresAt: string;
+  size: number;
+  url: string;
+}
+
+export interface BundleFile {
+  checksum: string;
+  path: string;
+  policyId?: string;
+  policyName?: string;
+  size: number;
+}
+
+export interface BundleOverview {
+  active: number;
+  recent: ActiveBundleRevision[];
+}
+
+export interface BundleSelector {
+  pathPrefix?: string;
+  tags?: string[];
+}
+
+export interface ChangePasswordRequest {
+  confirmPassword: string;
+  currentPassword: string;
+  newPassword: string;
+}
+
+export interface ClaimsTemplateResponse {
+  audiences: string[];
+  createdAt: string;
+  customClaims: Record<string, any>;
+  includePermissions: boolean;
+  includeRoles: boolean;
+  maxTokenSize: number;
+  roleFilter: string[];
+  tenantId: string;
+  tenantMetadata: string[];
+  updatedAt: string;
+}
+
+export interface CompilePolicyRulesResult {
+  content: string;
+}
+
+export interface CreateAccessRequestRequest {
+  durationMinutes: number;
+  justification: string;
+  roleId: string;
+}
+
+export interface CreateBundleRequest {
+  autoRebuild?: boolean;
+  description?: string;
+  includeData?: boolean;
+  isGlobal?: boolean;
+  name: string;
+  policyIds?: string[];
+  selector?: BundleSelector;
+  tenantId?: string;
+  version: string;
+}
+
+export interface CreateOAuthClientRequest {
+  name: string;
+  scopes: string[];
+}
+
+export interface CreatePolicyFromTemplateRequest {
+  description?: string;
+  name: string;
+  package?: string;
+  path?: string;
+  tags?: string[];
+  templateId: string;
+  variables?: Record<string, any>;
+}
+
+export interface CreatePolicyRequest {
+  content: string;
+  description?: string;
+  metadata?: Record<string, any>;
+  name: string;
+  path?: string;
+  tags?: string[];
+  testCases?: PolicyTestCase[];
+  type?: string;
+}
+
+export interface CreateTenantRequest {
+  maxRoles?: number;
+  maxUsers?: number;
+  name: string;
+  settings?: Record<string, any>;
+  slug: string;
+}
+
+export interface DecisionEvent {
+  bundles?: Record<string, any>;
+  decision_id: string;
+  erased?: string[];
+  error?: any;
+  input?: any;
+  labels: Record<string, any>;
+  masked?: string[];
+  metrics?: Record<string, any>;
+  path: string;
+  query?: string;
+  requested_by?: string;
+  result?: any;
+  timestamp: string;
+}
+
+export interface DecisionLog {
+  action?: string;
+  bundles?: Record<string, any>;
+  clientId?: string;
+  decisionId: string;
+  error?: any;
+  id: string;
+  input?: any;
+  instanceId: string;
+  ipAddress?: string;
+  labels?: Record<string, any>;
+  metrics?: Record<string, any>;
+  path: string;
+  requestedBy?: string;
+  resource?: string;
+  result?: any;
+  status: string;
+  timestamp: string;
+}
+
+export interface DefaultRole {
+  id: string;
+  name: string;
+}
+
+export interface DefaultRolesResponse {
+  roles: DefaultRole[];
+  tenantId: string;
+}
+
+export interface DeniedRoute {
+  action?: string;
+  denied: number;
+  resource?: string;
+  route: string;
+  source: string;
+}
+
+export interface DeployBundleRequest {
+  environment?: string;
+}
+
+export interface DeviceAuthorizationResponse {
+  clientId: string;
+  expiresAt: string;
+  status: string;
+  userCode: string;
+}
+
+export interface DeviceCodeRequest {
+  client_id: string;
+}
+
+export interface DeviceCodeResponse {
+  device_code: string;
+  expires_in: number;
+  interval: number;
+  user_code: string;
+  verification_uri: string;
+  verification_uri_complete: string;
+}
+
+export interface DeviceVerificationRequest {
+  userCode: string;
+}
+
+export interface DormantRole {
+  id: string;
+  members: number;
+  name: string;
+}
+
+export interface ElevateRoleRequest {
+  durationMinutes: number;
+  reason: string;
+  roleId: string;
+}
+
+export interface EnableBodyLoggingRequest {
+  durationMinutes?: number;
+  method?: string;
+  path: string;
+}
+
+export interface ExportPoliciesResult {
+  files: PolicyFile[];
+}
+
+export interface FailedDeployment {
+  bundleId: string;
+  bundleName: string;
+  deployedAt: string;
+  environment?: string;
+  error?: string;
+  id: string;
+  tenantId: string;
+}
+
+export interface FiredRule {
+  count: number;
+  location?: string;
+  name: string;
+}
+
+export interface ForgotPasswordRequest {
+  email: string;
+}
+
+/** holds the query parameters of GetAuthAnalytics */
+export interface GetAuthAnalyticsParams {
+  /** Start of the period (RFC 3339), rounded down to the day, defaults to 30 days before to */
+  from?: string;
+  /** End of the period (RFC 3339), defaults to now */
+  to?: string;
+}
+
+/** holds the query parameters of GetAuthzAnalytics */
+export interface GetAuthzAnalyticsParams {
+  /** Start of the period (RFC 3339), defaults to 30 days before to */
+  from?: string;
+  /** End of the period (RFC 3339), defaults to now */
+  to?: string;
+  /** Number of denied routes listed, at most 100 */
+  limit?: number;
+  /** Response format */
+  format?: 'json' | 'csv';
+}
+
+/** holds the query parameters of GetBundleDownloadURL */
+export interface GetBundleDownloadURLParams {
+  /** Lifetime of the URL in seconds, from 60 to 604800 (default 900) */
+  expiresIn?: number;
+}
+
+/** holds the query parameters of GetDeviceAuthorization */
+export interface GetDeviceAuthorizationParams {
+  /** User code shown by the device */
+  user_code?: string;
+}
+
+/** holds the query parameters of GetMyLoginHistory */
+export interface GetMyLoginHistoryParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt';
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface GetMyLoginHistoryResult {
+  logins?: LoginEvent[];
+  pagination?: Pagination;
+}
+
+export interface GetMyPermissionsResult {
+  permissions?: string[];
+}
+
+export interface IPAccessResponse {
+  allowlist: string[];
+  denylist: string[];
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface JWKS {
+  keys: (Record<string, any>)[];
+}
+
+export interface Job {
+  attempts: number;
+  completedAt?: string;
+  createdAt: string;
+  createdBy?: string;
+  id: string;
+  lastError?: string;
+  maxAttempts: number;
+  payload?: any;
+  result?: any;
+  runAt: string;
+  startedAt?: string;
+  status: string;
+  tenantId?: string;
+  type: string;
+  updatedAt: string;
+}
+
+export interface LintPolicyResult {
+  issues: PolicyLintIssue[];
+}
+
+/** holds the query parameters of ListAccessRequests */
+export interface ListAccessRequestsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+  /** Filter by requesting user */
+  requesterId?: string;
+  /** Filter by requested role */
+  roleId?: string;
+}
+
+export interface ListAccessRequestsResult {
+  accessRequests?: AccessRequest[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListAdminActions */
+export interface ListAdminActionsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+  /** Filter by action, e.g. roles.assign or tenants.suspend */
+  action?: string;
+  /** Filter by acting user */
+  userId?: string;
+}
+
+export interface ListAdminActionsResult {
+  actions?: AdminAction[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListAlertRules */
+export interface ListAlertRulesParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'name' | '-name';
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListAlertRulesResult {
+  pagination?: Pagination;
+  rules?: AlertRule[];
+}
+
+/** holds the query parameters of ListAlerts */
+export interface ListAlertsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt';
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListAlertsResult {
+  alerts?: Alert[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListBundles */
+export interface ListBundlesParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'name' | '-name' | 'updatedAt' | '-updatedAt' | 'version' | '-version';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListBundlesResult {
+  bundles?: PolicyBundle[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListDecisions */
+export interface ListDecisionsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'timestamp' | '-timestamp';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+  /** Filter by the OPA instance that reported the decisions */
+  instanceId?: string;
+  /** Filter by decision ID */
+  decisionId?: string;
+}
+
+export interface ListDecisionsResult {
+  decisions?: DecisionLog[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListDeletedPolicies */
+export interface ListDeletedPoliciesParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'deletedAt' | '-deletedAt' | 'name' | '-name';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListDeletedPoliciesResult {
+  pagination?: Pagination;
+  policies?: Policy[];
+}
+
+/** holds the query parameters of ListJobs */
+export interface ListJobsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'runAt' | '-runAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+  /** Filter by job type, e.g. bundle.build or policy.git_sync */
+  type?: string;
+}
+
+export interface ListJobsResult {
+  jobs?: Job[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListMyAccessRequests */
+export interface ListMyAccessRequestsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListMyAccessRequestsResult {
+  accessRequests?: AccessRequest[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListOPAInstances */
+export interface ListOPAInstancesParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'instanceId' | '-instanceId' | 'lastSeenAt' | '-lastSeenAt';
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListOPAInstancesResult {
+  instances?: OPAInstance[];
+  pagination?: Pagination;
+}
+
+/** holds the query parameters of ListPolicies */
+export interface ListPoliciesParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'name' | '-name' | 'updatedAt' | '-updatedAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListPoliciesResult {
+  pagination?: Pagination;
+  policies?: Policy[];
+}
+
+/** holds the query parameters of ListResources */
+export interface ListResourcesParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'updatedAt' | '-updatedAt';
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+  /** Filter by resource type */
+  type?: string;
+  /** Filter by owning user */
+  ownerId?: string;
+  /** Filter by label as a key=value pair, e.g. env=prod */
+  label?: string;
+}
+
+export interface ListResourcesResult {
+  pagination?: Pagination;
+  resources?: Resource[];
+}
+
+/** holds the query parameters of ListTenants */
+export interface ListTenantsParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'name' | '-name' | 'slug' | '-slug' | 'updatedAt' | '-updatedAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+}
+
+export interface ListTenantsResult {
+  pagination?: Pagination;
+  tenants?: TenantResponse[];
+}
+
+/** holds the query parameters of ListUsers */
+export interface ListUsersParams {
+  /** Page number, ignored when a cursor is given */
+  page?: number;
+  /** Items per page */
+  pageSize?: number;
+  /** Opaque cursor from a previous page's nextCursor */
+  cursor?: string;
+  /** Sort field, prefixed with '-' for descending order */
+  sort?: 'createdAt' | '-createdAt' | 'email' | '-email' | 'updatedAt' | '-updatedAt';
+  /** Filter by status */
+  status?: string;
+  /** Only include items created at or after this time */
+  createdAfter?: string;
+  /** Only include items created before this time */
+  createdBefore?: string;
+  /** Match an email prefix, or a first name, last name or full name prefix, case-insensitively. Names are not matched once user metadata is encrypted at rest */
+  query?: string;
+  /** Match the query anywhere in the email instead of as a prefix */
+  fuzzy?: boolean;
+  /** Filter by assigned role name */
+  role?: string;
+  /** Only include users who last logged in at or after this time */
+  lastLoginAfter?: string;
+  /** Only include users who last logged in before this time */
+  lastLoginBefore?: string;
+}
+
+export interface ListUsersResult {
+  pagination?: Pagination;
+  users?: UserProfile[];
+}
+
+export interface LoginEvent {
+  city?: string;
+  country?: string;
+  createdAt: string;
+  deviceFingerprint?: string;
+  id: string;
+  ipAddress?: string;
+  mfa: boolean;
+  reasons?: any;
+  region?: string;
+  suspicious: boolean;
+  tenantId: string;
+  userAgent?: string;
+  userId: string;
+}
+
+export interface LoginRequest {
+  email: string;
+  password: string;
+  rememberMe?: boolean;
+}
+
+export interface MagicLinkRedeemRequest {
+  token: string;
+}
+
+export interface MagicLinkRequest {
+  email: string;
+  tenantId?: string;
+}
+
+export interface MagicLinkResponse {
+  expiresIn: number;
+}
+
+export interface OAuthClientResponse {
+  clientId: string;
+  clientSecret?: string;
+  createdAt: string;
+  id: string;
+  lastUsedAt?: string;
+  name: string;
+  scopes: string[];
+  secretRotatedAt?: string;
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface OAuthErrorResponse {
+  error: string;
+  error_description?: string;
+}
+
+export interface OAuthTokenRequest {
+  client_id?: string;
+  client_secret?: string;
+  device_code?: string;
+  grant_type: string;
+  scope?: string;
+}
+
+export interface OAuthTokenResponse {
+  access_token: string;
+  expires_in: number;
+  refresh_token?: string;
+  scope?: string;
+  token_type: string;
+}
+
+export interface OPABundleStatus {
+  active_revision?: string;
+  code?: string;
+  last_successful_activation?: string;
+  last_successful_download?: string;
+  message?: string;
+  name: string;
+}
+
+export interface OPAInstance {
+  bundles: OPAInstanceBundle[];
+  clientId?: string;
+  firstSeenAt: string;
+  healthy: boolean;
+  id: string;
+  instanceId: string;
+  ipAddress?: string;
+  labels?: Record<string, any>;
+  lastSeenAt: string;
+  plugins?: Record<string, any>;
+  stale: boolean;
+  version?: string;
+}
+
+export interface OPAInstanceBundle {
+  activeRevision?: string;
+  bundleId?: string;
+  bundleName?: string;
+  bundleVersion?: string;
+  error?: string;
+  lastSuccessfulActivation?: string;
+  lastSuccessfulDownload?: string;
+  name: string;
+  upToDate?: boolean;
+}
+
+export interface OPAOverview {
+  error?: string;
+  healthy: number;
+  instances: number;
+  reachable: boolean;
+  stale: number;
+  unhealthy: number;
+}
+
+export interface OPAPluginStatus {
+  message?: string;
+  state: string;
+}
+
+export interface OPAStatusReport {
+  bundles?: Record<string, any>;
+  labels: Record<string, any>;
+  plugins?: Record<string, any>;
+}
+
+export interface OverviewErrorRates {
+  adminActionErrorRate: number;
+  adminActionErrors: number;
+  adminActions: number;
+  decisionErrorRate: number;
+  decisionErrors: number;
+  decisions: number;
+}
+
+export interface Pagination {
+  hasMore: boolean;
+  nextCursor?: string;
+  page?: number;
+  pageSize: number;
+  sort: string;
+  total: number;
+  totalPages: number;
+}
+
+export interface PasswordPolicy {
+  disallowCommon: boolean;
+  disallowUserInfo: boolean;
+  historySize: number;
+  maxLength: number;
+  minLength: number;
+  requireDigit: boolean;
+  requireLowercase: boolean;
+  requireSymbol: boolean;
+  requireUppercase: boolean;
+}
+
+export interface Permission {
+  action: string;
+  createdAt: string;
+  deletedAt?: string | null;
+  description?: string;
+  id: string;
+  isSystem: boolean;
+  name: string;
+  resource: string;
+  roles?: (Record<string, any>)[];
+  scope: string;
+  updatedAt: string;
+}
+
+export interface PlatformSigningKey {
+  alg: string;
+  createdAt: string;
+  id: string;
+  kid: string;
+  publicKey: string;
+  retiredAt?: string;
+  status: string;
+  updatedAt: string;
+}
+
+export interface Policy {
+  bundles?: PolicyBundle[];
+  content: string;
+  createdAt: string;
+  createdBy: string;
+  deletedAt?: string | null;
+  description?: string;
+  id: string;
+  isSystem: boolean;
+  isValid: boolean;
+  metadata?: any;
+  name: string;
+  path: string;
+  publishedAt?: string;
+  publishedBy?: string;
+  status: string;
+  tags?: any;
+  tenant?: Record<string, any>;
+  tenantId: string;
+  testCases?: any;
+  type: string;
+  updatedAt: string;
+  updatedBy: string;
+  validatedAt?: string;
+  validationError?: string;
+  version: number;
+}
+
+export interface PolicyBundle {
+  activatedAt?: string;
+  activatedBy?: string;
+  autoRebuild: boolean;
+  buildCompletedAt?: string;
+  buildError?: string;
+  buildJobId?: string;
+  buildLog?: string;
+  buildStartedAt?: string;
+  checksum?: string;
+  createdAt: string;
+  createdBy: string;
+  deactivatedAt?: string;
+  deactivatedBy?: string;
+  deletedAt?: string | null;
+  deployments?: BundleDeployment[];
+  description?: string;
+  id: string;
+  includeData: boolean;
+  isGlobal: boolean;
+  manifest?: any;
+  name: string;
+  policies?: Policy[];
+  selector?: any;
+  size?: number;
+  status: string;
+  storageBucket?: string;
+  storagePath?: string;
+  tenant?: Record<string, any>;
+  tenantId?: string;
+  updatedAt: string;
+  updatedBy: string;
+  version: string;
+}
+
+export interface PolicyContentRequest {
+  content: string;
+}
+
+export interface PolicyDependencies {
+  dependents: PolicyReference[];
+  imports: PolicyImport[];
+  policy: PolicyReference;
+}
+
+export interface PolicyDirective {
+  parameters?: Record<string, any>;
+  type: string;
+}
+
+export interface PolicyFile {
+  content: string;
+  path: string;
+}
+
+export interface PolicyFormatResult {
+  changed: boolean;
+  content: string;
+}
+
+export interface PolicyImport {
+  import: string;
+  package?: string;
+  policies?: PolicyReference[];
+}
+
+export interface PolicyLintIssue {
+  line: number;
+  message: string;
+  rule: string;
+  severity: string;
+}
+
+export interface PolicyReference {
+  id: string;
+  isValid: boolean;
+  name: string;
+  package?: string;
+  path: string;
+  status: string;
+}
+
+export interface PolicyRule {
+  actions: string[];
+  conditions?: PolicyRuleCondition[];
+  description?: string;
+  resources: string[];
+  subjects: PolicyRuleSubjects;
+}
+
+export interface PolicyRuleCondition {
+  attribute: string;
+  operator: string;
+  value?: any;
+  valueFrom?: string;
+}
+
+export interface PolicyRuleSet {
+  package?: string;
+  rules: PolicyRule[];
+}
+
+export interface PolicyRuleSubjects {
+  permissions?: string[];
+  roles?: string[];
+  users?: string[];
+}
+
+export interface PolicySyncChange {
+  action: string;
+  diff?: string;
+  path: string;
+  policyId?: string;
+}
+
+export interface PolicySyncResult {
+  changes: PolicySyncChange[];
+  created: number;
+  deleted: number;
+  dryRun: boolean;
+  unchanged: number;
+  updated: number;
+}
+
+export interface PolicyTemplate {
+  content: string;
+  description: string;
+  id: string;
+  name: string;
+  variables: PolicyTemplateVariable[];
+}
+
+export interface PolicyTemplateVariable {
+  default?: any;
+  description: string;
+  format?: string;
+  maximum?: number;
+  minimum?: number;
+  name: string;
+  required: boolean;
+  type: string;
+}
+
+export interface PolicyTestCase {
+  expected: Record<string, any>;
+  input: Record<string, any>;
+  name: string;
+  note?: string;
+}
+
+export interface PolicyTestResult {
+  message?: string;
+  passed: boolean;
+  testName: string;
+}
+
+export interface PolicyTestSuitePolicy {
+  content: string;
+  path: string;
+  testCases?: PolicyTestCase[];
+  type?: string;
+}
+
+export interface PolicyTestSuitePolicyResult {
+  error?: string;
+  package?: string;
+  path: string;
+  results: PolicyTestResult[];
+}
+
+export interface PolicyTestSuiteRequest {
+  policies: PolicyTestSuitePolicy[];
+}
+
+export interface PolicyTestSuiteResult {
+  exitCode: number;
+  failed: number;
+  passed: boolean;
+  policies: PolicyTestSuitePolicyResult[];
+  total: number;
+}
+
+export interface PolicyVersion {
+  changeNote?: string;
+  content: string;
+  createdAt: string;
+  createdBy: string;
+  id: string;
+  policy?: Policy;
+  policyId: string;
+  version: number;
+}
+
+export interface PolicyWarning {
+  code: string;
+  dependents?: PolicyReference[];
+  message: string;
+}
+
+export interface PublicTenant {
+  branding: TenantBranding;
+  id: string;
+  loginMethods: string[];
+  name: string;
+  slug: string;
+  sso: TenantSSO;
+}
+
+export interface RateLimitsResponse {
+  createdAt: string;
+  defaults: Record<string, any>;
+  limits: Record<string, any>;
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface RefreshTokenRequest {
+  refreshToken: string;
+}
+
+export interface RegisterRequest {
+  email: string;
+  firstName: string;
+  lastName: string;
+  password: string;
+  tenantId?: string;
+}
+
+export interface ReportDecisionLogsResult {
+  accepted: number;
+}
+
+export interface ResetPasswordRequest {
+  newPassword: string;
+  token: string;
+}
+
+export interface Resource {
+  attributes?: Record<string, any>;
+  createdAt?: string;
+  derived: boolean;
+  labels?: Record<string, any>;
+  ownerId?: string;
+  resourceId: string;
+  tenantId?: string;
+  type: string;
+  updatedAt?: string;
+}
+
+export interface ResourceContext {
+  attributes?: Record<string, any>;
+  id?: string;
+  ownerId?: string;
+  tenantId?: string;
+  type: string;
+}
+
+export interface RoleAssignment {
+  assignedAt: string;
+  assignedBy?: string;
+  expired: boolean;
+  expiresAt?: string;
+  id: string;
+  reason?: string;
+  roleId: string;
+  roleName: string;
+}
+
+export interface RoleDenyRate {
+  decisions: number;
+  denied: number;
+  denyRate: number;
+  role: string;
+}
+
+export interface RoleResponse {
+  createdAt: string;
+  description?: string;
+  id: string;
+  isSystem: boolean;
+  name: string;
+  parentRole?: string;
+  permissions: string[];
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface RotateOAuthClientSecretRequest {
+  gracePeriod?: number;
+}
+
+export interface SAMLConfigResponse {
+  acsUrl: string;
+  allowIdpInitiated: boolean;
+  createdAt: string;
+  emailAttribute?: string;
+  enabled: boolean;
+  entityId: string;
+  firstNameAttribute?: string;
+  idpEntityId: string;
+  lastNameAttribute?: string;
+  roleAttribute?: string;
+  roleMappings: SAMLRoleMapping[];
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface SAMLRoleMapping {
+  role: string;
+  value: string;
+}
+
+export interface SimulateRequest {
+  action: string;
+  bundleId?: string;
+  context?: SimulationContext;
+  resource?: ResourceContext;
+  roles?: string[];
+  userId?: string;
+}
+
+export interface SimulatedDecision {
+  allow: boolean;
+  bundleId?: string;
+  reason: string;
+}
+
+export interface SimulationContext {
+  ipAddress?: string;
+  mfaVerified?: boolean;
+  sessionAge?: number;
+  time?: string;
+}
+
+export interface StatusCounts {
+  byStatus: Record<string, any>;
+  total: number;
+}
+
+export interface SyncPoliciesRequest {
+  delete?: boolean;
+  dryRun?: boolean;
+  files: PolicyFile[];
+}
+
+export interface TenantBranding {
+  logoUrl?: string;
+  primaryColor?: string;
+}
+
+export interface TenantResponse {
+  createdAt: string;
+  id: string;
+  maxRoles: number;
+  maxUsers: number;
+  name: string;
+  settings?: Record<string, any>;
+  slug: string;
+  stats?: Record<string, any>;
+  status: string;
+  updatedAt: string;
+}
+
+export interface TenantSSO {
+  available: boolean;
+  loginUrl?: string;
+  protocol?: string;
+}
+
+export interface TenantSigningKey {
+  alg: string;
+  createdAt: string;
+  id: string;
+  kid: string;
+  publicKey: string;
+  retiredAt?: string;
+  revokedAt?: string;
+  status: string;
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface TokenExchangeRequest {
+  audience?: string;
+  expiresIn?: number;
+  grantType?: string;
+  scope: string;
+}
+
+export interface TokenExchangeResponse {
+  accessToken: string;
+  expiresIn: number;
+  issuedTokenType: string;
+  scope: string;
+  tokenType: string;
+}
+
+export interface TraceEvent {
+  locals?: any;
+  message?: string;
+  node?: any;
+  op: string;
+  parent_id: string;
+  query_id: string;
+  type: string;
+}
+
+export interface UnmatchedPolicy {
+  id: string;
+  name: string;
+  package?: string;
+  path: string;
+}
+
+export interface UnusedPermission {
+  action: string;
+  name: string;
+  resource: string;
+  roles: string[];
+}
+
+export interface UpdateDefaultRolesRequest {
+  add?: string[];
+  remove?: string[];
+}
+
+export interface UpdateOAuthClientRequest {
+  name?: string;
+  scopes?: string[];
+}
+
+export interface UpdatePolicyRequest {
+  content?: string;
+  description?: string;
+  metadata?: Record<string, any>;
+  name?: string;
+  status?: string;
+  tags?: string[];
+  testCases?: PolicyTestCase[];
+}
+
+export interface UpdateProfileRequest {
+  firstName?: string;
+  lastName?: string;
+  metadata?: Record<string, any>;
+}
+
+export interface UpdateTenantRequest {
+  maxRoles?: number;
+  maxUsers?: number;
+  name?: string;
+  settings?: Record<string, any>;
+}
+
+export interface UpdateUserStatusRequest {
+  status: string;
+}
+
+export interface UpsertClaimsTemplateRequest {
+  audiences?: string[];
+  customClaims?: Record<string, any>;
+  includePermissions?: boolean;
+  includeRoles?: boolean;
+  maxTokenSize?: number;
+  roleFilter?: string[];
+  tenantMetadata?: string[];
+}
+
+export interface UpsertIPAccessRequest {
+  allowlist?: string[];
+  denylist?: string[];
+}
+
+export interface UpsertPermissionRequest {
+  action: string;
+  description?: string;
+  resource: string;
+  scope?: string;
+}
+
+export interface UpsertPolicyRequest {
+  content: string;
+  description?: string;
+  metadata?: Record<string, any>;
+  name: string;
+  tags?: string[];
+  testCases?: PolicyTestCase[];
+  type?: string;
+}
+
+export interface UpsertRateLimitsRequest {
+  limits: Record<string, any>;
+}
+
+export interface UpsertResourceRequest {
+  attributes?: Record<string, any>;
+  labels?: Record<string, any>;
+  ownerId?: string;
+}
+
+export interface UpsertRoleRequest {
+  description?: string;
+  parentRole?: string;
+  permissions?: string[];
+}
+
+export interface UpsertSAMLConfigRequest {
+  allowIdpInitiated?: boolean;
+  emailAttribute?: string;
+  enabled?: boolean;
+  firstNameAttribute?: string;
+  idpMetadata: string;
+  lastNameAttribute?: string;
+  roleAttribute?: string;
+  roleMappings?: SAMLRoleMapping[];
+}
+
+export interface UpsertTenantRequest {
+  maxRoles?: number;
+  maxUsers?: number;
+  name: string;
+  settings?: Record<string, any>;
+  status?: string;
+}
+
+export interface UpsertUserAttributeSchemaRequest {
+  attributes: UserAttributeDefinition[];
+}
+
+export interface UserAttributeDefinition {
+  description?: string;
+  maximum?: number;
+  minimum?: number;
+  name: string;
+  required?: boolean;
+  type: string;
+  userEditable?: boolean;
+  values?: string[];
+}
+
+export interface UserAttributeSchemaResponse {
+  attributes: UserAttributeDefinition[];
+  tenantId: string;
+  updatedAt: string;
+}
+
+export interface UserInfo {
+  email: string;
+  firstName?: string;
+  id: string;
+  lastName?: string;
+  tenantId: string;
+}
+
+export interface UserOverview {
+  activeLast24h: number;
+  byStatus: Record<string, any>;
+  total: number;
+}
+
+export interface UserProfile {
+  createdAt: string;
+  deactivatedAt?: string;
+  email: string;
+  firstName?: string;
+  id: string;
+  lastLoginAt?: string;
+  lastName?: string;
+  loginCount: number;
+  metadata?: Record<string, any>;
+  roles?: string[];
+  status: string;
+  tenantId: string;
+}
+
+/**
+ * Typed client for every Heimdall API operation.
+ * Methods resolve with the response's data field and reject with a HeimdallError.
+ */
+export class HeimdallApi {
+  constructor(private readonly http: AxiosInstance) {}
+
+  /**
+   * List access requests
+   *
+   * List the access requests of the caller's tenant, e.g. ?status=pending for those awaiting a decision. Requires the access_requests.read permission.
+   *
+   * `GET /v1/access-requests`
+   */
+  async listAccessRequests(params?: ListAccessRequestsParams): Promise<ListAccessRequestsResult> {
+    return this.request<ListAccessRequestsResult>({ method: 'GET', url: '/v1/access-requests', params });
+  }
+
+  /**
+   * Request access
+   *
+   * Request a role of the caller's tenant for a limited time with a justification. The tenant's approvers are notified by webhook and, when enabled, email. Durations are capped by ROLE_ELEVATION_MAX_HOURS.
+   *
+   * `POST /v1/access-requests`
+   */
+  async createAccessRequest(body: CreateAccessRequestRequest): Promise<AccessRequest> {
+    return this.request<AccessRequest>({ method: 'POST', url: '/v1/access-requests', data: body });
+  }
+
+  /**
+   * Get access request
+   *
+   * Get an access request of the caller's tenant. Requires the access_requests.read permission.
+   *
+   * `GET /v1/access-requests/{requestId}`
+   */
+  async getAccessRequest(requestId: string): Promise<AccessRequest> {
+    return this.request<AccessRequest>({ method: 'GET', url: `/v1/access-requests/${encodeURIComponent(requestId)}` });
+  }
+
+  /**
+   * Approve access request
+   *
+   * Approve a pending request, granting the requested role until the requested duration, or a shorter durationMinutes, has passed. The assignment is removed automatically once it expires. Users cannot approve their own requests. Requires the access_requests.approve permission.
+   *
+   * `POST /v1/access-requests/{requestId}/approve`
+   */
+  async approveAccessRequest(requestId: string, body?: AccessRequestDecision): Promise<AccessRequest> {
+    return this.request<AccessRequest>({ method: 'POST', url: `/v1/access-requests/${encodeURIComponent(requestId)}/approve`, data: body });
+  }
+
+  /**
+   * Cancel access request
+   *
+   * Withdraw one of the caller's pending requests.
+   *
+   * `POST /v1/access-requests/{requestId}/cancel`
+   */
+  async cancelAccessRequest(requestId: string): Promise<AccessRequest> {
+    return this.request<AccessRequest>({ method: 'POST', url: `/v1/access-requests/${encodeURIComponent(requestId)}/cancel` });
+  }
+
+  /**
+   * Deny access request
+   *
+   * Deny a pending request. Users cannot deny their own requests. Requires the access_requests.approve permission.
+   *
+   * `POST /v1/access-requests/{requestId}/deny`
+   */
+  async denyAccessRequest(requestId: string, body?: AccessRequestDecision): Promise<AccessRequest> {
+    return this.request<AccessRequest>({ method: 'POST', url: `/v1/access-requests/${encodeURIComponent(requestId)}/deny`, data: body });
+  }
+
+  /**
+   * Issue action nonce
+   *
+   * Issue a one-time nonce confirming a destructive action of the caller on a resource: tenants.delete, users.deactivate, bundles.activate, bundles.delete or policies.purge. The action's request sends the nonce in the X-Action-Nonce header before it expires; each nonce is accepted once, so admin UIs fetch one per confirmation.
+   *
+   * `POST /v1/action-nonces`
+   */
+  async issueActionNonce(body: ActionNonceRequest): Promise<ActionNonce> {
+    return this.request<ActionNonce>({ method: 'POST', url: '/v1/action-nonces', data: body });
+  }
+
+  /**
+   * List body logging rules
+   *
+   * List the routes whose request and response bodies are logged, until their rules expire. Requires admin.logging.
+   *
+   * `GET /v1/admin/body-logging`
+   */
+  async listBodyLoggingRules(): Promise<BodyLoggingRule[]> {
+    return this.request<BodyLoggingRule[]>({ method: 'GET', url: '/v1/admin/body-logging' });
+  }
+
+  /**
+   * Enable body logging
+   *
+   * Log the request and response bodies of a route, on all instances, for durationMinutes (15 by default, at most BODY_LOG_MAX_MINUTES). The route is given as documented, e.g. /v1/users/{userId}, and covers every request it serves; a rule without method covers all its methods. Passwords, tokens, refresh tokens, API keys and other credentials are redacted from the logged bodies by field name and by pattern. Requires admin.logging.
+   *
+   * `POST /v1/admin/body-logging`
+   */
+  async enableBodyLogging(body: EnableBodyLoggingRequest): Promise<BodyLoggingRule> {
+    return this.request<BodyLoggingRule>({ method: 'POST', url: '/v1/admin/body-logging', data: body });
+  }
+
+  /**
+   * Disable body logging
+   *
+   * Delete a body logging rule before it expires. Requires admin.logging.
+   *
+   * `DELETE /v1/admin/body-logging/{ruleId}`
+   */
+  async disableBodyLogging(ruleId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/admin/body-logging/${encodeURIComponent(ruleId)}` });
+  }
+
+  /**
+   * Get admin overview
+   *
+   * Snapshot of all tenants for the admin landing page: tenants and users per status, users active in the last 24 hours, active bundles and their revisions, the health of the OPA server and of the OPA instances reporting their status, the most recent failed bundle deployments, and the error rates of administrative actions and reported decisions in the last 24 hours. Requires admin.overview.
+   *
+   * `GET /v1/admin/overview`
+   */
+  async getAdminOverview(): Promise<AdminOverview> {
+    return this.request<AdminOverview>({ method: 'GET', url: '/v1/admin/overview' });
+  }
+
+  /**
+   * List alert rules
+   *
+   * List the alert rules of the caller's tenant. Requires the alert_rules.read permission.
+   *
+   * `GET /v1/alert-rules`
+   */
+  async listAlertRules(params?: ListAlertRulesParams): Promise<ListAlertRulesResult> {
+    return this.request<ListAlertRulesResult>({ method: 'GET', url: '/v1/alert-rules', params });
+  }
+
+  /**
+   * Create alert rule
+   *
+   * Create a rule alerting when a condition on the tenant's audit log exceeds a threshold within a window: more than threshold requests of a user denied with 403 (user_denies), more than threshold failed logins from an IP address (ip_login_failures), or more than threshold percent of OPA decisions failing to evaluate (decision_error_rate). Alerts are delivered to the platform webhooks as alert.fired events, and to the rule's webhook and PagerDuty service. Requires the alert_rules.write permission.
+   *
+   * `POST /v1/alert-rules`
+   */
+  async createAlertRule(body: AlertRuleRequest): Promise<AlertRule> {
+    return this.request<AlertRule>({ method: 'POST', url: '/v1/alert-rules', data: body });
+  }
+
+  /**
+   * Get alert rule
+   *
+   * Get an alert rule of the caller's tenant. Requires the alert_rules.read permission.
+   *
+   * `GET /v1/alert-rules/{ruleId}`
+   */
+  async getAlertRule(ruleId: string): Promise<AlertRule> {
+    return this.request<AlertRule>({ method: 'GET', url: `/v1/alert-rules/${encodeURIComponent(ruleId)}` });
+  }
+
+  /**
+   * Update alert rule
+   *
+   * Replace the condition and settings of an alert rule. The webhook URL and PagerDuty routing key are kept when omitted and removed when empty. Requires the alert_rules.write permission.
+   *
+   * `PUT /v1/alert-rules/{ruleId}`
+   */
+  async updateAlertRule(ruleId: string, body: AlertRuleRequest): Promise<AlertRule> {
+    return this.request<AlertRule>({ method: 'PUT', url: `/v1/alert-rules/${encodeURIComponent(ruleId)}`, data: body });
+  }
+
+  /**
+   * Delete alert rule
+   *
+   * Delete an alert rule and the alerts it fired. Requires the alert_rules.write permission.
+   *
+   * `DELETE /v1/alert-rules/{ruleId}`
+   */
+  async deleteAlertRule(ruleId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/alert-rules/${encodeURIComponent(ruleId)}` });
+  }
+
+  /**
+   * List fired alerts
+   *
+   * List the alerts an alert rule fired. A rule fires once per user or IP address within its window. Requires the alert_rules.read permission.
+   *
+   * `GET /v1/alert-rules/{ruleId}/alerts`
+   */
+  async listAlerts(ruleId: string, params?: ListAlertsParams): Promise<ListAlertsResult> {
+    return this.request<ListAlertsResult>({ method: 'GET', url: `/v1/alert-rules/${encodeURIComponent(ruleId)}/alerts`, params });
+  }
+
+  /**
+   * Get login analytics
+   *
+   * Report the caller's tenant's daily and weekly active users, successful and failed logins, new registrations and MFA adoption per day (UTC) over a period, 30 days by default and at most 366. The report is read from daily stats a background job aggregates every AUTH_ANALYTICS_INTERVAL_SECONDS, so it lags behind the latest logins by up to one interval; aggregatedAt tells when the stats were last aggregated.
+   *
+   * `GET /v1/analytics/auth`
+   */
+  async getAuthAnalytics(params?: GetAuthAnalyticsParams): Promise<AuthAnalytics> {
+    return this.request<AuthAnalytics>({ method: 'GET', url: '/v1/analytics/auth', params });
+  }
+
+  /**
+   * Get authorization analytics
+   *
+   * Aggregate the caller's tenant's audit log over a period, 30 days by default and at most 366: the deny rate of administrative actions and reported OPA decisions, the most denied routes, deny rates per role, permissions granted but never used, roles whose members were not active, and active policies no reported decision queried. Send format=csv for a CSV export with one row per item.
+   *
+   * `GET /v1/analytics/authz`
+   */
+  async getAuthzAnalytics(params?: GetAuthzAnalyticsParams): Promise<AuthzAnalytics> {
+    return this.request<AuthzAnalytics>({ method: 'GET', url: '/v1/analytics/authz', params });
+  }
+
+  /**
+   * List admin actions
+   *
+   * List the sensitive administrative actions of the caller's tenant, such as role assignments, policy publishes, tenant suspensions and user deletions, with the acting user, route, result and redacted request and response payloads
+   *
+   * `GET /v1/audit/admin-actions`
+   */
+  async listAdminActions(params?: ListAdminActionsParams): Promise<ListAdminActionsResult> {
+    return this.request<ListAdminActionsResult>({ method: 'GET', url: '/v1/audit/admin-actions', params });
+  }
+
+  /**
+   * List reported decisions
+   *
+   * List the decisions reported by the caller's tenant's OPA instances through the decision log API, with the reporting instance, input, result and bundle revisions. Filter by status allowed, denied, error or unknown.
+   *
+   * `GET /v1/audit/decisions`
+   */
+  async listDecisions(params?: ListDecisionsParams): Promise<ListDecisionsResult> {
+    return this.request<ListDecisionsResult>({ method: 'GET', url: '/v1/audit/decisions', params });
+  }
+
+  /**
+   * Login with email and password
+   *
+   * Authenticate user and return access tokens
+   *
+   * `POST /v1/auth/login`
+   */
+  async login(body: LoginRequest): Promise<AuthResponse> {
+    return this.request<AuthResponse>({ method: 'POST', url: '/v1/auth/login', data: body });
+  }
+
+  /**
+   * Logout user
+   *
+   * Invalidate current session and tokens
+   *
+   * `POST /v1/auth/logout`
+   */
+  async logout(): Promise<void> {
+    return this.request<void>({ method: 'POST', url: '/v1/auth/logout' });
+  }
+
+  /**
+   * Logout from all devices
+   *
+   * Invalidate all sessions and tokens for the user
+   *
+   * `POST /v1/auth/logout-all`
+   */
+  async logoutAll(): Promise<void> {
+    return this.request<void>({ method: 'POST', url: '/v1/auth/logout-all' });
+  }
+
+  /**
+   * Request magic link
+   *
+   * Email a single-use sign-in link to an active user of the tenant, or of the default tenant when none is given. Unknown emails, inactive users and emails over their hourly limit get the same response without an email, so it does not reveal which emails are registered.
+   *
+   * `POST /v1/auth/magic-link`
+   */
+  async requestMagicLink(body: MagicLinkRequest): Promise<MagicLinkResponse> {
+    return this.request<MagicLinkResponse>({ method: 'POST', url: '/v1/auth/magic-link', data: body });
+  }
+
+  /**
+   * Redeem magic link
+   *
+   * Sign the user of a magic link in, running the same login hooks as a password login. Each link can be redeemed once.
+   *
+   * `POST /v1/auth/magic-link/verify`
+   */
+  async redeemMagicLink(body: MagicLinkRedeemRequest): Promise<AuthResponse> {
+    return this.request<AuthResponse>({ method: 'POST', url: '/v1/auth/magic-link/verify', data: body });
+  }
+
+  /**
+   * Change password
+   *
+   * Change password for authenticated user
+   *
+   * `POST /v1/auth/password/change`
+   */
+  async changePassword(body: ChangePasswordRequest): Promise<void> {
+    return this.request<void>({ method: 'POST', url: '/v1/auth/password/change', data: body });
+  }
+
+  /**
+   * Request password reset
+   *
+   * Email a password reset to the user. The response does not reveal whether the email is registered.
+   *
+   * `POST /v1/auth/password/reset`
+   */
+  async forgotPassword(body: ForgotPasswordRequest): Promise<void> {
+    return this.request<void>({ method: 'POST', url: '/v1/auth/password/reset', data: body });
+  }
+
+  /**
+   * Reset password
+   *
+   * Set a new password with the token of a password reset email. Identity providers completing resets on their own pages, such as FusionAuth, return PASSWORD_RESET_NOT_SUPPORTED.
+   *
+   * `POST /v1/auth/password/reset/confirm`
+   */
+  async resetPassword(body: ResetPasswordRequest): Promise<void> {
+    return this.request<void>({ method: 'POST', url: '/v1/auth/password/reset/confirm', data: body });
+  }
+
+  /**
+   * Refresh access token
+   *
+   * Obtain a new access token using refresh token
+   *
+   * `POST /v1/auth/refresh`
+   */
+  async refreshToken(body: RefreshTokenRequest): Promise<AuthResponse> {
+    return this.request<AuthResponse>({ method: 'POST', url: '/v1/auth/refresh', data: body });
+  }
+
+  /**
+   * Register a new user
+   *
+   * Create a new user account with email and password
+   *
+   * `POST /v1/auth/register`
+   */
+  async registerUser(body: RegisterRequest): Promise<AuthResponse> {
+    return this.request<AuthResponse>({ method: 'POST', url: '/v1/auth/register', data: body });
+  }
+
+  /**
+   * Exchange token
+   *
+   * Exchange the bearer token for a short-lived access token limited to a subset of its permissions and, optionally, to an audience (RFC 8693), e.g. to pass to a third-party service. The new token records the exchanged token in its act claim and is revoked with it. Exchanged tokens are denied permissions outside their scope and cannot be used for self-service endpoints.
+   *
+   * `POST /v1/auth/token/exchange`
+   */
+  async exchangeToken(body: TokenExchangeRequest): Promise<TokenExchangeResponse> {
+    return this.request<TokenExchangeResponse>({ method: 'POST', url: '/v1/auth/token/exchange', data: body });
+  }
+
+  /**
+   * Check authorization
+   *
+   * Evaluate whether the authenticated user may perform an action on a resource. A Cache-Control header with max-age, max-stale, no-cache or no-store controls use of cached decisions.
+   *
+   * `POST /v1/authz/check`
+   */
+  async checkAuthorization(body: AuthzCheckRequest): Promise<AuthzDecision> {
+    return this.request<AuthzDecision>({ method: 'POST', url: '/v1/authz/check', data: body });
+  }
+
+  /**
+   * Explain authorization decision
+   *
+   * Evaluate whether the authenticated user may perform an action on a resource with OPA's explanation enabled, returning the rules that fired, the input paths they read, the result of each policy module and why the request was denied. Set trace to include OPA's raw evaluation trace. Requires the authz.debug permission; decisions are never cached.
+   *
+   * `POST /v1/authz/explain`
+   */
+  async explainAuthorization(body: AuthzExplainRequest): Promise<AuthzExplanation> {
+    return this.request<AuthzExplanation>({ method: 'POST', url: '/v1/authz/explain', data: body });
+  }
+
+  /**
+   * Simulate authorization decision
+   *
+   * Evaluate what a user of the tenant, or a hypothetical set of roles, may do without acting as them. The context overrides the time, IP address, MFA state and session age of the request. With bundleId the request is also evaluated against a draft policy bundle, and changed reports whether its decision differs from the active policies. Requires the authz.simulate permission; decisions are never cached.
+   *
+   * `POST /v1/authz/simulate`
+   */
+  async simulateAuthorization(body: SimulateRequest): Promise<AuthzSimulation> {
+    return this.request<AuthzSimulation>({ method: 'POST', url: '/v1/authz/simulate', data: body });
+  }
+
+  /**
+   * Get break-glass session
+   *
+   * Describe the break-glass token the request was made with, to check a token before relying on it. Only available when BREAK_GLASS_PUBLIC_KEY_PATH is configured.
+   *
+   * `GET /v1/break-glass/session`
+   */
+  async getBreakGlassSession(): Promise<BreakGlassSession> {
+    return this.request<BreakGlassSession>({ method: 'GET', url: '/v1/break-glass/session' });
+  }
+
+  /**
+   * List bundles
+   *
+   * List the policy bundles of the current tenant
+   *
+   * `GET /v1/bundles`
+   */
+  async listBundles(params?: ListBundlesParams): Promise<ListBundlesResult> {
+    return this.request<ListBundlesResult>({ method: 'GET', url: '/v1/bundles', params });
+  }
+
+  /**
+   * Create bundle
+   *
+   * Create a bundle from policies; it is built asynchronously by a background job whose ID is returned as buildJobId, polled with GET /jobs/{jobId}
+   *
+   * `POST /v1/bundles`
+   */
+  async createBundle(body: CreateBundleRequest): Promise<PolicyBundle> {
+    return this.request<PolicyBundle>({ method: 'POST', url: '/v1/bundles', data: body });
+  }
+
+  /**
+   * Get bundle
+   *
+   * `GET /v1/bundles/{id}`
+   */
+  async getBundle(id: string): Promise<PolicyBundle> {
+    return this.request<PolicyBundle>({ method: 'GET', url: `/v1/bundles/${encodeURIComponent(id)}` });
+  }
+
+  /**
+   * Delete bundle
+   *
+   * Delete an inactive bundle. Must be confirmed with a bundles.delete action nonce
+   *
+   * `DELETE /v1/bundles/{id}`
+   */
+  async deleteBundle(id: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/bundles/${encodeURIComponent(id)}` });
+  }
+
+  /**
+   * Activate bundle
+   *
+   * Make a built bundle the active bundle of its tenant, or roll back to an earlier one. Must be confirmed with a bundles.activate action nonce
+   *
+   * `POST /v1/bundles/{id}/activate`
+   */
+  async activateBundle(id: string): Promise<PolicyBundle> {
+    return this.request<PolicyBundle>({ method: 'POST', url: `/v1/bundles/${encodeURIComponent(id)}/activate` });
+  }
+
+  /**
+   * List bundle contents
+   *
+   * List the files inside a built bundle as it was uploaded, with their sizes, SHA-256 checksums and the policies they were built from
+   *
+   * `GET /v1/bundles/{id}/contents`
+   */
+  async listBundleContents(id: string): Promise<BundleContents> {
+    return this.request<BundleContents>({ method: 'GET', url: `/v1/bundles/${encodeURIComponent(id)}/contents` });
+  }
+
+  /**
+   * Deploy bundle
+   *
+   * Deploy a built bundle to an environment (default production)
+   *
+   * `POST /v1/bundles/{id}/deploy`
+   */
+  async deployBundle(id: string, body?: DeployBundleRequest): Promise<BundleDeployment> {
+    return this.request<BundleDeployment>({ method: 'POST', url: `/v1/bundles/${encodeURIComponent(id)}/deploy`, data: body });
+  }
+
+  /**
+   * List bundle deployments
+   *
+   * List the deployments of a bundle, newest first, with the manifest revision and roots each deployment shipped
+   *
+   * `GET /v1/bundles/{id}/deployments`
+   */
+  async listBundleDeployments(id: string): Promise<BundleDeployment[]> {
+    return this.request<BundleDeployment[]>({ method: 'GET', url: `/v1/bundles/${encodeURIComponent(id)}/deployments` });
+  }
+
+  /**
+   * Get bundle download URL
+   *
+   * Get a time-limited presigned URL downloading a built bundle directly from MinIO, S3 or GCS instead of through the API. Not available with local bundle storage.
+   *
+   * `GET /v1/bundles/{id}/download-url`
+   */
+  async getBundleDownloadURL(id: string, params?: GetBundleDownloadURLParams): Promise<BundleDownloadURL> {
+    return this.request<BundleDownloadURL>({ method: 'GET', url: `/v1/bundles/${encodeURIComponent(id)}/download-url`, params });
+  }
+
+  /**
+   * List jobs
+   *
+   * List the background jobs of the caller's tenant. Filter by status dead to find jobs that failed permanently or ran out of attempts. Requires the jobs.read permission.
+   *
+   * `GET /v1/jobs`
+   */
+  async listJobs(params?: ListJobsParams): Promise<ListJobsResult> {
+    return this.request<ListJobsResult>({ method: 'GET', url: '/v1/jobs', params });
+  }
+
+  /**
+   * Get job
+   *
+   * Get a background job of the caller's tenant, or one the caller started, to poll the outcome of an asynchronous operation such as a bundle build. Failed jobs are retried with exponential backoff until they run out of attempts and are marked dead. Requires the jobs.read permission.
+   *
+   * `GET /v1/jobs/{jobId}`
+   */
+  async getJob(jobId: string): Promise<Job> {
+    return this.request<Job>({ method: 'GET', url: `/v1/jobs/${encodeURIComponent(jobId)}` });
+  }
+
+  /**
+   * Report decision logs
+   *
+   * Decision log API for OPA instances, e.g. sidecars running Heimdall's bundles. Accepts a batch of decision events as OPA's decision log plugin sends them, gzip compressed with Content-Encoding: gzip or plain, and stores them in the audit log of the caller's tenant attributed to the reporting instance. Requires decision_logs.write.
+   *
+   * `POST /v1/logs`
+   */
+  async reportDecisionLogs(body: DecisionEvent[]): Promise<ReportDecisionLogsResult> {
+    return this.request<ReportDecisionLogsResult>({ method: 'POST', url: '/v1/logs', data: body });
+  }
+
+  /**
+   * Get device authorization
+   *
+   * Look up the device authorization of a user code, so the verification page can show which client asks to sign in
+   *
+   * `GET /v1/oauth/device`
+   */
+  async getDeviceAuthorization(params?: GetDeviceAuthorizationParams): Promise<DeviceAuthorizationResponse> {
+    return this.request<DeviceAuthorizationResponse>({ method: 'GET', url: '/v1/oauth/device', params });
+  }
+
+  /**
+   * Approve device
+   *
+   * Approve the device authorization of a user code. At its next token request the device is signed in as the current user.
+   *
+   * `POST /v1/oauth/device/approve`
+   */
+  async approveDeviceAuthorization(body: DeviceVerificationRequest): Promise<DeviceAuthorizationResponse> {
+    return this.request<DeviceAuthorizationResponse>({ method: 'POST', url: '/v1/oauth/device/approve', data: body });
+  }
+
+  /**
+   * Deny device
+   *
+   * Deny the device authorization of a user code. The device's next token request fails with access_denied.
+   *
+   * `POST /v1/oauth/device/deny`
+   */
+  async denyDeviceAuthorization(body: DeviceVerificationRequest): Promise<DeviceAuthorizationResponse> {
+    return this.request<DeviceAuthorizationResponse>({ method: 'POST', url: '/v1/oauth/device/deny', data: body });
+  }
+
+  /**
+   * List OPA instances
+   *
+   * List the OPA instances reporting their status to the caller's tenant, most recently seen first, with the bundle revisions they have loaded and whether each is the latest build of its Heimdall bundle, their plugin states, and whether they are healthy or have stopped reporting
+   *
+   * `GET /v1/opa-instances`
+   */
+  async listOPAInstances(params?: ListOPAInstancesParams): Promise<ListOPAInstancesResult> {
+    return this.request<ListOPAInstancesResult>({ method: 'GET', url: '/v1/opa-instances', params });
+  }
+
+  /**
+   * Get permission
+   *
+   * `GET /v1/permissions/{name}`
+   */
+  async getPermission(name: string): Promise<Permission> {
+    return this.request<Permission>({ method: 'GET', url: `/v1/permissions/${encodeURIComponent(name)}` });
+  }
+
+  /**
+   * Upsert permission
+   *
+   * Create or replace a permission by name. Send If-Match with a previously returned ETag to update only an unchanged permission, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/permissions/{name}`
+   */
+  async upsertPermission(name: string, body: UpsertPermissionRequest): Promise<Permission> {
+    return this.request<Permission>({ method: 'PUT', url: `/v1/permissions/${encodeURIComponent(name)}`, data: body });
+  }
+
+  /**
+   * Delete permission
+   *
+   * Delete a permission and remove it from all roles
+   *
+   * `DELETE /v1/permissions/{name}`
+   */
+  async deletePermission(name: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/permissions/${encodeURIComponent(name)}` });
+  }
+
+  /**
+   * List policies
+   *
+   * List the policies of the current tenant
+   *
+   * `GET /v1/policies`
+   */
+  async listPolicies(params?: ListPoliciesParams): Promise<ListPoliciesResult> {
+    return this.request<ListPoliciesResult>({ method: 'GET', url: '/v1/policies', params });
+  }
+
+  /**
+   * Create policy
+   *
+   * Create a draft policy in the current tenant
+   *
+   * `POST /v1/policies`
+   */
+  async createPolicy(body: CreatePolicyRequest): Promise<Policy> {
+    return this.request<Policy>({ method: 'POST', url: '/v1/policies', data: body });
+  }
+
+  /**
+   * Compile policy rules
+   *
+   * Compile the structured rules of a JSON policy into the Rego module Heimdall loads for it, without saving them
+   *
+   * `POST /v1/policies/compile`
+   */
+  async compilePolicyRules(body: PolicyRuleSet): Promise<CompilePolicyRulesResult> {
+    return this.request<CompilePolicyRulesResult>({ method: 'POST', url: '/v1/policies/compile', data: body });
+  }
+
+  /**
+   * List deleted policies
+   *
+   * List the deleted policies of the current tenant that can still be restored
+   *
+   * `GET /v1/policies/deleted`
+   */
+  async listDeletedPolicies(params?: ListDeletedPoliciesParams): Promise<ListDeletedPoliciesResult> {
+    return this.request<ListDeletedPoliciesResult>({ method: 'GET', url: '/v1/policies/deleted', params });
+  }
+
+  /**
+   * Export policies
+   *
+   * Export the tenant's Rego policies as files, sorted by path
+   *
+   * `GET /v1/policies/export`
+   */
+  async exportPolicies(): Promise<ExportPoliciesResult> {
+    return this.request<ExportPoliciesResult>({ method: 'GET', url: '/v1/policies/export' });
+  }
+
+  /**
+   * Format policy
+   *
+   * Format a Rego module with opa fmt without saving it. changed is true when the module was not formatted.
+   *
+   * `POST /v1/policies/format`
+   */
+  async formatPolicy(body: PolicyContentRequest): Promise<PolicyFormatResult> {
+    return this.request<PolicyFormatResult>({ method: 'POST', url: '/v1/policies/format', data: body });
+  }
+
+  /**
+   * Create policy from template
+   *
+   * Create a draft Rego policy in the current tenant by filling in the variables of a policy template. The template and variables are recorded in the policy's metadata.
+   *
+   * `POST /v1/policies/from-template`
+   */
+  async createPolicyFromTemplate(body: CreatePolicyFromTemplateRequest): Promise<Policy> {
+    return this.request<Policy>({ method: 'POST', url: '/v1/policies/from-template', data: body });
+  }
+
+  /**
+   * Lint policy
+   *
+   * Check a Rego module without saving it for allow rules without conditions (error), allow rules without a default deny and unused local variables (warnings)
+   *
+   * `POST /v1/policies/lint`
+   */
+  async lintPolicy(body: PolicyContentRequest): Promise<LintPolicyResult> {
+    return this.request<LintPolicyResult>({ method: 'POST', url: '/v1/policies/lint', data: body });
+  }
+
+  /**
+   * Get policy by path
+   *
+   * `GET /v1/policies/path/{path}`
+   */
+  async getPolicyByPath(path: string): Promise<Policy> {
+    return this.request<Policy>({ method: 'GET', url: `/v1/policies/path/${encodeURIComponent(path)}` });
+  }
+
+  /**
+   * Upsert policy by path
+   *
+   * Create or replace the policy at the given path; content changes create a new version. Send If-Match with a previously returned ETag to update only an unchanged policy, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/policies/path/{path}`
+   */
+  async upsertPolicy(path: string, body: UpsertPolicyRequest): Promise<Policy> {
+    return this.request<Policy>({ method: 'PUT', url: `/v1/policies/path/${encodeURIComponent(path)}`, data: body });
+  }
+
+  /**
+   * Sync policies
+   *
+   * Create, update and optionally delete the tenant's Rego policies to match a set of files, matched by path. With dryRun the changes and their diffs are reported without being applied.
+   *
+   * `POST /v1/policies/sync`
+   */
+  async syncPolicies(body: SyncPoliciesRequest): Promise<PolicySyncResult> {
+    return this.request<PolicySyncResult>({ method: 'POST', url: '/v1/policies/sync', data: body });
+  }
+
+  /**
+   * Run policy test suite
+   *
+   * Load a set of policies into OPA together without saving them, so they can import each other, and run the test cases of each against its package. exitCode is 0 when every test passed, 1 when a test failed and 2 when a policy did not compile; failing suites are answered with 422 so CI steps gate on them.
+   *
+   * `POST /v1/policies/test-suite`
+   */
+  async runPolicyTestSuite(body: PolicyTestSuiteRequest): Promise<PolicyTestSuiteResult> {
+    return this.request<PolicyTestSuiteResult>({ method: 'POST', url: '/v1/policies/test-suite', data: body });
+  }
+
+  /**
+   * Get policy
+   *
+   * `GET /v1/policies/{id}`
+   */
+  async getPolicy(id: string): Promise<Policy> {
+    return this.request<Policy>({ method: 'GET', url: `/v1/policies/${encodeURIComponent(id)}` });
+  }
+
+  /**
+   * Update policy
+   *
+   * Update a policy; content changes create a new version
+   *
+   * `PUT /v1/policies/{id}`
+   */
+  async updatePolicy(id: string, body: UpdatePolicyRequest): Promise<Policy> {
+    return this.request<Policy>({ method: 'PUT', url: `/v1/policies/${encodeURIComponent(id)}`, data: body });
+  }
+
+  /**
+   * Delete policy
+   *
+   * Soft delete a policy; it can be restored until it is purged. Policies included in a ready or active bundle cannot be deleted, failing with POLICY_IN_BUNDLE and the bundles in the error details.
+   *
+   * `DELETE /v1/policies/{id}`
+   */
+  async deletePolicy(id: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/policies/${encodeURIComponent(id)}` });
+  }
+
+  /**
+   * Archive policy
+   *
+   * Mark a policy archived. Archiving a policy whose package other policies import, with no other published policy providing it, succeeds with a POLICY_HAS_DEPENDENTS warning listing them.
+   *
+   * `POST /v1/policies/{id}/archive`
+   */
+  async archivePolicy(id: string): Promise<Policy> {
+    return this.request<Policy>({ method: 'POST', url: `/v1/policies/${encodeURIComponent(id)}/archive` });
+  }
+
+  /**
+   * Get policy dependencies
+   *
+   * List the packages a policy imports, resolved to the policies of the tenant defining them, and the policies importing its package. Imports no policy of the tenant defines, such as synced data, have no package.
+   *
+   * `GET /v1/policies/{id}/dependencies`
+   */
+  async getPolicyDependencies(id: string): Promise<PolicyDependencies> {
+    return this.request<PolicyDependencies>({ method: 'GET', url: `/v1/policies/${encodeURIComponent(id)}/dependencies` });
+  }
+
+  /**
+   * Publish policy
+   *
+   * Validate a policy and mark it active. Policies importing packages of the tenant that no active, valid policy provides cannot be published, failing with POLICY_DEPENDENCY_UNPUBLISHED and the imports in the error details.
+   *
+   * `POST /v1/policies/{id}/publish`
+   */
+  async publishPolicy(id: string): Promise<Policy> {
+    return this.request<Policy>({ method: 'POST', url: `/v1/policies/${encodeURIComponent(id)}/publish` });
+  }
+
+  /**
+   * Purge policy
+   *
+   * Permanently delete a deleted policy with its versions, removing it from the inactive and failed bundles that included it. Must be confirmed with a policies.purge action nonce
+   *
+   * `DELETE /v1/policies/{id}/purge`
+   */
+  async purgePolicy(id: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/policies/${encodeURIComponent(id)}/purge` });
+  }
+
+  /**
+   * Restore policy
+   *
+   * Restore a deleted policy that has not been purged, with the status and content it was deleted with
+   *
+   * `POST /v1/policies/{id}/restore`
+   */
+  async restorePolicy(id: string): Promise<Policy> {
+    return this.request<Policy>({ method: 'POST', url: `/v1/policies/${encodeURIComponent(id)}/restore` });
+  }
+
+  /**
+   * Test policy
+   *
+   * Run the policy's test cases
+   *
+   * `POST /v1/policies/{id}/test`
+   */
+  async testPolicy(id: string): Promise<PolicyTestResult[]> {
+    return this.request<PolicyTestResult[]>({ method: 'POST', url: `/v1/policies/${encodeURIComponent(id)}/test` });
+  }
+
+  /**
+   * Validate policy
+   *
+   * Compile a policy without publishing it
+   *
+   * `POST /v1/policies/{id}/validate`
+   */
+  async validatePolicy(id: string): Promise<void> {
+    return this.request<void>({ method: 'POST', url: `/v1/policies/${encodeURIComponent(id)}/validate` });
+  }
+
+  /**
+   * List policy versions
+   *
+   * `GET /v1/policies/{id}/versions`
+   */
+  async getPolicyVersions(id: string): Promise<PolicyVersion[]> {
+    return this.request<PolicyVersion[]>({ method: 'GET', url: `/v1/policies/${encodeURIComponent(id)}/versions` });
+  }
+
+  /**
+   * List policy templates
+   *
+   * List the library of parameterized policy templates for common authorization patterns
+   *
+   * `GET /v1/policy-templates`
+   */
+  async listPolicyTemplates(): Promise<PolicyTemplate[]> {
+    return this.request<PolicyTemplate[]>({ method: 'GET', url: '/v1/policy-templates' });
+  }
+
+  /**
+   * List resources
+   *
+   * List the resources registered in the caller's tenant
+   *
+   * `GET /v1/resources`
+   */
+  async listResources(params?: ListResourcesParams): Promise<ListResourcesResult> {
+    return this.request<ListResourcesResult>({ method: 'GET', url: '/v1/resources', params });
+  }
+
+  /**
+   * Get resource
+   *
+   * Get a resource of the caller's tenant. Resources of Heimdall's own types (users, roles, policies, bundles and tenants) are derived from the database and marked derived; only registered resources have an ETag.
+   *
+   * `GET /v1/resources/{resourceType}/{resourceId}`
+   */
+  async getResource(resourceType: string, resourceId: string): Promise<Resource> {
+    return this.request<Resource>({ method: 'GET', url: `/v1/resources/${encodeURIComponent(resourceType)}/${encodeURIComponent(resourceId)}` });
+  }
+
+  /**
+   * Upsert resource
+   *
+   * Register a resource of the caller's tenant or replace its owner, labels and attributes. Authorization inputs for the resource then carry its owner as input.resource.ownerId, and its labels and attributes in input.resource.attributes. Heimdall's own types cannot be registered. Send If-Match with a previously returned ETag to update only an unchanged resource, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/resources/{resourceType}/{resourceId}`
+   */
+  async upsertResource(resourceType: string, resourceId: string, body: UpsertResourceRequest): Promise<Resource> {
+    return this.request<Resource>({ method: 'PUT', url: `/v1/resources/${encodeURIComponent(resourceType)}/${encodeURIComponent(resourceId)}`, data: body });
+  }
+
+  /**
+   * Delete resource
+   *
+   * Remove a registered resource. Policies no longer receive its owner and labels.
+   *
+   * `DELETE /v1/resources/{resourceType}/{resourceId}`
+   */
+  async deleteResource(resourceType: string, resourceId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/resources/${encodeURIComponent(resourceType)}/${encodeURIComponent(resourceId)}` });
+  }
+
+  /**
+   * Get role
+   *
+   * Get a role of the current tenant by name, with its permissions
+   *
+   * `GET /v1/roles/{name}`
+   */
+  async getRole(name: string): Promise<RoleResponse> {
+    return this.request<RoleResponse>({ method: 'GET', url: `/v1/roles/${encodeURIComponent(name)}` });
+  }
+
+  /**
+   * Upsert role
+   *
+   * Create or replace a role of the current tenant by name; its permissions are replaced by the listed ones. Send If-Match with a previously returned ETag to update only an unchanged role, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/roles/{name}`
+   */
+  async upsertRole(name: string, body: UpsertRoleRequest): Promise<RoleResponse> {
+    return this.request<RoleResponse>({ method: 'PUT', url: `/v1/roles/${encodeURIComponent(name)}`, data: body });
+  }
+
+  /**
+   * Delete role
+   *
+   * Delete a role of the current tenant and remove it from all users
+   *
+   * `DELETE /v1/roles/{name}`
+   */
+  async deleteRole(name: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/roles/${encodeURIComponent(name)}` });
+  }
+
+  /**
+   * List platform signing keys
+   *
+   * List the keys that sign and verify tokens not bound to a tenant's own keys, newest first. The active key signs new tokens; retired keys still verify the tokens they signed until they expire once the longest token lifetime has passed since their retirement. Requires signing_keys.read.
+   *
+   * `GET /v1/signing-keys`
+   */
+  async listPlatformSigningKeys(): Promise<PlatformSigningKey[]> {
+    return this.request<PlatformSigningKey[]>({ method: 'GET', url: '/v1/signing-keys' });
+  }
+
+  /**
+   * Rotate the platform signing key
+   *
+   * Generate a new platform signing key and make it the active key. The previous key, including the key read from JWT_PRIVATE_KEY_PATH on the first rotation, is retired: it keeps verifying the tokens it signed until they expire, so active sessions are not invalidated. Other instances pick up the new key within JWT_KEY_REFRESH_SECONDS. Requires signing_keys.rotate.
+   *
+   * `POST /v1/signing-keys/rotate`
+   */
+  async rotatePlatformSigningKey(): Promise<PlatformSigningKey> {
+    return this.request<PlatformSigningKey>({ method: 'POST', url: '/v1/signing-keys/rotate' });
+  }
+
+  /**
+   * Report OPA status
+   *
+   * Status API for OPA instances, e.g. sidecars running Heimdall's bundles. Accepts a status update as OPA's status plugin sends it and records the instance, identified by labels.id, with its loaded bundle revisions and plugin states. Requires opa_instances.report.
+   *
+   * `POST /v1/status`
+   */
+  async reportOPAStatus(body: OPAStatusReport): Promise<void> {
+    return this.request<void>({ method: 'POST', url: '/v1/status', data: body });
+  }
+
+  /**
+   * List tenants
+   *
+   * Get all tenants (admin only)
+   *
+   * `GET /v1/tenants`
+   */
+  async listTenants(params?: ListTenantsParams): Promise<ListTenantsResult> {
+    return this.request<ListTenantsResult>({ method: 'GET', url: '/v1/tenants', params });
+  }
+
+  /**
+   * Create tenant
+   *
+   * Create a new tenant (admin only)
+   *
+   * `POST /v1/tenants`
+   */
+  async createTenant(body: CreateTenantRequest): Promise<TenantResponse> {
+    return this.request<TenantResponse>({ method: 'POST', url: '/v1/tenants', data: body });
+  }
+
+  /**
+   * Get tenant by slug
+   *
+   * Get tenant details by slug
+   *
+   * `GET /v1/tenants/slug/{slug}`
+   */
+  async getTenantBySlug(slug: string): Promise<TenantResponse> {
+    return this.request<TenantResponse>({ method: 'GET', url: `/v1/tenants/slug/${encodeURIComponent(slug)}` });
+  }
+
+  /**
+   * Upsert tenant by slug
+   *
+   * Create or replace the tenant with the given slug. Send If-Match with a previously returned ETag to update only an unchanged tenant, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/tenants/slug/{slug}`
+   */
+  async upsertTenant(slug: string, body: UpsertTenantRequest): Promise<TenantResponse> {
+    return this.request<TenantResponse>({ method: 'PUT', url: `/v1/tenants/slug/${encodeURIComponent(slug)}`, data: body });
+  }
+
+  /**
+   * Get public tenant information
+   *
+   * Get the name, branding and login methods of an active tenant without authentication, so hosted login pages can render the tenant's login. Login methods are those the tenant's loginMethods setting allows, all by default, that are available: saml requires an enabled SAML configuration. Responses may be cached for five minutes and revalidated with If-None-Match.
+   *
+   * `GET /v1/tenants/slug/{slug}/public`
+   */
+  async getPublicTenant(slug: string): Promise<PublicTenant> {
+    return this.request<PublicTenant>({ method: 'GET', url: `/v1/tenants/slug/${encodeURIComponent(slug)}/public` });
+  }
+
+  /**
+   * Get tenant by ID
+   *
+   * Get specific tenant details
+   *
+   * `GET /v1/tenants/{tenantId}`
+   */
+  async getTenantById(tenantId: string): Promise<TenantResponse> {
+    return this.request<TenantResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}` });
+  }
+
+  /**
+   * Update tenant
+   *
+   * Update tenant details (admin only)
+   *
+   * `PATCH /v1/tenants/{tenantId}`
+   */
+  async updateTenant(tenantId: string, body: UpdateTenantRequest): Promise<TenantResponse> {
+    return this.request<TenantResponse>({ method: 'PATCH', url: `/v1/tenants/${encodeURIComponent(tenantId)}`, data: body });
+  }
+
+  /**
+   * Delete tenant
+   *
+   * Delete a tenant. Must be confirmed with a tenants.delete action nonce (admin only)
+   *
+   * `DELETE /v1/tenants/{tenantId}`
+   */
+  async deleteTenant(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}` });
+  }
+
+  /**
+   * Activate tenant
+   *
+   * Activate a suspended tenant. Requires a platform-scoped token.
+   *
+   * `POST /v1/tenants/{tenantId}/activate`
+   */
+  async activateTenant(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'POST', url: `/v1/tenants/${encodeURIComponent(tenantId)}/activate` });
+  }
+
+  /**
+   * Get tenant claims template
+   *
+   * Get the template controlling which roles, permissions, tenant metadata and custom claims are embedded in the tenant's access tokens
+   *
+   * `GET /v1/tenants/{tenantId}/claims-template`
+   */
+  async getTenantClaimsTemplate(tenantId: string): Promise<ClaimsTemplateResponse> {
+    return this.request<ClaimsTemplateResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/claims-template` });
+  }
+
+  /**
+   * Upsert tenant claims template
+   *
+   * Create or replace a tenant's claims template. It applies to access tokens issued at the next login or refresh. Tokens larger than maxTokenSize drop their permissions and set permissionsOmitted, leaving permission checks to the server, then drop tenant metadata and custom claims. Send If-Match with a previously returned ETag to update only an unchanged template, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/tenants/{tenantId}/claims-template`
+   */
+  async upsertTenantClaimsTemplate(tenantId: string, body: UpsertClaimsTemplateRequest): Promise<ClaimsTemplateResponse> {
+    return this.request<ClaimsTemplateResponse>({ method: 'PUT', url: `/v1/tenants/${encodeURIComponent(tenantId)}/claims-template`, data: body });
+  }
+
+  /**
+   * Delete tenant claims template
+   *
+   * Delete a tenant's claims template, restoring the default access token claims
+   *
+   * `DELETE /v1/tenants/{tenantId}/claims-template`
+   */
+  async deleteTenantClaimsTemplate(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/claims-template` });
+  }
+
+  /**
+   * Get tenant default roles
+   *
+   * Get the roles given to users when they register with a tenant
+   *
+   * `GET /v1/tenants/{tenantId}/default-roles`
+   */
+  async getTenantDefaultRoles(tenantId: string): Promise<DefaultRolesResponse> {
+    return this.request<DefaultRolesResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/default-roles` });
+  }
+
+  /**
+   * Update tenant default roles
+   *
+   * Add roles of the tenant to and remove roles from the roles given to users when they register, stored in the tenant's defaultRoles setting. A tenant has at most 20 default roles. Users registered before keep their roles.
+   *
+   * `PATCH /v1/tenants/{tenantId}/default-roles`
+   */
+  async updateTenantDefaultRoles(tenantId: string, body: UpdateDefaultRolesRequest): Promise<DefaultRolesResponse> {
+    return this.request<DefaultRolesResponse>({ method: 'PATCH', url: `/v1/tenants/${encodeURIComponent(tenantId)}/default-roles`, data: body });
+  }
+
+  /**
+   * Get tenant IP access lists
+   *
+   * Get the address ranges a tenant admits and rejects API requests and logins from
+   *
+   * `GET /v1/tenants/{tenantId}/ip-access`
+   */
+  async getTenantIPAccess(tenantId: string): Promise<IPAccessResponse> {
+    return this.request<IPAccessResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/ip-access` });
+  }
+
+  /**
+   * Upsert tenant IP access lists
+   *
+   * Create or replace a tenant's IP access lists of CIDR ranges or single addresses, stored in the tenant's ipAccess setting. Requests carrying the tenant's tokens or X-Tenant-ID header, and logins of its users, from a denylisted address or from outside a non-empty allowlist fail with 403 IP_NOT_ALLOWED before authentication. The lists are also passed to policies as input.tenant.settings.ipAccess. Lists that would reject the address of this request fail with IP_ACCESS_LOCKOUT. Send If-Match with a previously returned ETag to update only unchanged lists, or If-None-Match: * to only create them.
+   *
+   * `PUT /v1/tenants/{tenantId}/ip-access`
+   */
+  async upsertTenantIPAccess(tenantId: string, body: UpsertIPAccessRequest): Promise<IPAccessResponse> {
+    return this.request<IPAccessResponse>({ method: 'PUT', url: `/v1/tenants/${encodeURIComponent(tenantId)}/ip-access`, data: body });
+  }
+
+  /**
+   * Delete tenant IP access lists
+   *
+   * Delete a tenant's IP access lists, admitting requests from any address
+   *
+   * `DELETE /v1/tenants/{tenantId}/ip-access`
+   */
+  async deleteTenantIPAccess(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/ip-access` });
+  }
+
+  /**
+   * List OAuth clients
+   *
+   * List the OAuth clients of a tenant. Client secrets are never returned.
+   *
+   * `GET /v1/tenants/{tenantId}/oauth-clients`
+   */
+  async listTenantOAuthClients(tenantId: string): Promise<OAuthClientResponse[]> {
+    return this.request<OAuthClientResponse[]>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/oauth-clients` });
+  }
+
+  /**
+   * Create OAuth client
+   *
+   * Register an OAuth client that obtains access tokens for the given permission scopes with the client credentials grant at /oauth/token, e.g. for backend jobs. The client secret is only returned in this response. Client tokens cannot manage OAuth clients.
+   *
+   * `POST /v1/tenants/{tenantId}/oauth-clients`
+   */
+  async createTenantOAuthClient(tenantId: string, body: CreateOAuthClientRequest): Promise<OAuthClientResponse> {
+    return this.request<OAuthClientResponse>({ method: 'POST', url: `/v1/tenants/${encodeURIComponent(tenantId)}/oauth-clients`, data: body });
+  }
+
+  /**
+   * Get OAuth client
+   *
+   * Get an OAuth client of a tenant
+   *
+   * `GET /v1/tenants/{tenantId}/oauth-clients/{clientId}`
+   */
+  async getTenantOAuthClient(tenantId: string, clientId: string): Promise<OAuthClientResponse> {
+    return this.request<OAuthClientResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/oauth-clients/${encodeURIComponent(clientId)}` });
+  }
+
+  /**
+   * Update OAuth client
+   *
+   * Change the name or scopes of an OAuth client. Tokens already issued keep their scope until they expire.
+   *
+   * `PATCH /v1/tenants/{tenantId}/oauth-clients/{clientId}`
+   */
+  async updateTenantOAuthClient(tenantId: string, clientId: string, body: UpdateOAuthClientRequest): Promise<OAuthClientResponse> {
+    return this.request<OAuthClientResponse>({ method: 'PATCH', url: `/v1/tenants/${encodeURIComponent(tenantId)}/oauth-clients/${encodeURIComponent(clientId)}`, data: body });
+  }
+
+  /**
+   * Delete OAuth client
+   *
+   * Delete an OAuth client. Tokens already issued to it stay valid until they expire.
+   *
+   * `DELETE /v1/tenants/{tenantId}/oauth-clients/{clientId}`
+   */
+  async deleteTenantOAuthClient(tenantId: string, clientId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/oauth-clients/${encodeURIComponent(clientId)}` });
+  }
+
+  /**
+   * Rotate OAuth client secret
+   *
+   * Issue a new secret for an OAuth client, returned only in this response. The previous secret stays valid for the optional grace period so deployments can switch over.
+   *
+   * `POST /v1/tenants/{tenantId}/oauth-clients/{clientId}/rotate-secret`
+   */
+  async rotateTenantOAuthClientSecret(tenantId: string, clientId: string, body?: RotateOAuthClientSecretRequest): Promise<OAuthClientResponse> {
+    return this.request<OAuthClientResponse>({ method: 'POST', url: `/v1/tenants/${encodeURIComponent(tenantId)}/oauth-clients/${encodeURIComponent(clientId)}/rotate-secret`, data: body });
+  }
+
+  /**
+   * Get password policy
+   *
+   * Get the effective password policy of a tenant, so sign-up and password forms can check new passwords before submitting them
+   *
+   * `GET /v1/tenants/{tenantId}/password-policy`
+   */
+  async getPasswordPolicy(tenantId: string): Promise<PasswordPolicy> {
+    return this.request<PasswordPolicy>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/password-policy` });
+  }
+
+  /**
+   * Get tenant rate limits
+   *
+   * Get a tenant's quotas of requests per minute by route class, along with the per-IP limits of the route classes
+   *
+   * `GET /v1/tenants/{tenantId}/rate-limits`
+   */
+  async getTenantRateLimits(tenantId: string): Promise<RateLimitsResponse> {
+    return this.request<RateLimitsResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/rate-limits` });
+  }
+
+  /**
+   * Upsert tenant rate limits
+   *
+   * Create or replace a tenant's quotas. A quota counts the requests of all of the tenant's users and clients to a route class (auth, authz or default) per minute, on top of the per-IP limits. Requests over the quota fail with 429 TENANT_RATE_LIMIT_EXCEEDED and a Retry-After header. Requires the rate_limits update permission. Send If-Match with a previously returned ETag to update only unchanged quotas, or If-None-Match: * to only create them.
+   *
+   * `PUT /v1/tenants/{tenantId}/rate-limits`
+   */
+  async upsertTenantRateLimits(tenantId: string, body: UpsertRateLimitsRequest): Promise<RateLimitsResponse> {
+    return this.request<RateLimitsResponse>({ method: 'PUT', url: `/v1/tenants/${encodeURIComponent(tenantId)}/rate-limits`, data: body });
+  }
+
+  /**
+   * Delete tenant rate limits
+   *
+   * Delete a tenant's quotas, leaving only the per-IP rate limits
+   *
+   * `DELETE /v1/tenants/{tenantId}/rate-limits`
+   */
+  async deleteTenantRateLimits(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/rate-limits` });
+  }
+
+  /**
+   * Get tenant SAML configuration
+   *
+   * Get a tenant's SAML identity provider settings and the service provider URLs to register with the identity provider
+   *
+   * `GET /v1/tenants/{tenantId}/saml`
+   */
+  async getTenantSAMLConfig(tenantId: string): Promise<SAMLConfigResponse> {
+    return this.request<SAMLConfigResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/saml` });
+  }
+
+  /**
+   * Upsert tenant SAML configuration
+   *
+   * Create or replace a tenant's SAML configuration from its identity provider metadata. Users signing in with SAML are provisioned by email and receive the roles mapped from the role attribute. Send If-Match with a previously returned ETag to update only an unchanged configuration, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/tenants/{tenantId}/saml`
+   */
+  async upsertTenantSAMLConfig(tenantId: string, body: UpsertSAMLConfigRequest): Promise<SAMLConfigResponse> {
+    return this.request<SAMLConfigResponse>({ method: 'PUT', url: `/v1/tenants/${encodeURIComponent(tenantId)}/saml`, data: body });
+  }
+
+  /**
+   * Delete tenant SAML configuration
+   *
+   * Delete a tenant's SAML configuration, disabling SAML logins for the tenant
+   *
+   * `DELETE /v1/tenants/{tenantId}/saml`
+   */
+  async deleteTenantSAMLConfig(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/saml` });
+  }
+
+  /**
+   * List tenant signing keys
+   *
+   * List the keys signing a tenant's tokens
+   *
+   * `GET /v1/tenants/{tenantId}/signing-keys`
+   */
+  async listTenantSigningKeys(tenantId: string): Promise<TenantSigningKey[]> {
+    return this.request<TenantSigningKey[]>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/signing-keys` });
+  }
+
+  /**
+   * Create tenant signing key
+   *
+   * Issue a new active signing key for a tenant, retiring the current one
+   *
+   * `POST /v1/tenants/{tenantId}/signing-keys`
+   */
+  async createTenantSigningKey(tenantId: string): Promise<TenantSigningKey> {
+    return this.request<TenantSigningKey>({ method: 'POST', url: `/v1/tenants/${encodeURIComponent(tenantId)}/signing-keys` });
+  }
+
+  /**
+   * Migrate to tenant signing keys
+   *
+   * Move a tenant from the shared platform key to signing keys of its own by issuing its first key
+   *
+   * `POST /v1/tenants/{tenantId}/signing-keys/migrate`
+   */
+  async migrateTenantSigningKeys(tenantId: string): Promise<TenantSigningKey> {
+    return this.request<TenantSigningKey>({ method: 'POST', url: `/v1/tenants/${encodeURIComponent(tenantId)}/signing-keys/migrate` });
+  }
+
+  /**
+   * Revoke tenant signing key
+   *
+   * Revoke a tenant signing key. Tokens it signed are rejected as soon as each instance's key cache refreshes.
+   *
+   * `DELETE /v1/tenants/{tenantId}/signing-keys/{kid}`
+   */
+  async revokeTenantSigningKey(tenantId: string, kid: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/signing-keys/${encodeURIComponent(kid)}` });
+  }
+
+  /**
+   * Get tenant statistics
+   *
+   * Get statistics for a tenant
+   *
+   * `GET /v1/tenants/{tenantId}/stats`
+   */
+  async getTenantStats(tenantId: string): Promise<Record<string, any>> {
+    return this.request<Record<string, any>>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/stats` });
+  }
+
+  /**
+   * Suspend tenant
+   *
+   * Suspend a tenant, rejecting the logins and tokens of its users until it is activated again. Requires a platform-scoped token.
+   *
+   * `POST /v1/tenants/{tenantId}/suspend`
+   */
+  async suspendTenant(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'POST', url: `/v1/tenants/${encodeURIComponent(tenantId)}/suspend` });
+  }
+
+  /**
+   * Get tenant user attribute schema
+   *
+   * Get the typed attributes a tenant defines for its users
+   *
+   * `GET /v1/tenants/{tenantId}/user-attributes`
+   */
+  async getUserAttributeSchema(tenantId: string): Promise<UserAttributeSchemaResponse> {
+    return this.request<UserAttributeSchemaResponse>({ method: 'GET', url: `/v1/tenants/${encodeURIComponent(tenantId)}/user-attributes` });
+  }
+
+  /**
+   * Upsert tenant user attribute schema
+   *
+   * Create or replace the typed attributes of a tenant's users, stored in the tenant's userAttributes setting. User metadata is validated against the schema, and the attributes of the authenticated user are passed to policies as input.user.metadata. Only attributes marked userEditable can be changed by users through their own profile. Send If-Match with a previously returned ETag to update only an unchanged schema, or If-None-Match: * to only create it.
+   *
+   * `PUT /v1/tenants/{tenantId}/user-attributes`
+   */
+  async upsertUserAttributeSchema(tenantId: string, body: UpsertUserAttributeSchemaRequest): Promise<UserAttributeSchemaResponse> {
+    return this.request<UserAttributeSchemaResponse>({ method: 'PUT', url: `/v1/tenants/${encodeURIComponent(tenantId)}/user-attributes`, data: body });
+  }
+
+  /**
+   * Delete tenant user attribute schema
+   *
+   * Delete a tenant's user attribute schema. Existing user metadata is kept but no longer validated or passed to policies.
+   *
+   * `DELETE /v1/tenants/{tenantId}/user-attributes`
+   */
+  async deleteUserAttributeSchema(tenantId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/tenants/${encodeURIComponent(tenantId)}/user-attributes` });
+  }
+
+  /**
+   * List users
+   *
+   * List and search users in the current tenant (admin only)
+   *
+   * `GET /v1/users`
+   */
+  async listUsers(params?: ListUsersParams): Promise<ListUsersResult> {
+    return this.request<ListUsersResult>({ method: 'GET', url: '/v1/users', params });
+  }
+
+  /**
+   * Get current user profile
+   *
+   * Get authenticated user's profile information
+   *
+   * `GET /v1/users/me`
+   */
+  async getCurrentUser(): Promise<UserProfile> {
+    return this.request<UserProfile>({ method: 'GET', url: '/v1/users/me' });
+  }
+
+  /**
+   * Update current user profile
+   *
+   * Update authenticated user's profile information
+   *
+   * `PATCH /v1/users/me`
+   */
+  async updateCurrentUser(body: UpdateProfileRequest): Promise<UserProfile> {
+    return this.request<UserProfile>({ method: 'PATCH', url: '/v1/users/me', data: body });
+  }
+
+  /**
+   * Delete current user
+   *
+   * Deactivate the authenticated user's account. It is purged after the retention period unless an admin restores it.
+   *
+   * `DELETE /v1/users/me`
+   */
+  async deleteCurrentUser(): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: '/v1/users/me' });
+  }
+
+  /**
+   * List my access requests
+   *
+   * List the caller's own access requests
+   *
+   * `GET /v1/users/me/access-requests`
+   */
+  async listMyAccessRequests(params?: ListMyAccessRequestsParams): Promise<ListMyAccessRequestsResult> {
+    return this.request<ListMyAccessRequestsResult>({ method: 'GET', url: '/v1/users/me/access-requests', params });
+  }
+
+  /**
+   * Get my login history
+   *
+   * List the authenticated user's recent logins, flagging suspicious ones
+   *
+   * `GET /v1/users/me/login-history`
+   */
+  async getMyLoginHistory(params?: GetMyLoginHistoryParams): Promise<GetMyLoginHistoryResult> {
+    return this.request<GetMyLoginHistoryResult>({ method: 'GET', url: '/v1/users/me/login-history', params });
+  }
+
+  /**
+   * Get my permissions
+   *
+   * Get the permissions granted to the authenticated user through their roles
+   *
+   * `GET /v1/users/me/permissions`
+   */
+  async getMyPermissions(): Promise<GetMyPermissionsResult> {
+    return this.request<GetMyPermissionsResult>({ method: 'GET', url: '/v1/users/me/permissions' });
+  }
+
+  /**
+   * Get user by ID
+   *
+   * Get specific user's profile (admin only)
+   *
+   * `GET /v1/users/{userId}`
+   */
+  async getUserById(userId: string): Promise<UserProfile> {
+    return this.request<UserProfile>({ method: 'GET', url: `/v1/users/${encodeURIComponent(userId)}` });
+  }
+
+  /**
+   * Update user attributes
+   *
+   * Set attributes of a user defined by the user attribute schema of the user's tenant. Attributes are stored in the user's metadata and passed to policies as input.user.metadata. A null value removes an attribute. Unknown attributes, values that do not match their definition and missing required attributes fail with 400 INVALID_USER_ATTRIBUTES.
+   *
+   * `PATCH /v1/users/{userId}/attributes`
+   */
+  async updateUserAttributes(userId: string, body: Record<string, any>): Promise<UserProfile> {
+    return this.request<UserProfile>({ method: 'PATCH', url: `/v1/users/${encodeURIComponent(userId)}/attributes`, data: body });
+  }
+
+  /**
+   * Deactivate user
+   *
+   * Deactivate a user, disabling its login. Deactivated users can be restored until they are purged after the retention period. Must be confirmed with a users.deactivate action nonce (admin only)
+   *
+   * `POST /v1/users/{userId}/deactivate`
+   */
+  async deactivateUser(userId: string): Promise<void> {
+    return this.request<void>({ method: 'POST', url: `/v1/users/${encodeURIComponent(userId)}/deactivate` });
+  }
+
+  /**
+   * Grant temporary elevated access
+   *
+   * Grant a role to a user for a limited time, e.g. admin to an on-call engineer for 8 hours, with the reason recorded in the audit log. The assignment is removed automatically once it expires. Durations are capped by ROLE_ELEVATION_MAX_HOURS.
+   *
+   * `POST /v1/users/{userId}/elevations`
+   */
+  async elevateUserRole(userId: string, body: ElevateRoleRequest): Promise<RoleAssignment> {
+    return this.request<RoleAssignment>({ method: 'POST', url: `/v1/users/${encodeURIComponent(userId)}/elevations`, data: body });
+  }
+
+  /**
+   * Restore user
+   *
+   * Reactivate a deactivated user that has not been purged (admin only)
+   *
+   * `POST /v1/users/{userId}/restore`
+   */
+  async restoreUser(userId: string): Promise<UserProfile> {
+    return this.request<UserProfile>({ method: 'POST', url: `/v1/users/${encodeURIComponent(userId)}/restore` });
+  }
+
+  /**
+   * List role assignments
+   *
+   * List the roles assigned to a user with who assigned them and when temporary assignments expire. Expired assignments are listed with expired set until they are removed.
+   *
+   * `GET /v1/users/{userId}/roles`
+   */
+  async listRoleAssignments(userId: string): Promise<RoleAssignment[]> {
+    return this.request<RoleAssignment[]>({ method: 'GET', url: `/v1/users/${encodeURIComponent(userId)}/roles` });
+  }
+
+  /**
+   * Assign role to user
+   *
+   * Assign a role to a user (admin only). Set expiresAt to remove the assignment automatically at that time; assigning a role the user already has replaces its expiry.
+   *
+   * `POST /v1/users/{userId}/roles`
+   */
+  async assignRoleToUser(userId: string, body: AssignRoleToUserRequest): Promise<void> {
+    return this.request<void>({ method: 'POST', url: `/v1/users/${encodeURIComponent(userId)}/roles`, data: body });
+  }
+
+  /**
+   * Remove role from user
+   *
+   * Remove a role from a user (admin only)
+   *
+   * `DELETE /v1/users/{userId}/roles/{roleId}`
+   */
+  async removeRoleFromUser(userId: string, roleId: string): Promise<void> {
+    return this.request<void>({ method: 'DELETE', url: `/v1/users/${encodeURIComponent(userId)}/roles/${encodeURIComponent(roleId)}` });
+  }
+
+  /**
+   * Update user status
+   *
+   * Suspend or reactivate a user (admin only). Suspended users cannot sign in or refresh tokens, their sessions are revoked and authorization policies deny their requests.
+   *
+   * `PATCH /v1/users/{userId}/status`
+   */
+  async updateUserStatus(userId: string, body: UpdateUserStatusRequest): Promise<UserProfile> {
+    return this.request<UserProfile>({ method: 'PATCH', url: `/v1/users/${encodeURIComponent(userId)}/status`, data: body });
+  }
+
+  /**
+   * Unlock user
+   *
+   * Clear a user's lockout after too many failed logins (admin only)
+   *
+   * `POST /v1/users/{userId}/unlock`
+   */
+  async unlockUser(userId: string): Promise<void> {
+    return this.request<void>({ method: 'POST', url: `/v1/users/${encodeURIComponent(userId)}/unlock` });
+  }
+
+  private async request<T>(config: AxiosRequestConfig): Promise<T> {
+    try {
+      const response = await this.http.request<{ success: boolean; data: T }>(config);
+      return response.data.data;
+    } catch (error: any) {
+      if (error.response) {
+        const errorData = error.response.data;
+        throw new HeimdallError(
+          errorData?.error?.message || 'Request failed',
+          errorData?.error?.code || 'REQUEST_FAILED',
+          error.response.status
+        );
+      }
+      throw error;
+    }
+  }
+}
@@ -2,12 +2,14 @@ import axios, { AxiosInstance, AxiosRequestConfig } from 'axios';
 import { HeimdallConfig, HeimdallError } from './types';
 import { AuthModule } from './auth';
 import { UserModule } from './user';
+import { HeimdallApi } from './api';
 import { getDefaultStorage } from './storage';
 
 export class HeimdallClient {
   private client: AxiosInstance;
   public auth: AuthModule;
   public user: UserModule;
+  public api: HeimdallApi;
   private config: HeimdallConfig;
 
   constructor(config: HeimdallConfig) {
@@ -38,6 +40,9 @@ export class HeimdallClient {
 
     this.user = new UserModule(this.client);
 
+    // Generated client covering every API operation
+    this.api = new HeimdallApi(this.client);
+
     // Setup request interceptor to add auth token
     this.client.interceptors.request.use(
       async (config) => {
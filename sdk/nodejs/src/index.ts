@@ -1,6 +1,8 @@
 export { HeimdallClient } from './client';
 export { AuthModule } from './auth';
 export { UserModule } from './user';
+export { HeimdallApi } from './api';
+export * as ApiTypes from './api';
 export { MemoryStorage, LocalStorage, getDefaultStorage } from './storage';
 export {
   HeimdallConfig,
@@ -78,15 +78,13 @@ func TestUserRegistration(t *testing.T) {
 		resp2, err := client.Request(http.MethodPost, "/v1/auth/register", req, nil)
 		utils.AssertNoError(t, err, "Second registration request failed")
 
-		// Can be either 400 or 500 depending on FusionAuth response
-		utils.AssertTrue(t, resp2.StatusCode == http.StatusBadRequest || resp2.StatusCode == http.StatusInternalServerError,
-			fmt.Sprintf("Expected 400 or 500 status code for duplicate email, got %d", resp2.StatusCode))
+		utils.AssertStatusCode(t, http.StatusConflict, resp2.StatusCode, "Duplicate email registration status")
 
 		var authResp helpers.AuthResponse
 		err = client.DecodeResponse(resp2, &authResp)
 		utils.AssertNoError(t, err, "Failed to decode error response")
 
-		helpers.AssertAuthFailure(t, &authResp, "REGISTRATION_FAILED", "Duplicate email registration")
+		helpers.AssertAuthFailure(t, &authResp, "USER_EMAIL_EXISTS", "Duplicate email registration")
 	})
 
 	t.Run("Registration fails with invalid email", func(t *testing.T) {
@@ -125,7 +123,7 @@ func TestUserRegistration(t *testing.T) {
 		err = client.DecodeResponse(resp, &authResp)
 		utils.AssertNoError(t, err, "Failed to decode error response")
 
-		helpers.AssertAuthFailure(t, &authResp, "VALIDATION_ERROR", "Weak password validation")
+		helpers.AssertAuthFailure(t, &authResp, "PASSWORD_POLICY_VIOLATION", "Weak password validation")
 	})
 
 	t.Run("Registration fails with missing required fields", func(t *testing.T) {